@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,21 +11,34 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/controller/api"
+	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/route"
+	"scroll-tech/bridge-history-api/internal/types"
 )
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the cache serves most of the requests.
+var dbPool = database.Pool{
+	Name:                "bridge_history_api",
+	MaxOpenNum:          20,
+	MaxIdleNum:          5,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	app = cli.NewApp()
 
@@ -31,7 +46,14 @@ func init() {
 	app.Name = "Scroll Bridge History API Web Service"
 	app.Usage = "The Scroll Bridge History API Web Service"
 	app.Flags = append(app.Flags, utils.CommonFlags...)
-	app.Commands = []*cli.Command{}
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
+	app.Commands = []*cli.Command{
+		{
+			Name:   "openapi",
+			Usage:  "Print the OpenAPI spec of the apis, e.g. to generate SDKs.",
+			Action: printOpenAPI,
+		},
+	}
 
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -45,7 +67,12 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
-	db, err := database.InitDB(cfg.DB)
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
+	db, err := database.InitDBWithPool(cfg.DB, dbPool)
 	if err != nil {
 		log.Crit("failed to init db", "err", err)
 	}
@@ -54,6 +81,10 @@ func action(ctx *cli.Context) error {
 			log.Error("failed to close db", "err", err)
 		}
 	}()
+	if err = orm.CheckSchema(ctx.Context, db); err != nil {
+		log.Crit("failed to check db schema", "err", err)
+	}
+	types.SetChainIDs(chainIDs(ctx.Context, cfg, db))
 	opts := &redis.Options{
 		Addr:         cfg.Redis.Address,
 		Username:     cfg.Redis.Username,
@@ -71,10 +102,12 @@ func action(ctx *cli.Context) error {
 	log.Info("init redis client", "addr", opts.Addr, "user name", opts.Username, "is local", cfg.Redis.Local,
 		"min idle connections", opts.MinIdleConns, "read timeout", opts.ReadTimeout)
 	redisClient := redis.NewClient(opts)
-	api.InitController(db, redisClient)
+	subCtx, cancel := context.WithCancel(ctx.Context)
+	defer cancel()
+	api.InitController(subCtx, cfg, db, redisClient)
 
 	router := gin.Default()
-	registry := prometheus.DefaultRegisterer
+	registry := metrics.Registerer()
 	route.Route(router, cfg, registry)
 
 	go func() {
@@ -96,6 +129,42 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
+// chainIDs returns the chain ids of the L1 and L2 networks the db was indexed from, it exits if they differ from the
+// configured ones. The configured ones are used until the fetcher records the network.
+func chainIDs(ctx context.Context, cfg *config.Config, db *gorm.DB) (uint64, uint64) {
+	var l1ChainID, l2ChainID uint64
+	if cfg.L1 != nil {
+		l1ChainID = cfg.L1.ChainID
+	}
+	if cfg.L2 != nil {
+		l2ChainID = cfg.L2.ChainID
+	}
+	network, err := orm.NewBridgeNetwork(db).GetBridgeNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to get the network of the db", "err", err)
+	}
+	if network == nil {
+		if l1ChainID == 0 || l2ChainID == 0 {
+			log.Crit("unknown chain ids, configure them, select a network profile with --network or start the fetcher first")
+		}
+		return l1ChainID, l2ChainID
+	}
+	if (l1ChainID != 0 && l1ChainID != network.L1ChainID) || (l2ChainID != 0 && l2ChainID != network.L2ChainID) {
+		log.Crit("the db was indexed from another network", "l1 chain id", network.L1ChainID, "l2 chain id", network.L2ChainID,
+			"configured l1 chain id", l1ChainID, "configured l2 chain id", l2ChainID)
+	}
+	return network.L1ChainID, network.L2ChainID
+}
+
+func printOpenAPI(ctx *cli.Context) error {
+	spec, err := json.MarshalIndent(route.OpenAPI(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the OpenAPI spec, error: %w", err)
+	}
+	_, err = fmt.Fprintln(ctx.App.Writer, string(spec))
+	return err
+}
+
 // Run event watcher cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/utils"
+)
+
+var app *cli.App
+
+var (
+	messageHashFlag = cli.StringFlag{
+		Name:  "message-hash",
+		Usage: "Hash of the cross chain message",
+	}
+	txHashFlag = cli.StringFlag{
+		Name:  "tx-hash",
+		Usage: "Hash of a tx sending or relaying cross chain messages",
+	}
+	privateKeyFlag = cli.StringFlag{
+		Name:    "private-key",
+		Usage:   "Hex encoded private key of the account sending the tx, the gas is estimated from the message sender if not set",
+		EnvVars: []string{"BRIDGE_OPS_PRIVATE_KEY"},
+	}
+	sendFlag = cli.BoolFlag{
+		Name:  "send",
+		Usage: "Send the tx, it is only printed with its estimated gas otherwise",
+	}
+	gasLimitFlag = cli.Uint64Flag{
+		Name:     "gas-limit",
+		Usage:    "New L2 gas limit of the replayed L1 message",
+		Required: true,
+	}
+	batchSizeFlag = cli.IntFlag{
+		Name:  "batch-size",
+		Usage: "Number of messages updated per db transaction",
+		Value: 1000,
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = "bridge_ops"
+	app.Usage = "The Scroll bridge operations tool, it inspects cross chain messages, builds their relay and claim txs and maintains the indexed data"
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
+
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+
+	app.Commands = []*cli.Command{
+		{
+			Name:   "inspect",
+			Usage:  "Print the state of the messages of a message hash or tx hash, from the db and from the chains.",
+			Action: inspectMessages,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &messageHashFlag, &txHashFlag},
+		},
+		{
+			Name:   "claim",
+			Usage:  "Build, estimate and optionally send the L1 tx claiming a finalized L2 withdrawal.",
+			Action: claimWithdrawal,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &messageHashFlag, &privateKeyFlag, &sendFlag},
+		},
+		{
+			Name:   "replay",
+			Usage:  "Build, estimate and optionally send the L1 tx replaying an L1 deposit whose relay failed on L2, with a new gas limit.",
+			Action: replayDeposit,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &messageHashFlag, &gasLimitFlag, &privateKeyFlag, &sendFlag},
+		},
+		{
+			Name:   "offload-message-data",
+			Usage:  "Move the message data above the L2 messageDataOffloadThreshold of the messages indexed before it was set to the cross_message_data table.",
+			Action: offloadMessageData,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &batchSizeFlag},
+		},
+	}
+}
+
+// Run bridge ops cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
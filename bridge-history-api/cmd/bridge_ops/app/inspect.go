@@ -0,0 +1,302 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/utils"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// messageState is the state of a cross chain message, as indexed in the db and as seen on the chains.
+type messageState struct {
+	MessageHash  string `json:"message_hash"`
+	MessageType  string `json:"message_type"`
+	TxStatus     string `json:"tx_status"`
+	RollupStatus string `json:"rollup_status"`
+	Sender       string `json:"sender"`
+	Receiver     string `json:"receiver"`
+	MessageFrom  string `json:"message_from"`
+	MessageTo    string `json:"message_to"`
+	MessageValue string `json:"message_value"`
+	MessageNonce uint64 `json:"message_nonce"`
+	MessageData  string `json:"message_data"`
+	L1TxHash     string `json:"l1_tx_hash,omitempty"`
+	L2TxHash     string `json:"l2_tx_hash,omitempty"`
+	BatchIndex   uint64 `json:"batch_index,omitempty"`
+	RelayFailure string `json:"relay_failure,omitempty"`
+
+	Chain *chainState `json:"chain,omitempty"`
+}
+
+// chainState is the state of a cross chain message read from the chains.
+type chainState struct {
+	L1TxStatus string `json:"l1_tx_status,omitempty"`
+	L2TxStatus string `json:"l2_tx_status,omitempty"`
+	// only for L2 withdrawals
+	ExecutedOnL1 *bool `json:"executed_on_l1,omitempty"`
+	// only for L1 deposits
+	SkippedOnL2 *bool `json:"skipped_on_l2,omitempty"`
+	DroppedOnL1 *bool `json:"dropped_on_l1,omitempty"`
+}
+
+// opsEnv is the config, db and chain clients of a command.
+type opsEnv struct {
+	cfg      *config.Config
+	db       *gorm.DB
+	l1Client *ethclient.Client
+	l2Client *ethclient.Client
+}
+
+func newOpsEnv(ctx *cli.Context) (*opsEnv, error) {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file %s, error: %w", cfgFile, err)
+	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load network profile, error: %w", err)
+	}
+	cfg.ApplyNetwork(network)
+	if cfg.L1.MessengerAddr == "" || cfg.L1.MessageQueueAddr == "" {
+		return nil, errors.New("missing contract addresses, configure them or select a network profile with --network")
+	}
+
+	l1Client, err := ethclient.Dial(cfg.L1.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to L1 geth, error: %w", err)
+	}
+	l2Client, err := ethclient.Dial(cfg.L2.Endpoint)
+	if err != nil {
+		l1Client.Close()
+		return nil, fmt.Errorf("failed to connect to L2 geth, error: %w", err)
+	}
+	db, err := database.InitDB(cfg.DB)
+	if err != nil {
+		l1Client.Close()
+		l2Client.Close()
+		return nil, fmt.Errorf("failed to init db, error: %w", err)
+	}
+	return &opsEnv{cfg: cfg, db: db, l1Client: l1Client, l2Client: l2Client}, nil
+}
+
+func (e *opsEnv) close() {
+	e.l1Client.Close()
+	e.l2Client.Close()
+	if err := database.CloseDB(e.db); err != nil {
+		log.Error("failed to close db connection", "error", err)
+	}
+}
+
+// getMessage returns the indexed message of the message hash flag.
+func (e *opsEnv) getMessage(ctx *cli.Context) (*orm.CrossMessage, error) {
+	messageHash := ctx.String(messageHashFlag.Name)
+	if messageHash == "" {
+		return nil, errors.New("missing the message hash")
+	}
+	crossMessageOrm := orm.NewCrossMessage(e.db)
+	message, err := crossMessageOrm.GetMessageByMessageHash(ctx.Context, messageHash)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, fmt.Errorf("message %s is not indexed", messageHash)
+	}
+	if err := crossMessageOrm.LoadMessageData(ctx.Context, []*orm.CrossMessage{message}); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// inspectMessages prints the state of the message of a message hash, or of the messages of a tx hash.
+func inspectMessages(ctx *cli.Context) error {
+	if (ctx.String(messageHashFlag.Name) == "") == (ctx.String(txHashFlag.Name) == "") {
+		return errors.New("exactly one of the message hash and the tx hash is required")
+	}
+	env, err := newOpsEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	var messages []*orm.CrossMessage
+	if ctx.String(messageHashFlag.Name) != "" {
+		message, err := env.getMessage(ctx)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	} else {
+		crossMessageOrm := orm.NewCrossMessage(env.db)
+		messages, err = crossMessageOrm.GetMessagesByTxHashes(ctx.Context, []string{ctx.String(txHashFlag.Name)})
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return fmt.Errorf("no message of tx %s is indexed", ctx.String(txHashFlag.Name))
+		}
+		if err := crossMessageOrm.LoadMessageData(ctx.Context, messages); err != nil {
+			return err
+		}
+	}
+
+	states := make([]*messageState, 0, len(messages))
+	for _, message := range messages {
+		state := newMessageState(message)
+		// the chain state is best effort, the indexed state is printed anyway.
+		if state.Chain, err = env.getChainState(ctx.Context, message); err != nil {
+			log.Warn("failed to read message state from chain", "message hash", message.MessageHash, "error", err)
+		}
+		states = append(states, state)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(states)
+}
+
+func newMessageState(message *orm.CrossMessage) *messageState {
+	state := &messageState{
+		MessageHash:  message.MessageHash,
+		MessageType:  messageTypeName(orm.MessageType(message.MessageType)),
+		TxStatus:     txStatusName(orm.TxStatusType(message.TxStatus)),
+		RollupStatus: "unknown",
+		Sender:       message.Sender,
+		Receiver:     message.Receiver,
+		MessageFrom:  message.MessageFrom,
+		MessageTo:    message.MessageTo,
+		MessageValue: message.MessageValue,
+		MessageNonce: message.MessageNonce,
+		MessageData:  message.MessageData,
+		L1TxHash:     message.L1TxHash,
+		L2TxHash:     message.L2TxHash,
+		BatchIndex:   message.BatchIndex,
+	}
+	if orm.RollupStatusType(message.RollupStatus) == orm.RollupStatusTypeFinalized {
+		state.RollupStatus = "finalized"
+	}
+	if message.L2RelayFailureSelector != "" || message.L2RelayFailureReason != "" {
+		state.RelayFailure = message.L2RelayFailureSelector + " " + message.L2RelayFailureReason
+	}
+	return state
+}
+
+func (e *opsEnv) getChainState(ctx context.Context, message *orm.CrossMessage) (*chainState, error) {
+	state := &chainState{}
+	var err error
+	if message.L1TxHash != "" {
+		if state.L1TxStatus, err = txStatus(ctx, e.l1Client, message.L1TxHash); err != nil {
+			return nil, err
+		}
+	}
+	if message.L2TxHash != "" {
+		if state.L2TxStatus, err = txStatus(ctx, e.l2Client, message.L2TxHash); err != nil {
+			return nil, err
+		}
+	}
+
+	switch orm.MessageType(message.MessageType) {
+	case orm.MessageTypeL2SentMessage:
+		executed, err := callBool(ctx, e.l1Client, backendabi.IL1ScrollMessengerABI, e.cfg.L1.MessengerAddr, "isL2MessageExecuted", common.HexToHash(message.MessageHash))
+		if err != nil {
+			return nil, err
+		}
+		state.ExecutedOnL1 = &executed
+	case orm.MessageTypeL1SentMessage:
+		queueIndex := new(big.Int).SetUint64(message.MessageNonce)
+		skipped, err := callBool(ctx, e.l1Client, backendabi.IL1MessageQueueABI, e.cfg.L1.MessageQueueAddr, "isMessageSkipped", queueIndex)
+		if err != nil {
+			return nil, err
+		}
+		dropped, err := callBool(ctx, e.l1Client, backendabi.IL1MessageQueueABI, e.cfg.L1.MessageQueueAddr, "isMessageDropped", queueIndex)
+		if err != nil {
+			return nil, err
+		}
+		state.SkippedOnL2, state.DroppedOnL1 = &skipped, &dropped
+	}
+	return state, nil
+}
+
+// txStatus returns the receipt status of the tx, or pending if it has no receipt yet.
+func txStatus(ctx context.Context, client *ethclient.Client, txHash string) (string, error) {
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return "pending", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get receipt of tx %s, error: %w", txHash, err)
+	}
+	if receipt.Status == 1 {
+		return "success in block " + receipt.BlockNumber.String(), nil
+	}
+	return "reverted in block " + receipt.BlockNumber.String(), nil
+}
+
+func callBool(ctx context.Context, client *ethclient.Client, contractABI *abi.ABI, address, method string, args ...interface{}) (bool, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack %s, error: %w", method, err)
+	}
+	to := common.HexToAddress(address)
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to call %s, error: %w", method, err)
+	}
+	var result bool
+	if err := contractABI.UnpackIntoInterface(&result, method, output); err != nil {
+		return false, fmt.Errorf("failed to unpack %s, error: %w", method, err)
+	}
+	return result, nil
+}
+
+func messageTypeName(messageType orm.MessageType) string {
+	switch messageType {
+	case orm.MessageTypeL1SentMessage:
+		return "L1 deposit"
+	case orm.MessageTypeL2SentMessage:
+		return "L2 withdrawal"
+	default:
+		return "unknown"
+	}
+}
+
+func txStatusName(status orm.TxStatusType) string {
+	switch status {
+	case orm.TxStatusTypeSent:
+		return "sent"
+	case orm.TxStatusTypeSentTxReverted:
+		return "sent tx reverted"
+	case orm.TxStatusTypeRelayed:
+		return "relayed"
+	case orm.TxStatusTypeFailedRelayed:
+		return "failed relayed"
+	case orm.TxStatusTypeRelayTxReverted:
+		return "relay tx reverted"
+	case orm.TxStatusTypeSkipped:
+		return "skipped"
+	case orm.TxStatusTypeDropped:
+		return "dropped"
+	case orm.TxStatusTypeDroppedRefunded:
+		return "dropped and refunded"
+	default:
+		return strconv.Itoa(int(status))
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// offloadMessageData moves the message data above the L2 messageDataOffloadThreshold of the messages indexed before
+// it was set to the cross_message_data table, in batches.
+func offloadMessageData(ctx *cli.Context) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s, error: %w", cfgFile, err)
+	}
+	threshold := cfg.L2.MessageDataOffloadThreshold
+	if threshold <= 0 {
+		return errors.New("messageDataOffloadThreshold of the L2 fetcher config is not set")
+	}
+	batchSize := ctx.Int(batchSizeFlag.Name)
+	if batchSize <= 0 {
+		return errors.New("the batch size must be positive")
+	}
+
+	db, err := database.InitDB(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("failed to init db, error: %w", err)
+	}
+	defer func() {
+		if err := database.CloseDB(db); err != nil {
+			log.Error("failed to close db connection", "error", err)
+		}
+	}()
+
+	crossMessageOrm := orm.NewCrossMessage(db)
+	var total int
+	for {
+		moved, err := crossMessageOrm.OffloadMessageData(ctx.Context, threshold, batchSize)
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			break
+		}
+		total += moved
+		log.Info("offloaded message data", "batch", moved, "total", total)
+	}
+	log.Info("message data offload finished", "threshold", threshold, "total", total)
+	return nil
+}
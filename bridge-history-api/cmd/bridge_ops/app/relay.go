@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// claimWithdrawal builds the claim tx of a finalized L2 withdrawal, prints it with its estimated gas, and sends it if requested.
+func claimWithdrawal(ctx *cli.Context) error {
+	env, err := newOpsEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	message, err := env.getMessage(ctx)
+	if err != nil {
+		return err
+	}
+	if orm.TxStatusType(message.TxStatus) == orm.TxStatusTypeRelayed {
+		return fmt.Errorf("message %s is already claimed", message.MessageHash)
+	}
+	data, err := logic.ClaimCalldata(message)
+	if err != nil {
+		return err
+	}
+	return env.sendL1Tx(ctx, common.HexToAddress(message.Sender), big.NewInt(0), data)
+}
+
+// replayDeposit builds the replay tx of an L1 deposit, prints it with its estimated gas, and sends it if requested.
+func replayDeposit(ctx *cli.Context) error {
+	env, err := newOpsEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	message, err := env.getMessage(ctx)
+	if err != nil {
+		return err
+	}
+	switch orm.TxStatusType(message.TxStatus) {
+	case orm.TxStatusTypeFailedRelayed, orm.TxStatusTypeRelayTxReverted:
+	default:
+		return fmt.Errorf("message %s has no failed relay to replay", message.MessageHash)
+	}
+
+	gasLimit := ctx.Uint64(gasLimitFlag.Name)
+	if gasLimit > uint64(^uint32(0)) {
+		return fmt.Errorf("gas limit %d exceeds uint32", gasLimit)
+	}
+	fee, err := env.estimateCrossDomainMessageFee(ctx.Context, gasLimit)
+	if err != nil {
+		return err
+	}
+
+	sender := common.HexToAddress(message.Sender)
+	key, err := parsePrivateKey(ctx)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	data, err := logic.ReplayCalldata(message, uint32(gasLimit), sender)
+	if err != nil {
+		return err
+	}
+	return env.sendL1Tx(ctx, sender, fee, data)
+}
+
+func (e *opsEnv) estimateCrossDomainMessageFee(ctx context.Context, gasLimit uint64) (*big.Int, error) {
+	data, err := backendabi.IL1MessageQueueABI.Pack("estimateCrossDomainMessageFee", new(big.Int).SetUint64(gasLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack estimateCrossDomainMessageFee, error: %w", err)
+	}
+	to := common.HexToAddress(e.cfg.L1.MessageQueueAddr)
+	output, err := e.l1Client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate cross domain message fee, error: %w", err)
+	}
+	values, err := backendabi.IL1MessageQueueABI.Unpack("estimateCrossDomainMessageFee", output)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("failed to unpack cross domain message fee, error: %v", err)
+	}
+	fee, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("invalid cross domain message fee")
+	}
+	return fee, nil
+}
+
+func parsePrivateKey(ctx *cli.Context) (*ecdsa.PrivateKey, error) {
+	privateKey := ctx.String(privateKeyFlag.Name)
+	if privateKey == "" {
+		return nil, nil
+	}
+	key, err := crypto.HexToECDSA(common.Bytes2Hex(common.FromHex(privateKey)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key, error: %w", err)
+	}
+	return key, nil
+}
+
+// sendL1Tx estimates the gas of the tx to the L1 messenger and prints it, and sends the tx if requested.
+// The gas is estimated from the account of the private key, or from the fallback sender if no key is set.
+func (e *opsEnv) sendL1Tx(ctx *cli.Context, fallbackSender common.Address, value *big.Int, data []byte) error {
+	key, err := parsePrivateKey(ctx)
+	if err != nil {
+		return err
+	}
+	from := fallbackSender
+	if key != nil {
+		from = crypto.PubkeyToAddress(key.PublicKey)
+	}
+
+	to := common.HexToAddress(e.cfg.L1.MessengerAddr)
+	gas, err := e.l1Client.EstimateGas(ctx.Context, ethereum.CallMsg{From: from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas, the tx would revert, error: %w", err)
+	}
+	fmt.Printf("from:  %s\nto:    %s\nvalue: %s\ngas:   %d\ndata:  0x%s\n", from.Hex(), to.Hex(), value, gas, common.Bytes2Hex(data))
+
+	if !ctx.Bool(sendFlag.Name) {
+		return nil
+	}
+	if key == nil {
+		return errors.New("the private key is required to send the tx")
+	}
+	txHash, err := sendDynamicFeeTx(ctx.Context, e.l1Client, key, to, value, data, gas*6/5)
+	if err != nil {
+		return err
+	}
+	log.Info("sent tx", "hash", txHash.Hex())
+	return nil
+}
+
+func sendDynamicFeeTx(ctx context.Context, client *ethclient.Client, key *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte, gas uint64) (common.Hash, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain id, error: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get nonce, error: %w", err)
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to suggest gas tip cap, error: %w", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get head, error: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign tx, error: %w", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send tx, error: %w", err)
+	}
+	return tx.Hash(), nil
+}
@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/bridge-history-api/cmd/bridge_ops/app"
+
+func main() {
+	app.Run()
+}
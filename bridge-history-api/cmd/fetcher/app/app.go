@@ -5,21 +5,38 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
+	"scroll-tech/common/clock"
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/controller/fetcher"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
 )
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the L1 and L2 fetchers write the events of a range at a time.
+var dbPool = database.Pool{
+	Name:                "bridge_history_fetcher",
+	MaxOpenNum:          10,
+	MaxIdleNum:          2,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	app = cli.NewApp()
 
@@ -27,6 +44,8 @@ func init() {
 	app.Name = "Scroll Bridge History API Message Fetcher"
 	app.Usage = "The Scroll Bridge History API Message Fetcher"
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
+	app.Flags = append(app.Flags, fetcherFlags...)
 	app.Commands = []*cli.Command{}
 
 	app.Before = func(ctx *cli.Context) error {
@@ -40,6 +59,14 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
+	if cfg.L1.MessengerAddr == "" || cfg.L2.MessengerAddr == "" {
+		log.Crit("missing contract addresses, configure them or select a network profile with --network")
+	}
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	defer cancel()
 
@@ -53,7 +80,7 @@ func action(ctx *cli.Context) error {
 		log.Crit("failed to connect to L2 geth", "endpoint", cfg.L2.Endpoint, "err", err)
 	}
 
-	db, err := database.InitDB(cfg.DB)
+	db, err := database.InitDBWithPool(cfg.DB, dbPool)
 	if err != nil {
 		log.Crit("failed to init db", "err", err)
 	}
@@ -65,14 +92,102 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to connect to db", "config file", cfgFile, "error", err)
 	}
+	if err = orm.CheckSchema(ctx.Context, db); err != nil {
+		log.Crit("failed to check db schema", "err", err)
+	}
+
+	l1ChainID := checkChainID(ctx.Context, "L1", cfg.L1, l1Client)
+	l2ChainID := checkChainID(ctx.Context, "L2", cfg.L2, l2Client)
+	if err = orm.NewBridgeNetwork(db).CheckOrRecordBridgeNetwork(ctx.Context, l1ChainID, l2ChainID); err != nil {
+		log.Crit("failed to check the network of the db", "err", err)
+	}
 
 	observability.Server(ctx, db)
 
-	l1MessageFetcher := fetcher.NewL1MessageFetcher(subCtx, cfg.L1, db, l1Client)
-	go l1MessageFetcher.Start()
+	if ctx.Bool(reindexFlag.Name) {
+		reindex(subCtx, cfg, db, l1Client, l2Client, l1ChainID, l2ChainID)
+		return nil
+	}
+
+	startFetchers := func(fetcherCtx context.Context, leadership fetcher.LeadershipChecker) {
+		if cfg.ConsistencyCheck != nil && cfg.ConsistencyCheck.Enabled {
+			consistencyChecker := fetcher.NewConsistencyChecker(fetcherCtx, cfg.ConsistencyCheck, db, metrics.Registerer())
+			if checkErr := consistencyChecker.CheckOrFail(); checkErr != nil {
+				log.Crit("consistency check failed", "err", checkErr)
+			}
+		}
+
+		partitionMaintainer := fetcher.NewPartitionMaintainer(fetcherCtx, db)
+		partitionMaintainer.Start()
+
+		l1MessageFetcher := fetcher.NewL1MessageFetcher(fetcherCtx, cfg.L1, db, l1Client, leadership)
+		go l1MessageFetcher.Start()
+
+		l2MessageFetcher := fetcher.NewL2MessageFetcher(fetcherCtx, cfg.L2, db, l2Client, leadership)
+		if cfg.L1.ClaimAfterFinality {
+			l2MessageFetcher.SetL1FinalityGate(l1Client)
+		}
+		go l2MessageFetcher.Start()
+
+		if cfg.ClaimReconciliation != nil && cfg.ClaimReconciliation.Enabled {
+			claimReconciler := fetcher.NewClaimReconciler(fetcherCtx, cfg.ClaimReconciliation, cfg.L1.MessengerAddr, db, l1Client, leadership, metrics.Registerer())
+			claimReconciler.Start()
+		}
+
+		if cfg.Stats != nil && cfg.Stats.Enabled {
+			statsAggregator := fetcher.NewStatsAggregator(fetcherCtx, cfg.Stats, cfg.L1, cfg.L2, db, leadership, metrics.Registerer())
+			statsAggregator.Start()
+		}
+
+		if cfg.PendingDeposits != nil && cfg.PendingDeposits.Enabled {
+			endpoint := cfg.PendingDeposits.Endpoint
+			if endpoint == "" {
+				endpoint = cfg.L1.Endpoint
+			}
+			txPoolClient, dialErr := rpc.Dial(endpoint)
+			if dialErr != nil {
+				log.Crit("failed to connect to the L1 node of the pending deposits", "endpoint", endpoint, "err", dialErr)
+			}
+			pendingDepositWatcher := fetcher.NewPendingDepositWatcher(fetcherCtx, cfg.PendingDeposits, cfg.L1, db, txPoolClient, leadership, clock.New(), metrics.Registerer())
+			pendingDepositWatcher.Start()
+		}
+
+		if cfg.AutoClaim != nil && cfg.AutoClaim.Enabled {
+			claimClient := l1Client
+			if cfg.AutoClaim.Endpoint != "" {
+				var dialErr error
+				if claimClient, dialErr = ethclient.Dial(cfg.AutoClaim.Endpoint); dialErr != nil {
+					log.Crit("failed to connect to the L1 node of the auto claimer", "endpoint", cfg.AutoClaim.Endpoint, "err", dialErr)
+				}
+			}
+			autoClaimer, claimErr := fetcher.NewAutoClaimer(fetcherCtx, cfg.AutoClaim, cfg.L1.MessengerAddr, db, claimClient, leadership, clock.New(), metrics.Registerer())
+			if claimErr != nil {
+				log.Crit("failed to create the auto claimer", "err", claimErr)
+			}
+			autoClaimer.Start()
+		}
+	}
+
+	if cfg.LeaderElection != nil && cfg.LeaderElection.Enabled {
+		leaderElector := fetcher.NewLeaderElector(cfg.LeaderElection, db, metrics.Registerer())
+		go func() {
+			log.Info("waiting for fetcher leadership")
+			leaderCtx, campaignErr := leaderElector.Campaign(subCtx)
+			if campaignErr != nil {
+				log.Info("fetcher leader election stopped", "err", campaignErr)
+				return
+			}
+			startFetchers(leaderCtx, leaderElector)
 
-	l2MessageFetcher := fetcher.NewL2MessageFetcher(subCtx, cfg.L2, db, l2Client)
-	go l2MessageFetcher.Start()
+			<-leaderCtx.Done()
+			if subCtx.Err() == nil {
+				// The fetchers can not be restarted in place, exit to rejoin the election as a standby.
+				log.Crit("lost fetcher leadership, exiting")
+			}
+		}()
+	} else {
+		startFetchers(subCtx, fetcher.SoleInstance)
+	}
 
 	// Catch CTRL-C to ensure a graceful shutdown.
 	interrupt := make(chan os.Signal, 1)
@@ -84,6 +199,66 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
+// checkChainID returns the chain id of the endpoint of the layer, it exits if the chain id differs from the configured one.
+func checkChainID(ctx context.Context, layer string, cfg *config.FetcherConfig, client *ethclient.Client) uint64 {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Crit("failed to get the chain id", "layer", layer, "endpoint", cfg.Endpoint, "err", err)
+	}
+	if cfg.ChainID != 0 && chainID.Uint64() != cfg.ChainID {
+		log.Crit("the endpoint is of another chain than configured", "layer", layer, "endpoint", cfg.Endpoint, "chain id", chainID, "configured chain id", cfg.ChainID)
+	}
+	return chainID.Uint64()
+}
+
+// reindex reindexes all the events into orm.ReindexSchema and swaps the reindexed tables in, the running fetchers must
+// be stopped first. With leader election, it waits for the leadership, i.e. until the fetchers are stopped.
+func reindex(ctx context.Context, cfg *config.Config, db *gorm.DB, l1Client, l2Client *ethclient.Client, l1ChainID, l2ChainID uint64) {
+	leadership := fetcher.SoleInstance
+	if cfg.LeaderElection != nil && cfg.LeaderElection.Enabled {
+		leaderElector := fetcher.NewLeaderElector(cfg.LeaderElection, db, metrics.Registerer())
+		log.Info("waiting for fetcher leadership, stop the running fetchers")
+		leaderCtx, err := leaderElector.Campaign(ctx)
+		if err != nil {
+			log.Crit("fetcher leader election stopped", "err", err)
+		}
+		ctx, leadership = leaderCtx, leaderElector
+	}
+
+	if err := orm.CreateReindexSchema(ctx, db); err != nil {
+		log.Crit("failed to create the reindex schema", "err", err)
+	}
+	reindexCfg := *cfg.DB
+	reindexCfg.SearchPath = orm.ReindexSchema
+	reindexPool := dbPool
+	reindexPool.Name = "bridge_history_reindex"
+	reindexDB, err := database.InitDBWithPool(&reindexCfg, reindexPool)
+	if err != nil {
+		log.Crit("failed to init reindex db", "err", err)
+	}
+	defer func() {
+		if deferErr := database.CloseDB(reindexDB); deferErr != nil {
+			log.Error("failed to close reindex db", "err", deferErr)
+		}
+	}()
+	sqlDB, err := reindexDB.DB()
+	if err != nil {
+		log.Crit("failed to get reindex db connection", "err", err)
+	}
+	if err = migrate.Migrate(sqlDB); err != nil {
+		log.Crit("failed to migrate the reindex schema", "err", err)
+	}
+	if err = orm.NewBridgeNetwork(reindexDB).CheckOrRecordBridgeNetwork(ctx, l1ChainID, l2ChainID); err != nil {
+		log.Crit("failed to record the network of the reindex schema", "err", err)
+	}
+
+	log.Info("start reindexing", "schema", orm.ReindexSchema)
+	if err = fetcher.NewReindexer(ctx, cfg, db, reindexDB, l1Client, l2Client, leadership).Run(); err != nil {
+		log.Crit("failed to reindex", "err", err)
+	}
+	log.Info("reindex done, restart the fetchers without --reindex")
+}
+
 // Run event watcher cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
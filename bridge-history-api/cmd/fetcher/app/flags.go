@@ -0,0 +1,15 @@
+package app
+
+import "github.com/urfave/cli/v2"
+
+var (
+	fetcherFlags = []cli.Flag{
+		&reindexFlag,
+	}
+	// reindexFlag runs a full reindex with bulk loading, then swaps the reindexed tables in and exits.
+	reindexFlag = cli.BoolFlag{
+		Name:  "reindex",
+		Usage: "Reindex all the events into a staging schema with COPY, swap the reindexed tables in and exit",
+		Value: false,
+	}
+)
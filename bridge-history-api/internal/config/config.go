@@ -5,16 +5,26 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"scroll-tech/common/chains"
 	"scroll-tech/common/database"
+	"scroll-tech/common/secrets"
 )
 
 // FetcherConfig is the configuration of Layer1 or Layer2 fetcher.
 type FetcherConfig struct {
+	ChainID                  uint64 `json:"chainID"` // Optional with a network profile, the services refuse to start if the endpoint or the database is of another chain.
 	Confirmation             uint64 `json:"confirmation"`
 	Endpoint                 string `json:"endpoint"`
+	WSEndpoint               string `json:"wsEndpoint"`  // Optional websocket endpoint, subscribes to new heads to trigger fetching instead of waiting for the next poll.
 	StartHeight              uint64 `json:"startHeight"` // Can only be configured to contract deployment height, message proof should be updated from the very beginning.
 	BlockTime                int64  `json:"blockTime"`
 	FetchLimit               uint64 `json:"fetchLimit"`
+	MaxFetchLimit            uint64 `json:"maxFetchLimit"`          // Optional, sizes the fetched ranges by their log density between minFetchLimit and it, starting at fetchLimit, the range is fixed if 0.
+	MinFetchLimit            uint64 `json:"minFetchLimit"`          // Optional, lower bound of the adaptive range size, defaults to 1.
+	TargetLogsPerFetch       int    `json:"targetLogsPerFetch"`     // Optional, the adaptive range shrinks above this many logs and grows below a quarter of them, defaults to 2000.
+	FilterAddressBatchSize   int    `json:"filterAddressBatchSize"` // Optional, max number of contracts per log filter, defaults to 20, all watched contracts are queried in a single filter if negative.
 	MessengerAddr            string `json:"MessengerAddr"`
 	ETHGatewayAddr           string `json:"ETHGatewayAddr"`
 	StandardERC20GatewayAddr string `json:"StandardERC20GatewayAddr"`
@@ -28,6 +38,12 @@ type FetcherConfig struct {
 	ScrollChainAddr          string `json:"ScrollChainAddr"`
 	GatewayRouterAddr        string `json:"GatewayRouterAddr"`
 	MessageQueueAddr         string `json:"MessageQueueAddr"`
+	FeeVaultAddr             string `json:"FeeVaultAddr"`       // Optional, only used in L2, the withdrawals of the L2 tx fee vault are indexed into fee_vault_withdrawal if set.
+	TraceFailedRelays        bool   `json:"traceFailedRelays"`  // Optional, only used in L2, decodes revert reasons of failed relays, requires the debug namespace of the endpoint.
+	ClaimAfterFinality       bool   `json:"claimAfterFinality"` // Optional, only used in L1, withdrawals are only finalized and claimable once the L1 block finalizing their batch is finalized by the beacon chain.
+	// Optional, only used in L2, the message data longer than this many bytes, e.g. of contract calls, is stored in a side table and
+	// loaded only when read, shrinking cross_message_v2, all message data is inline if 0.
+	MessageDataOffloadThreshold int `json:"messageDataOffloadThreshold"`
 }
 
 // RedisConfig redis config
@@ -41,12 +57,142 @@ type RedisConfig struct {
 	ReadTimeoutMs int    `json:"readTimeoutMs"`
 }
 
+// ENSConfig is the configuration of the reverse ENS resolution of addresses in API responses.
+// Names are resolved through the L1 endpoint.
+type ENSConfig struct {
+	Enabled        bool   `json:"enabled"`
+	RegistryAddr   string `json:"registryAddr"`   // Optional, defaults to the ENS registry deployed on Ethereum mainnet and testnets.
+	CacheExpireSec uint64 `json:"cacheExpireSec"` // Optional, defaults to one hour.
+}
+
+// ServerConfig is the configuration of the http layer of the API server.
+type ServerConfig struct {
+	CORSAllowOrigins    []string `json:"corsAllowOrigins"`    // Optional, all origins are allowed if empty.
+	EnableGzip          bool     `json:"enableGzip"`          // Compresses responses for clients accepting gzip.
+	MaxRequestBodyBytes int64    `json:"maxRequestBodyBytes"` // Optional, defaults to 1MB.
+	RequestTimeoutSec   uint64   `json:"requestTimeoutSec"`   // Optional, defaults to 30 seconds.
+	QueryTimeoutMs      uint64   `json:"queryTimeoutMs"`      // Optional, bound of each db read of a request, defaults to 5 seconds.
+	// Optional, query timeouts in milliseconds overriding queryTimeoutMs per route path, e.g. "/api/v2/txs".
+	QueryTimeoutOverridesMs map[string]uint64 `json:"queryTimeoutOverridesMs"`
+	// Optional, the keystore of the operator key signing JSON responses, responses are signed if set.
+	// The keystore password is read from the RESPONSE_SIGNING_KEYSTORE_PASSWORD environment variable.
+	ResponseSigningKeystorePath string `json:"responseSigningKeystorePath"`
+}
+
+// LeaderElectionConfig is the configuration of the fetcher leader election, used to run multiple fetcher replicas
+// where only the elected leader fetches events.
+type LeaderElectionConfig struct {
+	Enabled     bool   `json:"enabled"`
+	LockID      int64  `json:"lockID"`      // Optional, the postgres advisory lock key, replicas sharing a database must use the same key.
+	IntervalSec uint64 `json:"intervalSec"` // Optional, defaults to 5 seconds.
+}
+
+// ClaimReconciliationConfig is the configuration of the job checking on L1 whether long claimable withdrawals were
+// executed, e.g. claimed through a third-party UI while the fetcher missed the relay event.
+type ClaimReconciliationConfig struct {
+	Enabled            bool   `json:"enabled"`
+	IntervalSec        uint64 `json:"intervalSec"`        // Optional, defaults to 10 minutes.
+	MinClaimableAgeSec uint64 `json:"minClaimableAgeSec"` // Optional, withdrawals claimable for less than this are not checked, defaults to 1 hour.
+	BatchSize          int    `json:"batchSize"`          // Optional, max number of withdrawals checked per run, defaults to 100.
+}
+
+// ConsistencyCheckConfig is the configuration of the invariant checks of the indexed messages run on fetcher startup.
+type ConsistencyCheckConfig struct {
+	Enabled         bool   `json:"enabled"`
+	Repair          bool   `json:"repair"`          // repairs the violations which can be repaired in place before reporting.
+	FailOnViolation bool   `json:"failOnViolation"` // exits instead of starting the fetchers if violations remain.
+	GracePeriodSec  uint64 `json:"gracePeriodSec"`  // Optional, relayed messages updated more recently are not checked, defaults to 1 hour.
+}
+
+// ETAConfig is the configuration of the completion time estimation of pending messages in API responses.
+// Estimations are based on the median latencies of the latest relayed deposits and finalized withdrawals.
+type ETAConfig struct {
+	Enabled     bool   `json:"enabled"`
+	IntervalSec uint64 `json:"intervalSec"` // Optional, interval of refreshing the latency statistics, defaults to 1 minute.
+	SampleSize  int    `json:"sampleSize"`  // Optional, number of latest messages the latency statistics are computed over, defaults to 100.
+}
+
+// LatencyConfig is the configuration of the latency statistics of each stage of the messages, from their tx to their
+// relay or claim, exposed as histograms and served by the stats api for the monitoring of the bridge.
+type LatencyConfig struct {
+	Enabled     bool   `json:"enabled"`
+	IntervalSec uint64 `json:"intervalSec"` // Optional, interval of refreshing the statistics, defaults to 1 minute.
+	WindowSec   uint64 `json:"windowSec"`   // Optional, the statistics are over the messages which reached a stage within this window, defaults to 1 day.
+}
+
+// StatsConfig is the configuration of the daily bridge statistics served to public dashboards. The fetcher aggregates
+// the indexed messages into daily stats tables, which the API serves.
+type StatsConfig struct {
+	Enabled       bool   `json:"enabled"`
+	IntervalSec   uint64 `json:"intervalSec"`   // Optional, interval of the aggregation, defaults to 5 minutes.
+	RecomputeDays uint64 `json:"recomputeDays"` // Optional, number of days up to the fetcher progress recomputed by each aggregation, defaults to 2.
+	MaxWindowDays uint64 `json:"maxWindowDays"` // Optional, the longest window the API aggregates over, defaults to 90 days.
+}
+
+// PendingDepositsConfig is the configuration of the watcher of the txpool of a trusted L1 node, which records the
+// deposits not mined yet so that UIs show them right away. The node must serve the txpool namespace.
+type PendingDepositsConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Endpoint    string `json:"endpoint"`    // Optional, defaults to the L1 endpoint.
+	IntervalSec uint64 `json:"intervalSec"` // Optional, interval of reading the txpool, defaults to 5 seconds.
+	// Optional, a pending deposit which left the txpool without being indexed is removed this long after it was last
+	// seen, defaults to 1 hour. It should cover the confirmations of the L1 fetcher.
+	ExpireSec uint64 `json:"expireSec"`
+}
+
+// AutoClaimConfig is the configuration of the claimer sending relayMessageWithProof on L1 from its own account for the
+// finalized withdrawals matching its criteria, e.g. to offer gasless claims to the users of a product.
+type AutoClaimConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Endpoint   string `json:"endpoint"`   // Optional, defaults to the L1 endpoint.
+	PrivateKey string `json:"privateKey"` // The key of the account paying the claims, optional in dry-run mode.
+	DryRun     bool   `json:"dryRun"`     // Logs the claims with their estimated gas instead of sending them.
+	// Optional, only the withdrawals sent by or to these addresses are claimed, all withdrawals if empty.
+	AddressAllowlist []string `json:"addressAllowlist"`
+	MinValue         string   `json:"minValue"`    // Optional, the min ETH value in wei of claimed withdrawals, token withdrawals carry none.
+	MaxGasPrice      string   `json:"maxGasPrice"` // Optional, in wei, no claims are sent while the base fee plus tip exceeds it.
+	IntervalSec      uint64   `json:"intervalSec"` // Optional, defaults to 1 minute.
+	BatchSize        int      `json:"batchSize"`   // Optional, max number of withdrawals checked per run, defaults to 100.
+	// Optional, a claim still claimable this long after it was sent, e.g. dropped from the txpool, is sent again,
+	// defaults to 30 minutes.
+	RetryIntervalSec uint64 `json:"retryIntervalSec"`
+}
+
+// Address masking modes of the privacy mode.
+const (
+	PrivacyAddressHash     = "hash"
+	PrivacyAddressTruncate = "truncate"
+)
+
+// PrivacyConfig is the configuration of the masking of user data in API responses, for private deployments with data
+// exposure constraints. The indexed data is not masked, queries by address keep working.
+type PrivacyConfig struct {
+	Enabled     bool   `json:"enabled"`
+	AddressMode string `json:"addressMode"` // Optional, "hash" or "truncate", defaults to "hash".
+	// Optional, key of the hashed addresses, so that they cannot be matched by hashing known addresses. The key is
+	// read from the PRIVACY_HASH_KEY environment variable if empty.
+	HashKey string `json:"hashKey"`
+}
+
 // Config is the configuration of the bridge history backend
 type Config struct {
-	L1    *FetcherConfig   `json:"L1"`
-	L2    *FetcherConfig   `json:"L2"`
-	DB    *database.Config `json:"db"`
-	Redis *RedisConfig     `json:"redis"`
+	L1     *FetcherConfig   `json:"L1"`
+	L2     *FetcherConfig   `json:"L2"`
+	DB     *database.Config `json:"db"`
+	Redis  *RedisConfig     `json:"redis"`
+	ENS    *ENSConfig       `json:"ens,omitempty"`
+	Server *ServerConfig    `json:"server,omitempty"`
+	ETA    *ETAConfig       `json:"eta,omitempty"`
+
+	Privacy *PrivacyConfig `json:"privacy,omitempty"`
+
+	LeaderElection      *LeaderElectionConfig      `json:"leaderElection,omitempty"`
+	ClaimReconciliation *ClaimReconciliationConfig `json:"claimReconciliation,omitempty"`
+	ConsistencyCheck    *ConsistencyCheckConfig    `json:"consistencyCheck,omitempty"`
+	Stats               *StatsConfig               `json:"stats,omitempty"`
+	Latency             *LatencyConfig             `json:"latency,omitempty"`
+	PendingDeposits     *PendingDepositsConfig     `json:"pendingDeposits,omitempty"`
+	AutoClaim           *AutoClaimConfig           `json:"autoClaim,omitempty"`
 }
 
 // NewConfig returns a new instance of Config.
@@ -55,6 +201,10 @@ func NewConfig(file string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	buf, err = secrets.ResolveJSON(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{}
 	err = json.Unmarshal(buf, cfg)
@@ -64,3 +214,72 @@ func NewConfig(file string) (*Config, error) {
 
 	return cfg, nil
 }
+
+// ApplyNetwork fills the contract addresses and start height left empty in the config from the network profile.
+func (c *Config) ApplyNetwork(network *chains.Network) {
+	if network == nil {
+		return
+	}
+	if c.L1 != nil {
+		l1 := network.L1Contracts
+		if c.L1.ChainID == 0 {
+			c.L1.ChainID = network.L1ChainID
+		}
+		if c.L1.StartHeight == 0 {
+			c.L1.StartHeight = network.L1StartHeight
+		}
+		c.L1.MessengerAddr = chains.AddressOr(c.L1.MessengerAddr, l1.Messenger)
+		c.L1.GatewayRouterAddr = chains.AddressOr(c.L1.GatewayRouterAddr, l1.GatewayRouter)
+		c.L1.ETHGatewayAddr = chains.AddressOr(c.L1.ETHGatewayAddr, l1.ETHGateway)
+		c.L1.WETHGatewayAddr = chains.AddressOr(c.L1.WETHGatewayAddr, l1.WETHGateway)
+		c.L1.StandardERC20GatewayAddr = chains.AddressOr(c.L1.StandardERC20GatewayAddr, l1.StandardERC20Gateway)
+		c.L1.CustomERC20GatewayAddr = chains.AddressOr(c.L1.CustomERC20GatewayAddr, l1.CustomERC20Gateway)
+		c.L1.ERC721GatewayAddr = chains.AddressOr(c.L1.ERC721GatewayAddr, l1.ERC721Gateway)
+		c.L1.ERC1155GatewayAddr = chains.AddressOr(c.L1.ERC1155GatewayAddr, l1.ERC1155Gateway)
+		c.L1.USDCGatewayAddr = chains.AddressOr(c.L1.USDCGatewayAddr, l1.USDCGateway)
+		c.L1.LIDOGatewayAddr = chains.AddressOr(c.L1.LIDOGatewayAddr, l1.LIDOGateway)
+		c.L1.DAIGatewayAddr = chains.AddressOr(c.L1.DAIGatewayAddr, l1.DAIGateway)
+		c.L1.ScrollChainAddr = chains.AddressOr(c.L1.ScrollChainAddr, l1.ScrollChain)
+		c.L1.MessageQueueAddr = chains.AddressOr(c.L1.MessageQueueAddr, l1.MessageQueue)
+	}
+	if c.L2 != nil {
+		l2 := network.L2Contracts
+		if c.L2.ChainID == 0 {
+			c.L2.ChainID = network.L2ChainID
+		}
+		c.L2.MessengerAddr = chains.AddressOr(c.L2.MessengerAddr, l2.Messenger)
+		c.L2.GatewayRouterAddr = chains.AddressOr(c.L2.GatewayRouterAddr, l2.GatewayRouter)
+		c.L2.ETHGatewayAddr = chains.AddressOr(c.L2.ETHGatewayAddr, l2.ETHGateway)
+		c.L2.WETHGatewayAddr = chains.AddressOr(c.L2.WETHGatewayAddr, l2.WETHGateway)
+		c.L2.StandardERC20GatewayAddr = chains.AddressOr(c.L2.StandardERC20GatewayAddr, l2.StandardERC20Gateway)
+		c.L2.CustomERC20GatewayAddr = chains.AddressOr(c.L2.CustomERC20GatewayAddr, l2.CustomERC20Gateway)
+		c.L2.ERC721GatewayAddr = chains.AddressOr(c.L2.ERC721GatewayAddr, l2.ERC721Gateway)
+		c.L2.ERC1155GatewayAddr = chains.AddressOr(c.L2.ERC1155GatewayAddr, l2.ERC1155Gateway)
+		c.L2.USDCGatewayAddr = chains.AddressOr(c.L2.USDCGatewayAddr, l2.USDCGateway)
+		c.L2.LIDOGatewayAddr = chains.AddressOr(c.L2.LIDOGatewayAddr, l2.LIDOGateway)
+		c.L2.DAIGatewayAddr = chains.AddressOr(c.L2.DAIGatewayAddr, l2.DAIGateway)
+		c.L2.FeeVaultAddr = chains.AddressOr(c.L2.FeeVaultAddr, l2.TxFeeVault)
+	}
+}
+
+// Gateways returns the names of the configured gateways by address, the addresses are EIP-55 checksummed as the
+// message senders indexed in the database.
+func (c *FetcherConfig) Gateways() map[string]string {
+	gateways := make(map[string]string)
+	for name, addr := range map[string]string{
+		"ETHGateway":           c.ETHGatewayAddr,
+		"StandardERC20Gateway": c.StandardERC20GatewayAddr,
+		"CustomERC20Gateway":   c.CustomERC20GatewayAddr,
+		"WETHGateway":          c.WETHGatewayAddr,
+		"DAIGateway":           c.DAIGatewayAddr,
+		"USDCGateway":          c.USDCGatewayAddr,
+		"LIDOGateway":          c.LIDOGatewayAddr,
+		"ERC721Gateway":        c.ERC721GatewayAddr,
+		"ERC1155Gateway":       c.ERC1155GatewayAddr,
+	} {
+		if addr != "" {
+			gateways[common.HexToAddress(addr).String()] = name
+		}
+	}
+	return gateways
+}
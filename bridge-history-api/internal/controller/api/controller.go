@@ -1,22 +1,71 @@
 package api
 
 import (
+	"context"
+	"os"
 	"sync"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
+
+	"scroll-tech/common/metrics"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/logic"
 )
 
+// privacyHashKeyEnv is the environment variable of the key of the hashed addresses, if not in the config.
+const privacyHashKeyEnv = "PRIVACY_HASH_KEY"
+
 var (
 	// HistoryCtrler is controller instance
 	HistoryCtrler *HistoryController
+	// HistoryCtrlerV2 is the controller instance of the v2 apis
+	HistoryCtrlerV2 *HistoryControllerV2
+	// StatsCtrler is the controller instance of the bridge statistics apis
+	StatsCtrler *StatsController
 
 	initControllerOnce sync.Once
 )
 
-// InitController inits Controller with database
-func InitController(db *gorm.DB, redis *redis.Client) {
+// InitController inits Controller with database, the background services of the controller stop when ctx is done.
+func InitController(ctx context.Context, cfg *config.Config, db *gorm.DB, redis *redis.Client) {
 	initControllerOnce.Do(func() {
-		HistoryCtrler = NewHistoryController(db, redis)
+		var privacyLogic *logic.PrivacyLogic
+		if cfg.Privacy != nil && cfg.Privacy.Enabled {
+			hashKey := cfg.Privacy.HashKey
+			if hashKey == "" {
+				hashKey = os.Getenv(privacyHashKeyEnv)
+			}
+			if hashKey == "" && cfg.Privacy.AddressMode != config.PrivacyAddressTruncate {
+				log.Warn("privacy mode without a hash key, the hashed addresses can be matched by hashing known addresses")
+			}
+			privacyLogic = logic.NewPrivacyLogic(cfg.Privacy, hashKey)
+		}
+		var ensLogic *logic.ENSLogic
+		if cfg.ENS != nil && cfg.ENS.Enabled && privacyLogic != nil {
+			log.Warn("ENS resolution is disabled in privacy mode, the names would identify the masked addresses")
+		} else if cfg.ENS != nil && cfg.ENS.Enabled {
+			l1Client, err := ethclient.Dial(cfg.L1.Endpoint)
+			if err != nil {
+				log.Crit("failed to connect to L1 geth for ENS resolution", "endpoint", cfg.L1.Endpoint, "err", err)
+			}
+			ensLogic = logic.NewENSLogic(cfg.ENS, l1Client, redis)
+		}
+		var etaLogic *logic.ETALogic
+		if cfg.ETA != nil && cfg.ETA.Enabled {
+			etaLogic = logic.NewETALogic(cfg.ETA, db)
+			etaLogic.Start(ctx)
+		}
+		var latencyLogic *logic.LatencyLogic
+		if cfg.Latency != nil && cfg.Latency.Enabled {
+			latencyLogic = logic.NewLatencyLogic(cfg.Latency, db, metrics.Registerer())
+			latencyLogic.Start(ctx)
+		}
+		HistoryCtrler = NewHistoryController(db, redis, ensLogic, etaLogic, privacyLogic)
+		HistoryCtrlerV2 = NewHistoryControllerV2(HistoryCtrler)
+		StatsCtrler = NewStatsController(logic.NewStatsLogic(cfg, db, redis), latencyLogic)
 	})
 }
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// checkETag returns the ETag of the response to a query of the txs of an address, given the version of its txs, and
+// renders 304 Not Modified if the If-None-Match header of the request matches it.
+func (c *HistoryController) checkETag(ctx *gin.Context, version string) (string, bool) {
+	etag := c.addressETag(ctx, version)
+	if !etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		return etag, false
+	}
+	setETag(ctx, etag)
+	ctx.Status(http.StatusNotModified)
+	return etag, true
+}
+
+// addressETag returns the ETag of the response to a query of the txs of an address, which changes with the version of
+// its txs, the path and query of the request and the latency statistics of the ETAs. It is weak, so that the gzip
+// encoded and the plain responses share it.
+func (c *HistoryController) addressETag(ctx *gin.Context, version string) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Request.URL.Path + "\n" + ctx.Request.URL.Query().Encode() + "\n" + version))
+	if c.etaLogic != nil {
+		h.Write([]byte("\n" + c.etaLogic.Version()))
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// renderSuccessWithETag renders the data of a successful query with its ETag, errors are rendered without one so that
+// clients do not revalidate them.
+func renderSuccessWithETag(ctx *gin.Context, etag string, data interface{}) {
+	setETag(ctx, etag)
+	types.RenderSuccess(ctx, data)
+}
+
+// setETag sets the ETag of the response, clients and proxies may store it but must revalidate it before each use.
+func setETag(ctx *gin.Context, etag string) {
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "no-cache")
+}
+
+// etagMatches returns whether an If-None-Match header matches the ETag, with the weak comparison of RFC 9110.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (candidate != "" && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
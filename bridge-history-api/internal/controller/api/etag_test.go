@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+func TestETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := NewHistoryControllerV2(&HistoryController{})
+	version := "1"
+	getVersion := func(_ context.Context, _ string) (string, error) {
+		return version, nil
+	}
+	var queried int
+	getTxs := func(_ context.Context, _, _ string, _, _ uint64) ([]*types.TxHistoryInfo, uint64, error) {
+		queried++
+		return newTestTxs(1), 1, nil
+	}
+	get := func(pageSize, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/api/v2/txs?address=0x0000000000000000000000000000000000000001&page_size="+pageSize, nil)
+		if ifNoneMatch != "" {
+			ctx.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		c.renderTxsPage(ctx, getVersion, getTxs, types.ErrGetTxsError)
+		ctx.Writer.WriteHeaderNow()
+		return w
+	}
+
+	w := get("2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, 1, queried)
+
+	// the client has the txs already, they are not queried.
+	w = get("2", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, 1, queried)
+
+	// another query of the same txs.
+	w = get("1", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// the txs changed.
+	version = "2"
+	w = get("2", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`W/"01"`, `W/"01"`))
+	assert.True(t, etagMatches(`"01"`, `W/"01"`))
+	assert.True(t, etagMatches(`"00", W/"01"`, `W/"01"`))
+	assert.True(t, etagMatches(`*`, `W/"01"`))
+	assert.False(t, etagMatches(``, `W/"01"`))
+	assert.False(t, etagMatches(`W/"00"`, `W/"01"`))
+}
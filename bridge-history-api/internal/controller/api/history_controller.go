@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
@@ -12,12 +14,19 @@ import (
 // HistoryController contains the query claimable txs service
 type HistoryController struct {
 	historyLogic *logic.HistoryLogic
+	ensLogic     *logic.ENSLogic     // nil if ENS resolution is disabled
+	etaLogic     *logic.ETALogic     // nil if ETA estimation is disabled
+	privacyLogic *logic.PrivacyLogic // nil if the privacy mode is disabled
 }
 
 // NewHistoryController return HistoryController instance
-func NewHistoryController(db *gorm.DB, redis *redis.Client) *HistoryController {
+func NewHistoryController(db *gorm.DB, redis *redis.Client, ensLogic *logic.ENSLogic, etaLogic *logic.ETALogic,
+	privacyLogic *logic.PrivacyLogic) *HistoryController {
 	return &HistoryController{
 		historyLogic: logic.NewHistoryLogic(db, redis),
+		ensLogic:     ensLogic,
+		etaLogic:     etaLogic,
+		privacyLogic: privacyLogic,
 	}
 }
 
@@ -25,61 +34,163 @@ func NewHistoryController(db *gorm.DB, redis *redis.Client) *HistoryController {
 func (c *HistoryController) GetL2UnclaimedWithdrawalsByAddress(ctx *gin.Context) {
 	var req types.QueryByAddressRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	version, err := c.historyLogic.GetAddressVersion(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+	if version, err = c.expandVersion(ctx, req.Expand, version); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+	etag, notModified := c.checkETag(ctx, version)
+	if notModified {
 		return
 	}
 
-	pagedTxs, total, err := c.historyLogic.GetL2UnclaimedWithdrawalsByAddress(ctx, req.Address, req.Page, req.PageSize)
+	pagedTxs, total, err := c.historyLogic.GetL2UnclaimedWithdrawalsByAddress(ctx, req.Address, version, (req.Page-1)*req.PageSize, req.PageSize)
 	if err != nil {
 		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
 		return
 	}
+	if err = c.expandTxs(ctx, req.Expand, pagedTxs); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
 
+	c.fillENSNames(ctx, pagedTxs)
+	c.fillETAs(pagedTxs)
+	c.maskTxs(pagedTxs)
 	resultData := &types.ResultData{Results: pagedTxs, Total: total}
-	types.RenderSuccess(ctx, resultData)
+	renderSuccessWithETag(ctx, etag, resultData)
+}
+
+// GetL2ClaimableWithdrawalsByAddress defines the http get method behavior
+func (c *HistoryController) GetL2ClaimableWithdrawalsByAddress(ctx *gin.Context) {
+	var req types.QueryByAddressRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	version, err := c.historyLogic.GetAddressVersion(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+	if version, err = c.expandVersion(ctx, req.Expand, version); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+	etag, notModified := c.checkETag(ctx, version)
+	if notModified {
+		return
+	}
+
+	pagedTxs, total, err := c.historyLogic.GetL2ClaimableWithdrawalsByAddress(ctx, req.Address, version, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+	if err = c.expandTxs(ctx, req.Expand, pagedTxs); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2ClaimableWithdrawalsError, err)
+		return
+	}
+
+	c.fillENSNames(ctx, pagedTxs)
+	c.fillETAs(pagedTxs)
+	c.maskTxs(pagedTxs)
+	resultData := &types.ResultData{Results: pagedTxs, Total: total}
+	renderSuccessWithETag(ctx, etag, resultData)
 }
 
 // GetL2WithdrawalsByAddress defines the http get method behavior
 func (c *HistoryController) GetL2WithdrawalsByAddress(ctx *gin.Context) {
 	var req types.QueryByAddressRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	version, err := c.historyLogic.GetAddressVersion(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2WithdrawalsError, err)
+		return
+	}
+	if version, err = c.expandVersion(ctx, req.Expand, version); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2WithdrawalsError, err)
+		return
+	}
+	etag, notModified := c.checkETag(ctx, version)
+	if notModified {
 		return
 	}
 
-	pagedTxs, total, err := c.historyLogic.GetL2WithdrawalsByAddress(ctx, req.Address, req.Page, req.PageSize)
+	pagedTxs, total, err := c.historyLogic.GetL2WithdrawalsByAddress(ctx, req.Address, version, (req.Page-1)*req.PageSize, req.PageSize)
 	if err != nil {
 		types.RenderFailure(ctx, types.ErrGetL2WithdrawalsError, err)
 		return
 	}
+	if err = c.expandTxs(ctx, req.Expand, pagedTxs); err != nil {
+		types.RenderFailure(ctx, types.ErrGetL2WithdrawalsError, err)
+		return
+	}
 
+	c.fillENSNames(ctx, pagedTxs)
+	c.fillETAs(pagedTxs)
+	c.maskTxs(pagedTxs)
 	resultData := &types.ResultData{Results: pagedTxs, Total: total}
-	types.RenderSuccess(ctx, resultData)
+	renderSuccessWithETag(ctx, etag, resultData)
 }
 
 // GetTxsByAddress defines the http get method behavior
 func (c *HistoryController) GetTxsByAddress(ctx *gin.Context) {
 	var req types.QueryByAddressRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	version, err := c.historyLogic.GetAddressVersion(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetTxsError, err)
+		return
+	}
+	if version, err = c.expandVersion(ctx, req.Expand, version); err != nil {
+		types.RenderFailure(ctx, types.ErrGetTxsError, err)
+		return
+	}
+	etag, notModified := c.checkETag(ctx, version)
+	if notModified {
 		return
 	}
 
-	pagedTxs, total, err := c.historyLogic.GetTxsByAddress(ctx, req.Address, req.Page, req.PageSize)
+	pagedTxs, total, err := c.historyLogic.GetTxsByAddress(ctx, req.Address, version, (req.Page-1)*req.PageSize, req.PageSize)
 	if err != nil {
 		types.RenderFailure(ctx, types.ErrGetTxsError, err)
 		return
 	}
+	if err = c.expandTxs(ctx, req.Expand, pagedTxs); err != nil {
+		types.RenderFailure(ctx, types.ErrGetTxsError, err)
+		return
+	}
 
+	c.fillENSNames(ctx, pagedTxs)
+	c.fillETAs(pagedTxs)
+	c.maskTxs(pagedTxs)
 	resultData := &types.ResultData{Results: pagedTxs, Total: total}
-	types.RenderSuccess(ctx, resultData)
+	renderSuccessWithETag(ctx, etag, resultData)
 }
 
 // PostQueryTxsByHashes defines the http post method behavior
 func (c *HistoryController) PostQueryTxsByHashes(ctx *gin.Context) {
 	var req types.QueryByHashRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		types.RenderParameterFailure(ctx, err)
 		return
 	}
 
@@ -89,6 +200,156 @@ func (c *HistoryController) PostQueryTxsByHashes(ctx *gin.Context) {
 		return
 	}
 
+	c.fillENSNames(ctx, results)
+	c.fillETAs(results)
+	c.maskTxs(results)
+	resultData := &types.ResultData{Results: results, Total: uint64(len(results))}
+	types.RenderSuccess(ctx, resultData)
+}
+
+// PostQueryTxsByAddresses defines the http post method behavior
+func (c *HistoryController) PostQueryTxsByAddresses(ctx *gin.Context) {
+	var req types.QueryByAddressesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	results, err := c.historyLogic.GetTxsByAddresses(ctx, req.Addresses, req.Limit)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetTxsByAddressesError, err)
+		return
+	}
+
+	// the names of all addresses are resolved at once, sharing the lookup budget of the request.
+	var txs []*types.TxHistoryInfo
+	for _, result := range results {
+		txs = append(txs, result.Results...)
+	}
+	c.fillENSNames(ctx, txs)
+	c.fillETAs(txs)
+	c.maskTxs(txs)
+	types.RenderSuccess(ctx, &types.ResultsByAddressData{Results: results})
+}
+
+// GetL1QueuePosition defines the http get method behavior
+func (c *HistoryController) GetL1QueuePosition(ctx *gin.Context) {
+	var req types.QueryByQueueIndexRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	position, err := c.historyLogic.GetL1QueuePosition(ctx, *req.QueueIndex)
+	if errors.Is(err, logic.ErrL1QueueIndexNotFound) {
+		types.RenderFailure(ctx, types.ErrL1QueueIndexNotFound, err)
+		return
+	}
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetL1QueuePositionError, err)
+		return
+	}
+	types.RenderSuccess(ctx, position)
+}
+
+// GetTxsByTokenAmountRange defines the http get method behavior
+func (c *HistoryController) GetTxsByTokenAmountRange(ctx *gin.Context) {
+	var req types.QueryByTokenAmountRangeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	results, err := c.historyLogic.GetTxsByTokenAmountRange(ctx, req.Address, req.Token, req.MinAmount, req.MaxAmount)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetTxsByTokenAmountError, err)
+		return
+	}
+
+	c.fillENSNames(ctx, results)
+	c.fillETAs(results)
+	c.maskTxs(results)
+	resultData := &types.ResultData{Results: results, Total: uint64(len(results))}
+	types.RenderSuccess(ctx, resultData)
+}
+
+// GetTokenTotalsByAddress defines the http get method behavior
+func (c *HistoryController) GetTokenTotalsByAddress(ctx *gin.Context) {
+	var req types.QueryTokenTotalsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	totals, err := c.historyLogic.GetTokenTotalsByAddress(ctx, req.Address, req.Token)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetTokenTotalsError, err)
+		return
+	}
+	types.RenderSuccess(ctx, totals)
+}
+
+// GetPendingDepositsByAddress defines the http get method behavior
+func (c *HistoryController) GetPendingDepositsByAddress(ctx *gin.Context) {
+	var req types.QueryPendingDepositsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	results, err := c.historyLogic.GetPendingDepositsByAddress(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetPendingDepositsError, err)
+		return
+	}
+
+	c.fillENSNames(ctx, results)
+	c.maskTxs(results)
 	resultData := &types.ResultData{Results: results, Total: uint64(len(results))}
 	types.RenderSuccess(ctx, resultData)
 }
+
+// expandVersion adds the version of the batches to the version of the txs of an address if the response includes
+// them, for its ETag.
+func (c *HistoryController) expandVersion(ctx *gin.Context, expand, version string) (string, error) {
+	if expand != types.ExpandBatch {
+		return version, nil
+	}
+	batchVersion, err := c.historyLogic.GetBatchVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return version + "-" + batchVersion, nil
+}
+
+// expandTxs includes the batches of the txs in the response if requested, after caching like the ETAs.
+func (c *HistoryController) expandTxs(ctx *gin.Context, expand string, txs []*types.TxHistoryInfo) error {
+	if expand != types.ExpandBatch {
+		return nil
+	}
+	return c.historyLogic.FillBatches(ctx, txs)
+}
+
+func (c *HistoryController) fillENSNames(ctx *gin.Context, txs []*types.TxHistoryInfo) {
+	if c.ensLogic == nil {
+		return
+	}
+	// the request context stops the lookups once the client went away, gin.Context itself is never done.
+	c.ensLogic.FillENSNames(ctx.Request.Context(), txs)
+}
+
+// fillETAs is applied to the responses after caching, so estimations of cached txs stay current.
+func (c *HistoryController) fillETAs(txs []*types.TxHistoryInfo) {
+	if c.etaLogic == nil {
+		return
+	}
+	c.etaLogic.FillETAs(txs)
+}
+
+// maskTxs is applied last to the responses, after the fields it masks are filled.
+func (c *HistoryController) maskTxs(txs []*types.TxHistoryInfo) {
+	if c.privacyLogic == nil {
+		return
+	}
+	c.privacyLogic.MaskTxs(txs)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// errCursorStale is returned when the txs before the cursor changed since the previous page was served.
+var errCursorStale = errors.New("txs changed since the previous page, restart from the first page")
+
+// pageCursor is the position of the next page in the sorted txs of a query, together with the message hash of
+// the last tx of the previous page, which detects txs inserted before the position since the cursor was issued.
+type pageCursor struct {
+	Offset      uint64 `json:"o"`
+	MessageHash string `json:"h"`
+}
+
+func encodeCursor(cursor *pageCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes the cursor of a page, the empty cursor is the one of the first page.
+func decodeCursor(cursor string) (*pageCursor, error) {
+	if cursor == "" {
+		return &pageCursor{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c pageCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.Offset > 0 && c.MessageHash == "" {
+		return nil, errors.New("invalid cursor: missing message hash")
+	}
+	return &c, nil
+}
+
+// serializeTxsV2 serializes txs into the schema of the v2 apis.
+func serializeTxsV2(txs []*types.TxHistoryInfo) []*types.TxHistoryInfoV2 {
+	results := make([]*types.TxHistoryInfoV2, 0, len(txs))
+	for _, tx := range txs {
+		results = append(results, &types.TxHistoryInfoV2{TxHistoryInfo: tx, Claimable: isClaimable(tx)})
+	}
+	return results
+}
+
+// isClaimable returns whether the tx is a finalized L2 withdrawal which can still be claimed on L1.
+func isClaimable(tx *types.TxHistoryInfo) bool {
+	return tx.MessageType == orm.MessageTypeL2SentMessage && tx.ClaimInfo != nil && orm.IsClaimableTxStatus(tx.TxStatus)
+}
+
+// addressVersionGetter gets the version of the txs of an address, which changes whenever they change.
+type addressVersionGetter func(ctx context.Context, address string) (string, error)
+
+// pagedTxsGetter gets up to limit txs of an address starting at offset, and the total number of txs.
+type pagedTxsGetter func(ctx context.Context, address, version string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error)
+
+// HistoryControllerV2 serves the v2 apis. It shares the logic of the v1 apis,
+// only paginating by cursor and serializing the responses into the v2 schema.
+type HistoryControllerV2 struct {
+	v1 *HistoryController
+}
+
+// NewHistoryControllerV2 return HistoryControllerV2 instance
+func NewHistoryControllerV2(v1 *HistoryController) *HistoryControllerV2 {
+	return &HistoryControllerV2{v1: v1}
+}
+
+// GetTxsByAddress defines the http get method behavior
+func (c *HistoryControllerV2) GetTxsByAddress(ctx *gin.Context) {
+	c.renderTxsPage(ctx, c.v1.historyLogic.GetAddressVersion, c.v1.historyLogic.GetTxsByAddress, types.ErrGetTxsError)
+}
+
+// GetL2WithdrawalsByAddress defines the http get method behavior
+func (c *HistoryControllerV2) GetL2WithdrawalsByAddress(ctx *gin.Context) {
+	c.renderTxsPage(ctx, c.v1.historyLogic.GetAddressVersion, c.v1.historyLogic.GetL2WithdrawalsByAddress, types.ErrGetL2WithdrawalsError)
+}
+
+// GetL2UnclaimedWithdrawalsByAddress defines the http get method behavior
+func (c *HistoryControllerV2) GetL2UnclaimedWithdrawalsByAddress(ctx *gin.Context) {
+	c.renderTxsPage(ctx, c.v1.historyLogic.GetAddressVersion, c.v1.historyLogic.GetL2UnclaimedWithdrawalsByAddress, types.ErrGetL2ClaimableWithdrawalsError)
+}
+
+// GetL2ClaimableWithdrawalsByAddress defines the http get method behavior
+func (c *HistoryControllerV2) GetL2ClaimableWithdrawalsByAddress(ctx *gin.Context) {
+	c.renderTxsPage(ctx, c.v1.historyLogic.GetAddressVersion, c.v1.historyLogic.GetL2ClaimableWithdrawalsByAddress, types.ErrGetL2ClaimableWithdrawalsError)
+}
+
+func (c *HistoryControllerV2) renderTxsPage(ctx *gin.Context, getVersion addressVersionGetter, getTxs pagedTxsGetter, errCode int) {
+	var req types.QueryByAddressCursorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+	cursor, err := decodeCursor(req.Cursor)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrCursorInvalid, err)
+		return
+	}
+
+	version, err := getVersion(ctx, req.Address)
+	if err != nil {
+		types.RenderFailure(ctx, errCode, err)
+		return
+	}
+	if version, err = c.v1.expandVersion(ctx, req.Expand, version); err != nil {
+		types.RenderFailure(ctx, errCode, err)
+		return
+	}
+	etag, notModified := c.v1.checkETag(ctx, version)
+	if notModified {
+		return
+	}
+
+	// after the first page, the last tx of the previous page is read again to check that it did not move.
+	offset, limit := cursor.Offset, req.PageSize
+	if cursor.Offset > 0 {
+		offset, limit = offset-1, limit+1
+	}
+	txs, total, err := getTxs(ctx, req.Address, version, offset, limit)
+	if err != nil {
+		types.RenderFailure(ctx, errCode, err)
+		return
+	}
+	if cursor.Offset > 0 {
+		if len(txs) == 0 || txs[0].MessageHash != cursor.MessageHash {
+			types.RenderFailure(ctx, types.ErrCursorInvalid, errCursorStale)
+			return
+		}
+		txs = txs[1:]
+	}
+	if err = c.v1.expandTxs(ctx, req.Expand, txs); err != nil {
+		types.RenderFailure(ctx, errCode, err)
+		return
+	}
+
+	c.v1.fillENSNames(ctx, txs)
+	c.v1.fillETAs(txs)
+	c.v1.maskTxs(txs)
+	resultData := &types.CursorResultData{Results: serializeTxsV2(txs), Total: total}
+	if next := cursor.Offset + uint64(len(txs)); len(txs) > 0 && next < total {
+		resultData.NextCursor, err = encodeCursor(&pageCursor{Offset: next, MessageHash: txs[len(txs)-1].MessageHash})
+		if err != nil {
+			types.RenderFailure(ctx, errCode, err)
+			return
+		}
+	}
+	renderSuccessWithETag(ctx, etag, resultData)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+type cursorResponse struct {
+	ErrCode int                     `json:"errcode"`
+	Data    *types.CursorResultData `json:"data"`
+}
+
+func newTestTxs(count int) []*types.TxHistoryInfo {
+	var txs []*types.TxHistoryInfo
+	for i := 0; i < count; i++ {
+		txs = append(txs, &types.TxHistoryInfo{MessageHash: fmt.Sprintf("0x%02d", i)})
+	}
+	return txs
+}
+
+func getVersion(_ context.Context, _ string) (string, error) {
+	return "1", nil
+}
+
+func getTxsPage(t *testing.T, c *HistoryControllerV2, getTxs pagedTxsGetter, cursor string) *cursorResponse {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/api/v2/txs?address=0x0000000000000000000000000000000000000001&page_size=2&cursor="+cursor, nil)
+	c.renderTxsPage(ctx, getVersion, getTxs, types.ErrGetTxsError)
+
+	var resp cursorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return &resp
+}
+
+func TestCursorPagination(t *testing.T) {
+	txs := newTestTxs(5)
+	getTxs := func(_ context.Context, _, _ string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+		end := offset + limit
+		if end > uint64(len(txs)) {
+			end = uint64(len(txs))
+		}
+		return txs[offset:end], uint64(len(txs)), nil
+	}
+	c := NewHistoryControllerV2(&HistoryController{})
+
+	var hashes []string
+	var cursor string
+	for {
+		resp := getTxsPage(t, c, getTxs, cursor)
+		assert.Equal(t, types.Success, resp.ErrCode)
+		assert.Equal(t, uint64(5), resp.Data.Total)
+		for _, tx := range resp.Data.Results {
+			hashes = append(hashes, tx.MessageHash)
+		}
+		if resp.Data.NextCursor == "" {
+			break
+		}
+		cursor = resp.Data.NextCursor
+	}
+	assert.Equal(t, []string{"0x00", "0x01", "0x02", "0x03", "0x04"}, hashes)
+
+	// a tx inserted before the cursor position invalidates the cursor instead of repeating a tx.
+	resp := getTxsPage(t, c, getTxs, "")
+	txs = append([]*types.TxHistoryInfo{{MessageHash: "0x05"}}, txs...)
+	resp = getTxsPage(t, c, getTxs, resp.Data.NextCursor)
+	assert.Equal(t, types.ErrCursorInvalid, resp.ErrCode)
+
+	resp = getTxsPage(t, c, getTxs, "not-a-cursor")
+	assert.Equal(t, types.ErrCursorInvalid, resp.ErrCode)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	cursor, err := decodeCursor("")
+	assert.NoError(t, err)
+	assert.Equal(t, &pageCursor{}, cursor)
+
+	encoded, err := encodeCursor(&pageCursor{Offset: 10, MessageHash: "0x01"})
+	assert.NoError(t, err)
+	cursor, err = decodeCursor(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, &pageCursor{Offset: 10, MessageHash: "0x01"}, cursor)
+
+	encoded, err = encodeCursor(&pageCursor{Offset: 10})
+	assert.NoError(t, err)
+	_, err = decodeCursor(encoded)
+	assert.Error(t, err)
+}
+
+func TestSerializeTxsV2(t *testing.T) {
+	txs := []*types.TxHistoryInfo{
+		{MessageType: orm.MessageTypeL2SentMessage, TxStatus: orm.TxStatusTypeSent, ClaimInfo: &types.ClaimInfo{Claimable: true}},
+		{MessageType: orm.MessageTypeL2SentMessage, TxStatus: orm.TxStatusTypeRelayed, ClaimInfo: &types.ClaimInfo{Claimable: true}},
+		{MessageType: orm.MessageTypeL2SentMessage, TxStatus: orm.TxStatusTypeSent},
+		{MessageType: orm.MessageTypeL1SentMessage, TxStatus: orm.TxStatusTypeSent},
+	}
+	results := serializeTxsV2(txs)
+	assert.Len(t, results, 4)
+	assert.True(t, results[0].Claimable)
+	assert.False(t, results[1].Claimable)
+	assert.False(t, results[2].Claimable)
+	assert.False(t, results[3].Claimable)
+
+	// the v1 fields are kept in the v2 schema.
+	data, err := json.Marshal(results[0])
+	assert.NoError(t, err)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, true, fields["claimable"])
+	assert.Contains(t, fields, "claim_info")
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// StatsController contains the bridge statistics service
+type StatsController struct {
+	statsLogic   *logic.StatsLogic
+	latencyLogic *logic.LatencyLogic // nil if the stage latencies are disabled
+}
+
+// NewStatsController returns StatsController instance
+func NewStatsController(statsLogic *logic.StatsLogic, latencyLogic *logic.LatencyLogic) *StatsController {
+	return &StatsController{statsLogic: statsLogic, latencyLogic: latencyLogic}
+}
+
+// GetTokenStats defines the http get method behavior
+func (c *StatsController) GetTokenStats(ctx *gin.Context) {
+	var req types.QueryTokenStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	data, err := c.statsLogic.GetTopTokens(ctx, &req)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetStatsError, err)
+		return
+	}
+	types.RenderSuccess(ctx, data)
+}
+
+// GetGatewayStats defines the http get method behavior
+func (c *StatsController) GetGatewayStats(ctx *gin.Context) {
+	var req types.QueryStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	data, err := c.statsLogic.GetGatewayStats(ctx, req.Days)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetStatsError, err)
+		return
+	}
+	types.RenderSuccess(ctx, data)
+}
+
+// GetBridgerStats defines the http get method behavior
+func (c *StatsController) GetBridgerStats(ctx *gin.Context) {
+	var req types.QueryStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	data, err := c.statsLogic.GetBridgerStats(ctx, req.Days)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetStatsError, err)
+		return
+	}
+	types.RenderSuccess(ctx, data)
+}
+
+// GetFeeVaultWithdrawals defines the http get method behavior
+func (c *StatsController) GetFeeVaultWithdrawals(ctx *gin.Context) {
+	var req types.QueryFeeVaultWithdrawalsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderParameterFailure(ctx, err)
+		return
+	}
+
+	data, err := c.statsLogic.GetFeeVaultWithdrawals(ctx, &req)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetStatsError, err)
+		return
+	}
+	types.RenderSuccess(ctx, data)
+}
+
+// GetStageLatency defines the http get method behavior
+func (c *StatsController) GetStageLatency(ctx *gin.Context) {
+	if c.latencyLogic == nil {
+		types.RenderFailure(ctx, types.ErrStageLatencyUnavailable, errors.New("the stage latencies are disabled"))
+		return
+	}
+	data := c.latencyLogic.GetStageLatencies()
+	if data == nil {
+		types.RenderFailure(ctx, types.ErrStageLatencyUnavailable, errors.New("the stage latencies are not computed yet"))
+		return
+	}
+	types.RenderSuccess(ctx, data)
+}
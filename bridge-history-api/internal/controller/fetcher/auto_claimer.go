@@ -0,0 +1,340 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/clock"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	defaultAutoClaimInterval      = time.Minute
+	defaultAutoClaimBatch         = 100
+	defaultAutoClaimRetryInterval = 30 * time.Minute
+)
+
+// Reasons of the withdrawals the AutoClaimer does not claim.
+const (
+	autoClaimSkipAllowlist = "allowlist"
+	autoClaimSkipMinValue  = "min_value"
+	autoClaimSkipStatus    = "status"
+	autoClaimSkipInFlight  = "in_flight"
+	autoClaimSkipGasPrice  = "gas_price"
+)
+
+// AutoClaimClient is the L1 client the AutoClaimer estimates and sends the claims through.
+type AutoClaimClient interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// AutoClaimer claims the finalized withdrawals matching the configured criteria on L1, sending relayMessageWithProof
+// from its own account. A withdrawal stays claimable until the L1 fetcher indexes its relay, the claims sent are
+// remembered meanwhile so that they are not sent twice, unless still claimable after the retry interval.
+type AutoClaimer struct {
+	ctx           context.Context
+	clock         clock.Clock
+	client        AutoClaimClient
+	messengerAddr common.Address
+	crossMessage  *orm.CrossMessage
+	leadership    LeadershipChecker // checked before sending claims
+
+	key    *ecdsa.PrivateKey // nil in dry-run mode without a key
+	from   common.Address
+	dryRun bool
+
+	allowlist   map[common.Address]struct{} // all addresses are allowed if empty
+	minValue    *big.Int
+	maxGasPrice *big.Int // nil if there is no ceiling
+
+	interval      time.Duration
+	retryInterval time.Duration
+	batchSize     int
+
+	// lastID is the id of the last checked withdrawal, the scan restarts from the beginning once all claimable
+	// withdrawals were checked.
+	lastID uint64
+	// sent holds the time the claims not indexed yet were sent, by message hash.
+	sent map[string]time.Time
+
+	autoClaimerRunningTotal prometheus.Counter
+	autoClaimerSentTotal    prometheus.Counter
+	autoClaimerDryRunTotal  prometheus.Counter
+	autoClaimerFailureTotal prometheus.Counter
+	autoClaimerSkippedTotal *prometheus.CounterVec
+}
+
+// NewAutoClaimer creates a new AutoClaimer instance, scheduled on clk.
+func NewAutoClaimer(ctx context.Context, cfg *config.AutoClaimConfig, messengerAddr string, db *gorm.DB, client AutoClaimClient, leadership LeadershipChecker, clk clock.Clock, reg prometheus.Registerer) (*AutoClaimer, error) {
+	c := &AutoClaimer{
+		ctx:           ctx,
+		clock:         clk,
+		client:        client,
+		messengerAddr: common.HexToAddress(messengerAddr),
+		crossMessage:  orm.NewCrossMessage(db),
+		leadership:    leadership,
+		dryRun:        cfg.DryRun,
+		allowlist:     make(map[common.Address]struct{}, len(cfg.AddressAllowlist)),
+		minValue:      new(big.Int),
+		interval:      defaultAutoClaimInterval,
+		retryInterval: defaultAutoClaimRetryInterval,
+		batchSize:     defaultAutoClaimBatch,
+		sent:          make(map[string]time.Time),
+	}
+
+	if cfg.PrivateKey != "" {
+		key, err := crypto.HexToECDSA(common.Bytes2Hex(common.FromHex(cfg.PrivateKey)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid auto claim private key, error: %w", err)
+		}
+		c.key, c.from = key, crypto.PubkeyToAddress(key.PublicKey)
+	} else if !cfg.DryRun {
+		return nil, errors.New("the auto claim private key is required unless in dry-run mode")
+	}
+	for _, addr := range cfg.AddressAllowlist {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid auto claim allowlist address %q", addr)
+		}
+		c.allowlist[common.HexToAddress(addr)] = struct{}{}
+	}
+	if cfg.MinValue != "" {
+		if _, ok := c.minValue.SetString(cfg.MinValue, 10); !ok {
+			return nil, fmt.Errorf("invalid auto claim min value %q", cfg.MinValue)
+		}
+	}
+	if cfg.MaxGasPrice != "" {
+		maxGasPrice, ok := new(big.Int).SetString(cfg.MaxGasPrice, 10)
+		if !ok || maxGasPrice.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid auto claim max gas price %q", cfg.MaxGasPrice)
+		}
+		c.maxGasPrice = maxGasPrice
+	}
+	if cfg.IntervalSec > 0 {
+		c.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.RetryIntervalSec > 0 {
+		c.retryInterval = time.Duration(cfg.RetryIntervalSec) * time.Second
+	}
+	if cfg.BatchSize > 0 {
+		c.batchSize = cfg.BatchSize
+	}
+
+	c.autoClaimerRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "auto_claimer_running_total",
+		Help: "Total count of auto claim runs.",
+	})
+	c.autoClaimerSentTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "auto_claimer_sent_total",
+		Help: "Total count of claim txs sent by the auto claimer.",
+	})
+	c.autoClaimerDryRunTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "auto_claimer_dry_run_total",
+		Help: "Total count of claims logged instead of sent in dry-run mode.",
+	})
+	c.autoClaimerFailureTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "auto_claimer_failure_total",
+		Help: "Total count of claims failed to be estimated or sent.",
+	})
+	c.autoClaimerSkippedTotal = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "auto_claimer_skipped_total",
+		Help: "Total count of claimable withdrawals not claimed, by reason: allowlist, min_value, status, in_flight or gas_price.",
+	}, []string{"reason"})
+
+	return c, nil
+}
+
+// Start starts the auto claim loop.
+func (c *AutoClaimer) Start() {
+	tick := c.clock.NewTicker(c.interval)
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C():
+				c.claim()
+			}
+		}
+	}()
+}
+
+func (c *AutoClaimer) claim() {
+	c.autoClaimerRunningTotal.Inc()
+
+	now := c.clock.Now().UTC()
+	for messageHash, sentAt := range c.sent {
+		if now.Sub(sentAt) >= c.retryInterval {
+			delete(c.sent, messageHash)
+		}
+	}
+
+	messages, err := c.crossMessage.GetClaimableL2WithdrawalsUpdatedBefore(c.ctx, now, c.lastID, c.batchSize)
+	if err != nil {
+		log.Error("failed to get claimable withdrawals", "err", err)
+		return
+	}
+	if len(messages) < c.batchSize {
+		c.lastID = 0
+	} else {
+		c.lastID = messages[len(messages)-1].ID
+	}
+
+	var candidates []*orm.CrossMessage
+	for _, message := range messages {
+		if reason := c.skipReason(message); reason != "" {
+			c.autoClaimerSkippedTotal.WithLabelValues(reason).Inc()
+			continue
+		}
+		candidates = append(candidates, message)
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	tip, feeCap, err := c.fees()
+	if err != nil {
+		log.Warn("failed to get the L1 gas price", "err", err)
+		return
+	}
+	if feeCap == nil {
+		c.autoClaimerSkippedTotal.WithLabelValues(autoClaimSkipGasPrice).Add(float64(len(candidates)))
+		log.Info("skip auto claims, the L1 gas price exceeds the ceiling", "max gas price", c.maxGasPrice, "count", len(candidates))
+		return
+	}
+	if err = c.crossMessage.LoadMessageData(c.ctx, candidates); err != nil {
+		log.Error("failed to load the message data of claimable withdrawals", "err", err)
+		return
+	}
+	if !c.dryRun {
+		if err = c.leadership.CheckLeadership(c.ctx); err != nil {
+			log.Error("skip auto claims, fetcher leadership check failed", "err", err)
+			return
+		}
+	}
+
+	for _, message := range candidates {
+		if err = c.claimWithdrawal(message, tip, feeCap); err != nil {
+			c.autoClaimerFailureTotal.Inc()
+			log.Warn("failed to auto claim withdrawal", "message hash", message.MessageHash, "err", err)
+		}
+	}
+}
+
+// skipReason returns why the withdrawal is not claimed, empty if it is.
+func (c *AutoClaimer) skipReason(message *orm.CrossMessage) string {
+	// failed relays are not retried automatically, they would fail again at the expense of the claimer.
+	if orm.TxStatusType(message.TxStatus) != orm.TxStatusTypeSent {
+		return autoClaimSkipStatus
+	}
+	if _, ok := c.sent[message.MessageHash]; ok {
+		return autoClaimSkipInFlight
+	}
+	if len(c.allowlist) > 0 {
+		_, senderAllowed := c.allowlist[common.HexToAddress(message.Sender)]
+		_, receiverAllowed := c.allowlist[common.HexToAddress(message.Receiver)]
+		if !senderAllowed && !receiverAllowed {
+			return autoClaimSkipAllowlist
+		}
+	}
+	if c.minValue.Sign() > 0 {
+		value, ok := new(big.Int).SetString(message.MessageValue, 10)
+		if !ok || value.Cmp(c.minValue) < 0 {
+			return autoClaimSkipMinValue
+		}
+	}
+	return ""
+}
+
+// fees returns the tip and the fee cap of the claims, the fee cap is nil if the gas price exceeds the ceiling.
+func (c *AutoClaimer) fees() (*big.Int, *big.Int, error) {
+	tip, err := c.client.SuggestGasTipCap(c.ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to suggest gas tip cap, error: %w", err)
+	}
+	head, err := c.client.HeaderByNumber(c.ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get head, error: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	if c.maxGasPrice == nil {
+		return tip, feeCap, nil
+	}
+	if new(big.Int).Add(head.BaseFee, tip).Cmp(c.maxGasPrice) > 0 {
+		return tip, nil, nil
+	}
+	if feeCap.Cmp(c.maxGasPrice) > 0 {
+		feeCap = new(big.Int).Set(c.maxGasPrice)
+	}
+	return tip, feeCap, nil
+}
+
+func (c *AutoClaimer) claimWithdrawal(message *orm.CrossMessage, tip, feeCap *big.Int) error {
+	data, err := logic.ClaimCalldata(message)
+	if err != nil {
+		return err
+	}
+	// the estimation fails if the withdrawal was claimed meanwhile, e.g. by its sender.
+	gas, err := c.client.EstimateGas(c.ctx, ethereum.CallMsg{From: c.from, To: &c.messengerAddr, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas, error: %w", err)
+	}
+	gas = gas * 6 / 5
+
+	if c.dryRun {
+		c.autoClaimerDryRunTotal.Inc()
+		c.sent[message.MessageHash] = c.clock.Now().UTC()
+		log.Info("auto claim dry run", "message hash", message.MessageHash, "sender", message.Sender, "value", message.MessageValue, "gas", gas, "fee cap", feeCap)
+		return nil
+	}
+
+	chainID, err := c.client.ChainID(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id, error: %w", err)
+	}
+	nonce, err := c.client.PendingNonceAt(c.ctx, c.from)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce, error: %w", err)
+	}
+	tx, err := types.SignNewTx(c.key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &c.messengerAddr,
+		Value:     big.NewInt(0),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign tx, error: %w", err)
+	}
+	if err = c.client.SendTransaction(c.ctx, tx); err != nil {
+		return fmt.Errorf("failed to send tx, error: %w", err)
+	}
+	c.autoClaimerSentTotal.Inc()
+	c.sent[message.MessageHash] = c.clock.Now().UTC()
+	log.Info("sent auto claim", "message hash", message.MessageHash, "tx hash", tx.Hash().Hex(), "nonce", nonce, "gas", gas)
+	return nil
+}
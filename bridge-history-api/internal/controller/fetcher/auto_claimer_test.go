@@ -0,0 +1,150 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/clock"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	autoClaimTestKey = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	allowedAddress   = "0x00000000000000000000000000000000000000aA"
+)
+
+// mockL1Client estimates and records the claims sent to L1.
+type mockL1Client struct {
+	baseFee   *big.Int
+	reverting map[string]bool // by calldata
+	sent      []*types.Transaction
+}
+
+func (m *mockL1Client) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (m *mockL1Client) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: m.baseFee}, nil
+}
+
+func (m *mockL1Client) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return uint64(len(m.sent)), nil
+}
+
+func (m *mockL1Client) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (m *mockL1Client) EstimateGas(_ context.Context, call ethereum.CallMsg) (uint64, error) {
+	if m.reverting[common.Bytes2Hex(call.Data)] {
+		return 0, errors.New("execution reverted")
+	}
+	return 100000, nil
+}
+
+func (m *mockL1Client) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	m.sent = append(m.sent, tx)
+	return nil
+}
+
+// setupAutoClaimableWithdrawals inserts finalized withdrawals of the given ETH values, sent by allowedAddress if
+// allowed.
+func setupAutoClaimableWithdrawals(t *testing.T, values []string, allowed []bool) []string {
+	messageHashes := setupClaimableWithdrawals(t, len(values))
+	for i, messageHash := range messageHashes {
+		sender := "0x00000000000000000000000000000000000000bB"
+		if allowed[i] {
+			sender = allowedAddress
+		}
+		assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET message_value = ?, sender = ?, receiver = ?, merkle_proof = ?, message_data = ? WHERE message_hash = ?",
+			values[i], sender, sender, common.FromHex("0xaabb"), "0x", messageHash).Error)
+	}
+	return messageHashes
+}
+
+func TestAutoClaimerSendsMatchingClaims(t *testing.T) {
+	setupAutoClaimableWithdrawals(t, []string{"100", "100", "1", "100"}, []bool{true, false, true, true})
+	client := &mockL1Client{baseFee: big.NewInt(10)}
+	cfg := &config.AutoClaimConfig{Enabled: true, PrivateKey: autoClaimTestKey, AddressAllowlist: []string{allowedAddress}, MinValue: "50", MaxGasPrice: "20"}
+	clk := clock.NewFake(time.Now())
+	c, err := NewAutoClaimer(context.Background(), cfg, common.Address{}.Hex(), db, client, SoleInstance, clk, prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	// the withdrawals of another sender and below the min value are skipped.
+	c.claim()
+	assert.Len(t, client.sent, 2)
+	assert.Equal(t, float64(2), testutil.ToFloat64(c.autoClaimerSentTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.autoClaimerSkippedTotal.WithLabelValues(autoClaimSkipAllowlist)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.autoClaimerSkippedTotal.WithLabelValues(autoClaimSkipMinValue)))
+	// the fee cap is capped by the ceiling.
+	assert.Equal(t, big.NewInt(20), client.sent[0].GasFeeCap())
+	assert.Equal(t, uint64(1), client.sent[1].Nonce())
+
+	// the claims sent are not sent again until the retry interval passed.
+	c.claim()
+	assert.Len(t, client.sent, 2)
+	assert.Equal(t, float64(2), testutil.ToFloat64(c.autoClaimerSkippedTotal.WithLabelValues(autoClaimSkipInFlight)))
+	clk.Advance(defaultAutoClaimRetryInterval)
+	c.claim()
+	assert.Len(t, client.sent, 4)
+}
+
+func TestAutoClaimerGasPriceCeiling(t *testing.T) {
+	setupAutoClaimableWithdrawals(t, []string{"100"}, []bool{true})
+	client := &mockL1Client{baseFee: big.NewInt(30)}
+	cfg := &config.AutoClaimConfig{Enabled: true, PrivateKey: autoClaimTestKey, MaxGasPrice: "20"}
+	c, err := NewAutoClaimer(context.Background(), cfg, common.Address{}.Hex(), db, client, SoleInstance, clock.NewFake(time.Now()), prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	c.claim()
+	assert.Empty(t, client.sent)
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.autoClaimerSkippedTotal.WithLabelValues(autoClaimSkipGasPrice)))
+
+	// the claim is sent once the gas price drops.
+	client.baseFee = big.NewInt(10)
+	c.claim()
+	assert.Len(t, client.sent, 1)
+}
+
+func TestAutoClaimerDryRun(t *testing.T) {
+	setupAutoClaimableWithdrawals(t, []string{"100", "100"}, []bool{true, true})
+	client := &mockL1Client{baseFee: big.NewInt(10), reverting: make(map[string]bool)}
+	cfg := &config.AutoClaimConfig{Enabled: true, DryRun: true}
+	c, err := NewAutoClaimer(context.Background(), cfg, common.Address{}.Hex(), db, client, notLeader{}, clock.NewFake(time.Now()), prometheus.NewRegistry())
+	assert.NoError(t, err)
+
+	// a withdrawal claimed meanwhile fails the estimation.
+	messages, err := orm.NewCrossMessage(db).GetClaimableL2WithdrawalsUpdatedBefore(context.Background(), time.Now(), 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	data, err := logic.ClaimCalldata(messages[1])
+	assert.NoError(t, err)
+	client.reverting[common.Bytes2Hex(data)] = true
+
+	// dry runs send nothing and do not need the leadership.
+	c.claim()
+	assert.Empty(t, client.sent)
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.autoClaimerDryRunTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.autoClaimerFailureTotal))
+}
+
+func TestNewAutoClaimerRequiresKey(t *testing.T) {
+	_, err := NewAutoClaimer(context.Background(), &config.AutoClaimConfig{Enabled: true}, common.Address{}.Hex(), db, &mockL1Client{}, SoleInstance, clock.New(), prometheus.NewRegistry())
+	assert.Error(t, err)
+	_, err = NewAutoClaimer(context.Background(), &config.AutoClaimConfig{Enabled: true, DryRun: true, MaxGasPrice: "-1"}, common.Address{}.Hex(), db, &mockL1Client{}, SoleInstance, clock.New(), prometheus.NewRegistry())
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	defaultClaimReconciliationInterval = 10 * time.Minute
+	defaultMinClaimableAge             = time.Hour
+	defaultClaimReconciliationBatch    = 100
+)
+
+// ClaimReconciler checks on L1 whether withdrawals that stay claimable for long were in fact executed,
+// and marks them relayed. This covers claims whose relay event the L1 fetcher never indexed.
+type ClaimReconciler struct {
+	ctx           context.Context
+	client        ethereum.ContractCaller
+	messengerAddr common.Address
+	crossMessage  *orm.CrossMessage
+	leadership    LeadershipChecker // checked before marking withdrawals relayed
+
+	interval        time.Duration
+	minClaimableAge time.Duration
+	batchSize       int
+
+	// lastID is the id of the last checked withdrawal, withdrawals are checked in id order and the scan restarts
+	// from the beginning once all claimable withdrawals were checked.
+	lastID uint64
+
+	claimReconcilerRunningTotal      prometheus.Counter
+	claimReconcilerCheckedTotal      prometheus.Counter
+	claimReconcilerCorrectedTotal    prometheus.Counter
+	claimReconcilerCheckFailureTotal prometheus.Counter
+}
+
+// NewClaimReconciler creates a new ClaimReconciler instance.
+func NewClaimReconciler(ctx context.Context, cfg *config.ClaimReconciliationConfig, messengerAddr string, db *gorm.DB, client ethereum.ContractCaller, leadership LeadershipChecker, reg prometheus.Registerer) *ClaimReconciler {
+	r := &ClaimReconciler{
+		ctx:             ctx,
+		client:          client,
+		messengerAddr:   common.HexToAddress(messengerAddr),
+		crossMessage:    orm.NewCrossMessage(db),
+		leadership:      leadership,
+		interval:        defaultClaimReconciliationInterval,
+		minClaimableAge: defaultMinClaimableAge,
+		batchSize:       defaultClaimReconciliationBatch,
+	}
+	if cfg.IntervalSec > 0 {
+		r.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.MinClaimableAgeSec > 0 {
+		r.minClaimableAge = time.Duration(cfg.MinClaimableAgeSec) * time.Second
+	}
+	if cfg.BatchSize > 0 {
+		r.batchSize = cfg.BatchSize
+	}
+
+	r.claimReconcilerRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "claim_reconciler_running_total",
+		Help: "Total count of claim reconciliation runs.",
+	})
+	r.claimReconcilerCheckedTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "claim_reconciler_checked_total",
+		Help: "Total count of claimable withdrawals checked on L1.",
+	})
+	r.claimReconcilerCorrectedTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "claim_reconciler_corrected_total",
+		Help: "Total count of claimable withdrawals found executed on L1 and marked relayed.",
+	})
+	r.claimReconcilerCheckFailureTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "claim_reconciler_check_failure_total",
+		Help: "Total count of failed L1 checks of claimable withdrawals.",
+	})
+
+	return r
+}
+
+// Start starts the claim reconciliation loop.
+func (r *ClaimReconciler) Start() {
+	tick := time.NewTicker(r.interval)
+	go func() {
+		for {
+			select {
+			case <-r.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				r.reconcile()
+			}
+		}
+	}()
+}
+
+func (r *ClaimReconciler) reconcile() {
+	r.claimReconcilerRunningTotal.Inc()
+
+	messages, err := r.crossMessage.GetClaimableL2WithdrawalsUpdatedBefore(r.ctx, time.Now().Add(-r.minClaimableAge), r.lastID, r.batchSize)
+	if err != nil {
+		log.Error("failed to get claimable withdrawals", "err", err)
+		return
+	}
+	if len(messages) < r.batchSize {
+		r.lastID = 0
+	} else {
+		r.lastID = messages[len(messages)-1].ID
+	}
+
+	var executedMessageHashes []string
+	for _, message := range messages {
+		executed, checkErr := r.isL2MessageExecuted(common.HexToHash(message.MessageHash))
+		if checkErr != nil {
+			r.claimReconcilerCheckFailureTotal.Inc()
+			log.Warn("failed to check withdrawal execution", "message hash", message.MessageHash, "err", checkErr)
+			continue
+		}
+		r.claimReconcilerCheckedTotal.Inc()
+		if executed {
+			executedMessageHashes = append(executedMessageHashes, message.MessageHash)
+		}
+	}
+
+	if len(executedMessageHashes) == 0 {
+		return
+	}
+	if err = r.leadership.CheckLeadership(r.ctx); err != nil {
+		log.Error("skip marking executed withdrawals relayed, fetcher leadership check failed", "err", err)
+		return
+	}
+
+	corrected, err := r.crossMessage.UpdateL2WithdrawalsRelayed(r.ctx, executedMessageHashes)
+	if err != nil {
+		log.Error("failed to mark executed withdrawals relayed", "err", err)
+		return
+	}
+	if corrected > 0 {
+		r.claimReconcilerCorrectedTotal.Add(float64(corrected))
+		log.Info("marked withdrawals executed on L1 relayed", "count", corrected, "message hashes", executedMessageHashes)
+	}
+}
+
+func (r *ClaimReconciler) isL2MessageExecuted(messageHash common.Hash) (bool, error) {
+	data, err := backendabi.IL1ScrollMessengerABI.Pack("isL2MessageExecuted", messageHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack isL2MessageExecuted, error: %w", err)
+	}
+	output, err := r.client.CallContract(r.ctx, ethereum.CallMsg{To: &r.messengerAddr, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to call isL2MessageExecuted, error: %w", err)
+	}
+	var executed bool
+	if err = backendabi.IL1ScrollMessengerABI.UnpackIntoInterface(&executed, "isL2MessageExecuted", output); err != nil {
+		return false, fmt.Errorf("failed to unpack isL2MessageExecuted, error: %w", err)
+	}
+	return executed, nil
+}
@@ -0,0 +1,122 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+// mockMessenger answers isL2MessageExecuted calls of the L1 messenger.
+type mockMessenger struct {
+	executed map[common.Hash]bool
+	failing  map[common.Hash]bool
+}
+
+func (m *mockMessenger) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	method := backendabi.IL1ScrollMessengerABI.Methods["isL2MessageExecuted"]
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	messageHash := common.Hash(args[0].([32]byte))
+	if m.failing[messageHash] {
+		return nil, errors.New("call failed")
+	}
+	return method.Outputs.Pack(m.executed[messageHash])
+}
+
+type notLeader struct{}
+
+func (notLeader) CheckLeadership(context.Context) error {
+	return ErrNotLeader
+}
+
+func setupClaimableWithdrawals(t *testing.T, count int) []string {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	var messages []*orm.CrossMessage
+	var messageHashes []string
+	for i := 0; i < count; i++ {
+		messageHash := common.BigToHash(big.NewInt(int64(i + 1))).Hex()
+		messageHashes = append(messageHashes, messageHash)
+		messages = append(messages, &orm.CrossMessage{MessageHash: messageHash, MessageType: int(orm.MessageTypeL2SentMessage), MessageNonce: uint64(i),
+			L2TxHash: fmt.Sprintf("0x%d", i), TokenAmounts: "1", TxStatus: int(orm.TxStatusTypeSent), RollupStatus: int(orm.RollupStatusTypeFinalized)})
+	}
+	assert.NoError(t, orm.NewCrossMessage(db).InsertOrUpdateL2Messages(context.Background(), messages))
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ?", time.Now().UTC().Add(-2*time.Hour)).Error)
+	return messageHashes
+}
+
+func txStatusOf(t *testing.T, messageHash string) orm.TxStatusType {
+	var message orm.CrossMessage
+	assert.NoError(t, db.Where("message_hash = ?", messageHash).First(&message).Error)
+	return orm.TxStatusType(message.TxStatus)
+}
+
+func TestClaimReconcilerMarksExecutedWithdrawalsRelayed(t *testing.T) {
+	messageHashes := setupClaimableWithdrawals(t, 3)
+	messenger := &mockMessenger{
+		executed: map[common.Hash]bool{common.HexToHash(messageHashes[0]): true, common.HexToHash(messageHashes[2]): true},
+		failing:  map[common.Hash]bool{common.HexToHash(messageHashes[2]): true},
+	}
+	cfg := &config.ClaimReconciliationConfig{Enabled: true, MinClaimableAgeSec: 3600, BatchSize: 2}
+	r := NewClaimReconciler(context.Background(), cfg, common.Address{}.Hex(), db, messenger, SoleInstance, prometheus.NewRegistry())
+
+	// the first batch holds an executed and a pending withdrawal.
+	r.reconcile()
+	assert.Equal(t, orm.TxStatusTypeRelayed, txStatusOf(t, messageHashes[0]))
+	assert.Equal(t, orm.TxStatusTypeSent, txStatusOf(t, messageHashes[1]))
+	assert.Equal(t, float64(2), testutil.ToFloat64(r.claimReconcilerCheckedTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(r.claimReconcilerCorrectedTotal))
+
+	// the second batch resumes after the first one, a failed check leaves the withdrawal claimable.
+	r.reconcile()
+	assert.Equal(t, orm.TxStatusTypeSent, txStatusOf(t, messageHashes[2]))
+	assert.Equal(t, float64(1), testutil.ToFloat64(r.claimReconcilerCheckFailureTotal))
+	assert.Zero(t, r.lastID)
+
+	// once the check succeeds, the next scan from the beginning corrects it.
+	delete(messenger.failing, common.HexToHash(messageHashes[2]))
+	r.reconcile()
+	assert.Equal(t, orm.TxStatusTypeRelayed, txStatusOf(t, messageHashes[2]))
+	assert.Equal(t, float64(2), testutil.ToFloat64(r.claimReconcilerCorrectedTotal))
+}
+
+func TestClaimReconcilerSkipsRecentlyUpdatedWithdrawals(t *testing.T) {
+	messageHashes := setupClaimableWithdrawals(t, 1)
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ?", time.Now().UTC()).Error)
+	messenger := &mockMessenger{executed: map[common.Hash]bool{common.HexToHash(messageHashes[0]): true}}
+	cfg := &config.ClaimReconciliationConfig{Enabled: true, MinClaimableAgeSec: 3600}
+	r := NewClaimReconciler(context.Background(), cfg, common.Address{}.Hex(), db, messenger, SoleInstance, prometheus.NewRegistry())
+
+	r.reconcile()
+	assert.Equal(t, orm.TxStatusTypeSent, txStatusOf(t, messageHashes[0]))
+	assert.Zero(t, testutil.ToFloat64(r.claimReconcilerCheckedTotal))
+}
+
+func TestClaimReconcilerRequiresLeadership(t *testing.T) {
+	messageHashes := setupClaimableWithdrawals(t, 1)
+	messenger := &mockMessenger{executed: map[common.Hash]bool{common.HexToHash(messageHashes[0]): true}}
+	cfg := &config.ClaimReconciliationConfig{Enabled: true, MinClaimableAgeSec: 3600}
+	r := NewClaimReconciler(context.Background(), cfg, common.Address{}.Hex(), db, messenger, notLeader{}, prometheus.NewRegistry())
+
+	r.reconcile()
+	assert.Equal(t, orm.TxStatusTypeSent, txStatusOf(t, messageHashes[0]))
+	assert.Zero(t, testutil.ToFloat64(r.claimReconcilerCorrectedTotal))
+}
@@ -0,0 +1,140 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const defaultConsistencyCheckGracePeriod = time.Hour
+
+// Invariants checked by the ConsistencyChecker.
+const (
+	InvariantFinalizedWithdrawalBatchIndex = "finalized_withdrawal_batch_index"
+	InvariantRelayedMessageTxHashes        = "relayed_message_tx_hashes"
+	InvariantL1MessageQueueIndexOrder      = "l1_message_queue_index_order"
+)
+
+// ConsistencyReport is the number of violations of each invariant, after repair.
+type ConsistencyReport struct {
+	Violations map[string]int64
+	Repaired   map[string]int64
+}
+
+// TotalViolations returns the number of violations of all invariants.
+func (r *ConsistencyReport) TotalViolations() int64 {
+	var total int64
+	for _, count := range r.Violations {
+		total += count
+	}
+	return total
+}
+
+// ConsistencyChecker verifies the invariants of the indexed messages on fetcher startup:
+//   - finalized withdrawals have a batch index, repaired from the indexed finalized batches.
+//   - relayed messages have the tx hashes of both sides, messages updated within the grace period are skipped as the
+//     fetcher of the other side may lag behind.
+//   - L1 message queue indexes are unique and increase with the L1 block number.
+//
+// Only the first invariant can be repaired in place, the others need the affected block ranges to be re-fetched.
+type ConsistencyChecker struct {
+	ctx          context.Context
+	cfg          *config.ConsistencyCheckConfig
+	crossMessage *orm.CrossMessage
+	gracePeriod  time.Duration
+
+	consistencyCheckViolations    *prometheus.GaugeVec
+	consistencyCheckRepairedTotal *prometheus.CounterVec
+}
+
+// NewConsistencyChecker creates a new ConsistencyChecker instance.
+func NewConsistencyChecker(ctx context.Context, cfg *config.ConsistencyCheckConfig, db *gorm.DB, reg prometheus.Registerer) *ConsistencyChecker {
+	c := &ConsistencyChecker{
+		ctx:          ctx,
+		cfg:          cfg,
+		crossMessage: orm.NewCrossMessage(db),
+		gracePeriod:  defaultConsistencyCheckGracePeriod,
+	}
+	if cfg.GracePeriodSec > 0 {
+		c.gracePeriod = time.Duration(cfg.GracePeriodSec) * time.Second
+	}
+
+	c.consistencyCheckViolations = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "consistency_check_violations",
+		Help: "Number of violations of each invariant found by the startup consistency check, after repair.",
+	}, []string{"invariant"})
+	c.consistencyCheckRepairedTotal = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "consistency_check_repaired_total",
+		Help: "Total count of violations repaired by the startup consistency check.",
+	}, []string{"invariant"})
+
+	return c
+}
+
+// Check checks the invariants, repairing the violations if enabled, and reports the remaining violations.
+func (c *ConsistencyChecker) Check() (*ConsistencyReport, error) {
+	report := &ConsistencyReport{Violations: make(map[string]int64), Repaired: make(map[string]int64)}
+
+	if c.cfg.Repair {
+		repaired, err := c.crossMessage.RepairFinalizedWithdrawalsWithoutBatchIndex(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.Repaired[InvariantFinalizedWithdrawalBatchIndex] = repaired
+		c.consistencyCheckRepairedTotal.WithLabelValues(InvariantFinalizedWithdrawalBatchIndex).Add(float64(repaired))
+	}
+
+	checks := []struct {
+		invariant string
+		count     func() (int64, error)
+	}{
+		{InvariantFinalizedWithdrawalBatchIndex, func() (int64, error) {
+			return c.crossMessage.CountFinalizedWithdrawalsWithoutBatchIndex(c.ctx)
+		}},
+		{InvariantRelayedMessageTxHashes, func() (int64, error) {
+			return c.crossMessage.CountRelayedMessagesWithoutTxHashes(c.ctx, time.Now().UTC().Add(-c.gracePeriod))
+		}},
+		{InvariantL1MessageQueueIndexOrder, func() (int64, error) {
+			return c.crossMessage.CountL1MessageQueueIndexViolations(c.ctx)
+		}},
+	}
+	for _, check := range checks {
+		count, err := check.count()
+		if err != nil {
+			return nil, err
+		}
+		report.Violations[check.invariant] = count
+		c.consistencyCheckViolations.WithLabelValues(check.invariant).Set(float64(count))
+		if count > 0 {
+			log.Warn("consistency check found violations", "invariant", check.invariant, "count", count)
+		}
+	}
+
+	log.Info("consistency check finished", "violations", report.Violations, "repaired", report.Repaired)
+	return report, nil
+}
+
+// CheckOrFail runs Check, and returns an error if the check fails or violations remain when configured to fail on them.
+// Otherwise failures are only logged, so that the fetchers still start.
+func (c *ConsistencyChecker) CheckOrFail() error {
+	report, err := c.Check()
+	if err != nil {
+		if c.cfg.FailOnViolation {
+			return err
+		}
+		log.Error("consistency check failed", "err", err)
+		return nil
+	}
+	if c.cfg.FailOnViolation && report.TotalViolations() > 0 {
+		return fmt.Errorf("consistency check found %d violations: %v", report.TotalViolations(), report.Violations)
+	}
+	return nil
+}
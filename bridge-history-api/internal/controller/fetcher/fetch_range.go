@@ -0,0 +1,16 @@
+package fetcher
+
+import (
+	"scroll-tech/bridge-history-api/internal/config"
+)
+
+// defaultTargetLogsPerFetch is the number of logs per fetched range the adaptive range sizing aims at when not
+// configured, well below the 10000 logs most RPC providers return at most.
+const defaultTargetLogsPerFetch = 2000
+
+func targetLogsPerFetch(cfg *config.FetcherConfig) int {
+	if cfg.TargetLogsPerFetch > 0 {
+		return cfg.TargetLogsPerFetch
+	}
+	return defaultTargetLogsPerFetch
+}
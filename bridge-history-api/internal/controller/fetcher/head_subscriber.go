@@ -0,0 +1,103 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/clock"
+)
+
+const (
+	// headResubscribeMinBackoff is the initial delay before re-dialing the websocket endpoint after a failure.
+	headResubscribeMinBackoff = time.Second
+	// headResubscribeMaxBackoff caps the delay between two websocket resubscribe attempts.
+	headResubscribeMaxBackoff = time.Minute
+)
+
+// subscribeNewHeads subscribes to newHeads over the websocket endpoint and signals the returned channel on every new header.
+// Subscription failures are retried with exponential backoff until ctx is done. The channel holds at most one pending
+// signal, so a slow consumer only coalesces notifications and never blocks the subscription.
+// The fetchers keep polling on their ticker as well, so indexing never stalls while the subscription is down.
+func subscribeNewHeads(ctx context.Context, wsEndpoint string, layer string) <-chan struct{} {
+	return subscribeHeads(ctx, clock.New(), wsHeadSubscriber(wsEndpoint), layer)
+}
+
+// headSubscriber subscribes to new heads, the returned release func frees the underlying connection.
+type headSubscriber func(ctx context.Context, headers chan<- *types.Header) (ethereum.Subscription, func(), error)
+
+// wsHeadSubscriber returns a headSubscriber dialing the websocket endpoint on every subscription.
+func wsHeadSubscriber(wsEndpoint string) headSubscriber {
+	return func(ctx context.Context, headers chan<- *types.Header) (ethereum.Subscription, func(), error) {
+		client, err := ethclient.DialContext(ctx, wsEndpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		sub, err := client.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			client.Close()
+			return nil, nil, err
+		}
+		log.Info("subscribed to new heads", "endpoint", wsEndpoint)
+		return sub, client.Close, nil
+	}
+}
+
+// subscribeHeads is subscribeNewHeads with the clock of the backoff and the subscription source injected.
+func subscribeHeads(ctx context.Context, clk clock.Clock, subscribe headSubscriber, layer string) <-chan struct{} {
+	notifyCh := make(chan struct{}, 1)
+
+	go func() {
+		backoff := headResubscribeMinBackoff
+		for {
+			err := runHeadSubscription(ctx, subscribe, layer, notifyCh)
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Warn("new head subscription dropped, falling back to polling until resubscribed", "layer", layer, "retry in", backoff, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-clk.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > headResubscribeMaxBackoff {
+				backoff = headResubscribeMaxBackoff
+			}
+		}
+	}()
+
+	return notifyCh
+}
+
+// runHeadSubscription subscribes to new heads and forwards the notifications until the subscription fails or ctx is done.
+func runHeadSubscription(ctx context.Context, subscribe headSubscriber, layer string, notifyCh chan<- struct{}) error {
+	headers := make(chan *types.Header, 16)
+	sub, release, err := subscribe(ctx, headers)
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case header := <-headers:
+			log.Debug("received new head", "layer", layer, "number", header.Number)
+			select {
+			case notifyCh <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
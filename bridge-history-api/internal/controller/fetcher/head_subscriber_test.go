@@ -0,0 +1,131 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/clock"
+)
+
+// fakeHeadSubscriber sends the given number of headers on every subscription, then fails the subscription
+// with failErr, or keeps it open when failErr is nil.
+func fakeHeadSubscriber(numHeaders int, failErr error, subscriptions, releases *int32) headSubscriber {
+	return func(ctx context.Context, headers chan<- *types.Header) (ethereum.Subscription, func(), error) {
+		atomic.AddInt32(subscriptions, 1)
+		sub := event.NewSubscription(func(quit <-chan struct{}) error {
+			for i := 0; i < numHeaders; i++ {
+				select {
+				case headers <- &types.Header{Number: big.NewInt(int64(i))}:
+				case <-quit:
+					return nil
+				}
+			}
+			if failErr != nil {
+				return failErr
+			}
+			<-quit
+			return nil
+		})
+		return sub, func() { atomic.AddInt32(releases, 1) }, nil
+	}
+}
+
+func TestSubscribeHeadsCoalescesNotifications(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var subscriptions, releases int32
+	notifyCh := subscribeHeads(ctx, clock.New(), fakeHeadSubscriber(10, nil, &subscriptions, &releases), "test")
+
+	select {
+	case <-notifyCh:
+	case <-time.After(time.Second):
+		t.Fatal("no new head notification")
+	}
+
+	// the ten headers are coalesced into at most one more pending notification.
+	time.Sleep(100 * time.Millisecond)
+	pending := 0
+	for done := false; !done; {
+		select {
+		case <-notifyCh:
+			pending++
+		default:
+			done = true
+		}
+	}
+	assert.LessOrEqual(t, pending, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&subscriptions))
+}
+
+func TestSubscribeHeadsResubscribes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var subscriptions, releases int32
+	clk := clock.NewFake(time.Now())
+	notifyCh := subscribeHeads(ctx, clk, fakeHeadSubscriber(1, errors.New("connection reset"), &subscriptions, &releases), "test")
+
+	<-notifyCh
+	// the failed subscription is released, and resubscribed once the backoff elapsed.
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&subscriptions))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&releases))
+	clk.Advance(headResubscribeMinBackoff)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&subscriptions) >= 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSubscribeHeadsRetriesFailedSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts int32
+	subscribe := func(ctx context.Context, headers chan<- *types.Header) (ethereum.Subscription, func(), error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, nil, errors.New("dial failed")
+	}
+	clk := clock.NewFake(time.Now())
+	subscribeHeads(ctx, clk, subscribe, "test")
+
+	// the retries back off exponentially.
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	clk.Advance(headResubscribeMinBackoff)
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	clk.Advance(headResubscribeMinBackoff)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	clk.Advance(headResubscribeMinBackoff)
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestSubscribeHeadsStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var subscriptions, releases int32
+	clk := clock.NewFake(time.Now())
+	subscribeHeads(ctx, clk, fakeHeadSubscriber(0, nil, &subscriptions, &releases), "test")
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&subscriptions) == 1
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&releases) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	clk.Advance(2 * headResubscribeMinBackoff)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&subscriptions))
+}
@@ -2,18 +2,23 @@ package fetcher
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/eventwatcher"
+	"scroll-tech/common/metrics"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
 )
 
@@ -23,28 +28,36 @@ type L1MessageFetcher struct {
 	cfg    *config.FetcherConfig
 	client *ethclient.Client
 
-	l1SyncHeight        uint64
-	l1LastSyncBlockHash common.Hash
+	// leadership is checked before saving events.
+	leadership LeadershipChecker
 
 	eventUpdateLogic *logic.EventUpdateLogic
 	l1FetcherLogic   *logic.L1FetcherLogic
+	watcher          *eventwatcher.Watcher[*logic.L1FilterResult]
+	adaptiveRange    *eventwatcher.AdaptiveRange // nil if the fetch range is fixed.
+	reindexing       bool                        // the events are bulk loaded, see SetBulkLoader.
+	// batchesReparsed is set once the batches unsupported by the previous fetchers are parsed again by the leader.
+	batchesReparsed bool
 
 	l1MessageFetcherRunningTotal prometheus.Counter
 	l1MessageFetcherReorgTotal   prometheus.Counter
 	l1MessageFetcherSyncHeight   prometheus.Gauge
+	l1MessageFetcherRangeSize    prometheus.Gauge
+	l1MessageFetcherBlocksPerSec prometheus.Gauge
 }
 
 // NewL1MessageFetcher creates a new L1MessageFetcher instance.
-func NewL1MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gorm.DB, client *ethclient.Client) *L1MessageFetcher {
+func NewL1MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gorm.DB, client *ethclient.Client, leadership LeadershipChecker) *L1MessageFetcher {
 	c := &L1MessageFetcher{
 		ctx:              ctx,
 		cfg:              cfg,
 		client:           client,
+		leadership:       leadership,
 		eventUpdateLogic: logic.NewEventUpdateLogic(db, true),
 		l1FetcherLogic:   logic.NewL1FetcherLogic(cfg, db, client),
 	}
 
-	reg := prometheus.DefaultRegisterer
+	reg := metrics.Registerer()
 	c.l1MessageFetcherRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
 		Name: "L1_message_fetcher_running_total",
 		Help: "Current count of running L1 message fetcher instances.",
@@ -57,12 +70,89 @@ func NewL1MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gor
 		Name: "L1_message_fetcher_sync_height",
 		Help: "Latest blockchain height the L1 message fetcher has synced with.",
 	})
+	c.l1MessageFetcherRangeSize = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "L1_message_fetcher_range_size",
+		Help: "Number of blocks the L1 message fetcher fetches at once.",
+	})
+	c.l1MessageFetcherBlocksPerSec = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "L1_message_fetcher_blocks_per_second",
+		Help: "Blocks synced per second by the last catch-up of the L1 message fetcher.",
+	})
+
+	c.watcher = eventwatcher.New[*logic.L1FilterResult](&l1EventDecoder{logic: c.l1FetcherLogic}, &l1EventSink{leadership: leadership, eventUpdateLogic: c.eventUpdateLogic}, cfg.FetchLimit, eventwatcher.Cursor{})
+	c.l1MessageFetcherRangeSize.Set(float64(cfg.FetchLimit))
+	if cfg.MaxFetchLimit > 0 {
+		adaptive := eventwatcher.NewAdaptiveRange(cfg.FetchLimit, cfg.MinFetchLimit, cfg.MaxFetchLimit, targetLogsPerFetch(cfg))
+		c.watcher.SetAdaptiveRange(adaptive)
+		c.adaptiveRange = adaptive
+	}
+	c.watcher.OnAdvance(func(cursor eventwatcher.Cursor, reorg bool) {
+		if reorg {
+			c.l1MessageFetcherReorgTotal.Inc()
+			log.Warn("L1 reorg happened, exit and re-enter fetchAndSaveEvents", "re-sync height", cursor.Height)
+			if c.reindexing {
+				// the bulk loaded events of the reorged blocks are not replaced, the reindex must start over.
+				log.Crit("L1 reorg happened during the reindex, drop the reindex schema and reindex again", "schema", orm.ReindexSchema, "re-sync height", cursor.Height)
+			}
+		}
+		c.l1MessageFetcherSyncHeight.Set(float64(cursor.Height))
+		c.l1MessageFetcherRunningTotal.Inc()
+	})
 
 	return c
 }
 
+// SetBulkLoader loads the events with the bulk loader, for a full reindex into the db of the fetcher, see CatchUp. A
+// reorg of the fetched blocks is fatal.
+func (c *L1MessageFetcher) SetBulkLoader(loader *orm.BulkLoader) {
+	c.eventUpdateLogic.SetBulkLoader(loader)
+	c.reindexing = true
+}
+
 // Start starts the L1 message fetching process.
 func (c *L1MessageFetcher) Start() {
+	c.initCursor()
+
+	// newHeadCh stays nil when no websocket endpoint is configured, so only the ticker drives fetching.
+	var newHeadCh <-chan struct{}
+	if c.cfg.WSEndpoint != "" {
+		newHeadCh = subscribeNewHeads(c.ctx, c.cfg.WSEndpoint, "L1")
+	}
+
+	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				c.fetchAndSaveEvents(c.cfg.Confirmation)
+			case <-newHeadCh:
+				c.fetchAndSaveEvents(c.cfg.Confirmation)
+			}
+		}
+	}()
+}
+
+// CatchUp fetches and saves the events up to the confirmed L1 head from the height synced in the db, fetching again
+// after the block time on failure, e.g. for a reindex. It returns once the head is reached, or the context is done.
+func (c *L1MessageFetcher) CatchUp() error {
+	c.initCursor()
+	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
+	defer tick.Stop()
+	for !c.fetchAndSaveEvents(c.cfg.Confirmation) {
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-tick.C:
+		}
+	}
+	return nil
+}
+
+// initCursor sets the cursor of the watcher to the height synced in the db, less the reorg safe depth.
+func (c *L1MessageFetcher) initCursor() {
 	messageSyncedHeight, batchSyncedHeight, dbErr := c.eventUpdateLogic.GetL1SyncHeight(c.ctx)
 	if dbErr != nil {
 		log.Crit("L1MessageFetcher start failed", "err", dbErr)
@@ -89,66 +179,78 @@ func (c *L1MessageFetcher) Start() {
 		return
 	}
 
-	c.updateL1SyncHeight(l1SyncHeight, header.Hash())
-
-	log.Info("Start L1 message fetcher", "message synced height", messageSyncedHeight, "batch synced height", batchSyncedHeight, "config start height", c.cfg.StartHeight, "sync start height", c.l1SyncHeight+1)
+	c.watcher.SetCursor(eventwatcher.Cursor{Height: l1SyncHeight, Hash: header.Hash()})
+	c.l1MessageFetcherSyncHeight.Set(float64(l1SyncHeight))
 
-	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
-	go func() {
-		for {
-			select {
-			case <-c.ctx.Done():
-				tick.Stop()
-				return
-			case <-tick.C:
-				c.fetchAndSaveEvents(c.cfg.Confirmation)
-			}
-		}
-	}()
+	log.Info("Start L1 message fetcher", "message synced height", messageSyncedHeight, "batch synced height", batchSyncedHeight, "config start height", c.cfg.StartHeight, "sync start height", l1SyncHeight+1)
 }
 
-func (c *L1MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
-	startHeight := c.l1SyncHeight + 1
+// fetchAndSaveEvents syncs the events up to the confirmed head, it returns whether the head is reached.
+func (c *L1MessageFetcher) fetchAndSaveEvents(confirmation uint64) (caughtUp bool) {
+	// a panic is reported and the events are fetched again at the next tick.
+	defer crashreport.Recover("L1_message_fetcher")
+
+	startHeight := c.watcher.Cursor().Height + 1
 	endHeight, rpcErr := utils.GetBlockNumber(c.ctx, c.client, confirmation)
 	if rpcErr != nil {
 		log.Error("failed to get L1 block number", "confirmation", confirmation, "err", rpcErr)
-		return
+		return false
 	}
 
 	log.Info("fetch and save missing L1 events", "start height", startHeight, "end height", endHeight, "confirmation", confirmation)
 
-	for from := startHeight; from <= endHeight; from += c.cfg.FetchLimit {
-		to := from + c.cfg.FetchLimit - 1
-		if to > endHeight {
-			to = endHeight
-		}
-
-		isReorg, resyncHeight, lastBlockHash, l1FetcherResult, fetcherErr := c.l1FetcherLogic.L1Fetcher(c.ctx, from, to, c.l1LastSyncBlockHash)
-		if fetcherErr != nil {
-			log.Error("failed to fetch L1 events", "from", from, "to", to, "err", fetcherErr)
-			return
+	if !c.batchesReparsed && c.leadership.CheckLeadership(c.ctx) == nil {
+		if err := c.l1FetcherLogic.ReparseBatchEvents(c.ctx); err != nil {
+			log.Error("failed to parse the commit txs of the unparsed batches again", "err", err)
+		} else {
+			c.batchesReparsed = true
 		}
+	}
 
-		if isReorg {
-			c.l1MessageFetcherReorgTotal.Inc()
-			log.Warn("L1 reorg happened, exit and re-enter fetchAndSaveEvents", "re-sync height", resyncHeight)
-			c.updateL1SyncHeight(resyncHeight, lastBlockHash)
-			c.l1MessageFetcherRunningTotal.Inc()
-			return
-		}
+	start := time.Now()
+	err := c.watcher.Sync(c.ctx, endHeight)
+	if syncedHeight := c.watcher.Cursor().Height; syncedHeight >= startHeight {
+		c.l1MessageFetcherBlocksPerSec.Set(float64(syncedHeight-startHeight+1) / time.Since(start).Seconds())
+	}
+	if c.adaptiveRange != nil {
+		c.l1MessageFetcherRangeSize.Set(float64(c.adaptiveRange.Size()))
+	}
+	if err != nil {
+		log.Error("failed to fetch and save L1 events", "synced height", c.watcher.Cursor().Height, "end height", endHeight, "err", err)
+		return false
+	}
+	return c.watcher.Cursor().Height >= endHeight
+}
 
-		if insertUpdateErr := c.eventUpdateLogic.L1InsertOrUpdate(c.ctx, l1FetcherResult); insertUpdateErr != nil {
-			log.Error("failed to save L1 events", "from", from, "to", to, "err", insertUpdateErr)
-			return
-		}
+// l1EventDecoder fetches the L1 events of a range, detecting reorgs by the hash of the synced block.
+type l1EventDecoder struct {
+	logic *logic.L1FetcherLogic
+}
 
-		c.updateL1SyncHeight(to, lastBlockHash)
-		c.l1MessageFetcherRunningTotal.Inc()
+func (d *l1EventDecoder) Decode(ctx context.Context, r eventwatcher.Range, cursor eventwatcher.Cursor) (*eventwatcher.Decoded[*logic.L1FilterResult], error) {
+	isReorg, resyncHeight, lastBlockHash, res, err := d.logic.L1Fetcher(ctx, r.From, r.To, cursor.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch L1 events, from: %d, to: %d, error: %w", r.From, r.To, err)
+	}
+	if isReorg {
+		return &eventwatcher.Decoded[*logic.L1FilterResult]{Cursor: eventwatcher.Cursor{Height: resyncHeight, Hash: lastBlockHash}, Reorg: true}, nil
 	}
+	return &eventwatcher.Decoded[*logic.L1FilterResult]{Events: res, Cursor: eventwatcher.Cursor{Height: r.To, Hash: lastBlockHash}, NumLogs: res.NumLogs}, nil
+}
+
+// l1EventSink saves the L1 events of a range while the fetcher is the leader.
+type l1EventSink struct {
+	leadership       LeadershipChecker
+	eventUpdateLogic *logic.EventUpdateLogic
 }
 
-func (c *L1MessageFetcher) updateL1SyncHeight(height uint64, blockHash common.Hash) {
-	c.l1MessageFetcherSyncHeight.Set(float64(height))
-	c.l1LastSyncBlockHash = blockHash
-	c.l1SyncHeight = height
+func (s *l1EventSink) Persist(ctx context.Context, r eventwatcher.Range, res *logic.L1FilterResult) error {
+	if err := s.leadership.CheckLeadership(ctx); err != nil {
+		return fmt.Errorf("failed to check leadership before saving L1 events, error: %w", err)
+	}
+
+	if err := s.eventUpdateLogic.L1InsertOrUpdate(ctx, res); err != nil {
+		return fmt.Errorf("failed to save L1 events, from: %d, to: %d, error: %w", r.From, r.To, err)
+	}
+	return nil
 }
@@ -2,50 +2,61 @@ package fetcher
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/eventwatcher"
+	"scroll-tech/common/metrics"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
 )
 
 // L2MessageFetcher fetches cross message events from L2 and saves them to database.
 type L2MessageFetcher struct {
-	ctx                 context.Context
-	cfg                 *config.FetcherConfig
-	db                  *gorm.DB
-	client              *ethclient.Client
-	l2SyncHeight        uint64
-	l2LastSyncBlockHash common.Hash
+	ctx        context.Context
+	cfg        *config.FetcherConfig
+	db         *gorm.DB
+	client     *ethclient.Client
+	leadership LeadershipChecker // checked before saving events
 
 	eventUpdateLogic *logic.EventUpdateLogic
 	l2FetcherLogic   *logic.L2FetcherLogic
+	watcher          *eventwatcher.Watcher[*logic.L2FilterResult]
+	adaptiveRange    *eventwatcher.AdaptiveRange // nil if the fetch range is fixed.
+	reindexing       bool                        // the events are bulk loaded, see SetBulkLoader.
 
 	l2MessageFetcherRunningTotal prometheus.Counter
 	l2MessageFetcherReorgTotal   prometheus.Counter
 	l2MessageFetcherSyncHeight   prometheus.Gauge
+	l2MessageFetcherRangeSize    prometheus.Gauge
+	l2MessageFetcherBlocksPerSec prometheus.Gauge
 }
 
 // NewL2MessageFetcher creates a new L2MessageFetcher instance.
-func NewL2MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gorm.DB, client *ethclient.Client) *L2MessageFetcher {
+func NewL2MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gorm.DB, client *ethclient.Client, leadership LeadershipChecker) *L2MessageFetcher {
 	c := &L2MessageFetcher{
 		ctx:              ctx,
 		cfg:              cfg,
 		db:               db,
 		client:           client,
+		leadership:       leadership,
 		eventUpdateLogic: logic.NewEventUpdateLogic(db, false),
 		l2FetcherLogic:   logic.NewL2FetcherLogic(cfg, db, client),
 	}
+	c.eventUpdateLogic.SetMessageDataOffloadThreshold(cfg.MessageDataOffloadThreshold)
 
-	reg := prometheus.DefaultRegisterer
+	reg := metrics.Registerer()
 	c.l2MessageFetcherRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
 		Name: "L2_message_fetcher_running_total",
 		Help: "Current count of running L2 message fetcher instances.",
@@ -58,12 +69,97 @@ func NewL2MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gor
 		Name: "L2_message_fetcher_sync_height",
 		Help: "Latest blockchain height the L2 message fetcher has synced with.",
 	})
+	c.l2MessageFetcherRangeSize = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "L2_message_fetcher_range_size",
+		Help: "Number of blocks the L2 message fetcher fetches at once.",
+	})
+	c.l2MessageFetcherBlocksPerSec = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "L2_message_fetcher_blocks_per_second",
+		Help: "Blocks synced per second by the last catch-up of the L2 message fetcher.",
+	})
+
+	c.watcher = eventwatcher.New[*logic.L2FilterResult](&l2EventDecoder{logic: c.l2FetcherLogic}, &l2EventSink{leadership: leadership, eventUpdateLogic: c.eventUpdateLogic}, cfg.FetchLimit, eventwatcher.Cursor{})
+	c.l2MessageFetcherRangeSize.Set(float64(cfg.FetchLimit))
+	if cfg.MaxFetchLimit > 0 {
+		adaptive := eventwatcher.NewAdaptiveRange(cfg.FetchLimit, cfg.MinFetchLimit, cfg.MaxFetchLimit, targetLogsPerFetch(cfg))
+		c.watcher.SetAdaptiveRange(adaptive)
+		c.adaptiveRange = adaptive
+	}
+	c.watcher.OnAdvance(func(cursor eventwatcher.Cursor, reorg bool) {
+		if reorg {
+			c.l2MessageFetcherReorgTotal.Inc()
+			log.Warn("L2 reorg happened, exit and re-enter fetchAndSaveEvents", "re-sync height", cursor.Height)
+			if c.reindexing {
+				// the bulk loaded events of the reorged blocks are not replaced, the reindex must start over.
+				log.Crit("L2 reorg happened during the reindex, drop the reindex schema and reindex again", "schema", orm.ReindexSchema, "re-sync height", cursor.Height)
+			}
+		}
+		c.l2MessageFetcherSyncHeight.Set(float64(cursor.Height))
+		c.l2MessageFetcherRunningTotal.Inc()
+	})
 
 	return c
 }
 
+// SetL1FinalityGate makes the L2 withdrawals claimable only once the L1 block finalizing their batch is finalized by
+// the beacon chain, according to the L1 client.
+func (c *L2MessageFetcher) SetL1FinalityGate(l1Client *ethclient.Client) {
+	c.eventUpdateLogic.SetL1FinalityGate(func(ctx context.Context) (uint64, error) {
+		return utils.GetFinalizedBlockNumber(ctx, l1Client)
+	})
+}
+
+// SetBulkLoader loads the events with the bulk loader, for a full reindex into the db of the fetcher, see CatchUp. A
+// reorg of the fetched blocks is fatal.
+func (c *L2MessageFetcher) SetBulkLoader(loader *orm.BulkLoader) {
+	c.eventUpdateLogic.SetBulkLoader(loader)
+	c.reindexing = true
+}
+
 // Start starts the L2 message fetching process.
 func (c *L2MessageFetcher) Start() {
+	c.initCursor()
+
+	// newHeadCh stays nil when no websocket endpoint is configured, so only the ticker drives fetching.
+	var newHeadCh <-chan struct{}
+	if c.cfg.WSEndpoint != "" {
+		newHeadCh = subscribeNewHeads(c.ctx, c.cfg.WSEndpoint, "L2")
+	}
+
+	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				c.fetchAndSaveEvents(c.cfg.Confirmation)
+			case <-newHeadCh:
+				c.fetchAndSaveEvents(c.cfg.Confirmation)
+			}
+		}
+	}()
+}
+
+// CatchUp fetches and saves the events up to the confirmed L2 head from the height synced in the db, fetching again
+// after the block time on failure, e.g. for a reindex. It returns once the head is reached, or the context is done.
+func (c *L2MessageFetcher) CatchUp() error {
+	c.initCursor()
+	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
+	defer tick.Stop()
+	for !c.fetchAndSaveEvents(c.cfg.Confirmation) {
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-tick.C:
+		}
+	}
+	return nil
+}
+
+// initCursor sets the cursor of the watcher to the height synced in the db, less the reorg safe depth.
+func (c *L2MessageFetcher) initCursor() {
 	l2SentMessageSyncedHeight, dbErr := c.eventUpdateLogic.GetL2MessageSyncedHeightInDB(c.ctx)
 	if dbErr != nil {
 		log.Crit("failed to get L2 cross message processed height", "err", dbErr)
@@ -84,70 +180,79 @@ func (c *L2MessageFetcher) Start() {
 		return
 	}
 
-	c.updateL2SyncHeight(l2SyncHeight, header.Hash())
+	c.watcher.SetCursor(eventwatcher.Cursor{Height: l2SyncHeight, Hash: header.Hash()})
+	c.l2MessageFetcherSyncHeight.Set(float64(l2SyncHeight))
 
 	log.Info("Start L2 message fetcher", "message synced height", l2SentMessageSyncedHeight, "sync start height", l2SyncHeight+1)
-
-	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
-	go func() {
-		for {
-			select {
-			case <-c.ctx.Done():
-				tick.Stop()
-				return
-			case <-tick.C:
-				c.fetchAndSaveEvents(c.cfg.Confirmation)
-			}
-		}
-	}()
 }
 
-func (c *L2MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
-	startHeight := c.l2SyncHeight + 1
+// fetchAndSaveEvents syncs the events up to the confirmed head, it returns whether the head is reached.
+func (c *L2MessageFetcher) fetchAndSaveEvents(confirmation uint64) (caughtUp bool) {
+	// a panic is reported and the events are fetched again at the next tick.
+	defer crashreport.Recover("L2_message_fetcher")
+
+	startHeight := c.watcher.Cursor().Height + 1
 	endHeight, rpcErr := utils.GetBlockNumber(c.ctx, c.client, confirmation)
 	if rpcErr != nil {
 		log.Error("failed to get L2 block number", "confirmation", confirmation, "err", rpcErr)
-		return
+		return false
 	}
+
 	log.Info("fetch and save missing L2 events", "start height", startHeight, "end height", endHeight, "confirmation", confirmation)
 
-	for from := startHeight; from <= endHeight; from += c.cfg.FetchLimit {
-		to := from + c.cfg.FetchLimit - 1
-		if to > endHeight {
-			to = endHeight
-		}
+	start := time.Now()
+	err := c.watcher.Sync(c.ctx, endHeight)
+	if syncedHeight := c.watcher.Cursor().Height; syncedHeight >= startHeight {
+		c.l2MessageFetcherBlocksPerSec.Set(float64(syncedHeight-startHeight+1) / time.Since(start).Seconds())
+	}
+	if c.adaptiveRange != nil {
+		c.l2MessageFetcherRangeSize.Set(float64(c.adaptiveRange.Size()))
+	}
+	if err != nil {
+		log.Error("failed to fetch and save L2 events", "synced height", c.watcher.Cursor().Height, "end height", endHeight, "err", err)
+		return false
+	}
+	return c.watcher.Cursor().Height >= endHeight
+}
 
-		isReorg, resyncHeight, lastBlockHash, l2FetcherResult, fetcherErr := c.l2FetcherLogic.L2Fetcher(c.ctx, from, to, c.l2LastSyncBlockHash)
-		if fetcherErr != nil {
-			log.Error("failed to fetch L2 events", "from", from, "to", to, "err", fetcherErr)
-			return
-		}
+// l2EventDecoder fetches the L2 events of a range, detecting reorgs by the hash of the synced block.
+type l2EventDecoder struct {
+	logic *logic.L2FetcherLogic
+}
 
-		if isReorg {
-			c.l2MessageFetcherReorgTotal.Inc()
-			log.Warn("L2 reorg happened, exit and re-enter fetchAndSaveEvents", "re-sync height", resyncHeight)
-			c.updateL2SyncHeight(resyncHeight, lastBlockHash)
-			c.l2MessageFetcherRunningTotal.Inc()
-			return
-		}
+func (d *l2EventDecoder) Decode(ctx context.Context, r eventwatcher.Range, cursor eventwatcher.Cursor) (*eventwatcher.Decoded[*logic.L2FilterResult], error) {
+	isReorg, resyncHeight, lastBlockHash, res, err := d.logic.L2Fetcher(ctx, r.From, r.To, cursor.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch L2 events, from: %d, to: %d, error: %w", r.From, r.To, err)
+	}
+	if isReorg {
+		return &eventwatcher.Decoded[*logic.L2FilterResult]{Cursor: eventwatcher.Cursor{Height: resyncHeight, Hash: lastBlockHash}, Reorg: true}, nil
+	}
+	return &eventwatcher.Decoded[*logic.L2FilterResult]{Events: res, Cursor: eventwatcher.Cursor{Height: r.To, Hash: lastBlockHash}, NumLogs: res.NumLogs}, nil
+}
 
-		if insertUpdateErr := c.eventUpdateLogic.L2InsertOrUpdate(c.ctx, l2FetcherResult); insertUpdateErr != nil {
-			log.Error("failed to save L2 events", "from", from, "to", to, "err", insertUpdateErr)
-			return
-		}
+// l2EventSink saves the L2 events of a range while the fetcher is the leader.
+type l2EventSink struct {
+	leadership       LeadershipChecker
+	eventUpdateLogic *logic.EventUpdateLogic
+}
 
-		if updateErr := c.eventUpdateLogic.UpdateL1BatchIndexAndStatus(c.ctx, c.l2SyncHeight); updateErr != nil {
-			log.Error("failed to update L1 batch index and status", "from", from, "to", to, "err", updateErr)
-			return
-		}
+func (s *l2EventSink) Persist(ctx context.Context, r eventwatcher.Range, res *logic.L2FilterResult) error {
+	if err := s.leadership.CheckLeadership(ctx); err != nil {
+		return fmt.Errorf("failed to check leadership before saving L2 events, error: %w", err)
+	}
 
-		c.updateL2SyncHeight(to, lastBlockHash)
-		c.l2MessageFetcherRunningTotal.Inc()
+	if err := s.eventUpdateLogic.L2InsertOrUpdate(ctx, res); err != nil {
+		return fmt.Errorf("failed to save L2 events, from: %d, to: %d, error: %w", r.From, r.To, err)
+	}
+
+	if err := s.leadership.CheckLeadership(ctx); err != nil {
+		return fmt.Errorf("failed to check leadership before updating L1 batch index and status, error: %w", err)
 	}
-}
 
-func (c *L2MessageFetcher) updateL2SyncHeight(height uint64, blockHash common.Hash) {
-	c.l2MessageFetcherSyncHeight.Set(float64(height))
-	c.l2LastSyncBlockHash = blockHash
-	c.l2SyncHeight = height
+	// the batch indices are derived up to the height synced before the range.
+	if err := s.eventUpdateLogic.UpdateL1BatchIndexAndStatus(ctx, r.From-1); err != nil {
+		return fmt.Errorf("failed to update L1 batch index and status, error: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,219 @@
+package fetcher
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+)
+
+const (
+	// defaultFetcherLeaderLockID is the postgres advisory lock key held by the fetcher leader.
+	defaultFetcherLeaderLockID = int64(0x6272696467650001)
+	// defaultLeaderElectionIntervalSec is the default interval of leadership acquisition attempts and liveness checks.
+	defaultLeaderElectionIntervalSec = 5
+
+	// leaderLockHeldSQL checks on the lock connection that its session still holds the advisory lock, a bigint lock
+	// key is split by postgres into the classid and objid columns of pg_locks.
+	leaderLockHeldSQL = `SELECT EXISTS (SELECT 1 FROM pg_locks WHERE locktype = 'advisory' AND pid = pg_backend_pid()
+		AND granted AND objsubid = 1 AND ((classid::BIGINT << 32) | objid::BIGINT) = $1)`
+)
+
+// ErrNotLeader is returned when checking the leadership of an instance which is not the fetcher leader.
+var ErrNotLeader = errors.New("not the fetcher leader")
+
+// LeadershipChecker checks whether this instance is the fetcher leader. The fetchers check it before each write,
+// so that a leader whose lock is gone stops writing before it notices the loss and along with the new leader.
+type LeadershipChecker interface {
+	CheckLeadership(ctx context.Context) error
+}
+
+type soleInstance struct{}
+
+// SoleInstance is the LeadershipChecker of a fetcher running without leader election, it is always the leader.
+var SoleInstance LeadershipChecker = soleInstance{}
+
+func (soleInstance) CheckLeadership(context.Context) error { return nil }
+
+// LeaderElector elects a single fetcher leader among the bridge-history fetcher replicas.
+// The leader holds a session-level postgres advisory lock on a dedicated connection, the lock is released by
+// postgres as soon as the connection is gone, so a standby replica takes over when the leader dies.
+type LeaderElector struct {
+	db       *gorm.DB
+	lockID   int64
+	interval time.Duration
+
+	// conn is the connection holding the lock while this instance is the leader.
+	mu   sync.Mutex
+	conn *sql.Conn
+
+	leaderElectorIsLeader prometheus.Gauge
+}
+
+// NewLeaderElector creates a new LeaderElector instance.
+func NewLeaderElector(cfg *config.LeaderElectionConfig, db *gorm.DB, reg prometheus.Registerer) *LeaderElector {
+	lockID := defaultFetcherLeaderLockID
+	if cfg.LockID != 0 {
+		lockID = cfg.LockID
+	}
+	intervalSec := uint64(defaultLeaderElectionIntervalSec)
+	if cfg.IntervalSec > 0 {
+		intervalSec = cfg.IntervalSec
+	}
+
+	e := &LeaderElector{
+		db:       db,
+		lockID:   lockID,
+		interval: time.Duration(intervalSec) * time.Second,
+	}
+
+	e.leaderElectorIsLeader = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "fetcher_leader_elector_is_leader",
+		Help: "Whether this instance is the elected fetcher leader (1) or a standby (0).",
+	})
+
+	return e
+}
+
+// Campaign blocks until this instance becomes the leader or ctx is canceled.
+// The returned context is canceled once the leadership is lost, or when ctx is canceled.
+func (e *LeaderElector) Campaign(ctx context.Context) (context.Context, error) {
+	sqlDB, err := e.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql db, error: %w", err)
+	}
+
+	tick := time.NewTicker(e.interval)
+	defer tick.Stop()
+	for {
+		conn, acquired, err := e.tryAcquire(ctx, sqlDB)
+		if err != nil {
+			log.Warn("failed to try to acquire fetcher leadership", "err", err)
+		}
+		if acquired {
+			log.Info("acquired fetcher leadership", "lock id", e.lockID)
+			e.leaderElectorIsLeader.Set(1)
+			e.mu.Lock()
+			e.conn = conn
+			e.mu.Unlock()
+			leaderCtx, cancel := context.WithCancel(ctx)
+			go e.keepLeadership(leaderCtx, cancel, conn)
+			return leaderCtx, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
+
+func (e *LeaderElector) tryAcquire(ctx context.Context, sqlDB *sql.DB) (*sql.Conn, bool, error) {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get db connection, error: %w", err)
+	}
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.lockID).Scan(&acquired); err != nil {
+		e.discard(conn)
+		return nil, false, fmt.Errorf("failed to try advisory lock, error: %w", err)
+	}
+	if !acquired {
+		if err = conn.Close(); err != nil {
+			log.Warn("failed to close db connection", "err", err)
+		}
+		return nil, false, nil
+	}
+	return conn, true, nil
+}
+
+// CheckLeadership checks that this instance is the leader and that its lock connection still holds the lock.
+func (e *LeaderElector) CheckLeadership(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return ErrNotLeader
+	}
+	return e.checkLock(ctx, e.conn)
+}
+
+func (e *LeaderElector) checkLock(ctx context.Context, conn *sql.Conn) error {
+	checkCtx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+	var held bool
+	if err := conn.QueryRowContext(checkCtx, leaderLockHeldSQL, e.lockID).Scan(&held); err != nil {
+		return fmt.Errorf("failed to check the leader lock, error: %w", err)
+	}
+	if !held {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// keepLeadership checks the lock periodically, and cancels the leader context once it is lost.
+func (e *LeaderElector) keepLeadership(ctx context.Context, cancel context.CancelFunc, conn *sql.Conn) {
+	defer cancel()
+	defer e.leaderElectorIsLeader.Set(0)
+
+	tick := time.NewTicker(e.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.mu.Lock()
+			e.conn = nil
+			e.release(conn)
+			e.mu.Unlock()
+			return
+		case <-tick.C:
+			e.mu.Lock()
+			err := e.checkLock(ctx, conn)
+			if err != nil && ctx.Err() == nil {
+				log.Error("lost fetcher leadership", "err", err)
+				e.conn = nil
+				e.discard(conn)
+				e.mu.Unlock()
+				return
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// release unlocks the advisory lock before returning the connection to the pool, a pooled connection
+// would otherwise keep holding the lock.
+func (e *LeaderElector) release(conn *sql.Conn) {
+	releaseCtx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+	var released bool
+	if err := conn.QueryRowContext(releaseCtx, "SELECT pg_advisory_unlock($1)", e.lockID).Scan(&released); err != nil || !released {
+		log.Warn("failed to release fetcher leadership, discarding the connection", "released", released, "err", err)
+		e.discard(conn)
+		return
+	}
+	if err := conn.Close(); err != nil {
+		log.Warn("failed to close db connection", "err", err)
+	}
+	log.Info("released fetcher leadership", "lock id", e.lockID)
+}
+
+// discard closes the underlying connection instead of returning it to the pool, which releases its session locks.
+func (e *LeaderElector) discard(conn *sql.Conn) {
+	if err := conn.Raw(func(interface{}) error { return driver.ErrBadConn }); err != nil && !errors.Is(err, driver.ErrBadConn) {
+		log.Warn("failed to discard db connection", "err", err)
+	}
+	if err := conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+		log.Warn("failed to close db connection", "err", err)
+	}
+}
@@ -0,0 +1,118 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+
+	"scroll-tech/bridge-history-api/internal/config"
+)
+
+var (
+	base *docker.App
+
+	db *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	t := &testing.T{}
+	setupEnv(t)
+	defer tearDownEnv(t)
+	m.Run()
+}
+
+func setupEnv(t *testing.T) {
+	base = docker.NewDockerApp()
+	base.RunDBImage(t)
+	var err error
+	db, err = database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	assert.NoError(t, err)
+}
+
+func tearDownEnv(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.Close()
+	base.Free()
+}
+
+func newTestLeaderElector(lockID int64) *LeaderElector {
+	return NewLeaderElector(&config.LeaderElectionConfig{Enabled: true, LockID: lockID, IntervalSec: 1}, db, prometheus.NewRegistry())
+}
+
+func TestLeaderElectorSingleLeader(t *testing.T) {
+	leader, standby := newTestLeaderElector(1001), newTestLeaderElector(1001)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderCtx, err := leader.Campaign(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, leader.CheckLeadership(context.Background()))
+
+	// the standby does not become the leader while the lock is held.
+	campaignCtx, campaignCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	_, err = standby.Campaign(campaignCtx)
+	campaignCancel()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, standby.CheckLeadership(context.Background()), ErrNotLeader)
+
+	// the leader releases the lock once stopped, and the standby takes over.
+	cancel()
+	<-leaderCtx.Done()
+	assert.Eventually(t, func() bool {
+		return leader.CheckLeadership(context.Background()) != nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	standbyCtx, standbyCancel := context.WithCancel(context.Background())
+	defer standbyCancel()
+	_, err = standby.Campaign(standbyCtx)
+	assert.NoError(t, err)
+	assert.NoError(t, standby.CheckLeadership(context.Background()))
+}
+
+func TestLeaderElectorLostLock(t *testing.T) {
+	leader, standby := newTestLeaderElector(1002), newTestLeaderElector(1002)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	leaderCtx, err := leader.Campaign(ctx)
+	assert.NoError(t, err)
+
+	// terminating the backend holding the lock releases it, the leader must neither keep writing nor keep running.
+	var terminated bool
+	err = db.Raw(`SELECT pg_terminate_backend(pid) FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND objsubid = 1 AND ((classid::BIGINT << 32) | objid::BIGINT) = ?`, int64(1002)).Scan(&terminated).Error
+	assert.NoError(t, err)
+	assert.True(t, terminated)
+
+	assert.Error(t, leader.CheckLeadership(context.Background()))
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the leader context is not canceled after the lock is lost")
+	}
+
+	standbyCtx, standbyCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer standbyCancel()
+	_, err = standby.Campaign(standbyCtx)
+	assert.NoError(t, err)
+	assert.NoError(t, standby.CheckLeadership(context.Background()))
+	assert.Error(t, leader.CheckLeadership(context.Background()))
+}
+
+func TestSoleInstance(t *testing.T) {
+	assert.NoError(t, SoleInstance.CheckLeadership(context.Background()))
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/metrics"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// partitionMaintenanceInterval is the interval between two partition maintenance runs.
+const partitionMaintenanceInterval = time.Hour
+
+// PartitionMaintainer creates the cross_message_v2 message nonce range partitions ahead of the indexed messages and keeps
+// the planner statistics fresh, so that queries filtered by message_type and message_nonce keep pruning to a single partition.
+type PartitionMaintainer struct {
+	ctx          context.Context
+	partitionOrm *orm.Partition
+
+	partitionMaintainerRunningTotal prometheus.Counter
+	partitionEstimatedRows          *prometheus.GaugeVec
+	partitionTotalSizeBytes         *prometheus.GaugeVec
+}
+
+// NewPartitionMaintainer creates a new PartitionMaintainer instance.
+func NewPartitionMaintainer(ctx context.Context, db *gorm.DB) *PartitionMaintainer {
+	m := &PartitionMaintainer{
+		ctx:          ctx,
+		partitionOrm: orm.NewPartition(db),
+	}
+
+	reg := metrics.Registerer()
+	m.partitionMaintainerRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "cross_message_partition_maintainer_running_total",
+		Help: "Total count of cross message partition maintenance runs.",
+	})
+	m.partitionEstimatedRows = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cross_message_partition_estimated_rows",
+		Help: "Estimated row count of each cross message partition.",
+	}, []string{"partition"})
+	m.partitionTotalSizeBytes = promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cross_message_partition_total_size_bytes",
+		Help: "Total size in bytes (including indexes) of each cross message partition.",
+	}, []string{"partition"})
+
+	return m
+}
+
+// Start starts the partition maintenance loop.
+func (m *PartitionMaintainer) Start() {
+	m.maintain()
+
+	tick := time.NewTicker(partitionMaintenanceInterval)
+	go func() {
+		for {
+			select {
+			case <-m.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				m.maintain()
+			}
+		}
+	}()
+}
+
+func (m *PartitionMaintainer) maintain() {
+	m.partitionMaintainerRunningTotal.Inc()
+
+	if err := m.partitionOrm.EnsureCrossMessagePartitions(m.ctx); err != nil {
+		log.Error("failed to ensure cross message partitions", "err", err)
+		return
+	}
+
+	if err := m.partitionOrm.AnalyzeCrossMessagePartitions(m.ctx); err != nil {
+		log.Error("failed to analyze cross message partitions", "err", err)
+		return
+	}
+
+	stats, err := m.partitionOrm.GetCrossMessagePartitionStats(m.ctx)
+	if err != nil {
+		log.Error("failed to get cross message partition stats", "err", err)
+		return
+	}
+	for _, stat := range stats {
+		m.partitionEstimatedRows.WithLabelValues(stat.Name).Set(float64(stat.EstimatedRows))
+		m.partitionTotalSizeBytes.WithLabelValues(stat.Name).Set(float64(stat.TotalSizeBytes))
+	}
+}
@@ -0,0 +1,133 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/clock"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	defaultPendingDepositInterval = 5 * time.Second
+	defaultPendingDepositExpiry   = time.Hour
+)
+
+// PendingDepositWatcher records the deposits in the txpool of an L1 node into pending_deposit, and removes them once
+// they are indexed by the L1 fetcher, or once they left the txpool for longer than the expiry without being indexed.
+type PendingDepositWatcher struct {
+	ctx            context.Context
+	clock          clock.Clock
+	logic          *logic.PendingDepositLogic
+	pendingDeposit *orm.PendingDeposit
+	leadership     LeadershipChecker // checked before each write
+
+	interval time.Duration
+	expiry   time.Duration
+
+	pendingDepositWatcherRunningTotal prometheus.Counter
+	pendingDepositWatcherFailureTotal prometheus.Counter
+	pendingDepositSeen                prometheus.Gauge
+	pendingDepositRemovedTotal        *prometheus.CounterVec
+}
+
+// NewPendingDepositWatcher creates a new PendingDepositWatcher instance, scheduled on clk.
+func NewPendingDepositWatcher(ctx context.Context, cfg *config.PendingDepositsConfig, l1Cfg *config.FetcherConfig, db *gorm.DB, client logic.TxPoolCaller, leadership LeadershipChecker, clk clock.Clock, reg prometheus.Registerer) *PendingDepositWatcher {
+	w := &PendingDepositWatcher{
+		ctx:            ctx,
+		clock:          clk,
+		logic:          logic.NewPendingDepositLogic(l1Cfg, client),
+		pendingDeposit: orm.NewPendingDeposit(db),
+		leadership:     leadership,
+		interval:       defaultPendingDepositInterval,
+		expiry:         defaultPendingDepositExpiry,
+	}
+	if cfg.IntervalSec > 0 {
+		w.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.ExpireSec > 0 {
+		w.expiry = time.Duration(cfg.ExpireSec) * time.Second
+	}
+
+	w.pendingDepositWatcherRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "pending_deposit_watcher_running_total",
+		Help: "Total count of txpool reads of the pending deposit watcher.",
+	})
+	w.pendingDepositWatcherFailureTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "pending_deposit_watcher_failure_total",
+		Help: "Total count of failed txpool reads or writes of the pending deposit watcher.",
+	})
+	w.pendingDepositSeen = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "pending_deposit_seen",
+		Help: "The number of deposits in the txpool at the last read.",
+	})
+	w.pendingDepositRemovedTotal = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "pending_deposit_removed_total",
+		Help: "Total count of removed pending deposits, by reason: indexed or expired.",
+	}, []string{"reason"})
+
+	return w
+}
+
+// Start starts the txpool watching loop.
+func (w *PendingDepositWatcher) Start() {
+	tick := w.clock.NewTicker(w.interval)
+	go func() {
+		for {
+			select {
+			case <-w.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C():
+				w.watch()
+			}
+		}
+	}()
+}
+
+func (w *PendingDepositWatcher) watch() {
+	w.pendingDepositWatcherRunningTotal.Inc()
+
+	now := w.clock.Now().UTC()
+	deposits, err := w.logic.GetTxPoolDeposits(w.ctx, now)
+	if err != nil {
+		w.pendingDepositWatcherFailureTotal.Inc()
+		log.Warn("failed to read the deposits of the txpool", "err", err)
+		return
+	}
+	w.pendingDepositSeen.Set(float64(len(deposits)))
+
+	if err = w.leadership.CheckLeadership(w.ctx); err != nil {
+		log.Error("skip recording pending deposits, fetcher leadership check failed", "err", err)
+		return
+	}
+	if err = w.pendingDeposit.InsertOrUpdatePendingDeposits(w.ctx, deposits); err != nil {
+		w.pendingDepositWatcherFailureTotal.Inc()
+		log.Error("failed to record pending deposits", "err", err)
+		return
+	}
+
+	indexed, err := w.pendingDeposit.DeleteIndexedPendingDeposits(w.ctx)
+	if err != nil {
+		w.pendingDepositWatcherFailureTotal.Inc()
+		log.Error("failed to remove indexed pending deposits", "err", err)
+		return
+	}
+	w.pendingDepositRemovedTotal.WithLabelValues("indexed").Add(float64(indexed))
+
+	expired, err := w.pendingDeposit.DeletePendingDepositsSeenBefore(w.ctx, now.Add(-w.expiry))
+	if err != nil {
+		w.pendingDepositWatcherFailureTotal.Inc()
+		log.Error("failed to remove expired pending deposits", "err", err)
+		return
+	}
+	w.pendingDepositRemovedTotal.WithLabelValues("expired").Add(float64(expired))
+}
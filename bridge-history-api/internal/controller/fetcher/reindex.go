@@ -0,0 +1,90 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// Reindexer runs a full reindex: the L1 and L2 events are fetched into the tables of orm.ReindexSchema and loaded
+// with COPY, then the reindexed tables replace the live ones at once. The api keeps serving the live tables meanwhile.
+// An interrupted reindex resumes from the heights synced in the reindex schema.
+type Reindexer struct {
+	ctx        context.Context
+	cfg        *config.Config
+	db         *gorm.DB // the live tables
+	reindexDB  *gorm.DB // the tables of the reindex schema, see orm.ReindexSchema
+	l1Client   *ethclient.Client
+	l2Client   *ethclient.Client
+	leadership LeadershipChecker
+}
+
+// NewReindexer creates a new Reindexer instance, reindexDB must have orm.ReindexSchema as its search path and be
+// migrated.
+func NewReindexer(ctx context.Context, cfg *config.Config, db, reindexDB *gorm.DB, l1Client, l2Client *ethclient.Client, leadership LeadershipChecker) *Reindexer {
+	return &Reindexer{
+		ctx:        ctx,
+		cfg:        cfg,
+		db:         db,
+		reindexDB:  reindexDB,
+		l1Client:   l1Client,
+		l2Client:   l2Client,
+		leadership: leadership,
+	}
+}
+
+// Run reindexes the events up to the confirmed L1 and L2 heads and swaps the reindexed tables in. The fetchers must
+// not run meanwhile, the events they index after the reindex reaches the heads would be lost with the live tables.
+func (r *Reindexer) Run() error {
+	start := time.Now()
+	l1Loader := orm.NewBulkLoader(r.reindexDB)
+	if err := l1Loader.CreateRelayedMessageStage(r.ctx); err != nil {
+		return err
+	}
+	l2Loader := orm.NewBulkLoader(r.reindexDB)
+	l2Loader.SetMessageDataOffloadThreshold(r.cfg.L2.MessageDataOffloadThreshold)
+
+	l1MessageFetcher := NewL1MessageFetcher(r.ctx, r.cfg.L1, r.reindexDB, r.l1Client, r.leadership)
+	l1MessageFetcher.SetBulkLoader(l1Loader)
+	l2MessageFetcher := NewL2MessageFetcher(r.ctx, r.cfg.L2, r.reindexDB, r.l2Client, r.leadership)
+	if r.cfg.L1.ClaimAfterFinality {
+		l2MessageFetcher.SetL1FinalityGate(r.l1Client)
+	}
+	l2MessageFetcher.SetBulkLoader(l2Loader)
+
+	// the relays on L1 wait for the L2 withdrawals they relay to be indexed, see fillRelayedMessageNonces.
+	var eg errgroup.Group
+	eg.Go(l1MessageFetcher.CatchUp)
+	eg.Go(l2MessageFetcher.CatchUp)
+	if err := eg.Wait(); err != nil {
+		return fmt.Errorf("failed to reindex the events, error: %w", err)
+	}
+	log.Info("reindexed the events", "L1 height", l1MessageFetcher.watcher.Cursor().Height, "L2 height", l2MessageFetcher.watcher.Cursor().Height, "duration", time.Since(start))
+
+	// the L2 fetcher finalizes the withdrawals up to the height synced before its last range, the L1 fetcher may
+	// have indexed the batches finalizing the following ones since.
+	if err := l2MessageFetcher.eventUpdateLogic.UpdateL1BatchIndexAndStatus(r.ctx, l2MessageFetcher.watcher.Cursor().Height); err != nil {
+		return fmt.Errorf("failed to update L1 batch index and status, error: %w", err)
+	}
+	if err := l1Loader.ApplyStagedRelayedMessages(r.ctx); err != nil {
+		return err
+	}
+
+	if err := r.leadership.CheckLeadership(r.ctx); err != nil {
+		return fmt.Errorf("failed to check leadership before swapping the reindexed tables, error: %w", err)
+	}
+	tables, err := orm.SwapReindexedTables(r.ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to swap the reindexed tables, error: %w", err)
+	}
+	log.Info("swapped the reindexed tables in, drop the replaced ones once verified", "tables", tables, "schema", orm.PreReindexSchema, "duration", time.Since(start))
+	return nil
+}
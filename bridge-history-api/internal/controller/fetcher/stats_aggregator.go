@@ -0,0 +1,192 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	defaultStatsAggregationInterval = 5 * time.Minute
+	defaultStatsRecomputeDays       = 2
+	// statsAggregationChunkDays bounds the days aggregated per transaction, e.g. while backfilling the whole history.
+	statsAggregationChunkDays = 30
+
+	oneDay = 24 * time.Hour
+)
+
+// StatsAggregator aggregates the indexed messages into the daily stats tables served by the stats apis. Each run
+// recomputes the last days up to the progress of the slowest fetcher, and the days after it, so that the days the
+// fetchers are still indexing are recomputed until complete. The whole history is aggregated by the first run.
+type StatsAggregator struct {
+	ctx          context.Context
+	crossMessage *orm.CrossMessage
+	bridgeStats  *orm.BridgeStats
+	leadership   LeadershipChecker // checked before each aggregation transaction
+
+	interval      time.Duration
+	recomputeDays int
+	gatewayAddrs  []string // addresses of the L1 and L2 gateways, other message senders are the messenger
+
+	statsAggregatorRunningTotal     prometheus.Counter
+	statsAggregatorFailureTotal     prometheus.Counter
+	statsAggregatorLastDayTimestamp prometheus.Gauge
+	statsAggregatorDurationSeconds  prometheus.Histogram
+}
+
+// NewStatsAggregator creates a new StatsAggregator instance.
+func NewStatsAggregator(ctx context.Context, cfg *config.StatsConfig, l1Cfg, l2Cfg *config.FetcherConfig, db *gorm.DB, leadership LeadershipChecker, reg prometheus.Registerer) *StatsAggregator {
+	a := &StatsAggregator{
+		ctx:           ctx,
+		crossMessage:  orm.NewCrossMessage(db),
+		bridgeStats:   orm.NewBridgeStats(db),
+		leadership:    leadership,
+		interval:      defaultStatsAggregationInterval,
+		recomputeDays: defaultStatsRecomputeDays,
+	}
+	if cfg.IntervalSec > 0 {
+		a.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.RecomputeDays > 0 {
+		a.recomputeDays = int(cfg.RecomputeDays)
+	}
+	for _, gateways := range []map[string]string{l1Cfg.Gateways(), l2Cfg.Gateways()} {
+		for addr := range gateways {
+			a.gatewayAddrs = append(a.gatewayAddrs, addr)
+		}
+	}
+
+	a.statsAggregatorRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "stats_aggregator_running_total",
+		Help: "Total count of daily stats aggregation runs.",
+	})
+	a.statsAggregatorFailureTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name: "stats_aggregator_failure_total",
+		Help: "Total count of failed daily stats aggregation runs.",
+	})
+	a.statsAggregatorLastDayTimestamp = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "stats_aggregator_last_day_timestamp",
+		Help: "The timestamp of the last aggregated day.",
+	})
+	a.statsAggregatorDurationSeconds = promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+		Name:    "stats_aggregator_duration_seconds",
+		Help:    "The duration of the daily stats aggregation runs.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	return a
+}
+
+// Start aggregates the stats once, then periodically in the background until the context is done.
+func (a *StatsAggregator) Start() {
+	a.aggregate()
+
+	tick := time.NewTicker(a.interval)
+	go func() {
+		for {
+			select {
+			case <-a.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				a.aggregate()
+			}
+		}
+	}()
+}
+
+func (a *StatsAggregator) aggregate() {
+	a.statsAggregatorRunningTotal.Inc()
+	start := time.Now()
+	defer func() { a.statsAggregatorDurationSeconds.Observe(time.Since(start).Seconds()) }()
+
+	firstL1, lastL1, err := a.crossMessage.GetSentBlockTimestampRange(a.ctx, orm.MessageTypeL1SentMessage)
+	if err != nil {
+		a.statsAggregatorFailureTotal.Inc()
+		log.Error("failed to get the indexed deposits range", "err", err)
+		return
+	}
+	firstL2, lastL2, err := a.crossMessage.GetSentBlockTimestampRange(a.ctx, orm.MessageTypeL2SentMessage)
+	if err != nil {
+		a.statsAggregatorFailureTotal.Inc()
+		log.Error("failed to get the indexed withdrawals range", "err", err)
+		return
+	}
+	lastAggregatedDay, err := a.bridgeStats.GetLastAggregatedDay(a.ctx)
+	if err != nil {
+		a.statsAggregatorFailureTotal.Inc()
+		log.Error("failed to get the last aggregated day", "err", err)
+		return
+	}
+
+	from, to, ok := statsAggregationRange(firstL1, lastL1, firstL2, lastL2, lastAggregatedDay, a.recomputeDays)
+	if !ok {
+		return
+	}
+	for chunkFrom := from; chunkFrom.Before(to); chunkFrom = chunkFrom.Add(statsAggregationChunkDays * oneDay) {
+		chunkTo := chunkFrom.Add(statsAggregationChunkDays * oneDay)
+		if chunkTo.After(to) {
+			chunkTo = to
+		}
+		if err = a.leadership.CheckLeadership(a.ctx); err != nil {
+			log.Error("skip aggregating daily stats, fetcher leadership check failed", "err", err)
+			return
+		}
+		if err = a.bridgeStats.AggregateDays(a.ctx, chunkFrom, chunkTo, a.gatewayAddrs); err != nil {
+			a.statsAggregatorFailureTotal.Inc()
+			log.Error("failed to aggregate daily stats", "from", chunkFrom, "to", chunkTo, "err", err)
+			return
+		}
+	}
+	a.statsAggregatorLastDayTimestamp.Set(float64(to.Add(-oneDay).Unix()))
+	log.Debug("aggregated daily stats", "from", from, "to", to)
+}
+
+// statsAggregationRange returns the UTC days [from, to) to aggregate given the block timestamp ranges of the indexed
+// deposits and withdrawals, 0 if none, and the last aggregated day. The recomputed days start recomputeDays before the
+// last aggregated day or the day of the progress of the slowest fetcher, whichever is earlier, as messages of these
+// days may have been indexed since. ok is false if no message is indexed.
+func statsAggregationRange(firstL1, lastL1, firstL2, lastL2 uint64, lastAggregatedDay time.Time, recomputeDays int) (from, to time.Time, ok bool) {
+	var first, progress, last uint64
+	for _, r := range [][2]uint64{{firstL1, lastL1}, {firstL2, lastL2}} {
+		if r[1] == 0 {
+			continue
+		}
+		if first == 0 || r[0] < first {
+			first = r[0]
+		}
+		if progress == 0 || r[1] < progress {
+			progress = r[1]
+		}
+		last = max(last, r[1])
+	}
+	if last == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	to = unixDay(last).Add(oneDay)
+	if lastAggregatedDay.IsZero() {
+		return unixDay(first), to, true
+	}
+	from = time.Date(lastAggregatedDay.Year(), lastAggregatedDay.Month(), lastAggregatedDay.Day(), 0, 0, 0, 0, time.UTC)
+	if progressDay := unixDay(progress); progressDay.Before(from) {
+		from = progressDay
+	}
+	from = from.Add(-time.Duration(recomputeDays-1) * oneDay)
+	if firstDay := unixDay(first); from.Before(firstDay) {
+		from = firstDay
+	}
+	return from, to, true
+}
+
+// unixDay returns the start of the UTC day of a unix timestamp.
+func unixDay(timestamp uint64) time.Time {
+	return time.Unix(int64(timestamp), 0).UTC().Truncate(oneDay)
+}
@@ -0,0 +1,37 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsAggregationRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	at := func(d int, hour int) uint64 { return uint64(day(d).Add(time.Duration(hour) * time.Hour).Unix()) }
+
+	// nothing indexed.
+	_, _, ok := statsAggregationRange(0, 0, 0, 0, time.Time{}, 2)
+	assert.False(t, ok)
+
+	// the first run aggregates the whole history.
+	from, to, ok := statsAggregationRange(at(3, 5), at(10, 1), at(2, 23), at(9, 12), time.Time{}, 2)
+	assert.True(t, ok)
+	assert.Equal(t, day(2), from)
+	assert.Equal(t, day(11), to)
+
+	// the next runs recompute the days before the last aggregated day, or before the progress of the slower fetcher.
+	from, to, ok = statsAggregationRange(at(3, 5), at(12, 1), at(2, 23), at(11, 12), day(10), 2)
+	assert.True(t, ok)
+	assert.Equal(t, day(9), from)
+	assert.Equal(t, day(13), to)
+	from, _, _ = statsAggregationRange(at(3, 5), at(12, 1), at(2, 23), at(6, 12), day(10), 2)
+	assert.Equal(t, day(5), from)
+
+	// a direction without messages does not hold back the aggregation, the history bounds the recomputed days.
+	from, to, ok = statsAggregationRange(at(3, 5), at(4, 1), 0, 0, day(4), 7)
+	assert.True(t, ok)
+	assert.Equal(t, day(3), from)
+	assert.Equal(t, day(5), to)
+}
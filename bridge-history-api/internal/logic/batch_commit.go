@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/codec"
+	"scroll-tech/common/types/encoding"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/utils"
+)
+
+// fillBatchCommitInfo decodes the commit tx of a committed batch into its L2 block range and codec version. A tx of an
+// unknown codec version or method, or a malformed one, does not stop the fetcher: the batch is saved with the parse
+// status and without a block range, so that the withdrawals are not finalized by it.
+func fillBatchCommitInfo(batch *orm.BatchEvent, commitTx *types.Transaction) {
+	batch.CommitTxHash = commitTx.Hash().String()
+	batch.StartBlockNumber, batch.EndBlockNumber = 0, 0
+	batch.BlobVersionedHash = ""
+
+	info, err := utils.ParseCommitBatchCalldata(commitTx.Data())
+	if err == nil {
+		err = checkBatchBlob(info, commitTx.BlobHashes())
+	}
+	switch {
+	case err == nil:
+		batch.ParseStatus = int(orm.BatchParseStatusTypeParsed)
+	case errors.Is(err, codec.ErrUnsupportedVersion) || errors.Is(err, utils.ErrUnsupportedCommitBatch):
+		log.Warn("unsupported commit batch tx, the batch is parsed again once supported", "index", batch.BatchIndex, "hash", batch.CommitTxHash, "err", err)
+		batch.ParseStatus = int(orm.BatchParseStatusTypeUnsupported)
+		return
+	default:
+		log.Error("invalid commit batch tx", "index", batch.BatchIndex, "hash", batch.CommitTxHash, "err", err)
+		batch.ParseStatus = int(orm.BatchParseStatusTypeInvalid)
+		return
+	}
+
+	batch.StartBlockNumber = info.StartBlockNumber
+	batch.EndBlockNumber = info.EndBlockNumber
+	batch.CodecVersion = int(info.Version)
+	if blobHashes := commitTx.BlobHashes(); len(blobHashes) > 0 {
+		batch.BlobVersionedHash = blobHashes[0].String()
+	}
+}
+
+// checkBatchBlob checks that the commit tx carries the blob of the payload of the batch since codec v1.
+func checkBatchBlob(info *utils.CommitBatchInfo, blobHashes []common.Hash) error {
+	if info.Version < encoding.CodecV1 {
+		if len(blobHashes) > 0 {
+			return fmt.Errorf("%w: %d blobs in a commit tx of codec version %d", codec.ErrInvalidData, len(blobHashes), info.Version)
+		}
+		return nil
+	}
+	if len(blobHashes) != 1 {
+		return fmt.Errorf("%w: %d blobs in a commit tx of codec version %d, expected 1", codec.ErrInvalidData, len(blobHashes), info.Version)
+	}
+	return nil
+}
+
+// ReparseBatchEvents parses again the commit txs of the batches of an unsupported codec version or method, e.g. after
+// an upgrade of the fetcher supporting a new codec version.
+func (f *L1FetcherLogic) ReparseBatchEvents(ctx context.Context) error {
+	batches, err := f.batchEventOrm.GetUnparsedBatchEvents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		if batch.CommitTxHash == "" {
+			continue
+		}
+		commitTx, isPending, err := f.client.TransactionByHash(ctx, common.HexToHash(batch.CommitTxHash))
+		if err != nil || isPending {
+			return fmt.Errorf("failed to get commit batch tx, hash: %s, isPending: %v, error: %w", batch.CommitTxHash, isPending, err)
+		}
+		fillBatchCommitInfo(batch, commitTx)
+		if orm.BatchParseStatusType(batch.ParseStatus) == orm.BatchParseStatusTypeUnsupported {
+			continue
+		}
+		if err := f.batchEventOrm.UpdateBatchEventParseResult(ctx, batch); err != nil {
+			return err
+		}
+		log.Info("parsed the commit tx of a batch again", "index", batch.BatchIndex, "start", batch.StartBlockNumber, "end", batch.EndBlockNumber, "parse status", batch.ParseStatus)
+	}
+	return nil
+}
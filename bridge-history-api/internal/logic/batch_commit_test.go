@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// commitBatchCalldata is a commitBatch of a chunk of block 1 without L2 txs, of codec version %x.
+const commitBatchCalldata = "1325aca000000000000000000000000000000000000000000000000000000000000000%02x0000000000000000000000000000000000000000000000000000000000000080000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000005900000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000003d0100000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000100000000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000"
+
+func TestFillBatchCommitInfo(t *testing.T) {
+	calldata := func(version uint8) []byte {
+		return common.Hex2Bytes(fmt.Sprintf(commitBatchCalldata, version))
+	}
+	legacyTx := func(data []byte) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{Data: data})
+	}
+	blobTx := func(data []byte, blobHashes ...common.Hash) *types.Transaction {
+		return types.NewTx(&types.BlobTx{
+			ChainID:    uint256.NewInt(1),
+			GasTipCap:  uint256.NewInt(0),
+			GasFeeCap:  uint256.NewInt(0),
+			Value:      uint256.NewInt(0),
+			Data:       data,
+			BlobFeeCap: uint256.NewInt(0),
+			BlobHashes: blobHashes,
+		})
+	}
+
+	batch := &orm.BatchEvent{BatchIndex: 1}
+	tx := legacyTx(calldata(0))
+	fillBatchCommitInfo(batch, tx)
+	assert.Equal(t, int(orm.BatchParseStatusTypeParsed), batch.ParseStatus)
+	assert.Equal(t, tx.Hash().String(), batch.CommitTxHash)
+	assert.Equal(t, uint64(1), batch.StartBlockNumber)
+	assert.Equal(t, uint64(1), batch.EndBlockNumber)
+	assert.Equal(t, 0, batch.CodecVersion)
+	assert.Empty(t, batch.BlobVersionedHash)
+
+	// the block contexts stay in the calldata since codec v1, the payload is in the blob of the tx.
+	blobHash := common.HexToHash("0x01aa")
+	batch = &orm.BatchEvent{BatchIndex: 1}
+	fillBatchCommitInfo(batch, blobTx(calldata(1), blobHash))
+	assert.Equal(t, int(orm.BatchParseStatusTypeParsed), batch.ParseStatus)
+	assert.Equal(t, uint64(1), batch.StartBlockNumber)
+	assert.Equal(t, uint64(1), batch.EndBlockNumber)
+	assert.Equal(t, 1, batch.CodecVersion)
+	assert.Equal(t, blobHash.String(), batch.BlobVersionedHash)
+
+	// a codec v1 batch without its blob, or a codec v0 batch with one, is invalid.
+	fillBatchCommitInfo(batch, legacyTx(calldata(1)))
+	assert.Equal(t, int(orm.BatchParseStatusTypeInvalid), batch.ParseStatus)
+	assert.Zero(t, batch.EndBlockNumber)
+	assert.Empty(t, batch.BlobVersionedHash)
+	fillBatchCommitInfo(batch, blobTx(calldata(0), blobHash))
+	assert.Equal(t, int(orm.BatchParseStatusTypeInvalid), batch.ParseStatus)
+
+	// unknown codec versions and methods are saved to be parsed again.
+	fillBatchCommitInfo(batch, blobTx(calldata(9), blobHash))
+	assert.Equal(t, int(orm.BatchParseStatusTypeUnsupported), batch.ParseStatus)
+	assert.Zero(t, batch.StartBlockNumber)
+	assert.Zero(t, batch.EndBlockNumber)
+	fillBatchCommitInfo(batch, legacyTx(common.Hex2Bytes("deadbeef")))
+	assert.Equal(t, int(orm.BatchParseStatusTypeUnsupported), batch.ParseStatus)
+	fillBatchCommitInfo(batch, legacyTx(nil))
+	assert.Equal(t, int(orm.BatchParseStatusTypeUnsupported), batch.ParseStatus)
+
+	// malformed chunks are invalid, e.g. of a block with more L1 messages than txs.
+	data := calldata(0)
+	data[4+11*32+60] = 2
+	fillBatchCommitInfo(batch, legacyTx(data))
+	assert.Equal(t, int(orm.BatchParseStatusTypeInvalid), batch.ParseStatus)
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// GetBatchVersion returns the version of the batches, which changes whenever a batch is committed or finalized. It
+// is added to the version of the txs of an address when their batches are included, the batches change without
+// the txs changing.
+func (h *HistoryLogic) GetBatchVersion(ctx context.Context) (string, error) {
+	syncedHeight, err := h.batchEventOrm.GetBatchEventSyncedHeightInDB(ctx)
+	if err != nil {
+		log.Error("failed to get batch synced height", "error", err)
+		return "", err
+	}
+	return strconv.FormatUint(syncedHeight, 10), nil
+}
+
+// FillBatches sets the batch of the layer 2 messages of committed batches, with one query for all txs. It is applied
+// to the responses after caching, so the batches of cached txs stay current.
+func (h *HistoryLogic) FillBatches(ctx context.Context, txs []*types.TxHistoryInfo) error {
+	var blockNumbers []uint64
+	seen := make(map[uint64]bool)
+	for _, tx := range txs {
+		if tx.MessageType == orm.MessageTypeL2SentMessage && !seen[tx.BlockNumber] {
+			seen[tx.BlockNumber] = true
+			blockNumbers = append(blockNumbers, tx.BlockNumber)
+		}
+	}
+	batches, err := h.batchEventOrm.GetBatchesByL2BlockNumbers(ctx, blockNumbers)
+	if err != nil {
+		log.Error("failed to get batches by L2 block numbers", "error", err)
+		return err
+	}
+	for _, tx := range txs {
+		if batch, ok := batches[tx.BlockNumber]; ok && tx.MessageType == orm.MessageTypeL2SentMessage {
+			tx.Batch = getBatchInfo(batch)
+		}
+	}
+	return nil
+}
+
+func getBatchInfo(batch *orm.BatchEvent) *types.BatchInfo {
+	batchInfo := &types.BatchInfo{
+		BatchIndex:      batch.BatchIndex,
+		BatchHash:       batch.BatchHash,
+		BatchStatus:     batch.BatchStatus,
+		CommitTxHash:    batch.CommitTxHash,
+		CommitTimestamp: batch.CommitBlockTimestamp,
+	}
+	if orm.BatchStatusType(batch.BatchStatus) == orm.BatchStatusTypeFinalized {
+		batchInfo.FinalizeTxHash = batch.FinalizeTxHash
+		batchInfo.FinalizeTimestamp = batch.FinalizeBlockTimestamp
+	}
+	return batchInfo
+}
@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// fillClaimSenders sets the claimed_by of L2 withdrawals relayed on L1 to the sender of their relay tx, i.e., the
+// user itself or a relayer claiming on the user's behalf. The relay txs are part of the fetched blocks.
+func fillClaimSenders(blocks []*types.Block, l1RelayedMessages []*orm.CrossMessage) error {
+	if len(l1RelayedMessages) == 0 {
+		return nil
+	}
+
+	txs := make(map[string]*types.Transaction)
+	for _, block := range blocks {
+		for _, tx := range block.Transactions() {
+			txs[tx.Hash().String()] = tx
+		}
+	}
+
+	for _, message := range l1RelayedMessages {
+		tx, ok := txs[message.L1TxHash]
+		if !ok {
+			continue
+		}
+		signer := types.LatestSignerForChainID(new(big.Int).SetUint64(tx.ChainId().Uint64()))
+		sender, err := signer.Sender(tx)
+		if err != nil {
+			return fmt.Errorf("failed to get sender of claim tx, tx hash: %v, error: %w", message.L1TxHash, err)
+		}
+		message.ClaimedBy = sender.String()
+	}
+	return nil
+}
+
+// isSelfClaimed returns whether the withdrawal was claimed by its sender or receiver, rather than by a relayer.
+func isSelfClaimed(message *orm.CrossMessage) bool {
+	return strings.EqualFold(message.ClaimedBy, message.Sender) || strings.EqualFold(message.ClaimedBy, message.Receiver)
+}
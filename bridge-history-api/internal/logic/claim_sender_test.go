@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestFillClaimSenders(t *testing.T) {
+	relayerKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	relayer := crypto.PubkeyToAddress(relayerKey.PublicKey)
+	user := common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(relayerKey, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       400000,
+		To:        &common.Address{},
+	})
+	assert.NoError(t, err)
+	blocks := []*types.Block{types.NewBlockWithHeader(&types.Header{Number: big.NewInt(100)}).WithBody([]*types.Transaction{tx}, nil)}
+
+	relayed := &orm.CrossMessage{L1TxHash: tx.Hash().String(), Sender: user.String(), Receiver: user.String()}
+	// not part of the fetched blocks, left unset.
+	unknown := &orm.CrossMessage{L1TxHash: common.HexToHash("0x01").String()}
+	assert.NoError(t, fillClaimSenders(blocks, []*orm.CrossMessage{relayed, unknown}))
+	assert.Equal(t, relayer.String(), relayed.ClaimedBy)
+	assert.Empty(t, unknown.ClaimedBy)
+
+	assert.False(t, isSelfClaimed(relayed))
+	relayed.Receiver = relayer.Hex()
+	assert.True(t, isSelfClaimed(relayed))
+}
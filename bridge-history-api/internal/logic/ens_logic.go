@@ -0,0 +1,227 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"golang.org/x/sync/singleflight"
+
+	"scroll-tech/common/concurrency"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/types"
+	"scroll-tech/bridge-history-api/internal/utils"
+)
+
+const (
+	cacheKeyPrefixENSName = cacheKeyPrefixBridgeHistory + "ensName:"
+
+	// defaultENSRegistryAddr is the ENS registry address, deployed at the same address on Ethereum mainnet and testnets.
+	defaultENSRegistryAddr = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+	// defaultENSCacheExpiredTime is the default expiry of cached ENS names, including negative results.
+	defaultENSCacheExpiredTime = 1 * time.Hour
+	// ensLookupTimeout bounds the RPC calls of a single reverse lookup, which outlives the request that started it
+	// so its result is cached for the next requests.
+	ensLookupTimeout = 3 * time.Second
+	// ensRequestTimeout bounds the time FillENSNames adds to an API response, names not resolved by then are left empty.
+	ensRequestTimeout = 2 * time.Second
+	// ensLookupConcurrency is the max number of reverse lookups running at a time for a single request.
+	ensLookupConcurrency = 8
+)
+
+// ENSLogic resolves the primary ENS names of addresses through an L1 node.
+type ENSLogic struct {
+	client           *ethclient.Client
+	registryAddr     common.Address
+	redis            *redis.Client
+	cacheExpiredTime time.Duration
+	singleFlight     singleflight.Group
+}
+
+// NewENSLogic returns ENS resolution services.
+func NewENSLogic(cfg *config.ENSConfig, client *ethclient.Client, redis *redis.Client) *ENSLogic {
+	registryAddr := defaultENSRegistryAddr
+	if cfg.RegistryAddr != "" {
+		registryAddr = cfg.RegistryAddr
+	}
+	cacheExpiredTime := defaultENSCacheExpiredTime
+	if cfg.CacheExpireSec > 0 {
+		cacheExpiredTime = time.Duration(cfg.CacheExpireSec) * time.Second
+	}
+	return &ENSLogic{
+		client:           client,
+		registryAddr:     common.HexToAddress(registryAddr),
+		redis:            redis,
+		cacheExpiredTime: cacheExpiredTime,
+	}
+}
+
+// FillENSNames sets the sender and receiver ENS names of the given txs.
+// Each distinct address is looked up once, at most ensLookupConcurrency at a time, and the whole resolution is
+// bounded by ensRequestTimeout. Resolution is best effort: a failed or late lookup leaves the name empty and never
+// fails the request.
+func (e *ENSLogic) FillENSNames(ctx context.Context, txs []*types.TxHistoryInfo) {
+	ctx, cancel := context.WithTimeout(ctx, ensRequestTimeout)
+	defer cancel()
+
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		for _, address := range []string{tx.Sender, tx.Receiver} {
+			if address == "" || !common.IsHexAddress(address) || seen[address] {
+				continue
+			}
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	var mu sync.Mutex
+	names := make(map[string]string, len(addresses))
+	pool := concurrency.NewPool(ensLookupConcurrency)
+	for _, address := range addresses {
+		address := address
+		err := pool.Go(ctx, func() {
+			name, err := e.LookupAddress(ctx, common.HexToAddress(address))
+			if err != nil {
+				log.Warn("failed to lookup ENS name", "address", address, "err", err)
+				return
+			}
+			mu.Lock()
+			names[address] = name
+			mu.Unlock()
+		})
+		if err != nil {
+			log.Warn("ENS resolution timed out", "resolved", len(names), "addresses", len(addresses))
+			break
+		}
+	}
+	pool.Wait()
+
+	for _, tx := range txs {
+		tx.SenderENSName = names[tx.Sender]
+		tx.ReceiverENSName = names[tx.Receiver]
+	}
+}
+
+// LookupAddress returns the verified primary ENS name of an address, or an empty string if it has none.
+// Concurrent lookups of an address share a single resolution, which runs detached from the callers' contexts, so a
+// caller giving up does not fail the others, and the resolved name is still cached.
+func (e *ENSLogic) LookupAddress(ctx context.Context, addr common.Address) (string, error) {
+	cacheKey := cacheKeyPrefixENSName + addr.Hex()
+	name, err := e.redis.Get(ctx, cacheKey).Result()
+	if err == nil {
+		return name, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		log.Error("failed to get data from Redis", "error", err)
+	}
+
+	resultCh := e.singleFlight.DoChan(cacheKey, func() (interface{}, error) {
+		lookupCtx, cancel := context.WithTimeout(context.Background(), ensLookupTimeout)
+		defer cancel()
+		name, err := e.reverseResolve(lookupCtx, addr)
+		if err != nil {
+			return "", err
+		}
+		// Cache empty names as well, most addresses have no primary name.
+		if cacheErr := e.redis.Set(lookupCtx, cacheKey, name, e.cacheExpiredTime).Err(); cacheErr != nil {
+			log.Error("failed to set data to Redis", "error", cacheErr)
+		}
+		return name, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		name, ok := result.Val.(string)
+		if !ok {
+			return "", errors.New("unexpected error")
+		}
+		return name, nil
+	}
+}
+
+// reverseResolve looks up the reverse record of addr and checks that the name resolves back to addr,
+// as reverse records can be set to arbitrary names by their owners.
+func (e *ENSLogic) reverseResolve(ctx context.Context, addr common.Address) (string, error) {
+	reverseNode := utils.ENSReverseNode(addr)
+	resolver, err := e.getResolver(ctx, reverseNode)
+	if err != nil {
+		return "", err
+	}
+	if resolver == (common.Address{}) {
+		return "", nil
+	}
+
+	var name string
+	if err = e.call(ctx, backendabi.IENSResolverABI, resolver, "name", &name, reverseNode); err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", nil
+	}
+	// only names stored in their normalized form are shown, others may be spoofs of the name they render as.
+	normalized, err := utils.ENSNormalize(name)
+	if err != nil || normalized != name {
+		return "", nil
+	}
+
+	forwardNode := utils.ENSNameHash(name)
+	forwardResolver, err := e.getResolver(ctx, forwardNode)
+	if err != nil {
+		return "", err
+	}
+	if forwardResolver == (common.Address{}) {
+		return "", nil
+	}
+
+	var resolvedAddr common.Address
+	if err = e.call(ctx, backendabi.IENSResolverABI, forwardResolver, "addr", &resolvedAddr, forwardNode); err != nil {
+		return "", err
+	}
+	if resolvedAddr != addr {
+		return "", nil
+	}
+	return name, nil
+}
+
+func (e *ENSLogic) getResolver(ctx context.Context, node common.Hash) (common.Address, error) {
+	var resolver common.Address
+	if err := e.call(ctx, backendabi.IENSRegistryABI, e.registryAddr, "resolver", &resolver, node); err != nil {
+		return common.Address{}, err
+	}
+	return resolver, nil
+}
+
+func (e *ENSLogic) call(ctx context.Context, contractABI *abi.ABI, to common.Address, method string, out interface{}, node common.Hash) error {
+	data, err := contractABI.Pack(method, node)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s, error: %w", method, err)
+	}
+	output, err := e.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s, contract: %s, error: %w", method, to.Hex(), err)
+	}
+	if len(output) == 0 {
+		return nil
+	}
+	if err = contractABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to unpack %s, error: %w", method, err)
+	}
+	return nil
+}
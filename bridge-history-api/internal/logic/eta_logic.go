@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/metrics"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+const (
+	defaultETARefreshInterval = 1 * time.Minute
+	defaultETASampleSize      = 100
+	// etaRefreshTimeout bounds the queries of a single refresh of the latency statistics.
+	etaRefreshTimeout = 30 * time.Second
+)
+
+// ETALogic maintains rolling statistics of the latest deposit relay and withdrawal finalization latencies,
+// and estimates the completion time of pending messages from them.
+type ETALogic struct {
+	crossMessageOrm *orm.CrossMessage
+	interval        time.Duration
+	sampleSize      int
+
+	mu                        sync.RWMutex
+	depositRelayLatency       time.Duration // zero if no relayed deposit was sampled yet
+	withdrawalFinalizeLatency time.Duration // zero if no finalized withdrawal was sampled yet
+
+	depositRelayLatencySeconds       prometheus.Gauge
+	withdrawalFinalizeLatencySeconds prometheus.Gauge
+}
+
+// NewETALogic returns completion time estimation services.
+func NewETALogic(cfg *config.ETAConfig, db *gorm.DB) *ETALogic {
+	e := &ETALogic{
+		crossMessageOrm: orm.NewCrossMessage(db),
+		interval:        defaultETARefreshInterval,
+		sampleSize:      defaultETASampleSize,
+	}
+	if cfg.IntervalSec > 0 {
+		e.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.SampleSize > 0 {
+		e.sampleSize = cfg.SampleSize
+	}
+
+	reg := metrics.Registerer()
+	e.depositRelayLatencySeconds = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "bridge_history_api_eta_deposit_relay_latency_seconds",
+		Help: "The median relay latency of the latest relayed deposits.",
+	})
+	e.withdrawalFinalizeLatencySeconds = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "bridge_history_api_eta_withdrawal_finalize_latency_seconds",
+		Help: "The median finalization latency of the latest finalized withdrawals.",
+	})
+	return e
+}
+
+// Start refreshes the latency statistics once, then periodically in the background until ctx is done.
+func (e *ETALogic) Start(ctx context.Context) {
+	e.refresh(ctx)
+	go func() {
+		tick := time.NewTicker(e.interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				e.refresh(ctx)
+			}
+		}
+	}()
+}
+
+func (e *ETALogic) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, etaRefreshTimeout)
+	defer cancel()
+
+	// A failed query keeps the previous statistic, stale estimations are better than none.
+	depositStat, err := e.crossMessageOrm.GetRecentDepositRelayLatency(ctx, e.sampleSize)
+	if err != nil {
+		log.Error("failed to refresh deposit relay latency", "err", err)
+	} else if depositStat.SampleCount > 0 {
+		e.mu.Lock()
+		e.depositRelayLatency = time.Duration(depositStat.MedianSec * float64(time.Second))
+		e.mu.Unlock()
+		e.depositRelayLatencySeconds.Set(depositStat.MedianSec)
+	}
+
+	withdrawalStat, err := e.crossMessageOrm.GetRecentWithdrawalFinalizeLatency(ctx, e.sampleSize)
+	if err != nil {
+		log.Error("failed to refresh withdrawal finalize latency", "err", err)
+	} else if withdrawalStat.SampleCount > 0 {
+		e.mu.Lock()
+		e.withdrawalFinalizeLatency = time.Duration(withdrawalStat.MedianSec * float64(time.Second))
+		e.mu.Unlock()
+		e.withdrawalFinalizeLatencySeconds.Set(withdrawalStat.MedianSec)
+	}
+}
+
+// Version returns the version of the latency statistics, the ETAs of the same txs change with it.
+func (e *ETALogic) Version() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return strconv.FormatInt(int64(e.depositRelayLatency/time.Second), 10) + "-" + strconv.FormatInt(int64(e.withdrawalFinalizeLatency/time.Second), 10)
+}
+
+// FillETAs sets the estimated completion time of the pending messages among the given txs: the relay on L2 of
+// deposits, and the finalization on L1 of withdrawals. Overdue messages are estimated to complete now.
+func (e *ETALogic) FillETAs(txs []*types.TxHistoryInfo) {
+	e.mu.RLock()
+	depositRelayLatency, withdrawalFinalizeLatency := e.depositRelayLatency, e.withdrawalFinalizeLatency
+	e.mu.RUnlock()
+
+	now := uint64(time.Now().Unix())
+	for _, tx := range txs {
+		if tx.TxStatus != orm.TxStatusTypeSent {
+			continue
+		}
+		var latency time.Duration
+		switch tx.MessageType {
+		case orm.MessageTypeL1SentMessage:
+			latency = depositRelayLatency
+		case orm.MessageTypeL2SentMessage:
+			if tx.ClaimInfo != nil {
+				// already finalized, the withdrawal waits for the claim of the user.
+				continue
+			}
+			latency = withdrawalFinalizeLatency
+		}
+		if latency == 0 {
+			continue
+		}
+		tx.ETA = max(tx.BlockTimestamp+uint64(latency/time.Second), now)
+	}
+}
@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+func TestFillETAs(t *testing.T) {
+	e := &ETALogic{depositRelayLatency: 10 * time.Minute, withdrawalFinalizeLatency: time.Hour}
+	now := uint64(time.Now().Unix())
+
+	deposit := &types.TxHistoryInfo{MessageType: orm.MessageTypeL1SentMessage, TxStatus: orm.TxStatusTypeSent, BlockTimestamp: now}
+	withdrawal := &types.TxHistoryInfo{MessageType: orm.MessageTypeL2SentMessage, TxStatus: orm.TxStatusTypeSent, BlockTimestamp: now}
+	finalized := &types.TxHistoryInfo{MessageType: orm.MessageTypeL2SentMessage, TxStatus: orm.TxStatusTypeSent, BlockTimestamp: now, ClaimInfo: &types.ClaimInfo{}}
+	relayed := &types.TxHistoryInfo{MessageType: orm.MessageTypeL1SentMessage, TxStatus: orm.TxStatusTypeRelayed, BlockTimestamp: now}
+	overdue := &types.TxHistoryInfo{MessageType: orm.MessageTypeL1SentMessage, TxStatus: orm.TxStatusTypeSent, BlockTimestamp: now - 3600}
+	e.FillETAs([]*types.TxHistoryInfo{deposit, withdrawal, finalized, relayed, overdue})
+
+	assert.Equal(t, now+600, deposit.ETA)
+	assert.Equal(t, now+3600, withdrawal.ETA)
+	assert.Zero(t, finalized.ETA)
+	assert.Zero(t, relayed.ETA)
+	assert.GreaterOrEqual(t, overdue.ETA, now)
+	assert.Less(t, overdue.ETA, now+600)
+
+	// no estimation without sampled latencies.
+	deposit.ETA = 0
+	(&ETALogic{}).FillETAs([]*types.TxHistoryInfo{deposit})
+	assert.Zero(t, deposit.ETA)
+}
@@ -10,15 +10,24 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/metrics"
+
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
 )
 
 // EventUpdateLogic the logic of insert/update the database
 type EventUpdateLogic struct {
-	db              *gorm.DB
-	crossMessageOrm *orm.CrossMessage
-	batchEventOrm   *orm.BatchEvent
+	db                    *gorm.DB
+	crossMessageOrm       *orm.CrossMessage
+	batchEventOrm         *orm.BatchEvent
+	messageQueueCursorOrm *orm.MessageQueueCursor
+	l1MessageInclusionOrm *orm.L1MessageInclusion
+	feeVaultWithdrawalOrm *orm.FeeVaultWithdrawal
+
+	bulkLoader *orm.BulkLoader // nil unless reindexing, see SetBulkLoader
+
+	l1FinalizedHeight L1FinalizedHeightGetter // nil if withdrawals are claimable once their batch is finalized
 
 	eventUpdateLogicL1FinalizeBatchEventL2BlockUpdateHeight prometheus.Gauge
 	eventUpdateLogicL2MessageNonceUpdateHeight              prometheus.Gauge
@@ -27,13 +36,16 @@ type EventUpdateLogic struct {
 // NewEventUpdateLogic creates a EventUpdateLogic instance
 func NewEventUpdateLogic(db *gorm.DB, isL1 bool) *EventUpdateLogic {
 	b := &EventUpdateLogic{
-		db:              db,
-		crossMessageOrm: orm.NewCrossMessage(db),
-		batchEventOrm:   orm.NewBatchEvent(db),
+		db:                    db,
+		crossMessageOrm:       orm.NewCrossMessage(db),
+		batchEventOrm:         orm.NewBatchEvent(db),
+		messageQueueCursorOrm: orm.NewMessageQueueCursor(db),
+		l1MessageInclusionOrm: orm.NewL1MessageInclusion(db),
+		feeVaultWithdrawalOrm: orm.NewFeeVaultWithdrawal(db),
 	}
 
 	if !isL1 {
-		reg := prometheus.DefaultRegisterer
+		reg := metrics.Registerer()
 		b.eventUpdateLogicL1FinalizeBatchEventL2BlockUpdateHeight = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
 			Name: "event_update_logic_L1_finalize_batch_event_L2_block_update_height",
 			Help: "L2 block height of the latest L1 batch event that has been finalized and updated in the message_table.",
@@ -47,6 +59,29 @@ func NewEventUpdateLogic(db *gorm.DB, isL1 bool) *EventUpdateLogic {
 	return b
 }
 
+// L1FinalizedHeightGetter gets the number of the latest L1 block finalized by the beacon chain.
+type L1FinalizedHeightGetter func(ctx context.Context) (uint64, error)
+
+// SetL1FinalityGate defers the finalization of the L2 withdrawals of a batch, which makes them claimable, until the L1
+// block of the finalize tx of the batch is finalized, so that a reorg of L1 can not revert the finalization of a
+// withdrawal reported claimable.
+func (b *EventUpdateLogic) SetL1FinalityGate(getter L1FinalizedHeightGetter) {
+	b.l1FinalizedHeight = getter
+}
+
+// SetMessageDataOffloadThreshold stores the message data longer than threshold bytes of the inserted messages in
+// cross_message_data rather than cross_message_v2, 0 keeps all of them inline.
+func (b *EventUpdateLogic) SetMessageDataOffloadThreshold(threshold int) {
+	b.crossMessageOrm.SetMessageDataOffloadThreshold(threshold)
+}
+
+// SetBulkLoader loads the sent messages, the relayed messages, the L1 message inclusions and the fee vault withdrawals
+// with the bulk loader instead of row-wise upserts, for a full reindex. The relayed messages are staged until
+// BulkLoader.ApplyStagedRelayedMessages, and the message data offload threshold is the one of the bulk loader.
+func (b *EventUpdateLogic) SetBulkLoader(loader *orm.BulkLoader) {
+	b.bulkLoader = loader
+}
+
 // GetL1SyncHeight gets the l1 sync height from db
 func (b *EventUpdateLogic) GetL1SyncHeight(ctx context.Context) (uint64, uint64, error) {
 	messageSyncedHeight, err := b.crossMessageOrm.GetMessageSyncedHeightInDB(ctx, orm.MessageTypeL1SentMessage)
@@ -76,14 +111,22 @@ func (b *EventUpdateLogic) GetL2MessageSyncedHeightInDB(ctx context.Context) (ui
 
 // L1InsertOrUpdate inserts or updates l1 messages
 func (b *EventUpdateLogic) L1InsertOrUpdate(ctx context.Context, l1FetcherResult *L1FilterResult) error {
-	if err := b.crossMessageOrm.InsertOrUpdateL1Messages(ctx, l1FetcherResult.DepositMessages); err != nil {
-		log.Error("failed to insert L1 deposit messages", "err", err)
-		return err
-	}
+	if b.bulkLoader != nil {
+		events := &orm.BulkEvents{SentMessages: l1FetcherResult.DepositMessages, RelayedMessages: l1FetcherResult.RelayedMessages}
+		if err := b.bulkLoader.Load(ctx, events); err != nil {
+			log.Error("failed to bulk load L1 deposit messages and L1 relayed messages of L2 withdrawals", "err", err)
+			return err
+		}
+	} else {
+		if err := b.crossMessageOrm.InsertOrUpdateL1Messages(ctx, l1FetcherResult.DepositMessages); err != nil {
+			log.Error("failed to insert L1 deposit messages", "err", err)
+			return err
+		}
 
-	if err := b.crossMessageOrm.InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx, l1FetcherResult.RelayedMessages); err != nil {
-		log.Error("failed to update L1 relayed messages of L2 withdrawals", "err", err)
-		return err
+		if err := b.crossMessageOrm.InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx, l1FetcherResult.RelayedMessages); err != nil {
+			log.Error("failed to update L1 relayed messages of L2 withdrawals", "err", err)
+			return err
+		}
 	}
 
 	if err := b.batchEventOrm.InsertOrUpdateBatchEvents(ctx, l1FetcherResult.BatchEvents); err != nil {
@@ -91,11 +134,16 @@ func (b *EventUpdateLogic) L1InsertOrUpdate(ctx context.Context, l1FetcherResult
 		return err
 	}
 
-	if err := b.crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, l1FetcherResult.MessageQueueEvents); err != nil {
+	if err := b.crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, l1FetcherResult.FromBlock, l1FetcherResult.MessageQueueEvents); err != nil {
 		log.Error("failed to insert L1 message queue events", "err", err)
 		return err
 	}
 
+	if err := b.messageQueueCursorOrm.ReplaceMessageQueueCursors(ctx, l1FetcherResult.FromBlock, l1FetcherResult.MessageQueueCursors); err != nil {
+		log.Error("failed to replace L1 message queue cursors", "err", err)
+		return err
+	}
+
 	if err := b.crossMessageOrm.InsertFailedL1GatewayTxs(ctx, l1FetcherResult.RevertedTxs); err != nil {
 		log.Error("failed to insert failed L1 gateway transactions", "err", err)
 		return err
@@ -160,7 +208,27 @@ func (b *EventUpdateLogic) UpdateL1BatchIndexAndStatus(ctx context.Context, heig
 		return err
 	}
 
+	var l1FinalizedHeight uint64
+	if b.l1FinalizedHeight != nil && len(finalizedBatches) > 0 {
+		l1FinalizedHeight, err = b.l1FinalizedHeight(ctx)
+		if err != nil {
+			log.Error("failed to get L1 finalized block number", "error", err)
+			return err
+		}
+	}
+
 	for _, finalizedBatch := range finalizedBatches {
+		// the withdrawals are finalized in order, those of the batches after one without a block range wait for it to
+		// be parsed.
+		if orm.BatchParseStatusType(finalizedBatch.ParseStatus) != orm.BatchParseStatusTypeParsed {
+			log.Warn("finalized batch without a block range, its commit tx is not parsed", "index", finalizedBatch.BatchIndex, "commit tx", finalizedBatch.CommitTxHash, "parse status", finalizedBatch.ParseStatus)
+			return nil
+		}
+		// and those of the batches after one finalized in an L1 block which is not finalized yet wait for it.
+		if b.l1FinalizedHeight != nil && finalizedBatch.FinalizeL1BlockNumber > l1FinalizedHeight {
+			log.Debug("finalized batch waiting for L1 finality", "index", finalizedBatch.BatchIndex, "finalize L1 block", finalizedBatch.FinalizeL1BlockNumber, "L1 finalized block", l1FinalizedHeight)
+			return nil
+		}
 		log.Info("update finalized batch info of L2 withdrawals", "index", finalizedBatch.BatchIndex, "start", finalizedBatch.StartBlockNumber, "end", finalizedBatch.EndBlockNumber)
 		if updateErr := b.updateL2WithdrawMessageInfos(ctx, finalizedBatch.BatchIndex, finalizedBatch.StartBlockNumber, finalizedBatch.EndBlockNumber); updateErr != nil {
 			log.Error("failed to update L2 withdraw message infos", "index", finalizedBatch.BatchIndex, "start", finalizedBatch.StartBlockNumber, "end", finalizedBatch.EndBlockNumber, "error", updateErr)
@@ -177,14 +245,37 @@ func (b *EventUpdateLogic) UpdateL1BatchIndexAndStatus(ctx context.Context, heig
 
 // L2InsertOrUpdate inserts or updates L2 messages
 func (b *EventUpdateLogic) L2InsertOrUpdate(ctx context.Context, l2FetcherResult *L2FilterResult) error {
-	if err := b.crossMessageOrm.InsertOrUpdateL2Messages(ctx, l2FetcherResult.WithdrawMessages); err != nil {
-		log.Error("failed to insert L2 withdrawal messages", "err", err)
-		return err
-	}
+	if b.bulkLoader != nil {
+		events := &orm.BulkEvents{
+			SentMessages:        l2FetcherResult.WithdrawMessages,
+			RelayedMessages:     l2FetcherResult.RelayedMessages,
+			L1MessageInclusions: l2FetcherResult.L1MessageInclusions,
+			FeeVaultWithdrawals: l2FetcherResult.FeeVaultWithdrawals,
+		}
+		if err := b.bulkLoader.Load(ctx, events); err != nil {
+			log.Error("failed to bulk load L2 withdrawal messages, L2 relayed messages of L1 deposits, L1 message inclusions and L2 fee vault withdrawals", "err", err)
+			return err
+		}
+	} else {
+		if err := b.crossMessageOrm.InsertOrUpdateL2Messages(ctx, l2FetcherResult.WithdrawMessages); err != nil {
+			log.Error("failed to insert L2 withdrawal messages", "err", err)
+			return err
+		}
 
-	if err := b.crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, l2FetcherResult.RelayedMessages); err != nil {
-		log.Error("failed to update L2 relayed messages of L1 deposits", "err", err)
-		return err
+		if err := b.crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, l2FetcherResult.RelayedMessages); err != nil {
+			log.Error("failed to update L2 relayed messages of L1 deposits", "err", err)
+			return err
+		}
+
+		if err := b.l1MessageInclusionOrm.InsertOrUpdateL1MessageInclusions(ctx, l2FetcherResult.L1MessageInclusions); err != nil {
+			log.Error("failed to insert L1 message inclusions", "err", err)
+			return err
+		}
+
+		if err := b.feeVaultWithdrawalOrm.InsertOrUpdateFeeVaultWithdrawals(ctx, l2FetcherResult.FeeVaultWithdrawals); err != nil {
+			log.Error("failed to insert L2 fee vault withdrawals", "err", err)
+			return err
+		}
 	}
 
 	if err := b.crossMessageOrm.InsertFailedL2GatewayTxs(ctx, l2FetcherResult.OtherRevertedTxs); err != nil {
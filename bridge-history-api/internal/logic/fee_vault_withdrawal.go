@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types/events"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// parseFeeVaultWithdrawals parses the withdrawal events of the fee vault. A withdrawal sends the collected fees to L1
+// through the messenger, so it is linked to the withdraw message sent by the vault in the same tx.
+func parseFeeVaultWithdrawals(logs []types.Log, vault common.Address, withdrawMessages []*orm.CrossMessage, blockTimestampsMap map[uint64]uint64) ([]*orm.FeeVaultWithdrawal, error) {
+	messageHashes := make(map[string]string)
+	for _, message := range withdrawMessages {
+		if common.HexToAddress(message.MessageFrom) == vault {
+			messageHashes[message.L2TxHash] = message.MessageHash
+		}
+	}
+
+	var withdrawals []*orm.FeeVaultWithdrawal
+	for _, vlog := range logs {
+		if vlog.Address != vault || len(vlog.Topics) == 0 || vlog.Topics[0] != events.L2TxFeeVaultWithdrawalEventSig {
+			continue
+		}
+		event, err := events.UnpackL2TxFeeVaultWithdrawalEvent(vlog)
+		if err != nil {
+			log.Error("Failed to unpack fee vault Withdrawal event", "err", err)
+			return nil, err
+		}
+		withdrawals = append(withdrawals, &orm.FeeVaultWithdrawal{
+			L2TxHash:       vlog.TxHash.String(),
+			LogIndex:       vlog.Index,
+			VaultAddress:   vault.String(),
+			Value:          orm.NewBigInt(event.Value),
+			Recipient:      event.To.String(),
+			TriggeredBy:    event.From.String(),
+			MessageHash:    messageHashes[vlog.TxHash.String()],
+			L2BlockNumber:  vlog.BlockNumber,
+			BlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
+		})
+	}
+	return withdrawals, nil
+}
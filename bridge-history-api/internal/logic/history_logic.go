@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"reflect"
 	"strconv"
 	"time"
@@ -26,39 +28,63 @@ const (
 	// access rights can read or write to keys starting with "bridge-history".
 	cacheKeyPrefixBridgeHistory = "bridge-history-"
 
-	cacheKeyPrefixL2ClaimableWithdrawalsByAddr = cacheKeyPrefixBridgeHistory + "l2ClaimableWithdrawalsByAddr:"
-	cacheKeyPrefixL2WithdrawalsByAddr          = cacheKeyPrefixBridgeHistory + "l2WithdrawalsByAddr:"
-	cacheKeyPrefixTxsByAddr                    = cacheKeyPrefixBridgeHistory + "txsByAddr:"
-	cacheKeyPrefixQueryTxsByHashes             = cacheKeyPrefixBridgeHistory + "queryTxsByHashes:"
-	cacheKeyExpiredTime                        = 1 * time.Minute
+	cacheKeyPrefixL2ClaimableWithdrawalsByAddr          = cacheKeyPrefixBridgeHistory + "l2ClaimableWithdrawalsByAddr:"
+	cacheKeyPrefixL2FinalizedClaimableWithdrawalsByAddr = cacheKeyPrefixBridgeHistory + "l2FinalizedClaimableWithdrawalsByAddr:"
+	cacheKeyPrefixL2WithdrawalsByAddr                   = cacheKeyPrefixBridgeHistory + "l2WithdrawalsByAddr:"
+	cacheKeyPrefixTxsByAddr                             = cacheKeyPrefixBridgeHistory + "txsByAddr:"
+	cacheKeyPrefixQueryTxsByHashes                      = cacheKeyPrefixBridgeHistory + "queryTxsByHashes:"
+	cacheKeyExpiredTime                                 = 1 * time.Minute
+
+	// defaultTxsByAddressesLimit is the default max number of txs returned per address by batch address queries.
+	defaultTxsByAddressesLimit = 100
+	// maxPendingDepositsPerAddress is the max number of pending deposits returned per address.
+	maxPendingDepositsPerAddress = 100
 )
 
+// ErrL1QueueIndexNotFound indicates that a queue index is not appended to the L1 message queue yet.
+var ErrL1QueueIndexNotFound = errors.New("queue index not found in the L1 message queue")
+
 // HistoryLogic services.
 type HistoryLogic struct {
-	crossMessageOrm *orm.CrossMessage
-	batchEventOrm   *orm.BatchEvent
-	redis           *redis.Client
-	singleFlight    singleflight.Group
-	cacheMetrics    *cacheMetrics
+	crossMessageOrm       *orm.CrossMessage
+	batchEventOrm         *orm.BatchEvent
+	messageQueueCursorOrm *orm.MessageQueueCursor
+	pendingDepositOrm     *orm.PendingDeposit
+	redis                 *redis.Client
+	singleFlight          singleflight.Group
+	cacheMetrics          *cacheMetrics
 }
 
 // NewHistoryLogic returns bridge history services.
 func NewHistoryLogic(db *gorm.DB, redis *redis.Client) *HistoryLogic {
 	logic := &HistoryLogic{
-		crossMessageOrm: orm.NewCrossMessage(db),
-		batchEventOrm:   orm.NewBatchEvent(db),
-		redis:           redis,
-		cacheMetrics:    initCacheMetrics(),
+		crossMessageOrm:       orm.NewCrossMessage(db),
+		batchEventOrm:         orm.NewBatchEvent(db),
+		messageQueueCursorOrm: orm.NewMessageQueueCursor(db),
+		pendingDepositOrm:     orm.NewPendingDeposit(db),
+		redis:                 redis,
+		cacheMetrics:          initCacheMetrics(),
 	}
 	return logic
 }
 
+// GetAddressVersion returns the version of the txs of the given address, which changes whenever they change. The
+// per-address queries take it to key their cache, so that the cached txs are never older than the version.
+func (h *HistoryLogic) GetAddressVersion(ctx context.Context, address string) (string, error) {
+	updatedAt, count, err := h.crossMessageOrm.GetLatestUpdateByAddress(ctx, address)
+	if err != nil {
+		log.Error("failed to get latest update by address", "address", address, "error", err)
+		return "", err
+	}
+	return strconv.FormatInt(updatedAt.UnixMicro(), 10) + "-" + strconv.FormatUint(count, 10), nil
+}
+
 // GetL2UnclaimedWithdrawalsByAddress gets all unclaimed withdrawal txs under given address.
-func (h *HistoryLogic) GetL2UnclaimedWithdrawalsByAddress(ctx context.Context, address string, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
-	cacheKey := cacheKeyPrefixL2ClaimableWithdrawalsByAddr + address
-	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, page, pageSize)
+func (h *HistoryLogic) GetL2UnclaimedWithdrawalsByAddress(ctx context.Context, address, version string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	cacheKey := cacheKeyPrefixL2ClaimableWithdrawalsByAddr + address + ":" + version
+	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, offset, limit)
 	if err != nil {
-		log.Error("failed to get cached tx info", "cached key", cacheKey, "page", page, "page size", pageSize, "error", err)
+		log.Error("failed to get cached tx info", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
 		return nil, 0, err
 	}
 
@@ -90,15 +116,55 @@ func (h *HistoryLogic) GetL2UnclaimedWithdrawalsByAddress(ctx context.Context, a
 		return nil, 0, errors.New("unexpected error")
 	}
 
-	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, page, pageSize)
+	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, offset, limit)
+}
+
+// GetL2ClaimableWithdrawalsByAddress gets the withdrawal txs under given address which can be claimed on L1 now.
+func (h *HistoryLogic) GetL2ClaimableWithdrawalsByAddress(ctx context.Context, address, version string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	cacheKey := cacheKeyPrefixL2FinalizedClaimableWithdrawalsByAddr + address + ":" + version
+	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, offset, limit)
+	if err != nil {
+		log.Error("failed to get cached tx info", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
+		return nil, 0, err
+	}
+
+	if isHit {
+		h.cacheMetrics.cacheHits.WithLabelValues("GetL2ClaimableWithdrawalsByAddress").Inc()
+		log.Info("cache hit", "cache key", cacheKey)
+		return pagedTxs, total, nil
+	}
+
+	h.cacheMetrics.cacheMisses.WithLabelValues("GetL2ClaimableWithdrawalsByAddress").Inc()
+	log.Info("cache miss", "cache key", cacheKey)
+
+	result, err, _ := h.singleFlight.Do(cacheKey, func() (interface{}, error) {
+		var messages []*orm.CrossMessage
+		messages, err = h.crossMessageOrm.GetL2ClaimableWithdrawalsByAddress(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		return messages, nil
+	})
+	if err != nil {
+		log.Error("failed to get L2 finalized claimable withdrawals by address", "address", address, "error", err)
+		return nil, 0, err
+	}
+
+	messages, ok := result.([]*orm.CrossMessage)
+	if !ok {
+		log.Error("unexpected type", "expected", "[]*types.TxHistoryInfo", "got", reflect.TypeOf(result), "address", address)
+		return nil, 0, errors.New("unexpected error")
+	}
+
+	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, offset, limit)
 }
 
 // GetL2WithdrawalsByAddress gets all withdrawal txs under given address.
-func (h *HistoryLogic) GetL2WithdrawalsByAddress(ctx context.Context, address string, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
-	cacheKey := cacheKeyPrefixL2WithdrawalsByAddr + address
-	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, page, pageSize)
+func (h *HistoryLogic) GetL2WithdrawalsByAddress(ctx context.Context, address, version string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	cacheKey := cacheKeyPrefixL2WithdrawalsByAddr + address + ":" + version
+	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, offset, limit)
 	if err != nil {
-		log.Error("failed to get cached tx info", "cached key", cacheKey, "page", page, "page size", pageSize, "error", err)
+		log.Error("failed to get cached tx info", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
 		return nil, 0, err
 	}
 
@@ -130,15 +196,15 @@ func (h *HistoryLogic) GetL2WithdrawalsByAddress(ctx context.Context, address st
 		return nil, 0, errors.New("unexpected error")
 	}
 
-	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, page, pageSize)
+	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, offset, limit)
 }
 
 // GetTxsByAddress gets tx infos under given address.
-func (h *HistoryLogic) GetTxsByAddress(ctx context.Context, address string, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
-	cacheKey := cacheKeyPrefixTxsByAddr + address
-	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, page, pageSize)
+func (h *HistoryLogic) GetTxsByAddress(ctx context.Context, address, version string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	cacheKey := cacheKeyPrefixTxsByAddr + address + ":" + version
+	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, offset, limit)
 	if err != nil {
-		log.Error("failed to get cached tx info", "cached key", cacheKey, "page", page, "page size", pageSize, "error", err)
+		log.Error("failed to get cached tx info", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
 		return nil, 0, err
 	}
 
@@ -170,7 +236,61 @@ func (h *HistoryLogic) GetTxsByAddress(ctx context.Context, address string, page
 		return nil, 0, errors.New("unexpected error")
 	}
 
-	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, page, pageSize)
+	return h.processAndCacheTxHistoryInfo(ctx, cacheKey, messages, offset, limit)
+}
+
+// GetTxsByAddresses gets the latest tx infos of each of the given addresses, grouped by address in the given order.
+// Results are not cached, as the combinations of addresses rarely repeat.
+func (h *HistoryLogic) GetTxsByAddresses(ctx context.Context, addresses []string, limit uint64) ([]*types.AddressResultData, error) {
+	if limit == 0 {
+		limit = defaultTxsByAddressesLimit
+	}
+
+	results := make([]*types.AddressResultData, 0, len(addresses))
+	resultMap := make(map[string]*types.AddressResultData, len(addresses))
+	for _, address := range addresses {
+		// Senders are stored as checksummed addresses.
+		address = common.HexToAddress(address).String()
+		if _, exists := resultMap[address]; exists {
+			// Skip duplicate addresses.
+			continue
+		}
+		result := &types.AddressResultData{Address: address, Results: []*types.TxHistoryInfo{}}
+		resultMap[address] = result
+		results = append(results, result)
+	}
+
+	senders := make([]string, 0, len(results))
+	for _, result := range results {
+		senders = append(senders, result.Address)
+	}
+	messages, err := h.crossMessageOrm.GetTxsByAddresses(ctx, senders, limit)
+	if err != nil {
+		log.Error("failed to get txs by addresses", "addresses", senders, "error", err)
+		return nil, err
+	}
+
+	// the total counts all txs of an address, not only the returned latest ones.
+	totals, err := h.crossMessageOrm.GetTxCountsByAddresses(ctx, senders)
+	if err != nil {
+		log.Error("failed to count txs by addresses", "addresses", senders, "error", err)
+		return nil, err
+	}
+
+	if err := h.loadClaimMessageData(ctx, messages); err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		result, found := resultMap[message.Sender]
+		if !found {
+			continue
+		}
+		result.Results = append(result.Results, getTxHistoryInfo(message))
+	}
+	for _, result := range results {
+		result.Total = totals[result.Address]
+	}
+	return results, nil
 }
 
 // GetTxsByHashes gets tx infos under given tx hashes.
@@ -223,6 +343,9 @@ func (h *HistoryLogic) GetTxsByHashes(ctx context.Context, txHashes []string) ([
 			log.Error("failed to get messages by tx hashes", "hashes", uncachedHashes)
 			return nil, err
 		}
+		if err := h.loadClaimMessageData(ctx, messages); err != nil {
+			return nil, err
+		}
 
 		var txHistories []*types.TxHistoryInfo
 		for _, message := range messages {
@@ -260,9 +383,140 @@ func (h *HistoryLogic) GetTxsByHashes(ctx context.Context, txHashes []string) ([
 	return results, nil
 }
 
+// GetL1QueuePosition gets the position of an L1 message in the L1 message queue, by its queue index.
+// It returns ErrL1QueueIndexNotFound if the queue index is not appended to the queue yet.
+func (h *HistoryLogic) GetL1QueuePosition(ctx context.Context, queueIndex uint64) (*types.QueuePositionInfo, error) {
+	cursor, err := h.messageQueueCursorOrm.GetMessageQueueCursor(ctx)
+	if err != nil {
+		log.Error("failed to get message queue cursor", "queue index", queueIndex, "error", err)
+		return nil, err
+	}
+	includedQueueIndex, err := h.crossMessageOrm.GetNextL2IncludedL1MessageNonce(ctx)
+	if err != nil {
+		log.Error("failed to get next L2 included L1 message nonce", "queue index", queueIndex, "error", err)
+		return nil, err
+	}
+
+	position := getQueuePositionInfo(queueIndex, cursor, includedQueueIndex)
+	if position == nil {
+		return nil, ErrL1QueueIndexNotFound
+	}
+	return position, nil
+}
+
+// getQueuePositionInfo computes the position of an L1 message from the queue cursors tracked on L1 and the index of the first
+// message not included on L2 yet. L2 includes the messages in queue order long before their batch is committed on L1,
+// so the inclusion moves the pending index ahead of the popped one. It returns nil if the queue index is not queued yet.
+func getQueuePositionInfo(queueIndex uint64, cursor *orm.MessageQueueCursor, includedQueueIndex uint64) *types.QueuePositionInfo {
+	position := &types.QueuePositionInfo{QueueIndex: queueIndex}
+	if cursor != nil {
+		position.PendingQueueIndex = cursor.PendingQueueIndex
+		position.NextQueueIndex = cursor.NextQueueIndex
+		position.L1BlockNumber = cursor.L1BlockNumber
+	}
+	position.PendingQueueIndex = max(position.PendingQueueIndex, includedQueueIndex)
+	position.NextQueueIndex = max(position.NextQueueIndex, position.PendingQueueIndex)
+	if queueIndex >= position.NextQueueIndex {
+		return nil
+	}
+
+	position.Popped = queueIndex < position.PendingQueueIndex
+	if !position.Popped {
+		position.Ahead = queueIndex - position.PendingQueueIndex
+	}
+	return position
+}
+
+// GetTxsByTokenAmountRange gets the txs of an address transferring a token with an amount within [minAmount, maxAmount],
+// an empty bound leaves its side of the range open.
+func (h *HistoryLogic) GetTxsByTokenAmountRange(ctx context.Context, address, token, minAmount, maxAmount string) ([]*types.TxHistoryInfo, error) {
+	minValue, err := parseAmountBound(minAmount)
+	if err != nil {
+		return nil, err
+	}
+	maxValue, err := parseAmountBound(maxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Senders and token addresses are stored as checksummed addresses.
+	address = common.HexToAddress(address).String()
+	token = common.HexToAddress(token).String()
+	messages, err := h.crossMessageOrm.GetTxsByAddressAndTokenAmountRange(ctx, address, token, minValue, maxValue)
+	if err != nil {
+		log.Error("failed to get txs by token amount range", "address", address, "token", token, "min", minAmount, "max", maxAmount, "error", err)
+		return nil, err
+	}
+	if err := h.loadClaimMessageData(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	txHistories := make([]*types.TxHistoryInfo, 0, len(messages))
+	for _, message := range messages {
+		txHistories = append(txHistories, getTxHistoryInfo(message))
+	}
+	return txHistories, nil
+}
+
+// GetPendingDepositsByAddress gets the latest deposits sent by or to an address seen in the L1 txpool and not
+// indexed yet.
+func (h *HistoryLogic) GetPendingDepositsByAddress(ctx context.Context, address string) ([]*types.TxHistoryInfo, error) {
+	// Senders and receivers are stored as checksummed addresses.
+	address = common.HexToAddress(address).String()
+	deposits, err := h.pendingDepositOrm.GetPendingDepositsByAddress(ctx, address, maxPendingDepositsPerAddress)
+	if err != nil {
+		log.Error("failed to get pending deposits by address", "address", address, "error", err)
+		return nil, err
+	}
+	txHistories := make([]*types.TxHistoryInfo, 0, len(deposits))
+	for _, deposit := range deposits {
+		txHistories = append(txHistories, getPendingDepositInfo(deposit))
+	}
+	return txHistories, nil
+}
+
+// GetTokenTotalsByAddress gets the total amounts of the tokens sent by an address, of a single token if token is set.
+func (h *HistoryLogic) GetTokenTotalsByAddress(ctx context.Context, address, token string) ([]*types.TokenTotalInfo, error) {
+	address = common.HexToAddress(address).String()
+	if token != "" {
+		token = common.HexToAddress(token).String()
+	}
+	sums, err := h.crossMessageOrm.SumTokenAmountsByAddress(ctx, address, token)
+	if err != nil {
+		log.Error("failed to sum token amounts by address", "address", address, "token", token, "error", err)
+		return nil, err
+	}
+
+	totals := make([]*types.TokenTotalInfo, 0, len(sums))
+	for _, sum := range sums {
+		totals = append(totals, &types.TokenTotalInfo{
+			MessageType:    sum.MessageType,
+			TokenType:      orm.TokenType(sum.TokenType),
+			L1TokenAddress: sum.L1TokenAddress,
+			TotalAmount:    sum.TotalAmount.String(),
+			TxCount:        sum.TxCount,
+		})
+	}
+	return totals, nil
+}
+
+// parseAmountBound parses an optional decimal bound of an amount range, nil if the bound is empty.
+func parseAmountBound(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return value, nil
+}
+
 func getTxHistoryInfo(message *orm.CrossMessage) *types.TxHistoryInfo {
 	txHistory := &types.TxHistoryInfo{
 		MessageHash:    message.MessageHash,
+		Sender:         message.Sender,
+		Receiver:       message.Receiver,
 		TokenType:      orm.TokenType(message.TokenType),
 		TokenIDs:       utils.ConvertStringToStringArray(message.TokenIDs),
 		TokenAmounts:   utils.ConvertStringToStringArray(message.TokenAmounts),
@@ -273,7 +527,9 @@ func getTxHistoryInfo(message *orm.CrossMessage) *types.TxHistoryInfo {
 		BlockTimestamp: message.BlockTimestamp,
 	}
 	if txHistory.MessageType == orm.MessageTypeL1SentMessage {
+		queueIndex := message.MessageNonce
 		txHistory.Hash = message.L1TxHash
+		txHistory.QueueIndex = &queueIndex
 		txHistory.ReplayTxHash = message.L1ReplayTxHash
 		txHistory.RefundTxHash = message.L1RefundTxHash
 		txHistory.BlockNumber = message.L1BlockNumber
@@ -281,6 +537,27 @@ func getTxHistoryInfo(message *orm.CrossMessage) *types.TxHistoryInfo {
 			Hash:        message.L2TxHash,
 			BlockNumber: message.L2BlockNumber,
 		}
+		if message.L2RelayFailureSelector != "" || message.L2RelayFailureReason != "" {
+			txHistory.RelayFailure = &types.RelayFailureInfo{
+				Selector: message.L2RelayFailureSelector,
+				Reason:   message.L2RelayFailureReason,
+			}
+		}
+		if message.DepositCallSelector != "" || message.DepositCallData != "" {
+			txHistory.DepositCall = &types.DepositCallInfo{
+				Target:   message.Receiver,
+				Selector: message.DepositCallSelector,
+				Data:     message.DepositCallData,
+				Args:     utils.SplitABIWords(message.DepositCallData),
+			}
+			method, callArgs := utils.DecodeCallArgs(message.DepositCallSelector, message.DepositCallData)
+			if method != "" {
+				txHistory.DepositCall.Method = method
+				for _, arg := range callArgs {
+					txHistory.DepositCall.Params = append(txHistory.DepositCall.Params, &types.DepositCallParam{Name: arg.Name, Type: arg.Type, Value: arg.Value})
+				}
+			}
+		}
 	} else {
 		txHistory.Hash = message.L2TxHash
 		txHistory.BlockNumber = message.L2BlockNumber
@@ -302,13 +579,34 @@ func getTxHistoryInfo(message *orm.CrossMessage) *types.TxHistoryInfo {
 				Claimable: true,
 			}
 		}
+		// failed relays record the sender of their tx too, only a successful relay claims the withdrawal.
+		if message.ClaimedBy != "" && orm.TxStatusType(message.TxStatus) == orm.TxStatusTypeRelayed {
+			selfClaimed := isSelfClaimed(message)
+			txHistory.ClaimedBy = message.ClaimedBy
+			txHistory.SelfClaimed = &selfClaimed
+		}
+	}
+	if message.L1TxEffectiveGasPrice != "" {
+		txHistory.L1Fee = getL1FeeInfo(message.L1TxGasUsed, message.L1TxEffectiveGasPrice)
 	}
 	return txHistory
 }
 
-func (h *HistoryLogic) getCachedTxsInfo(ctx context.Context, cacheKey string, pageNum, pageSize uint64) ([]*types.TxHistoryInfo, uint64, bool, error) {
-	start := int64((pageNum - 1) * pageSize)
-	end := start + int64(pageSize) - 1
+func getL1FeeInfo(gasUsed uint64, effectiveGasPrice string) *types.L1FeeInfo {
+	l1Fee := &types.L1FeeInfo{
+		GasUsed:           gasUsed,
+		EffectiveGasPrice: effectiveGasPrice,
+	}
+	if gasPrice, ok := new(big.Int).SetString(effectiveGasPrice, 10); ok {
+		l1Fee.Fee = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed)).String()
+	}
+	return l1Fee
+}
+
+// getCachedTxsInfo returns up to limit cached txs starting at offset of the sorted txs, and the total number of txs.
+func (h *HistoryLogic) getCachedTxsInfo(ctx context.Context, cacheKey string, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, bool, error) {
+	start := int64(offset)
+	end := start + int64(limit) - 1
 
 	total, err := h.redis.ZCard(ctx, cacheKey).Result()
 	if err != nil {
@@ -381,7 +679,26 @@ func (h *HistoryLogic) cacheTxsInfo(ctx context.Context, cacheKey string, txs []
 	return nil
 }
 
-func (h *HistoryLogic) processAndCacheTxHistoryInfo(ctx context.Context, cacheKey string, messages []*orm.CrossMessage, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
+// loadClaimMessageData loads the offloaded message data of the finalized L2 withdrawals, which is only read to build
+// their claim info.
+func (h *HistoryLogic) loadClaimMessageData(ctx context.Context, messages []*orm.CrossMessage) error {
+	var claimable []*orm.CrossMessage
+	for _, message := range messages {
+		if orm.MessageType(message.MessageType) == orm.MessageTypeL2SentMessage && orm.RollupStatusType(message.RollupStatus) == orm.RollupStatusTypeFinalized {
+			claimable = append(claimable, message)
+		}
+	}
+	if err := h.crossMessageOrm.LoadMessageData(ctx, claimable); err != nil {
+		log.Error("failed to load message data", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (h *HistoryLogic) processAndCacheTxHistoryInfo(ctx context.Context, cacheKey string, messages []*orm.CrossMessage, offset, limit uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	if err := h.loadClaimMessageData(ctx, messages); err != nil {
+		return nil, 0, err
+	}
 	var txHistories []*types.TxHistoryInfo
 	for _, message := range messages {
 		txHistories = append(txHistories, getTxHistoryInfo(message))
@@ -393,14 +710,14 @@ func (h *HistoryLogic) processAndCacheTxHistoryInfo(ctx context.Context, cacheKe
 		return nil, 0, err
 	}
 
-	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, page, pageSize)
+	pagedTxs, total, isHit, err := h.getCachedTxsInfo(ctx, cacheKey, offset, limit)
 	if err != nil {
-		log.Error("failed to get cached tx info", "cached key", cacheKey, "page", page, "page size", pageSize, "error", err)
+		log.Error("failed to get cached tx info", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
 		return nil, 0, err
 	}
 
 	if !isHit {
-		log.Error("cache miss after write, expect hit", "cached key", cacheKey, "page", page, "page size", pageSize, "error", err)
+		log.Error("cache miss after write, expect hit", "cached key", cacheKey, "offset", offset, "limit", limit, "error", err)
 		return nil, 0, err
 	}
 	return pagedTxs, total, nil
@@ -5,6 +5,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"scroll-tech/common/metrics"
 )
 
 type cacheMetrics struct {
@@ -20,14 +22,14 @@ var (
 func initCacheMetrics() *cacheMetrics {
 	initMetricsOnce.Do(func() {
 		cm = &cacheMetrics{
-			cacheHits: promauto.NewCounterVec(
+			cacheHits: promauto.With(metrics.Registerer()).NewCounterVec(
 				prometheus.CounterOpts{
 					Name: "bridge_history_api_cache_hits_total",
 					Help: "The total number of cache hits",
 				},
 				[]string{"api"},
 			),
-			cacheMisses: promauto.NewCounterVec(
+			cacheMisses: promauto.With(metrics.Registerer()).NewCounterVec(
 				prometheus.CounterOpts{
 					Name: "bridge_history_api_cache_misses_total",
 					Help: "The total number of cache misses",
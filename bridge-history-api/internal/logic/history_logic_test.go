@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestGetQueuePositionInfo(t *testing.T) {
+	cursor := &orm.MessageQueueCursor{L1BlockNumber: 100, NextQueueIndex: 20, PendingQueueIndex: 5}
+
+	// messages popped by a committed batch.
+	position := getQueuePositionInfo(4, cursor, 0)
+	assert.True(t, position.Popped)
+	assert.Zero(t, position.Ahead)
+
+	// messages included on L2 are popped before their batch is committed.
+	position = getQueuePositionInfo(10, cursor, 8)
+	assert.Equal(t, uint64(8), position.PendingQueueIndex)
+	assert.False(t, position.Popped)
+	assert.Equal(t, uint64(2), position.Ahead)
+	position = getQueuePositionInfo(7, cursor, 8)
+	assert.True(t, position.Popped)
+	assert.Equal(t, uint64(100), position.L1BlockNumber)
+
+	// queue indexes not appended to the queue yet are not found.
+	assert.Nil(t, getQueuePositionInfo(20, cursor, 8))
+	assert.Nil(t, getQueuePositionInfo(0, nil, 0))
+
+	// without cursors tracked on L1, the inclusion on L2 is enough.
+	position = getQueuePositionInfo(2, nil, 3)
+	assert.True(t, position.Popped)
+	assert.Equal(t, uint64(3), position.NextQueueIndex)
+}
@@ -6,11 +6,12 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
-	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 
-	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/common/types/crossdomain"
+	"scroll-tech/common/types/events"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
@@ -36,9 +37,9 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 	var l1RelayedMessages []*orm.CrossMessage
 	for _, vlog := range logs {
 		switch vlog.Topics[0] {
-		case backendabi.L1DepositETHSig:
-			event := backendabi.ETHMessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ETHGatewayABI, &event, "DepositETH", vlog); err != nil {
+		case events.L1ETHGatewayDepositETHEventSig:
+			event, err := events.UnpackL1ETHGatewayDepositETHEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack DepositETH event", "err", err)
 				return nil, nil, err
 			}
@@ -47,9 +48,9 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.Receiver = event.To.String()
 			lastMessage.TokenType = int(orm.TokenTypeETH)
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L1DepositERC20Sig:
-			event := backendabi.ERC20MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL1ERC20GatewayABI, &event, "DepositERC20", vlog)
+			lastMessage.DepositCallSelector, lastMessage.DepositCallData = utils.SplitCallData(event.Data)
+		case events.L1ERC20GatewayDepositERC20EventSig:
+			event, err := events.UnpackL1ERC20GatewayDepositERC20Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack DepositERC20 event", "err", err)
 				return nil, nil, err
@@ -61,9 +62,10 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L1DepositERC721Sig:
-			event := backendabi.ERC721MessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ERC721GatewayABI, &event, "DepositERC721", vlog); err != nil {
+			lastMessage.DepositCallSelector, lastMessage.DepositCallData = utils.SplitCallData(event.Data)
+		case events.L1ERC721GatewayDepositERC721EventSig:
+			event, err := events.UnpackL1ERC721GatewayDepositERC721Event(vlog)
+			if err != nil {
 				log.Error("Failed to unpack DepositERC721 event", "err", err)
 				return nil, nil, err
 			}
@@ -73,10 +75,10 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.TokenType = int(orm.TokenTypeERC721)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = event.TokenID.String()
-		case backendabi.L1BatchDepositERC721Sig:
-			event := backendabi.BatchERC721MessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ERC721GatewayABI, &event, "BatchDepositERC721", vlog); err != nil {
+			lastMessage.TokenIDs = event.TokenId.String()
+		case events.L1ERC721GatewayBatchDepositERC721EventSig:
+			event, err := events.UnpackL1ERC721GatewayBatchDepositERC721Event(vlog)
+			if err != nil {
 				log.Error("Failed to unpack BatchDepositERC721 event", "err", err)
 				return nil, nil, err
 			}
@@ -86,10 +88,10 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.TokenType = int(orm.TokenTypeERC721)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIDs)
-		case backendabi.L1DepositERC1155Sig:
-			event := backendabi.ERC1155MessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ERC1155GatewayABI, &event, "DepositERC1155", vlog); err != nil {
+			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIds)
+		case events.L1ERC1155GatewayDepositERC1155EventSig:
+			event, err := events.UnpackL1ERC1155GatewayDepositERC1155Event(vlog)
+			if err != nil {
 				log.Error("Failed to unpack DepositERC1155 event", "err", err)
 				return nil, nil, err
 			}
@@ -99,11 +101,11 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.TokenType = int(orm.TokenTypeERC1155)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = event.TokenID.String()
+			lastMessage.TokenIDs = event.TokenId.String()
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L1BatchDepositERC1155Sig:
-			event := backendabi.BatchERC1155MessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ERC1155GatewayABI, &event, "BatchDepositERC1155", vlog); err != nil {
+		case events.L1ERC1155GatewayBatchDepositERC1155EventSig:
+			event, err := events.UnpackL1ERC1155GatewayBatchDepositERC1155Event(vlog)
+			if err != nil {
 				log.Error("Failed to unpack BatchDepositERC1155 event", "err", err)
 				return nil, nil, err
 			}
@@ -113,11 +115,11 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 			lastMessage.TokenType = int(orm.TokenTypeERC1155)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIDs)
-			lastMessage.TokenAmounts = utils.ConvertBigIntArrayToString(event.TokenAmounts)
-		case backendabi.L1SentMessageEventSig:
-			event := backendabi.L1SentMessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ScrollMessengerABI, &event, "SentMessage", vlog); err != nil {
+			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIds)
+			lastMessage.TokenAmounts = utils.ConvertBigIntArrayToString(event.Amounts)
+		case events.L1ScrollMessengerSentMessageEventSig:
+			event, err := events.UnpackL1ScrollMessengerSentMessageEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack SentMessage event", "err", err)
 				return nil, nil, err
 			}
@@ -133,37 +135,40 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 				TokenType:      int(orm.TokenTypeETH),
 				L1TxHash:       vlog.TxHash.String(),
 				TokenAmounts:   event.Value.String(),
+				MessageFrom:    event.Sender.String(),
 				MessageNonce:   event.MessageNonce.Uint64(),
 				MessageType:    int(orm.MessageTypeL1SentMessage),
 				TxStatus:       int(orm.TxStatusTypeSent),
 				BlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
-				MessageHash:    utils.ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message).String(),
+				MessageHash:    crossdomain.ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message).String(),
 			})
-		case backendabi.L1RelayedMessageEventSig:
-			event := backendabi.L1RelayedMessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ScrollMessengerABI, &event, "RelayedMessage", vlog); err != nil {
+		case events.L1ScrollMessengerRelayedMessageEventSig:
+			event, err := events.UnpackL1ScrollMessengerRelayedMessageEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack RelayedMessage event", "err", err)
 				return nil, nil, err
 			}
 			l1RelayedMessages = append(l1RelayedMessages, &orm.CrossMessage{
-				MessageHash:   event.MessageHash.String(),
-				L1BlockNumber: vlog.BlockNumber,
-				L1TxHash:      vlog.TxHash.String(),
-				TxStatus:      int(orm.TxStatusTypeRelayed),
-				MessageType:   int(orm.MessageTypeL2SentMessage),
+				MessageHash:           event.MessageHash.String(),
+				L1BlockNumber:         vlog.BlockNumber,
+				L1TxHash:              vlog.TxHash.String(),
+				TxStatus:              int(orm.TxStatusTypeRelayed),
+				MessageType:           int(orm.MessageTypeL2SentMessage),
+				L1RelayBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 			})
-		case backendabi.L1FailedRelayedMessageEventSig:
-			event := backendabi.L1FailedRelayedMessageEvent{}
-			if err := utils.UnpackLog(backendabi.IL1ScrollMessengerABI, &event, "FailedRelayedMessage", vlog); err != nil {
+		case events.L1ScrollMessengerFailedRelayedMessageEventSig:
+			event, err := events.UnpackL1ScrollMessengerFailedRelayedMessageEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack FailedRelayedMessage event", "err", err)
 				return nil, nil, err
 			}
 			l1RelayedMessages = append(l1RelayedMessages, &orm.CrossMessage{
-				MessageHash:   event.MessageHash.String(),
-				L1BlockNumber: vlog.BlockNumber,
-				L1TxHash:      vlog.TxHash.String(),
-				TxStatus:      int(orm.TxStatusTypeFailedRelayed),
-				MessageType:   int(orm.MessageTypeL2SentMessage),
+				MessageHash:           event.MessageHash.String(),
+				L1BlockNumber:         vlog.BlockNumber,
+				L1TxHash:              vlog.TxHash.String(),
+				TxStatus:              int(orm.TxStatusTypeFailedRelayed),
+				MessageType:           int(orm.MessageTypeL2SentMessage),
+				L1RelayBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 			})
 		}
 	}
@@ -171,13 +176,13 @@ func (e *L1EventParser) ParseL1CrossChainEventLogs(ctx context.Context, logs []t
 }
 
 // ParseL1BatchEventLogs parses L1 watched batch events.
-func (e *L1EventParser) ParseL1BatchEventLogs(ctx context.Context, logs []types.Log, client *ethclient.Client) ([]*orm.BatchEvent, error) {
+func (e *L1EventParser) ParseL1BatchEventLogs(ctx context.Context, logs []types.Log, client *ethclient.Client, blockTimestampsMap map[uint64]uint64) ([]*orm.BatchEvent, error) {
 	var l1BatchEvents []*orm.BatchEvent
 	for _, vlog := range logs {
 		switch vlog.Topics[0] {
-		case backendabi.L1CommitBatchEventSig:
-			event := backendabi.L1CommitBatchEvent{}
-			if err := utils.UnpackLog(backendabi.IScrollChainABI, &event, "CommitBatch", vlog); err != nil {
+		case events.ScrollChainCommitBatchEventSig:
+			event, err := events.UnpackScrollChainCommitBatchEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack CommitBatch event", "err", err)
 				return nil, err
 			}
@@ -186,22 +191,18 @@ func (e *L1EventParser) ParseL1BatchEventLogs(ctx context.Context, logs []types.
 				log.Error("Failed to get commit batch tx or the tx is still pending", "err", err, "isPending", isPending)
 				return nil, err
 			}
-			startBlock, endBlock, err := utils.GetBatchRangeFromCalldata(commitTx.Data())
-			if err != nil {
-				log.Error("Failed to get batch range from calldata", "hash", commitTx.Hash().String(), "height", vlog.BlockNumber)
-				return nil, err
+			batch := &orm.BatchEvent{
+				BatchStatus:          int(orm.BatchStatusTypeCommitted),
+				BatchIndex:           event.BatchIndex.Uint64(),
+				BatchHash:            event.BatchHash.String(),
+				L1BlockNumber:        vlog.BlockNumber,
+				CommitBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 			}
-			l1BatchEvents = append(l1BatchEvents, &orm.BatchEvent{
-				BatchStatus:      int(orm.BatchStatusTypeCommitted),
-				BatchIndex:       event.BatchIndex.Uint64(),
-				BatchHash:        event.BatchHash.String(),
-				StartBlockNumber: startBlock,
-				EndBlockNumber:   endBlock,
-				L1BlockNumber:    vlog.BlockNumber,
-			})
-		case backendabi.L1RevertBatchEventSig:
-			event := backendabi.L1RevertBatchEvent{}
-			if err := utils.UnpackLog(backendabi.IScrollChainABI, &event, "RevertBatch", vlog); err != nil {
+			fillBatchCommitInfo(batch, commitTx)
+			l1BatchEvents = append(l1BatchEvents, batch)
+		case events.ScrollChainRevertBatchEventSig:
+			event, err := events.UnpackScrollChainRevertBatchEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack RevertBatch event", "err", err)
 				return nil, err
 			}
@@ -211,17 +212,20 @@ func (e *L1EventParser) ParseL1BatchEventLogs(ctx context.Context, logs []types.
 				BatchHash:     event.BatchHash.String(),
 				L1BlockNumber: vlog.BlockNumber,
 			})
-		case backendabi.L1FinalizeBatchEventSig:
-			event := backendabi.L1FinalizeBatchEvent{}
-			if err := utils.UnpackLog(backendabi.IScrollChainABI, &event, "FinalizeBatch", vlog); err != nil {
+		case events.ScrollChainFinalizeBatchEventSig:
+			event, err := events.UnpackScrollChainFinalizeBatchEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack FinalizeBatch event", "err", err)
 				return nil, err
 			}
 			l1BatchEvents = append(l1BatchEvents, &orm.BatchEvent{
-				BatchStatus:   int(orm.BatchStatusTypeFinalized),
-				BatchIndex:    event.BatchIndex.Uint64(),
-				BatchHash:     event.BatchHash.String(),
-				L1BlockNumber: vlog.BlockNumber,
+				BatchStatus:            int(orm.BatchStatusTypeFinalized),
+				BatchIndex:             event.BatchIndex.Uint64(),
+				BatchHash:              event.BatchHash.String(),
+				L1BlockNumber:          vlog.BlockNumber,
+				FinalizeBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
+				FinalizeL1BlockNumber:  vlog.BlockNumber,
+				FinalizeTxHash:         vlog.TxHash.String(),
 			})
 		}
 	}
@@ -238,51 +242,102 @@ func (e *L1EventParser) ParseL1MessageQueueEventLogs(logs []types.Log, l1Deposit
 	var l1MessageQueueEvents []*orm.MessageQueueEvent
 	for _, vlog := range logs {
 		switch vlog.Topics[0] {
-		case backendabi.L1QueueTransactionEventSig:
-			event := backendabi.L1QueueTransactionEvent{}
-			if err := utils.UnpackLog(backendabi.IL1MessageQueueABI, &event, "QueueTransaction", vlog); err != nil {
+		case events.L1MessageQueueQueueTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueQueueTransactionEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack QueueTransaction event", "err", err)
 				return nil, err
 			}
-			messageHash := common.BytesToHash(crypto.Keccak256(event.Data))
+			messageHash := crossdomain.HashEncodedMessage(event.Data)
 			// If the message hash is not found in the map, it's not a replayMessage or enforced tx (omitted); add it to the events.
 			if _, exists := messageHashes[messageHash]; !exists {
 				l1MessageQueueEvents = append(l1MessageQueueEvents, &orm.MessageQueueEvent{
-					EventType:   orm.MessageQueueEventTypeQueueTransaction,
-					QueueIndex:  event.QueueIndex,
-					MessageHash: messageHash,
-					TxHash:      vlog.TxHash,
+					EventType:     orm.MessageQueueEventTypeQueueTransaction,
+					QueueIndex:    event.QueueIndex,
+					L1BlockNumber: vlog.BlockNumber,
+					MessageHash:   messageHash,
+					TxHash:        vlog.TxHash,
 				})
 			}
-		case backendabi.L1DequeueTransactionEventSig:
-			event := backendabi.L1DequeueTransactionEvent{}
-			if err := utils.UnpackLog(backendabi.IL1MessageQueueABI, &event, "DequeueTransaction", vlog); err != nil {
+		case events.L1MessageQueueDequeueTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueDequeueTransactionEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack DequeueTransaction event", "err", err)
 				return nil, err
 			}
 			skippedIndices := utils.GetSkippedQueueIndices(event.StartIndex.Uint64(), event.SkippedBitmap)
 			for _, index := range skippedIndices {
 				l1MessageQueueEvents = append(l1MessageQueueEvents, &orm.MessageQueueEvent{
-					EventType:  orm.MessageQueueEventTypeDequeueTransaction,
-					QueueIndex: index,
+					EventType:     orm.MessageQueueEventTypeDequeueTransaction,
+					QueueIndex:    index,
+					L1BlockNumber: vlog.BlockNumber,
 				})
 			}
-		case backendabi.L1DropTransactionEventSig:
-			event := backendabi.L1DropTransactionEvent{}
-			if err := utils.UnpackLog(backendabi.IL1MessageQueueABI, &event, "DropTransaction", vlog); err != nil {
+		case events.L1MessageQueueDropTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueDropTransactionEvent(vlog)
+			if err != nil {
 				log.Error("Failed to unpack DropTransaction event", "err", err)
 				return nil, err
 			}
 			l1MessageQueueEvents = append(l1MessageQueueEvents, &orm.MessageQueueEvent{
-				EventType:  orm.MessageQueueEventTypeDropTransaction,
-				QueueIndex: event.Index.Uint64(),
-				TxHash:     vlog.TxHash,
+				EventType:     orm.MessageQueueEventTypeDropTransaction,
+				QueueIndex:    event.Index.Uint64(),
+				L1BlockNumber: vlog.BlockNumber,
+				TxHash:        vlog.TxHash,
 			})
+		case events.L1ETHGatewayRefundETHEventSig, events.L1ERC20GatewayRefundERC20EventSig,
+			events.L1ERC721GatewayRefundERC721EventSig, events.L1ERC721GatewayBatchRefundERC721EventSig,
+			events.L1ERC1155GatewayRefundERC1155EventSig, events.L1ERC1155GatewayBatchRefundERC1155EventSig:
+			// dropMessage calls back the gateway of the message, which refunds the sender after the message queue emitted
+			// DropTransaction, so the refund belongs to the latest drop of the same tx.
+			for i := len(l1MessageQueueEvents) - 1; i >= 0; i-- {
+				dropEvent := l1MessageQueueEvents[i]
+				if dropEvent.TxHash != vlog.TxHash {
+					break
+				}
+				if dropEvent.EventType == orm.MessageQueueEventTypeDropTransaction && !dropEvent.Refunded {
+					dropEvent.Refunded = true
+					break
+				}
+			}
 		}
 	}
 	return l1MessageQueueEvents, nil
 }
 
+// ParseL1MessageQueueCursors parses the cursors of the L1 message queue from the QueueTransaction and DequeueTransaction events,
+// one per block with such events, holding the highest indexes seen in the block. The logs are ordered by block number.
+func (e *L1EventParser) ParseL1MessageQueueCursors(logs []types.Log) ([]*orm.MessageQueueCursor, error) {
+	var cursors []*orm.MessageQueueCursor
+	cursorOf := func(blockNumber uint64) *orm.MessageQueueCursor {
+		if len(cursors) == 0 || cursors[len(cursors)-1].L1BlockNumber != blockNumber {
+			cursors = append(cursors, &orm.MessageQueueCursor{L1BlockNumber: blockNumber})
+		}
+		return cursors[len(cursors)-1]
+	}
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case events.L1MessageQueueQueueTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueQueueTransactionEvent(vlog)
+			if err != nil {
+				log.Error("Failed to unpack QueueTransaction event", "err", err)
+				return nil, err
+			}
+			cursor := cursorOf(vlog.BlockNumber)
+			cursor.NextQueueIndex = max(cursor.NextQueueIndex, event.QueueIndex+1)
+		case events.L1MessageQueueDequeueTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueDequeueTransactionEvent(vlog)
+			if err != nil {
+				log.Error("Failed to unpack DequeueTransaction event", "err", err)
+				return nil, err
+			}
+			cursor := cursorOf(vlog.BlockNumber)
+			cursor.PendingQueueIndex = max(cursor.PendingQueueIndex, event.StartIndex.Uint64()+event.Count.Uint64())
+		}
+	}
+	return cursors, nil
+}
+
 func getRealFromAddress(ctx context.Context, eventSender common.Address, eventMessage []byte, client *ethclient.Client, txHash common.Hash, gatewayRouterAddr string) (string, error) {
 	if eventSender != common.HexToAddress(gatewayRouterAddr) {
 		return eventSender.String(), nil
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/events"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestParseL1MessageQueueEventLogsRefunds(t *testing.T) {
+	dropLog := func(txHash common.Hash, queueIndex uint64) types.Log {
+		data := common.BigToHash(new(big.Int).SetUint64(queueIndex)).Bytes()
+		return types.Log{Topics: []common.Hash{events.L1MessageQueueDropTransactionEventSig}, Data: data, TxHash: txHash, BlockNumber: 10}
+	}
+	refundLog := func(txHash common.Hash, sig common.Hash) types.Log {
+		return types.Log{Topics: []common.Hash{sig}, TxHash: txHash, BlockNumber: 10}
+	}
+
+	// message 1 and 2 are dropped and refunded in the same tx, message 3 is dropped without a refund event, and the
+	// refund of another tx does not belong to any drop.
+	logs := []types.Log{
+		dropLog(common.HexToHash("0xa1"), 1),
+		refundLog(common.HexToHash("0xa1"), events.L1ETHGatewayRefundETHEventSig),
+		dropLog(common.HexToHash("0xa1"), 2),
+		refundLog(common.HexToHash("0xa1"), events.L1ERC20GatewayRefundERC20EventSig),
+		dropLog(common.HexToHash("0xa2"), 3),
+		refundLog(common.HexToHash("0xa3"), events.L1ERC721GatewayRefundERC721EventSig),
+	}
+	messageQueueEvents, err := NewL1EventParser(nil, nil).ParseL1MessageQueueEventLogs(logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, messageQueueEvents, 3)
+	for i, refunded := range []bool{true, true, false} {
+		assert.Equal(t, orm.MessageQueueEventTypeDropTransaction, messageQueueEvents[i].EventType)
+		assert.Equal(t, uint64(i+1), messageQueueEvents[i].QueueIndex)
+		assert.Equal(t, refunded, messageQueueEvents[i].Refunded)
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
-	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/common/metrics"
+	"scroll-tech/common/types/events"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
@@ -25,11 +27,16 @@ const L1ReorgSafeDepth = 64
 
 // L1FilterResult L1 fetcher result
 type L1FilterResult struct {
-	DepositMessages    []*orm.CrossMessage
-	RelayedMessages    []*orm.CrossMessage
-	BatchEvents        []*orm.BatchEvent
-	MessageQueueEvents []*orm.MessageQueueEvent
-	RevertedTxs        []*orm.CrossMessage
+	DepositMessages     []*orm.CrossMessage
+	RelayedMessages     []*orm.CrossMessage
+	BatchEvents         []*orm.BatchEvent
+	MessageQueueEvents  []*orm.MessageQueueEvent
+	MessageQueueCursors []*orm.MessageQueueCursor
+	RevertedTxs         []*orm.CrossMessage
+	// FromBlock is the first block of the fetched range, the message queue cursors from it on are replaced.
+	FromBlock uint64
+	// NumLogs is the number of event logs of the fetched range.
+	NumLogs int
 }
 
 // L1FetcherLogic the L1 fetcher logic
@@ -39,6 +46,7 @@ type L1FetcherLogic struct {
 	addressList     []common.Address
 	gatewayList     []common.Address
 	parser          *L1EventParser
+	txFees          *l1TxFeeFiller
 	db              *gorm.DB
 	crossMessageOrm *orm.CrossMessage
 	batchEventOrm   *orm.BatchEvent
@@ -104,9 +112,10 @@ func NewL1FetcherLogic(cfg *config.FetcherConfig, db *gorm.DB, client *ethclient
 		addressList:     addressList,
 		gatewayList:     gatewayList,
 		parser:          NewL1EventParser(cfg, client),
+		txFees:          newL1TxFeeFiller(client.Client()),
 	}
 
-	reg := prometheus.DefaultRegisterer
+	reg := metrics.Registerer()
 	f.l1FetcherLogicFetchedTotal = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 		Name: "L1_fetcher_logic_fetched_total",
 		Help: "The total number of events or failed txs fetched in L1 fetcher logic.",
@@ -198,22 +207,28 @@ func (f *L1FetcherLogic) l1FetcherLogs(ctx context.Context, from, to uint64) ([]
 		Topics:    make([][]common.Hash, 1),
 	}
 
-	query.Topics[0] = make([]common.Hash, 13)
-	query.Topics[0][0] = backendabi.L1DepositETHSig
-	query.Topics[0][1] = backendabi.L1DepositERC20Sig
-	query.Topics[0][2] = backendabi.L1DepositERC721Sig
-	query.Topics[0][3] = backendabi.L1DepositERC1155Sig
-	query.Topics[0][4] = backendabi.L1SentMessageEventSig
-	query.Topics[0][5] = backendabi.L1RelayedMessageEventSig
-	query.Topics[0][6] = backendabi.L1FailedRelayedMessageEventSig
-	query.Topics[0][7] = backendabi.L1CommitBatchEventSig
-	query.Topics[0][8] = backendabi.L1RevertBatchEventSig
-	query.Topics[0][9] = backendabi.L1FinalizeBatchEventSig
-	query.Topics[0][10] = backendabi.L1QueueTransactionEventSig
-	query.Topics[0][11] = backendabi.L1DequeueTransactionEventSig
-	query.Topics[0][12] = backendabi.L1DropTransactionEventSig
-
-	eventLogs, err := f.client.FilterLogs(ctx, query)
+	query.Topics[0] = make([]common.Hash, 19)
+	query.Topics[0][0] = events.L1ETHGatewayDepositETHEventSig
+	query.Topics[0][1] = events.L1ERC20GatewayDepositERC20EventSig
+	query.Topics[0][2] = events.L1ERC721GatewayDepositERC721EventSig
+	query.Topics[0][3] = events.L1ERC1155GatewayDepositERC1155EventSig
+	query.Topics[0][4] = events.L1ScrollMessengerSentMessageEventSig
+	query.Topics[0][5] = events.L1ScrollMessengerRelayedMessageEventSig
+	query.Topics[0][6] = events.L1ScrollMessengerFailedRelayedMessageEventSig
+	query.Topics[0][7] = events.ScrollChainCommitBatchEventSig
+	query.Topics[0][8] = events.ScrollChainRevertBatchEventSig
+	query.Topics[0][9] = events.ScrollChainFinalizeBatchEventSig
+	query.Topics[0][10] = events.L1MessageQueueQueueTransactionEventSig
+	query.Topics[0][11] = events.L1MessageQueueDequeueTransactionEventSig
+	query.Topics[0][12] = events.L1MessageQueueDropTransactionEventSig
+	query.Topics[0][13] = events.L1ETHGatewayRefundETHEventSig
+	query.Topics[0][14] = events.L1ERC20GatewayRefundERC20EventSig
+	query.Topics[0][15] = events.L1ERC721GatewayRefundERC721EventSig
+	query.Topics[0][16] = events.L1ERC721GatewayBatchRefundERC721EventSig
+	query.Topics[0][17] = events.L1ERC1155GatewayRefundERC1155EventSig
+	query.Topics[0][18] = events.L1ERC1155GatewayBatchRefundERC1155EventSig
+
+	eventLogs, err := utils.FilterLogsInAddressBatches(ctx, f.client, query, f.cfg.FilterAddressBatchSize)
 	if err != nil {
 		log.Error("failed to filter L1 event logs", "from", from, "to", to, "err", err)
 		return nil, err
@@ -253,7 +268,22 @@ func (f *L1FetcherLogic) L1Fetcher(ctx context.Context, from, to uint64, lastBlo
 		return false, 0, common.Hash{}, nil, err
 	}
 
-	l1BatchEvents, err := f.parser.ParseL1BatchEventLogs(ctx, eventLogs, f.client)
+	if err = fillRelayedMessageNonces(ctx, f.crossMessageOrm, blocks, l1RelayedMessages, func(m *orm.CrossMessage) string { return m.L1TxHash }, decodeL1RelayNonce); err != nil {
+		log.Error("failed to fill L1 relayed message nonces", "from", from, "to", to, "err", err)
+		return false, 0, common.Hash{}, nil, err
+	}
+
+	if err = fillClaimSenders(blocks, l1RelayedMessages); err != nil {
+		log.Error("failed to fill claim senders of L1 relayed messages", "from", from, "to", to, "err", err)
+		return false, 0, common.Hash{}, nil, err
+	}
+
+	if err = f.txFees.fillL1TxFees(ctx, l1DepositMessages, l1RelayedMessages); err != nil {
+		log.Error("failed to fill L1 tx fees", "from", from, "to", to, "err", err)
+		return false, 0, common.Hash{}, nil, err
+	}
+
+	l1BatchEvents, err := f.parser.ParseL1BatchEventLogs(ctx, eventLogs, f.client, blockTimestampsMap)
 	if err != nil {
 		log.Error("failed to parse L1 batch event logs", "from", from, "to", to, "err", err)
 		return false, 0, common.Hash{}, nil, err
@@ -265,12 +295,21 @@ func (f *L1FetcherLogic) L1Fetcher(ctx context.Context, from, to uint64, lastBlo
 		return false, 0, common.Hash{}, nil, err
 	}
 
+	l1MessageQueueCursors, err := f.parser.ParseL1MessageQueueCursors(eventLogs)
+	if err != nil {
+		log.Error("failed to parse L1 message queue cursors", "from", from, "to", to, "err", err)
+		return false, 0, common.Hash{}, nil, err
+	}
+
 	res := L1FilterResult{
-		DepositMessages:    l1DepositMessages,
-		RelayedMessages:    l1RelayedMessages,
-		BatchEvents:        l1BatchEvents,
-		MessageQueueEvents: l1MessageQueueEvents,
-		RevertedTxs:        l1RevertedTxs,
+		DepositMessages:     l1DepositMessages,
+		RelayedMessages:     l1RelayedMessages,
+		BatchEvents:         l1BatchEvents,
+		MessageQueueEvents:  l1MessageQueueEvents,
+		MessageQueueCursors: l1MessageQueueCursors,
+		RevertedTxs:         l1RevertedTxs,
+		FromBlock:           from,
+		NumLogs:             len(eventLogs),
 	}
 
 	f.updateMetrics(res)
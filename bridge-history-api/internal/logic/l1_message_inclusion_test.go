@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/events"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestL1MessageTxStatus(t *testing.T) {
+	messenger := common.HexToAddress("0x781e90f1c8Fc4611c9b7497C3B47F99Ef6969CbC")
+	messageHash := common.HexToHash("0x01")
+	receipt := func(status uint64, logs ...*types.Log) *types.Receipt {
+		return &types.Receipt{Status: status, Logs: logs}
+	}
+
+	assert.Equal(t, orm.TxStatusTypeRelayTxReverted, l1MessageTxStatus(receipt(types.ReceiptStatusFailed), messenger, messageHash.String()))
+	assert.Equal(t, orm.TxStatusTypeRelayed, l1MessageTxStatus(receipt(types.ReceiptStatusSuccessful,
+		&types.Log{Address: messenger, Topics: []common.Hash{events.L2ScrollMessengerRelayedMessageEventSig, messageHash}}), messenger, messageHash.String()))
+	assert.Equal(t, orm.TxStatusTypeFailedRelayed, l1MessageTxStatus(receipt(types.ReceiptStatusSuccessful,
+		&types.Log{Address: messenger, Topics: []common.Hash{events.L2ScrollMessengerFailedRelayedMessageEventSig, messageHash}}), messenger, messageHash.String()))
+
+	// events of other contracts or messages, and enforced txs without any, are not relays of the message.
+	assert.Equal(t, orm.TxStatusTypeSent, l1MessageTxStatus(receipt(types.ReceiptStatusSuccessful,
+		&types.Log{Address: common.HexToAddress("0x02"), Topics: []common.Hash{events.L2ScrollMessengerRelayedMessageEventSig, messageHash}},
+		&types.Log{Address: messenger, Topics: []common.Hash{events.L2ScrollMessengerRelayedMessageEventSig, common.HexToHash("0x03")}}), messenger, messageHash.String()))
+	assert.Equal(t, orm.TxStatusTypeSent, l1MessageTxStatus(receipt(types.ReceiptStatusSuccessful), messenger, messageHash.String()))
+}
+
+func TestRelayedMessagesOfInclusions(t *testing.T) {
+	inclusions := []*orm.L1MessageInclusion{
+		{QueueIndex: 1, MessageHash: "0x01", MessageNonce: 1, L2TxHash: "0xa1", L2BlockNumber: 10, L2BlockTimestamp: 100, TxStatus: int(orm.TxStatusTypeRelayed)},
+		{QueueIndex: 2, MessageHash: "0x02", MessageNonce: 2, L2TxHash: "0xa2", L2BlockNumber: 10, L2BlockTimestamp: 100, TxStatus: int(orm.TxStatusTypeFailedRelayed)},
+		// reverted relays are tracked from the L1 message txs already, enforced txs have no deposit.
+		{QueueIndex: 3, MessageHash: "0x03", L2TxHash: "0xa3", L2BlockNumber: 11, TxStatus: int(orm.TxStatusTypeRelayTxReverted)},
+		{QueueIndex: 4, MessageHash: "0x04", L2TxHash: "0xa4", L2BlockNumber: 11, TxStatus: int(orm.TxStatusTypeSent)},
+	}
+	indexed := []*orm.CrossMessage{{MessageHash: "0x01", L2TxHash: "0xa1", TxStatus: int(orm.TxStatusTypeRelayed)}}
+
+	recovered := relayedMessagesOfInclusions(inclusions, indexed)
+	assert.Len(t, recovered, 1)
+	assert.Equal(t, "0x02", recovered[0].MessageHash)
+	assert.Equal(t, uint64(2), recovered[0].MessageNonce)
+	assert.Equal(t, "0xa2", recovered[0].L2TxHash)
+	assert.Equal(t, uint64(10), recovered[0].L2BlockNumber)
+	assert.Equal(t, uint64(100), recovered[0].L2RelayBlockTimestamp)
+	assert.Equal(t, int(orm.TxStatusTypeFailedRelayed), recovered[0].TxStatus)
+	assert.Equal(t, int(orm.MessageTypeL1SentMessage), recovered[0].MessageType)
+}
@@ -0,0 +1,119 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+const (
+	// receiptBatchSize is the max number of receipts fetched in a single batch RPC request.
+	receiptBatchSize = 100
+
+	// receiptCacheSize is the max number of receipts kept across fetch rounds, so that a range fetched again,
+	// e.g. after a failed db write, does not fetch its receipts again.
+	receiptCacheSize = 10000
+)
+
+// l1TxReceipt is the part of a transaction receipt needed to derive the fee paid by the transaction.
+type l1TxReceipt struct {
+	BlockNumber       *hexutil.Big   `json:"blockNumber"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+}
+
+// batchCaller sends batch RPC requests, implemented by *rpc.Client.
+type batchCaller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// l1TxFeeFiller derives the fees paid by L1 txs from their receipts.
+type l1TxFeeFiller struct {
+	client batchCaller
+	cache  *lru.Cache // tx hash -> *l1TxReceipt
+}
+
+func newL1TxFeeFiller(client batchCaller) *l1TxFeeFiller {
+	cache, err := lru.New(receiptCacheSize)
+	if err != nil {
+		// only fails on a non-positive size.
+		panic(err)
+	}
+	return &l1TxFeeFiller{client: client, cache: cache}
+}
+
+// fillL1TxFees sets the gas used and the effective gas price of the L1 txs of the given messages,
+// i.e., the deposit txs of L1 messages and the claim txs of L2 messages.
+// Receipts are cached, and the missing ones are fetched in batch RPC requests. The cached receipt of a tx
+// since reorged into another block is fetched again.
+func (r *l1TxFeeFiller) fillL1TxFees(ctx context.Context, messageLists ...[]*orm.CrossMessage) error {
+	receipts := make(map[common.Hash]*l1TxReceipt)
+	blockNumbers := make(map[common.Hash]uint64)
+	var txHashes []common.Hash
+	for _, messages := range messageLists {
+		for _, message := range messages {
+			txHash := common.HexToHash(message.L1TxHash)
+			if _, found := blockNumbers[txHash]; found {
+				continue
+			}
+			blockNumbers[txHash] = message.L1BlockNumber
+			if cached, ok := r.cache.Get(txHash); ok {
+				if receipt := cached.(*l1TxReceipt); receipt.BlockNumber.ToInt().Uint64() == message.L1BlockNumber {
+					receipts[txHash] = receipt
+					continue
+				}
+			}
+			txHashes = append(txHashes, txHash)
+		}
+	}
+
+	for start := 0; start < len(txHashes); start += receiptBatchSize {
+		end := start + receiptBatchSize
+		if end > len(txHashes) {
+			end = len(txHashes)
+		}
+		batch := make([]rpc.BatchElem, end-start)
+		batchReceipts := make([]l1TxReceipt, end-start)
+		for i, txHash := range txHashes[start:end] {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{txHash},
+				Result: &batchReceipts[i],
+			}
+		}
+		if err := r.client.BatchCallContext(ctx, batch); err != nil {
+			return fmt.Errorf("failed to batch fetch receipts, error: %w", err)
+		}
+		for i, elem := range batch {
+			txHash := txHashes[start+i]
+			if elem.Error != nil {
+				return fmt.Errorf("failed to fetch receipt, tx hash: %v, error: %w", txHash, elem.Error)
+			}
+			receipt := &batchReceipts[i]
+			if receipt.BlockNumber == nil || receipt.EffectiveGasPrice == nil {
+				return fmt.Errorf("incomplete receipt, tx hash: %v", txHash)
+			}
+			if blockNumber := receipt.BlockNumber.ToInt().Uint64(); blockNumber != blockNumbers[txHash] {
+				// the tx was reorged into another block since its event was fetched, the range will be refetched.
+				return fmt.Errorf("receipt block mismatch, tx hash: %v, block number: %v", txHash, blockNumber)
+			}
+			r.cache.Add(txHash, receipt)
+			receipts[txHash] = receipt
+		}
+	}
+
+	for _, messages := range messageLists {
+		for _, message := range messages {
+			receipt := receipts[common.HexToHash(message.L1TxHash)]
+			message.L1TxGasUsed = uint64(receipt.GasUsed)
+			message.L1TxEffectiveGasPrice = receipt.EffectiveGasPrice.ToInt().String()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+type mockBatchCaller struct {
+	receipts map[common.Hash]*l1TxReceipt
+	calls    []common.Hash
+}
+
+func (m *mockBatchCaller) BatchCallContext(_ context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		txHash := b[i].Args[0].(common.Hash)
+		m.calls = append(m.calls, txHash)
+		receipt, ok := m.receipts[txHash]
+		if !ok {
+			b[i].Error = errors.New("not found")
+			continue
+		}
+		// round trip through json as the rpc client does.
+		data, err := json.Marshal(receipt)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, b[i].Result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestReceipt(blockNumber, gasUsed, gasPrice uint64) *l1TxReceipt {
+	return &l1TxReceipt{
+		BlockNumber:       (*hexutil.Big)(new(big.Int).SetUint64(blockNumber)),
+		GasUsed:           hexutil.Uint64(gasUsed),
+		EffectiveGasPrice: (*hexutil.Big)(new(big.Int).SetUint64(gasPrice)),
+	}
+}
+
+func TestFillL1TxFees(t *testing.T) {
+	txA, txB := common.HexToHash("0xa"), common.HexToHash("0xb")
+	client := &mockBatchCaller{receipts: map[common.Hash]*l1TxReceipt{
+		txA: newTestReceipt(10, 21000, 7),
+		txB: newTestReceipt(11, 50000, 9),
+	}}
+	filler := newL1TxFeeFiller(client)
+
+	// a tx shared by a deposit and a claim is fetched once.
+	deposits := []*orm.CrossMessage{{L1TxHash: txA.String(), L1BlockNumber: 10}, {L1TxHash: txB.String(), L1BlockNumber: 11}}
+	claims := []*orm.CrossMessage{{L1TxHash: txA.String(), L1BlockNumber: 10}}
+	assert.NoError(t, filler.fillL1TxFees(context.Background(), deposits, claims))
+	assert.Equal(t, []common.Hash{txA, txB}, client.calls)
+	assert.Equal(t, uint64(21000), deposits[0].L1TxGasUsed)
+	assert.Equal(t, "7", deposits[0].L1TxEffectiveGasPrice)
+	assert.Equal(t, uint64(50000), deposits[1].L1TxGasUsed)
+	assert.Equal(t, "9", deposits[1].L1TxEffectiveGasPrice)
+	assert.Equal(t, uint64(21000), claims[0].L1TxGasUsed)
+
+	// the receipts are cached across calls, e.g. a range fetched again.
+	client.calls = nil
+	deposits = []*orm.CrossMessage{{L1TxHash: txA.String(), L1BlockNumber: 10}}
+	assert.NoError(t, filler.fillL1TxFees(context.Background(), deposits))
+	assert.Empty(t, client.calls)
+	assert.Equal(t, uint64(21000), deposits[0].L1TxGasUsed)
+
+	// a tx reorged into another block is fetched again.
+	client.receipts[txA] = newTestReceipt(12, 30000, 8)
+	deposits = []*orm.CrossMessage{{L1TxHash: txA.String(), L1BlockNumber: 12}}
+	assert.NoError(t, filler.fillL1TxFees(context.Background(), deposits))
+	assert.Equal(t, []common.Hash{txA}, client.calls)
+	assert.Equal(t, uint64(30000), deposits[0].L1TxGasUsed)
+	assert.Equal(t, "8", deposits[0].L1TxEffectiveGasPrice)
+}
+
+func TestFillL1TxFeesErrors(t *testing.T) {
+	txA, txB := common.HexToHash("0xa"), common.HexToHash("0xb")
+	client := &mockBatchCaller{receipts: map[common.Hash]*l1TxReceipt{
+		txA: newTestReceipt(10, 21000, 7),
+	}}
+	filler := newL1TxFeeFiller(client)
+
+	// the event was fetched from another block than the receipt, the range is fetched again.
+	err := filler.fillL1TxFees(context.Background(), []*orm.CrossMessage{{L1TxHash: txA.String(), L1BlockNumber: 9}})
+	assert.ErrorContains(t, err, "receipt block mismatch")
+	assert.Equal(t, 0, filler.cache.Len())
+
+	err = filler.fillL1TxFees(context.Background(), []*orm.CrossMessage{{L1TxHash: txB.String(), L1BlockNumber: 10}})
+	assert.ErrorContains(t, err, "failed to fetch receipt")
+
+	client.receipts[txB] = &l1TxReceipt{BlockNumber: (*hexutil.Big)(big.NewInt(10))}
+	err = filler.fillL1TxFees(context.Background(), []*orm.CrossMessage{{L1TxHash: txB.String(), L1BlockNumber: 10}})
+	assert.ErrorContains(t, err, "incomplete receipt")
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 
-	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/common/types/crossdomain"
+	"scroll-tech/common/types/events"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
@@ -34,9 +36,8 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 	var l2RelayedMessages []*orm.CrossMessage
 	for _, vlog := range logs {
 		switch vlog.Topics[0] {
-		case backendabi.L2WithdrawETHSig:
-			event := backendabi.ETHMessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ETHGatewayABI, &event, "WithdrawETH", vlog)
+		case events.L2ETHGatewayWithdrawETHEventSig:
+			event, err := events.UnpackL2ETHGatewayWithdrawETHEvent(vlog)
 			if err != nil {
 				log.Error("Failed to unpack WithdrawETH event", "err", err)
 				return nil, nil, err
@@ -46,9 +47,8 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.Receiver = event.To.String()
 			lastMessage.TokenType = int(orm.TokenTypeETH)
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L2WithdrawERC20Sig:
-			event := backendabi.ERC20MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ERC20GatewayABI, &event, "WithdrawERC20", vlog)
+		case events.L2ERC20GatewayWithdrawERC20EventSig:
+			event, err := events.UnpackL2ERC20GatewayWithdrawERC20Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack WithdrawERC20 event", "err", err)
 				return nil, nil, err
@@ -60,9 +60,8 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L2WithdrawERC721Sig:
-			event := backendabi.ERC721MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ERC721GatewayABI, &event, "WithdrawERC721", vlog)
+		case events.L2ERC721GatewayWithdrawERC721EventSig:
+			event, err := events.UnpackL2ERC721GatewayWithdrawERC721Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack WithdrawERC721 event", "err", err)
 				return nil, nil, err
@@ -73,10 +72,9 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.TokenType = int(orm.TokenTypeERC721)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = event.TokenID.String()
-		case backendabi.L2BatchWithdrawERC721Sig:
-			event := backendabi.BatchERC721MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ERC721GatewayABI, &event, "BatchWithdrawERC721", vlog)
+			lastMessage.TokenIDs = event.TokenId.String()
+		case events.L2ERC721GatewayBatchWithdrawERC721EventSig:
+			event, err := events.UnpackL2ERC721GatewayBatchWithdrawERC721Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack BatchWithdrawERC721 event", "err", err)
 				return nil, nil, err
@@ -87,10 +85,9 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.TokenType = int(orm.TokenTypeERC721)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIDs)
-		case backendabi.L2WithdrawERC1155Sig:
-			event := backendabi.ERC1155MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ERC1155GatewayABI, &event, "WithdrawERC1155", vlog)
+			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIds)
+		case events.L2ERC1155GatewayWithdrawERC1155EventSig:
+			event, err := events.UnpackL2ERC1155GatewayWithdrawERC1155Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack WithdrawERC1155 event", "err", err)
 				return nil, nil, err
@@ -101,11 +98,10 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.TokenType = int(orm.TokenTypeERC1155)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = event.TokenID.String()
+			lastMessage.TokenIDs = event.TokenId.String()
 			lastMessage.TokenAmounts = event.Amount.String()
-		case backendabi.L2BatchWithdrawERC1155Sig:
-			event := backendabi.BatchERC1155MessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ERC1155GatewayABI, &event, "BatchWithdrawERC1155", vlog)
+		case events.L2ERC1155GatewayBatchWithdrawERC1155EventSig:
+			event, err := events.UnpackL2ERC1155GatewayBatchWithdrawERC1155Event(vlog)
 			if err != nil {
 				log.Error("Failed to unpack BatchWithdrawERC1155 event", "err", err)
 				return nil, nil, err
@@ -116,11 +112,10 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 			lastMessage.TokenType = int(orm.TokenTypeERC1155)
 			lastMessage.L1TokenAddress = event.L1Token.String()
 			lastMessage.L2TokenAddress = event.L2Token.String()
-			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIDs)
-			lastMessage.TokenAmounts = utils.ConvertBigIntArrayToString(event.TokenAmounts)
-		case backendabi.L2SentMessageEventSig:
-			event := backendabi.L2SentMessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ScrollMessengerABI, &event, "SentMessage", vlog)
+			lastMessage.TokenIDs = utils.ConvertBigIntArrayToString(event.TokenIds)
+			lastMessage.TokenAmounts = utils.ConvertBigIntArrayToString(event.Amounts)
+		case events.L2ScrollMessengerSentMessageEventSig:
+			event, err := events.UnpackL2ScrollMessengerSentMessageEvent(vlog)
 			if err != nil {
 				log.Error("Failed to unpack SentMessage event", "err", err)
 				return nil, nil, err
@@ -131,7 +126,7 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 				return nil, nil, err
 			}
 			l2WithdrawMessages = append(l2WithdrawMessages, &orm.CrossMessage{
-				MessageHash:    utils.ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message).String(),
+				MessageHash:    crossdomain.ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message).String(),
 				Sender:         from,
 				Receiver:       event.Target.String(),
 				TokenType:      int(orm.TokenTypeETH),
@@ -147,33 +142,33 @@ func (e *L2EventParser) ParseL2EventLogs(ctx context.Context, logs []types.Log,
 				BlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 				L2BlockNumber:  vlog.BlockNumber,
 			})
-		case backendabi.L2RelayedMessageEventSig:
-			event := backendabi.L2RelayedMessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ScrollMessengerABI, &event, "RelayedMessage", vlog)
+		case events.L2ScrollMessengerRelayedMessageEventSig:
+			event, err := events.UnpackL2ScrollMessengerRelayedMessageEvent(vlog)
 			if err != nil {
 				log.Error("Failed to unpack RelayedMessage event", "err", err)
 				return nil, nil, err
 			}
 			l2RelayedMessages = append(l2RelayedMessages, &orm.CrossMessage{
-				MessageHash:   event.MessageHash.String(),
-				L2BlockNumber: vlog.BlockNumber,
-				L2TxHash:      vlog.TxHash.String(),
-				TxStatus:      int(orm.TxStatusTypeRelayed),
-				MessageType:   int(orm.MessageTypeL1SentMessage),
+				MessageHash:           event.MessageHash.String(),
+				L2BlockNumber:         vlog.BlockNumber,
+				L2TxHash:              vlog.TxHash.String(),
+				TxStatus:              int(orm.TxStatusTypeRelayed),
+				MessageType:           int(orm.MessageTypeL1SentMessage),
+				L2RelayBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 			})
-		case backendabi.L2FailedRelayedMessageEventSig:
-			event := backendabi.L2RelayedMessageEvent{}
-			err := utils.UnpackLog(backendabi.IL2ScrollMessengerABI, &event, "FailedRelayedMessage", vlog)
+		case events.L2ScrollMessengerFailedRelayedMessageEventSig:
+			event, err := events.UnpackL2ScrollMessengerFailedRelayedMessageEvent(vlog)
 			if err != nil {
 				log.Error("Failed to unpack FailedRelayedMessage event", "err", err)
 				return nil, nil, err
 			}
 			l2RelayedMessages = append(l2RelayedMessages, &orm.CrossMessage{
-				MessageHash:   event.MessageHash.String(),
-				L2BlockNumber: vlog.BlockNumber,
-				L2TxHash:      vlog.TxHash.String(),
-				TxStatus:      int(orm.TxStatusTypeFailedRelayed),
-				MessageType:   int(orm.MessageTypeL1SentMessage),
+				MessageHash:           event.MessageHash.String(),
+				L2BlockNumber:         vlog.BlockNumber,
+				L2TxHash:              vlog.TxHash.String(),
+				TxStatus:              int(orm.TxStatusTypeFailedRelayed),
+				MessageType:           int(orm.MessageTypeL1SentMessage),
+				L2RelayBlockTimestamp: blockTimestampsMap[vlog.BlockNumber],
 			})
 		}
 	}
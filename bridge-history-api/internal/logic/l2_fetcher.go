@@ -9,12 +9,14 @@ import (
 	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
-	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
-	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/common/metrics"
+	"scroll-tech/common/types/crossdomain"
+	"scroll-tech/common/types/events"
+
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/utils"
@@ -29,6 +31,12 @@ type L2FilterResult struct {
 	WithdrawMessages []*orm.CrossMessage
 	RelayedMessages  []*orm.CrossMessage // relayed, failed relayed, relay tx reverted.
 	OtherRevertedTxs []*orm.CrossMessage // reverted txs except relay tx reverted.
+	NumLogs          int                 // number of event logs of the fetched range.
+
+	// L1MessageInclusions are the L1 messages executed by the L1 message txs of the fetched range.
+	L1MessageInclusions []*orm.L1MessageInclusion
+	// FeeVaultWithdrawals are the withdrawals of the fee vault of the fetched range, if it is configured.
+	FeeVaultWithdrawals []*orm.FeeVaultWithdrawal
 }
 
 // L2FetcherLogic the L2 fetcher logic
@@ -88,6 +96,11 @@ func NewL2FetcherLogic(cfg *config.FetcherConfig, db *gorm.DB, client *ethclient
 		gatewayList = append(gatewayList, common.HexToAddress(cfg.USDCGatewayAddr))
 	}
 
+	// Optional fee vault, its withdrawals are indexed separately.
+	if common.HexToAddress(cfg.FeeVaultAddr) != (common.Address{}) {
+		addressList = append(addressList, common.HexToAddress(cfg.FeeVaultAddr))
+	}
+
 	log.Info("L2 Fetcher configured with the following address list", "addresses", addressList, "gateways", gatewayList)
 
 	f := &L2FetcherLogic{
@@ -101,7 +114,7 @@ func NewL2FetcherLogic(cfg *config.FetcherConfig, db *gorm.DB, client *ethclient
 		parser:          NewL2EventParser(cfg, client),
 	}
 
-	reg := prometheus.DefaultRegisterer
+	reg := metrics.Registerer()
 	f.l2FetcherLogicFetchedTotal = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 		Name: "L2_fetcher_logic_fetched_total",
 		Help: "The total number of events or failed txs fetched in L2 fetcher logic.",
@@ -137,9 +150,12 @@ func (f *L2FetcherLogic) getBlocksAndDetectReorg(ctx context.Context, from, to u
 	return false, 0, lastBlockHash, blocks, nil
 }
 
-func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, blocks []*types.Block) (map[uint64]uint64, []*orm.CrossMessage, []*orm.CrossMessage, error) {
+// getRevertedTxs returns the block timestamps, the reverted gateway txs and relay txs of the blocks, and the
+// inclusions of the L1 messages executed by their L1 message txs.
+func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, blocks []*types.Block) (map[uint64]uint64, []*orm.CrossMessage, []*orm.CrossMessage, []*orm.L1MessageInclusion, error) {
 	var l2RevertedUserTxs []*orm.CrossMessage
 	var l2RevertedRelayedMessageTxs []*orm.CrossMessage
+	var l1MessageInclusions []*orm.L1MessageInclusion
 	blockTimestampsMap := make(map[uint64]uint64)
 
 	for i := from; i <= to; i++ {
@@ -151,13 +167,27 @@ func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, bl
 				receipt, receiptErr := f.client.TransactionReceipt(ctx, tx.Hash())
 				if receiptErr != nil {
 					log.Error("Failed to get transaction receipt", "txHash", tx.Hash().String(), "err", receiptErr)
-					return nil, nil, nil, receiptErr
+					return nil, nil, nil, nil, receiptErr
 				}
 
+				messageHash := crossdomain.HashEncodedMessage(tx.AsL1MessageTx().Data).String()
+				// L1 messages not sent by the messenger, e.g. enforced txs, carry no message nonce.
+				messageNonce, _ := decodeL2RelayNonce(tx.AsL1MessageTx().Data, messageHash)
+				l1MessageInclusions = append(l1MessageInclusions, &orm.L1MessageInclusion{
+					QueueIndex:       tx.AsL1MessageTx().QueueIndex,
+					MessageHash:      messageHash,
+					MessageNonce:     messageNonce,
+					L2TxHash:         tx.Hash().String(),
+					L2BlockNumber:    block.NumberU64(),
+					L2BlockTimestamp: block.Time(),
+					TxStatus:         int(l1MessageTxStatus(receipt, common.HexToAddress(f.cfg.MessengerAddr), messageHash)),
+				})
+
 				// Check if the transaction is failed
 				if receipt.Status == types.ReceiptStatusFailed {
 					l2RevertedRelayedMessageTxs = append(l2RevertedRelayedMessageTxs, &orm.CrossMessage{
-						MessageHash:   common.BytesToHash(crypto.Keccak256(tx.AsL1MessageTx().Data)).String(),
+						MessageHash:   messageHash,
+						MessageNonce:  messageNonce,
 						L2TxHash:      tx.Hash().String(),
 						TxStatus:      int(orm.TxStatusTypeRelayTxReverted),
 						L2BlockNumber: receipt.BlockNumber.Uint64(),
@@ -175,7 +205,7 @@ func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, bl
 			receipt, receiptErr := f.client.TransactionReceipt(ctx, tx.Hash())
 			if receiptErr != nil {
 				log.Error("Failed to get transaction receipt", "txHash", tx.Hash().String(), "err", receiptErr)
-				return nil, nil, nil, receiptErr
+				return nil, nil, nil, nil, receiptErr
 			}
 
 			// Check if the transaction is failed
@@ -184,7 +214,7 @@ func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, bl
 				sender, signerErr := signer.Sender(tx)
 				if signerErr != nil {
 					log.Error("get sender failed", "chain id", tx.ChainId().Uint64(), "tx hash", tx.Hash().String(), "err", signerErr)
-					return nil, nil, nil, signerErr
+					return nil, nil, nil, nil, signerErr
 				}
 
 				l2RevertedUserTxs = append(l2RevertedUserTxs, &orm.CrossMessage{
@@ -199,7 +229,57 @@ func (f *L2FetcherLogic) getRevertedTxs(ctx context.Context, from, to uint64, bl
 			}
 		}
 	}
-	return blockTimestampsMap, l2RevertedUserTxs, l2RevertedRelayedMessageTxs, nil
+	return blockTimestampsMap, l2RevertedUserTxs, l2RevertedRelayedMessageTxs, l1MessageInclusions, nil
+}
+
+// l1MessageTxStatus returns the status of the message executed by an L1 message tx, according to the events of the
+// messenger in its receipt. It is TxStatusTypeSent for a successful tx without any, e.g. an enforced tx.
+func l1MessageTxStatus(receipt *types.Receipt, messenger common.Address, messageHash string) orm.TxStatusType {
+	if receipt.Status == types.ReceiptStatusFailed {
+		return orm.TxStatusTypeRelayTxReverted
+	}
+	for _, vlog := range receipt.Logs {
+		if vlog.Address != messenger || len(vlog.Topics) < 2 || vlog.Topics[1].String() != messageHash {
+			continue
+		}
+		switch vlog.Topics[0] {
+		case events.L2ScrollMessengerRelayedMessageEventSig:
+			return orm.TxStatusTypeRelayed
+		case events.L2ScrollMessengerFailedRelayedMessageEventSig:
+			return orm.TxStatusTypeFailedRelayed
+		}
+	}
+	return orm.TxStatusTypeSent
+}
+
+// relayedMessagesOfInclusions returns the relayed and failed relayed messages of the inclusions which have no relayed
+// message event among the fetched ones, e.g. as the event was not indexed, so that their deposits are matched to
+// their L2 execution anyway.
+func relayedMessagesOfInclusions(inclusions []*orm.L1MessageInclusion, relayedMessages []*orm.CrossMessage) []*orm.CrossMessage {
+	indexed := make(map[string]bool, len(relayedMessages))
+	for _, message := range relayedMessages {
+		indexed[message.L2TxHash+message.MessageHash] = true
+	}
+	var recovered []*orm.CrossMessage
+	for _, inclusion := range inclusions {
+		status := orm.TxStatusType(inclusion.TxStatus)
+		if status != orm.TxStatusTypeRelayed && status != orm.TxStatusTypeFailedRelayed {
+			continue
+		}
+		if indexed[inclusion.L2TxHash+inclusion.MessageHash] {
+			continue
+		}
+		recovered = append(recovered, &orm.CrossMessage{
+			MessageHash:           inclusion.MessageHash,
+			MessageNonce:          inclusion.MessageNonce,
+			L2BlockNumber:         inclusion.L2BlockNumber,
+			L2TxHash:              inclusion.L2TxHash,
+			TxStatus:              inclusion.TxStatus,
+			MessageType:           int(orm.MessageTypeL1SentMessage),
+			L2RelayBlockTimestamp: inclusion.L2BlockTimestamp,
+		})
+	}
+	return recovered
 }
 
 func (f *L2FetcherLogic) l2FetcherLogs(ctx context.Context, from, to uint64) ([]types.Log, error) {
@@ -209,16 +289,17 @@ func (f *L2FetcherLogic) l2FetcherLogs(ctx context.Context, from, to uint64) ([]
 		Addresses: f.addressList,
 		Topics:    make([][]common.Hash, 1),
 	}
-	query.Topics[0] = make([]common.Hash, 7)
-	query.Topics[0][0] = backendabi.L2WithdrawETHSig
-	query.Topics[0][1] = backendabi.L2WithdrawERC20Sig
-	query.Topics[0][2] = backendabi.L2WithdrawERC721Sig
-	query.Topics[0][3] = backendabi.L2WithdrawERC1155Sig
-	query.Topics[0][4] = backendabi.L2SentMessageEventSig
-	query.Topics[0][5] = backendabi.L2RelayedMessageEventSig
-	query.Topics[0][6] = backendabi.L2FailedRelayedMessageEventSig
-
-	eventLogs, err := f.client.FilterLogs(ctx, query)
+	query.Topics[0] = make([]common.Hash, 8)
+	query.Topics[0][0] = events.L2ETHGatewayWithdrawETHEventSig
+	query.Topics[0][1] = events.L2ERC20GatewayWithdrawERC20EventSig
+	query.Topics[0][2] = events.L2ERC721GatewayWithdrawERC721EventSig
+	query.Topics[0][3] = events.L2ERC1155GatewayWithdrawERC1155EventSig
+	query.Topics[0][4] = events.L2ScrollMessengerSentMessageEventSig
+	query.Topics[0][5] = events.L2ScrollMessengerRelayedMessageEventSig
+	query.Topics[0][6] = events.L2ScrollMessengerFailedRelayedMessageEventSig
+	query.Topics[0][7] = events.L2TxFeeVaultWithdrawalEventSig
+
+	eventLogs, err := utils.FilterLogsInAddressBatches(ctx, f.client, query, f.cfg.FilterAddressBatchSize)
 	if err != nil {
 		log.Error("Failed to filter L2 event logs", "from", from, "to", to, "err", err)
 		return nil, err
@@ -240,7 +321,7 @@ func (f *L2FetcherLogic) L2Fetcher(ctx context.Context, from, to uint64, lastBlo
 		return isReorg, reorgHeight, blockHash, nil, nil
 	}
 
-	blockTimestampsMap, revertedUserTxs, revertedRelayMsgs, routerErr := f.getRevertedTxs(ctx, from, to, blocks)
+	blockTimestampsMap, revertedUserTxs, revertedRelayMsgs, l1MessageInclusions, routerErr := f.getRevertedTxs(ctx, from, to, blocks)
 	if routerErr != nil {
 		log.Error("L2Fetcher getRevertedTxs failed", "from", from, "to", to, "error", routerErr)
 		return false, 0, common.Hash{}, nil, routerErr
@@ -258,10 +339,38 @@ func (f *L2FetcherLogic) L2Fetcher(ctx context.Context, from, to uint64, lastBlo
 		return false, 0, common.Hash{}, nil, err
 	}
 
+	if err = fillRelayedMessageNonces(ctx, f.crossMessageOrm, blocks, l2RelayedMessages, func(m *orm.CrossMessage) string { return m.L2TxHash }, decodeL2RelayNonce); err != nil {
+		log.Error("failed to fill L2 relayed message nonces", "from", from, "to", to, "err", err)
+		return false, 0, common.Hash{}, nil, err
+	}
+
+	var feeVaultWithdrawals []*orm.FeeVaultWithdrawal
+	if feeVault := common.HexToAddress(f.cfg.FeeVaultAddr); feeVault != (common.Address{}) {
+		feeVaultWithdrawals, err = parseFeeVaultWithdrawals(eventLogs, feeVault, l2WithdrawMessages, blockTimestampsMap)
+		if err != nil {
+			log.Error("failed to parse L2 fee vault withdrawals", "from", from, "to", to, "err", err)
+			return false, 0, common.Hash{}, nil, err
+		}
+	}
+
+	recoveredRelayedMsgs := relayedMessagesOfInclusions(l1MessageInclusions, l2RelayedMessages)
+	if len(recoveredRelayedMsgs) > 0 {
+		log.Warn("relayed messages without indexed events recovered from L1 message txs", "from", from, "to", to, "count", len(recoveredRelayedMsgs))
+	}
+	f.l2FetcherLogicFetchedTotal.WithLabelValues("L2_recovered_relayed_message").Add(float64(len(recoveredRelayedMsgs)))
+	l2RelayedMessages = append(l2RelayedMessages, recoveredRelayedMsgs...)
+
 	res := L2FilterResult{
-		WithdrawMessages: l2WithdrawMessages,
-		RelayedMessages:  append(l2RelayedMessages, revertedRelayMsgs...),
-		OtherRevertedTxs: revertedUserTxs,
+		WithdrawMessages:    l2WithdrawMessages,
+		RelayedMessages:     append(l2RelayedMessages, revertedRelayMsgs...),
+		OtherRevertedTxs:    revertedUserTxs,
+		L1MessageInclusions: l1MessageInclusions,
+		FeeVaultWithdrawals: feeVaultWithdrawals,
+		NumLogs:             len(eventLogs),
+	}
+
+	if f.cfg.TraceFailedRelays {
+		f.fillRelayFailureReasons(ctx, res.RelayedMessages)
 	}
 
 	f.updateMetrics(res)
@@ -271,6 +380,8 @@ func (f *L2FetcherLogic) L2Fetcher(ctx context.Context, from, to uint64, lastBlo
 
 func (f *L2FetcherLogic) updateMetrics(res L2FilterResult) {
 	f.l2FetcherLogicFetchedTotal.WithLabelValues("L2_failed_gateway_router_transaction").Add(float64(len(res.OtherRevertedTxs)))
+	f.l2FetcherLogicFetchedTotal.WithLabelValues("L2_l1_message_inclusion").Add(float64(len(res.L1MessageInclusions)))
+	f.l2FetcherLogicFetchedTotal.WithLabelValues("L2_fee_vault_withdrawal").Add(float64(len(res.FeeVaultWithdrawals)))
 
 	for _, withdrawMessage := range res.WithdrawMessages {
 		switch orm.TokenType(withdrawMessage.TokenType) {
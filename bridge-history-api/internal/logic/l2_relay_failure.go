@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/utils"
+)
+
+// callFrame is a call frame of the callTracer.
+type callFrame struct {
+	Output hexutil.Bytes `json:"output"`
+	Error  string        `json:"error"`
+	Calls  []callFrame   `json:"calls"`
+}
+
+// fillRelayFailureReasons sets the decoded revert reasons of failed relays of L1 messages.
+// It is best effort: the reason is left empty if the transaction can not be traced, e.g. the debug namespace is not exposed.
+func (f *L2FetcherLogic) fillRelayFailureReasons(ctx context.Context, relayedMessages []*orm.CrossMessage) {
+	for _, message := range relayedMessages {
+		txStatus := orm.TxStatusType(message.TxStatus)
+		if txStatus != orm.TxStatusTypeFailedRelayed && txStatus != orm.TxStatusTypeRelayTxReverted {
+			continue
+		}
+		selector, reason, err := f.traceRelayFailure(ctx, message.L2TxHash, txStatus)
+		if err != nil {
+			log.Warn("failed to trace failed relay", "tx hash", message.L2TxHash, "message hash", message.MessageHash, "err", err)
+			continue
+		}
+		message.L2RelayFailureSelector = selector
+		message.L2RelayFailureReason = reason
+	}
+}
+
+// traceRelayFailure returns the error selector and the reason of a failed relay.
+// A reverted relay tx fails in the messenger itself, e.g. the message was already executed, so the top call frame is decoded.
+// A FailedRelayedMessage is emitted when the call from the messenger to the target fails, so the failed sub call is decoded.
+// In both cases the revert may originate deeper, e.g. in a token contract called by the gateway, so the errored frames are
+// followed down to the one where the error was raised.
+func (f *L2FetcherLogic) traceRelayFailure(ctx context.Context, txHash string, txStatus orm.TxStatusType) (string, string, error) {
+	var frame callFrame
+	if err := f.client.Client().CallContext(ctx, &frame, "debug_traceTransaction", txHash, map[string]interface{}{"tracer": "callTracer"}); err != nil {
+		return "", "", fmt.Errorf("failed to trace transaction, error: %w", err)
+	}
+
+	if txStatus == orm.TxStatusTypeFailedRelayed {
+		failed := lastErroredCall(frame.Calls)
+		if failed == nil {
+			return "", "", fmt.Errorf("failed sub call not found")
+		}
+		frame = *failed
+	}
+
+	selector, reason := decodeFrameFailure(deepestErroredFrame(&frame))
+	return selector, reason, nil
+}
+
+// deepestErroredFrame follows the errored sub calls of an errored frame down to the frame where the error was raised.
+// The last errored sub call is followed, as earlier ones may have been caught by the caller.
+func deepestErroredFrame(frame *callFrame) *callFrame {
+	for {
+		next := lastErroredCall(frame.Calls)
+		if next == nil {
+			return frame
+		}
+		frame = next
+	}
+}
+
+// lastErroredCall returns the last errored call of the given calls, nil if none errored.
+func lastErroredCall(calls []callFrame) *callFrame {
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i].Error != "" {
+			return &calls[i]
+		}
+	}
+	return nil
+}
+
+// decodeFrameFailure decodes the revert data of an errored frame.
+func decodeFrameFailure(frame *callFrame) (string, string) {
+	selector, reason := utils.DecodeRevertReason(frame.Output)
+	if reason == "" {
+		// no revert data, e.g. out of gas, fall back to the error of the call frame.
+		reason = frame.Error
+	}
+	return selector, reason
+}
@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepestErroredFrame(t *testing.T) {
+	// Error(string) "insufficient balance"
+	revert := common.FromHex("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"696e73756666696369656e742062616c616e6365000000000000000000000000")
+
+	// messenger -> gateway -> token, the token reverts and the gateway bubbles up a generic error,
+	// an earlier errored call of the gateway was caught.
+	frame := callFrame{
+		Error: "execution reverted",
+		Calls: []callFrame{{
+			Error:  "execution reverted",
+			Output: common.FromHex("0xdeadbeef"),
+			Calls: []callFrame{
+				{Error: "out of gas"},
+				{},
+				{Error: "execution reverted", Output: revert},
+			},
+		}},
+	}
+	selector, reason := decodeFrameFailure(deepestErroredFrame(&frame))
+	assert.Equal(t, "0x08c379a0", selector)
+	assert.Equal(t, "insufficient balance", reason)
+
+	// no revert data in the deepest frame, the frame error is used.
+	frame = callFrame{Error: "execution reverted", Calls: []callFrame{{Error: "out of gas"}}}
+	selector, reason = decodeFrameFailure(deepestErroredFrame(&frame))
+	assert.Empty(t, selector)
+	assert.Equal(t, "out of gas", reason)
+
+	// no errored sub call, the frame itself is decoded.
+	frame = callFrame{Error: "execution reverted", Output: revert, Calls: []callFrame{{}}}
+	assert.Equal(t, &frame, deepestErroredFrame(&frame))
+	assert.Nil(t, lastErroredCall(frame.Calls))
+}
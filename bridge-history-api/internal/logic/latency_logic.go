@@ -0,0 +1,134 @@
+package logic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+const (
+	defaultLatencyRefreshInterval = 1 * time.Minute
+	defaultLatencyWindow          = 24 * time.Hour
+	// latencyRefreshTimeout bounds the queries of a single refresh of the stage latencies.
+	latencyRefreshTimeout = 30 * time.Second
+)
+
+// LatencyLogic maintains the latency statistics of each stage of the messages which reached it within a recent window,
+// computed from the timestamps the fetchers store for each stage. It is a prometheus collector exposing them as the
+// bridge_history_api_stage_latency_seconds histograms, so that SLOs of the bridge are monitored.
+type LatencyLogic struct {
+	crossMessageOrm *orm.CrossMessage
+	interval        time.Duration
+	window          time.Duration
+	now             func() time.Time
+
+	mu        sync.RWMutex
+	latencies []*orm.StageLatency // nil until the first successful refresh
+	updatedAt time.Time
+
+	stageLatencySeconds *prometheus.Desc
+}
+
+// NewLatencyLogic returns the stage latency services, registered as a collector to reg.
+func NewLatencyLogic(cfg *config.LatencyConfig, db *gorm.DB, reg prometheus.Registerer) *LatencyLogic {
+	l := &LatencyLogic{
+		crossMessageOrm: orm.NewCrossMessage(db),
+		interval:        defaultLatencyRefreshInterval,
+		window:          defaultLatencyWindow,
+		now:             time.Now,
+		stageLatencySeconds: prometheus.NewDesc("bridge_history_api_stage_latency_seconds",
+			"The latency of each stage of the messages which reached it within the window.", []string{"stage"}, nil),
+	}
+	if cfg.IntervalSec > 0 {
+		l.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.WindowSec > 0 {
+		l.window = time.Duration(cfg.WindowSec) * time.Second
+	}
+	reg.MustRegister(l)
+	return l
+}
+
+// Start refreshes the stage latencies once, then periodically in the background until ctx is done.
+func (l *LatencyLogic) Start(ctx context.Context) {
+	l.refresh(ctx)
+	go func() {
+		tick := time.NewTicker(l.interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				l.refresh(ctx)
+			}
+		}
+	}()
+}
+
+func (l *LatencyLogic) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, latencyRefreshTimeout)
+	defer cancel()
+
+	now := l.now()
+	since := now.Add(-l.window).Unix()
+	// A failed query keeps the previous statistics, their update time tells they are stale.
+	latencies, err := l.crossMessageOrm.GetStageLatencies(ctx, uint64(max(since, 0)))
+	if err != nil {
+		log.Error("failed to refresh stage latencies", "err", err)
+		return
+	}
+	l.mu.Lock()
+	l.latencies, l.updatedAt = latencies, now
+	l.mu.Unlock()
+}
+
+// GetStageLatencies returns the latest statistics of each stage, nil if they are not computed yet.
+func (l *LatencyLogic) GetStageLatencies() *types.StageLatencyData {
+	l.mu.RLock()
+	latencies, updatedAt := l.latencies, l.updatedAt
+	l.mu.RUnlock()
+	if latencies == nil {
+		return nil
+	}
+
+	results := make([]*types.StageLatencyInfo, 0, len(latencies))
+	for _, latency := range latencies {
+		results = append(results, &types.StageLatencyInfo{
+			Stage:       latency.Stage,
+			SampleCount: latency.SampleCount,
+			P50:         latency.P50Sec,
+			P90:         latency.P90Sec,
+			P99:         latency.P99Sec,
+		})
+	}
+	return &types.StageLatencyData{WindowSec: uint64(l.window / time.Second), UpdatedAt: uint64(updatedAt.Unix()), Results: results}
+}
+
+// Describe implements prometheus.Collector.
+func (l *LatencyLogic) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.stageLatencySeconds
+}
+
+// Collect implements prometheus.Collector, the histograms are the ones of the latest refresh.
+func (l *LatencyLogic) Collect(ch chan<- prometheus.Metric) {
+	l.mu.RLock()
+	latencies := l.latencies
+	l.mu.RUnlock()
+
+	for _, latency := range latencies {
+		buckets := make(map[float64]uint64, len(orm.StageLatencyBuckets))
+		for i, upperBound := range orm.StageLatencyBuckets {
+			buckets[upperBound] = latency.BucketCounts[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(l.stageLatencySeconds, latency.SampleCount, latency.SumSec, buckets, latency.Stage)
+	}
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestLatencyLogic(t *testing.T) {
+	l := NewLatencyLogic(&config.LatencyConfig{Enabled: true, WindowSec: 3600}, nil, prometheus.NewRegistry())
+
+	// nothing is served nor exposed before the first refresh.
+	assert.Nil(t, l.GetStageLatencies())
+	assert.Zero(t, testutil.CollectAndCount(l))
+
+	bucketCounts := make([]uint64, len(orm.StageLatencyBuckets))
+	for i := 2; i < len(bucketCounts); i++ {
+		bucketCounts[i] = 2
+	}
+	l.latencies = []*orm.StageLatency{
+		{Stage: orm.StageDepositRelay, SampleCount: 2, SumSec: 200, P50Sec: 100, P90Sec: 100, P99Sec: 100, BucketCounts: bucketCounts},
+	}
+	l.updatedAt = time.Unix(1000, 0)
+
+	data := l.GetStageLatencies()
+	assert.Equal(t, uint64(3600), data.WindowSec)
+	assert.Equal(t, uint64(1000), data.UpdatedAt)
+	assert.Len(t, data.Results, 1)
+	assert.Equal(t, orm.StageDepositRelay, data.Results[0].Stage)
+	assert.Equal(t, float64(100), data.Results[0].P90)
+
+	expected := `
+# HELP bridge_history_api_stage_latency_seconds The latency of each stage of the messages which reached it within the window.
+# TYPE bridge_history_api_stage_latency_seconds histogram
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="30"} 0
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="60"} 0
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="120"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="300"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="600"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="1200"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="1800"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="3600"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="7200"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="14400"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="28800"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="86400"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="259200"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="604800"} 2
+bridge_history_api_stage_latency_seconds_bucket{stage="deposit_relay",le="+Inf"} 2
+bridge_history_api_stage_latency_seconds_sum{stage="deposit_relay"} 200
+bridge_history_api_stage_latency_seconds_count{stage="deposit_relay"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(l, strings.NewReader(expected)))
+}
@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// l2MessageProof is the L2MessageProof struct of the L1 messenger.
+type l2MessageProof struct {
+	BatchIndex  *big.Int
+	MerkleProof []byte
+}
+
+// messageCallArgs returns the from, to, value, nonce and message arguments shared by the messenger calls of a message.
+func messageCallArgs(message *orm.CrossMessage) (common.Address, common.Address, *big.Int, *big.Int, []byte, error) {
+	value, ok := new(big.Int).SetString(message.MessageValue, 10)
+	if !ok {
+		return common.Address{}, common.Address{}, nil, nil, nil, fmt.Errorf("invalid message value %q", message.MessageValue)
+	}
+	return common.HexToAddress(message.MessageFrom), common.HexToAddress(message.MessageTo), value,
+		new(big.Int).SetUint64(message.MessageNonce), common.FromHex(message.MessageData), nil
+}
+
+// ClaimCalldata builds the calldata of relayMessageWithProof on the L1 messenger, which claims a finalized L2 withdrawal.
+// The message data of the withdrawal must be loaded.
+func ClaimCalldata(message *orm.CrossMessage) ([]byte, error) {
+	if orm.MessageType(message.MessageType) != orm.MessageTypeL2SentMessage {
+		return nil, fmt.Errorf("message %s is not an L2 withdrawal", message.MessageHash)
+	}
+	if orm.RollupStatusType(message.RollupStatus) != orm.RollupStatusTypeFinalized || len(message.MerkleProof) == 0 {
+		return nil, fmt.Errorf("the batch of message %s is not finalized yet", message.MessageHash)
+	}
+	from, to, value, nonce, data, err := messageCallArgs(message)
+	if err != nil {
+		return nil, err
+	}
+	proof := l2MessageProof{BatchIndex: new(big.Int).SetUint64(message.BatchIndex), MerkleProof: message.MerkleProof}
+	return backendabi.IL1ScrollMessengerABI.Pack("relayMessageWithProof", from, to, value, nonce, data, proof)
+}
+
+// ReplayCalldata builds the calldata of replayMessage on the L1 messenger, which replays an L1 deposit with a new
+// gas limit, e.g. after its relay ran out of gas on L2. The excess fee is refunded to the refund address.
+func ReplayCalldata(message *orm.CrossMessage, gasLimit uint32, refundAddress common.Address) ([]byte, error) {
+	if orm.MessageType(message.MessageType) != orm.MessageTypeL1SentMessage {
+		return nil, fmt.Errorf("message %s is not an L1 deposit", message.MessageHash)
+	}
+	from, to, value, nonce, data, err := messageCallArgs(message)
+	if err != nil {
+		return nil, err
+	}
+	return backendabi.IL1ScrollMessengerABI.Pack("replayMessage", from, to, value, nonce, data, gasLimit, refundAddress)
+}
@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestClaimCalldata(t *testing.T) {
+	message := &orm.CrossMessage{
+		MessageHash:  "0x01",
+		MessageType:  int(orm.MessageTypeL2SentMessage),
+		RollupStatus: int(orm.RollupStatusTypeFinalized),
+		MessageFrom:  "0x0000000000000000000000000000000000000001",
+		MessageTo:    "0x0000000000000000000000000000000000000002",
+		MessageValue: "100",
+		MessageNonce: 3,
+		MessageData:  "0x1234",
+		MerkleProof:  common.FromHex("0xaabb"),
+		BatchIndex:   4,
+	}
+	data, err := ClaimCalldata(message)
+	assert.NoError(t, err)
+
+	method := backendabi.IL1ScrollMessengerABI.Methods["relayMessageWithProof"]
+	assert.Equal(t, method.ID, data[:4])
+	args, err := method.Inputs.Unpack(data[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(message.MessageFrom), args[0])
+	assert.Equal(t, common.HexToAddress(message.MessageTo), args[1])
+	assert.Equal(t, big.NewInt(100), args[2])
+	assert.Equal(t, big.NewInt(3), args[3])
+	assert.Equal(t, common.FromHex("0x1234"), args[4])
+
+	// the batch of the withdrawal must be finalized.
+	message.RollupStatus = int(orm.RollupStatusTypeUnknown)
+	_, err = ClaimCalldata(message)
+	assert.Error(t, err)
+
+	// deposits are not claimed.
+	message.MessageType = int(orm.MessageTypeL1SentMessage)
+	_, err = ClaimCalldata(message)
+	assert.Error(t, err)
+}
+
+func TestReplayCalldata(t *testing.T) {
+	message := &orm.CrossMessage{
+		MessageHash:  "0x01",
+		MessageType:  int(orm.MessageTypeL1SentMessage),
+		MessageFrom:  "0x0000000000000000000000000000000000000001",
+		MessageTo:    "0x0000000000000000000000000000000000000002",
+		MessageValue: "0",
+		MessageNonce: 5,
+		MessageData:  "0x",
+	}
+	refundAddress := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	data, err := ReplayCalldata(message, 200000, refundAddress)
+	assert.NoError(t, err)
+
+	method := backendabi.IL1ScrollMessengerABI.Methods["replayMessage"]
+	assert.Equal(t, method.ID, data[:4])
+	args, err := method.Inputs.Unpack(data[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), args[3])
+	assert.Equal(t, uint32(200000), args[5])
+	assert.Equal(t, refundAddress, args[6])
+
+	message.MessageValue = "not a number"
+	_, err = ReplayCalldata(message, 200000, refundAddress)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+	"scroll-tech/bridge-history-api/internal/utils"
+)
+
+// depositABIs are the abis of the L1 contracts users deposit through, the gateway router takes the eth and erc20
+// deposit methods of the gateways.
+var depositABIs = []*abi.ABI{
+	backendabi.IL1ETHGatewayABI,
+	backendabi.IL1ERC20GatewayABI,
+	backendabi.IL1ERC721GatewayABI,
+	backendabi.IL1ERC1155GatewayABI,
+	backendabi.IL1ScrollMessengerABI,
+}
+
+// TxPoolCaller is the subset of the rpc client used to read the txpool of a node.
+type TxPoolCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// txPoolTx is the part of a transaction of the txpool content needed to decode a deposit.
+type txPoolTx struct {
+	Hash  common.Hash     `json:"hash"`
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+// PendingDepositLogic decodes the deposits among the pending transactions of the txpool of an L1 node.
+type PendingDepositLogic struct {
+	client    TxPoolCaller
+	contracts map[common.Address]bool
+}
+
+// NewPendingDepositLogic returns the logic reading the txpool of the client, the deposits are the calls of the
+// gateways, the gateway router and the messenger of the L1 config.
+func NewPendingDepositLogic(cfg *config.FetcherConfig, client TxPoolCaller) *PendingDepositLogic {
+	contracts := make(map[common.Address]bool)
+	for addr := range cfg.Gateways() {
+		contracts[common.HexToAddress(addr)] = true
+	}
+	for _, addr := range []string{cfg.GatewayRouterAddr, cfg.MessengerAddr} {
+		if addr != "" {
+			contracts[common.HexToAddress(addr)] = true
+		}
+	}
+	return &PendingDepositLogic{client: client, contracts: contracts}
+}
+
+// GetTxPoolDeposits returns the deposits among the pending transactions of the txpool, seen at now. The queued
+// transactions are left out, they are not executable yet.
+func (p *PendingDepositLogic) GetTxPoolDeposits(ctx context.Context, now time.Time) ([]*orm.PendingDeposit, error) {
+	var content map[string]map[string]map[string]*txPoolTx
+	if err := p.client.CallContext(ctx, &content, "txpool_content"); err != nil {
+		return nil, fmt.Errorf("failed to get txpool content, error: %w", err)
+	}
+	var deposits []*orm.PendingDeposit
+	for _, txs := range content["pending"] {
+		for _, tx := range txs {
+			if deposit := p.parsePendingDeposit(tx, now); deposit != nil {
+				deposits = append(deposits, deposit)
+			}
+		}
+	}
+	return deposits, nil
+}
+
+// parsePendingDeposit decodes the deposit of a transaction calling a deposit method, nil if it is not a deposit.
+func (p *PendingDepositLogic) parsePendingDeposit(tx *txPoolTx, now time.Time) *orm.PendingDeposit {
+	if tx == nil || tx.To == nil || !p.contracts[*tx.To] || len(tx.Input) < 4 {
+		return nil
+	}
+	var method *abi.Method
+	for _, contractABI := range depositABIs {
+		if m, err := contractABI.MethodById(tx.Input[:4]); err == nil {
+			method = m
+			break
+		}
+	}
+	if method == nil {
+		return nil
+	}
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, tx.Input[4:]); err != nil {
+		return nil
+	}
+
+	deposit := &orm.PendingDeposit{
+		TxHash:          tx.Hash.String(),
+		Sender:          tx.From.String(),
+		Receiver:        tx.From.String(),
+		ContractAddress: tx.To.String(),
+		FirstSeenAt:     now,
+		LastSeenAt:      now,
+	}
+	for _, name := range []string{"to", "_to", "target"} {
+		if to, ok := args[name].(common.Address); ok {
+			deposit.Receiver = to.String()
+		}
+	}
+	token, _ := args["_token"].(common.Address)
+	switch method.RawName {
+	case "depositETH", "depositETHAndCall":
+		deposit.TokenType = int(orm.TokenTypeETH)
+		deposit.TokenAmounts = bigArg(args, "amount")
+	case "sendMessage":
+		deposit.TokenType = int(orm.TokenTypeETH)
+		deposit.TokenAmounts = bigArg(args, "value")
+	case "depositERC20", "depositERC20AndCall":
+		deposit.TokenType = int(orm.TokenTypeERC20)
+		deposit.L1TokenAddress = token.String()
+		deposit.TokenAmounts = bigArg(args, "_amount")
+	case "depositERC721":
+		deposit.TokenType = int(orm.TokenTypeERC721)
+		deposit.L1TokenAddress = token.String()
+		deposit.TokenIDs = bigArg(args, "_tokenId")
+	case "batchDepositERC721":
+		deposit.TokenType = int(orm.TokenTypeERC721)
+		deposit.L1TokenAddress = token.String()
+		deposit.TokenIDs = bigArrayArg(args, "_tokenIds")
+	case "depositERC1155":
+		deposit.TokenType = int(orm.TokenTypeERC1155)
+		deposit.L1TokenAddress = token.String()
+		deposit.TokenIDs = bigArg(args, "_tokenId")
+		deposit.TokenAmounts = bigArg(args, "_amount")
+	case "batchDepositERC1155":
+		deposit.TokenType = int(orm.TokenTypeERC1155)
+		deposit.L1TokenAddress = token.String()
+		deposit.TokenIDs = bigArrayArg(args, "_tokenIds")
+		deposit.TokenAmounts = bigArrayArg(args, "_amounts")
+	default:
+		return nil
+	}
+	return deposit
+}
+
+func bigArg(args map[string]interface{}, name string) string {
+	if value, ok := args[name].(*big.Int); ok {
+		return value.String()
+	}
+	return ""
+}
+
+func bigArrayArg(args map[string]interface{}, name string) string {
+	if values, ok := args[name].([]*big.Int); ok {
+		return utils.ConvertBigIntArrayToString(values)
+	}
+	return ""
+}
+
+// getPendingDepositInfo returns the tx history info of a pending deposit, its tx is not mined yet.
+func getPendingDepositInfo(deposit *orm.PendingDeposit) *types.TxHistoryInfo {
+	return &types.TxHistoryInfo{
+		Hash:           deposit.TxHash,
+		Sender:         deposit.Sender,
+		Receiver:       deposit.Receiver,
+		TokenType:      orm.TokenType(deposit.TokenType),
+		TokenIDs:       utils.ConvertStringToStringArray(deposit.TokenIDs),
+		TokenAmounts:   utils.ConvertStringToStringArray(deposit.TokenAmounts),
+		MessageType:    orm.MessageTypeL1SentMessage,
+		L1TokenAddress: deposit.L1TokenAddress,
+		TxStatus:       orm.TxStatusTypeSent,
+		Pending:        true,
+	}
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+type mockTxPoolCaller struct {
+	content map[string]map[string]map[string]*txPoolTx
+}
+
+func (m *mockTxPoolCaller) CallContext(_ context.Context, result interface{}, _ string, _ ...interface{}) error {
+	// round trip through json as the rpc client does.
+	data, err := json.Marshal(m.content)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func packCall(t *testing.T, contractABI *abi.ABI, sig string, args ...interface{}) []byte {
+	for _, method := range contractABI.Methods {
+		if method.Sig == sig {
+			input, err := method.Inputs.Pack(args...)
+			assert.NoError(t, err)
+			return append(append([]byte{}, method.ID...), input...)
+		}
+	}
+	t.Fatalf("method %s not found", sig)
+	return nil
+}
+
+func TestGetTxPoolDeposits(t *testing.T) {
+	ethGateway := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	erc20Gateway := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	router := common.HexToAddress("0x1000000000000000000000000000000000000003")
+	messenger := common.HexToAddress("0x1000000000000000000000000000000000000004")
+	other := common.HexToAddress("0x1000000000000000000000000000000000000005")
+	user := common.HexToAddress("0x2000000000000000000000000000000000000001")
+	receiver := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	token := common.HexToAddress("0x3000000000000000000000000000000000000001")
+
+	ethDeposit := packCall(t, backendabi.IL1ETHGatewayABI, "depositETH(uint256,uint256)", big.NewInt(100), big.NewInt(200000))
+	txs := map[string]*txPoolTx{
+		"0": {Hash: common.HexToHash("0x01"), From: user, To: &ethGateway, Input: ethDeposit},
+		"1": {Hash: common.HexToHash("0x02"), From: user, To: &router, Input: packCall(t, backendabi.IL1ERC20GatewayABI,
+			"depositERC20(address,address,uint256,uint256)", token, receiver, big.NewInt(300), big.NewInt(200000))},
+		"2": {Hash: common.HexToHash("0x03"), From: user, To: &messenger, Input: packCall(t, backendabi.IL1ScrollMessengerABI,
+			"sendMessage(address,uint256,bytes,uint256)", receiver, big.NewInt(400), []byte{}, big.NewInt(200000))},
+		// a deposit call of a contract which is not a gateway, and a call of a gateway which is not a deposit.
+		"3": {Hash: common.HexToHash("0x04"), From: user, To: &other, Input: ethDeposit},
+		"4": {Hash: common.HexToHash("0x05"), From: user, To: &erc20Gateway, Input: []byte{0x01, 0x02, 0x03, 0x04}},
+	}
+	caller := &mockTxPoolCaller{content: map[string]map[string]map[string]*txPoolTx{
+		"pending": {user.String(): txs},
+		"queued":  {user.String(): {"5": {Hash: common.HexToHash("0x06"), From: user, To: &ethGateway, Input: ethDeposit}}},
+	}}
+	cfg := &config.FetcherConfig{
+		ETHGatewayAddr:           ethGateway.String(),
+		StandardERC20GatewayAddr: erc20Gateway.String(),
+		GatewayRouterAddr:        router.String(),
+		MessengerAddr:            messenger.String(),
+	}
+
+	now := time.Unix(1700000000, 0)
+	deposits, err := NewPendingDepositLogic(cfg, caller).GetTxPoolDeposits(context.Background(), now)
+	assert.NoError(t, err)
+	byHash := make(map[string]*orm.PendingDeposit)
+	for _, deposit := range deposits {
+		byHash[deposit.TxHash] = deposit
+	}
+	assert.Len(t, byHash, 3)
+
+	eth := byHash[common.HexToHash("0x01").String()]
+	if assert.NotNil(t, eth) {
+		assert.Equal(t, user.String(), eth.Sender)
+		assert.Equal(t, user.String(), eth.Receiver)
+		assert.Equal(t, ethGateway.String(), eth.ContractAddress)
+		assert.Equal(t, int(orm.TokenTypeETH), eth.TokenType)
+		assert.Equal(t, "100", eth.TokenAmounts)
+		assert.Equal(t, now, eth.FirstSeenAt)
+	}
+	erc20 := byHash[common.HexToHash("0x02").String()]
+	if assert.NotNil(t, erc20) {
+		assert.Equal(t, receiver.String(), erc20.Receiver)
+		assert.Equal(t, int(orm.TokenTypeERC20), erc20.TokenType)
+		assert.Equal(t, token.String(), erc20.L1TokenAddress)
+		assert.Equal(t, "300", erc20.TokenAmounts)
+	}
+	message := byHash[common.HexToHash("0x03").String()]
+	if assert.NotNil(t, message) {
+		assert.Equal(t, receiver.String(), message.Receiver)
+		assert.Equal(t, int(orm.TokenTypeETH), message.TokenType)
+		assert.Equal(t, "400", message.TokenAmounts)
+	}
+
+	info := getPendingDepositInfo(erc20)
+	assert.True(t, info.Pending)
+	assert.Equal(t, orm.MessageTypeL1SentMessage, info.MessageType)
+	assert.Equal(t, []string{"300"}, info.TokenAmounts)
+	assert.Empty(t, info.TokenIDs)
+}
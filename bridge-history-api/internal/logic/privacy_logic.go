@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// PrivacyLogic masks the user data of the txs rendered by the API, the indexed data is left intact.
+type PrivacyLogic struct {
+	addressMode string
+	hashKey     []byte
+}
+
+// NewPrivacyLogic returns the masking of the privacy mode, keyed by hashKey for the hashed addresses.
+func NewPrivacyLogic(cfg *config.PrivacyConfig, hashKey string) *PrivacyLogic {
+	addressMode := config.PrivacyAddressHash
+	if cfg.AddressMode == config.PrivacyAddressTruncate {
+		addressMode = config.PrivacyAddressTruncate
+	}
+	return &PrivacyLogic{addressMode: addressMode, hashKey: []byte(hashKey)}
+}
+
+// MaskTxs masks the senders, receivers and claimers of txs, and omits their ENS names and the call data of deposits.
+// The claim info is kept, the claim tx of a withdrawal is built from it.
+func (p *PrivacyLogic) MaskTxs(txs []*types.TxHistoryInfo) {
+	for _, tx := range txs {
+		tx.Sender = p.maskAddress(tx.Sender)
+		tx.Receiver = p.maskAddress(tx.Receiver)
+		tx.ClaimedBy = p.maskAddress(tx.ClaimedBy)
+		tx.SenderENSName = ""
+		tx.ReceiverENSName = ""
+		tx.DepositCall = nil
+	}
+}
+
+// maskAddress returns the keyed hash of address shaped as an address, so that the txs of an address can still be
+// grouped, or its first and last 4 hex digits, e.g. 0x1234...abcd.
+func (p *PrivacyLogic) maskAddress(address string) string {
+	if address == "" {
+		return ""
+	}
+	if p.addressMode == config.PrivacyAddressTruncate {
+		if len(address) <= 10 {
+			return address
+		}
+		return address[:6] + "..." + address[len(address)-4:]
+	}
+	mac := hmac.New(sha256.New, p.hashKey)
+	mac.Write([]byte(strings.ToLower(address)))
+	return "0x" + hex.EncodeToString(mac.Sum(nil)[:20])
+}
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+func TestPrivacyLogicMaskTxs(t *testing.T) {
+	const (
+		sender   = "0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63"
+		receiver = "0x0000000000000000000000000000000000000001"
+	)
+	newTx := func() *types.TxHistoryInfo {
+		return &types.TxHistoryInfo{
+			Sender:          sender,
+			Receiver:        receiver,
+			SenderENSName:   "sender.eth",
+			ReceiverENSName: "receiver.eth",
+			DepositCall:     &types.DepositCallInfo{Target: receiver, Data: "0x01"},
+			ClaimInfo:       &types.ClaimInfo{From: sender, To: receiver, Message: "0x01"},
+		}
+	}
+
+	p := NewPrivacyLogic(&config.PrivacyConfig{Enabled: true}, "key")
+	tx, sameSender := newTx(), &types.TxHistoryInfo{Sender: "0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"}
+	p.MaskTxs([]*types.TxHistoryInfo{tx, sameSender})
+	assert.Len(t, tx.Sender, 42)
+	assert.NotEqual(t, sender, tx.Sender)
+	assert.NotEqual(t, tx.Sender, tx.Receiver)
+	// the txs of an address are grouped whatever the case of the address.
+	assert.Equal(t, tx.Sender, sameSender.Sender)
+	assert.Empty(t, tx.SenderENSName)
+	assert.Empty(t, tx.ReceiverENSName)
+	assert.Empty(t, tx.ClaimedBy)
+	assert.Nil(t, tx.DepositCall)
+	assert.Equal(t, &types.ClaimInfo{From: sender, To: receiver, Message: "0x01"}, tx.ClaimInfo)
+
+	// another key hashes to other addresses.
+	otherKeyTx := newTx()
+	NewPrivacyLogic(&config.PrivacyConfig{Enabled: true}, "other key").MaskTxs([]*types.TxHistoryInfo{otherKeyTx})
+	assert.NotEqual(t, tx.Sender, otherKeyTx.Sender)
+
+	tx = newTx()
+	NewPrivacyLogic(&config.PrivacyConfig{Enabled: true, AddressMode: config.PrivacyAddressTruncate}, "").MaskTxs([]*types.TxHistoryInfo{tx})
+	assert.Equal(t, "0x1C5A...0b63", tx.Sender)
+	assert.Equal(t, "0x0000...0001", tx.Receiver)
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"scroll-tech/common/types/crossdomain"
+
+	backendabi "scroll-tech/bridge-history-api/abi"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// relayNonceDecoder decodes the nonce of the message relayed by a tx calling the messenger directly.
+type relayNonceDecoder func(data []byte, messageHash string) (uint64, bool)
+
+// fillRelayedMessageNonces sets the message nonce of relayed messages, as the nonce is a partition key of
+// cross_message_v2 and relayed events only carry the message hash. The nonce is decoded from the relay tx when
+// it calls the messenger directly, otherwise it is the nonce of the sent message already indexed.
+// An error is returned when neither is available, so the range is fetched again once the sent message is indexed.
+func fillRelayedMessageNonces(ctx context.Context, crossMessageOrm *orm.CrossMessage, blocks []*types.Block, relayedMessages []*orm.CrossMessage, txHashOf func(*orm.CrossMessage) string, decode relayNonceDecoder) error {
+	if len(relayedMessages) == 0 {
+		return nil
+	}
+
+	txs := make(map[string]*types.Transaction)
+	for _, block := range blocks {
+		for _, tx := range block.Transactions() {
+			txs[tx.Hash().String()] = tx
+		}
+	}
+
+	var unresolved []*orm.CrossMessage
+	for _, message := range relayedMessages {
+		if tx, ok := txs[txHashOf(message)]; ok {
+			if nonce, ok := decode(tx.Data(), message.MessageHash); ok {
+				message.MessageNonce = nonce
+				continue
+			}
+		}
+		unresolved = append(unresolved, message)
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	messageHashes := make([]string, 0, len(unresolved))
+	for _, message := range unresolved {
+		messageHashes = append(messageHashes, message.MessageHash)
+	}
+	nonces, err := crossMessageOrm.GetMessageNoncesByHashes(ctx, messageHashes)
+	if err != nil {
+		return fmt.Errorf("failed to get message nonces of relayed messages, error: %w", err)
+	}
+	for _, message := range unresolved {
+		nonce, ok := nonces[message.MessageHash]
+		if !ok {
+			return fmt.Errorf("sent message of relayed message %s is not indexed yet", message.MessageHash)
+		}
+		message.MessageNonce = nonce
+	}
+	return nil
+}
+
+// decodeL2RelayNonce decodes the nonce of a deposit relayed on L2, the data of the L1 message tx is the
+// relayMessage call of the message.
+func decodeL2RelayNonce(data []byte, messageHash string) (uint64, bool) {
+	if crossdomain.HashEncodedMessage(data).String() != messageHash {
+		return 0, false
+	}
+	msg, _, err := crossdomain.DecodeMessage(data)
+	if err != nil || !msg.MessageNonce.IsUint64() {
+		return 0, false
+	}
+	return msg.MessageNonce.Uint64(), true
+}
+
+// decodeL1RelayNonce decodes the nonce of a withdrawal relayed on L1 by a relayMessageWithProof call.
+func decodeL1RelayNonce(data []byte, messageHash string) (uint64, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+	method, err := backendabi.IL1ScrollMessengerABI.MethodById(data[:4])
+	if err != nil || method.Name != "relayMessageWithProof" {
+		return 0, false
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(values) < 5 {
+		return 0, false
+	}
+	from, fromOk := values[0].(common.Address)
+	to, toOk := values[1].(common.Address)
+	value, valueOk := values[2].(*big.Int)
+	nonce, nonceOk := values[3].(*big.Int)
+	message, messageOk := values[4].([]byte)
+	if !fromOk || !toOk || !valueOk || !nonceOk || !messageOk || !nonce.IsUint64() {
+		return 0, false
+	}
+	if crossdomain.ComputeMessageHash(from, to, value, nonce, message).String() != messageHash {
+		return 0, false
+	}
+	return nonce.Uint64(), true
+}
@@ -0,0 +1,251 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+const (
+	cacheKeyPrefixStats = cacheKeyPrefixBridgeHistory + "stats:"
+
+	defaultStatsWindowDays    = 7
+	defaultStatsMaxWindowDays = 90
+	defaultTopTokensLimit     = 20
+)
+
+// StatsLogic serves the daily bridge statistics aggregated by the fetcher. The statistics are the same for all
+// clients and change with the aggregation only, so the responses are cached briefly in redis.
+type StatsLogic struct {
+	bridgeStatsOrm        *orm.BridgeStats
+	feeVaultWithdrawalOrm *orm.FeeVaultWithdrawal
+	redis                 *redis.Client
+	singleFlight          singleflight.Group
+	cacheMetrics          *cacheMetrics
+
+	maxWindowDays uint64
+	l1Gateways    map[string]string // names of the L1 gateways by address
+	l2Gateways    map[string]string // names of the L2 gateways by address
+	now           func() time.Time
+}
+
+// NewStatsLogic returns the bridge statistics services.
+func NewStatsLogic(cfg *config.Config, db *gorm.DB, redis *redis.Client) *StatsLogic {
+	s := &StatsLogic{
+		bridgeStatsOrm:        orm.NewBridgeStats(db),
+		feeVaultWithdrawalOrm: orm.NewFeeVaultWithdrawal(db),
+		redis:                 redis,
+		cacheMetrics:          initCacheMetrics(),
+		maxWindowDays:         defaultStatsMaxWindowDays,
+		l1Gateways:            map[string]string{},
+		l2Gateways:            map[string]string{},
+		now:                   time.Now,
+	}
+	if cfg.Stats != nil && cfg.Stats.MaxWindowDays > 0 {
+		s.maxWindowDays = cfg.Stats.MaxWindowDays
+	}
+	if cfg.L1 != nil {
+		s.l1Gateways = cfg.L1.Gateways()
+	}
+	if cfg.L2 != nil {
+		s.l2Gateways = cfg.L2.Gateways()
+	}
+	return s
+}
+
+// GetTopTokens returns the tokens with the most volume or messages over the last days.
+func (s *StatsLogic) GetTopTokens(ctx context.Context, req *types.QueryTokenStatsRequest) (*types.TokenStatsData, error) {
+	from := s.windowFrom(req.Days)
+	orderBy := orm.TokenStatOrderByVolume
+	if req.SortBy != "" {
+		orderBy = req.SortBy
+	}
+	limit := uint64(defaultTopTokensLimit)
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	cacheKey := fmt.Sprintf("%stokens:%s:%d:%d:%s:%d", cacheKeyPrefixStats, from.Format(time.DateOnly), req.MessageType, req.TokenType, orderBy, limit)
+	data := &types.TokenStatsData{}
+	err := s.cached(ctx, "GetTopTokens", cacheKey, data, func() (interface{}, error) {
+		stats, err := s.bridgeStatsOrm.GetTopTokens(ctx, from, orm.MessageType(req.MessageType), orm.TokenType(req.TokenType), orderBy, int(limit))
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*types.TokenStatInfo, 0, len(stats))
+		for _, stat := range stats {
+			results = append(results, &types.TokenStatInfo{
+				TokenType:      orm.TokenType(stat.TokenType),
+				L1TokenAddress: stat.L1TokenAddress,
+				L2TokenAddress: stat.L2TokenAddress,
+				TxCount:        stat.TxCount,
+				Volume:         stat.Volume.String(),
+			})
+		}
+		return &types.TokenStatsData{From: from.Format(time.DateOnly), Results: results}, nil
+	})
+	if err != nil {
+		log.Error("failed to get top tokens", "from", from, "error", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetGatewayStats returns the number of messages per direction and gateway over the last days.
+func (s *StatsLogic) GetGatewayStats(ctx context.Context, days uint64) (*types.GatewayStatsData, error) {
+	from := s.windowFrom(days)
+	cacheKey := cacheKeyPrefixStats + "gateways:" + from.Format(time.DateOnly)
+	data := &types.GatewayStatsData{}
+	err := s.cached(ctx, "GetGatewayStats", cacheKey, data, func() (interface{}, error) {
+		stats, err := s.bridgeStatsOrm.GetGatewayStats(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*types.GatewayStatInfo, 0, len(stats))
+		for _, stat := range stats {
+			result := &types.GatewayStatInfo{MessageType: stat.MessageType, Gateway: stat.Gateway, TxCount: stat.TxCount}
+			if stat.Gateway != orm.GatewayMessenger && stat.Gateway != orm.GatewayUnknown {
+				// the gateways of deposits are on L1, the ones of withdrawals on L2.
+				gateways := s.l1Gateways
+				if orm.MessageType(stat.MessageType) == orm.MessageTypeL2SentMessage {
+					gateways = s.l2Gateways
+				}
+				result.GatewayAddress = stat.Gateway
+				result.Gateway = gateways[stat.Gateway]
+				if result.Gateway == "" {
+					// the gateway was removed from the config since its messages were aggregated.
+					result.Gateway = orm.GatewayUnknown
+				}
+			}
+			results = append(results, result)
+		}
+		return &types.GatewayStatsData{From: from.Format(time.DateOnly), Results: results}, nil
+	})
+	if err != nil {
+		log.Error("failed to get gateway stats", "from", from, "error", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetBridgerStats returns the number of distinct bridgers per day over the last days.
+func (s *StatsLogic) GetBridgerStats(ctx context.Context, days uint64) (*types.BridgerStatsData, error) {
+	from := s.windowFrom(days)
+	cacheKey := cacheKeyPrefixStats + "bridgers:" + from.Format(time.DateOnly)
+	data := &types.BridgerStatsData{}
+	err := s.cached(ctx, "GetBridgerStats", cacheKey, data, func() (interface{}, error) {
+		stats, err := s.bridgeStatsOrm.GetBridgerDailyStats(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*types.BridgerStatInfo, 0, len(stats))
+		for _, stat := range stats {
+			results = append(results, &types.BridgerStatInfo{
+				Day:                stat.Day.Format(time.DateOnly),
+				DepositBridgers:    stat.DepositBridgers,
+				WithdrawalBridgers: stat.WithdrawalBridgers,
+				UniqueBridgers:     stat.UniqueBridgers,
+			})
+		}
+		return &types.BridgerStatsData{From: from.Format(time.DateOnly), Results: results}, nil
+	})
+	if err != nil {
+		log.Error("failed to get bridger stats", "from", from, "error", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// GetFeeVaultWithdrawals returns the latest withdrawals of a fee vault, of all fee vaults if vault is empty, with their
+// total number and value, so that the protocol revenue bridged to L1 can be tracked.
+func (s *StatsLogic) GetFeeVaultWithdrawals(ctx context.Context, req *types.QueryFeeVaultWithdrawalsRequest) (*types.FeeVaultWithdrawalsData, error) {
+	var vault string
+	if req.Vault != "" {
+		vault = common.HexToAddress(req.Vault).String()
+	}
+	cacheKey := fmt.Sprintf("%sfee_vault:%s:%d:%d", cacheKeyPrefixStats, vault, req.Page, req.PageSize)
+	data := &types.FeeVaultWithdrawalsData{}
+	err := s.cached(ctx, "GetFeeVaultWithdrawals", cacheKey, data, func() (interface{}, error) {
+		totals, err := s.feeVaultWithdrawalOrm.GetFeeVaultWithdrawalTotals(ctx, vault)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals, err := s.feeVaultWithdrawalOrm.GetFeeVaultWithdrawals(ctx, vault, int((req.Page-1)*req.PageSize), int(req.PageSize))
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*types.FeeVaultWithdrawalInfo, 0, len(withdrawals))
+		for _, withdrawal := range withdrawals {
+			results = append(results, &types.FeeVaultWithdrawalInfo{
+				L2TxHash:       withdrawal.L2TxHash,
+				VaultAddress:   withdrawal.VaultAddress,
+				Value:          withdrawal.Value.String(),
+				Recipient:      withdrawal.Recipient,
+				TriggeredBy:    withdrawal.TriggeredBy,
+				MessageHash:    withdrawal.MessageHash,
+				BlockNumber:    withdrawal.L2BlockNumber,
+				BlockTimestamp: withdrawal.BlockTimestamp,
+				TxStatus:       withdrawal.TxStatus,
+				RollupStatus:   withdrawal.RollupStatus,
+				L1TxHash:       withdrawal.L1TxHash,
+			})
+		}
+		return &types.FeeVaultWithdrawalsData{Results: results, Total: totals.Count, TotalValue: totals.Value.String()}, nil
+	})
+	if err != nil {
+		log.Error("failed to get fee vault withdrawals", "vault", vault, "error", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// windowFrom returns the first UTC day of the window of the last days including today, capped by the max window.
+func (s *StatsLogic) windowFrom(days uint64) time.Time {
+	if days == 0 {
+		days = defaultStatsWindowDays
+	}
+	days = min(days, s.maxWindowDays)
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -int(days-1))
+}
+
+// cached decodes the cached response of the key into data, or computes it by query and caches it. Concurrent misses
+// of the same key share a single query.
+func (s *StatsLogic) cached(ctx context.Context, api, cacheKey string, data interface{}, query func() (interface{}, error)) error {
+	cachedData, err := s.redis.Get(ctx, cacheKey).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(cachedData, data); err == nil {
+			s.cacheMetrics.cacheHits.WithLabelValues(api).Inc()
+			return nil
+		}
+		log.Error("failed to unmarshal cached stats", "cache key", cacheKey, "error", err)
+	} else if !errors.Is(err, redis.Nil) {
+		log.Error("failed to get data from Redis", "error", err)
+	}
+	s.cacheMetrics.cacheMisses.WithLabelValues(api).Inc()
+
+	result, err, _ := s.singleFlight.Do(cacheKey, query)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats, error: %w", err)
+	}
+	if cacheErr := s.redis.Set(ctx, cacheKey, jsonData, cacheKeyExpiredTime).Err(); cacheErr != nil {
+		log.Error("failed to set data to Redis", "error", cacheErr)
+	}
+	return json.Unmarshal(jsonData, data)
+}
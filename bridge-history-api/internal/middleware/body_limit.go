@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// MaxRequestBodySize returns the middleware rejecting requests whose body exceeds maxBytes.
+// Requests declaring a larger Content-Length are rejected up front, other bodies fail to be read past the limit.
+func MaxRequestBodySize(maxBytes int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.ContentLength > maxBytes {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, types.NewResponse(types.ErrRequestBodyTooLarge, fmt.Sprintf("request body too large, limit: %d bytes", maxBytes), nil))
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS returns the cross-origin resource sharing middleware, all origins are allowed if allowOrigins is empty or contains "*".
+func CORS(allowOrigins []string) gin.HandlerFunc {
+	if len(allowOrigins) == 0 || slices.Contains(allowOrigins, "*") {
+		allowOrigins = []string{"*"}
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:     allowOrigins,
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	})
+}
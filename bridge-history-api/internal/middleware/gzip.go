@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gzip returns the middleware compressing responses with gzip at the given level, for clients accepting it.
+func Gzip(level int) gin.HandlerFunc {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	pool := sync.Pool{
+		New: func() interface{} {
+			// the level is validated above, the error is always nil.
+			w, _ := gzip.NewWriterLevel(nil, level) //nolint:errcheck
+			return w
+		},
+	}
+
+	return func(ctx *gin.Context) {
+		if !strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") || ctx.Request.Method == "HEAD" {
+			ctx.Next()
+			return
+		}
+
+		gz := pool.Get().(*gzip.Writer)
+		gz.Reset(ctx.Writer)
+		defer pool.Put(gz)
+
+		ctx.Header("Content-Encoding", "gzip")
+		ctx.Header("Vary", "Accept-Encoding")
+		originalWriter := ctx.Writer
+		ctx.Writer = &gzipWriter{ResponseWriter: originalWriter, writer: gz}
+		defer func() {
+			// Restore the writer for the outer middlewares, the gzip stream is closed below.
+			ctx.Writer = originalWriter
+			// The gzip header is written to the response on the first write, a negative size means nothing
+			// was written at all and the headers are still mutable.
+			if originalWriter.Size() < 0 {
+				originalWriter.Header().Del("Content-Encoding")
+				gz.Reset(io.Discard)
+			}
+			if err := gz.Close(); err != nil {
+				_ = ctx.Error(err)
+			}
+		}()
+
+		ctx.Next()
+	}
+}
+
+type gzipWriter struct {
+	gin.ResponseWriter
+	writer *gzip.Writer
+}
+
+// WriteHeader drops the Content-Length header, which refers to the uncompressed body.
+func (g *gzipWriter) WriteHeader(code int) {
+	g.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(code)
+}
+
+// Write compresses the data written to the response body.
+func (g *gzipWriter) Write(data []byte) (int, error) {
+	g.Header().Del("Content-Length")
+	return g.writer.Write(data)
+}
+
+// WriteString compresses the string written to the response body.
+func (g *gzipWriter) WriteString(s string) (int, error) {
+	return g.Write([]byte(s))
+}
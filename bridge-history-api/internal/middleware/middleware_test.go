@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+func newTestRouter(middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares...)
+	return router
+}
+
+func TestGzip(t *testing.T) {
+	router := newTestRouter(Gzip(gzip.DefaultCompression))
+	router.GET("/data", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, strings.Repeat("scroll", 100))
+	})
+	router.GET("/empty", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("scroll", 100), string(body))
+
+	req = httptest.NewRequest(http.MethodGet, "/data", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, strings.Repeat("scroll", 100), w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/empty", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestMaxRequestBodySize(t *testing.T) {
+	router := newTestRouter(MaxRequestBodySize(16))
+	router.POST("/echo", func(ctx *gin.Context) {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.Status(http.StatusBadRequest)
+			return
+		}
+		ctx.String(http.StatusOK, string(body))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("small")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "small", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(strings.Repeat("a", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"errcode":40006`)
+
+	// Bodies without a declared length are cut off while being read.
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.NopCloser(strings.NewReader(strings.Repeat("a", 17))))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTimeout(t *testing.T) {
+	router := newTestRouter(Timeout(50 * time.Millisecond))
+	router.GET("/slow", func(ctx *gin.Context) {
+		<-ctx.Request.Context().Done()
+	})
+	router.GET("/fast", func(ctx *gin.Context) {
+		types.RenderSuccess(ctx, nil)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), `"errcode":40007`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	router := newTestRouter(QueryTimeout(time.Second, map[string]time.Duration{"/api/txs": 3 * time.Second}))
+	var readTimeout time.Duration
+	handler := func(ctx *gin.Context) {
+		readTimeout = database.ReadTimeout(ctx.Request.Context())
+		types.RenderSuccess(ctx, nil)
+	}
+	router.GET("/api/txs", handler)
+	router.GET("/api/l1/queue", handler)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/txs?address=0x1", nil))
+	assert.Equal(t, 3*time.Second, readTimeout)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/l1/queue", nil))
+	assert.Equal(t, time.Second, readTimeout)
+}
+
+func TestCORS(t *testing.T) {
+	router := newTestRouter(CORS([]string{"https://scroll.io"}))
+	router.GET("/data", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Origin", "https://scroll.io")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "https://scroll.io", w.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSignResponse(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	router := newTestRouter(SignResponse(key))
+	router.GET("/json", func(ctx *gin.Context) {
+		types.RenderSuccess(ctx, map[string]interface{}{"hash": "0x01", "amount": "1000"})
+	})
+	router.GET("/text", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "scroll")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/json?page=1&address=0x01", nil)
+	req.Header.Set(NonceHeader, "nonce-1")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), w.Header().Get(SignerHeader))
+	timestamp := w.Header().Get(TimestampHeader)
+	assert.NotEmpty(t, timestamp)
+
+	canonical, err := CanonicalizeJSON(w.Body.Bytes())
+	assert.NoError(t, err)
+	signature, err := hexutil.Decode(w.Header().Get(SignatureHeader))
+	assert.NoError(t, err)
+	recoverSigner := func(payload []byte) common.Address {
+		pubKey, recoverErr := crypto.SigToPub(crypto.Keccak256(payload), signature)
+		assert.NoError(t, recoverErr)
+		return crypto.PubkeyToAddress(*pubKey)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	// the order of the query parameters does not matter.
+	assert.Equal(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/json", "address=0x01&page=1", timestamp, "nonce-1", canonical)))
+
+	// the signature does not verify for another request, time, nonce or a tampered body.
+	assert.NotEqual(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/json", "address=0x02&page=1", timestamp, "nonce-1", canonical)))
+	assert.NotEqual(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/other", "address=0x01&page=1", timestamp, "nonce-1", canonical)))
+	assert.NotEqual(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/json", "address=0x01&page=1", "0", "nonce-1", canonical)))
+	assert.NotEqual(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/json", "address=0x01&page=1", timestamp, "", canonical)))
+	tampered, err := CanonicalizeJSON([]byte(strings.Replace(w.Body.String(), "1000", "2000", 1)))
+	assert.NoError(t, err)
+	assert.NotEqual(t, signer, recoverSigner(ResponseSigningPayload(http.MethodGet, "/json", "address=0x01&page=1", timestamp, "nonce-1", tampered)))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/text", nil))
+	assert.Equal(t, "scroll", w.Body.String())
+	assert.Empty(t, w.Header().Get(SignatureHeader))
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	canonical, err := CanonicalizeJSON([]byte(`{ "b": 1.50, "a": {"d": "<x>", "c": [1, 2]} }`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":{"c":[1,2],"d":"<x>"},"b":1.50}`, string(canonical))
+
+	_, err = CanonicalizeJSON([]byte("scroll"))
+	assert.Error(t, err)
+}
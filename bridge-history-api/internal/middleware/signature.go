@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+const (
+	// SignatureHeader carries the signature over the keccak256 hash of the signing payload of the response.
+	SignatureHeader = "X-Response-Signature"
+	// SignerHeader carries the address of the operator key signing the responses.
+	SignerHeader = "X-Response-Signer"
+	// TimestampHeader carries the unix timestamp at which the response was signed.
+	TimestampHeader = "X-Response-Timestamp"
+	// NonceHeader carries an optional client chosen nonce of the request, which the signature of the response covers.
+	NonceHeader = "X-Request-Nonce"
+)
+
+// SignResponse returns the middleware signing JSON responses with the operator key, so clients can detect responses
+// tampered with by proxies or CDNs. The signature is a 65 bytes [R || S || V] secp256k1 signature, the signer can be
+// recovered with ecrecover and compared with the published operator address.
+// The signature covers the request and the signing time besides the body, see ResponseSigningPayload, so that a signed
+// response can neither be served for another request nor replayed later.
+// Responses which are not JSON, or written by outer middlewares, are left unsigned.
+func SignResponse(key *ecdsa.PrivateKey) gin.HandlerFunc {
+	signer := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	return func(ctx *gin.Context) {
+		originalWriter := ctx.Writer
+		writer := &bufferedWriter{ResponseWriter: originalWriter}
+		ctx.Writer = writer
+		defer func() {
+			ctx.Writer = originalWriter
+		}()
+
+		ctx.Next()
+
+		body := writer.body.Bytes()
+		if strings.HasPrefix(originalWriter.Header().Get("Content-Type"), "application/json") {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			if signature, err := signJSON(key, ctx.Request, timestamp, body); err != nil {
+				log.Warn("failed to sign response", "path", ctx.FullPath(), "err", err)
+			} else {
+				originalWriter.Header().Set(SignatureHeader, signature)
+				originalWriter.Header().Set(SignerHeader, signer)
+				originalWriter.Header().Set(TimestampHeader, timestamp)
+			}
+		}
+		// Nothing is written for empty bodies, so outer middlewares still see an unwritten response.
+		if len(body) == 0 {
+			return
+		}
+		if _, err := originalWriter.Write(body); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
+
+// ResponseSigningPayload returns the payload whose keccak256 hash signs a response: the newline separated request
+// method, request path, canonical query (keys sorted, as url.Values.Encode returns it), response timestamp, request
+// nonce (empty if none) and canonical body, see CanonicalizeJSON.
+func ResponseSigningPayload(method, path, query, timestamp, nonce string, canonicalBody []byte) []byte {
+	var buf bytes.Buffer
+	for _, field := range []string{method, path, canonicalQuery(query), timestamp, nonce} {
+		buf.WriteString(field)
+		buf.WriteByte('\n')
+	}
+	buf.Write(canonicalBody)
+	return buf.Bytes()
+}
+
+// CanonicalizeJSON returns the canonical form of a JSON document: object keys sorted by their UTF-8 bytes, no
+// insignificant whitespace, strings escaped as encoding/json escapes them without HTML escaping, and numbers kept as
+// written. It is not the RFC 8785 (JCS) form, which sorts keys by their UTF-16 code units and rewrites numbers in
+// their shortest ECMAScript form, so clients must canonicalize the body with the same rules to verify the signature.
+// The API renders amounts as strings, so the number rules do not matter for them.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	// Encode terminates the document with a newline, which is not part of the canonical form.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// canonicalQuery sorts the query parameters by key, a query which can not be parsed is kept as is.
+func canonicalQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+	return values.Encode()
+}
+
+func signJSON(key *ecdsa.PrivateKey, req *http.Request, timestamp string, body []byte) (string, error) {
+	canonical, err := CanonicalizeJSON(body)
+	if err != nil {
+		return "", err
+	}
+	payload := ResponseSigningPayload(req.Method, req.URL.Path, req.URL.RawQuery, timestamp, req.Header.Get(NonceHeader), canonical)
+	signature, err := crypto.Sign(crypto.Keccak256(payload), key)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signature), nil
+}
+
+// bufferedWriter holds the response body until it is signed, the status code and headers are still set on
+// the underlying writer, which only sends them on the first write.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write buffers the data written to the response body.
+func (b *bufferedWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// WriteString buffers the string written to the response body.
+func (b *bufferedWriter) WriteString(s string) (int, error) {
+	return b.body.WriteString(s)
+}
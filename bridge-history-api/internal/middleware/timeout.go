@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/database"
+
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// Timeout returns the middleware bounding the handling time of a request.
+// The deadline is set on the request context, handlers are expected to propagate it to their db and redis calls,
+// a timeout response is rendered if the deadline is exceeded before a response is written.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeoutCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(timeoutCtx)
+
+		ctx.Next()
+
+		if !ctx.Writer.Written() && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			ctx.AbortWithStatusJSON(http.StatusGatewayTimeout, types.NewResponse(types.ErrRequestTimeout, "request timeout", nil))
+		}
+	}
+}
+
+// QueryTimeout returns the middleware bounding each db read of a request, by the override of the route path if any,
+// by timeout otherwise. The timeout is set on the request context, the orm reads apply it through
+// database.ReadContext, so a slow query is cancelled instead of holding its connection until the request times out.
+func QueryTimeout(timeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		queryTimeout := timeout
+		if override, ok := overrides[ctx.FullPath()]; ok {
+			queryTimeout = override
+		}
+		ctx.Request = ctx.Request.WithContext(database.WithReadTimeout(ctx.Request.Context(), queryTimeout))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,371 @@
+// Package openapi generates the OpenAPI 3 spec of the apis from their request and response types, and validates
+// responses against it, so that the spec the SDKs are generated from cannot drift from the Go code.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.0.3"
+
+// Patterns of the validation tags of the request parameters.
+const (
+	addressPattern = "^0x[0-9a-fA-F]{40}$"
+	txHashPattern  = "^0x[0-9a-fA-F]{64}$"
+	numericPattern = `^[-+]?[0-9]+(\.[0-9]+)?$`
+)
+
+// Operation describes an api. Params, Body and Data are values of the types the api binds and renders.
+type Operation struct {
+	ID      string // unique operation id, the method name of generated SDKs
+	Method  string
+	Path    string // gin path of the api
+	Summary string
+	Params  interface{} // struct bound from the query by its form tags, nil if none
+	Body    interface{} // struct bound from the json body, nil if none
+	Data    interface{} // data of the response envelope
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                               `json:"openapi"`
+	Info       Info                                 `json:"info"`
+	Paths      map[string]map[string]*PathOperation `json:"paths"`
+	Components Components                           `json:"components"`
+}
+
+// Info is the metadata of a document.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathOperation is an operation of a path of a document.
+type PathOperation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a query parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the json body of an operation.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the named schemas referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of the OpenAPI schema object the generated documents use.
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+	MinItems   *uint64            `json:"minItems,omitempty"`
+	MaxItems   *uint64            `json:"maxItems,omitempty"`
+	Pattern    string             `json:"pattern,omitempty"`
+	Enum       []interface{}      `json:"enum,omitempty"`
+	AllOf      []*Schema          `json:"allOf,omitempty"`
+}
+
+// Generate returns the document of the operations. The structs of the bodies and responses are the named schemas of
+// the components, the envelope of the responses is types.Response, given as envelope.
+func Generate(info Info, envelope interface{}, operations []*Operation) *Document {
+	g := &generator{schemas: make(map[string]*Schema)}
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]map[string]*PathOperation),
+		Components: Components{Schemas: g.schemas},
+	}
+	for _, op := range operations {
+		pathOp := &PathOperation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Parameters:  g.parameters(op.Params),
+			Responses:   make(map[string]*Response),
+		}
+		if op.Body != nil {
+			pathOp.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{"application/json": {Schema: g.schema(reflect.TypeOf(op.Body), nil)}},
+			}
+		}
+		// errors are rendered in the envelope with status 200 as well, the data is then null.
+		pathOp.Responses[strconv.Itoa(http.StatusOK)] = &Response{
+			Description: "the data, or the error code and message",
+			Content:     map[string]*MediaType{"application/json": {Schema: g.envelope(envelope, op.Data)}},
+		}
+		pathOp.Responses[strconv.Itoa(http.StatusInternalServerError)] = &Response{
+			Description: "internal server error",
+			Content:     map[string]*MediaType{"application/json": {Schema: g.envelope(envelope, nil)}},
+		}
+
+		path := openAPIPath(op.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*PathOperation)
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = pathOp
+	}
+	return doc
+}
+
+// Operation returns the operation of the method and gin path, nil if there is none.
+func (d *Document) Operation(method, path string) *PathOperation {
+	return d.Paths[openAPIPath(path)][strings.ToLower(method)]
+}
+
+// openAPIPath converts the parameters of a gin path, e.g. /txs/:hash, to the ones of OpenAPI, e.g. /txs/{hash}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// envelope returns the schema of the response envelope, with the data schema of the operation, null if data is nil.
+func (g *generator) envelope(envelope, data interface{}) *Schema {
+	s := g.inline(reflect.TypeOf(envelope))
+	properties := make(map[string]*Schema, len(s.Properties))
+	for name, property := range s.Properties {
+		properties[name] = property
+	}
+	dataSchema := &Schema{Nullable: true}
+	if data != nil {
+		dataSchema = nullable(g.schema(reflect.TypeOf(data), nil))
+	}
+	properties["data"] = dataSchema
+	s.Properties = properties
+	return s
+}
+
+// schema returns the schema of t, a reference to the named schema of the components for structs. The binding tags
+// of request fields add their constraints.
+func (g *generator) schema(t reflect.Type, binding []string) *Schema {
+	isPtr := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		isPtr = true
+	}
+	var s *Schema
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// registered before its fields, for recursive types.
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.inline(t)
+		}
+		s = &Schema{Ref: "#/components/schemas/" + t.Name()}
+		if isPtr {
+			s = nullable(s)
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		// the items are constrained by the tags following dive.
+		var itemBinding []string
+		for i, tag := range binding {
+			if tag == "dive" {
+				binding, itemBinding = binding[:i], binding[i+1:]
+				break
+			}
+		}
+		s = &Schema{Type: "array", Items: g.schema(t.Elem(), itemBinding), Nullable: t.Kind() == reflect.Slice}
+	case reflect.String:
+		s = &Schema{Type: "string"}
+	case reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = &Schema{Type: "integer", Format: "int64", Minimum: float64Ptr(0)}
+	case reflect.Float32, reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		// interface{} and the like, any value.
+		return &Schema{Nullable: true}
+	}
+	s.Nullable = s.Nullable || isPtr
+	applyBinding(s, binding)
+	return s
+}
+
+// inline returns the object schema of the struct t, the fields of embedded structs are flattened as encoding/json
+// does.
+func (g *generator) inline(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			embedded := g.inline(field.Type)
+			for name, property := range embedded.Properties {
+				s.Properties[name] = property
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		// request bodies are required by their binding tags, responses have the fields not omitted when empty.
+		binding, isRequest := field.Tag.Lookup("binding")
+		bindingTags := strings.Split(binding, ",")
+		s.Properties[name] = g.schema(field.Type, bindingTags)
+		if (isRequest && hasTag(bindingTags, "required")) || (!isRequest && !omitEmpty) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// parameters returns the query parameters of the struct params, by their form and binding tags.
+func (g *generator) parameters(params interface{}) []*Parameter {
+	if params == nil {
+		return nil
+	}
+	t := reflect.TypeOf(params)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var parameters []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		binding := strings.Split(field.Tag.Get("binding"), ",")
+		schema := g.schema(field.Type, binding)
+		schema.Nullable = false
+		parameters = append(parameters, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: hasTag(binding, "required"),
+			Schema:   schema,
+		})
+	}
+	return parameters
+}
+
+// applyBinding adds the constraints of the binding tags of a request field to its schema.
+func applyBinding(s *Schema, binding []string) {
+	for _, tag := range binding {
+		key, value, _ := strings.Cut(tag, "=")
+		switch key {
+		case "address":
+			s.Pattern = addressPattern
+		case "tx_hash":
+			s.Pattern = txHashPattern
+		case "numeric":
+			s.Pattern = numericPattern
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				if number, err := strconv.ParseFloat(v, 64); err == nil && s.Type != "string" {
+					s.Enum = append(s.Enum, number)
+				} else {
+					s.Enum = append(s.Enum, v)
+				}
+			}
+		case "min", "max":
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case s.Type == "array" && key == "min":
+				s.MinItems = uint64Ptr(uint64(bound))
+			case s.Type == "array":
+				s.MaxItems = uint64Ptr(uint64(bound))
+			case key == "min":
+				s.Minimum = float64Ptr(bound)
+			default:
+				s.Maximum = float64Ptr(bound)
+			}
+		}
+	}
+	if hasTag(binding, "required") && s.Type == "array" {
+		s.Nullable = false
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := strings.Split(field.Tag.Get("json"), ",")
+	name := tag[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, hasTag(tag[1:], "omitempty")
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable returns the schema s accepting null too. References are wrapped, siblings of $ref being ignored by
+// OpenAPI 3.0.
+func nullable(s *Schema) *Schema {
+	if s.Ref == "" {
+		s.Nullable = true
+		return s
+	}
+	return &Schema{Nullable: true, AllOf: []*Schema{s}}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func uint64Ptr(u uint64) *uint64 {
+	return &u
+}
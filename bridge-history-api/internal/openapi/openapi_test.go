@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	Hashes []string `json:"hashes" binding:"required,min=1,max=10,dive,tx_hash"`
+	Limit  uint64   `json:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type testQuery struct {
+	Address  string  `form:"address" binding:"required,address"`
+	PageSize uint64  `form:"page_size" binding:"required,min=1,max=100"`
+	Index    *uint64 `form:"index"`
+	Order    string  `form:"order" binding:"omitempty,oneof=asc desc"`
+	Kind     int     `form:"kind" binding:"omitempty,oneof=1 2"`
+}
+
+type testItem struct {
+	Hash   string     `json:"hash"`
+	Amount uint64     `json:"amount"`
+	Tags   []string   `json:"tags"`
+	Parent *testItem  `json:"parent"`
+	Extra  *testExtra `json:"extra,omitempty"`
+}
+
+type testExtra struct {
+	Note string `json:"note"`
+}
+
+type testItemV2 struct {
+	*testItem
+	Claimable bool `json:"claimable"`
+}
+
+type testData struct {
+	Results []*testItemV2 `json:"results"`
+	Total   uint64        `json:"total"`
+}
+
+type testEnvelope struct {
+	ErrCode int         `json:"errcode"`
+	ErrMsg  string      `json:"errmsg"`
+	Data    interface{} `json:"data"`
+}
+
+func testDocument() *Document {
+	return Generate(Info{Title: "test", Version: "v1"}, testEnvelope{}, []*Operation{
+		{ID: "getItems", Method: http.MethodGet, Path: "/api/items/:hash", Params: testQuery{}, Data: testData{}},
+		{ID: "postItems", Method: http.MethodPost, Path: "/api/items", Body: testRequest{}, Data: []*testItem{}},
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	doc := testDocument()
+	assert.Equal(t, Version, doc.OpenAPI)
+
+	get := doc.Paths["/api/items/{hash}"]["get"]
+	assert.Equal(t, "getItems", get.OperationID)
+	assert.Len(t, get.Parameters, 5)
+	assert.Equal(t, "address", get.Parameters[0].Name)
+	assert.True(t, get.Parameters[0].Required)
+	assert.Equal(t, addressPattern, get.Parameters[0].Schema.Pattern)
+	assert.Equal(t, 100.0, *get.Parameters[1].Schema.Maximum)
+	assert.False(t, get.Parameters[2].Required)
+	assert.Equal(t, "integer", get.Parameters[2].Schema.Type)
+	assert.False(t, get.Parameters[2].Schema.Nullable)
+	assert.Equal(t, []interface{}{"asc", "desc"}, get.Parameters[3].Schema.Enum)
+	assert.Equal(t, []interface{}{1.0, 2.0}, get.Parameters[4].Schema.Enum)
+
+	post := doc.Operation(http.MethodPost, "/api/items")
+	assert.Equal(t, "#/components/schemas/testRequest", post.RequestBody.Content["application/json"].Schema.Ref)
+	request := doc.Components.Schemas["testRequest"]
+	assert.Equal(t, []string{"hashes"}, request.Required)
+	assert.Equal(t, uint64(10), *request.Properties["hashes"].MaxItems)
+	assert.Equal(t, txHashPattern, request.Properties["hashes"].Items.Pattern)
+	assert.False(t, request.Properties["hashes"].Nullable)
+
+	// the fields of embedded structs are flattened, the fields omitted when empty are optional.
+	item := doc.Components.Schemas["testItemV2"]
+	assert.ElementsMatch(t, []string{"hash", "amount", "tags", "parent", "claimable"}, item.Required)
+	assert.Contains(t, item.Properties, "extra")
+	assert.True(t, item.Properties["tags"].Nullable)
+	assert.Equal(t, "#/components/schemas/testItem", item.Properties["parent"].AllOf[0].Ref)
+
+	spec, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.Contains(t, string(spec), `"$ref":"#/components/schemas/testData"`)
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := testDocument()
+	validate := func(data interface{}) error {
+		body, err := json.Marshal(testEnvelope{Data: data})
+		assert.NoError(t, err)
+		return doc.ValidateResponse(http.MethodGet, "/api/items/:hash", http.StatusOK, body)
+	}
+
+	parent := &testItem{Hash: "0x01", Tags: []string{"a"}, Extra: &testExtra{Note: "n"}}
+	assert.NoError(t, validate(&testData{Results: []*testItemV2{{testItem: &testItem{Parent: parent}}}, Total: 1}))
+	assert.NoError(t, validate(nil))
+
+	// undocumented or missing properties and mistyped values break the contract.
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": nil, "total": 1, "next": "x"}), "property next is not in the spec")
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": nil}), "required property total is missing")
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": nil, "total": -1}), "less than 0")
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": nil, "total": "1"}), "not a number")
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": []interface{}{"0x01"}, "total": 1}), "is not an object")
+	assert.ErrorContains(t, validate(map[string]interface{}{"results": nil, "total": nil}), "null is not nullable")
+
+	body := []byte(`{"errcode":500,"errmsg":"failed","data":null}`)
+	assert.NoError(t, doc.ValidateResponse(http.MethodGet, "/api/items/:hash", http.StatusInternalServerError, body))
+	assert.Error(t, doc.ValidateResponse(http.MethodGet, "/api/unknown", http.StatusOK, body))
+}
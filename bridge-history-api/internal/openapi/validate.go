@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateResponse validates the json body of a response of the operation of the method and gin path against the
+// document. Properties missing from the schemas are rejected too, they would be unknown to the generated SDKs.
+func (d *Document) ValidateResponse(method, path string, status int, body []byte) error {
+	op := d.Operation(method, path)
+	if op == nil {
+		return fmt.Errorf("no operation %s %s in the spec", method, path)
+	}
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("no response of status %d of operation %s %s in the spec", status, method, path)
+	}
+	media, ok := response.Content["application/json"]
+	if !ok {
+		return fmt.Errorf("no json response of status %d of operation %s %s in the spec", status, method, path)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to decode response, error: %w", err)
+	}
+	return d.validate(media.Schema, value, "$")
+}
+
+func (d *Document) validate(s *Schema, value interface{}, at string) error {
+	if s.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", at, s.Ref)
+		}
+		return d.validate(resolved, value, at)
+	}
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not nullable", at)
+	}
+	for _, all := range s.AllOf {
+		if err := d.validate(all, value, at); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %v is not an object", at, value)
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: required property %s is missing", at, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				return fmt.Errorf("%s: property %s is not in the spec", at, name)
+			}
+			if err := d.validate(property, object[name], at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %v is not an array", at, value)
+		}
+		if s.MinItems != nil && uint64(len(array)) < *s.MinItems {
+			return fmt.Errorf("%s: %d items, less than %d", at, len(array), *s.MinItems)
+		}
+		if s.MaxItems != nil && uint64(len(array)) > *s.MaxItems {
+			return fmt.Errorf("%s: %d items, more than %d", at, len(array), *s.MaxItems)
+		}
+		for i, item := range array {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: %v is not a string", at, value)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			return fmt.Errorf("%s: %q does not match %s", at, str, s.Pattern)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: %v is not a boolean", at, value)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: %v is not a number", at, value)
+		}
+		f, err := number.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %v is not a number", at, value)
+		}
+		if s.Type == "integer" && strings.ContainsAny(number.String(), ".eE") {
+			return fmt.Errorf("%s: %v is not an integer", at, value)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Errorf("%s: %v is less than %v", at, value, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Errorf("%s: %v is more than %v", at, value, *s.Maximum)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %s", at, s.Type)
+	}
+	return nil
+}
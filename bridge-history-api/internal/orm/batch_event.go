@@ -3,10 +3,14 @@ package orm
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
 )
 
 // BatchStatusType represents the type of batch status.
@@ -29,21 +33,42 @@ const (
 	UpdateStatusTypeUpdated
 )
 
+// BatchParseStatusType represents the result of decoding the commit tx of a batch.
+type BatchParseStatusType int
+
+// Constants for BatchParseStatusType.
+const (
+	BatchParseStatusTypeUnknown BatchParseStatusType = iota
+	BatchParseStatusTypeParsed
+	// BatchParseStatusTypeUnsupported is the status of the batches of an unknown codec version or commit method, they
+	// are parsed again by the fetchers supporting them.
+	BatchParseStatusTypeUnsupported
+	BatchParseStatusTypeInvalid
+)
+
 // BatchEvent represents a batch event.
 type BatchEvent struct {
 	db *gorm.DB `gorm:"column:-"`
 
-	ID               uint64     `json:"id" gorm:"column:id;primary_key"`
-	L1BlockNumber    uint64     `json:"l1_block_number" gorm:"column:l1_block_number"`
-	BatchStatus      int        `json:"batch_status" gorm:"column:batch_status"`
-	BatchIndex       uint64     `json:"batch_index" gorm:"column:batch_index"`
-	BatchHash        string     `json:"batch_hash" gorm:"column:batch_hash"`
-	StartBlockNumber uint64     `json:"start_block_number" gorm:"column:start_block_number"`
-	EndBlockNumber   uint64     `json:"end_block_number" gorm:"column:end_block_number"`
-	UpdateStatus     int        `json:"update_status" gorm:"column:update_status"`
-	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at"`
-	DeletedAt        *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
+	ID                     uint64     `json:"id" gorm:"column:id;primary_key"`
+	L1BlockNumber          uint64     `json:"l1_block_number" gorm:"column:l1_block_number"`
+	BatchStatus            int        `json:"batch_status" gorm:"column:batch_status"`
+	BatchIndex             uint64     `json:"batch_index" gorm:"column:batch_index"`
+	BatchHash              string     `json:"batch_hash" gorm:"column:batch_hash"`
+	StartBlockNumber       uint64     `json:"start_block_number" gorm:"column:start_block_number"`
+	EndBlockNumber         uint64     `json:"end_block_number" gorm:"column:end_block_number"`
+	CommitTxHash           string     `json:"commit_tx_hash" gorm:"column:commit_tx_hash"`
+	CommitBlockTimestamp   uint64     `json:"commit_block_timestamp" gorm:"column:commit_block_timestamp"` // 0 if committed before it was stored.
+	CodecVersion           int        `json:"codec_version" gorm:"column:codec_version"`
+	BlobVersionedHash      string     `json:"blob_versioned_hash" gorm:"column:blob_versioned_hash"` // empty before codec v1.
+	ParseStatus            int        `json:"parse_status" gorm:"column:parse_status"`
+	UpdateStatus           int        `json:"update_status" gorm:"column:update_status"`
+	FinalizeBlockTimestamp uint64     `json:"finalize_block_timestamp" gorm:"column:finalize_block_timestamp"` // 0 if not finalized or unknown.
+	FinalizeL1BlockNumber  uint64     `json:"finalize_l1_block_number" gorm:"column:finalize_l1_block_number"` // 0 if not finalized or unknown.
+	FinalizeTxHash         string     `json:"finalize_tx_hash" gorm:"column:finalize_tx_hash"`                 // empty if not finalized or unknown.
+	CreatedAt              time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt              *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
 }
 
 // TableName returns the table name for the BatchEvent model.
@@ -58,6 +83,9 @@ func NewBatchEvent(db *gorm.DB) *BatchEvent {
 
 // GetBatchEventSyncedHeightInDB returns the maximum l1_block_number from the batch_event_v2 table.
 func (c *BatchEvent) GetBatchEventSyncedHeightInDB(ctx context.Context) (uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var batch BatchEvent
 	db := c.db.WithContext(ctx)
 	db = db.Model(&BatchEvent{})
@@ -73,6 +101,9 @@ func (c *BatchEvent) GetBatchEventSyncedHeightInDB(ctx context.Context) (uint64,
 
 // GetFinalizedBatchesLEBlockHeight returns the finalized batches with end block <= given block height in db.
 func (c *BatchEvent) GetFinalizedBatchesLEBlockHeight(ctx context.Context, blockHeight uint64) ([]*BatchEvent, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var batches []*BatchEvent
 	db := c.db.WithContext(ctx)
 	db = db.Model(&BatchEvent{})
@@ -89,6 +120,58 @@ func (c *BatchEvent) GetFinalizedBatchesLEBlockHeight(ctx context.Context, block
 	return batches, nil
 }
 
+// GetUnparsedBatchEvents returns the committed batches whose commit tx is of an unsupported codec version or method.
+func (c *BatchEvent) GetUnparsedBatchEvents(ctx context.Context) ([]*BatchEvent, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var batches []*BatchEvent
+	db := c.db.WithContext(ctx)
+	db = db.Model(&BatchEvent{})
+	db = db.Where("parse_status = ?", BatchParseStatusTypeUnsupported)
+	db = db.Order("batch_index asc")
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unparsed batches, error: %w", err)
+	}
+	return batches, nil
+}
+
+// GetBatchesByL2BlockNumbers returns the committed or finalized batches including the given L2 blocks, by block
+// number, in one query. The blocks of batches not committed yet or without a block range are missing.
+func (c *BatchEvent) GetBatchesByL2BlockNumbers(ctx context.Context, blockNumbers []uint64) (map[uint64]*BatchEvent, error) {
+	if len(blockNumbers) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var rows []struct {
+		L2BlockNumber uint64 `gorm:"column:l2_block_number"`
+		BatchEvent
+	}
+	// the block numbers are passed as one array literal, gorm would expand a slice into a list.
+	numbers := make([]string, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		numbers[i] = strconv.FormatUint(blockNumber, 10)
+	}
+	// the first batch ending at or after a block is the one including it, unless the block is in a batch without a
+	// block range.
+	sql := `SELECT blocks.l2_block_number, be.* FROM unnest(CAST(? AS BIGINT[])) AS blocks(l2_block_number)
+		JOIN LATERAL (SELECT * FROM batch_event_v2
+			WHERE end_block_number >= blocks.l2_block_number AND start_block_number <= blocks.l2_block_number
+			AND batch_status IN ? AND deleted_at IS NULL
+			ORDER BY end_block_number ASC LIMIT 1) AS be ON TRUE`
+	statuses := []BatchStatusType{BatchStatusTypeCommitted, BatchStatusTypeFinalized}
+	if err := c.db.WithContext(ctx).Raw(sql, "{"+strings.Join(numbers, ",")+"}", statuses).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get batches by L2 block numbers, error: %w", err)
+	}
+	batches := make(map[uint64]*BatchEvent, len(rows))
+	for i := range rows {
+		batches[rows[i].L2BlockNumber] = &rows[i].BatchEvent
+	}
+	return batches, nil
+}
+
 // InsertOrUpdateBatchEvents inserts a new batch event or updates an existing one based on the BatchStatusType.
 func (c *BatchEvent) InsertOrUpdateBatchEvents(ctx context.Context, l1BatchEvents []*BatchEvent) error {
 	for _, l1BatchEvent := range l1BatchEvents {
@@ -110,6 +193,9 @@ func (c *BatchEvent) InsertOrUpdateBatchEvents(ctx context.Context, l1BatchEvent
 			db = db.Where("batch_index = ?", l1BatchEvent.BatchIndex)
 			db = db.Where("batch_hash = ?", l1BatchEvent.BatchHash)
 			updateFields["batch_status"] = BatchStatusTypeFinalized
+			updateFields["finalize_block_timestamp"] = l1BatchEvent.FinalizeBlockTimestamp
+			updateFields["finalize_l1_block_number"] = l1BatchEvent.FinalizeL1BlockNumber
+			updateFields["finalize_tx_hash"] = l1BatchEvent.FinalizeTxHash
 			if err := db.Updates(updateFields).Error; err != nil {
 				return fmt.Errorf("failed to update batch event, error: %w", err)
 			}
@@ -129,6 +215,24 @@ func (c *BatchEvent) InsertOrUpdateBatchEvents(ctx context.Context, l1BatchEvent
 	return nil
 }
 
+// UpdateBatchEventParseResult updates the block range and the parse result of the commit tx of a batch.
+func (c *BatchEvent) UpdateBatchEventParseResult(ctx context.Context, batch *BatchEvent) error {
+	db := c.db.WithContext(ctx)
+	db = db.Model(&BatchEvent{})
+	db = db.Where("batch_hash = ?", batch.BatchHash)
+	updateFields := map[string]interface{}{
+		"start_block_number":  batch.StartBlockNumber,
+		"end_block_number":    batch.EndBlockNumber,
+		"codec_version":       batch.CodecVersion,
+		"blob_versioned_hash": batch.BlobVersionedHash,
+		"parse_status":        batch.ParseStatus,
+	}
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("failed to update batch event parse result, batchHash: %s, error: %w", batch.BatchHash, err)
+	}
+	return nil
+}
+
 // UpdateBatchEventStatus updates the UpdateStatusType of a BatchEvent given its batch index.
 func (c *BatchEvent) UpdateBatchEventStatus(ctx context.Context, batchIndex uint64) error {
 	db := c.db.WithContext(ctx)
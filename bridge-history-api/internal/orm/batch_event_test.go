@@ -0,0 +1,73 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalizeL1BlockNumber(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	batchEventOrm := NewBatchEvent(db)
+
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 1, BatchHash: "0xb1", L1BlockNumber: 90, StartBlockNumber: 1, EndBlockNumber: 10, ParseStatus: int(BatchParseStatusTypeParsed)},
+	}))
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 1, BatchHash: "0xb1", L1BlockNumber: 100, FinalizeL1BlockNumber: 100, FinalizeBlockTimestamp: 1600},
+	}))
+
+	batches, err := batchEventOrm.GetFinalizedBatchesLEBlockHeight(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	// the block of the commit tx is kept, the one of the finalize tx is stored along.
+	assert.Equal(t, uint64(90), batches[0].L1BlockNumber)
+	assert.Equal(t, uint64(100), batches[0].FinalizeL1BlockNumber)
+
+	// the finalize tx reorged into a later block.
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 1, BatchHash: "0xb1", L1BlockNumber: 101, FinalizeL1BlockNumber: 101, FinalizeBlockTimestamp: 1612},
+	}))
+	batches, err = batchEventOrm.GetFinalizedBatchesLEBlockHeight(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, uint64(101), batches[0].FinalizeL1BlockNumber)
+}
+
+func TestGetBatchesByL2BlockNumbers(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	batchEventOrm := NewBatchEvent(db)
+
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 1, BatchHash: "0xb1", L1BlockNumber: 90, CommitTxHash: "0xc1", CommitBlockTimestamp: 1500, StartBlockNumber: 1, EndBlockNumber: 10, ParseStatus: int(BatchParseStatusTypeParsed)},
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 2, BatchHash: "0xb2", L1BlockNumber: 91, CommitTxHash: "0xc2", CommitBlockTimestamp: 1512, StartBlockNumber: 11, EndBlockNumber: 20, ParseStatus: int(BatchParseStatusTypeParsed)},
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 3, BatchHash: "0xb3", L1BlockNumber: 92, CommitTxHash: "0xc3", ParseStatus: int(BatchParseStatusTypeUnsupported)},
+	}))
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 1, BatchHash: "0xb1", L1BlockNumber: 100, FinalizeL1BlockNumber: 100, FinalizeBlockTimestamp: 1600, FinalizeTxHash: "0xf1"},
+	}))
+
+	batches, err := batchEventOrm.GetBatchesByL2BlockNumbers(ctx, []uint64{1, 10, 15, 21})
+	assert.NoError(t, err)
+	assert.Len(t, batches, 3)
+	assert.Equal(t, uint64(1), batches[1].BatchIndex)
+	assert.Equal(t, uint64(1), batches[10].BatchIndex)
+	assert.Equal(t, "0xf1", batches[10].FinalizeTxHash)
+	assert.Equal(t, uint64(1600), batches[10].FinalizeBlockTimestamp)
+	assert.Equal(t, uint64(2), batches[15].BatchIndex)
+	assert.Equal(t, "0xc2", batches[15].CommitTxHash)
+	assert.Equal(t, uint64(1512), batches[15].CommitBlockTimestamp)
+	// the block after the last batch with a block range is not committed or in the unparsed batch.
+	assert.NotContains(t, batches, uint64(21))
+
+	// a reverted batch no longer includes its blocks.
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeReverted), BatchIndex: 2, BatchHash: "0xb2", L1BlockNumber: 101},
+	}))
+	batches, err = batchEventOrm.GetBatchesByL2BlockNumbers(ctx, []uint64{15})
+	assert.NoError(t, err)
+	assert.Empty(t, batches)
+}
@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNetworkMismatch is returned when the database was indexed from another network than the configured one.
+var ErrNetworkMismatch = errors.New("the database was indexed from another network")
+
+// BridgeNetwork holds the chain ids of the L1 and L2 networks the data of the database was indexed from.
+type BridgeNetwork struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        int       `json:"id" gorm:"column:id;primary_key"`
+	L1ChainID uint64    `json:"l1_chain_id" gorm:"column:l1_chain_id"`
+	L2ChainID uint64    `json:"l2_chain_id" gorm:"column:l2_chain_id"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName returns the table name for the BridgeNetwork model.
+func (*BridgeNetwork) TableName() string {
+	return "bridge_network"
+}
+
+// NewBridgeNetwork returns a new instance of BridgeNetwork.
+func NewBridgeNetwork(db *gorm.DB) *BridgeNetwork {
+	return &BridgeNetwork{db: db}
+}
+
+// GetBridgeNetwork returns the network recorded in the database, or nil if none is recorded yet.
+func (b *BridgeNetwork) GetBridgeNetwork(ctx context.Context) (*BridgeNetwork, error) {
+	var network BridgeNetwork
+	db := b.db.WithContext(ctx)
+	db = db.Model(&BridgeNetwork{})
+	if err := db.First(&network).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bridge network, error: %w", err)
+	}
+	return &network, nil
+}
+
+// CheckOrRecordBridgeNetwork records the chain ids if no network is recorded yet, and returns ErrNetworkMismatch if
+// the recorded network differs from them.
+func (b *BridgeNetwork) CheckOrRecordBridgeNetwork(ctx context.Context, l1ChainID, l2ChainID uint64) error {
+	db := b.db.WithContext(ctx)
+	db = db.Clauses(clause.OnConflict{DoNothing: true})
+	if err := db.Create(&BridgeNetwork{ID: 1, L1ChainID: l1ChainID, L2ChainID: l2ChainID}).Error; err != nil {
+		return fmt.Errorf("failed to record bridge network, l1 chain id: %v, l2 chain id: %v, error: %w", l1ChainID, l2ChainID, err)
+	}
+	network, err := b.GetBridgeNetwork(ctx)
+	if err != nil {
+		return err
+	}
+	return network.Check(l1ChainID, l2ChainID)
+}
+
+// Check returns ErrNetworkMismatch if the chain ids differ from the recorded ones.
+func (b *BridgeNetwork) Check(l1ChainID, l2ChainID uint64) error {
+	if b.L1ChainID != l1ChainID || b.L2ChainID != l2ChainID {
+		return fmt.Errorf("%w, recorded l1 chain id: %v, l2 chain id: %v, configured l1 chain id: %v, l2 chain id: %v",
+			ErrNetworkMismatch, b.L1ChainID, b.L2ChainID, l1ChainID, l2ChainID)
+	}
+	return nil
+}
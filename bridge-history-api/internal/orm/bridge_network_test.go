@@ -0,0 +1,31 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgeNetworkOrm(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	bridgeNetworkOrm := NewBridgeNetwork(db)
+
+	network, err := bridgeNetworkOrm.GetBridgeNetwork(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, network)
+
+	// the first check records the network, the next ones compare against it.
+	assert.NoError(t, bridgeNetworkOrm.CheckOrRecordBridgeNetwork(ctx, 1, 534352))
+	assert.NoError(t, bridgeNetworkOrm.CheckOrRecordBridgeNetwork(ctx, 1, 534352))
+	assert.ErrorIs(t, bridgeNetworkOrm.CheckOrRecordBridgeNetwork(ctx, 11155111, 534351), ErrNetworkMismatch)
+	assert.ErrorIs(t, bridgeNetworkOrm.CheckOrRecordBridgeNetwork(ctx, 1, 534351), ErrNetworkMismatch)
+
+	network, err = bridgeNetworkOrm.GetBridgeNetwork(ctx)
+	assert.NoError(t, err)
+	if assert.NotNil(t, network) {
+		assert.Equal(t, uint64(1), network.L1ChainID)
+		assert.Equal(t, uint64(534352), network.L2ChainID)
+	}
+}
@@ -0,0 +1,228 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+)
+
+// Gateways of gateway_daily_stats which are not gateway addresses.
+const (
+	GatewayMessenger = "messenger" // messages sent by calling the messenger directly
+	GatewayUnknown   = "unknown"   // deposits indexed before their message sender was stored
+)
+
+// statsDayExpr is the UTC day of the source block of a message.
+const statsDayExpr = "(to_timestamp(block_timestamp) AT TIME ZONE 'UTC')::date"
+
+// TokenDailyStat is the number and volume of the messages of a token in a direction on a day.
+type TokenDailyStat struct {
+	Day            time.Time `json:"day" gorm:"column:day;primary_key"`
+	MessageType    int       `json:"message_type" gorm:"column:message_type;primary_key"`
+	TokenType      int       `json:"token_type" gorm:"column:token_type;primary_key"`
+	L1TokenAddress string    `json:"l1_token_address" gorm:"column:l1_token_address;primary_key"`
+	L2TokenAddress string    `json:"l2_token_address" gorm:"column:l2_token_address"`
+	TxCount        uint64    `json:"tx_count" gorm:"column:tx_count"`
+	Volume         BigInt    `json:"volume" gorm:"column:volume"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the TokenDailyStat model.
+func (*TokenDailyStat) TableName() string {
+	return "token_daily_stats"
+}
+
+// GatewayDailyStat is the number of the messages sent through a gateway in a direction on a day.
+type GatewayDailyStat struct {
+	Day         time.Time `json:"day" gorm:"column:day;primary_key"`
+	MessageType int       `json:"message_type" gorm:"column:message_type;primary_key"`
+	Gateway     string    `json:"gateway" gorm:"column:gateway;primary_key"`
+	TxCount     uint64    `json:"tx_count" gorm:"column:tx_count"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the GatewayDailyStat model.
+func (*GatewayDailyStat) TableName() string {
+	return "gateway_daily_stats"
+}
+
+// BridgerDailyStat is the number of distinct senders of messages on a day.
+type BridgerDailyStat struct {
+	Day                time.Time `json:"day" gorm:"column:day;primary_key"`
+	DepositBridgers    uint64    `json:"deposit_bridgers" gorm:"column:deposit_bridgers"`
+	WithdrawalBridgers uint64    `json:"withdrawal_bridgers" gorm:"column:withdrawal_bridgers"`
+	UniqueBridgers     uint64    `json:"unique_bridgers" gorm:"column:unique_bridgers"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the BridgerDailyStat model.
+func (*BridgerDailyStat) TableName() string {
+	return "bridger_daily_stats"
+}
+
+// TokenStat is the number and volume of the messages of a token over a window.
+type TokenStat struct {
+	TokenType      int    `gorm:"column:token_type"`
+	L1TokenAddress string `gorm:"column:l1_token_address"`
+	L2TokenAddress string `gorm:"column:l2_token_address"`
+	TxCount        uint64 `gorm:"column:tx_count"`
+	Volume         BigInt `gorm:"column:volume"`
+}
+
+// GatewayStat is the number of the messages sent through a gateway in a direction over a window.
+type GatewayStat struct {
+	MessageType int    `gorm:"column:message_type"`
+	Gateway     string `gorm:"column:gateway"`
+	TxCount     uint64 `gorm:"column:tx_count"`
+}
+
+// Orders of the top tokens.
+const (
+	TokenStatOrderByVolume  = "volume"
+	TokenStatOrderByTxCount = "tx_count"
+)
+
+// BridgeStats aggregates the indexed messages into the daily stats tables, and queries them.
+type BridgeStats struct {
+	db *gorm.DB
+}
+
+// NewBridgeStats returns a new instance of BridgeStats.
+func NewBridgeStats(db *gorm.DB) *BridgeStats {
+	return &BridgeStats{db: db}
+}
+
+// GetLastAggregatedDay returns the last day having aggregated stats, the zero time if none.
+func (s *BridgeStats) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	var stat BridgerDailyStat
+	db := s.db.WithContext(ctx)
+	db = db.Model(&BridgerDailyStat{})
+	db = db.Order("day desc")
+	if err := db.First(&stat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get last aggregated day, error: %w", err)
+	}
+	return stat.Day, nil
+}
+
+// AggregateDays recomputes the stats of the UTC days in [from, to) from cross_message_v2. The stats of the days are
+// replaced in a single transaction, so that the messages deleted or re-indexed since the last run are accounted.
+// Messages sent through other senders than the given gateway addresses are aggregated as sent through the messenger.
+func (s *BridgeStats) AggregateDays(ctx context.Context, from, to time.Time, gatewayAddrs []string) error {
+	messages := func(db *gorm.DB) *gorm.DB {
+		db = db.Session(&gorm.Session{NewDB: true}).Model(&CrossMessage{})
+		db = db.Where("block_timestamp >= ? AND block_timestamp < ?", from.Unix(), to.Unix())
+		db = db.Where("message_type IN (?)", []MessageType{MessageTypeL1SentMessage, MessageTypeL2SentMessage})
+		db = db.Where("tx_status <> ?", TxStatusTypeSentTxReverted)
+		return db.Where("deleted_at IS NULL")
+	}
+	fromDay, toDay := from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&TokenDailyStat{}, &GatewayDailyStat{}, &BridgerDailyStat{}} {
+			if err := tx.Where("day >= ? AND day < ?", fromDay, toDay).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		tokens := messages(tx)
+		tokens = tokens.Select(statsDayExpr + " AS day, message_type, token_type, l1_token_address, MAX(l2_token_address), COUNT(*), COALESCE(SUM(token_amounts_numeric), 0)")
+		tokens = tokens.Group("1, 2, 3, 4")
+		sql := "INSERT INTO token_daily_stats (day, message_type, token_type, l1_token_address, l2_token_address, tx_count, volume) ?"
+		if err := tx.Exec(sql, tokens).Error; err != nil {
+			return err
+		}
+
+		gateways := messages(tx)
+		gateways = gateways.Select(statsDayExpr+" AS day, message_type, CASE WHEN message_from IN (?) THEN message_from WHEN message_from = '' THEN ? ELSE ? END, COUNT(*)",
+			gatewayAddrs, GatewayUnknown, GatewayMessenger)
+		gateways = gateways.Group("1, 2, 3")
+		sql = "INSERT INTO gateway_daily_stats (day, message_type, gateway, tx_count) ?"
+		if err := tx.Exec(sql, gateways).Error; err != nil {
+			return err
+		}
+
+		bridgers := messages(tx)
+		bridgers = bridgers.Select(statsDayExpr+" AS day, COUNT(DISTINCT sender) FILTER (WHERE message_type = ?), COUNT(DISTINCT sender) FILTER (WHERE message_type = ?), COUNT(DISTINCT sender)",
+			MessageTypeL1SentMessage, MessageTypeL2SentMessage)
+		bridgers = bridgers.Group("1")
+		sql = "INSERT INTO bridger_daily_stats (day, deposit_bridgers, withdrawal_bridgers, unique_bridgers) ?"
+		return tx.Exec(sql, bridgers).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate daily stats, from: %v, to: %v, error: %w", fromDay, toDay, err)
+	}
+	return nil
+}
+
+// GetTopTokens returns the tokens with the most volume or messages since the UTC day of from, at most limit of them.
+// A zero messageType counts both directions. Volumes are in the smallest unit of each token, ordering tokens of
+// different decimals by volume is only meaningful for a single token type.
+func (s *BridgeStats) GetTopTokens(ctx context.Context, from time.Time, messageType MessageType, tokenType TokenType, orderBy string, limit int) ([]*TokenStat, error) {
+	if orderBy != TokenStatOrderByVolume && orderBy != TokenStatOrderByTxCount {
+		return nil, fmt.Errorf("invalid token stats order %q", orderBy)
+	}
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var stats []*TokenStat
+	db := s.db.WithContext(ctx)
+	db = db.Model(&TokenDailyStat{})
+	db = db.Select("token_type, l1_token_address, MAX(l2_token_address) AS l2_token_address, SUM(tx_count) AS tx_count, SUM(volume) AS volume")
+	db = db.Where("day >= ?", from.UTC().Format(time.DateOnly))
+	if messageType != MessageTypeUnknown {
+		db = db.Where("message_type = ?", messageType)
+	}
+	if tokenType != TokenTypeUnknown {
+		db = db.Where("token_type = ?", tokenType)
+	}
+	db = db.Group("token_type, l1_token_address")
+	db = db.Order(orderBy + " desc, token_type, l1_token_address")
+	db = db.Limit(limit)
+	if err := db.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get top tokens, from: %v, error: %w", from, err)
+	}
+	return stats, nil
+}
+
+// GetGatewayStats returns the number of messages per direction and gateway since the UTC day of from.
+func (s *BridgeStats) GetGatewayStats(ctx context.Context, from time.Time) ([]*GatewayStat, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var stats []*GatewayStat
+	db := s.db.WithContext(ctx)
+	db = db.Model(&GatewayDailyStat{})
+	db = db.Select("message_type, gateway, SUM(tx_count) AS tx_count")
+	db = db.Where("day >= ?", from.UTC().Format(time.DateOnly))
+	db = db.Group("message_type, gateway")
+	db = db.Order("message_type, tx_count desc, gateway")
+	if err := db.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get gateway stats, from: %v, error: %w", from, err)
+	}
+	return stats, nil
+}
+
+// GetBridgerDailyStats returns the numbers of distinct senders of the days since the UTC day of from, by day. Days
+// without messages are missing.
+func (s *BridgeStats) GetBridgerDailyStats(ctx context.Context, from time.Time) ([]*BridgerDailyStat, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var stats []*BridgerDailyStat
+	db := s.db.WithContext(ctx)
+	db = db.Model(&BridgerDailyStat{})
+	db = db.Where("day >= ?", from.UTC().Format(time.DateOnly))
+	db = db.Order("day")
+	if err := db.Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bridger daily stats, from: %v, error: %w", from, err)
+	}
+	return stats, nil
+}
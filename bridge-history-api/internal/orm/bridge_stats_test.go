@@ -0,0 +1,96 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgeStats(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+	bridgeStatsOrm := NewBridgeStats(db)
+
+	day1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	gateway, usdc, usdcL2 := "0x0000000000000000000000000000000000000a01", "0x0000000000000000000000000000000000000b01", "0x0000000000000000000000000000000000000c01"
+
+	deposits := []*CrossMessage{
+		{Sender: "0x01", TokenType: int(TokenTypeETH), TokenAmounts: "100", BlockTimestamp: uint64(day1.Unix()) + 10, MessageFrom: "0x01"},
+		{Sender: "0x01", TokenType: int(TokenTypeERC20), L1TokenAddress: usdc, L2TokenAddress: usdcL2, TokenAmounts: "5", BlockTimestamp: uint64(day1.Unix()) + 20, MessageFrom: gateway},
+		{Sender: "0x02", TokenType: int(TokenTypeERC20), L1TokenAddress: usdc, L2TokenAddress: usdcL2, TokenAmounts: "7", BlockTimestamp: uint64(day2.Unix()) + 30, MessageFrom: gateway},
+		// indexed before the message sender was stored.
+		{Sender: "0x03", TokenType: int(TokenTypeETH), TokenAmounts: "1", BlockTimestamp: uint64(day2.Unix()) + 40},
+		// reverted deposits moved no funds.
+		{Sender: "0x04", TokenType: int(TokenTypeETH), TokenAmounts: "1000", BlockTimestamp: uint64(day2.Unix()) + 50, TxStatus: int(TxStatusTypeSentTxReverted)},
+	}
+	for i, deposit := range deposits {
+		deposit.MessageHash, deposit.L1TxHash, deposit.L1BlockNumber = fmt.Sprintf("0x0%d", i), fmt.Sprintf("0x1%d", i), uint64(i+1)
+		deposit.MessageType, deposit.MessageNonce = int(MessageTypeL1SentMessage), uint64(i)
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, deposits))
+	withdrawals := []*CrossMessage{
+		{Sender: "0x01", TokenType: int(TokenTypeETH), TokenAmounts: "30", BlockTimestamp: uint64(day2.Unix()) + 60, MessageFrom: "0x01"},
+	}
+	for i, withdrawal := range withdrawals {
+		withdrawal.MessageHash, withdrawal.L2TxHash, withdrawal.L2BlockNumber = fmt.Sprintf("0x2%d", i), fmt.Sprintf("0x3%d", i), uint64(i+1)
+		withdrawal.MessageType, withdrawal.MessageNonce = int(MessageTypeL2SentMessage), uint64(i)
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, withdrawals))
+
+	first, last, err := crossMessageOrm.GetSentBlockTimestampRange(ctx, MessageTypeL1SentMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(day1.Unix())+10, first)
+	assert.Equal(t, uint64(day2.Unix())+50, last)
+
+	lastDay, err := bridgeStatsOrm.GetLastAggregatedDay(ctx)
+	assert.NoError(t, err)
+	assert.True(t, lastDay.IsZero())
+
+	// aggregating twice replaces the stats of the days.
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, bridgeStatsOrm.AggregateDays(ctx, day1, day2.Add(24*time.Hour), []string{gateway}))
+	}
+	lastDay, err = bridgeStatsOrm.GetLastAggregatedDay(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, day2, lastDay.UTC())
+
+	tokens, err := bridgeStatsOrm.GetTopTokens(ctx, day1, MessageTypeUnknown, TokenTypeUnknown, TokenStatOrderByVolume, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 2) {
+		assert.Equal(t, int(TokenTypeETH), tokens[0].TokenType)
+		assert.Equal(t, "131", tokens[0].Volume.String())
+		assert.Equal(t, uint64(3), tokens[0].TxCount)
+		assert.Equal(t, usdc, tokens[1].L1TokenAddress)
+		assert.Equal(t, usdcL2, tokens[1].L2TokenAddress)
+		assert.Equal(t, "12", tokens[1].Volume.String())
+	}
+	tokens, err = bridgeStatsOrm.GetTopTokens(ctx, day2, MessageTypeL1SentMessage, TokenTypeUnknown, TokenStatOrderByTxCount, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, uint64(1), tokens[0].TxCount)
+	}
+
+	gateways, err := bridgeStatsOrm.GetGatewayStats(ctx, day1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GatewayStat{
+		{MessageType: int(MessageTypeL1SentMessage), Gateway: gateway, TxCount: 2},
+		{MessageType: int(MessageTypeL1SentMessage), Gateway: GatewayMessenger, TxCount: 1},
+		{MessageType: int(MessageTypeL1SentMessage), Gateway: GatewayUnknown, TxCount: 1},
+		{MessageType: int(MessageTypeL2SentMessage), Gateway: GatewayMessenger, TxCount: 1},
+	}, gateways)
+
+	bridgers, err := bridgeStatsOrm.GetBridgerDailyStats(ctx, day1)
+	assert.NoError(t, err)
+	if assert.Len(t, bridgers, 2) {
+		assert.Equal(t, uint64(1), bridgers[0].DepositBridgers)
+		assert.Equal(t, uint64(1), bridgers[0].UniqueBridgers)
+		assert.Equal(t, uint64(2), bridgers[1].DepositBridgers)
+		assert.Equal(t, uint64(1), bridgers[1].WithdrawalBridgers)
+		assert.Equal(t, uint64(3), bridgers[1].UniqueBridgers)
+	}
+}
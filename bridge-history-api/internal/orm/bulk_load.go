@@ -0,0 +1,234 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// relayedMessageStageTable holds the relayed messages of a reindex until they are merged into cross_message_v2 by
+// ApplyStagedRelayedMessages, it only exists in the reindex schema.
+const relayedMessageStageTable = "reindex_relayed_message"
+
+// BulkEvents are the events of a block range loaded at once during a reindex.
+type BulkEvents struct {
+	// SentMessages are the L1 deposits or the L2 withdrawals, with the reverted gateway txs they are the rows of
+	// cross_message_v2 until the relayed messages are merged.
+	SentMessages []*CrossMessage
+	// RelayedMessages are staged, the relays of a message are merged once all the messages are loaded.
+	RelayedMessages     []*CrossMessage
+	L1MessageInclusions []*L1MessageInclusion
+	FeeVaultWithdrawals []*FeeVaultWithdrawal
+}
+
+// BulkLoader loads the events of a full reindex with postgres COPY instead of row-wise upserts. The rows are copied
+// to temporary tables and inserted from there, rows already loaded, e.g. of a block range fetched again after a
+// failure, are skipped. It requires the pgx driver.
+type BulkLoader struct {
+	db                          *gorm.DB
+	messageDataOffloadThreshold int
+}
+
+// NewBulkLoader returns a new instance of BulkLoader.
+func NewBulkLoader(db *gorm.DB) *BulkLoader {
+	return &BulkLoader{db: db}
+}
+
+// SetMessageDataOffloadThreshold offloads the message data longer than threshold bytes of the loaded messages to
+// cross_message_data, as CrossMessage.SetMessageDataOffloadThreshold.
+func (b *BulkLoader) SetMessageDataOffloadThreshold(threshold int) {
+	b.messageDataOffloadThreshold = threshold
+}
+
+// CreateRelayedMessageStage creates the table staging the relayed messages, if it does not exist.
+func (b *BulkLoader) CreateRelayedMessageStage(ctx context.Context) error {
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS)", relayedMessageStageTable, (&CrossMessage{}).TableName())
+	if err := b.db.WithContext(ctx).Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to create the relayed message stage, error: %w", err)
+	}
+	return nil
+}
+
+// Load loads the events in a single transaction. The message nonce range partitions are created ahead of the loaded
+// messages first, as a reindex indexes them much faster than the partition maintenance creates them.
+func (b *BulkLoader) Load(ctx context.Context, events *BulkEvents) error {
+	if err := NewPartition(b.db).EnsureCrossMessagePartitions(ctx); err != nil {
+		return err
+	}
+
+	setNumericAmounts(events.SentMessages)
+	setNumericAmounts(events.RelayedMessages)
+	sentMessages, messageData := offloadMessageData(events.SentMessages, b.messageDataOffloadThreshold)
+
+	err := b.withPgxTx(ctx, func(tx pgx.Tx) error {
+		if err := copyInsert(ctx, tx, b.db, (&CrossMessage{}).TableName(), sentMessages, "id"); err != nil {
+			return err
+		}
+		if err := copyInsert(ctx, tx, b.db, (&CrossMessageData{}).TableName(), messageData); err != nil {
+			return err
+		}
+		if err := copyInsert(ctx, tx, b.db, (&L1MessageInclusion{}).TableName(), events.L1MessageInclusions); err != nil {
+			return err
+		}
+		if err := copyInsert(ctx, tx, b.db, (&FeeVaultWithdrawal{}).TableName(), events.FeeVaultWithdrawals); err != nil {
+			return err
+		}
+		// the ids of the staged relays follow the order of the events, which breaks the ties of their precedence.
+		columns, rows, err := copyRows(ctx, b.db, events.RelayedMessages, "id")
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		if _, err = tx.CopyFrom(ctx, pgx.Identifier{relayedMessageStageTable}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return fmt.Errorf("failed to copy to %s, error: %w", relayedMessageStageTable, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bulk load events, error: %w", err)
+	}
+	return nil
+}
+
+// ApplyStagedRelayedMessages merges the staged relayed messages into cross_message_v2 with the precedence of the
+// upserts of the fetchers, see mergeRelayedMessages: for each message a successful relay beats a failed one,
+// otherwise the relay of the newest block wins. The relayed and dropped withdrawals leave claimable_withdrawal.
+func (b *BulkLoader) ApplyStagedRelayedMessages(ctx context.Context) error {
+	columns, _, err := copyRows(ctx, b.db, []*CrossMessage{{}}, "id")
+	if err != nil {
+		return err
+	}
+	columnList := strings.Join(columns, ", ")
+
+	// the L2 relays of the L1 deposits keep the failure reason of a relay which could not be traced.
+	l2RelayUpdates := `l2_block_number = excluded.l2_block_number, l2_tx_hash = excluded.l2_tx_hash, tx_status = excluded.tx_status,
+		l2_relay_block_timestamp = excluded.l2_relay_block_timestamp,
+		l2_relay_failure_selector = COALESCE(NULLIF(excluded.l2_relay_failure_selector, ''), cross_message_v2.l2_relay_failure_selector),
+		l2_relay_failure_reason = COALESCE(NULLIF(excluded.l2_relay_failure_reason, ''), cross_message_v2.l2_relay_failure_reason),
+		updated_at = excluded.updated_at`
+	l1RelayUpdates := `l1_block_number = excluded.l1_block_number, l1_tx_hash = excluded.l1_tx_hash, tx_status = excluded.tx_status,
+		l1_tx_gas_used = excluded.l1_tx_gas_used, l1_tx_effective_gas_price = excluded.l1_tx_effective_gas_price,
+		claimed_by = excluded.claimed_by, l1_relay_block_timestamp = excluded.l1_relay_block_timestamp, updated_at = excluded.updated_at`
+
+	err = b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, relay := range []struct {
+			messageType MessageType
+			blockColumn string
+			updates     string
+		}{
+			{MessageTypeL1SentMessage, "l2_block_number", l2RelayUpdates},
+			{MessageTypeL2SentMessage, "l1_block_number", l1RelayUpdates},
+		} {
+			sql := fmt.Sprintf(`INSERT INTO cross_message_v2 (%[1]s)
+				SELECT %[1]s FROM (
+					SELECT DISTINCT ON (message_hash, message_nonce) * FROM %[2]s
+					WHERE message_type = @message_type
+					ORDER BY message_hash, message_nonce, tx_status = @relayed DESC, %[3]s DESC, id DESC
+				) relay
+				ON CONFLICT (message_hash, message_type, message_nonce) DO UPDATE SET %[4]s
+				WHERE cross_message_v2.tx_status NOT IN (@relayed, @dropped, @dropped_refunded)`, columnList, relayedMessageStageTable, relay.blockColumn, relay.updates)
+			args := map[string]interface{}{"message_type": relay.messageType, "relayed": TxStatusTypeRelayed, "dropped": TxStatusTypeDropped, "dropped_refunded": TxStatusTypeDroppedRefunded}
+			if err := tx.Exec(sql, args).Error; err != nil {
+				return fmt.Errorf("failed to merge the relayed messages of message type %d, error: %w", relay.messageType, err)
+			}
+		}
+
+		relayedWithdrawals := tx.Session(&gorm.Session{NewDB: true}).Table(relayedMessageStageTable)
+		relayedWithdrawals = relayedWithdrawals.Select("message_hash")
+		relayedWithdrawals = relayedWithdrawals.Where("message_type = ?", MessageTypeL2SentMessage)
+		db := tx.Session(&gorm.Session{NewDB: true}).Where("message_hash IN (?)", relayedWithdrawals)
+		db = db.Where("NOT EXISTS (?)", stillClaimableWithdrawal(tx))
+		if err := db.Delete(&ClaimableWithdrawal{}).Error; err != nil {
+			return fmt.Errorf("failed to delete the unclaimable withdrawals, error: %w", err)
+		}
+		return tx.Exec(fmt.Sprintf("DROP TABLE %s", relayedMessageStageTable)).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply the staged relayed messages, error: %w", err)
+	}
+	return nil
+}
+
+// withPgxTx runs fn in a transaction of a pgx connection of the pool, as COPY is not available through database/sql.
+func (b *BulkLoader) withPgxTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	return conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("bulk loading requires the pgx driver, got %T", driverConn)
+		}
+		return pgx.BeginFunc(ctx, pgxConn.Conn(), fn)
+	})
+}
+
+// copyInsert copies the models to a temporary table and inserts them into table, skipping the rows which conflict
+// with the loaded ones.
+func copyInsert[T any](ctx context.Context, tx pgx.Tx, db *gorm.DB, table string, models []T, omit ...string) error {
+	columns, rows, err := copyRows(ctx, db, models, omit...)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	stage := "bulk_" + table
+	if _, err = tx.Exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", stage, table)); err != nil {
+		return fmt.Errorf("failed to create %s, error: %w", stage, err)
+	}
+	if _, err = tx.CopyFrom(ctx, pgx.Identifier{stage}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("failed to copy to %s, error: %w", stage, err)
+	}
+	columnList := strings.Join(columns, ", ")
+	if _, err = tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT DO NOTHING", table, columnList, columnList, stage)); err != nil {
+		return fmt.Errorf("failed to insert into %s, error: %w", table, err)
+	}
+	return nil
+}
+
+// copyRows returns the columns of the model and the values of the models, leaving out the omitted columns. The zero
+// created_at and updated_at are set to now, as gorm does on insert.
+func copyRows[T any](ctx context.Context, db *gorm.DB, models []T, omit ...string) ([]string, [][]interface{}, error) {
+	if len(models) == 0 {
+		return nil, nil, nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(models[0]); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the model, error: %w", err)
+	}
+	omitted := make(map[string]bool, len(omit))
+	for _, column := range omit {
+		omitted[column] = true
+	}
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && !omitted[field.DBName] {
+			columns = append(columns, field.DBName)
+		}
+	}
+
+	now := db.NowFunc()
+	rows := make([][]interface{}, 0, len(models))
+	for _, model := range models {
+		value := reflect.Indirect(reflect.ValueOf(model))
+		row := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			field := stmt.Schema.LookUpField(column)
+			fieldValue, isZero := field.ValueOf(ctx, value)
+			if isZero && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) {
+				fieldValue = now
+			}
+			row = append(row, fieldValue)
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
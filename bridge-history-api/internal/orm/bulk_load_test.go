@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkLoader(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	loader := NewBulkLoader(db)
+	loader.SetMessageDataOffloadThreshold(1)
+	assert.NoError(t, loader.CreateRelayedMessageStage(ctx))
+
+	l1Events := &BulkEvents{
+		SentMessages: []*CrossMessage{
+			{MessageHash: "0xd1", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L1BlockNumber: 1, TxStatus: int(TxStatusTypeSent), TokenAmounts: "5"},
+		},
+		RelayedMessages: []*CrossMessage{
+			{MessageHash: "0xw1", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L1BlockNumber: 10, L1TxHash: "0xf1", TxStatus: int(TxStatusTypeFailedRelayed)},
+			{MessageHash: "0xw1", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L1BlockNumber: 12, L1TxHash: "0xf2", TxStatus: int(TxStatusTypeFailedRelayed)},
+			{MessageHash: "0xw2", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 2, L1BlockNumber: 11, L1TxHash: "0xr2", TxStatus: int(TxStatusTypeRelayed), ClaimedBy: "0xc"},
+		},
+	}
+	l2Events := &BulkEvents{
+		SentMessages: []*CrossMessage{
+			{MessageHash: "0xw1", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L2BlockNumber: 3, TxStatus: int(TxStatusTypeSent), MessageData: "0x0102"},
+			{MessageHash: "0xw2", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 2, L2BlockNumber: 4, TxStatus: int(TxStatusTypeSent), MessageValue: "7"},
+		},
+		RelayedMessages: []*CrossMessage{
+			{MessageHash: "0xd1", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 4, L2TxHash: "0xs1", TxStatus: int(TxStatusTypeRelayed)},
+			{MessageHash: "0xd1", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 5, L2TxHash: "0xs2", TxStatus: int(TxStatusTypeFailedRelayed)},
+			{MessageHash: "0xd9", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 9, L2BlockNumber: 6, L2TxHash: "0xs9", TxStatus: int(TxStatusTypeRelayed)},
+		},
+		L1MessageInclusions: []*L1MessageInclusion{{QueueIndex: 1, MessageHash: "0xd1", MessageNonce: 1, L2TxHash: "0xs1", L2BlockNumber: 4}},
+		FeeVaultWithdrawals: []*FeeVaultWithdrawal{{L2TxHash: "0xw1", LogIndex: 1, Value: NewBigInt(big.NewInt(100)), MessageHash: "0xw1", L2BlockNumber: 3}},
+	}
+	// the events of a range fetched again are loaded once.
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, loader.Load(ctx, l1Events))
+		assert.NoError(t, loader.Load(ctx, l2Events))
+	}
+
+	var count int64
+	assert.NoError(t, db.Model(&CrossMessage{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, db.Model(&L1MessageInclusion{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	var withdrawal FeeVaultWithdrawal
+	assert.NoError(t, db.First(&withdrawal).Error)
+	assert.Equal(t, "100", withdrawal.Value.String())
+
+	// both withdrawals are finalized, and claimable until the relays are applied.
+	assert.NoError(t, db.Model(&CrossMessage{}).Where("message_type = ?", MessageTypeL2SentMessage).Update("rollup_status", RollupStatusTypeFinalized).Error)
+	assert.NoError(t, insertClaimableWithdrawals(db, byMessageHashes([]string{"0xw1", "0xw2"})))
+
+	assert.NoError(t, loader.ApplyStagedRelayedMessages(ctx))
+	assert.False(t, db.Migrator().HasTable(relayedMessageStageTable))
+
+	messages := make(map[string]*CrossMessage)
+	var rows []*CrossMessage
+	assert.NoError(t, db.Find(&rows).Error)
+	for _, row := range rows {
+		messages[row.MessageHash] = row
+	}
+	if assert.Len(t, messages, 4) {
+		// a successful relay beats a failed one of a later block.
+		assert.Equal(t, int(TxStatusTypeRelayed), messages["0xd1"].TxStatus)
+		assert.Equal(t, "0xs1", messages["0xd1"].L2TxHash)
+		assert.Equal(t, uint64(1), messages["0xd1"].L1BlockNumber)
+		assert.Equal(t, "5", messages["0xd1"].TokenAmountsNumeric.String())
+		// otherwise the relay of the latest block wins.
+		assert.Equal(t, int(TxStatusTypeFailedRelayed), messages["0xw1"].TxStatus)
+		assert.Equal(t, "0xf2", messages["0xw1"].L1TxHash)
+		assert.True(t, messages["0xw1"].MessageDataOffloaded)
+		assert.Equal(t, int(TxStatusTypeRelayed), messages["0xw2"].TxStatus)
+		assert.Equal(t, "0xc", messages["0xw2"].ClaimedBy)
+		assert.Equal(t, "7", messages["0xw2"].MessageValueNumeric.String())
+		// the relay of a message which is not indexed is kept.
+		assert.Equal(t, "0xs9", messages["0xd9"].L2TxHash)
+	}
+
+	var data CrossMessageData
+	assert.NoError(t, db.Where("message_hash = ?", "0xw1").First(&data).Error)
+	assert.Equal(t, "0x0102", data.MessageData)
+
+	var claimable []*ClaimableWithdrawal
+	assert.NoError(t, db.Find(&claimable).Error)
+	if assert.Len(t, claimable, 1) {
+		assert.Equal(t, "0xw1", claimable[0].MessageHash)
+	}
+}
@@ -0,0 +1,104 @@
+package orm
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// claimableTxStatuses are the tx statuses of finalized L2 withdrawals that can still be claimed on L1.
+var claimableTxStatuses = []TxStatusType{TxStatusTypeSent, TxStatusTypeFailedRelayed, TxStatusTypeRelayTxReverted}
+
+// IsClaimableTxStatus returns whether a finalized L2 withdrawal with the tx status can still be claimed on L1.
+func IsClaimableTxStatus(status TxStatusType) bool {
+	for _, s := range claimableTxStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimableWithdrawal represents a finalized L2 withdrawal which is not relayed on L1 yet.
+// Rows are inserted when the batch of the withdrawal is finalized and deleted once the withdrawal is relayed, dropped
+// or otherwise not claimable any more, in the same transactions as the CrossMessage updates of these statuses.
+type ClaimableWithdrawal struct {
+	ID             uint64    `json:"id" gorm:"column:id;primary_key"`
+	MessageHash    string    `json:"message_hash" gorm:"column:message_hash"`
+	Sender         string    `json:"sender" gorm:"column:sender"`
+	BlockTimestamp uint64    `json:"block_timestamp" gorm:"column:block_timestamp"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName returns the table name for the ClaimableWithdrawal model.
+func (*ClaimableWithdrawal) TableName() string {
+	return "claimable_withdrawal"
+}
+
+// insertClaimableWithdrawals copies the L2 withdrawals matched by the conditions of query into claimable_withdrawal,
+// if they are finalized and not relayed, and records the time they became claimable. query is a scope over
+// cross_message_v2, e.g. a list of message hashes.
+func insertClaimableWithdrawals(db *gorm.DB, query func(db *gorm.DB) *gorm.DB) error {
+	subQuery := claimableWithdrawals(db, query)
+	subQuery = subQuery.Select("message_hash, sender, block_timestamp")
+
+	sql := "INSERT INTO claimable_withdrawal (message_hash, sender, block_timestamp) ? ON CONFLICT (message_hash) DO NOTHING"
+	if err := db.Exec(sql, subQuery).Error; err != nil {
+		return fmt.Errorf("failed to insert claimable withdrawals, error: %w", err)
+	}
+
+	// the first time is kept, e.g. when the finalization of the batch is fetched again.
+	update := claimableWithdrawals(db, query)
+	update = update.Where("claimable_timestamp = 0")
+	if err := update.Update("claimable_timestamp", db.NowFunc().Unix()).Error; err != nil {
+		return fmt.Errorf("failed to update claimable timestamp of withdrawals, error: %w", err)
+	}
+	return nil
+}
+
+// claimableWithdrawals returns the query over cross_message_v2 of the claimable L2 withdrawals matched by query.
+func claimableWithdrawals(db *gorm.DB, query func(db *gorm.DB) *gorm.DB) *gorm.DB {
+	claimable := db.Session(&gorm.Session{NewDB: true}).Model(&CrossMessage{})
+	claimable = claimable.Where("message_type = ?", MessageTypeL2SentMessage)
+	claimable = claimable.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	claimable = claimable.Where("tx_status IN (?)", claimableTxStatuses)
+	claimable = claimable.Where("message_hash IS NOT NULL")
+	claimable = claimable.Where("deleted_at IS NULL")
+	return query(claimable)
+}
+
+// deleteUnclaimableWithdrawals removes the given L2 withdrawals from claimable_withdrawal unless they are still claimable,
+// i.e. the ones which are relayed, dropped, deleted or not finalized any more.
+func deleteUnclaimableWithdrawals(db *gorm.DB, messageHashes []string) error {
+	if len(messageHashes) == 0 {
+		return nil
+	}
+	db = db.Session(&gorm.Session{NewDB: true})
+	db = db.Where("message_hash IN (?)", messageHashes)
+	db = db.Where("NOT EXISTS (?)", stillClaimableWithdrawal(db))
+	if err := db.Delete(&ClaimableWithdrawal{}).Error; err != nil {
+		return fmt.Errorf("failed to delete unclaimable withdrawals, message hashes: %v, error: %w", messageHashes, err)
+	}
+	return nil
+}
+
+// stillClaimableWithdrawal returns the subquery over cross_message_v2 matching the withdrawal of a claimable_withdrawal
+// row if it is still claimable.
+func stillClaimableWithdrawal(db *gorm.DB) *gorm.DB {
+	stillClaimable := db.Session(&gorm.Session{NewDB: true}).Model(&CrossMessage{})
+	stillClaimable = stillClaimable.Select("1")
+	stillClaimable = stillClaimable.Where("cross_message_v2.message_hash = claimable_withdrawal.message_hash")
+	stillClaimable = stillClaimable.Where("message_type = ?", MessageTypeL2SentMessage)
+	stillClaimable = stillClaimable.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	stillClaimable = stillClaimable.Where("tx_status IN (?)", claimableTxStatuses)
+	stillClaimable = stillClaimable.Where("deleted_at IS NULL")
+	return stillClaimable
+}
+
+// byMessageHashes scopes a cross_message_v2 query to the given message hashes.
+func byMessageHashes(messageHashes []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("message_hash IN (?)", messageHashes)
+	}
+}
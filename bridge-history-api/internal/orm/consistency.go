@@ -0,0 +1,83 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"scroll-tech/common/database"
+)
+
+// CountFinalizedWithdrawalsWithoutBatchIndex counts the finalized L2 withdrawals whose batch index is not set.
+func (c *CrossMessage) CountFinalizedWithdrawalsWithoutBatchIndex(ctx context.Context) (int64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var count int64
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("batch_index = 0")
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count finalized withdrawals without batch index, error: %w", err)
+	}
+	return count, nil
+}
+
+// RepairFinalizedWithdrawalsWithoutBatchIndex sets the batch index of the finalized L2 withdrawals without one
+// from the finalized batch containing their block, and returns the number of repaired withdrawals.
+// Withdrawals whose batch is not indexed are left alone.
+func (c *CrossMessage) RepairFinalizedWithdrawalsWithoutBatchIndex(ctx context.Context) (int64, error) {
+	sql := `UPDATE cross_message_v2 SET batch_index = batch_event_v2.batch_index, updated_at = NOW()
+		FROM batch_event_v2
+		WHERE cross_message_v2.message_type = ? AND cross_message_v2.rollup_status = ? AND cross_message_v2.batch_index = 0
+		AND cross_message_v2.deleted_at IS NULL
+		AND batch_event_v2.batch_status = ? AND batch_event_v2.deleted_at IS NULL
+		AND cross_message_v2.l2_block_number BETWEEN batch_event_v2.start_block_number AND batch_event_v2.end_block_number`
+	result := c.db.WithContext(ctx).Exec(sql, MessageTypeL2SentMessage, RollupStatusTypeFinalized, BatchStatusTypeFinalized)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to repair finalized withdrawals without batch index, error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CountRelayedMessagesWithoutTxHashes counts the relayed messages updated before the given time which lack the tx hash
+// of either side. Withdrawals marked relayed by the claim reconciler have no L1 relay tx, they are told apart
+// by their unset L1 block number.
+func (c *CrossMessage) CountRelayedMessagesWithoutTxHashes(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var count int64
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("tx_status = ?", TxStatusTypeRelayed)
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("l2_tx_hash = '' OR (l1_tx_hash = '' AND NOT (message_type = ? AND l1_block_number = 0))", MessageTypeL2SentMessage)
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count relayed messages without tx hashes, updated before: %v, error: %w", updatedBefore, err)
+	}
+	return count, nil
+}
+
+// CountL1MessageQueueIndexViolations counts the L1 messages whose queue index is duplicated, or not above the queue
+// indexes of all the messages of earlier L1 blocks.
+func (c *CrossMessage) CountL1MessageQueueIndexViolations(ctx context.Context) (int64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var count int64
+	sql := `SELECT COUNT(*) FROM (
+			SELECT message_nonce,
+				MAX(message_nonce) OVER (ORDER BY l1_block_number RANGE BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING) AS earlier_max_nonce,
+				COUNT(*) OVER (PARTITION BY message_nonce) AS nonce_count
+			FROM cross_message_v2
+			WHERE message_type = ? AND tx_status <> ? AND l1_block_number > 0 AND deleted_at IS NULL
+		) AS queue
+		WHERE message_nonce <= earlier_max_nonce OR nonce_count > 1`
+	if err := c.db.WithContext(ctx).Raw(sql, MessageTypeL1SentMessage, TxStatusTypeSentTxReverted).Scan(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count L1 message queue index violations, error: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalizedWithdrawalsWithoutBatchIndex(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	assert.NoError(t, db.Create(&BatchEvent{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 3, BatchHash: "0x03", StartBlockNumber: 1, EndBlockNumber: 10}).Error)
+	// the batch containing block 50 is not indexed, the withdrawal can not be repaired.
+	blockNumbers := []uint64{5, 50, 8}
+	batchIndexes := []uint64{0, 0, 2}
+	var messages []*CrossMessage
+	for i := range blockNumbers {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL2SentMessage), MessageNonce: uint64(i),
+			L2TxHash: fmt.Sprintf("0x1%d", i), L2BlockNumber: blockNumbers[i], BatchIndex: batchIndexes[i], TokenAmounts: "1", RollupStatus: int(RollupStatusTypeFinalized)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, messages))
+
+	count, err := crossMessageOrm.CountFinalizedWithdrawalsWithoutBatchIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	repaired, err := crossMessageOrm.RepairFinalizedWithdrawalsWithoutBatchIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), repaired)
+	message, err := crossMessageOrm.GetMessageByMessageHash(ctx, "0x00")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), message.BatchIndex)
+
+	count, err = crossMessageOrm.CountFinalizedWithdrawalsWithoutBatchIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRelayedMessagesWithoutTxHashes(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	deposits := []*CrossMessage{
+		{MessageHash: "0x00", L1TxHash: "0x10", L2TxHash: "0x20", L1BlockNumber: 1},
+		// relayed before the L1 fetcher indexed the deposit.
+		{MessageHash: "0x01", L2TxHash: "0x21"},
+		{MessageHash: "0x02", L2TxHash: "0x22"},
+	}
+	for i, deposit := range deposits {
+		deposit.MessageType, deposit.MessageNonce, deposit.TokenAmounts, deposit.TxStatus = int(MessageTypeL1SentMessage), uint64(i), "1", int(TxStatusTypeRelayed)
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, deposits))
+	withdrawals := []*CrossMessage{
+		// marked relayed by the claim reconciler.
+		{MessageHash: "0x03", L2TxHash: "0x23"},
+		{MessageHash: "0x04", L2TxHash: "0x24", L1BlockNumber: 9},
+	}
+	for i, withdrawal := range withdrawals {
+		withdrawal.MessageType, withdrawal.MessageNonce, withdrawal.TokenAmounts, withdrawal.TxStatus = int(MessageTypeL2SentMessage), uint64(i), "1", int(TxStatusTypeRelayed)
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, withdrawals))
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ?", time.Now().UTC().Add(-2*time.Hour)).Error)
+	// within the grace period, the L1 fetcher may still catch up.
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ? WHERE message_hash = ?", time.Now().UTC(), "0x02").Error)
+
+	count, err := crossMessageOrm.CountRelayedMessagesWithoutTxHashes(ctx, time.Now().UTC().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestL1MessageQueueIndexViolations(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	insertDeposits := func(nonces, blockNumbers []uint64) {
+		var messages []*CrossMessage
+		for i := range nonces {
+			messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x%d%d", nonces[i], blockNumbers[i]), MessageType: int(MessageTypeL1SentMessage),
+				MessageNonce: nonces[i], L1TxHash: fmt.Sprintf("0x1%d", i), L1BlockNumber: blockNumbers[i], TokenAmounts: "1"})
+		}
+		assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+	}
+
+	// the queue indexes within a block may be stored in any order.
+	insertDeposits([]uint64{0, 2, 1, 3}, []uint64{1, 2, 2, 3})
+	count, err := crossMessageOrm.CountL1MessageQueueIndexViolations(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	// a queue index of an earlier block, and a duplicated one.
+	insertDeposits([]uint64{1, 4, 4}, []uint64{4, 5, 6})
+	count, err = crossMessageOrm.CountL1MessageQueueIndexViolations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
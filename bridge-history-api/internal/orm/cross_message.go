@@ -3,11 +3,14 @@ package orm
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
 )
 
 // TokenType represents the type of token.
@@ -52,6 +55,8 @@ const (
 	TxStatusTypeRelayTxReverted
 	TxStatusTypeSkipped
 	TxStatusTypeDropped // Terminal status.
+	// Terminal status, dropped and refunded on L1 by the gateway of the message in the same tx.
+	TxStatusTypeDroppedRefunded
 )
 
 // RollupStatusType represents the status of a rollup.
@@ -76,49 +81,82 @@ const (
 
 // MessageQueueEvent struct represents the details of a batch event.
 type MessageQueueEvent struct {
-	EventType  MessageQueueEventType
-	QueueIndex uint64
+	EventType     MessageQueueEventType
+	QueueIndex    uint64
+	L1BlockNumber uint64
 
 	// Track replay tx hash and refund tx hash.
 	TxHash common.Hash
 
 	// QueueTransaction only in replayMessage, to track which message is replayed.
 	MessageHash common.Hash
+
+	// DropTransaction only, whether the gateway of the message emitted a refund event in the same tx.
+	Refunded bool
+}
+
+// TokenAmountSum is the total token amount of the messages of a token.
+type TokenAmountSum struct {
+	MessageType    int    `gorm:"column:message_type"`
+	TokenType      int    `gorm:"column:token_type"`
+	L1TokenAddress string `gorm:"column:l1_token_address"`
+	TotalAmount    BigInt `gorm:"column:total_amount"`
+	TxCount        uint64 `gorm:"column:tx_count"`
+}
+
+// LatencyStat is the median of a sample of latencies, in seconds.
+type LatencyStat struct {
+	SampleCount uint64  `gorm:"column:sample_count"`
+	MedianSec   float64 `gorm:"column:median_sec"`
 }
 
 // CrossMessage represents a cross message.
 type CrossMessage struct {
-	db *gorm.DB `gorm:"column:-"`
-
-	ID             uint64     `json:"id" gorm:"column:id;primary_key"`
-	MessageType    int        `json:"message_type" gorm:"column:message_type"`
-	RollupStatus   int        `json:"rollup_status" gorm:"column:rollup_status"`
-	TxStatus       int        `json:"tx_status" gorm:"column:tx_status"`
-	TokenType      int        `json:"token_type" gorm:"column:token_type"`
-	Sender         string     `json:"sender" gorm:"column:sender"`
-	Receiver       string     `json:"receiver" gorm:"column:receiver"`
-	MessageHash    string     `json:"message_hash" gorm:"column:message_hash"`
-	L1TxHash       string     `json:"l1_tx_hash" gorm:"column:l1_tx_hash"` // initial tx hash, if MessageType is MessageTypeL1SentMessage.
-	L1ReplayTxHash string     `json:"l1_replay_tx_hash" gorm:"column:l1_replay_tx_hash"`
-	L1RefundTxHash string     `json:"l1_refund_tx_hash" gorm:"column:l1_refund_tx_hash"`
-	L2TxHash       string     `json:"l2_tx_hash" gorm:"column:l2_tx_hash"` // initial tx hash, if MessageType is MessageTypeL2SentMessage.
-	L1BlockNumber  uint64     `json:"l1_block_number" gorm:"column:l1_block_number"`
-	L2BlockNumber  uint64     `json:"l2_block_number" gorm:"column:l2_block_number"`
-	L1TokenAddress string     `json:"l1_token_address" gorm:"column:l1_token_address"`
-	L2TokenAddress string     `json:"l2_token_address" gorm:"column:l2_token_address"`
-	TokenIDs       string     `json:"token_ids" gorm:"column:token_ids"`
-	TokenAmounts   string     `json:"token_amounts" gorm:"column:token_amounts"`
-	BlockTimestamp uint64     `json:"block_timestamp" gorm:"column:block_timestamp"`
-	MessageFrom    string     `json:"message_from" gorm:"column:message_from"`
-	MessageTo      string     `json:"message_to" gorm:"column:message_to"`
-	MessageValue   string     `json:"message_value" gorm:"column:message_value"`
-	MessageNonce   uint64     `json:"message_nonce" gorm:"column:message_nonce"`
-	MessageData    string     `json:"message_data" gorm:"column:message_data"`
-	MerkleProof    []byte     `json:"merkle_proof" gorm:"column:merkle_proof"`
-	BatchIndex     uint64     `json:"batch_index" gorm:"column:batch_index"`
-	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at"`
-	DeletedAt      *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
+	db                          *gorm.DB `gorm:"column:-"`
+	messageDataOffloadThreshold int      `gorm:"column:-"` // see SetMessageDataOffloadThreshold
+
+	ID                     uint64     `json:"id" gorm:"column:id;primary_key"`
+	MessageType            int        `json:"message_type" gorm:"column:message_type"`
+	RollupStatus           int        `json:"rollup_status" gorm:"column:rollup_status"`
+	TxStatus               int        `json:"tx_status" gorm:"column:tx_status"`
+	TokenType              int        `json:"token_type" gorm:"column:token_type"`
+	Sender                 string     `json:"sender" gorm:"column:sender"`
+	Receiver               string     `json:"receiver" gorm:"column:receiver"`
+	MessageHash            string     `json:"message_hash" gorm:"column:message_hash"`
+	L1TxHash               string     `json:"l1_tx_hash" gorm:"column:l1_tx_hash"` // initial tx hash, if MessageType is MessageTypeL1SentMessage.
+	L1ReplayTxHash         string     `json:"l1_replay_tx_hash" gorm:"column:l1_replay_tx_hash"`
+	L1RefundTxHash         string     `json:"l1_refund_tx_hash" gorm:"column:l1_refund_tx_hash"`
+	L2TxHash               string     `json:"l2_tx_hash" gorm:"column:l2_tx_hash"` // initial tx hash, if MessageType is MessageTypeL2SentMessage.
+	L1BlockNumber          uint64     `json:"l1_block_number" gorm:"column:l1_block_number"`
+	L2BlockNumber          uint64     `json:"l2_block_number" gorm:"column:l2_block_number"`
+	L1TokenAddress         string     `json:"l1_token_address" gorm:"column:l1_token_address"`
+	L2TokenAddress         string     `json:"l2_token_address" gorm:"column:l2_token_address"`
+	TokenIDs               string     `json:"token_ids" gorm:"column:token_ids"`
+	TokenAmounts           string     `json:"token_amounts" gorm:"column:token_amounts"`
+	BlockTimestamp         uint64     `json:"block_timestamp" gorm:"column:block_timestamp"`
+	MessageFrom            string     `json:"message_from" gorm:"column:message_from"`
+	MessageTo              string     `json:"message_to" gorm:"column:message_to"`
+	MessageValue           string     `json:"message_value" gorm:"column:message_value"`
+	MessageNonce           uint64     `json:"message_nonce" gorm:"column:message_nonce"`
+	MessageData            string     `json:"message_data" gorm:"column:message_data"`
+	MessageDataOffloaded   bool       `json:"message_data_offloaded" gorm:"column:message_data_offloaded"` // the message data is in cross_message_data, see LoadMessageData.
+	MerkleProof            []byte     `json:"merkle_proof" gorm:"column:merkle_proof"`
+	BatchIndex             uint64     `json:"batch_index" gorm:"column:batch_index"`
+	L2RelayFailureSelector string     `json:"l2_relay_failure_selector" gorm:"column:l2_relay_failure_selector"`
+	L2RelayFailureReason   string     `json:"l2_relay_failure_reason" gorm:"column:l2_relay_failure_reason"`
+	L2RelayBlockTimestamp  uint64     `json:"l2_relay_block_timestamp" gorm:"column:l2_relay_block_timestamp"` // only for L1 messages relayed on L2, 0 if unknown.
+	L1TxGasUsed            uint64     `json:"l1_tx_gas_used" gorm:"column:l1_tx_gas_used"`
+	L1TxEffectiveGasPrice  string     `json:"l1_tx_effective_gas_price" gorm:"column:l1_tx_effective_gas_price"`
+	MessageValueNumeric    BigInt     `json:"message_value_numeric" gorm:"column:message_value_numeric"`
+	TokenAmountsNumeric    BigInt     `json:"token_amounts_numeric" gorm:"column:token_amounts_numeric"`
+	DepositCallSelector    string     `json:"deposit_call_selector" gorm:"column:deposit_call_selector"` // only for deposits with call data, e.g. depositERC20AndCall.
+	DepositCallData        string     `json:"deposit_call_data" gorm:"column:deposit_call_data"`
+	ClaimedBy              string     `json:"claimed_by" gorm:"column:claimed_by"`                             // only for L2 messages relayed on L1, the sender of the claim tx.
+	L1RelayBlockTimestamp  uint64     `json:"l1_relay_block_timestamp" gorm:"column:l1_relay_block_timestamp"` // only for L2 messages relayed on L1, 0 if unknown.
+	ClaimableTimestamp     uint64     `json:"claimable_timestamp" gorm:"column:claimable_timestamp"`           // only for L2 messages, the unix time they were indexed as claimable, 0 if unknown.
+	CreatedAt              time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt              *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
 }
 
 // TableName returns the table name for the CrossMessage model.
@@ -133,6 +171,9 @@ func NewCrossMessage(db *gorm.DB) *CrossMessage {
 
 // GetMessageSyncedHeightInDB returns the latest synced cross message height from the database for a given message type.
 func (c *CrossMessage) GetMessageSyncedHeightInDB(ctx context.Context, messageType MessageType) (uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var message CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -159,8 +200,41 @@ func (c *CrossMessage) GetMessageSyncedHeightInDB(ctx context.Context, messageTy
 	}
 }
 
+// GetSentBlockTimestampRange returns the block timestamps of the first and of the latest indexed messages of a given
+// message type by source block, 0 if none is indexed.
+func (c *CrossMessage) GetSentBlockTimestampRange(ctx context.Context, messageType MessageType) (uint64, uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	blockNumberColumn := "l1_block_number"
+	if messageType == MessageTypeL2SentMessage {
+		blockNumberColumn = "l2_block_number"
+	}
+	var timestamps []uint64
+	for _, order := range []string{"asc", "desc"} {
+		var message CrossMessage
+		db := c.db.WithContext(ctx)
+		db = db.Model(&CrossMessage{})
+		db = db.Where("message_type = ?", messageType)
+		// messages whose relay is indexed before them have no source block yet.
+		db = db.Where(blockNumberColumn + " > 0")
+		db = db.Order(blockNumberColumn + " " + order)
+		if err := db.First(&message).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return 0, 0, nil
+			}
+			return 0, 0, fmt.Errorf("failed to get sent block timestamp range, type: %v, error: %w", messageType, err)
+		}
+		timestamps = append(timestamps, message.BlockTimestamp)
+	}
+	return timestamps[0], timestamps[1], nil
+}
+
 // GetL2LatestFinalizedWithdrawal returns the latest finalized L2 withdrawal from the database.
 func (c *CrossMessage) GetL2LatestFinalizedWithdrawal(ctx context.Context) (*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var message CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -178,6 +252,9 @@ func (c *CrossMessage) GetL2LatestFinalizedWithdrawal(ctx context.Context) (*Cro
 
 // GetL2WithdrawalsByBlockRange returns the L2 withdrawals by block range from the database.
 func (c *CrossMessage) GetL2WithdrawalsByBlockRange(ctx context.Context, startBlock, endBlock uint64) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var messages []*CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -197,6 +274,9 @@ func (c *CrossMessage) GetL2WithdrawalsByBlockRange(ctx context.Context, startBl
 
 // GetMessagesByTxHashes retrieves all cross messages from the database that match the provided transaction hashes.
 func (c *CrossMessage) GetMessagesByTxHashes(ctx context.Context, txHashes []string) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var messages []*CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -207,8 +287,74 @@ func (c *CrossMessage) GetMessagesByTxHashes(ctx context.Context, txHashes []str
 	return messages, nil
 }
 
+// GetMessageByMessageHash returns the message of the given message hash, nil if it is not indexed.
+func (c *CrossMessage) GetMessageByMessageHash(ctx context.Context, messageHash string) (*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var message CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_hash = ?", messageHash)
+	if err := db.First(&message).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get message by message hash, message hash: %v, error: %w", messageHash, err)
+	}
+	return &message, nil
+}
+
+// GetMessageNoncesByHashes returns the message nonces of the sent messages with the given message hashes, keyed by message hash.
+func (c *CrossMessage) GetMessageNoncesByHashes(ctx context.Context, messageHashes []string) (map[string]uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Select("message_hash, message_nonce")
+	db = db.Where("message_hash in (?)", messageHashes)
+	// rows of relayed messages indexed before their sent message carry no sender.
+	db = db.Where("sender <> ''")
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get message nonces by message hashes, message hashes: %v, error: %w", messageHashes, err)
+	}
+	nonces := make(map[string]uint64, len(messages))
+	for _, message := range messages {
+		nonces[message.MessageHash] = message.MessageNonce
+	}
+	return nonces, nil
+}
+
+// GetNextL2IncludedL1MessageNonce returns the nonce following the highest one of the L1 messages relayed on L2,
+// successfully or not. L2 includes L1 messages in queue order, so all the messages before it are included.
+func (c *CrossMessage) GetNextL2IncludedL1MessageNonce(ctx context.Context) (uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var message CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Select("message_nonce")
+	db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+	db = db.Where("tx_status IN (?)", []TxStatusType{TxStatusTypeRelayed, TxStatusTypeFailedRelayed, TxStatusTypeRelayTxReverted})
+	db = db.Order("message_nonce desc")
+	result := db.Limit(1).Find(&message)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get next L2 included L1 message nonce, error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, nil
+	}
+	return message.MessageNonce + 1, nil
+}
+
 // GetL2UnclaimedWithdrawalsByAddress retrieves all L2 unclaimed withdrawal messages for a given sender address.
 func (c *CrossMessage) GetL2UnclaimedWithdrawalsByAddress(ctx context.Context, sender string) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var messages []*CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -223,8 +369,84 @@ func (c *CrossMessage) GetL2UnclaimedWithdrawalsByAddress(ctx context.Context, s
 	return messages, nil
 }
 
+// GetL2ClaimableWithdrawalsByAddress retrieves the L2 withdrawal messages of a given sender address which can be claimed on L1 now,
+// i.e. the finalized withdrawals which are not relayed yet, looked up through the claimable_withdrawal table.
+func (c *CrossMessage) GetL2ClaimableWithdrawalsByAddress(ctx context.Context, sender string) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Table(c.TableName() + " AS cm")
+	db = db.Select("cm.*")
+	db = db.Joins("JOIN claimable_withdrawal AS cw ON cw.message_hash = cm.message_hash")
+	// message_type prunes the join to the L2 sent partition.
+	db = db.Where("cm.message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("cw.sender = ?", sender)
+	// the statuses are re-checked so that a stale row is never served as claimable.
+	db = db.Where("cm.rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("cm.tx_status IN (?)", claimableTxStatuses)
+	db = db.Where("cm.deleted_at IS NULL")
+	db = db.Order("cw.block_timestamp desc")
+	db = db.Limit(500)
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get L2 finalized claimable withdrawal messages by sender address, sender: %v, error: %w", sender, err)
+	}
+	return messages, nil
+}
+
+// GetClaimableL2WithdrawalsUpdatedBefore retrieves claimable L2 withdrawals not updated since the given time, ordered by id
+// and starting after afterID, so that callers can page through them.
+func (c *CrossMessage) GetClaimableL2WithdrawalsUpdatedBefore(ctx context.Context, updatedBefore time.Time, afterID uint64, limit int) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("tx_status IN (?)", []TxStatusType{TxStatusTypeSent, TxStatusTypeFailedRelayed, TxStatusTypeRelayTxReverted})
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("id > ?", afterID)
+	db = db.Order("id asc")
+	db = db.Limit(limit)
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get claimable L2 withdrawals, updated before: %v, after id: %v, error: %w", updatedBefore, afterID, err)
+	}
+	return messages, nil
+}
+
+// UpdateL2WithdrawalsRelayed marks L2 withdrawals as relayed, used when they are found executed on L1
+// without their relay tx being indexed. Terminal statuses are not over-written.
+func (c *CrossMessage) UpdateL2WithdrawalsRelayed(ctx context.Context, messageHashes []string) (int64, error) {
+	if len(messageHashes) == 0 {
+		return 0, nil
+	}
+	var rowsAffected int64
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&CrossMessage{})
+		db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+		db = db.Where("message_hash IN (?)", messageHashes)
+		db = db.Where("tx_status NOT IN (?)", []TxStatusType{TxStatusTypeRelayed, TxStatusTypeDropped, TxStatusTypeDroppedRefunded})
+		result := db.Update("tx_status", TxStatusTypeRelayed)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		return deleteUnclaimableWithdrawals(tx, messageHashes)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update L2 withdrawals relayed, message hashes: %v, error: %w", messageHashes, err)
+	}
+	return rowsAffected, nil
+}
+
 // GetL2WithdrawalsByAddress retrieves all L2 claimable withdrawal messages for a given sender address.
 func (c *CrossMessage) GetL2WithdrawalsByAddress(ctx context.Context, sender string) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var messages []*CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -240,6 +462,9 @@ func (c *CrossMessage) GetL2WithdrawalsByAddress(ctx context.Context, sender str
 
 // GetTxsByAddress retrieves all txs for a given sender address.
 func (c *CrossMessage) GetTxsByAddress(ctx context.Context, sender string) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
 	var messages []*CrossMessage
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
@@ -252,81 +477,318 @@ func (c *CrossMessage) GetTxsByAddress(ctx context.Context, sender string) ([]*C
 	return messages, nil
 }
 
-// UpdateL1MessageQueueEventsInfo updates the information about L1 message queue events in the database.
-func (c *CrossMessage) UpdateL1MessageQueueEventsInfo(ctx context.Context, l1MessageQueueEvents []*MessageQueueEvent) error {
-	// update tx statuses.
-	for _, l1MessageQueueEvent := range l1MessageQueueEvents {
-		db := c.db
-		db = db.WithContext(ctx)
-		db = db.Model(&CrossMessage{})
-		txStatusUpdateFields := make(map[string]interface{})
-		switch l1MessageQueueEvent.EventType {
-		case MessageQueueEventTypeQueueTransaction:
-			continue
-		case MessageQueueEventTypeDequeueTransaction:
-			// do not over-write terminal statuses.
-			db = db.Where("tx_status != ?", TxStatusTypeRelayed)
-			db = db.Where("tx_status != ?", TxStatusTypeDropped)
-			db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
-			db = db.Where("message_type = ?", MessageTypeL1SentMessage)
-			txStatusUpdateFields["tx_status"] = TxStatusTypeSkipped
-		case MessageQueueEventTypeDropTransaction:
-			// do not over-write terminal statuses.
-			db = db.Where("tx_status != ?", TxStatusTypeRelayed)
-			db = db.Where("tx_status != ?", TxStatusTypeDropped)
-			db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
-			db = db.Where("message_type = ?", MessageTypeL1SentMessage)
-			txStatusUpdateFields["tx_status"] = TxStatusTypeDropped
+// GetTxsByAddresses returns the latest txs of each of the given sender addresses in a single query, at most limit txs per sender.
+func (c *CrossMessage) GetTxsByAddresses(ctx context.Context, senders []string, limit uint64) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var messages []*CrossMessage
+	subQuery := c.db.WithContext(ctx).Model(&CrossMessage{})
+	subQuery = subQuery.Select("*, ROW_NUMBER() OVER (PARTITION BY sender ORDER BY block_timestamp DESC) AS sender_row_number")
+	subQuery = subQuery.Where("sender IN ?", senders)
+
+	db := c.db.WithContext(ctx)
+	db = db.Table("(?) AS sender_txs", subQuery)
+	db = db.Where("sender_row_number <= ?", limit)
+	db = db.Order("sender, block_timestamp desc")
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get txs by sender addresses, senders: %v, error: %w", senders, err)
+	}
+	return messages, nil
+}
+
+// GetTxCountsByAddresses returns the number of txs of each of the given sender addresses, senders without txs are left out.
+func (c *CrossMessage) GetTxCountsByAddresses(ctx context.Context, senders []string) (map[string]uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var counts []struct {
+		Sender string `gorm:"column:sender"`
+		Count  uint64 `gorm:"column:count"`
+	}
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Select("sender, COUNT(*) AS count")
+	db = db.Where("sender IN ?", senders)
+	db = db.Group("sender")
+	if err := db.Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count txs by sender addresses, senders: %v, error: %w", senders, err)
+	}
+	countMap := make(map[string]uint64, len(counts))
+	for _, count := range counts {
+		countMap[count.Sender] = count.Count
+	}
+	return countMap, nil
+}
+
+// GetLatestUpdateByAddress returns the latest updated_at of the txs of a sender, zero if it has none, and the number
+// of its txs, which changes when a tx is deleted. Together they change whenever the txs of the sender change, the
+// upserts of the messages assign updated_at for this.
+func (c *CrossMessage) GetLatestUpdateByAddress(ctx context.Context, sender string) (time.Time, uint64, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var latest struct {
+		MaxUpdatedAt *time.Time `gorm:"column:max_updated_at"`
+		Count        uint64     `gorm:"column:count"`
+	}
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Select("MAX(updated_at) AS max_updated_at, COUNT(*) AS count")
+	db = db.Where("sender = ?", sender)
+	if err := db.Scan(&latest).Error; err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to get latest update by sender address, sender: %v, error: %w", sender, err)
+	}
+	if latest.MaxUpdatedAt == nil {
+		return time.Time{}, latest.Count, nil
+	}
+	return *latest.MaxUpdatedAt, latest.Count, nil
+}
+
+// GetTxsByAddressAndTokenAmountRange returns the txs of a sender transferring a token whose token amount is within
+// [minAmount, maxAmount], a nil bound leaves its side of the range open. The token is given by its L1 or L2 address,
+// the zero address selects eth. Messages without a numeric token amount are excluded.
+func (c *CrossMessage) GetTxsByAddressAndTokenAmountRange(ctx context.Context, sender, tokenAddress string, minAmount, maxAmount *big.Int) ([]*CrossMessage, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("sender = ?", sender)
+	db = whereToken(db, tokenAddress)
+	db = db.Where("token_amounts_numeric IS NOT NULL")
+	if minAmount != nil {
+		db = db.Where("token_amounts_numeric >= ?", NewBigInt(minAmount))
+	}
+	if maxAmount != nil {
+		db = db.Where("token_amounts_numeric <= ?", NewBigInt(maxAmount))
+	}
+	db = db.Order("block_timestamp desc")
+	db = db.Limit(500)
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get txs by sender address and token amount range, sender: %v, token: %v, min: %v, max: %v, error: %w", sender, tokenAddress, minAmount, maxAmount, err)
+	}
+	return messages, nil
+}
+
+// SumTokenAmountsByAddress returns the total token amounts sent by a sender, grouped by message type and token.
+// An empty tokenAddress sums all tokens, otherwise only the token of the L1 or L2 address, the zero address selects eth.
+// Txs reverted on the source chain moved no funds and are excluded.
+func (c *CrossMessage) SumTokenAmountsByAddress(ctx context.Context, sender, tokenAddress string) ([]*TokenAmountSum, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var sums []*TokenAmountSum
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Select("message_type, token_type, l1_token_address, SUM(token_amounts_numeric) AS total_amount, COUNT(*) AS tx_count")
+	db = db.Where("sender = ?", sender)
+	if tokenAddress != "" {
+		db = whereToken(db, tokenAddress)
+	}
+	db = db.Where("token_amounts_numeric IS NOT NULL")
+	db = db.Where("tx_status <> ?", TxStatusTypeSentTxReverted)
+	db = db.Group("message_type, token_type, l1_token_address")
+	db = db.Order("message_type, token_type, l1_token_address")
+	if err := db.Scan(&sums).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum token amounts by sender address, sender: %v, token: %v, error: %w", sender, tokenAddress, err)
+	}
+	return sums, nil
+}
+
+// whereToken filters the messages transferring a token, given by its L1 or L2 address, the zero address selects eth.
+func whereToken(db *gorm.DB, tokenAddress string) *gorm.DB {
+	if tokenAddress == (common.Address{}).String() {
+		return db.Where("token_type = ?", TokenTypeETH)
+	}
+	return db.Where("(l1_token_address = ? OR l2_token_address = ?)", tokenAddress, tokenAddress)
+}
+
+// GetRecentDepositRelayLatency returns the median latency between the blocks of the deposit tx and of its relay on L2,
+// over the latest relayed deposits.
+func (c *CrossMessage) GetRecentDepositRelayLatency(ctx context.Context, sampleSize int) (*LatencyStat, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	samples := c.db.WithContext(ctx).Model(&CrossMessage{})
+	samples = samples.Select("l2_relay_block_timestamp - block_timestamp AS latency")
+	samples = samples.Where("message_type = ?", MessageTypeL1SentMessage)
+	samples = samples.Where("tx_status = ?", TxStatusTypeRelayed)
+	// relays indexed before their block timestamp was stored, and relays indexed before their deposit, are not sampled.
+	samples = samples.Where("l2_relay_block_timestamp > 0")
+	samples = samples.Where("block_timestamp > 0")
+	samples = samples.Order("l2_block_number desc")
+	samples = samples.Limit(sampleSize)
+
+	stat, err := c.getLatencyStat(ctx, samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent deposit relay latency, error: %w", err)
+	}
+	return stat, nil
+}
+
+// GetRecentWithdrawalFinalizeLatency returns the median latency between the block of the withdrawal tx and the block
+// finalizing its batch on L1, over the latest finalized withdrawals.
+func (c *CrossMessage) GetRecentWithdrawalFinalizeLatency(ctx context.Context, sampleSize int) (*LatencyStat, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	samples := c.db.WithContext(ctx).Model(&CrossMessage{})
+	samples = samples.Select("batch_event_v2.finalize_block_timestamp - cross_message_v2.block_timestamp AS latency")
+	// batches finalized before their block timestamp was stored are not sampled.
+	samples = samples.Joins("JOIN batch_event_v2 ON batch_event_v2.batch_index = cross_message_v2.batch_index AND batch_event_v2.batch_status = ? AND batch_event_v2.finalize_block_timestamp > 0 AND batch_event_v2.deleted_at IS NULL", BatchStatusTypeFinalized)
+	samples = samples.Where("cross_message_v2.message_type = ?", MessageTypeL2SentMessage)
+	samples = samples.Where("cross_message_v2.rollup_status = ?", RollupStatusTypeFinalized)
+	samples = samples.Order("cross_message_v2.l2_block_number desc")
+	samples = samples.Limit(sampleSize)
+
+	stat, err := c.getLatencyStat(ctx, samples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent withdrawal finalize latency, error: %w", err)
+	}
+	return stat, nil
+}
+
+func (c *CrossMessage) getLatencyStat(ctx context.Context, samples *gorm.DB) (*LatencyStat, error) {
+	var stat LatencyStat
+	db := c.db.WithContext(ctx)
+	db = db.Table("(?) AS samples", samples)
+	db = db.Select("COUNT(*) AS sample_count, COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency), 0) AS median_sec")
+	// block timestamps of two chains are not strictly ordered, which could produce negative samples.
+	db = db.Where("latency >= 0")
+	if err := db.Scan(&stat).Error; err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+// UpdateL1MessageQueueEventsInfo updates the information about the L1 message queue events of the blocks from fromBlock
+// on in the database. The statuses set by the skip and drop events previously saved for these blocks are rolled back
+// first, so that re-fetching the blocks after a reorg does not leave the statuses of events reorged out behind.
+func (c *CrossMessage) UpdateL1MessageQueueEventsInfo(ctx context.Context, fromBlock uint64, l1MessageQueueEvents []*MessageQueueEvent) error {
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := rollbackMessageQueueEffects(tx, fromBlock); err != nil {
+			return fmt.Errorf("failed to roll back the statuses set by L1 message queue events, error: %w", err)
 		}
-		if err := db.Updates(txStatusUpdateFields).Error; err != nil {
-			return fmt.Errorf("failed to update tx statuses of L1 message queue events, update fields: %v, error: %w", txStatusUpdateFields, err)
+
+		// update tx statuses.
+		var latestBlock uint64
+		for _, l1MessageQueueEvent := range l1MessageQueueEvents {
+			latestBlock = max(latestBlock, l1MessageQueueEvent.L1BlockNumber)
+			var txStatus TxStatusType
+			switch l1MessageQueueEvent.EventType {
+			case MessageQueueEventTypeDequeueTransaction:
+				txStatus = TxStatusTypeSkipped
+			case MessageQueueEventTypeDropTransaction:
+				txStatus = TxStatusTypeDropped
+				if l1MessageQueueEvent.Refunded {
+					txStatus = TxStatusTypeDroppedRefunded
+				}
+			default:
+				continue
+			}
+			if err := updateTxStatusOfMessageQueueEvent(tx, l1MessageQueueEvent, txStatus); err != nil {
+				return fmt.Errorf("failed to update tx status of L1 message queue event, queue index: %v, tx status: %v, error: %w", l1MessageQueueEvent.QueueIndex, txStatus, err)
+			}
 		}
-	}
 
-	// update tx hashes of replay and refund.
-	for _, l1MessageQueueEvent := range l1MessageQueueEvents {
-		db := c.db
-		db = db.WithContext(ctx)
-		db = db.Model(&CrossMessage{})
-		txHashUpdateFields := make(map[string]interface{})
-		switch l1MessageQueueEvent.EventType {
-		case MessageQueueEventTypeDequeueTransaction:
-			continue
-		case MessageQueueEventTypeQueueTransaction:
-			// only replayMessages or enforced txs (whose message hashes would not be found), sendMessages have been filtered out.
-			// replayMessage case:
-			// First SentMessage in L1: https://sepolia.etherscan.io/tx/0xbee4b631312448fcc2caac86e4dccf0a2ae0a88acd6c5fd8764d39d746e472eb
-			// Transaction reverted in L2: https://sepolia.scrollscan.com/tx/0xde6ef307a7da255888aad7a4c40a6b8c886e46a8a05883070bbf18b736cbfb8c
-			// replayMessage: https://sepolia.etherscan.io/tx/0xa5392891232bb32d98fcdbaca0d91b4d22ef2755380d07d982eebd47b147ce28
-			//
-			// Note: update l1_tx_hash if the user calls replayMessage, cannot use queue index here,
-			// because in replayMessage, queue index != message nonce.
-			// Ref: https://github.com/scroll-tech/scroll/blob/v4.3.44/contracts/src/L1/L1ScrollMessenger.sol#L187-L190
-			db = db.Where("message_hash = ?", l1MessageQueueEvent.MessageHash.String())
-			txHashUpdateFields["l1_replay_tx_hash"] = l1MessageQueueEvent.TxHash.String()
-		case MessageQueueEventTypeDropTransaction:
-			db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
-			db = db.Where("message_type = ?", MessageTypeL1SentMessage)
-			txHashUpdateFields["l1_refund_tx_hash"] = l1MessageQueueEvent.TxHash.String()
+		// update tx hashes of replay and refund.
+		for _, l1MessageQueueEvent := range l1MessageQueueEvents {
+			db := tx.Model(&CrossMessage{})
+			txHashUpdateFields := make(map[string]interface{})
+			switch l1MessageQueueEvent.EventType {
+			case MessageQueueEventTypeDequeueTransaction:
+				continue
+			case MessageQueueEventTypeQueueTransaction:
+				// only replayMessages or enforced txs (whose message hashes would not be found), sendMessages have been filtered out.
+				// replayMessage case:
+				// First SentMessage in L1: https://sepolia.etherscan.io/tx/0xbee4b631312448fcc2caac86e4dccf0a2ae0a88acd6c5fd8764d39d746e472eb
+				// Transaction reverted in L2: https://sepolia.scrollscan.com/tx/0xde6ef307a7da255888aad7a4c40a6b8c886e46a8a05883070bbf18b736cbfb8c
+				// replayMessage: https://sepolia.etherscan.io/tx/0xa5392891232bb32d98fcdbaca0d91b4d22ef2755380d07d982eebd47b147ce28
+				//
+				// Note: update l1_tx_hash if the user calls replayMessage, cannot use queue index here,
+				// because in replayMessage, queue index != message nonce.
+				// Ref: https://github.com/scroll-tech/scroll/blob/v4.3.44/contracts/src/L1/L1ScrollMessenger.sol#L187-L190
+				db = db.Where("message_hash = ?", l1MessageQueueEvent.MessageHash.String())
+				db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+				txHashUpdateFields["l1_replay_tx_hash"] = l1MessageQueueEvent.TxHash.String()
+			case MessageQueueEventTypeDropTransaction:
+				db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
+				db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+				txHashUpdateFields["l1_refund_tx_hash"] = l1MessageQueueEvent.TxHash.String()
+			}
+			if err := db.Updates(txHashUpdateFields).Error; err != nil {
+				return fmt.Errorf("failed to update tx hashes of replay and refund in L1 message queue events info, update fields: %v, error: %w", txHashUpdateFields, err)
+			}
 		}
-		if err := db.Updates(txHashUpdateFields).Error; err != nil {
-			return fmt.Errorf("failed to update tx hashes of replay and refund in L1 message queue events info, update fields: %v, error: %w", txHashUpdateFields, err)
+
+		if err := pruneMessageQueueEffects(tx, latestBlock); err != nil {
+			return fmt.Errorf("failed to prune the statuses set by L1 message queue events, error: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update L1 message queue events info, from block: %v, error: %w", fromBlock, err)
 	}
 	return nil
 }
 
+// updateTxStatusOfMessageQueueEvent sets the tx status of the message skipped or dropped by a message queue event,
+// recording the status it replaces. Terminal statuses are not over-written.
+func updateTxStatusOfMessageQueueEvent(tx *gorm.DB, l1MessageQueueEvent *MessageQueueEvent, txStatus TxStatusType) error {
+	var messages []*CrossMessage
+	db := tx.Model(&CrossMessage{})
+	db = db.Select("tx_status, l1_refund_tx_hash")
+	db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
+	db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+	db = db.Where("tx_status NOT IN (?)", []TxStatusType{TxStatusTypeRelayed, TxStatusTypeDropped, TxStatusTypeDroppedRefunded})
+	if err := db.Limit(1).Find(&messages).Error; err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	effect := &MessageQueueEffect{
+		QueueIndex:    l1MessageQueueEvent.QueueIndex,
+		EventType:     l1MessageQueueEvent.EventType,
+		L1BlockNumber: l1MessageQueueEvent.L1BlockNumber,
+		TxStatus:      txStatus,
+		PriorTxStatus: TxStatusType(messages[0].TxStatus),
+	}
+	if messages[0].L1RefundTxHash != "" {
+		effect.PriorL1RefundTxHash = &messages[0].L1RefundTxHash
+	}
+	if err := tx.Create(effect).Error; err != nil {
+		return err
+	}
+
+	db = tx.Model(&CrossMessage{})
+	db = db.Where("message_nonce = ?", l1MessageQueueEvent.QueueIndex)
+	db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+	db = db.Where("tx_status NOT IN (?)", []TxStatusType{TxStatusTypeRelayed, TxStatusTypeDropped, TxStatusTypeDroppedRefunded})
+	return db.Update("tx_status", txStatus).Error
+}
+
 // UpdateBatchStatusOfL2Withdrawals updates batch status of L2 withdrawals.
 func (c *CrossMessage) UpdateBatchStatusOfL2Withdrawals(ctx context.Context, startBlockNumber, endBlockNumber, batchIndex uint64) error {
-	db := c.db.WithContext(ctx)
-	db = db.Model(&CrossMessage{})
-	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
-	db = db.Where("l2_block_number >= ?", startBlockNumber)
-	db = db.Where("l2_block_number <= ?", endBlockNumber)
 	updateFields := make(map[string]interface{})
 	updateFields["batch_index"] = batchIndex
 	updateFields["rollup_status"] = RollupStatusTypeFinalized
-	if err := db.Updates(updateFields).Error; err != nil {
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&CrossMessage{})
+		db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+		db = db.Where("l2_block_number >= ?", startBlockNumber)
+		db = db.Where("l2_block_number <= ?", endBlockNumber)
+		if err := db.Updates(updateFields).Error; err != nil {
+			return err
+		}
+		return insertClaimableWithdrawals(tx, func(db *gorm.DB) *gorm.DB {
+			return db.Where("l2_block_number >= ? AND l2_block_number <= ?", startBlockNumber, endBlockNumber)
+		})
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update batch status of L2 sent messages, start: %v, end: %v, index: %v, error: %w", startBlockNumber, endBlockNumber, batchIndex, err)
 	}
 	return nil
@@ -337,20 +799,27 @@ func (c *CrossMessage) UpdateBatchIndexRollupStatusMerkleProofOfL2Messages(ctx c
 	if len(messages) == 0 {
 		return nil
 	}
-	for _, message := range messages {
-		updateFields := map[string]interface{}{
-			"batch_index":   message.BatchIndex,
-			"rollup_status": message.RollupStatus,
-			"merkle_proof":  message.MerkleProof,
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		messageHashes := make([]string, len(messages))
+		for i, message := range messages {
+			updateFields := map[string]interface{}{
+				"batch_index":   message.BatchIndex,
+				"rollup_status": message.RollupStatus,
+				"merkle_proof":  message.MerkleProof,
+			}
+			db := tx.Model(&CrossMessage{})
+			db = db.Where("message_hash = ?", message.MessageHash)
+			db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+			if err := db.Updates(updateFields).Error; err != nil {
+				return fmt.Errorf("failed to update L2 message with message_hash %s, error: %w", message.MessageHash, err)
+			}
+			messageHashes[i] = message.MessageHash
 		}
-		db := c.db.WithContext(ctx)
-		db = db.Model(&CrossMessage{})
-		db = db.Where("message_hash = ?", message.MessageHash)
-		if err := db.Updates(updateFields).Error; err != nil {
-			return fmt.Errorf("failed to update L2 message with message_hash %s, error: %w", message.MessageHash, err)
+		if err := insertClaimableWithdrawals(tx, byMessageHashes(messageHashes)); err != nil {
+			return err
 		}
-	}
-	return nil
+		return deleteUnclaimableWithdrawals(tx, messageHashes)
+	})
 }
 
 // InsertOrUpdateL1Messages inserts or updates a list of L1 cross messages into the database.
@@ -358,15 +827,17 @@ func (c *CrossMessage) InsertOrUpdateL1Messages(ctx context.Context, messages []
 	if len(messages) == 0 {
 		return nil
 	}
+	setNumericAmounts(messages)
 	db := c.db
 	db = db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
 	// 'tx_status' column is not explicitly assigned during the update to prevent a later status from being overwritten back to "sent".
 	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
-		DoUpdates: clause.AssignmentColumns([]string{"sender", "receiver", "token_type", "l1_block_number", "l1_tx_hash", "l1_token_address", "l2_token_address", "token_ids", "token_amounts", "message_type", "block_timestamp", "message_nonce"}),
+		Columns:   []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sender", "receiver", "token_type", "l1_block_number", "l1_tx_hash", "l1_token_address", "l2_token_address", "token_ids", "token_amounts", "message_type", "block_timestamp", "message_from", "message_nonce", "l1_tx_gas_used", "l1_tx_effective_gas_price", "token_amounts_numeric", "deposit_call_selector", "deposit_call_data", "updated_at"}),
 	})
-	if err := db.Create(messages).Error; err != nil {
+	// The L2 fetcher upserts the relayed status of the same deposits concurrently, retry on deadlocks.
+	if err := database.WithRetry(ctx, func() error { return db.Session(&gorm.Session{}).Create(messages).Error }); err != nil {
 		return fmt.Errorf("failed to insert message, error: %w", err)
 	}
 	return nil
@@ -377,15 +848,22 @@ func (c *CrossMessage) InsertOrUpdateL2Messages(ctx context.Context, messages []
 	if len(messages) == 0 {
 		return nil
 	}
-	db := c.db
-	db = db.WithContext(ctx)
-	db = db.Model(&CrossMessage{})
+	setNumericAmounts(messages)
+	messages, messageData := offloadMessageData(messages, c.messageDataOffloadThreshold)
 	// 'tx_status' column is not explicitly assigned during the update to prevent a later status from being overwritten back to "sent".
-	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
-		DoUpdates: clause.AssignmentColumns([]string{"sender", "receiver", "token_type", "l2_block_number", "l2_tx_hash", "l1_token_address", "l2_token_address", "token_ids", "token_amounts", "message_type", "block_timestamp", "message_from", "message_to", "message_value", "message_data", "message_nonce"}),
+	onConflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sender", "receiver", "token_type", "l2_block_number", "l2_tx_hash", "l1_token_address", "l2_token_address", "token_ids", "token_amounts", "message_type", "block_timestamp", "message_from", "message_to", "message_value", "message_data", "message_data_offloaded", "message_nonce", "message_value_numeric", "token_amounts_numeric", "updated_at"}),
+	}
+	// The offloaded message data is written in the same transaction, so an offloaded message always has its data.
+	// The L1 fetcher upserts the relayed status of the same withdrawals concurrently, retry on deadlocks.
+	err := database.TransactionWithRetry(ctx, c.db, func(tx *gorm.DB) error {
+		if err := insertMessageData(tx, messageData); err != nil {
+			return err
+		}
+		return tx.Model(&CrossMessage{}).Clauses(onConflict).Create(messages).Error
 	})
-	if err := db.Create(messages).Error; err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to insert message, error: %w", err)
 	}
 	return nil
@@ -402,12 +880,13 @@ func (c *CrossMessage) InsertFailedL2GatewayTxs(ctx context.Context, messages []
 	for _, message := range messages {
 		message.MessageHash = message.L2TxHash
 	}
+	setNumericAmounts(messages)
 
 	db := c.db
 	db = db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
 	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
+		Columns:   []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
 		DoNothing: true,
 	})
 
@@ -428,12 +907,13 @@ func (c *CrossMessage) InsertFailedL1GatewayTxs(ctx context.Context, messages []
 	for _, message := range messages {
 		message.MessageHash = message.L1TxHash
 	}
+	setNumericAmounts(messages)
 
 	db := c.db
 	db = db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
 	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
+		Columns:   []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
 		DoNothing: true,
 	})
 
@@ -448,7 +928,7 @@ func (c *CrossMessage) InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx context.C
 	if len(l2RelayedMessages) == 0 {
 		return nil
 	}
-	// Deduplicate messages, for each message_hash, retaining message with the highest block number.
+	// Deduplicate messages, for each message_hash, retaining the relay which takes precedence, see mergeRelayedMessages.
 	// This is necessary as a single message, like a FailedRelayedMessage or a reverted relayed transaction,
 	// may be relayed multiple times within certain block ranges, potentially leading to the error:
 	// "ERROR: ON CONFLICT DO UPDATE command cannot affect row a second time (SQLSTATE 21000)".
@@ -459,20 +939,7 @@ func (c *CrossMessage) InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx context.C
 	// Another example:
 	// FailedRelayedMessage 1: https://sepolia.scrollscan.com/tx/0xfadb147fb211e5096446c5cac3ae0a8a705d2ece6c47c65135c8874f84638f17
 	// FailedRelayedMessage 2: https://sepolia.scrollscan.com/tx/0x6cb149b61afd07bf2e17561a59ebebde41e343b6610290c97515b2f862160b42
-	mergedL2RelayedMessages := make(map[string]*CrossMessage)
-	for _, message := range l2RelayedMessages {
-		if existing, found := mergedL2RelayedMessages[message.MessageHash]; found {
-			if TxStatusType(message.TxStatus) == TxStatusTypeRelayed || message.L2BlockNumber > existing.L2BlockNumber {
-				mergedL2RelayedMessages[message.MessageHash] = message
-			}
-		} else {
-			mergedL2RelayedMessages[message.MessageHash] = message
-		}
-	}
-	uniqueL2RelayedMessages := make([]*CrossMessage, 0, len(mergedL2RelayedMessages))
-	for _, msg := range mergedL2RelayedMessages {
-		uniqueL2RelayedMessages = append(uniqueL2RelayedMessages, msg)
-	}
+	uniqueL2RelayedMessages := mergeRelayedMessages(l2RelayedMessages, func(message *CrossMessage) uint64 { return message.L2BlockNumber })
 	// Do not update tx status of successfully relayed messages,
 	// because if a message is handled, the later relayed message tx would be reverted.
 	// ref: https://github.com/scroll-tech/scroll/blob/v4.3.44/contracts/src/L2/L2ScrollMessenger.sol#L102
@@ -483,19 +950,26 @@ func (c *CrossMessage) InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx context.C
 	db := c.db.WithContext(ctx)
 	db = db.Model(&CrossMessage{})
 	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
-		DoUpdates: clause.AssignmentColumns([]string{"message_type", "l2_block_number", "l2_tx_hash", "tx_status"}),
+		Columns: []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
+		// keep the stored failure reason when a relay could not be traced, e.g. a re-fetched range while the node is unavailable.
+		DoUpdates: append(clause.AssignmentColumns([]string{"message_type", "l2_block_number", "l2_tx_hash", "tx_status", "l2_relay_block_timestamp", "updated_at"}),
+			clause.Assignment{Column: clause.Column{Name: "l2_relay_failure_selector"}, Value: gorm.Expr("COALESCE(NULLIF(excluded.l2_relay_failure_selector, ''), cross_message_v2.l2_relay_failure_selector)")},
+			clause.Assignment{Column: clause.Column{Name: "l2_relay_failure_reason"}, Value: gorm.Expr("COALESCE(NULLIF(excluded.l2_relay_failure_reason, ''), cross_message_v2.l2_relay_failure_reason)")},
+		),
 		Where: clause.Where{
 			Exprs: []clause.Expression{
 				clause.And(
 					// do not over-write terminal statuses.
 					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeRelayed},
 					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeDropped},
+					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeDroppedRefunded},
+					// do not over-write a failed relay with one of an older block, e.g. of a re-fetched block range.
+					gorm.Expr("(excluded.tx_status = ? OR excluded.l2_block_number >= cross_message_v2.l2_block_number)", TxStatusTypeRelayed),
 				),
 			},
 		},
 	})
-	if err := db.Create(uniqueL2RelayedMessages).Error; err != nil {
+	if err := database.WithRetry(ctx, func() error { return db.Session(&gorm.Session{}).Create(uniqueL2RelayedMessages).Error }); err != nil {
 		return fmt.Errorf("failed to update L2 reverted relayed message of L1 deposit, error: %w", err)
 	}
 	return nil
@@ -506,7 +980,7 @@ func (c *CrossMessage) InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx contex
 	if len(l1RelayedMessages) == 0 {
 		return nil
 	}
-	// Deduplicate messages, for each message_hash, retaining message with the highest block number.
+	// Deduplicate messages, for each message_hash, retaining the relay which takes precedence, see mergeRelayedMessages.
 	// This is necessary as a single message, like a FailedRelayedMessage or a reverted relayed transaction,
 	// may be relayed multiple times within certain block ranges, potentially leading to the error:
 	// "ERROR: ON CONFLICT DO UPDATE command cannot affect row a second time (SQLSTATE 21000)".
@@ -517,38 +991,68 @@ func (c *CrossMessage) InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx contex
 	// Another example (relayed success, then relayed again):
 	// Relay Message, and success: https://sepolia.etherscan.io/tx/0xcfdf2f5446719e3e123a8aa06e4d6b3809c3850a13adf875755c8b1e423aa448#eventlog
 	// Relay Message again, and reverted: https://sepolia.etherscan.io/tx/0xb1fcae7546f3de4cfd0b4d679f4075adb4eb69578b12e2b5673f5f24b1836578
-	mergedL1RelayedMessages := make(map[string]*CrossMessage)
-	for _, message := range l1RelayedMessages {
-		if existing, found := mergedL1RelayedMessages[message.MessageHash]; found {
-			if TxStatusType(message.TxStatus) == TxStatusTypeRelayed || message.L1BlockNumber > existing.L1BlockNumber {
-				mergedL1RelayedMessages[message.MessageHash] = message
-			}
-		} else {
-			mergedL1RelayedMessages[message.MessageHash] = message
-		}
-	}
-	uniqueL1RelayedMessages := make([]*CrossMessage, 0, len(mergedL1RelayedMessages))
-	for _, msg := range mergedL1RelayedMessages {
-		uniqueL1RelayedMessages = append(uniqueL1RelayedMessages, msg)
+	uniqueL1RelayedMessages := mergeRelayedMessages(l1RelayedMessages, func(message *CrossMessage) uint64 { return message.L1BlockNumber })
+	messageHashes := make([]string, 0, len(uniqueL1RelayedMessages))
+	for _, msg := range uniqueL1RelayedMessages {
+		messageHashes = append(messageHashes, msg.MessageHash)
 	}
-	db := c.db
-	db = db.WithContext(ctx)
-	db = db.Model(&CrossMessage{})
-	db = db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "message_hash"}},
-		DoUpdates: clause.AssignmentColumns([]string{"message_type", "l1_block_number", "l1_tx_hash", "tx_status"}),
+	onConflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_hash"}, {Name: "message_type"}, {Name: "message_nonce"}},
+		DoUpdates: clause.AssignmentColumns([]string{"message_type", "l1_block_number", "l1_tx_hash", "tx_status", "l1_tx_gas_used", "l1_tx_effective_gas_price", "claimed_by", "l1_relay_block_timestamp", "updated_at"}),
 		Where: clause.Where{
 			Exprs: []clause.Expression{
 				clause.And(
 					// do not over-write terminal statuses.
 					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeRelayed},
 					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeDropped},
+					clause.Neq{Column: "cross_message_v2.tx_status", Value: TxStatusTypeDroppedRefunded},
+					// do not over-write a failed relay with one of an older block, e.g. of a re-fetched block range.
+					gorm.Expr("(excluded.tx_status = ? OR excluded.l1_block_number >= cross_message_v2.l1_block_number)", TxStatusTypeRelayed),
 				),
 			},
 		},
+	}
+	// Relayed or dropped withdrawals leave claimable_withdrawal in the same transaction, failed relays stay claimable.
+	err := database.WithRetry(ctx, func() error {
+		return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&CrossMessage{}).Clauses(onConflict).Create(uniqueL1RelayedMessages).Error; err != nil {
+				return err
+			}
+			return deleteUnclaimableWithdrawals(tx, messageHashes)
+		})
 	})
-	if err := db.Create(uniqueL1RelayedMessages).Error; err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to update L1 relayed message of L2 withdrawal, error: %w", err)
 	}
 	return nil
 }
+
+// mergeRelayedMessages deduplicates the relays of the same message, keeping the one which takes precedence:
+// a successful relay beats a failed one, otherwise the relay of the newer block wins, and within the same block
+// the later one in the given order, which is the order of the events.
+// The order of the first relay of each message is kept, so that the batches written to the db are deterministic.
+func mergeRelayedMessages(messages []*CrossMessage, blockNumber func(*CrossMessage) uint64) []*CrossMessage {
+	merged := make([]*CrossMessage, 0, len(messages))
+	indexes := make(map[string]int, len(messages))
+	for _, message := range messages {
+		i, found := indexes[message.MessageHash]
+		if !found {
+			indexes[message.MessageHash] = len(merged)
+			merged = append(merged, message)
+			continue
+		}
+		existing := merged[i]
+		messageRelayed := TxStatusType(message.TxStatus) == TxStatusTypeRelayed
+		existingRelayed := TxStatusType(existing.TxStatus) == TxStatusTypeRelayed
+		if messageRelayed != existingRelayed {
+			if messageRelayed {
+				merged[i] = message
+			}
+			continue
+		}
+		if blockNumber(message) >= blockNumber(existing) {
+			merged[i] = message
+		}
+	}
+	return merged
+}
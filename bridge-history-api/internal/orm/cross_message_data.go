@@ -0,0 +1,153 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
+)
+
+// CrossMessageData is the message data of a cross message offloaded from cross_message_v2, e.g. the calldata of a
+// contract call, which can be tens of KB and is rarely read.
+type CrossMessageData struct {
+	MessageHash string    `json:"message_hash" gorm:"column:message_hash;primary_key"`
+	MessageData string    `json:"message_data" gorm:"column:message_data"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the CrossMessageData model.
+func (*CrossMessageData) TableName() string {
+	return "cross_message_data"
+}
+
+// SetMessageDataOffloadThreshold offloads the message data longer than threshold bytes of the messages inserted
+// afterwards to cross_message_data, 0 keeps all of them inline.
+func (c *CrossMessage) SetMessageDataOffloadThreshold(threshold int) {
+	c.messageDataOffloadThreshold = threshold
+}
+
+// messageDataOffloadLength returns the length of the longest hex encoded message data which is kept inline.
+func messageDataOffloadLength(threshold int) int {
+	return len("0x") + 2*threshold
+}
+
+// offloadMessageData returns the messages to insert with their message data longer than threshold bytes offloaded,
+// and the offloaded message data. The offloaded messages are copied, the given ones are left as they are.
+func offloadMessageData(messages []*CrossMessage, threshold int) ([]*CrossMessage, []*CrossMessageData) {
+	if threshold <= 0 {
+		return messages, nil
+	}
+	var data []*CrossMessageData
+	result := make([]*CrossMessage, len(messages))
+	for i, message := range messages {
+		result[i] = message
+		if len(message.MessageData) <= messageDataOffloadLength(threshold) {
+			continue
+		}
+		data = append(data, &CrossMessageData{MessageHash: message.MessageHash, MessageData: message.MessageData})
+		offloaded := *message
+		offloaded.MessageData = ""
+		offloaded.MessageDataOffloaded = true
+		result[i] = &offloaded
+	}
+	return result, data
+}
+
+// insertMessageData inserts or updates the offloaded message data in the transaction.
+func insertMessageData(tx *gorm.DB, data []*CrossMessageData) error {
+	if len(data) == 0 {
+		return nil
+	}
+	db := tx.Model(&CrossMessageData{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"message_data", "updated_at"}),
+	})
+	return db.Create(data).Error
+}
+
+// LoadMessageData loads the offloaded message data of the messages, the message data of the others is inline.
+func (c *CrossMessage) LoadMessageData(ctx context.Context, messages []*CrossMessage) error {
+	offloaded := make(map[string][]*CrossMessage)
+	var messageHashes []string
+	for _, message := range messages {
+		if !message.MessageDataOffloaded {
+			continue
+		}
+		if _, ok := offloaded[message.MessageHash]; !ok {
+			messageHashes = append(messageHashes, message.MessageHash)
+		}
+		offloaded[message.MessageHash] = append(offloaded[message.MessageHash], message)
+	}
+	if len(messageHashes) == 0 {
+		return nil
+	}
+
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var data []*CrossMessageData
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessageData{})
+	db = db.Where("message_hash IN (?)", messageHashes)
+	if err := db.Find(&data).Error; err != nil {
+		return fmt.Errorf("failed to load offloaded message data, error: %w", err)
+	}
+	if len(data) != len(messageHashes) {
+		return fmt.Errorf("failed to load offloaded message data, %d of %d messages are missing", len(messageHashes)-len(data), len(messageHashes))
+	}
+	for _, d := range data {
+		for _, message := range offloaded[d.MessageHash] {
+			message.MessageData = d.MessageData
+		}
+	}
+	return nil
+}
+
+// OffloadMessageData moves the message data longer than threshold bytes of up to limit messages indexed before it
+// was set to cross_message_data, and returns the number of messages moved, 0 once all are.
+func (c *CrossMessage) OffloadMessageData(ctx context.Context, threshold, limit int) (int, error) {
+	var moved int
+	err := database.TransactionWithRetry(ctx, c.db, func(tx *gorm.DB) error {
+		var messages []*CrossMessage
+		db := tx.Model(&CrossMessage{})
+		db = db.Select("id, message_hash, message_data")
+		db = db.Where("NOT message_data_offloaded AND message_hash IS NOT NULL")
+		db = db.Where("length(message_data) > ?", messageDataOffloadLength(threshold))
+		db = db.Order("id")
+		db = db.Limit(limit)
+		if err := db.Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			moved = 0
+			return nil
+		}
+
+		ids := make([]uint64, 0, len(messages))
+		data := make([]*CrossMessageData, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+			data = append(data, &CrossMessageData{MessageHash: message.MessageHash, MessageData: message.MessageData})
+		}
+		if err := insertMessageData(tx, data); err != nil {
+			return err
+		}
+		db = tx.Model(&CrossMessage{})
+		db = db.Where("id IN (?)", ids)
+		if err := db.Updates(map[string]interface{}{"message_data": "", "message_data_offloaded": true}).Error; err != nil {
+			return err
+		}
+		moved = len(messages)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to offload message data, error: %w", err)
+	}
+	return moved, nil
+}
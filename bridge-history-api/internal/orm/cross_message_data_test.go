@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffloadMessageData(t *testing.T) {
+	short := &CrossMessage{MessageHash: "0x01", MessageData: "0x1234"}
+	long := &CrossMessage{MessageHash: "0x02", MessageData: "0x" + strings.Repeat("ab", 3)}
+
+	messages, data := offloadMessageData([]*CrossMessage{short, long}, 0)
+	assert.Equal(t, []*CrossMessage{short, long}, messages)
+	assert.Empty(t, data)
+
+	messages, data = offloadMessageData([]*CrossMessage{short, long}, 2)
+	assert.Len(t, messages, 2)
+	assert.Same(t, short, messages[0])
+	assert.Equal(t, "", messages[1].MessageData)
+	assert.True(t, messages[1].MessageDataOffloaded)
+	assert.Equal(t, []*CrossMessageData{{MessageHash: "0x02", MessageData: long.MessageData}}, data)
+	// the given messages are left as they are.
+	assert.Equal(t, "0xababab", long.MessageData)
+	assert.False(t, long.MessageDataOffloaded)
+}
+
+func TestMessageDataOffloadAndLoad(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+	longData := "0x" + strings.Repeat("ab", 64)
+
+	// indexed before the threshold is set.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, MessageData: longData},
+	}))
+	crossMessageOrm.SetMessageDataOffloadThreshold(32)
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x02", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 2, MessageData: longData},
+		{MessageHash: "0x03", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 3, MessageData: "0x1234"},
+	}))
+
+	message, err := crossMessageOrm.GetMessageByMessageHash(ctx, "0x02")
+	assert.NoError(t, err)
+	assert.True(t, message.MessageDataOffloaded)
+	assert.Equal(t, "", message.MessageData)
+	assert.NoError(t, crossMessageOrm.LoadMessageData(ctx, []*CrossMessage{message}))
+	assert.Equal(t, longData, message.MessageData)
+
+	message, err = crossMessageOrm.GetMessageByMessageHash(ctx, "0x03")
+	assert.NoError(t, err)
+	assert.False(t, message.MessageDataOffloaded)
+	assert.Equal(t, "0x1234", message.MessageData)
+
+	moved, err := crossMessageOrm.OffloadMessageData(ctx, 32, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	moved, err = crossMessageOrm.OffloadMessageData(ctx, 32, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, moved)
+
+	message, err = crossMessageOrm.GetMessageByMessageHash(ctx, "0x01")
+	assert.NoError(t, err)
+	assert.True(t, message.MessageDataOffloaded)
+	assert.NoError(t, crossMessageOrm.LoadMessageData(ctx, []*CrossMessage{message}))
+	assert.Equal(t, longData, message.MessageData)
+}
@@ -0,0 +1,540 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+func TestRelayFailureReasonNotOverwrittenByEmpty(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0xa", TxStatus: int(TxStatusTypeFailedRelayed),
+			L2RelayFailureSelector: "0x08c379a0", L2RelayFailureReason: "insufficient balance"},
+	}))
+
+	// the same relay fetched again while it can not be traced.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0xa", TxStatus: int(TxStatusTypeFailedRelayed)},
+	}))
+
+	var message CrossMessage
+	assert.NoError(t, db.Where("message_hash = ?", "0x01").First(&message).Error)
+	assert.Equal(t, "0x08c379a0", message.L2RelayFailureSelector)
+	assert.Equal(t, "insufficient balance", message.L2RelayFailureReason)
+
+	// a later failed relay with a decoded reason replaces it.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0xb", TxStatus: int(TxStatusTypeFailedRelayed),
+			L2RelayFailureSelector: "0x4e487b71", L2RelayFailureReason: "panic code 0x11"},
+	}))
+	assert.NoError(t, db.Where("message_hash = ?", "0x01").First(&message).Error)
+	assert.Equal(t, "0xb", message.L2TxHash)
+	assert.Equal(t, "0x4e487b71", message.L2RelayFailureSelector)
+	assert.Equal(t, "panic code 0x11", message.L2RelayFailureReason)
+}
+
+func TestMergeRelayedMessages(t *testing.T) {
+	l2BlockNumber := func(message *CrossMessage) uint64 { return message.L2BlockNumber }
+	failed := &CrossMessage{MessageHash: "0x01", L2BlockNumber: 2, L2TxHash: "0xa", TxStatus: int(TxStatusTypeFailedRelayed)}
+	relayed := &CrossMessage{MessageHash: "0x01", L2BlockNumber: 1, L2TxHash: "0xb", TxStatus: int(TxStatusTypeRelayed)}
+	reverted := &CrossMessage{MessageHash: "0x01", L2BlockNumber: 3, L2TxHash: "0xc", TxStatus: int(TxStatusTypeRelayTxReverted)}
+	other := &CrossMessage{MessageHash: "0x02", L2BlockNumber: 1, TxStatus: int(TxStatusTypeFailedRelayed)}
+
+	// a successful relay beats failed ones, whether they are in older or newer blocks.
+	assert.Equal(t, []*CrossMessage{relayed, other}, mergeRelayedMessages([]*CrossMessage{failed, other, relayed, reverted}, l2BlockNumber))
+	assert.Equal(t, []*CrossMessage{relayed}, mergeRelayedMessages([]*CrossMessage{relayed, reverted}, l2BlockNumber))
+
+	// among failed relays, the one of the newest block wins, within the same block the later one.
+	assert.Equal(t, []*CrossMessage{reverted}, mergeRelayedMessages([]*CrossMessage{reverted, failed}, l2BlockNumber))
+	sameBlock := &CrossMessage{MessageHash: "0x01", L2BlockNumber: 2, L2TxHash: "0xd", TxStatus: int(TxStatusTypeRelayTxReverted)}
+	assert.Equal(t, []*CrossMessage{sameBlock}, mergeRelayedMessages([]*CrossMessage{failed, sameBlock}, l2BlockNumber))
+}
+
+func TestRelayedMessagePrecedence(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	getMessage := func(messageHash string) *CrossMessage {
+		var message CrossMessage
+		assert.NoError(t, db.Where("message_hash = ?", messageHash).First(&message).Error)
+		return &message
+	}
+
+	// L1 deposits relayed on L2.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 10, L2TxHash: "0xa", TxStatus: int(TxStatusTypeFailedRelayed)},
+	}))
+	// a failed relay of an older block, e.g. of a re-fetched range, does not replace the newer one.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 5, L2TxHash: "0xb", TxStatus: int(TxStatusTypeRelayTxReverted)},
+	}))
+	assert.Equal(t, "0xa", getMessage("0x01").L2TxHash)
+	// a successful relay replaces failed ones, also of newer blocks, and is not replaced by later failed relays.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 8, L2TxHash: "0xc", TxStatus: int(TxStatusTypeRelayed)},
+	}))
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2BlockNumber: 12, L2TxHash: "0xd", TxStatus: int(TxStatusTypeRelayTxReverted)},
+	}))
+	message := getMessage("0x01")
+	assert.Equal(t, "0xc", message.L2TxHash)
+	assert.Equal(t, TxStatusTypeRelayed, TxStatusType(message.TxStatus))
+
+	// L2 withdrawals relayed on L1, with a success and a later failure in the same batch.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx, []*CrossMessage{
+		{MessageHash: "0x02", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 2, L1BlockNumber: 20, L1TxHash: "0xe", TxStatus: int(TxStatusTypeRelayed)},
+		{MessageHash: "0x02", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 2, L1BlockNumber: 21, L1TxHash: "0xf", TxStatus: int(TxStatusTypeRelayTxReverted)},
+	}))
+	message = getMessage("0x02")
+	assert.Equal(t, "0xe", message.L1TxHash)
+	assert.Equal(t, TxStatusTypeRelayed, TxStatusType(message.TxStatus))
+}
+
+func TestGetTxsByAddresses(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	var messages []*CrossMessage
+	for i := 0; i < 3; i++ {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL1SentMessage), MessageNonce: uint64(i),
+			Sender: "0xa", L1TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", TxStatus: int(TxStatusTypeSent), BlockTimestamp: uint64(i)})
+	}
+	messages = append(messages, &CrossMessage{MessageHash: "0x03", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 3,
+		Sender: "0xb", L1TxHash: "0x13", TokenAmounts: "1", TxStatus: int(TxStatusTypeSent), BlockTimestamp: 3})
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+
+	txs, err := crossMessageOrm.GetTxsByAddresses(ctx, []string{"0xa", "0xb", "0xc"}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 3)
+	assert.Equal(t, "0x02", txs[0].MessageHash)
+	assert.Equal(t, "0x01", txs[1].MessageHash)
+	assert.Equal(t, "0x03", txs[2].MessageHash)
+
+	// the counts are not truncated by the limit of returned txs
+	counts, err := crossMessageOrm.GetTxCountsByAddresses(ctx, []string{"0xa", "0xb", "0xc"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"0xa": 3, "0xb": 1}, counts)
+}
+
+func TestGetLatestUpdateByAddress(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	updatedAt, count, err := crossMessageOrm.GetLatestUpdateByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.True(t, updatedAt.IsZero())
+	assert.Equal(t, uint64(0), count)
+
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, Sender: "0xa", L1TxHash: "0x11", TokenAmounts: "1", TxStatus: int(TxStatusTypeSent)},
+	}))
+	inserted, count, err := crossMessageOrm.GetLatestUpdateByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.False(t, inserted.IsZero())
+	assert.Equal(t, uint64(1), count)
+
+	// the upsert of the relay updates the message.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0xa", TxStatus: int(TxStatusTypeRelayed)},
+	}))
+	relayed, count, err := crossMessageOrm.GetLatestUpdateByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.True(t, relayed.After(inserted))
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestTokenAmountQueries(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	eth := common.Address{}.String()
+	l1Token, l2Token := common.HexToAddress("0x01").String(), common.HexToAddress("0x02").String()
+	messages := []*CrossMessage{
+		{MessageHash: "0x01", MessageNonce: 1, TokenType: int(TokenTypeETH), TokenAmounts: "100", BlockTimestamp: 1},
+		{MessageHash: "0x02", MessageNonce: 2, TokenType: int(TokenTypeETH), TokenAmounts: "300", BlockTimestamp: 2},
+		{MessageHash: "0x03", MessageNonce: 3, TokenType: int(TokenTypeERC20), L1TokenAddress: l1Token, L2TokenAddress: l2Token, TokenAmounts: "200", BlockTimestamp: 3},
+		{MessageHash: "0x04", MessageNonce: 4, TokenType: int(TokenTypeETH), TokenAmounts: "1000", BlockTimestamp: 4, TxStatus: int(TxStatusTypeSentTxReverted)},
+		{MessageHash: "0x05", MessageNonce: 5, TokenType: int(TokenTypeERC1155), L1TokenAddress: l1Token, TokenIDs: "1, 2", TokenAmounts: "5, 5", BlockTimestamp: 5},
+	}
+	for _, message := range messages {
+		message.MessageType = int(MessageTypeL1SentMessage)
+		message.Sender = "0xa"
+		message.L1TxHash = message.MessageHash
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+
+	txs, err := crossMessageOrm.GetTxsByAddressAndTokenAmountRange(ctx, "0xa", eth, big.NewInt(100), big.NewInt(500))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Equal(t, "0x02", txs[0].MessageHash)
+	assert.Equal(t, "0x01", txs[1].MessageHash)
+
+	// the token is matched by its L1 or L2 address, erc1155 batches of several token ids have no total amount.
+	for _, token := range []string{l1Token, l2Token} {
+		txs, err = crossMessageOrm.GetTxsByAddressAndTokenAmountRange(ctx, "0xa", token, nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, txs, 1)
+		assert.Equal(t, "0x03", txs[0].MessageHash)
+	}
+
+	txs, err = crossMessageOrm.GetTxsByAddressAndTokenAmountRange(ctx, "0xa", eth, big.NewInt(301), nil)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, "0x04", txs[0].MessageHash)
+
+	sums, err := crossMessageOrm.SumTokenAmountsByAddress(ctx, "0xa", "")
+	assert.NoError(t, err)
+	assert.Len(t, sums, 2)
+	assert.Equal(t, int(TokenTypeETH), sums[0].TokenType)
+	assert.Equal(t, big.NewInt(400), sums[0].TotalAmount.Int)
+	assert.Equal(t, uint64(2), sums[0].TxCount)
+	assert.Equal(t, int(TokenTypeERC20), sums[1].TokenType)
+	assert.Equal(t, big.NewInt(200), sums[1].TotalAmount.Int)
+
+	sums, err = crossMessageOrm.SumTokenAmountsByAddress(ctx, "0xa", l2Token)
+	assert.NoError(t, err)
+	assert.Len(t, sums, 1)
+	assert.Equal(t, l1Token, sums[0].L1TokenAddress)
+	assert.Equal(t, big.NewInt(200), sums[0].TotalAmount.Int)
+}
+
+func TestMigrateBackfillsNumericAmounts(t *testing.T) {
+	defer resetDB(t)
+
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	version := int64(6)
+	assert.NoError(t, migrate.Rollback(sqlDB, &version))
+
+	insert := `INSERT INTO cross_message_v2 (message_type, tx_status, rollup_status, token_type, sender, receiver, message_hash, message_nonce, message_value, token_ids, token_amounts, block_timestamp)
+		VALUES (1, 0, 0, ?, '0xa', '0xb', ?, ?, ?, ?, ?, 0)`
+	assert.NoError(t, db.Exec(insert, TokenTypeETH, "0x01", 1, "10", "", "10").Error)
+	assert.NoError(t, db.Exec(insert, TokenTypeERC1155, "0x02", 2, "", "1, 1", "2, 3").Error)
+	assert.NoError(t, db.Exec(insert, TokenTypeERC1155, "0x03", 3, "", "1, 2", "2, 3").Error)
+	assert.NoError(t, db.Exec(insert, TokenTypeERC721, "0x04", 4, "x", "1", "").Error)
+
+	assert.NoError(t, migrate.Migrate(sqlDB))
+
+	var rows []struct {
+		MessageValueNumeric BigInt
+		TokenAmountsNumeric BigInt
+	}
+	assert.NoError(t, db.Table("cross_message_v2").Select("message_value_numeric, token_amounts_numeric").Order("message_nonce").Scan(&rows).Error)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, big.NewInt(10), rows[0].MessageValueNumeric.Int)
+	assert.Equal(t, big.NewInt(10), rows[0].TokenAmountsNumeric.Int)
+	assert.Equal(t, big.NewInt(5), rows[1].TokenAmountsNumeric.Int)
+	assert.Nil(t, rows[2].TokenAmountsNumeric.Int)
+	assert.Nil(t, rows[3].MessageValueNumeric.Int)
+	assert.Nil(t, rows[3].TokenAmountsNumeric.Int)
+}
+
+func TestGetClaimableL2WithdrawalsUpdatedBefore(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	statuses := []TxStatusType{TxStatusTypeSent, TxStatusTypeFailedRelayed, TxStatusTypeRelayTxReverted, TxStatusTypeRelayed, TxStatusTypeSent}
+	var messages []*CrossMessage
+	for i, status := range statuses {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL2SentMessage), MessageNonce: uint64(i),
+			L2TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", TxStatus: int(status), RollupStatus: int(RollupStatusTypeFinalized)})
+	}
+	// not finalized yet, so not claimable.
+	messages[4].RollupStatus = int(RollupStatusTypeUnknown)
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, messages))
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ?", time.Now().UTC().Add(-2*time.Hour)).Error)
+	// recently updated, left alone.
+	assert.NoError(t, db.Exec("UPDATE cross_message_v2 SET updated_at = ? WHERE message_hash = ?", time.Now().UTC(), "0x02").Error)
+
+	updatedBefore := time.Now().UTC().Add(-time.Hour)
+	claimable, err := crossMessageOrm.GetClaimableL2WithdrawalsUpdatedBefore(ctx, updatedBefore, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimable, 2)
+	assert.Equal(t, "0x00", claimable[0].MessageHash)
+	assert.Equal(t, "0x01", claimable[1].MessageHash)
+
+	// paging by id
+	page, err := crossMessageOrm.GetClaimableL2WithdrawalsUpdatedBefore(ctx, updatedBefore, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, "0x00", page[0].MessageHash)
+	page, err = crossMessageOrm.GetClaimableL2WithdrawalsUpdatedBefore(ctx, updatedBefore, page[0].ID, 1)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, "0x01", page[0].MessageHash)
+	page, err = crossMessageOrm.GetClaimableL2WithdrawalsUpdatedBefore(ctx, updatedBefore, page[0].ID, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestUpdateL2WithdrawalsRelayed(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	statuses := []TxStatusType{TxStatusTypeSent, TxStatusTypeFailedRelayed, TxStatusTypeDropped}
+	var messages []*CrossMessage
+	for i, status := range statuses {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL2SentMessage), MessageNonce: uint64(i),
+			L2TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", TxStatus: int(status), RollupStatus: int(RollupStatusTypeFinalized)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, messages))
+
+	corrected, err := crossMessageOrm.UpdateL2WithdrawalsRelayed(ctx, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, corrected)
+
+	// dropped withdrawals stay dropped, unknown hashes are ignored.
+	corrected, err = crossMessageOrm.UpdateL2WithdrawalsRelayed(ctx, []string{"0x00", "0x01", "0x02", "0x09"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), corrected)
+
+	expected := []TxStatusType{TxStatusTypeRelayed, TxStatusTypeRelayed, TxStatusTypeDropped}
+	for i, status := range expected {
+		var message CrossMessage
+		assert.NoError(t, db.Where("message_hash = ?", fmt.Sprintf("0x0%d", i)).First(&message).Error)
+		assert.Equal(t, int(status), message.TxStatus)
+	}
+
+	// already relayed withdrawals are not counted again.
+	corrected, err = crossMessageOrm.UpdateL2WithdrawalsRelayed(ctx, []string{"0x00"})
+	assert.NoError(t, err)
+	assert.Zero(t, corrected)
+}
+
+func claimableMessageHashes(t *testing.T) []string {
+	var messageHashes []string
+	assert.NoError(t, db.Model(&ClaimableWithdrawal{}).Order("message_hash").Pluck("message_hash", &messageHashes).Error)
+	return messageHashes
+}
+
+func TestClaimableWithdrawalMaintenance(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	statuses := []TxStatusType{TxStatusTypeSent, TxStatusTypeSent, TxStatusTypeFailedRelayed, TxStatusTypeDropped, TxStatusTypeSent}
+	var messages []*CrossMessage
+	for i, status := range statuses {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL2SentMessage), MessageNonce: uint64(i),
+			Sender: "0xa", L2TxHash: fmt.Sprintf("0x1%d", i), L2BlockNumber: uint64(i + 1), BlockTimestamp: uint64(i + 1), TokenAmounts: "1", TxStatus: int(status)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, messages))
+
+	// unclaimed withdrawals are all the sent ones, whether finalized or not.
+	unclaimed, err := crossMessageOrm.GetL2UnclaimedWithdrawalsByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.Len(t, unclaimed, 3)
+	claimable, err := crossMessageOrm.GetL2ClaimableWithdrawalsByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.Empty(t, claimable)
+
+	// finalization adds the claimable withdrawals of the batch, not the dropped one nor the ones of later batches.
+	assert.NoError(t, crossMessageOrm.UpdateBatchStatusOfL2Withdrawals(ctx, 1, 4, 1))
+	assert.Equal(t, []string{"0x00", "0x01", "0x02"}, claimableMessageHashes(t))
+	assert.NoError(t, crossMessageOrm.UpdateBatchIndexRollupStatusMerkleProofOfL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x04", BatchIndex: 2, RollupStatus: int(RollupStatusTypeFinalized), MerkleProof: []byte{0x01}},
+	}))
+	assert.Equal(t, []string{"0x00", "0x01", "0x02", "0x04"}, claimableMessageHashes(t))
+	claimable, err = crossMessageOrm.GetL2ClaimableWithdrawalsByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	if assert.Len(t, claimable, 4) {
+		assert.Equal(t, "0x04", claimable[0].MessageHash)
+	}
+
+	// a relayed withdrawal leaves the table, a failed relay stays claimable.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx, []*CrossMessage{
+		{MessageHash: "0x00", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 0, L1TxHash: "0x20", L1BlockNumber: 1, TxStatus: int(TxStatusTypeRelayed)},
+		{MessageHash: "0x01", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L1TxHash: "0x21", L1BlockNumber: 1, TxStatus: int(TxStatusTypeFailedRelayed)},
+	}))
+	assert.Equal(t, []string{"0x01", "0x02", "0x04"}, claimableMessageHashes(t))
+
+	// a withdrawal dropped after it was finalized leaves the table with the next update touching it.
+	assert.NoError(t, db.Model(&CrossMessage{}).Where("message_hash = ?", "0x02").Update("tx_status", TxStatusTypeDropped).Error)
+	claimable, err = crossMessageOrm.GetL2ClaimableWithdrawalsByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.Len(t, claimable, 2)
+	corrected, err := crossMessageOrm.UpdateL2WithdrawalsRelayed(ctx, []string{"0x02", "0x04"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), corrected)
+	assert.Equal(t, []string{"0x01"}, claimableMessageHashes(t))
+
+	unclaimed, err = crossMessageOrm.GetL2UnclaimedWithdrawalsByAddress(ctx, "0xa")
+	assert.NoError(t, err)
+	assert.Empty(t, unclaimed)
+}
+
+func TestGetNextL2IncludedL1MessageNonce(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	next, err := crossMessageOrm.GetNextL2IncludedL1MessageNonce(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, next)
+
+	statuses := []TxStatusType{TxStatusTypeRelayed, TxStatusTypeSent, TxStatusTypeSent}
+	var messages []*CrossMessage
+	for i, status := range statuses {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL1SentMessage), MessageNonce: uint64(i),
+			L1TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", TxStatus: int(status)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+	next, err = crossMessageOrm.GetNextL2IncludedL1MessageNonce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+
+	// a failed relay is included on L2 too.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0x21", TxStatus: int(TxStatusTypeFailedRelayed)},
+	}))
+	next, err = crossMessageOrm.GetNextL2IncludedL1MessageNonce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), next)
+}
+
+func TestRecentLatencies(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	// deposits relayed 60s, 120s and 180s after their tx, one relayed before the relay timestamp was stored.
+	var deposits []*CrossMessage
+	for i := 0; i < 4; i++ {
+		deposits = append(deposits, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL1SentMessage), MessageNonce: uint64(i),
+			L1TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", BlockTimestamp: 1000, TxStatus: int(TxStatusTypeSent)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, deposits))
+	var relays []*CrossMessage
+	for i := 0; i < 4; i++ {
+		relayBlockTimestamp := uint64(1000 + 60*(i+1))
+		if i == 3 {
+			relayBlockTimestamp = 0
+		}
+		relays = append(relays, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL1SentMessage), MessageNonce: uint64(i),
+			L2TxHash: fmt.Sprintf("0x2%d", i), L2BlockNumber: uint64(i + 1), TxStatus: int(TxStatusTypeRelayed), L2RelayBlockTimestamp: relayBlockTimestamp})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, relays))
+
+	stat, err := crossMessageOrm.GetRecentDepositRelayLatency(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), stat.SampleCount)
+	assert.Equal(t, float64(120), stat.MedianSec)
+
+	// withdrawals finalized 600s after their tx, the batch finalization block timestamp is stored by the batch event.
+	batchEventOrm := NewBatchEvent(db)
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 1, BatchHash: "0xb1", StartBlockNumber: 1, EndBlockNumber: 10},
+	}))
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 1, BatchHash: "0xb1", FinalizeBlockTimestamp: 1600},
+	}))
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x10", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 0, L2TxHash: "0x30", L2BlockNumber: 5, TokenAmounts: "1", BlockTimestamp: 1000},
+	}))
+	assert.NoError(t, crossMessageOrm.UpdateBatchStatusOfL2Withdrawals(ctx, 1, 10, 1))
+
+	stat, err = crossMessageOrm.GetRecentWithdrawalFinalizeLatency(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stat.SampleCount)
+	assert.Equal(t, float64(600), stat.MedianSec)
+}
+
+func TestMessageQueueEventsRollback(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	var messages []*CrossMessage
+	for i := 0; i < 3; i++ {
+		messages = append(messages, &CrossMessage{MessageHash: fmt.Sprintf("0x0%d", i), MessageType: int(MessageTypeL1SentMessage), MessageNonce: uint64(i),
+			L1TxHash: fmt.Sprintf("0x1%d", i), TokenAmounts: "1", TxStatus: int(TxStatusTypeSent)})
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+	txStatusOf := func(nonce uint64) TxStatusType {
+		var message CrossMessage
+		assert.NoError(t, db.Where("message_nonce = ? AND message_type = ?", nonce, MessageTypeL1SentMessage).First(&message).Error)
+		return TxStatusType(message.TxStatus)
+	}
+
+	// message 0 is skipped in block 10 and dropped in block 20, message 1 is skipped in block 20.
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 10, []*MessageQueueEvent{
+		{EventType: MessageQueueEventTypeDequeueTransaction, QueueIndex: 0, L1BlockNumber: 10},
+	}))
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 20, []*MessageQueueEvent{
+		{EventType: MessageQueueEventTypeDropTransaction, QueueIndex: 0, L1BlockNumber: 20, TxHash: common.HexToHash("0xaa")},
+		{EventType: MessageQueueEventTypeDequeueTransaction, QueueIndex: 1, L1BlockNumber: 20},
+	}))
+	assert.Equal(t, TxStatusTypeDropped, txStatusOf(0))
+	assert.Equal(t, TxStatusTypeSkipped, txStatusOf(1))
+
+	// block 20 is reorged out, its re-fetch holds the skip of message 2 only.
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 15, []*MessageQueueEvent{
+		{EventType: MessageQueueEventTypeDequeueTransaction, QueueIndex: 2, L1BlockNumber: 21},
+	}))
+	assert.Equal(t, TxStatusTypeSkipped, txStatusOf(0))
+	assert.Equal(t, TxStatusTypeSent, txStatusOf(1))
+	assert.Equal(t, TxStatusTypeSkipped, txStatusOf(2))
+	var message CrossMessage
+	assert.NoError(t, db.Where("message_nonce = ? AND message_type = ?", 0, MessageTypeL1SentMessage).First(&message).Error)
+	assert.Empty(t, message.L1RefundTxHash)
+
+	// re-fetching block 10 after it was reorged out too restores the initial status.
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 10, nil))
+	assert.Equal(t, TxStatusTypeSent, txStatusOf(0))
+	assert.Equal(t, TxStatusTypeSent, txStatusOf(2))
+
+	var effects int64
+	assert.NoError(t, db.Model(&MessageQueueEffect{}).Count(&effects).Error)
+	assert.Zero(t, effects)
+}
+
+func TestMessageQueueEventsDroppedRefunded(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L1TxHash: "0x11", TokenAmounts: "1", TxStatus: int(TxStatusTypeSkipped)},
+	}))
+	messageOf := func() *CrossMessage {
+		var message CrossMessage
+		assert.NoError(t, db.Where("message_hash = ?", "0x01").First(&message).Error)
+		return &message
+	}
+
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 20, []*MessageQueueEvent{
+		{EventType: MessageQueueEventTypeDropTransaction, QueueIndex: 1, L1BlockNumber: 20, TxHash: common.HexToHash("0xaa"), Refunded: true},
+	}))
+	message := messageOf()
+	assert.Equal(t, int(TxStatusTypeDroppedRefunded), message.TxStatus)
+	assert.Equal(t, common.HexToHash("0xaa").String(), message.L1RefundTxHash)
+
+	// the terminal status is not over-written by a later relay.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L2TxHash: "0x21", L2BlockNumber: 30, TxStatus: int(TxStatusTypeFailedRelayed)},
+	}))
+	assert.Equal(t, int(TxStatusTypeDroppedRefunded), messageOf().TxStatus)
+
+	// the drop is rolled back with its block.
+	assert.NoError(t, crossMessageOrm.UpdateL1MessageQueueEventsInfo(ctx, 20, nil))
+	message = messageOf()
+	assert.Equal(t, int(TxStatusTypeSkipped), message.TxStatus)
+	assert.Empty(t, message.L1RefundTxHash)
+}
@@ -0,0 +1,112 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
+)
+
+// FeeVaultWithdrawal is a withdrawal of an L2 fee vault, i.e. protocol revenue bridged to L1.
+type FeeVaultWithdrawal struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	L2TxHash       string    `json:"l2_tx_hash" gorm:"column:l2_tx_hash;primary_key"`
+	LogIndex       uint      `json:"log_index" gorm:"column:log_index;primary_key"`
+	VaultAddress   string    `json:"vault_address" gorm:"column:vault_address"`
+	Value          BigInt    `json:"value" gorm:"column:value"`
+	Recipient      string    `json:"recipient" gorm:"column:recipient"`
+	TriggeredBy    string    `json:"triggered_by" gorm:"column:triggered_by"`
+	MessageHash    string    `json:"message_hash" gorm:"column:message_hash"`
+	L2BlockNumber  uint64    `json:"l2_block_number" gorm:"column:l2_block_number"`
+	BlockTimestamp uint64    `json:"block_timestamp" gorm:"column:block_timestamp"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// FeeVaultWithdrawalWithStatus is a fee vault withdrawal with the status of its L2 message, zero if not indexed.
+type FeeVaultWithdrawalWithStatus struct {
+	FeeVaultWithdrawal
+	TxStatus     int    `gorm:"column:tx_status"`
+	RollupStatus int    `gorm:"column:rollup_status"`
+	L1TxHash     string `gorm:"column:l1_tx_hash"` // the tx claiming the fees on L1, empty if not claimed yet
+}
+
+// FeeVaultWithdrawalTotals is the number and total value of fee vault withdrawals.
+type FeeVaultWithdrawalTotals struct {
+	Count uint64 `gorm:"column:count"`
+	Value BigInt `gorm:"column:value"`
+}
+
+// TableName returns the table name for the FeeVaultWithdrawal model.
+func (*FeeVaultWithdrawal) TableName() string {
+	return "fee_vault_withdrawal"
+}
+
+// NewFeeVaultWithdrawal returns a new instance of FeeVaultWithdrawal.
+func NewFeeVaultWithdrawal(db *gorm.DB) *FeeVaultWithdrawal {
+	return &FeeVaultWithdrawal{db: db}
+}
+
+// GetFeeVaultWithdrawals returns the latest fee vault withdrawals of a vault, of all vaults if vault is empty, with
+// the status of their L2 messages.
+func (f *FeeVaultWithdrawal) GetFeeVaultWithdrawals(ctx context.Context, vault string, offset, limit int) ([]*FeeVaultWithdrawalWithStatus, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var withdrawals []*FeeVaultWithdrawalWithStatus
+	db := f.db.WithContext(ctx)
+	db = db.Table("fee_vault_withdrawal AS w")
+	db = db.Select("w.*, COALESCE(cm.tx_status, 0) AS tx_status, COALESCE(cm.rollup_status, 0) AS rollup_status, COALESCE(cm.l1_tx_hash, '') AS l1_tx_hash")
+	db = db.Joins("LEFT JOIN cross_message_v2 AS cm ON cm.message_hash = w.message_hash AND cm.message_type = ? AND cm.deleted_at IS NULL", int(MessageTypeL2SentMessage))
+	if vault != "" {
+		db = db.Where("w.vault_address = ?", vault)
+	}
+	db = db.Order("w.l2_block_number DESC, w.log_index DESC")
+	db = db.Offset(offset)
+	db = db.Limit(limit)
+	if err := db.Find(&withdrawals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get fee vault withdrawals, vault: %v, error: %w", vault, err)
+	}
+	return withdrawals, nil
+}
+
+// GetFeeVaultWithdrawalTotals returns the number and total value of the withdrawals of a vault, of all vaults if vault
+// is empty.
+func (f *FeeVaultWithdrawal) GetFeeVaultWithdrawalTotals(ctx context.Context, vault string) (*FeeVaultWithdrawalTotals, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var totals FeeVaultWithdrawalTotals
+	db := f.db.WithContext(ctx)
+	db = db.Model(&FeeVaultWithdrawal{})
+	db = db.Select("COUNT(*) AS count, COALESCE(SUM(value), 0) AS value")
+	if vault != "" {
+		db = db.Where("vault_address = ?", vault)
+	}
+	if err := db.Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get fee vault withdrawal totals, vault: %v, error: %w", vault, err)
+	}
+	return &totals, nil
+}
+
+// InsertOrUpdateFeeVaultWithdrawals inserts fee vault withdrawals, the ones already indexed are over-written, e.g. by
+// the blocks re-fetched after an L2 reorg.
+func (f *FeeVaultWithdrawal) InsertOrUpdateFeeVaultWithdrawals(ctx context.Context, withdrawals []*FeeVaultWithdrawal) error {
+	if len(withdrawals) == 0 {
+		return nil
+	}
+	db := f.db.WithContext(ctx)
+	db = db.Model(&FeeVaultWithdrawal{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "l2_tx_hash"}, {Name: "log_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"vault_address", "value", "recipient", "triggered_by", "message_hash", "l2_block_number", "block_timestamp", "updated_at"}),
+	})
+	if err := database.WithRetry(ctx, func() error { return db.Session(&gorm.Session{}).Create(withdrawals).Error }); err != nil {
+		return fmt.Errorf("failed to insert or update fee vault withdrawals, error: %w", err)
+	}
+	return nil
+}
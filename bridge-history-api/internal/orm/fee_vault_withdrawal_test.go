@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeVaultWithdrawalOrm(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	feeVaultWithdrawalOrm := NewFeeVaultWithdrawal(db)
+	crossMessageOrm := NewCrossMessage(db)
+
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0xm1", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L2TxHash: "0xt1", TxStatus: int(TxStatusTypeSent)},
+	}))
+	assert.NoError(t, feeVaultWithdrawalOrm.InsertOrUpdateFeeVaultWithdrawals(ctx, []*FeeVaultWithdrawal{
+		{L2TxHash: "0xt1", LogIndex: 1, VaultAddress: "0xv1", Value: NewBigInt(big.NewInt(100)), MessageHash: "0xm1", L2BlockNumber: 10},
+		{L2TxHash: "0xt2", LogIndex: 3, VaultAddress: "0xv1", Value: NewBigInt(big.NewInt(200)), L2BlockNumber: 20},
+		{L2TxHash: "0xt3", LogIndex: 2, VaultAddress: "0xv2", Value: NewBigInt(big.NewInt(400)), L2BlockNumber: 15},
+	}))
+	// re-indexed after a reorg.
+	assert.NoError(t, feeVaultWithdrawalOrm.InsertOrUpdateFeeVaultWithdrawals(ctx, []*FeeVaultWithdrawal{
+		{L2TxHash: "0xt2", LogIndex: 3, VaultAddress: "0xv1", Value: NewBigInt(big.NewInt(300)), L2BlockNumber: 21},
+	}))
+
+	withdrawals, err := feeVaultWithdrawalOrm.GetFeeVaultWithdrawals(ctx, "", 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, withdrawals, 3) {
+		assert.Equal(t, "0xt2", withdrawals[0].L2TxHash)
+		assert.Equal(t, "300", withdrawals[0].Value.String())
+		assert.Equal(t, uint64(21), withdrawals[0].L2BlockNumber)
+		assert.Equal(t, 0, withdrawals[0].TxStatus)
+		assert.Equal(t, "0xt3", withdrawals[1].L2TxHash)
+		assert.Equal(t, "0xt1", withdrawals[2].L2TxHash)
+		assert.Equal(t, int(TxStatusTypeSent), withdrawals[2].TxStatus)
+	}
+
+	withdrawals, err = feeVaultWithdrawalOrm.GetFeeVaultWithdrawals(ctx, "0xv1", 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, withdrawals, 1) {
+		assert.Equal(t, "0xt1", withdrawals[0].L2TxHash)
+	}
+
+	totals, err := feeVaultWithdrawalOrm.GetFeeVaultWithdrawalTotals(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), totals.Count)
+	assert.Equal(t, "800", totals.Value.String())
+
+	totals, err = feeVaultWithdrawalOrm.GetFeeVaultWithdrawalTotals(ctx, "0xv3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), totals.Count)
+	assert.Equal(t, "0", totals.Value.String())
+}
@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
+)
+
+// L1MessageInclusion represents an L1 message executed on L2 by an L1 message tx.
+type L1MessageInclusion struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	QueueIndex       uint64    `json:"queue_index" gorm:"column:queue_index;primary_key"`
+	MessageHash      string    `json:"message_hash" gorm:"column:message_hash"`
+	MessageNonce     uint64    `json:"message_nonce" gorm:"column:message_nonce"`
+	L2TxHash         string    `json:"l2_tx_hash" gorm:"column:l2_tx_hash"`
+	L2BlockNumber    uint64    `json:"l2_block_number" gorm:"column:l2_block_number"`
+	L2BlockTimestamp uint64    `json:"l2_block_timestamp" gorm:"column:l2_block_timestamp"`
+	TxStatus         int       `json:"tx_status" gorm:"column:tx_status"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the L1MessageInclusion model.
+func (*L1MessageInclusion) TableName() string {
+	return "l1_message_inclusion"
+}
+
+// NewL1MessageInclusion returns a new instance of L1MessageInclusion.
+func NewL1MessageInclusion(db *gorm.DB) *L1MessageInclusion {
+	return &L1MessageInclusion{db: db}
+}
+
+// GetL1MessageInclusionsByMessageHashes returns the inclusions of the L1 messages of the message hashes, a replayed
+// message may be included once per queue index.
+func (m *L1MessageInclusion) GetL1MessageInclusionsByMessageHashes(ctx context.Context, messageHashes []string) ([]*L1MessageInclusion, error) {
+	if len(messageHashes) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var inclusions []*L1MessageInclusion
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1MessageInclusion{})
+	db = db.Where("message_hash IN (?)", messageHashes)
+	db = db.Order("queue_index")
+	if err := db.Find(&inclusions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get L1 message inclusions by message hashes, error: %w", err)
+	}
+	return inclusions, nil
+}
+
+// InsertOrUpdateL1MessageInclusions inserts the inclusions of L1 messages, the ones of queue indexes already included
+// are over-written, e.g. by the blocks re-fetched after an L2 reorg.
+func (m *L1MessageInclusion) InsertOrUpdateL1MessageInclusions(ctx context.Context, inclusions []*L1MessageInclusion) error {
+	if len(inclusions) == 0 {
+		return nil
+	}
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1MessageInclusion{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "queue_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"message_hash", "message_nonce", "l2_tx_hash", "l2_block_number", "l2_block_timestamp", "tx_status", "updated_at"}),
+	})
+	if err := database.WithRetry(ctx, func() error { return db.Session(&gorm.Session{}).Create(inclusions).Error }); err != nil {
+		return fmt.Errorf("failed to insert or update L1 message inclusions, error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertOrUpdateL1MessageInclusions(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	inclusionOrm := NewL1MessageInclusion(db)
+
+	assert.NoError(t, inclusionOrm.InsertOrUpdateL1MessageInclusions(ctx, []*L1MessageInclusion{
+		{QueueIndex: 1, MessageHash: "0x01", MessageNonce: 1, L2TxHash: "0xa1", L2BlockNumber: 10, L2BlockTimestamp: 100, TxStatus: int(TxStatusTypeRelayed)},
+		{QueueIndex: 2, MessageHash: "0x02", MessageNonce: 2, L2TxHash: "0xa2", L2BlockNumber: 10, L2BlockTimestamp: 100, TxStatus: int(TxStatusTypeRelayTxReverted)},
+	}))
+	// the replay of message 0x02 is included with another queue index, block 10 is re-fetched after a reorg.
+	assert.NoError(t, inclusionOrm.InsertOrUpdateL1MessageInclusions(ctx, []*L1MessageInclusion{
+		{QueueIndex: 1, MessageHash: "0x01", MessageNonce: 1, L2TxHash: "0xb1", L2BlockNumber: 10, L2BlockTimestamp: 101, TxStatus: int(TxStatusTypeRelayed)},
+		{QueueIndex: 5, MessageHash: "0x02", MessageNonce: 2, L2TxHash: "0xa5", L2BlockNumber: 12, L2BlockTimestamp: 120, TxStatus: int(TxStatusTypeRelayed)},
+	}))
+
+	inclusions, err := inclusionOrm.GetL1MessageInclusionsByMessageHashes(ctx, []string{"0x01", "0x02", "0x03"})
+	assert.NoError(t, err)
+	assert.Len(t, inclusions, 3)
+	assert.Equal(t, uint64(1), inclusions[0].QueueIndex)
+	assert.Equal(t, "0xb1", inclusions[0].L2TxHash)
+	assert.Equal(t, uint64(101), inclusions[0].L2BlockTimestamp)
+	assert.Equal(t, uint64(2), inclusions[1].QueueIndex)
+	assert.Equal(t, int(TxStatusTypeRelayTxReverted), inclusions[1].TxStatus)
+	assert.Equal(t, uint64(5), inclusions[2].QueueIndex)
+	assert.Equal(t, "0x02", inclusions[2].MessageHash)
+
+	inclusions, err = inclusionOrm.GetL1MessageInclusionsByMessageHashes(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, inclusions)
+}
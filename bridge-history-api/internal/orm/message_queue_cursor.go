@@ -0,0 +1,97 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+)
+
+// messageQueueCursorRetainedBlocks is the number of L1 blocks whose cursors are kept below the latest one,
+// it covers re-fetching after a restart or a reorg, which both resync from 64 blocks back.
+const messageQueueCursorRetainedBlocks = 1024
+
+// MessageQueueCursor represents the cursors of the L1 message queue after an L1 block.
+type MessageQueueCursor struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	L1BlockNumber     uint64    `json:"l1_block_number" gorm:"column:l1_block_number;primary_key"`
+	NextQueueIndex    uint64    `json:"next_queue_index" gorm:"column:next_queue_index"`
+	PendingQueueIndex uint64    `json:"pending_queue_index" gorm:"column:pending_queue_index"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName returns the table name for the MessageQueueCursor model.
+func (*MessageQueueCursor) TableName() string {
+	return "message_queue_cursor"
+}
+
+// NewMessageQueueCursor returns a new instance of MessageQueueCursor.
+func NewMessageQueueCursor(db *gorm.DB) *MessageQueueCursor {
+	return &MessageQueueCursor{db: db}
+}
+
+// GetMessageQueueCursor returns the latest cursors of the L1 message queue, or nil if no message queue event is tracked yet.
+func (m *MessageQueueCursor) GetMessageQueueCursor(ctx context.Context) (*MessageQueueCursor, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var cursor MessageQueueCursor
+	db := m.db.WithContext(ctx)
+	db = db.Model(&MessageQueueCursor{})
+	db = db.Order("l1_block_number desc")
+	if err := db.First(&cursor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get message queue cursor, error: %w", err)
+	}
+	return &cursor, nil
+}
+
+// ReplaceMessageQueueCursors replaces the cursors of the L1 blocks from fromBlock on with the given ones, which hold the
+// highest indexes seen in the events of their block, ordered by block number. The cursors tracked for blocks at or after
+// fromBlock are dropped, so re-fetching a range after a reorg rewinds them. The cursors of a block carry the ones of the
+// blocks before it, since the queue indexes only grow.
+func (m *MessageQueueCursor) ReplaceMessageQueueCursors(ctx context.Context, fromBlock uint64, cursors []*MessageQueueCursor) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("l1_block_number >= ?", fromBlock).Delete(&MessageQueueCursor{}).Error; err != nil {
+			return err
+		}
+		if len(cursors) == 0 {
+			return nil
+		}
+
+		var previous MessageQueueCursor
+		db := tx.Model(&MessageQueueCursor{})
+		db = db.Where("l1_block_number < ?", fromBlock)
+		db = db.Order("l1_block_number desc")
+		if err := db.Limit(1).Find(&previous).Error; err != nil {
+			return err
+		}
+		for _, cursor := range cursors {
+			cursor.NextQueueIndex = max(cursor.NextQueueIndex, previous.NextQueueIndex)
+			cursor.PendingQueueIndex = max(cursor.PendingQueueIndex, previous.PendingQueueIndex)
+			previous = *cursor
+		}
+		if err := tx.Model(&MessageQueueCursor{}).Create(cursors).Error; err != nil {
+			return err
+		}
+
+		// keep the latest cursors before the retained blocks, so that there is always one to rewind to.
+		latest := cursors[len(cursors)-1].L1BlockNumber
+		if latest <= messageQueueCursorRetainedBlocks {
+			return nil
+		}
+		retainedSince := tx.Model(&MessageQueueCursor{}).Select("MAX(l1_block_number)").Where("l1_block_number < ?", latest-messageQueueCursorRetainedBlocks)
+		return tx.Where("l1_block_number < (?)", retainedSince).Delete(&MessageQueueCursor{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace message queue cursors, from block: %v, error: %w", fromBlock, err)
+	}
+	return nil
+}
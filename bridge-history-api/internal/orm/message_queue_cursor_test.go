@@ -0,0 +1,52 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceMessageQueueCursors(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	cursorOrm := NewMessageQueueCursor(db)
+
+	cursor, err := cursorOrm.GetMessageQueueCursor(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	// the cursors of a block carry the ones of the blocks before it.
+	assert.NoError(t, cursorOrm.ReplaceMessageQueueCursors(ctx, 1, []*MessageQueueCursor{
+		{L1BlockNumber: 10, NextQueueIndex: 5},
+		{L1BlockNumber: 12, PendingQueueIndex: 3},
+		{L1BlockNumber: 15, NextQueueIndex: 8},
+	}))
+	cursor, err = cursorOrm.GetMessageQueueCursor(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), cursor.L1BlockNumber)
+	assert.Equal(t, uint64(8), cursor.NextQueueIndex)
+	assert.Equal(t, uint64(3), cursor.PendingQueueIndex)
+
+	// blocks re-fetched after a reorg rewind the cursors, also when they hold no queue event any more.
+	assert.NoError(t, cursorOrm.ReplaceMessageQueueCursors(ctx, 12, nil))
+	cursor, err = cursorOrm.GetMessageQueueCursor(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), cursor.L1BlockNumber)
+	assert.Equal(t, uint64(5), cursor.NextQueueIndex)
+	assert.Zero(t, cursor.PendingQueueIndex)
+
+	assert.NoError(t, cursorOrm.ReplaceMessageQueueCursors(ctx, 11, []*MessageQueueCursor{{L1BlockNumber: 13, NextQueueIndex: 6, PendingQueueIndex: 2}}))
+	cursor, err = cursorOrm.GetMessageQueueCursor(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(13), cursor.L1BlockNumber)
+	assert.Equal(t, uint64(6), cursor.NextQueueIndex)
+	assert.Equal(t, uint64(2), cursor.PendingQueueIndex)
+
+	// old cursors are pruned, the latest one before the retained blocks is kept.
+	latest := uint64(13 + messageQueueCursorRetainedBlocks + 5)
+	assert.NoError(t, cursorOrm.ReplaceMessageQueueCursors(ctx, latest, []*MessageQueueCursor{{L1BlockNumber: latest, NextQueueIndex: 7}}))
+	var blockNumbers []uint64
+	assert.NoError(t, db.Model(&MessageQueueCursor{}).Order("l1_block_number").Pluck("l1_block_number", &blockNumbers).Error)
+	assert.Equal(t, []uint64{13, latest}, blockNumbers)
+}
@@ -0,0 +1,59 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessageQueueEffect is the status change of an L1 message made by a DequeueTransaction or DropTransaction event.
+type MessageQueueEffect struct {
+	ID                  uint64                `json:"id" gorm:"column:id;primary_key"`
+	QueueIndex          uint64                `json:"queue_index" gorm:"column:queue_index"`
+	EventType           MessageQueueEventType `json:"event_type" gorm:"column:event_type"`
+	L1BlockNumber       uint64                `json:"l1_block_number" gorm:"column:l1_block_number"`
+	TxStatus            TxStatusType          `json:"tx_status" gorm:"column:tx_status"`
+	PriorTxStatus       TxStatusType          `json:"prior_tx_status" gorm:"column:prior_tx_status"`
+	PriorL1RefundTxHash *string               `json:"prior_l1_refund_tx_hash" gorm:"column:prior_l1_refund_tx_hash"`
+	CreatedAt           time.Time             `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName returns the table name for the MessageQueueEffect model.
+func (*MessageQueueEffect) TableName() string {
+	return "message_queue_effect"
+}
+
+// rollbackMessageQueueEffects restores the prior statuses of the L1 messages changed by the events of the blocks from
+// fromBlock on, latest change first, and forgets these changes. A message whose status changed since is left alone.
+func rollbackMessageQueueEffects(tx *gorm.DB, fromBlock uint64) error {
+	var effects []*MessageQueueEffect
+	if err := tx.Where("l1_block_number >= ?", fromBlock).Order("id desc").Find(&effects).Error; err != nil {
+		return err
+	}
+	for _, effect := range effects {
+		db := tx.Model(&CrossMessage{})
+		db = db.Where("message_nonce = ?", effect.QueueIndex)
+		db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+		db = db.Where("tx_status = ?", effect.TxStatus)
+		updateFields := map[string]interface{}{"tx_status": effect.PriorTxStatus}
+		if effect.EventType == MessageQueueEventTypeDropTransaction {
+			updateFields["l1_refund_tx_hash"] = effect.PriorL1RefundTxHash
+		}
+		if err := db.Updates(updateFields).Error; err != nil {
+			return err
+		}
+	}
+	if len(effects) == 0 {
+		return nil
+	}
+	return tx.Where("l1_block_number >= ?", fromBlock).Delete(&MessageQueueEffect{}).Error
+}
+
+// pruneMessageQueueEffects forgets the changes of the blocks below the retained blocks before latestBlock, which are
+// not re-fetched any more.
+func pruneMessageQueueEffects(tx *gorm.DB, latestBlock uint64) error {
+	if latestBlock <= messageQueueCursorRetainedBlocks {
+		return nil
+	}
+	return tx.Where("l1_block_number < ?", latestBlock-messageQueueCursorRetainedBlocks).Delete(&MessageQueueEffect{}).Error
+}
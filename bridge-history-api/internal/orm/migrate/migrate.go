@@ -15,10 +15,13 @@ var embedMigrations embed.FS
 // MigrationsDir migration dir
 const MigrationsDir string = "migrations"
 
+// TableName is the table recording the applied migrations.
+const TableName = "bridge_historyv2_migrations"
+
 func init() {
 	goose.SetBaseFS(embedMigrations)
 	goose.SetSequential(true)
-	goose.SetTableName("bridge_historyv2_migrations")
+	goose.SetTableName(TableName)
 
 	verbose, _ := strconv.ParseBool(os.Getenv("LOG_SQL_MIGRATIONS"))
 	goose.SetVerbose(verbose)
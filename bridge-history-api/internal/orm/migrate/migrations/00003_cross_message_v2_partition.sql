@@ -0,0 +1,137 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Partition cross_message_v2 by message_type, then each message type by ranges of message_nonce.
+-- The messengers assign message nonces sequentially, so a nonce range is a range of blocks of the sending layer,
+-- and the partitions of old messages stay cold while new messages only touch the latest partition.
+-- message_type and message_nonce are fixed for a given message_hash (the nonce is part of the hashed message,
+-- relayed events are stored with the type and nonce of the sent message), so (message_hash, message_type, message_nonce)
+-- keeps the uniqueness of the old message_hash index while including the partition keys, as Postgres requires.
+-- block_timestamp is not used as the key since relayed events may be indexed before the sent event that carries it.
+-- The range size must match crossMessageNoncePartitionSize in orm/partition.go.
+ALTER TABLE cross_message_v2 RENAME TO cross_message_v2_unpartitioned;
+
+CREATE TABLE cross_message_v2
+(
+    id                  BIGINT       NOT NULL DEFAULT nextval('cross_message_v2_id_seq'),
+    message_type        SMALLINT     NOT NULL,
+    tx_status           SMALLINT     NOT NULL,
+    rollup_status       SMALLINT     NOT NULL,
+    token_type          SMALLINT     NOT NULL,
+    sender              VARCHAR      NOT NULL,
+    receiver            VARCHAR      NOT NULL,
+
+    message_hash        VARCHAR      DEFAULT NULL, -- NULL for failed txs
+    l1_tx_hash          VARCHAR      DEFAULT NULL,
+    l1_replay_tx_hash   VARCHAR      DEFAULT NULL,
+    l1_refund_tx_hash   VARCHAR      DEFAULT NULL,
+    l2_tx_hash          VARCHAR      DEFAULT NULL,
+    l1_block_number     BIGINT       DEFAULT NULL,
+    l2_block_number     BIGINT       DEFAULT NULL,
+    l1_token_address    VARCHAR      DEFAULT NULL,
+    l2_token_address    VARCHAR      DEFAULT NULL,
+    token_ids           VARCHAR      DEFAULT NULL,
+    token_amounts       VARCHAR      NOT NULL,
+    block_timestamp     BIGINT       NOT NULL,     -- timestamp to sort L1 Deposit & L2 Withdraw events altogether
+
+--- claim info
+    message_from        VARCHAR      DEFAULT NULL,
+    message_to          VARCHAR      DEFAULT NULL,
+    message_value       VARCHAR      DEFAULT NULL,
+    message_nonce       BIGINT       NOT NULL DEFAULT 0, -- 0 for failed txs
+    message_data        VARCHAR      DEFAULT NULL,
+    merkle_proof        BYTEA        DEFAULT NULL,
+    batch_index         BIGINT       DEFAULT NULL,
+
+-- metadata
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0) DEFAULT NULL,
+
+    PRIMARY KEY (id, message_type, message_nonce)
+) PARTITION BY LIST (message_type);
+
+CREATE TABLE IF NOT EXISTS cross_message_v2_l1_sent PARTITION OF cross_message_v2 FOR VALUES IN (1) PARTITION BY RANGE (message_nonce);
+CREATE TABLE IF NOT EXISTS cross_message_v2_l2_sent PARTITION OF cross_message_v2 FOR VALUES IN (2) PARTITION BY RANGE (message_nonce);
+CREATE TABLE IF NOT EXISTS cross_message_v2_l1_sent_default PARTITION OF cross_message_v2_l1_sent DEFAULT;
+CREATE TABLE IF NOT EXISTS cross_message_v2_l2_sent_default PARTITION OF cross_message_v2_l2_sent DEFAULT;
+CREATE TABLE IF NOT EXISTS cross_message_v2_default PARTITION OF cross_message_v2 DEFAULT;
+
+-- Create the nonce ranges of the existing messages and the next one before copying,
+-- a range partition can not be created while the default partition holds rows of its range.
+DO $$
+DECLARE
+    range_size  CONSTANT BIGINT := 1000000;
+    parent      TEXT;
+    msg_type    SMALLINT;
+    max_range   BIGINT;
+BEGIN
+    FOREACH parent IN ARRAY ARRAY['cross_message_v2_l1_sent', 'cross_message_v2_l2_sent'] LOOP
+        msg_type := CASE parent WHEN 'cross_message_v2_l1_sent' THEN 1 ELSE 2 END;
+        SELECT COALESCE(MAX(message_nonce), 0) / range_size + 1 INTO max_range
+        FROM cross_message_v2_unpartitioned WHERE message_type = msg_type;
+        FOR i IN 0..max_range LOOP
+            EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%s) TO (%s)',
+                parent || '_p' || i, parent, i * range_size, (i + 1) * range_size);
+        END LOOP;
+    END LOOP;
+END $$;
+
+INSERT INTO cross_message_v2 (id, message_type, tx_status, rollup_status, token_type, sender, receiver,
+    message_hash, l1_tx_hash, l1_replay_tx_hash, l1_refund_tx_hash, l2_tx_hash, l1_block_number, l2_block_number,
+    l1_token_address, l2_token_address, token_ids, token_amounts, block_timestamp,
+    message_from, message_to, message_value, message_nonce, message_data, merkle_proof, batch_index,
+    created_at, updated_at, deleted_at)
+SELECT id, message_type, tx_status, rollup_status, token_type, sender, receiver,
+    message_hash, l1_tx_hash, l1_replay_tx_hash, l1_refund_tx_hash, l2_tx_hash, l1_block_number, l2_block_number,
+    l1_token_address, l2_token_address, token_ids, token_amounts, block_timestamp,
+    message_from, message_to, message_value, COALESCE(message_nonce, 0), message_data, merkle_proof, batch_index,
+    created_at, updated_at, deleted_at
+FROM cross_message_v2_unpartitioned;
+ALTER SEQUENCE cross_message_v2_id_seq OWNED BY cross_message_v2.id;
+DROP TABLE cross_message_v2_unpartitioned;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cm_message_hash_message_type_message_nonce ON cross_message_v2 (message_hash, message_type, message_nonce);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_l1_block_number ON cross_message_v2 (message_type, l1_block_number DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_l2_block_number ON cross_message_v2 (message_type, l2_block_number DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_rollup_status_message_nonce ON cross_message_v2 (message_type, rollup_status, message_nonce DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_message_nonce_tx_status_l2_block_number ON cross_message_v2 (message_type, message_nonce, tx_status, l2_block_number);
+CREATE INDEX IF NOT EXISTS idx_cm_l1_tx_hash ON cross_message_v2 (l1_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_cm_l2_tx_hash ON cross_message_v2 (l2_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_tx_status_sender_block_timestamp ON cross_message_v2 (message_type, tx_status, sender, block_timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_sender_block_timestamp ON cross_message_v2 (message_type, sender, block_timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_sender_block_timestamp ON cross_message_v2 (sender, block_timestamp DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2 RENAME TO cross_message_v2_partitioned;
+
+CREATE TABLE cross_message_v2 (LIKE cross_message_v2_partitioned INCLUDING DEFAULTS);
+ALTER TABLE cross_message_v2 ALTER COLUMN message_nonce DROP NOT NULL, ALTER COLUMN message_nonce SET DEFAULT NULL;
+INSERT INTO cross_message_v2 (id, message_type, tx_status, rollup_status, token_type, sender, receiver,
+    message_hash, l1_tx_hash, l1_replay_tx_hash, l1_refund_tx_hash, l2_tx_hash, l1_block_number, l2_block_number,
+    l1_token_address, l2_token_address, token_ids, token_amounts, block_timestamp,
+    message_from, message_to, message_value, message_nonce, message_data, merkle_proof, batch_index,
+    created_at, updated_at, deleted_at)
+SELECT id, message_type, tx_status, rollup_status, token_type, sender, receiver,
+    message_hash, l1_tx_hash, l1_replay_tx_hash, l1_refund_tx_hash, l2_tx_hash, l1_block_number, l2_block_number,
+    l1_token_address, l2_token_address, token_ids, token_amounts, block_timestamp,
+    message_from, message_to, message_value, message_nonce, message_data, merkle_proof, batch_index,
+    created_at, updated_at, deleted_at
+FROM cross_message_v2_partitioned;
+ALTER SEQUENCE cross_message_v2_id_seq OWNED BY cross_message_v2.id;
+DROP TABLE cross_message_v2_partitioned;
+
+ALTER TABLE cross_message_v2 ADD PRIMARY KEY (id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cm_message_hash ON cross_message_v2 (message_hash);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_l1_block_number ON cross_message_v2 (message_type, l1_block_number DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_l2_block_number ON cross_message_v2 (message_type, l2_block_number DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_rollup_status_message_nonce ON cross_message_v2 (message_type, rollup_status, message_nonce DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_message_nonce_tx_status_l2_block_number ON cross_message_v2 (message_type, message_nonce, tx_status, l2_block_number);
+CREATE INDEX IF NOT EXISTS idx_cm_l1_tx_hash ON cross_message_v2 (l1_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_cm_l2_tx_hash ON cross_message_v2 (l2_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_tx_status_sender_block_timestamp ON cross_message_v2 (message_type, tx_status, sender, block_timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_message_type_sender_block_timestamp ON cross_message_v2 (message_type, sender, block_timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_cm_sender_block_timestamp ON cross_message_v2 (sender, block_timestamp DESC);
+-- +goose StatementEnd
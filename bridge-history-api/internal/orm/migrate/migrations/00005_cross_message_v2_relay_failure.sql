@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Decoded revert reasons of failed relays of L1 messages on L2.
+ALTER TABLE cross_message_v2
+    ADD COLUMN l2_relay_failure_selector VARCHAR DEFAULT NULL,
+    ADD COLUMN l2_relay_failure_reason   VARCHAR DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS l2_relay_failure_selector,
+    DROP COLUMN IF EXISTS l2_relay_failure_reason;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Gas used and effective gas price of the L1 tx, i.e., the deposit tx of L1 messages and the claim tx of L2 messages.
+ALTER TABLE cross_message_v2
+    ADD COLUMN l1_tx_gas_used            BIGINT  DEFAULT NULL,
+    ADD COLUMN l1_tx_effective_gas_price VARCHAR DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS l1_tx_gas_used,
+    DROP COLUMN IF EXISTS l1_tx_effective_gas_price;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose NO TRANSACTION
+-- The backfill commits batch by batch, which can not run inside the transaction goose wraps migrations in by default.
+
+-- +goose Up
+-- +goose StatementBegin
+-- Numeric copies of message_value and token_amounts, so values can be range-filtered and summed in SQL.
+-- token_amounts_numeric is the total of the token amounts of the message, i.e., the amount for eth and erc20.
+-- It is NULL for erc1155 batches of several token ids, whose amounts are of different assets.
+ALTER TABLE cross_message_v2
+    ADD COLUMN IF NOT EXISTS message_value_numeric NUMERIC(78, 0) DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS token_amounts_numeric NUMERIC(78, 0) DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Backfill by ranges of ids and commit each range, so that the migration neither locks all rows of the table
+-- until it is done nor rewrites the whole table in a single transaction. It is safe to rerun after an interruption.
+DO $$
+DECLARE
+    batch_size  CONSTANT BIGINT := 10000;
+    batch_start BIGINT;
+    max_id      BIGINT;
+BEGIN
+    SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), -1) INTO batch_start, max_id FROM cross_message_v2;
+    WHILE batch_start <= max_id LOOP
+        UPDATE cross_message_v2
+        SET message_value_numeric = message_value::NUMERIC(78, 0)
+        WHERE id >= batch_start AND id < batch_start + batch_size
+          AND message_value_numeric IS NULL
+          AND message_value ~ '^[0-9]{1,78}$';
+
+        UPDATE cross_message_v2
+        SET token_amounts_numeric = (
+            SELECT SUM(TRIM(amount)::NUMERIC(78, 0))
+            FROM unnest(string_to_array(token_amounts, ',')) AS amount
+        )
+        WHERE id >= batch_start AND id < batch_start + batch_size
+          AND token_amounts_numeric IS NULL
+          AND token_amounts ~ '^ *[0-9]{1,78} *(, *[0-9]{1,78} *)*$'
+          AND (COALESCE(token_ids, '') = '' OR (
+              SELECT COUNT(DISTINCT TRIM(token_id))
+              FROM unnest(string_to_array(token_ids, ',')) AS token_id
+          ) = 1);
+
+        COMMIT;
+        batch_start := batch_start + batch_size;
+    END LOOP;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_cm_sender_token_amounts_numeric ON cross_message_v2 (sender, token_amounts_numeric);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_cm_sender_token_amounts_numeric;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS message_value_numeric,
+    DROP COLUMN IF EXISTS token_amounts_numeric;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Call data attached to depositETHAndCall and depositERC20AndCall, split into the selector and the hex encoded arguments.
+ALTER TABLE cross_message_v2
+    ADD COLUMN deposit_call_selector VARCHAR DEFAULT NULL,
+    ADD COLUMN deposit_call_data     TEXT    DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS deposit_call_selector,
+    DROP COLUMN IF EXISTS deposit_call_data;
+-- +goose StatementEnd
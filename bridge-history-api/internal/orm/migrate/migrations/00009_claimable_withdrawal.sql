@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Finalized and not yet relayed L2 withdrawals, maintained by the ORM on batch finalization and relay,
+-- so that the unclaimed withdrawals of a sender are served without scanning the status columns of cross_message_v2.
+CREATE TABLE claimable_withdrawal
+(
+    id                  BIGSERIAL    PRIMARY KEY,
+    message_hash        VARCHAR      NOT NULL,
+    sender              VARCHAR      NOT NULL,
+    block_timestamp     BIGINT       NOT NULL,
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cw_message_hash ON claimable_withdrawal (message_hash);
+CREATE INDEX IF NOT EXISTS idx_cw_sender_block_timestamp ON claimable_withdrawal (sender, block_timestamp DESC);
+
+-- tx_status: 0 sent, 3 failed relayed, 4 relay tx reverted; rollup_status: 1 finalized.
+INSERT INTO claimable_withdrawal (message_hash, sender, block_timestamp)
+SELECT message_hash, sender, block_timestamp FROM cross_message_v2
+WHERE message_type = 2 AND rollup_status = 1 AND tx_status IN (0, 3, 4) AND message_hash IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS claimable_withdrawal;
+-- +goose StatementEnd
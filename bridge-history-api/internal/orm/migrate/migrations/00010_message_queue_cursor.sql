@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cursors of the L1 message queue after each L1 block with QueueTransaction or DequeueTransaction events,
+-- kept per block so that re-fetched blocks, e.g. after a reorg, rewind them.
+CREATE TABLE message_queue_cursor
+(
+    l1_block_number     BIGINT       PRIMARY KEY,
+    next_queue_index    BIGINT       NOT NULL DEFAULT 0, -- index of the next message appended to the queue
+    pending_queue_index BIGINT       NOT NULL DEFAULT 0, -- index of the first message not popped by a committed batch
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS message_queue_cursor;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The L1 fee vault emits no refund event, the fee refund columns added by a former migration 00004 are never filled.
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS l1_fee_refund_tx_hash,
+    DROP COLUMN IF EXISTS l1_fee_refund_amount;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
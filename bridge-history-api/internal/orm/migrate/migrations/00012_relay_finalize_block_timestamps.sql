@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Block timestamps of the relay of L1 messages on L2 and of the finalization of batches on L1, 0 if indexed before.
+ALTER TABLE cross_message_v2
+    ADD COLUMN l2_relay_block_timestamp BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE batch_event_v2
+    ADD COLUMN finalize_block_timestamp BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS l2_relay_block_timestamp;
+ALTER TABLE batch_event_v2
+    DROP COLUMN IF EXISTS finalize_block_timestamp;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sender of the claim tx of L2 messages relayed on L1, the user itself or a relayer claiming on its behalf.
+ALTER TABLE cross_message_v2
+    ADD COLUMN claimed_by VARCHAR DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS claimed_by;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Status changes of L1 messages made by DequeueTransaction (skipped) and DropTransaction (dropped) events, with the
+-- status they replaced, so that re-fetched blocks, e.g. after a reorg, roll back the changes of their events.
+CREATE TABLE message_queue_effect
+(
+    id                       BIGSERIAL    PRIMARY KEY,
+    queue_index              BIGINT       NOT NULL,
+    event_type               SMALLINT     NOT NULL,
+    l1_block_number          BIGINT       NOT NULL,
+    tx_status                SMALLINT     NOT NULL, -- status set by the event
+    prior_tx_status          SMALLINT     NOT NULL,
+    prior_l1_refund_tx_hash  VARCHAR      DEFAULT NULL,
+    created_at               TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_message_queue_effect_l1_block_number ON message_queue_effect (l1_block_number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS message_queue_effect;
+-- +goose StatementEnd
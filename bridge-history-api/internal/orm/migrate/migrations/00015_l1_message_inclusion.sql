@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- L1 messages executed on L2, indexed from the L1 message txs of the L2 blocks, so that deposits are matched to their
+-- L2 execution even when no relayed message event of theirs is indexed.
+CREATE TABLE l1_message_inclusion
+(
+    queue_index         BIGINT       PRIMARY KEY,
+    message_hash        VARCHAR      NOT NULL,
+    message_nonce       BIGINT       NOT NULL DEFAULT 0, -- 0 if the L1 message is not a relayMessage call, e.g. an enforced tx
+    l2_tx_hash          VARCHAR      NOT NULL,
+    l2_block_number     BIGINT       NOT NULL,
+    l2_block_timestamp  BIGINT       NOT NULL,
+    tx_status           SMALLINT     NOT NULL, -- status of the message according to the receipt of the L1 message tx
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_l1_message_inclusion_message_hash ON l1_message_inclusion (message_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS l1_message_inclusion;
+-- +goose StatementEnd
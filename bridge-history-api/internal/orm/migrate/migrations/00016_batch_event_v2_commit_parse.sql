@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Result of decoding the commit tx of a batch, its L2 block range is only known if it is parsed. The batches indexed
+-- before are parsed, their commit tx hash is unknown.
+ALTER TABLE batch_event_v2
+    ADD COLUMN commit_tx_hash      VARCHAR  NOT NULL DEFAULT '',
+    ADD COLUMN codec_version       SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN blob_versioned_hash VARCHAR  NOT NULL DEFAULT '', -- empty before codec v1
+    ADD COLUMN parse_status        SMALLINT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_be_parse_status ON batch_event_v2 (parse_status) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_be_parse_status;
+ALTER TABLE batch_event_v2
+    DROP COLUMN IF EXISTS commit_tx_hash,
+    DROP COLUMN IF EXISTS codec_version,
+    DROP COLUMN IF EXISTS blob_versioned_hash,
+    DROP COLUMN IF EXISTS parse_status;
+-- +goose StatementEnd
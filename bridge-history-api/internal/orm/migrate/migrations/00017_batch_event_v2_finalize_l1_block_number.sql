@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- L1 block of the finalize tx of a batch, 0 for the batches finalized before it was stored.
+ALTER TABLE batch_event_v2
+    ADD COLUMN finalize_l1_block_number BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE batch_event_v2
+    DROP COLUMN IF EXISTS finalize_l1_block_number;
+-- +goose StatementEnd
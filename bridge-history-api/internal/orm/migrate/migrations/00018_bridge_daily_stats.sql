@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Daily aggregates of the sent messages by UTC day of their source block, recomputed from cross_message_v2 by the
+-- stats aggregator of the fetcher and served to public dashboards. Messages whose source tx reverted are excluded.
+CREATE TABLE token_daily_stats
+(
+    day                 DATE           NOT NULL,
+    message_type        SMALLINT       NOT NULL,
+    token_type          SMALLINT       NOT NULL,
+    l1_token_address    VARCHAR        NOT NULL,
+    l2_token_address    VARCHAR        NOT NULL,
+    tx_count            BIGINT         NOT NULL,
+    volume              NUMERIC(78, 0) NOT NULL, -- sum of the token amounts, 0 for NFTs
+    updated_at          TIMESTAMP(0)   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, message_type, token_type, l1_token_address)
+);
+
+CREATE TABLE gateway_daily_stats
+(
+    day                 DATE           NOT NULL,
+    message_type        SMALLINT       NOT NULL,
+    gateway             VARCHAR        NOT NULL, -- the gateway address, 'messenger' for direct messenger calls, 'unknown' if not indexed
+    tx_count            BIGINT         NOT NULL,
+    updated_at          TIMESTAMP(0)   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, message_type, gateway)
+);
+
+CREATE TABLE bridger_daily_stats
+(
+    day                 DATE           PRIMARY KEY,
+    deposit_bridgers    BIGINT         NOT NULL, -- distinct senders of deposits
+    withdrawal_bridgers BIGINT         NOT NULL, -- distinct senders of withdrawals
+    unique_bridgers     BIGINT         NOT NULL, -- distinct senders of deposits or withdrawals
+    updated_at          TIMESTAMP(0)   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS token_daily_stats;
+DROP TABLE IF EXISTS gateway_daily_stats;
+DROP TABLE IF EXISTS bridger_daily_stats;
+-- +goose StatementEnd
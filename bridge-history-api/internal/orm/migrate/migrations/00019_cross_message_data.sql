@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Message data longer than the offload threshold of the fetcher, e.g. of contract calls, moved out of the hot
+-- cross_message_v2 table and loaded lazily for the few reads needing it, i.e. the claim info of withdrawals.
+CREATE TABLE cross_message_data
+(
+    message_hash        VARCHAR      PRIMARY KEY,
+    message_data        VARCHAR      NOT NULL,
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- message_data of cross_message_v2 is empty if it is offloaded.
+ALTER TABLE cross_message_v2
+    ADD COLUMN message_data_offloaded BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE cross_message_v2 SET message_data = d.message_data, message_data_offloaded = FALSE
+FROM cross_message_data d WHERE cross_message_v2.message_hash = d.message_hash AND cross_message_v2.message_data_offloaded;
+
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS message_data_offloaded;
+
+DROP TABLE IF EXISTS cross_message_data;
+-- +goose StatementEnd
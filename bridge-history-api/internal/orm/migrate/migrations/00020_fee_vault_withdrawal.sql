@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Withdrawals of the L2 fee vaults, i.e. the protocol revenue bridged to L1, indexed from their Withdrawal events so
+-- that treasury dashboards track the fee flows apart from the user withdrawals. The L2 message sending the fees is
+-- indexed in cross_message_v2 as well, by message_hash.
+CREATE TABLE fee_vault_withdrawal
+(
+    l2_tx_hash          VARCHAR        NOT NULL,
+    log_index           INTEGER        NOT NULL,
+    vault_address       VARCHAR        NOT NULL,
+    value               NUMERIC(78, 0) NOT NULL, -- withdrawn amount in wei
+    recipient           VARCHAR        NOT NULL, -- L1 address receiving the fees
+    triggered_by        VARCHAR        NOT NULL, -- L2 address calling withdraw
+    message_hash        VARCHAR        NOT NULL DEFAULT '', -- empty if the sent message of the withdrawal is not indexed
+    l2_block_number     BIGINT         NOT NULL,
+    block_timestamp     BIGINT         NOT NULL,
+    updated_at          TIMESTAMP(0)   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (l2_tx_hash, log_index)
+);
+
+CREATE INDEX idx_fee_vault_withdrawal_vault_block ON fee_vault_withdrawal (vault_address, l2_block_number DESC);
+CREATE INDEX idx_fee_vault_withdrawal_block ON fee_vault_withdrawal (l2_block_number DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS fee_vault_withdrawal;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Block timestamp of the commit tx and hash of the finalize tx of a batch, unset for the batches indexed before.
+ALTER TABLE batch_event_v2
+    ADD COLUMN commit_block_timestamp BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN finalize_tx_hash VARCHAR NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE batch_event_v2
+    DROP COLUMN IF EXISTS commit_block_timestamp,
+    DROP COLUMN IF EXISTS finalize_tx_hash;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deposits seen in the txpool of a trusted L1 node before they are mined, so that UIs show them right away. A pending
+-- deposit is removed once its tx is indexed in cross_message_v2, or once it left the txpool without being indexed,
+-- e.g. it was replaced or dropped.
+CREATE TABLE pending_deposit
+(
+    tx_hash             VARCHAR        NOT NULL PRIMARY KEY,
+    sender              VARCHAR        NOT NULL,
+    receiver            VARCHAR        NOT NULL,
+    contract_address    VARCHAR        NOT NULL, -- the gateway, router or messenger the tx calls
+    token_type          SMALLINT       NOT NULL,
+    l1_token_address    VARCHAR        NOT NULL DEFAULT '', -- empty for eth
+    token_ids           VARCHAR        NOT NULL DEFAULT '', -- only for erc721 and erc1155
+    token_amounts       VARCHAR        NOT NULL DEFAULT '',
+    first_seen_at       TIMESTAMP(0)   NOT NULL,
+    last_seen_at        TIMESTAMP(0)   NOT NULL
+);
+
+CREATE INDEX idx_pending_deposit_sender ON pending_deposit (sender);
+CREATE INDEX idx_pending_deposit_receiver ON pending_deposit (receiver);
+CREATE INDEX idx_pending_deposit_last_seen_at ON pending_deposit (last_seen_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pending_deposit;
+-- +goose StatementEnd
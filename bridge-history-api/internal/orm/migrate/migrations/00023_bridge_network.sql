@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The chain ids of the L1 and L2 networks the data was indexed from, recorded by the first fetcher started on the
+-- database, so that the services refuse to start against another network. It holds a single row.
+CREATE TABLE bridge_network
+(
+    id              SMALLINT       NOT NULL PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    l1_chain_id     BIGINT         NOT NULL,
+    l2_chain_id     BIGINT         NOT NULL,
+    created_at      TIMESTAMP(0)   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bridge_network;
+-- +goose StatementEnd
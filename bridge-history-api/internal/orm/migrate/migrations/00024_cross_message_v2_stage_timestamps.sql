@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Block timestamp of the relay on L1 of L2 messages, and unix time the L2 withdrawals were indexed as claimable, 0 if
+-- indexed before.
+ALTER TABLE cross_message_v2
+    ADD COLUMN l1_relay_block_timestamp BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN claimable_timestamp BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_v2
+    DROP COLUMN IF EXISTS l1_relay_block_timestamp,
+    DROP COLUMN IF EXISTS claimable_timestamp;
+-- +goose StatementEnd
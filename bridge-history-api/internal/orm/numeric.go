@@ -0,0 +1,114 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BigInt is a big integer stored in a NUMERIC(78,0) column, wide enough for any uint256 value.
+// A nil Int is stored as NULL.
+type BigInt struct {
+	*big.Int
+}
+
+// NewBigInt returns a BigInt holding x.
+func NewBigInt(x *big.Int) BigInt {
+	return BigInt{Int: x}
+}
+
+// GormDataType returns the data type of the column.
+func (BigInt) GormDataType() string {
+	return "numeric"
+}
+
+// Value implements the driver.Valuer interface.
+func (b BigInt) Value() (driver.Value, error) {
+	if b.Int == nil {
+		return nil, nil
+	}
+	return b.Int.String(), nil
+}
+
+// NumericValue implements the pgtype.NumericValuer interface, the COPY of BulkLoader encodes the values in binary.
+func (b BigInt) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: b.Int, Valid: b.Int != nil}, nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *BigInt) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		b.Int = nil
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		b.Int = big.NewInt(v)
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %T for BigInt", src)
+	}
+	// Aggregates of NUMERIC(78,0) columns keep a zero scale, but strip a fractional part defensively.
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s = s[:i]
+	}
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid numeric value %q", s)
+	}
+	b.Int = x
+	return nil
+}
+
+// parseAmount parses a decimal amount string, it returns nil if the string is not a valid non-negative integer.
+func parseAmount(s string) *big.Int {
+	x, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok || x.Sign() < 0 {
+		return nil
+	}
+	return x
+}
+
+// sumAmounts returns the total of the comma separated decimal amounts of TokenAmounts, or nil if the string is empty
+// or holds an invalid amount. tokenIDs holds the matching token ids of erc721 and erc1155 transfers: amounts of
+// different erc1155 token ids are amounts of different assets and have no meaningful total, so nil is returned
+// unless all amounts are of the same token id.
+func sumAmounts(tokenIDs, tokenAmounts string) *big.Int {
+	if tokenAmounts == "" {
+		return nil
+	}
+	amounts := strings.Split(tokenAmounts, ",")
+	if tokenIDs != "" {
+		ids := strings.Split(tokenIDs, ",")
+		for _, id := range ids[1:] {
+			if strings.TrimSpace(id) != strings.TrimSpace(ids[0]) {
+				return nil
+			}
+		}
+	}
+	sum := new(big.Int)
+	for _, part := range amounts {
+		amount := parseAmount(part)
+		if amount == nil {
+			return nil
+		}
+		sum.Add(sum, amount)
+	}
+	return sum
+}
+
+// setNumericAmounts fills the numeric columns of messages from their string amounts.
+// During the transition the string columns stay the source of truth, the numeric columns are written alongside them.
+func setNumericAmounts(messages []*CrossMessage) {
+	for _, message := range messages {
+		message.MessageValueNumeric = NewBigInt(parseAmount(message.MessageValue))
+		message.TokenAmountsNumeric = NewBigInt(sumAmounts(message.TokenIDs, message.TokenAmounts))
+	}
+}
@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigIntValueScan(t *testing.T) {
+	value, err := BigInt{}.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	value, err = NewBigInt(maxUint256).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, maxUint256.String(), value)
+
+	var b BigInt
+	assert.NoError(t, b.Scan([]byte(maxUint256.String())))
+	assert.Equal(t, maxUint256, b.Int)
+	assert.NoError(t, b.Scan("42.000"))
+	assert.Equal(t, big.NewInt(42), b.Int)
+	assert.NoError(t, b.Scan(int64(7)))
+	assert.Equal(t, big.NewInt(7), b.Int)
+	assert.NoError(t, b.Scan(nil))
+	assert.Nil(t, b.Int)
+	assert.Error(t, b.Scan("abc"))
+}
+
+func TestSetNumericAmounts(t *testing.T) {
+	messages := []*CrossMessage{
+		{MessageValue: "100", TokenAmounts: "100"},
+		{TokenAmounts: "1, 2, 3"},
+		{MessageValue: "-1", TokenAmounts: "1, x"},
+		{},
+		{TokenIDs: "7, 7", TokenAmounts: "2, 3"},
+		{TokenIDs: "7, 8", TokenAmounts: "2, 3"},
+		{TokenIDs: "7"},
+	}
+	setNumericAmounts(messages)
+
+	assert.Equal(t, big.NewInt(100), messages[0].MessageValueNumeric.Int)
+	assert.Equal(t, big.NewInt(100), messages[0].TokenAmountsNumeric.Int)
+	assert.Nil(t, messages[1].MessageValueNumeric.Int)
+	assert.Equal(t, big.NewInt(6), messages[1].TokenAmountsNumeric.Int)
+	assert.Nil(t, messages[2].MessageValueNumeric.Int)
+	assert.Nil(t, messages[2].TokenAmountsNumeric.Int)
+	assert.Nil(t, messages[3].MessageValueNumeric.Int)
+	assert.Nil(t, messages[3].TokenAmountsNumeric.Int)
+	// erc1155 amounts are only totalled when they are of the same token id.
+	assert.Equal(t, big.NewInt(5), messages[4].TokenAmountsNumeric.Int)
+	assert.Nil(t, messages[5].TokenAmountsNumeric.Int)
+	// erc721 transfers have no amounts.
+	assert.Nil(t, messages[6].TokenAmountsNumeric.Int)
+}
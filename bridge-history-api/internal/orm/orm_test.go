@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+var (
+	base *docker.App
+
+	db *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	t := &testing.T{}
+	setupEnv(t)
+	defer tearDownEnv(t)
+	m.Run()
+}
+
+func setupEnv(t *testing.T) {
+	base = docker.NewDockerApp()
+	base.RunDBImage(t)
+	var err error
+	db, err = database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	assert.NoError(t, err)
+	resetDB(t)
+}
+
+func tearDownEnv(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.Close()
+	base.Free()
+}
+
+func resetDB(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+}
+
+func TestMigrateDropsFeeRefundColumns(t *testing.T) {
+	defer resetDB(t)
+
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	version := int64(10)
+	assert.NoError(t, migrate.Rollback(sqlDB, &version))
+
+	// databases migrated with the former migration 00004 have the fee refund columns.
+	err = db.Exec(`ALTER TABLE cross_message_v2
+		ADD COLUMN l1_fee_refund_tx_hash VARCHAR DEFAULT NULL,
+		ADD COLUMN l1_fee_refund_amount VARCHAR DEFAULT NULL`).Error
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrate.Migrate(sqlDB))
+	assert.False(t, db.Migrator().HasColumn(&CrossMessage{}, "l1_fee_refund_tx_hash"))
+	assert.False(t, db.Migrator().HasColumn(&CrossMessage{}, "l1_fee_refund_amount"))
+}
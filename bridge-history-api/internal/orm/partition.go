@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+)
+
+// crossMessageNoncePartitionSize is the number of message nonces held by a range partition of a message type,
+// it must match the range size of migration 00003.
+const crossMessageNoncePartitionSize = 1000000
+
+// crossMessagePartitions maps each message type partition of cross_message_v2 to its message type, the partitions
+// are split in ranges of message nonces. Rows of any other message type land in the default partition.
+var crossMessagePartitions = map[string]MessageType{
+	"cross_message_v2_l1_sent": MessageTypeL1SentMessage,
+	"cross_message_v2_l2_sent": MessageTypeL2SentMessage,
+}
+
+// crossMessageDefaultPartition is the name of the default partition of cross_message_v2.
+const crossMessageDefaultPartition = "cross_message_v2_default"
+
+// PartitionStat holds the planner statistics of a table partition.
+type PartitionStat struct {
+	Name           string `gorm:"column:name"`
+	EstimatedRows  int64  `gorm:"column:estimated_rows"`
+	TotalSizeBytes int64  `gorm:"column:total_size_bytes"`
+}
+
+// Partition maintains the partitions of cross_message_v2.
+type Partition struct {
+	db *gorm.DB
+}
+
+// NewPartition returns a new instance of Partition.
+func NewPartition(db *gorm.DB) *Partition {
+	return &Partition{db: db}
+}
+
+// crossMessageNoncePartitionName returns the name of the i-th nonce range partition of a message type partition.
+func crossMessageNoncePartitionName(parent string, i uint64) string {
+	return fmt.Sprintf("%s_p%d", parent, i)
+}
+
+// EnsureCrossMessagePartitions creates the cross_message_v2 partitions which are missing: the nonce ranges up to
+// the one following the highest message nonce of each message type, so that new messages never land in the default
+// partitions, from which rows can only be moved out by hand.
+func (p *Partition) EnsureCrossMessagePartitions(ctx context.Context) error {
+	db := p.db.WithContext(ctx)
+	for name, messageType := range crossMessagePartitions {
+		sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES IN (%d) PARTITION BY RANGE (message_nonce)", name, (&CrossMessage{}).TableName(), messageType)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s, error: %w", name, err)
+		}
+		defaultName := name + "_default"
+		if err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s DEFAULT", defaultName, name)).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s, error: %w", defaultName, err)
+		}
+
+		var maxNonce uint64
+		if err := db.Model(&CrossMessage{}).Select("COALESCE(MAX(message_nonce), 0)").Where("message_type = ?", messageType).Scan(&maxNonce).Error; err != nil {
+			return fmt.Errorf("failed to get max message nonce of partition %s, error: %w", name, err)
+		}
+		for i := uint64(0); i <= maxNonce/crossMessageNoncePartitionSize+1; i++ {
+			rangeName := crossMessageNoncePartitionName(name, i)
+			sql = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)", rangeName, name, i*crossMessageNoncePartitionSize, (i+1)*crossMessageNoncePartitionSize)
+			if err := db.Exec(sql).Error; err != nil {
+				return fmt.Errorf("failed to create partition %s, error: %w", rangeName, err)
+			}
+		}
+	}
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s DEFAULT", crossMessageDefaultPartition, (&CrossMessage{}).TableName())
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to create partition %s, error: %w", crossMessageDefaultPartition, err)
+	}
+	return nil
+}
+
+// AnalyzeCrossMessagePartitions refreshes the planner statistics of every cross_message_v2 partition.
+// Autovacuum does not analyze the partitioned parent table, so the parent is analyzed explicitly as well.
+func (p *Partition) AnalyzeCrossMessagePartitions(ctx context.Context) error {
+	db := p.db.WithContext(ctx)
+	if err := db.Exec(fmt.Sprintf("ANALYZE %s", (&CrossMessage{}).TableName())).Error; err != nil {
+		return fmt.Errorf("failed to analyze cross message partitions, error: %w", err)
+	}
+	return nil
+}
+
+// GetCrossMessagePartitionStats returns the estimated row count and total size of every leaf partition of cross_message_v2.
+func (p *Partition) GetCrossMessagePartitionStats(ctx context.Context) ([]*PartitionStat, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var stats []*PartitionStat
+	db := p.db.WithContext(ctx)
+	db = db.Raw(`SELECT c.relname AS name, c.reltuples::BIGINT AS estimated_rows, pg_total_relation_size(c.oid) AS total_size_bytes
+		FROM pg_partition_tree(?::regclass) t
+		JOIN pg_class c ON c.oid = t.relid
+		WHERE t.isleaf`, (&CrossMessage{}).TableName())
+	if err := db.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get cross message partition stats, error: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,97 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// explain returns the plan of a query on cross_message_v2 with the given condition.
+func explain(t *testing.T, condition string, args ...interface{}) string {
+	var lines []string
+	assert.NoError(t, db.Raw("EXPLAIN SELECT id FROM cross_message_v2 WHERE "+condition, args...).Scan(&lines).Error)
+	return strings.Join(lines, "\n")
+}
+
+func TestCrossMessagePartitionPruning(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+	partitionOrm := NewPartition(db)
+
+	messages := []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, Sender: "0xa", TokenAmounts: "1"},
+		{MessageHash: "0x02", MessageType: int(MessageTypeL1SentMessage), MessageNonce: crossMessageNoncePartitionSize + 1, Sender: "0xa", TokenAmounts: "1"},
+	}
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, messages))
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x03", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, Sender: "0xa", TokenAmounts: "1"},
+	}))
+
+	// the migration creates the range of the highest nonce and the next one, the maintainer keeps one range ahead.
+	assert.NoError(t, partitionOrm.EnsureCrossMessagePartitions(ctx))
+	stats, err := partitionOrm.GetCrossMessagePartitionStats(ctx)
+	assert.NoError(t, err)
+	names := make(map[string]bool)
+	for _, stat := range stats {
+		names[stat.Name] = true
+	}
+	for _, name := range []string{"cross_message_v2_l1_sent_p0", "cross_message_v2_l1_sent_p1", "cross_message_v2_l1_sent_p2", "cross_message_v2_l2_sent_p0", "cross_message_v2_l2_sent_p1"} {
+		assert.True(t, names[name], name)
+	}
+
+	var count int64
+	assert.NoError(t, db.Table("cross_message_v2_l1_sent_p1").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, db.Table("cross_message_v2_l1_sent_default").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	// a message type and nonce prune to a single range partition.
+	plan := explain(t, "message_type = ? AND message_nonce = ?", MessageTypeL1SentMessage, 1)
+	assert.Contains(t, plan, "cross_message_v2_l1_sent_p0")
+	assert.NotContains(t, plan, "cross_message_v2_l1_sent_p1")
+	assert.NotContains(t, plan, "cross_message_v2_l2_sent")
+	assert.NotContains(t, plan, "cross_message_v2_default")
+
+	// a message type prunes the partitions of the other message types.
+	plan = explain(t, "message_type = ? AND sender = ?", MessageTypeL2SentMessage, "0xa")
+	assert.Contains(t, plan, "cross_message_v2_l2_sent_p0")
+	assert.NotContains(t, plan, "cross_message_v2_l1_sent")
+
+	// a nonce range prunes the ranges out of it.
+	plan = explain(t, fmt.Sprintf("message_type = %d AND message_nonce >= %d", MessageTypeL1SentMessage, crossMessageNoncePartitionSize))
+	assert.NotContains(t, plan, "cross_message_v2_l1_sent_p0")
+	assert.Contains(t, plan, "cross_message_v2_l1_sent_p1")
+}
+
+func TestRelayedMessageBeforeSentMessage(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	// the relayed message is indexed first, with the nonce decoded from the relay tx.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 7, L2TxHash: "0xb", TxStatus: int(TxStatusTypeRelayed)},
+	}))
+	nonces, err := crossMessageOrm.GetMessageNoncesByHashes(ctx, []string{"0x01"})
+	assert.NoError(t, err)
+	assert.Empty(t, nonces)
+
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 7, Sender: "0xa", L1TxHash: "0xc", TokenAmounts: "1", TxStatus: int(TxStatusTypeSent)},
+	}))
+
+	var messages []*CrossMessage
+	assert.NoError(t, db.Where("message_hash = ?", "0x01").Find(&messages).Error)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "0xa", messages[0].Sender)
+	assert.Equal(t, "0xb", messages[0].L2TxHash)
+	assert.Equal(t, int(TxStatusTypeRelayed), messages[0].TxStatus)
+
+	nonces, err = crossMessageOrm.GetMessageNoncesByHashes(ctx, []string{"0x01"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"0x01": 7}, nonces)
+}
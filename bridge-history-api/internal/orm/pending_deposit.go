@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
+)
+
+// PendingDeposit is a deposit tx seen in the txpool of an L1 node, not indexed in cross_message_v2 yet.
+type PendingDeposit struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	TxHash          string    `json:"tx_hash" gorm:"column:tx_hash;primary_key"`
+	Sender          string    `json:"sender" gorm:"column:sender"`
+	Receiver        string    `json:"receiver" gorm:"column:receiver"`
+	ContractAddress string    `json:"contract_address" gorm:"column:contract_address"`
+	TokenType       int       `json:"token_type" gorm:"column:token_type"`
+	L1TokenAddress  string    `json:"l1_token_address" gorm:"column:l1_token_address"`
+	TokenIDs        string    `json:"token_ids" gorm:"column:token_ids"`
+	TokenAmounts    string    `json:"token_amounts" gorm:"column:token_amounts"`
+	FirstSeenAt     time.Time `json:"first_seen_at" gorm:"column:first_seen_at"`
+	LastSeenAt      time.Time `json:"last_seen_at" gorm:"column:last_seen_at"`
+}
+
+// TableName returns the table name for the PendingDeposit model.
+func (*PendingDeposit) TableName() string {
+	return "pending_deposit"
+}
+
+// NewPendingDeposit returns a new instance of PendingDeposit.
+func NewPendingDeposit(db *gorm.DB) *PendingDeposit {
+	return &PendingDeposit{db: db}
+}
+
+// GetPendingDepositsByAddress returns the pending deposits sent by or to an address, latest first. The deposits
+// already indexed are left out, also before the txpool watcher removes them.
+func (p *PendingDeposit) GetPendingDepositsByAddress(ctx context.Context, address string, limit int) ([]*PendingDeposit, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	var deposits []*PendingDeposit
+	db := p.db.WithContext(ctx)
+	db = db.Model(&PendingDeposit{})
+	db = db.Where("sender = ? OR receiver = ?", address, address)
+	db = db.Where("NOT EXISTS (SELECT 1 FROM cross_message_v2 AS cm WHERE cm.l1_tx_hash = pending_deposit.tx_hash AND cm.message_type = ? AND cm.deleted_at IS NULL)", MessageTypeL1SentMessage)
+	db = db.Order("first_seen_at DESC")
+	db = db.Limit(limit)
+	if err := db.Find(&deposits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending deposits by address, address: %v, error: %w", address, err)
+	}
+	return deposits, nil
+}
+
+// InsertOrUpdatePendingDeposits inserts the deposits seen in the txpool, the ones already seen keep their first sighting
+// and are seen again at their last_seen_at.
+func (p *PendingDeposit) InsertOrUpdatePendingDeposits(ctx context.Context, deposits []*PendingDeposit) error {
+	if len(deposits) == 0 {
+		return nil
+	}
+	db := p.db.WithContext(ctx)
+	db = db.Model(&PendingDeposit{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	})
+	if err := db.Create(deposits).Error; err != nil {
+		return fmt.Errorf("failed to insert or update pending deposits, error: %w", err)
+	}
+	return nil
+}
+
+// DeleteIndexedPendingDeposits removes the pending deposits whose tx is indexed in cross_message_v2, and returns their
+// number.
+func (p *PendingDeposit) DeleteIndexedPendingDeposits(ctx context.Context) (int64, error) {
+	db := p.db.WithContext(ctx)
+	db = db.Where("EXISTS (SELECT 1 FROM cross_message_v2 AS cm WHERE cm.l1_tx_hash = pending_deposit.tx_hash AND cm.message_type = ? AND cm.deleted_at IS NULL)", MessageTypeL1SentMessage)
+	result := db.Delete(&PendingDeposit{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete indexed pending deposits, error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeletePendingDepositsSeenBefore removes the pending deposits last seen in the txpool before the given time, i.e.
+// replaced, dropped or mined without being indexed, and returns their number.
+func (p *PendingDeposit) DeletePendingDepositsSeenBefore(ctx context.Context, before time.Time) (int64, error) {
+	db := p.db.WithContext(ctx)
+	db = db.Where("last_seen_at < ?", before)
+	result := db.Delete(&PendingDeposit{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete pending deposits seen before %v, error: %w", before, result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,59 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingDepositOrm(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	pendingDepositOrm := NewPendingDeposit(db)
+	crossMessageOrm := NewCrossMessage(db)
+
+	seenAt := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, pendingDepositOrm.InsertOrUpdatePendingDeposits(ctx, []*PendingDeposit{
+		{TxHash: "0xt1", Sender: "0xa1", Receiver: "0xa1", TokenType: int(TokenTypeETH), TokenAmounts: "100", FirstSeenAt: seenAt, LastSeenAt: seenAt},
+		{TxHash: "0xt2", Sender: "0xa1", Receiver: "0xa2", TokenType: int(TokenTypeERC20), L1TokenAddress: "0xe1", TokenAmounts: "200", FirstSeenAt: seenAt.Add(time.Second), LastSeenAt: seenAt.Add(time.Second)},
+		{TxHash: "0xt3", Sender: "0xa3", Receiver: "0xa3", TokenType: int(TokenTypeETH), TokenAmounts: "300", FirstSeenAt: seenAt, LastSeenAt: seenAt},
+	}))
+	// seen again, the first sighting is kept.
+	assert.NoError(t, pendingDepositOrm.InsertOrUpdatePendingDeposits(ctx, []*PendingDeposit{
+		{TxHash: "0xt1", Sender: "0xa1", Receiver: "0xa1", TokenType: int(TokenTypeETH), TokenAmounts: "100", FirstSeenAt: seenAt.Add(time.Minute), LastSeenAt: seenAt.Add(time.Minute)},
+	}))
+
+	deposits, err := pendingDepositOrm.GetPendingDepositsByAddress(ctx, "0xa1", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deposits, 2) {
+		assert.Equal(t, "0xt2", deposits[0].TxHash)
+		assert.Equal(t, "0xt1", deposits[1].TxHash)
+		assert.True(t, seenAt.Equal(deposits[1].FirstSeenAt))
+		assert.True(t, seenAt.Add(time.Minute).Equal(deposits[1].LastSeenAt))
+	}
+	deposits, err = pendingDepositOrm.GetPendingDepositsByAddress(ctx, "0xa2", 10)
+	assert.NoError(t, err)
+	assert.Len(t, deposits, 1)
+
+	// the deposit of 0xt2 is indexed, it is left out before being removed.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, []*CrossMessage{
+		{MessageHash: "0xm2", MessageType: int(MessageTypeL1SentMessage), MessageNonce: 1, L1TxHash: "0xt2", TxStatus: int(TxStatusTypeSent)},
+	}))
+	deposits, err = pendingDepositOrm.GetPendingDepositsByAddress(ctx, "0xa2", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deposits)
+	deleted, err := pendingDepositOrm.DeleteIndexedPendingDeposits(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = pendingDepositOrm.DeletePendingDepositsSeenBefore(ctx, seenAt.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	deposits, err = pendingDepositOrm.GetPendingDepositsByAddress(ctx, "0xa1", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deposits, 1) {
+		assert.Equal(t, "0xt1", deposits[0].TxHash)
+	}
+}
@@ -0,0 +1,89 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+const (
+	// ReindexSchema is the schema a full reindex is loaded into, the tables are swapped into public once it is done.
+	ReindexSchema = "bridge_history_reindex"
+	// PreReindexSchema holds the tables replaced by the last reindex, until they are dropped by hand.
+	PreReindexSchema = "bridge_history_pre_reindex"
+)
+
+// CreateReindexSchema creates the schema of a reindex, if it does not exist.
+func CreateReindexSchema(ctx context.Context, db *gorm.DB) error {
+	if err := db.WithContext(ctx).Exec("CREATE SCHEMA IF NOT EXISTS " + pgx.Identifier{ReindexSchema}.Sanitize()).Error; err != nil {
+		return fmt.Errorf("failed to create schema %s, error: %w", ReindexSchema, err)
+	}
+	return nil
+}
+
+// SwapReindexedTables replaces the tables of the current schema, i.e. public, by the reindexed ones in a single
+// transaction, the api serves either the old or the reindexed tables. The replaced tables, with their partitions, are
+// moved to PreReindexSchema, which must not exist, i.e. the tables replaced by a previous reindex must be dropped
+// first. The reindex schema is dropped afterwards. It returns the names of the swapped tables.
+func SwapReindexedTables(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var tables []string
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schema string
+		if err := tx.Raw("SELECT current_schema()").Scan(&schema).Error; err != nil {
+			return fmt.Errorf("failed to get the current schema, error: %w", err)
+		}
+		if err := tx.Exec("CREATE SCHEMA " + pgx.Identifier{PreReindexSchema}.Sanitize()).Error; err != nil {
+			return fmt.Errorf("failed to create schema %s, drop the tables replaced by the previous reindex first, error: %w", PreReindexSchema, err)
+		}
+
+		// the top level tables only, the partitions are moved with their parent.
+		sql := `SELECT c.relname FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = ? AND c.relkind IN ('r', 'p') AND NOT c.relispartition AND c.relname NOT IN (?)
+			ORDER BY c.relname`
+		if err := tx.Raw(sql, ReindexSchema, []string{migrate.TableName, relayedMessageStageTable}).Scan(&tables).Error; err != nil {
+			return fmt.Errorf("failed to list the reindexed tables, error: %w", err)
+		}
+		if len(tables) == 0 {
+			return fmt.Errorf("no reindexed table in schema %s", ReindexSchema)
+		}
+
+		for _, moves := range []struct{ from, to string }{{schema, PreReindexSchema}, {ReindexSchema, schema}} {
+			for _, table := range tables {
+				if err := moveTable(tx, moves.from, table, moves.to); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", pgx.Identifier{ReindexSchema}.Sanitize())).Error; err != nil {
+			return fmt.Errorf("failed to drop schema %s, error: %w", ReindexSchema, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// moveTable moves a table with its partitions, indexes and owned sequences from a schema to another, a missing table
+// is skipped, e.g. a table created by a migration newer than the replaced tables.
+func moveTable(tx *gorm.DB, from, table, to string) error {
+	var members []string
+	sql := `SELECT format('%I.%I', n.nspname, c.relname) FROM pg_partition_tree(to_regclass(?)) t
+		JOIN pg_class c ON c.oid = t.relid JOIN pg_namespace n ON n.oid = c.relnamespace
+		ORDER BY t.level DESC`
+	if err := tx.Raw(sql, pgx.Identifier{from, table}.Sanitize()).Scan(&members).Error; err != nil {
+		return fmt.Errorf("failed to list the partitions of table %s.%s, error: %w", from, table, err)
+	}
+	for _, member := range members {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s SET SCHEMA %s", member, pgx.Identifier{to}.Sanitize())).Error; err != nil {
+			return fmt.Errorf("failed to move table %s to schema %s, error: %w", member, to, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+func TestSwapReindexedTables(t *testing.T) {
+	resetDB(t)
+	defer func() {
+		assert.NoError(t, db.Exec("DROP SCHEMA IF EXISTS "+PreReindexSchema+" CASCADE").Error)
+		assert.NoError(t, db.Exec("DROP SCHEMA IF EXISTS "+ReindexSchema+" CASCADE").Error)
+		resetDB(t)
+	}()
+	ctx := context.Background()
+	assert.NoError(t, NewCrossMessage(db).InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0xold", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L2BlockNumber: 1},
+	}))
+
+	assert.NoError(t, CreateReindexSchema(ctx, db))
+	reindexDB, err := database.InitDB(&database.Config{DSN: base.DBConfig.DSN, DriverName: base.DBConfig.DriverName, SearchPath: ReindexSchema})
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, database.CloseDB(reindexDB)) }()
+	sqlDB, err := reindexDB.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.Migrate(sqlDB))
+	assert.NoError(t, NewPartition(reindexDB).EnsureCrossMessagePartitions(ctx))
+	assert.NoError(t, NewCrossMessage(reindexDB).InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0xnew", MessageType: int(MessageTypeL2SentMessage), MessageNonce: 1, L2BlockNumber: 1},
+	}))
+
+	tables, err := SwapReindexedTables(ctx, db)
+	assert.NoError(t, err)
+	assert.Contains(t, tables, (&CrossMessage{}).TableName())
+	assert.NotContains(t, tables, migrate.TableName)
+
+	var hashes []string
+	assert.NoError(t, db.Model(&CrossMessage{}).Pluck("message_hash", &hashes).Error)
+	assert.Equal(t, []string{"0xnew"}, hashes)
+	assert.NoError(t, db.Table(PreReindexSchema+".cross_message_v2").Pluck("message_hash", &hashes).Error)
+	assert.Equal(t, []string{"0xold"}, hashes)
+	// the partitions are swapped with their parent.
+	var schemas []string
+	assert.NoError(t, db.Raw("SELECT schemaname FROM pg_tables WHERE tablename = 'cross_message_v2_l2_sent_p0' ORDER BY schemaname").Scan(&schemas).Error)
+	assert.Equal(t, []string{PreReindexSchema, "public"}, schemas)
+
+	// the tables replaced by the previous reindex must be dropped first.
+	assert.NoError(t, CreateReindexSchema(ctx, db))
+	_, err = SwapReindexedTables(ctx, db)
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schemaModels are the models whose tables are checked against the live schema.
+var schemaModels = []interface{}{
+	&CrossMessage{},
+	&BatchEvent{},
+	&ClaimableWithdrawal{},
+	&MessageQueueCursor{},
+	&MessageQueueEffect{},
+	&L1MessageInclusion{},
+	&TokenDailyStat{},
+	&GatewayDailyStat{},
+	&BridgerDailyStat{},
+	&CrossMessageData{},
+	&FeeVaultWithdrawal{},
+	&BridgeNetwork{},
+}
+
+// schemaIndexes are the indexes the queries rely on, by table, as created by the migrations.
+var schemaIndexes = map[string][]string{
+	"cross_message_v2": {
+		"idx_cm_message_hash_message_type_message_nonce",
+		"idx_cm_message_type_l1_block_number",
+		"idx_cm_message_type_l2_block_number",
+		"idx_cm_message_type_rollup_status_message_nonce",
+		"idx_cm_message_type_message_nonce_tx_status_l2_block_number",
+		"idx_cm_l1_tx_hash",
+		"idx_cm_l2_tx_hash",
+		"idx_cm_message_type_tx_status_sender_block_timestamp",
+		"idx_cm_message_type_sender_block_timestamp",
+		"idx_cm_sender_block_timestamp",
+		"idx_cm_sender_token_amounts_numeric",
+	},
+	"batch_event_v2": {
+		"unique_idx_be_batch_hash",
+		"idx_be_l1_block_number",
+		"idx_be_batch_index",
+		"idx_be_batch_index_batch_hash",
+		"idx_be_end_block_number_update_status_batch_status_batch_index",
+		"idx_be_parse_status",
+	},
+	"claimable_withdrawal": {
+		"idx_cw_message_hash",
+		"idx_cw_sender_block_timestamp",
+	},
+	"message_queue_effect": {
+		"idx_message_queue_effect_l1_block_number",
+	},
+	"l1_message_inclusion": {
+		"idx_l1_message_inclusion_message_hash",
+	},
+}
+
+var (
+	integerColumnTypes   = []string{"smallint", "integer", "bigint", "numeric"}
+	stringColumnTypes    = []string{"character varying", "text", "character"}
+	timestampColumnTypes = []string{"timestamp without time zone", "timestamp with time zone", "date"}
+)
+
+// columnTypes returns the postgres data types a column of the go type can have, nil if any type is accepted.
+func columnTypes(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(BigInt{}):
+		return []string{"numeric"}
+	case reflect.TypeOf(time.Time{}):
+		return timestampColumnTypes
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerColumnTypes
+	case reflect.Float32, reflect.Float64:
+		return []string{"real", "double precision", "numeric"}
+	case reflect.Bool:
+		return []string{"boolean"}
+	case reflect.String:
+		return stringColumnTypes
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return []string{"bytea"}
+		}
+	}
+	return nil
+}
+
+// SchemaMismatches compares the live schema of the tables of the models with their go definitions, and returns a
+// line per missing table, missing column, column of a type the model can not hold and missing index.
+func SchemaMismatches(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var mismatches []string
+	for _, model := range schemaModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse the model %T, error: %w", model, err)
+		}
+		table := stmt.Schema.Table
+
+		var columns []struct {
+			ColumnName string `gorm:"column:column_name"`
+			DataType   string `gorm:"column:data_type"`
+		}
+		query := db.WithContext(ctx).Table("information_schema.columns").Select("column_name, data_type")
+		query = query.Where("table_schema = current_schema() AND table_name = ?", table)
+		if err := query.Find(&columns).Error; err != nil {
+			return nil, fmt.Errorf("failed to get the columns of %s, error: %w", table, err)
+		}
+		if len(columns) == 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s: missing table", table))
+			continue
+		}
+		liveTypes := make(map[string]string, len(columns))
+		for _, column := range columns {
+			liveTypes[column.ColumnName] = column.DataType
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			liveType, ok := liveTypes[field.DBName]
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: missing column", table, field.DBName))
+				continue
+			}
+			expected := columnTypes(field.FieldType)
+			if expected != nil && !containsString(expected, liveType) {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: type %s, expected %s for %s %s", table, field.DBName, liveType, strings.Join(expected, " or "), field.Name, field.FieldType))
+			}
+		}
+
+		var indexes []string
+		query = db.WithContext(ctx).Table("pg_indexes").Select("indexname")
+		query = query.Where("schemaname = current_schema() AND tablename = ?", table)
+		if err := query.Pluck("indexname", &indexes).Error; err != nil {
+			return nil, fmt.Errorf("failed to get the indexes of %s, error: %w", table, err)
+		}
+		for _, index := range schemaIndexes[table] {
+			if !containsString(indexes, index) {
+				mismatches = append(mismatches, fmt.Sprintf("%s: missing index %s", table, index))
+			}
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches, nil
+}
+
+// CheckSchema returns an error listing the schema mismatches if the live schema does not match the models, e.g. after
+// a partial migration, so that the services fail at startup instead of on the first query of a missing column.
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+	mismatches, err := SchemaMismatches(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		return errors.New("the database schema does not match the models, check the migrations:\n  " + strings.Join(mismatches, "\n  "))
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
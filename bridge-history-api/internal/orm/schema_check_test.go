@@ -0,0 +1,30 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchema(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	assert.NoError(t, CheckSchema(ctx, db))
+
+	// a partial migration: a missing column, a column of another type and a missing index.
+	assert.NoError(t, db.Exec("ALTER TABLE batch_event_v2 DROP COLUMN finalize_l1_block_number").Error)
+	assert.NoError(t, db.Exec("ALTER TABLE batch_event_v2 ALTER COLUMN parse_status TYPE VARCHAR").Error)
+	assert.NoError(t, db.Exec("DROP INDEX idx_cw_sender_block_timestamp").Error)
+
+	mismatches, err := SchemaMismatches(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"batch_event_v2.finalize_l1_block_number: missing column",
+		"batch_event_v2.parse_status: type character varying, expected smallint or integer or bigint or numeric for ParseStatus int",
+		"claimable_withdrawal: missing index idx_cw_sender_block_timestamp",
+	}, mismatches)
+	assert.ErrorContains(t, CheckSchema(ctx, db), "missing column")
+
+	resetDB(t)
+}
@@ -0,0 +1,120 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+)
+
+// Stages of the messages, the latency of a stage is the time from the previous stage of the message to it.
+const (
+	StageDepositRelay        = "deposit_relay"        // from the deposit tx on L1 to its relay on L2
+	StageWithdrawalCommit    = "withdrawal_commit"    // from the withdrawal tx on L2 to the commit of its batch on L1
+	StageWithdrawalFinalize  = "withdrawal_finalize"  // from the commit of the batch of the withdrawal to its finalization
+	StageWithdrawalClaimable = "withdrawal_claimable" // from the finalization of the batch to the withdrawal being indexed as claimable
+	StageWithdrawalClaim     = "withdrawal_claim"     // from the withdrawal being indexed as claimable to its claim on L1
+)
+
+// Stages are the stages of the messages, deposits first.
+var Stages = []string{StageDepositRelay, StageWithdrawalCommit, StageWithdrawalFinalize, StageWithdrawalClaimable, StageWithdrawalClaim}
+
+// StageLatencyBuckets are the upper bounds of the buckets of the stage latencies, in seconds.
+var StageLatencyBuckets = []float64{30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400, 28800, 86400, 259200, 604800}
+
+// StageLatency is the distribution of the latencies of a stage, in seconds.
+type StageLatency struct {
+	Stage        string
+	SampleCount  uint64
+	SumSec       float64
+	P50Sec       float64
+	P90Sec       float64
+	P99Sec       float64
+	BucketCounts []uint64 // cumulative, the number of samples up to each of StageLatencyBuckets
+}
+
+// GetStageLatencies returns the distribution of the latencies of each stage over the messages which reached it since
+// the given timestamp, in the order of Stages. The stage timestamps unknown for the messages indexed before they were
+// stored are not sampled.
+func (c *CrossMessage) GetStageLatencies(ctx context.Context, since uint64) ([]*StageLatency, error) {
+	ctx, cancel := database.ReadContext(ctx)
+	defer cancel()
+
+	latencies := make([]*StageLatency, 0, len(Stages))
+	for _, stage := range Stages {
+		latency, err := c.getStageLatency(ctx, stage, c.stageSamples(ctx, stage, since))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stage latency, stage: %v, since: %v, error: %w", stage, since, err)
+		}
+		latencies = append(latencies, latency)
+	}
+	return latencies, nil
+}
+
+// stageSamples returns the query of the latencies of the stage of the messages which reached it since the timestamp.
+func (c *CrossMessage) stageSamples(ctx context.Context, stage string, since uint64) *gorm.DB {
+	samples := c.db.WithContext(ctx).Model(&CrossMessage{})
+	switch stage {
+	case StageDepositRelay:
+		samples = samples.Select("l2_relay_block_timestamp - block_timestamp AS latency")
+		samples = samples.Where("message_type = ?", MessageTypeL1SentMessage)
+		samples = samples.Where("tx_status = ?", TxStatusTypeRelayed)
+		samples = samples.Where("l2_relay_block_timestamp >= ?", since)
+		samples = samples.Where("block_timestamp > 0")
+	case StageWithdrawalCommit:
+		// the batch index of a withdrawal is stored at finalization, committed withdrawals are matched by block.
+		samples = samples.Select("batch_event_v2.commit_block_timestamp - cross_message_v2.block_timestamp AS latency")
+		samples = samples.Joins("JOIN batch_event_v2 ON cross_message_v2.l2_block_number BETWEEN batch_event_v2.start_block_number AND batch_event_v2.end_block_number AND batch_event_v2.commit_block_timestamp >= ? AND batch_event_v2.deleted_at IS NULL", since)
+		samples = samples.Where("cross_message_v2.message_type = ?", MessageTypeL2SentMessage)
+		samples = samples.Where("cross_message_v2.block_timestamp > 0")
+	case StageWithdrawalFinalize:
+		samples = samples.Select("batch_event_v2.finalize_block_timestamp - batch_event_v2.commit_block_timestamp AS latency")
+		samples = samples.Joins("JOIN batch_event_v2 ON batch_event_v2.batch_index = cross_message_v2.batch_index AND batch_event_v2.batch_status = ? AND batch_event_v2.finalize_block_timestamp >= ? AND batch_event_v2.commit_block_timestamp > 0 AND batch_event_v2.deleted_at IS NULL", BatchStatusTypeFinalized, since)
+		samples = samples.Where("cross_message_v2.message_type = ?", MessageTypeL2SentMessage)
+		samples = samples.Where("cross_message_v2.rollup_status = ?", RollupStatusTypeFinalized)
+	case StageWithdrawalClaimable:
+		samples = samples.Select("cross_message_v2.claimable_timestamp - batch_event_v2.finalize_block_timestamp AS latency")
+		samples = samples.Joins("JOIN batch_event_v2 ON batch_event_v2.batch_index = cross_message_v2.batch_index AND batch_event_v2.batch_status = ? AND batch_event_v2.finalize_block_timestamp > 0 AND batch_event_v2.deleted_at IS NULL", BatchStatusTypeFinalized)
+		samples = samples.Where("cross_message_v2.message_type = ?", MessageTypeL2SentMessage)
+		samples = samples.Where("cross_message_v2.claimable_timestamp >= ?", since)
+	case StageWithdrawalClaim:
+		samples = samples.Select("l1_relay_block_timestamp - claimable_timestamp AS latency")
+		samples = samples.Where("message_type = ?", MessageTypeL2SentMessage)
+		samples = samples.Where("tx_status = ?", TxStatusTypeRelayed)
+		samples = samples.Where("l1_relay_block_timestamp >= ?", since)
+		samples = samples.Where("claimable_timestamp > 0")
+	}
+	return samples
+}
+
+func (c *CrossMessage) getStageLatency(ctx context.Context, stage string, samples *gorm.DB) (*StageLatency, error) {
+	columns := []string{
+		"COUNT(*)",
+		"COALESCE(SUM(latency), 0)::DOUBLE PRECISION",
+		"COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency), 0)",
+		"COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY latency), 0)",
+		"COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY latency), 0)",
+	}
+	for _, bucket := range StageLatencyBuckets {
+		columns = append(columns, fmt.Sprintf("COUNT(*) FILTER (WHERE latency <= %v)", bucket))
+	}
+
+	latency := &StageLatency{Stage: stage, BucketCounts: make([]uint64, len(StageLatencyBuckets))}
+	dest := []interface{}{&latency.SampleCount, &latency.SumSec, &latency.P50Sec, &latency.P90Sec, &latency.P99Sec}
+	for i := range latency.BucketCounts {
+		dest = append(dest, &latency.BucketCounts[i])
+	}
+
+	db := c.db.WithContext(ctx)
+	db = db.Table("(?) AS samples", samples)
+	db = db.Select(strings.Join(columns, ", "))
+	// block timestamps of two chains are not strictly ordered, which could produce negative samples.
+	db = db.Where("latency >= 0")
+	if err := db.Row().Scan(dest...); err != nil {
+		return nil, err
+	}
+	return latency, nil
+}
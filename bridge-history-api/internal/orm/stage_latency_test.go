@@ -0,0 +1,62 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStageLatencies(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	crossMessageOrm := NewCrossMessage(db)
+
+	// a deposit relayed 100s after its tx.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), L1TxHash: "0x11", TokenAmounts: "1", BlockTimestamp: 1000, TxStatus: int(TxStatusTypeSent)},
+	}))
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2RelayedMessagesOfL1Deposits(ctx, []*CrossMessage{
+		{MessageHash: "0x01", MessageType: int(MessageTypeL1SentMessage), L2TxHash: "0x21", L2BlockNumber: 1, TxStatus: int(TxStatusTypeRelayed), L2RelayBlockTimestamp: 1100},
+	}))
+
+	// a withdrawal committed 300s after its tx, finalized 600s after the commit, then claimed.
+	batchEventOrm := NewBatchEvent(db)
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeCommitted), BatchIndex: 1, BatchHash: "0xb1", StartBlockNumber: 1, EndBlockNumber: 10, CommitBlockTimestamp: 1300},
+	}))
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(ctx, []*CrossMessage{
+		{MessageHash: "0x02", MessageType: int(MessageTypeL2SentMessage), L2TxHash: "0x22", L2BlockNumber: 5, TokenAmounts: "1", BlockTimestamp: 1000},
+	}))
+	assert.NoError(t, batchEventOrm.InsertOrUpdateBatchEvents(ctx, []*BatchEvent{
+		{BatchStatus: int(BatchStatusTypeFinalized), BatchIndex: 1, BatchHash: "0xb1", FinalizeBlockTimestamp: 1900},
+	}))
+	assert.NoError(t, crossMessageOrm.UpdateBatchStatusOfL2Withdrawals(ctx, 1, 10, 1))
+
+	var withdrawal CrossMessage
+	assert.NoError(t, db.Where("message_hash = ?", "0x02").First(&withdrawal).Error)
+	assert.NotZero(t, withdrawal.ClaimableTimestamp)
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1RelayedMessagesOfL2Withdrawals(ctx, []*CrossMessage{
+		{MessageHash: "0x02", MessageType: int(MessageTypeL2SentMessage), L1BlockNumber: 20, L1TxHash: "0x32", TxStatus: int(TxStatusTypeRelayed), L1RelayBlockTimestamp: withdrawal.ClaimableTimestamp + 60},
+	}))
+
+	latencies, err := crossMessageOrm.GetStageLatencies(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, latencies, len(Stages))
+	expected := map[string]float64{StageDepositRelay: 100, StageWithdrawalCommit: 300, StageWithdrawalFinalize: 600, StageWithdrawalClaim: 60}
+	for _, latency := range latencies {
+		assert.Equal(t, uint64(1), latency.SampleCount, latency.Stage)
+		if sec, ok := expected[latency.Stage]; ok {
+			assert.Equal(t, sec, latency.P50Sec, latency.Stage)
+			assert.Equal(t, sec, latency.SumSec, latency.Stage)
+		}
+	}
+	// the deposit relay of 100s is in the bucket of 120s and the next ones.
+	assert.Equal(t, []uint64{0, 0, 1, 1}, latencies[0].BucketCounts[:4])
+
+	// the deposit was relayed before the window.
+	latencies, err = crossMessageOrm.GetStageLatencies(ctx, 1200)
+	assert.NoError(t, err)
+	assert.Zero(t, latencies[0].SampleCount)
+	assert.Equal(t, uint64(1), latencies[1].SampleCount)
+}
@@ -0,0 +1,106 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/version"
+
+	"scroll-tech/bridge-history-api/internal/controller/api"
+	"scroll-tech/bridge-history-api/internal/openapi"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+// endpoint is an api with the types it binds and renders, from which its OpenAPI spec is generated.
+type endpoint struct {
+	openapi.Operation
+	handler gin.HandlerFunc
+}
+
+// v1Endpoints are the v1 apis, their paths are relative to the version prefix.
+func v1Endpoints() []*endpoint {
+	return []*endpoint{
+		{openapi.Operation{ID: "getTxsByAddress", Method: http.MethodGet, Path: "/txs",
+			Summary: "get all txs under the given address",
+			Params:  types.QueryByAddressRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetTxsByAddress},
+		{openapi.Operation{ID: "getL2WithdrawalsByAddress", Method: http.MethodGet, Path: "/l2/withdrawals",
+			Summary: "get all L2 withdrawals under the given address",
+			Params:  types.QueryByAddressRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetL2WithdrawalsByAddress},
+		{openapi.Operation{ID: "getL2UnclaimedWithdrawalsByAddress", Method: http.MethodGet, Path: "/l2/unclaimed/withdrawals",
+			Summary: "get all L2 unclaimed withdrawals under the given address",
+			Params:  types.QueryByAddressRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetL2UnclaimedWithdrawalsByAddress},
+		{openapi.Operation{ID: "getL2ClaimableWithdrawalsByAddress", Method: http.MethodGet, Path: "/l2/claimable/withdrawals",
+			Summary: "get the L2 withdrawals under the given address which can be claimed on L1 now",
+			Params:  types.QueryByAddressRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetL2ClaimableWithdrawalsByAddress},
+		{openapi.Operation{ID: "getL1QueuePosition", Method: http.MethodGet, Path: "/l1/queue",
+			Summary: "get the position of an L1 message in the L1 message queue",
+			Params:  types.QueryByQueueIndexRequest{}, Data: types.QueuePositionInfo{}}, api.HistoryCtrler.GetL1QueuePosition},
+		{openapi.Operation{ID: "getL1PendingDepositsByAddress", Method: http.MethodGet, Path: "/l1/pending/deposits",
+			Summary: "get the deposits under the given address seen in the L1 txpool and not indexed yet",
+			Params:  types.QueryPendingDepositsRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetPendingDepositsByAddress},
+		{openapi.Operation{ID: "getTxsByTokenAmountRange", Method: http.MethodGet, Path: "/txs/amount",
+			Summary: "get the latest txs of the given address transferring a token with an amount within the given range",
+			Params:  types.QueryByTokenAmountRangeRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.GetTxsByTokenAmountRange},
+		{openapi.Operation{ID: "getTokenTotalsByAddress", Method: http.MethodGet, Path: "/token/totals",
+			Summary: "get the total amounts of the tokens sent by the given address, per direction",
+			Params:  types.QueryTokenTotalsRequest{}, Data: []*types.TokenTotalInfo{}}, api.HistoryCtrler.GetTokenTotalsByAddress},
+		{openapi.Operation{ID: "postQueryTxsByHashes", Method: http.MethodPost, Path: "/txsbyhashes",
+			Summary: "get txs by given tx hashes",
+			Body:    types.QueryByHashRequest{}, Data: types.ResultData{}}, api.HistoryCtrler.PostQueryTxsByHashes},
+		{openapi.Operation{ID: "postQueryTxsByAddresses", Method: http.MethodPost, Path: "/txsbyaddresses",
+			Summary: "get the latest txs of each of the given addresses, grouped by address",
+			Body:    types.QueryByAddressesRequest{}, Data: types.ResultsByAddressData{}}, api.HistoryCtrler.PostQueryTxsByAddresses},
+		{openapi.Operation{ID: "getTokenStats", Method: http.MethodGet, Path: "/stats/tokens",
+			Summary: "get the top bridged tokens by volume or tx count over the last days",
+			Params:  types.QueryTokenStatsRequest{}, Data: types.TokenStatsData{}}, api.StatsCtrler.GetTokenStats},
+		{openapi.Operation{ID: "getGatewayStats", Method: http.MethodGet, Path: "/stats/gateways",
+			Summary: "get the number of txs per direction and gateway over the last days",
+			Params:  types.QueryStatsRequest{}, Data: types.GatewayStatsData{}}, api.StatsCtrler.GetGatewayStats},
+		{openapi.Operation{ID: "getBridgerStats", Method: http.MethodGet, Path: "/stats/bridgers",
+			Summary: "get the number of unique bridgers per day over the last days",
+			Params:  types.QueryStatsRequest{}, Data: types.BridgerStatsData{}}, api.StatsCtrler.GetBridgerStats},
+		{openapi.Operation{ID: "getStageLatency", Method: http.MethodGet, Path: "/stats/latency",
+			Summary: "get the latency percentiles of each stage of the messages, from their tx to their relay or claim, over a recent window",
+			Data:    types.StageLatencyData{}}, api.StatsCtrler.GetStageLatency},
+		{openapi.Operation{ID: "getFeeVaultWithdrawals", Method: http.MethodGet, Path: "/fee_vault/withdrawals",
+			Summary: "get the latest withdrawals of the L2 fee vaults to L1, with their total number and value",
+			Params:  types.QueryFeeVaultWithdrawalsRequest{}, Data: types.FeeVaultWithdrawalsData{}}, api.StatsCtrler.GetFeeVaultWithdrawals},
+	}
+}
+
+// v2Endpoints are the v2 apis, their paths are relative to the version prefix.
+func v2Endpoints() []*endpoint {
+	return []*endpoint{
+		{openapi.Operation{ID: "getTxsByAddressV2", Method: http.MethodGet, Path: "/txs",
+			Summary: "get all txs under the given address, paginated by cursor",
+			Params:  types.QueryByAddressCursorRequest{}, Data: types.CursorResultData{}}, api.HistoryCtrlerV2.GetTxsByAddress},
+		{openapi.Operation{ID: "getL2WithdrawalsByAddressV2", Method: http.MethodGet, Path: "/l2/withdrawals",
+			Summary: "get all L2 withdrawals under the given address, paginated by cursor",
+			Params:  types.QueryByAddressCursorRequest{}, Data: types.CursorResultData{}}, api.HistoryCtrlerV2.GetL2WithdrawalsByAddress},
+		{openapi.Operation{ID: "getL2UnclaimedWithdrawalsByAddressV2", Method: http.MethodGet, Path: "/l2/unclaimed/withdrawals",
+			Summary: "get all L2 unclaimed withdrawals under the given address, paginated by cursor",
+			Params:  types.QueryByAddressCursorRequest{}, Data: types.CursorResultData{}}, api.HistoryCtrlerV2.GetL2UnclaimedWithdrawalsByAddress},
+		{openapi.Operation{ID: "getL2ClaimableWithdrawalsByAddressV2", Method: http.MethodGet, Path: "/l2/claimable/withdrawals",
+			Summary: "get the L2 withdrawals under the given address which can be claimed on L1 now, paginated by cursor",
+			Params:  types.QueryByAddressCursorRequest{}, Data: types.CursorResultData{}}, api.HistoryCtrlerV2.GetL2ClaimableWithdrawalsByAddress},
+	}
+}
+
+// OpenAPI returns the OpenAPI spec of the apis. The v1 apis are documented under api/v1/ only, the unversioned
+// api/ paths are aliases kept for existing clients.
+func OpenAPI() *openapi.Document {
+	var operations []*openapi.Operation
+	for _, e := range v1Endpoints() {
+		op := e.Operation
+		op.Path = "/api/v1" + op.Path
+		operations = append(operations, &op)
+	}
+	for _, e := range v2Endpoints() {
+		op := e.Operation
+		op.Path = "/api/v2" + op.Path
+		operations = append(operations, &op)
+	}
+	info := openapi.Info{Title: "Scroll Bridge History API", Version: version.Version}
+	return openapi.Generate(info, types.Response{}, operations)
+}
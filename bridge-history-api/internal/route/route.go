@@ -1,35 +1,91 @@
 package route
 
 import (
+	"compress/gzip"
+	"os"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
 
 	"scroll-tech/common/observability"
+	"scroll-tech/common/utils"
 
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/controller/api"
+	"scroll-tech/bridge-history-api/internal/middleware"
+)
+
+const (
+	// responseSigningPasswordEnv is the environment variable holding the password of the response signing keystore.
+	responseSigningPasswordEnv = "RESPONSE_SIGNING_KEYSTORE_PASSWORD"
+
+	defaultMaxRequestBodyBytes = 1 << 20
+	defaultRequestTimeout      = 30 * time.Second
+	defaultQueryTimeout        = 5 * time.Second
 )
 
 // Route routes the APIs
 func Route(router *gin.Engine, conf *config.Config, reg prometheus.Registerer) {
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	serverCfg := conf.Server
+	if serverCfg == nil {
+		serverCfg = &config.ServerConfig{}
+	}
+	maxRequestBodyBytes := int64(defaultMaxRequestBodyBytes)
+	if serverCfg.MaxRequestBodyBytes > 0 {
+		maxRequestBodyBytes = serverCfg.MaxRequestBodyBytes
+	}
+	requestTimeout := defaultRequestTimeout
+	if serverCfg.RequestTimeoutSec > 0 {
+		requestTimeout = time.Duration(serverCfg.RequestTimeoutSec) * time.Second
+	}
+	queryTimeout := defaultQueryTimeout
+	if serverCfg.QueryTimeoutMs > 0 {
+		queryTimeout = time.Duration(serverCfg.QueryTimeoutMs) * time.Millisecond
+	}
+	queryTimeoutOverrides := make(map[string]time.Duration, len(serverCfg.QueryTimeoutOverridesMs))
+	for path, timeoutMs := range serverCfg.QueryTimeoutOverridesMs {
+		queryTimeoutOverrides[path] = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	// Handlers pass the gin context down as a context.Context, let it carry the request deadline.
+	router.ContextWithFallback = true
+
+	router.Use(middleware.CORS(serverCfg.CORSAllowOrigins))
 
 	observability.Use(router, "bridge_history_api", reg)
 
-	r := router.Group("api/")
+	router.Use(middleware.MaxRequestBodySize(maxRequestBodyBytes))
+	router.Use(middleware.Timeout(requestTimeout))
+	router.Use(middleware.QueryTimeout(queryTimeout, queryTimeoutOverrides))
+	if serverCfg.EnableGzip {
+		router.Use(middleware.Gzip(gzip.DefaultCompression))
+	}
+	// Registered after gzip, responses are signed before being compressed.
+	if serverCfg.ResponseSigningKeystorePath != "" {
+		key, err := utils.LoadKey(serverCfg.ResponseSigningKeystorePath, os.Getenv(responseSigningPasswordEnv))
+		if err != nil {
+			log.Crit("failed to load response signing key", "keystore", serverCfg.ResponseSigningKeystorePath, "err", err)
+		}
+		router.Use(middleware.SignResponse(key))
+	}
+
+	// The v1 apis keep being served without version prefix for existing clients.
+	registerV1(router.Group("api/"))
+	registerV1(router.Group("api/v1/"))
+	registerV2(router.Group("api/v2/"))
+}
 
-	r.GET("/txs", api.HistoryCtrler.GetTxsByAddress)
-	r.GET("/l2/withdrawals", api.HistoryCtrler.GetL2WithdrawalsByAddress)
-	r.GET("/l2/unclaimed/withdrawals", api.HistoryCtrler.GetL2UnclaimedWithdrawalsByAddress)
+func registerV1(r *gin.RouterGroup) {
+	for _, e := range v1Endpoints() {
+		r.Handle(e.Method, e.Path, e.handler)
+	}
+}
 
-	r.POST("/txsbyhashes", api.HistoryCtrler.PostQueryTxsByHashes)
+// registerV2 registers the v2 apis, only the apis whose response shape changed have a v2 version.
+func registerV2(r *gin.RouterGroup) {
+	for _, e := range v2Endpoints() {
+		r.Handle(e.Method, e.Path, e.handler)
+	}
 }
@@ -0,0 +1,124 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Route(router, &config.Config{}, prometheus.NewRegistry())
+	return router
+}
+
+// fill sets every field of v, allocating pointers and appending an item to slices, so that no property of the
+// serialized value is left out.
+func fill(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				fill(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0))
+	case reflect.String:
+		v.SetString("1")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	}
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	spec := OpenAPI()
+	var operations int
+	for _, methods := range spec.Paths {
+		operations += len(methods)
+	}
+
+	var versioned int
+	for _, r := range newTestRouter().Routes() {
+		if !strings.HasPrefix(r.Path, "/api/v1/") && !strings.HasPrefix(r.Path, "/api/v2/") {
+			continue
+		}
+		versioned++
+		assert.NotNil(t, spec.Operation(r.Method, r.Path), "%s %s is not in the spec", r.Method, r.Path)
+	}
+	assert.Equal(t, operations, versioned)
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	spec := OpenAPI()
+	router := newTestRouter()
+
+	// parameter failures are rendered by the live handlers, before the logic is reached.
+	for _, r := range router.Routes() {
+		op := spec.Operation(r.Method, r.Path)
+		// the apis without parameters have no parameter failure.
+		if op == nil || (len(op.Parameters) == 0 && op.RequestBody == nil) {
+			continue
+		}
+		var requests []*http.Request
+		if r.Method == http.MethodGet {
+			// the stats apis have no required parameter, their window is invalid instead.
+			requests = append(requests,
+				httptest.NewRequest(r.Method, r.Path+"?days=x", nil),
+				httptest.NewRequest(r.Method, r.Path+"?address=0xzz&token=0x01&page=0&page_size=1000&min_amount=x&days=-1&sort_by=x", nil),
+			)
+		} else {
+			requests = append(requests,
+				httptest.NewRequest(r.Method, r.Path, strings.NewReader(`{}`)),
+				httptest.NewRequest(r.Method, r.Path, strings.NewReader(`{"txs":["0x01"],"addresses":["0xzz"],"limit":1000}`)),
+				httptest.NewRequest(r.Method, r.Path, strings.NewReader(`{`)),
+			)
+		}
+		for _, req := range requests {
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			var resp types.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.NotEqual(t, types.Success, resp.ErrCode)
+			assert.NoError(t, spec.ValidateResponse(r.Method, r.Path, w.Code, w.Body.Bytes()), "%s %s", r.Method, req.URL)
+		}
+	}
+
+	// the data rendered by the handlers, fully populated and empty.
+	check := func(prefix string, endpoints []*endpoint) {
+		for _, e := range endpoints {
+			path := prefix + e.Path
+			for _, populated := range []bool{true, false} {
+				data := reflect.New(reflect.TypeOf(e.Data))
+				if populated {
+					fill(data.Elem())
+				}
+				body, err := json.Marshal(&types.Response{Data: data.Interface()})
+				assert.NoError(t, err)
+				assert.NoError(t, spec.ValidateResponse(e.Method, path, http.StatusOK, body), "%s %s", e.Method, path)
+			}
+		}
+	}
+	check("/api/v1", v1Endpoints())
+	check("/api/v2", v2Endpoints())
+}
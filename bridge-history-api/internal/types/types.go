@@ -23,18 +23,111 @@ const (
 	ErrGetTxsError = 40004
 	// ErrGetTxsByHashError represents an error when trying to get transactions by hash list.
 	ErrGetTxsByHashError = 40005
+	// ErrRequestBodyTooLarge represents an error when the request body exceeds the configured limit.
+	ErrRequestBodyTooLarge = 40006
+	// ErrRequestTimeout represents an error when the request is not handled within the configured timeout.
+	ErrRequestTimeout = 40007
+	// ErrGetTxsByAddressesError represents an error when trying to get transactions by address list.
+	ErrGetTxsByAddressesError = 40008
+	// ErrGetL1QueuePositionError represents an error when trying to get the position of an L1 message in the message queue.
+	ErrGetL1QueuePositionError = 40009
+	// ErrGetTxsByTokenAmountError represents an error when trying to get transactions by token amount range.
+	ErrGetTxsByTokenAmountError = 40010
+	// ErrGetTokenTotalsError represents an error when trying to get the total token amounts of an address.
+	ErrGetTokenTotalsError = 40011
+	// ErrL1QueueIndexNotFound represents an error when the queue index is not appended to the L1 message queue yet.
+	ErrL1QueueIndexNotFound = 40012
+	// ErrCursorInvalid represents an error when the pagination cursor is malformed or its txs changed, the client should restart from the first page.
+	ErrCursorInvalid = 40013
+	// ErrParameterMissing represents an error when a required parameter is missing.
+	ErrParameterMissing = 40014
+	// ErrInvalidAddress represents an error when an address parameter is not a hex address or does not match its checksum.
+	ErrInvalidAddress = 40015
+	// ErrInvalidTxHash represents an error when a tx hash parameter is not a 32-byte hex hash.
+	ErrInvalidTxHash = 40016
+	// ErrInvalidPagination represents an error when a pagination parameter is out of bounds.
+	ErrInvalidPagination = 40017
+	// ErrGetStatsError represents an error when trying to get the bridge statistics.
+	ErrGetStatsError = 40018
+	// ErrGetPendingDepositsError represents an error when trying to get the pending deposits of an address.
+	ErrGetPendingDepositsError = 40019
+	// ErrStageLatencyUnavailable represents an error when the latency statistics are disabled or not computed yet.
+	ErrStageLatencyUnavailable = 40020
 )
 
+// ExpandBatch is the expand parameter of the address apis including the batch of each layer 2 message in the txs.
+const ExpandBatch = "batch"
+
 // QueryByAddressRequest the request parameter of address api
 type QueryByAddressRequest struct {
-	Address  string `form:"address" binding:"required"`
+	Address  string `form:"address" binding:"required,address"`
 	Page     uint64 `form:"page" binding:"required,min=1"`
 	PageSize uint64 `form:"page_size" binding:"required,min=1,max=100"`
+	Expand   string `form:"expand" binding:"omitempty,oneof=batch"` // batch: include the batch of layer 2 messages
+}
+
+// QueryByAddressCursorRequest the request parameter of v2 address api, paginated by cursor
+type QueryByAddressCursorRequest struct {
+	Address  string `form:"address" binding:"required,address"`
+	Cursor   string `form:"cursor"` // next_cursor of the previous page, empty for the first page
+	PageSize uint64 `form:"page_size" binding:"required,min=1,max=100"`
+	Expand   string `form:"expand" binding:"omitempty,oneof=batch"` // batch: include the batch of layer 2 messages
 }
 
 // QueryByHashRequest the request parameter of hash api
 type QueryByHashRequest struct {
-	Txs []string `json:"txs" binding:"required,min=1,max=100"`
+	Txs []string `json:"txs" binding:"required,min=1,max=100,dive,tx_hash"`
+}
+
+// QueryByAddressesRequest the request parameter of batch address api
+type QueryByAddressesRequest struct {
+	Addresses []string `json:"addresses" binding:"required,min=1,max=20,dive,address"`
+	Limit     uint64   `json:"limit" binding:"omitempty,min=1,max=500"` // max number of txs per address, defaults to 100
+}
+
+// QueryByQueueIndexRequest the request parameter of queue index api
+type QueryByQueueIndexRequest struct {
+	QueueIndex *uint64 `form:"queue_index" binding:"required"`
+}
+
+// QueryByTokenAmountRangeRequest the request parameter of token amount range api
+type QueryByTokenAmountRangeRequest struct {
+	Address   string `form:"address" binding:"required,address"`
+	Token     string `form:"token" binding:"required,address"` // L1 or L2 token address, the zero address for eth
+	MinAmount string `form:"min_amount" binding:"omitempty,numeric"`
+	MaxAmount string `form:"max_amount" binding:"omitempty,numeric"`
+}
+
+// QueryTokenTotalsRequest the request parameter of token totals api
+type QueryTokenTotalsRequest struct {
+	Address string `form:"address" binding:"required,address"`
+	Token   string `form:"token" binding:"omitempty,address"` // L1 or L2 token address, the zero address for eth, all tokens if empty
+}
+
+// QueryPendingDepositsRequest the request parameter of pending deposits api
+type QueryPendingDepositsRequest struct {
+	Address string `form:"address" binding:"required,address"`
+}
+
+// QueryTokenStatsRequest the request parameter of token stats api
+type QueryTokenStatsRequest struct {
+	Days        uint64 `form:"days" binding:"omitempty,min=1"`                    // window of the last days including today, defaults to 7, capped by the server
+	MessageType int    `form:"message_type" binding:"omitempty,oneof=1 2"`        // 1: deposits, 2: withdrawals, both if empty
+	TokenType   int    `form:"token_type" binding:"omitempty,oneof=1 2 3 4"`      // all token types if empty
+	SortBy      string `form:"sort_by" binding:"omitempty,oneof=volume tx_count"` // defaults to volume
+	Limit       uint64 `form:"limit" binding:"omitempty,min=1,max=100"`           // defaults to 20
+}
+
+// QueryStatsRequest the request parameter of gateway and bridger stats apis
+type QueryStatsRequest struct {
+	Days uint64 `form:"days" binding:"omitempty,min=1"` // window of the last days including today, defaults to 7, capped by the server
+}
+
+// QueryFeeVaultWithdrawalsRequest the request parameter of fee vault withdrawals api
+type QueryFeeVaultWithdrawalsRequest struct {
+	Vault    string `form:"vault" binding:"omitempty,address"` // all fee vaults if empty
+	Page     uint64 `form:"page" binding:"required,min=1"`
+	PageSize uint64 `form:"page_size" binding:"required,min=1,max=100"`
 }
 
 // ResultData contains return txs and total
@@ -43,11 +136,42 @@ type ResultData struct {
 	Total   uint64           `json:"total"`
 }
 
+// CursorResultData contains return txs of v2 apis, total and the cursor of the next page
+type CursorResultData struct {
+	Results    []*TxHistoryInfoV2 `json:"results"`
+	Total      uint64             `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// AddressResultData contains the txs of an address
+type AddressResultData struct {
+	Address string           `json:"address"`
+	Results []*TxHistoryInfo `json:"results"`
+	Total   uint64           `json:"total"`
+}
+
+// ResultsByAddressData contains return txs grouped by address, in the order of the requested addresses
+type ResultsByAddressData struct {
+	Results []*AddressResultData `json:"results"`
+}
+
 // Response the response schema
 type Response struct {
-	ErrCode int         `json:"errcode"`
-	ErrMsg  string      `json:"errmsg"`
-	Data    interface{} `json:"data"`
+	ErrCode int           `json:"errcode"`
+	ErrMsg  string        `json:"errmsg"`
+	Data    interface{}   `json:"data"`
+	Errors  []*ParamError `json:"errors,omitempty"` // the invalid parameters, if the request parameters failed validation
+	// the chain ids of the L1 and L2 networks the data refers to.
+	L1ChainID uint64 `json:"l1_chain_id"`
+	L2ChainID uint64 `json:"l2_chain_id"`
+}
+
+// l1ChainID and l2ChainID are declared in all responses, set once at startup.
+var l1ChainID, l2ChainID uint64
+
+// SetChainIDs sets the chain ids of the L1 and L2 networks declared in all responses, it must be called before serving.
+func SetChainIDs(l1, l2 uint64) {
+	l1ChainID, l2ChainID = l1, l2
 }
 
 // CounterpartChainTx is the schema of counterpart chain tx info
@@ -67,6 +191,148 @@ type ClaimInfo struct {
 	Claimable bool           `json:"claimable"`
 }
 
+// RelayFailureInfo is the schema of the decoded revert reason of a failed relay
+type RelayFailureInfo struct {
+	Selector string `json:"selector"`
+	Reason   string `json:"reason"`
+}
+
+// L1FeeInfo is the schema of the fee paid by a layer 1 tx
+type L1FeeInfo struct {
+	GasUsed           uint64 `json:"gas_used"`
+	EffectiveGasPrice string `json:"effective_gas_price"`
+	Fee               string `json:"fee"`
+}
+
+// DepositCallInfo is the schema of the contract call accompanying a deposit, e.g. made through depositERC20AndCall
+type DepositCallInfo struct {
+	Target   string   `json:"target"`
+	Selector string   `json:"selector"`
+	Data     string   `json:"data"` // hex encoded arguments, without the selector
+	Args     []string `json:"args"` // arguments split into 32-byte ABI words
+	// Method and Params are only set if the selector is of a known function, e.g. an erc20 transfer or permit.
+	Method string              `json:"method,omitempty"` // function signature, e.g. transfer(address,uint256)
+	Params []*DepositCallParam `json:"params,omitempty"`
+}
+
+// DepositCallParam is the schema of a decoded argument of the contract call accompanying a deposit
+type DepositCallParam struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// QueuePositionInfo is the schema of the position of an L1 message in the L1 message queue
+type QueuePositionInfo struct {
+	QueueIndex        uint64 `json:"queue_index"`
+	PendingQueueIndex uint64 `json:"pending_queue_index"` // index of the first message neither included on L2 nor popped by a committed batch
+	NextQueueIndex    uint64 `json:"next_queue_index"`    // index of the next message appended to the queue
+	Ahead             uint64 `json:"ahead"`               // number of messages ahead of the message, 0 once popped
+	Popped            bool   `json:"popped"`              // whether the message is included on L2 or popped by a committed batch
+	L1BlockNumber     uint64 `json:"l1_block_number"`     // L1 block number the cursors are tracked at
+}
+
+// TokenTotalInfo is the schema of the total amount of a token sent by an address in a direction
+type TokenTotalInfo struct {
+	MessageType    int           `json:"message_type"` // 1: deposit, 2: withdrawal
+	TokenType      orm.TokenType `json:"token_type"`
+	L1TokenAddress string        `json:"l1_token_address"`
+	TotalAmount    string        `json:"total_amount"`
+	TxCount        uint64        `json:"tx_count"`
+}
+
+// TokenStatsData contains the top tokens since the first UTC day of the window
+type TokenStatsData struct {
+	From    string           `json:"from"` // first day of the window, e.g. 2024-01-31
+	Results []*TokenStatInfo `json:"results"`
+}
+
+// TokenStatInfo is the schema of the number and volume of the messages of a token over a window
+type TokenStatInfo struct {
+	TokenType      orm.TokenType `json:"token_type"`
+	L1TokenAddress string        `json:"l1_token_address"`
+	L2TokenAddress string        `json:"l2_token_address"`
+	TxCount        uint64        `json:"tx_count"`
+	Volume         string        `json:"volume"` // in the smallest unit of the token, 0 for NFTs
+}
+
+// GatewayStatsData contains the gateway activity since the first UTC day of the window
+type GatewayStatsData struct {
+	From    string             `json:"from"`
+	Results []*GatewayStatInfo `json:"results"`
+}
+
+// GatewayStatInfo is the schema of the number of messages sent through a gateway in a direction over a window
+type GatewayStatInfo struct {
+	MessageType    int    `json:"message_type"` // 1: deposit, 2: withdrawal
+	Gateway        string `json:"gateway"`      // e.g. StandardERC20Gateway, messenger for direct messenger calls, unknown for deposits indexed before their gateway
+	GatewayAddress string `json:"gateway_address,omitempty"`
+	TxCount        uint64 `json:"tx_count"`
+}
+
+// BridgerStatsData contains the number of distinct bridgers per UTC day of the window
+type BridgerStatsData struct {
+	From    string             `json:"from"`
+	Results []*BridgerStatInfo `json:"results"` // by day, days without messages are missing
+}
+
+// BridgerStatInfo is the schema of the number of distinct senders of messages on a day
+type BridgerStatInfo struct {
+	Day                string `json:"day"`
+	DepositBridgers    uint64 `json:"deposit_bridgers"`
+	WithdrawalBridgers uint64 `json:"withdrawal_bridgers"`
+	UniqueBridgers     uint64 `json:"unique_bridgers"`
+}
+
+// StageLatencyData contains the latency statistics of each stage over the messages which reached it within the window
+type StageLatencyData struct {
+	WindowSec uint64              `json:"window_sec"`
+	UpdatedAt uint64              `json:"updated_at"` // unix time the statistics were computed
+	Results   []*StageLatencyInfo `json:"results"`
+}
+
+// StageLatencyInfo is the schema of the latency statistics of a stage, in seconds
+type StageLatencyInfo struct {
+	Stage       string  `json:"stage"` // deposit_relay, withdrawal_commit, withdrawal_finalize, withdrawal_claimable or withdrawal_claim
+	SampleCount uint64  `json:"sample_count"`
+	P50         float64 `json:"p50"`
+	P90         float64 `json:"p90"`
+	P99         float64 `json:"p99"`
+}
+
+// FeeVaultWithdrawalsData contains the latest fee vault withdrawals, their total number and value
+type FeeVaultWithdrawalsData struct {
+	Results    []*FeeVaultWithdrawalInfo `json:"results"`
+	Total      uint64                    `json:"total"`
+	TotalValue string                    `json:"total_value"` // in wei
+}
+
+// FeeVaultWithdrawalInfo is the schema of a withdrawal of the collected fees of an L2 fee vault to L1
+type FeeVaultWithdrawalInfo struct {
+	L2TxHash       string `json:"l2_tx_hash"`
+	VaultAddress   string `json:"vault_address"`
+	Value          string `json:"value"` // in wei
+	Recipient      string `json:"recipient"`
+	TriggeredBy    string `json:"triggered_by"`
+	MessageHash    string `json:"message_hash"`
+	BlockNumber    uint64 `json:"block_number"`
+	BlockTimestamp uint64 `json:"block_timestamp"`
+	TxStatus       int    `json:"tx_status"`
+	RollupStatus   int    `json:"rollup_status"`
+	L1TxHash       string `json:"l1_tx_hash"` // the tx claiming the fees on L1, empty if not claimed yet
+}
+
+// BatchInfo is the schema of the batch including the L2 block of a layer 2 message
+type BatchInfo struct {
+	BatchIndex        uint64 `json:"batch_index"`
+	BatchHash         string `json:"batch_hash"`
+	BatchStatus       int    `json:"batch_status"` // 1: committed, 3: finalized
+	CommitTxHash      string `json:"commit_tx_hash"`
+	CommitTimestamp   uint64 `json:"commit_timestamp"`             // 0 if committed before it was stored
+	FinalizeTxHash    string `json:"finalize_tx_hash,omitempty"`   // empty if not finalized or finalized before it was stored
+	FinalizeTimestamp uint64 `json:"finalize_timestamp,omitempty"` // 0 if not finalized or finalized before it was stored
+}
+
 // L2MessageProof is the schema of L2 message proof
 type L2MessageProof struct {
 	BatchIndex  string `json:"batch_index"`
@@ -77,19 +343,50 @@ type L2MessageProof struct {
 type TxHistoryInfo struct {
 	Hash               string              `json:"hash"`
 	ReplayTxHash       string              `json:"replay_tx_hash"`
-	RefundTxHash       string              `json:"refund_tx_hash"`
+	RefundTxHash       string              `json:"refund_tx_hash"` // the tx dropping the layer 1 message, which refunds it if the tx status is 7
 	MessageHash        string              `json:"message_hash"`
-	TokenType          orm.TokenType       `json:"token_type"`    // 0: unknown, 1: eth, 2: erc20, 3: erc721, 4: erc1155
-	TokenIDs           []string            `json:"token_ids"`     // only for erc721 and erc1155
-	TokenAmounts       []string            `json:"token_amounts"` // for eth and erc20, the length is 1, for erc721 and erc1155, the length could be > 1
-	MessageType        orm.MessageType     `json:"message_type"`  // 0: unknown, 1: layer 1 message, 2: layer 2 message
+	Sender             string              `json:"sender"`
+	Receiver           string              `json:"receiver"`
+	SenderENSName      string              `json:"sender_ens_name,omitempty"`   // only set if ENS resolution is enabled and the sender has a primary name
+	ReceiverENSName    string              `json:"receiver_ens_name,omitempty"` // only set if ENS resolution is enabled and the receiver has a primary name
+	TokenType          orm.TokenType       `json:"token_type"`                  // 0: unknown, 1: eth, 2: erc20, 3: erc721, 4: erc1155
+	TokenIDs           []string            `json:"token_ids"`                   // only for erc721 and erc1155
+	TokenAmounts       []string            `json:"token_amounts"`               // for eth and erc20, the length is 1, for erc721 and erc1155, the length could be > 1
+	MessageType        orm.MessageType     `json:"message_type"`                // 0: unknown, 1: layer 1 message, 2: layer 2 message
 	L1TokenAddress     string              `json:"l1_token_address"`
 	L2TokenAddress     string              `json:"l2_token_address"`
 	BlockNumber        uint64              `json:"block_number"`
-	TxStatus           orm.TxStatusType    `json:"tx_status"` // 0: sent, 1: sent failed, 2: relayed, 3: failed relayed, 4: relayed reverted, 5: skipped, 6: dropped
+	QueueIndex         *uint64             `json:"queue_index,omitempty"` // only for layer 1 messages, the index in the L1 message queue
+	TxStatus           orm.TxStatusType    `json:"tx_status"`             // 0: sent, 1: sent failed, 2: relayed, 3: failed relayed, 4: relayed reverted, 5: skipped, 6: dropped, 7: dropped and refunded
 	CounterpartChainTx *CounterpartChainTx `json:"counterpart_chain_tx"`
 	ClaimInfo          *ClaimInfo          `json:"claim_info"`
+	RelayFailure       *RelayFailureInfo   `json:"relay_failure,omitempty"` // only for layer 1 messages whose relay on layer 2 failed
+	L1Fee              *L1FeeInfo          `json:"l1_fee,omitempty"`        // fee of the deposit tx of layer 1 messages, or of the claim tx of layer 2 messages
+	ClaimedBy          string              `json:"claimed_by,omitempty"`    // only for claimed layer 2 messages, the sender of the claim tx
+	SelfClaimed        *bool               `json:"self_claimed,omitempty"`  // only for claimed layer 2 messages, false if claimed by a third party on the user's behalf
+	DepositCall        *DepositCallInfo    `json:"deposit_call,omitempty"`  // only for layer 1 messages of deposits with call data
 	BlockTimestamp     uint64              `json:"block_timestamp"`
+	ETA                uint64              `json:"eta,omitempty"`     // only for pending messages if ETA estimation is enabled, unix timestamp of the estimated relay of deposits or finalization of withdrawals
+	Batch              *BatchInfo          `json:"batch,omitempty"`   // only for layer 2 messages of committed batches with expand=batch
+	Pending            bool                `json:"pending,omitempty"` // only for deposits seen in the L1 txpool, their tx is not mined yet
+}
+
+// TxHistoryInfoV2 the schema of tx history infos of v2 apis
+type TxHistoryInfoV2 struct {
+	*TxHistoryInfo
+	// Claimable is derived from the rollup and tx status, unlike claim_info.claimable it is false once the withdrawal is relayed.
+	Claimable bool `json:"claimable"`
+}
+
+// NewResponse returns the response of the error code, message and data, declaring the chain ids.
+func NewResponse(errCode int, errMsg string, data interface{}) *Response {
+	return &Response{
+		ErrCode:   errCode,
+		ErrMsg:    errMsg,
+		Data:      data,
+		L1ChainID: l1ChainID,
+		L2ChainID: l2ChainID,
+	}
 }
 
 // RenderJSON renders response with json
@@ -98,12 +395,7 @@ func RenderJSON(ctx *gin.Context, errCode int, err error, data interface{}) {
 	if err != nil {
 		errMsg = err.Error()
 	}
-	renderData := Response{
-		ErrCode: errCode,
-		ErrMsg:  errMsg,
-		Data:    data,
-	}
-	ctx.JSON(http.StatusOK, renderData)
+	ctx.JSON(http.StatusOK, NewResponse(errCode, errMsg, data))
 }
 
 // RenderSuccess renders success response with json
@@ -122,11 +414,6 @@ func RenderFatal(ctx *gin.Context, err error) {
 	if err != nil {
 		errMsg = err.Error()
 	}
-	renderData := Response{
-		ErrCode: InternalServerError,
-		ErrMsg:  errMsg,
-		Data:    nil,
-	}
 	ctx.Set("errcode", InternalServerError)
-	ctx.JSON(http.StatusInternalServerError, renderData)
+	ctx.JSON(http.StatusInternalServerError, NewResponse(InternalServerError, errMsg, nil))
 }
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// Validation tags of the request parameters, in addition to the ones of the validator package.
+const (
+	// ValidationTagAddress accepts 0x-prefixed hex addresses, mixed-case ones must match their EIP-55 checksum.
+	ValidationTagAddress = "address"
+	// ValidationTagTxHash accepts 0x-prefixed 32-byte hex hashes.
+	ValidationTagTxHash = "tx_hash"
+)
+
+var txHashRegexp = regexp.MustCompile("^0x[0-9a-fA-F]{64}$")
+
+// paginationParams are the parameters bounding the number of returned txs.
+var paginationParams = map[string]bool{"page": true, "page_size": true, "limit": true}
+
+// ParamError describes why a request parameter is invalid.
+type ParamError struct {
+	Param   string `json:"param"` // name of the parameter in the query or body, with the index for list items, e.g. txs[1]
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// The validation tags are registered with the validator of gin as the request types use them.
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// field errors are reported by the parameter names of the query or body.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"form", "json"} {
+			if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	if err := v.RegisterValidation(ValidationTagAddress, func(fl validator.FieldLevel) bool {
+		return IsValidAddress(fl.Field().String())
+	}); err != nil {
+		panic(err)
+	}
+	if err := v.RegisterValidation(ValidationTagTxHash, func(fl validator.FieldLevel) bool {
+		return txHashRegexp.MatchString(fl.Field().String())
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// IsValidAddress returns whether the address is 0x-prefixed hex, and matches its EIP-55 checksum if mixed-case.
+// All lower or upper case addresses carry no checksum and are accepted.
+func IsValidAddress(address string) bool {
+	if !strings.HasPrefix(address, "0x") || !common.IsHexAddress(address) {
+		return false
+	}
+	digits := address[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return true
+	}
+	return common.HexToAddress(address).Hex() == address
+}
+
+// RenderParameterFailure renders the failure to bind the request parameters. Validation failures are reported
+// parameter by parameter, the error code of the response is the one of the first invalid parameter.
+// Other failures, e.g. malformed json or a non-numeric page, are reported as ErrParameterInvalidNo.
+func RenderParameterFailure(ctx *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) == 0 {
+		RenderFailure(ctx, ErrParameterInvalidNo, err)
+		return
+	}
+
+	paramErrs := make([]*ParamError, 0, len(validationErrs))
+	var messages []string
+	for _, fieldErr := range validationErrs {
+		paramErr := newParamError(fieldErr)
+		paramErrs = append(paramErrs, paramErr)
+		messages = append(messages, fmt.Sprintf("%s %s", paramErr.Param, paramErr.Message))
+	}
+	response := NewResponse(paramErrs[0].Code, strings.Join(messages, "; "), nil)
+	response.Errors = paramErrs
+	ctx.JSON(http.StatusOK, response)
+}
+
+func newParamError(fieldErr validator.FieldError) *ParamError {
+	param := fieldErr.Field()
+	paramErr := &ParamError{Param: param, Code: ErrParameterInvalidNo, Message: paramErrorMessage(fieldErr)}
+	switch {
+	case fieldErr.Tag() == ValidationTagAddress:
+		paramErr.Code = ErrInvalidAddress
+	case fieldErr.Tag() == ValidationTagTxHash:
+		paramErr.Code = ErrInvalidTxHash
+	case paginationParams[param]:
+		// a zero page fails the required validation as well.
+		paramErr.Code = ErrInvalidPagination
+	case fieldErr.Tag() == "required":
+		paramErr.Code = ErrParameterMissing
+	}
+	return paramErr
+}
+
+func paramErrorMessage(fieldErr validator.FieldError) string {
+	unit := ""
+	if fieldErr.Kind() == reflect.Slice {
+		unit = " items"
+	}
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case ValidationTagAddress:
+		return "must be a 0x-prefixed hex address, matching its EIP-55 checksum if mixed-case"
+	case ValidationTagTxHash:
+		return "must be a 0x-prefixed 32-byte hex hash"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fieldErr.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fieldErr.Param(), unit)
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed the %s validation", fieldErr.Tag())
+	}
+}
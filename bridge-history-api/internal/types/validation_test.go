@@ -0,0 +1,70 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidAddress(t *testing.T) {
+	assert.True(t, IsValidAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	assert.True(t, IsValidAddress("0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"))
+	assert.True(t, IsValidAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"))
+	// wrong checksum
+	assert.False(t, IsValidAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"))
+	assert.False(t, IsValidAddress("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	assert.False(t, IsValidAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea"))
+	assert.False(t, IsValidAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beazz"))
+}
+
+func bindAndRender(t *testing.T, req interface{}, target string, body []byte) *Response {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	if body == nil {
+		ctx.Request = httptest.NewRequest("GET", target, nil)
+	} else {
+		ctx.Request = httptest.NewRequest("POST", target, bytes.NewReader(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+	}
+	if err := ctx.ShouldBind(req); err != nil {
+		RenderParameterFailure(ctx, err)
+	} else {
+		RenderSuccess(ctx, nil)
+	}
+
+	var resp Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return &resp
+}
+
+func TestRenderParameterFailure(t *testing.T) {
+	resp := bindAndRender(t, &QueryByAddressRequest{}, "/api/txs?address=0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed&page=1&page_size=10", nil)
+	assert.Equal(t, Success, resp.ErrCode)
+	assert.Empty(t, resp.Errors)
+
+	resp = bindAndRender(t, &QueryByAddressRequest{}, "/api/txs?page=1&page_size=10", nil)
+	assert.Equal(t, ErrParameterMissing, resp.ErrCode)
+	assert.Equal(t, []*ParamError{{Param: "address", Code: ErrParameterMissing, Message: "is required"}}, resp.Errors)
+
+	resp = bindAndRender(t, &QueryByAddressRequest{}, "/api/txs?address=0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD&page=0&page_size=1000", nil)
+	assert.Equal(t, ErrInvalidAddress, resp.ErrCode)
+	assert.Len(t, resp.Errors, 3)
+	assert.Equal(t, "address", resp.Errors[0].Param)
+	assert.Equal(t, &ParamError{Param: "page", Code: ErrInvalidPagination, Message: "is required"}, resp.Errors[1])
+	assert.Equal(t, &ParamError{Param: "page_size", Code: ErrInvalidPagination, Message: "must be at most 100"}, resp.Errors[2])
+
+	body := []byte(`{"txs": ["0x1c1a1ce5c0b4cf2e47e68ec2b6ad06e3a3d4a7b2c9a0e2de2a1bb7f3de1e5f42", "0x1234"]}`)
+	resp = bindAndRender(t, &QueryByHashRequest{}, "/api/txsbyhashes", body)
+	assert.Equal(t, ErrInvalidTxHash, resp.ErrCode)
+	assert.Equal(t, []*ParamError{{Param: "txs[1]", Code: ErrInvalidTxHash, Message: "must be a 0x-prefixed 32-byte hex hash"}}, resp.Errors)
+
+	// binding failures other than validation keep the generic code.
+	resp = bindAndRender(t, &QueryByHashRequest{}, "/api/txsbyhashes", []byte(`{"txs": `))
+	assert.Equal(t, ErrParameterInvalidNo, resp.ErrCode)
+	assert.Empty(t, resp.Errors)
+}
@@ -1,20 +1,29 @@
 package utils
 
 import (
+	"bytes"
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
+	"unicode"
 
+	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
-	"golang.org/x/sync/errgroup"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"golang.org/x/net/idna"
+
+	"scroll-tech/common/codec"
+	"scroll-tech/common/concurrency"
+	"scroll-tech/common/types/encoding"
 
 	backendabi "scroll-tech/bridge-history-api/abi"
 )
@@ -34,36 +43,16 @@ func GetBlockNumber(ctx context.Context, client *ethclient.Client, confirmations
 	return number, nil
 }
 
-// UnpackLog unpacks a retrieved log into the provided output structure.
-// @todo: add unit test.
-func UnpackLog(c *abi.ABI, out interface{}, event string, log types.Log) error {
-	if log.Topics[0] != c.Events[event].ID {
-		return fmt.Errorf("event signature mismatch")
-	}
-	if len(log.Data) > 0 {
-		if err := c.UnpackIntoInterface(out, event, log.Data); err != nil {
-			return err
-		}
+// GetFinalizedBlockNumber gets the number of the latest block finalized by the beacon chain
+func GetFinalizedBlockNumber(ctx context.Context, client *ethclient.Client) (uint64, error) {
+	header, err := client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return 0, err
 	}
-	var indexed abi.Arguments
-	for _, arg := range c.Events[event].Inputs {
-		if arg.Indexed {
-			indexed = append(indexed, arg)
-		}
+	if !header.Number.IsInt64() {
+		return 0, fmt.Errorf("received invalid finalized block number: %v", header.Number)
 	}
-	return abi.ParseTopics(out, indexed, log.Topics[1:])
-}
-
-// ComputeMessageHash compute the message hash
-func ComputeMessageHash(
-	sender common.Address,
-	target common.Address,
-	value *big.Int,
-	messageNonce *big.Int,
-	message []byte,
-) common.Hash {
-	data, _ := backendabi.IL2ScrollMessengerABI.Pack("relayMessage", sender, target, value, messageNonce, message)
-	return common.BytesToHash(crypto.Keccak256(data))
+	return header.Number.Uint64(), nil
 }
 
 type commitBatchArgs struct {
@@ -73,8 +62,26 @@ type commitBatchArgs struct {
 	SkippedL1MessageBitmap []byte
 }
 
-// GetBatchRangeFromCalldata find the block range from calldata, both inclusive.
-func GetBatchRangeFromCalldata(calldata []byte) (uint64, uint64, error) {
+// ErrUnsupportedCommitBatch is returned for the calldata of a commit tx of none of the known commit methods.
+var ErrUnsupportedCommitBatch = errors.New("unsupported commit batch calldata")
+
+// CommitBatchInfo is the info of a batch recovered from the calldata of its commit tx.
+type CommitBatchInfo struct {
+	Version encoding.CodecVersion
+	// StartBlockNumber and EndBlockNumber are the L2 block range of the batch, both inclusive, 0 for the genesis batch.
+	StartBlockNumber uint64
+	EndBlockNumber   uint64
+	NumChunks        int
+}
+
+// ParseCommitBatchCalldata decodes the calldata of a commitBatch or importGenesisBatch tx. The L2 block range is read
+// from the block contexts of the chunks, which stay in the calldata since codec v1, only the L2 txs move to the blob.
+// The errors of an unknown codec version wrap codec.ErrUnsupportedVersion, the ones of an unknown method
+// ErrUnsupportedCommitBatch.
+func ParseCommitBatchCalldata(calldata []byte) (*CommitBatchInfo, error) {
+	if len(calldata) < 4 {
+		return nil, fmt.Errorf("%w, calldata of %d bytes", ErrUnsupportedCommitBatch, len(calldata))
+	}
 	method := backendabi.IScrollChainABI.Methods["commitBatch"]
 	values, err := method.Inputs.Unpack(calldata[4:])
 	if err != nil {
@@ -83,64 +90,119 @@ func GetBatchRangeFromCalldata(calldata []byte) (uint64, uint64, error) {
 		_, err2 := method.Inputs.Unpack(calldata[4:])
 		if err2 == nil {
 			// genesis batch
-			return 0, 0, nil
+			return &CommitBatchInfo{Version: encoding.CodecV0}, nil
 		}
 		// none of "commitBatch" and "importGenesisBatch" match, give up
-		return 0, 0, err
+		return nil, fmt.Errorf("%w, error: %v", ErrUnsupportedCommitBatch, err)
 	}
 	args := commitBatchArgs{}
 	err = method.Inputs.Copy(&args, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args.Chunks) == 0 {
+		return nil, fmt.Errorf("%w: batch without chunks", codec.ErrInvalidData)
+	}
+	// the chunks are validated, malformed calldata is reported instead of crashing the fetcher.
+	version := encoding.CodecVersion(args.Version)
+	firstChunk, err := codec.DecodeChunk(version, args.Chunks[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the first chunk, error: %w", err)
+	}
+	lastChunk, err := codec.DecodeChunk(version, args.Chunks[len(args.Chunks)-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the last chunk, error: %w", err)
+	}
+	return &CommitBatchInfo{
+		Version:          version,
+		StartBlockNumber: firstChunk.Blocks[0].BlockNumber,
+		EndBlockNumber:   lastChunk.Blocks[len(lastChunk.Blocks)-1].BlockNumber,
+		NumChunks:        len(args.Chunks),
+	}, nil
+}
+
+// GetBatchRangeFromCalldata find the block range from calldata, both inclusive.
+func GetBatchRangeFromCalldata(calldata []byte) (uint64, uint64, error) {
+	info, err := ParseCommitBatchCalldata(calldata)
 	if err != nil {
 		return 0, 0, err
 	}
+	return info.StartBlockNumber, info.EndBlockNumber, nil
+}
 
-	var startBlock uint64
-	var finishBlock uint64
+// DefaultFilterAddressBatchSize is the max number of addresses per log filter when not configured, within the limits of
+// common RPC providers while still querying all the watched contracts of a fetcher in a single filter.
+const DefaultFilterAddressBatchSize = 20
 
-	// decode blocks from chunk and assume that there's no empty chunk
-	// |   1 byte   | 60 bytes | ... | 60 bytes |
-	// | num blocks |  block 1 | ... |  block n |
-	if len(args.Chunks) == 0 {
-		return 0, 0, errors.New("invalid chunks")
+// FilterLogsInAddressBatches filters the logs of query, splitting its addresses into groups of at most addressBatchSize
+// addresses, one filter per group, for RPC providers limiting the size of a filter. addressBatchSize defaults to
+// DefaultFilterAddressBatchSize if 0, and all addresses are queried in a single filter if it is negative.
+// Duplicated addresses are removed, and the merged logs are in canonical order.
+func FilterLogsInAddressBatches(ctx context.Context, filterer ethereum.LogFilterer, query ethereum.FilterQuery, addressBatchSize int) ([]types.Log, error) {
+	if addressBatchSize == 0 {
+		addressBatchSize = DefaultFilterAddressBatchSize
+	}
+
+	addresses := make([]common.Address, 0, len(query.Addresses))
+	seen := make(map[common.Address]struct{}, len(query.Addresses))
+	for _, address := range query.Addresses {
+		if _, found := seen[address]; found {
+			continue
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+
+	if addressBatchSize < 0 || addressBatchSize >= len(addresses) {
+		query.Addresses = addresses
+		return filterer.FilterLogs(ctx, query)
 	}
-	chunk := args.Chunks[0]
-	block := chunk[1:61] // first block in chunk
-	startBlock = binary.BigEndian.Uint64(block[0:8])
 
-	chunk = args.Chunks[len(args.Chunks)-1]
-	lastBlockIndex := int(chunk[0]) - 1
-	block = chunk[1+lastBlockIndex*60 : 1+lastBlockIndex*60+60] // last block in chunk
-	finishBlock = binary.BigEndian.Uint64(block[0:8])
+	var logs []types.Log
+	for start := 0; start < len(addresses); start += addressBatchSize {
+		end := start + addressBatchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		batchQuery := query
+		batchQuery.Addresses = addresses[start:end]
+		batchLogs, err := filterer.FilterLogs(ctx, batchQuery)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, batchLogs...)
+	}
 
-	return startBlock, finishBlock, err
+	// Parsers rely on the emission order, e.g. a gateway event is followed by the messenger event of the same tx.
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+	return logs, nil
 }
 
+// getBlocksConcurrency is the max number of blocks fetched concurrently by GetBlocksInRange.
+const getBlocksConcurrency = 32
+
 // GetBlocksInRange gets a batch of blocks for a block range [start, end] inclusive.
 func GetBlocksInRange(ctx context.Context, cli *ethclient.Client, start, end uint64) ([]*types.Block, error) {
-	var (
-		eg          errgroup.Group
-		blocks      = make([]*types.Block, end-start+1)
-		concurrency = 32
-		sem         = make(chan struct{}, concurrency)
-	)
-
+	blockNums := make([]uint64, 0, end-start+1)
 	for i := start; i <= end; i++ {
-		sem <- struct{}{} // Acquire a slot in the semaphore
-		blockNum := int64(i)
-		index := i - start
-		eg.Go(func() error {
-			defer func() { <-sem }() // Release the slot when done
-			block, err := cli.BlockByNumber(ctx, big.NewInt(blockNum))
-			if err != nil {
-				log.Error("Failed to fetch block number", "number", blockNum, "error", err)
-				return err
-			}
-			blocks[index] = block
-			return nil
-		})
-	}
-
-	if err := eg.Wait(); err != nil {
+		blockNums = append(blockNums, i)
+	}
+
+	blocks, err := concurrency.Map(ctx, blockNums, getBlocksConcurrency, func(ctx context.Context, blockNum uint64) (*types.Block, error) {
+		block, err := cli.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
+		if err != nil {
+			log.Error("Failed to fetch block number", "number", blockNum, "error", err)
+			return nil, err
+		}
+		return block, nil
+	})
+	if err != nil {
 		log.Error("Error waiting for block fetching routines", "error", err)
 		return nil, err
 	}
@@ -183,3 +245,164 @@ func GetSkippedQueueIndices(startIndex uint64, skippedBitmap *big.Int) []uint64
 	}
 	return indices
 }
+
+// ensProfile is the UTS-46 mapping ENSIP-15 builds upon: non-transitional, without the DNS hostname rules.
+var ensProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false), idna.CheckHyphens(false), idna.VerifyDNSLength(false))
+
+// ENSNormalize normalizes an ENS name with the UTS-46 mapping of ENSIP-15 (case folding, NFC, disallowed characters).
+// The emoji and confusable rules of ENSIP-15 are not applied, so callers displaying names must only show names
+// which are already in their normalized form.
+func ENSNormalize(name string) (string, error) {
+	normalized, err := ensProfile.ToUnicode(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid ENS name %q: %w", name, err)
+	}
+	for _, label := range strings.Split(normalized, ".") {
+		if label == "" {
+			return "", fmt.Errorf("invalid ENS name %q: empty label", name)
+		}
+	}
+	// without the STD3 rules the mapping keeps ASCII controls and spaces, which ENSIP-15 disallows.
+	for _, r := range normalized {
+		if unicode.IsControl(r) || unicode.IsSpace(r) {
+			return "", fmt.Errorf("invalid ENS name %q: disallowed rune %U", name, r)
+		}
+	}
+	return normalized, nil
+}
+
+// ENSNameHash computes the ENS namehash (EIP-137) of a dot-separated name, which must be normalized, see ENSNormalize.
+func ENSNameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256Hash([]byte(labels[i]))
+		node = Keccak2(node, labelHash)
+	}
+	return node
+}
+
+// ENSReverseNode returns the ENS reverse record node of an address, i.e., namehash("<addr>.addr.reverse").
+func ENSReverseNode(addr common.Address) common.Hash {
+	return ENSNameHash(strings.ToLower(addr.Hex()[2:]) + ".addr.reverse")
+}
+
+var (
+	// revertErrorSelector is the selector of Error(string), used by require and revert with a reason string.
+	revertErrorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	// revertPanicSelector is the selector of Panic(uint256), used by failed asserts and arithmetic errors.
+	revertPanicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// DecodeRevertReason decodes the revert data of a failed call into its error selector and a readable message.
+// Custom errors are returned with their selector and the hex encoded arguments, as their ABI is unknown.
+func DecodeRevertReason(data []byte) (string, string) {
+	if len(data) < 4 {
+		return "", ""
+	}
+	selector := hexutil.Encode(data[:4])
+
+	switch {
+	case bytes.Equal(data[:4], revertErrorSelector):
+		stringType, err := abi.NewType("string", "", nil)
+		if err != nil {
+			return selector, ""
+		}
+		values, err := (abi.Arguments{{Type: stringType}}).Unpack(data[4:])
+		if err != nil || len(values) != 1 {
+			return selector, hexutil.Encode(data[4:])
+		}
+		reason, ok := values[0].(string)
+		if !ok {
+			return selector, hexutil.Encode(data[4:])
+		}
+		return selector, reason
+	case bytes.Equal(data[:4], revertPanicSelector) && len(data) == 4+32:
+		return selector, fmt.Sprintf("panic code %s", hexutil.EncodeBig(new(big.Int).SetBytes(data[4:])))
+	default:
+		return selector, hexutil.Encode(data[4:])
+	}
+}
+
+// SplitCallData splits the call data attached to a deposit into its function selector and its hex encoded arguments.
+// Data too short to hold a selector is returned as arguments only.
+func SplitCallData(data []byte) (string, string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+	if len(data) < 4 {
+		return "", hexutil.Encode(data)
+	}
+	return hexutil.Encode(data[:4]), hexutil.Encode(data[4:])
+}
+
+// CallArg is a decoded argument of a contract call.
+type CallArg struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// DecodeCallArgs decodes the hex encoded arguments of a call whose selector is one of the known deposit call functions,
+// it returns the signature of the function and its arguments, or an empty signature if the call can not be decoded.
+// Addresses are checksummed, integers are decimal and bytes are hex encoded.
+func DecodeCallArgs(selector, args string) (string, []CallArg) {
+	selectorBytes, err := hexutil.Decode(selector)
+	if err != nil || len(selectorBytes) != 4 {
+		return "", nil
+	}
+	method, err := backendabi.IDepositCallABI.MethodById(selectorBytes)
+	if err != nil {
+		return "", nil
+	}
+	var data []byte
+	if args != "" {
+		if data, err = hexutil.Decode(args); err != nil {
+			return "", nil
+		}
+	}
+	values, err := method.Inputs.Unpack(data)
+	if err != nil || len(values) != len(method.Inputs) {
+		return "", nil
+	}
+	callArgs := make([]CallArg, len(values))
+	for i, value := range values {
+		callArgs[i] = CallArg{Name: method.Inputs[i].Name, Type: method.Inputs[i].Type.String(), Value: formatABIValue(value)}
+	}
+	return method.Sig, callArgs
+}
+
+func formatABIValue(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.String()
+	case *big.Int:
+		return v.String()
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	case []byte:
+		return hexutil.Encode(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SplitABIWords splits hex encoded ABI arguments into their 32-byte words, a trailing partial word is kept as is.
+func SplitABIWords(args string) []string {
+	data, err := hexutil.Decode(args)
+	if err != nil || len(data) == 0 {
+		return []string{}
+	}
+	words := make([]string, 0, (len(data)+31)/32)
+	for start := 0; start < len(data); start += 32 {
+		end := start + 32
+		if end > len(data) {
+			end = len(data)
+		}
+		words = append(words, hexutil.Encode(data[start:end]))
+	}
+	return words
+}
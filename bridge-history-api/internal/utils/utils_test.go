@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
+	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,3 +92,189 @@ func TestGetSkippedQueueIndices(t *testing.T) {
 		assert.Equal(t, test.expected, got)
 	}
 }
+
+// TestENSNameHash tests the ENSNameHash function with the EIP-137 test vectors
+func TestENSNameHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected common.Hash
+	}{
+		{"", common.Hash{}},
+		{"eth", common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae")},
+		{"foo.eth", common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f")},
+		{"addr.reverse", common.HexToHash("0x91d1777781884d03a6757a803996e38de2a42967fb37eeaca72729271025a9e2")},
+	}
+
+	for _, test := range tests {
+		got := ENSNameHash(test.name)
+		assert.Equal(t, test.expected, got)
+	}
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	// empty revert
+	selector, reason := DecodeRevertReason(nil)
+	assert.Empty(t, selector)
+	assert.Empty(t, reason)
+
+	// Error(string): revert("Ownable: caller is not the owner")
+	selector, reason = DecodeRevertReason(common.Hex2Bytes("08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000204f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e6572"))
+	assert.Equal(t, "0x08c379a0", selector)
+	assert.Equal(t, "Ownable: caller is not the owner", reason)
+
+	// Panic(uint256): arithmetic overflow
+	selector, reason = DecodeRevertReason(common.Hex2Bytes("4e487b710000000000000000000000000000000000000000000000000000000000000011"))
+	assert.Equal(t, "0x4e487b71", selector)
+	assert.Equal(t, "panic code 0x11", reason)
+
+	// custom error with arguments
+	selector, reason = DecodeRevertReason(common.Hex2Bytes("1e4fbdf70000000000000000000000000000000000000000000000000000000000000001"))
+	assert.Equal(t, "0x1e4fbdf7", selector)
+	assert.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000001", reason)
+}
+
+type mockLogFilterer struct {
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+}
+
+func (m *mockLogFilterer) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.queries = append(m.queries, q)
+	var logs []types.Log
+	for _, l := range m.logs {
+		for _, address := range q.Addresses {
+			if l.Address == address {
+				logs = append(logs, l)
+				break
+			}
+		}
+	}
+	return logs, nil
+}
+
+func (m *mockLogFilterer) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, nil
+}
+
+func TestFilterLogsInAddressBatches(t *testing.T) {
+	addresses := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+	filterer := &mockLogFilterer{logs: []types.Log{
+		{Address: addresses[2], BlockNumber: 1, Index: 0},
+		{Address: addresses[0], BlockNumber: 1, Index: 1},
+		{Address: addresses[1], BlockNumber: 2, Index: 0},
+		{Address: addresses[0], BlockNumber: 2, Index: 1},
+	}}
+	query := ethereum.FilterQuery{Addresses: append(addresses, addresses[0])}
+
+	// single filter with deduplicated addresses
+	logs, err := FilterLogsInAddressBatches(context.Background(), filterer, query, -1)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 4)
+	assert.Len(t, filterer.queries, 1)
+	assert.Equal(t, addresses, filterer.queries[0].Addresses)
+
+	// the default batch size fits the addresses in a single filter
+	filterer.queries = nil
+	logs, err = FilterLogsInAddressBatches(context.Background(), filterer, query, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, filterer.logs, logs)
+	assert.Len(t, filterer.queries, 1)
+
+	// addresses beyond the default batch size are split
+	var manyAddresses []common.Address
+	for i := 0; i < DefaultFilterAddressBatchSize+1; i++ {
+		manyAddresses = append(manyAddresses, common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+	filterer.queries = nil
+	logs, err = FilterLogsInAddressBatches(context.Background(), filterer, ethereum.FilterQuery{Addresses: manyAddresses}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, filterer.logs, logs)
+	assert.Len(t, filterer.queries, 2)
+	assert.Len(t, filterer.queries[0].Addresses, DefaultFilterAddressBatchSize)
+
+	// batched filters, logs in canonical order
+	filterer.queries = nil
+	logs, err = FilterLogsInAddressBatches(context.Background(), filterer, query, 2)
+	assert.NoError(t, err)
+	assert.Len(t, filterer.queries, 2)
+	assert.Equal(t, filterer.logs, logs)
+}
+
+func TestSplitCallData(t *testing.T) {
+	selector, args := SplitCallData(nil)
+	assert.Empty(t, selector)
+	assert.Empty(t, args)
+
+	selector, args = SplitCallData([]byte{0x01, 0x02})
+	assert.Empty(t, selector)
+	assert.Equal(t, "0x0102", args)
+
+	// transfer(address,uint256)
+	data := common.Hex2Bytes("a9059cbb0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000a")
+	selector, args = SplitCallData(data)
+	assert.Equal(t, "0xa9059cbb", selector)
+	assert.Equal(t, []string{
+		"0x0000000000000000000000000000000000000000000000000000000000000001",
+		"0x000000000000000000000000000000000000000000000000000000000000000a",
+	}, SplitABIWords(args))
+
+	assert.Equal(t, []string{}, SplitABIWords(""))
+	assert.Equal(t, []string{"0x0102"}, SplitABIWords("0x0102"))
+}
+
+func TestDecodeCallArgs(t *testing.T) {
+	// transfer(address,uint256)
+	method, args := DecodeCallArgs("0xa9059cbb", "0x0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000a")
+	assert.Equal(t, "transfer(address,uint256)", method)
+	assert.Equal(t, []CallArg{
+		{Name: "to", Type: "address", Value: "0x0000000000000000000000000000000000000001"},
+		{Name: "amount", Type: "uint256", Value: "10"},
+	}, args)
+
+	// deposit() of weth takes no arguments
+	method, args = DecodeCallArgs("0xd0e30db0", "")
+	assert.Equal(t, "deposit()", method)
+	assert.Empty(t, args)
+
+	// unknown selector
+	method, args = DecodeCallArgs("0x01020304", "0x")
+	assert.Empty(t, method)
+	assert.Nil(t, args)
+
+	// truncated arguments
+	method, args = DecodeCallArgs("0xa9059cbb", "0x0000000000000000000000000000000000000000000000000000000000000001")
+	assert.Empty(t, method)
+	assert.Nil(t, args)
+}
+
+func TestENSNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		valid    bool
+	}{
+		{"vitalik.eth", "vitalik.eth", true},
+		{"Vitalik.ETH", "vitalik.eth", true},
+		{"ﬁnance.eth", "finance.eth", true},
+		{"faß.eth", "faß.eth", true},
+		{"under_score.eth", "under_score.eth", true},
+		{"foo..eth", "", false},
+		{".eth", "", false},
+		{"foo\uFFFD.eth", "", false},
+		{"foo\u0000.eth", "", false},
+		{"foo bar.eth", "", false},
+	}
+
+	for _, test := range tests {
+		got, err := ENSNormalize(test.name)
+		if !test.valid {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, got)
+	}
+	normalized, err := ENSNormalize("Foo.ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, ENSNameHash("foo.eth"), ENSNameHash(normalized))
+}
@@ -0,0 +1,203 @@
+// Package chains defines the named network profiles shared by all binaries, so that config files
+// only need to carry the values that differ from the profile of the network they run on.
+package chains
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+const (
+	// Mainnet is the name of the Scroll mainnet profile.
+	Mainnet = "mainnet"
+	// Sepolia is the name of the Scroll Sepolia testnet profile.
+	Sepolia = "sepolia"
+)
+
+// L1Contracts holds the addresses of the Scroll contracts deployed on layer 1.
+type L1Contracts struct {
+	Messenger            common.Address
+	GatewayRouter        common.Address
+	ETHGateway           common.Address
+	WETHGateway          common.Address
+	StandardERC20Gateway common.Address
+	CustomERC20Gateway   common.Address
+	ERC721Gateway        common.Address
+	ERC1155Gateway       common.Address
+	USDCGateway          common.Address
+	LIDOGateway          common.Address
+	DAIGateway           common.Address
+	ScrollChain          common.Address
+	MessageQueue         common.Address
+}
+
+// L2Contracts holds the addresses of the Scroll contracts deployed on layer 2.
+type L2Contracts struct {
+	Messenger            common.Address
+	GatewayRouter        common.Address
+	ETHGateway           common.Address
+	WETHGateway          common.Address
+	StandardERC20Gateway common.Address
+	CustomERC20Gateway   common.Address
+	ERC721Gateway        common.Address
+	ERC1155Gateway       common.Address
+	USDCGateway          common.Address
+	LIDOGateway          common.Address
+	DAIGateway           common.Address
+	MessageQueue         common.Address
+	L1GasPriceOracle     common.Address
+	TxFeeVault           common.Address
+}
+
+// Network is the profile of a Scroll network. Zero values are not deployed on the network, e.g. the USDC gateway
+// on Sepolia, and must be configured explicitly if needed. Local devnets deploy their contracts at different
+// addresses on every run, so they have no profile.
+type Network struct {
+	Name      string
+	L1ChainID uint64
+	L2ChainID uint64
+	// L1StartHeight is the L1 block height the Scroll contracts were deployed at.
+	L1StartHeight uint64
+	// L2ForkHeights maps the names of the block height based L2 hard forks to their activation heights.
+	L2ForkHeights map[string]uint64
+	L1Contracts   L1Contracts
+	L2Contracts   L2Contracts
+}
+
+// The L2 message queue, L1 gas price oracle and L2 tx fee vault are predeployed at the same addresses on every Scroll
+// network.
+var (
+	l2MessageQueuePredeploy   = common.HexToAddress("0x5300000000000000000000000000000000000000")
+	l1GasPriceOraclePredeploy = common.HexToAddress("0x5300000000000000000000000000000000000002")
+	l2TxFeeVaultPredeploy     = common.HexToAddress("0x5300000000000000000000000000000000000005")
+)
+
+var networks = map[string]*Network{
+	Mainnet: {
+		Name:          Mainnet,
+		L1ChainID:     1,
+		L2ChainID:     534352,
+		L1StartHeight: 18306000,
+		L2ForkHeights: map[string]uint64{
+			"bernoulli": 5220340,
+			"curie":     7096836,
+		},
+		L1Contracts: L1Contracts{
+			Messenger:            common.HexToAddress("0x6774Bcbd5ceCeF1336b5300fb5186a12DDD8b367"),
+			GatewayRouter:        common.HexToAddress("0xF8B1378579659D8F7EE5f3C929c2f3E332E41Fd6"),
+			ETHGateway:           common.HexToAddress("0x7F2b8C31F88B6006c382775eea88297Ec1e3E905"),
+			WETHGateway:          common.HexToAddress("0x7AC440cAe8EB6328de4fA621163a792c1EA9D4fE"),
+			StandardERC20Gateway: common.HexToAddress("0xD8A791fE2bE73eb6E6cF1eb0cb3F36adC9B3F8f9"),
+			CustomERC20Gateway:   common.HexToAddress("0xb2b10a289A229415a124EFDeF310C10cb004B6ff"),
+			ERC721Gateway:        common.HexToAddress("0x6260aF48e8948617b8FA17F4e5CEa2d21D21554B"),
+			ERC1155Gateway:       common.HexToAddress("0xb94f7F6ABcb811c5Ac709dE14E37590fcCd975B6"),
+			USDCGateway:          common.HexToAddress("0xf1AF3b23DE0A5Ca3CAb7261cb0061C0D779A5c7B"),
+			LIDOGateway:          common.HexToAddress("0x6625C6332c9F91F2D27c304E729B86db87A3f504"),
+			DAIGateway:           common.HexToAddress("0x67260A8B73C5B77B55c1805218A42A7A6F98F515"),
+			ScrollChain:          common.HexToAddress("0xa13BAF47339d63B743e7Da8741db5456DAc1E556"),
+			MessageQueue:         common.HexToAddress("0x0d7E906BD9cAFa154b048cFa766Cc1E54E39AF9B"),
+		},
+		L2Contracts: L2Contracts{
+			Messenger:            common.HexToAddress("0x781e90f1c8Fc4611c9b7497C3B47F99Ef6969CbC"),
+			GatewayRouter:        common.HexToAddress("0x4C0926FF5252A435FD19e10ED15e5a249Ba19d79"),
+			ETHGateway:           common.HexToAddress("0x6EA73e05AdC79974B931123675ea8F78FfdacDF0"),
+			WETHGateway:          common.HexToAddress("0x7003E7B7186f0E6601203b99F7B8DECBfA391cf9"),
+			StandardERC20Gateway: common.HexToAddress("0xE2b4795039517653c5Ae8C2A9BFdd783b48f447A"),
+			CustomERC20Gateway:   common.HexToAddress("0x64CCBE37c9A82D85A1F2E74649b7A42923067988"),
+			ERC721Gateway:        common.HexToAddress("0x7bC08E1c04fb41d75F1410363F0c5746Eae80582"),
+			ERC1155Gateway:       common.HexToAddress("0x62597Cc19703aF10B58feF87B0d5D29eFE263bcc"),
+			USDCGateway:          common.HexToAddress("0x33B60d5Dd260d453cAC3782b0bDC01ce84672142"),
+			LIDOGateway:          common.HexToAddress("0x8aE8f22226B9d789A36AC81474e633f8bE2856c9"),
+			DAIGateway:           common.HexToAddress("0xaC78dff3A87b5b534e366A93E785a0ce8fA6Cc62"),
+			MessageQueue:         l2MessageQueuePredeploy,
+			L1GasPriceOracle:     l1GasPriceOraclePredeploy,
+			TxFeeVault:           l2TxFeeVaultPredeploy,
+		},
+	},
+	Sepolia: {
+		Name:          Sepolia,
+		L1ChainID:     11155111,
+		L2ChainID:     534351,
+		L1StartHeight: 4038000,
+		L2ForkHeights: map[string]uint64{
+			"bernoulli": 3747132,
+			"curie":     4740239,
+		},
+		L1Contracts: L1Contracts{
+			Messenger:            common.HexToAddress("0x50c7d3e7f7c656493D1D76aaa1a836CedfCBB16A"),
+			GatewayRouter:        common.HexToAddress("0x13FBE0D0e5552b8c9c4AE9e2435F38f37355998a"),
+			ETHGateway:           common.HexToAddress("0x8A54A2347Da2562917304141ab67324615e9866d"),
+			WETHGateway:          common.HexToAddress("0x3dA0BF44814cfC678376b3311838272158211695"),
+			StandardERC20Gateway: common.HexToAddress("0x65D123d6389b900d954677c26327bfc1C3e88A13"),
+			CustomERC20Gateway:   common.HexToAddress("0x31C994F2017E71b82fd4D8118F140c81215bbb37"),
+			ERC721Gateway:        common.HexToAddress("0xEF27A5E63aa3f1B8312f744b9b4DcEB910Ba77AC"),
+			ERC1155Gateway:       common.HexToAddress("0xa5Df8530766A85936EE3E139dECE3bF081c83146"),
+			ScrollChain:          common.HexToAddress("0x2D567EcE699Eabe5afCd141eDB7A4f2D0D6ce8a0"),
+			MessageQueue:         common.HexToAddress("0xF0B2293F5D834eAe920c6974D50957A1732de763"),
+		},
+		L2Contracts: L2Contracts{
+			Messenger:            common.HexToAddress("0xBa50f5340FB9F3Bd074bD638c9BE13eCB36E603d"),
+			GatewayRouter:        common.HexToAddress("0x9aD3c5617eCAa556d6E166787A97081907171230"),
+			ETHGateway:           common.HexToAddress("0x91e8ADDFe1358aCa5314c644312d38237fC1101C"),
+			WETHGateway:          common.HexToAddress("0x481B20A927206aF7A754dB8b904B052e2781ea27"),
+			StandardERC20Gateway: common.HexToAddress("0xaDcA915b1041C7a1f0c9fC2B7EDA8Df0e3F7aAa2"),
+			CustomERC20Gateway:   common.HexToAddress("0x058dec71E53079F9ED053F3a0bBca877F6f3eAcf"),
+			ERC721Gateway:        common.HexToAddress("0x179B9415194B67DC3c0b8760E075cD4415785c97"),
+			ERC1155Gateway:       common.HexToAddress("0xe17C9b9C66FAF07753cdB04316D09f52144612A5"),
+			MessageQueue:         l2MessageQueuePredeploy,
+			L1GasPriceOracle:     l1GasPriceOraclePredeploy,
+			TxFeeVault:           l2TxFeeVaultPredeploy,
+		},
+	},
+}
+
+// Get returns the profile of the named network.
+func Get(name string) (*Network, error) {
+	network, ok := networks[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q, supported networks: %s", name, strings.Join(Names(), ", "))
+	}
+	profile := *network
+	profile.L2ForkHeights = make(map[string]uint64, len(network.L2ForkHeights))
+	for name, height := range network.L2ForkHeights {
+		profile.L2ForkHeights[name] = height
+	}
+	return &profile, nil
+}
+
+// Names returns the names of all known networks.
+func Names() []string {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddressOr returns addr, or the hex string of fallback if addr is empty and fallback is known.
+func AddressOr(addr string, fallback common.Address) string {
+	if addr != "" || fallback == (common.Address{}) {
+		return addr
+	}
+	return fallback.Hex()
+}
+
+// FillForkHeights adds the fork heights of the network missing in forkHeights, returning the result.
+func (n *Network) FillForkHeights(forkHeights map[string]uint64) map[string]uint64 {
+	if len(n.L2ForkHeights) == 0 {
+		return forkHeights
+	}
+	if forkHeights == nil {
+		forkHeights = make(map[string]uint64, len(n.L2ForkHeights))
+	}
+	for name, height := range n.L2ForkHeights {
+		if _, ok := forkHeights[name]; !ok {
+			forkHeights[name] = height
+		}
+	}
+	return forkHeights
+}
@@ -0,0 +1,60 @@
+package chains
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	for _, name := range Names() {
+		network, err := Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, network.Name)
+		assert.NotZero(t, network.L1ChainID)
+		assert.NotZero(t, network.L2ChainID)
+		assert.NotZero(t, network.L1StartHeight)
+		assert.NotEmpty(t, network.L2ForkHeights)
+		assert.NotZero(t, network.L1Contracts.Messenger)
+		assert.NotZero(t, network.L1Contracts.ScrollChain)
+		assert.NotZero(t, network.L1Contracts.MessageQueue)
+		assert.NotZero(t, network.L2Contracts.Messenger)
+	}
+
+	network, err := Get("Mainnet")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(534352), network.L2ChainID)
+
+	// profiles are copies, modifying them does not affect later lookups
+	network.L1StartHeight = 0
+	network.L2ForkHeights["curie"] = 0
+	network, err = Get(Mainnet)
+	assert.NoError(t, err)
+	assert.NotZero(t, network.L1StartHeight)
+	assert.NotZero(t, network.L2ForkHeights["curie"])
+
+	_, err = Get("unknown")
+	assert.ErrorContains(t, err, "unknown network")
+}
+
+func TestAddressOr(t *testing.T) {
+	fallback := common.HexToAddress("0x1")
+	assert.Equal(t, "0x2", AddressOr("0x2", fallback))
+	assert.Equal(t, fallback.Hex(), AddressOr("", fallback))
+	assert.Equal(t, "", AddressOr("", common.Address{}))
+}
+
+func TestFillForkHeights(t *testing.T) {
+	network, err := Get(Mainnet)
+	assert.NoError(t, err)
+
+	forkHeights := network.FillForkHeights(nil)
+	assert.Equal(t, network.L2ForkHeights, forkHeights)
+
+	// configured heights take precedence
+	forkHeights = network.FillForkHeights(map[string]uint64{"curie": 1, "darwin": 2})
+	assert.Equal(t, uint64(1), forkHeights["curie"])
+	assert.Equal(t, uint64(2), forkHeights["darwin"])
+	assert.Equal(t, network.L2ForkHeights["bernoulli"], forkHeights["bernoulli"])
+}
@@ -0,0 +1,50 @@
+// Package clock abstracts the time of the timeout watchers, pruning jobs and schedulers, so that their tests move
+// a Fake clock forward instead of sleeping.
+package clock
+
+import "time"
+
+// Clock tells the time and schedules on it.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the time once d elapsed.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a ticker receiving the time every d, d is positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker receives the time periodically until stopped.
+type Ticker interface {
+	// C returns the channel of the ticks. As with time.Ticker, a tick is dropped when the former one was not received.
+	C() <-chan time.Time
+	// Stop stops the ticks, the channel is not closed.
+	Stop()
+}
+
+// New returns the clock of the time package.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
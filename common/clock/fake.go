@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only moves forward on Advance. Its timers and tickers fire during Advance, in the order of
+// their deadlines.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	// changed is closed and replaced whenever a waiter is added, to wake up BlockUntil.
+	changed chan struct{}
+}
+
+// fakeWaiter is a pending timer of After, or a ticker when its period is positive.
+type fakeWaiter struct {
+	clock  *Fake
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFake returns a fake clock starting at now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel receiving the time once the fake clock advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{clock: f, at: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+	f.addLocked(w)
+	return w.ch
+}
+
+// NewTicker returns a ticker receiving the time every d the fake clock advances by.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake.NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{clock: f, at: f.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	f.addLocked(w)
+	return w
+}
+
+// Advance moves the fake clock forward by d, and fires the timers and tickers due meanwhile. A ticker due several
+// times fires once per period, dropping the ticks its receiver did not take in time, as time.Ticker does.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		next := f.nextLocked(end)
+		if next == nil {
+			break
+		}
+		f.now = next.at
+		select {
+		case next.ch <- next.at:
+		default:
+		}
+		if next.period > 0 {
+			next.at = next.at.Add(next.period)
+		} else {
+			f.removeLocked(next)
+		}
+	}
+	f.now = end
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending, i.e. neither fired nor stopped, so that a test
+// advances the clock only once the code under test is waiting on it.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		pending, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (f *Fake) addLocked(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *Fake) removeLocked(w *fakeWaiter) {
+	for i, waiter := range f.waiters {
+		if waiter == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// nextLocked returns the earliest waiter due no later than end, nil if none.
+func (f *Fake) nextLocked(end time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range f.waiters {
+		if !w.at.After(end) && (next == nil || w.at.Before(next.at)) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	w.clock.removeLocked(w)
+}
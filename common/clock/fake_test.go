@@ -0,0 +1,64 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func received(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-ch:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFake(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	clk := NewFake(start)
+	assert.Equal(t, start, clk.Now())
+
+	after := clk.After(10 * time.Second)
+	ticker := clk.NewTicker(3 * time.Second)
+	clk.BlockUntil(2)
+
+	clk.Advance(2 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), clk.Now())
+	_, ok := received(ticker.C())
+	assert.False(t, ok)
+
+	clk.Advance(time.Second)
+	tick, ok := received(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(3*time.Second), tick)
+
+	// the ticks not received in time are dropped, the timer fires once.
+	clk.Advance(10 * time.Second)
+	tick, ok = received(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(6*time.Second), tick)
+	_, ok = received(ticker.C())
+	assert.False(t, ok)
+	fired, ok := received(after)
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(10*time.Second), fired)
+	assert.Equal(t, start.Add(13*time.Second), clk.Now())
+
+	// a stopped ticker no longer ticks, an elapsed timer fires at once.
+	ticker.Stop()
+	clk.Advance(time.Minute)
+	_, ok = received(ticker.C())
+	assert.False(t, ok)
+	_, ok = received(clk.After(0))
+	assert.True(t, ok)
+
+	// BlockUntil returns once the code under test waits on the clock.
+	go func() {
+		<-clk.After(time.Second)
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+}
@@ -0,0 +1,169 @@
+package codec
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/encoding/codecv1"
+)
+
+const (
+	batchHeaderV0Length = 89
+	batchHeaderV1Length = 121
+	// bitmapWordLength is the length of a word of the skipped L1 message bitmap, covering 256 messages.
+	bitmapWordLength = 32
+)
+
+// BatchHeader is the header of a committed batch, whose hash identifies the batch in the rollup contract.
+type BatchHeader struct {
+	Version              encoding.CodecVersion
+	BatchIndex           uint64
+	L1MessagePopped      uint64
+	TotalL1MessagePopped uint64
+	DataHash             common.Hash
+	// BlobVersionedHash is the versioned hash of the blob of the batch, zero before codec v1.
+	BlobVersionedHash      common.Hash
+	ParentBatchHash        common.Hash
+	SkippedL1MessageBitmap []byte
+}
+
+// NewBatchHeader returns the header of the batch encoded with the codec version.
+func NewBatchHeader(version encoding.CodecVersion, batch *encoding.Batch) (*BatchHeader, error) {
+	switch version {
+	case encoding.CodecV0:
+		daBatch, err := codecv0.NewDABatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		return &BatchHeader{
+			Version:                version,
+			BatchIndex:             daBatch.BatchIndex,
+			L1MessagePopped:        daBatch.L1MessagePopped,
+			TotalL1MessagePopped:   daBatch.TotalL1MessagePopped,
+			DataHash:               daBatch.DataHash,
+			ParentBatchHash:        daBatch.ParentBatchHash,
+			SkippedL1MessageBitmap: daBatch.SkippedL1MessageBitmap,
+		}, nil
+	case encoding.CodecV1:
+		daBatch, err := codecv1.NewDABatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		return &BatchHeader{
+			Version:                version,
+			BatchIndex:             daBatch.BatchIndex,
+			L1MessagePopped:        daBatch.L1MessagePopped,
+			TotalL1MessagePopped:   daBatch.TotalL1MessagePopped,
+			DataHash:               daBatch.DataHash,
+			BlobVersionedHash:      daBatch.BlobVersionedHash,
+			ParentBatchHash:        daBatch.ParentBatchHash,
+			SkippedL1MessageBitmap: daBatch.SkippedL1MessageBitmap,
+		}, nil
+	default:
+		return nil, checkVersion(version)
+	}
+}
+
+// DecodeBatchHeader decodes and validates an encoded batch header, of the codec version of its first byte.
+func DecodeBatchHeader(data []byte) (*BatchHeader, error) {
+	if len(data) == 0 {
+		return nil, invalidDataError("empty batch header")
+	}
+	version := encoding.CodecVersion(data[0])
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+	fixedLength := batchHeaderLength(version)
+	if len(data) < fixedLength {
+		return nil, invalidDataError("batch header of version %d is %d bytes, expected at least %d", version, len(data), fixedLength)
+	}
+
+	h := &BatchHeader{
+		Version:              version,
+		BatchIndex:           binary.BigEndian.Uint64(data[1:9]),
+		L1MessagePopped:      binary.BigEndian.Uint64(data[9:17]),
+		TotalL1MessagePopped: binary.BigEndian.Uint64(data[17:25]),
+		DataHash:             common.BytesToHash(data[25:57]),
+	}
+	parentHashOffset := 57
+	if version >= encoding.CodecV1 {
+		h.BlobVersionedHash = common.BytesToHash(data[57:89])
+		parentHashOffset = 89
+	}
+	h.ParentBatchHash = common.BytesToHash(data[parentHashOffset : parentHashOffset+32])
+	h.SkippedL1MessageBitmap = append([]byte{}, data[fixedLength:]...)
+
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Validate checks the consistency of the header fields.
+func (h *BatchHeader) Validate() error {
+	if err := checkVersion(h.Version); err != nil {
+		return err
+	}
+	if h.TotalL1MessagePopped < h.L1MessagePopped {
+		return invalidDataError("total L1 messages popped %d less than the %d popped by the batch", h.TotalL1MessagePopped, h.L1MessagePopped)
+	}
+	// the bitmap has a bit per popped message, in words of 256 bits.
+	words := h.L1MessagePopped / 256
+	if h.L1MessagePopped%256 != 0 {
+		words++
+	}
+	if uint64(len(h.SkippedL1MessageBitmap))/bitmapWordLength != words || len(h.SkippedL1MessageBitmap)%bitmapWordLength != 0 {
+		return invalidDataError("skipped L1 message bitmap is %d bytes for %d popped messages, expected %d words of %d bytes",
+			len(h.SkippedL1MessageBitmap), h.L1MessagePopped, words, bitmapWordLength)
+	}
+	if h.Version < encoding.CodecV1 && h.BlobVersionedHash != (common.Hash{}) {
+		return invalidDataError("blob versioned hash in a batch header of version %d", h.Version)
+	}
+	return nil
+}
+
+// IsSkipped returns whether the i-th L1 message popped by the batch was skipped, each word of the bitmap is a big
+// endian uint256 whose bit i%256 is the one of the message.
+func (h *BatchHeader) IsSkipped(i uint64) bool {
+	if i >= h.L1MessagePopped || (i/256+1)*bitmapWordLength > uint64(len(h.SkippedL1MessageBitmap)) {
+		return false
+	}
+	word, bit := i/256, i%256
+	b := h.SkippedL1MessageBitmap[word*bitmapWordLength+bitmapWordLength-1-bit/8]
+	return b&(1<<(bit%8)) != 0
+}
+
+// Encode serializes the header as committed to L1.
+func (h *BatchHeader) Encode() []byte {
+	fixedLength := batchHeaderLength(h.Version)
+	data := make([]byte, fixedLength+len(h.SkippedL1MessageBitmap))
+	data[0] = byte(h.Version)
+	binary.BigEndian.PutUint64(data[1:], h.BatchIndex)
+	binary.BigEndian.PutUint64(data[9:], h.L1MessagePopped)
+	binary.BigEndian.PutUint64(data[17:], h.TotalL1MessagePopped)
+	copy(data[25:], h.DataHash[:])
+	if h.Version >= encoding.CodecV1 {
+		copy(data[57:], h.BlobVersionedHash[:])
+		copy(data[89:], h.ParentBatchHash[:])
+	} else {
+		copy(data[57:], h.ParentBatchHash[:])
+	}
+	copy(data[fixedLength:], h.SkippedL1MessageBitmap)
+	return data
+}
+
+// Hash returns the batch hash, the keccak256 of the encoded header.
+func (h *BatchHeader) Hash() common.Hash {
+	return crypto.Keccak256Hash(h.Encode())
+}
+
+func batchHeaderLength(version encoding.CodecVersion) int {
+	if version >= encoding.CodecV1 {
+		return batchHeaderV1Length
+	}
+	return batchHeaderV0Length
+}
@@ -0,0 +1,150 @@
+package codec
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/rlp"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv1"
+)
+
+const (
+	blobFieldElementLength = 32
+	// blobPayloadCapacity is the number of payload bytes of a blob, the first byte of each field element is 0 so that
+	// the element is less than the BLS modulus.
+	blobPayloadCapacity = len(kzg4844.Blob{}) / blobFieldElementLength * (blobFieldElementLength - 1)
+	// blobChunkSizeLength is the length of the size of a chunk in the blob metadata.
+	blobChunkSizeLength = 4
+)
+
+// BlobPayload is the payload of the blob of a batch since codec v1, the L2 txs of its chunks.
+type BlobPayload struct {
+	// Chunks are the concatenated RLP encoded L2 txs of each chunk of the batch, SplitTransactions splits them.
+	Chunks [][]byte
+}
+
+// DecodeBlobPayload decodes and validates the payload of the blob of a batch of the codec version.
+func DecodeBlobPayload(version encoding.CodecVersion, blob *kzg4844.Blob) (*BlobPayload, error) {
+	if err := checkBlobVersion(version); err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, blobPayloadCapacity)
+	for i := 0; i < len(blob); i += blobFieldElementLength {
+		if blob[i] != 0 {
+			return nil, invalidDataError("first byte of blob field element %d is %d, expected 0", i/blobFieldElementLength, blob[i])
+		}
+		data = append(data, blob[i+1:i+blobFieldElementLength]...)
+	}
+
+	metadataLength := blobMetadataLength()
+	numChunks := int(binary.BigEndian.Uint16(data))
+	if numChunks == 0 || numChunks > codecv1.MaxNumChunks {
+		return nil, invalidDataError("blob of %d chunks, expected between 1 and %d", numChunks, codecv1.MaxNumChunks)
+	}
+	p := &BlobPayload{Chunks: make([][]byte, 0, numChunks)}
+	offset := metadataLength
+	for i := 0; i < codecv1.MaxNumChunks; i++ {
+		chunkSize := int(binary.BigEndian.Uint32(data[2+i*blobChunkSizeLength:]))
+		if i >= numChunks {
+			if chunkSize != 0 {
+				return nil, invalidDataError("size %d of chunk %d of a blob of %d chunks", chunkSize, i, numChunks)
+			}
+			continue
+		}
+		if chunkSize > len(data)-offset {
+			return nil, invalidDataError("chunk %d is %d bytes, only %d left in the blob", i, chunkSize, len(data)-offset)
+		}
+		p.Chunks = append(p.Chunks, data[offset:offset+chunkSize:offset+chunkSize])
+		offset += chunkSize
+	}
+	for i := offset; i < len(data); i++ {
+		if data[i] != 0 {
+			return nil, invalidDataError("non zero byte after the %d bytes of the blob payload", offset)
+		}
+	}
+	return p, nil
+}
+
+// Validate checks that the payload fits in a blob of the codec version.
+func (p *BlobPayload) Validate(version encoding.CodecVersion) error {
+	if err := checkBlobVersion(version); err != nil {
+		return err
+	}
+	if len(p.Chunks) == 0 || len(p.Chunks) > codecv1.MaxNumChunks {
+		return invalidDataError("blob of %d chunks, expected between 1 and %d", len(p.Chunks), codecv1.MaxNumChunks)
+	}
+	size := blobMetadataLength()
+	for _, chunk := range p.Chunks {
+		size += len(chunk)
+	}
+	if size > blobPayloadCapacity {
+		return invalidDataError("blob payload is %d bytes, more than the %d of a blob", size, blobPayloadCapacity)
+	}
+	return nil
+}
+
+// Encode returns the blob of the payload of the codec version, as committed with the batch.
+func (p *BlobPayload) Encode(version encoding.CodecVersion) (*kzg4844.Blob, error) {
+	if err := p.Validate(version); err != nil {
+		return nil, err
+	}
+	data := make([]byte, blobMetadataLength())
+	binary.BigEndian.PutUint16(data, uint16(len(p.Chunks)))
+	for i, chunk := range p.Chunks {
+		binary.BigEndian.PutUint32(data[2+i*blobChunkSizeLength:], uint32(len(chunk)))
+		data = append(data, chunk...)
+	}
+
+	var blob kzg4844.Blob
+	for i := 0; i*(blobFieldElementLength-1) < len(data); i++ {
+		from := i * (blobFieldElementLength - 1)
+		to := from + blobFieldElementLength - 1
+		if to > len(data) {
+			to = len(data)
+		}
+		copy(blob[i*blobFieldElementLength+1:], data[from:to])
+	}
+	return &blob, nil
+}
+
+// SplitTransactions splits the concatenated encodings of typed and legacy txs, e.g. of a chunk of a blob payload.
+func SplitTransactions(data []byte) ([][]byte, error) {
+	var txs [][]byte
+	for len(data) > 0 {
+		// typed txs are prefixed with their EIP-2718 type, legacy txs start with the RLP list.
+		prefixLength := 0
+		if data[0] <= 0x7f {
+			prefixLength = 1
+		} else if data[0] < 0xc0 {
+			return nil, invalidDataError("tx %d starts with %#x, neither a tx type nor an RLP list", len(txs), data[0])
+		}
+		kind, _, rest, err := rlp.Split(data[prefixLength:])
+		if err != nil {
+			return nil, invalidDataError("tx %d: %v", len(txs), err)
+		}
+		if kind != rlp.List {
+			return nil, invalidDataError("tx %d is not an RLP list", len(txs))
+		}
+		txLength := len(data) - len(rest)
+		txs = append(txs, data[:txLength:txLength])
+		data = rest
+	}
+	return txs, nil
+}
+
+func checkBlobVersion(version encoding.CodecVersion) error {
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+	if version < encoding.CodecV1 {
+		return invalidDataError("no blob before codec v1, version %d", version)
+	}
+	return nil
+}
+
+// blobMetadataLength is the length of the number of chunks followed by the sizes of the max number of chunks.
+func blobMetadataLength() int {
+	return 2 + codecv1.MaxNumChunks*blobChunkSizeLength
+}
@@ -0,0 +1,181 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/encoding/codecv1"
+)
+
+const (
+	blockContextLength = 60
+	// txLengthPrefixLength is the length of the length prefix of the L2 txs of the chunks before codec v1.
+	txLengthPrefixLength = 4
+)
+
+// BlockContext is the context of an L2 block in a chunk.
+type BlockContext struct {
+	BlockNumber     uint64
+	Timestamp       uint64
+	BaseFee         *big.Int
+	GasLimit        uint64
+	NumTransactions uint16 // including the L1 messages, skipped ones too
+	NumL1Messages   uint16 // including the skipped L1 messages
+}
+
+// Chunk is a chunk of the commitBatch calldata.
+type Chunk struct {
+	Blocks []*BlockContext
+	// L2Transactions are the RLP encoded L2 txs of the blocks before codec v1, they are in the blob since.
+	L2Transactions [][]byte
+}
+
+// NewChunk returns the chunk encoded with the codec version, totalL1MessagePoppedBefore is the queue index of the
+// first L1 message of the chunk.
+func NewChunk(version encoding.CodecVersion, chunk *encoding.Chunk, totalL1MessagePoppedBefore uint64) (*Chunk, error) {
+	var data []byte
+	switch version {
+	case encoding.CodecV0:
+		daChunk, err := codecv0.NewDAChunk(chunk, totalL1MessagePoppedBefore)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = daChunk.Encode(); err != nil {
+			return nil, err
+		}
+	case encoding.CodecV1:
+		daChunk, err := codecv1.NewDAChunk(chunk, totalL1MessagePoppedBefore)
+		if err != nil {
+			return nil, err
+		}
+		data = daChunk.Encode()
+	default:
+		return nil, checkVersion(version)
+	}
+	return DecodeChunk(version, data)
+}
+
+// DecodeChunk decodes and validates a chunk of the commitBatch calldata of the codec version.
+func DecodeChunk(version encoding.CodecVersion, data []byte) (*Chunk, error) {
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, invalidDataError("empty chunk")
+	}
+	numBlocks := int(data[0])
+	if len(data) < 1+numBlocks*blockContextLength {
+		return nil, invalidDataError("chunk of %d blocks is %d bytes, expected at least %d", numBlocks, len(data), 1+numBlocks*blockContextLength)
+	}
+
+	c := &Chunk{Blocks: make([]*BlockContext, 0, numBlocks)}
+	for i := 0; i < numBlocks; i++ {
+		blockContext := data[1+i*blockContextLength : 1+(i+1)*blockContextLength]
+		c.Blocks = append(c.Blocks, &BlockContext{
+			BlockNumber:     binary.BigEndian.Uint64(blockContext[0:8]),
+			Timestamp:       binary.BigEndian.Uint64(blockContext[8:16]),
+			BaseFee:         new(big.Int).SetBytes(blockContext[16:48]),
+			GasLimit:        binary.BigEndian.Uint64(blockContext[48:56]),
+			NumTransactions: binary.BigEndian.Uint16(blockContext[56:58]),
+			NumL1Messages:   binary.BigEndian.Uint16(blockContext[58:60]),
+		})
+	}
+
+	txData := data[1+numBlocks*blockContextLength:]
+	if version >= encoding.CodecV1 && len(txData) > 0 {
+		return nil, invalidDataError("%d bytes of L2 txs in a chunk of version %d", len(txData), version)
+	}
+	for len(txData) > 0 {
+		if len(txData) < txLengthPrefixLength {
+			return nil, invalidDataError("incomplete length of L2 tx %d", len(c.L2Transactions))
+		}
+		txLength := uint64(binary.BigEndian.Uint32(txData))
+		txData = txData[txLengthPrefixLength:]
+		if txLength > uint64(len(txData)) {
+			return nil, invalidDataError("L2 tx %d is %d bytes, only %d left", len(c.L2Transactions), txLength, len(txData))
+		}
+		c.L2Transactions = append(c.L2Transactions, txData[:txLength:txLength])
+		txData = txData[txLength:]
+	}
+
+	if err := c.Validate(version); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the blocks of the chunk and, before codec v1, that the chunk has the L2 txs of its blocks.
+func (c *Chunk) Validate(version encoding.CodecVersion) error {
+	if err := checkVersion(version); err != nil {
+		return err
+	}
+	if len(c.Blocks) == 0 || len(c.Blocks) > math.MaxUint8 {
+		return invalidDataError("chunk of %d blocks, expected between 1 and %d", len(c.Blocks), math.MaxUint8)
+	}
+	for _, block := range c.Blocks {
+		if block.BaseFee != nil && (block.BaseFee.Sign() < 0 || block.BaseFee.BitLen() > 256) {
+			return invalidDataError("base fee %v of block %d is not a uint256", block.BaseFee, block.BlockNumber)
+		}
+		if block.NumL1Messages > block.NumTransactions {
+			return invalidDataError("block %d has %d L1 messages, more than its %d txs", block.BlockNumber, block.NumL1Messages, block.NumTransactions)
+		}
+	}
+	if version >= encoding.CodecV1 {
+		if len(c.L2Transactions) > 0 {
+			return invalidDataError("%d L2 txs in a chunk of version %d", len(c.L2Transactions), version)
+		}
+		return nil
+	}
+	if numL2Transactions := c.NumL2Transactions(); uint64(len(c.L2Transactions)) != numL2Transactions {
+		return invalidDataError("chunk has %d L2 txs, its blocks have %d", len(c.L2Transactions), numL2Transactions)
+	}
+	for i, tx := range c.L2Transactions {
+		if len(tx) == 0 || uint64(len(tx)) > math.MaxUint32 {
+			return invalidDataError("L2 tx %d is %d bytes", i, len(tx))
+		}
+	}
+	return nil
+}
+
+// NumL2Transactions returns the number of L2 txs of the blocks of the chunk.
+func (c *Chunk) NumL2Transactions() uint64 {
+	var numL2Transactions uint64
+	for _, block := range c.Blocks {
+		if block.NumTransactions > block.NumL1Messages {
+			numL2Transactions += uint64(block.NumTransactions - block.NumL1Messages)
+		}
+	}
+	return numL2Transactions
+}
+
+// Encode serializes the valid chunk as in the commitBatch calldata of the codec version, the L2 txs are only encoded
+// before codec v1.
+func (c *Chunk) Encode(version encoding.CodecVersion) []byte {
+	data := make([]byte, 1, 1+len(c.Blocks)*blockContextLength)
+	data[0] = byte(len(c.Blocks))
+	for _, block := range c.Blocks {
+		blockContext := make([]byte, blockContextLength)
+		binary.BigEndian.PutUint64(blockContext[0:], block.BlockNumber)
+		binary.BigEndian.PutUint64(blockContext[8:], block.Timestamp)
+		if block.BaseFee != nil {
+			block.BaseFee.FillBytes(blockContext[16:48])
+		}
+		binary.BigEndian.PutUint64(blockContext[48:], block.GasLimit)
+		binary.BigEndian.PutUint16(blockContext[56:], block.NumTransactions)
+		binary.BigEndian.PutUint16(blockContext[58:], block.NumL1Messages)
+		data = append(data, blockContext...)
+	}
+	if version >= encoding.CodecV1 {
+		return data
+	}
+	for _, tx := range c.L2Transactions {
+		var txLength [txLengthPrefixLength]byte
+		binary.BigEndian.PutUint32(txLength[:], uint32(len(tx)))
+		data = append(data, txLength[:]...)
+		data = append(data, tx...)
+	}
+	return data
+}
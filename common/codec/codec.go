@@ -0,0 +1,35 @@
+// Package codec implements the encodings of the batches committed to L1, for all the codec versions: the batch
+// header, the chunks of the commitBatch calldata and the blob payload, with their decoders and validation.
+//
+// The batches are built from L2 blocks by the codecs of scroll-tech/common/types/encoding, NewBatchHeader and
+// NewChunk convert their results. The decoders read the committed batches back, e.g. from the commitBatch calldata
+// and the blobs indexed on L1, and reject malformed data with an error instead of panicking.
+package codec
+
+import (
+	"errors"
+	"fmt"
+
+	"scroll-tech/common/types/encoding"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for the data of an unknown codec version.
+	ErrUnsupportedVersion = errors.New("unsupported codec version")
+	// ErrInvalidData is wrapped by the errors of malformed data.
+	ErrInvalidData = errors.New("invalid data")
+)
+
+// MaxVersion is the latest supported codec version.
+const MaxVersion = encoding.CodecV1
+
+func checkVersion(version encoding.CodecVersion) error {
+	if version < encoding.CodecV0 || version > MaxVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	return nil
+}
+
+func invalidDataError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidData, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,152 @@
+package codec
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/encoding/codecv1"
+)
+
+func readBlockFromJSON(t *testing.T, filename string) *encoding.Block {
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+
+	block := &encoding.Block{}
+	assert.NoError(t, json.Unmarshal(data, block))
+	return block
+}
+
+func newTestBatch(t *testing.T, traces ...string) *encoding.Batch {
+	batch := &encoding.Batch{}
+	for _, trace := range traces {
+		block := readBlockFromJSON(t, "../testdata/"+trace)
+		batch.Chunks = append(batch.Chunks, &encoding.Chunk{Blocks: []*encoding.Block{block}})
+	}
+	return batch
+}
+
+func TestBatchHeader(t *testing.T) {
+	batch := newTestBatch(t, "blockTrace_02.json", "blockTrace_04.json", "blockTrace_05.json")
+
+	daBatchV0, err := codecv0.NewDABatch(batch)
+	assert.NoError(t, err)
+	header, err := NewBatchHeader(encoding.CodecV0, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, daBatchV0.Encode(), header.Encode())
+	assert.Equal(t, daBatchV0.Hash(), header.Hash())
+	decoded, err := DecodeBatchHeader(daBatchV0.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, header, decoded)
+
+	daBatchV1, err := codecv1.NewDABatch(batch)
+	assert.NoError(t, err)
+	header, err = NewBatchHeader(encoding.CodecV1, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, daBatchV1.Encode(), header.Encode())
+	assert.Equal(t, daBatchV1.Hash(), header.Hash())
+	decoded, err = DecodeBatchHeader(daBatchV1.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, header, decoded)
+
+	// trace 4 skips the L1 messages 0 to 9 and includes 10, trace 5 skips 11 to 36 and includes 37 to 41.
+	assert.Equal(t, uint64(42), decoded.L1MessagePopped)
+	assert.True(t, decoded.IsSkipped(0))
+	assert.True(t, decoded.IsSkipped(9))
+	assert.False(t, decoded.IsSkipped(10))
+	assert.True(t, decoded.IsSkipped(11))
+	assert.True(t, decoded.IsSkipped(36))
+	assert.False(t, decoded.IsSkipped(37))
+	assert.False(t, decoded.IsSkipped(42))
+
+	_, err = DecodeBatchHeader(nil)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = DecodeBatchHeader(append([]byte{2}, daBatchV1.Encode()[1:]...))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	_, err = DecodeBatchHeader(daBatchV1.Encode()[:120])
+	assert.ErrorIs(t, err, ErrInvalidData)
+	// the bitmap does not cover the popped messages.
+	_, err = DecodeBatchHeader(daBatchV1.Encode()[:121])
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestChunk(t *testing.T) {
+	batch := newTestBatch(t, "blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json")
+	var totalL1MessagePoppedBefore uint64
+	for _, chunk := range batch.Chunks {
+		daChunkV0, err := codecv0.NewDAChunk(chunk, totalL1MessagePoppedBefore)
+		assert.NoError(t, err)
+		dataV0, err := daChunkV0.Encode()
+		assert.NoError(t, err)
+		c, err := DecodeChunk(encoding.CodecV0, dataV0)
+		assert.NoError(t, err)
+		assert.Equal(t, dataV0, c.Encode(encoding.CodecV0))
+		assert.Equal(t, chunk.NumL2Transactions(), c.NumL2Transactions())
+		assert.Len(t, c.L2Transactions, int(chunk.NumL2Transactions()))
+		assert.Equal(t, chunk.Blocks[0].Header.Number.Uint64(), c.Blocks[0].BlockNumber)
+
+		daChunkV1, err := codecv1.NewDAChunk(chunk, totalL1MessagePoppedBefore)
+		assert.NoError(t, err)
+		c, err = NewChunk(encoding.CodecV1, chunk, totalL1MessagePoppedBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, daChunkV1.Encode(), c.Encode(encoding.CodecV1))
+		assert.Empty(t, c.L2Transactions)
+
+		totalL1MessagePoppedBefore += chunk.NumL1Messages(totalL1MessagePoppedBefore)
+	}
+
+	_, err := DecodeChunk(encoding.CodecV0, []byte{1})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = DecodeChunk(encoding.CodecV0, make([]byte, 1))
+	assert.ErrorIs(t, err, ErrInvalidData)
+	// v1 chunks have no L2 txs.
+	c, err := NewChunk(encoding.CodecV0, batch.Chunks[0], 0)
+	assert.NoError(t, err)
+	_, err = DecodeChunk(encoding.CodecV1, c.Encode(encoding.CodecV0))
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestBlobPayload(t *testing.T) {
+	batch := newTestBatch(t, "blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json", "blockTrace_05.json")
+	daBatch, err := codecv1.NewDABatch(batch)
+	assert.NoError(t, err)
+
+	payload, err := DecodeBlobPayload(encoding.CodecV1, daBatch.Blob())
+	assert.NoError(t, err)
+	assert.Len(t, payload.Chunks, len(batch.Chunks))
+	blob, err := payload.Encode(encoding.CodecV1)
+	assert.NoError(t, err)
+	assert.Equal(t, daBatch.Blob(), blob)
+
+	// the txs of the chunks are the L2 txs of the blocks.
+	for i, chunk := range batch.Chunks {
+		txs, err := SplitTransactions(payload.Chunks[i])
+		assert.NoError(t, err)
+		var expected [][]byte
+		for _, tx := range chunk.Blocks[0].Transactions {
+			if tx.Type == types.L1MessageTxType {
+				continue
+			}
+			rlpTx, err := encoding.ConvertTxDataToRLPEncoding(tx)
+			assert.NoError(t, err)
+			expected = append(expected, rlpTx)
+		}
+		assert.Equal(t, expected, txs)
+	}
+
+	_, err = DecodeBlobPayload(encoding.CodecV0, daBatch.Blob())
+	assert.ErrorIs(t, err, ErrInvalidData)
+	notCanonical := *daBatch.Blob()
+	notCanonical[32] = 1
+	_, err = DecodeBlobPayload(encoding.CodecV1, &notCanonical)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = (&BlobPayload{Chunks: [][]byte{make([]byte, blobPayloadCapacity)}}).Encode(encoding.CodecV1)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = SplitTransactions([]byte{0x02, 0x80})
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
@@ -0,0 +1,86 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+
+	"scroll-tech/common/types/encoding"
+)
+
+// The fuzz tests check that the decoders do not panic on any input, and that the decoded data encodes back to it.
+
+func FuzzDecodeBatchHeader(f *testing.F) {
+	f.Add(make([]byte, batchHeaderV0Length))
+	f.Add(append([]byte{1}, make([]byte, batchHeaderV1Length-1)...))
+	v1 := make([]byte, batchHeaderV1Length+bitmapWordLength)
+	v1[0], v1[16] = 1, 11
+	f.Add(v1)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := DecodeBatchHeader(data)
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(data, header.Encode()) {
+			t.Fatalf("batch header %x encodes to %x", data, header.Encode())
+		}
+		for i := uint64(0); i < header.L1MessagePopped && i < 512; i++ {
+			header.IsSkipped(i)
+		}
+	})
+}
+
+func FuzzDecodeChunk(f *testing.F) {
+	block := make([]byte, blockContextLength)
+	block[57] = 1 // a single L2 tx
+	f.Add(uint8(encoding.CodecV0), append(append([]byte{1}, block...), 0, 0, 0, 1, 0xc0))
+	f.Add(uint8(encoding.CodecV1), append([]byte{1}, make([]byte, blockContextLength)...))
+	f.Fuzz(func(t *testing.T, version uint8, data []byte) {
+		c, err := DecodeChunk(encoding.CodecVersion(version), data)
+		if err != nil {
+			return
+		}
+		if encoded := c.Encode(encoding.CodecVersion(version)); !bytes.Equal(data, encoded) {
+			t.Fatalf("chunk %x of version %d encodes to %x", data, version, encoded)
+		}
+	})
+}
+
+func FuzzDecodeBlobPayload(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 0, 0, 1})
+	f.Add([]byte{0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// the fuzzed data is the start of the blob, the rest is zero.
+		var blob kzg4844.Blob
+		copy(blob[:], data)
+		payload, err := DecodeBlobPayload(encoding.CodecV1, &blob)
+		if err != nil {
+			return
+		}
+		encoded, err := payload.Encode(encoding.CodecV1)
+		if err != nil {
+			t.Fatalf("decoded blob payload does not encode: %v", err)
+		}
+		if *encoded != blob {
+			t.Fatalf("blob %x does not encode back", data)
+		}
+		for _, chunk := range payload.Chunks {
+			_, _ = SplitTransactions(chunk)
+		}
+	})
+}
+
+func FuzzSplitTransactions(f *testing.F) {
+	f.Add([]byte{0xc0})
+	f.Add([]byte{0x02, 0xc1, 0x80, 0xc0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		txs, err := SplitTransactions(data)
+		if err != nil {
+			return
+		}
+		if joined := bytes.Join(txs, nil); !bytes.Equal(data, joined) {
+			t.Fatalf("txs of %x join to %x", data, joined)
+		}
+	})
+}
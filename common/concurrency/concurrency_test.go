@@ -0,0 +1,72 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	pool := NewPool(2)
+	assert.Equal(t, 2, pool.Size())
+
+	var running, maxRunning int32
+	task := func() {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, pool.Go(context.Background(), task))
+	}
+	pool.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+
+	// Run waits for a free slot and gives up once the context is done.
+	block := make(chan struct{})
+	assert.NoError(t, pool.Go(context.Background(), func() { <-block }))
+	assert.NoError(t, pool.Go(context.Background(), func() { <-block }))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := Run(ctx, pool, func() (int, error) { return 1, nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(block)
+	pool.Wait()
+
+	value, err := Run(context.Background(), pool, func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
+
+func TestMap(t *testing.T) {
+	inputs := []int{5, 4, 3, 2, 1}
+	outputs, err := Map(context.Background(), inputs, 3, func(ctx context.Context, in int) (int, error) {
+		time.Sleep(time.Duration(in) * time.Millisecond)
+		return in * 10, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{50, 40, 30, 20, 10}, outputs)
+
+	targetErr := errors.New("map failure")
+	var calls int32
+	_, err = Map(context.Background(), []int{1, 2, 3, 4, 5, 6}, 1, func(ctx context.Context, in int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if in == 2 {
+			return 0, targetErr
+		}
+		return in, nil
+	})
+	assert.ErrorIs(t, err, targetErr)
+	// no call is started after the failure.
+	assert.LessOrEqual(t, atomic.LoadInt32(&calls), int32(3))
+}
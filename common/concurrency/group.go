@@ -0,0 +1,43 @@
+package concurrency
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Map calls fn on every input with at most limit calls running at a time, and returns the outputs in the order of
+// the inputs. The first error cancels the context passed to the other calls, no further call is started, and the
+// error is returned. limit is at least 1.
+func Map[In, Out any](ctx context.Context, inputs []In, limit int, fn func(ctx context.Context, in In) (Out, error)) ([]Out, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(limit)
+
+	outputs := make([]Out, len(inputs))
+	for i := range inputs {
+		// Go blocks while limit calls are running, stop starting calls once one of them failed.
+		if gctx.Err() != nil {
+			break
+		}
+		i := i
+		g.Go(func() error {
+			out, err := fn(gctx, inputs[i])
+			if err != nil {
+				return err
+			}
+			outputs[i] = out
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	// the parent context may be done without any call failing, e.g. before the first call started.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
@@ -0,0 +1,72 @@
+// Package concurrency provides bounded concurrency primitives: a worker pool limiting the number of tasks running at
+// a time, and errgroup based fan-out helpers cancelling on the first error.
+package concurrency
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool bounds the number of tasks running concurrently. Tasks run on the goroutines of their callers or on goroutines
+// spawned per task, the pool only holds the slots, so an idle pool costs nothing.
+type Pool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewPool creates a pool running at most size tasks at a time, size is at least 1.
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{slots: make(chan struct{}, size)}
+}
+
+// Size returns the max number of tasks running at a time.
+func (p *Pool) Size() int {
+	return cap(p.slots)
+}
+
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) release() {
+	<-p.slots
+}
+
+// Go runs task on a new goroutine once a slot is free, blocking until then.
+// The context error is returned without running task if ctx is done before a slot is free.
+func (p *Pool) Go(ctx context.Context, task func()) error {
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		task()
+	}()
+	return nil
+}
+
+// Wait waits for the tasks started by Go.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Run runs fn on the calling goroutine once a slot of the pool is free, and returns its result.
+// The context error is returned without running fn if ctx is done before a slot is free.
+func Run[T any](ctx context.Context, p *Pool, fn func() (T, error)) (T, error) {
+	if err := p.acquire(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	defer p.release()
+	return fn()
+}
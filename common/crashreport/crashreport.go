@@ -0,0 +1,134 @@
+// Package crashreport captures the panics of long-running goroutines, e.g. watchers, fetchers and schedulers, so that
+// a panic is logged with its stack trace, counted and optionally posted to a crash-report webhook instead of silently
+// killing the goroutine or the process.
+package crashreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/metrics"
+	"scroll-tech/common/version"
+)
+
+// webhookTimeout bounds the post of a report, the goroutine posting it is not awaited.
+const webhookTimeout = 5 * time.Second
+
+var (
+	webhookMu  sync.RWMutex
+	webhookURL string
+
+	initPanicTotalOnce sync.Once
+	panicTotal         *prometheus.CounterVec
+)
+
+// Report is the crash report posted to the webhook as json.
+type Report struct {
+	Component string `json:"component"`
+	Panic     string `json:"panic"`
+	Stack     string `json:"stack"`
+	Binary    string `json:"binary"`
+	Version   string `json:"version"`
+	Hostname  string `json:"hostname"`
+	Time      int64  `json:"time"`
+}
+
+// SetWebhook sets the url the crash reports are posted to, reports are only logged and counted if empty.
+func SetWebhook(url string) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	webhookURL = url
+}
+
+// Recover reports the panic of the calling goroutine, if any, and lets the goroutine return normally. It must be
+// deferred directly, e.g. defer crashreport.Recover("l1_watcher").
+func Recover(component string) {
+	if r := recover(); r != nil {
+		report(component, r, debug.Stack())
+	}
+}
+
+// Wrap returns f reporting its panics, so that a loop running it goes on with the next run.
+func Wrap(component string, f func()) func() {
+	return func() {
+		defer Recover(component)
+		f()
+	}
+}
+
+// WrapWithContext is Wrap of a function taking a context.
+func WrapWithContext(component string, f func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		defer Recover(component)
+		f(ctx)
+	}
+}
+
+// Go runs f in a new goroutine reporting its panic.
+func Go(component string, f func()) {
+	go Wrap(component, f)()
+}
+
+func report(component string, r interface{}, stack []byte) {
+	log.Error("recovered from a panic", "component", component, "panic", r, "stack", string(stack))
+
+	initPanicTotalOnce.Do(func() {
+		panicTotal = promauto.With(metrics.Registerer()).NewCounterVec(prometheus.CounterOpts{
+			Name: "goroutine_panic_recovered_total",
+			Help: "Total number of panics recovered in long-running goroutines.",
+		}, []string{"component"})
+	})
+	panicTotal.WithLabelValues(component).Inc()
+
+	webhookMu.RLock()
+	url := webhookURL
+	webhookMu.RUnlock()
+	if url == "" {
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	rep := &Report{
+		Component: component,
+		Panic:     fmt.Sprint(r),
+		Stack:     string(stack),
+		Binary:    filepath.Base(os.Args[0]),
+		Version:   version.Version,
+		Hostname:  hostname,
+		Time:      time.Now().Unix(),
+	}
+	go func() {
+		if err := post(url, rep); err != nil {
+			log.Warn("failed to post crash report", "component", component, "err", err)
+		}
+	}()
+}
+
+func post(url string, rep *Report) error {
+	body, err := json.Marshal(rep)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("crash report webhook responded %s", resp.Status)
+	}
+	return nil
+}
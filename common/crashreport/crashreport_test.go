@@ -0,0 +1,59 @@
+package crashreport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	reports := make(chan *Report, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rep Report
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&rep))
+		reports <- &rep
+	}))
+	defer srv.Close()
+	SetWebhook(srv.URL)
+	defer SetWebhook("")
+
+	// the loop goes on with the next run after a panic.
+	runs := 0
+	f := Wrap("test_loop", func() {
+		runs++
+		if runs == 1 {
+			panic("boom")
+		}
+	})
+	f()
+	f()
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, float64(1), testutil.ToFloat64(panicTotal.WithLabelValues("test_loop")))
+
+	select {
+	case rep := <-reports:
+		assert.Equal(t, "test_loop", rep.Component)
+		assert.Equal(t, "boom", rep.Panic)
+		assert.Contains(t, rep.Stack, "TestRecover")
+		assert.NotZero(t, rep.Time)
+	case <-time.After(5 * time.Second):
+		t.Fatal("crash report not posted")
+	}
+
+	// a goroutine reporting its panic returns normally.
+	done := make(chan struct{})
+	Go("test_goroutine", func() {
+		defer close(done)
+		var m map[string]int
+		m["x"] = 1
+	})
+	<-done
+	rep := <-reports
+	assert.Equal(t, "test_goroutine", rep.Component)
+	assert.Equal(t, float64(1), testutil.ToFloat64(panicTotal.WithLabelValues("test_goroutine")))
+}
@@ -1,11 +1,73 @@
 package database
 
+import "time"
+
 // Config db config
 type Config struct {
 	// data source name
 	DSN        string `json:"dsn"`
 	DriverName string `json:"driver_name"`
 
+	// Optional, the max open and idle connections of the pool, the defaults of the binary if 0. The connections of
+	// all the binaries sharing a postgres instance must stay below its max_connections.
 	MaxOpenNum int `json:"maxOpenNum"`
 	MaxIdleNum int `json:"maxIdleNum"`
+	// Optional, the max lifetime and idle time of the pooled connections in seconds, the defaults of the binary if 0.
+	ConnMaxLifetimeSec int `json:"connMaxLifetimeSec"`
+	ConnMaxIdleTimeSec int `json:"connMaxIdleTimeSec"`
+	// Optional, the interval in seconds the pool is pinged at, the failures are logged and counted, the defaults of
+	// the binary if 0.
+	HealthCheckIntervalSec int `json:"healthCheckIntervalSec"`
+
+	// Optional, the statement_timeout of the sessions in milliseconds, postgres cancels the statements running
+	// longer, writes included. Statements are not bounded if 0.
+	StatementTimeoutMs int `json:"statementTimeoutMs"`
+	// Optional, the search_path of the sessions, e.g. to work on the tables of another schema, the default of the
+	// role if empty.
+	SearchPath string `json:"searchPath"`
+}
+
+// Pool is the connection pool of a db handle.
+type Pool struct {
+	// Name labels the pool metrics, e.g. the binary name, the pool metrics are not exported if empty.
+	Name                string
+	MaxOpenNum          int
+	MaxIdleNum          int
+	ConnMaxLifetime     time.Duration
+	ConnMaxIdleTime     time.Duration
+	HealthCheckInterval time.Duration
+}
+
+// DefaultPool is the pool of the db handles whose binary does not set its own defaults, e.g. the tools.
+var DefaultPool = Pool{
+	MaxOpenNum:          10,
+	MaxIdleNum:          2,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
+// Pool returns the pool of the config, the settings left 0 are the ones of defaults.
+func (c *Config) Pool(defaults Pool) Pool {
+	pool := defaults
+	if c.MaxOpenNum > 0 {
+		pool.MaxOpenNum = c.MaxOpenNum
+	}
+	if c.MaxIdleNum > 0 {
+		pool.MaxIdleNum = c.MaxIdleNum
+	}
+	if c.ConnMaxLifetimeSec > 0 {
+		pool.ConnMaxLifetime = time.Duration(c.ConnMaxLifetimeSec) * time.Second
+	}
+	if c.ConnMaxIdleTimeSec > 0 {
+		pool.ConnMaxIdleTime = time.Duration(c.ConnMaxIdleTimeSec) * time.Second
+	}
+	if c.HealthCheckIntervalSec > 0 {
+		pool.HealthCheckInterval = time.Duration(c.HealthCheckIntervalSec) * time.Second
+	}
+	// idle connections above the max open ones would be closed anyway.
+	if pool.MaxOpenNum > 0 && pool.MaxIdleNum > pool.MaxOpenNum {
+		pool.MaxIdleNum = pool.MaxOpenNum
+	}
+	return pool
 }
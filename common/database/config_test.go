@@ -0,0 +1,36 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigPool(t *testing.T) {
+	defaults := Pool{
+		Name:                "test",
+		MaxOpenNum:          20,
+		MaxIdleNum:          5,
+		ConnMaxLifetime:     10 * time.Minute,
+		ConnMaxIdleTime:     5 * time.Minute,
+		HealthCheckInterval: time.Minute,
+	}
+
+	// the settings left 0 are the defaults.
+	assert.Equal(t, defaults, (&Config{}).Pool(defaults))
+
+	cfg := &Config{MaxOpenNum: 50, ConnMaxLifetimeSec: 300, HealthCheckIntervalSec: 10}
+	assert.Equal(t, Pool{
+		Name:                "test",
+		MaxOpenNum:          50,
+		MaxIdleNum:          5,
+		ConnMaxLifetime:     5 * time.Minute,
+		ConnMaxIdleTime:     5 * time.Minute,
+		HealthCheckInterval: 10 * time.Second,
+	}, cfg.Pool(defaults))
+
+	// the idle connections are bounded by the open ones.
+	cfg = &Config{MaxOpenNum: 3}
+	assert.Equal(t, 3, cfg.Pool(defaults).MaxIdleNum)
+}
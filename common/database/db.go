@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -44,13 +47,23 @@ func (g *gormLogger) Trace(_ context.Context, begin time.Time, fc func() (string
 	g.gethLogger.Debug("gorm", "line", utils.FileWithLineNum(), "cost", elapsed, "sql", sql, "rowsAffected", rowsAffected, "err", err)
 }
 
-// InitDB init the db handler
+// InitDB init the db handler, with the default pool settings for the ones not configured.
 func InitDB(config *Config) (*gorm.DB, error) {
+	return InitDBWithPool(config, DefaultPool)
+}
+
+// InitDBWithPool init the db handler, with the pool settings of the binary for the ones not configured.
+func InitDBWithPool(config *Config, defaults Pool) (*gorm.DB, error) {
 	tmpGormLogger := gormLogger{
 		gethLogger: log.Root(),
 	}
 
-	db, err := gorm.Open(postgres.Open(config.DSN), &gorm.Config{
+	dialector, err := newDialector(config)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: &tmpGormLogger,
 		NowFunc: func() time.Time {
 			// why set time to UTC.
@@ -70,21 +83,38 @@ func InitDB(config *Config) (*gorm.DB, error) {
 		return nil, pingErr
 	}
 
-	sqlDB.SetConnMaxLifetime(time.Minute * 10)
-	sqlDB.SetConnMaxIdleTime(time.Minute * 5)
-
-	sqlDB.SetMaxOpenConns(config.MaxOpenNum)
-	sqlDB.SetMaxIdleConns(config.MaxIdleNum)
+	configurePool(sqlDB, config.Pool(defaults))
 
 	return db, nil
 }
 
+// newDialector returns the postgres dialector of the DSN, whose sessions set the configured statement_timeout and
+// search_path.
+func newDialector(config *Config) (gorm.Dialector, error) {
+	if config.StatementTimeoutMs <= 0 && config.SearchPath == "" {
+		return postgres.Open(config.DSN), nil
+	}
+	connConfig, err := pgx.ParseConfig(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dsn: %w", err)
+	}
+	// runtime params are sent on connect, every connection of the pool gets them.
+	if config.StatementTimeoutMs > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(config.StatementTimeoutMs)
+	}
+	if config.SearchPath != "" {
+		connConfig.RuntimeParams["search_path"] = config.SearchPath
+	}
+	return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connConfig)}), nil
+}
+
 // CloseDB close the db handler. notice the db handler only can close when then program exit.
 func CloseDB(db *gorm.DB) error {
 	sqlDB, err := db.DB()
 	if err != nil {
 		return err
 	}
+	stopHealthCheck(sqlDB)
 	if err := sqlDB.Close(); err != nil {
 		return err
 	}
@@ -58,5 +58,32 @@ func TestDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, sqlDB)
 
+	assert.Equal(t, base.DBConfig.MaxOpenNum, sqlDB.Stats().MaxOpenConnections)
+	assert.NoError(t, CloseDB(db))
+
+	// the settings left 0 are the ones of the binary.
+	db, err = InitDBWithPool(&Config{DSN: base.DBConfig.DSN, DriverName: base.DBConfig.DriverName}, Pool{Name: "test", MaxOpenNum: 7, HealthCheckInterval: time.Second})
+	assert.NoError(t, err)
+	sqlDB, err = Ping(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+	assert.NoError(t, CloseDB(db))
+
+	// the sessions get the statement timeout.
+	dbCfg.StatementTimeoutMs = 1500
+	db, err = InitDB(dbCfg)
+	assert.NoError(t, err)
+	var statementTimeout string
+	assert.NoError(t, db.Raw("SHOW statement_timeout").Scan(&statementTimeout).Error)
+	assert.Equal(t, "1500ms", statementTimeout)
+	assert.NoError(t, CloseDB(db))
+
+	// and the search path.
+	dbCfg.SearchPath = "reindex"
+	db, err = InitDB(dbCfg)
+	assert.NoError(t, err)
+	var searchPath string
+	assert.NoError(t, db.Raw("SHOW search_path").Scan(&searchPath).Error)
+	assert.Equal(t, "reindex", searchPath)
 	assert.NoError(t, CloseDB(db))
 }
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/metrics"
+)
+
+var (
+	healthCheckFailureTotalOnce sync.Once
+	healthCheckFailureTotal     *prometheus.CounterVec
+
+	// healthChecks are the stop channels of the health checks of the open pools.
+	healthChecks sync.Map
+)
+
+func healthCheckFailureCounter() *prometheus.CounterVec {
+	healthCheckFailureTotalOnce.Do(func() {
+		healthCheckFailureTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_pool_health_check_failure_total",
+			Help: "Total number of failed pings of the health check of the db connection pool.",
+		}, []string{"db_name"})
+		metrics.Registerer().MustRegister(healthCheckFailureTotal)
+	})
+	return healthCheckFailureTotal
+}
+
+// configurePool applies the pool settings to sqlDB, exports its stats as go_sql_* metrics labelled with the pool name
+// and starts its health check, which stops on CloseDB.
+func configurePool(sqlDB *sql.DB, pool Pool) {
+	sqlDB.SetMaxOpenConns(pool.MaxOpenNum)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleNum)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	if pool.Name != "" {
+		if err := metrics.Registerer().Register(collectors.NewDBStatsCollector(sqlDB, pool.Name)); err != nil {
+			log.Warn("failed to register the db pool metrics", "db_name", pool.Name, "err", err)
+		}
+	}
+	if pool.HealthCheckInterval > 0 {
+		stop := make(chan struct{})
+		healthChecks.Store(sqlDB, stop)
+		go healthCheck(sqlDB, pool, stop)
+	}
+	log.Info("db connection pool", "db_name", pool.Name, "maxOpen", pool.MaxOpenNum, "maxIdle", pool.MaxIdleNum,
+		"connMaxLifetime", pool.ConnMaxLifetime, "connMaxIdleTime", pool.ConnMaxIdleTime, "healthCheckInterval", pool.HealthCheckInterval)
+}
+
+// healthCheck pings the pool every interval until stop is closed, a ping waits for a connection at most an interval.
+func healthCheck(sqlDB *sql.DB, pool Pool, stop <-chan struct{}) {
+	ticker := time.NewTicker(pool.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), pool.HealthCheckInterval)
+			err := sqlDB.PingContext(ctx)
+			cancel()
+			if err != nil {
+				healthCheckFailureCounter().WithLabelValues(pool.Name).Inc()
+				stats := sqlDB.Stats()
+				log.Warn("db health check failed", "db_name", pool.Name, "inUse", stats.InUse, "idle", stats.Idle,
+					"waitCount", stats.WaitCount, "err", err)
+			}
+		}
+	}
+}
+
+// stopHealthCheck stops the health check of sqlDB, if any.
+func stopHealthCheck(sqlDB *sql.DB) {
+	if stop, ok := healthChecks.LoadAndDelete(sqlDB); ok {
+		close(stop.(chan struct{}))
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+type readTimeoutKey struct{}
+
+// WithReadTimeout returns a copy of ctx whose database reads through ReadContext are bounded by timeout, e.g. set by
+// the API server per endpoint. A timeout not greater than 0 leaves the reads unbounded.
+func WithReadTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, readTimeoutKey{}, timeout)
+}
+
+// ReadTimeout returns the read timeout set on ctx by WithReadTimeout, 0 if none.
+func ReadTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(readTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// ReadContext returns the context a read query runs with, bounded by the read timeout of ctx if any, the query is
+// cancelled once it expires or ctx is done. cancel must be called once the query returns.
+func ReadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := ReadTimeout(ctx); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadContext(t *testing.T) {
+	ctx, cancel := ReadContext(context.Background())
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	ctx = WithReadTimeout(context.Background(), 10*time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, ReadTimeout(ctx))
+	readCtx, cancel := ReadContext(ctx)
+	defer cancel()
+	<-readCtx.Done()
+	assert.True(t, errors.Is(readCtx.Err(), context.DeadlineExceeded))
+	// the timeout bounds the read only, not the caller.
+	assert.NoError(t, ctx.Err())
+
+	assert.Zero(t, ReadTimeout(context.Background()))
+}
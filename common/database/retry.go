@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/retry"
+)
+
+const (
+	// pgSerializationFailure and pgDeadlockDetected are the postgres error codes of transactions aborted by
+	// conflicts with concurrent transactions, which succeed when retried.
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+
+	maxRetryAttempts = 5
+	minRetryBackoff  = 50 * time.Millisecond
+	maxRetryBackoff  = time.Second
+)
+
+// retryPolicy retries the transactions aborted by conflicts, the jitter keeps the conflicting ones from retrying
+// together.
+var retryPolicy = retry.Policy{
+	InitialBackoff: minRetryBackoff,
+	MaxBackoff:     maxRetryBackoff,
+	Jitter:         0.5,
+	MaxAttempts:    maxRetryAttempts,
+	Retryable:      IsRetryableError,
+	OnRetry: func(attempt int, backoff time.Duration, err error) {
+		log.Debug("retrying db operation", "attempt", attempt, "backoff", backoff, "err", err)
+	},
+}
+
+// IsRetryableError returns whether err is a postgres serialization failure or deadlock.
+func IsRetryableError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// WithRetry runs fn, and runs it again with exponential backoff while it fails with a serialization failure or
+// deadlock, up to a bounded number of attempts. fn must not run in a transaction of the caller, as postgres
+// aborts the whole transaction on such errors.
+func WithRetry(ctx context.Context, fn func() error) error {
+	return retry.Do(ctx, retryPolicy, func(context.Context) error { return fn() })
+}
+
+// TransactionWithRetry runs fc in a transaction, and retries the whole transaction while it fails with a
+// serialization failure or deadlock.
+func TransactionWithRetry(ctx context.Context, db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	return WithRetry(ctx, func() error {
+		return db.WithContext(ctx).Transaction(fc)
+	})
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, IsRetryableError(&pgconn.PgError{Code: pgSerializationFailure}))
+	assert.True(t, IsRetryableError(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: pgDeadlockDetected})))
+	assert.False(t, IsRetryableError(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, IsRetryableError(errors.New("deadlock detected")))
+	assert.False(t, IsRetryableError(nil))
+}
+
+func TestWithRetry(t *testing.T) {
+	deadlock := &pgconn.PgError{Code: pgDeadlockDetected}
+
+	attempts := 0
+	err := WithRetry(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = WithRetry(context.Background(), func() error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, maxRetryAttempts, attempts)
+
+	attempts = 0
+	otherErr := errors.New("other error")
+	err = WithRetry(context.Background(), func() error {
+		attempts++
+		return otherErr
+	})
+	assert.ErrorIs(t, err, otherErr)
+	assert.Equal(t, 1, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	err = WithRetry(ctx, func() error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 1, attempts)
+}
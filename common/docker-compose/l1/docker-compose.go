@@ -3,13 +3,18 @@ package dockercompose
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/cloudflare/cfssl/log"
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	tc "github.com/testcontainers/testcontainers-go/modules/compose"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -17,14 +22,25 @@ import (
 
 // PoSL1TestEnv represents the config needed to test in PoS Layer 1.
 type PoSL1TestEnv struct {
-	dockerComposeFile string
-	compose           tc.ComposeStack
-	gethHTTPPort      int
-	hostPath          string
+	dockerComposeFiles []string
+	compose            tc.ComposeStack
+	gethHTTPPort       int
+	beaconHTTPPort     int // 0 unless the blob preset is enabled
+	hostPath           string
 }
 
 // NewPoSL1TestEnv creates and initializes a new instance of PoSL1TestEnv with a random HTTP port.
 func NewPoSL1TestEnv() (*PoSL1TestEnv, error) {
+	return newPoSL1TestEnv(false)
+}
+
+// NewPoSL1BlobTestEnv creates a PoSL1TestEnv with the blob preset, which also publishes the REST API of the beacon
+// node on a random port, so that the blob sidecars of EIP-4844 transactions can be checked with WaitForBlobs.
+func NewPoSL1BlobTestEnv() (*PoSL1TestEnv, error) {
+	return newPoSL1TestEnv(true)
+}
+
+func newPoSL1TestEnv(blobs bool) (*PoSL1TestEnv, error) {
 	rootDir, err := findProjectRootDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find project root directory: %v", err)
@@ -35,27 +51,38 @@ func NewPoSL1TestEnv() (*PoSL1TestEnv, error) {
 		hostPath = ""
 	}
 
-	rnd, err := rand.Int(rand.Reader, big.NewInt(65536-1024))
+	gethHTTPPort, err := randomPort()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate a random: %v", err)
+		return nil, err
 	}
-	gethHTTPPort := int(rnd.Int64()) + 1024
-
 	if err := os.Setenv("GETH_HTTP_PORT", fmt.Sprintf("%d", gethHTTPPort)); err != nil {
 		return nil, fmt.Errorf("failed to set GETH_HTTP_PORT: %v", err)
 	}
 
-	return &PoSL1TestEnv{
-		dockerComposeFile: filepath.Join(rootDir, "common", "docker-compose", "l1", "docker-compose.yml"),
-		gethHTTPPort:      gethHTTPPort,
-		hostPath:          hostPath,
-	}, nil
+	composeDir := filepath.Join(rootDir, "common", "docker-compose", "l1")
+	env := &PoSL1TestEnv{
+		dockerComposeFiles: []string{filepath.Join(composeDir, "docker-compose.yml")},
+		gethHTTPPort:       gethHTTPPort,
+		hostPath:           hostPath,
+	}
+
+	if blobs {
+		env.beaconHTTPPort, err = randomPort()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Setenv("BEACON_HTTP_PORT", fmt.Sprintf("%d", env.beaconHTTPPort)); err != nil {
+			return nil, fmt.Errorf("failed to set BEACON_HTTP_PORT: %v", err)
+		}
+		env.dockerComposeFiles = append(env.dockerComposeFiles, filepath.Join(composeDir, "docker-compose.blob.yml"))
+	}
+	return env, nil
 }
 
 // Start starts the PoS L1 test environment by running the associated Docker Compose configuration.
 func (e *PoSL1TestEnv) Start() error {
 	var err error
-	e.compose, err = tc.NewDockerCompose([]string{e.dockerComposeFile}...)
+	e.compose, err = tc.NewDockerCompose(e.dockerComposeFiles...)
 	if err != nil {
 		return fmt.Errorf("failed to create docker compose: %w", err)
 	}
@@ -68,7 +95,13 @@ func (e *PoSL1TestEnv) Start() error {
 		env["HOST_PATH"] = e.hostPath
 	}
 
-	if err = e.compose.WaitForService("geth", wait.NewHTTPStrategy("/").WithPort("8545/tcp").WithStartupTimeout(15*time.Second)).WithEnv(env).Up(context.Background()); err != nil {
+	stack := e.compose.WaitForService("geth", wait.NewHTTPStrategy("/").WithPort("8545/tcp").WithStartupTimeout(15*time.Second))
+	if e.beaconHTTPPort != 0 {
+		env["BEACON_HTTP_PORT"] = fmt.Sprintf("%d", e.beaconHTTPPort)
+		stack = stack.WaitForService("beacon-chain", wait.NewHTTPStrategy("/eth/v1/node/health").WithPort("3500/tcp").WithStartupTimeout(30*time.Second))
+	}
+
+	if err = stack.WithEnv(env).Up(context.Background()); err != nil {
 		if errStop := e.Stop(); errStop != nil {
 			log.Error("failed to stop PoS L1 test environment", "err", errStop)
 		}
@@ -105,6 +138,127 @@ func (e *PoSL1TestEnv) L1Client() (*ethclient.Client, error) {
 	return client, nil
 }
 
+// BeaconEndpoint returns the REST endpoint of the beacon node, only available with the blob preset.
+func (e *PoSL1TestEnv) BeaconEndpoint() (string, error) {
+	if e == nil || e.beaconHTTPPort == 0 {
+		return "", errors.New("the beacon API is only available in the blob preset of the PoS L1 test environment")
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", e.beaconHTTPPort), nil
+}
+
+// WaitForBlobs waits until the beacon node serves at least count blob sidecars for the beacon block carrying the
+// execution block of the given hash, i.e. until the blobs of the block are available to the blob clients.
+func (e *PoSL1TestEnv) WaitForBlobs(ctx context.Context, blockHash common.Hash, count int) error {
+	endpoint, err := e.BeaconEndpoint()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		slot, found, err := findBeaconSlot(ctx, endpoint, blockHash)
+		if err != nil {
+			log.Warning("failed to find beacon block", "block hash", blockHash.Hex(), "err", err)
+		} else if found {
+			var sidecars struct {
+				Data []json.RawMessage `json:"data"`
+			}
+			found, err = getBeaconAPI(ctx, fmt.Sprintf("%s/eth/v1/beacon/blob_sidecars/%d", endpoint, slot), &sidecars)
+			if err != nil {
+				log.Warning("failed to get blob sidecars", "slot", slot, "err", err)
+			} else if found && len(sidecars.Data) >= count {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("blobs of block %s not available: %w", blockHash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// maxBeaconSlotsScanned bounds the slots scanned back from the head to find the beacon block of an execution block.
+const maxBeaconSlotsScanned = 64
+
+// findBeaconSlot returns the slot of the beacon block whose execution payload is the block of the given hash.
+func findBeaconSlot(ctx context.Context, endpoint string, blockHash common.Hash) (uint64, bool, error) {
+	var head struct {
+		Data struct {
+			Header struct {
+				Message struct {
+					Slot string `json:"slot"`
+				} `json:"message"`
+			} `json:"header"`
+		} `json:"data"`
+	}
+	if _, err := getBeaconAPI(ctx, endpoint+"/eth/v1/beacon/headers/head", &head); err != nil {
+		return 0, false, err
+	}
+	headSlot, err := strconv.ParseUint(head.Data.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid head slot: %w", err)
+	}
+
+	for i := uint64(0); i < maxBeaconSlotsScanned && i <= headSlot; i++ {
+		slot := headSlot - i
+		var block struct {
+			Data struct {
+				Message struct {
+					Body struct {
+						ExecutionPayload struct {
+							BlockHash common.Hash `json:"block_hash"`
+						} `json:"execution_payload"`
+					} `json:"body"`
+				} `json:"message"`
+			} `json:"data"`
+		}
+		found, err := getBeaconAPI(ctx, fmt.Sprintf("%s/eth/v2/beacon/blocks/%d", endpoint, slot), &block)
+		if err != nil {
+			return 0, false, err
+		}
+		if found && block.Data.Message.Body.ExecutionPayload.BlockHash == blockHash {
+			return slot, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// getBeaconAPI decodes the response of a beacon API into result, it returns false if the resource is not found,
+// e.g. the block of an empty slot.
+func getBeaconAPI(ctx context.Context, url string, result interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code %d of %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return false, fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+	return true, nil
+}
+
+func randomPort() (int, error) {
+	rnd, err := rand.Int(rand.Reader, big.NewInt(65536-1024))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate a random: %v", err)
+	}
+	return int(rnd.Int64()) + 1024, nil
+}
+
 func findProjectRootDir() (string, error) {
 	currentDir, err := os.Getwd()
 	if err != nil {
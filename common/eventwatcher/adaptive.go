@@ -0,0 +1,92 @@
+package eventwatcher
+
+import (
+	"strings"
+)
+
+// rangeTooLargeErrors are the messages of the nodes and RPC providers rejecting a log query for its block range or the
+// size of its response.
+var rangeTooLargeErrors = []string{
+	"query returned more than",
+	"response size exceeded",
+	"response is too big",
+	"response too large",
+	"block range is too large",
+	"block range too large",
+	"exceed maximum block range",
+	"too many blocks",
+	"limit exceeded",
+}
+
+// IsRangeTooLarge returns whether err rejects a query for its block range or the size of its response, which
+// succeeds with a smaller range.
+func IsRangeTooLarge(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range rangeTooLargeErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdaptiveRange sizes the ranges of a watcher by the density of their logs: it halves the size when a range is
+// rejected as too large or has more than targetLogs logs, and doubles it when a full range has less than a quarter of
+// them, between min and max blocks.
+type AdaptiveRange struct {
+	min        uint64
+	max        uint64
+	size       uint64
+	targetLogs int
+}
+
+// NewAdaptiveRange creates a range sizing starting at initial blocks, bounded by min and max, min is at least 1.
+func NewAdaptiveRange(initial, min, max uint64, targetLogs int) *AdaptiveRange {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	a := &AdaptiveRange{min: min, max: max, size: initial, targetLogs: targetLogs}
+	a.clamp()
+	return a
+}
+
+// Size returns the number of blocks of the next range.
+func (a *AdaptiveRange) Size() uint64 {
+	return a.size
+}
+
+// observe adapts the size to the number of logs of a decoded range.
+func (a *AdaptiveRange) observe(r Range, numLogs int) {
+	switch {
+	case numLogs > a.targetLogs:
+		a.size /= 2
+	case numLogs < a.targetLogs/4 && r.To-r.From+1 >= a.size:
+		a.size *= 2
+	}
+	a.clamp()
+}
+
+// shrink halves the size after a range was rejected as too large, it returns false if the size is already min.
+func (a *AdaptiveRange) shrink() bool {
+	if a.size <= a.min {
+		return false
+	}
+	a.size /= 2
+	a.clamp()
+	return true
+}
+
+func (a *AdaptiveRange) clamp() {
+	if a.size < a.min {
+		a.size = a.min
+	}
+	if a.size > a.max {
+		a.size = a.max
+	}
+}
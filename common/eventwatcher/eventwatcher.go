@@ -0,0 +1,154 @@
+// Package eventwatcher is the event ingestion loop shared by the rollup watchers and the bridge-history-api fetchers:
+// it splits the confirmed blocks after a cursor into ranges, decodes the events of each range and persists them,
+// moving the cursor range by range so that a failed sync resumes where it stopped.
+package eventwatcher
+
+import (
+	"context"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// Cursor is the last block whose events are persisted.
+type Cursor struct {
+	Height uint64
+	// Hash of the block, zero if the decoder does not detect reorgs.
+	Hash common.Hash
+}
+
+// Range is an inclusive range of blocks.
+type Range struct {
+	From uint64
+	To   uint64
+}
+
+// PlanRanges splits the blocks after synced up to confirmed into consecutive ranges of at most limit blocks, limit is
+// at least 1. There are no ranges if confirmed is not after synced.
+func PlanRanges(synced, confirmed, limit uint64) []Range {
+	if limit < 1 {
+		limit = 1
+	}
+	var ranges []Range
+	for from := synced + 1; from <= confirmed; from += limit {
+		to := from + limit - 1
+		if to > confirmed {
+			to = confirmed
+		}
+		ranges = append(ranges, Range{From: from, To: to})
+	}
+	return ranges
+}
+
+// Decoded is the outcome of decoding a range of blocks.
+type Decoded[E any] struct {
+	Events E
+	// Cursor is the last block of the range, or the block to resync from if Reorg.
+	Cursor Cursor
+	// Reorg is set if the range does not extend the chain of the cursor, Events is then empty.
+	Reorg bool
+	// NumLogs is the number of logs of the range, it sizes the next ranges of a watcher with an AdaptiveRange.
+	NumLogs int
+}
+
+// Decoder fetches and decodes the events of a range of blocks.
+type Decoder[E any] interface {
+	// Decode decodes the events of r, which directly follows cursor.
+	Decode(ctx context.Context, r Range, cursor Cursor) (*Decoded[E], error)
+}
+
+// Sink persists the decoded events of a range of blocks.
+type Sink[E any] interface {
+	Persist(ctx context.Context, r Range, events E) error
+}
+
+// Watcher ingests the events of the confirmed blocks range by range, from its cursor on. It is not safe for
+// concurrent use.
+type Watcher[E any] struct {
+	decoder    Decoder[E]
+	sink       Sink[E]
+	fetchLimit uint64
+	cursor     Cursor
+	adaptive   *AdaptiveRange
+	onAdvance  func(cursor Cursor, reorg bool)
+}
+
+// New creates a watcher decoding ranges of at most fetchLimit blocks, starting after cursor.
+func New[E any](decoder Decoder[E], sink Sink[E], fetchLimit uint64, cursor Cursor) *Watcher[E] {
+	return &Watcher[E]{
+		decoder:    decoder,
+		sink:       sink,
+		fetchLimit: fetchLimit,
+		cursor:     cursor,
+	}
+}
+
+// OnAdvance sets fn to be called whenever Sync moves the cursor, forward after a persisted range or back on a reorg.
+func (w *Watcher[E]) OnAdvance(fn func(cursor Cursor, reorg bool)) {
+	w.onAdvance = fn
+}
+
+// SetAdaptiveRange sizes the ranges by a, instead of by the fixed fetch limit. A range rejected as too large is then
+// retried with a smaller size instead of failing the sync.
+func (w *Watcher[E]) SetAdaptiveRange(a *AdaptiveRange) {
+	w.adaptive = a
+}
+
+// Cursor returns the last block whose events are persisted.
+func (w *Watcher[E]) Cursor() Cursor {
+	return w.cursor
+}
+
+// SetCursor moves the cursor, e.g. to the height restored from the database on startup.
+func (w *Watcher[E]) SetCursor(cursor Cursor) {
+	w.cursor = cursor
+}
+
+// Sync ingests the ranges after the cursor up to confirmed. It returns the first decode or persist error unchanged,
+// and returns without error after moving the cursor back on a reorg; the next Sync resumes from the cursor.
+func (w *Watcher[E]) Sync(ctx context.Context, confirmed uint64) error {
+	for w.cursor.Height < confirmed {
+		r := w.nextRange(confirmed)
+		decoded, err := w.decoder.Decode(ctx, r, w.cursor)
+		if err != nil {
+			if w.adaptive != nil && IsRangeTooLarge(err) && w.adaptive.shrink() {
+				continue
+			}
+			return err
+		}
+		if decoded.Reorg {
+			w.advance(decoded.Cursor, true)
+			return nil
+		}
+		if w.adaptive != nil {
+			w.adaptive.observe(r, decoded.NumLogs)
+		}
+		if err = w.sink.Persist(ctx, r, decoded.Events); err != nil {
+			return err
+		}
+		w.advance(decoded.Cursor, false)
+	}
+	return nil
+}
+
+// nextRange returns the range after the cursor, it is not empty as the cursor is before confirmed.
+func (w *Watcher[E]) nextRange(confirmed uint64) Range {
+	limit := w.fetchLimit
+	if w.adaptive != nil {
+		limit = w.adaptive.Size()
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	r := Range{From: w.cursor.Height + 1, To: w.cursor.Height + limit}
+	if r.To > confirmed {
+		r.To = confirmed
+	}
+	return r
+}
+
+func (w *Watcher[E]) advance(cursor Cursor, reorg bool) {
+	w.cursor = cursor
+	if w.onAdvance != nil {
+		w.onAdvance(cursor, reorg)
+	}
+}
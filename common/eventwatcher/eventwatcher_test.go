@@ -0,0 +1,130 @@
+package eventwatcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanRanges(t *testing.T) {
+	assert.Empty(t, PlanRanges(10, 10, 5))
+	assert.Empty(t, PlanRanges(10, 5, 5))
+	assert.Equal(t, []Range{{From: 11, To: 15}, {From: 16, To: 20}, {From: 21, To: 22}}, PlanRanges(10, 22, 5))
+	assert.Equal(t, []Range{{From: 1, To: 1}, {From: 2, To: 2}}, PlanRanges(0, 2, 0))
+}
+
+type fakeDecoder struct {
+	reorgAt uint64
+	err     error
+}
+
+func (d *fakeDecoder) Decode(_ context.Context, r Range, _ Cursor) (*Decoded[[]uint64], error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.reorgAt != 0 && r.To >= d.reorgAt {
+		return &Decoded[[]uint64]{Cursor: Cursor{Height: d.reorgAt - 5}, Reorg: true}, nil
+	}
+	var events []uint64
+	for height := r.From; height <= r.To; height++ {
+		events = append(events, height)
+	}
+	return &Decoded[[]uint64]{Events: events, Cursor: Cursor{Height: r.To}}, nil
+}
+
+type fakeSink struct {
+	persisted []uint64
+	failAt    uint64
+}
+
+func (s *fakeSink) Persist(_ context.Context, r Range, events []uint64) error {
+	if s.failAt >= r.From && s.failAt <= r.To {
+		return errors.New("persist failure")
+	}
+	s.persisted = append(s.persisted, events...)
+	return nil
+}
+
+func TestWatcherSync(t *testing.T) {
+	decoder := &fakeDecoder{}
+	sink := &fakeSink{}
+	w := New[[]uint64](decoder, sink, 3, Cursor{Height: 10})
+	var advances []uint64
+	w.OnAdvance(func(cursor Cursor, reorg bool) {
+		advances = append(advances, cursor.Height)
+	})
+
+	assert.NoError(t, w.Sync(context.Background(), 17))
+	assert.Equal(t, []uint64{11, 12, 13, 14, 15, 16, 17}, sink.persisted)
+	assert.Equal(t, []uint64{13, 16, 17}, advances)
+	assert.Equal(t, uint64(17), w.Cursor().Height)
+
+	// a failed range keeps the cursor at the last persisted range.
+	sink.failAt = 22
+	assert.EqualError(t, w.Sync(context.Background(), 25), "persist failure")
+	assert.Equal(t, uint64(20), w.Cursor().Height)
+
+	// a reorg moves the cursor back and stops the sync.
+	sink.failAt = 0
+	decoder.reorgAt = 24
+	var reorged bool
+	w.OnAdvance(func(cursor Cursor, reorg bool) {
+		reorged = reorged || reorg
+	})
+	assert.NoError(t, w.Sync(context.Background(), 25))
+	assert.True(t, reorged)
+	assert.Equal(t, uint64(19), w.Cursor().Height)
+
+	decoder.err = errors.New("decode failure")
+	assert.EqualError(t, w.Sync(context.Background(), 25), "decode failure")
+	assert.Equal(t, uint64(19), w.Cursor().Height)
+}
+
+// denseDecoder returns a log per block, and rejects the ranges with more than maxLogs logs.
+type denseDecoder struct {
+	maxLogs int
+	ranges  []Range
+}
+
+func (d *denseDecoder) Decode(_ context.Context, r Range, _ Cursor) (*Decoded[[]uint64], error) {
+	numLogs := int(r.To - r.From + 1)
+	if numLogs > d.maxLogs {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	d.ranges = append(d.ranges, r)
+	return &Decoded[[]uint64]{Cursor: Cursor{Height: r.To}, NumLogs: numLogs}, nil
+}
+
+func TestIsRangeTooLarge(t *testing.T) {
+	assert.False(t, IsRangeTooLarge(nil))
+	assert.False(t, IsRangeTooLarge(errors.New("connection refused")))
+	assert.True(t, IsRangeTooLarge(errors.New("failed to fetch L1 events, error: Log response size exceeded.")))
+	assert.True(t, IsRangeTooLarge(errors.New("exceed maximum block range: 5000")))
+}
+
+func TestWatcherAdaptiveRange(t *testing.T) {
+	decoder := &denseDecoder{maxLogs: 10}
+	adaptive := NewAdaptiveRange(40, 2, 64, 8)
+	w := New[[]uint64](decoder, &fakeSink{}, 40, Cursor{})
+	w.SetAdaptiveRange(adaptive)
+
+	// the rejected ranges of 40 and 20 blocks are retried with 10 blocks, which have more logs than the target.
+	assert.NoError(t, w.Sync(context.Background(), 15))
+	assert.Equal(t, []Range{{From: 1, To: 10}, {From: 11, To: 15}}, decoder.ranges)
+	assert.Equal(t, uint64(15), w.Cursor().Height)
+	assert.Equal(t, uint64(5), adaptive.Size())
+
+	// sparse ranges grow the size up to the max.
+	decoder.maxLogs, decoder.ranges = 1000, nil
+	adaptive.targetLogs = 1000
+	assert.NoError(t, w.Sync(context.Background(), 200))
+	assert.Equal(t, Range{From: 16, To: 20}, decoder.ranges[0])
+	assert.Equal(t, uint64(64), adaptive.Size())
+
+	// a range rejected at the min size fails the sync.
+	decoder.maxLogs = 1
+	assert.EqualError(t, w.Sync(context.Background(), 300), "query returned more than 10000 results")
+	assert.Equal(t, uint64(200), w.Cursor().Height)
+}
@@ -0,0 +1,203 @@
+// Package featureflag toggles risky behaviors of a service per deployment without redeploys. A flag is set by, from
+// the lowest to the highest precedence: the default of its call site, the config file, the SCROLL_FEATURE_<NAME>
+// environment variable, and the overrides of a Source, e.g. the feature_flag table, refreshed periodically.
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/utils"
+)
+
+const (
+	envPrefix = "SCROLL_FEATURE_"
+
+	defaultRefreshInterval = 30 * time.Second
+)
+
+// Config is the feature flags section of a service config.
+type Config struct {
+	// Flags by name, e.g. {"bundled_finalization": true}.
+	Flags map[string]bool `json:"flags"`
+	// RefreshIntervalSec is the period of reloading the overrides of the source, 30s by default.
+	RefreshIntervalSec uint64 `json:"refresh_interval_sec"`
+}
+
+// Source provides the runtime overrides of the flags.
+type Source interface {
+	Overrides(ctx context.Context) (map[string]bool, error)
+}
+
+// Flags are the feature flags of a service. It is safe for concurrent use.
+type Flags struct {
+	static          map[string]bool // config file and environment
+	source          Source
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	overrides map[string]bool
+
+	enabledGauge *prometheus.GaugeVec
+}
+
+// New creates the flags of cfg, which may be nil, with the environment applied. source may be nil if the flags are
+// not toggled at runtime.
+func New(cfg *Config, source Source, reg prometheus.Registerer) *Flags {
+	f := &Flags{
+		static:          make(map[string]bool),
+		source:          source,
+		refreshInterval: defaultRefreshInterval,
+		overrides:       make(map[string]bool),
+		enabledGauge: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "feature_flag_enabled",
+			Help: "Whether a feature flag set by the config, the environment or a runtime override is enabled.",
+		}, []string{"name"}),
+	}
+	if cfg != nil {
+		for name, enabled := range cfg.Flags {
+			f.static[name] = enabled
+		}
+		if cfg.RefreshIntervalSec > 0 {
+			f.refreshInterval = time.Duration(cfg.RefreshIntervalSec) * time.Second
+		}
+	}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, envPrefix) {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Warn("ignoring invalid feature flag environment variable", "key", key, "value", value)
+			continue
+		}
+		f.static[strings.ToLower(strings.TrimPrefix(key, envPrefix))] = enabled
+	}
+	f.updateGauge()
+	return f
+}
+
+// Enabled returns whether the flag is enabled, false if it is not set.
+func (f *Flags) Enabled(name string) bool {
+	return f.EnabledOr(name, false)
+}
+
+// EnabledOr returns whether the flag is enabled, or defaultValue if it is not set.
+func (f *Flags) EnabledOr(name string, defaultValue bool) bool {
+	f.mu.RLock()
+	enabled, ok := f.overrides[name]
+	f.mu.RUnlock()
+	if ok {
+		return enabled
+	}
+	if enabled, ok = f.static[name]; ok {
+		return enabled
+	}
+	return defaultValue
+}
+
+// Gate returns a function running fn only while the flag is enabled, e.g. to toggle a loop of utils.Loop at runtime.
+func (f *Flags) Gate(name string, defaultValue bool, fn func()) func() {
+	return func() {
+		if f.EnabledOr(name, defaultValue) {
+			fn()
+		}
+	}
+}
+
+// Refresh reloads the overrides of the source. The previous overrides stay in effect if it fails.
+func (f *Flags) Refresh(ctx context.Context) error {
+	if f.source == nil {
+		return nil
+	}
+	overrides, err := f.source.Overrides(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load feature flag overrides: %w", err)
+	}
+
+	f.mu.Lock()
+	for name, enabled := range overrides {
+		if previous, ok := f.overrides[name]; !ok || previous != enabled {
+			log.Info("feature flag overridden", "name", name, "enabled", enabled)
+		}
+	}
+	for name := range f.overrides {
+		if _, ok := overrides[name]; !ok {
+			log.Info("feature flag override removed", "name", name)
+		}
+	}
+	f.overrides = overrides
+	f.mu.Unlock()
+
+	f.updateGauge()
+	return nil
+}
+
+// Start refreshes the overrides periodically until ctx is done, it does nothing without a source.
+func (f *Flags) Start(ctx context.Context) {
+	if f.source == nil {
+		return
+	}
+	go utils.LoopWithContext(ctx, f.refreshInterval, func(ctx context.Context) {
+		if err := f.Refresh(ctx); err != nil {
+			log.Warn("failed to refresh feature flags", "error", err)
+		}
+	})
+}
+
+func (f *Flags) updateGauge() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	f.enabledGauge.Reset()
+	for name, enabled := range f.static {
+		if _, ok := f.overrides[name]; !ok {
+			f.enabledGauge.WithLabelValues(name).Set(boolToFloat(enabled))
+		}
+	}
+	for name, enabled := range f.overrides {
+		f.enabledGauge.WithLabelValues(name).Set(boolToFloat(enabled))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// dbSource reads the overrides from the feature_flag table.
+type dbSource struct {
+	db *gorm.DB
+}
+
+// NewDBSource returns a source of the overrides stored in the feature_flag table of db.
+func NewDBSource(db *gorm.DB) Source {
+	return &dbSource{db: db}
+}
+
+func (s *dbSource) Overrides(ctx context.Context) (map[string]bool, error) {
+	var rows []struct {
+		Name    string `gorm:"column:name"`
+		Enabled bool   `gorm:"column:enabled"`
+	}
+	if err := s.db.WithContext(ctx).Table("feature_flag").Select("name, enabled").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		overrides[row.Name] = row.Enabled
+	}
+	return overrides, nil
+}
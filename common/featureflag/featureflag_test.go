@@ -0,0 +1,65 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	overrides map[string]bool
+	err       error
+}
+
+func (s *fakeSource) Overrides(context.Context) (map[string]bool, error) {
+	return s.overrides, s.err
+}
+
+func TestFlags(t *testing.T) {
+	t.Setenv("SCROLL_FEATURE_BLOB_SUBMISSION", "false")
+	t.Setenv("SCROLL_FEATURE_NEW_DECODER", "not-a-bool")
+
+	source := &fakeSource{}
+	cfg := &Config{Flags: map[string]bool{"blob_submission": true, "bundled_finalization": true}}
+	flags := New(cfg, source, prometheus.NewRegistry())
+
+	// the environment overrides the config file.
+	assert.False(t, flags.Enabled("blob_submission"))
+	assert.True(t, flags.Enabled("bundled_finalization"))
+	assert.False(t, flags.Enabled("new_decoder"))
+	assert.True(t, flags.EnabledOr("new_decoder", true))
+
+	// the overrides of the source take precedence, and stay while it fails.
+	source.overrides = map[string]bool{"blob_submission": true, "bundled_finalization": false}
+	assert.NoError(t, flags.Refresh(context.Background()))
+	assert.True(t, flags.Enabled("blob_submission"))
+	assert.False(t, flags.Enabled("bundled_finalization"))
+
+	source.err = errors.New("db down")
+	assert.Error(t, flags.Refresh(context.Background()))
+	assert.True(t, flags.Enabled("blob_submission"))
+
+	// removing an override falls back to the config file and the environment.
+	source.overrides, source.err = map[string]bool{}, nil
+	assert.NoError(t, flags.Refresh(context.Background()))
+	assert.False(t, flags.Enabled("blob_submission"))
+	assert.True(t, flags.Enabled("bundled_finalization"))
+
+	var runs int
+	gated := flags.Gate("bundled_finalization", false, func() { runs++ })
+	gated()
+	source.overrides = map[string]bool{"bundled_finalization": false}
+	assert.NoError(t, flags.Refresh(context.Background()))
+	gated()
+	assert.Equal(t, 1, runs)
+}
+
+func TestFlagsWithoutConfig(t *testing.T) {
+	flags := New(nil, nil, prometheus.NewRegistry())
+	assert.NoError(t, flags.Refresh(context.Background()))
+	assert.False(t, flags.Enabled("blob_submission"))
+	assert.True(t, flags.EnabledOr("blob_submission", true))
+}
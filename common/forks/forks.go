@@ -87,3 +87,60 @@ func BlockRange(currentForkHeight uint64, forkHeights []uint64) (from, to uint64
 	}
 	return
 }
+
+// ForkNameByBlockHeight returns the name of the fork active at the block height, which is the fork
+// with the highest activation height not above blockHeight
+// returns an empty string if no fork is active at the block height
+func ForkNameByBlockHeight(blockHeight uint64, nameForkMap map[string]uint64) string {
+	var forkName string
+	var forkHeight uint64
+	for name, height := range nameForkMap {
+		if height > blockHeight {
+			continue
+		}
+		// break ties by name so that the result does not depend on map iteration order
+		if forkName == "" || height > forkHeight || (height == forkHeight && name > forkName) {
+			forkName = name
+			forkHeight = height
+		}
+	}
+	return forkName
+}
+
+// SpansForkBoundary returns whether the block range [startBlock, endBlock] crosses a fork boundary,
+// i.e. a fork is activated on a block after startBlock and not after endBlock
+func SpansForkBoundary(startBlock, endBlock uint64, forkHeights []uint64) bool {
+	for _, forkHeight := range forkHeights {
+		if forkHeight > startBlock && forkHeight <= endBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideForkHeights applies the overrides to the fork name to height map and returns the sorted
+// fork heights together with the resulting map, the input map is left untouched
+// like CollectSortedForkHeights, only one fork is kept at each height, and an overridden fork wins
+func OverrideForkHeights(nameForkMap map[string]uint64, overrides map[string]uint64) ([]uint64, map[string]uint64) {
+	forkHeightNameMap := make(map[uint64]string)
+	for name, height := range nameForkMap {
+		if _, overridden := overrides[name]; overridden {
+			continue
+		}
+		forkHeightNameMap[height] = name
+	}
+	for name, height := range overrides {
+		forkHeightNameMap[height] = name
+	}
+
+	forkNameHeightMap := make(map[string]uint64)
+	var forkHeights []uint64
+	for height, name := range forkHeightNameMap {
+		forkNameHeightMap[name] = height
+		forkHeights = append(forkHeights, height)
+	}
+	sort.Slice(forkHeights, func(i, j int) bool {
+		return forkHeights[i] < forkHeights[j]
+	})
+	return forkHeights, forkNameHeightMap
+}
@@ -140,3 +140,137 @@ func TestBlockRange(t *testing.T) {
 		})
 	}
 }
+
+func TestForkNameByBlockHeight(t *testing.T) {
+	nameForkMap := map[string]uint64{
+		"archimedes": 0,
+		"bernoulli":  100,
+		"curie":      200,
+	}
+	tests := map[string]struct {
+		block    uint64
+		forks    map[string]uint64
+		expected string
+	}{
+		"NoFork": {
+			block:    44,
+			forks:    map[string]uint64{},
+			expected: "",
+		},
+		"BeforeFirstFork": {
+			block:    5,
+			forks:    map[string]uint64{"curie": 10},
+			expected: "",
+		},
+		"Genesis": {
+			block:    0,
+			forks:    nameForkMap,
+			expected: "archimedes",
+		},
+		"BeforeFork": {
+			block:    99,
+			forks:    nameForkMap,
+			expected: "archimedes",
+		},
+		"OnFork": {
+			block:    100,
+			forks:    nameForkMap,
+			expected: "bernoulli",
+		},
+		"AfterLastFork": {
+			block:    1000,
+			forks:    nameForkMap,
+			expected: "curie",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, ForkNameByBlockHeight(test.block, test.forks))
+		})
+	}
+}
+
+func TestSpansForkBoundary(t *testing.T) {
+	tests := map[string]struct {
+		start    uint64
+		end      uint64
+		forks    []uint64
+		expected bool
+	}{
+		"NoFork": {
+			start:    1,
+			end:      10,
+			forks:    []uint64{},
+			expected: false,
+		},
+		"StartsOnFork": {
+			start:    5,
+			end:      10,
+			forks:    []uint64{0, 5},
+			expected: false,
+		},
+		"EndsBeforeFork": {
+			start:    1,
+			end:      4,
+			forks:    []uint64{0, 5},
+			expected: false,
+		},
+		"EndsOnFork": {
+			start:    1,
+			end:      5,
+			forks:    []uint64{0, 5},
+			expected: true,
+		},
+		"ContainsFork": {
+			start:    1,
+			end:      10,
+			forks:    []uint64{0, 5, 20},
+			expected: true,
+		},
+		"SingleBlock": {
+			start:    5,
+			end:      5,
+			forks:    []uint64{5},
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, SpansForkBoundary(test.start, test.end, test.forks))
+		})
+	}
+}
+
+func TestOverrideForkHeights(t *testing.T) {
+	nameForkMap := map[string]uint64{
+		"archimedes": 0,
+		"bernoulli":  100,
+	}
+
+	heights, names := OverrideForkHeights(nameForkMap, nil)
+	require.Equal(t, []uint64{0, 100}, heights)
+	require.Equal(t, nameForkMap, names)
+
+	heights, names = OverrideForkHeights(nameForkMap, map[string]uint64{
+		"bernoulli": 150,
+		"curie":     200,
+	})
+	require.Equal(t, []uint64{0, 150, 200}, heights)
+	require.Equal(t, map[string]uint64{
+		"archimedes": 0,
+		"bernoulli":  150,
+		"curie":      200,
+	}, names)
+	require.Equal(t, uint64(100), nameForkMap["bernoulli"])
+
+	heights, names = OverrideForkHeights(nameForkMap, map[string]uint64{
+		"curie": 100,
+	})
+	require.Equal(t, []uint64{0, 100}, heights)
+	require.Equal(t, map[string]uint64{
+		"archimedes": 0,
+		"curie":      100,
+	}, names)
+}
@@ -0,0 +1,142 @@
+// Package goldenlogs records the logs of the watched events into golden files, and replays them through the
+// decoders in unit tests, so that the decoding layers of the bridge and the bridge history can be refactored safely.
+//
+// A golden file holds the logs of an event of a contract along with their expected decoded values, i.e. the json of
+// the typed event of common/types/events the rollup watcher and the bridge history fetcher decode it into.
+// The golden files are in common/testdata/golden_logs, they are recorded with ./goldenlogs/record and their decoded
+// values filled by replaying them once with -update-golden. The files without a chain id were encoded from the
+// contract abi, recording them from a mainnet rpc replaces them.
+package goldenlogs
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite the expected decoded values of the replayed golden logs")
+
+// File is a golden file, the logs of an event emitted by a contract.
+type File struct {
+	Network string `json:"network"`
+	// ChainID is the chain id of the rpc the logs were recorded from, 0 for logs encoded from the contract abi.
+	ChainID   uint64         `json:"chain_id,omitempty"`
+	Contract  common.Address `json:"contract"`
+	Signature string         `json:"signature"` // e.g. QueueTransaction(address,address,uint256,uint64,uint256,bytes)
+	Cases     []*Case        `json:"cases"`
+}
+
+// Case is a log and its expected decoded value, which is null until the first replay with -update-golden.
+type Case struct {
+	Log     gethTypes.Log   `json:"log"`
+	Decoded json.RawMessage `json:"decoded"`
+}
+
+// Event returns the name of the event of the file.
+func (f *File) Event() string {
+	return strings.SplitN(f.Signature, "(", 2)[0]
+}
+
+// Topic returns the topic of the event of the file.
+func (f *File) Topic() common.Hash {
+	return crypto.Keccak256Hash([]byte(f.Signature))
+}
+
+// Load reads a golden file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file %s, err: %w", path, err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode golden file %s, err: %w", path, err)
+	}
+	return &file, nil
+}
+
+// Save writes the golden file.
+func (f *File) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode golden file %s, err: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write golden file %s, err: %w", path, err)
+	}
+	return nil
+}
+
+// LogFilterer is the subset of the ethclient the logs are recorded with.
+type LogFilterer interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error)
+}
+
+// Record returns the golden file of at most limit logs of the event of signature emitted by contract in the block
+// range [from, to]. The expected decoded values are left null, the first replay with -update-golden fills them.
+func Record(ctx context.Context, client LogFilterer, network string, contract common.Address, signature string, from, to uint64, limit int) (*File, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id, err: %w", err)
+	}
+	file := &File{Network: network, ChainID: chainID.Uint64(), Contract: contract, Signature: signature}
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{contract},
+		Topics:    [][]common.Hash{{file.Topic()}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs of %s in blocks [%d, %d], err: %w", file.Event(), from, to, err)
+	}
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	for _, l := range logs {
+		file.Cases = append(file.Cases, &Case{Log: l})
+	}
+	return file, nil
+}
+
+// Replay decodes the logs of the golden file at path with decode, and checks that their json encodings are the
+// expected decoded values. With -update-golden, the expected decoded values are rewritten instead.
+func Replay(t *testing.T, path string, decode func(gethTypes.Log) (interface{}, error)) {
+	file, err := Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, file.Cases, "no logs in golden file %s", path)
+
+	for i, c := range file.Cases {
+		require.NotEmpty(t, c.Log.Topics, "case %d of %s", i, path)
+		require.Equal(t, file.Topic(), c.Log.Topics[0], "case %d of %s is not a %s log", i, path, file.Event())
+
+		decoded, err := decode(c.Log)
+		require.NoError(t, err, "case %d of %s", i, path)
+		actual, err := json.Marshal(decoded)
+		require.NoError(t, err, "case %d of %s", i, path)
+
+		if *update {
+			c.Decoded = actual
+			continue
+		}
+		require.False(t, len(c.Decoded) == 0 || string(c.Decoded) == "null",
+			"case %d of %s has no expected decoded value, replay with -update-golden", i, path)
+		assert.JSONEq(t, string(c.Decoded), string(actual), "case %d of %s, tx %s", i, path, c.Log.TxHash.Hex())
+	}
+
+	if *update {
+		require.NoError(t, file.Save(path))
+	}
+}
@@ -0,0 +1,68 @@
+package goldenlogs
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFilterer struct {
+	query ethereum.FilterQuery
+	logs  []gethTypes.Log
+}
+
+func (m *mockFilterer) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (m *mockFilterer) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]gethTypes.Log, error) {
+	m.query = q
+	return m.logs, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	const signature = "RelayedMessage(bytes32)"
+	contract := common.HexToAddress("0x6774Bcbd5ceCeF1336b5300fb5186a12DDD8b367")
+	topic := (&File{Signature: signature}).Topic()
+
+	client := &mockFilterer{}
+	for i := 0; i < 3; i++ {
+		client.logs = append(client.logs, gethTypes.Log{
+			Address:     contract,
+			Topics:      []common.Hash{topic, common.BigToHash(big.NewInt(int64(i)))},
+			BlockNumber: uint64(100 + i),
+			TxHash:      common.BigToHash(big.NewInt(int64(1000 + i))),
+		})
+	}
+
+	file, err := Record(context.Background(), client, "mainnet", contract, signature, 100, 200, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{contract}, client.query.Addresses)
+	assert.Equal(t, [][]common.Hash{{topic}}, client.query.Topics)
+	assert.Equal(t, uint64(1), file.ChainID)
+	assert.Equal(t, "RelayedMessage", file.Event())
+	assert.Len(t, file.Cases, 2)
+
+	path := filepath.Join(t.TempDir(), "relayed_message.json")
+	assert.NoError(t, file.Save(path))
+
+	decode := func(l gethTypes.Log) (interface{}, error) {
+		return map[string]interface{}{"MessageHash": l.Topics[1]}, nil
+	}
+	*update = true
+	Replay(t, path, decode)
+	*update = false
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"MessageHash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`, string(loaded.Cases[1].Decoded))
+	assert.Equal(t, file.Cases[1].Log.TxHash, loaded.Cases[1].Log.TxHash)
+
+	Replay(t, path, decode)
+}
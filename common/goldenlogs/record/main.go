@@ -0,0 +1,93 @@
+// Command record records the logs of an event of a contract into a golden file, e.g.
+//
+//	go run ./goldenlogs/record --rpc $L1_RPC --contract 0x0d7E906BD9cAFa154b048cFa766Cc1E54E39AF9B \
+//		--signature 'QueueTransaction(address,address,uint256,uint64,uint256,bytes)' \
+//		--from 18306000 --to 18310000 --out testdata/golden_logs/l1_message_queue_queue_transaction.json
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/goldenlogs"
+)
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:     "rpc",
+		Usage:    "Endpoint of the rpc the logs are recorded from",
+		Required: true,
+	}
+	networkFlag = cli.StringFlag{
+		Name:  "network",
+		Usage: "Name of the network of the rpc",
+		Value: "mainnet",
+	}
+	contractFlag = cli.StringFlag{
+		Name:     "contract",
+		Usage:    "Address of the contract emitting the event",
+		Required: true,
+	}
+	signatureFlag = cli.StringFlag{
+		Name:     "signature",
+		Usage:    "Signature of the event, e.g. CommitBatch(uint256,bytes32)",
+		Required: true,
+	}
+	fromFlag = cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "First block of the range the logs are recorded from",
+		Required: true,
+	}
+	toFlag = cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Last block of the range the logs are recorded from",
+		Required: true,
+	}
+	limitFlag = cli.IntFlag{
+		Name:  "limit",
+		Usage: "Maximum number of logs recorded, 0 for all",
+		Value: 10,
+	}
+	outFlag = cli.StringFlag{
+		Name:     "out",
+		Usage:    "Path of the golden file",
+		Required: true,
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "record"
+	app.Usage = "Records the logs of an event into a golden file, replay it with -update-golden to fill the decoded values"
+	app.Flags = []cli.Flag{&rpcFlag, &networkFlag, &contractFlag, &signatureFlag, &fromFlag, &toFlag, &limitFlag, &outFlag}
+	app.Action = record
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func record(ctx *cli.Context) error {
+	if !common.IsHexAddress(ctx.String(contractFlag.Name)) {
+		return fmt.Errorf("invalid contract address %s", ctx.String(contractFlag.Name))
+	}
+	client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to dial %s, err: %w", ctx.String(rpcFlag.Name), err)
+	}
+	defer client.Close()
+
+	file, err := goldenlogs.Record(ctx.Context, client, ctx.String(networkFlag.Name), common.HexToAddress(ctx.String(contractFlag.Name)),
+		ctx.String(signatureFlag.Name), ctx.Uint64(fromFlag.Name), ctx.Uint64(toFlag.Name), ctx.Int(limitFlag.Name))
+	if err != nil {
+		return err
+	}
+	if len(file.Cases) == 0 {
+		return fmt.Errorf("no %s logs in blocks [%d, %d]", file.Event(), ctx.Uint64(fromFlag.Name), ctx.Uint64(toFlag.Name))
+	}
+	return file.Save(ctx.String(outFlag.Name))
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	initRegistryOnce sync.Once
+	registry         *prometheus.Registry
+)
+
+// Registry returns the process-wide prometheus registry, with the Go runtime and process collectors registered.
+func Registry() *prometheus.Registry {
+	initRegistryOnce.Do(func() {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	})
+	return registry
+}
+
+// Registerer returns the registerer of the process-wide registry, all module metrics should be registered through it.
+// Like the default prometheus registerer, MustRegister panics on a collector which is already registered.
+func Registerer() prometheus.Registerer {
+	return Registry()
+}
+
+// Handler returns the http handler exposing the metrics of the process-wide registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry(), promhttp.HandlerOpts{})
+}
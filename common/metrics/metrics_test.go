@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistererDuplicateRegistration(t *testing.T) {
+	opts := prometheus.CounterOpts{
+		Name: "metrics_test_duplicate_registration_total",
+		Help: "Test counter registered twice.",
+	}
+	assert.NotPanics(t, func() {
+		promauto.With(Registerer()).NewCounter(opts)
+	})
+
+	// The second counter would never be exported.
+	assert.Panics(t, func() {
+		promauto.With(Registerer()).NewCounter(opts)
+	})
+}
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	Serve(ctx, &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: time.Minute})
+
+	var resp *http.Response
+	assert.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + addr + "/metrics") //nolint:gosec
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.True(t, strings.Contains(string(body), "go_goroutines"))
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, err = http.Get("http://" + addr + "/metrics") //nolint:gosec
+		return err != nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// serverShutdownTimeout bounds the time spent draining in-flight requests on shutdown.
+const serverShutdownTimeout = 5 * time.Second
+
+// Serve runs the given http server in the background, and shuts it down gracefully when the given context is canceled.
+func Serve(ctx context.Context, server *http.Server) {
+	go func() {
+		if runServerErr := server.ListenAndServe(); runServerErr != nil && !errors.Is(runServerErr, http.ErrServerClosed) {
+			log.Crit("run metrics http server failure", "error", runServerErr)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to shutdown metrics http server", "error", err)
+		}
+	}()
+}
@@ -6,7 +6,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"scroll-tech/common/metrics"
 )
 
 var (
@@ -27,7 +28,7 @@ func (m *Monitor) Use(r gin.IRoutes) {
 
 	r.Use(m.monitorInterceptor)
 	r.GET(m.metricPath, func(ctx *gin.Context) {
-		promhttp.Handler().ServeHTTP(ctx.Writer, ctx.Request)
+		metrics.Handler().ServeHTTP(ctx.Writer, ctx.Request)
 	})
 }
 
@@ -44,7 +45,7 @@ func (m *Monitor) UseWithoutExposingEndpoint(r gin.IRoutes) {
 // This allows to expose metrics on different port.
 func (m *Monitor) Expose(r gin.IRoutes) {
 	r.GET(m.metricPath, func(ctx *gin.Context) {
-		promhttp.Handler().ServeHTTP(ctx.Writer, ctx.Request)
+		metrics.Handler().ServeHTTP(ctx.Writer, ctx.Request)
 	})
 }
 
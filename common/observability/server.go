@@ -1,7 +1,6 @@
 package observability
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,17 +10,17 @@ import (
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/utils"
 )
 
-// Server starts the metrics server on the given address, will be closed when the given
-// context is canceled.
-func Server(c *cli.Context, db *gorm.DB) {
+// Server starts the metrics server on the given address, will be shut down gracefully when the given
+// context is canceled. The routes register the operational apis of the service, e.g. its admin apis.
+func Server(c *cli.Context, db *gorm.DB, routes ...func(r gin.IRouter)) {
 	if !c.Bool(utils.MetricsEnabled.Name) {
 		return
 	}
@@ -30,12 +29,16 @@ func Server(c *cli.Context, db *gorm.DB) {
 	r.Use(gin.Recovery())
 	pprof.Register(r)
 	r.GET("/metrics", func(context *gin.Context) {
-		promhttp.Handler().ServeHTTP(context.Writer, context.Request)
+		metrics.Handler().ServeHTTP(context.Writer, context.Request)
 	})
 
 	probeController := NewProbesController(db)
 	r.GET("/health", probeController.HealthCheck)
 	r.GET("/ready", probeController.Ready)
+	r.GET("/version", VersionHandler(c.App.Name))
+	for _, route := range routes {
+		route(r)
+	}
 
 	address := fmt.Sprintf(":%s", c.String(utils.MetricsPort.Name))
 	server := &http.Server{
@@ -44,10 +47,5 @@ func Server(c *cli.Context, db *gorm.DB) {
 		ReadHeaderTimeout: time.Minute,
 	}
 	log.Info("Starting metrics server", "address", address)
-
-	go func() {
-		if runServerErr := server.ListenAndServe(); runServerErr != nil && !errors.Is(runServerErr, http.ErrServerClosed) {
-			log.Crit("run metrics http server failure", "error", runServerErr)
-		}
-	}()
+	metrics.Serve(c.Context, server)
 }
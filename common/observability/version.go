@@ -0,0 +1,16 @@
+package observability
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/version"
+)
+
+// VersionHandler returns the handler of the version endpoint, which serves the build and runtime information of the
+// service.
+func VersionHandler(service string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		types.RenderSuccess(c, version.Info(service))
+	}
+}
@@ -0,0 +1,126 @@
+// Package retry runs operations again with exponential backoff and jitter while they fail, within a number of
+// attempts and a total time budget, so that the modules retry transient failures of their RPCs and databases alike.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"scroll-tech/common/clock"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultMultiplier     = 2
+)
+
+// Policy is how an operation is retried. The zero value retries every error forever, from 100ms of backoff doubling up
+// to 10s, without jitter.
+type Policy struct {
+	InitialBackoff time.Duration // Optional, the backoff after the first failure, defaults to 100ms.
+	MaxBackoff     time.Duration // Optional, the backoff cap, defaults to 10s.
+	Multiplier     float64       // Optional, the growth factor of the backoff, defaults to 2, 1 for a constant backoff.
+	// Optional, the fraction of each backoff which is randomized, between 0 and 1, so that the callers failing together
+	// do not retry together. A backoff b with jitter j is drawn from [b*(1-j), b].
+	Jitter float64
+	// Optional, the max number of attempts, including the first one, unlimited if 0.
+	MaxAttempts int
+	// Optional, the total time of the attempts and backoffs, unlimited if 0. The attempts get a context with its
+	// deadline, and no attempt starts which would begin after it.
+	Budget time.Duration
+	// Optional, whether the error of an attempt is retried, all errors are if nil.
+	Retryable func(err error) bool
+
+	// Optional, called before backing off after a failed attempt, e.g. to log or count the retries.
+	OnRetry func(attempt int, backoff time.Duration, err error)
+	// Optional, called when the call returns, with its number of attempts and its error, nil on success.
+	OnDone func(attempts int, elapsed time.Duration, err error)
+
+	// Optional, the clock of the backoffs and the budget, defaults to the time package.
+	Clock clock.Clock
+}
+
+// Do runs fn until it succeeds, returns a non retryable error, the attempts or the budget are exhausted, or ctx is
+// done, and returns the error of its last attempt, nil on success.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	clk := p.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+	start := clk.Now()
+	if p.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, start.Add(p.Budget))
+		defer cancel()
+	}
+
+	var err error
+	attempt := 0
+	defer func() {
+		if p.OnDone != nil {
+			p.OnDone(attempt, clk.Now().Sub(start), err)
+		}
+	}()
+	for backoff := p.initialBackoff(); ; backoff = p.nextBackoff(backoff) {
+		attempt++
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return err
+		}
+		if p.Retryable != nil && !p.Retryable(err) {
+			return err
+		}
+
+		wait := p.jitter(backoff)
+		if p.Budget > 0 && clk.Now().Add(wait).Sub(start) >= p.Budget {
+			return err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, wait, err)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clk.After(wait):
+		}
+	}
+}
+
+func (p *Policy) initialBackoff() time.Duration {
+	backoff := defaultInitialBackoff
+	if p.InitialBackoff > 0 {
+		backoff = p.InitialBackoff
+	}
+	return min(backoff, p.maxBackoff())
+}
+
+func (p *Policy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return max(defaultMaxBackoff, p.InitialBackoff)
+}
+
+func (p *Policy) nextBackoff(backoff time.Duration) time.Duration {
+	multiplier := float64(defaultMultiplier)
+	if p.Multiplier > 0 {
+		multiplier = p.Multiplier
+	}
+	next := time.Duration(float64(backoff) * multiplier)
+	if maxBackoff := p.maxBackoff(); next > maxBackoff || next < 0 {
+		return maxBackoff
+	}
+	return next
+}
+
+func (p *Policy) jitter(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+	jitter := min(p.Jitter, 1)
+	return backoff - time.Duration(rand.Float64()*jitter*float64(backoff))
+}
@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTransient = errors.New("transient error")
+
+func TestDo(t *testing.T) {
+	p := Policy{InitialBackoff: time.Millisecond, MaxAttempts: 5}
+
+	attempts := 0
+	err := Do(context.Background(), p, func(context.Context) error {
+		if attempts++; attempts < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// the error of the last attempt is returned once the attempts are exhausted.
+	attempts = 0
+	err = Do(context.Background(), p, func(context.Context) error {
+		attempts++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 5, attempts)
+
+	// non retryable errors are returned right away.
+	attempts = 0
+	otherErr := errors.New("other error")
+	p.Retryable = func(err error) bool { return errors.Is(err, errTransient) }
+	err = Do(context.Background(), p, func(context.Context) error {
+		attempts++
+		return otherErr
+	})
+	assert.ErrorIs(t, err, otherErr)
+	assert.Equal(t, 1, attempts)
+
+	// no attempt follows the cancellation of the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	err = Do(ctx, p, func(context.Context) error {
+		attempts++
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestDoBudget(t *testing.T) {
+	p := Policy{InitialBackoff: 100 * time.Millisecond, Multiplier: 1, Budget: 250 * time.Millisecond}
+
+	attempts := 0
+	start := time.Now()
+	err := Do(context.Background(), p, func(ctx context.Context) error {
+		attempts++
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, start.Add(p.Budget), deadline, 50*time.Millisecond)
+		return errTransient
+	})
+	assert.ErrorIs(t, err, errTransient)
+	// the third backoff would end after the budget.
+	assert.Equal(t, 3, attempts)
+	assert.Less(t, time.Since(start), p.Budget)
+}
+
+func TestBackoff(t *testing.T) {
+	p := &Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	backoff := p.initialBackoff()
+	var backoffs []time.Duration
+	for i := 0; i < 4; i++ {
+		backoffs = append(backoffs, backoff)
+		backoff = p.nextBackoff(backoff)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, backoffs)
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		jittered := p.jitter(time.Second)
+		assert.GreaterOrEqual(t, jittered, 500*time.Millisecond)
+		assert.LessOrEqual(t, jittered, time.Second)
+	}
+
+	// the defaults.
+	p = &Policy{}
+	assert.Equal(t, defaultInitialBackoff, p.initialBackoff())
+	assert.Equal(t, defaultMaxBackoff, p.nextBackoff(8*time.Second))
+}
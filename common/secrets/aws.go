@@ -0,0 +1,175 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const awsSecretsManagerService = "secretsmanager"
+
+// AWSCredentials are the credentials requests to AWS are signed with.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // empty for long-term credentials
+}
+
+// AWSProvider reads the secrets of AWS Secrets Manager. Paths are secret names or ARNs, e.g.
+// secret://aws/prod/coordinator#jwt_secret, the key selecting a field of a secret string holding a JSON object.
+type AWSProvider struct {
+	region   string
+	endpoint string
+	creds    AWSCredentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSProvider returns a provider reading the secrets of the region with the credentials, from the regional
+// endpoint if endpoint is empty.
+func NewAWSProvider(region, endpoint string, creds AWSCredentials) *AWSProvider {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", awsSecretsManagerService, region)
+	}
+	return &AWSProvider{
+		region:   region,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+}
+
+// NewAWSProviderFromEnv returns the provider of the region of AWS_REGION or AWS_DEFAULT_REGION with the credentials of
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, from AWS_ENDPOINT_URL_SECRETS_MANAGER or
+// AWS_ENDPOINT_URL if set, nil if the region or the credentials are not set.
+func NewAWSProviderFromEnv() *AWSProvider {
+	region := getenv("AWS_REGION", "AWS_DEFAULT_REGION")
+	creds := AWSCredentials{
+		AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    getenv("AWS_SESSION_TOKEN"),
+	}
+	if region == "" || creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil
+	}
+	return NewAWSProvider(region, getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", "AWS_ENDPOINT_URL"), creds)
+}
+
+// Resolve reads the current version of the secret named path, or the field key of its JSON object.
+func (p *AWSProvider) Resolve(ctx context.Context, path, key string) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signAWSRequestV4(req, body, p.creds, p.region, awsSecretsManagerService, p.now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type string `json:"__type"`
+		}
+		_ = json.Unmarshal(respBody, &awsErr)
+		return "", fmt.Errorf("secrets manager responded %s %s", resp.Status, awsErr.Type)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(respBody, &secret); err != nil {
+		return "", fmt.Errorf("failed to decode the secrets manager response, err: %w", err)
+	}
+	if secret.SecretString == nil {
+		return "", fmt.Errorf("the secret has no secret string, binary secrets are not supported")
+	}
+	if key == "" {
+		return *secret.SecretString, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*secret.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("the secret string is not a JSON object, err: %w", err)
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no field %q", key)
+	}
+	return stringValue(value)
+}
+
+// signAWSRequestV4 signs a request with its headers and body by the AWS signature version 4.
+func signAWSRequestV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonicalRequest := strings.Join([]string{req.Method, uri, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hexSHA256(body)}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(awsSigningKey(creds.SecretAccessKey, date, region, service), stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// awsSigningKey derives the signing key of the day, region and service from the secret access key.
+func awsSigningKey(secretAccessKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
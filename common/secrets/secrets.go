@@ -0,0 +1,171 @@
+// Package secrets resolves the secrets referenced by the config files of the services, so that DB DSNs, private keys
+// and JWT secrets are kept in a secrets manager instead of in plaintext. A string of a config file of the form
+// secret://<provider>/<path>[#<key>] is replaced by the secret at path of the provider before the config is parsed,
+// key selecting a field of a secret holding several ones.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme prefixes the secret references of the config files.
+const Scheme = "secret://"
+
+const resolveTimeout = 30 * time.Second
+
+// Provider resolves the secrets of a secrets manager.
+type Provider interface {
+	// Resolve returns the secret at path, or its field key if key is not empty.
+	Resolve(ctx context.Context, path, key string) (string, error)
+}
+
+// Resolver resolves secret references with the providers registered by name.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver without providers.
+func NewResolver() *Resolver {
+	return &Resolver{providers: make(map[string]Provider)}
+}
+
+// NewResolverFromEnv returns a resolver with the providers configured by the environment: vault with VAULT_ADDR and
+// VAULT_TOKEN, and aws with AWS_REGION (or AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func NewResolverFromEnv() *Resolver {
+	r := NewResolver()
+	if vault := NewVaultProviderFromEnv(); vault != nil {
+		r.Register("vault", vault)
+	}
+	if aws := NewAWSProviderFromEnv(); aws != nil {
+		r.Register("aws", aws)
+	}
+	return r
+}
+
+// Register registers the provider of the references secret://<name>/...
+func (r *Resolver) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+// Resolve returns the secret of a reference, values which are not references are returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, Scheme) {
+		return value, nil
+	}
+	name, path, key, err := parseReference(value)
+	if err != nil {
+		return "", err
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		return "", fmt.Errorf("secret provider %q of %s is not configured", name, value)
+	}
+	secret, err := provider.Resolve(ctx, path, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s, err: %w", value, err)
+	}
+	return secret, nil
+}
+
+// ResolveJSON replaces the secret references among the string values of a JSON document by their secrets. Documents
+// without references are returned unchanged.
+func (r *Resolver) ResolveJSON(ctx context.Context, buf []byte) ([]byte, error) {
+	if !bytes.Contains(buf, []byte(Scheme)) {
+		return buf, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	// keeps the numbers as written, e.g. uint64 values above 2^53.
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	resolved := make(map[string]string)
+	doc, err := r.resolveValue(ctx, doc, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func (r *Resolver) resolveValue(ctx context.Context, value interface{}, resolved map[string]string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if secret, ok := resolved[v]; ok {
+			return secret, nil
+		}
+		secret, err := r.Resolve(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		resolved[v] = secret
+		return secret, nil
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			resolvedValue, err := r.resolveValue(ctx, fieldValue, resolved)
+			if err != nil {
+				return nil, err
+			}
+			v[field] = resolvedValue
+		}
+	case []interface{}:
+		for i, element := range v {
+			resolvedValue, err := r.resolveValue(ctx, element, resolved)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolvedValue
+		}
+	}
+	return value, nil
+}
+
+// parseReference splits secret://<provider>/<path>[#<key>] into its parts.
+func parseReference(ref string) (string, string, string, error) {
+	rest := strings.TrimPrefix(ref, Scheme)
+	var key string
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		rest, key = rest[:i], rest[i+1:]
+	}
+	name, path, found := strings.Cut(rest, "/")
+	if !found || name == "" || path == "" {
+		return "", "", "", fmt.Errorf("invalid secret reference %s, expected %s<provider>/<path>[#<key>]", ref, Scheme)
+	}
+	return name, path, key, nil
+}
+
+var (
+	defaultResolver     *Resolver
+	defaultResolverOnce sync.Once
+)
+
+// ResolveJSON replaces the secret references of a config file by their secrets, with the providers configured by the
+// environment. It is called by the config loaders before parsing the config.
+func ResolveJSON(buf []byte) ([]byte, error) {
+	if !bytes.Contains(buf, []byte(Scheme)) {
+		return buf, nil
+	}
+	defaultResolverOnce.Do(func() {
+		defaultResolver = NewResolverFromEnv()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	return defaultResolver.ResolveJSON(ctx, buf)
+}
+
+// getenv returns the first of the environment variables which is set.
+func getenv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
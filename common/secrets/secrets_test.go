@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapProvider map[string]string
+
+func (m mapProvider) Resolve(_ context.Context, path, key string) (string, error) {
+	if key != "" {
+		path += "#" + key
+	}
+	secret, ok := m[path]
+	if !ok {
+		return "", io.EOF
+	}
+	return secret, nil
+}
+
+func TestResolveJSON(t *testing.T) {
+	r := NewResolver()
+	r.Register("test", mapProvider{"db#dsn": "postgres://user:pass@db/scroll", "keys/commit": "0x1212"})
+
+	buf, err := r.ResolveJSON(context.Background(), []byte(`{"db": {"dsn": "secret://test/db#dsn", "max_open_num": 200},
+		"keys": ["secret://test/keys/commit", "plain"], "chain_id": 18446744073709551615}`))
+	require.NoError(t, err)
+	var cfg struct {
+		DB struct {
+			DSN        string `json:"dsn"`
+			MaxOpenNum int    `json:"max_open_num"`
+		} `json:"db"`
+		Keys    []string `json:"keys"`
+		ChainID uint64   `json:"chain_id"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &cfg))
+	assert.Equal(t, "postgres://user:pass@db/scroll", cfg.DB.DSN)
+	assert.Equal(t, 200, cfg.DB.MaxOpenNum)
+	assert.Equal(t, []string{"0x1212", "plain"}, cfg.Keys)
+	assert.Equal(t, uint64(18446744073709551615), cfg.ChainID)
+
+	// documents without references are kept as they are.
+	plain := []byte(`{"dsn": "postgres://localhost"}`)
+	buf, err = r.ResolveJSON(context.Background(), plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, buf)
+
+	_, err = r.ResolveJSON(context.Background(), []byte(`{"dsn": "secret://vault/secret/db"}`))
+	assert.ErrorContains(t, err, `secret provider "vault"`)
+	_, err = r.ResolveJSON(context.Background(), []byte(`{"dsn": "secret://test/missing"}`))
+	assert.Error(t, err)
+	_, err = r.ResolveJSON(context.Background(), []byte(`{"dsn": "secret://test"}`))
+	assert.ErrorContains(t, err, "invalid secret reference")
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/coordinator/auth" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"value": "jwt secret", "port": 8390}, "metadata": {"version": 3}}}`))
+	}))
+	defer server.Close()
+
+	p := NewVaultProvider(server.URL, "token", "")
+	secret, err := p.Resolve(context.Background(), "secret/coordinator/auth", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt secret", secret)
+	secret, err = p.Resolve(context.Background(), "secret/coordinator/auth", "port")
+	assert.NoError(t, err)
+	assert.Equal(t, "8390", secret)
+
+	_, err = p.Resolve(context.Background(), "secret/coordinator/auth", "missing")
+	assert.Error(t, err)
+	_, err = p.Resolve(context.Background(), "secret/other", "")
+	assert.ErrorContains(t, err, "404")
+	_, err = NewVaultProvider(server.URL, "wrong token", "").Resolve(context.Background(), "secret/coordinator/auth", "")
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestAWSProvider(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		authorization := r.Header.Get("Authorization")
+		// the request is signed again with the received headers, the signature must match.
+		resigned, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.Path, nil)
+		for _, name := range []string{"Content-Type", "X-Amz-Target"} {
+			resigned.Header.Set(name, r.Header.Get(name))
+		}
+		signAWSRequestV4(resigned, body, creds, "us-east-1", awsSecretsManagerService, now)
+		if authorization != resigned.Header.Get("Authorization") || r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "InvalidSignatureException"}`))
+			return
+		}
+		if !strings.Contains(string(body), `"SecretId":"prod/rollup"`) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "ResourceNotFoundException"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Name": "prod/rollup", "SecretString": "{\"commit_key\": \"0x1212\"}"}`))
+	}))
+	defer server.Close()
+
+	p := NewAWSProvider("us-east-1", server.URL, creds)
+	p.now = func() time.Time { return now }
+	secret, err := p.Resolve(context.Background(), "prod/rollup", "commit_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1212", secret)
+	secret, err = p.Resolve(context.Background(), "prod/rollup", "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"commit_key": "0x1212"}`, secret)
+
+	_, err = p.Resolve(context.Background(), "prod/other", "")
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+	wrong := NewAWSProvider("us-east-1", server.URL, AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wrong"})
+	wrong.now = p.now
+	_, err = wrong.Resolve(context.Background(), "prod/rollup", "")
+	assert.ErrorContains(t, err, "InvalidSignatureException")
+}
+
+func TestAWSSigningKey(t *testing.T) {
+	// the example of the AWS signature version 4 documentation.
+	key := awsSigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
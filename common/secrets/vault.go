@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultSecretKey = "value"
+
+// VaultProvider reads the secrets of the KV version 2 secrets engines of a HashiCorp Vault. Paths are
+// <mount>/<secret path>, e.g. secret://vault/secret/rollup/db#dsn, the field defaults to "value".
+type VaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultProvider returns a provider reading the secrets of the vault at addr with the token.
+func NewVaultProvider(addr, token, namespace string) *VaultProvider {
+	return &VaultProvider{
+		addr:      strings.TrimSuffix(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewVaultProviderFromEnv returns the provider of the vault of VAULT_ADDR with VAULT_TOKEN, in VAULT_NAMESPACE if set,
+// nil if VAULT_ADDR is not set.
+func NewVaultProviderFromEnv() *VaultProvider {
+	addr := getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil
+	}
+	return NewVaultProvider(addr, getenv("VAULT_TOKEN"), getenv("VAULT_NAMESPACE"))
+}
+
+// Resolve reads the field key of the latest version of the secret at path.
+func (p *VaultProvider) Resolve(ctx context.Context, path, key string) (string, error) {
+	mount, secretPath, found := strings.Cut(path, "/")
+	if !found || secretPath == "" {
+		return "", fmt.Errorf("vault path %s must be <mount>/<secret path>", path)
+	}
+	if key == "" {
+		key = defaultSecretKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+mount+"/data/"+secretPath, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to decode the vault response, err: %w", err)
+	}
+	value, ok := secret.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no field %q", key)
+	}
+	return stringValue(value)
+}
+
+// stringValue returns a field of a secret as a string, other JSON values as written.
+func stringValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,123 @@
+// Package crossdomain computes the hashes identifying the cross domain messages of the Scroll messengers.
+package crossdomain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// Encoding is the encoding of a cross domain message whose keccak256 is the message hash.
+type Encoding int
+
+const (
+	// EncodingUndefined is an unknown message encoding.
+	EncodingUndefined Encoding = iota
+	// EncodingRelayMessage is the calldata of relayMessage(address,address,uint256,uint256,bytes), the encoding of
+	// L1ScrollMessenger and L2ScrollMessenger since the mainnet launch.
+	EncodingRelayMessage
+)
+
+// LatestEncoding is the encoding of the messages sent by the current messengers. An upgrade of the messengers
+// changing the encoding adds a new Encoding and moves LatestEncoding, so older messages still hash with theirs.
+const LatestEncoding = EncodingRelayMessage
+
+// Message is a cross domain message sent through the messengers.
+type Message struct {
+	Sender       common.Address
+	Target       common.Address
+	Value        *big.Int
+	MessageNonce *big.Int
+	Message      []byte
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRelayMessage:
+		return "relayMessage"
+	default:
+		return fmt.Sprintf("illegal message encoding (%d)", int(e))
+	}
+}
+
+var (
+	relayMessageSelector  = crypto.Keccak256([]byte("relayMessage(address,address,uint256,uint256,bytes)"))[:4]
+	relayMessageArguments = mustNewArguments("address", "address", "uint256", "uint256", "bytes")
+
+	// ErrUnknownEncoding is returned for message encodings the library does not know.
+	ErrUnknownEncoding = errors.New("unknown message encoding")
+	// ErrNotAMessage is returned when decoding data which is not an encoded message.
+	ErrNotAMessage = errors.New("data is not an encoded message")
+)
+
+// ComputeMessageHash computes the hash of a message sent by the current messengers.
+func ComputeMessageHash(sender, target common.Address, value, messageNonce *big.Int, message []byte) common.Hash {
+	// the latest encoding is always known, the error can be ignored.
+	hash, _ := ComputeMessageHashWithEncoding(LatestEncoding, sender, target, value, messageNonce, message)
+	return hash
+}
+
+// ComputeMessageHashWithEncoding computes the hash of a message with the given encoding.
+func ComputeMessageHashWithEncoding(encoding Encoding, sender, target common.Address, value, messageNonce *big.Int, message []byte) (common.Hash, error) {
+	data, err := EncodeMessage(encoding, sender, target, value, messageNonce, message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// EncodeMessage returns the encoding of a message, whose keccak256 is the message hash.
+func EncodeMessage(encoding Encoding, sender, target common.Address, value, messageNonce *big.Int, message []byte) ([]byte, error) {
+	switch encoding {
+	case EncodingRelayMessage:
+		args, err := relayMessageArguments.Pack(sender, target, value, messageNonce, message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack relayMessage: %w", err)
+		}
+		return append(common.CopyBytes(relayMessageSelector), args...), nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownEncoding, encoding)
+	}
+}
+
+// HashEncodedMessage returns the message hash of an encoded message, e.g. the data of a QueueTransaction event of a
+// message sent through L1ScrollMessenger.
+func HashEncodedMessage(data []byte) common.Hash {
+	return crypto.Keccak256Hash(data)
+}
+
+// DecodeMessage decodes an encoded message and returns it with its encoding.
+func DecodeMessage(data []byte) (*Message, Encoding, error) {
+	if len(data) < 4 || !bytes.Equal(data[:4], relayMessageSelector) {
+		return nil, EncodingUndefined, ErrNotAMessage
+	}
+	values, err := relayMessageArguments.Unpack(data[4:])
+	if err != nil {
+		return nil, EncodingUndefined, fmt.Errorf("%w: failed to unpack relayMessage: %v", ErrNotAMessage, err)
+	}
+	msg := &Message{
+		Sender:       values[0].(common.Address),
+		Target:       values[1].(common.Address),
+		Value:        values[2].(*big.Int),
+		MessageNonce: values[3].(*big.Int),
+		Message:      values[4].([]byte),
+	}
+	return msg, EncodingRelayMessage, nil
+}
+
+func mustNewArguments(types ...string) abi.Arguments {
+	args := make(abi.Arguments, 0, len(types))
+	for _, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}
@@ -0,0 +1,117 @@
+package crossdomain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/math"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// vectors are messages with their hashes, the first one is the vector of the former rollup utils test.
+var vectors = []struct {
+	sender       string
+	target       string
+	value        *big.Int
+	messageNonce *big.Int
+	message      []byte
+	hash         string
+}{
+	{
+		sender:       "0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63",
+		target:       "0x4592D8f8D7B001e72Cb26A73e4Fa1806a51aC79d",
+		value:        big.NewInt(0),
+		messageNonce: big.NewInt(1),
+		message:      []byte("testbridgecontract"),
+		hash:         "0xda253c04595a49017bb54b1b46088c69752b5ad2f0c47971ac76b8b25abec202",
+	},
+	{
+		sender:       "0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63",
+		target:       "0x4592D8f8D7B001e72Cb26A73e4Fa1806a51aC79d",
+		value:        big.NewInt(1e18),
+		messageNonce: big.NewInt(123456),
+		message:      []byte{},
+		hash:         "0x1a6033eb9399376e25ba94523fef1db62d6e0f53901a59c16284ac4044700937",
+	},
+	{
+		sender:       "0x0000000000000000000000000000000000000000",
+		target:       "0xffffffffffffffffffffffffffffffffffffffff",
+		value:        math.MaxBig256,
+		messageNonce: new(big.Int).Lsh(big.NewInt(1), 64),
+		message:      common.FromHex("0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"),
+		hash:         "0x701e898907043c003fc0482284f9b5bd6f4388b2257f4e1ad6173b0e0300929a",
+	},
+}
+
+func TestComputeMessageHash(t *testing.T) {
+	for _, v := range vectors {
+		hash := ComputeMessageHash(common.HexToAddress(v.sender), common.HexToAddress(v.target), v.value, v.messageNonce, v.message)
+		assert.Equal(t, v.hash, hash.String())
+	}
+}
+
+func TestComputeMessageHashWithEncoding(t *testing.T) {
+	v := vectors[0]
+	hash, err := ComputeMessageHashWithEncoding(EncodingRelayMessage, common.HexToAddress(v.sender), common.HexToAddress(v.target), v.value, v.messageNonce, v.message)
+	assert.NoError(t, err)
+	assert.Equal(t, v.hash, hash.String())
+
+	_, err = ComputeMessageHashWithEncoding(EncodingUndefined, common.HexToAddress(v.sender), common.HexToAddress(v.target), v.value, v.messageNonce, v.message)
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+}
+
+func TestEncodingString(t *testing.T) {
+	assert.Equal(t, "relayMessage", EncodingRelayMessage.String())
+	assert.Equal(t, "illegal message encoding (0)", EncodingUndefined.String())
+}
+
+func TestDecodeMessage(t *testing.T) {
+	for _, v := range vectors {
+		data, err := EncodeMessage(LatestEncoding, common.HexToAddress(v.sender), common.HexToAddress(v.target), v.value, v.messageNonce, v.message)
+		assert.NoError(t, err)
+		assert.Equal(t, v.hash, HashEncodedMessage(data).String())
+
+		msg, encoding, err := DecodeMessage(data)
+		assert.NoError(t, err)
+		assert.Equal(t, LatestEncoding, encoding)
+		assert.Equal(t, common.HexToAddress(v.sender), msg.Sender)
+		assert.Equal(t, common.HexToAddress(v.target), msg.Target)
+		assert.Equal(t, 0, v.value.Cmp(msg.Value))
+		assert.Equal(t, 0, v.messageNonce.Cmp(msg.MessageNonce))
+		assert.Equal(t, v.message, msg.Message)
+	}
+
+	_, _, err := DecodeMessage([]byte{0x01, 0x02})
+	assert.ErrorIs(t, err, ErrNotAMessage)
+	_, _, err = DecodeMessage(append(common.CopyBytes(relayMessageSelector), 0x01))
+	assert.ErrorIs(t, err, ErrNotAMessage)
+}
+
+// FuzzComputeMessageHash checks the abi encoding of the library against a hand written encoding of relayMessage.
+func FuzzComputeMessageHash(f *testing.F) {
+	for _, v := range vectors {
+		f.Add(common.HexToAddress(v.sender).Bytes(), common.HexToAddress(v.target).Bytes(), v.value.Bytes(), v.messageNonce.Bytes(), v.message)
+	}
+	f.Fuzz(func(t *testing.T, sender, target, value, messageNonce, message []byte) {
+		if len(value) > 32 || len(messageNonce) > 32 {
+			return
+		}
+		senderAddr, targetAddr := common.BytesToAddress(sender), common.BytesToAddress(target)
+		valueInt, messageNonceInt := new(big.Int).SetBytes(value), new(big.Int).SetBytes(messageNonce)
+
+		var data []byte
+		data = append(data, crypto.Keccak256([]byte("relayMessage(address,address,uint256,uint256,bytes)"))[:4]...)
+		data = append(data, common.LeftPadBytes(senderAddr.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(targetAddr.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(valueInt.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(messageNonceInt.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(big.NewInt(5*32).Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(big.NewInt(int64(len(message))).Bytes(), 32)...)
+		data = append(data, common.RightPadBytes(message, (len(message)+31)/32*32)...)
+
+		hash := ComputeMessageHash(senderAddr, targetAddr, valueInt, messageNonceInt, message)
+		assert.Equal(t, crypto.Keccak256Hash(data), hash)
+	})
+}
@@ -66,8 +66,44 @@ const (
 
 	// MsgRelayFailed represents the from_layer message status is relay failed
 	MsgRelayFailed
+
+	// MsgSkipped represents the from_layer message status is skipped by the sequencer
+	MsgSkipped
+
+	// MsgReplayed represents the from_layer message status is replayed with a new gas limit
+	MsgReplayed
 )
 
+// MsgSkipReason represents the reason a layer1 message was skipped by the sequencer
+type MsgSkipReason int
+
+const (
+	// MsgSkipReasonUndefined : undefined skip reason, the message is not skipped or not classified yet
+	MsgSkipReasonUndefined MsgSkipReason = iota
+
+	// MsgSkipReasonGasLimitExceeded represents the gas limit of the message exceeds the gas cap of L2 blocks
+	MsgSkipReasonGasLimitExceeded
+
+	// MsgSkipReasonCircuitCapacity represents the message fits the gas cap but exceeds the circuit capacity
+	MsgSkipReasonCircuitCapacity
+
+	// MsgSkipReasonNotReplayable represents the message is not sent by the messenger, e.g. an enforced tx, and can not be replayed
+	MsgSkipReasonNotReplayable
+)
+
+func (r MsgSkipReason) String() string {
+	switch r {
+	case MsgSkipReasonGasLimitExceeded:
+		return "MsgSkipReasonGasLimitExceeded"
+	case MsgSkipReasonCircuitCapacity:
+		return "MsgSkipReasonCircuitCapacity"
+	case MsgSkipReasonNotReplayable:
+		return "MsgSkipReasonNotReplayable"
+	default:
+		return fmt.Sprintf("Undefined MsgSkipReason (%d)", int32(r))
+	}
+}
+
 // ProverProveStatus is the prover prove status of a block batch (session)
 type ProverProveStatus int32
 
@@ -80,6 +116,9 @@ const (
 	ProverProofValid
 	// ProverProofInvalid indicates prover has submitted invalid proof
 	ProverProofInvalid
+	// ProverReserved indicates the task is reserved for a prover prefetching its next task, its deadline is the end
+	// of the reservation
+	ProverReserved
 )
 
 func (s ProverProveStatus) String() string {
@@ -90,6 +129,8 @@ func (s ProverProveStatus) String() string {
 		return "ProverProofValid"
 	case ProverProofInvalid:
 		return "ProverProofInvalid"
+	case ProverReserved:
+		return "ProverReserved"
 	default:
 		return fmt.Sprintf("Bad Value: %d", int32(s))
 	}
@@ -109,6 +150,10 @@ const (
 	ProverTaskFailureTypeVerifiedFailed
 	// ProverTaskFailureTypeServerError collect occur error
 	ProverTaskFailureTypeServerError
+	// ProverTaskFailureTypeSessionExpired prover task expired by the session cleanup, its deadline passed long ago
+	ProverTaskFailureTypeSessionExpired
+	// ProverTaskFailureTypeReservationExpired prover task reserved by a prefetch was not taken before the reservation ended
+	ProverTaskFailureTypeReservationExpired
 )
 
 func (r ProverTaskFailureType) String() string {
@@ -123,11 +168,44 @@ func (r ProverTaskFailureType) String() string {
 		return "prover task failure verified failed"
 	case ProverTaskFailureTypeServerError:
 		return "prover task failure server exception"
+	case ProverTaskFailureTypeSessionExpired:
+		return "prover task failure session expired"
+	case ProverTaskFailureTypeReservationExpired:
+		return "prover task failure reservation expired"
 	default:
 		return fmt.Sprintf("illegal prover task failure type (%d)", int32(r))
 	}
 }
 
+// ProofFailureClass is the probable cause of a failed proof, used to triage circuit bugs from prover environment issues
+type ProofFailureClass int
+
+const (
+	// ProofFailureClassUnknown indicates a failure of unknown cause
+	ProofFailureClassUnknown ProofFailureClass = iota
+	// ProofFailureClassOOM indicates the prover ran out of memory
+	ProofFailureClassOOM
+	// ProofFailureClassWitnessMismatch indicates the witness did not satisfy the circuit constraints, or a proof was invalid
+	ProofFailureClassWitnessMismatch
+	// ProofFailureClassVersionMismatch indicates the prover or proof does not match the circuit version of the task
+	ProofFailureClassVersionMismatch
+)
+
+func (c ProofFailureClass) String() string {
+	switch c {
+	case ProofFailureClassUnknown:
+		return "unknown"
+	case ProofFailureClassOOM:
+		return "oom"
+	case ProofFailureClassWitnessMismatch:
+		return "witness_mismatch"
+	case ProofFailureClassVersionMismatch:
+		return "version_mismatch"
+	default:
+		return fmt.Sprintf("illegal proof failure class (%d)", int32(c))
+	}
+}
+
 // ProvingStatus block_batch proving_status (unassigned, assigned, proved, verified, submitted)
 type ProvingStatus int
 
@@ -245,6 +323,8 @@ const (
 	SenderTypeL1GasOracle
 	// SenderTypeL2GasOracle indicates a sender from L1 responsible for updating L2 gas prices.
 	SenderTypeL2GasOracle
+	// SenderTypeReplayMessage indicates the sender is responsible for replaying skipped L1 messages.
+	SenderTypeReplayMessage
 )
 
 // String returns a string representation of the SenderType.
@@ -258,6 +338,8 @@ func (t SenderType) String() string {
 		return "SenderTypeL1GasOracle"
 	case SenderTypeL2GasOracle:
 		return "SenderTypeL2GasOracle"
+	case SenderTypeReplayMessage:
+		return "SenderTypeReplayMessage"
 	default:
 		return fmt.Sprintf("Unknown SenderType (%d)", int32(t))
 	}
@@ -27,6 +27,11 @@ func TestProverProveStatus(t *testing.T) {
 			ProverProofInvalid,
 			"ProverProofInvalid",
 		},
+		{
+			"ProverReserved",
+			ProverReserved,
+			"ProverReserved",
+		},
 		{
 			"Bad Value",
 			ProverProveStatus(999), // Invalid value.
@@ -177,6 +182,11 @@ func TestSenderType(t *testing.T) {
 			SenderTypeL2GasOracle,
 			"SenderTypeL2GasOracle",
 		},
+		{
+			"SenderTypeReplayMessage",
+			SenderTypeReplayMessage,
+			"SenderTypeReplayMessage",
+		},
 		{
 			"Invalid Value",
 			SenderType(999),
@@ -312,6 +322,16 @@ func TestProverTaskFailureType(t *testing.T) {
 			ProverTaskFailureTypeServerError,
 			"prover task failure server exception",
 		},
+		{
+			"ProverTaskFailureTypeSessionExpired",
+			ProverTaskFailureTypeSessionExpired,
+			"prover task failure session expired",
+		},
+		{
+			"ProverTaskFailureTypeReservationExpired",
+			ProverTaskFailureTypeReservationExpired,
+			"prover task failure reservation expired",
+		},
 		{
 			"Invalid Value",
 			ProverTaskFailureType(999),
@@ -325,3 +345,43 @@ func TestProverTaskFailureType(t *testing.T) {
 		})
 	}
 }
+
+func TestProofFailureClass(t *testing.T) {
+	tests := []struct {
+		name string
+		c    ProofFailureClass
+		want string
+	}{
+		{
+			"ProofFailureClassUnknown",
+			ProofFailureClassUnknown,
+			"unknown",
+		},
+		{
+			"ProofFailureClassOOM",
+			ProofFailureClassOOM,
+			"oom",
+		},
+		{
+			"ProofFailureClassWitnessMismatch",
+			ProofFailureClassWitnessMismatch,
+			"witness_mismatch",
+		},
+		{
+			"ProofFailureClassVersionMismatch",
+			ProofFailureClassVersionMismatch,
+			"version_mismatch",
+		},
+		{
+			"Invalid Value",
+			ProofFailureClass(999),
+			"illegal proof failure class (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.c.String())
+		})
+	}
+}
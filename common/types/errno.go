@@ -8,7 +8,7 @@ const (
 
 	// ErrJWTCommonErr jwt common error
 	ErrJWTCommonErr = 50000
-	// ErrJWTTokenExpired jwt token expired
+	// ErrJWTTokenExpired jwt token expired, the prover should login again
 	ErrJWTTokenExpired = 50001
 
 	// ErrProverStatsAPIParameterInvalidNo is invalid params
@@ -24,6 +24,44 @@ const (
 	ErrCoordinatorGetTaskFailure = 20002
 	// ErrCoordinatorHandleZkProofFailure is handle submit proof error
 	ErrCoordinatorHandleZkProofFailure = 20003
-	// ErrCoordinatorEmptyProofData get empty proof data
-	ErrCoordinatorEmptyProofData = 20004
+	// ErrCoordinatorNoTaskAvailable no task is available for the prover now, the prover should ask again later
+	ErrCoordinatorNoTaskAvailable = 20004
+	// ErrCoordinatorTaskExpired the proof is submitted after the task timed out, passed its deadline or was proven
+	// by another prover, the prover should drop the task
+	ErrCoordinatorTaskExpired = 20005
+	// ErrCoordinatorAdminUnauthorized the admin api request has no valid admin token
+	ErrCoordinatorAdminUnauthorized = 20006
+	// ErrCoordinatorGetProofFailuresFailure is getting proof failures error
+	ErrCoordinatorGetProofFailuresFailure = 20007
+	// ErrCoordinatorProofMismatch a different proof was submitted and verified for the task already
+	ErrCoordinatorProofMismatch = 20008
+	// ErrCoordinatorReloadVKsFailure is reloading the verifying key registry error
+	ErrCoordinatorReloadVKsFailure = 20009
+	// ErrCoordinatorUnknownTenant is the tenant of the login token is not served error
+	ErrCoordinatorUnknownTenant = 20010
+	// ErrCoordinatorProverVersionTooLow the prover version is below the minimum version, the prover should upgrade
+	ErrCoordinatorProverVersionTooLow = 20011
+	// ErrCoordinatorProofInvalid the proof failed the verification or was generated with a different verifying key,
+	// the prover should drop the task
+	ErrCoordinatorProofInvalid = 20012
+	// ErrCoordinatorRateLimited the prover asks for tasks too often or before finishing its assigned task, the prover
+	// should back off
+	ErrCoordinatorRateLimited = 20013
+	// ErrCoordinatorGetSnapshotFailure is taking the scheduler snapshot error
+	ErrCoordinatorGetSnapshotFailure = 20014
+	// ErrCoordinatorTaskPayloadNotFound the task data of the task is not stored for the prover, e.g. it was pruned or the
+	// task is assigned to another prover
+	ErrCoordinatorTaskPayloadNotFound = 20015
+	// ErrCoordinatorProofNotAccepted the proof was not handled as another proof of its all-or-nothing submission failed,
+	// the prover should submit it again
+	ErrCoordinatorProofNotAccepted = 20016
+	// ErrCoordinatorGetBatchDependenciesFailure is getting the dependencies of the batch tasks on chunk proofs error
+	ErrCoordinatorGetBatchDependenciesFailure = 20017
+
+	// ErrRollupAdminUnauthorized the admin api request has no valid admin token
+	ErrRollupAdminUnauthorized = 30001
+	// ErrRollupPipelineUnknown the pipeline of the request is not a relayer pipeline
+	ErrRollupPipelineUnknown = 30002
+	// ErrRollupPipelineControlFailure is pausing or resuming a relayer pipeline error
+	ErrRollupPipelineControlFailure = 30003
 )
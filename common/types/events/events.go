@@ -0,0 +1,45 @@
+// Package events holds the typed events of the bridge and rollup contracts, generated from the event abis in ./abi:
+// for each event a struct with one field per argument, in the order of the arguments, its topic and a function
+// unpacking its logs, e.g. ScrollChainCommitBatchEvent, ScrollChainCommitBatchEventSig and
+// UnpackScrollChainCommitBatchEvent. The fields are named after the arguments, as the abi unpacking maps them, so a
+// struct cannot get out of sync with its event. Run go generate after editing an abi.
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+//go:generate go run ./gen --abi abi --out events_gen.go
+
+// mustParseABI parses the embedded abi of a contract.
+func mustParseABI(abiJSON string) *abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse the embedded abi: %v", err))
+	}
+	return &parsed
+}
+
+// unpackLog unpacks a log of an event of a contract into out, the non indexed arguments from the data and the
+// indexed ones from the topics.
+func unpackLog(contractABI *abi.ABI, out interface{}, event string, log types.Log) error {
+	if len(log.Topics) == 0 || log.Topics[0] != contractABI.Events[event].ID {
+		return fmt.Errorf("event signature mismatch, event: %s", event)
+	}
+	if len(log.Data) > 0 {
+		if err := contractABI.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range contractABI.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, log.Topics[1:])
+}
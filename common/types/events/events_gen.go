@@ -0,0 +1,1069 @@
+// Code generated by ./gen from the abis in ./abi. DO NOT EDIT.
+
+package events
+
+import (
+	_ "embed"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+var (
+	//go:embed abi/L1ERC1155Gateway.json
+	l1ERC1155GatewayABIJSON string
+	//go:embed abi/L1ERC20Gateway.json
+	l1ERC20GatewayABIJSON string
+	//go:embed abi/L1ERC721Gateway.json
+	l1ERC721GatewayABIJSON string
+	//go:embed abi/L1ETHGateway.json
+	l1ETHGatewayABIJSON string
+	//go:embed abi/L1MessageQueue.json
+	l1MessageQueueABIJSON string
+	//go:embed abi/L1ScrollMessenger.json
+	l1ScrollMessengerABIJSON string
+	//go:embed abi/L2ERC1155Gateway.json
+	l2ERC1155GatewayABIJSON string
+	//go:embed abi/L2ERC20Gateway.json
+	l2ERC20GatewayABIJSON string
+	//go:embed abi/L2ERC721Gateway.json
+	l2ERC721GatewayABIJSON string
+	//go:embed abi/L2ETHGateway.json
+	l2ETHGatewayABIJSON string
+	//go:embed abi/L2MessageQueue.json
+	l2MessageQueueABIJSON string
+	//go:embed abi/L2ScrollMessenger.json
+	l2ScrollMessengerABIJSON string
+	//go:embed abi/L2TxFeeVault.json
+	l2TxFeeVaultABIJSON string
+	//go:embed abi/ScrollChain.json
+	scrollChainABIJSON string
+)
+
+var (
+	l1ERC1155GatewayABI  = mustParseABI(l1ERC1155GatewayABIJSON)
+	l1ERC20GatewayABI    = mustParseABI(l1ERC20GatewayABIJSON)
+	l1ERC721GatewayABI   = mustParseABI(l1ERC721GatewayABIJSON)
+	l1ETHGatewayABI      = mustParseABI(l1ETHGatewayABIJSON)
+	l1MessageQueueABI    = mustParseABI(l1MessageQueueABIJSON)
+	l1ScrollMessengerABI = mustParseABI(l1ScrollMessengerABIJSON)
+	l2ERC1155GatewayABI  = mustParseABI(l2ERC1155GatewayABIJSON)
+	l2ERC20GatewayABI    = mustParseABI(l2ERC20GatewayABIJSON)
+	l2ERC721GatewayABI   = mustParseABI(l2ERC721GatewayABIJSON)
+	l2ETHGatewayABI      = mustParseABI(l2ETHGatewayABIJSON)
+	l2MessageQueueABI    = mustParseABI(l2MessageQueueABIJSON)
+	l2ScrollMessengerABI = mustParseABI(l2ScrollMessengerABIJSON)
+	l2TxFeeVaultABI      = mustParseABI(l2TxFeeVaultABIJSON)
+	scrollChainABI       = mustParseABI(scrollChainABIJSON)
+)
+
+// L1ERC1155GatewayBatchDepositERC1155EventSig is the topic of the BatchDepositERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("BatchDepositERC1155(address,address,address,address,uint256[],uint256[])").
+var L1ERC1155GatewayBatchDepositERC1155EventSig = common.HexToHash("0x743f65db61a23bc629915d35e22af5cf13478a8b3dbd154d3e5db0149509756d")
+
+// L1ERC1155GatewayBatchDepositERC1155Event is the BatchDepositERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayBatchDepositERC1155Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+	Amounts  []*big.Int
+}
+
+// UnpackL1ERC1155GatewayBatchDepositERC1155Event unpacks a log of the BatchDepositERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayBatchDepositERC1155Event(log types.Log) (*L1ERC1155GatewayBatchDepositERC1155Event, error) {
+	event := &L1ERC1155GatewayBatchDepositERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "BatchDepositERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC1155GatewayBatchRefundERC1155EventSig is the topic of the BatchRefundERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("BatchRefundERC1155(address,address,uint256[],uint256[])").
+var L1ERC1155GatewayBatchRefundERC1155EventSig = common.HexToHash("0xe198c04cbd4522ed7825c7e6ab1ae33fdaf6ab3565c4a3fb4c0cf24338f306e6")
+
+// L1ERC1155GatewayBatchRefundERC1155Event is the BatchRefundERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayBatchRefundERC1155Event struct {
+	Token     common.Address // indexed
+	Recipient common.Address // indexed
+	TokenIds  []*big.Int
+	Amounts   []*big.Int
+}
+
+// UnpackL1ERC1155GatewayBatchRefundERC1155Event unpacks a log of the BatchRefundERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayBatchRefundERC1155Event(log types.Log) (*L1ERC1155GatewayBatchRefundERC1155Event, error) {
+	event := &L1ERC1155GatewayBatchRefundERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "BatchRefundERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC1155GatewayDepositERC1155EventSig is the topic of the DepositERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("DepositERC1155(address,address,address,address,uint256,uint256)").
+var L1ERC1155GatewayDepositERC1155EventSig = common.HexToHash("0x7f6552b688fa94306ca59e44dd4454ff550542445a3f1cb39b8c768be6f5c08a")
+
+// L1ERC1155GatewayDepositERC1155Event is the DepositERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayDepositERC1155Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// UnpackL1ERC1155GatewayDepositERC1155Event unpacks a log of the DepositERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayDepositERC1155Event(log types.Log) (*L1ERC1155GatewayDepositERC1155Event, error) {
+	event := &L1ERC1155GatewayDepositERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "DepositERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC1155GatewayFinalizeBatchWithdrawERC1155EventSig is the topic of the FinalizeBatchWithdrawERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("FinalizeBatchWithdrawERC1155(address,address,address,address,uint256[],uint256[])").
+var L1ERC1155GatewayFinalizeBatchWithdrawERC1155EventSig = common.HexToHash("0x45294b6ad6ad2408cc3ee9a37203aa1b0480616667a97b157c52ac9294cbc258")
+
+// L1ERC1155GatewayFinalizeBatchWithdrawERC1155Event is the FinalizeBatchWithdrawERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayFinalizeBatchWithdrawERC1155Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+	Amounts  []*big.Int
+}
+
+// UnpackL1ERC1155GatewayFinalizeBatchWithdrawERC1155Event unpacks a log of the FinalizeBatchWithdrawERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayFinalizeBatchWithdrawERC1155Event(log types.Log) (*L1ERC1155GatewayFinalizeBatchWithdrawERC1155Event, error) {
+	event := &L1ERC1155GatewayFinalizeBatchWithdrawERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "FinalizeBatchWithdrawERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC1155GatewayFinalizeWithdrawERC1155EventSig is the topic of the FinalizeWithdrawERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("FinalizeWithdrawERC1155(address,address,address,address,uint256,uint256)").
+var L1ERC1155GatewayFinalizeWithdrawERC1155EventSig = common.HexToHash("0xfcc2841e9e72e6d610944e1b668912e92d5df94003055dbe06d615ba8d9efad4")
+
+// L1ERC1155GatewayFinalizeWithdrawERC1155Event is the FinalizeWithdrawERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayFinalizeWithdrawERC1155Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// UnpackL1ERC1155GatewayFinalizeWithdrawERC1155Event unpacks a log of the FinalizeWithdrawERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayFinalizeWithdrawERC1155Event(log types.Log) (*L1ERC1155GatewayFinalizeWithdrawERC1155Event, error) {
+	event := &L1ERC1155GatewayFinalizeWithdrawERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "FinalizeWithdrawERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC1155GatewayRefundERC1155EventSig is the topic of the RefundERC1155 event of the L1ERC1155Gateway contract,
+// keccak256("RefundERC1155(address,address,uint256,uint256)").
+var L1ERC1155GatewayRefundERC1155EventSig = common.HexToHash("0xee285671d9ac3b0e0ed40037cb6db081095aa6cd68363f3e56989dde39e0df09")
+
+// L1ERC1155GatewayRefundERC1155Event is the RefundERC1155 event of the L1ERC1155Gateway contract.
+type L1ERC1155GatewayRefundERC1155Event struct {
+	Token     common.Address // indexed
+	Recipient common.Address // indexed
+	TokenId   *big.Int
+	Amount    *big.Int
+}
+
+// UnpackL1ERC1155GatewayRefundERC1155Event unpacks a log of the RefundERC1155 event of the L1ERC1155Gateway contract.
+func UnpackL1ERC1155GatewayRefundERC1155Event(log types.Log) (*L1ERC1155GatewayRefundERC1155Event, error) {
+	event := &L1ERC1155GatewayRefundERC1155Event{}
+	if err := unpackLog(l1ERC1155GatewayABI, event, "RefundERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC20GatewayDepositERC20EventSig is the topic of the DepositERC20 event of the L1ERC20Gateway contract,
+// keccak256("DepositERC20(address,address,address,address,uint256,bytes)").
+var L1ERC20GatewayDepositERC20EventSig = common.HexToHash("0x31cd3b976e4d654022bf95c68a2ce53f1d5d94afabe0454d2832208eeb40af25")
+
+// L1ERC20GatewayDepositERC20Event is the DepositERC20 event of the L1ERC20Gateway contract.
+type L1ERC20GatewayDepositERC20Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	Amount  *big.Int
+	Data    []byte
+}
+
+// UnpackL1ERC20GatewayDepositERC20Event unpacks a log of the DepositERC20 event of the L1ERC20Gateway contract.
+func UnpackL1ERC20GatewayDepositERC20Event(log types.Log) (*L1ERC20GatewayDepositERC20Event, error) {
+	event := &L1ERC20GatewayDepositERC20Event{}
+	if err := unpackLog(l1ERC20GatewayABI, event, "DepositERC20", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC20GatewayFinalizeWithdrawERC20EventSig is the topic of the FinalizeWithdrawERC20 event of the L1ERC20Gateway contract,
+// keccak256("FinalizeWithdrawERC20(address,address,address,address,uint256,bytes)").
+var L1ERC20GatewayFinalizeWithdrawERC20EventSig = common.HexToHash("0xc6f985873b37805705f6bce756dce3d1ff4b603e298d506288cce499926846a7")
+
+// L1ERC20GatewayFinalizeWithdrawERC20Event is the FinalizeWithdrawERC20 event of the L1ERC20Gateway contract.
+type L1ERC20GatewayFinalizeWithdrawERC20Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	Amount  *big.Int
+	Data    []byte
+}
+
+// UnpackL1ERC20GatewayFinalizeWithdrawERC20Event unpacks a log of the FinalizeWithdrawERC20 event of the L1ERC20Gateway contract.
+func UnpackL1ERC20GatewayFinalizeWithdrawERC20Event(log types.Log) (*L1ERC20GatewayFinalizeWithdrawERC20Event, error) {
+	event := &L1ERC20GatewayFinalizeWithdrawERC20Event{}
+	if err := unpackLog(l1ERC20GatewayABI, event, "FinalizeWithdrawERC20", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC20GatewayRefundERC20EventSig is the topic of the RefundERC20 event of the L1ERC20Gateway contract,
+// keccak256("RefundERC20(address,address,uint256)").
+var L1ERC20GatewayRefundERC20EventSig = common.HexToHash("0xdbdf8eb487847e4c0f22847f5dac07f2d3690f96f581a6ae4b102769917645a8")
+
+// L1ERC20GatewayRefundERC20Event is the RefundERC20 event of the L1ERC20Gateway contract.
+type L1ERC20GatewayRefundERC20Event struct {
+	Token     common.Address // indexed
+	Recipient common.Address // indexed
+	Amount    *big.Int
+}
+
+// UnpackL1ERC20GatewayRefundERC20Event unpacks a log of the RefundERC20 event of the L1ERC20Gateway contract.
+func UnpackL1ERC20GatewayRefundERC20Event(log types.Log) (*L1ERC20GatewayRefundERC20Event, error) {
+	event := &L1ERC20GatewayRefundERC20Event{}
+	if err := unpackLog(l1ERC20GatewayABI, event, "RefundERC20", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayBatchDepositERC721EventSig is the topic of the BatchDepositERC721 event of the L1ERC721Gateway contract,
+// keccak256("BatchDepositERC721(address,address,address,address,uint256[])").
+var L1ERC721GatewayBatchDepositERC721EventSig = common.HexToHash("0xf05915e3b4fbd6f61b8b6f80b07f10e1cad039ccc7abe7c7fec115d038fe3dd6")
+
+// L1ERC721GatewayBatchDepositERC721Event is the BatchDepositERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayBatchDepositERC721Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+}
+
+// UnpackL1ERC721GatewayBatchDepositERC721Event unpacks a log of the BatchDepositERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayBatchDepositERC721Event(log types.Log) (*L1ERC721GatewayBatchDepositERC721Event, error) {
+	event := &L1ERC721GatewayBatchDepositERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "BatchDepositERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayBatchRefundERC721EventSig is the topic of the BatchRefundERC721 event of the L1ERC721Gateway contract,
+// keccak256("BatchRefundERC721(address,address,uint256[])").
+var L1ERC721GatewayBatchRefundERC721EventSig = common.HexToHash("0x998a3ef0a23771412ff48d871a2288502a89da39c5db04a2a66e5eb85586cc22")
+
+// L1ERC721GatewayBatchRefundERC721Event is the BatchRefundERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayBatchRefundERC721Event struct {
+	Token     common.Address // indexed
+	Recipient common.Address // indexed
+	TokenIds  []*big.Int
+}
+
+// UnpackL1ERC721GatewayBatchRefundERC721Event unpacks a log of the BatchRefundERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayBatchRefundERC721Event(log types.Log) (*L1ERC721GatewayBatchRefundERC721Event, error) {
+	event := &L1ERC721GatewayBatchRefundERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "BatchRefundERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayDepositERC721EventSig is the topic of the DepositERC721 event of the L1ERC721Gateway contract,
+// keccak256("DepositERC721(address,address,address,address,uint256)").
+var L1ERC721GatewayDepositERC721EventSig = common.HexToHash("0xfc1d17c06ff1e4678321cc30660a73f3f1436df8195108a288d3159a961febec")
+
+// L1ERC721GatewayDepositERC721Event is the DepositERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayDepositERC721Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+}
+
+// UnpackL1ERC721GatewayDepositERC721Event unpacks a log of the DepositERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayDepositERC721Event(log types.Log) (*L1ERC721GatewayDepositERC721Event, error) {
+	event := &L1ERC721GatewayDepositERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "DepositERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayFinalizeBatchWithdrawERC721EventSig is the topic of the FinalizeBatchWithdrawERC721 event of the L1ERC721Gateway contract,
+// keccak256("FinalizeBatchWithdrawERC721(address,address,address,address,uint256[])").
+var L1ERC721GatewayFinalizeBatchWithdrawERC721EventSig = common.HexToHash("0x9b8e51c8f180115b421b26c9042287d6bf95e0ce9c0c5434784e2af3d0b9de7d")
+
+// L1ERC721GatewayFinalizeBatchWithdrawERC721Event is the FinalizeBatchWithdrawERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayFinalizeBatchWithdrawERC721Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+}
+
+// UnpackL1ERC721GatewayFinalizeBatchWithdrawERC721Event unpacks a log of the FinalizeBatchWithdrawERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayFinalizeBatchWithdrawERC721Event(log types.Log) (*L1ERC721GatewayFinalizeBatchWithdrawERC721Event, error) {
+	event := &L1ERC721GatewayFinalizeBatchWithdrawERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "FinalizeBatchWithdrawERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayFinalizeWithdrawERC721EventSig is the topic of the FinalizeWithdrawERC721 event of the L1ERC721Gateway contract,
+// keccak256("FinalizeWithdrawERC721(address,address,address,address,uint256)").
+var L1ERC721GatewayFinalizeWithdrawERC721EventSig = common.HexToHash("0xacdbfefc030b5ccccd5f60ca6d9ca371c6d6d6956fe16ebe10f81920198206e9")
+
+// L1ERC721GatewayFinalizeWithdrawERC721Event is the FinalizeWithdrawERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayFinalizeWithdrawERC721Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+}
+
+// UnpackL1ERC721GatewayFinalizeWithdrawERC721Event unpacks a log of the FinalizeWithdrawERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayFinalizeWithdrawERC721Event(log types.Log) (*L1ERC721GatewayFinalizeWithdrawERC721Event, error) {
+	event := &L1ERC721GatewayFinalizeWithdrawERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "FinalizeWithdrawERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ERC721GatewayRefundERC721EventSig is the topic of the RefundERC721 event of the L1ERC721Gateway contract,
+// keccak256("RefundERC721(address,address,uint256)").
+var L1ERC721GatewayRefundERC721EventSig = common.HexToHash("0xb9a838365634e4fb87a9333edf0ea86f82836e361b311a125aefd14135581208")
+
+// L1ERC721GatewayRefundERC721Event is the RefundERC721 event of the L1ERC721Gateway contract.
+type L1ERC721GatewayRefundERC721Event struct {
+	Token     common.Address // indexed
+	Recipient common.Address // indexed
+	TokenId   *big.Int
+}
+
+// UnpackL1ERC721GatewayRefundERC721Event unpacks a log of the RefundERC721 event of the L1ERC721Gateway contract.
+func UnpackL1ERC721GatewayRefundERC721Event(log types.Log) (*L1ERC721GatewayRefundERC721Event, error) {
+	event := &L1ERC721GatewayRefundERC721Event{}
+	if err := unpackLog(l1ERC721GatewayABI, event, "RefundERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ETHGatewayDepositETHEventSig is the topic of the DepositETH event of the L1ETHGateway contract,
+// keccak256("DepositETH(address,address,uint256,bytes)").
+var L1ETHGatewayDepositETHEventSig = common.HexToHash("0x6670de856ec8bf5cb2b7e957c5dc24759716056f79d97ea5e7c939ca0ba5a675")
+
+// L1ETHGatewayDepositETHEvent is the DepositETH event of the L1ETHGateway contract.
+type L1ETHGatewayDepositETHEvent struct {
+	From   common.Address // indexed
+	To     common.Address // indexed
+	Amount *big.Int
+	Data   []byte
+}
+
+// UnpackL1ETHGatewayDepositETHEvent unpacks a log of the DepositETH event of the L1ETHGateway contract.
+func UnpackL1ETHGatewayDepositETHEvent(log types.Log) (*L1ETHGatewayDepositETHEvent, error) {
+	event := &L1ETHGatewayDepositETHEvent{}
+	if err := unpackLog(l1ETHGatewayABI, event, "DepositETH", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ETHGatewayFinalizeWithdrawETHEventSig is the topic of the FinalizeWithdrawETH event of the L1ETHGateway contract,
+// keccak256("FinalizeWithdrawETH(address,address,uint256,bytes)").
+var L1ETHGatewayFinalizeWithdrawETHEventSig = common.HexToHash("0x96db5d1cee1dd2760826bb56fabd9c9f6e978083e0a8b88559c741a29e9746e7")
+
+// L1ETHGatewayFinalizeWithdrawETHEvent is the FinalizeWithdrawETH event of the L1ETHGateway contract.
+type L1ETHGatewayFinalizeWithdrawETHEvent struct {
+	From   common.Address // indexed
+	To     common.Address // indexed
+	Amount *big.Int
+	Data   []byte
+}
+
+// UnpackL1ETHGatewayFinalizeWithdrawETHEvent unpacks a log of the FinalizeWithdrawETH event of the L1ETHGateway contract.
+func UnpackL1ETHGatewayFinalizeWithdrawETHEvent(log types.Log) (*L1ETHGatewayFinalizeWithdrawETHEvent, error) {
+	event := &L1ETHGatewayFinalizeWithdrawETHEvent{}
+	if err := unpackLog(l1ETHGatewayABI, event, "FinalizeWithdrawETH", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ETHGatewayRefundETHEventSig is the topic of the RefundETH event of the L1ETHGateway contract,
+// keccak256("RefundETH(address,uint256)").
+var L1ETHGatewayRefundETHEventSig = common.HexToHash("0x289360176646a5f99cb4b6300628426dca46b723f40db3c04449d6ed1745a0e7")
+
+// L1ETHGatewayRefundETHEvent is the RefundETH event of the L1ETHGateway contract.
+type L1ETHGatewayRefundETHEvent struct {
+	Recipient common.Address // indexed
+	Amount    *big.Int
+}
+
+// UnpackL1ETHGatewayRefundETHEvent unpacks a log of the RefundETH event of the L1ETHGateway contract.
+func UnpackL1ETHGatewayRefundETHEvent(log types.Log) (*L1ETHGatewayRefundETHEvent, error) {
+	event := &L1ETHGatewayRefundETHEvent{}
+	if err := unpackLog(l1ETHGatewayABI, event, "RefundETH", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1MessageQueueDequeueTransactionEventSig is the topic of the DequeueTransaction event of the L1MessageQueue contract,
+// keccak256("DequeueTransaction(uint256,uint256,uint256)").
+var L1MessageQueueDequeueTransactionEventSig = common.HexToHash("0xc77f792f838ae38399ac31acc3348389aeb110ce7bedf3cfdbdd5e6679267970")
+
+// L1MessageQueueDequeueTransactionEvent is the DequeueTransaction event of the L1MessageQueue contract.
+type L1MessageQueueDequeueTransactionEvent struct {
+	StartIndex    *big.Int
+	Count         *big.Int
+	SkippedBitmap *big.Int
+}
+
+// UnpackL1MessageQueueDequeueTransactionEvent unpacks a log of the DequeueTransaction event of the L1MessageQueue contract.
+func UnpackL1MessageQueueDequeueTransactionEvent(log types.Log) (*L1MessageQueueDequeueTransactionEvent, error) {
+	event := &L1MessageQueueDequeueTransactionEvent{}
+	if err := unpackLog(l1MessageQueueABI, event, "DequeueTransaction", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1MessageQueueDropTransactionEventSig is the topic of the DropTransaction event of the L1MessageQueue contract,
+// keccak256("DropTransaction(uint256)").
+var L1MessageQueueDropTransactionEventSig = common.HexToHash("0x43a375005206d20a83abc71722cba68c24434a8dc1f583775be7c3fde0396cbf")
+
+// L1MessageQueueDropTransactionEvent is the DropTransaction event of the L1MessageQueue contract.
+type L1MessageQueueDropTransactionEvent struct {
+	Index *big.Int
+}
+
+// UnpackL1MessageQueueDropTransactionEvent unpacks a log of the DropTransaction event of the L1MessageQueue contract.
+func UnpackL1MessageQueueDropTransactionEvent(log types.Log) (*L1MessageQueueDropTransactionEvent, error) {
+	event := &L1MessageQueueDropTransactionEvent{}
+	if err := unpackLog(l1MessageQueueABI, event, "DropTransaction", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1MessageQueueQueueTransactionEventSig is the topic of the QueueTransaction event of the L1MessageQueue contract,
+// keccak256("QueueTransaction(address,address,uint256,uint64,uint256,bytes)").
+var L1MessageQueueQueueTransactionEventSig = common.HexToHash("0x69cfcb8e6d4192b8aba9902243912587f37e550d75c1fa801491fce26717f37e")
+
+// L1MessageQueueQueueTransactionEvent is the QueueTransaction event of the L1MessageQueue contract.
+type L1MessageQueueQueueTransactionEvent struct {
+	Sender     common.Address // indexed
+	Target     common.Address // indexed
+	Value      *big.Int
+	QueueIndex uint64
+	GasLimit   *big.Int
+	Data       []byte
+}
+
+// UnpackL1MessageQueueQueueTransactionEvent unpacks a log of the QueueTransaction event of the L1MessageQueue contract.
+func UnpackL1MessageQueueQueueTransactionEvent(log types.Log) (*L1MessageQueueQueueTransactionEvent, error) {
+	event := &L1MessageQueueQueueTransactionEvent{}
+	if err := unpackLog(l1MessageQueueABI, event, "QueueTransaction", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ScrollMessengerFailedRelayedMessageEventSig is the topic of the FailedRelayedMessage event of the L1ScrollMessenger contract,
+// keccak256("FailedRelayedMessage(bytes32)").
+var L1ScrollMessengerFailedRelayedMessageEventSig = common.HexToHash("0x99d0e048484baa1b1540b1367cb128acd7ab2946d1ed91ec10e3c85e4bf51b8f")
+
+// L1ScrollMessengerFailedRelayedMessageEvent is the FailedRelayedMessage event of the L1ScrollMessenger contract.
+type L1ScrollMessengerFailedRelayedMessageEvent struct {
+	MessageHash common.Hash // indexed
+}
+
+// UnpackL1ScrollMessengerFailedRelayedMessageEvent unpacks a log of the FailedRelayedMessage event of the L1ScrollMessenger contract.
+func UnpackL1ScrollMessengerFailedRelayedMessageEvent(log types.Log) (*L1ScrollMessengerFailedRelayedMessageEvent, error) {
+	event := &L1ScrollMessengerFailedRelayedMessageEvent{}
+	if err := unpackLog(l1ScrollMessengerABI, event, "FailedRelayedMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ScrollMessengerRelayedMessageEventSig is the topic of the RelayedMessage event of the L1ScrollMessenger contract,
+// keccak256("RelayedMessage(bytes32)").
+var L1ScrollMessengerRelayedMessageEventSig = common.HexToHash("0x4641df4a962071e12719d8c8c8e5ac7fc4d97b927346a3d7a335b1f7517e133c")
+
+// L1ScrollMessengerRelayedMessageEvent is the RelayedMessage event of the L1ScrollMessenger contract.
+type L1ScrollMessengerRelayedMessageEvent struct {
+	MessageHash common.Hash // indexed
+}
+
+// UnpackL1ScrollMessengerRelayedMessageEvent unpacks a log of the RelayedMessage event of the L1ScrollMessenger contract.
+func UnpackL1ScrollMessengerRelayedMessageEvent(log types.Log) (*L1ScrollMessengerRelayedMessageEvent, error) {
+	event := &L1ScrollMessengerRelayedMessageEvent{}
+	if err := unpackLog(l1ScrollMessengerABI, event, "RelayedMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ScrollMessengerSentMessageEventSig is the topic of the SentMessage event of the L1ScrollMessenger contract,
+// keccak256("SentMessage(address,address,uint256,uint256,uint256,bytes)").
+var L1ScrollMessengerSentMessageEventSig = common.HexToHash("0x104371f3b442861a2a7b82a070afbbaab748bb13757bf47769e170e37809ec1e")
+
+// L1ScrollMessengerSentMessageEvent is the SentMessage event of the L1ScrollMessenger contract.
+type L1ScrollMessengerSentMessageEvent struct {
+	Sender       common.Address // indexed
+	Target       common.Address // indexed
+	Value        *big.Int
+	MessageNonce *big.Int
+	GasLimit     *big.Int
+	Message      []byte
+}
+
+// UnpackL1ScrollMessengerSentMessageEvent unpacks a log of the SentMessage event of the L1ScrollMessenger contract.
+func UnpackL1ScrollMessengerSentMessageEvent(log types.Log) (*L1ScrollMessengerSentMessageEvent, error) {
+	event := &L1ScrollMessengerSentMessageEvent{}
+	if err := unpackLog(l1ScrollMessengerABI, event, "SentMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L1ScrollMessengerUpdateMaxReplayTimesEventSig is the topic of the UpdateMaxReplayTimes event of the L1ScrollMessenger contract,
+// keccak256("UpdateMaxReplayTimes(uint256,uint256)").
+var L1ScrollMessengerUpdateMaxReplayTimesEventSig = common.HexToHash("0xd700562df02eb66951f6f5275df7ebd7c0ec58b3422915789b3b1877aab2e52b")
+
+// L1ScrollMessengerUpdateMaxReplayTimesEvent is the UpdateMaxReplayTimes event of the L1ScrollMessenger contract.
+type L1ScrollMessengerUpdateMaxReplayTimesEvent struct {
+	OldMaxReplayTimes *big.Int
+	NewMaxReplayTimes *big.Int
+}
+
+// UnpackL1ScrollMessengerUpdateMaxReplayTimesEvent unpacks a log of the UpdateMaxReplayTimes event of the L1ScrollMessenger contract.
+func UnpackL1ScrollMessengerUpdateMaxReplayTimesEvent(log types.Log) (*L1ScrollMessengerUpdateMaxReplayTimesEvent, error) {
+	event := &L1ScrollMessengerUpdateMaxReplayTimesEvent{}
+	if err := unpackLog(l1ScrollMessengerABI, event, "UpdateMaxReplayTimes", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC1155GatewayBatchWithdrawERC1155EventSig is the topic of the BatchWithdrawERC1155 event of the L2ERC1155Gateway contract,
+// keccak256("BatchWithdrawERC1155(address,address,address,address,uint256[],uint256[])").
+var L2ERC1155GatewayBatchWithdrawERC1155EventSig = common.HexToHash("0x5d2d5d4cdbf7b115e43f0b9986644dd8b9514b10be6a019ab6a4a87f12290970")
+
+// L2ERC1155GatewayBatchWithdrawERC1155Event is the BatchWithdrawERC1155 event of the L2ERC1155Gateway contract.
+type L2ERC1155GatewayBatchWithdrawERC1155Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+	Amounts  []*big.Int
+}
+
+// UnpackL2ERC1155GatewayBatchWithdrawERC1155Event unpacks a log of the BatchWithdrawERC1155 event of the L2ERC1155Gateway contract.
+func UnpackL2ERC1155GatewayBatchWithdrawERC1155Event(log types.Log) (*L2ERC1155GatewayBatchWithdrawERC1155Event, error) {
+	event := &L2ERC1155GatewayBatchWithdrawERC1155Event{}
+	if err := unpackLog(l2ERC1155GatewayABI, event, "BatchWithdrawERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC1155GatewayFinalizeBatchDepositERC1155EventSig is the topic of the FinalizeBatchDepositERC1155 event of the L2ERC1155Gateway contract,
+// keccak256("FinalizeBatchDepositERC1155(address,address,address,address,uint256[],uint256[])").
+var L2ERC1155GatewayFinalizeBatchDepositERC1155EventSig = common.HexToHash("0xf07745bfeb45fb1184165136e9148689adf57ba578a5b90dde949f26066b7756")
+
+// L2ERC1155GatewayFinalizeBatchDepositERC1155Event is the FinalizeBatchDepositERC1155 event of the L2ERC1155Gateway contract.
+type L2ERC1155GatewayFinalizeBatchDepositERC1155Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+	Amounts  []*big.Int
+}
+
+// UnpackL2ERC1155GatewayFinalizeBatchDepositERC1155Event unpacks a log of the FinalizeBatchDepositERC1155 event of the L2ERC1155Gateway contract.
+func UnpackL2ERC1155GatewayFinalizeBatchDepositERC1155Event(log types.Log) (*L2ERC1155GatewayFinalizeBatchDepositERC1155Event, error) {
+	event := &L2ERC1155GatewayFinalizeBatchDepositERC1155Event{}
+	if err := unpackLog(l2ERC1155GatewayABI, event, "FinalizeBatchDepositERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC1155GatewayFinalizeDepositERC1155EventSig is the topic of the FinalizeDepositERC1155 event of the L2ERC1155Gateway contract,
+// keccak256("FinalizeDepositERC1155(address,address,address,address,uint256,uint256)").
+var L2ERC1155GatewayFinalizeDepositERC1155EventSig = common.HexToHash("0x5399dc7b86d085e50a28946dbc213966bb7a7ac78d312aedd6018c791ad6cef9")
+
+// L2ERC1155GatewayFinalizeDepositERC1155Event is the FinalizeDepositERC1155 event of the L2ERC1155Gateway contract.
+type L2ERC1155GatewayFinalizeDepositERC1155Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// UnpackL2ERC1155GatewayFinalizeDepositERC1155Event unpacks a log of the FinalizeDepositERC1155 event of the L2ERC1155Gateway contract.
+func UnpackL2ERC1155GatewayFinalizeDepositERC1155Event(log types.Log) (*L2ERC1155GatewayFinalizeDepositERC1155Event, error) {
+	event := &L2ERC1155GatewayFinalizeDepositERC1155Event{}
+	if err := unpackLog(l2ERC1155GatewayABI, event, "FinalizeDepositERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC1155GatewayWithdrawERC1155EventSig is the topic of the WithdrawERC1155 event of the L2ERC1155Gateway contract,
+// keccak256("WithdrawERC1155(address,address,address,address,uint256,uint256)").
+var L2ERC1155GatewayWithdrawERC1155EventSig = common.HexToHash("0x1f9dcda7fce6f73a13055f044ffecaed2032a7a844e0a37a3eb8bbb17488d01a")
+
+// L2ERC1155GatewayWithdrawERC1155Event is the WithdrawERC1155 event of the L2ERC1155Gateway contract.
+type L2ERC1155GatewayWithdrawERC1155Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// UnpackL2ERC1155GatewayWithdrawERC1155Event unpacks a log of the WithdrawERC1155 event of the L2ERC1155Gateway contract.
+func UnpackL2ERC1155GatewayWithdrawERC1155Event(log types.Log) (*L2ERC1155GatewayWithdrawERC1155Event, error) {
+	event := &L2ERC1155GatewayWithdrawERC1155Event{}
+	if err := unpackLog(l2ERC1155GatewayABI, event, "WithdrawERC1155", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC20GatewayFinalizeDepositERC20EventSig is the topic of the FinalizeDepositERC20 event of the L2ERC20Gateway contract,
+// keccak256("FinalizeDepositERC20(address,address,address,address,uint256,bytes)").
+var L2ERC20GatewayFinalizeDepositERC20EventSig = common.HexToHash("0x165ba69f6ab40c50cade6f65431801e5f9c7d7830b7545391920db039133ba34")
+
+// L2ERC20GatewayFinalizeDepositERC20Event is the FinalizeDepositERC20 event of the L2ERC20Gateway contract.
+type L2ERC20GatewayFinalizeDepositERC20Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	Amount  *big.Int
+	Data    []byte
+}
+
+// UnpackL2ERC20GatewayFinalizeDepositERC20Event unpacks a log of the FinalizeDepositERC20 event of the L2ERC20Gateway contract.
+func UnpackL2ERC20GatewayFinalizeDepositERC20Event(log types.Log) (*L2ERC20GatewayFinalizeDepositERC20Event, error) {
+	event := &L2ERC20GatewayFinalizeDepositERC20Event{}
+	if err := unpackLog(l2ERC20GatewayABI, event, "FinalizeDepositERC20", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC20GatewayWithdrawERC20EventSig is the topic of the WithdrawERC20 event of the L2ERC20Gateway contract,
+// keccak256("WithdrawERC20(address,address,address,address,uint256,bytes)").
+var L2ERC20GatewayWithdrawERC20EventSig = common.HexToHash("0xd8d3a3f4ab95694bef40475997598bcf8acd3ed9617a4c1013795429414c27e8")
+
+// L2ERC20GatewayWithdrawERC20Event is the WithdrawERC20 event of the L2ERC20Gateway contract.
+type L2ERC20GatewayWithdrawERC20Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	Amount  *big.Int
+	Data    []byte
+}
+
+// UnpackL2ERC20GatewayWithdrawERC20Event unpacks a log of the WithdrawERC20 event of the L2ERC20Gateway contract.
+func UnpackL2ERC20GatewayWithdrawERC20Event(log types.Log) (*L2ERC20GatewayWithdrawERC20Event, error) {
+	event := &L2ERC20GatewayWithdrawERC20Event{}
+	if err := unpackLog(l2ERC20GatewayABI, event, "WithdrawERC20", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayBatchWithdrawERC721EventSig is the topic of the BatchWithdrawERC721 event of the L2ERC721Gateway contract,
+// keccak256("BatchWithdrawERC721(address,address,address,address,uint256[])").
+var L2ERC721GatewayBatchWithdrawERC721EventSig = common.HexToHash("0xbdb7b5cec70093e3ce49b258071951d245c0871c006fd9327778c69d0e9f244d")
+
+// L2ERC721GatewayBatchWithdrawERC721Event is the BatchWithdrawERC721 event of the L2ERC721Gateway contract.
+type L2ERC721GatewayBatchWithdrawERC721Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+}
+
+// UnpackL2ERC721GatewayBatchWithdrawERC721Event unpacks a log of the BatchWithdrawERC721 event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayBatchWithdrawERC721Event(log types.Log) (*L2ERC721GatewayBatchWithdrawERC721Event, error) {
+	event := &L2ERC721GatewayBatchWithdrawERC721Event{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "BatchWithdrawERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayFinalizeBatchDepositERC721EventSig is the topic of the FinalizeBatchDepositERC721 event of the L2ERC721Gateway contract,
+// keccak256("FinalizeBatchDepositERC721(address,address,address,address,uint256[])").
+var L2ERC721GatewayFinalizeBatchDepositERC721EventSig = common.HexToHash("0xafa88b850da44ca05b319e813873eac8d08e7c041d2d9b3072db0f087e3cd29e")
+
+// L2ERC721GatewayFinalizeBatchDepositERC721Event is the FinalizeBatchDepositERC721 event of the L2ERC721Gateway contract.
+type L2ERC721GatewayFinalizeBatchDepositERC721Event struct {
+	L1Token  common.Address // indexed
+	L2Token  common.Address // indexed
+	From     common.Address // indexed
+	To       common.Address
+	TokenIds []*big.Int
+}
+
+// UnpackL2ERC721GatewayFinalizeBatchDepositERC721Event unpacks a log of the FinalizeBatchDepositERC721 event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayFinalizeBatchDepositERC721Event(log types.Log) (*L2ERC721GatewayFinalizeBatchDepositERC721Event, error) {
+	event := &L2ERC721GatewayFinalizeBatchDepositERC721Event{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "FinalizeBatchDepositERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayFinalizeDepositERC721EventSig is the topic of the FinalizeDepositERC721 event of the L2ERC721Gateway contract,
+// keccak256("FinalizeDepositERC721(address,address,address,address,uint256)").
+var L2ERC721GatewayFinalizeDepositERC721EventSig = common.HexToHash("0xc655ec1de34d98630aa4572239414f926d6b3d07653dde093a6df97377e31b41")
+
+// L2ERC721GatewayFinalizeDepositERC721Event is the FinalizeDepositERC721 event of the L2ERC721Gateway contract.
+type L2ERC721GatewayFinalizeDepositERC721Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+}
+
+// UnpackL2ERC721GatewayFinalizeDepositERC721Event unpacks a log of the FinalizeDepositERC721 event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayFinalizeDepositERC721Event(log types.Log) (*L2ERC721GatewayFinalizeDepositERC721Event, error) {
+	event := &L2ERC721GatewayFinalizeDepositERC721Event{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "FinalizeDepositERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayOwnershipTransferredEventSig is the topic of the OwnershipTransferred event of the L2ERC721Gateway contract,
+// keccak256("OwnershipTransferred(address,address)").
+var L2ERC721GatewayOwnershipTransferredEventSig = common.HexToHash("0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0")
+
+// L2ERC721GatewayOwnershipTransferredEvent is the OwnershipTransferred event of the L2ERC721Gateway contract.
+type L2ERC721GatewayOwnershipTransferredEvent struct {
+	PreviousOwner common.Address // indexed
+	NewOwner      common.Address // indexed
+}
+
+// UnpackL2ERC721GatewayOwnershipTransferredEvent unpacks a log of the OwnershipTransferred event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayOwnershipTransferredEvent(log types.Log) (*L2ERC721GatewayOwnershipTransferredEvent, error) {
+	event := &L2ERC721GatewayOwnershipTransferredEvent{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayUpdateTokenMappingEventSig is the topic of the UpdateTokenMapping event of the L2ERC721Gateway contract,
+// keccak256("UpdateTokenMapping(address,address)").
+var L2ERC721GatewayUpdateTokenMappingEventSig = common.HexToHash("0xcb7d5959c6ea086e1e4326bb4745f80c494524693345a2ca0f1f1221d7cc77db")
+
+// L2ERC721GatewayUpdateTokenMappingEvent is the UpdateTokenMapping event of the L2ERC721Gateway contract.
+type L2ERC721GatewayUpdateTokenMappingEvent struct {
+	L2Token common.Address
+	L1Token common.Address
+}
+
+// UnpackL2ERC721GatewayUpdateTokenMappingEvent unpacks a log of the UpdateTokenMapping event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayUpdateTokenMappingEvent(log types.Log) (*L2ERC721GatewayUpdateTokenMappingEvent, error) {
+	event := &L2ERC721GatewayUpdateTokenMappingEvent{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "UpdateTokenMapping", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ERC721GatewayWithdrawERC721EventSig is the topic of the WithdrawERC721 event of the L2ERC721Gateway contract,
+// keccak256("WithdrawERC721(address,address,address,address,uint256)").
+var L2ERC721GatewayWithdrawERC721EventSig = common.HexToHash("0xe9e85cf0c862dd491ecda3c9a230e12ada8956472028ebde4fdc4f8e2d77bcda")
+
+// L2ERC721GatewayWithdrawERC721Event is the WithdrawERC721 event of the L2ERC721Gateway contract.
+type L2ERC721GatewayWithdrawERC721Event struct {
+	L1Token common.Address // indexed
+	L2Token common.Address // indexed
+	From    common.Address // indexed
+	To      common.Address
+	TokenId *big.Int
+}
+
+// UnpackL2ERC721GatewayWithdrawERC721Event unpacks a log of the WithdrawERC721 event of the L2ERC721Gateway contract.
+func UnpackL2ERC721GatewayWithdrawERC721Event(log types.Log) (*L2ERC721GatewayWithdrawERC721Event, error) {
+	event := &L2ERC721GatewayWithdrawERC721Event{}
+	if err := unpackLog(l2ERC721GatewayABI, event, "WithdrawERC721", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ETHGatewayFinalizeDepositETHEventSig is the topic of the FinalizeDepositETH event of the L2ETHGateway contract,
+// keccak256("FinalizeDepositETH(address,address,uint256,bytes)").
+var L2ETHGatewayFinalizeDepositETHEventSig = common.HexToHash("0x9e86c356e14e24e26e3ce769bf8b87de38e0faa0ed0ca946fa09659aa606bd2d")
+
+// L2ETHGatewayFinalizeDepositETHEvent is the FinalizeDepositETH event of the L2ETHGateway contract.
+type L2ETHGatewayFinalizeDepositETHEvent struct {
+	From   common.Address // indexed
+	To     common.Address // indexed
+	Amount *big.Int
+	Data   []byte
+}
+
+// UnpackL2ETHGatewayFinalizeDepositETHEvent unpacks a log of the FinalizeDepositETH event of the L2ETHGateway contract.
+func UnpackL2ETHGatewayFinalizeDepositETHEvent(log types.Log) (*L2ETHGatewayFinalizeDepositETHEvent, error) {
+	event := &L2ETHGatewayFinalizeDepositETHEvent{}
+	if err := unpackLog(l2ETHGatewayABI, event, "FinalizeDepositETH", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ETHGatewayWithdrawETHEventSig is the topic of the WithdrawETH event of the L2ETHGateway contract,
+// keccak256("WithdrawETH(address,address,uint256,bytes)").
+var L2ETHGatewayWithdrawETHEventSig = common.HexToHash("0xd8ed6eaa9a7a8980d7901e911fde6686810b989d3082182d1d3a3df6306ce20e")
+
+// L2ETHGatewayWithdrawETHEvent is the WithdrawETH event of the L2ETHGateway contract.
+type L2ETHGatewayWithdrawETHEvent struct {
+	From   common.Address // indexed
+	To     common.Address // indexed
+	Amount *big.Int
+	Data   []byte
+}
+
+// UnpackL2ETHGatewayWithdrawETHEvent unpacks a log of the WithdrawETH event of the L2ETHGateway contract.
+func UnpackL2ETHGatewayWithdrawETHEvent(log types.Log) (*L2ETHGatewayWithdrawETHEvent, error) {
+	event := &L2ETHGatewayWithdrawETHEvent{}
+	if err := unpackLog(l2ETHGatewayABI, event, "WithdrawETH", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2MessageQueueAppendMessageEventSig is the topic of the AppendMessage event of the L2MessageQueue contract,
+// keccak256("AppendMessage(uint256,bytes32)").
+var L2MessageQueueAppendMessageEventSig = common.HexToHash("0xfaa617c2d8ce12c62637dbce76efcc18dae60574aa95709bdcedce7e76071693")
+
+// L2MessageQueueAppendMessageEvent is the AppendMessage event of the L2MessageQueue contract.
+type L2MessageQueueAppendMessageEvent struct {
+	Index       *big.Int
+	MessageHash common.Hash
+}
+
+// UnpackL2MessageQueueAppendMessageEvent unpacks a log of the AppendMessage event of the L2MessageQueue contract.
+func UnpackL2MessageQueueAppendMessageEvent(log types.Log) (*L2MessageQueueAppendMessageEvent, error) {
+	event := &L2MessageQueueAppendMessageEvent{}
+	if err := unpackLog(l2MessageQueueABI, event, "AppendMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ScrollMessengerFailedRelayedMessageEventSig is the topic of the FailedRelayedMessage event of the L2ScrollMessenger contract,
+// keccak256("FailedRelayedMessage(bytes32)").
+var L2ScrollMessengerFailedRelayedMessageEventSig = common.HexToHash("0x99d0e048484baa1b1540b1367cb128acd7ab2946d1ed91ec10e3c85e4bf51b8f")
+
+// L2ScrollMessengerFailedRelayedMessageEvent is the FailedRelayedMessage event of the L2ScrollMessenger contract.
+type L2ScrollMessengerFailedRelayedMessageEvent struct {
+	MessageHash common.Hash // indexed
+}
+
+// UnpackL2ScrollMessengerFailedRelayedMessageEvent unpacks a log of the FailedRelayedMessage event of the L2ScrollMessenger contract.
+func UnpackL2ScrollMessengerFailedRelayedMessageEvent(log types.Log) (*L2ScrollMessengerFailedRelayedMessageEvent, error) {
+	event := &L2ScrollMessengerFailedRelayedMessageEvent{}
+	if err := unpackLog(l2ScrollMessengerABI, event, "FailedRelayedMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ScrollMessengerRelayedMessageEventSig is the topic of the RelayedMessage event of the L2ScrollMessenger contract,
+// keccak256("RelayedMessage(bytes32)").
+var L2ScrollMessengerRelayedMessageEventSig = common.HexToHash("0x4641df4a962071e12719d8c8c8e5ac7fc4d97b927346a3d7a335b1f7517e133c")
+
+// L2ScrollMessengerRelayedMessageEvent is the RelayedMessage event of the L2ScrollMessenger contract.
+type L2ScrollMessengerRelayedMessageEvent struct {
+	MessageHash common.Hash // indexed
+}
+
+// UnpackL2ScrollMessengerRelayedMessageEvent unpacks a log of the RelayedMessage event of the L2ScrollMessenger contract.
+func UnpackL2ScrollMessengerRelayedMessageEvent(log types.Log) (*L2ScrollMessengerRelayedMessageEvent, error) {
+	event := &L2ScrollMessengerRelayedMessageEvent{}
+	if err := unpackLog(l2ScrollMessengerABI, event, "RelayedMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ScrollMessengerSentMessageEventSig is the topic of the SentMessage event of the L2ScrollMessenger contract,
+// keccak256("SentMessage(address,address,uint256,uint256,uint256,bytes)").
+var L2ScrollMessengerSentMessageEventSig = common.HexToHash("0x104371f3b442861a2a7b82a070afbbaab748bb13757bf47769e170e37809ec1e")
+
+// L2ScrollMessengerSentMessageEvent is the SentMessage event of the L2ScrollMessenger contract.
+type L2ScrollMessengerSentMessageEvent struct {
+	Sender       common.Address // indexed
+	Target       common.Address // indexed
+	Value        *big.Int
+	MessageNonce *big.Int
+	GasLimit     *big.Int
+	Message      []byte
+}
+
+// UnpackL2ScrollMessengerSentMessageEvent unpacks a log of the SentMessage event of the L2ScrollMessenger contract.
+func UnpackL2ScrollMessengerSentMessageEvent(log types.Log) (*L2ScrollMessengerSentMessageEvent, error) {
+	event := &L2ScrollMessengerSentMessageEvent{}
+	if err := unpackLog(l2ScrollMessengerABI, event, "SentMessage", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2ScrollMessengerUpdateMaxFailedExecutionTimesEventSig is the topic of the UpdateMaxFailedExecutionTimes event of the L2ScrollMessenger contract,
+// keccak256("UpdateMaxFailedExecutionTimes(uint256,uint256)").
+var L2ScrollMessengerUpdateMaxFailedExecutionTimesEventSig = common.HexToHash("0x8a4c22c9b46f23dedd49b843839940ce0c36fa1612073a9bc7dbaeef9ee547ba")
+
+// L2ScrollMessengerUpdateMaxFailedExecutionTimesEvent is the UpdateMaxFailedExecutionTimes event of the L2ScrollMessenger contract.
+type L2ScrollMessengerUpdateMaxFailedExecutionTimesEvent struct {
+	OldMaxFailedExecutionTimes *big.Int
+	NewMaxFailedExecutionTimes *big.Int
+}
+
+// UnpackL2ScrollMessengerUpdateMaxFailedExecutionTimesEvent unpacks a log of the UpdateMaxFailedExecutionTimes event of the L2ScrollMessenger contract.
+func UnpackL2ScrollMessengerUpdateMaxFailedExecutionTimesEvent(log types.Log) (*L2ScrollMessengerUpdateMaxFailedExecutionTimesEvent, error) {
+	event := &L2ScrollMessengerUpdateMaxFailedExecutionTimesEvent{}
+	if err := unpackLog(l2ScrollMessengerABI, event, "UpdateMaxFailedExecutionTimes", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// L2TxFeeVaultWithdrawalEventSig is the topic of the Withdrawal event of the L2TxFeeVault contract,
+// keccak256("Withdrawal(uint256,address,address)").
+var L2TxFeeVaultWithdrawalEventSig = common.HexToHash("0xc8a211cc64b6ed1b50595a9fcb1932b6d1e5a6e8ef15b60e5b1f988ea9086bba")
+
+// L2TxFeeVaultWithdrawalEvent is the Withdrawal event of the L2TxFeeVault contract.
+type L2TxFeeVaultWithdrawalEvent struct {
+	Value *big.Int
+	To    common.Address
+	From  common.Address
+}
+
+// UnpackL2TxFeeVaultWithdrawalEvent unpacks a log of the Withdrawal event of the L2TxFeeVault contract.
+func UnpackL2TxFeeVaultWithdrawalEvent(log types.Log) (*L2TxFeeVaultWithdrawalEvent, error) {
+	event := &L2TxFeeVaultWithdrawalEvent{}
+	if err := unpackLog(l2TxFeeVaultABI, event, "Withdrawal", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ScrollChainCommitBatchEventSig is the topic of the CommitBatch event of the ScrollChain contract,
+// keccak256("CommitBatch(uint256,bytes32)").
+var ScrollChainCommitBatchEventSig = common.HexToHash("0x2c32d4ae151744d0bf0b9464a3e897a1d17ed2f1af71f7c9a75f12ce0d28238f")
+
+// ScrollChainCommitBatchEvent is the CommitBatch event of the ScrollChain contract.
+type ScrollChainCommitBatchEvent struct {
+	BatchIndex *big.Int    // indexed
+	BatchHash  common.Hash // indexed
+}
+
+// UnpackScrollChainCommitBatchEvent unpacks a log of the CommitBatch event of the ScrollChain contract.
+func UnpackScrollChainCommitBatchEvent(log types.Log) (*ScrollChainCommitBatchEvent, error) {
+	event := &ScrollChainCommitBatchEvent{}
+	if err := unpackLog(scrollChainABI, event, "CommitBatch", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ScrollChainFinalizeBatchEventSig is the topic of the FinalizeBatch event of the ScrollChain contract,
+// keccak256("FinalizeBatch(uint256,bytes32,bytes32,bytes32)").
+var ScrollChainFinalizeBatchEventSig = common.HexToHash("0x26ba82f907317eedc97d0cbef23de76a43dd6edb563bdb6e9407645b950a7a2d")
+
+// ScrollChainFinalizeBatchEvent is the FinalizeBatch event of the ScrollChain contract.
+type ScrollChainFinalizeBatchEvent struct {
+	BatchIndex   *big.Int    // indexed
+	BatchHash    common.Hash // indexed
+	StateRoot    common.Hash
+	WithdrawRoot common.Hash
+}
+
+// UnpackScrollChainFinalizeBatchEvent unpacks a log of the FinalizeBatch event of the ScrollChain contract.
+func UnpackScrollChainFinalizeBatchEvent(log types.Log) (*ScrollChainFinalizeBatchEvent, error) {
+	event := &ScrollChainFinalizeBatchEvent{}
+	if err := unpackLog(scrollChainABI, event, "FinalizeBatch", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ScrollChainRevertBatchEventSig is the topic of the RevertBatch event of the ScrollChain contract,
+// keccak256("RevertBatch(uint256,bytes32)").
+var ScrollChainRevertBatchEventSig = common.HexToHash("0x00cae2739091badfd91c373f0a16cede691e0cd25bb80cff77dd5caeb4710146")
+
+// ScrollChainRevertBatchEvent is the RevertBatch event of the ScrollChain contract.
+type ScrollChainRevertBatchEvent struct {
+	BatchIndex *big.Int    // indexed
+	BatchHash  common.Hash // indexed
+}
+
+// UnpackScrollChainRevertBatchEvent unpacks a log of the RevertBatch event of the ScrollChain contract.
+func UnpackScrollChainRevertBatchEvent(log types.Log) (*ScrollChainRevertBatchEvent, error) {
+	event := &ScrollChainRevertBatchEvent{}
+	if err := unpackLog(scrollChainABI, event, "RevertBatch", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
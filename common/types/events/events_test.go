@@ -0,0 +1,58 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/goldenlogs"
+)
+
+func TestEventSigs(t *testing.T) {
+	for _, tt := range []struct {
+		sig      common.Hash
+		expected string
+	}{
+		{ScrollChainCommitBatchEventSig, "2c32d4ae151744d0bf0b9464a3e897a1d17ed2f1af71f7c9a75f12ce0d28238f"},
+		{ScrollChainFinalizeBatchEventSig, "26ba82f907317eedc97d0cbef23de76a43dd6edb563bdb6e9407645b950a7a2d"},
+		{L1MessageQueueQueueTransactionEventSig, "69cfcb8e6d4192b8aba9902243912587f37e550d75c1fa801491fce26717f37e"},
+		{L2ScrollMessengerSentMessageEventSig, "104371f3b442861a2a7b82a070afbbaab748bb13757bf47769e170e37809ec1e"},
+		{L2ScrollMessengerRelayedMessageEventSig, "4641df4a962071e12719d8c8c8e5ac7fc4d97b927346a3d7a335b1f7517e133c"},
+		{L2ScrollMessengerFailedRelayedMessageEventSig, "99d0e048484baa1b1540b1367cb128acd7ab2946d1ed91ec10e3c85e4bf51b8f"},
+		{L2MessageQueueAppendMessageEventSig, "faa617c2d8ce12c62637dbce76efcc18dae60574aa95709bdcedce7e76071693"},
+	} {
+		assert.Equal(t, common.HexToHash(tt.expected), tt.sig)
+	}
+
+	// the generated topics are the ids of the events of the embedded abis.
+	assert.Equal(t, scrollChainABI.Events["RevertBatch"].ID, ScrollChainRevertBatchEventSig)
+	assert.Equal(t, l1ERC721GatewayABI.Events["BatchDepositERC721"].ID, L1ERC721GatewayBatchDepositERC721EventSig)
+	assert.Equal(t, l2TxFeeVaultABI.Events["Withdrawal"].ID, L2TxFeeVaultWithdrawalEventSig)
+}
+
+func TestUnpackSignatureMismatch(t *testing.T) {
+	_, err := UnpackScrollChainCommitBatchEvent(types.Log{Topics: []common.Hash{ScrollChainFinalizeBatchEventSig}})
+	assert.Error(t, err)
+	_, err = UnpackScrollChainCommitBatchEvent(types.Log{})
+	assert.Error(t, err)
+}
+
+func TestGoldenLogs(t *testing.T) {
+	tests := []struct {
+		file   string
+		event  string
+		decode func(types.Log) (interface{}, error)
+	}{
+		{"l1_message_queue_queue_transaction.json", "QueueTransaction", func(vLog types.Log) (interface{}, error) { return UnpackL1MessageQueueQueueTransactionEvent(vLog) }},
+		{"l1_message_queue_dequeue_transaction.json", "DequeueTransaction", func(vLog types.Log) (interface{}, error) { return UnpackL1MessageQueueDequeueTransactionEvent(vLog) }},
+		{"scroll_chain_commit_batch.json", "CommitBatch", func(vLog types.Log) (interface{}, error) { return UnpackScrollChainCommitBatchEvent(vLog) }},
+		{"scroll_chain_finalize_batch.json", "FinalizeBatch", func(vLog types.Log) (interface{}, error) { return UnpackScrollChainFinalizeBatchEvent(vLog) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			goldenlogs.Replay(t, "../../testdata/golden_logs/"+tt.file, tt.decode)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Command gen generates the typed events of the contracts from their event abis, run through go generate in
+// common/types/events:
+//
+//	go run ./gen --abi abi --out events_gen.go
+//
+// Each abi/<Contract>.json yields, for each event of the contract, a struct with one field per argument, a topic and
+// an unpack function, all named after the contract and the event, e.g. ScrollChainCommitBatchEvent.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	abiDirFlag = cli.StringFlag{
+		Name:  "abi",
+		Usage: "Directory of the event abis, one <Contract>.json per contract",
+		Value: "abi",
+	}
+	outFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "Path of the generated file",
+		Value: "events_gen.go",
+	}
+	packageFlag = cli.StringFlag{
+		Name:  "package",
+		Usage: "Package of the generated file",
+		Value: "events",
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "gen"
+	app.Usage = "Generate the typed events of the contracts from their abis"
+	app.Flags = []cli.Flag{&abiDirFlag, &outFlag, &packageFlag}
+	app.Action = func(ctx *cli.Context) error {
+		src, err := generate(ctx.String(packageFlag.Name), ctx.String(abiDirFlag.Name))
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(ctx.String(outFlag.Name), src, 0644) // #nosec G306
+	}
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// abiArgument is an argument of an event in the abi json.
+type abiArgument struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed"`
+}
+
+// abiEntry is an entry of the abi json, only the events are generated.
+type abiEntry struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Anonymous bool          `json:"anonymous"`
+	Inputs    []abiArgument `json:"inputs"`
+}
+
+// contract is a contract of the abi directory with its events.
+type contract struct {
+	Name    string // the name of the abi file, e.g. ScrollChain
+	ABIFile string // the path of the abi file relative to the abi directory's parent, for go:embed
+	Events  []*event
+}
+
+// event is an event of a contract.
+type event struct {
+	Name      string // e.g. CommitBatch
+	Signature string // e.g. CommitBatch(uint256,bytes32)
+	Topic     string // keccak256 of the signature, hex encoded
+	Fields    []*field
+}
+
+// field is a field of the struct of an event.
+type field struct {
+	Name    string // the argument name in camel case, as the abi unpacking expects
+	Type    string
+	Indexed bool
+}
+
+// generate returns the gofmt'ed source of the typed events of the contracts in abiDir.
+func generate(pkg, abiDir string) ([]byte, error) {
+	files, err := filepath.Glob(filepath.Join(abiDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no abi in %s", abiDir)
+	}
+	sort.Strings(files)
+
+	var contracts []*contract
+	usesBig := false
+	for _, file := range files {
+		c, err := loadContract(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range c.Events {
+			for _, f := range e.Fields {
+				usesBig = usesBig || strings.Contains(f.Type, "*big.Int")
+			}
+		}
+		contracts = append(contracts, c)
+	}
+
+	var buf bytes.Buffer
+	w := func(format string, args ...interface{}) { _, _ = fmt.Fprintf(&buf, format, args...) }
+	w("// Code generated by ./gen from the abis in ./abi. DO NOT EDIT.\n\n")
+	w("package %s\n\n", pkg)
+	w("import (\n\t_ \"embed\"\n")
+	if usesBig {
+		w("\t\"math/big\"\n")
+	}
+	w("\n\t\"github.com/scroll-tech/go-ethereum/common\"\n\t\"github.com/scroll-tech/go-ethereum/core/types\"\n)\n\n")
+
+	w("var (\n")
+	for _, c := range contracts {
+		w("\t//go:embed %s\n\t%sABIJSON string\n", c.ABIFile, lowerFirst(c.Name))
+	}
+	w(")\n\n")
+	w("var (\n")
+	for _, c := range contracts {
+		w("\t%[1]sABI = mustParseABI(%[1]sABIJSON)\n", lowerFirst(c.Name))
+	}
+	w(")\n")
+
+	for _, c := range contracts {
+		for _, e := range c.Events {
+			typeName := c.Name + e.Name + "Event"
+			w("\n// %sSig is the topic of the %s event of the %s contract,\n// keccak256(\"%s\").\n", typeName, e.Name, c.Name, e.Signature)
+			w("var %sSig = common.HexToHash(%q)\n\n", typeName, "0x"+e.Topic)
+			w("// %s is the %s event of the %s contract.\n", typeName, e.Name, c.Name)
+			w("type %s struct {\n", typeName)
+			for _, f := range e.Fields {
+				if f.Indexed {
+					w("\t%s %s // indexed\n", f.Name, f.Type)
+				} else {
+					w("\t%s %s\n", f.Name, f.Type)
+				}
+			}
+			w("}\n\n")
+			w("// Unpack%[1]s unpacks a log of the %[2]s event of the %[3]s contract.\n", typeName, e.Name, c.Name)
+			w("func Unpack%[1]s(log types.Log) (*%[1]s, error) {\n", typeName)
+			w("\tevent := &%s{}\n", typeName)
+			w("\tif err := unpackLog(%sABI, event, %q, log); err != nil {\n\t\treturn nil, err\n\t}\n", lowerFirst(c.Name), e.Name)
+			w("\treturn event, nil\n}\n")
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the generated source: %w", err)
+	}
+	return src, nil
+}
+
+// loadContract loads the events of the abi file of a contract.
+func loadContract(file string) (*contract, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	var entries []abiEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse abi %s: %w", file, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), ".json")
+	c := &contract{Name: name, ABIFile: filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)))}
+	for _, entry := range entries {
+		if entry.Type != "event" {
+			continue
+		}
+		if entry.Anonymous {
+			return nil, fmt.Errorf("anonymous event %s of %s has no topic", entry.Name, name)
+		}
+		e, err := newEvent(entry)
+		if err != nil {
+			return nil, fmt.Errorf("event %s of %s: %w", entry.Name, name, err)
+		}
+		c.Events = append(c.Events, e)
+	}
+	if len(c.Events) == 0 {
+		return nil, fmt.Errorf("abi %s has no event", file)
+	}
+	sort.Slice(c.Events, func(i, j int) bool { return c.Events[i].Name < c.Events[j].Name })
+	for i := 1; i < len(c.Events); i++ {
+		if c.Events[i].Name == c.Events[i-1].Name {
+			return nil, fmt.Errorf("overloaded event %s of %s is not supported", c.Events[i].Name, name)
+		}
+	}
+	return c, nil
+}
+
+func newEvent(entry abiEntry) (*event, error) {
+	e := &event{Name: entry.Name}
+	argTypes := make([]string, 0, len(entry.Inputs))
+	names := make(map[string]bool, len(entry.Inputs))
+	for _, input := range entry.Inputs {
+		name := toCamelCase(input.Name)
+		if name == "" || names[name] {
+			return nil, fmt.Errorf("argument %q has no unique name", input.Name)
+		}
+		names[name] = true
+		goType, err := goTypeOf(input.Type, input.Indexed)
+		if err != nil {
+			return nil, err
+		}
+		e.Fields = append(e.Fields, &field{Name: name, Type: goType, Indexed: input.Indexed})
+		argTypes = append(argTypes, input.Type)
+	}
+	e.Signature = fmt.Sprintf("%s(%s)", entry.Name, strings.Join(argTypes, ","))
+	hash := sha3.NewLegacyKeccak256()
+	_, _ = hash.Write([]byte(e.Signature))
+	e.Topic = hex.EncodeToString(hash.Sum(nil))
+	return e, nil
+}
+
+// goTypeOf returns the go type an argument is unpacked into. The indexed arguments of dynamic types are unpacked
+// into the hash of their value, which is the topic.
+func goTypeOf(abiType string, indexed bool) (string, error) {
+	if strings.HasSuffix(abiType, "[]") {
+		if indexed {
+			return "common.Hash", nil
+		}
+		elem, err := goTypeOf(strings.TrimSuffix(abiType, "[]"), false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	}
+
+	switch {
+	case abiType == "address":
+		return "common.Address", nil
+	case abiType == "bool":
+		return "bool", nil
+	case abiType == "string" || abiType == "bytes":
+		if indexed {
+			return "common.Hash", nil
+		}
+		if abiType == "string" {
+			return "string", nil
+		}
+		return "[]byte", nil
+	case abiType == "bytes32":
+		return "common.Hash", nil
+	case strings.HasPrefix(abiType, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(abiType, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return "", fmt.Errorf("unsupported abi type %s", abiType)
+		}
+		return fmt.Sprintf("[%d]byte", size), nil
+	case strings.HasPrefix(abiType, "uint") || strings.HasPrefix(abiType, "int"):
+		prefix := "int"
+		if strings.HasPrefix(abiType, "uint") {
+			prefix = "uint"
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(abiType, prefix))
+		if err != nil || size < 8 || size > 256 || size%8 != 0 {
+			return "", fmt.Errorf("unsupported abi type %s", abiType)
+		}
+		switch size {
+		case 8, 16, 32, 64:
+			return fmt.Sprintf("%s%d", prefix, size), nil
+		default:
+			return "*big.Int", nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported abi type %s", abiType)
+	}
+}
+
+// toCamelCase converts an argument name to the field name the abi unpacking maps it to, as abi.ToCamelCase.
+func toCamelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if len(part) > 0 {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// lowerFirst lowers the first letter of a contract name, for the unexported names of its abi.
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerated checks the generated events are up to date with the abis, run go generate otherwise.
+func TestGenerated(t *testing.T) {
+	src, err := generate("events", "../abi")
+	require.NoError(t, err)
+	generated, err := os.ReadFile("../events_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(src))
+}
+
+func TestGoTypeOf(t *testing.T) {
+	for abiType, goType := range map[string]string{
+		"address":   "common.Address",
+		"bytes32":   "common.Hash",
+		"bytes4":    "[4]byte",
+		"bytes":     "[]byte",
+		"string":    "string",
+		"bool":      "bool",
+		"uint8":     "uint8",
+		"uint64":    "uint64",
+		"int32":     "int32",
+		"uint256":   "*big.Int",
+		"int128":    "*big.Int",
+		"uint256[]": "[]*big.Int",
+		"address[]": "[]common.Address",
+	} {
+		actual, err := goTypeOf(abiType, false)
+		assert.NoError(t, err, abiType)
+		assert.Equal(t, goType, actual, abiType)
+	}
+
+	// the topics of the indexed dynamic arguments are the hashes of their values.
+	for _, abiType := range []string{"bytes", "string", "uint256[]"} {
+		actual, err := goTypeOf(abiType, true)
+		assert.NoError(t, err, abiType)
+		assert.Equal(t, "common.Hash", actual, abiType)
+	}
+
+	for _, abiType := range []string{"uint7", "bytes33", "tuple", "fixed128x18"} {
+		_, err := goTypeOf(abiType, false)
+		assert.Error(t, err, abiType)
+	}
+}
+
+func TestToCamelCase(t *testing.T) {
+	assert.Equal(t, "TokenIds", toCamelCase("_tokenIds"))
+	assert.Equal(t, "L1Token", toCamelCase("l1Token"))
+	assert.Equal(t, "OldMaxReplayTimes", toCamelCase("oldMaxReplayTimes"))
+	assert.Equal(t, "ABC", toCamelCase("a_b_c"))
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
@@ -76,6 +77,10 @@ type Identity struct {
 	ProverVersion string `json:"prover_version"`
 	// Challenge unique challenge generated by manager
 	Challenge string `json:"challenge"`
+	// SessionPublicKey the compressed public key of the ephemeral key the prover derives the task data key of the
+	// session with, only set if the prover requests encrypted task data. Optional, so the hash of identities
+	// without it is unchanged.
+	SessionPublicKey string `json:"session_public_key,omitempty" rlp:"optional"`
 }
 
 // GenerateToken generates token
@@ -221,6 +226,9 @@ type TaskMsg struct {
 // ChunkTaskDetail is a type containing ChunkTask detail.
 type ChunkTaskDetail struct {
 	BlockHashes []common.Hash `json:"block_hashes"`
+	// BlockTraces are the traces of the blocks sorted by block number, empty if the coordinator does not fetch them,
+	// in which case provers fetch them from l2geth.
+	BlockTraces []*types.BlockTrace `json:"block_traces,omitempty"`
 }
 
 // BatchTaskDetail is a type containing BatchTask detail.
@@ -0,0 +1,74 @@
+package message
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/ecies"
+)
+
+// taskDataKeyDomain separates the task data key from other uses of the shared secret of a prover session.
+var taskDataKeyDomain = []byte("scroll-prover-task-data-key")
+
+// DeriveTaskDataKey derives the AES-256 key encrypting the task data of a prover session from the ECDH shared secret
+// of the ephemeral keys of both sides of the session, given the private key of one side and the compressed public key
+// of the other side.
+func DeriveTaskDataKey(priv *ecdsa.PrivateKey, peerPublicKey string) ([]byte, error) {
+	pub, err := crypto.DecompressPubkey(common.FromHex(peerPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid session public key: %w", err)
+	}
+	shared, err := ecies.ImportECDSA(priv).GenerateShared(ecies.ImportECDSAPublic(pub), 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate shared secret: %w", err)
+	}
+	return crypto.Keccak256(taskDataKeyDomain, shared), nil
+}
+
+// EncryptTaskData encrypts task data with AES-256-GCM, the result is the base64 encoded nonce followed by the ciphertext.
+func EncryptTaskData(key []byte, data string) (string, error) {
+	gcm, err := newTaskDataCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(data), nil)), nil
+}
+
+// DecryptTaskData decrypts task data encrypted by EncryptTaskData.
+func DecryptTaskData(key []byte, data string) (string, error) {
+	gcm, err := newTaskDataCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted task data: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted task data: too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt task data: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newTaskDataCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,54 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskDataEncryption(t *testing.T) {
+	proverKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	coordinatorKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	// both sides of the session derive the same key from their own private key and the public key of the other side.
+	proverSideKey, err := DeriveTaskDataKey(proverKey, common.Bytes2Hex(crypto.CompressPubkey(&coordinatorKey.PublicKey)))
+	assert.NoError(t, err)
+	coordinatorSideKey, err := DeriveTaskDataKey(coordinatorKey, common.Bytes2Hex(crypto.CompressPubkey(&proverKey.PublicKey)))
+	assert.NoError(t, err)
+	assert.Equal(t, proverSideKey, coordinatorSideKey)
+	assert.Len(t, proverSideKey, 32)
+
+	encrypted, err := EncryptTaskData(coordinatorSideKey, `{"block_hashes":["0x01"]}`)
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "block_hashes")
+	decrypted, err := DecryptTaskData(proverSideKey, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"block_hashes":["0x01"]}`, decrypted)
+
+	// tampered data and other keys are rejected.
+	otherKey, err := DeriveTaskDataKey(proverKey, common.Bytes2Hex(crypto.CompressPubkey(&proverKey.PublicKey)))
+	assert.NoError(t, err)
+	_, err = DecryptTaskData(otherKey, encrypted)
+	assert.Error(t, err)
+	_, err = DecryptTaskData(proverSideKey, encrypted[:len(encrypted)-4]+"AAAA")
+	assert.Error(t, err)
+
+	_, err = DeriveTaskDataKey(proverKey, "0x1234")
+	assert.Error(t, err)
+}
+
+func TestIdentityHashWithSessionPublicKey(t *testing.T) {
+	identity := &Identity{Challenge: "challenge", ProverName: "test", ProverVersion: "v1.0.0"}
+	hash, err := identity.Hash()
+	assert.NoError(t, err)
+
+	// the session public key is signed with the identity.
+	identity.SessionPublicKey = "02aabb"
+	hashWithSessionKey, err := identity.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hashWithSessionKey)
+}
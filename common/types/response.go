@@ -6,6 +6,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// ProverVersionDeprecationHeader is set on coordinator responses to provers whose version is deprecated,
+// the value tells the minimum version provers should upgrade to.
+const ProverVersionDeprecationHeader = "X-Prover-Version-Deprecation"
+
 // Response the response schema
 type Response struct {
 	ErrCode int         `json:"errcode"`
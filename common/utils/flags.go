@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/chains"
 )
 
 var (
@@ -15,6 +20,7 @@ var (
 		&MetricsEnabled,
 		&MetricsAddr,
 		&MetricsPort,
+		&CrashReportWebhookFlag,
 		&ServicePortFlag,
 		&Genesis,
 	}
@@ -72,6 +78,12 @@ var (
 		Category: "METRICS",
 		Value:    6060,
 	}
+	// CrashReportWebhookFlag is the url the reports of the panics recovered in long-running goroutines are posted to
+	CrashReportWebhookFlag = cli.StringFlag{
+		Name:    "crash-report.webhook",
+		Usage:   "URL the crash reports of the recovered panics are posted to, they are only logged if empty",
+		EnvVars: []string{"CRASH_REPORT_WEBHOOK"},
+	}
 	// ImportGenesisFlag import genesis batch during startup
 	ImportGenesisFlag = cli.BoolFlag{
 		Name:  "import-genesis",
@@ -84,6 +96,12 @@ var (
 		Usage: "Port that the service will listen on",
 		Value: 8080,
 	}
+	// NetworkFlag selects the network profile providing the defaults of chain IDs, contract addresses and start heights,
+	// only registered by the apps whose config has values provided by the profiles.
+	NetworkFlag = cli.StringFlag{
+		Name:  "network",
+		Usage: fmt.Sprintf("Network profile (%s) providing config defaults, values in the config file take precedence", strings.Join(chains.Names(), ", ")),
+	}
 	// Genesis is the genesis file
 	Genesis = cli.StringFlag{
 		Name:  "genesis",
@@ -91,3 +109,12 @@ var (
 		Value: "./conf/genesis.json",
 	}
 )
+
+// GetNetwork returns the network profile selected by the --network flag, or nil if no network is selected.
+func GetNetwork(ctx *cli.Context) (*chains.Network, error) {
+	name := ctx.String(NetworkFlag.Name)
+	if name == "" {
+		return nil, nil
+	}
+	return chains.Get(name)
+}
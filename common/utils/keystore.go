@@ -42,3 +42,17 @@ func LoadOrCreateKey(keystorePath string, keystorePassword string) (*ecdsa.Priva
 	}
 	return key.PrivateKey, nil
 }
+
+// LoadKey loads the private key of an existing keystore, unlike LoadOrCreateKey it never creates one.
+func LoadKey(keystorePath string, keystorePassword string) (*ecdsa.PrivateKey, error) {
+	keyjson, err := os.ReadFile(filepath.Clean(keystorePath))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keystore.DecryptKey(keyjson, keystorePassword)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
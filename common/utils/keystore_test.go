@@ -31,3 +31,21 @@ func TestLoadOrCreateKey(t *testing.T) {
 	assert.NoError(t, err)
 	os.RemoveAll(keyDir)
 }
+
+func TestLoadKey(t *testing.T) {
+	ksPath := filepath.Join(t.TempDir(), "my-key")
+	// a missing keystore is not created.
+	_, err := LoadKey(ksPath, "pwd")
+	assert.Error(t, err)
+	_, err = os.Stat(ksPath)
+	assert.True(t, os.IsNotExist(err))
+
+	key, err := LoadOrCreateKey(ksPath, "pwd")
+	assert.NoError(t, err)
+	loaded, err := LoadKey(ksPath, "pwd")
+	assert.NoError(t, err)
+	assert.Equal(t, key.D, loaded.D)
+
+	_, err = LoadKey(ksPath, "wrong")
+	assert.Error(t, err)
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/mattn/go-isatty"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/version"
 )
 
 // LogSetup is for setup logger
@@ -38,5 +41,9 @@ func LogSetup(ctx *cli.Context) error {
 	// Set log level
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
 	log.Root().SetHandler(glogger)
+	// the recovered panics are logged, and posted to the crash report webhook if any
+	crashreport.SetWebhook(ctx.String(CrashReportWebhookFlag.Name))
+	// operators confirm what is deployed from the startup log, or the version endpoint of the metrics server
+	log.Info("build info", version.Info(ctx.App.Name).LogCtx()...)
 	return nil
 }
@@ -0,0 +1,103 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Build information set with ldflags by the Makefiles of the services, see ldflags.mk. The vcs info embedded by
+// go build is used if they are unset, i.e. the commit and its time.
+var (
+	// GitCommit is the full git commit the binary is built from.
+	GitCommit = ""
+	// BuildTime is the UTC time the binary is built at, in RFC 3339.
+	BuildTime = ""
+	// Features is the comma separated list of the features enabled at build time, in addition to the build tags.
+	Features = ""
+)
+
+// startTime is the start time of the process.
+var startTime = time.Now()
+
+// BuildInfo is the build and runtime information of a running service, so operators can tell what is deployed.
+type BuildInfo struct {
+	Service   string    `json:"service"`
+	Version   string    `json:"version"`
+	GitCommit string    `json:"git_commit"`
+	Modified  bool      `json:"modified"` // the binary is built from a worktree with local changes
+	BuildTime string    `json:"build_time"`
+	GoVersion string    `json:"go_version"`
+	Platform  string    `json:"platform"`
+	Features  []string  `json:"features"`
+	StartTime time.Time `json:"start_time"`
+	UptimeSec uint64    `json:"uptime_sec"`
+}
+
+// Info returns the build and runtime information of the service.
+func Info(service string) *BuildInfo {
+	info := &BuildInfo{
+		Service:   service,
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		StartTime: startTime,
+		UptimeSec: uint64(time.Since(startTime).Seconds()),
+	}
+
+	var tags string
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			case "-tags":
+				tags = setting.Value
+			}
+		}
+	}
+	info.Features = buildFeatures(tags, Features)
+	return info
+}
+
+// LogCtx returns the build information as key value pairs for logging.
+func (i *BuildInfo) LogCtx() []interface{} {
+	return []interface{}{
+		"service", i.Service,
+		"version", i.Version,
+		"git commit", i.GitCommit,
+		"modified", i.Modified,
+		"build time", i.BuildTime,
+		"go version", i.GoVersion,
+		"platform", i.Platform,
+		"features", strings.Join(i.Features, ","),
+	}
+}
+
+// buildFeatures returns the sorted distinct features of the comma separated build tags and features.
+func buildFeatures(tags, features string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, feature := range strings.Split(tags+","+features, ",") {
+		feature = strings.TrimSpace(feature)
+		if feature == "" || seen[feature] {
+			continue
+		}
+		seen[feature] = true
+		result = append(result, feature)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,25 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFeatures(t *testing.T) {
+	assert.Equal(t, []string{}, buildFeatures("", ""))
+	assert.Equal(t, []string{"mock_prover", "mock_verifier"}, buildFeatures("mock_verifier,mock_prover", ""))
+	assert.Equal(t, []string{"gpu", "mock_verifier", "tracing"}, buildFeatures("gpu,mock_verifier", " tracing, gpu ,"))
+}
+
+func TestInfo(t *testing.T) {
+	info := Info("test")
+	assert.Equal(t, "test", info.Service)
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+	assert.Equal(t, startTime, info.StartTime)
+	assert.NotNil(t, info.Features)
+	assert.Len(t, info.LogCtx(), 16)
+}
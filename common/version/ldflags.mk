@@ -0,0 +1,7 @@
+# VERSION_LDFLAGS embeds the build info served by the version endpoint of the services, see build_info.go.
+# FEATURES lists the features enabled at build time in addition to the build tags, e.g. `make FEATURES=foo,bar`.
+GIT_COMMIT ?= $(shell git rev-parse HEAD 2>/dev/null)
+BUILD_TIME ?= $(shell date -u +%Y-%m-%dT%H:%M:%SZ)
+FEATURES ?=
+
+VERSION_LDFLAGS = -X scroll-tech/common/version.GitCommit=$(GIT_COMMIT) -X scroll-tech/common/version.BuildTime=$(BUILD_TIME) -X scroll-tech/common/version.Features=$(FEATURES)
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,17 +18,29 @@ import (
 	"gorm.io/gorm"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
 	"scroll-tech/coordinator/internal/config"
 	"scroll-tech/coordinator/internal/controller/api"
+	"scroll-tech/coordinator/internal/logic/snapshot"
 	"scroll-tech/coordinator/internal/route"
 )
 
 var app *cli.App
 
+// dbPool is the default db connection pool, every request of the provers queries the db.
+var dbPool = database.Pool{
+	Name:                "coordinator_api",
+	MaxOpenNum:          40,
+	MaxIdleNum:          10,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	// Set up coordinator app info.
 	app = cli.NewApp()
@@ -36,6 +49,7 @@ func init() {
 	app.Usage = "The Scroll L2 Coordinator"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Flags = append(app.Flags, apiFlags...)
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -50,7 +64,12 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
-	db, err := database.InitDB(cfg.DB)
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
+	db, err := database.InitDBWithPool(cfg.DB, dbPool)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
@@ -59,6 +78,14 @@ func action(ctx *cli.Context) error {
 			log.Error("can not close db connection", "error", err)
 		}
 	}()
+	tenantDBs := initTenantDBs(cfg)
+	defer func() {
+		for tenant, tenantDB := range tenantDBs {
+			if closeErr := database.CloseDB(tenantDB); closeErr != nil {
+				log.Error("can not close tenant db connection", "tenant", tenant, "error", closeErr)
+			}
+		}
+	}()
 
 	genesisPath := ctx.String(utils.Genesis.Name)
 	genesis, err := utils.ReadGenesis(genesisPath)
@@ -66,16 +93,33 @@ func action(ctx *cli.Context) error {
 		log.Crit("failed to read genesis", "genesis file", genesisPath, "error", err)
 	}
 
-	registry := prometheus.DefaultRegisterer
+	registry := metrics.Registerer()
 	observability.Server(ctx, db)
 
-	apiSrv := apiServer(ctx, cfg, genesis.Config, db, registry)
+	apiSrv := apiServer(ctx, cfg, genesis.Config, db, tenantDBs, registry)
+
+	// The scheduler snapshots are written for post-mortems, e.g. to see which provers held the tasks during an incident.
+	if cfg.Admin != nil && cfg.Admin.Snapshots != nil {
+		snapshotWriter := snapshot.NewWriter(cfg.Admin.Snapshots, api.Snapshotter)
+		go utils.LoopWithContext(ctx.Context, snapshotWriter.Interval(), snapshotWriter.Write)
+	}
 
 	log.Info(
 		"Start coordinator api successfully.",
 		"version", version.Version,
 	)
 
+	// Reload the verifying key registries of the tenants on SIGHUP, the provers keep their sessions.
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			if _, reloadErr := api.ReloadVerifierKeys(); reloadErr != nil {
+				log.Error("failed to reload verifying keys", "error", reloadErr)
+			}
+		}
+	}()
+
 	// Catch CTRL-C to ensure a graceful shutdown.
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
@@ -96,9 +140,10 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
-func apiServer(ctx *cli.Context, cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, reg prometheus.Registerer) *http.Server {
+func apiServer(ctx *cli.Context, cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, tenantDBs map[string]*gorm.DB, reg prometheus.Registerer) *http.Server {
 	router := gin.New()
 	api.InitController(cfg, chainCfg, db, reg)
+	api.InitTenants(cfg, chainCfg, tenantDBs, reg)
 	route.Route(router, cfg, reg)
 	port := ctx.String(httpPortFlag.Name)
 	srv := &http.Server{
@@ -123,3 +168,18 @@ func Run() {
 		os.Exit(1)
 	}
 }
+
+// initTenantDBs connects to the databases of the tenants, by tenant name.
+func initTenantDBs(cfg *config.Config) map[string]*gorm.DB {
+	dbs := make(map[string]*gorm.DB, len(cfg.Tenants))
+	for _, tenant := range cfg.Tenants {
+		pool := dbPool
+		pool.Name = dbPool.Name + "_" + tenant.Name
+		db, err := database.InitDBWithPool(tenant.DB, pool)
+		if err != nil {
+			log.Crit("failed to init tenant db connection", "tenant", tenant.Name, "err", err)
+		}
+		dbs[tenant.Name] = db
+	}
+	return dbs
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
+	"scroll-tech/common/clock"
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
@@ -21,6 +24,16 @@ import (
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the cron jobs run one at a time.
+var dbPool = database.Pool{
+	Name:                "coordinator_cron",
+	MaxOpenNum:          5,
+	MaxIdleNum:          2,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	// Set up coordinator app info.
 	app = cli.NewApp()
@@ -29,6 +42,7 @@ func init() {
 	app.Usage = "The Scroll L2 Coordinator cron"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -42,23 +56,49 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
 
 	subCtx, cancel := context.WithCancel(ctx.Context)
-	db, err := database.InitDB(cfg.DB)
+	db, err := database.InitDBWithPool(cfg.DB, dbPool)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
 
-	registry := prometheus.DefaultRegisterer
+	registry := metrics.Registerer()
 	observability.Server(ctx, db)
 
-	proofCollector := cron.NewCollector(subCtx, db, cfg, registry)
+	proofCollector := cron.NewCollector(subCtx, db, cfg, clock.New(), cfg.TenantRegisterer(registry, ""))
+	// the tasks of each tenant are collected from its own db.
+	tenantCollectors := make(map[string]*cron.Collector, len(cfg.Tenants))
+	tenantDBs := make(map[string]*gorm.DB, len(cfg.Tenants))
+	for _, tenant := range cfg.Tenants {
+		pool := dbPool
+		pool.Name = dbPool.Name + "_" + tenant.Name
+		tenantDB, dbErr := database.InitDBWithPool(tenant.DB, pool)
+		if dbErr != nil {
+			log.Crit("failed to init tenant db connection", "tenant", tenant.Name, "err", dbErr)
+		}
+		tenantDBs[tenant.Name] = tenantDB
+		tenantCollectors[tenant.Name] = cron.NewCollector(subCtx, tenantDB, cfg.ForTenant(tenant), clock.New(), cfg.TenantRegisterer(registry, tenant.Name))
+	}
 	defer func() {
 		proofCollector.Stop()
+		for _, tenantCollector := range tenantCollectors {
+			tenantCollector.Stop()
+		}
 		cancel()
 		if err = database.CloseDB(db); err != nil {
 			log.Error("can not close db connection", "error", err)
 		}
+		for tenant, tenantDB := range tenantDBs {
+			if closeErr := database.CloseDB(tenantDB); closeErr != nil {
+				log.Error("can not close tenant db connection", "tenant", tenant, "error", closeErr)
+			}
+		}
 	}()
 
 	log.Info(
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+)
+
+var app *cli.App
+
+var (
+	coordinatorURLFlag = cli.StringFlag{
+		Name:  "url",
+		Usage: "Base URL of the coordinator",
+		Value: "http://localhost:8390",
+	}
+	proversFlag = cli.IntFlag{
+		Name:  "provers",
+		Usage: "Number of simulated provers",
+		Value: 100,
+	}
+	taskTypeFlag = cli.StringFlag{
+		Name:  "task-type",
+		Usage: "Type of the proof tasks the provers ask for, chunk, batch or mixed (half of the provers each)",
+		Value: "chunk",
+	}
+	proverVersionFlag = cli.StringFlag{
+		Name:  "prover-version",
+		Usage: "Version the provers log in with",
+		Value: version.Version,
+	}
+	hardForkFlag = cli.StringFlag{
+		Name:  "hard-fork",
+		Usage: "Hard fork name the provers ask tasks of",
+	}
+	durationFlag = cli.DurationFlag{
+		Name:  "duration",
+		Usage: "Duration of the load test",
+		Value: 5 * time.Minute,
+	}
+	pollIntervalFlag = cli.DurationFlag{
+		Name:  "poll-interval",
+		Usage: "Interval between two polls of a prover without task",
+		Value: 5 * time.Second,
+	}
+	provingTimeDistFlag = cli.StringFlag{
+		Name:  "proving-time-dist",
+		Usage: "Distribution of the proving times, constant, uniform, normal or exponential",
+		Value: "normal",
+	}
+	provingTimeMeanFlag = cli.DurationFlag{
+		Name:  "proving-time-mean",
+		Usage: "Mean proving time",
+		Value: 30 * time.Second,
+	}
+	provingTimeStddevFlag = cli.DurationFlag{
+		Name:  "proving-time-stddev",
+		Usage: "Standard deviation of normal proving times, half width of uniform ones",
+		Value: 10 * time.Second,
+	}
+	failureRateFlag = cli.Float64Flag{
+		Name:  "failure-rate",
+		Usage: "Fraction of the tasks the provers report failed instead of submitting a proof",
+		Value: 0,
+	}
+	seedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Seed of the proving times and failures, the current time if 0",
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = "coordinator loadtest"
+	app.Usage = "Simulates provers against a coordinator and reports assignment fairness and latencies, the coordinator must run with the mock verifier"
+	app.Version = version.Version
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &coordinatorURLFlag, &proversFlag, &taskTypeFlag, &proverVersionFlag, &hardForkFlag, &durationFlag,
+		&pollIntervalFlag, &provingTimeDistFlag, &provingTimeMeanFlag, &provingTimeStddevFlag, &failureRateFlag, &seedFlag)
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+	app.Action = action
+}
+
+func action(ctx *cli.Context) error {
+	provingTime, err := newProvingTimeDist(ctx.String(provingTimeDistFlag.Name), ctx.Duration(provingTimeMeanFlag.Name), ctx.Duration(provingTimeStddevFlag.Name))
+	if err != nil {
+		return err
+	}
+	proofTypes, err := parseTaskType(ctx.String(taskTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	numProvers := ctx.Int(proversFlag.Name)
+	if numProvers <= 0 {
+		return fmt.Errorf("invalid number of provers: %d", numProvers)
+	}
+	seed := ctx.Int64(seedFlag.Name)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx.Context, ctx.Duration(durationFlag.Name))
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			log.Info("interrupted, stopping the provers")
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	stats := newLoadStats()
+	var wg sync.WaitGroup
+	log.Info("starting simulated provers", "provers", numProvers, "coordinator", ctx.String(coordinatorURLFlag.Name), "seed", seed)
+	for i := 0; i < numProvers; i++ {
+		p, err := newSimProver(&simProverConfig{
+			name:           fmt.Sprintf("loadtest_prover_%d", i),
+			version:        ctx.String(proverVersionFlag.Name),
+			coordinatorURL: ctx.String(coordinatorURLFlag.Name),
+			proofType:      proofTypes[i%len(proofTypes)],
+			hardForkName:   ctx.String(hardForkFlag.Name),
+			pollInterval:   ctx.Duration(pollIntervalFlag.Name),
+			provingTime:    provingTime,
+			failureRate:    ctx.Float64(failureRateFlag.Name),
+			rng:            rand.New(rand.NewSource(seed + int64(i))), //nolint:gosec
+		}, stats)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.run(runCtx)
+		}()
+	}
+	wg.Wait()
+
+	stats.report(os.Stdout, numProvers)
+	return nil
+}
+
+func parseTaskType(taskType string) ([]message.ProofType, error) {
+	switch taskType {
+	case "chunk":
+		return []message.ProofType{message.ProofTypeChunk}, nil
+	case "batch":
+		return []message.ProofType{message.ProofTypeBatch}, nil
+	case "mixed":
+		return []message.ProofType{message.ProofTypeChunk, message.ProofTypeBatch}, nil
+	default:
+		return nil, fmt.Errorf("unknown task type %s, expected chunk, batch or mixed", taskType)
+	}
+}
+
+// Run the coordinator load test.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
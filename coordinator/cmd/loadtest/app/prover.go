@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	ctypes "scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/types"
+)
+
+// errUnauthorized is returned when the login token of a prover is rejected, e.g. expired, the prover logs in again.
+var errUnauthorized = errors.New("unauthorized")
+
+// provingTimeDist samples the time a simulated prover takes to prove a task.
+type provingTimeDist struct {
+	kind   string
+	mean   time.Duration
+	stddev time.Duration
+}
+
+func newProvingTimeDist(kind string, mean, stddev time.Duration) (*provingTimeDist, error) {
+	switch kind {
+	case "constant", "uniform", "normal", "exponential":
+	default:
+		return nil, fmt.Errorf("unknown proving time distribution %s, expected constant, uniform, normal or exponential", kind)
+	}
+	if mean < 0 || stddev < 0 {
+		return nil, errors.New("proving time mean and stddev must not be negative")
+	}
+	return &provingTimeDist{kind: kind, mean: mean, stddev: stddev}, nil
+}
+
+// sample returns a proving time, never negative.
+func (d *provingTimeDist) sample(rng *rand.Rand) time.Duration {
+	var t float64
+	switch d.kind {
+	case "constant":
+		t = float64(d.mean)
+	case "uniform":
+		t = float64(d.mean) + (2*rng.Float64()-1)*float64(d.stddev)
+	case "normal":
+		t = float64(d.mean) + rng.NormFloat64()*float64(d.stddev)
+	case "exponential":
+		t = rng.ExpFloat64() * float64(d.mean)
+	}
+	return time.Duration(math.Max(t, 0))
+}
+
+type simProverConfig struct {
+	name           string
+	version        string
+	coordinatorURL string
+	proofType      message.ProofType
+	hardForkName   string
+	pollInterval   time.Duration
+	provingTime    *provingTimeDist
+	failureRate    float64
+	rng            *rand.Rand
+}
+
+// simProver logs in, polls tasks and submits fake proofs like a prover does, without proving.
+type simProver struct {
+	cfg       *simProverConfig
+	privKey   *ecdsa.PrivateKey
+	publicKey string
+	client    *resty.Client
+	stats     *loadStats
+
+	token string
+}
+
+func newSimProver(cfg *simProverConfig, stats *loadStats) (*simProver, error) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate prover key: %w", err)
+	}
+	return &simProver{
+		cfg:       cfg,
+		privKey:   privKey,
+		publicKey: fmt.Sprintf("%x", crypto.CompressPubkey(&privKey.PublicKey)),
+		client:    resty.New().SetBaseURL(cfg.coordinatorURL).SetTimeout(30 * time.Second),
+		stats:     stats,
+	}, nil
+}
+
+func (p *simProver) run(ctx context.Context) {
+	// the assignment wait of a task is the time the prover was idle, polling, before it got the task.
+	idleSince := time.Now()
+	for ctx.Err() == nil {
+		if p.token == "" {
+			if err := p.login(ctx); err != nil {
+				log.Warn("simulated prover failed to login", "prover", p.cfg.name, "err", err)
+				p.wait(ctx, p.cfg.pollInterval)
+				continue
+			}
+		}
+
+		task, err := p.getTask(ctx)
+		if errors.Is(err, errUnauthorized) {
+			p.token = ""
+			continue
+		}
+		if err != nil {
+			log.Warn("simulated prover failed to get task", "prover", p.cfg.name, "err", err)
+		}
+		if task == nil {
+			p.wait(ctx, p.cfg.pollInterval)
+			continue
+		}
+		p.stats.recordAssignment(p.publicKey, time.Since(idleSince))
+
+		p.wait(ctx, p.cfg.provingTime.sample(p.cfg.rng))
+		if ctx.Err() != nil {
+			return
+		}
+		failed := p.cfg.rng.Float64() < p.cfg.failureRate
+		if err = p.submitProof(ctx, task, failed); errors.Is(err, errUnauthorized) {
+			p.token = ""
+		} else if err != nil {
+			log.Warn("simulated prover failed to submit proof", "prover", p.cfg.name, "task id", task.TaskID, "err", err)
+		}
+		idleSince = time.Now()
+	}
+}
+
+func (p *simProver) wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+func (p *simProver) login(ctx context.Context) error {
+	start := time.Now()
+	var challenge struct {
+		Token string `json:"token"`
+	}
+	if err := p.call(ctx, http.MethodGet, "/coordinator/v1/challenge", "", nil, &challenge); err != nil {
+		p.stats.recordCall(callLogin, time.Since(start), false)
+		return fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	authMsg := message.AuthMsg{
+		Identity: &message.Identity{
+			Challenge:     challenge.Token,
+			ProverName:    p.cfg.name,
+			ProverVersion: p.cfg.version,
+		},
+	}
+	if err := authMsg.SignWithKey(p.privKey); err != nil {
+		return fmt.Errorf("failed to sign login message: %w", err)
+	}
+	body := &types.LoginParameter{
+		Message: types.Message{
+			Challenge:     authMsg.Identity.Challenge,
+			ProverName:    authMsg.Identity.ProverName,
+			ProverVersion: authMsg.Identity.ProverVersion,
+		},
+		Signature: authMsg.Signature,
+	}
+	var login types.LoginSchema
+	err := p.call(ctx, http.MethodPost, "/coordinator/v1/login", challenge.Token, body, &login)
+	p.stats.recordCall(callLogin, time.Since(start), err == nil)
+	if err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+	p.token = login.Token
+	return nil
+}
+
+// getTask returns nil without error if there is no task to assign.
+func (p *simProver) getTask(ctx context.Context) (*types.GetTaskSchema, error) {
+	start := time.Now()
+	body := &types.GetTaskParameter{
+		TaskType:     int(p.cfg.proofType),
+		HardForkName: p.cfg.hardForkName,
+	}
+	var task types.GetTaskSchema
+	err := p.call(ctx, http.MethodPost, "/coordinator/v1/get_task", p.token, body, &task)
+	var respErr *responseError
+	// provers deferred by the assignment fairness are rate limited, they wait for a task too.
+	if errors.As(err, &respErr) && (respErr.errCode == ctypes.ErrCoordinatorNoTaskAvailable || respErr.errCode == ctypes.ErrCoordinatorRateLimited) {
+		p.stats.recordCall(callGetTaskEmpty, time.Since(start), true)
+		return nil, nil
+	}
+	p.stats.recordCall(callGetTask, time.Since(start), err == nil)
+	if err != nil {
+		return nil, err
+	}
+	if task.TaskID == "" {
+		return nil, nil
+	}
+	return &task, nil
+}
+
+func (p *simProver) submitProof(ctx context.Context, task *types.GetTaskSchema, failed bool) error {
+	body := &types.SubmitProofParameter{
+		UUID:     task.UUID,
+		TaskID:   task.TaskID,
+		TaskType: task.TaskType,
+		Status:   int(message.StatusOk),
+	}
+	if failed {
+		body.Status = int(message.StatusProofError)
+		body.FailureType = int(message.ProofFailureNoPanic)
+		body.FailureMsg = "simulated proving failure"
+	} else {
+		proof := make([]byte, 256)
+		p.cfg.rng.Read(proof)
+		var encoded []byte
+		var err error
+		switch message.ProofType(task.TaskType) {
+		case message.ProofTypeChunk:
+			encoded, err = json.Marshal(&message.ChunkProof{Proof: proof})
+		case message.ProofTypeBatch:
+			encoded, err = json.Marshal(&message.BatchProof{Proof: proof})
+		default:
+			return fmt.Errorf("unknown task type %d", task.TaskType)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode proof: %w", err)
+		}
+		body.Proof = string(encoded)
+	}
+
+	start := time.Now()
+	err := p.call(ctx, http.MethodPost, "/coordinator/v1/submit_proof", p.token, body, nil)
+	p.stats.recordCall(callSubmitProof, time.Since(start), err == nil)
+	if err == nil {
+		p.stats.recordSubmission(p.publicKey, failed)
+	}
+	return err
+}
+
+// responseError is an error code returned by the coordinator.
+type responseError struct {
+	errCode int
+	errMsg  string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("errcode %d: %s", e.errCode, e.errMsg)
+}
+
+// call sends a request to the coordinator, and decodes the data of the response into result if not nil.
+func (p *simProver) call(ctx context.Context, method, path, token string, body, result interface{}) error {
+	var resp struct {
+		ErrCode int             `json:"errcode"`
+		ErrMsg  string          `json:"errmsg"`
+		Data    json.RawMessage `json:"data"`
+	}
+	req := p.client.R().SetContext(ctx).SetResult(&resp).SetError(&resp)
+	if token != "" {
+		req.SetAuthToken(token)
+	}
+	if body != nil {
+		req.SetBody(body)
+	}
+	httpResp, err := req.Execute(method, path)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("unexpected http status %d", httpResp.StatusCode())
+	}
+	if resp.ErrCode == ctypes.ErrJWTTokenExpired || resp.ErrCode == ctypes.ErrJWTCommonErr {
+		return fmt.Errorf("%w: %s", errUnauthorized, resp.ErrMsg)
+	}
+	if resp.ErrCode != ctypes.Success {
+		return &responseError{errCode: resp.ErrCode, errMsg: resp.ErrMsg}
+	}
+	if result != nil && len(resp.Data) > 0 {
+		if err = json.Unmarshal(resp.Data, result); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return nil
+}
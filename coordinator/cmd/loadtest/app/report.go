@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Coordinator calls whose latencies are reported.
+const (
+	callLogin        = "login"
+	callGetTask      = "get_task"
+	callGetTaskEmpty = "get_task (no task)"
+	callSubmitProof  = "submit_proof"
+)
+
+var reportedCalls = []string{callLogin, callGetTask, callGetTaskEmpty, callSubmitProof}
+
+type callStats struct {
+	latencies []time.Duration
+	failures  int
+}
+
+type proverStats struct {
+	assigned  int
+	submitted int
+	failed    int
+}
+
+// loadStats collects the results of the simulated provers.
+type loadStats struct {
+	mu              sync.Mutex
+	calls           map[string]*callStats
+	provers         map[string]*proverStats // by public key
+	assignmentWaits []time.Duration
+}
+
+func newLoadStats() *loadStats {
+	s := &loadStats{
+		calls:   make(map[string]*callStats),
+		provers: make(map[string]*proverStats),
+	}
+	for _, call := range reportedCalls {
+		s.calls[call] = &callStats{}
+	}
+	return s
+}
+
+func (s *loadStats) recordCall(call string, latency time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !ok {
+		s.calls[call].failures++
+		return
+	}
+	s.calls[call].latencies = append(s.calls[call].latencies, latency)
+}
+
+func (s *loadStats) recordAssignment(publicKey string, wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proverLocked(publicKey).assigned++
+	s.assignmentWaits = append(s.assignmentWaits, wait)
+}
+
+func (s *loadStats) recordSubmission(publicKey string, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if failed {
+		s.proverLocked(publicKey).failed++
+	} else {
+		s.proverLocked(publicKey).submitted++
+	}
+}
+
+func (s *loadStats) proverLocked(publicKey string) *proverStats {
+	stats, ok := s.provers[publicKey]
+	if !ok {
+		stats = &proverStats{}
+		s.provers[publicKey] = stats
+	}
+	return stats
+}
+
+// report writes the latency percentiles of the calls and assignments, and the fairness of the assignments over
+// the provers. Provers which never got a task count with zero assignments.
+func (s *loadStats) report(w io.Writer, numProvers int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CALL\tCOUNT\tFAILURES\tP50\tP90\tP99\tMAX")
+	for _, call := range reportedCalls {
+		stats := s.calls[call]
+		writeLatencyRow(tw, call, stats.latencies, stats.failures)
+	}
+	writeLatencyRow(tw, "assignment wait", s.assignmentWaits, 0)
+	_ = tw.Flush()
+
+	assigned := make([]int, 0, numProvers)
+	var submitted, failed int
+	for _, stats := range s.provers {
+		assigned = append(assigned, stats.assigned)
+		submitted += stats.submitted
+		failed += stats.failed
+	}
+	for len(assigned) < numProvers {
+		assigned = append(assigned, 0)
+	}
+	fairness := assignmentFairness(assigned)
+	_, _ = fmt.Fprintf(w, "\nassigned tasks: %d, submitted proofs: %d, reported failures: %d\n", len(s.assignmentWaits), submitted, failed)
+	_, _ = fmt.Fprintf(w, "tasks per prover: min %d, max %d, mean %.2f, stddev %.2f, jain fairness index %.3f\n",
+		fairness.min, fairness.max, fairness.mean, fairness.stddev, fairness.jainIndex)
+}
+
+func writeLatencyRow(w io.Writer, name string, latencies []time.Duration, failures int) {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%v\t%v\t%v\n", name, len(sorted), failures,
+		percentile(sorted, 50), percentile(sorted, 90), percentile(sorted, 99), percentile(sorted, 100))
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies, 0 if there are none.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+type fairnessStats struct {
+	min, max     int
+	mean, stddev float64
+	// jainIndex is (sum x)^2 / (n * sum x^2), 1 if all provers got as many tasks, 1/n if a single prover got all.
+	jainIndex float64
+}
+
+func assignmentFairness(assigned []int) *fairnessStats {
+	stats := &fairnessStats{}
+	if len(assigned) == 0 {
+		return stats
+	}
+	var sum, sumSquares float64
+	stats.min, stats.max = assigned[0], assigned[0]
+	for _, n := range assigned {
+		if n < stats.min {
+			stats.min = n
+		}
+		if n > stats.max {
+			stats.max = n
+		}
+		sum += float64(n)
+		sumSquares += float64(n) * float64(n)
+	}
+	count := float64(len(assigned))
+	stats.mean = sum / count
+	stats.stddev = math.Sqrt(math.Max(sumSquares/count-stats.mean*stats.mean, 0))
+	if sumSquares > 0 {
+		stats.jainIndex = sum * sum / (count * sumSquares)
+	}
+	return stats
+}
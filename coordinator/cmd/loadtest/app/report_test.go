@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.Zero(t, percentile(nil, 50))
+
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	assert.Equal(t, time.Millisecond, percentile(sorted, 0))
+}
+
+func TestAssignmentFairness(t *testing.T) {
+	even := assignmentFairness([]int{3, 3, 3, 3})
+	assert.Equal(t, 3, even.min)
+	assert.Equal(t, 3, even.max)
+	assert.InDelta(t, 1, even.jainIndex, 1e-9)
+	assert.InDelta(t, 0, even.stddev, 1e-9)
+
+	// a single prover got all the tasks.
+	single := assignmentFairness([]int{8, 0, 0, 0})
+	assert.Equal(t, 0, single.min)
+	assert.InDelta(t, 0.25, single.jainIndex, 1e-9)
+	assert.InDelta(t, 2, single.mean, 1e-9)
+
+	assert.Zero(t, assignmentFairness([]int{0, 0}).jainIndex)
+}
+
+func TestProvingTimeDist(t *testing.T) {
+	_, err := newProvingTimeDist("pareto", time.Second, 0)
+	assert.Error(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	constant, err := newProvingTimeDist("constant", time.Second, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, constant.sample(rng))
+
+	uniform, err := newProvingTimeDist("uniform", 10*time.Second, 2*time.Second)
+	assert.NoError(t, err)
+	normal, err := newProvingTimeDist("normal", time.Second, 10*time.Second)
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		sample := uniform.sample(rng)
+		assert.True(t, sample >= 8*time.Second && sample <= 12*time.Second)
+		assert.True(t, normal.sample(rng) >= 0)
+	}
+}
+
+func TestLoadStatsReport(t *testing.T) {
+	stats := newLoadStats()
+	stats.recordCall(callLogin, 10*time.Millisecond, true)
+	stats.recordCall(callSubmitProof, 0, false)
+	stats.recordAssignment("p1", time.Second)
+	stats.recordSubmission("p1", false)
+	stats.recordAssignment("p1", 2*time.Second)
+	stats.recordSubmission("p1", true)
+
+	var out bytes.Buffer
+	stats.report(&out, 2)
+	assert.Contains(t, out.String(), "assigned tasks: 2, submitted proofs: 1, reported failures: 1")
+	// the prover without tasks counts.
+	assert.Contains(t, out.String(), "tasks per prover: min 0, max 2, mean 1.00, stddev 1.00, jain fairness index 0.500")
+}
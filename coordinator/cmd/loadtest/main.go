@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/coordinator/cmd/loadtest/app"
+
+func main() {
+	app.Run()
+}
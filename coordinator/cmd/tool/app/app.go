@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+)
+
+var app *cli.App
+
+var (
+	taskTypeFlag = cli.StringFlag{
+		Name:  "task-type",
+		Usage: "Type of the proof tasks, chunk or batch",
+		Value: "chunk",
+	}
+	hashFlag = cli.StringFlag{
+		Name:     "hash",
+		Usage:    "Hash of the chunk or batch",
+		Required: true,
+	}
+	publicKeyFlag = cli.StringFlag{
+		Name:     "public-key",
+		Usage:    "Public key of the prover",
+		Required: true,
+	}
+	sinceFlag = cli.DurationFlag{
+		Name:  "since",
+		Usage: "Time window of the exported stats, e.g. 24h",
+		Value: 24 * time.Hour,
+	}
+	formatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Format of the exported stats, csv or json",
+		Value: "csv",
+	}
+	limitFlag = cli.IntFlag{
+		Name:  "limit",
+		Usage: "Max number of listed entries",
+		Value: 100,
+	}
+)
+
+func init() {
+	app = cli.NewApp()
+	app.Name = "coordinator tool"
+	app.Usage = "The Scroll L2 Coordinator operator tool, it works on the database of the coordinator"
+	app.Version = version.Version
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+
+	app.Commands = []*cli.Command{
+		{
+			Name:   "pending-tasks",
+			Usage:  "List the chunks or batches which are not proven yet.",
+			Action: listPendingTasks,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &taskTypeFlag, &limitFlag},
+		},
+		{
+			Name:   "requeue",
+			Usage:  "Make a chunk or batch which is not verified assignable to provers again, e.g. after it failed.",
+			Action: requeueTask,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &taskTypeFlag, &hashFlag},
+		},
+		{
+			Name:   "prover-submissions",
+			Usage:  "Inspect the latest tasks of a prover with their proving statuses and failures.",
+			Action: listProverSubmissions,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &publicKeyFlag, &limitFlag},
+		},
+		{
+			Name:   "export-stats",
+			Usage:  "Export the verification stats of the prover tasks by task type, prover version and proving status.",
+			Action: exportStats,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &sinceFlag, &formatFlag},
+		},
+		{
+			Name:   "proving-times",
+			Usage:  "Export the percentiles of the proving times reported for the verified chunks by prover version and chunk size.",
+			Action: exportProvingTimes,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &sinceFlag, &formatFlag},
+		},
+	}
+}
+
+// Run the coordinator tool.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
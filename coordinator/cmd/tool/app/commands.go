@@ -0,0 +1,231 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// withDB runs fn with a connection to the db of the coordinator config.
+func withDB(ctx *cli.Context, fn func(db *gorm.DB) error) error {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", cfgFile, err)
+	}
+	db, err := database.InitDB(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("failed to init db connection: %w", err)
+	}
+	defer func() {
+		if err := database.CloseDB(db); err != nil {
+			log.Error("failed to close db connection", "error", err)
+		}
+	}()
+	return fn(db)
+}
+
+func parseTaskType(ctx *cli.Context) (message.ProofType, error) {
+	switch taskType := ctx.String(taskTypeFlag.Name); taskType {
+	case "chunk":
+		return message.ProofTypeChunk, nil
+	case "batch":
+		return message.ProofTypeBatch, nil
+	default:
+		return message.ProofTypeUndefined, fmt.Errorf("unknown task type %q, expected chunk or batch", taskType)
+	}
+}
+
+func taskTypeName(taskType message.ProofType) string {
+	switch taskType {
+	case message.ProofTypeChunk:
+		return "chunk"
+	case message.ProofTypeBatch:
+		return "batch"
+	default:
+		return strconv.Itoa(int(taskType))
+	}
+}
+
+// listPendingTasks prints the unassigned and assigned chunks or batches with their attempts.
+func listPendingTasks(ctx *cli.Context) error {
+	taskType, err := parseTaskType(ctx)
+	if err != nil {
+		return err
+	}
+	return withDB(ctx, func(db *gorm.DB) error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "INDEX\tHASH\tPROVING STATUS\tACTIVE ATTEMPTS\tTOTAL ATTEMPTS\tASSIGNED AT")
+		limit := ctx.Int(limitFlag.Name)
+		if taskType == message.ProofTypeChunk {
+			chunks, err := orm.NewChunk(db).GetPendingChunks(ctx.Context, limit)
+			if err != nil {
+				return err
+			}
+			for _, chunk := range chunks {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", chunk.Index, chunk.Hash, types.ProvingStatus(chunk.ProvingStatus),
+					chunk.ActiveAttempts, chunk.TotalAttempts, formatTime(chunk.ProverAssignedAt))
+			}
+		} else {
+			batches, err := orm.NewBatch(db).GetPendingBatches(ctx.Context, limit)
+			if err != nil {
+				return err
+			}
+			for _, batch := range batches {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", batch.Index, batch.Hash, types.ProvingStatus(batch.ProvingStatus),
+					batch.ActiveAttempts, batch.TotalAttempts, formatTime(batch.ProverAssignedAt))
+			}
+		}
+		return w.Flush()
+	})
+}
+
+// requeueTask makes a chunk or batch assignable to provers again.
+func requeueTask(ctx *cli.Context) error {
+	taskType, err := parseTaskType(ctx)
+	if err != nil {
+		return err
+	}
+	hash := ctx.String(hashFlag.Name)
+	return withDB(ctx, func(db *gorm.DB) error {
+		var requeued bool
+		if taskType == message.ProofTypeChunk {
+			requeued, err = orm.NewChunk(db).RequeueChunk(ctx.Context, hash)
+		} else {
+			requeued, err = orm.NewBatch(db).RequeueBatch(ctx.Context, hash)
+		}
+		if err != nil {
+			return err
+		}
+		if !requeued {
+			return fmt.Errorf("no %s of hash %s which is not verified", taskTypeName(taskType), hash)
+		}
+		log.Info("requeued proof task", "task type", taskTypeName(taskType), "hash", hash)
+		return nil
+	})
+}
+
+// listProverSubmissions prints the latest tasks of a prover.
+func listProverSubmissions(ctx *cli.Context) error {
+	publicKey := ctx.String(publicKeyFlag.Name)
+	return withDB(ctx, func(db *gorm.DB) error {
+		proverTasks, err := orm.NewProverTask(db).GetProverTasksByPublicKey(ctx.Context, publicKey, ctx.Int(limitFlag.Name))
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "UUID\tTASK TYPE\tTASK ID\tPROVER VERSION\tPROVING STATUS\tFAILURE TYPE\tASSIGNED AT\tUPDATED AT")
+		for _, proverTask := range proverTasks {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", proverTask.UUID, taskTypeName(message.ProofType(proverTask.TaskType)), proverTask.TaskID,
+				proverTask.ProverVersion, types.ProverProveStatus(proverTask.ProvingStatus), types.ProverTaskFailureType(proverTask.FailureType),
+				formatTime(&proverTask.AssignedAt), formatTime(&proverTask.UpdatedAt))
+		}
+		return w.Flush()
+	})
+}
+
+// exportStats writes the verification stats of the prover tasks assigned in the time window to stdout.
+func exportStats(ctx *cli.Context) error {
+	format := ctx.String(formatFlag.Name)
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+	since := utils.NowUTC().Add(-ctx.Duration(sinceFlag.Name))
+	return withDB(ctx, func(db *gorm.DB) error {
+		stats, err := orm.NewProverTask(db).GetProverTaskStats(ctx.Context, since)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stats)
+		}
+
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write([]string{"task_type", "prover_version", "proving_status", "count", "avg_duration_sec"}); err != nil {
+			return err
+		}
+		for _, stat := range stats {
+			record := []string{
+				taskTypeName(message.ProofType(stat.TaskType)),
+				stat.ProverVersion,
+				types.ProverProveStatus(stat.ProvingStatus).String(),
+				strconv.FormatUint(stat.Count, 10),
+				strconv.FormatFloat(stat.AvgDurationSec, 'f', 1, 64),
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
+
+func exportProvingTimes(ctx *cli.Context) error {
+	format := ctx.String(formatFlag.Name)
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+	since := utils.NowUTC().Add(-ctx.Duration(sinceFlag.Name))
+	return withDB(ctx, func(db *gorm.DB) error {
+		stats, err := orm.NewProverTask(db).GetChunkProvingTimeStats(ctx.Context, since)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stats)
+		}
+
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write([]string{"prover_version", "size_class", "count", "p50_ms", "p90_ms", "p99_ms", "avg_l2_tx_num", "avg_l2_tx_gas"}); err != nil {
+			return err
+		}
+		for _, stat := range stats {
+			record := []string{
+				stat.ProverVersion,
+				stat.SizeClass,
+				strconv.FormatUint(stat.Count, 10),
+				strconv.FormatFloat(stat.P50Ms, 'f', 0, 64),
+				strconv.FormatFloat(stat.P90Ms, 'f', 0, 64),
+				strconv.FormatFloat(stat.P99Ms, 'f', 0, 64),
+				strconv.FormatFloat(stat.AvgL2TxNum, 'f', 1, 64),
+				strconv.FormatFloat(stat.AvgL2TxGas, 'f', 0, 64),
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
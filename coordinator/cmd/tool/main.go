@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/coordinator/cmd/tool/app"
+
+func main() {
+	app.Run()
+}
@@ -2,10 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"scroll-tech/common/chains"
 	"scroll-tech/common/database"
+	"scroll-tech/common/secrets"
+	"scroll-tech/common/types/message"
 )
 
 // ProverManager loads sequencer configuration items.
@@ -21,16 +29,151 @@ type ProverManager struct {
 	BatchCollectionTimeSec int `json:"batch_collection_time_sec"`
 	// ChunkCollectionTimeSec chunk Proof collection time (in seconds).
 	ChunkCollectionTimeSec int `json:"chunk_collection_time_sec"`
-	// Max number of workers in verifier worker pool
+	// Max number of proofs verified concurrently, defaults to the number of CPUs.
 	MaxVerifierWorkers int `json:"max_verifier_workers"`
-	// MinProverVersion is the minimum version of the prover that is required.
+	// MinProverVersion is the minimum version of the prover that is required, enforced at login and get_task.
 	MinProverVersion string `json:"min_prover_version"`
+	// DeprecatedProverVersion is the minimum version of the prover that is not deprecated. Provers below it are served,
+	// but warned with the X-Prover-Version-Deprecation response header, empty disables the warning.
+	DeprecatedProverVersion string `json:"deprecated_prover_version,omitempty"`
+	// FinalizationTargetSec is the target time (in seconds) from the creation of a chunk or batch to the finalization
+	// of its batch. When set, provers are sent the time by which proofs meet the target to prioritize tasks, the task
+	// deadlines stay at the end of the collection time. 0 disables it.
+	FinalizationTargetSec int `json:"finalization_target_sec,omitempty"`
+	// SessionCleanupIntervalSec is the interval (in seconds) of the orphaned session cleanup, defaults to 60 seconds.
+	SessionCleanupIntervalSec int `json:"session_cleanup_interval_sec,omitempty"`
+	// SessionCleanupGraceSec is the time (in seconds) prover tasks are left alone after their deadline, and tasks after
+	// their last update, before being considered orphaned, defaults to 300 seconds.
+	SessionCleanupGraceSec int `json:"session_cleanup_grace_sec,omitempty"`
+	// AssignmentFairness defers the tasks of provers which got more than their share while tasks are scarce, tasks are
+	// assigned first come first served if nil.
+	AssignmentFairness *AssignmentFairness `json:"assignment_fairness,omitempty"`
+	// TaskGeneration bounds the chunks and batches tasks are assigned for to a budget of outstanding tasks, tasks are
+	// assigned for all the unproven chunks and batches if nil.
+	TaskGeneration *TaskGeneration `json:"task_generation,omitempty"`
+	// TaskPrefetch lets provers reserve their next task while proving the assigned one, disabled if nil.
+	TaskPrefetch *TaskPrefetch `json:"task_prefetch,omitempty"`
+	// TaskPayloads serves the task data of large tasks through a resumable download rather than inline in get_task,
+	// disabled if nil.
+	TaskPayloads *TaskPayloads `json:"task_payloads,omitempty"`
+	// MaxAssignedTasksPerProver is the number of tasks a prover is assigned at the same time, defaults to 1.
+	MaxAssignedTasksPerProver int `json:"max_assigned_tasks_per_prover,omitempty"`
+	// ConfigOverrides reloads the overrides of select parameters from the database, the parameters are set by the
+	// config file only if nil.
+	ConfigOverrides *ConfigOverrides `json:"config_overrides,omitempty"`
+}
+
+// ConfigOverrides configures the reloading of the coordinator_config_override table. Its rows override, by name, the
+// chunk_collection_time_sec and batch_collection_time_sec, the max_assigned_tasks_per_prover and the
+// verifier_fork_name of the config file, so that operators tune the scheduler during incidents without restarts.
+// Deleting a row restores the config file value.
+type ConfigOverrides struct {
+	// ReloadIntervalSec is the interval (in seconds) the overrides are reloaded at, defaults to 30 seconds.
+	ReloadIntervalSec int `json:"reload_interval_sec,omitempty"`
+}
+
+// TaskPayloads configures the download of large task data. The task data of a task at least MinSizeBytes large is
+// stored in Dir and left out of the get_task response, the prover downloads it from task_payload with Range requests
+// and resumes an interrupted download where it stopped. The coordinator replicas must share Dir.
+type TaskPayloads struct {
+	// Dir is the directory the task data is stored in.
+	Dir string `json:"dir"`
+	// MinSizeBytes is the size of the task data from which it is downloaded, defaults to 16 MiB.
+	MinSizeBytes int `json:"min_size_bytes,omitempty"`
+	// RetentionSec is the time (in seconds) the task data is kept for, defaults to 1 day. It should cover the proof
+	// collection time of the tasks.
+	RetentionSec int `json:"retention_sec,omitempty"`
+}
+
+// TaskPrefetch configures the reservations of the next task of provers. A prover asking for a task with prefetch set
+// while proving its assigned one is sent an unassigned task reserved for it, so it can download the witness ahead.
+// The next get_task of the prover assigns it the reserved task, unless the reservation expired meanwhile.
+type TaskPrefetch struct {
+	// ReservationTTLSec is the time (in seconds) a task is reserved for a prover, defaults to 60 seconds. It should
+	// cover the time from the end of the current proof to the next get_task, not the proof itself.
+	ReservationTTLSec int `json:"reservation_ttl_sec,omitempty"`
+}
+
+// TaskGeneration configures the budgets of outstanding tasks. The coordinator cron advances a watermark per task type
+// up to the chunk or batch index keeping the unassigned and assigned tasks within the budget, the coordinator api only
+// assigns tasks up to the watermark, so that the tasks do not pile up during prover outages.
+type TaskGeneration struct {
+	// MaxOutstandingChunkTasks is the budget of outstanding chunk tasks, 0 for no budget.
+	MaxOutstandingChunkTasks int `json:"max_outstanding_chunk_tasks"`
+	// MaxOutstandingBatchTasks is the budget of outstanding batch tasks, 0 for no budget.
+	MaxOutstandingBatchTasks int `json:"max_outstanding_batch_tasks"`
+	// IntervalSec is the interval (in seconds) the watermarks are advanced at, defaults to 10 seconds.
+	IntervalSec int `json:"interval_sec,omitempty"`
+}
+
+// MaxOutstandingTasks returns the budget of outstanding tasks of the task type, 0 for no budget.
+func (t *TaskGeneration) MaxOutstandingTasks(taskType message.ProofType) int {
+	if t == nil {
+		return 0
+	}
+	switch taskType {
+	case message.ProofTypeChunk:
+		return t.MaxOutstandingChunkTasks
+	case message.ProofTypeBatch:
+		return t.MaxOutstandingBatchTasks
+	default:
+		return 0
+	}
+}
+
+// Assignment fairness strategies.
+const (
+	// FairnessRoundRobin serves first the waiting prover assigned a task the longest time ago.
+	FairnessRoundRobin = "round_robin"
+	// FairnessLeastLoaded serves first the waiting prover assigned the fewest tasks recently.
+	FairnessLeastLoaded = "least_loaded"
+	// FairnessWeighted serves first the waiting prover assigned the fewest tasks recently relative to its weight.
+	FairnessWeighted = "weighted"
+)
+
+// AssignmentFairness configures the order in which the provers waiting for a task are served. A prover asking for a
+// task is rate limited while another prover waiting for a task of the same type and hard fork comes first.
+// Each coordinator replica orders the provers it serves.
+type AssignmentFairness struct {
+	// Strategy is round_robin, least_loaded or weighted.
+	Strategy string `json:"strategy"`
+	// Weights of the provers by public key for the weighted strategy, e.g. their stake, 1 if not listed.
+	Weights map[string]float64 `json:"weights,omitempty"`
+	// ActiveWindowSec is the time (in seconds) since its last request a prover is considered waiting, defaults to 30
+	// seconds, it should exceed the polling interval of the provers.
+	ActiveWindowSec int `json:"active_window_sec,omitempty"`
+	// LoadHalfLifeSec is the half-life (in seconds) of the recent assignments of a prover, defaults to one hour.
+	LoadHalfLifeSec int `json:"load_half_life_sec,omitempty"`
+	// MaxDeferralSec is the longest time (in seconds) a prover is deferred, so it is served even if provers coming
+	// first never get a task, defaults to 300 seconds.
+	MaxDeferralSec int `json:"max_deferral_sec,omitempty"`
 }
 
 // L2 loads l2geth configuration items.
 type L2 struct {
 	// l2geth chain_id.
 	ChainID uint64 `json:"chain_id"`
+	// ForkHeights maps hard fork names to their activation heights. Entries override or extend
+	// the fork heights of the chain config, e.g. to schedule a fork the genesis does not know about yet.
+	ForkHeights map[string]uint64 `json:"fork_heights,omitempty"`
+	// Endpoint of l2geth the block traces of chunk tasks are fetched from and sent to provers with the task.
+	// Provers fetch the traces themselves if empty.
+	Endpoint string `json:"endpoint,omitempty"`
+	// TraceCache is the configuration of fetching and caching block traces, only used with Endpoint.
+	TraceCache *TraceCacheConfig `json:"trace_cache,omitempty"`
+	// ValidateChunks checks that the block traces of a chunk are retrievable and that their state roots chain before
+	// the chunk is assigned, only used with Endpoint. Chunks failing the check are marked failed for operator review.
+	ValidateChunks bool `json:"validate_chunks,omitempty"`
+}
+
+// TraceCacheConfig represents the configuration for fetching and caching block traces.
+type TraceCacheConfig struct {
+	Size                 int    `json:"size"`                              // number of block traces kept in memory
+	DiskDir              string `json:"disk_dir,omitempty"`                // disk cache is disabled if empty
+	DiskMaxEntries       int    `json:"disk_max_entries,omitempty"`        // number of block traces kept on disk
+	DiskPruneIntervalSec int    `json:"disk_prune_interval_sec,omitempty"` // interval of pruning the disk cache, defaults to 60 seconds
+	FetchConcurrency     int    `json:"fetch_concurrency"`                 // number of concurrent trace requests
+	PrefetchBlocks       uint64 `json:"prefetch_blocks"`                   // number of blocks prefetched after each chunk, 0 disables prefetching
 }
 
 // Auth provides the auth coordinator
@@ -38,6 +181,89 @@ type Auth struct {
 	Secret                     string `json:"secret"`
 	ChallengeExpireDurationSec int    `json:"challenge_expire_duration_sec"`
 	LoginExpireDurationSec     int    `json:"login_expire_duration_sec"`
+	// TaskDataEncryption encrypts the task data sent to provers with a key negotiated at login, disabled if nil.
+	TaskDataEncryption *TaskDataEncryption `json:"task_data_encryption,omitempty"`
+	// SessionStore is where the challenge nonces and login sessions are stored, the database if nil.
+	SessionStore *SessionStore `json:"session_store,omitempty"`
+}
+
+// Session store types.
+const (
+	SessionStoreDB     = "db"
+	SessionStoreMemory = "memory"
+	SessionStoreRedis  = "redis"
+)
+
+// SessionStore configures the store of the challenge nonces and login sessions. The db and redis stores are shared
+// by the coordinator replicas and kept over restarts, the memory store only suits a single replica.
+type SessionStore struct {
+	// Type is db, memory or redis.
+	Type  string       `json:"type"`
+	Redis *RedisConfig `json:"redis,omitempty"`
+}
+
+// RedisConfig configures the connection to redis.
+type RedisConfig struct {
+	Address  string `json:"address"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	DB       int    `json:"db,omitempty"`
+	// TLS enables transit encryption, e.g. for managed redis services.
+	TLS bool `json:"tls,omitempty"`
+	// KeyPrefix is prepended to the keys, so that several coordinators can share a redis, defaults to "coordinator:".
+	KeyPrefix string `json:"key_prefix,omitempty"`
+}
+
+// TaskDataEncryption configures the end-to-end encryption of the task data, so that the execution traces
+// containing user transactions can not be read by a load balancer or cache between the coordinator and the provers.
+type TaskDataEncryption struct {
+	Enabled bool `json:"enabled"`
+	// Required rejects the login of provers which do not negotiate a session key.
+	Required bool `json:"required"`
+}
+
+// WebhookConfig is a webhook notified of task results, e.g. to trigger the relayer or feed analytics.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Events the webhook subscribes to, e.g. batch_proof_verified, all events if empty.
+	Events []string `json:"events,omitempty"`
+	// Secret signs the request body with HMAC-SHA256 in the X-Coordinator-Signature header, unsigned if empty.
+	Secret string `json:"secret,omitempty"`
+	// Request timeout in seconds, defaults to 5 seconds.
+	TimeoutSec int `json:"timeout_sec,omitempty"`
+	// Max number of retries of a failed delivery, defaults to 3.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// Subscribes returns whether the webhook subscribes to the event.
+func (w *WebhookConfig) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminConfig enables the admin api, served under /coordinator/v1/admin.
+type AdminConfig struct {
+	// Token authenticates admin requests, passed in the X-Admin-Token header. The admin api is disabled if empty.
+	Token string `json:"token"`
+	// Snapshots periodically writes the scheduler snapshot of the admin api to disk for post-mortems, disabled if nil.
+	Snapshots *SnapshotConfig `json:"snapshots,omitempty"`
+}
+
+// SnapshotConfig configures the periodic scheduler snapshots, written as JSON files named after their time.
+type SnapshotConfig struct {
+	// Dir is the directory the snapshots are written to, created if missing.
+	Dir string `json:"dir"`
+	// IntervalSec is the interval (in seconds) the snapshots are written at, defaults to 300 seconds.
+	IntervalSec int `json:"interval_sec,omitempty"`
+	// Keep is the number of the latest snapshots kept, the older ones are deleted, defaults to 288.
+	Keep int `json:"keep,omitempty"`
 }
 
 // Config load configuration items.
@@ -46,6 +272,29 @@ type Config struct {
 	DB            *database.Config `json:"db"`
 	L2            *L2              `json:"l2"`
 	Auth          *Auth            `json:"auth"`
+	Webhooks      []*WebhookConfig `json:"webhooks,omitempty"`
+	Admin         *AdminConfig     `json:"admin,omitempty"`
+	// Tenants are the other rollup instances served by the coordinator, e.g. a devnet next to staging. The top level
+	// config is the default tenant, serving the provers not listed by any tenant.
+	Tenants []*TenantConfig `json:"tenants,omitempty"`
+}
+
+// DefaultTenantLabel is the tenant label of the metrics of the default tenant.
+const DefaultTenantLabel = "default"
+
+// TenantConfig is a rollup instance served by the coordinator besides the default one. Its tasks are assigned from
+// its own db, to the provers it lists only, and their proofs are checked against its own verifying keys.
+type TenantConfig struct {
+	// Name identifies the tenant in the login tokens of its provers and in the tenant label of its metrics.
+	Name string `json:"name"`
+	// ProverPublicKeys are the compressed public keys of the provers of the tenant, in hex.
+	ProverPublicKeys []string         `json:"prover_public_keys"`
+	DB               *database.Config `json:"db"`
+	// L2 of the tenant, the l2 of the default tenant if nil. Its fork heights override the ones of the genesis.
+	L2 *L2 `json:"l2,omitempty"`
+	// VKRegistryDir is the verifying key registry of the tenant, the keys of the assets are used if empty. The
+	// circuits are shared by the tenants.
+	VKRegistryDir string `json:"vk_registry_dir,omitempty"`
 }
 
 // VerifierConfig load zk verifier config.
@@ -53,6 +302,13 @@ type VerifierConfig struct {
 	MockMode   bool   `json:"mock_mode"`
 	ParamsPath string `json:"params_path"`
 	AssetsPath string `json:"assets_path"`
+	// ForkName is the hard fork the circuit assets are built for. When set, proofs of tasks
+	// belonging to another fork are rejected before verification.
+	ForkName string `json:"fork_name,omitempty"`
+	// VKRegistryDir is the directory of the verifying keys per hard fork, with a sub directory per fork holding
+	// chunk_vk.vkey, agg_vk.vkey and their sha256sums. Forks not in it use the keys of the assets. It is reloaded
+	// on SIGHUP and by the admin api.
+	VKRegistryDir string `json:"vk_registry_dir,omitempty"`
 }
 
 // NewConfig returns a new instance of Config.
@@ -61,6 +317,10 @@ func NewConfig(file string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	buf, err = secrets.ResolveJSON(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{}
 	err = json.Unmarshal(buf, cfg)
@@ -68,5 +328,121 @@ func NewConfig(file string) (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.ProverManager != nil && cfg.ProverManager.AssignmentFairness != nil {
+		switch strategy := cfg.ProverManager.AssignmentFairness.Strategy; strategy {
+		case FairnessRoundRobin, FairnessLeastLoaded, FairnessWeighted:
+		default:
+			return nil, fmt.Errorf("unknown assignment fairness strategy %q, expected round_robin, least_loaded or weighted", strategy)
+		}
+	}
+
+	if cfg.Auth != nil && cfg.Auth.SessionStore != nil {
+		switch storeType := cfg.Auth.SessionStore.Type; storeType {
+		case SessionStoreDB, SessionStoreMemory:
+		case SessionStoreRedis:
+			if cfg.Auth.SessionStore.Redis == nil || cfg.Auth.SessionStore.Redis.Address == "" {
+				return nil, errors.New("the redis session store requires a redis address")
+			}
+		default:
+			return nil, fmt.Errorf("unknown session store type %q, expected db, memory or redis", storeType)
+		}
+	}
+
+	if cfg.Admin != nil && cfg.Admin.Snapshots != nil && cfg.Admin.Snapshots.Dir == "" {
+		return nil, errors.New("the scheduler snapshots require a dir")
+	}
+
+	if err = cfg.validateTenants(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
+
+// validateTenants checks the tenants are named uniquely and each prover belongs to a tenant at most.
+func (c *Config) validateTenants() error {
+	names := make(map[string]bool)
+	proverTenants := make(map[string]string)
+	for _, tenant := range c.Tenants {
+		if tenant.Name == "" || tenant.Name == DefaultTenantLabel {
+			return fmt.Errorf("invalid tenant name %q", tenant.Name)
+		}
+		if names[tenant.Name] {
+			return fmt.Errorf("duplicate tenant %s", tenant.Name)
+		}
+		names[tenant.Name] = true
+		if tenant.DB == nil {
+			return fmt.Errorf("tenant %s has no db", tenant.Name)
+		}
+		for _, publicKey := range tenant.ProverPublicKeys {
+			publicKey = normalizePublicKey(publicKey)
+			if other, ok := proverTenants[publicKey]; ok {
+				return fmt.Errorf("prover %s belongs to the tenants %s and %s", publicKey, other, tenant.Name)
+			}
+			proverTenants[publicKey] = tenant.Name
+		}
+	}
+	return nil
+}
+
+// TenantOf returns the name of the tenant of the prover, "" for the default tenant.
+func (c *Config) TenantOf(publicKey string) string {
+	publicKey = normalizePublicKey(publicKey)
+	for _, tenant := range c.Tenants {
+		for _, key := range tenant.ProverPublicKeys {
+			if normalizePublicKey(key) == publicKey {
+				return tenant.Name
+			}
+		}
+	}
+	return ""
+}
+
+// ForTenant returns the config of the tenant, the top level config with the db, l2 and verifying key registry of the
+// tenant.
+func (c *Config) ForTenant(tenant *TenantConfig) *Config {
+	cfg := *c
+	cfg.DB = tenant.DB
+	if tenant.L2 != nil {
+		cfg.L2 = tenant.L2
+	}
+	if c.ProverManager != nil {
+		proverManager := *c.ProverManager
+		if c.ProverManager.Verifier != nil {
+			verifier := *c.ProverManager.Verifier
+			verifier.VKRegistryDir = tenant.VKRegistryDir
+			proverManager.Verifier = &verifier
+		}
+		cfg.ProverManager = &proverManager
+	}
+	cfg.Tenants = nil
+	return &cfg
+}
+
+// TenantRegisterer returns the registerer of the metrics of the tenant, labeled by its name if the coordinator
+// serves several tenants, so that the metrics of their controllers do not collide.
+func (c *Config) TenantRegisterer(reg prometheus.Registerer, tenant string) prometheus.Registerer {
+	if reg == nil || len(c.Tenants) == 0 {
+		return reg
+	}
+	if tenant == "" {
+		tenant = DefaultTenantLabel
+	}
+	return prometheus.WrapRegistererWith(prometheus.Labels{"tenant": tenant}, reg)
+}
+
+func normalizePublicKey(publicKey string) string {
+	return strings.TrimPrefix(strings.ToLower(publicKey), "0x")
+}
+
+// ApplyNetwork fills the L2 chain id and the fork heights left out of the config from the network profile,
+// so that tasks are assigned by the fork heights of the network even if the genesis file predates a fork.
+func (c *Config) ApplyNetwork(network *chains.Network) {
+	if network == nil || c.L2 == nil {
+		return
+	}
+	if c.L2.ChainID == 0 {
+		c.L2.ChainID = network.L2ChainID
+	}
+	c.L2.ForkHeights = network.FillForkHeights(c.L2.ForkHeights)
+}
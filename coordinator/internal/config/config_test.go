@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/chains"
 )
 
 func TestConfig(t *testing.T) {
@@ -90,3 +93,51 @@ func TestConfig(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestApplyNetwork(t *testing.T) {
+	network, err := chains.Get(chains.Sepolia)
+	assert.NoError(t, err)
+
+	cfg := &Config{L2: &L2{ForkHeights: map[string]uint64{"curie": 1}}}
+	cfg.ApplyNetwork(network)
+
+	// configured values take precedence over the network profile
+	assert.Equal(t, network.L2ChainID, cfg.L2.ChainID)
+	assert.Equal(t, uint64(1), cfg.L2.ForkHeights["curie"])
+	assert.Equal(t, network.L2ForkHeights["bernoulli"], cfg.L2.ForkHeights["bernoulli"])
+}
+
+func TestTenants(t *testing.T) {
+	writeConfig := func(t *testing.T, tenants string) string {
+		file := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(file, []byte(`{"l2": {"chain_id": 111}, "tenants": `+tenants+`}`), 0644))
+		return file
+	}
+
+	cfg, err := NewConfig(writeConfig(t, `[
+		{"name": "devnet", "prover_public_keys": ["0x02AB"], "db": {"dsn": "postgres://localhost/devnet"}, "vk_registry_dir": "/vks/devnet"},
+		{"name": "staging", "prover_public_keys": ["03cd"], "db": {"dsn": "postgres://localhost/staging"}, "l2": {"chain_id": 222}}
+	]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "devnet", cfg.TenantOf("02ab"))
+	assert.Equal(t, "staging", cfg.TenantOf("0x03CD"))
+	assert.Equal(t, "", cfg.TenantOf("04ef"))
+
+	devnet := cfg.ForTenant(cfg.Tenants[0])
+	assert.Equal(t, "postgres://localhost/devnet", devnet.DB.DSN)
+	assert.Equal(t, uint64(111), devnet.L2.ChainID)
+	assert.Empty(t, devnet.Tenants)
+	staging := cfg.ForTenant(cfg.Tenants[1])
+	assert.Equal(t, uint64(222), staging.L2.ChainID)
+
+	for name, tenants := range map[string]string{
+		"unnamed":        `[{"prover_public_keys": ["02ab"], "db": {}}]`,
+		"reserved name":  `[{"name": "default", "db": {}}]`,
+		"duplicate name": `[{"name": "devnet", "db": {}}, {"name": "devnet", "db": {}}]`,
+		"no db":          `[{"name": "devnet"}]`,
+		"shared prover":  `[{"name": "devnet", "prover_public_keys": ["02ab"], "db": {}}, {"name": "staging", "prover_public_keys": ["0x02AB"], "db": {}}]`,
+	} {
+		_, err = NewConfig(writeConfig(t, tenants))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	ctypes "scroll-tech/common/types"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/logic/snapshot"
+	"scroll-tech/coordinator/internal/orm"
+	"scroll-tech/coordinator/internal/types"
+)
+
+const (
+	defaultProofFailuresSince  = 24 * time.Hour
+	defaultProofFailuresLimit  = 100
+	defaultBlockedBatchesLimit = 100
+)
+
+// AdminController the admin api controller, e.g. for circuit debugging
+type AdminController struct {
+	proofFailureOrm    *orm.ProofFailure
+	batchDependencyOrm *orm.BatchDependency
+	snapshotter        *snapshot.Snapshotter
+}
+
+// NewAdminController create the admin api controller instance
+func NewAdminController(db *gorm.DB, snapshotter *snapshot.Snapshotter) *AdminController {
+	return &AdminController{
+		proofFailureOrm:    orm.NewProofFailure(db),
+		batchDependencyOrm: orm.NewBatchDependency(db),
+		snapshotter:        snapshotter,
+	}
+}
+
+// GetProofFailures returns the breakdown of the proof failures by failure class, task type and prover version,
+// and the latest failures with the block ranges of their tasks.
+func (a *AdminController) GetProofFailures(ctx *gin.Context) {
+	var param types.GetProofFailuresParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	since := utils.NowUTC().Add(-defaultProofFailuresSince)
+	if param.Since > 0 {
+		since = time.Unix(param.Since, 0).UTC()
+	}
+	limit := defaultProofFailuresLimit
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+
+	counts, err := a.proofFailureOrm.GetProofFailureCounts(ctx, since)
+	if err != nil {
+		nerr := fmt.Errorf("get proof failure counts failure, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorGetProofFailuresFailure, nerr)
+		return
+	}
+	proofFailures, err := a.proofFailureOrm.GetProofFailures(ctx, since, param.FailureClass, limit)
+	if err != nil {
+		nerr := fmt.Errorf("get proof failures failure, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorGetProofFailuresFailure, nerr)
+		return
+	}
+
+	resp := types.GetProofFailuresSchema{
+		Breakdown: make([]*types.ProofFailureCountSchema, 0, len(counts)),
+		Failures:  make([]*types.ProofFailureSchema, 0, len(proofFailures)),
+	}
+	for _, count := range counts {
+		resp.Breakdown = append(resp.Breakdown, &types.ProofFailureCountSchema{
+			FailureClass:  ctypes.ProofFailureClass(count.FailureClass).String(),
+			TaskType:      int(count.TaskType),
+			ProverVersion: count.ProverVersion,
+			Count:         count.Count,
+		})
+	}
+	for _, proofFailure := range proofFailures {
+		resp.Failures = append(resp.Failures, &types.ProofFailureSchema{
+			TaskID:           proofFailure.TaskID,
+			TaskType:         int(proofFailure.TaskType),
+			ProverTaskUUID:   proofFailure.ProverTaskUUID,
+			ProverName:       proofFailure.ProverName,
+			ProverPublicKey:  proofFailure.ProverPublicKey,
+			ProverVersion:    proofFailure.ProverVersion,
+			FailureType:      ctypes.ProverTaskFailureType(proofFailure.FailureType).String(),
+			FailureClass:     ctypes.ProofFailureClass(proofFailure.FailureClass).String(),
+			FailureMsg:       proofFailure.FailureMsg,
+			StartBlockNumber: proofFailure.StartBlockNumber,
+			EndBlockNumber:   proofFailure.EndBlockNumber,
+			HardForkName:     proofFailure.HardForkName,
+			CreatedAt:        proofFailure.CreatedAt.Unix(),
+		})
+	}
+	ctypes.RenderSuccess(ctx, resp)
+}
+
+// ReloadVKs reloads the verifying key registries of the tenants, so that the keys rolled out to their directories are
+// used without a restart, and replies the forks of the default tenant. The current keys of a tenant are kept if its
+// directory fails validation.
+func (a *AdminController) ReloadVKs(ctx *gin.Context) {
+	forks, err := ReloadVerifierKeys()
+	if err != nil {
+		nerr := fmt.Errorf("reload verifying keys failure, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorReloadVKsFailure, nerr)
+		return
+	}
+	ctypes.RenderSuccess(ctx, types.ReloadVKsSchema{Forks: forks})
+}
+
+// GetSchedulerSnapshot returns the scheduler state of the default tenant, i.e. the pending chunks and batches, the
+// assignments with their ages and the prover sessions, e.g. to be attached to an incident post-mortem.
+func (a *AdminController) GetSchedulerSnapshot(ctx *gin.Context) {
+	var param types.GetSchedulerSnapshotParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	limit := snapshot.DefaultLimit
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	resp, err := a.snapshotter.Take(ctx, limit, utils.NowUTC())
+	if err != nil {
+		nerr := fmt.Errorf("take scheduler snapshot failure, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorGetSnapshotFailure, nerr)
+		return
+	}
+	ctypes.RenderSuccess(ctx, resp)
+}
+
+// GetBatchDependencies returns the chunk proofs a batch task depends on, or without a batch hash the batch tasks
+// waiting for chunk proofs, e.g. to find the chunk holding back the proving of the batches.
+func (a *AdminController) GetBatchDependencies(ctx *gin.Context) {
+	var param types.GetBatchDependenciesParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	var resp types.BatchDependenciesSchema
+	if param.BatchHash != "" {
+		dependencies, err := a.batchDependencyOrm.GetBatchDependencies(ctx, param.BatchHash)
+		if err != nil {
+			nerr := fmt.Errorf("get batch dependencies failure, err:%w", err)
+			ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorGetBatchDependenciesFailure, nerr)
+			return
+		}
+		resp.Dependencies = make([]*types.BatchDependencySchema, 0, len(dependencies))
+		for _, dependency := range dependencies {
+			schema := &types.BatchDependencySchema{
+				ChunkHash:  dependency.ChunkHash,
+				ChunkIndex: dependency.ChunkIndex,
+				Verified:   dependency.VerifiedAt != nil,
+			}
+			if dependency.VerifiedAt != nil {
+				schema.VerifiedAt = dependency.VerifiedAt.Unix()
+			}
+			resp.Dependencies = append(resp.Dependencies, schema)
+		}
+		ctypes.RenderSuccess(ctx, resp)
+		return
+	}
+
+	limit := defaultBlockedBatchesLimit
+	if param.Limit > 0 {
+		limit = param.Limit
+	}
+	blockedBatches, err := a.batchDependencyOrm.GetBlockedBatches(ctx, limit)
+	if err != nil {
+		nerr := fmt.Errorf("get blocked batches failure, err:%w", err)
+		ctypes.RenderFailure(ctx, ctypes.ErrCoordinatorGetBatchDependenciesFailure, nerr)
+		return
+	}
+	now := utils.NowUTC()
+	resp.BlockedBatches = make([]*types.BlockedBatchSchema, 0, len(blockedBatches))
+	for _, blockedBatch := range blockedBatches {
+		resp.BlockedBatches = append(resp.BlockedBatches, &types.BlockedBatchSchema{
+			BatchHash:          blockedBatch.BatchHash,
+			BatchIndex:         blockedBatch.BatchIndex,
+			Dependencies:       blockedBatch.Dependencies,
+			UnverifiedChunks:   blockedBatch.UnverifiedChunks,
+			LowestBlockedChunk: blockedBatch.LowestBlockedChunk,
+			BlockedSec:         int64(now.Sub(blockedBatch.BlockedSince) / time.Second),
+		})
+	}
+	ctypes.RenderSuccess(ctx, resp)
+}
@@ -1,27 +1,63 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"github.com/scroll-tech/go-ethereum/log"
 
+	ctypes "scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 
+	"scroll-tech/coordinator/internal/config"
 	"scroll-tech/coordinator/internal/logic/auth"
 	"scroll-tech/coordinator/internal/types"
 )
 
 // AuthController is login API
 type AuthController struct {
-	loginLogic *auth.LoginLogic
+	cfg         *config.Config
+	loginLogic  *auth.LoginLogic
+	versionGate *auth.ProverVersionGate
+}
+
+// loginSession is the prover session created at login, from which the login token claims are built.
+type loginSession struct {
+	publicKey     string
+	proverName    string
+	proverVersion string
+	sessionID     string
+	tenant        string
 }
 
 // NewAuthController returns an LoginController instance
-func NewAuthController(db *gorm.DB) *AuthController {
+func NewAuthController(cfg *config.Config, sessionStore auth.SessionStore, versionGate *auth.ProverVersionGate) *AuthController {
 	return &AuthController{
-		loginLogic: auth.NewLoginLogic(db),
+		cfg:         cfg,
+		loginLogic:  auth.NewLoginLogic(cfg, sessionStore),
+		versionGate: versionGate,
+	}
+}
+
+// Challenge the api controller for challenge, it issues a one-time nonce carried by the challenge token
+func (a *AuthController) Challenge(c *gin.Context) (interface{}, error) {
+	nonce, err := a.loginLogic.IssueChallenge(c)
+	if err != nil {
+		return "", fmt.Errorf("issue challenge failure:%w", err)
+	}
+	return nonce, nil
+}
+
+// ChallengePayloadFunc returns jwt.MapClaims with {challenge nonce}.
+func (a *AuthController) ChallengePayloadFunc(data interface{}) jwt.MapClaims {
+	nonce, ok := data.(string)
+	if !ok {
+		return jwt.MapClaims{}
+	}
+	return jwt.MapClaims{
+		types.ChallengeNonce: nonce,
 	}
 }
 
@@ -39,42 +75,99 @@ func (a *AuthController) Login(c *gin.Context) (interface{}, error) {
 		return "", fmt.Errorf("check challenge failure for the not equal challenge string")
 	}
 
-	// check the challenge is used, if used, return failure
-	if err := a.loginLogic.InsertChallengeString(c, login.Message.Challenge); err != nil {
-		return "", fmt.Errorf("login insert challenge string failure:%w", err)
+	// the claims of the challenge token are extracted by the challenge middleware,
+	// check the nonce was issued and is not used, and mark it as used
+	nonce, ok := jwt.ExtractClaims(c)[types.ChallengeNonce].(string)
+	if !ok || nonce == "" {
+		return "", errors.New("check challenge failure for the missing challenge nonce")
+	}
+	if err := a.loginLogic.UseChallenge(c, nonce); err != nil {
+		return "", fmt.Errorf("login use challenge failure:%w", err)
 	}
-	return login, nil
-}
 
-// PayloadFunc returns jwt.MapClaims with {public key, prover name}.
-func (a *AuthController) PayloadFunc(data interface{}) jwt.MapClaims {
-	v, ok := data.(types.LoginParameter)
-	if !ok {
-		return jwt.MapClaims{}
+	deprecation, err := a.versionGate.Check(auth.ProverVersionEndpointLogin, login.Message.ProverVersion)
+	if err != nil {
+		return "", err
+	}
+	if deprecation != "" {
+		c.Header(ctypes.ProverVersionDeprecationHeader, deprecation)
 	}
 
 	// recover the public key
 	authMsg := message.AuthMsg{
 		Identity: &message.Identity{
-			Challenge:     v.Message.Challenge,
-			ProverName:    v.Message.ProverName,
-			ProverVersion: v.Message.ProverVersion,
+			Challenge:        login.Message.Challenge,
+			ProverName:       login.Message.ProverName,
+			ProverVersion:    login.Message.ProverVersion,
+			SessionPublicKey: login.Message.SessionPublicKey,
 		},
-		Signature: v.Signature,
+		Signature: login.Signature,
 	}
-
 	publicKey, err := authMsg.PublicKey()
 	if err != nil {
+		return "", fmt.Errorf("recover public key failure:%w", err)
+	}
+
+	// the session is stored in the db, so that the login token is accepted by every coordinator replica
+	// the session public key is signed with the identity, so it can not be replaced on the way to the coordinator
+	sessionID, sessionPublicKey, err := a.loginLogic.CreateSession(c, publicKey, login.Message.ProverName, login.Message.ProverVersion, login.Message.SessionPublicKey)
+	if err != nil {
+		return "", fmt.Errorf("login create session failure:%w", err)
+	}
+	if sessionPublicKey != "" {
+		c.Set(types.SessionPublicKey, sessionPublicKey)
+	}
+	return &loginSession{
+		publicKey:     publicKey,
+		proverName:    login.Message.ProverName,
+		proverVersion: login.Message.ProverVersion,
+		sessionID:     sessionID,
+		tenant:        a.cfg.TenantOf(publicKey),
+	}, nil
+}
+
+// PayloadFunc returns jwt.MapClaims with {public key, prover name, prover version, session id, tenant}.
+func (a *AuthController) PayloadFunc(data interface{}) jwt.MapClaims {
+	v, ok := data.(*loginSession)
+	if !ok {
 		return jwt.MapClaims{}
 	}
 
 	return jwt.MapClaims{
-		types.PublicKey:     publicKey,
-		types.ProverName:    v.Message.ProverName,
-		types.ProverVersion: v.Message.ProverVersion,
+		types.PublicKey:     v.publicKey,
+		types.ProverName:    v.proverName,
+		types.ProverVersion: v.proverVersion,
+		types.SessionID:     v.sessionID,
+		types.Tenant:        v.tenant,
 	}
 }
 
+// Authorizator checks the session of the login token is stored and has not expired, and the prover still belongs to
+// the tenant of the token, and sets the task data key of the session in the context.
+func (a *AuthController) Authorizator(_ interface{}, c *gin.Context) bool {
+	claims := jwt.ExtractClaims(c)
+	sessionID, sessionOk := claims[types.SessionID].(string)
+	publicKey, publicKeyOk := claims[types.PublicKey].(string)
+	if !sessionOk || !publicKeyOk {
+		return false
+	}
+	// the tokens issued before the tenants were introduced are of the default tenant.
+	tenant, _ := claims[types.Tenant].(string)
+	if tenant != a.cfg.TenantOf(publicKey) {
+		log.Warn("prover tenant check failure", "public key", publicKey, "tenant", tenant)
+		return false
+	}
+	taskDataKey, err := a.loginLogic.CheckSession(c, sessionID, publicKey)
+	if err != nil {
+		log.Warn("prover session check failure", "public key", publicKey, "error", err)
+		return false
+	}
+	if taskDataKey != "" {
+		c.Set(types.TaskDataKey, taskDataKey)
+	}
+	return true
+}
+
 // IdentityHandler replies to client for /login
 func (a *AuthController) IdentityHandler(c *gin.Context) interface{} {
 	claims := jwt.ExtractClaims(c)
@@ -89,5 +182,9 @@ func (a *AuthController) IdentityHandler(c *gin.Context) interface{} {
 	if proverVersion, ok := claims[types.ProverVersion]; ok {
 		c.Set(types.ProverVersion, proverVersion)
 	}
+
+	if tenant, ok := claims[types.Tenant]; ok {
+		c.Set(types.Tenant, tenant)
+	}
 	return nil
 }
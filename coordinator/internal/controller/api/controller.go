@@ -1,31 +1,123 @@
 package api
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/clock"
+
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/auth"
+	"scroll-tech/coordinator/internal/logic/overrides"
+	"scroll-tech/coordinator/internal/logic/payload"
+	"scroll-tech/coordinator/internal/logic/snapshot"
+	"scroll-tech/coordinator/internal/logic/trace"
 	"scroll-tech/coordinator/internal/logic/verifier"
 )
 
 var (
-	// GetTask the prover task controller
+	// GetTask the prover task controller of the default tenant
 	GetTask *GetTaskController
-	// SubmitProof the submit proof controller
+	// SubmitProof the submit proof controller of the default tenant
 	SubmitProof *SubmitProofController
+	// Tenants dispatches the prover requests to the controllers of their tenant
+	Tenants *TenantDispatcher
 	// Auth the auth controller
 	Auth *AuthController
+	// Admin the admin api controller
+	Admin *AdminController
+	// Snapshotter the scheduler snapshotter of the default tenant, for the admin api and the periodic snapshots
+	Snapshotter *snapshot.Snapshotter
+
+	vf          *verifier.Verifier
+	versionGate *auth.ProverVersionGate
 )
 
 // InitController inits Controller with database
 func InitController(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, reg prometheus.Registerer) {
-	vf, err := verifier.NewVerifier(cfg.ProverManager.Verifier)
+	var err error
+	vf, err = verifier.NewVerifier(cfg.ProverManager.Verifier)
 	if err != nil {
 		panic("proof receiver new verifier failure")
 	}
 
-	Auth = NewAuthController(db)
-	GetTask = NewGetTaskController(cfg, chainCfg, db, vf, reg)
-	SubmitProof = NewSubmitProofController(cfg, db, vf, reg)
+	sessionStore, err := auth.NewSessionStore(cfg.Auth.SessionStore, db)
+	if err != nil {
+		panic("failed to create session store")
+	}
+
+	versionGate = auth.NewProverVersionGate(cfg.ProverManager, reg)
+	Auth = NewAuthController(cfg, sessionStore, versionGate)
+	tenantReg := cfg.TenantRegisterer(reg, "")
+	ov := newOverrides(cfg, db, tenantReg)
+	GetTask = NewGetTaskController(cfg, chainCfg, db, vf, versionGate, newTraceService(cfg), newPayloadStore(cfg), ov, tenantReg)
+	SubmitProof = NewSubmitProofController(cfg, chainCfg, db, vf, ov, tenantReg)
+	Tenants = NewTenantDispatcher(GetTask, SubmitProof, vf)
+	Snapshotter = snapshot.NewSnapshotter(db, sessionStore)
+	Admin = NewAdminController(db, Snapshotter)
+}
+
+// InitTenants inits the controllers of the tenants of the config with their databases by tenant name, after
+// InitController. The tenants share the circuits of the verifier of the default tenant.
+func InitTenants(cfg *config.Config, chainCfg *params.ChainConfig, dbs map[string]*gorm.DB, reg prometheus.Registerer) {
+	for _, tenant := range cfg.Tenants {
+		db, ok := dbs[tenant.Name]
+		if !ok {
+			panic("missing db of tenant " + tenant.Name)
+		}
+		tenantCfg := cfg.ForTenant(tenant)
+		tenantVF, err := vf.WithVKRegistry(tenant.VKRegistryDir)
+		if err != nil {
+			panic("failed to load the verifying key registry of tenant " + tenant.Name)
+		}
+		tenantReg := cfg.TenantRegisterer(reg, tenant.Name)
+		ov := newOverrides(tenantCfg, db, tenantReg)
+		getTask := NewGetTaskController(tenantCfg, chainCfg, db, tenantVF, versionGate, newTraceService(tenantCfg), newPayloadStore(tenantCfg), ov, tenantReg)
+		submitProof := NewSubmitProofController(tenantCfg, chainCfg, db, tenantVF, ov, tenantReg)
+		Tenants.AddTenant(tenant.Name, getTask, submitProof, tenantVF)
+	}
+}
+
+// ReloadVerifierKeys reloads the verifying key registries of the tenants, e.g. on SIGHUP.
+func ReloadVerifierKeys() ([]string, error) {
+	return Tenants.ReloadVKs()
+}
+
+// newTraceService returns the block trace service of the l2 endpoint of the config, nil if it has none.
+func newTraceService(cfg *config.Config) *trace.Service {
+	if cfg.L2 == nil || cfg.L2.Endpoint == "" {
+		return nil
+	}
+	l2Client, err := ethclient.Dial(cfg.L2.Endpoint)
+	if err != nil {
+		panic("failed to dial l2geth")
+	}
+	// Use gzip compression.
+	l2Client.SetHeader("Accept-Encoding", "gzip")
+	traceService, err := trace.NewService(context.Background(), cfg.L2.TraceCache, l2Client)
+	if err != nil {
+		panic("failed to create block trace service")
+	}
+	return traceService
+}
+
+// newPayloadStore returns the store of the task data downloaded by provers, nil if the config disables it.
+func newPayloadStore(cfg *config.Config) *payload.Store {
+	payloadStore, err := payload.NewStore(context.Background(), cfg.ProverManager.TaskPayloads, clock.New())
+	if err != nil {
+		panic("failed to create task payload store")
+	}
+	return payloadStore
+}
+
+// newOverrides returns the prover manager parameters of the config with the overrides of the database of the tenant,
+// reloaded in the background.
+func newOverrides(cfg *config.Config, db *gorm.DB, reg prometheus.Registerer) *overrides.Overrides {
+	ov := overrides.New(cfg.ProverManager, db, reg)
+	ov.Start(context.Background())
+	return ov
 }
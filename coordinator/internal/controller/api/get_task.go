@@ -1,11 +1,14 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
@@ -13,23 +16,34 @@ import (
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/auth"
+	"scroll-tech/coordinator/internal/logic/overrides"
+	"scroll-tech/coordinator/internal/logic/payload"
 	"scroll-tech/coordinator/internal/logic/provertask"
+	"scroll-tech/coordinator/internal/logic/trace"
 	"scroll-tech/coordinator/internal/logic/verifier"
+	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
 // GetTaskController the get prover task api controller
 type GetTaskController struct {
-	proverTasks map[message.ProofType]provertask.ProverTask
+	proverTasks   map[message.ProofType]provertask.ProverTask
+	versionGate   *auth.ProverVersionGate
+	payloads      *payload.Store
+	proverTaskOrm *orm.ProverTask
 }
 
 // NewGetTaskController create a get prover task controller
-func NewGetTaskController(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vf *verifier.Verifier, reg prometheus.Registerer) *GetTaskController {
-	chunkProverTask := provertask.NewChunkProverTask(cfg, chainCfg, db, vf.ChunkVK, reg)
-	batchProverTask := provertask.NewBatchProverTask(cfg, chainCfg, db, vf.BatchVK, reg)
+func NewGetTaskController(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vf *verifier.Verifier, versionGate *auth.ProverVersionGate, traceService *trace.Service, payloads *payload.Store, ov *overrides.Overrides, reg prometheus.Registerer) *GetTaskController {
+	chunkProverTask := provertask.NewChunkProverTask(cfg, chainCfg, db, traceService, vf.ChunkVKOf, ov, reg)
+	batchProverTask := provertask.NewBatchProverTask(cfg, chainCfg, db, vf.BatchVKOf, ov, reg)
 
 	ptc := &GetTaskController{
-		proverTasks: make(map[message.ProofType]provertask.ProverTask),
+		proverTasks:   make(map[message.ProofType]provertask.ProverTask),
+		versionGate:   versionGate,
+		payloads:      payloads,
+		proverTaskOrm: orm.NewProverTask(db),
 	}
 
 	ptc.proverTasks[message.ProofTypeChunk] = chunkProverTask
@@ -47,6 +61,17 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 		return
 	}
 
+	// the token of a prover logged in before the minimum version was raised is valid until it expires, check again.
+	deprecation, err := ptc.versionGate.Check(auth.ProverVersionEndpointGetTask, ctx.GetString(coordinatorType.ProverVersion))
+	if err != nil {
+		nerr := fmt.Errorf("return prover task err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorProverVersionTooLow, nerr)
+		return
+	}
+	if deprecation != "" {
+		ctx.Header(types.ProverVersionDeprecationHeader, deprecation)
+	}
+
 	proofType := ptc.proofType(&getTaskParameter)
 	proverTask, isExist := ptc.proverTasks[proofType]
 	if !isExist {
@@ -58,19 +83,85 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 	result, err := proverTask.Assign(ctx, &getTaskParameter)
 	if err != nil {
 		nerr := fmt.Errorf("return prover task err:%w", err)
-		types.RenderFailure(ctx, types.ErrCoordinatorGetTaskFailure, nerr)
+		types.RenderFailure(ctx, getTaskErrCode(err), nerr)
 		return
 	}
 
 	if result == nil {
 		nerr := fmt.Errorf("get empty prover task")
-		types.RenderFailure(ctx, types.ErrCoordinatorEmptyProofData, nerr)
+		types.RenderFailure(ctx, types.ErrCoordinatorNoTaskAvailable, nerr)
 		return
 	}
 
+	if taskDataKey := ctx.GetString(coordinatorType.TaskDataKey); taskDataKey != "" {
+		encrypted, err := message.EncryptTaskData(common.Hex2Bytes(taskDataKey), result.TaskData)
+		if err != nil {
+			nerr := fmt.Errorf("encrypt task data err:%w", err)
+			types.RenderFailure(ctx, types.ErrCoordinatorGetTaskFailure, nerr)
+			return
+		}
+		result.TaskData = encrypted
+		result.Encrypted = true
+	}
+
+	// large task data is left out, the prover downloads it from task_payload and resumes an interrupted download.
+	size, sum, err := ptc.payloads.Offload(result.UUID, result.TaskData)
+	if err != nil {
+		nerr := fmt.Errorf("store task payload err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorGetTaskFailure, nerr)
+		return
+	}
+	if size > 0 {
+		result.TaskData = ""
+		result.PayloadSize = size
+		result.PayloadSHA256 = sum
+	}
+
 	types.RenderSuccess(ctx, result)
 }
 
+// GetTaskPayload serves the task data of a task of the prover which was left out of get_task. Range and If-Range
+// requests are supported, so that the prover resumes an interrupted download of the same task data.
+func (ptc *GetTaskController) GetTaskPayload(ctx *gin.Context) {
+	taskUUID := ctx.Param("uuid")
+	publicKey := ctx.GetString(coordinatorType.PublicKey)
+	if _, err := ptc.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(ctx, taskUUID, publicKey); err != nil {
+		nerr := fmt.Errorf("get task payload err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorTaskPayloadNotFound, nerr)
+		return
+	}
+
+	f, sum, err := ptc.payloads.Open(taskUUID)
+	if err != nil {
+		nerr := fmt.Errorf("get task payload err:%w, uuid:%s", err, taskUUID)
+		types.RenderFailure(ctx, types.ErrCoordinatorTaskPayloadNotFound, nerr)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		nerr := fmt.Errorf("get task payload err:%w, uuid:%s", err, taskUUID)
+		types.RenderFailure(ctx, types.ErrCoordinatorTaskPayloadNotFound, nerr)
+		return
+	}
+
+	ctx.Header("Content-Type", "application/octet-stream")
+	ctx.Header("ETag", `"`+sum+`"`)
+	http.ServeContent(ctx.Writer, ctx.Request, "", info.ModTime(), f)
+}
+
+// getTaskErrCode returns the error code of a failure to assign a task, which provers branch on.
+func getTaskErrCode(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrProverVersionTooLow):
+		return types.ErrCoordinatorProverVersionTooLow
+	case errors.Is(err, provertask.ErrProverRateLimited):
+		return types.ErrCoordinatorRateLimited
+	default:
+		return types.ErrCoordinatorGetTaskFailure
+	}
+}
+
 func (ptc *GetTaskController) proofType(para *coordinatorType.GetTaskParameter) message.ProofType {
 	proofType := message.ProofType(para.TaskType)
 
@@ -2,18 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/overrides"
 	"scroll-tech/coordinator/internal/logic/submitproof"
 	"scroll-tech/coordinator/internal/logic/verifier"
+	"scroll-tech/coordinator/internal/logic/webhook"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
@@ -23,9 +27,9 @@ type SubmitProofController struct {
 }
 
 // NewSubmitProofController create the submit proof api controller instance
-func NewSubmitProofController(cfg *config.Config, db *gorm.DB, vf *verifier.Verifier, reg prometheus.Registerer) *SubmitProofController {
+func NewSubmitProofController(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vf *verifier.Verifier, ov *overrides.Overrides, reg prometheus.Registerer) *SubmitProofController {
 	return &SubmitProofController{
-		submitProofReceiverLogic: submitproof.NewSubmitProofReceiverLogic(cfg.ProverManager, db, vf, reg),
+		submitProofReceiverLogic: submitproof.NewSubmitProofReceiverLogic(cfg.ProverManager, cfg.L2, chainCfg, db, vf, ov, webhook.NewNotifier(cfg.Webhooks, reg), reg),
 	}
 }
 
@@ -38,6 +42,61 @@ func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 		return
 	}
 
+	proofMsg, err := newProofMsg(spp)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, err)
+		return
+	}
+
+	if err := spc.submitProofReceiverLogic.HandleZkProof(ctx, proofMsg, spp); err != nil {
+		nerr := fmt.Errorf("handle zk proof failure, err:%w", err)
+		types.RenderFailure(ctx, submitProofErrCode(err), nerr)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
+
+// SubmitProofs prover submit several proofs to coordinator at once, the result of each proof is returned in order
+func (spc *SubmitProofController) SubmitProofs(ctx *gin.Context) {
+	var spp coordinatorType.SubmitProofsParameter
+	if err := ctx.ShouldBindJSON(&spp); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	proofMsgs := make([]*message.ProofMsg, len(spp.Proofs))
+	submitted := make(map[string]struct{}, len(spp.Proofs))
+	for i, proof := range spp.Proofs {
+		key := fmt.Sprintf("%d:%s", proof.TaskType, proof.TaskID)
+		if _, ok := submitted[key]; ok {
+			types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("parameter invalid, task %s submitted twice", proof.TaskID))
+			return
+		}
+		submitted[key] = struct{}{}
+
+		proofMsg, err := newProofMsg(proof)
+		if err != nil {
+			types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("proof %d: %w", i, err))
+			return
+		}
+		proofMsgs[i] = proofMsg
+	}
+
+	errs := spc.submitProofReceiverLogic.HandleZkProofs(ctx, proofMsgs, spp.Proofs, spp.Mode == coordinatorType.SubmitModeAllOrNothing)
+	results := make([]*coordinatorType.SubmitProofResult, len(spp.Proofs))
+	for i, proof := range spp.Proofs {
+		results[i] = &coordinatorType.SubmitProofResult{UUID: proof.UUID, TaskID: proof.TaskID, ErrCode: types.Success}
+		if errs[i] != nil {
+			results[i].ErrCode = submitProofErrCode(errs[i])
+			results[i].ErrMsg = fmt.Sprintf("handle zk proof failure, err:%v", errs[i])
+		}
+	}
+	types.RenderSuccess(ctx, &coordinatorType.SubmitProofsSchema{Results: results})
+}
+
+// newProofMsg returns the proof message of the submitted proof, decoding the proof of a successful task.
+func newProofMsg(spp coordinatorType.SubmitProofParameter) (*message.ProofMsg, error) {
 	proofMsg := message.ProofMsg{
 		ProofDetail: &message.ProofDetail{
 			ID:     spp.TaskID,
@@ -51,26 +110,37 @@ func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 		case message.ProofTypeChunk:
 			var tmpChunkProof message.ChunkProof
 			if err := json.Unmarshal([]byte(spp.Proof), &tmpChunkProof); err != nil {
-				nerr := fmt.Errorf("unmarshal parameter chunk proof invalid, err:%w", err)
-				types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
-				return
+				return nil, fmt.Errorf("unmarshal parameter chunk proof invalid, err:%w", err)
 			}
 			proofMsg.ChunkProof = &tmpChunkProof
 		case message.ProofTypeBatch:
 			var tmpBatchProof message.BatchProof
 			if err := json.Unmarshal([]byte(spp.Proof), &tmpBatchProof); err != nil {
-				nerr := fmt.Errorf("unmarshal parameter batch proof invalid, err:%w", err)
-				types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
-				return
+				return nil, fmt.Errorf("unmarshal parameter batch proof invalid, err:%w", err)
 			}
 			proofMsg.BatchProof = &tmpBatchProof
 		}
 	}
+	return &proofMsg, nil
+}
 
-	if err := spc.submitProofReceiverLogic.HandleZkProof(ctx, &proofMsg, spp); err != nil {
-		nerr := fmt.Errorf("handle zk proof failure, err:%w", err)
-		types.RenderFailure(ctx, types.ErrCoordinatorHandleZkProofFailure, nerr)
-		return
+// submitProofErrCode returns the error code of a failure to handle a proof, which provers branch on.
+func submitProofErrCode(err error) int {
+	switch {
+	case errors.Is(err, submitproof.ErrValidatorFailureProofTimeout),
+		errors.Is(err, submitproof.ErrValidatorFailureProofDeadlineExceeded),
+		errors.Is(err, submitproof.ErrValidatorFailureTaskHaveVerifiedSuccess):
+		return types.ErrCoordinatorTaskExpired
+	case errors.Is(err, submitproof.ErrValidatorFailureProofMismatch):
+		return types.ErrCoordinatorProofMismatch
+	case errors.Is(err, submitproof.ErrProofNotAccepted):
+		return types.ErrCoordinatorProofNotAccepted
+	case errors.Is(err, submitproof.ErrValidatorFailureHardForkMismatch),
+		errors.Is(err, submitproof.ErrValidatorFailureVerifierKeyMismatch),
+		errors.Is(err, submitproof.ErrValidatorFailureVerifiedFailed),
+		errors.Is(err, submitproof.ErrValidatorSuccessInvalidProof):
+		return types.ErrCoordinatorProofInvalid
+	default:
+		return types.ErrCoordinatorHandleZkProofFailure
 	}
-	types.RenderSuccess(ctx, nil)
 }
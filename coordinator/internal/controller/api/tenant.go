@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/logic/verifier"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// tenantControllers are the controllers of a tenant, with its own db, chain and verifying keys.
+type tenantControllers struct {
+	getTask     *GetTaskController
+	submitProof *SubmitProofController
+	verifier    *verifier.Verifier
+}
+
+// TenantDispatcher dispatches the requests of the provers to the controllers of the tenant of their login token, so
+// that provers are only assigned the tasks of their tenant and only submit proofs for them.
+type TenantDispatcher struct {
+	tenants map[string]*tenantControllers
+}
+
+// NewTenantDispatcher returns a dispatcher serving the default tenant with the controllers.
+func NewTenantDispatcher(getTask *GetTaskController, submitProof *SubmitProofController, vf *verifier.Verifier) *TenantDispatcher {
+	d := &TenantDispatcher{tenants: make(map[string]*tenantControllers)}
+	d.AddTenant("", getTask, submitProof, vf)
+	return d
+}
+
+// AddTenant serves the tenant with the controllers.
+func (d *TenantDispatcher) AddTenant(name string, getTask *GetTaskController, submitProof *SubmitProofController, vf *verifier.Verifier) {
+	d.tenants[name] = &tenantControllers{
+		getTask:     getTask,
+		submitProof: submitProof,
+		verifier:    vf,
+	}
+}
+
+// GetTasks dispatches to the get task controller of the tenant of the prover.
+func (d *TenantDispatcher) GetTasks(ctx *gin.Context) {
+	if tenant := d.tenant(ctx); tenant != nil {
+		tenant.getTask.GetTasks(ctx)
+	}
+}
+
+// GetTaskPayload dispatches to the get task controller of the tenant of the prover.
+func (d *TenantDispatcher) GetTaskPayload(ctx *gin.Context) {
+	if tenant := d.tenant(ctx); tenant != nil {
+		tenant.getTask.GetTaskPayload(ctx)
+	}
+}
+
+// SubmitProof dispatches to the submit proof controller of the tenant of the prover.
+func (d *TenantDispatcher) SubmitProof(ctx *gin.Context) {
+	if tenant := d.tenant(ctx); tenant != nil {
+		tenant.submitProof.SubmitProof(ctx)
+	}
+}
+
+// SubmitProofs dispatches to the submit proof controller of the tenant of the prover.
+func (d *TenantDispatcher) SubmitProofs(ctx *gin.Context) {
+	if tenant := d.tenant(ctx); tenant != nil {
+		tenant.submitProof.SubmitProofs(ctx)
+	}
+}
+
+// ReloadVKs reloads the verifying key registries of the tenants having one, and returns the forks of the registry of
+// the default tenant. A tenant whose registry fails validation keeps its current keys.
+func (d *TenantDispatcher) ReloadVKs() ([]string, error) {
+	names := make([]string, 0, len(d.tenants))
+	for name, tenant := range d.tenants {
+		if tenant.verifier.HasVKRegistry() {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, verifier.ErrNoVKRegistry
+	}
+	sort.Strings(names)
+
+	var forks []string
+	for _, name := range names {
+		tenantForks, err := d.tenants[name].verifier.ReloadVKs()
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", name, err)
+		}
+		if name == "" {
+			forks = tenantForks
+		}
+	}
+	return forks, nil
+}
+
+// tenant returns the controllers of the tenant of the prover, or renders the failure if it is not served, e.g. the
+// token was issued by a coordinator with another tenant config.
+func (d *TenantDispatcher) tenant(ctx *gin.Context) *tenantControllers {
+	name := ctx.GetString(coordinatorType.Tenant)
+	tenant, ok := d.tenants[name]
+	if !ok {
+		types.RenderFailure(ctx, types.ErrCoordinatorUnknownTenant, fmt.Errorf("unknown tenant %q", name))
+		return nil
+	}
+	return tenant
+}
@@ -1,27 +1,22 @@
 package cron
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/log"
 
-	"scroll-tech/common/utils"
+	"scroll-tech/common/crashreport"
 )
 
 func (c *Collector) cleanupChallenge() {
-	defer func() {
-		if err := recover(); err != nil {
-			nerr := fmt.Errorf("clean challenge panic error: %v", err)
-			log.Warn(nerr.Error())
-		}
-	}()
+	defer crashreport.Recover("coordinator_cleanup_challenge")
 
-	ticker := time.NewTicker(time.Minute * 10)
+	ticker := c.clock.NewTicker(time.Minute * 10)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
-			expiredTime := utils.NowUTC().Add(-time.Hour)
+		case <-ticker.C():
+			expiredTime := c.clock.Now().UTC().Add(-time.Hour)
 			if err := c.challenge.DeleteExpireChallenge(c.ctx, expiredTime); err != nil {
 				log.Error("delete expired challenge failure", "error", err)
 			}
@@ -0,0 +1,32 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/crashreport"
+)
+
+func (c *Collector) cleanupProverSession() {
+	defer crashreport.Recover("coordinator_cleanup_prover_session")
+
+	ticker := c.clock.NewTicker(time.Minute * 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			if err := c.proverSession.DeleteExpiredProverSessions(c.ctx, c.clock.Now().UTC()); err != nil {
+				log.Error("delete expired prover session failure", "error", err)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
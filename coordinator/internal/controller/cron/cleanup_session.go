@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/types/message"
+)
+
+const (
+	defaultSessionCleanupInterval = time.Minute
+	defaultSessionCleanupGrace    = 5 * time.Minute
+)
+
+// cleanupSession periodically expires the sessions whose deadline passed more than the grace time ago, e.g. of provers
+// which disappeared, and recycles the active attempts of expired sessions and of sessions which were never stored,
+// so that tasks are not held by provers which disappeared.
+func (c *Collector) cleanupSession() {
+	defer crashreport.Recover("coordinator_cleanup_session")
+
+	interval := defaultSessionCleanupInterval
+	if c.cfg.ProverManager.SessionCleanupIntervalSec > 0 {
+		interval = time.Duration(c.cfg.ProverManager.SessionCleanupIntervalSec) * time.Second
+	}
+	grace := defaultSessionCleanupGrace
+	if c.cfg.ProverManager.SessionCleanupGraceSec > 0 {
+		grace = time.Duration(c.cfg.ProverManager.SessionCleanupGraceSec) * time.Second
+	}
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.sessionCleanupRunTotal.Inc()
+			before := c.clock.Now().UTC().Add(-grace)
+			for _, taskType := range []message.ProofType{message.ProofTypeChunk, message.ProofTypeBatch} {
+				expired, err := c.proverTaskOrm.ExpireOrphanedProverTasks(c.ctx, taskType, c.overrides.CollectionTimeSec(taskType), before)
+				if err != nil {
+					log.Error("expire orphaned prover tasks failure", "task type", taskType.String(), "error", err)
+					continue
+				}
+				if expired > 0 {
+					c.expiredSessionTotal.WithLabelValues(taskType.String()).Add(float64(expired))
+					log.Info("expired orphaned prover sessions", "task type", taskType.String(), "count", expired)
+				}
+			}
+
+			if recycled, err := c.chunkOrm.RecycleLeakedActiveAttempts(c.ctx, before); err != nil {
+				log.Error("recycle leaked chunk active attempts failure", "error", err)
+			} else if recycled > 0 {
+				c.recycledTaskTotal.WithLabelValues(message.ProofTypeChunk.String()).Add(float64(recycled))
+				log.Info("recycled leaked chunk active attempts", "count", recycled)
+			}
+			if recycled, err := c.batchOrm.RecycleLeakedActiveAttempts(c.ctx, before); err != nil {
+				log.Error("recycle leaked batch active attempts failure", "error", err)
+			} else if recycled > 0 {
+				c.recycledTaskTotal.WithLabelValues(message.ProofTypeBatch.String()).Add(float64(recycled))
+				log.Info("recycled leaked batch active attempts", "count", recycled)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
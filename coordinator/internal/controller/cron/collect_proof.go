@@ -2,7 +2,6 @@ package cron
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,10 +9,14 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/clock"
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/database"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/overrides"
 	"scroll-tech/coordinator/internal/orm"
 )
 
@@ -22,6 +25,8 @@ type Collector struct {
 	cfg *config.Config
 	db  *gorm.DB
 	ctx context.Context
+	// clock schedules the timeout checks and cleanups, and tells their deadlines.
+	clock clock.Clock
 
 	stopTimeoutChan chan struct{}
 
@@ -29,25 +34,47 @@ type Collector struct {
 	chunkOrm      *orm.Chunk
 	batchOrm      *orm.Batch
 	challenge     *orm.Challenge
+	proverSession *orm.ProverSession
+
+	taskWatermarkOrm   *orm.TaskWatermark
+	batchDependencyOrm *orm.BatchDependency
+
+	// overrides are the collection times of the config, overridden by the database.
+	overrides *overrides.Overrides
 
 	timeoutBatchCheckerRunTotal     prometheus.Counter
 	batchProverTaskTimeoutTotal     prometheus.Counter
 	timeoutChunkCheckerRunTotal     prometheus.Counter
 	chunkProverTaskTimeoutTotal     prometheus.Counter
 	checkBatchAllChunkReadyRunTotal prometheus.Counter
+	sessionCleanupRunTotal          prometheus.Counter
+	expiredSessionTotal             *prometheus.CounterVec
+	recycledTaskTotal               *prometheus.CounterVec
+	taskGenerationRunTotal          prometheus.Counter
+	taskWatermarkIndex              *prometheus.GaugeVec
+	outstandingTasks                *prometheus.GaugeVec
+	expiredReservationTotal         *prometheus.CounterVec
+	blockedBatchTasks               prometheus.Gauge
+	blockedChunkDependencies        prometheus.Gauge
 }
 
-// NewCollector create a collector to cron collect the data to send to prover
-func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, reg prometheus.Registerer) *Collector {
+// NewCollector create a collector to cron collect the data to send to prover, scheduled on clk
+func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, clk clock.Clock, reg prometheus.Registerer) *Collector {
 	c := &Collector{
 		cfg:             cfg,
 		db:              db,
 		ctx:             ctx,
+		clock:           clk,
 		stopTimeoutChan: make(chan struct{}),
 		proverTaskOrm:   orm.NewProverTask(db),
 		chunkOrm:        orm.NewChunk(db),
 		batchOrm:        orm.NewBatch(db),
 		challenge:       orm.NewChallenge(db),
+		proverSession:   orm.NewProverSession(db),
+
+		taskWatermarkOrm:   orm.NewTaskWatermark(db),
+		batchDependencyOrm: orm.NewBatchDependency(db),
+		overrides:          overrides.New(cfg.ProverManager, db, reg),
 
 		timeoutBatchCheckerRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_batch_timeout_checker_run_total",
@@ -69,12 +96,58 @@ func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, reg prom
 			Name: "coordinator_check_batch_all_chunk_ready_run_total",
 			Help: "Total number of check batch all chunks ready total",
 		}),
+		sessionCleanupRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_session_cleanup_run_total",
+			Help: "Total number of orphaned session cleanup run.",
+		}),
+		expiredSessionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_session_cleanup_expired_session_total",
+			Help: "Total number of orphaned prover sessions expired.",
+		}, []string{"task_type"}),
+		recycledTaskTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_session_cleanup_recycled_task_total",
+			Help: "Total number of tasks whose leaked active attempts were recycled.",
+		}, []string{"task_type"}),
+		taskGenerationRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_task_generation_run_total",
+			Help: "Total number of task watermark advance run.",
+		}),
+		taskWatermarkIndex: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "coordinator_task_watermark_index",
+			Help: "The highest chunk or batch index tasks are assigned for.",
+		}, []string{"task_type"}),
+		outstandingTasks: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "coordinator_outstanding_tasks",
+			Help: "Number of unassigned and assigned tasks up to the task watermark.",
+		}, []string{"task_type"}),
+		expiredReservationTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_expired_reservation_total",
+			Help: "Total number of tasks reserved by a prefetch whose reservation expired.",
+		}, []string{"task_type"}),
+		blockedBatchTasks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "coordinator_blocked_batch_tasks",
+			Help: "Number of batch tasks waiting for chunk proofs.",
+		}),
+		blockedChunkDependencies: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "coordinator_blocked_chunk_dependencies",
+			Help: "Number of chunk proofs the blocked batch tasks wait for.",
+		}),
 	}
 
+	c.overrides.Start(ctx)
+
 	go c.timeoutBatchProofTask()
 	go c.timeoutChunkProofTask()
 	go c.checkBatchAllChunkReady()
 	go c.cleanupChallenge()
+	go c.cleanupProverSession()
+	go c.cleanupSession()
+	if c.cfg.ProverManager.TaskGeneration != nil {
+		go c.generateTask()
+	}
+	if c.cfg.ProverManager.TaskPrefetch != nil {
+		go c.expireReservation()
+	}
 
 	log.Info("Start coordinator cron successfully.")
 
@@ -89,20 +162,16 @@ func (c *Collector) Stop() {
 // timeoutTask cron check the send task is timeout. if timeout reached, restore the
 // chunk/batch task to unassigned. then the batch/chunk collector can retry it.
 func (c *Collector) timeoutBatchProofTask() {
-	defer func() {
-		if err := recover(); err != nil {
-			nerr := fmt.Errorf("timeout batch proof task panic error:%v", err)
-			log.Warn(nerr.Error())
-		}
-	}()
+	defer crashreport.Recover("coordinator_timeout_batch_proof_task")
 
-	ticker := time.NewTicker(time.Second * 2)
+	ticker := c.clock.NewTicker(time.Second * 2)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			c.timeoutBatchCheckerRunTotal.Inc()
-			timeout := time.Duration(c.cfg.ProverManager.BatchCollectionTimeSec) * time.Second
-			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeBatch, timeout)
+			timeout := time.Duration(c.overrides.CollectionTimeSec(message.ProofTypeBatch)) * time.Second
+			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeBatch, c.clock.Now().UTC().Add(-timeout))
 			if err != nil {
 				log.Error("get unassigned session info failure", "error", err)
 				break
@@ -121,20 +190,16 @@ func (c *Collector) timeoutBatchProofTask() {
 }
 
 func (c *Collector) timeoutChunkProofTask() {
-	defer func() {
-		if err := recover(); err != nil {
-			nerr := fmt.Errorf("timeout proof chunk task panic error:%v", err)
-			log.Warn(nerr.Error())
-		}
-	}()
+	defer crashreport.Recover("coordinator_timeout_chunk_proof_task")
 
-	ticker := time.NewTicker(time.Second * 2)
+	ticker := c.clock.NewTicker(time.Second * 2)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			c.timeoutChunkCheckerRunTotal.Inc()
-			timeout := time.Duration(c.cfg.ProverManager.ChunkCollectionTimeSec) * time.Second
-			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeChunk, timeout)
+			timeout := time.Duration(c.overrides.CollectionTimeSec(message.ProofTypeChunk)) * time.Second
+			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeChunk, c.clock.Now().UTC().Add(-timeout))
 			if err != nil {
 				log.Error("get unassigned session info failure", "error", err)
 				break
@@ -166,7 +231,7 @@ func (c *Collector) check(assignedProverTasks []orm.ProverTask, timeout promethe
 		log.Warn("proof task have reach the timeout", "task id", assignedProverTask.TaskID,
 			"prover public key", assignedProverTask.ProverPublicKey, "prover name", assignedProverTask.ProverName, "task type", assignedProverTask.TaskType)
 
-		err := c.db.Transaction(func(tx *gorm.DB) error {
+		err := database.TransactionWithRetry(c.ctx, c.db, func(tx *gorm.DB) error {
 			if err := c.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(c.ctx, assignedProverTask.UUID, types.ProverProofInvalid, types.ProverTaskFailureTypeTimeout, tx); err != nil {
 				log.Error("update prover task proving status failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
 				return err
@@ -204,17 +269,13 @@ func (c *Collector) check(assignedProverTasks []orm.ProverTask, timeout promethe
 }
 
 func (c *Collector) checkBatchAllChunkReady() {
-	defer func() {
-		if err := recover(); err != nil {
-			nerr := fmt.Errorf("check batch all chunk ready panic error:%v", err)
-			log.Warn(nerr.Error())
-		}
-	}()
+	defer crashreport.Recover("coordinator_check_batch_all_chunk_ready")
 
-	ticker := time.NewTicker(time.Second * 10)
+	ticker := c.clock.NewTicker(time.Second * 10)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			c.checkBatchAllChunkReadyRunTotal.Inc()
 			page := 1
 			pageSize := 50
@@ -227,18 +288,20 @@ func (c *Collector) checkBatchAllChunkReady() {
 				}
 
 				for _, batch := range batches {
-					allReady, checkErr := c.chunkOrm.CheckIfBatchChunkProofsAreReady(c.ctx, batch.Hash)
-					if checkErr != nil {
-						log.Warn("checkBatchAllChunkReady CheckIfBatchChunkProofsAreReady failure", "error", checkErr, "hash", batch.Hash)
+					// the dependencies of the batches created since are recorded, and the ones verified without the
+					// proof receiver marking them, e.g. before a restart, catch up.
+					unverified, syncErr := c.batchDependencyOrm.SyncBatchDependencies(c.ctx, batch.Hash)
+					if syncErr != nil {
+						log.Warn("checkBatchAllChunkReady SyncBatchDependencies failure", "error", syncErr, "hash", batch.Hash)
 						continue
 					}
 
-					if !allReady {
+					if unverified > 0 {
 						continue
 					}
 
 					if updateErr := c.batchOrm.UpdateChunkProofsStatusByBatchHash(c.ctx, batch.Hash, types.ChunkProofsStatusReady); updateErr != nil {
-						log.Warn("checkBatchAllChunkReady UpdateChunkProofsStatusByBatchHash failure", "error", checkErr, "hash", batch.Hash)
+						log.Warn("checkBatchAllChunkReady UpdateChunkProofsStatusByBatchHash failure", "error", updateErr, "hash", batch.Hash)
 					}
 				}
 
@@ -248,6 +311,14 @@ func (c *Collector) checkBatchAllChunkReady() {
 				page++
 			}
 
+			blockedBatches, blockedChunks, err := c.batchDependencyOrm.CountBlocked(c.ctx)
+			if err != nil {
+				log.Warn("checkBatchAllChunkReady CountBlocked failure", "error", err)
+				continue
+			}
+			c.blockedBatchTasks.Set(float64(blockedBatches))
+			c.blockedChunkDependencies.Set(float64(blockedChunks))
+
 		case <-c.ctx.Done():
 			if c.ctx.Err() != nil {
 				log.Error("manager context canceled with error", "error", c.ctx.Err())
@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/database"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// expireReservation periodically ends the reservations of the tasks prefetched by provers which did not ask for them
+// before the end of the reservation, and gives back the attempts counted for them so the tasks are assigned again.
+func (c *Collector) expireReservation() {
+	defer crashreport.Recover("coordinator_expire_reservation")
+
+	ticker := c.clock.NewTicker(time.Second * 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			reservedProverTasks, err := c.proverTaskOrm.GetExpiredReservedProverTasks(c.ctx, 100, c.clock.Now().UTC())
+			if err != nil {
+				log.Error("get expired reserved prover tasks failure", "error", err)
+				break
+			}
+			for _, reservedProverTask := range reservedProverTasks {
+				c.releaseReservation(reservedProverTask)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
+
+// releaseReservation expires a reserved prover task and gives back the attempt of its chunk or batch, unless the
+// prover was assigned the task meanwhile.
+func (c *Collector) releaseReservation(proverTask *orm.ProverTask) {
+	taskType := message.ProofType(proverTask.TaskType)
+	var released bool
+	err := database.TransactionWithRetry(c.ctx, c.db, func(tx *gorm.DB) error {
+		var err error
+		released, err = c.proverTaskOrm.ExpireReservedProverTask(c.ctx, proverTask.UUID, tx)
+		if err != nil || !released {
+			return err
+		}
+		switch taskType {
+		case message.ProofTypeChunk:
+			return c.chunkOrm.ReleaseReservedAttemptsByHash(c.ctx, proverTask.TaskID, tx)
+		case message.ProofTypeBatch:
+			return c.batchOrm.ReleaseReservedAttemptsByHash(c.ctx, proverTask.TaskID, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("expire reserved prover task failure", "uuid", proverTask.UUID, "hash", proverTask.TaskID, "pubKey", proverTask.ProverPublicKey, "error", err)
+		return
+	}
+	if released {
+		c.expiredReservationTotal.WithLabelValues(taskType.String()).Inc()
+		log.Info("prover task reservation expired", "task type", taskType.String(), "hash", proverTask.TaskID, "pubKey", proverTask.ProverPublicKey)
+	}
+}
@@ -0,0 +1,69 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/crashreport"
+	"scroll-tech/common/types/message"
+)
+
+const defaultTaskGenerationInterval = 10 * time.Second
+
+// generateTask periodically advances the task watermark of each task type with a budget of outstanding tasks, up to
+// the chunk or batch index keeping the unassigned and assigned tasks within the budget. Tasks are assigned up to the
+// watermarks only, the tasks beyond them are generated as the outstanding ones are proven.
+func (c *Collector) generateTask() {
+	defer crashreport.Recover("coordinator_generate_task")
+
+	taskGeneration := c.cfg.ProverManager.TaskGeneration
+	interval := defaultTaskGenerationInterval
+	if taskGeneration.IntervalSec > 0 {
+		interval = time.Duration(taskGeneration.IntervalSec) * time.Second
+	}
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.taskGenerationRunTotal.Inc()
+			for _, taskType := range []message.ProofType{message.ProofTypeChunk, message.ProofTypeBatch} {
+				budget := taskGeneration.MaxOutstandingTasks(taskType)
+				if budget <= 0 {
+					continue
+				}
+
+				var maxIndex uint64
+				var outstanding int64
+				var err error
+				switch taskType {
+				case message.ProofTypeChunk:
+					maxIndex, outstanding, err = c.chunkOrm.GetTaskWatermark(c.ctx, budget)
+				case message.ProofTypeBatch:
+					maxIndex, outstanding, err = c.batchOrm.GetTaskWatermark(c.ctx, budget)
+				}
+				if err != nil {
+					log.Error("get task watermark failure", "task type", taskType.String(), "error", err)
+					continue
+				}
+				if err = c.taskWatermarkOrm.UpsertTaskWatermark(c.ctx, taskType, maxIndex, outstanding); err != nil {
+					log.Error("update task watermark failure", "task type", taskType.String(), "error", err)
+					continue
+				}
+				c.taskWatermarkIndex.WithLabelValues(taskType.String()).Set(float64(maxIndex))
+				c.outstandingTasks.WithLabelValues(taskType.String()).Set(float64(outstanding))
+				log.Debug("advanced task watermark", "task type", taskType.String(), "max index", maxIndex, "outstanding tasks", outstanding, "budget", budget)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
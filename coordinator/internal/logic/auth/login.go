@@ -1,25 +1,116 @@
 package auth
 
 import (
-	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
 
-	"scroll-tech/coordinator/internal/orm"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
 )
 
 // LoginLogic the auth logic
 type LoginLogic struct {
-	challengeOrm *orm.Challenge
+	cfg   *config.Config
+	store SessionStore
 }
 
 // NewLoginLogic new a LoginLogic
-func NewLoginLogic(db *gorm.DB) *LoginLogic {
+func NewLoginLogic(cfg *config.Config, store SessionStore) *LoginLogic {
 	return &LoginLogic{
-		challengeOrm: orm.NewChallenge(db),
+		cfg:   cfg,
+		store: store,
+	}
+}
+
+// IssueChallenge generates a random challenge nonce and stores it until it expires,
+// so the nonce can be used at login on any coordinator replica sharing the store, also after a restart.
+func (l *LoginLogic) IssueChallenge(ctx context.Context) (string, error) {
+	nonce, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("generate challenge nonce failure: %w", err)
+	}
+
+	expiredAt := utils.NowUTC().Add(time.Second * time.Duration(l.cfg.Auth.ChallengeExpireDurationSec))
+	if err := l.store.InsertChallenge(ctx, nonce, expiredAt); err != nil {
+		return "", err
 	}
+	return nonce, nil
+}
+
+// UseChallenge checks the challenge nonce was issued and has not expired, and marks it as used so it can not be replayed.
+func (l *LoginLogic) UseChallenge(ctx context.Context, nonce string) error {
+	return l.store.UseChallenge(ctx, nonce, utils.NowUTC())
 }
 
-// InsertChallengeString insert and check the challenge string is existed
-func (l *LoginLogic) InsertChallengeString(ctx *gin.Context, challenge string) error {
-	return l.challengeOrm.InsertChallenge(ctx, challenge)
+// CreateSession stores a login session of the prover until the login token expires, and returns its id.
+// If the task data is encrypted, the task data key of the session is derived from the session public key of the
+// prover and an ephemeral key of the coordinator, whose public key is returned to be sent back to the prover.
+func (l *LoginLogic) CreateSession(ctx context.Context, publicKey, proverName, proverVersion, proverSessionPublicKey string) (string, string, error) {
+	sessionID, err := randomToken()
+	if err != nil {
+		return "", "", fmt.Errorf("generate session id failure: %w", err)
+	}
+
+	session := Session{
+		SessionID:     sessionID,
+		PublicKey:     publicKey,
+		ProverName:    proverName,
+		ProverVersion: proverVersion,
+		ExpiredAt:     utils.NowUTC().Add(time.Second * time.Duration(l.cfg.Auth.LoginExpireDurationSec)),
+	}
+
+	var sessionPublicKey string
+	if encryption := l.cfg.Auth.TaskDataEncryption; encryption != nil && encryption.Enabled {
+		if proverSessionPublicKey == "" {
+			if encryption.Required {
+				return "", "", errors.New("the task data encryption is required, but the prover sent no session public key")
+			}
+		} else {
+			sessionKey, err := crypto.GenerateKey()
+			if err != nil {
+				return "", "", fmt.Errorf("generate session key failure: %w", err)
+			}
+			taskDataKey, err := message.DeriveTaskDataKey(sessionKey, proverSessionPublicKey)
+			if err != nil {
+				return "", "", fmt.Errorf("derive task data key failure: %w", err)
+			}
+			session.TaskDataKey = common.Bytes2Hex(taskDataKey)
+			sessionPublicKey = common.Bytes2Hex(crypto.CompressPubkey(&sessionKey.PublicKey))
+		}
+	}
+
+	if err := l.store.InsertSession(ctx, &session); err != nil {
+		return "", "", err
+	}
+	return sessionID, sessionPublicKey, nil
+}
+
+// CheckSession checks the login session of the prover is stored and has not expired,
+// and returns the hex encoded task data key of the session, empty if the task data is not encrypted.
+func (l *LoginLogic) CheckSession(ctx context.Context, sessionID, publicKey string) (string, error) {
+	session, err := l.store.GetSession(ctx, sessionID, publicKey, utils.NowUTC())
+	if err != nil {
+		return "", err
+	}
+	if session == nil {
+		return "", fmt.Errorf("the session of prover %s is unknown or expired", publicKey)
+	}
+	return session.TaskDataKey, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
 }
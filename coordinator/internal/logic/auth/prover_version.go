@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"scroll-tech/common/version"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+// Endpoints whose requests are checked against the prover version gate.
+const (
+	ProverVersionEndpointLogin   = "login"
+	ProverVersionEndpointGetTask = "get_task"
+)
+
+// ErrProverVersionTooLow the prover version is below the minimum prover version
+var ErrProverVersionTooLow = errors.New("incompatible prover version")
+
+// ProverVersionGate rejects provers older than the minimum prover version and flags provers older than the
+// deprecated prover version, so that circuit upgrades can be announced before old provers are cut off.
+type ProverVersionGate struct {
+	minVersion        string
+	deprecatedVersion string
+
+	proverVersionTotal           *prometheus.CounterVec
+	proverVersionRejectedTotal   *prometheus.CounterVec
+	proverVersionDeprecatedTotal *prometheus.CounterVec
+}
+
+// NewProverVersionGate returns a ProverVersionGate configured by the prover manager config.
+func NewProverVersionGate(cfg *config.ProverManager, reg prometheus.Registerer) *ProverVersionGate {
+	return &ProverVersionGate{
+		minVersion:        cfg.MinProverVersion,
+		deprecatedVersion: cfg.DeprecatedProverVersion,
+		proverVersionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_prover_version_total",
+			Help: "Total number of prover requests by prover version tag.",
+		}, []string{"endpoint", "version"}),
+		proverVersionRejectedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_prover_version_rejected_total",
+			Help: "Total number of prover requests rejected for an incompatible prover version.",
+		}, []string{"endpoint"}),
+		proverVersionDeprecatedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_prover_version_deprecated_total",
+			Help: "Total number of prover requests with a deprecated prover version.",
+		}, []string{"endpoint"}),
+	}
+}
+
+// Check checks the prover version of a request to the given endpoint. It returns an error if the version is below
+// the minimum prover version, or else a non-empty deprecation warning if the version is below the deprecated version.
+func (g *ProverVersionGate) Check(endpoint, proverVersion string) (string, error) {
+	if !version.CheckScrollRepoVersion(proverVersion, g.minVersion) {
+		g.proverVersionRejectedTotal.WithLabelValues(endpoint).Inc()
+		return "", fmt.Errorf("%w. please upgrade your prover, minimum allowed version: %s, actual version: %s", ErrProverVersionTooLow, g.minVersion, proverVersion)
+	}
+
+	// the version is a valid semver here, only its tag is used as label to bound the cardinality.
+	g.proverVersionTotal.WithLabelValues(endpoint, versionTag(proverVersion)).Inc()
+
+	if g.deprecatedVersion == "" || version.CheckScrollRepoVersion(proverVersion, g.deprecatedVersion) {
+		return "", nil
+	}
+	g.proverVersionDeprecatedTotal.WithLabelValues(endpoint).Inc()
+	return fmt.Sprintf("prover version %s is deprecated, please upgrade to %s or later", proverVersion, g.deprecatedVersion), nil
+}
+
+// versionTag returns the "vX.Y.Z" tag of a prover version in the format of "tag-commit-scroll_prover-halo2".
+func versionTag(proverVersion string) string {
+	return strings.Split(proverVersion, "-")[0]
+}
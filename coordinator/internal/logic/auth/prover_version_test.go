@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestProverVersionGate(t *testing.T) {
+	gate := NewProverVersionGate(&config.ProverManager{MinProverVersion: "v4.1.0", DeprecatedProverVersion: "v4.2.0"}, nil)
+
+	_, err := gate.Check(ProverVersionEndpointLogin, "v4.0.9-abcdef1-000000-000000")
+	assert.EqualError(t, err, "incompatible prover version. please upgrade your prover, minimum allowed version: v4.1.0, actual version: v4.0.9-abcdef1-000000-000000")
+
+	_, err = gate.Check(ProverVersionEndpointLogin, "invalid")
+	assert.Error(t, err)
+
+	deprecation, err := gate.Check(ProverVersionEndpointGetTask, "v4.1.5-abcdef1-000000-000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "prover version v4.1.5-abcdef1-000000-000000 is deprecated, please upgrade to v4.2.0 or later", deprecation)
+
+	deprecation, err = gate.Check(ProverVersionEndpointGetTask, "v4.2.0-abcdef1-000000-000000")
+	assert.NoError(t, err)
+	assert.Empty(t, deprecation)
+
+	gate = NewProverVersionGate(&config.ProverManager{MinProverVersion: "v4.1.0"}, nil)
+	deprecation, err = gate.Check(ProverVersionEndpointGetTask, "v4.1.0")
+	assert.NoError(t, err)
+	assert.Empty(t, deprecation)
+
+	assert.Equal(t, "v4.1.5", versionTag("v4.1.5-abcdef1-000000-000000"))
+}
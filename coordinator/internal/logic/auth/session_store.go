@@ -0,0 +1,345 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+)
+
+const (
+	defaultRedisKeyPrefix = "coordinator:"
+
+	memoryStorePruneInterval = time.Minute
+)
+
+// Session is the login session of a prover.
+type Session struct {
+	SessionID     string    `json:"session_id"`
+	PublicKey     string    `json:"public_key"`
+	ProverName    string    `json:"prover_name"`
+	ProverVersion string    `json:"prover_version"`
+	ExpiredAt     time.Time `json:"expired_at"`
+	// TaskDataKey is the hex encoded key encrypting the task data sent to the prover, empty if not negotiated.
+	TaskDataKey string `json:"task_data_key"`
+}
+
+// SessionStore stores the challenge nonces issued to the provers and their login sessions.
+type SessionStore interface {
+	// InsertChallenge stores an issued challenge nonce, which can be used once before expiredAt.
+	InsertChallenge(ctx context.Context, nonce string, expiredAt time.Time) error
+	// UseChallenge marks an issued challenge nonce as used, it fails if the nonce is unknown, expired or used.
+	UseChallenge(ctx context.Context, nonce string, now time.Time) error
+	// InsertSession stores a login session until it expires.
+	InsertSession(ctx context.Context, session *Session) error
+	// GetSession returns the session of the session id and public key which has not expired, nil if there is none.
+	GetSession(ctx context.Context, sessionID, publicKey string, now time.Time) (*Session, error)
+	// ListSessions returns the sessions which have not expired, e.g. to snapshot the logged in provers.
+	ListSessions(ctx context.Context, now time.Time) ([]*Session, error)
+}
+
+// NewSessionStore creates the session store of cfg, the database store if cfg is nil.
+func NewSessionStore(cfg *config.SessionStore, db *gorm.DB) (SessionStore, error) {
+	if cfg == nil {
+		return newDBSessionStore(db), nil
+	}
+	switch cfg.Type {
+	case config.SessionStoreDB:
+		return newDBSessionStore(db), nil
+	case config.SessionStoreMemory:
+		return newMemorySessionStore(), nil
+	case config.SessionStoreRedis:
+		return newRedisSessionStore(cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unknown session store type %q", cfg.Type)
+	}
+}
+
+func challengeUnusableError(nonce string) error {
+	return fmt.Errorf("the challenge string:%s is unknown, expired or has been used", nonce)
+}
+
+// dbSessionStore stores the challenges and sessions in the challenge and prover_session tables.
+type dbSessionStore struct {
+	challengeOrm     *orm.Challenge
+	proverSessionOrm *orm.ProverSession
+}
+
+func newDBSessionStore(db *gorm.DB) *dbSessionStore {
+	return &dbSessionStore{
+		challengeOrm:     orm.NewChallenge(db),
+		proverSessionOrm: orm.NewProverSession(db),
+	}
+}
+
+func (s *dbSessionStore) InsertChallenge(ctx context.Context, nonce string, expiredAt time.Time) error {
+	return s.challengeOrm.InsertChallenge(ctx, nonce, expiredAt)
+}
+
+func (s *dbSessionStore) UseChallenge(ctx context.Context, nonce string, now time.Time) error {
+	return s.challengeOrm.UseChallenge(ctx, nonce, now)
+}
+
+func (s *dbSessionStore) InsertSession(ctx context.Context, session *Session) error {
+	return s.proverSessionOrm.InsertProverSession(ctx, &orm.ProverSession{
+		SessionID:     session.SessionID,
+		PublicKey:     session.PublicKey,
+		ProverName:    session.ProverName,
+		ProverVersion: session.ProverVersion,
+		ExpiredAt:     session.ExpiredAt,
+		TaskDataKey:   session.TaskDataKey,
+	})
+}
+
+func (s *dbSessionStore) GetSession(ctx context.Context, sessionID, publicKey string, now time.Time) (*Session, error) {
+	session, err := s.proverSessionOrm.GetProverSession(ctx, sessionID, publicKey, now)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	return newSessionFromOrm(session), nil
+}
+
+func (s *dbSessionStore) ListSessions(ctx context.Context, now time.Time) ([]*Session, error) {
+	proverSessions, err := s.proverSessionOrm.GetProverSessions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(proverSessions))
+	for _, session := range proverSessions {
+		sessions = append(sessions, newSessionFromOrm(session))
+	}
+	return sessions, nil
+}
+
+func newSessionFromOrm(session *orm.ProverSession) *Session {
+	return &Session{
+		SessionID:     session.SessionID,
+		PublicKey:     session.PublicKey,
+		ProverName:    session.ProverName,
+		ProverVersion: session.ProverVersion,
+		ExpiredAt:     session.ExpiredAt,
+		TaskDataKey:   session.TaskDataKey,
+	}
+}
+
+// memorySessionStore stores the challenges and sessions in process memory, they are lost on restart and not
+// shared with other replicas. The expired ones are pruned while inserting.
+type memorySessionStore struct {
+	mu         sync.Mutex
+	challenges map[string]time.Time // expiry by nonce
+	sessions   map[string]*Session  // by session id
+	lastPruned time.Time
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{
+		challenges: make(map[string]time.Time),
+		sessions:   make(map[string]*Session),
+	}
+}
+
+func (s *memorySessionStore) InsertChallenge(_ context.Context, nonce string, expiredAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.challenges[nonce]; ok {
+		return fmt.Errorf("the challenge string:%s has been issued already", nonce)
+	}
+	s.challenges[nonce] = expiredAt
+	s.prune(time.Now())
+	return nil
+}
+
+func (s *memorySessionStore) UseChallenge(_ context.Context, nonce string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiredAt, ok := s.challenges[nonce]
+	if !ok || !expiredAt.After(now) {
+		return challengeUnusableError(nonce)
+	}
+	delete(s.challenges, nonce)
+	return nil
+}
+
+func (s *memorySessionStore) InsertSession(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *session
+	s.sessions[session.SessionID] = &stored
+	s.prune(time.Now())
+	return nil
+}
+
+func (s *memorySessionStore) GetSession(_ context.Context, sessionID, publicKey string, now time.Time) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok || session.PublicKey != publicKey || !session.ExpiredAt.After(now) {
+		return nil, nil
+	}
+	found := *session
+	return &found, nil
+}
+
+func (s *memorySessionStore) ListSessions(_ context.Context, now time.Time) ([]*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.ExpiredAt.After(now) {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ExpiredAt.Before(sessions[j].ExpiredAt) })
+	return sessions, nil
+}
+
+// prune deletes the expired challenges and sessions, at most once per prune interval.
+func (s *memorySessionStore) prune(now time.Time) {
+	if now.Sub(s.lastPruned) < memoryStorePruneInterval {
+		return
+	}
+	s.lastPruned = now
+	for nonce, expiredAt := range s.challenges {
+		if !expiredAt.After(now) {
+			delete(s.challenges, nonce)
+		}
+	}
+	for sessionID, session := range s.sessions {
+		if !session.ExpiredAt.After(now) {
+			delete(s.sessions, sessionID)
+		}
+	}
+}
+
+// redisSessionStore stores the challenges and sessions in redis, expiring with them.
+type redisSessionStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+func newRedisSessionStore(cfg *config.RedisConfig) (*redisSessionStore, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, errors.New("the redis session store requires a redis address")
+	}
+	opts := &redis.Options{
+		Addr:     cfg.Address,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	keyPrefix := cfg.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = defaultRedisKeyPrefix
+	}
+	return &redisSessionStore{client: redis.NewClient(opts), keyPrefix: keyPrefix}, nil
+}
+
+func (s *redisSessionStore) challengeKey(nonce string) string {
+	return s.keyPrefix + "challenge:" + nonce
+}
+
+func (s *redisSessionStore) sessionKey(sessionID string) string {
+	return s.keyPrefix + "session:" + sessionID
+}
+
+func (s *redisSessionStore) InsertChallenge(ctx context.Context, nonce string, expiredAt time.Time) error {
+	ttl := time.Until(expiredAt)
+	if ttl <= 0 {
+		return nil
+	}
+	inserted, err := s.client.SetNX(ctx, s.challengeKey(nonce), 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("redisSessionStore.InsertChallenge error: %w", err)
+	}
+	if !inserted {
+		return fmt.Errorf("the challenge string:%s has been issued already", nonce)
+	}
+	return nil
+}
+
+// UseChallenge deletes the nonce, only one of concurrent logins with the same nonce deletes it. Redis expires the
+// nonce, now is not used.
+func (s *redisSessionStore) UseChallenge(ctx context.Context, nonce string, _ time.Time) error {
+	deleted, err := s.client.Del(ctx, s.challengeKey(nonce)).Result()
+	if err != nil {
+		return fmt.Errorf("redisSessionStore.UseChallenge error: %w", err)
+	}
+	if deleted == 0 {
+		return challengeUnusableError(nonce)
+	}
+	return nil
+}
+
+func (s *redisSessionStore) InsertSession(ctx context.Context, session *Session) error {
+	ttl := time.Until(session.ExpiredAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("redisSessionStore.InsertSession error: %w, public key: %v", err, session.PublicKey)
+	}
+	if err := s.client.Set(ctx, s.sessionKey(session.SessionID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("redisSessionStore.InsertSession error: %w, public key: %v", err, session.PublicKey)
+	}
+	return nil
+}
+
+func (s *redisSessionStore) GetSession(ctx context.Context, sessionID, publicKey string, now time.Time) (*Session, error) {
+	data, err := s.client.Get(ctx, s.sessionKey(sessionID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redisSessionStore.GetSession error: %w, public key: %v", err, publicKey)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("redisSessionStore.GetSession error: %w, public key: %v", err, publicKey)
+	}
+	if session.PublicKey != publicKey || !session.ExpiredAt.After(now) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// ListSessions scans the session keys, the sessions expiring during the scan may be missing.
+func (s *redisSessionStore) ListSessions(ctx context.Context, now time.Time) ([]*Session, error) {
+	var sessions []*Session
+	iter := s.client.Scan(ctx, 0, s.sessionKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		data, err := s.client.Get(ctx, iter.Val()).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("redisSessionStore.ListSessions error: %w", err)
+		}
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, fmt.Errorf("redisSessionStore.ListSessions error: %w, key: %v", err, iter.Val())
+		}
+		if session.ExpiredAt.After(now) {
+			sessions = append(sessions, &session)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("redisSessionStore.ListSessions error: %w", err)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ExpiredAt.Before(sessions[j].ExpiredAt) })
+	return sessions, nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	store, err := NewSessionStore(&config.SessionStore{Type: config.SessionStoreMemory}, nil)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now()
+
+	// a challenge is used once before it expires.
+	assert.NoError(t, store.InsertChallenge(ctx, "nonce", now.Add(time.Minute)))
+	assert.Error(t, store.InsertChallenge(ctx, "nonce", now.Add(time.Minute)))
+	assert.NoError(t, store.UseChallenge(ctx, "nonce", now))
+	assert.Error(t, store.UseChallenge(ctx, "nonce", now))
+	assert.Error(t, store.UseChallenge(ctx, "unknown", now))
+	assert.NoError(t, store.InsertChallenge(ctx, "expiring", now.Add(time.Minute)))
+	assert.Error(t, store.UseChallenge(ctx, "expiring", now.Add(2*time.Minute)))
+
+	session := &Session{SessionID: "session", PublicKey: "prover", ProverName: "name", ExpiredAt: now.Add(time.Hour), TaskDataKey: "key"}
+	assert.NoError(t, store.InsertSession(ctx, session))
+	found, err := store.GetSession(ctx, "session", "prover", now)
+	assert.NoError(t, err)
+	assert.Equal(t, session, found)
+
+	// the session is only found with its public key and before it expires.
+	found, err = store.GetSession(ctx, "session", "other prover", now)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+	found, err = store.GetSession(ctx, "session", "prover", now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	// the sessions which have not expired are listed, the soonest expiring first.
+	other := &Session{SessionID: "other session", PublicKey: "other prover", ExpiredAt: now.Add(time.Minute)}
+	assert.NoError(t, store.InsertSession(ctx, other))
+	sessions, err := store.ListSessions(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Session{other, session}, sessions)
+	sessions, err = store.ListSessions(ctx, now.Add(30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Session{session}, sessions)
+
+	_, err = NewSessionStore(&config.SessionStore{Type: config.SessionStoreRedis}, nil)
+	assert.Error(t, err)
+}
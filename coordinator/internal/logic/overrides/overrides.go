@@ -0,0 +1,189 @@
+package overrides
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// Names of the parameters overridden by the rows of the coordinator_config_override table.
+const (
+	ChunkCollectionTimeSec    = "chunk_collection_time_sec"
+	BatchCollectionTimeSec    = "batch_collection_time_sec"
+	MaxAssignedTasksPerProver = "max_assigned_tasks_per_prover"
+	VerifierForkName          = "verifier_fork_name"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// Overrides are the parameters of the prover manager config with the overrides of the database applied, reloaded
+// periodically. It is safe for concurrent use.
+type Overrides struct {
+	cfg               *config.ProverManager
+	configOverrideOrm *orm.ConfigOverride // nil if the parameters are not overridden
+	reloadInterval    time.Duration
+
+	mu     sync.RWMutex
+	values map[string]string // the valid overrides by name
+
+	overriddenGauge    *prometheus.GaugeVec
+	reloadFailureTotal prometheus.Counter
+}
+
+// New creates the parameters of cfg, overridden by the coordinator_config_override table of db if cfg enables it.
+func New(cfg *config.ProverManager, db *gorm.DB, reg prometheus.Registerer) *Overrides {
+	o := &Overrides{
+		cfg:            cfg,
+		reloadInterval: defaultReloadInterval,
+		values:         make(map[string]string),
+		overriddenGauge: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "coordinator_config_overridden",
+			Help: "Whether a coordinator config parameter is overridden by the database.",
+		}, []string{"name"}),
+		reloadFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_config_override_reload_failure_total",
+			Help: "Total number of failed reloads of the coordinator config overrides.",
+		}),
+	}
+	if cfg.ConfigOverrides != nil {
+		o.configOverrideOrm = orm.NewConfigOverride(db)
+		if cfg.ConfigOverrides.ReloadIntervalSec > 0 {
+			o.reloadInterval = time.Duration(cfg.ConfigOverrides.ReloadIntervalSec) * time.Second
+		}
+	}
+	return o
+}
+
+// CollectionTimeSec returns the proof collection time (in seconds) of the task type, after which its prover tasks
+// time out.
+func (o *Overrides) CollectionTimeSec(taskType message.ProofType) int {
+	switch taskType {
+	case message.ProofTypeChunk:
+		return o.intOr(ChunkCollectionTimeSec, o.cfg.ChunkCollectionTimeSec)
+	case message.ProofTypeBatch:
+		return o.intOr(BatchCollectionTimeSec, o.cfg.BatchCollectionTimeSec)
+	default:
+		return 0
+	}
+}
+
+// MaxAssignedTasksPerProver returns the number of tasks a prover is assigned at the same time, at least 1.
+func (o *Overrides) MaxAssignedTasksPerProver() int {
+	if maxAssigned := o.intOr(MaxAssignedTasksPerProver, o.cfg.MaxAssignedTasksPerProver); maxAssigned > 0 {
+		return maxAssigned
+	}
+	return 1
+}
+
+// VerifierForkName returns the hard fork the proofs are verified for, the proofs of the tasks of other forks are
+// rejected before verification. Empty accepts the tasks of all forks.
+func (o *Overrides) VerifierForkName() string {
+	o.mu.RLock()
+	forkName, ok := o.values[VerifierForkName]
+	o.mu.RUnlock()
+	if ok {
+		return forkName
+	}
+	if o.cfg.Verifier == nil {
+		return ""
+	}
+	return o.cfg.Verifier.ForkName
+}
+
+func (o *Overrides) intOr(name string, defaultValue int) int {
+	o.mu.RLock()
+	value, ok := o.values[name]
+	o.mu.RUnlock()
+	if !ok {
+		return defaultValue
+	}
+	// the values are validated on reload.
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// Reload reloads the overrides of the database, the previous overrides stay in effect if it fails. The rows of
+// unknown parameters and of invalid values are ignored.
+func (o *Overrides) Reload(ctx context.Context) error {
+	if o.configOverrideOrm == nil {
+		return nil
+	}
+	rows, err := o.configOverrideOrm.GetConfigOverrides(ctx)
+	if err != nil {
+		o.reloadFailureTotal.Inc()
+		return fmt.Errorf("failed to load config overrides: %w", err)
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if err = validate(row.Name, row.Value); err != nil {
+			log.Warn("ignoring invalid config override", "name", row.Name, "value", row.Value, "err", err)
+			continue
+		}
+		values[row.Name] = row.Value
+	}
+
+	o.mu.Lock()
+	for name, value := range values {
+		if previous, ok := o.values[name]; !ok || previous != value {
+			log.Info("config parameter overridden", "name", name, "value", value)
+		}
+	}
+	for name := range o.values {
+		if _, ok := values[name]; !ok {
+			log.Info("config parameter override removed", "name", name)
+		}
+	}
+	o.values = values
+	o.mu.Unlock()
+
+	o.overriddenGauge.Reset()
+	for name := range values {
+		o.overriddenGauge.WithLabelValues(name).Set(1)
+	}
+	return nil
+}
+
+// Start reloads the overrides periodically until ctx is done, it does nothing if the parameters are not overridden.
+func (o *Overrides) Start(ctx context.Context) {
+	if o.configOverrideOrm == nil {
+		return
+	}
+	go utils.LoopWithContext(ctx, o.reloadInterval, func(ctx context.Context) {
+		if err := o.Reload(ctx); err != nil {
+			log.Warn("failed to reload config overrides", "error", err)
+		}
+	})
+}
+
+// validate checks the value of an overridden parameter.
+func validate(name, value string) error {
+	switch name {
+	case ChunkCollectionTimeSec, BatchCollectionTimeSec, MaxAssignedTasksPerProver:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+		return nil
+	case VerifierForkName:
+		return nil
+	default:
+		return fmt.Errorf("unknown parameter %s", name)
+	}
+}
@@ -0,0 +1,48 @@
+package overrides
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestOverrides(t *testing.T) {
+	cfg := &config.ProverManager{
+		ChunkCollectionTimeSec: 600,
+		BatchCollectionTimeSec: 900,
+		Verifier:               &config.VerifierConfig{ForkName: "darwin"},
+	}
+	o := New(cfg, nil, prometheus.NewRegistry())
+
+	// the config file values apply without overrides, reloading does nothing.
+	assert.NoError(t, o.Reload(context.Background()))
+	assert.Equal(t, 600, o.CollectionTimeSec(message.ProofTypeChunk))
+	assert.Equal(t, 900, o.CollectionTimeSec(message.ProofTypeBatch))
+	assert.Equal(t, 1, o.MaxAssignedTasksPerProver())
+	assert.Equal(t, "darwin", o.VerifierForkName())
+
+	o.values = map[string]string{
+		BatchCollectionTimeSec:    "3600",
+		MaxAssignedTasksPerProver: "2",
+		VerifierForkName:          "",
+	}
+	assert.Equal(t, 600, o.CollectionTimeSec(message.ProofTypeChunk))
+	assert.Equal(t, 3600, o.CollectionTimeSec(message.ProofTypeBatch))
+	assert.Equal(t, 2, o.MaxAssignedTasksPerProver())
+	assert.Equal(t, "", o.VerifierForkName())
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, validate(ChunkCollectionTimeSec, "600"))
+	assert.NoError(t, validate(MaxAssignedTasksPerProver, "3"))
+	assert.NoError(t, validate(VerifierForkName, ""))
+	assert.Error(t, validate(BatchCollectionTimeSec, "0"))
+	assert.Error(t, validate(BatchCollectionTimeSec, "1h"))
+	assert.Error(t, validate("session_attempts", "5"))
+}
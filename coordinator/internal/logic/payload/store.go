@@ -0,0 +1,174 @@
+package payload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/clock"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+const (
+	// defaultMinSize is the default size of the task data from which it is downloaded rather than sent inline.
+	defaultMinSize = 16 << 20
+	// defaultRetention is the default time the task data is kept for.
+	defaultRetention = 24 * time.Hour
+	// pruneInterval is the interval of removing the task data older than the retention.
+	pruneInterval = 10 * time.Minute
+	// tmpPrefix is the prefix of the task data being written.
+	tmpPrefix = ".tmp-"
+)
+
+// ErrNotFound the prover task has no task data stored, it was sent inline or was pruned.
+var ErrNotFound = errors.New("task payload not found")
+
+// Store keeps the task data of large tasks on disk, so that provers download it with Range requests and resume an
+// interrupted download instead of starting it over. The task data is stored as <uuid>.<sha256> after the uuid of its
+// prover task and its hash, which is the ETag of the download.
+type Store struct {
+	ctx       context.Context
+	clock     clock.Clock
+	dir       string
+	minSize   int
+	retention time.Duration
+}
+
+// NewStore creates the task data store of the config, nil if disabled. Its pruning runs on clk and stops when ctx is
+// done.
+func NewStore(ctx context.Context, cfg *config.TaskPayloads, clk clock.Clock) (*Store, error) {
+	if cfg == nil || cfg.Dir == "" {
+		return nil, nil
+	}
+	s := &Store{
+		ctx:       ctx,
+		clock:     clk,
+		dir:       cfg.Dir,
+		minSize:   defaultMinSize,
+		retention: defaultRetention,
+	}
+	if cfg.MinSizeBytes > 0 {
+		s.minSize = cfg.MinSizeBytes
+	}
+	if cfg.RetentionSec > 0 {
+		s.retention = time.Duration(cfg.RetentionSec) * time.Second
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create task payload dir: %w", err)
+	}
+	go s.pruneLoop()
+	return s, nil
+}
+
+// Offload stores the task data of a prover task if it is at least the minimum size, and returns its size and hash,
+// or 0 if it is sent inline. Storing the task data of a prover task again, e.g. once its reservation is assigned,
+// replaces the former one.
+func (s *Store) Offload(taskUUID, taskData string) (int64, string, error) {
+	if s == nil || len(taskData) < s.minSize {
+		return 0, "", nil
+	}
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, taskData)
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	// Write to a temporary file first so that a prover never downloads a truncated payload.
+	tmp, err := os.CreateTemp(s.dir, tmpPrefix)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create task payload: %w", err)
+	}
+	if _, err = io.WriteString(tmp, taskData); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return 0, "", fmt.Errorf("failed to write task payload: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, "", fmt.Errorf("failed to write task payload: %w", err)
+	}
+	path := filepath.Join(s.dir, taskUUID+"."+sum)
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, "", fmt.Errorf("failed to store task payload: %w", err)
+	}
+
+	former, _ := filepath.Glob(filepath.Join(s.dir, taskUUID+".*"))
+	for _, file := range former {
+		if file != path {
+			_ = os.Remove(file)
+		}
+	}
+	return int64(len(taskData)), sum, nil
+}
+
+// Open opens the latest task data of a prover task and returns its hash.
+func (s *Store) Open(taskUUID string) (*os.File, string, error) {
+	// the uuid is part of the request path, parsing it keeps it from escaping the dir.
+	if _, err := uuid.Parse(taskUUID); s == nil || err != nil {
+		return nil, "", ErrNotFound
+	}
+	files, err := filepath.Glob(filepath.Join(s.dir, taskUUID+".*"))
+	if err != nil || len(files) == 0 {
+		return nil, "", ErrNotFound
+	}
+	modTimes := make(map[string]int64, len(files))
+	for _, file := range files {
+		if info, statErr := os.Stat(file); statErr == nil {
+			modTimes[file] = info.ModTime().UnixNano()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return modTimes[files[i]] > modTimes[files[j]]
+	})
+	f, err := os.Open(files[0])
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to open task payload: %w", err)
+	}
+	return f, strings.TrimPrefix(filepath.Ext(files[0]), "."), nil
+}
+
+// pruneLoop removes the task data older than the retention periodically.
+func (s *Store) pruneLoop() {
+	ticker := s.clock.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C():
+			s.prune(s.clock.Now())
+		}
+	}
+}
+
+// prune removes the task data, and the temporary files left by a crash, last written before the retention.
+func (s *Store) prune(now time.Time) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		log.Warn("failed to list task payloads", "dir", s.dir, "err", err)
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || now.Sub(info.ModTime()) < s.retention {
+			continue
+		}
+		if err = os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			log.Warn("failed to remove task payload", "file", entry.Name(), "err", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package payload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/clock"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	clk := clock.NewFake(time.Now())
+	store, err := NewStore(ctx, &config.TaskPayloads{Dir: dir, MinSizeBytes: 8, RetentionSec: 60}, clk)
+	assert.NoError(t, err)
+
+	taskUUID := "2c0a4f6e-5b1d-4e33-9c58-9a0b5bfe6a10"
+	size, sum, err := store.Offload(taskUUID, "small")
+	assert.NoError(t, err)
+	assert.Zero(t, size)
+	assert.Empty(t, sum)
+	_, _, err = store.Open(taskUUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// storing the task data again replaces the former one.
+	_, _, err = store.Offload(taskUUID, "former task data")
+	assert.NoError(t, err)
+	taskData := "encrypted task data"
+	size, sum, err = store.Offload(taskUUID, taskData)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(taskData)), size)
+	hash := sha256.Sum256([]byte(taskData))
+	assert.Equal(t, hex.EncodeToString(hash[:]), sum)
+
+	f, openSum, err := store.Open(taskUUID)
+	assert.NoError(t, err)
+	buf, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.Equal(t, taskData, string(buf))
+	assert.Equal(t, sum, openSum)
+	files, err := filepath.Glob(filepath.Join(dir, taskUUID+".*"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// the uuid of the request path never escapes the dir.
+	_, _, err = store.Open("../" + taskUUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// the task data older than the retention is pruned, along with the temporary files left by a crash.
+	tmp, err := os.CreateTemp(dir, tmpPrefix)
+	assert.NoError(t, err)
+	assert.NoError(t, tmp.Close())
+	store.prune(clk.Now())
+	f, _, err = store.Open(taskUUID)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	clk.BlockUntil(1)
+	clk.Advance(pruneInterval)
+	assert.Eventually(t, func() bool {
+		entries, readErr := os.ReadDir(dir)
+		return readErr == nil && len(entries) == 0
+	}, time.Second, 10*time.Millisecond)
+	_, _, err = store.Open(taskUUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	disabled, err := NewStore(ctx, nil, clk)
+	assert.NoError(t, err)
+	assert.Nil(t, disabled)
+	size, _, err = disabled.Offload(taskUUID, taskData)
+	assert.NoError(t, err)
+	assert.Zero(t, size)
+}
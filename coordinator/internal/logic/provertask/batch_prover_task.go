@@ -3,6 +3,7 @@ package provertask
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/overrides"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -31,11 +33,13 @@ type BatchProverTask struct {
 
 	batchAttemptsExceedTotal prometheus.Counter
 	batchTaskGetTaskTotal    *prometheus.CounterVec
+	batchTaskDeferredTotal   *prometheus.CounterVec
+	batchTaskReservedTotal   *prometheus.CounterVec
 }
 
 // NewBatchProverTask new a batch collector
-func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vk string, reg prometheus.Registerer) *BatchProverTask {
-	forkHeights, _, nameForkMap := forks.CollectSortedForkHeights(chainCfg)
+func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vk func(hardForkName string) string, ov *overrides.Overrides, reg prometheus.Registerer) *BatchProverTask {
+	forkHeights, nameForkMap := collectForkHeights(cfg, chainCfg)
 	log.Info("new batch prover task", "forkHeights", forkHeights, "nameForks", nameForkMap)
 
 	bp := &BatchProverTask{
@@ -49,6 +53,9 @@ func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			batchOrm:           orm.NewBatch(db),
 			proverTaskOrm:      orm.NewProverTask(db),
 			proverBlockListOrm: orm.NewProverBlockList(db),
+			fairness:           newFairnessScheduler(cfg.ProverManager.AssignmentFairness),
+			watermark:          newTaskWatermark(cfg.ProverManager.TaskGeneration, message.ProofTypeBatch, db),
+			overrides:          ov,
 		},
 		batchAttemptsExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_batch_attempts_exceed_total",
@@ -58,6 +65,14 @@ func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			Name: "coordinator_batch_get_task_total",
 			Help: "Total number of batch get task.",
 		}, []string{"fork_name"}),
+		batchTaskDeferredTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_batch_get_task_deferred_total",
+			Help: "Total number of batch get task deferred by the assignment fairness.",
+		}, []string{"fork_name"}),
+		batchTaskReservedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_batch_get_task_reserved_total",
+			Help: "Total number of batch tasks reserved for provers prefetching their next task.",
+		}, []string{"fork_name"}),
 	}
 	return bp
 }
@@ -75,11 +90,44 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		return nil, err
 	}
 
+	fromBlockNum, toBlockNum := forks.BlockRange(hardForkNumber, bp.forkHeights)
+	hardForkName := forks.ForkNameByBlockHeight(fromBlockNum, bp.nameForkMap)
+
+	if !taskCtx.Prefetch {
+		reservedTask, takeErr := bp.takeReservation(ctx, taskCtx, message.ProofTypeBatch, bp.overrides.CollectionTimeSec(message.ProofTypeBatch))
+		if takeErr != nil {
+			if errors.Is(takeErr, ErrProverRateLimited) {
+				return nil, takeErr
+			}
+			log.Error("failed to take the reserved batch task", "public key", taskCtx.PublicKey, "err", takeErr)
+			return nil, ErrCoordinatorInternalFailure
+		}
+		if reservedTask != nil {
+			batchTask, getErr := bp.batchOrm.GetBatchByHash(ctx, reservedTask.TaskID)
+			if getErr != nil {
+				log.Error("failed to get the reserved batch", "hash", reservedTask.TaskID, "err", getErr)
+				return nil, ErrCoordinatorInternalFailure
+			}
+			log.Info("start reserved batch proof generation session", "id", batchTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+			taskMsg, sendErr := bp.sendProverTask(ctx, batchTask, reservedTask, hardForkName)
+			if sendErr != nil {
+				return nil, sendErr
+			}
+			bp.batchTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+			return taskMsg, nil
+		}
+	}
+
+	if !bp.admitFairly(getTaskParameter.HardForkName, taskCtx.PublicKey) {
+		bp.batchTaskDeferredTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+		log.Debug("batch task deferred by the assignment fairness", "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+		return nil, ErrProverRateLimited
+	}
+
 	// if the hard fork number set, rollup relayer must generate the chunk from hard fork number,
 	// so the hard fork chunk's start_block_number must be ForkBlockNumber
 	var startChunkIndex uint64 = 0
 	var endChunkIndex uint64 = math.MaxInt64
-	fromBlockNum, toBlockNum := forks.BlockRange(hardForkNumber, bp.forkHeights)
 	if fromBlockNum != 0 {
 		startChunk, chunkErr := bp.chunkOrm.GetChunkByStartBlockNumber(ctx, fromBlockNum)
 		if chunkErr != nil {
@@ -104,22 +152,31 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		}
 	}
 
+	maxIndex, err := bp.watermark.maxTaskIndex(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to get batch task watermark", "height", getTaskParameter.ProverHeight, "err", err)
+		return nil, ErrCoordinatorInternalFailure
+	}
+
 	maxActiveAttempts := bp.cfg.ProverManager.ProversPerSession
 	maxTotalAttempts := bp.cfg.ProverManager.SessionAttempts
 	var batchTask *orm.Batch
 	for i := 0; i < 5; i++ {
 		var getTaskError error
 		var tmpBatchTask *orm.Batch
-		tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(ctx, startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
-		if getTaskError != nil {
-			log.Error("failed to get assigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
-			return nil, ErrCoordinatorInternalFailure
+		// a prefetch reserves an unassigned batch, the assigned ones may include the batch the prover is proving.
+		if !taskCtx.Prefetch {
+			tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(ctx, startChunkIndex, endChunkIndex, maxIndex, maxActiveAttempts, maxTotalAttempts)
+			if getTaskError != nil {
+				log.Error("failed to get assigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
+				return nil, ErrCoordinatorInternalFailure
+			}
 		}
 
 		// Why here need get again? In order to support a task can assign to multiple prover, need also assign `ProvingTaskAssigned`
 		// batch to prover. But use `proving_status in (1, 2)` will not use the postgres index. So need split the sql.
 		if tmpBatchTask == nil {
-			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(ctx, startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
+			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(ctx, startChunkIndex, endChunkIndex, maxIndex, maxActiveAttempts, maxTotalAttempts)
 			if getTaskError != nil {
 				log.Error("failed to get unassigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 				return nil, ErrCoordinatorInternalFailure
@@ -151,40 +208,59 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		return nil, nil
 	}
 
-	log.Info("start batch proof generation session", "id", batchTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
-
-	proverTask := orm.ProverTask{
-		TaskID:          batchTask.Hash,
-		ProverPublicKey: taskCtx.PublicKey,
-		TaskType:        int16(message.ProofTypeBatch),
-		ProverName:      taskCtx.ProverName,
-		ProverVersion:   taskCtx.ProverVersion,
-		ProvingStatus:   int16(types.ProverAssigned),
-		FailureType:     int16(types.ProverTaskFailureTypeUndefined),
-		// here why need use UTC time. see scroll/common/databased/db.go
-		AssignedAt: utils.NowUTC(),
+	if taskCtx.Prefetch {
+		log.Info("reserve batch task for a prefetch", "id", batchTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+	} else {
+		log.Info("start batch proof generation session", "id", batchTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
 	}
 
 	// Store session info.
-	if err = bp.proverTaskOrm.InsertProverTask(ctx, &proverTask); err != nil {
+	// here why need use UTC time. see scroll/common/databased/db.go
+	proverTask := bp.newProverTask(taskCtx, batchTask.Hash, message.ProofTypeBatch, utils.NowUTC(), bp.overrides.CollectionTimeSec(message.ProofTypeBatch))
+	if err = bp.storeProverTask(ctx, taskCtx, proverTask); err != nil {
 		bp.recoverActiveAttempts(ctx, batchTask)
+		if errors.Is(err, ErrProverRateLimited) {
+			return nil, err
+		}
 		log.Error("insert batch prover task info fail", "taskID", batchTask.Hash, "publicKey", taskCtx.PublicKey, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
-	taskMsg, err := bp.formatProverTask(ctx, &proverTask)
+	taskMsg, err := bp.sendProverTask(ctx, batchTask, proverTask, hardForkName)
 	if err != nil {
-		bp.recoverActiveAttempts(ctx, batchTask)
-		log.Error("format prover task failure", "hash", batchTask.Hash, "err", err)
-		return nil, ErrCoordinatorInternalFailure
+		return nil, err
+	}
+
+	bp.recordFairAssignment(getTaskParameter.HardForkName, taskCtx.PublicKey)
+	if taskCtx.Prefetch {
+		bp.batchTaskReservedTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+	} else {
+		bp.batchTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
 	}
 
-	bp.batchTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+	return taskMsg, nil
+}
 
+// sendProverTask returns the task of a batch assigned or reserved for the prover. The attempt of the batch is given
+// back if the task can not be formatted.
+func (bp *BatchProverTask) sendProverTask(ctx *gin.Context, batchTask *orm.Batch, proverTask *orm.ProverTask, hardForkName string) (*coordinatorType.GetTaskSchema, error) {
+	taskMsg, err := bp.formatProverTask(ctx, proverTask, hardForkName)
+	if err != nil {
+		if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverReserved {
+			if releaseErr := bp.releaseReservation(ctx, proverTask); releaseErr != nil {
+				log.Error("failed to release the batch reservation", "hash", batchTask.Hash, "error", releaseErr)
+			}
+		} else {
+			bp.recoverActiveAttempts(ctx, batchTask)
+		}
+		log.Error("format prover task failure", "hash", batchTask.Hash, "err", err)
+		return nil, ErrCoordinatorInternalFailure
+	}
+	bp.setTaskTimes(taskMsg, proverTask, batchTask.CreatedAt)
 	return taskMsg, nil
 }
 
-func (bp *BatchProverTask) formatProverTask(ctx context.Context, task *orm.ProverTask) (*coordinatorType.GetTaskSchema, error) {
+func (bp *BatchProverTask) formatProverTask(ctx context.Context, task *orm.ProverTask, hardForkName string) (*coordinatorType.GetTaskSchema, error) {
 	// get chunk from db
 	chunks, err := bp.chunkOrm.GetChunksByBatchHash(ctx, task.TaskID)
 	if err != nil {
@@ -226,10 +302,14 @@ func (bp *BatchProverTask) formatProverTask(ctx context.Context, task *orm.Prove
 	}
 
 	taskMsg := &coordinatorType.GetTaskSchema{
-		UUID:     task.UUID.String(),
-		TaskID:   task.TaskID,
-		TaskType: int(message.ProofTypeBatch),
-		TaskData: string(chunkProofsBytes),
+		UUID:         task.UUID.String(),
+		TaskID:       task.TaskID,
+		TaskType:     int(message.ProofTypeBatch),
+		TaskData:     string(chunkProofsBytes),
+		HardForkName: hardForkName,
+	}
+	if task.Deadline != nil {
+		taskMsg.Deadline = task.Deadline.Unix()
 	}
 	return taskMsg, nil
 }
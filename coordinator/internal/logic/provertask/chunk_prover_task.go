@@ -3,12 +3,14 @@ package provertask
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
@@ -19,6 +21,8 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/overrides"
+	"scroll-tech/coordinator/internal/logic/trace"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -27,13 +31,18 @@ import (
 type ChunkProverTask struct {
 	BaseProverTask
 
+	traceService *trace.Service // nil if provers fetch the block traces themselves
+
 	chunkAttemptsExceedTotal prometheus.Counter
+	chunkInvalidTotal        prometheus.Counter
 	chunkTaskGetTaskTotal    *prometheus.CounterVec
+	chunkTaskDeferredTotal   *prometheus.CounterVec
+	chunkTaskReservedTotal   *prometheus.CounterVec
 }
 
 // NewChunkProverTask new a chunk prover task
-func NewChunkProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, vk string, reg prometheus.Registerer) *ChunkProverTask {
-	forkHeights, _, nameForkMap := forks.CollectSortedForkHeights(chainCfg)
+func NewChunkProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *gorm.DB, traceService *trace.Service, vk func(hardForkName string) string, ov *overrides.Overrides, reg prometheus.Registerer) *ChunkProverTask {
+	forkHeights, nameForkMap := collectForkHeights(cfg, chainCfg)
 	log.Info("new chunk prover task", "forkHeights", forkHeights, "nameForks", nameForkMap)
 	cp := &ChunkProverTask{
 		BaseProverTask: BaseProverTask{
@@ -46,15 +55,31 @@ func NewChunkProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			blockOrm:           orm.NewL2Block(db),
 			proverTaskOrm:      orm.NewProverTask(db),
 			proverBlockListOrm: orm.NewProverBlockList(db),
+			fairness:           newFairnessScheduler(cfg.ProverManager.AssignmentFairness),
+			watermark:          newTaskWatermark(cfg.ProverManager.TaskGeneration, message.ProofTypeChunk, db),
+			overrides:          ov,
 		},
+		traceService: traceService,
 		chunkAttemptsExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_chunk_attempts_exceed_total",
 			Help: "Total number of chunk attempts exceed.",
 		}),
+		chunkInvalidTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_chunk_invalid_total",
+			Help: "Total number of chunks marked failed as their block traces can not be proven.",
+		}),
 		chunkTaskGetTaskTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "coordinator_chunk_get_task_total",
 			Help: "Total number of chunk get task.",
 		}, []string{"fork_name"}),
+		chunkTaskDeferredTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_chunk_get_task_deferred_total",
+			Help: "Total number of chunk get task deferred by the assignment fairness.",
+		}, []string{"fork_name"}),
+		chunkTaskReservedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_chunk_get_task_reserved_total",
+			Help: "Total number of chunk tasks reserved for provers prefetching their next task.",
+		}, []string{"fork_name"}),
 	}
 	return cp
 }
@@ -72,27 +97,67 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		return nil, err
 	}
 
+	if !taskCtx.Prefetch {
+		reservedTask, takeErr := cp.takeReservation(ctx, taskCtx, message.ProofTypeChunk, cp.overrides.CollectionTimeSec(message.ProofTypeChunk))
+		if takeErr != nil {
+			if errors.Is(takeErr, ErrProverRateLimited) {
+				return nil, takeErr
+			}
+			log.Error("failed to take the reserved chunk task", "public key", taskCtx.PublicKey, "err", takeErr)
+			return nil, ErrCoordinatorInternalFailure
+		}
+		if reservedTask != nil {
+			chunkTask, getErr := cp.chunkOrm.GetChunkByHash(ctx, reservedTask.TaskID)
+			if getErr != nil {
+				log.Error("failed to get the reserved chunk", "hash", reservedTask.TaskID, "err", getErr)
+				return nil, ErrCoordinatorInternalFailure
+			}
+			log.Info("start reserved chunk generation session", "id", chunkTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+			taskMsg, sendErr := cp.sendProverTask(ctx, chunkTask, reservedTask)
+			if sendErr != nil {
+				return nil, sendErr
+			}
+			cp.chunkTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+			return taskMsg, nil
+		}
+	}
+
+	if !cp.admitFairly(getTaskParameter.HardForkName, taskCtx.PublicKey) {
+		cp.chunkTaskDeferredTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+		log.Debug("chunk task deferred by the assignment fairness", "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+		return nil, ErrProverRateLimited
+	}
+
 	fromBlockNum, toBlockNum := forks.BlockRange(hardForkNumber, cp.forkHeights)
 	if toBlockNum > getTaskParameter.ProverHeight {
 		toBlockNum = getTaskParameter.ProverHeight + 1
 	}
 
+	maxIndex, err := cp.watermark.maxTaskIndex(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to get chunk task watermark", "height", getTaskParameter.ProverHeight, "err", err)
+		return nil, ErrCoordinatorInternalFailure
+	}
+
 	maxActiveAttempts := cp.cfg.ProverManager.ProversPerSession
 	maxTotalAttempts := cp.cfg.ProverManager.SessionAttempts
 	var chunkTask *orm.Chunk
 	for i := 0; i < 5; i++ {
 		var getTaskError error
 		var tmpChunkTask *orm.Chunk
-		tmpChunkTask, getTaskError = cp.chunkOrm.GetAssignedChunk(ctx, fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
-		if getTaskError != nil {
-			log.Error("failed to get assigned chunk proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
-			return nil, ErrCoordinatorInternalFailure
+		// a prefetch reserves an unassigned chunk, the assigned ones may include the chunk the prover is proving.
+		if !taskCtx.Prefetch {
+			tmpChunkTask, getTaskError = cp.chunkOrm.GetAssignedChunk(ctx, fromBlockNum, toBlockNum, maxIndex, maxActiveAttempts, maxTotalAttempts)
+			if getTaskError != nil {
+				log.Error("failed to get assigned chunk proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
+				return nil, ErrCoordinatorInternalFailure
+			}
 		}
 
 		// Why here need get again? In order to support a task can assign to multiple prover, need also assign `ProvingTaskAssigned`
 		// chunk to prover. But use `proving_status in (1, 2)` will not use the postgres index. So need split the sql.
 		if tmpChunkTask == nil {
-			tmpChunkTask, getTaskError = cp.chunkOrm.GetUnassignedChunk(ctx, fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
+			tmpChunkTask, getTaskError = cp.chunkOrm.GetUnassignedChunk(ctx, fromBlockNum, toBlockNum, maxIndex, maxActiveAttempts, maxTotalAttempts)
 			if getTaskError != nil {
 				log.Error("failed to get unassigned chunk proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 				return nil, ErrCoordinatorInternalFailure
@@ -115,6 +180,18 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 			continue
 		}
 
+		valid, validateErr := cp.validateChunk(ctx, tmpChunkTask)
+		if validateErr != nil || !valid {
+			cp.recoverActiveAttempts(ctx, tmpChunkTask)
+		}
+		if validateErr != nil {
+			log.Error("failed to validate chunk", "hash", tmpChunkTask.Hash, "err", validateErr)
+			return nil, ErrCoordinatorInternalFailure
+		}
+		if !valid {
+			continue
+		}
+
 		chunkTask = tmpChunkTask
 		break
 	}
@@ -124,39 +201,82 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		return nil, nil
 	}
 
-	log.Info("start chunk generation session", "id", chunkTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
-
-	proverTask := orm.ProverTask{
-		TaskID:          chunkTask.Hash,
-		ProverPublicKey: taskCtx.PublicKey,
-		TaskType:        int16(message.ProofTypeChunk),
-		ProverName:      taskCtx.ProverName,
-		ProverVersion:   taskCtx.ProverVersion,
-		ProvingStatus:   int16(types.ProverAssigned),
-		FailureType:     int16(types.ProverTaskFailureTypeUndefined),
-		// here why need use UTC time. see scroll/common/databased/db.go
-		AssignedAt: utils.NowUTC(),
+	if taskCtx.Prefetch {
+		log.Info("reserve chunk task for a prefetch", "id", chunkTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
+	} else {
+		log.Info("start chunk generation session", "id", chunkTask.Hash, "public key", taskCtx.PublicKey, "prover name", taskCtx.ProverName)
 	}
 
-	if err = cp.proverTaskOrm.InsertProverTask(ctx, &proverTask); err != nil {
+	// here why need use UTC time. see scroll/common/databased/db.go
+	proverTask := cp.newProverTask(taskCtx, chunkTask.Hash, message.ProofTypeChunk, utils.NowUTC(), cp.overrides.CollectionTimeSec(message.ProofTypeChunk))
+	if err = cp.storeProverTask(ctx, taskCtx, proverTask); err != nil {
 		cp.recoverActiveAttempts(ctx, chunkTask)
+		if errors.Is(err, ErrProverRateLimited) {
+			return nil, err
+		}
 		log.Error("insert chunk prover task fail", "taskID", chunkTask.Hash, "publicKey", taskCtx.PublicKey, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
-	taskMsg, err := cp.formatProverTask(ctx, &proverTask)
+	taskMsg, err := cp.sendProverTask(ctx, chunkTask, proverTask)
 	if err != nil {
-		cp.recoverActiveAttempts(ctx, chunkTask)
+		return nil, err
+	}
+
+	cp.recordFairAssignment(getTaskParameter.HardForkName, taskCtx.PublicKey)
+	if taskCtx.Prefetch {
+		cp.chunkTaskReservedTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+	} else {
+		cp.chunkTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+	}
+
+	return taskMsg, nil
+}
+
+// sendProverTask returns the task of a chunk assigned or reserved for the prover. The attempt of the chunk is given
+// back if the task can not be formatted.
+func (cp *ChunkProverTask) sendProverTask(ctx *gin.Context, chunkTask *orm.Chunk, proverTask *orm.ProverTask) (*coordinatorType.GetTaskSchema, error) {
+	taskMsg, err := cp.formatProverTask(ctx, proverTask, forks.ForkNameByBlockHeight(chunkTask.StartBlockNumber, cp.nameForkMap))
+	if err != nil {
+		if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverReserved {
+			if releaseErr := cp.releaseReservation(ctx, proverTask); releaseErr != nil {
+				log.Error("failed to release the chunk reservation", "hash", chunkTask.Hash, "error", releaseErr)
+			}
+		} else {
+			cp.recoverActiveAttempts(ctx, chunkTask)
+		}
 		log.Error("format prover task failure", "hash", chunkTask.Hash, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
+	cp.setTaskTimes(taskMsg, proverTask, chunkTask.CreatedAt)
+	return taskMsg, nil
+}
 
-	cp.chunkTaskGetTaskTotal.WithLabelValues(getTaskParameter.HardForkName).Inc()
+// validateChunk checks the block traces of a chunk before it is assigned if enabled, and marks the chunk failed for
+// operator review if they can not be proven, so no prover time is spent on it.
+func (cp *ChunkProverTask) validateChunk(ctx context.Context, chunk *orm.Chunk) (bool, error) {
+	if cp.traceService == nil || !cp.cfg.L2.ValidateChunks {
+		return true, nil
+	}
+	blockHashes, err := cp.blockOrm.GetL2BlockHashesByChunkHash(ctx, chunk.Hash)
+	if err != nil || len(blockHashes) == 0 {
+		return false, fmt.Errorf("failed to fetch block hashes of a chunk, chunk hash:%s err:%w", chunk.Hash, err)
+	}
+	err = cp.traceService.ValidateChunk(ctx, blockHashes, common.HexToHash(chunk.ParentChunkStateRoot), common.HexToHash(chunk.StateRoot))
+	var invalidErr *trace.InvalidChunkError
+	if !errors.As(err, &invalidErr) {
+		return err == nil, err
+	}
 
-	return taskMsg, nil
+	log.Error("chunk can not be proven, marking it failed for operator review", "index", chunk.Index, "hash", chunk.Hash, "reason", invalidErr.Reason)
+	cp.chunkInvalidTotal.Inc()
+	if err = cp.chunkOrm.UpdateProvingStatusInvalid(ctx, chunk.Hash); err != nil {
+		return false, err
+	}
+	return false, nil
 }
 
-func (cp *ChunkProverTask) formatProverTask(ctx context.Context, task *orm.ProverTask) (*coordinatorType.GetTaskSchema, error) {
+func (cp *ChunkProverTask) formatProverTask(ctx context.Context, task *orm.ProverTask, hardForkName string) (*coordinatorType.GetTaskSchema, error) {
 	// Get block hashes.
 	blockHashes, dbErr := cp.blockOrm.GetL2BlockHashesByChunkHash(ctx, task.TaskID)
 	if dbErr != nil || len(blockHashes) == 0 {
@@ -166,16 +286,27 @@ func (cp *ChunkProverTask) formatProverTask(ctx context.Context, task *orm.Prove
 	taskDetail := message.ChunkTaskDetail{
 		BlockHashes: blockHashes,
 	}
+	if cp.traceService != nil {
+		traces, err := cp.traceService.GetSortedTracesByHashes(ctx, blockHashes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch block traces of a chunk, chunk hash:%s err:%w", task.TaskID, err)
+		}
+		taskDetail.BlockTraces = traces
+	}
 	blockHashesBytes, err := json.Marshal(taskDetail)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal block hashes hash:%s, err:%w", task.TaskID, err)
 	}
 
 	proverTaskSchema := &coordinatorType.GetTaskSchema{
-		UUID:     task.UUID.String(),
-		TaskID:   task.TaskID,
-		TaskType: int(message.ProofTypeChunk),
-		TaskData: string(blockHashesBytes),
+		UUID:         task.UUID.String(),
+		TaskID:       task.TaskID,
+		TaskType:     int(message.ProofTypeChunk),
+		TaskData:     string(blockHashesBytes),
+		HardForkName: hardForkName,
+	}
+	if task.Deadline != nil {
+		proverTaskSchema.Deadline = task.Deadline.Unix()
 	}
 
 	return proverTaskSchema, nil
@@ -0,0 +1,167 @@
+package provertask
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+const (
+	defaultFairnessActiveWindow = 30 * time.Second
+	defaultFairnessLoadHalfLife = time.Hour
+	defaultFairnessMaxDeferral  = 300 * time.Second
+)
+
+// waitingProver is a prover which asked for a task of a queue and was not assigned one since.
+type waitingProver struct {
+	since       time.Time // first request since its last assignment
+	lastRequest time.Time
+}
+
+// proverLoad is the number of tasks assigned to a prover recently, decayed by the load half-life.
+type proverLoad struct {
+	load         float64
+	lastAssigned time.Time
+}
+
+// fairnessScheduler orders the provers waiting for a task of a queue, i.e. a hard fork, by the strategy of the config,
+// and defers the prover asking for a task while another waiting prover comes first. It is safe for concurrent use.
+type fairnessScheduler struct {
+	strategy     string
+	weights      map[string]float64
+	activeWindow time.Duration
+	loadHalfLife time.Duration
+	maxDeferral  time.Duration
+
+	mu      sync.Mutex
+	waiting map[string]map[string]*waitingProver // by queue and public key
+	loads   map[string]*proverLoad               // by public key
+}
+
+// newFairnessScheduler returns nil if cfg is nil, tasks are then assigned first come first served.
+func newFairnessScheduler(cfg *config.AssignmentFairness) *fairnessScheduler {
+	if cfg == nil {
+		return nil
+	}
+	s := &fairnessScheduler{
+		strategy:     cfg.Strategy,
+		weights:      cfg.Weights,
+		activeWindow: defaultFairnessActiveWindow,
+		loadHalfLife: defaultFairnessLoadHalfLife,
+		maxDeferral:  defaultFairnessMaxDeferral,
+		waiting:      make(map[string]map[string]*waitingProver),
+		loads:        make(map[string]*proverLoad),
+	}
+	if cfg.ActiveWindowSec > 0 {
+		s.activeWindow = time.Duration(cfg.ActiveWindowSec) * time.Second
+	}
+	if cfg.LoadHalfLifeSec > 0 {
+		s.loadHalfLife = time.Duration(cfg.LoadHalfLifeSec) * time.Second
+	}
+	if cfg.MaxDeferralSec > 0 {
+		s.maxDeferral = time.Duration(cfg.MaxDeferralSec) * time.Second
+	}
+	return s
+}
+
+// admit records the request of a prover for a task of the queue, and returns whether it is served now: no other
+// prover waiting for a task of the queue comes first, or the prover has been deferred for the max deferral.
+func (s *fairnessScheduler) admit(queue, publicKey string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	provers, ok := s.waiting[queue]
+	if !ok {
+		provers = make(map[string]*waitingProver)
+		s.waiting[queue] = provers
+	}
+	self, ok := provers[publicKey]
+	if !ok {
+		self = &waitingProver{since: now}
+		provers[publicKey] = self
+	}
+	self.lastRequest = now
+
+	if now.Sub(self.since) >= s.maxDeferral {
+		return true
+	}
+	for other, waiting := range provers {
+		if other == publicKey {
+			continue
+		}
+		// provers which stopped asking, e.g. went offline, do not hold the others back.
+		if now.Sub(waiting.lastRequest) > s.activeWindow {
+			delete(provers, other)
+			continue
+		}
+		if s.comesBefore(other, waiting, publicKey, self, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// assigned records the assignment of a task of the queue to a prover, which stops waiting.
+func (s *fairnessScheduler) assigned(queue, publicKey string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.waiting[queue], publicKey)
+	l, ok := s.loads[publicKey]
+	if !ok {
+		l = &proverLoad{}
+		s.loads[publicKey] = l
+	}
+	l.load = s.decayedLoad(l, now) + 1
+	l.lastAssigned = now
+}
+
+// comesBefore returns whether the waiting prover a is served before the waiting prover b, ties are broken by the
+// time they have been waiting.
+func (s *fairnessScheduler) comesBefore(a string, aWaiting *waitingProver, b string, bWaiting *waitingProver, now time.Time) bool {
+	var aKey, bKey float64
+	switch s.strategy {
+	case config.FairnessRoundRobin:
+		// provers never assigned a task have the zero time, they come first.
+		aKey, bKey = float64(s.lastAssigned(a).UnixNano()), float64(s.lastAssigned(b).UnixNano())
+	case config.FairnessLeastLoaded:
+		aKey, bKey = s.load(a, now), s.load(b, now)
+	case config.FairnessWeighted:
+		aKey, bKey = s.load(a, now)/s.weight(a), s.load(b, now)/s.weight(b)
+	}
+	if aKey != bKey {
+		return aKey < bKey
+	}
+	return aWaiting.since.Before(bWaiting.since)
+}
+
+func (s *fairnessScheduler) lastAssigned(publicKey string) time.Time {
+	if l, ok := s.loads[publicKey]; ok {
+		return l.lastAssigned
+	}
+	return time.Time{}
+}
+
+func (s *fairnessScheduler) load(publicKey string, now time.Time) float64 {
+	if l, ok := s.loads[publicKey]; ok {
+		return s.decayedLoad(l, now)
+	}
+	return 0
+}
+
+func (s *fairnessScheduler) decayedLoad(l *proverLoad, now time.Time) float64 {
+	elapsed := now.Sub(l.lastAssigned)
+	if elapsed <= 0 {
+		return l.load
+	}
+	return l.load * math.Exp2(-float64(elapsed)/float64(s.loadHalfLife))
+}
+
+func (s *fairnessScheduler) weight(publicKey string) float64 {
+	if w, ok := s.weights[publicKey]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
@@ -0,0 +1,138 @@
+package provertask
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+type simulatedProver struct {
+	publicKey   string
+	pollEvery   int // seconds between two requests
+	busyUntil   int
+	assignments int
+}
+
+// simulateAssignments runs provers asking for tasks of a single queue for duration seconds, while a task is created
+// every taskEvery seconds and a task is proven in provingTime seconds. The provers listed first ask first within a
+// second. It returns the number of tasks assigned to each prover.
+func simulateAssignments(s *fairnessScheduler, provers []*simulatedProver, duration, taskEvery, provingTime int) []int {
+	start := time.Unix(1_700_000_000, 0)
+	available := 0
+	for second := 0; second < duration; second++ {
+		if second%taskEvery == 0 {
+			available++
+		}
+		now := start.Add(time.Duration(second) * time.Second)
+		for _, p := range provers {
+			if second < p.busyUntil || second%p.pollEvery != 0 {
+				continue
+			}
+			if s != nil && !s.admit("bernoulli", p.publicKey, now) {
+				continue
+			}
+			if available == 0 {
+				continue
+			}
+			available--
+			p.assignments++
+			p.busyUntil = second + provingTime
+			if s != nil {
+				s.assigned("bernoulli", p.publicKey, now)
+			}
+		}
+	}
+	assignments := make([]int, len(provers))
+	for i, p := range provers {
+		assignments[i] = p.assignments
+	}
+	return assignments
+}
+
+// newFleet returns a large fleet of provers asking every second followed by small provers asking every 5 seconds.
+func newFleet(large, small int) []*simulatedProver {
+	var provers []*simulatedProver
+	for i := 0; i < large; i++ {
+		provers = append(provers, &simulatedProver{publicKey: fmt.Sprintf("large-%d", i), pollEvery: 1})
+	}
+	for i := 0; i < small; i++ {
+		provers = append(provers, &simulatedProver{publicKey: fmt.Sprintf("small-%d", i), pollEvery: 5})
+	}
+	return provers
+}
+
+func sum(values []int) int {
+	var total int
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func TestFairnessSimulation(t *testing.T) {
+	const (
+		duration    = 3600
+		taskEvery   = 4
+		provingTime = 20
+	)
+
+	// first come first served, the large fleet asking more often takes nearly all the scarce tasks.
+	fifo := simulateAssignments(nil, newFleet(8, 2), duration, taskEvery, provingTime)
+	assert.Less(t, fifo[8]+fifo[9], sum(fifo)/20)
+
+	for _, strategy := range []string{config.FairnessRoundRobin, config.FairnessLeastLoaded} {
+		s := newFairnessScheduler(&config.AssignmentFairness{Strategy: strategy, ActiveWindowSec: 10})
+		assignments := simulateAssignments(s, newFleet(8, 2), duration, taskEvery, provingTime)
+		fairShare := sum(assignments) / len(assignments)
+		for i, n := range assignments {
+			assert.InDelta(t, fairShare, n, float64(fairShare)/4, "strategy %s, prover %d", strategy, i)
+		}
+		// no task is left unassigned for long, the scarce tasks are all assigned.
+		assert.GreaterOrEqual(t, sum(assignments), duration/taskEvery-5, strategy)
+	}
+}
+
+func TestFairnessWeighted(t *testing.T) {
+	s := newFairnessScheduler(&config.AssignmentFairness{
+		Strategy:        config.FairnessWeighted,
+		Weights:         map[string]float64{"small-0": 3},
+		ActiveWindowSec: 10,
+	})
+	assignments := simulateAssignments(s, newFleet(2, 1), 3600, 30, 20)
+	// the prover of weight 3 gets about three times as many tasks as each of the others.
+	assert.InDelta(t, 3*assignments[0], assignments[2], float64(assignments[2])/8)
+	assert.InDelta(t, 3*assignments[1], assignments[2], float64(assignments[2])/8)
+}
+
+func TestFairnessScheduler(t *testing.T) {
+	s := newFairnessScheduler(&config.AssignmentFairness{Strategy: config.FairnessLeastLoaded, ActiveWindowSec: 10, MaxDeferralSec: 60})
+	now := time.Unix(1_700_000_000, 0)
+
+	assert.True(t, s.admit("fork", "a", now))
+	s.assigned("fork", "a", now)
+
+	// b was never assigned a task, a waits for it.
+	assert.True(t, s.admit("fork", "b", now.Add(time.Second)))
+	assert.False(t, s.admit("fork", "a", now.Add(2*time.Second)))
+	// other queues are ordered separately.
+	assert.True(t, s.admit("other-fork", "a", now.Add(2*time.Second)))
+
+	// b stopped asking, it does not hold a back anymore.
+	assert.True(t, s.admit("fork", "a", now.Add(20*time.Second)))
+
+	// a prover deferred for the max deferral is served, although c which never gets a task comes first.
+	s.assigned("fork", "b", now.Add(21*time.Second))
+	s.assigned("fork", "b", now.Add(22*time.Second))
+	assert.False(t, s.admit("fork", "b", now.Add(23*time.Second)))
+	for second := 24; second < 83; second++ {
+		assert.True(t, s.admit("fork", "c", now.Add(time.Duration(second)*time.Second)))
+		assert.False(t, s.admit("fork", "b", now.Add(time.Duration(second)*time.Second)))
+	}
+	assert.True(t, s.admit("fork", "b", now.Add(83*time.Second)))
+
+	assert.Nil(t, newFairnessScheduler(nil))
+}
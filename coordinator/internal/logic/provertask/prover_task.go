@@ -2,13 +2,18 @@ package provertask
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/forks"
 	"scroll-tech/common/version"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/auth"
+	"scroll-tech/coordinator/internal/logic/overrides"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -19,6 +24,10 @@ var ErrCoordinatorInternalFailure = fmt.Errorf("coordinator internal error")
 // ErrHardForkName indicates client request with the wrong hard fork name
 var ErrHardForkName = fmt.Errorf("wrong hard fork name")
 
+// ErrProverRateLimited indicates the prover asks for a task before its turn by the assignment fairness, or before
+// finishing its assigned task
+var ErrProverRateLimited = fmt.Errorf("prover rate limited")
+
 // ProverTask the interface of a collector who send data to prover
 type ProverTask interface {
 	Assign(ctx *gin.Context, getTaskParameter *coordinatorType.GetTaskParameter) (*coordinatorType.GetTaskSchema, error)
@@ -28,7 +37,7 @@ type ProverTask interface {
 type BaseProverTask struct {
 	cfg *config.Config
 	db  *gorm.DB
-	vk  string
+	vk  func(hardForkName string) string // the verifying key provers of the hard fork must have
 
 	nameForkMap map[string]uint64
 	forkHeights []uint64
@@ -38,12 +47,50 @@ type BaseProverTask struct {
 	blockOrm           *orm.L2Block
 	proverTaskOrm      *orm.ProverTask
 	proverBlockListOrm *orm.ProverBlockList
+
+	fairness  *fairnessScheduler   // nil if tasks are assigned first come first served
+	watermark *taskWatermark       // nil if tasks are assigned for all the unproven chunks or batches
+	overrides *overrides.Overrides // the collection times and the max assigned tasks per prover
+}
+
+// admitFairly returns whether the prover asking for a task of the hard fork is served now by the assignment fairness,
+// always if it is disabled.
+func (b *BaseProverTask) admitFairly(hardForkName, publicKey string) bool {
+	return b.fairness == nil || b.fairness.admit(hardForkName, publicKey, time.Now())
+}
+
+// recordFairAssignment records the assignment of a task of the hard fork to the prover for the assignment fairness.
+func (b *BaseProverTask) recordFairAssignment(hardForkName, publicKey string) {
+	if b.fairness != nil {
+		b.fairness.assigned(hardForkName, publicKey, time.Now())
+	}
+}
+
+// taskDeadline returns the deadline of a task assigned at assignedAt, i.e. the end of its proof collection time.
+// The task is not reassigned before then, so an earlier deadline would only reject proofs the coordinator still awaits.
+func taskDeadline(assignedAt time.Time, collectionTimeSec int) time.Time {
+	return assignedAt.Add(time.Duration(collectionTimeSec) * time.Second)
+}
+
+// finalizationTarget returns the time by which the proof of a task created at taskCreatedAt is wanted to meet the
+// finalization target, so provers can prioritize the task. It is nil if no target is configured or if the target
+// is not before the deadline, proofs are still accepted until the deadline.
+func (b *BaseProverTask) finalizationTarget(taskCreatedAt, deadline time.Time) *time.Time {
+	if b.cfg.ProverManager.FinalizationTargetSec <= 0 {
+		return nil
+	}
+	target := taskCreatedAt.Add(time.Duration(b.cfg.ProverManager.FinalizationTargetSec) * time.Second)
+	if !target.Before(deadline) {
+		return nil
+	}
+	return &target
 }
 
 type proverTaskContext struct {
 	PublicKey     string
 	ProverName    string
 	ProverVersion string
+	Prefetch      bool // the prover proves its assigned task and asks to reserve the next one
 }
 
 // checkParameter check the prover task parameter illegal
@@ -68,15 +115,11 @@ func (b *BaseProverTask) checkParameter(ctx *gin.Context, getTaskParameter *coor
 	}
 	ptc.ProverVersion = proverVersion.(string)
 
-	if !version.CheckScrollRepoVersion(proverVersion.(string), b.cfg.ProverManager.MinProverVersion) {
-		return nil, fmt.Errorf("incompatible prover version. please upgrade your prover, minimum allowed version: %s, actual version: %s", b.cfg.ProverManager.MinProverVersion, proverVersion.(string))
-	}
-
 	// if the prover has a different vk
-	if getTaskParameter.VK != b.vk {
+	if getTaskParameter.VK != b.vk(getTaskParameter.HardForkName) {
 		// if the prover reports a different prover version
 		if !version.CheckScrollProverVersion(proverVersion.(string)) {
-			return nil, fmt.Errorf("incompatible prover version. please upgrade your prover, expect version: %s, actual version: %s", version.Version, proverVersion.(string))
+			return nil, fmt.Errorf("%w. please upgrade your prover, expect version: %s, actual version: %s", auth.ErrProverVersionTooLow, version.Version, proverVersion.(string))
 		}
 		// if the prover reports a same prover version
 		return nil, fmt.Errorf("incompatible vk. please check your params files or config files")
@@ -90,13 +133,24 @@ func (b *BaseProverTask) checkParameter(ctx *gin.Context, getTaskParameter *coor
 		return nil, fmt.Errorf("public key %s is blocked from fetching tasks. ProverName: %s, ProverVersion: %s", publicKey, proverName, proverVersion)
 	}
 
-	isAssigned, err := b.proverTaskOrm.IsProverAssigned(ctx, publicKey.(string))
+	assigned, err := b.proverTaskOrm.CountProverAssignedTasks(ctx, publicKey.(string))
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if prover %s is assigned a task, err: %w", publicKey.(string), err)
 	}
 
-	if isAssigned {
-		return nil, fmt.Errorf("prover with publicKey %s is already assigned a task. ProverName: %s, ProverVersion: %s", publicKey, proverName, proverVersion)
+	if assigned >= int64(b.overrides.MaxAssignedTasksPerProver()) {
+		if !getTaskParameter.Prefetch || b.cfg.ProverManager.TaskPrefetch == nil {
+			return nil, fmt.Errorf("prover with publicKey %s is already assigned a task. ProverName: %s, ProverVersion: %s, err:%w", publicKey, proverName, proverVersion, ErrProverRateLimited)
+		}
+
+		isReserved, err := b.proverTaskOrm.IsProverReserved(ctx, publicKey.(string))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if prover %s has a reserved task, err: %w", publicKey.(string), err)
+		}
+		if isReserved {
+			return nil, fmt.Errorf("prover with publicKey %s already has a reserved task. ProverName: %s, ProverVersion: %s, err:%w", publicKey, proverName, proverVersion, ErrProverRateLimited)
+		}
+		ptc.Prefetch = true
 	}
 	return &ptc, nil
 }
@@ -115,3 +169,14 @@ func (b *BaseProverTask) getHardForkNumberByName(forkName string) (uint64, error
 
 	return hardForkNumber, nil
 }
+
+// collectForkHeights returns the sorted fork heights and the fork name to height map of the chain,
+// the fork heights configured in the coordinator config take precedence over the chain config.
+func collectForkHeights(cfg *config.Config, chainCfg *params.ChainConfig) ([]uint64, map[string]uint64) {
+	_, _, nameForkMap := forks.CollectSortedForkHeights(chainCfg)
+	var overrides map[string]uint64
+	if cfg.L2 != nil {
+		overrides = cfg.L2.ForkHeights
+	}
+	return forks.OverrideForkHeights(nameForkMap, overrides)
+}
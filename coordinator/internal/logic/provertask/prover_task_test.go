@@ -0,0 +1,34 @@
+package provertask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestTaskDeadline(t *testing.T) {
+	assignedAt := time.Unix(1000, 0)
+	assert.Equal(t, time.Unix(1600, 0), taskDeadline(assignedAt, 600))
+}
+
+func TestFinalizationTarget(t *testing.T) {
+	b := &BaseProverTask{cfg: &config.Config{ProverManager: &config.ProverManager{}}}
+	createdAt := time.Unix(1000, 0)
+	deadline := time.Unix(2000, 0)
+
+	// disabled
+	assert.Nil(t, b.finalizationTarget(createdAt, deadline))
+
+	b.cfg.ProverManager.FinalizationTargetSec = 500
+	target := b.finalizationTarget(createdAt, deadline)
+	if assert.NotNil(t, target) {
+		assert.Equal(t, time.Unix(1500, 0), *target)
+	}
+
+	// a target not before the deadline does not tell the prover more than the deadline
+	assert.Nil(t, b.finalizationTarget(createdAt, time.Unix(1500, 0)))
+	assert.Nil(t, b.finalizationTarget(createdAt, time.Unix(1400, 0)))
+}
@@ -0,0 +1,142 @@
+package provertask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/orm"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+const defaultReservationTTL = time.Minute
+
+// reservationTTL returns the time a prefetched task is reserved for its prover.
+func (b *BaseProverTask) reservationTTL() time.Duration {
+	if b.cfg.ProverManager.TaskPrefetch != nil && b.cfg.ProverManager.TaskPrefetch.ReservationTTLSec > 0 {
+		return time.Duration(b.cfg.ProverManager.TaskPrefetch.ReservationTTLSec) * time.Second
+	}
+	return defaultReservationTTL
+}
+
+// newProverTask returns the prover task of a task selected for a prover at now: reserved until the end of the
+// reservation if the prover prefetches its next task, assigned until the end of the proof collection time otherwise.
+func (b *BaseProverTask) newProverTask(taskCtx *proverTaskContext, taskID string, taskType message.ProofType, now time.Time, collectionTimeSec int) *orm.ProverTask {
+	proverTask := &orm.ProverTask{
+		TaskID:          taskID,
+		ProverPublicKey: taskCtx.PublicKey,
+		TaskType:        int16(taskType),
+		ProverName:      taskCtx.ProverName,
+		ProverVersion:   taskCtx.ProverVersion,
+		ProvingStatus:   int16(types.ProverAssigned),
+		FailureType:     int16(types.ProverTaskFailureTypeUndefined),
+		AssignedAt:      now,
+	}
+	deadline := taskDeadline(now, collectionTimeSec)
+	if taskCtx.Prefetch {
+		proverTask.ProvingStatus = int16(types.ProverReserved)
+		deadline = now.Add(b.reservationTTL())
+	}
+	proverTask.Deadline = &deadline
+	return proverTask
+}
+
+// storeProverTask stores the prover task of a task selected for a prover. The prover may have been assigned or
+// reserved a task by another coordinator replica since checkParameter, it is rate limited then.
+func (b *BaseProverTask) storeProverTask(ctx context.Context, taskCtx *proverTaskContext, proverTask *orm.ProverTask) error {
+	if taskCtx.Prefetch {
+		err := b.proverTaskOrm.InsertReservedProverTask(ctx, proverTask)
+		if errors.Is(err, orm.ErrProverAlreadyReserved) {
+			return fmt.Errorf("prover with publicKey %s already has a reserved task. ProverName: %s, ProverVersion: %s, err:%w", taskCtx.PublicKey, taskCtx.ProverName, taskCtx.ProverVersion, ErrProverRateLimited)
+		}
+		return err
+	}
+	err := b.proverTaskOrm.InsertAssignedProverTask(ctx, proverTask, b.overrides.MaxAssignedTasksPerProver())
+	if errors.Is(err, orm.ErrProverAlreadyAssigned) {
+		return fmt.Errorf("prover with publicKey %s is already assigned a task. ProverName: %s, ProverVersion: %s, err:%w", taskCtx.PublicKey, taskCtx.ProverName, taskCtx.ProverVersion, ErrProverRateLimited)
+	}
+	return err
+}
+
+// setTaskTimes sets the deadline and the finalization target of the task sent to a prover, or the end of the
+// reservation of a prefetched task, whose deadline is sent once it is assigned.
+func (b *BaseProverTask) setTaskTimes(taskMsg *coordinatorType.GetTaskSchema, proverTask *orm.ProverTask, taskCreatedAt time.Time) {
+	if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverReserved {
+		taskMsg.Deadline = 0
+		taskMsg.ReservedUntil = proverTask.Deadline.Unix()
+		return
+	}
+	if target := b.finalizationTarget(taskCreatedAt, *proverTask.Deadline); target != nil {
+		taskMsg.TargetTime = target.Unix()
+	}
+}
+
+// takeReservation assigns the prover the task of the task type it reserved by a prefetch. It returns nil if the prover
+// has no reservation left or if the reserved task no longer needs a proof, the prover is assigned another task then.
+// The attempt of the task was counted when it was reserved.
+func (b *BaseProverTask) takeReservation(ctx context.Context, taskCtx *proverTaskContext, taskType message.ProofType, collectionTimeSec int) (*orm.ProverTask, error) {
+	if b.cfg.ProverManager.TaskPrefetch == nil {
+		return nil, nil
+	}
+	proverTask, err := b.proverTaskOrm.GetReservedProverTask(ctx, taskCtx.PublicKey, taskType)
+	if err != nil || proverTask == nil {
+		return nil, err
+	}
+
+	var status types.ProvingStatus
+	switch taskType {
+	case message.ProofTypeChunk:
+		status, err = b.chunkOrm.GetProvingStatusByHash(ctx, proverTask.TaskID)
+	case message.ProofTypeBatch:
+		status, err = b.batchOrm.GetProvingStatusByHash(ctx, proverTask.TaskID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == types.ProvingTaskVerified || status == types.ProvingTaskFailed {
+		log.Info("reserved task no longer needs a proof", "task type", taskType.String(), "id", proverTask.TaskID, "public key", taskCtx.PublicKey, "proving status", status.String())
+		return nil, b.releaseReservation(ctx, proverTask)
+	}
+
+	proverTask.AssignedAt = utils.NowUTC()
+	deadline := taskDeadline(proverTask.AssignedAt, collectionTimeSec)
+	proverTask.Deadline = &deadline
+	err = b.proverTaskOrm.AssignReservedProverTask(ctx, proverTask, b.overrides.MaxAssignedTasksPerProver())
+	switch {
+	case errors.Is(err, orm.ErrReservationExpired):
+		// the coordinator cron gives back the attempt of the expired reservation.
+		return nil, nil
+	case errors.Is(err, orm.ErrProverAlreadyAssigned):
+		return nil, fmt.Errorf("prover with publicKey %s is already assigned a task. ProverName: %s, ProverVersion: %s, err:%w", taskCtx.PublicKey, taskCtx.ProverName, taskCtx.ProverVersion, ErrProverRateLimited)
+	case err != nil:
+		return nil, err
+	}
+	return proverTask, nil
+}
+
+// releaseReservation ends the reservation of a prover task and gives back the attempt counted for it, unless the task
+// is no longer reserved.
+func (b *BaseProverTask) releaseReservation(ctx context.Context, proverTask *orm.ProverTask) error {
+	return database.TransactionWithRetry(ctx, b.db, func(tx *gorm.DB) error {
+		released, err := b.proverTaskOrm.ExpireReservedProverTask(ctx, proverTask.UUID, tx)
+		if err != nil || !released {
+			return err
+		}
+		switch message.ProofType(proverTask.TaskType) {
+		case message.ProofTypeChunk:
+			return b.chunkOrm.ReleaseReservedAttemptsByHash(ctx, proverTask.TaskID, tx)
+		case message.ProofTypeBatch:
+			return b.batchOrm.ReleaseReservedAttemptsByHash(ctx, proverTask.TaskID, tx)
+		}
+		return nil
+	})
+}
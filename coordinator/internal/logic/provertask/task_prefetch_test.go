@@ -0,0 +1,47 @@
+package provertask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/config"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+func TestNewProverTask(t *testing.T) {
+	b := &BaseProverTask{cfg: &config.Config{ProverManager: &config.ProverManager{FinalizationTargetSec: 300}}}
+	now := time.Unix(1000, 0)
+	createdAt := time.Unix(900, 0)
+
+	taskCtx := &proverTaskContext{PublicKey: "0", ProverName: "prover-0", ProverVersion: "v4.4.0"}
+	proverTask := b.newProverTask(taskCtx, "chunk-0", message.ProofTypeChunk, now, 600)
+	assert.Equal(t, int16(types.ProverAssigned), proverTask.ProvingStatus)
+	assert.Equal(t, time.Unix(1600, 0), *proverTask.Deadline)
+
+	var taskMsg coordinatorType.GetTaskSchema
+	b.setTaskTimes(&taskMsg, proverTask, createdAt)
+	assert.Equal(t, int64(1200), taskMsg.TargetTime)
+	assert.Zero(t, taskMsg.ReservedUntil)
+
+	// a prefetched task is reserved for the default reservation time, its deadline is sent once it is assigned.
+	taskCtx.Prefetch = true
+	b.cfg.ProverManager.TaskPrefetch = &config.TaskPrefetch{}
+	proverTask = b.newProverTask(taskCtx, "chunk-1", message.ProofTypeChunk, now, 600)
+	assert.Equal(t, int16(types.ProverReserved), proverTask.ProvingStatus)
+	assert.Equal(t, now.Add(defaultReservationTTL), *proverTask.Deadline)
+
+	taskMsg = coordinatorType.GetTaskSchema{Deadline: proverTask.Deadline.Unix()}
+	b.setTaskTimes(&taskMsg, proverTask, createdAt)
+	assert.Zero(t, taskMsg.Deadline)
+	assert.Zero(t, taskMsg.TargetTime)
+	assert.Equal(t, now.Add(defaultReservationTTL).Unix(), taskMsg.ReservedUntil)
+
+	b.cfg.ProverManager.TaskPrefetch.ReservationTTLSec = 30
+	proverTask = b.newProverTask(taskCtx, "chunk-1", message.ProofTypeChunk, now, 600)
+	assert.Equal(t, time.Unix(1030, 0), *proverTask.Deadline)
+}
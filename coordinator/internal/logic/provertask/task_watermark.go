@@ -0,0 +1,65 @@
+package provertask
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// taskWatermarkTTL is how long the watermark read from the database is used, it is advanced by the coordinator cron
+// every few seconds.
+const taskWatermarkTTL = 5 * time.Second
+
+// unboundedTaskIndex is the max index of the tasks assigned without a budget of outstanding tasks.
+const unboundedTaskIndex = uint64(math.MaxInt64)
+
+// taskWatermark caches the watermark of a task type, the highest chunk or batch index tasks are assigned for.
+type taskWatermark struct {
+	taskType message.ProofType
+	orm      *orm.TaskWatermark
+
+	mu        sync.Mutex
+	maxIndex  uint64
+	fetchedAt time.Time
+}
+
+// newTaskWatermark returns the watermark of the task type, nil if it has no budget of outstanding tasks.
+func newTaskWatermark(cfg *config.TaskGeneration, taskType message.ProofType, db *gorm.DB) *taskWatermark {
+	if cfg.MaxOutstandingTasks(taskType) <= 0 {
+		return nil
+	}
+	return &taskWatermark{taskType: taskType, orm: orm.NewTaskWatermark(db)}
+}
+
+// maxTaskIndex returns the highest chunk or batch index tasks are assigned for, unbounded without a budget of
+// outstanding tasks or until the coordinator cron advances the watermark.
+func (w *taskWatermark) maxTaskIndex(ctx context.Context, now time.Time) (uint64, error) {
+	if w == nil {
+		return unboundedTaskIndex, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.fetchedAt.IsZero() && now.Sub(w.fetchedAt) < taskWatermarkTTL {
+		return w.maxIndex, nil
+	}
+
+	watermark, err := w.orm.GetTaskWatermark(ctx, w.taskType)
+	if err != nil {
+		return 0, err
+	}
+	w.maxIndex = unboundedTaskIndex
+	if watermark != nil && watermark.MaxIndex < unboundedTaskIndex {
+		w.maxIndex = watermark.MaxIndex
+	}
+	w.fetchedAt = now
+	return w.maxIndex, nil
+}
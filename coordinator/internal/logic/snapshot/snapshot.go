@@ -0,0 +1,239 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	ctypes "scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/auth"
+	"scroll-tech/coordinator/internal/orm"
+	"scroll-tech/coordinator/internal/types"
+)
+
+const (
+	// DefaultLimit is the default max number of pending chunks, pending batches and assignments of a snapshot each.
+	DefaultLimit = 1000
+
+	defaultInterval = 300 * time.Second
+	defaultKeep     = 288 // one day of snapshots at the default interval
+
+	filePrefix     = "scheduler-snapshot-"
+	fileSuffix     = ".json"
+	fileTimeLayout = "20060102T150405Z"
+)
+
+// Snapshotter takes snapshots of the scheduler state, i.e. the pending tasks, the assignments and the prover
+// sessions, so that incidents can be investigated without debugging a live coordinator.
+type Snapshotter struct {
+	chunkOrm      *orm.Chunk
+	batchOrm      *orm.Batch
+	proverTaskOrm *orm.ProverTask
+	sessionStore  auth.SessionStore
+}
+
+// NewSnapshotter creates a Snapshotter of the scheduler state in db and the sessions of the session store.
+func NewSnapshotter(db *gorm.DB, sessionStore auth.SessionStore) *Snapshotter {
+	return &Snapshotter{
+		chunkOrm:      orm.NewChunk(db),
+		batchOrm:      orm.NewBatch(db),
+		proverTaskOrm: orm.NewProverTask(db),
+		sessionStore:  sessionStore,
+	}
+}
+
+// Take returns the scheduler state at now, listing at most limit pending chunks, pending batches and assignments
+// each. The sessions are listed without their task data keys.
+func (s *Snapshotter) Take(ctx context.Context, limit int, now time.Time) (*types.SchedulerSnapshotSchema, error) {
+	chunks, err := s.chunkOrm.GetPendingChunks(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.batchOrm.GetPendingBatches(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	proverTasks, err := s.proverTaskOrm.GetAssignedProverTasks(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionStore.ListSessions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &types.SchedulerSnapshotSchema{
+		TakenAt:        now.Unix(),
+		PendingChunks:  make([]*types.PendingTaskSchema, 0, len(chunks)),
+		PendingBatches: make([]*types.PendingTaskSchema, 0, len(batches)),
+		Assignments:    make([]*types.AssignmentSchema, 0, len(proverTasks)),
+		Sessions:       make([]*types.ProverSessionSchema, 0, len(sessions)),
+	}
+	for _, chunk := range chunks {
+		snapshot.PendingChunks = append(snapshot.PendingChunks, &types.PendingTaskSchema{
+			TaskID:         chunk.Hash,
+			Index:          chunk.Index,
+			ProvingStatus:  ctypes.ProvingStatus(chunk.ProvingStatus).String(),
+			ActiveAttempts: chunk.ActiveAttempts,
+			TotalAttempts:  chunk.TotalAttempts,
+			AgeSec:         ageSec(chunk.CreatedAt, now),
+		})
+	}
+	for _, batch := range batches {
+		snapshot.PendingBatches = append(snapshot.PendingBatches, &types.PendingTaskSchema{
+			TaskID:         batch.Hash,
+			Index:          batch.Index,
+			ProvingStatus:  ctypes.ProvingStatus(batch.ProvingStatus).String(),
+			ActiveAttempts: batch.ActiveAttempts,
+			TotalAttempts:  batch.TotalAttempts,
+			AgeSec:         ageSec(batch.CreatedAt, now),
+		})
+	}
+	for _, proverTask := range proverTasks {
+		assignment := &types.AssignmentSchema{
+			UUID:            proverTask.UUID.String(),
+			TaskID:          proverTask.TaskID,
+			TaskType:        int(proverTask.TaskType),
+			ProverName:      proverTask.ProverName,
+			ProverPublicKey: proverTask.ProverPublicKey,
+			ProverVersion:   proverTask.ProverVersion,
+			AssignedAt:      proverTask.AssignedAt.Unix(),
+			AgeSec:          ageSec(proverTask.AssignedAt, now),
+		}
+		if proverTask.Deadline != nil {
+			assignment.Deadline = proverTask.Deadline.Unix()
+		}
+		snapshot.Assignments = append(snapshot.Assignments, assignment)
+	}
+	for _, session := range sessions {
+		snapshot.Sessions = append(snapshot.Sessions, &types.ProverSessionSchema{
+			PublicKey:          session.PublicKey,
+			ProverName:         session.ProverName,
+			ProverVersion:      session.ProverVersion,
+			ExpiredAt:          session.ExpiredAt.Unix(),
+			TaskDataEncryption: session.TaskDataKey != "",
+		})
+	}
+	return snapshot, nil
+}
+
+func ageSec(since, now time.Time) int64 {
+	return int64(now.Sub(since) / time.Second)
+}
+
+// Writer periodically writes the scheduler snapshots to a directory, keeping the latest ones.
+type Writer struct {
+	dir      string
+	interval time.Duration
+	keep     int
+	take     func(ctx context.Context, now time.Time) (*types.SchedulerSnapshotSchema, error)
+	now      func() time.Time
+}
+
+// NewWriter creates a Writer of the snapshots of the snapshotter, with the directory and retention of cfg.
+func NewWriter(cfg *config.SnapshotConfig, snapshotter *Snapshotter) *Writer {
+	w := &Writer{
+		dir:      cfg.Dir,
+		interval: defaultInterval,
+		keep:     defaultKeep,
+		take: func(ctx context.Context, now time.Time) (*types.SchedulerSnapshotSchema, error) {
+			return snapshotter.Take(ctx, DefaultLimit, now)
+		},
+		now: time.Now,
+	}
+	if cfg.IntervalSec > 0 {
+		w.interval = time.Duration(cfg.IntervalSec) * time.Second
+	}
+	if cfg.Keep > 0 {
+		w.keep = cfg.Keep
+	}
+	return w
+}
+
+// Interval returns the interval the snapshots should be written at.
+func (w *Writer) Interval() time.Duration {
+	return w.interval
+}
+
+// Write takes a snapshot, writes it to the directory and deletes the snapshots beyond the retention, e.g. in a loop
+// of utils.LoopWithContext. The failures are logged, a failed snapshot is skipped.
+func (w *Writer) Write(ctx context.Context) {
+	now := w.now().UTC()
+	snapshot, err := w.take(ctx, now)
+	if err != nil {
+		log.Error("failed to take the scheduler snapshot", "error", err)
+		return
+	}
+	path, err := w.writeFile(snapshot, now)
+	if err != nil {
+		log.Error("failed to write the scheduler snapshot", "dir", w.dir, "error", err)
+		return
+	}
+	log.Debug("scheduler snapshot written", "path", path, "pending chunks", len(snapshot.PendingChunks),
+		"pending batches", len(snapshot.PendingBatches), "assignments", len(snapshot.Assignments), "sessions", len(snapshot.Sessions))
+	if err := w.prune(); err != nil {
+		log.Warn("failed to delete the old scheduler snapshots", "dir", w.dir, "error", err)
+	}
+}
+
+// writeFile writes the snapshot to a temporary file renamed once complete, so that a crash leaves no truncated one.
+func (w *Writer) writeFile(snapshot *types.SchedulerSnapshotSchema, now time.Time) (string, error) {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(w.dir, filePrefix+now.Format(fileTimeLayout)+fileSuffix)
+	tmp, err := os.CreateTemp(w.dir, ".tmp-"+filePrefix)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to rename the snapshot to %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// prune deletes the snapshots but the latest keep ones, the file names sort by time.
+func (w *Writer) prune() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) <= w.keep {
+		return nil
+	}
+	sort.Strings(names)
+	for _, name := range names[:len(names)-w.keep] {
+		if err := os.Remove(filepath.Join(w.dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/types"
+)
+
+func TestWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	w := NewWriter(&config.SnapshotConfig{Dir: dir, Keep: 2}, nil)
+	assert.Equal(t, defaultInterval, w.Interval())
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	var takeErr error
+	w.take = func(_ context.Context, now time.Time) (*types.SchedulerSnapshotSchema, error) {
+		if takeErr != nil {
+			return nil, takeErr
+		}
+		return &types.SchedulerSnapshotSchema{
+			TakenAt:     now.Unix(),
+			Assignments: []*types.AssignmentSchema{{TaskID: "task", AgeSec: 42}},
+		}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		w.Write(context.Background())
+		now = now.Add(time.Minute)
+	}
+	// a failed snapshot is skipped.
+	takeErr = errors.New("db down")
+	w.Write(context.Background())
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"scheduler-snapshot-20240102T030505Z.json", "scheduler-snapshot-20240102T030605Z.json"}, names)
+
+	data, err := os.ReadFile(filepath.Join(dir, names[1]))
+	assert.NoError(t, err)
+	var snapshot types.SchedulerSnapshotSchema
+	assert.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 6, 5, 0, time.UTC).Unix(), snapshot.TakenAt)
+	assert.Equal(t, []*types.AssignmentSchema{{TaskID: "task", AgeSec: 42}}, snapshot.Assignments)
+}
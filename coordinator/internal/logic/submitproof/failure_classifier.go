@@ -0,0 +1,76 @@
+package submitproof
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"scroll-tech/common/types"
+)
+
+// maxFailureMsgLength bounds the failure message stored with a proof failure, prover backtraces can be huge.
+const maxFailureMsgLength = 4096
+
+// failureClassPatterns are matched in order against the lower-cased failure message, the first match wins.
+// They are anchored to the error strings of the prover and its runtime, as bare keywords like "killed" or
+// "version" also show up in unrelated panics and backtraces.
+var failureClassPatterns = []struct {
+	class    types.ProofFailureClass
+	patterns []*regexp.Regexp
+}{
+	{types.ProofFailureClassOOM, []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^memory allocation of \d+ bytes failed`), // rust allocation failure
+		regexp.MustCompile(`\bout of memory\b`),
+		regexp.MustCompile(`\boom[- ]kill`),
+		regexp.MustCompile(`\bkilled by signal 9\b`),
+		regexp.MustCompile(`(?m)signal: killed$`), // os/exec error of a process killed by SIGKILL
+	}},
+	{types.ProofFailureClassVersionMismatch, []*regexp.Regexp{
+		regexp.MustCompile(`\bvk mismatch\b`),
+		regexp.MustCompile(`\bverifying key mismatch\b`),
+		regexp.MustCompile(`\bunsupported (circuit|prover) version\b`),
+		regexp.MustCompile(`\bhard ?fork mismatch\b`),
+	}},
+	{types.ProofFailureClassWitnessMismatch, []*regexp.Regexp{
+		regexp.MustCompile(`\bwitness generation failed\b`),
+		regexp.MustCompile(`\bfailed to generate witness\b`),
+		regexp.MustCompile(`\bconstraints? (is |are )?not satisfied\b`),
+	}},
+}
+
+// classifyProofFailure classifies the failure of a prover task by its error and the failure message of the prover.
+// Proofs rejected for their fork or vk are version mismatches, and invalid proofs without a more specific
+// message are witness mismatches, as they mean the witness did not satisfy the circuit of the verifier.
+func classifyProofFailure(err error, failureMsg string) types.ProofFailureClass {
+	if errors.Is(err, ErrValidatorFailureHardForkMismatch) || errors.Is(err, ErrValidatorFailureVerifierKeyMismatch) {
+		return types.ProofFailureClassVersionMismatch
+	}
+
+	msg := strings.ToLower(failureMsg)
+	for _, c := range failureClassPatterns {
+		for _, pattern := range c.patterns {
+			if pattern.MatchString(msg) {
+				return c.class
+			}
+		}
+	}
+
+	if errors.Is(err, ErrValidatorSuccessInvalidProof) {
+		return types.ProofFailureClassWitnessMismatch
+	}
+	return types.ProofFailureClassUnknown
+}
+
+// truncateFailureMsg truncates a failure message to at most maxFailureMsgLength bytes,
+// cutting on a rune boundary so that the stored message stays valid UTF-8.
+func truncateFailureMsg(msg string) string {
+	if len(msg) <= maxFailureMsgLength {
+		return msg
+	}
+	cut := maxFailureMsgLength
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut]
+}
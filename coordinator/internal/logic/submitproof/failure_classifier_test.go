@@ -0,0 +1,53 @@
+package submitproof
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+)
+
+func TestClassifyProofFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		failureMsg string
+		want       types.ProofFailureClass
+	}{
+		{"hard fork mismatch", ErrValidatorFailureHardForkMismatch, "", types.ProofFailureClassVersionMismatch},
+		{"vk mismatch", ErrValidatorFailureVerifierKeyMismatch, "", types.ProofFailureClassVersionMismatch},
+		{"oom", ErrValidatorFailureProofMsgStatusNotOk, "memory allocation of 68719476736 bytes failed", types.ProofFailureClassOOM},
+		{"killed", ErrValidatorFailureProofMsgStatusNotOk, "prover process Killed by signal 9", types.ProofFailureClassOOM},
+		{"witness", ErrValidatorFailureProofMsgStatusNotOk, "panicked at 'witness generation failed: state root mismatch'", types.ProofFailureClassWitnessMismatch},
+		{"constraint", ErrValidatorFailureProofMsgStatusNotOk, "constraint not satisfied in region evm circuit", types.ProofFailureClassWitnessMismatch},
+		{"go oom killed", ErrValidatorFailureProofMsgStatusNotOk, "prover exited: signal: killed", types.ProofFailureClassOOM},
+		{"version", ErrValidatorFailureProofMsgStatusNotOk, "unsupported circuit version", types.ProofFailureClassVersionMismatch},
+		{"vk", ErrValidatorFailureProofMsgStatusNotOk, "verifying key mismatch for chunk proof", types.ProofFailureClassVersionMismatch},
+		{"witness panic printing a version", ErrValidatorFailureProofMsgStatusNotOk, "prover version v4.4.1: witness generation failed: tx 3 reverted", types.ProofFailureClassWitnessMismatch},
+		{"killed task", ErrValidatorFailureProofMsgStatusNotOk, "task killed by coordinator restart", types.ProofFailureClassUnknown},
+		{"deserialize", ErrValidatorFailureProofMsgStatusNotOk, "failed to deserialize block trace", types.ProofFailureClassUnknown},
+		{"invalid proof", ErrValidatorSuccessInvalidProof, "", types.ProofFailureClassWitnessMismatch},
+		{"verifier error", ErrValidatorFailureVerifiedFailed, "", types.ProofFailureClassUnknown},
+		{"unknown", errors.New("other"), "connection reset by peer", types.ProofFailureClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyProofFailure(tt.err, tt.failureMsg))
+		})
+	}
+}
+
+func TestTruncateFailureMsg(t *testing.T) {
+	assert.Equal(t, "panic", truncateFailureMsg("panic"))
+	assert.Len(t, truncateFailureMsg(strings.Repeat("a", maxFailureMsgLength+1)), maxFailureMsgLength)
+
+	// a multi-byte rune crossing the limit is dropped as a whole
+	truncated := truncateFailureMsg(strings.Repeat("a", maxFailureMsgLength-1) + "é")
+	assert.Len(t, truncated, maxFailureMsgLength-1)
+	assert.True(t, utf8.ValidString(truncated))
+}
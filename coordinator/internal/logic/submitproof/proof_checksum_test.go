@@ -0,0 +1,71 @@
+package submitproof
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/orm"
+)
+
+func newChunkProofMsg(proof []byte) *message.ProofMsg {
+	return &message.ProofMsg{ProofDetail: &message.ProofDetail{
+		ID:         "chunk-hash",
+		Type:       message.ProofTypeChunk,
+		Status:     message.StatusOk,
+		ChunkProof: &message.ChunkProof{Proof: proof, Instances: []byte{1}, Vk: []byte{2}},
+	}}
+}
+
+func TestProofChecksum(t *testing.T) {
+	proofBytes, err := marshalProof(newChunkProofMsg([]byte{1, 2, 3}))
+	assert.NoError(t, err)
+	sameBytes, err := marshalProof(newChunkProofMsg([]byte{1, 2, 3}))
+	assert.NoError(t, err)
+	otherBytes, err := marshalProof(newChunkProofMsg([]byte{1, 2, 4}))
+	assert.NoError(t, err)
+
+	assert.Len(t, proofChecksum(proofBytes), 64)
+	assert.Equal(t, proofChecksum(proofBytes), proofChecksum(sameBytes))
+	assert.NotEqual(t, proofChecksum(proofBytes), proofChecksum(otherBytes))
+
+	_, err = marshalProof(&message.ProofMsg{ProofDetail: &message.ProofDetail{Type: message.ProofTypeUndefined}})
+	assert.Error(t, err)
+}
+
+func TestCheckDuplicateProof(t *testing.T) {
+	m := &ProofReceiverLogic{
+		duplicateProofTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_duplicate_total"}, []string{"result"}),
+	}
+	proofBytes, err := marshalProof(newChunkProofMsg([]byte{1, 2, 3}))
+	assert.NoError(t, err)
+	checksum := proofChecksum(proofBytes)
+
+	newProverTask := func(status types.ProverProveStatus, checksum string) *orm.ProverTask {
+		return &orm.ProverTask{UUID: "uuid", ProvingStatus: int16(status), ProofChecksum: checksum}
+	}
+
+	// not verified yet, or verified before the checksums were stored, the proof is handled as usual.
+	duplicate, err := m.checkDuplicateProof(newProverTask(types.ProverAssigned, ""), newChunkProofMsg([]byte{1, 2, 3}))
+	assert.False(t, duplicate)
+	assert.NoError(t, err)
+	duplicate, _ = m.checkDuplicateProof(newProverTask(types.ProverProofValid, ""), newChunkProofMsg([]byte{1, 2, 3}))
+	assert.False(t, duplicate)
+
+	// the same proof gets the result of its verification.
+	duplicate, err = m.checkDuplicateProof(newProverTask(types.ProverProofValid, checksum), newChunkProofMsg([]byte{1, 2, 3}))
+	assert.True(t, duplicate)
+	assert.NoError(t, err)
+	duplicate, err = m.checkDuplicateProof(newProverTask(types.ProverProofInvalid, checksum), newChunkProofMsg([]byte{1, 2, 3}))
+	assert.True(t, duplicate)
+	assert.ErrorIs(t, err, ErrValidatorSuccessInvalidProof)
+
+	// a different proof is rejected.
+	duplicate, err = m.checkDuplicateProof(newProverTask(types.ProverProofValid, checksum), newChunkProofMsg([]byte{1, 2, 4}))
+	assert.True(t, duplicate)
+	assert.ErrorIs(t, err, ErrValidatorFailureProofMismatch)
+}
@@ -2,23 +2,35 @@ package submitproof
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/concurrency"
+	"scroll-tech/common/database"
+	"scroll-tech/common/forks"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/overrides"
 	"scroll-tech/coordinator/internal/logic/verifier"
+	"scroll-tech/coordinator/internal/logic/webhook"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -30,28 +42,46 @@ var (
 	ErrValidatorFailureProverTaskEmpty = errors.New("validator failure get none prover task for the proof")
 	// ErrValidatorFailureProverTaskCannotSubmitTwice prove task can not submit proof twice
 	ErrValidatorFailureProverTaskCannotSubmitTwice = errors.New("validator failure prove task cannot submit proof twice")
+	// ErrValidatorFailureProverTaskReserved the prover task is only reserved by a prefetch, it was not assigned yet
+	ErrValidatorFailureProverTaskReserved = errors.New("validator failure prover task is reserved but not assigned")
 	// ErrValidatorFailureProofTimeout the submit proof is timeout
 	ErrValidatorFailureProofTimeout = errors.New("validator failure submit proof timeout")
+	// ErrValidatorFailureProofDeadlineExceeded the proof is submitted after the deadline of the task
+	ErrValidatorFailureProofDeadlineExceeded = errors.New("validator failure submit proof after the task deadline")
+	// ErrValidatorFailureHardForkMismatch the task belongs to a hard fork the verifier is not built for
+	ErrValidatorFailureHardForkMismatch = errors.New("validator failure task hard fork mismatch with the verifier")
+	// ErrValidatorFailureVerifierKeyMismatch the proof was generated with a different verifier key
+	ErrValidatorFailureVerifierKeyMismatch = errors.New("validator failure proof vk mismatch with the hard fork vk")
+	// ErrValidatorFailureProofMismatch a different proof was submitted and verified for the prover task already
+	ErrValidatorFailureProofMismatch = errors.New("validator failure proof mismatch with the one submitted for the task")
 	// ErrValidatorFailureTaskHaveVerifiedSuccess have proved success and verified success
 	ErrValidatorFailureTaskHaveVerifiedSuccess = errors.New("validator failure chunk/batch have proved and verified success")
 	// ErrValidatorFailureVerifiedFailed failed to verify and the verifier returns error
 	ErrValidatorFailureVerifiedFailed = fmt.Errorf("verification failed, verifier returns error")
 	// ErrValidatorSuccessInvalidProof successful verified and the proof is invalid
 	ErrValidatorSuccessInvalidProof = fmt.Errorf("verification succeeded, it's an invalid proof")
+	// ErrProofNotAccepted the proof was left unhandled as another proof of its all-or-nothing submission failed
+	ErrProofNotAccepted = errors.New("proof not accepted, another proof of the submission failed")
 	// ErrCoordinatorInternalFailure coordinator internal db failure
 	ErrCoordinatorInternalFailure = fmt.Errorf("coordinator internal error")
 )
 
 // ProofReceiverLogic the proof receiver logic
 type ProofReceiverLogic struct {
-	chunkOrm      *orm.Chunk
-	batchOrm      *orm.Batch
-	proverTaskOrm *orm.ProverTask
+	chunkOrm           *orm.Chunk
+	batchOrm           *orm.Batch
+	batchDependencyOrm *orm.BatchDependency
+	proverTaskOrm      *orm.ProverTask
+	proofFailureOrm    *orm.ProofFailure
 
 	db  *gorm.DB
 	cfg *config.ProverManager
 
-	verifier *verifier.Verifier
+	verifier     *verifier.Verifier
+	verifierPool *concurrency.Pool
+	nameForkMap  map[string]uint64
+	notifier     *webhook.Notifier
+	overrides    *overrides.Overrides // the hard fork the proofs are verified for
 
 	proofReceivedTotal                    prometheus.Counter
 	proofSubmitFailure                    prometheus.Counter
@@ -63,19 +93,40 @@ type ProofReceiverLogic struct {
 	validateFailureProverTaskStatusNotOk  prometheus.Counter
 	validateFailureProverTaskTimeout      prometheus.Counter
 	validateFailureProverTaskHaveVerifier prometheus.Counter
+	validateFailureHardForkMismatch       prometheus.Counter
+	validateFailureDeadlineExceeded       prometheus.Counter
+	proofDeadlineMissSeconds              prometheus.Histogram
+	proofFailureTotal                     *prometheus.CounterVec
+	provingTimeSeconds                    *prometheus.HistogramVec
+	duplicateProofTotal                   *prometheus.CounterVec
 }
 
 // NewSubmitProofReceiverLogic create a proof receiver logic
-func NewSubmitProofReceiverLogic(cfg *config.ProverManager, db *gorm.DB, vf *verifier.Verifier, reg prometheus.Registerer) *ProofReceiverLogic {
+func NewSubmitProofReceiverLogic(cfg *config.ProverManager, l2Cfg *config.L2, chainCfg *params.ChainConfig, db *gorm.DB, vf *verifier.Verifier, ov *overrides.Overrides, notifier *webhook.Notifier, reg prometheus.Registerer) *ProofReceiverLogic {
+	_, _, nameForkMap := forks.CollectSortedForkHeights(chainCfg)
+	if l2Cfg != nil {
+		_, nameForkMap = forks.OverrideForkHeights(nameForkMap, l2Cfg.ForkHeights)
+	}
+	maxVerifierWorkers := cfg.MaxVerifierWorkers
+	if maxVerifierWorkers <= 0 {
+		maxVerifierWorkers = runtime.NumCPU()
+	}
+
 	return &ProofReceiverLogic{
-		chunkOrm:      orm.NewChunk(db),
-		batchOrm:      orm.NewBatch(db),
-		proverTaskOrm: orm.NewProverTask(db),
+		chunkOrm:           orm.NewChunk(db),
+		batchOrm:           orm.NewBatch(db),
+		batchDependencyOrm: orm.NewBatchDependency(db),
+		proverTaskOrm:      orm.NewProverTask(db),
+		proofFailureOrm:    orm.NewProofFailure(db),
 
 		cfg: cfg,
 		db:  db,
 
-		verifier: vf,
+		verifier:     vf,
+		verifierPool: concurrency.NewPool(maxVerifierWorkers),
+		nameForkMap:  nameForkMap,
+		notifier:     notifier,
+		overrides:    ov,
 
 		proofReceivedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_submit_proof_total",
@@ -118,21 +169,153 @@ func NewSubmitProofReceiverLogic(cfg *config.ProverManager, db *gorm.DB, vf *ver
 			Name: "coordinator_validate_failure_submit_have_been_verifier",
 			Help: "Total number of submit proof validate failure proof have been verifier.",
 		}),
+		validateFailureHardForkMismatch: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_validate_failure_hard_fork_mismatch",
+			Help: "Total number of submit proof validate failure hard fork or vk mismatch.",
+		}),
+		validateFailureDeadlineExceeded: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_validate_failure_submit_deadline_exceeded",
+			Help: "Total number of submit proof validate failure proof submitted after the task deadline.",
+		}),
+		proofDeadlineMissSeconds: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Name:    "coordinator_proof_deadline_miss_seconds",
+			Help:    "Time by which proofs submitted after the task deadline missed it.",
+			Buckets: []float64{10, 30, 60, 180, 300, 600, 1800, 3600},
+		}),
+		proofFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_proof_failure_total",
+			Help: "Total number of failed proofs by task type and failure class.",
+		}, []string{"task_type", "failure_class"}),
+		provingTimeSeconds: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "coordinator_proving_time_seconds",
+			Help:    "Proving time of the verified proofs by task type, hard fork and task size class, as reported by the provers or else since the task assignment.",
+			Buckets: []float64{60, 120, 180, 300, 480, 600, 900, 1200, 1800, 2700, 3600},
+		}, []string{"task_type", "hard_fork", "size_class"}),
+		duplicateProofTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_submit_proof_duplicate_total",
+			Help: "Total number of proofs submitted again for verified prover tasks, by whether they match the verified proof.",
+		}, []string{"result"}),
 	}
 }
 
+// proofSubmission is a submitted proof along with its prover task, handled in the steps of HandleZkProof.
+type proofSubmission struct {
+	proofMsg       *message.ProofMsg
+	proofParameter coordinatorType.SubmitProofParameter
+	proverTask     *orm.ProverTask
+	proverVersion  string
+	proofTimeSec   uint64
+	// duplicate is set if the proof was submitted again for a verified prover task, it is answered without verification.
+	duplicate bool
+}
+
 // HandleZkProof handle a ZkProof submitted from a prover.
 // For now only proving/verifying error will lead to setting status as skipped.
 // db/unmarshal errors will not because they are errors on the business logic side.
 func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.ProofMsg, proofParameter coordinatorType.SubmitProofParameter) error {
+	submission, err := m.prepare(ctx, proofMsg, proofParameter)
+	if err != nil || submission.duplicate {
+		return err
+	}
+	if err = m.verify(ctx, submission); err != nil {
+		return err
+	}
+
+	if err = m.closeProofTask(ctx, submission.proverTask, proofMsg, submission.proofTimeSec); err != nil {
+		m.proofSubmitFailure.Inc()
+
+		m.proofRecover(ctx, submission.proverTask, types.ProverTaskFailureTypeServerError, proofMsg)
+
+		return ErrCoordinatorInternalFailure
+	}
+	m.notifyAccepted(submission)
+	return nil
+}
+
+// HandleZkProofs handles the proofs submitted together by a prover, and returns the error of each of them, nil for
+// the accepted ones. The proofs are verified concurrently. If allOrNothing is set, the proofs are only accepted if all
+// of them are valid, in a single transaction, the valid proofs are otherwise left unhandled with ErrProofNotAccepted.
+func (m *ProofReceiverLogic) HandleZkProofs(ctx *gin.Context, proofMsgs []*message.ProofMsg, proofParameters []coordinatorType.SubmitProofParameter, allOrNothing bool) []error {
+	errs := make([]error, len(proofMsgs))
+	submissions := make([]*proofSubmission, len(proofMsgs))
+	for i, proofMsg := range proofMsgs {
+		submissions[i], errs[i] = m.prepare(ctx, proofMsg, proofParameters[i])
+	}
+
+	var wg sync.WaitGroup
+	for i, submission := range submissions {
+		if errs[i] != nil || submission.duplicate {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, submission *proofSubmission) {
+			defer wg.Done()
+			errs[i] = m.verify(ctx, submission)
+		}(i, submission)
+	}
+	wg.Wait()
+
+	if !allOrNothing {
+		for i, submission := range submissions {
+			if errs[i] != nil || submission.duplicate {
+				continue
+			}
+			if err := m.closeProofTask(ctx, submission.proverTask, submission.proofMsg, submission.proofTimeSec); err != nil {
+				m.proofSubmitFailure.Inc()
+				m.proofRecover(ctx, submission.proverTask, types.ProverTaskFailureTypeServerError, submission.proofMsg)
+				errs[i] = ErrCoordinatorInternalFailure
+				continue
+			}
+			m.notifyAccepted(submission)
+		}
+		return errs
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = ErrProofNotAccepted
+				}
+			}
+			return errs
+		}
+	}
+
+	var accepted []*proofSubmission
+	for _, submission := range submissions {
+		if !submission.duplicate {
+			accepted = append(accepted, submission)
+		}
+	}
+	if err := m.closeProofTasks(ctx, accepted); err != nil {
+		m.proofSubmitFailure.Add(float64(len(accepted)))
+		for _, submission := range accepted {
+			m.proofRecover(ctx, submission.proverTask, types.ProverTaskFailureTypeServerError, submission.proofMsg)
+		}
+		for i, submission := range submissions {
+			if !submission.duplicate {
+				errs[i] = ErrCoordinatorInternalFailure
+			}
+		}
+		return errs
+	}
+	for _, submission := range accepted {
+		m.notifyAccepted(submission)
+	}
+	return errs
+}
+
+// prepare looks up the prover task of the proof and validates the submission, the proof is not verified yet.
+func (m *ProofReceiverLogic) prepare(ctx *gin.Context, proofMsg *message.ProofMsg, proofParameter coordinatorType.SubmitProofParameter) (*proofSubmission, error) {
 	m.proofReceivedTotal.Inc()
 	pk := ctx.GetString(coordinatorType.PublicKey)
 	if len(pk) == 0 {
-		return fmt.Errorf("get public key from context failed")
+		return nil, fmt.Errorf("get public key from context failed")
 	}
 	pv := ctx.GetString(coordinatorType.ProverVersion)
 	if len(pv) == 0 {
-		return fmt.Errorf("get ProverVersion from context failed")
+		return nil, fmt.Errorf("get ProverVersion from context failed")
 	}
 
 	var proverTask *orm.ProverTask
@@ -141,79 +324,159 @@ func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.P
 		proverTask, err = m.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(ctx, proofParameter.UUID, pk)
 		if proverTask == nil || err != nil {
 			log.Error("get none prover task for the proof", "uuid", proofParameter.UUID, "key", pk, "taskID", proofMsg.ID, "error", err)
-			return ErrValidatorFailureProverTaskEmpty
+			return nil, ErrValidatorFailureProverTaskEmpty
 		}
 	} else {
 		// TODO When prover all have upgrade, need delete this logic
 		proverTask, err = m.proverTaskOrm.GetAssignedProverTaskByTaskIDAndProver(ctx, proofMsg.Type, proofMsg.ID, pk, pv)
 		if proverTask == nil || err != nil {
 			log.Error("get none prover task for the proof", "key", pk, "taskID", proofMsg.ID, "error", err)
-			return ErrValidatorFailureProverTaskEmpty
+			return nil, ErrValidatorFailureProverTaskEmpty
 		}
 	}
 
-	proofTime := time.Since(proverTask.CreatedAt)
-	proofTimeSec := uint64(proofTime.Seconds())
+	if proofParameter.ProvingTimeMs > 0 {
+		if err = m.proverTaskOrm.UpdateProverTaskProvingTime(ctx, proverTask.UUID, proofParameter.ProvingTimeMs); err != nil {
+			log.Warn("failed to store the reported proving time", "uuid", proverTask.UUID, "taskID", proofMsg.ID, "error", err)
+		}
+	}
+
+	submission := &proofSubmission{
+		proofMsg:       proofMsg,
+		proofParameter: proofParameter,
+		proverTask:     proverTask,
+		proverVersion:  pv,
+		proofTimeSec:   uint64(time.Since(proverTask.CreatedAt).Seconds()),
+	}
 
 	log.Info("handling zk proof", "proofID", proofMsg.ID, "proverName", proverTask.ProverName,
-		"proverPublicKey", pk, "proveType", proverTask.TaskType, "proofTime", proofTimeSec)
+		"proverPublicKey", pk, "proveType", proverTask.TaskType, "proofTime", submission.proofTimeSec)
+
+	if duplicate, duplicateErr := m.checkDuplicateProof(proverTask, proofMsg); duplicate {
+		if duplicateErr != nil {
+			return nil, duplicateErr
+		}
+		submission.duplicate = true
+		return submission, nil
+	}
 
 	if err = m.validator(ctx, proverTask, pk, proofMsg, proofParameter); err != nil {
-		return err
+		return nil, err
 	}
+	return submission, nil
+}
 
-	m.verifierTotal.WithLabelValues(pv).Inc()
+// verify verifies the proof of the submission, the prover task is marked failed if the proof is invalid.
+func (m *ProofReceiverLogic) verify(ctx *gin.Context, submission *proofSubmission) error {
+	proofMsg, proverTask := submission.proofMsg, submission.proverTask
+	m.verifierTotal.WithLabelValues(submission.proverVersion).Inc()
 
-	var success bool
+	// verifications are cpu heavy, at most MaxVerifierWorkers of them run at a time.
 	var verifyErr error
-	if proofMsg.Type == message.ProofTypeChunk {
-		success, verifyErr = m.verifier.VerifyChunkProof(proofMsg.ChunkProof)
-	} else if proofMsg.Type == message.ProofTypeBatch {
-		success, verifyErr = m.verifier.VerifyBatchProof(proofMsg.BatchProof)
+	success, poolErr := concurrency.Run(ctx.Request.Context(), m.verifierPool, func() (bool, error) {
+		var success bool
+		if proofMsg.Type == message.ProofTypeChunk {
+			success, verifyErr = m.verifier.VerifyChunkProof(proofMsg.ChunkProof)
+		} else if proofMsg.Type == message.ProofTypeBatch {
+			success, verifyErr = m.verifier.VerifyBatchProof(proofMsg.BatchProof)
+		}
+		return success, nil
+	})
+	if poolErr != nil {
+		// the request ended while waiting for a verifier, which is not the fault of the prover, the task is left
+		// assigned until it times out.
+		log.Warn("proof not verified, no verifier available", "proof id", proofMsg.ID, "prover name", proverTask.ProverName, "error", poolErr)
+		return ErrCoordinatorInternalFailure
 	}
 
 	if verifyErr != nil || !success {
-		m.verifierFailureTotal.WithLabelValues(pv).Inc()
+		m.verifierFailureTotal.WithLabelValues(submission.proverVersion).Inc()
 
 		m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, proofMsg)
 
 		log.Info("proof verified by coordinator failed", "proof id", proofMsg.ID, "prover name", proverTask.ProverName,
-			"prover pk", pk, "prove type", proofMsg.Type, "proof time", proofTimeSec, "error", verifyErr)
+			"prover pk", proverTask.ProverPublicKey, "prove type", proofMsg.Type, "proof time", submission.proofTimeSec, "error", verifyErr)
 
 		if verifyErr != nil {
+			m.recordProofFailure(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, ErrValidatorFailureVerifiedFailed, verifyErr.Error())
 			return ErrValidatorFailureVerifiedFailed
 		}
+		m.recordProofFailure(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, ErrValidatorSuccessInvalidProof, "")
 		return ErrValidatorSuccessInvalidProof
 	}
 
 	m.proverTaskProveDuration.Observe(time.Since(proverTask.CreatedAt).Seconds())
+	m.observeProvingTime(ctx, proverTask, proofMsg.Type, submission.proofParameter.ProvingTimeMs)
 
 	log.Info("proof verified and valid", "proof id", proofMsg.ID, "prover name", proverTask.ProverName,
-		"prover pk", pk, "prove type", proofMsg.Type, "proof time", proofTimeSec)
-
-	if err := m.closeProofTask(ctx, proverTask, proofMsg, proofTimeSec); err != nil {
-		m.proofSubmitFailure.Inc()
+		"prover pk", proverTask.ProverPublicKey, "prove type", proofMsg.Type, "proof time", submission.proofTimeSec)
+	return nil
+}
 
-		m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeServerError, proofMsg)
+// notifyAccepted notifies the webhooks of an accepted batch proof.
+func (m *ProofReceiverLogic) notifyAccepted(submission *proofSubmission) {
+	if submission.proofMsg.Type != message.ProofTypeBatch {
+		return
+	}
+	proverTask := submission.proverTask
+	m.notifier.NotifyBatchProofVerified(&webhook.BatchProofVerifiedEvent{
+		BatchHash:       submission.proofMsg.ID,
+		TaskUUID:        proverTask.UUID.String(),
+		ProofTimeSec:    submission.proofTimeSec,
+		ProverName:      proverTask.ProverName,
+		ProverPublicKey: proverTask.ProverPublicKey,
+		ProverVersion:   proverTask.ProverVersion,
+		VerifiedAt:      utils.NowUTC().Unix(),
+	})
+}
 
-		return ErrCoordinatorInternalFailure
+// checkDuplicateProof answers the proofs submitted again for a prover task already verified, e.g. by a prover retrying
+// after a lost response, without verifying them again: the result of the verification is returned for the same proof,
+// and a different proof is rejected. It returns false if the proof is to be handled as usual.
+func (m *ProofReceiverLogic) checkDuplicateProof(proverTask *orm.ProverTask, proofMsg *message.ProofMsg) (bool, error) {
+	status := types.ProverProveStatus(proverTask.ProvingStatus)
+	if proverTask.ProofChecksum == "" || proofMsg.Status != message.StatusOk ||
+		(status != types.ProverProofValid && status != types.ProverProofInvalid) {
+		return false, nil
+	}
+	proofBytes, err := marshalProof(proofMsg)
+	if err != nil {
+		return false, nil
 	}
 
-	return nil
+	if proofChecksum(proofBytes) != proverTask.ProofChecksum {
+		m.duplicateProofTotal.WithLabelValues("mismatch").Inc()
+		log.Warn("a different proof submitted for a verified prover task", "hash", proofMsg.ID, "taskType", proverTask.TaskType,
+			"proverName", proverTask.ProverName, "proverPublicKey", proverTask.ProverPublicKey, "uuid", proverTask.UUID)
+		return true, ErrValidatorFailureProofMismatch
+	}
+	m.duplicateProofTotal.WithLabelValues("match").Inc()
+	log.Info("proof submitted again for a verified prover task, skip verification", "hash", proofMsg.ID, "taskType", proverTask.TaskType,
+		"proverName", proverTask.ProverName, "proverPublicKey", proverTask.ProverPublicKey, "uuid", proverTask.UUID, "valid", status == types.ProverProofValid)
+	if status == types.ProverProofValid {
+		return true, nil
+	}
+	return true, ErrValidatorSuccessInvalidProof
 }
 
+// checkAreAllChunkProofsReady marks the dependency of the batch of the chunk on its proof verified, and makes the
+// batch assignable once all its dependencies are verified.
 func (m *ProofReceiverLogic) checkAreAllChunkProofsReady(ctx context.Context, chunkHash string) error {
-	batch, err := m.chunkOrm.GetChunkByHash(ctx, chunkHash)
+	chunk, err := m.chunkOrm.GetChunkByHash(ctx, chunkHash)
 	if err != nil {
 		return err
 	}
+	if chunk.BatchHash == "" {
+		// the dependencies are recorded once the chunk is batched.
+		return nil
+	}
 
-	allReady, err := m.chunkOrm.CheckIfBatchChunkProofsAreReady(ctx, batch.BatchHash)
+	unverified, err := m.batchDependencyOrm.SyncBatchDependencies(ctx, chunk.BatchHash)
 	if err != nil {
 		return err
 	}
-	if allReady {
-		err := m.batchOrm.UpdateChunkProofsStatusByBatchHash(ctx, batch.BatchHash, types.ChunkProofsStatusReady)
+	if unverified == 0 {
+		err := m.batchOrm.UpdateChunkProofsStatusByBatchHash(ctx, chunk.BatchHash, types.ChunkProofsStatusReady)
 		if err != nil {
 			return err
 		}
@@ -245,6 +508,12 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 		return ErrValidatorFailureProverTaskCannotSubmitTwice
 	}
 
+	// the reserved task must be assigned by get_task before its proof is accepted.
+	if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverReserved {
+		log.Warn("cannot submit proof for a reserved prover task", "taskType", proverTask.TaskType, "hash", proofMsg.ID, "proverPublicKey", proverTask.ProverPublicKey)
+		return ErrValidatorFailureProverTaskReserved
+	}
+
 	proofTime := time.Since(proverTask.CreatedAt)
 	proofTimeSec := uint64(proofTime.Seconds())
 
@@ -253,6 +522,7 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 		failureMsg := strings.Replace(proofParameter.FailureMsg, "panic", "pa-nic", -1)
 
 		m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeSubmitStatusNotOk, proofMsg)
+		m.recordProofFailure(ctx, proverTask, types.ProverTaskFailureTypeSubmitStatusNotOk, ErrValidatorFailureProofMsgStatusNotOk, proofParameter.FailureMsg)
 
 		m.validateFailureProverTaskStatusNotOk.Inc()
 
@@ -271,6 +541,26 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 		return ErrValidatorFailureProofTimeout
 	}
 
+	if proverTask.Deadline != nil {
+		if late := utils.NowUTC().Sub(*proverTask.Deadline); late > 0 {
+			m.validateFailureDeadlineExceeded.Inc()
+			m.proofDeadlineMissSeconds.Observe(late.Seconds())
+			m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeTimeout, proofMsg)
+			log.Info("proof submitted after the task deadline, skip this submit proof", "hash", proofMsg.ID, "taskType", proverTask.TaskType,
+				"proverName", proverTask.ProverName, "proverPublicKey", pk, "deadline", proverTask.Deadline, "late", late)
+			return ErrValidatorFailureProofDeadlineExceeded
+		}
+	}
+
+	if hardForkErr := m.validateHardFork(ctx, proverTask, proofMsg); hardForkErr != nil {
+		m.validateFailureHardForkMismatch.Inc()
+		m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, proofMsg)
+		m.recordProofFailure(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, hardForkErr, hardForkErr.Error())
+		log.Info("proof does not match the hard fork of the task", "hash", proofMsg.ID, "taskType", proverTask.TaskType,
+			"proverName", proverTask.ProverName, "proverPublicKey", pk, "error", hardForkErr)
+		return hardForkErr
+	}
+
 	// store the proof to prover task
 	if updateTaskProofErr := m.updateProverTaskProof(ctx, proverTask, proofMsg); updateTaskProofErr != nil {
 		log.Warn("update prover task proof failure", "hash", proofMsg.ID, "proverPublicKey", pk,
@@ -287,6 +577,142 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 	return nil
 }
 
+// validateHardFork checks that the task belongs to the hard fork the verifier is built for,
+// and that the proof was generated with that fork's verifier key.
+func (m *ProofReceiverLogic) validateHardFork(ctx context.Context, proverTask *orm.ProverTask, proofMsg *message.ProofMsg) error {
+	var proofVK []byte
+	switch proofMsg.Type {
+	case message.ProofTypeChunk:
+		if proofMsg.ChunkProof != nil {
+			proofVK = proofMsg.ChunkProof.Vk
+		}
+	case message.ProofTypeBatch:
+		if proofMsg.BatchProof != nil {
+			proofVK = proofMsg.BatchProof.Vk
+		}
+	}
+
+	verifierForkName := m.overrides.VerifierForkName()
+	forkNameConfigured := verifierForkName != ""
+	var hardForkName string
+	if forkNameConfigured || m.verifier.HasVKRegistry() {
+		var err error
+		hardForkName, err = m.getTaskHardForkName(ctx, proverTask.TaskID, proofMsg.Type)
+		if err != nil {
+			return err
+		}
+	}
+	if forkNameConfigured && hardForkName != verifierForkName {
+		log.Warn("task hard fork mismatch", "hash", proverTask.TaskID, "taskHardFork", hardForkName, "verifierHardFork", verifierForkName)
+		return ErrValidatorFailureHardForkMismatch
+	}
+
+	// the mock verifier and old provers carry no vk, nothing to compare in that case
+	expectedVK := m.verifier.VK(proofMsg.Type, hardForkName)
+	if expectedVK == "" || len(proofVK) == 0 {
+		return nil
+	}
+	if base64.StdEncoding.EncodeToString(proofVK) != expectedVK {
+		return ErrValidatorFailureVerifierKeyMismatch
+	}
+	return nil
+}
+
+// getTaskHardForkName returns the hard fork of a chunk/batch task, which is the fork of its first block.
+func (m *ProofReceiverLogic) getTaskHardForkName(ctx context.Context, hash string, proofType message.ProofType) (string, error) {
+	startBlockNumber, _, err := m.getTaskBlockRange(ctx, hash, proofType)
+	if err != nil {
+		return "", err
+	}
+	return forks.ForkNameByBlockHeight(startBlockNumber, m.nameForkMap), nil
+}
+
+// getTaskBlockRange returns the first and last l2 blocks of a chunk/batch task.
+func (m *ProofReceiverLogic) getTaskBlockRange(ctx context.Context, hash string, proofType message.ProofType) (uint64, uint64, error) {
+	switch proofType {
+	case message.ProofTypeChunk:
+		chunk, err := m.chunkOrm.GetChunkByHash(ctx, hash)
+		if err != nil {
+			return 0, 0, err
+		}
+		return chunk.StartBlockNumber, chunk.EndBlockNumber, nil
+	case message.ProofTypeBatch:
+		chunks, err := m.chunkOrm.GetChunksByBatchHash(ctx, hash)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(chunks) == 0 {
+			return 0, 0, fmt.Errorf("no chunks found for batch hash: %v", hash)
+		}
+		return chunks[0].StartBlockNumber, chunks[len(chunks)-1].EndBlockNumber, nil
+	}
+	return 0, 0, nil
+}
+
+// observeProvingTime records the proving time of a verified proof with the hard fork and size class of its task, the
+// time since the assignment stands in for the proving time if the prover did not report it.
+func (m *ProofReceiverLogic) observeProvingTime(ctx context.Context, proverTask *orm.ProverTask, proofType message.ProofType, provingTimeMs uint64) {
+	provingTime := time.Since(proverTask.CreatedAt)
+	if provingTimeMs > 0 {
+		provingTime = time.Duration(provingTimeMs) * time.Millisecond
+	}
+
+	var startBlockNumber uint64
+	var sizeClass string
+	switch proofType {
+	case message.ProofTypeChunk:
+		chunk, err := m.chunkOrm.GetChunkByHash(ctx, proverTask.TaskID)
+		if err != nil {
+			log.Warn("failed to get the chunk of a proving time", "hash", proverTask.TaskID, "error", err)
+			return
+		}
+		startBlockNumber, sizeClass = chunk.StartBlockNumber, orm.ChunkSizeClass(chunk.TotalL2TxGas)
+	case message.ProofTypeBatch:
+		chunks, err := m.chunkOrm.GetChunksByBatchHash(ctx, proverTask.TaskID)
+		if err != nil || len(chunks) == 0 {
+			log.Warn("failed to get the chunks of a proving time", "hash", proverTask.TaskID, "error", err)
+			return
+		}
+		startBlockNumber, sizeClass = chunks[0].StartBlockNumber, orm.BatchSizeClass(uint64(len(chunks)))
+	default:
+		return
+	}
+	hardForkName := forks.ForkNameByBlockHeight(startBlockNumber, m.nameForkMap)
+	m.provingTimeSeconds.WithLabelValues(proofType.String(), hardForkName, sizeClass).Observe(provingTime.Seconds())
+}
+
+// recordProofFailure classifies the failure of a prover task and stores it with the block range of the task, so the
+// traces the prover failed on can be fetched again to debug the circuits. Failures to record are only logged.
+func (m *ProofReceiverLogic) recordProofFailure(ctx context.Context, proverTask *orm.ProverTask, failureType types.ProverTaskFailureType, err error, failureMsg string) {
+	proofType := message.ProofType(proverTask.TaskType)
+	failureClass := classifyProofFailure(err, failureMsg)
+	m.proofFailureTotal.WithLabelValues(proofType.String(), failureClass.String()).Inc()
+
+	proofFailure := &orm.ProofFailure{
+		TaskID:          proverTask.TaskID,
+		TaskType:        proverTask.TaskType,
+		ProverTaskUUID:  proverTask.UUID.String(),
+		ProverName:      proverTask.ProverName,
+		ProverPublicKey: proverTask.ProverPublicKey,
+		ProverVersion:   proverTask.ProverVersion,
+		FailureType:     int16(failureType),
+		FailureClass:    int16(failureClass),
+		FailureMsg:      truncateFailureMsg(failureMsg),
+	}
+	startBlockNumber, endBlockNumber, rangeErr := m.getTaskBlockRange(ctx, proverTask.TaskID, proofType)
+	if rangeErr != nil {
+		log.Warn("failed to get block range of the failed task", "hash", proverTask.TaskID, "taskType", proofType, "error", rangeErr)
+	} else {
+		proofFailure.StartBlockNumber = startBlockNumber
+		proofFailure.EndBlockNumber = endBlockNumber
+		proofFailure.HardForkName = forks.ForkNameByBlockHeight(startBlockNumber, m.nameForkMap)
+	}
+
+	if insertErr := m.proofFailureOrm.InsertProofFailure(ctx, proofFailure); insertErr != nil {
+		log.Error("failed to record proof failure", "hash", proverTask.TaskID, "uuid", proverTask.UUID, "failureClass", failureClass, "error", insertErr)
+	}
+}
+
 func (m *ProofReceiverLogic) proofRecover(ctx context.Context, proverTask *orm.ProverTask, failureType types.ProverTaskFailureType, proofMsg *message.ProofMsg) {
 	log.Info("proof recover update proof status", "hash", proverTask.TaskID, "proverPublicKey", proverTask.ProverPublicKey,
 		"taskType", message.ProofType(proverTask.TaskType).String(), "status", types.ProvingTaskUnassigned.String())
@@ -307,48 +733,38 @@ func (m *ProofReceiverLogic) closeProofTask(ctx context.Context, proverTask *orm
 	return nil
 }
 
-// UpdateProofStatus update the chunk/batch task and session info status
-func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *orm.ProverTask,
-	proofMsg *message.ProofMsg, status types.ProverProveStatus, failureType types.ProverTaskFailureType, proofTimeSec uint64) error {
-	err := m.db.Transaction(func(tx *gorm.DB) error {
-		if updateErr := m.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(ctx, proverTask.UUID, status, failureType, tx); updateErr != nil {
-			log.Error("failed to update prover task proving status and failure type", "uuid", proverTask.UUID, "error", updateErr)
-			return updateErr
-		}
-
-		switch proofMsg.Type {
-		case message.ProofTypeChunk:
-			if err := m.chunkOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
-				log.Error("failed to update chunk proving_status as failed", "hash", proverTask.TaskID, "error", err)
-				return err
-			}
-		case message.ProofTypeBatch:
-			if err := m.batchOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
-				log.Error("failed to update batch proving_status as failed", "hash", proverTask.TaskID, "error", err)
+// closeProofTasks closes the prover tasks of verified proofs and their chunk or batch tasks in a single transaction.
+func (m *ProofReceiverLogic) closeProofTasks(ctx context.Context, submissions []*proofSubmission) error {
+	err := database.TransactionWithRetry(ctx, m.db, func(tx *gorm.DB) error {
+		for _, submission := range submissions {
+			if err := m.updateProofStatusInTx(ctx, tx, submission.proverTask, submission.proofMsg, types.ProverProofValid, types.ProverTaskFailureTypeUndefined, submission.proofTimeSec); err != nil {
 				return err
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to update proof status ProvingTaskVerified of the submitted proofs", "count", len(submissions), "error", err)
+		return err
+	}
 
-		// if the block batch has proof verified, so the failed status not update block batch proving status
-		if m.checkIsTaskSuccess(ctx, proverTask.TaskID, proofMsg.Type) {
-			log.Info("update proof status skip because this chunk/batch has been verified", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey)
-			return nil
+	for _, submission := range submissions {
+		if submission.proofMsg.Type != message.ProofTypeChunk {
+			continue
 		}
-
-		if status == types.ProverProofValid {
-			var storeProofErr error
-			switch proofMsg.Type {
-			case message.ProofTypeChunk:
-				storeProofErr = m.chunkOrm.UpdateProofAndProvingStatusByHash(ctx, proofMsg.ID, proofMsg.ChunkProof, types.ProvingTaskVerified, proofTimeSec, tx)
-			case message.ProofTypeBatch:
-				storeProofErr = m.batchOrm.UpdateProofAndProvingStatusByHash(ctx, proofMsg.ID, proofMsg.BatchProof, types.ProvingTaskVerified, proofTimeSec, tx)
-			}
-			if storeProofErr != nil {
-				log.Error("failed to store chunk/batch proof and proving status", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey, "error", storeProofErr)
-				return storeProofErr
-			}
+		if checkReadyErr := m.checkAreAllChunkProofsReady(ctx, submission.proverTask.TaskID); checkReadyErr != nil {
+			log.Error("failed to check are all chunk proofs ready", "error", checkReadyErr)
+			return checkReadyErr
 		}
-		return nil
+	}
+	return nil
+}
+
+// UpdateProofStatus update the chunk/batch task and session info status
+func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *orm.ProverTask,
+	proofMsg *message.ProofMsg, status types.ProverProveStatus, failureType types.ProverTaskFailureType, proofTimeSec uint64) error {
+	err := database.TransactionWithRetry(ctx, m.db, func(tx *gorm.DB) error {
+		return m.updateProofStatusInTx(ctx, tx, proverTask, proofMsg, status, failureType, proofTimeSec)
 	})
 
 	if err != nil {
@@ -365,6 +781,48 @@ func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *
 	return nil
 }
 
+func (m *ProofReceiverLogic) updateProofStatusInTx(ctx context.Context, tx *gorm.DB, proverTask *orm.ProverTask,
+	proofMsg *message.ProofMsg, status types.ProverProveStatus, failureType types.ProverTaskFailureType, proofTimeSec uint64) error {
+	if updateErr := m.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(ctx, proverTask.UUID, status, failureType, tx); updateErr != nil {
+		log.Error("failed to update prover task proving status and failure type", "uuid", proverTask.UUID, "error", updateErr)
+		return updateErr
+	}
+
+	switch proofMsg.Type {
+	case message.ProofTypeChunk:
+		if err := m.chunkOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
+			log.Error("failed to update chunk proving_status as failed", "hash", proverTask.TaskID, "error", err)
+			return err
+		}
+	case message.ProofTypeBatch:
+		if err := m.batchOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
+			log.Error("failed to update batch proving_status as failed", "hash", proverTask.TaskID, "error", err)
+			return err
+		}
+	}
+
+	// if the block batch has proof verified, so the failed status not update block batch proving status
+	if m.checkIsTaskSuccess(ctx, proverTask.TaskID, proofMsg.Type) {
+		log.Info("update proof status skip because this chunk/batch has been verified", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey)
+		return nil
+	}
+
+	if status == types.ProverProofValid {
+		var storeProofErr error
+		switch proofMsg.Type {
+		case message.ProofTypeChunk:
+			storeProofErr = m.chunkOrm.UpdateProofAndProvingStatusByHash(ctx, proofMsg.ID, proofMsg.ChunkProof, types.ProvingTaskVerified, proofTimeSec, tx)
+		case message.ProofTypeBatch:
+			storeProofErr = m.batchOrm.UpdateProofAndProvingStatusByHash(ctx, proofMsg.ID, proofMsg.BatchProof, types.ProvingTaskVerified, proofTimeSec, tx)
+		}
+		if storeProofErr != nil {
+			log.Error("failed to store chunk/batch proof and proving status", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey, "error", storeProofErr)
+			return storeProofErr
+		}
+	}
+	return nil
+}
+
 func (m *ProofReceiverLogic) checkIsTaskSuccess(ctx context.Context, hash string, proofType message.ProofType) bool {
 	var provingStatus types.ProvingStatus
 	var err error
@@ -387,6 +845,16 @@ func (m *ProofReceiverLogic) checkIsTaskSuccess(ctx context.Context, hash string
 
 func (m *ProofReceiverLogic) updateProverTaskProof(ctx context.Context, proverTask *orm.ProverTask, proofMsg *message.ProofMsg) error {
 	// store the proof to prover task
+	proofBytes, marshalErr := marshalProof(proofMsg)
+	if marshalErr != nil {
+		return fmt.Errorf("updateProverTaskProof marshal proof error:%w", marshalErr)
+	}
+	return m.proverTaskOrm.UpdateProverTaskProof(ctx, proverTask.UUID, proofBytes, proofChecksum(proofBytes))
+}
+
+// marshalProof returns the json encoding of the proof of proofMsg, the proof is re-encoded from its decoded fields,
+// so that the encodings of the same proof are equal whatever the formatting of the submissions.
+func marshalProof(proofMsg *message.ProofMsg) ([]byte, error) {
 	var proofBytes []byte
 	var marshalErr error
 	switch proofMsg.Type {
@@ -395,9 +863,17 @@ func (m *ProofReceiverLogic) updateProverTaskProof(ctx context.Context, proverTa
 	case message.ProofTypeBatch:
 		proofBytes, marshalErr = json.Marshal(proofMsg.BatchProof)
 	}
-
-	if len(proofBytes) == 0 || marshalErr != nil {
-		return fmt.Errorf("updateProverTaskProof marshal proof error:%w", marshalErr)
+	if marshalErr != nil {
+		return nil, marshalErr
 	}
-	return m.proverTaskOrm.UpdateProverTaskProof(ctx, proverTask.UUID, proofBytes)
+	if len(proofBytes) == 0 {
+		return nil, fmt.Errorf("empty proof of type %v", proofMsg.Type)
+	}
+	return proofBytes, nil
+}
+
+// proofChecksum returns the hex encoded sha256 of the encoded proof.
+func proofChecksum(proofBytes []byte) string {
+	sum := sha256.Sum256(proofBytes)
+	return hex.EncodeToString(sum[:])
 }
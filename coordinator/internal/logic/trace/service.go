@@ -0,0 +1,291 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+const (
+	// defaultCacheSize is the default number of block traces kept in memory, block traces can take several MBs each.
+	defaultCacheSize = 64
+	// defaultFetchConcurrency is the default number of concurrent block trace requests sent to l2geth.
+	defaultFetchConcurrency = 8
+	// defaultDiskMaxEntries is the default number of block traces kept in the disk cache.
+	defaultDiskMaxEntries = 1024
+	// defaultDiskPruneInterval is the default interval of removing the oldest block traces from the disk cache.
+	defaultDiskPruneInterval = time.Minute
+	// fetchTimeout bounds a block trace request, which outlives the get_task request that started it.
+	fetchTimeout = 2 * time.Minute
+)
+
+// Client is the subset of the l2geth client used to fetch block traces.
+type Client interface {
+	GetBlockTraceByHash(ctx context.Context, blockHash common.Hash) (*types.BlockTrace, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Service fetches the block traces of chunk tasks from l2geth, so that provers receive them with the task.
+// Traces are fetched in parallel and kept in an in-memory LRU cache, and optionally in a disk cache,
+// so retried tasks and tasks whose blocks were prefetched do not wait for the trace RPC calls.
+type Service struct {
+	ctx    context.Context
+	client Client
+
+	cache          *lru.Cache
+	diskDir        string
+	diskMaxEntries int
+	pruneInterval  time.Duration
+	concurrency    int
+	prefetchBlocks uint64
+
+	singleFlight singleflight.Group
+	prefetching  int32
+}
+
+// NewService creates a new trace Service, its background jobs stop when ctx is done.
+func NewService(ctx context.Context, cfg *config.TraceCacheConfig, client Client) (*Service, error) {
+	if cfg == nil {
+		cfg = &config.TraceCacheConfig{}
+	}
+	cacheSize := defaultCacheSize
+	if cfg.Size > 0 {
+		cacheSize = cfg.Size
+	}
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace cache: %w", err)
+	}
+
+	s := &Service{
+		ctx:            ctx,
+		client:         client,
+		cache:          cache,
+		diskDir:        cfg.DiskDir,
+		diskMaxEntries: defaultDiskMaxEntries,
+		pruneInterval:  defaultDiskPruneInterval,
+		concurrency:    defaultFetchConcurrency,
+		prefetchBlocks: cfg.PrefetchBlocks,
+	}
+	if cfg.DiskMaxEntries > 0 {
+		s.diskMaxEntries = cfg.DiskMaxEntries
+	}
+	if cfg.DiskPruneIntervalSec > 0 {
+		s.pruneInterval = time.Duration(cfg.DiskPruneIntervalSec) * time.Second
+	}
+	if cfg.FetchConcurrency > 0 {
+		s.concurrency = cfg.FetchConcurrency
+	}
+	if s.diskDir != "" {
+		if err = os.MkdirAll(s.diskDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create trace cache dir: %w", err)
+		}
+		go s.pruneLoop()
+	}
+	return s, nil
+}
+
+// GetSortedTracesByHashes returns the block traces of the given blocks sorted by block number,
+// and checks that the block numbers are continuous.
+func (s *Service) GetSortedTracesByHashes(ctx context.Context, blockHashes []common.Hash) ([]*types.BlockTrace, error) {
+	if len(blockHashes) == 0 {
+		return nil, fmt.Errorf("blockHashes is empty")
+	}
+
+	traces := make([]*types.BlockTrace, len(blockHashes))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.concurrency)
+	for i, blockHash := range blockHashes {
+		i, blockHash := i, blockHash
+		g.Go(func() error {
+			trace, err := s.getTrace(ctx, blockHash)
+			if err != nil {
+				return err
+			}
+			traces[i] = trace
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Sort BlockTraces by header number.
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Header.Number.Int64() < traces[j].Header.Number.Int64()
+	})
+
+	// Check that the block numbers are continuous
+	for i := 0; i < len(traces)-1; i++ {
+		if traces[i].Header.Number.Int64()+1 != traces[i+1].Header.Number.Int64() {
+			return nil, fmt.Errorf("block numbers are not continuous, got %v and %v",
+				traces[i].Header.Number.Int64(), traces[i+1].Header.Number.Int64())
+		}
+	}
+
+	s.prefetchAfter(traces[len(traces)-1].Header.Number.Uint64())
+	return traces, nil
+}
+
+// prefetchAfter fetches the traces of the blocks following a chunk in the background,
+// as the next chunk task usually starts right after the current one.
+func (s *Service) prefetchAfter(blockNumber uint64) {
+	if s.prefetchBlocks == 0 || !atomic.CompareAndSwapInt32(&s.prefetching, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&s.prefetching, 0)
+
+		g, ctx := errgroup.WithContext(s.ctx)
+		g.SetLimit(s.concurrency)
+		for number := blockNumber + 1; number <= blockNumber+s.prefetchBlocks; number++ {
+			number := number
+			g.Go(func() error {
+				header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+				if err != nil {
+					return err
+				}
+				_, err = s.getTrace(ctx, header.Hash())
+				return err
+			})
+		}
+		// Blocks beyond the chain head are expected to be missing, prefetching is best effort.
+		if err := g.Wait(); err != nil {
+			log.Debug("failed to prefetch block traces", "from", blockNumber+1, "count", s.prefetchBlocks, "err", err)
+		}
+	}()
+}
+
+// getTrace returns the trace of a block, from the caches if present. Concurrent requests of a block share a single
+// fetch, which is not bound to the context of the first caller: a caller giving up, e.g. a prover disconnecting,
+// neither fails the other callers nor discards the fetched trace.
+func (s *Service) getTrace(ctx context.Context, blockHash common.Hash) (*types.BlockTrace, error) {
+	if trace, ok := s.cache.Get(blockHash); ok {
+		return trace.(*types.BlockTrace), nil
+	}
+
+	resultCh := s.singleFlight.DoChan(blockHash.Hex(), func() (interface{}, error) {
+		if trace := s.readDisk(blockHash); trace != nil {
+			s.cache.Add(blockHash, trace)
+			return trace, nil
+		}
+		fetchCtx, cancel := context.WithTimeout(s.ctx, fetchTimeout)
+		defer cancel()
+		trace, err := s.client.GetBlockTraceByHash(fetchCtx, blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block trace, hash: %v, err: %w", blockHash.Hex(), err)
+		}
+		if trace == nil || trace.Header == nil {
+			return nil, fmt.Errorf("failed to get block trace, hash: %v, err: %w", blockHash.Hex(), ethereum.NotFound)
+		}
+		s.cache.Add(blockHash, trace)
+		s.writeDisk(blockHash, trace)
+		return trace, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*types.BlockTrace), nil
+	}
+}
+
+func (s *Service) readDisk(blockHash common.Hash) *types.BlockTrace {
+	if s.diskDir == "" {
+		return nil
+	}
+	buf, err := os.ReadFile(s.diskPath(blockHash))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("failed to read cached block trace", "hash", blockHash.Hex(), "err", err)
+		}
+		return nil
+	}
+	trace := &types.BlockTrace{}
+	if err = json.Unmarshal(buf, trace); err != nil || trace.Header == nil {
+		log.Warn("failed to decode cached block trace, removing it", "hash", blockHash.Hex(), "err", err)
+		_ = os.Remove(s.diskPath(blockHash))
+		return nil
+	}
+	return trace
+}
+
+// writeDisk stores a block trace in the disk cache, a failed write only costs a refetch later.
+func (s *Service) writeDisk(blockHash common.Hash, trace *types.BlockTrace) {
+	if s.diskDir == "" {
+		return
+	}
+	buf, err := json.Marshal(trace)
+	if err != nil {
+		log.Warn("failed to encode block trace", "hash", blockHash.Hex(), "err", err)
+		return
+	}
+	// Write to a temporary file first so that a crash never leaves a truncated trace behind.
+	tmpPath := s.diskPath(blockHash) + ".tmp"
+	if err = os.WriteFile(tmpPath, buf, 0644); err != nil {
+		log.Warn("failed to write block trace cache", "hash", blockHash.Hex(), "err", err)
+		return
+	}
+	if err = os.Rename(tmpPath, s.diskPath(blockHash)); err != nil {
+		log.Warn("failed to write block trace cache", "hash", blockHash.Hex(), "err", err)
+	}
+}
+
+// pruneLoop prunes the disk cache periodically, rather than on every write, as listing the cache dir is not cheap.
+func (s *Service) pruneLoop() {
+	ticker := time.NewTicker(s.pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.pruneDisk()
+		}
+	}
+}
+
+// pruneDisk removes the oldest block traces once the disk cache holds more than diskMaxEntries of them.
+func (s *Service) pruneDisk() {
+	entries, err := filepath.Glob(filepath.Join(s.diskDir, "*.json"))
+	if err != nil || len(entries) <= s.diskMaxEntries {
+		return
+	}
+	modTimes := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		if info, statErr := os.Stat(entry); statErr == nil {
+			modTimes[entry] = info.ModTime().UnixNano()
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return modTimes[entries[i]] < modTimes[entries[j]]
+	})
+	for _, entry := range entries[:len(entries)-s.diskMaxEntries] {
+		if err = os.Remove(entry); err != nil && !os.IsNotExist(err) {
+			log.Warn("failed to remove cached block trace", "file", entry, "err", err)
+		}
+	}
+}
+
+func (s *Service) diskPath(blockHash common.Hash) string {
+	return filepath.Join(s.diskDir, blockHash.Hex()+".json")
+}
@@ -0,0 +1,176 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+type mockClient struct {
+	mu      sync.Mutex
+	headers map[common.Hash]*types.Header
+	calls   map[common.Hash]int
+}
+
+func newMockClient(from, to int64) *mockClient {
+	c := &mockClient{headers: make(map[common.Hash]*types.Header), calls: make(map[common.Hash]int)}
+	for number := from; number <= to; number++ {
+		header := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(0)}
+		c.headers[header.Hash()] = header
+	}
+	return c
+}
+
+func (c *mockClient) GetBlockTraceByHash(_ context.Context, blockHash common.Hash) (*types.BlockTrace, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[blockHash]++
+	header, ok := c.headers[blockHash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &types.BlockTrace{Header: header}, nil
+}
+
+func (c *mockClient) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, header := range c.headers {
+		if header.Number.Cmp(number) == 0 {
+			return header, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (c *mockClient) hashes(numbers ...int64) []common.Hash {
+	var hashes []common.Hash
+	for _, number := range numbers {
+		for hash, header := range c.headers {
+			if header.Number.Int64() == number {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	return hashes
+}
+
+func (c *mockClient) callCount(blockHash common.Hash) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[blockHash]
+}
+
+func TestGetSortedTracesByHashes(t *testing.T) {
+	client := newMockClient(1, 10)
+	s, err := NewService(context.Background(), &config.TraceCacheConfig{}, client)
+	assert.NoError(t, err)
+
+	_, err = s.GetSortedTracesByHashes(context.Background(), nil)
+	assert.Error(t, err)
+
+	traces, err := s.GetSortedTracesByHashes(context.Background(), client.hashes(3, 1, 2))
+	assert.NoError(t, err)
+	assert.Len(t, traces, 3)
+	for i, trace := range traces {
+		assert.Equal(t, int64(i+1), trace.Header.Number.Int64())
+	}
+
+	// cached traces are not fetched again
+	_, err = s.GetSortedTracesByHashes(context.Background(), client.hashes(1, 2, 3))
+	assert.NoError(t, err)
+	for _, hash := range client.hashes(1, 2, 3) {
+		assert.Equal(t, 1, client.callCount(hash))
+	}
+
+	_, err = s.GetSortedTracesByHashes(context.Background(), client.hashes(1, 3))
+	assert.ErrorContains(t, err, "block numbers are not continuous")
+
+	_, err = s.GetSortedTracesByHashes(context.Background(), []common.Hash{{0x1}})
+	assert.ErrorContains(t, err, "failed to get block trace")
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	client := newMockClient(1, 5)
+	cfg := &config.TraceCacheConfig{Size: 1, DiskDir: dir, DiskMaxEntries: 3}
+
+	s, err := NewService(context.Background(), cfg, client)
+	assert.NoError(t, err)
+	_, err = s.GetSortedTracesByHashes(context.Background(), client.hashes(1, 2, 3))
+	assert.NoError(t, err)
+
+	// a new service, e.g. after a restart, reads the traces from disk
+	s, err = NewService(context.Background(), cfg, client)
+	assert.NoError(t, err)
+	traces, err := s.GetSortedTracesByHashes(context.Background(), client.hashes(1, 2, 3))
+	assert.NoError(t, err)
+	assert.Len(t, traces, 3)
+	for _, hash := range client.hashes(1, 2, 3) {
+		assert.Equal(t, 1, client.callCount(hash))
+	}
+
+	// the disk cache is pruned periodically rather than on writes
+	_, err = s.GetSortedTracesByHashes(context.Background(), client.hashes(4, 5))
+	assert.NoError(t, err)
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+
+	s.pruneDisk()
+	entries, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+type blockingClient struct {
+	*mockClient
+	release chan struct{}
+}
+
+func (c *blockingClient) GetBlockTraceByHash(ctx context.Context, blockHash common.Hash) (*types.BlockTrace, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.release:
+	}
+	return c.mockClient.GetBlockTraceByHash(ctx, blockHash)
+}
+
+func TestSharedFetchOutlivesCaller(t *testing.T) {
+	client := &blockingClient{mockClient: newMockClient(1, 1), release: make(chan struct{})}
+	s, err := NewService(context.Background(), &config.TraceCacheConfig{}, client)
+	assert.NoError(t, err)
+	hashes := client.hashes(1)
+
+	// the first caller gives up while the trace is being fetched
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, getErr := s.GetSortedTracesByHashes(ctx, hashes)
+		firstErr <- getErr
+	}()
+	secondResult := make(chan []*types.BlockTrace)
+	go func() {
+		traces, getErr := s.GetSortedTracesByHashes(context.Background(), hashes)
+		assert.NoError(t, getErr)
+		secondResult <- traces
+	}()
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// the other caller still gets the trace, fetched once
+	close(client.release)
+	traces := <-secondResult
+	assert.Len(t, traces, 1)
+	assert.Equal(t, 1, client.callCount(hashes[0]))
+}
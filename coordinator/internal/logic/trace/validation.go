@@ -0,0 +1,79 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// InvalidChunkError is returned by ValidateChunk if the block traces of a chunk can not be proven, e.g. a block is
+// unknown to l2geth or the state roots of its blocks do not chain. Retrying does not help, unlike other errors.
+type InvalidChunkError struct {
+	Reason string
+}
+
+func (e *InvalidChunkError) Error() string {
+	return "invalid chunk: " + e.Reason
+}
+
+// ValidateChunk checks that the block traces of a chunk are retrievable from l2geth and consistent with each other and
+// with the state roots of the chunk, before the chunk is dispatched to a prover. The parent state root is not checked
+// if it is empty, i.e. for the first chunk. The fetched traces are cached for the task.
+func (s *Service) ValidateChunk(ctx context.Context, blockHashes []common.Hash, parentStateRoot, stateRoot common.Hash) error {
+	if len(blockHashes) == 0 {
+		return &InvalidChunkError{Reason: "no blocks"}
+	}
+	traces := make([]*types.BlockTrace, 0, len(blockHashes))
+	for _, blockHash := range blockHashes {
+		trace, err := s.getTrace(ctx, blockHash)
+		if errors.Is(err, ethereum.NotFound) {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block trace %v is not found", blockHash.Hex())}
+		}
+		if err != nil {
+			return err
+		}
+		traces = append(traces, trace)
+	}
+	return validateTraces(blockHashes, traces, parentStateRoot, stateRoot)
+}
+
+// validateTraces checks the block traces of a chunk, ordered as its blocks.
+func validateTraces(blockHashes []common.Hash, traces []*types.BlockTrace, parentStateRoot, stateRoot common.Hash) error {
+	for i, trace := range traces {
+		if trace.StorageTrace == nil {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block trace %v has no storage trace", blockHashes[i].Hex())}
+		}
+		if hash := trace.Header.Hash(); hash != blockHashes[i] {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block trace of %v has hash %v", blockHashes[i].Hex(), hash.Hex())}
+		}
+		if trace.StorageTrace.RootAfter != trace.Header.Root {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block %v has state root %v, its trace ends at %v",
+				trace.Header.Number, trace.Header.Root.Hex(), trace.StorageTrace.RootAfter.Hex())}
+		}
+		if i == 0 {
+			continue
+		}
+		parent := traces[i-1]
+		if trace.Header.Number.Uint64() != parent.Header.Number.Uint64()+1 || trace.Header.ParentHash != parent.Header.Hash() {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block %v does not follow block %v", trace.Header.Number, parent.Header.Number)}
+		}
+		if trace.StorageTrace.RootBefore != parent.StorageTrace.RootAfter {
+			return &InvalidChunkError{Reason: fmt.Sprintf("block %v starts at state root %v, block %v ends at %v",
+				trace.Header.Number, trace.StorageTrace.RootBefore.Hex(), parent.Header.Number, parent.StorageTrace.RootAfter.Hex())}
+		}
+	}
+
+	first, last := traces[0], traces[len(traces)-1]
+	if parentStateRoot != (common.Hash{}) && first.StorageTrace.RootBefore != parentStateRoot {
+		return &InvalidChunkError{Reason: fmt.Sprintf("chunk starts at state root %v, its parent chunk ends at %v",
+			first.StorageTrace.RootBefore.Hex(), parentStateRoot.Hex())}
+	}
+	if last.Header.Root != stateRoot {
+		return &InvalidChunkError{Reason: fmt.Sprintf("chunk has state root %v, its last block %v", stateRoot.Hex(), last.Header.Root.Hex())}
+	}
+	return nil
+}
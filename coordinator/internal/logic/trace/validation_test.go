@@ -0,0 +1,100 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+type traceClient struct {
+	traces map[common.Hash]*types.BlockTrace
+}
+
+func (c *traceClient) GetBlockTraceByHash(_ context.Context, blockHash common.Hash) (*types.BlockTrace, error) {
+	trace, ok := c.traces[blockHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return trace, nil
+}
+
+func (c *traceClient) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return nil, ethereum.NotFound
+}
+
+// chainedTraces returns the traces of a chain of blocks whose state roots are 0x1, 0x2, ... after parentStateRoot 0x0.
+func chainedTraces(count int) ([]common.Hash, []*types.BlockTrace) {
+	var hashes []common.Hash
+	var traces []*types.BlockTrace
+	parentHash := common.Hash{}
+	for i := 0; i < count; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), Difficulty: big.NewInt(0), ParentHash: parentHash, Root: common.BigToHash(big.NewInt(int64(i + 1)))}
+		storageTrace := &types.StorageTrace{RootBefore: common.BigToHash(big.NewInt(int64(i))), RootAfter: header.Root}
+		hashes = append(hashes, header.Hash())
+		traces = append(traces, &types.BlockTrace{Header: header, StorageTrace: storageTrace})
+		parentHash = header.Hash()
+	}
+	return hashes, traces
+}
+
+func TestValidateTraces(t *testing.T) {
+	parentStateRoot, stateRoot := common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(3))
+	hashes, traces := chainedTraces(3)
+	assert.NoError(t, validateTraces(hashes, traces, parentStateRoot, stateRoot))
+	// the parent state root of the first chunk is unknown.
+	assert.NoError(t, validateTraces(hashes, traces, common.Hash{}, stateRoot))
+
+	var invalidErr *InvalidChunkError
+	err := validateTraces(hashes, traces, common.BigToHash(big.NewInt(9)), stateRoot)
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Contains(t, invalidErr.Reason, "its parent chunk ends at")
+
+	err = validateTraces(hashes, traces, parentStateRoot, common.BigToHash(big.NewInt(9)))
+	assert.True(t, errors.As(err, &invalidErr))
+
+	err = validateTraces([]common.Hash{hashes[0], hashes[2]}, []*types.BlockTrace{traces[0], traces[2]}, parentStateRoot, stateRoot)
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Contains(t, invalidErr.Reason, "does not follow")
+
+	_, traces = chainedTraces(3)
+	traces[1].StorageTrace.RootBefore = common.BigToHash(big.NewInt(9))
+	err = validateTraces(hashes, traces, parentStateRoot, stateRoot)
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Contains(t, invalidErr.Reason, "starts at state root")
+
+	_, traces = chainedTraces(3)
+	traces[2].StorageTrace.RootAfter = common.BigToHash(big.NewInt(9))
+	err = validateTraces(hashes, traces, parentStateRoot, stateRoot)
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Contains(t, invalidErr.Reason, "its trace ends at")
+
+	_, traces = chainedTraces(3)
+	traces[0].StorageTrace = nil
+	err = validateTraces(hashes, traces, parentStateRoot, stateRoot)
+	assert.True(t, errors.As(err, &invalidErr))
+}
+
+func TestValidateChunk(t *testing.T) {
+	hashes, traces := chainedTraces(3)
+	client := &traceClient{traces: make(map[common.Hash]*types.BlockTrace)}
+	for i, hash := range hashes {
+		client.traces[hash] = traces[i]
+	}
+	s, err := NewService(context.Background(), &config.TraceCacheConfig{}, client)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.ValidateChunk(context.Background(), hashes, common.BigToHash(big.NewInt(0)), common.BigToHash(big.NewInt(3))))
+
+	var invalidErr *InvalidChunkError
+	err = s.ValidateChunk(context.Background(), append(hashes, common.Hash{0x1}), common.Hash{}, common.BigToHash(big.NewInt(3)))
+	assert.True(t, errors.As(err, &invalidErr))
+	assert.Contains(t, invalidErr.Reason, "is not found")
+}
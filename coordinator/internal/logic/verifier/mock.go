@@ -9,8 +9,12 @@ import (
 )
 
 // NewVerifier Sets up a mock verifier.
-func NewVerifier(_ *config.VerifierConfig) (*Verifier, error) {
-	return &Verifier{}, nil
+func NewVerifier(cfg *config.VerifierConfig) (*Verifier, error) {
+	registry, err := newVKRegistry(cfg.VKRegistryDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{registry: registry}, nil
 }
 
 // VerifyChunkProof return a mock verification result for a ChunkProof.
@@ -12,4 +12,6 @@ type Verifier struct {
 	cfg     *config.VerifierConfig
 	BatchVK string
 	ChunkVK string
+
+	registry *VKRegistry // nil if the keys of the assets serve all the hard forks
 }
@@ -27,8 +27,12 @@ import (
 
 // NewVerifier Sets up a rust ffi to call verify.
 func NewVerifier(cfg *config.VerifierConfig) (*Verifier, error) {
+	registry, err := newVKRegistry(cfg.VKRegistryDir)
+	if err != nil {
+		return nil, err
+	}
 	if cfg.MockMode {
-		return &Verifier{cfg: cfg}, nil
+		return &Verifier{cfg: cfg, registry: registry}, nil
 	}
 	paramsPathStr := C.CString(cfg.ParamsPath)
 	assetsPathStr := C.CString(cfg.AssetsPath)
@@ -54,6 +58,8 @@ func NewVerifier(cfg *config.VerifierConfig) (*Verifier, error) {
 		cfg:     cfg,
 		BatchVK: batchVK,
 		ChunkVK: chunkVK,
+
+		registry: registry,
 	}, nil
 }
 
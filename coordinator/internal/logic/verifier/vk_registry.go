@@ -0,0 +1,240 @@
+package verifier
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types/message"
+)
+
+const (
+	chunkVKFile    = "chunk_vk.vkey"
+	batchVKFile    = "agg_vk.vkey"
+	vkChecksumFile = "sha256sums"
+)
+
+// ErrNoVKRegistry is returned when reloading the verifying keys of a verifier without a registry dir.
+var ErrNoVKRegistry = errors.New("no verifying key registry configured")
+
+// ForkVKs are the base64 encoded verifying keys of the chunk and batch circuits of a hard fork.
+type ForkVKs struct {
+	ChunkVK string
+	BatchVK string
+}
+
+// VKRegistry holds the verifying keys of each hard fork, loaded from a directory with a sub directory per hard fork.
+// Each sub directory holds chunk_vk.vkey, agg_vk.vkey and a sha256sums file of their checksums in the format of
+// sha256sum. The keys of all the forks are swapped in at once on reload, a directory failing validation keeps the
+// previous keys.
+type VKRegistry struct {
+	dir string
+
+	mu  sync.RWMutex
+	vks map[string]*ForkVKs
+}
+
+// NewVKRegistry loads the verifying key registry of the directory.
+func NewVKRegistry(dir string) (*VKRegistry, error) {
+	r := &VKRegistry{dir: dir}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the verifying keys of the directory again, they replace the current ones only if all of them are valid.
+func (r *VKRegistry) Reload() error {
+	vks, err := loadVKs(r.dir)
+	if err != nil {
+		return fmt.Errorf("failed to load verifying keys from %s, err: %w", r.dir, err)
+	}
+
+	r.mu.Lock()
+	r.vks = vks
+	r.mu.Unlock()
+
+	log.Info("verifying keys loaded", "dir", r.dir, "forks", r.Forks())
+	return nil
+}
+
+// Get returns the verifying keys of the hard fork, false if the registry does not have the fork.
+func (r *VKRegistry) Get(forkName string) (*ForkVKs, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	vks, ok := r.vks[forkName]
+	return vks, ok
+}
+
+// Forks returns the sorted names of the hard forks of the registry.
+func (r *VKRegistry) Forks() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	forks := make([]string, 0, len(r.vks))
+	for fork := range r.vks {
+		forks = append(forks, fork)
+	}
+	sort.Strings(forks)
+	return forks
+}
+
+// VK returns the verifying key of the proof type for the hard fork, the key of the registry if it has the fork,
+// otherwise the key of the assets, empty for the mock verifier.
+func (v *Verifier) VK(proofType message.ProofType, forkName string) string {
+	if v.registry != nil {
+		if vks, ok := v.registry.Get(forkName); ok {
+			switch proofType {
+			case message.ProofTypeChunk:
+				return vks.ChunkVK
+			case message.ProofTypeBatch:
+				return vks.BatchVK
+			}
+		}
+	}
+	switch proofType {
+	case message.ProofTypeChunk:
+		return v.ChunkVK
+	case message.ProofTypeBatch:
+		return v.BatchVK
+	}
+	return ""
+}
+
+// ChunkVKOf returns the chunk verifying key of the hard fork.
+func (v *Verifier) ChunkVKOf(forkName string) string {
+	return v.VK(message.ProofTypeChunk, forkName)
+}
+
+// BatchVKOf returns the batch verifying key of the hard fork.
+func (v *Verifier) BatchVKOf(forkName string) string {
+	return v.VK(message.ProofTypeBatch, forkName)
+}
+
+// HasVKRegistry returns whether the verifying keys depend on the hard fork.
+func (v *Verifier) HasVKRegistry() bool {
+	return v.registry != nil
+}
+
+// ReloadVKs reloads the verifying key registry and returns its hard forks.
+func (v *Verifier) ReloadVKs() ([]string, error) {
+	if v.registry == nil {
+		return nil, ErrNoVKRegistry
+	}
+	if err := v.registry.Reload(); err != nil {
+		return nil, err
+	}
+	return v.registry.Forks(), nil
+}
+
+// WithVKRegistry returns a verifier sharing the circuits of v with the verifying key registry of dir, e.g. for a
+// tenant of the coordinator, the keys of the assets are used for all the hard forks if dir is empty.
+func (v *Verifier) WithVKRegistry(dir string) (*Verifier, error) {
+	registry, err := newVKRegistry(dir)
+	if err != nil {
+		return nil, err
+	}
+	vf := *v
+	vf.registry = registry
+	return &vf, nil
+}
+
+// newVKRegistry returns the verifying key registry of the config, nil if it has no registry dir.
+func newVKRegistry(dir string) (*VKRegistry, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	return NewVKRegistry(dir)
+}
+
+func loadVKs(dir string) (map[string]*ForkVKs, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	vks := make(map[string]*ForkVKs)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		forkVKs, err := loadForkVKs(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("hard fork %s: %w", entry.Name(), err)
+		}
+		vks[entry.Name()] = forkVKs
+	}
+	if len(vks) == 0 {
+		return nil, errors.New("no hard fork directory")
+	}
+	return vks, nil
+}
+
+func loadForkVKs(dir string) (*ForkVKs, error) {
+	checksums, err := readChecksums(filepath.Join(dir, vkChecksumFile))
+	if err != nil {
+		return nil, err
+	}
+	chunkVK, err := readCheckedVK(dir, chunkVKFile, checksums)
+	if err != nil {
+		return nil, err
+	}
+	batchVK, err := readCheckedVK(dir, batchVKFile, checksums)
+	if err != nil {
+		return nil, err
+	}
+	return &ForkVKs{ChunkVK: chunkVK, BatchVK: batchVK}, nil
+}
+
+// readChecksums reads a sha256sums file, mapping the file names to their hex encoded checksums.
+func readChecksums(file string) (map[string]string, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed line of %s: %q", vkChecksumFile, line)
+		}
+		// sha256sum marks the files read in binary mode with a leading '*'
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return checksums, nil
+}
+
+// readCheckedVK reads a verifying key file and checks it against its checksum.
+func readCheckedVK(dir, name string, checksums map[string]string) (string, error) {
+	checksum, ok := checksums[name]
+	if !ok {
+		return "", fmt.Errorf("no checksum of %s", name)
+	}
+	byt, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(byt)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return "", fmt.Errorf("checksum mismatch of %s", name)
+	}
+	return base64.StdEncoding.EncodeToString(byt), nil
+}
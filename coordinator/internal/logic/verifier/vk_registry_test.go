@@ -0,0 +1,94 @@
+package verifier
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scroll-tech/common/types/message"
+)
+
+func writeForkVKs(t *testing.T, dir, forkName string, chunkVK, batchVK []byte) {
+	forkDir := filepath.Join(dir, forkName)
+	require.NoError(t, os.MkdirAll(forkDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(forkDir, chunkVKFile), chunkVK, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(forkDir, batchVKFile), batchVK, 0o600))
+	chunkSum, batchSum := sha256.Sum256(chunkVK), sha256.Sum256(batchVK)
+	checksums := fmt.Sprintf("%s  %s\n%s *%s\n", hex.EncodeToString(chunkSum[:]), chunkVKFile, hex.EncodeToString(batchSum[:]), batchVKFile)
+	require.NoError(t, os.WriteFile(filepath.Join(forkDir, vkChecksumFile), []byte(checksums), 0o600))
+}
+
+func TestVKRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeForkVKs(t, dir, "bernoulli", []byte("chunk vk 1"), []byte("batch vk 1"))
+
+	registry, err := NewVKRegistry(dir)
+	require.NoError(t, err)
+	v := &Verifier{ChunkVK: "assets chunk vk", BatchVK: "assets batch vk", registry: registry}
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("chunk vk 1")), v.ChunkVKOf("bernoulli"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("batch vk 1")), v.BatchVKOf("bernoulli"))
+	// forks not in the registry use the keys of the assets.
+	assert.Equal(t, "assets chunk vk", v.VK(message.ProofTypeChunk, "curie"))
+	assert.Equal(t, "assets batch vk", v.VK(message.ProofTypeBatch, ""))
+
+	// a new fork and new keys are rolled out.
+	writeForkVKs(t, dir, "bernoulli", []byte("chunk vk 2"), []byte("batch vk 2"))
+	writeForkVKs(t, dir, "curie", []byte("curie chunk vk"), []byte("curie batch vk"))
+	forks, err := v.ReloadVKs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bernoulli", "curie"}, forks)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("chunk vk 2")), v.ChunkVKOf("bernoulli"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("curie batch vk")), v.BatchVKOf("curie"))
+
+	// a key not matching its checksum fails the reload, the current keys are kept.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "curie", chunkVKFile), []byte("corrupted"), 0o600))
+	_, err = v.ReloadVKs()
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("curie chunk vk")), v.ChunkVKOf("curie"))
+
+	// a key without checksum fails the reload.
+	writeForkVKs(t, dir, "curie", []byte("curie chunk vk"), []byte("curie batch vk"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "curie", vkChecksumFile), []byte("00  "+chunkVKFile+"\n"), 0o600))
+	_, err = v.ReloadVKs()
+	assert.Error(t, err)
+
+	_, err = NewVKRegistry(t.TempDir())
+	assert.Error(t, err)
+
+	_, err = (&Verifier{}).ReloadVKs()
+	assert.ErrorIs(t, err, ErrNoVKRegistry)
+}
+
+func TestWithVKRegistry(t *testing.T) {
+	devnetDir, stagingDir := t.TempDir(), t.TempDir()
+	writeForkVKs(t, devnetDir, "curie", []byte("devnet chunk vk"), []byte("devnet batch vk"))
+	writeForkVKs(t, stagingDir, "curie", []byte("staging chunk vk"), []byte("staging batch vk"))
+
+	v := &Verifier{ChunkVK: "assets chunk vk", BatchVK: "assets batch vk"}
+	devnet, err := v.WithVKRegistry(devnetDir)
+	require.NoError(t, err)
+	staging, err := v.WithVKRegistry(stagingDir)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("devnet chunk vk")), devnet.ChunkVKOf("curie"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("staging chunk vk")), staging.ChunkVKOf("curie"))
+	assert.Equal(t, "assets chunk vk", v.ChunkVKOf("curie"))
+	assert.False(t, v.HasVKRegistry())
+
+	// the keys rolled out to a tenant are not seen by the others.
+	writeForkVKs(t, stagingDir, "curie", []byte("staging chunk vk 2"), []byte("staging batch vk 2"))
+	_, err = staging.ReloadVKs()
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("staging batch vk 2")), staging.BatchVKOf("curie"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("devnet batch vk")), devnet.BatchVKOf("curie"))
+
+	plain, err := v.WithVKRegistry("")
+	require.NoError(t, err)
+	assert.Equal(t, "assets batch vk", plain.BatchVKOf("curie"))
+}
@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/retry"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+const (
+	// EventBatchProofVerified is the event fired when a batch proof is verified.
+	EventBatchProofVerified = "batch_proof_verified"
+
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, keyed by the secret of the webhook.
+	SignatureHeader = "X-Coordinator-Signature"
+
+	defaultTimeout    = 5 * time.Second
+	defaultMaxRetries = 3
+	retryBackoff      = time.Second
+	queueSize         = 1024 // per webhook
+)
+
+// BatchProofVerifiedEvent is the payload posted to webhooks when a batch proof is verified.
+type BatchProofVerifiedEvent struct {
+	Event           string `json:"event"`
+	BatchHash       string `json:"batch_hash"`
+	TaskUUID        string `json:"task_uuid"`
+	ProofTimeSec    uint64 `json:"proof_time_sec"`
+	ProverName      string `json:"prover_name"`
+	ProverPublicKey string `json:"prover_public_key"`
+	ProverVersion   string `json:"prover_version"`
+	VerifiedAt      int64  `json:"verified_at"`
+}
+
+type notification struct {
+	event string
+	body  []byte
+}
+
+// endpoint is a webhook with its own queue and delivery worker, so that a slow or unavailable receiver
+// neither delays nor drops the notifications of the other webhooks.
+type endpoint struct {
+	webhook *config.WebhookConfig
+	queue   chan *notification
+}
+
+// Notifier posts task result events to the configured webhooks. Delivery is best effort: events are queued in memory
+// and delivered asynchronously, so that a slow or unavailable receiver never delays proof submission, but queued
+// events are lost on restart and dropped once the queue of a webhook is full. A delivery may be retried after the
+// receiver handled it, receivers should be idempotent, and poll the coordinator if they must not miss an event.
+type Notifier struct {
+	endpoints []*endpoint
+	client    *http.Client
+
+	notificationTotal        *prometheus.CounterVec
+	notificationFailureTotal *prometheus.CounterVec
+	notificationDroppedTotal *prometheus.CounterVec
+}
+
+// NewNotifier creates a Notifier, it does nothing if no webhook is configured.
+// The delivery workers run for the lifetime of the process, like the api controllers.
+func NewNotifier(webhooks []*config.WebhookConfig, reg prometheus.Registerer) *Notifier {
+	n := &Notifier{
+		client: &http.Client{},
+
+		notificationTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_webhook_notification_total",
+			Help: "Total number of webhook notifications delivered.",
+		}, []string{"event"}),
+		notificationFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_webhook_notification_failure_total",
+			Help: "Total number of webhook notifications failed to be delivered after all retries.",
+		}, []string{"event"}),
+		notificationDroppedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_webhook_notification_dropped_total",
+			Help: "Total number of webhook notifications dropped because the queue of the webhook is full.",
+		}, []string{"event"}),
+	}
+	for _, webhook := range webhooks {
+		e := &endpoint{webhook: webhook, queue: make(chan *notification, queueSize)}
+		n.endpoints = append(n.endpoints, e)
+		go n.run(context.Background(), e)
+	}
+	return n
+}
+
+// NotifyBatchProofVerified queues a BatchProofVerifiedEvent to every webhook subscribed to it.
+func (n *Notifier) NotifyBatchProofVerified(event *BatchProofVerifiedEvent) {
+	event.Event = EventBatchProofVerified
+	n.notify(event.Event, event)
+}
+
+func (n *Notifier) notify(event string, payload interface{}) {
+	if len(n.endpoints) == 0 {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Error("failed to marshal webhook payload", "event", event, "err", err)
+		return
+	}
+	for _, e := range n.endpoints {
+		if !e.webhook.Subscribes(event) {
+			continue
+		}
+		select {
+		case e.queue <- &notification{event: event, body: body}:
+		default:
+			n.notificationDroppedTotal.WithLabelValues(event).Inc()
+			log.Warn("webhook notification queue is full, drop notification", "event", event, "url", e.webhook.URL)
+		}
+	}
+}
+
+func (n *Notifier) run(ctx context.Context, e *endpoint) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notif := <-e.queue:
+			n.deliver(ctx, e.webhook, notif)
+		}
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, webhook *config.WebhookConfig, notif *notification) {
+	maxRetries := defaultMaxRetries
+	if webhook.MaxRetries > 0 {
+		maxRetries = webhook.MaxRetries
+	}
+
+	policy := retry.Policy{InitialBackoff: retryBackoff, Jitter: 0.2, MaxAttempts: maxRetries + 1}
+	err := retry.Do(ctx, policy, func(ctx context.Context) error { return n.post(ctx, webhook, notif) })
+	if err == nil {
+		n.notificationTotal.WithLabelValues(notif.event).Inc()
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	n.notificationFailureTotal.WithLabelValues(notif.event).Inc()
+	log.Error("failed to deliver webhook notification", "event", notif.event, "url", webhook.URL, "err", err)
+}
+
+func (n *Notifier) post(ctx context.Context, webhook *config.WebhookConfig, notif *notification) error {
+	timeout := defaultTimeout
+	if webhook.TimeoutSec > 0 {
+		timeout = time.Duration(webhook.TimeoutSec) * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, webhook.URL, bytes.NewReader(notif.body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, notif.body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body keyed by secret, receivers compare it to the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestNotifyBatchProofVerified(t *testing.T) {
+	received := make(chan *BatchProofVerifiedEvent, 1)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+
+		var event BatchProofVerifiedEvent
+		assert.NoError(t, json.Unmarshal(body, &event))
+		received <- &event
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier([]*config.WebhookConfig{
+		{URL: server.URL, Secret: "secret"},
+		{URL: server.URL, Events: []string{"chunk_proof_verified"}},
+	}, nil)
+	notifier.NotifyBatchProofVerified(&BatchProofVerifiedEvent{BatchHash: "0x01", ProofTimeSec: 10, ProverName: "prover"})
+
+	select {
+	case event := <-received:
+		assert.Equal(t, EventBatchProofVerified, event.Event)
+		assert.Equal(t, "0x01", event.BatchHash)
+		assert.Equal(t, uint64(10), event.ProofTimeSec)
+		assert.Equal(t, "prover", event.ProverName)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not notified")
+	}
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	assert.True(t, (&config.WebhookConfig{}).Subscribes(EventBatchProofVerified))
+	assert.True(t, (&config.WebhookConfig{Events: []string{EventBatchProofVerified}}).Subscribes(EventBatchProofVerified))
+	assert.False(t, (&config.WebhookConfig{Events: []string{"chunk_proof_verified"}}).Subscribes(EventBatchProofVerified))
+}
+
+func TestSlowWebhookDoesNotDelayOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	received := make(chan struct{}, 2)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer fast.Close()
+
+	notifier := NewNotifier([]*config.WebhookConfig{{URL: slow.URL, TimeoutSec: 60}, {URL: fast.URL}}, nil)
+	notifier.NotifyBatchProofVerified(&BatchProofVerifiedEvent{BatchHash: "0x01"})
+	notifier.NotifyBatchProofVerified(&BatchProofVerifiedEvent{BatchHash: "0x02"})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook delayed by another webhook")
+		}
+	}
+}
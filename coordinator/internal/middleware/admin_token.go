@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+// AdminTokenHeader is the header carrying the admin token of admin api requests.
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminTokenMiddleware rejects the requests without the configured admin token.
+func AdminTokenMiddleware(conf *config.AdminConfig) gin.HandlerFunc {
+	token := []byte(conf.Token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminTokenHeader)), token) != 1 {
+			types.RenderFailure(c, types.ErrCoordinatorAdminUnauthorized, errors.New("invalid admin token"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
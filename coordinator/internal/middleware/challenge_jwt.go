@@ -1,34 +1,20 @@
 package middleware
 
 import (
-	"crypto/rand"
-	"encoding/base64"
 	"time"
 
-	"github.com/gin-gonic/gin"
-
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/scroll-tech/go-ethereum/log"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/controller/api"
 )
 
 // ChallengeMiddleware jwt challenge middleware
 func ChallengeMiddleware(conf *config.Config) *jwt.GinJWTMiddleware {
 	jwtMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
-		Authenticator: func(c *gin.Context) (interface{}, error) {
-			return nil, nil
-		},
-		PayloadFunc: func(data interface{}) jwt.MapClaims {
-			b := make([]byte, 32)
-			_, err := rand.Read(b)
-			if err != nil {
-				return jwt.MapClaims{}
-			}
-			return jwt.MapClaims{
-				"random": base64.URLEncoding.EncodeToString(b),
-			}
-		},
+		Authenticator: api.Auth.Challenge,
+		PayloadFunc:   api.Auth.ChallengePayloadFunc,
 		Unauthorized:  unauthorized,
 		Key:           []byte(conf.Auth.Secret),
 		Timeout:       time.Second * time.Duration(conf.Auth.ChallengeExpireDurationSec),
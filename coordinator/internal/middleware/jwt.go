@@ -10,14 +10,28 @@ import (
 
 	"scroll-tech/common/types"
 
+	"scroll-tech/coordinator/internal/logic/auth"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
+// errCodeKey is the context key of the error code of a failed login, rendered by unauthorized.
+const errCodeKey = "coordinator_err_code"
+
+// httpStatusMessage returns the message of a failed login, and records the error code of the failures provers branch on.
+func httpStatusMessage(err error, c *gin.Context) string {
+	if errors.Is(err, auth.ErrProverVersionTooLow) {
+		c.Set(errCodeKey, types.ErrCoordinatorProverVersionTooLow)
+	}
+	return err.Error()
+}
+
 func unauthorized(c *gin.Context, _ int, message string) {
 	lower := strings.ToLower(message)
 	var errCode int
 	err := errors.New(lower)
-	if jwt.ErrExpiredToken.Error() == lower {
+	if code, ok := c.Get(errCodeKey); ok {
+		errCode = code.(int)
+	} else if jwt.ErrExpiredToken.Error() == lower {
 		errCode = types.ErrJWTTokenExpired
 	} else {
 		errCode = types.ErrJWTCommonErr
@@ -30,5 +44,7 @@ func loginResponse(c *gin.Context, code int, message string, time time.Time) {
 		Time:  time,
 		Token: message,
 	}
+	// set by the login controller if a task data key was negotiated
+	resp.SessionPublicKey = c.GetString(coordinatorType.SessionPublicKey)
 	types.RenderSuccess(c, resp)
 }
@@ -14,17 +14,19 @@ import (
 // LoginMiddleware jwt auth middleware
 func LoginMiddleware(conf *config.Config) *jwt.GinJWTMiddleware {
 	jwtMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
-		PayloadFunc:     api.Auth.PayloadFunc,
-		IdentityHandler: api.Auth.IdentityHandler,
-		IdentityKey:     types.PublicKey,
-		Key:             []byte(conf.Auth.Secret),
-		Timeout:         time.Second * time.Duration(conf.Auth.LoginExpireDurationSec),
-		Authenticator:   api.Auth.Login,
-		Unauthorized:    unauthorized,
-		TokenLookup:     "header: Authorization, query: token, cookie: jwt",
-		TokenHeadName:   "Bearer",
-		TimeFunc:        time.Now,
-		LoginResponse:   loginResponse,
+		PayloadFunc:           api.Auth.PayloadFunc,
+		IdentityHandler:       api.Auth.IdentityHandler,
+		Authorizator:          api.Auth.Authorizator,
+		IdentityKey:           types.PublicKey,
+		Key:                   []byte(conf.Auth.Secret),
+		Timeout:               time.Second * time.Duration(conf.Auth.LoginExpireDurationSec),
+		Authenticator:         api.Auth.Login,
+		Unauthorized:          unauthorized,
+		HTTPStatusMessageFunc: httpStatusMessage,
+		TokenLookup:           "header: Authorization, query: token, cookie: jwt",
+		TokenHeadName:         "Bearer",
+		TimeFunc:              time.Now,
+		LoginResponse:         loginResponse,
 	})
 
 	if err != nil {
@@ -71,12 +71,13 @@ func (*Batch) TableName() string {
 }
 
 // GetUnassignedBatch retrieves unassigned batch based on the specified limit.
+// Only the batches up to maxIndex, the task watermark, are returned.
 // The returned batch are sorted in ascending order by their index.
-func (o *Batch) GetUnassignedBatch(ctx context.Context, startChunkIndex, endChunkIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
+func (o *Batch) GetUnassignedBatch(ctx context.Context, startChunkIndex, endChunkIndex, maxIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
 	var batch Batch
 	db := o.db.WithContext(ctx)
-	sql := fmt.Sprintf("SELECT * FROM batch WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND chunk_proofs_status = %d AND start_chunk_index >= %d AND end_chunk_index < %d AND batch.deleted_at IS NULL ORDER BY batch.index LIMIT 1;",
-		int(types.ProvingTaskUnassigned), maxTotalAttempts, maxActiveAttempts, int(types.ChunkProofsStatusReady), startChunkIndex, endChunkIndex)
+	sql := fmt.Sprintf("SELECT * FROM batch WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND chunk_proofs_status = %d AND start_chunk_index >= %d AND end_chunk_index < %d AND batch.index <= %d AND batch.deleted_at IS NULL ORDER BY batch.index LIMIT 1;",
+		int(types.ProvingTaskUnassigned), maxTotalAttempts, maxActiveAttempts, int(types.ChunkProofsStatusReady), startChunkIndex, endChunkIndex, maxIndex)
 	err := db.Raw(sql).Scan(&batch).Error
 	if err != nil {
 		return nil, fmt.Errorf("Batch.GetUnassignedBatch error: %w", err)
@@ -88,12 +89,13 @@ func (o *Batch) GetUnassignedBatch(ctx context.Context, startChunkIndex, endChun
 }
 
 // GetAssignedBatch retrieves assigned batch based on the specified limit.
+// Only the batches up to maxIndex, the task watermark, are returned.
 // The returned batch are sorted in ascending order by their index.
-func (o *Batch) GetAssignedBatch(ctx context.Context, startChunkIndex, endChunkIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
+func (o *Batch) GetAssignedBatch(ctx context.Context, startChunkIndex, endChunkIndex, maxIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
 	var batch Batch
 	db := o.db.WithContext(ctx)
-	sql := fmt.Sprintf("SELECT * FROM batch WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND chunk_proofs_status = %d AND start_chunk_index >= %d AND end_chunk_index < %d AND batch.deleted_at IS NULL ORDER BY batch.index LIMIT 1;",
-		int(types.ProvingTaskAssigned), maxTotalAttempts, maxActiveAttempts, int(types.ChunkProofsStatusReady), startChunkIndex, endChunkIndex)
+	sql := fmt.Sprintf("SELECT * FROM batch WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND chunk_proofs_status = %d AND start_chunk_index >= %d AND end_chunk_index < %d AND batch.index <= %d AND batch.deleted_at IS NULL ORDER BY batch.index LIMIT 1;",
+		int(types.ProvingTaskAssigned), maxTotalAttempts, maxActiveAttempts, int(types.ChunkProofsStatusReady), startChunkIndex, endChunkIndex, maxIndex)
 	err := db.Raw(sql).Scan(&batch).Error
 	if err != nil {
 		return nil, fmt.Errorf("Batch.GetAssignedBatch error: %w", err)
@@ -104,6 +106,40 @@ func (o *Batch) GetAssignedBatch(ctx context.Context, startChunkIndex, endChunkI
 	return &batch, nil
 }
 
+// GetTaskWatermark returns the highest index of the batches tasks are assigned for, so that at most budget batches
+// are outstanding tasks, i.e. unassigned or assigned with their chunk proofs ready, and the number of outstanding tasks
+// up to it. With fewer than budget the watermark leaves room for the next batches.
+func (o *Batch) GetTaskWatermark(ctx context.Context, budget int) (uint64, int64, error) {
+	outstandingTasks := func() *gorm.DB {
+		db := o.db.WithContext(ctx)
+		db = db.Model(&Batch{})
+		db = db.Where("proving_status IN ?", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+		db = db.Where("chunk_proofs_status = ?", int(types.ChunkProofsStatusReady))
+		return db
+	}
+
+	var outstanding int64
+	if err := outstandingTasks().Count(&outstanding).Error; err != nil {
+		return 0, 0, fmt.Errorf("Batch.GetTaskWatermark error: %w", err)
+	}
+	if outstanding >= int64(budget) {
+		var batch Batch
+		if err := outstandingTasks().Select("index").Order("index ASC").Offset(budget - 1).Limit(1).Scan(&batch).Error; err != nil {
+			return 0, 0, fmt.Errorf("Batch.GetTaskWatermark error: %w", err)
+		}
+		return batch.Index, int64(budget), nil
+	}
+
+	latestBatch, err := o.GetLatestBatch(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Batch.GetTaskWatermark error: %w", err)
+	}
+	if latestBatch == nil {
+		return uint64(budget) - 1, 0, nil
+	}
+	return latestBatch.Index + uint64(int64(budget)-outstanding), outstanding, nil
+}
+
 // GetUnassignedAndChunksUnreadyBatches get the batches which is unassigned and chunks is not ready
 func (o *Batch) GetUnassignedAndChunksUnreadyBatches(ctx context.Context, offset, limit int) ([]*Batch, error) {
 	if offset < 0 || limit < 0 {
@@ -151,6 +187,19 @@ func (o *Batch) GetProvingStatusByHash(ctx context.Context, hash string) (types.
 	return types.ProvingStatus(batch.ProvingStatus), nil
 }
 
+// GetBatchByHash retrieves the given batch.
+func (o *Batch) GetBatchByHash(ctx context.Context, hash string) (*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", hash)
+
+	var batch Batch
+	if err := db.First(&batch).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetBatchByHash error: %w, batch hash: %v", err, hash)
+	}
+	return &batch, nil
+}
+
 // GetLatestBatch retrieves the latest batch from the database.
 func (o *Batch) GetLatestBatch(ctx context.Context) (*Batch, error) {
 	db := o.db.WithContext(ctx)
@@ -346,6 +395,8 @@ func (o *Batch) UpdateBatchAttempts(ctx context.Context, index uint64, curActive
 		"proving_status":  types.ProvingTaskAssigned,
 		"total_attempts":  gorm.Expr("total_attempts + 1"),
 		"active_attempts": gorm.Expr("active_attempts + 1"),
+		// RecycleLeakedActiveAttempts relies on updated_at to leave the attempts of ongoing assignments alone.
+		"updated_at": utils.NowUTC(),
 	})
 
 	if result.Error != nil {
@@ -374,3 +425,81 @@ func (o *Batch) DecreaseActiveAttemptsByHash(ctx context.Context, batchHash stri
 	}
 	return nil
 }
+
+// ReleaseReservedAttemptsByHash gives back the attempt counted for the reservation of a batch, both the active and
+// the total one, as the reservation ended without proving the batch.
+func (o *Batch) ReleaseReservedAttemptsByHash(ctx context.Context, hash string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	db = db.Where("active_attempts > ? AND total_attempts > ?", 0, 0)
+	result := db.UpdateColumns(map[string]interface{}{
+		"active_attempts": gorm.Expr("active_attempts - 1"),
+		"total_attempts":  gorm.Expr("total_attempts - 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("Batch.ReleaseReservedAttemptsByHash error: %w, batch hash: %v", result.Error, hash)
+	}
+	return nil
+}
+
+// RecycleLeakedActiveAttempts resets the active_attempts of the assigned batches updated before the given time to their
+// number of assigned and reserved prover tasks. Attempts leak when the coordinator fails between counting an attempt
+// and storing its prover task, and batches whose active attempts reached the limit would otherwise never be assigned
+// again. Counting an attempt updates updated_at, so the attempts of assignments still storing their prover task are
+// left alone as long as updatedBefore is older than the time an assignment takes; postgres re-checks the condition of a
+// row updated concurrently by an assignment before resetting it.
+func (o *Batch) RecycleLeakedActiveAttempts(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	assignedProverTasks := "(SELECT COUNT(*) FROM prover_task WHERE prover_task.task_id = batch.hash AND prover_task.task_type = ? AND prover_task.proving_status IN ? AND prover_task.deleted_at IS NULL)"
+	// the reservations of prefetched tasks hold an attempt until they are assigned or expired.
+	holdingStatuses := []int{int(types.ProverAssigned), int(types.ProverReserved)}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("active_attempts > "+assignedProverTasks, int(message.ProofTypeBatch), holdingStatuses)
+	result := db.UpdateColumn("active_attempts", gorm.Expr(assignedProverTasks, int(message.ProofTypeBatch), holdingStatuses))
+	if result.Error != nil {
+		return 0, fmt.Errorf("Batch.RecycleLeakedActiveAttempts error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetPendingBatches returns the batches which are not proven yet, i.e. unassigned or assigned, in ascending order by index.
+func (o *Batch) GetPendingBatches(ctx context.Context, limit int) ([]*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("proving_status IN (?)", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+	db = db.Order("index ASC")
+	db = db.Limit(limit)
+
+	var batches []*Batch
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetPendingBatches error: %w", err)
+	}
+	return batches, nil
+}
+
+// RequeueBatch makes a batch which is not verified assignable to provers again, e.g. after it failed by exhausting
+// its attempts. The total attempts restart from the active ones, which are still held by the assigned provers.
+// It returns whether a batch of the hash was requeued.
+func (o *Batch) RequeueBatch(ctx context.Context, hash string) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	result := db.Updates(map[string]interface{}{
+		"proving_status": int(types.ProvingTaskUnassigned),
+		"total_attempts": gorm.Expr("active_attempts"),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("Batch.RequeueBatch error: %w, batch hash: %v", result.Error, hash)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,132 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+)
+
+// BatchDependency is a chunk proof a batch proof depends on, the batch is assignable once all its dependencies are
+// verified.
+type BatchDependency struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	BatchHash  string     `json:"batch_hash" gorm:"column:batch_hash;primaryKey"`
+	ChunkHash  string     `json:"chunk_hash" gorm:"column:chunk_hash;primaryKey"`
+	ChunkIndex uint64     `json:"chunk_index" gorm:"column:chunk_index"`
+	VerifiedAt *time.Time `json:"verified_at" gorm:"column:verified_at;default:NULL"`
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// BlockedBatch is a batch waiting for chunk proofs.
+type BlockedBatch struct {
+	BatchHash          string    `gorm:"column:batch_hash"`
+	BatchIndex         uint64    `gorm:"column:batch_index"`
+	Dependencies       int64     `gorm:"column:dependencies"`
+	UnverifiedChunks   int64     `gorm:"column:unverified_chunks"`
+	LowestBlockedChunk uint64    `gorm:"column:lowest_blocked_chunk"`
+	BlockedSince       time.Time `gorm:"column:blocked_since"`
+}
+
+// NewBatchDependency creates a new BatchDependency instance.
+func NewBatchDependency(db *gorm.DB) *BatchDependency {
+	return &BatchDependency{db: db}
+}
+
+// TableName returns the name of the "batch_chunk_dependency" table.
+func (*BatchDependency) TableName() string {
+	return "batch_chunk_dependency"
+}
+
+// SyncBatchDependencies records the chunks of the batch as its dependencies, and marks the ones whose proofs are
+// verified, so that the dependencies follow the chunk proofs. It returns the number of unverified dependencies.
+func (o *BatchDependency) SyncBatchDependencies(ctx context.Context, batchHash string) (int64, error) {
+	var unverified int64
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		insert := `INSERT INTO batch_chunk_dependency (batch_hash, chunk_hash, chunk_index)
+			SELECT chunk.batch_hash, chunk.hash, chunk.index FROM chunk WHERE chunk.batch_hash = ? AND chunk.deleted_at IS NULL
+			ON CONFLICT (batch_hash, chunk_hash) DO NOTHING`
+		if err := tx.Exec(insert, batchHash).Error; err != nil {
+			return err
+		}
+
+		verified := tx.Model(&Chunk{}).Select("hash")
+		verified = verified.Where("batch_hash = ?", batchHash)
+		verified = verified.Where("proving_status = ?", int(types.ProvingTaskVerified))
+		db := tx.Model(&BatchDependency{})
+		db = db.Where("batch_hash = ?", batchHash)
+		db = db.Where("verified_at IS NULL")
+		db = db.Where("chunk_hash IN (?)", verified)
+		if err := db.Update("verified_at", gorm.Expr("CURRENT_TIMESTAMP")).Error; err != nil {
+			return err
+		}
+
+		db = tx.Model(&BatchDependency{})
+		db = db.Where("batch_hash = ?", batchHash)
+		db = db.Where("verified_at IS NULL")
+		return db.Count(&unverified).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("BatchDependency.SyncBatchDependencies error: %w, batch hash: %v", err, batchHash)
+	}
+	return unverified, nil
+}
+
+// GetBatchDependencies returns the dependencies of the batch in ascending order by chunk index.
+func (o *BatchDependency) GetBatchDependencies(ctx context.Context, batchHash string) ([]*BatchDependency, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&BatchDependency{})
+	db = db.Where("batch_hash = ?", batchHash)
+	db = db.Order("chunk_index ASC")
+
+	var dependencies []*BatchDependency
+	if err := db.Find(&dependencies).Error; err != nil {
+		return nil, fmt.Errorf("BatchDependency.GetBatchDependencies error: %w, batch hash: %v", err, batchHash)
+	}
+	return dependencies, nil
+}
+
+// GetBlockedBatches returns the batches not proven yet which wait for chunk proofs, in ascending order by index.
+func (o *BatchDependency) GetBlockedBatches(ctx context.Context, limit int) ([]*BlockedBatch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Table("batch_chunk_dependency AS d")
+	db = db.Select(`d.batch_hash, batch.index AS batch_index, COUNT(*) AS dependencies,
+		COUNT(*) FILTER (WHERE d.verified_at IS NULL) AS unverified_chunks,
+		MIN(d.chunk_index) FILTER (WHERE d.verified_at IS NULL) AS lowest_blocked_chunk, MIN(d.created_at) AS blocked_since`)
+	db = db.Joins("JOIN batch ON batch.hash = d.batch_hash AND batch.deleted_at IS NULL")
+	db = db.Where("batch.chunk_proofs_status = ?", int(types.ChunkProofsStatusPending))
+	db = db.Group("d.batch_hash, batch.index")
+	db = db.Having("COUNT(*) FILTER (WHERE d.verified_at IS NULL) > 0")
+	db = db.Order("batch.index ASC")
+	db = db.Limit(limit)
+
+	var batches []*BlockedBatch
+	if err := db.Scan(&batches).Error; err != nil {
+		return nil, fmt.Errorf("BatchDependency.GetBlockedBatches error: %w", err)
+	}
+	return batches, nil
+}
+
+// CountBlocked returns the number of batches waiting for chunk proofs and the number of chunk proofs they wait for.
+func (o *BatchDependency) CountBlocked(ctx context.Context) (int64, int64, error) {
+	var counts struct {
+		Batches int64 `gorm:"column:batches"`
+		Chunks  int64 `gorm:"column:chunks"`
+	}
+	db := o.db.WithContext(ctx)
+	db = db.Table("batch_chunk_dependency AS d")
+	db = db.Select("COUNT(DISTINCT d.batch_hash) AS batches, COUNT(*) AS chunks")
+	db = db.Joins("JOIN batch ON batch.hash = d.batch_hash AND batch.deleted_at IS NULL")
+	db = db.Where("batch.chunk_proofs_status = ?", int(types.ChunkProofsStatusPending))
+	db = db.Where("d.verified_at IS NULL")
+	if err := db.Scan(&counts).Error; err != nil {
+		return 0, 0, fmt.Errorf("BatchDependency.CountBlocked error: %w", err)
+	}
+	return counts.Batches, counts.Chunks, nil
+}
@@ -8,12 +8,14 @@ import (
 	"gorm.io/gorm"
 )
 
-// Challenge store the challenge string from prover client
+// Challenge store the challenge nonce issued to prover client
 type Challenge struct {
 	db *gorm.DB `gorm:"column:-"`
 
-	ID        int64  `json:"id" gorm:"column:id"`
-	Challenge string `json:"challenge" gorm:"column:challenge"`
+	ID        int64      `json:"id" gorm:"column:id"`
+	Challenge string     `json:"challenge" gorm:"column:challenge"`
+	ExpiredAt *time.Time `json:"expired_at" gorm:"column:expired_at;default:NULL"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at;default:NULL"`
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
@@ -25,42 +27,51 @@ func NewChallenge(db *gorm.DB) *Challenge {
 	return &Challenge{db: db}
 }
 
-// TableName returns the name of the "prover_task" table.
+// TableName returns the name of the "challenge" table.
 func (r *Challenge) TableName() string {
 	return "challenge"
 }
 
-// InsertChallenge check the challenge string exist, if the challenge string is existed
-// return error, if not, just insert it
-func (r *Challenge) InsertChallenge(ctx context.Context, challengeString string) error {
+// InsertChallenge persists an issued challenge nonce, which can be used once before expiredAt.
+func (r *Challenge) InsertChallenge(ctx context.Context, challengeString string, expiredAt time.Time) error {
 	challenge := Challenge{
 		Challenge: challengeString,
+		ExpiredAt: &expiredAt,
 	}
 
 	db := r.db.WithContext(ctx)
 	db = db.Model(&Challenge{})
-	db = db.Where("challenge = ?", challengeString)
-	result := db.FirstOrCreate(&challenge)
-	if result.Error != nil {
-		return result.Error
+	if err := db.Create(&challenge).Error; err != nil {
+		return fmt.Errorf("Challenge.InsertChallenge error: %w", err)
 	}
+	return nil
+}
 
-	if result.RowsAffected == 1 {
-		return nil
+// UseChallenge marks an issued challenge nonce as used. It fails if the nonce was never issued, has expired,
+// or has been used already, the check and the update are done in a single statement so that concurrent
+// logins across coordinator replicas can not use the same nonce twice.
+func (r *Challenge) UseChallenge(ctx context.Context, challengeString string, usedAt time.Time) error {
+	db := r.db.WithContext(ctx)
+	db = db.Model(&Challenge{})
+	db = db.Where("challenge = ?", challengeString)
+	db = db.Where("used_at IS NULL")
+	db = db.Where("expired_at > ?", usedAt)
+	result := db.Update("used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("Challenge.UseChallenge error: %w", result.Error)
 	}
-
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("the challenge string:%s have been used", challengeString)
+		return fmt.Errorf("the challenge string:%s is unknown, expired or has been used", challengeString)
 	}
-
-	return fmt.Errorf("insert challenge string affected rows more than 1")
+	return nil
 }
 
-// DeleteExpireChallenge delete the expire challenge
+// DeleteExpireChallenge delete the challenges expired before the given time.
+// Challenges without expiry were stored before nonces were persisted, they are deleted by creation time.
 func (r *Challenge) DeleteExpireChallenge(ctx context.Context, expiredTime time.Time) error {
 	db := r.db.WithContext(ctx)
 	db = db.Model(&Challenge{})
-	db = db.Where("created_at < ?", expiredTime)
+	db = db.Where("COALESCE(expired_at, created_at) < ?", expiredTime)
 	if err := db.Unscoped().Delete(&Challenge{}).Error; err != nil {
 		return fmt.Errorf("Challenge.DeleteExpireChallenge err: %w", err)
 	}
@@ -69,12 +69,13 @@ func (*Chunk) TableName() string {
 }
 
 // GetUnassignedChunk retrieves unassigned chunk based on the specified limit.
+// Only the chunks up to maxIndex, the task watermark, are returned.
 // The returned chunks are sorted in ascending order by their index.
-func (o *Chunk) GetUnassignedChunk(ctx context.Context, fromBlockNum, toBlockNum uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
+func (o *Chunk) GetUnassignedChunk(ctx context.Context, fromBlockNum, toBlockNum, maxIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
 	var chunk Chunk
 	db := o.db.WithContext(ctx)
-	sql := fmt.Sprintf("SELECT * FROM chunk WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND start_block_number >= %d AND end_block_number < %d AND chunk.deleted_at IS NULL ORDER BY chunk.index LIMIT 1;",
-		int(types.ProvingTaskUnassigned), maxTotalAttempts, maxActiveAttempts, fromBlockNum, toBlockNum)
+	sql := fmt.Sprintf("SELECT * FROM chunk WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND start_block_number >= %d AND end_block_number < %d AND chunk.index <= %d AND chunk.deleted_at IS NULL ORDER BY chunk.index LIMIT 1;",
+		int(types.ProvingTaskUnassigned), maxTotalAttempts, maxActiveAttempts, fromBlockNum, toBlockNum, maxIndex)
 	err := db.Raw(sql).Scan(&chunk).Error
 	if err != nil {
 		return nil, fmt.Errorf("Chunk.GetUnassignedChunk error: %w", err)
@@ -86,12 +87,13 @@ func (o *Chunk) GetUnassignedChunk(ctx context.Context, fromBlockNum, toBlockNum
 }
 
 // GetAssignedChunk retrieves assigned chunk based on the specified limit.
+// Only the chunks up to maxIndex, the task watermark, are returned.
 // The returned chunks are sorted in ascending order by their index.
-func (o *Chunk) GetAssignedChunk(ctx context.Context, fromBlockNum, toBlockNum uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
+func (o *Chunk) GetAssignedChunk(ctx context.Context, fromBlockNum, toBlockNum, maxIndex uint64, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
 	var chunk Chunk
 	db := o.db.WithContext(ctx)
-	sql := fmt.Sprintf("SELECT * FROM chunk WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND start_block_number >= %d AND end_block_number < %d AND chunk.deleted_at IS NULL ORDER BY chunk.index LIMIT 1;",
-		int(types.ProvingTaskAssigned), maxTotalAttempts, maxActiveAttempts, fromBlockNum, toBlockNum)
+	sql := fmt.Sprintf("SELECT * FROM chunk WHERE proving_status = %d AND total_attempts < %d AND active_attempts < %d AND start_block_number >= %d AND end_block_number < %d AND chunk.index <= %d AND chunk.deleted_at IS NULL ORDER BY chunk.index LIMIT 1;",
+		int(types.ProvingTaskAssigned), maxTotalAttempts, maxActiveAttempts, fromBlockNum, toBlockNum, maxIndex)
 	err := db.Raw(sql).Scan(&chunk).Error
 	if err != nil {
 		return nil, fmt.Errorf("Chunk.GetAssignedChunk error: %w", err)
@@ -102,6 +104,39 @@ func (o *Chunk) GetAssignedChunk(ctx context.Context, fromBlockNum, toBlockNum u
 	return &chunk, nil
 }
 
+// GetTaskWatermark returns the highest index of the chunks tasks are assigned for, so that at most budget chunks are
+// outstanding tasks, i.e. unassigned or assigned, and the number of outstanding tasks up to it. Chunks are outstanding
+// tasks as they are inserted, with fewer than budget the watermark leaves room for the next chunks.
+func (o *Chunk) GetTaskWatermark(ctx context.Context, budget int) (uint64, int64, error) {
+	outstandingTasks := func() *gorm.DB {
+		db := o.db.WithContext(ctx)
+		db = db.Model(&Chunk{})
+		db = db.Where("proving_status IN ?", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+		return db
+	}
+
+	var outstanding int64
+	if err := outstandingTasks().Count(&outstanding).Error; err != nil {
+		return 0, 0, fmt.Errorf("Chunk.GetTaskWatermark error: %w", err)
+	}
+	if outstanding >= int64(budget) {
+		var chunk Chunk
+		if err := outstandingTasks().Select("index").Order("index ASC").Offset(budget - 1).Limit(1).Scan(&chunk).Error; err != nil {
+			return 0, 0, fmt.Errorf("Chunk.GetTaskWatermark error: %w", err)
+		}
+		return chunk.Index, int64(budget), nil
+	}
+
+	latestChunk, err := o.getLatestChunk(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Chunk.GetTaskWatermark error: %w", err)
+	}
+	if latestChunk == nil {
+		return uint64(budget) - 1, 0, nil
+	}
+	return latestChunk.Index + uint64(int64(budget)-outstanding), outstanding, nil
+}
+
 // GetChunksByBatchHash retrieves the chunks associated with a specific batch hash.
 // The returned chunks are sorted in ascending order by their associated chunk index.
 func (o *Chunk) GetChunksByBatchHash(ctx context.Context, batchHash string) ([]*Chunk, error) {
@@ -173,19 +208,6 @@ func (o *Chunk) GetProvingStatusByHash(ctx context.Context, hash string) (types.
 	return types.ProvingStatus(chunk.ProvingStatus), nil
 }
 
-// CheckIfBatchChunkProofsAreReady checks if all proofs for all chunks of a given batchHash are collected.
-func (o *Chunk) CheckIfBatchChunkProofsAreReady(ctx context.Context, batchHash string) (bool, error) {
-	db := o.db.WithContext(ctx)
-	db = db.Model(&Chunk{})
-	db = db.Where("batch_hash = ? AND proving_status != ?", batchHash, types.ProvingTaskVerified)
-
-	var count int64
-	if err := db.Count(&count).Error; err != nil {
-		return false, fmt.Errorf("Chunk.CheckIfBatchChunkProofsAreReady error: %w, batch hash: %v", err, batchHash)
-	}
-	return count == 0, nil
-}
-
 // GetChunkByHash retrieves the given chunk.
 func (o *Chunk) GetChunkByHash(ctx context.Context, chunkHash string) (*Chunk, error) {
 	db := o.db.WithContext(ctx)
@@ -333,6 +355,19 @@ func (o *Chunk) UpdateProvingStatusFailed(ctx context.Context, hash string, maxA
 	return nil
 }
 
+// UpdateProvingStatusInvalid marks a chunk which is not verified failed regardless of its attempts, as its inputs
+// can not be proven. It is requeued by an operator after review.
+func (o *Chunk) UpdateProvingStatusInvalid(ctx context.Context, hash string) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	if err := db.Update("proving_status", int(types.ProvingTaskFailed)).Error; err != nil {
+		return fmt.Errorf("Chunk.UpdateProvingStatusInvalid error: %w, chunk hash: %v", err, hash)
+	}
+	return nil
+}
+
 // UpdateProofAndProvingStatusByHash updates the chunk proof and proving_status by hash.
 func (o *Chunk) UpdateProofAndProvingStatusByHash(ctx context.Context, hash string, proof *message.ChunkProof, status types.ProvingStatus, proofTimeSec uint64, dbTX ...*gorm.DB) error {
 	db := o.db
@@ -385,6 +420,8 @@ func (o *Chunk) UpdateChunkAttempts(ctx context.Context, index uint64, curActive
 		"proving_status":  types.ProvingTaskAssigned,
 		"total_attempts":  gorm.Expr("total_attempts + 1"),
 		"active_attempts": gorm.Expr("active_attempts + 1"),
+		// RecycleLeakedActiveAttempts relies on updated_at to leave the attempts of ongoing assignments alone.
+		"updated_at": utils.NowUTC(),
 	})
 
 	if result.Error != nil {
@@ -413,3 +450,81 @@ func (o *Chunk) DecreaseActiveAttemptsByHash(ctx context.Context, chunkHash stri
 	}
 	return nil
 }
+
+// ReleaseReservedAttemptsByHash gives back the attempt counted for the reservation of a chunk, both the active and
+// the total one, as the reservation ended without proving the chunk.
+func (o *Chunk) ReleaseReservedAttemptsByHash(ctx context.Context, hash string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	db = db.Where("active_attempts > ? AND total_attempts > ?", 0, 0)
+	result := db.UpdateColumns(map[string]interface{}{
+		"active_attempts": gorm.Expr("active_attempts - 1"),
+		"total_attempts":  gorm.Expr("total_attempts - 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("Chunk.ReleaseReservedAttemptsByHash error: %w, chunk hash: %v", result.Error, hash)
+	}
+	return nil
+}
+
+// RecycleLeakedActiveAttempts resets the active_attempts of the assigned chunks updated before the given time to their
+// number of assigned and reserved prover tasks. Attempts leak when the coordinator fails between counting an attempt
+// and storing its prover task, and chunks whose active attempts reached the limit would otherwise never be assigned
+// again. Counting an attempt updates updated_at, so the attempts of assignments still storing their prover task are
+// left alone as long as updatedBefore is older than the time an assignment takes; postgres re-checks the condition of a
+// row updated concurrently by an assignment before resetting it.
+func (o *Chunk) RecycleLeakedActiveAttempts(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	assignedProverTasks := "(SELECT COUNT(*) FROM prover_task WHERE prover_task.task_id = chunk.hash AND prover_task.task_type = ? AND prover_task.proving_status IN ? AND prover_task.deleted_at IS NULL)"
+	// the reservations of prefetched tasks hold an attempt until they are assigned or expired.
+	holdingStatuses := []int{int(types.ProverAssigned), int(types.ProverReserved)}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("active_attempts > "+assignedProverTasks, int(message.ProofTypeChunk), holdingStatuses)
+	result := db.UpdateColumn("active_attempts", gorm.Expr(assignedProverTasks, int(message.ProofTypeChunk), holdingStatuses))
+	if result.Error != nil {
+		return 0, fmt.Errorf("Chunk.RecycleLeakedActiveAttempts error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetPendingChunks returns the chunks which are not proven yet, i.e. unassigned or assigned, in ascending order by index.
+func (o *Chunk) GetPendingChunks(ctx context.Context, limit int) ([]*Chunk, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("proving_status IN (?)", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+	db = db.Order("index ASC")
+	db = db.Limit(limit)
+
+	var chunks []*Chunk
+	if err := db.Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.GetPendingChunks error: %w", err)
+	}
+	return chunks, nil
+}
+
+// RequeueChunk makes a chunk which is not verified assignable to provers again, e.g. after it failed by exhausting
+// its attempts. The total attempts restart from the active ones, which are still held by the assigned provers.
+// It returns whether a chunk of the hash was requeued.
+func (o *Chunk) RequeueChunk(ctx context.Context, hash string) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	result := db.Updates(map[string]interface{}{
+		"proving_status": int(types.ProvingTaskUnassigned),
+		"total_attempts": gorm.Expr("active_attempts"),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("Chunk.RequeueChunk error: %w, chunk hash: %v", result.Error, hash)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConfigOverride is a coordinator config parameter overridden at runtime, reloaded periodically by the coordinator.
+type ConfigOverride struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Name  string `json:"name" gorm:"column:name;primaryKey"`
+	Value string `json:"value" gorm:"column:value"`
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewConfigOverride creates a new ConfigOverride instance.
+func NewConfigOverride(db *gorm.DB) *ConfigOverride {
+	return &ConfigOverride{db: db}
+}
+
+// TableName returns the name of the "coordinator_config_override" table.
+func (*ConfigOverride) TableName() string {
+	return "coordinator_config_override"
+}
+
+// GetConfigOverrides returns the overridden parameters.
+func (o *ConfigOverride) GetConfigOverrides(ctx context.Context) ([]*ConfigOverride, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ConfigOverride{})
+	db = db.Order("name ASC")
+
+	var overrides []*ConfigOverride
+	if err := db.Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("ConfigOverride.GetConfigOverrides error: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertConfigOverride overrides the parameter with value.
+func (o *ConfigOverride) UpsertConfigOverride(ctx context.Context, name, value string) error {
+	override := ConfigOverride{Name: name, Value: value}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ConfigOverride{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": value, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")}),
+	})
+	if err := db.Create(&override).Error; err != nil {
+		return fmt.Errorf("ConfigOverride.UpsertConfigOverride error: %w, name: %v", err, name)
+	}
+	return nil
+}
+
+// DeleteConfigOverride removes the override of the parameter, the config file value applies again.
+func (o *ConfigOverride) DeleteConfigOverride(ctx context.Context, name string) error {
+	db := o.db.WithContext(ctx)
+	db = db.Where("name = ?", name)
+	if err := db.Delete(&ConfigOverride{}).Error; err != nil {
+		return fmt.Errorf("ConfigOverride.DeleteConfigOverride error: %w, name: %v", err, name)
+	}
+	return nil
+}
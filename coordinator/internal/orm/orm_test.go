@@ -2,8 +2,10 @@ package orm
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -124,3 +126,593 @@ func TestProverTaskOrmUint256(t *testing.T) {
 	assert.Equal(t, resultRewardUint256, rewardUint256)
 	assert.Equal(t, resultRewardUint256.String(), "115792089237316195423570985008687907853269984665640564039457584007913129639935")
 }
+
+func TestChallengeOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	challengeOrm := NewChallenge(db)
+	now := utils.NowUTC()
+
+	// unknown challenge
+	assert.Error(t, challengeOrm.UseChallenge(context.Background(), "unknown", now))
+
+	assert.NoError(t, challengeOrm.InsertChallenge(context.Background(), "nonce-0", now.Add(time.Minute)))
+	// duplicated challenge
+	assert.Error(t, challengeOrm.InsertChallenge(context.Background(), "nonce-0", now.Add(time.Minute)))
+
+	// a challenge can be used only once
+	assert.NoError(t, challengeOrm.UseChallenge(context.Background(), "nonce-0", now))
+	assert.Error(t, challengeOrm.UseChallenge(context.Background(), "nonce-0", now))
+
+	// expired challenge
+	assert.NoError(t, challengeOrm.InsertChallenge(context.Background(), "nonce-1", now.Add(time.Minute)))
+	assert.Error(t, challengeOrm.UseChallenge(context.Background(), "nonce-1", now.Add(2*time.Minute)))
+
+	// expired challenges are cleaned up, the others are kept
+	assert.NoError(t, challengeOrm.InsertChallenge(context.Background(), "nonce-2", now.Add(time.Hour)))
+	assert.NoError(t, challengeOrm.DeleteExpireChallenge(context.Background(), now.Add(2*time.Minute)))
+	assert.NoError(t, challengeOrm.UseChallenge(context.Background(), "nonce-2", now))
+}
+
+func TestProverSessionOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	// the session is stored by one replica and checked by another one.
+	replica0, replica1 := NewProverSession(db), NewProverSession(db)
+	now := utils.NowUTC()
+
+	assert.NoError(t, replica0.InsertProverSession(context.Background(), &ProverSession{
+		SessionID:     "session-0",
+		PublicKey:     "0",
+		ProverName:    "prover-0",
+		ProverVersion: "v1.0.0",
+		ExpiredAt:     now.Add(time.Minute),
+	}))
+	// duplicated session id
+	assert.Error(t, replica0.InsertProverSession(context.Background(), &ProverSession{
+		SessionID: "session-0",
+		PublicKey: "1",
+		ExpiredAt: now.Add(time.Minute),
+	}))
+
+	session, err := replica1.GetProverSession(context.Background(), "session-0", "0", now)
+	assert.NoError(t, err)
+	assert.NotNil(t, session)
+	assert.Equal(t, "prover-0", session.ProverName)
+
+	// the session id of another prover
+	session, err = replica1.GetProverSession(context.Background(), "session-0", "1", now)
+	assert.NoError(t, err)
+	assert.Nil(t, session)
+
+	// expired session
+	session, err = replica1.GetProverSession(context.Background(), "session-0", "0", now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Nil(t, session)
+
+	// expired sessions are cleaned up, the others are kept
+	assert.NoError(t, replica0.InsertProverSession(context.Background(), &ProverSession{
+		SessionID: "session-1",
+		PublicKey: "1",
+		ExpiredAt: now.Add(time.Hour),
+	}))
+	assert.NoError(t, replica0.DeleteExpiredProverSessions(context.Background(), now.Add(2*time.Minute)))
+	var count int64
+	assert.NoError(t, db.Model(&ProverSession{}).Unscoped().Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	session, err = replica1.GetProverSession(context.Background(), "session-1", "1", now)
+	assert.NoError(t, err)
+	assert.NotNil(t, session)
+}
+
+func TestInsertAssignedProverTask(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	newProverTask := func(taskID string) *ProverTask {
+		return &ProverTask{
+			TaskType:        int16(message.ProofTypeChunk),
+			TaskID:          taskID,
+			ProverName:      "prover-0",
+			ProverPublicKey: "0",
+			ProvingStatus:   int16(types.ProverAssigned),
+			AssignedAt:      utils.NowUTC(),
+		}
+	}
+
+	// concurrent assignments to the same prover, only one of them succeeds.
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			errs <- proverTaskOrm.InsertAssignedProverTask(context.Background(), newProverTask(fmt.Sprintf("task-%d", i)), 1)
+		}(i)
+	}
+	var succeeded int
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrProverAlreadyAssigned)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	isAssigned, err := proverTaskOrm.IsProverAssigned(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.True(t, isAssigned)
+
+	// a prover allowed two assigned tasks gets a second one only.
+	assert.NoError(t, proverTaskOrm.InsertAssignedProverTask(context.Background(), newProverTask("task-4"), 2))
+	err = proverTaskOrm.InsertAssignedProverTask(context.Background(), newProverTask("task-5"), 2)
+	assert.ErrorIs(t, err, ErrProverAlreadyAssigned)
+	assigned, err := proverTaskOrm.CountProverAssignedTasks(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), assigned)
+}
+
+func TestExpireOrphanedProverTasks(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	now := utils.NowUTC()
+	pastDeadline, futureDeadline := now.Add(-10*time.Minute), now.Add(time.Hour)
+	proverTasks := []ProverTask{
+		// the deadline passed before the grace time.
+		{AssignedAt: now.Add(-time.Hour), Deadline: &pastDeadline},
+		// assigned long ago, but its deadline is still ahead.
+		{AssignedAt: now.Add(-time.Hour), Deadline: &futureDeadline},
+		// assigned without a deadline, due after the collection time.
+		{AssignedAt: now.Add(-2 * time.Hour)},
+		{AssignedAt: now.Add(-time.Minute)},
+	}
+	for i := range proverTasks {
+		proverTasks[i].TaskType = int16(message.ProofTypeChunk)
+		proverTasks[i].TaskID = fmt.Sprintf("chunk-%d", i)
+		proverTasks[i].ProverName = fmt.Sprintf("prover-%d", i)
+		proverTasks[i].ProverPublicKey = fmt.Sprintf("%d", i)
+		proverTasks[i].ProvingStatus = int16(types.ProverAssigned)
+		assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &proverTasks[i]))
+	}
+
+	expired, err := proverTaskOrm.ExpireOrphanedProverTasks(context.Background(), message.ProofTypeChunk, 3600, now.Add(-5*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), expired)
+
+	for publicKey, assigned := range map[string]bool{"0": false, "1": true, "2": false, "3": true} {
+		isAssigned, err := proverTaskOrm.IsProverAssigned(context.Background(), publicKey)
+		assert.NoError(t, err)
+		assert.Equal(t, assigned, isAssigned, publicKey)
+	}
+
+	expired, err = proverTaskOrm.ExpireOrphanedProverTasks(context.Background(), message.ProofTypeBatch, 3600, now.Add(-5*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), expired)
+}
+
+func TestRecycleLeakedActiveAttempts(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	chunkOrm := NewChunk(db)
+	for i := 0; i < 3; i++ {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), ProvingStatus: int16(types.ProvingTaskAssigned), ActiveAttempts: 2, TotalAttempts: 2}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+	// chunk-0 and chunk-1 leaked an attempt, chunk-2 holds both of its attempts.
+	for i, taskID := range []string{"chunk-0", "chunk-1", "chunk-2", "chunk-2"} {
+		proverTask := ProverTask{
+			TaskType:        int16(message.ProofTypeChunk),
+			TaskID:          taskID,
+			ProverName:      fmt.Sprintf("prover-%d", i),
+			ProverPublicKey: fmt.Sprintf("%d", i),
+			ProvingStatus:   int16(types.ProverAssigned),
+			AssignedAt:      utils.NowUTC(),
+		}
+		assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &proverTask))
+	}
+	assert.NoError(t, db.Exec("UPDATE chunk SET updated_at = ?", utils.NowUTC().Add(-time.Hour)).Error)
+
+	// an assignment counting an attempt of chunk-1 without having stored its prover task yet.
+	rowsAffected, err := chunkOrm.UpdateChunkAttempts(context.Background(), 1, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	recycled, err := chunkOrm.RecycleLeakedActiveAttempts(context.Background(), utils.NowUTC().Add(-5*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), recycled)
+
+	for hash, activeAttempts := range map[string]int16{"chunk-0": 1, "chunk-1": 3, "chunk-2": 2} {
+		var chunk Chunk
+		assert.NoError(t, db.Where("hash = ?", hash).First(&chunk).Error)
+		assert.Equal(t, activeAttempts, chunk.ActiveAttempts, hash)
+	}
+}
+
+func TestReservedProverTask(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	chunkOrm := NewChunk(db)
+	for i := 0; i < 2; i++ {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), ProvingStatus: int16(types.ProvingTaskAssigned), ActiveAttempts: 1, TotalAttempts: 1}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+	newReservedProverTask := func(publicKey, taskID string, reservedUntil time.Time) *ProverTask {
+		return &ProverTask{
+			TaskType:        int16(message.ProofTypeChunk),
+			TaskID:          taskID,
+			ProverName:      "prover-" + publicKey,
+			ProverPublicKey: publicKey,
+			ProvingStatus:   int16(types.ProverReserved),
+			AssignedAt:      utils.NowUTC(),
+			Deadline:        &reservedUntil,
+		}
+	}
+
+	now := utils.NowUTC()
+	reserved := newReservedProverTask("0", "chunk-0", now.Add(time.Minute))
+	assert.NoError(t, proverTaskOrm.InsertReservedProverTask(context.Background(), reserved))
+	err = proverTaskOrm.InsertReservedProverTask(context.Background(), newReservedProverTask("0", "chunk-1", now.Add(time.Minute)))
+	assert.ErrorIs(t, err, ErrProverAlreadyReserved)
+	expired := newReservedProverTask("1", "chunk-1", now.Add(-time.Minute))
+	assert.NoError(t, proverTaskOrm.InsertReservedProverTask(context.Background(), expired))
+
+	// a reservation is not an assignment.
+	isAssigned, err := proverTaskOrm.IsProverAssigned(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.False(t, isAssigned)
+	isReserved, err := proverTaskOrm.IsProverReserved(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.True(t, isReserved)
+
+	proverTask, err := proverTaskOrm.GetReservedProverTask(context.Background(), "0", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	if assert.NotNil(t, proverTask) {
+		assert.Equal(t, reserved.UUID, proverTask.UUID)
+	}
+	proverTask, err = proverTaskOrm.GetReservedProverTask(context.Background(), "0", message.ProofTypeBatch)
+	assert.NoError(t, err)
+	assert.Nil(t, proverTask)
+	proverTask, err = proverTaskOrm.GetReservedProverTask(context.Background(), "1", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.Nil(t, proverTask)
+
+	deadline := now.Add(time.Hour)
+	reserved.Deadline = &deadline
+	assert.NoError(t, proverTaskOrm.AssignReservedProverTask(context.Background(), reserved, 1))
+	isAssigned, err = proverTaskOrm.IsProverAssigned(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.True(t, isAssigned)
+	expired.Deadline = &deadline
+	assert.ErrorIs(t, proverTaskOrm.AssignReservedProverTask(context.Background(), expired, 1), ErrReservationExpired)
+
+	expiredProverTasks, err := proverTaskOrm.GetExpiredReservedProverTasks(context.Background(), 10, now)
+	assert.NoError(t, err)
+	if assert.Len(t, expiredProverTasks, 1) {
+		assert.Equal(t, expired.UUID, expiredProverTasks[0].UUID)
+	}
+	released, err := proverTaskOrm.ExpireReservedProverTask(context.Background(), expired.UUID)
+	assert.NoError(t, err)
+	assert.True(t, released)
+	released, err = proverTaskOrm.ExpireReservedProverTask(context.Background(), expired.UUID)
+	assert.NoError(t, err)
+	assert.False(t, released)
+	released, err = proverTaskOrm.ExpireReservedProverTask(context.Background(), reserved.UUID)
+	assert.NoError(t, err)
+	assert.False(t, released)
+
+	assert.NoError(t, chunkOrm.ReleaseReservedAttemptsByHash(context.Background(), "chunk-1"))
+	activeAttempts, totalAttempts, err := chunkOrm.GetAttemptsByHash(context.Background(), "chunk-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int16(0), activeAttempts)
+	assert.Equal(t, int16(0), totalAttempts)
+}
+
+func TestProofFailureOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	proofFailureOrm := NewProofFailure(db)
+	classes := []types.ProofFailureClass{types.ProofFailureClassOOM, types.ProofFailureClassOOM, types.ProofFailureClassWitnessMismatch}
+	for i, class := range classes {
+		proofFailure := ProofFailure{
+			TaskID:           fmt.Sprintf("chunk-%d", i),
+			TaskType:         int16(message.ProofTypeChunk),
+			ProverTaskUUID:   fmt.Sprintf("uuid-%d", i),
+			ProverName:       "prover-0",
+			ProverPublicKey:  "0",
+			ProverVersion:    "v4.0.0",
+			FailureType:      int16(types.ProverTaskFailureTypeSubmitStatusNotOk),
+			FailureClass:     int16(class),
+			StartBlockNumber: uint64(i * 10),
+			EndBlockNumber:   uint64(i*10 + 9),
+		}
+		assert.NoError(t, proofFailureOrm.InsertProofFailure(context.Background(), &proofFailure))
+	}
+
+	since := utils.NowUTC().Add(-time.Hour)
+	counts, err := proofFailureOrm.GetProofFailureCounts(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Len(t, counts, 2)
+	assert.Equal(t, int16(types.ProofFailureClassOOM), counts[0].FailureClass)
+	assert.Equal(t, uint64(2), counts[0].Count)
+	assert.Equal(t, uint64(1), counts[1].Count)
+
+	failureClass := int16(types.ProofFailureClassWitnessMismatch)
+	proofFailures, err := proofFailureOrm.GetProofFailures(context.Background(), since, &failureClass, 10)
+	assert.NoError(t, err)
+	assert.Len(t, proofFailures, 1)
+	assert.Equal(t, "chunk-2", proofFailures[0].TaskID)
+	assert.Equal(t, uint64(29), proofFailures[0].EndBlockNumber)
+
+	proofFailures, err = proofFailureOrm.GetProofFailures(context.Background(), utils.NowUTC().Add(time.Hour), nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, proofFailures)
+}
+
+func TestRequeueAndPendingTasks(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	chunkOrm := NewChunk(db)
+	for i, status := range []types.ProvingStatus{types.ProvingTaskVerified, types.ProvingTaskFailed, types.ProvingTaskAssigned, types.ProvingTaskUnassigned} {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), ProvingStatus: int16(status), ActiveAttempts: 1, TotalAttempts: 5}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+
+	chunks, err := chunkOrm.GetPendingChunks(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, "chunk-2", chunks[0].Hash)
+	assert.Equal(t, "chunk-3", chunks[1].Hash)
+
+	// a failed chunk is requeued with the attempts of its assigned provers, a verified one is not.
+	requeued, err := chunkOrm.RequeueChunk(context.Background(), "chunk-1")
+	assert.NoError(t, err)
+	assert.True(t, requeued)
+	requeued, err = chunkOrm.RequeueChunk(context.Background(), "chunk-0")
+	assert.NoError(t, err)
+	assert.False(t, requeued)
+	requeued, err = chunkOrm.RequeueChunk(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.False(t, requeued)
+
+	chunk, err := chunkOrm.GetChunkByHash(context.Background(), "chunk-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int16(types.ProvingTaskUnassigned), chunk.ProvingStatus)
+	assert.Equal(t, int16(1), chunk.TotalAttempts)
+	assert.Equal(t, int16(1), chunk.ActiveAttempts)
+}
+
+func TestProverTaskStats(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	now := utils.NowUTC()
+	for i, status := range []types.ProverProveStatus{types.ProverProofValid, types.ProverProofValid, types.ProverProofInvalid} {
+		proverTask := ProverTask{
+			TaskType:        int16(message.ProofTypeChunk),
+			TaskID:          fmt.Sprintf("chunk-%d", i),
+			ProverName:      "prover-0",
+			ProverPublicKey: "0",
+			ProverVersion:   "v1.0.0",
+			ProvingStatus:   int16(status),
+			AssignedAt:      now,
+		}
+		assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &proverTask))
+	}
+
+	proverTasks, err := proverTaskOrm.GetProverTasksByPublicKey(context.Background(), "0", 2)
+	assert.NoError(t, err)
+	assert.Len(t, proverTasks, 2)
+	assert.Equal(t, "chunk-2", proverTasks[0].TaskID)
+
+	stats, err := proverTaskOrm.GetProverTaskStats(context.Background(), now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, int16(types.ProverProofValid), stats[0].ProvingStatus)
+	assert.Equal(t, uint64(2), stats[0].Count)
+	assert.Equal(t, int16(types.ProverProofInvalid), stats[1].ProvingStatus)
+	assert.Equal(t, uint64(1), stats[1].Count)
+
+	stats, err = proverTaskOrm.GetProverTaskStats(context.Background(), now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
+
+func TestChunkProvingTimeStats(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	assert.Equal(t, "gas<1M", ChunkSizeClass(999_999))
+	assert.Equal(t, "gas<10M", ChunkSizeClass(5_000_000))
+	assert.Equal(t, "gas>=10M", ChunkSizeClass(10_000_000))
+	assert.Equal(t, "chunks<5", BatchSizeClass(1))
+	assert.Equal(t, "chunks>=45", BatchSizeClass(45))
+
+	gas := []uint64{500_000, 800_000, 2_000_000, 12_000_000}
+	for i := range gas {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), TotalL2TxGas: gas[i], TotalL2TxNum: uint64(10 * (i + 1))}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+
+	now := utils.NowUTC()
+	for i := range gas {
+		proverTask := ProverTask{
+			TaskType:        int16(message.ProofTypeChunk),
+			TaskID:          fmt.Sprintf("chunk-%d", i),
+			ProverName:      fmt.Sprintf("prover-%d", i),
+			ProverPublicKey: fmt.Sprintf("%d", i),
+			ProverVersion:   "v1.0.0",
+			ProvingStatus:   int16(types.ProverProofValid),
+			AssignedAt:      now,
+		}
+		assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &proverTask))
+		// chunk-3 did not report its proving time.
+		if i < 3 {
+			assert.NoError(t, proverTaskOrm.UpdateProverTaskProvingTime(context.Background(), proverTask.UUID, uint64(1000*(i+1))))
+		}
+	}
+
+	stats, err := proverTaskOrm.GetChunkProvingTimeStats(context.Background(), now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "gas<1M", stats[0].SizeClass)
+	assert.Equal(t, uint64(2), stats[0].Count)
+	assert.InDelta(t, 1500, stats[0].P50Ms, 1e-9)
+	assert.InDelta(t, 15, stats[0].AvgL2TxNum, 1e-9)
+	assert.Equal(t, "gas<5M", stats[1].SizeClass)
+	assert.InDelta(t, 3000, stats[1].P99Ms, 1e-9)
+
+	stats, err = proverTaskOrm.GetChunkProvingTimeStats(context.Background(), now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
+
+func TestTaskWatermark(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	chunkOrm := NewChunk(db)
+	taskWatermarkOrm := NewTaskWatermark(db)
+
+	// without chunks the watermark leaves room for the first budget chunks.
+	maxIndex, outstanding, err := chunkOrm.GetTaskWatermark(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), maxIndex)
+	assert.Equal(t, int64(0), outstanding)
+
+	statuses := []types.ProvingStatus{types.ProvingTaskVerified, types.ProvingTaskAssigned, types.ProvingTaskFailed, types.ProvingTaskUnassigned, types.ProvingTaskUnassigned, types.ProvingTaskUnassigned}
+	for i, status := range statuses {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), ProvingStatus: int16(status)}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+
+	// chunk-1, chunk-3 and chunk-4 are the first 3 outstanding tasks.
+	maxIndex, outstanding, err = chunkOrm.GetTaskWatermark(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), maxIndex)
+	assert.Equal(t, int64(3), outstanding)
+
+	// 4 outstanding tasks leave room for the next 2 chunks.
+	maxIndex, outstanding, err = chunkOrm.GetTaskWatermark(context.Background(), 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), maxIndex)
+	assert.Equal(t, int64(4), outstanding)
+
+	watermark, err := taskWatermarkOrm.GetTaskWatermark(context.Background(), message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.Nil(t, watermark)
+	assert.NoError(t, taskWatermarkOrm.UpsertTaskWatermark(context.Background(), message.ProofTypeChunk, 4, 3))
+	assert.NoError(t, taskWatermarkOrm.UpsertTaskWatermark(context.Background(), message.ProofTypeChunk, 7, 4))
+	watermark, err = taskWatermarkOrm.GetTaskWatermark(context.Background(), message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), watermark.MaxIndex)
+	assert.Equal(t, int64(4), watermark.OutstandingTasks)
+
+	// the chunks beyond the watermark are not assigned.
+	chunk, err := chunkOrm.GetUnassignedChunk(context.Background(), 0, 100, 3, 2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "chunk-3", chunk.Hash)
+	chunk, err = chunkOrm.GetUnassignedChunk(context.Background(), 0, 100, 2, 2, 5)
+	assert.NoError(t, err)
+	assert.Nil(t, chunk)
+}
+
+func TestConfigOverrideOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	configOverrideOrm := NewConfigOverride(db)
+	overrides, err := configOverrideOrm.GetConfigOverrides(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	assert.NoError(t, configOverrideOrm.UpsertConfigOverride(context.Background(), "chunk_collection_time_sec", "600"))
+	assert.NoError(t, configOverrideOrm.UpsertConfigOverride(context.Background(), "batch_collection_time_sec", "900"))
+	assert.NoError(t, configOverrideOrm.UpsertConfigOverride(context.Background(), "chunk_collection_time_sec", "1200"))
+	overrides, err = configOverrideOrm.GetConfigOverrides(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, overrides, 2) {
+		assert.Equal(t, "batch_collection_time_sec", overrides[0].Name)
+		assert.Equal(t, "900", overrides[0].Value)
+		assert.Equal(t, "chunk_collection_time_sec", overrides[1].Name)
+		assert.Equal(t, "1200", overrides[1].Value)
+	}
+
+	assert.NoError(t, configOverrideOrm.DeleteConfigOverride(context.Background(), "batch_collection_time_sec"))
+	overrides, err = configOverrideOrm.GetConfigOverrides(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, overrides, 1)
+}
+
+func TestBatchDependency(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	batchDependencyOrm := NewBatchDependency(db)
+
+	batch := &Batch{Index: 1, Hash: "batch-1", BatchHeader: []byte{1}, ChunkProofsStatus: int16(types.ChunkProofsStatusPending)}
+	assert.NoError(t, db.Create(batch).Error)
+	statuses := []types.ProvingStatus{types.ProvingTaskVerified, types.ProvingTaskAssigned, types.ProvingTaskUnassigned}
+	for i, status := range statuses {
+		chunk := &Chunk{Index: uint64(i), Hash: fmt.Sprintf("chunk-%d", i), BatchHash: "batch-1", ProvingStatus: int16(status)}
+		assert.NoError(t, db.Create(chunk).Error)
+	}
+
+	unverified, err := batchDependencyOrm.SyncBatchDependencies(context.Background(), "batch-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), unverified)
+
+	dependencies, err := batchDependencyOrm.GetBatchDependencies(context.Background(), "batch-1")
+	assert.NoError(t, err)
+	if assert.Len(t, dependencies, 3) {
+		for i, dependency := range dependencies {
+			assert.Equal(t, fmt.Sprintf("chunk-%d", i), dependency.ChunkHash)
+			assert.Equal(t, i == 0, dependency.VerifiedAt != nil)
+		}
+	}
+
+	blockedBatches, err := batchDependencyOrm.GetBlockedBatches(context.Background(), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, blockedBatches, 1) {
+		assert.Equal(t, "batch-1", blockedBatches[0].BatchHash)
+		assert.Equal(t, uint64(1), blockedBatches[0].BatchIndex)
+		assert.Equal(t, int64(3), blockedBatches[0].Dependencies)
+		assert.Equal(t, int64(2), blockedBatches[0].UnverifiedChunks)
+		assert.Equal(t, uint64(1), blockedBatches[0].LowestBlockedChunk)
+	}
+	batches, chunks, err := batchDependencyOrm.CountBlocked(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), batches)
+	assert.Equal(t, int64(2), chunks)
+
+	// the batch is unblocked once the proofs of its remaining chunks are verified.
+	assert.NoError(t, db.Model(&Chunk{}).Where("batch_hash = ?", "batch-1").Update("proving_status", int16(types.ProvingTaskVerified)).Error)
+	unverified, err = batchDependencyOrm.SyncBatchDependencies(context.Background(), "batch-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), unverified)
+
+	blockedBatches, err = batchDependencyOrm.GetBlockedBatches(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, blockedBatches)
+	batches, chunks, err = batchDependencyOrm.CountBlocked(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), batches)
+	assert.Equal(t, int64(0), chunks)
+}
@@ -0,0 +1,103 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProofFailure is a classified failure of a prover task, with a reference to the inputs of the task,
+// kept to debug the circuits.
+type ProofFailure struct {
+	db *gorm.DB `gorm:"-"`
+
+	ID int64 `json:"id" gorm:"column:id;primaryKey"`
+
+	// task
+	TaskID         string `json:"task_id" gorm:"column:task_id"`
+	TaskType       int16  `json:"task_type" gorm:"column:task_type"`
+	ProverTaskUUID string `json:"prover_task_uuid" gorm:"column:prover_task_uuid"`
+
+	// prover
+	ProverName      string `json:"prover_name" gorm:"column:prover_name"`
+	ProverPublicKey string `json:"prover_public_key" gorm:"column:prover_public_key"`
+	ProverVersion   string `json:"prover_version" gorm:"column:prover_version"`
+
+	// failure
+	FailureType  int16  `json:"failure_type" gorm:"column:failure_type"`
+	FailureClass int16  `json:"failure_class" gorm:"column:failure_class"`
+	FailureMsg   string `json:"failure_msg" gorm:"column:failure_msg"`
+
+	// task inputs reference, the l2 blocks whose traces are the inputs of the task
+	StartBlockNumber uint64 `json:"start_block_number" gorm:"column:start_block_number"`
+	EndBlockNumber   uint64 `json:"end_block_number" gorm:"column:end_block_number"`
+	HardForkName     string `json:"hard_fork_name" gorm:"column:hard_fork_name"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// ProofFailureCount is the number of proof failures of a failure class, task type and prover version.
+type ProofFailureCount struct {
+	FailureClass  int16  `json:"failure_class" gorm:"column:failure_class"`
+	TaskType      int16  `json:"task_type" gorm:"column:task_type"`
+	ProverVersion string `json:"prover_version" gorm:"column:prover_version"`
+	Count         uint64 `json:"count" gorm:"column:count"`
+}
+
+// NewProofFailure creates a new ProofFailure instance.
+func NewProofFailure(db *gorm.DB) *ProofFailure {
+	return &ProofFailure{db: db}
+}
+
+// TableName returns the name of the "proof_failure" table.
+func (*ProofFailure) TableName() string {
+	return "proof_failure"
+}
+
+// InsertProofFailure records a proof failure.
+func (o *ProofFailure) InsertProofFailure(ctx context.Context, proofFailure *ProofFailure) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProofFailure{})
+	if err := db.Create(proofFailure).Error; err != nil {
+		return fmt.Errorf("ProofFailure.InsertProofFailure error: %w, task id: %v, prover task uuid: %v", err, proofFailure.TaskID, proofFailure.ProverTaskUUID)
+	}
+	return nil
+}
+
+// GetProofFailureCounts returns the number of proof failures since the given time, grouped by failure class, task type
+// and prover version, the most frequent first.
+func (o *ProofFailure) GetProofFailureCounts(ctx context.Context, since time.Time) ([]*ProofFailureCount, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProofFailure{})
+	db = db.Select("failure_class, task_type, prover_version, COUNT(*) AS count")
+	db = db.Where("created_at >= ?", since)
+	db = db.Group("failure_class, task_type, prover_version")
+	db = db.Order("count DESC")
+
+	var counts []*ProofFailureCount
+	if err := db.Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("ProofFailure.GetProofFailureCounts error: %w, since: %v", err, since)
+	}
+	return counts, nil
+}
+
+// GetProofFailures returns the latest proof failures since the given time, optionally of a failure class only.
+func (o *ProofFailure) GetProofFailures(ctx context.Context, since time.Time, failureClass *int16, limit int) ([]*ProofFailure, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProofFailure{})
+	db = db.Where("created_at >= ?", since)
+	if failureClass != nil {
+		db = db.Where("failure_class = ?", *failureClass)
+	}
+	db = db.Order("id DESC")
+	db = db.Limit(limit)
+
+	var proofFailures []*ProofFailure
+	if err := db.Find(&proofFailures).Error; err != nil {
+		return nil, fmt.Errorf("ProofFailure.GetProofFailures error: %w, since: %v", err, since)
+	}
+	return proofFailures, nil
+}
@@ -0,0 +1,94 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProverSession is the login session of a prover, stored so that a prover logged in to one coordinator replica
+// is accepted by all of them.
+type ProverSession struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID            int64     `json:"id" gorm:"column:id"`
+	SessionID     string    `json:"session_id" gorm:"column:session_id"`
+	PublicKey     string    `json:"public_key" gorm:"column:public_key"`
+	ProverName    string    `json:"prover_name" gorm:"column:prover_name"`
+	ProverVersion string    `json:"prover_version" gorm:"column:prover_version"`
+	ExpiredAt     time.Time `json:"expired_at" gorm:"column:expired_at"`
+	// TaskDataKey is the hex encoded key encrypting the task data sent to the prover, empty if not negotiated.
+	TaskDataKey string `json:"task_data_key" gorm:"column:task_data_key"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+// NewProverSession creates a new ProverSession instance.
+func NewProverSession(db *gorm.DB) *ProverSession {
+	return &ProverSession{db: db}
+}
+
+// TableName returns the name of the "prover_session" table.
+func (*ProverSession) TableName() string {
+	return "prover_session"
+}
+
+// InsertProverSession stores a new prover login session.
+func (o *ProverSession) InsertProverSession(ctx context.Context, session *ProverSession) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverSession{})
+	if err := db.Create(session).Error; err != nil {
+		return fmt.Errorf("ProverSession.InsertProverSession error: %w, public key: %v", err, session.PublicKey)
+	}
+	return nil
+}
+
+// GetProverSession retrieves the session of the given session id and public key which has not expired at the
+// given time, it returns nil if there is no such session.
+func (o *ProverSession) GetProverSession(ctx context.Context, sessionID, publicKey string, now time.Time) (*ProverSession, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverSession{})
+	db = db.Where("session_id = ?", sessionID)
+	db = db.Where("public_key = ?", publicKey)
+	db = db.Where("expired_at > ?", now)
+
+	var session ProverSession
+	if err := db.First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ProverSession.GetProverSession error: %w, public key: %v", err, publicKey)
+	}
+	return &session, nil
+}
+
+// DeleteExpiredProverSessions deletes the sessions expired before the given time.
+func (o *ProverSession) DeleteExpiredProverSessions(ctx context.Context, expiredTime time.Time) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverSession{})
+	db = db.Where("expired_at < ?", expiredTime)
+	if err := db.Unscoped().Delete(&ProverSession{}).Error; err != nil {
+		return fmt.Errorf("ProverSession.DeleteExpiredProverSessions error: %w", err)
+	}
+	return nil
+}
+
+// GetProverSessions returns the sessions which have not expired at the given time, ordered by expiry.
+func (o *ProverSession) GetProverSessions(ctx context.Context, now time.Time) ([]*ProverSession, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverSession{})
+	db = db.Where("expired_at > ?", now)
+	db = db.Order("expired_at ASC")
+
+	var sessions []*ProverSession
+	if err := db.Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("ProverSession.GetProverSessions error: %w", err)
+	}
+	return sessions, nil
+}
@@ -2,7 +2,9 @@ package orm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +12,21 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"scroll-tech/common/database"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 )
 
+// ErrProverAlreadyAssigned indicates that the prover already has an assigned task.
+var ErrProverAlreadyAssigned = errors.New("prover is already assigned a task")
+
+// ErrProverAlreadyReserved indicates that the prover already has a task reserved by a prefetch.
+var ErrProverAlreadyReserved = errors.New("prover already has a reserved task")
+
+// ErrReservationExpired indicates that the reservation of a prover task ended before the task was assigned.
+var ErrReservationExpired = errors.New("prover task reservation expired")
+
 // ProverTask is assigned provers info of chunk/batch proof prover task
 type ProverTask struct {
 	db *gorm.DB `gorm:"column:-"`
@@ -36,7 +48,10 @@ type ProverTask struct {
 	FailureType   int16           `json:"failure_type" gorm:"column:failure_type;default:0"`
 	Reward        decimal.Decimal `json:"reward" gorm:"column:reward;default:0;type:decimal(78)"`
 	Proof         []byte          `json:"proof" gorm:"column:proof;default:NULL"`
+	ProofChecksum string          `json:"proof_checksum" gorm:"column:proof_checksum;default:NULL"`
 	AssignedAt    time.Time       `json:"assigned_at" gorm:"assigned_at"`
+	Deadline      *time.Time      `json:"deadline" gorm:"column:deadline;default:NULL"`
+	ProvingTimeMs uint64          `json:"proving_time_ms" gorm:"column:proving_time_ms;default:NULL"`
 
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
@@ -44,6 +59,78 @@ type ProverTask struct {
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at"`
 }
 
+// ProverTaskStat is the number of prover tasks of a task type, prover version and proving status, and their average
+// duration from the assignment to the last update, e.g. the submission of the proof.
+type ProverTaskStat struct {
+	TaskType       int16   `json:"task_type" gorm:"column:task_type"`
+	ProverVersion  string  `json:"prover_version" gorm:"column:prover_version"`
+	ProvingStatus  int16   `json:"proving_status" gorm:"column:proving_status"`
+	Count          uint64  `json:"count" gorm:"column:count"`
+	AvgDurationSec float64 `json:"avg_duration_sec" gorm:"column:avg_duration_sec"`
+}
+
+// ProvingTimeStat holds the percentiles of the proving times reported for the verified chunk tasks of a prover
+// version and chunk size class, with the average size of the chunks.
+type ProvingTimeStat struct {
+	ProverVersion string  `json:"prover_version" gorm:"column:prover_version"`
+	SizeClass     string  `json:"size_class" gorm:"column:size_class"`
+	Count         uint64  `json:"count" gorm:"column:count"`
+	P50Ms         float64 `json:"p50_ms" gorm:"column:p50_ms"`
+	P90Ms         float64 `json:"p90_ms" gorm:"column:p90_ms"`
+	P99Ms         float64 `json:"p99_ms" gorm:"column:p99_ms"`
+	AvgL2TxNum    float64 `json:"avg_l2_tx_num" gorm:"column:avg_l2_tx_num"`
+	AvgL2TxGas    float64 `json:"avg_l2_tx_gas" gorm:"column:avg_l2_tx_gas"`
+}
+
+// sizeClasses splits tasks by a size, e.g. the l2 tx gas of chunks, bounds are the exclusive upper bounds of all the
+// classes but the last one.
+type sizeClasses struct {
+	bounds []uint64
+	names  []string
+}
+
+// chunkSizeClasses split chunks by their total l2 tx gas.
+var chunkSizeClasses = sizeClasses{
+	bounds: []uint64{1_000_000, 5_000_000, 10_000_000},
+	names:  []string{"gas<1M", "gas<5M", "gas<10M", "gas>=10M"},
+}
+
+// batchSizeClasses split batches by their number of chunks.
+var batchSizeClasses = sizeClasses{
+	bounds: []uint64{5, 15, 45},
+	names:  []string{"chunks<5", "chunks<15", "chunks<45", "chunks>=45"},
+}
+
+func (c *sizeClasses) of(size uint64) string {
+	for i, bound := range c.bounds {
+		if size < bound {
+			return c.names[i]
+		}
+	}
+	return c.names[len(c.bounds)]
+}
+
+// sql returns the expression of the size class of the size column.
+func (c *sizeClasses) sql(column string) string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	for i, bound := range c.bounds {
+		fmt.Fprintf(&b, " WHEN %s < %d THEN '%s'", column, bound, c.names[i])
+	}
+	fmt.Fprintf(&b, " ELSE '%s' END", c.names[len(c.bounds)])
+	return b.String()
+}
+
+// ChunkSizeClass returns the size class of a chunk of the given total l2 tx gas.
+func ChunkSizeClass(totalL2TxGas uint64) string {
+	return chunkSizeClasses.of(totalL2TxGas)
+}
+
+// BatchSizeClass returns the size class of a batch of the given number of chunks.
+func BatchSizeClass(numChunks uint64) string {
+	return batchSizeClasses.of(numChunks)
+}
+
 // NewProverTask creates a new ProverTask instance.
 func NewProverTask(db *gorm.DB) *ProverTask {
 	return &ProverTask{db: db}
@@ -68,6 +155,87 @@ func (o *ProverTask) IsProverAssigned(ctx context.Context, publicKey string) (bo
 	return true, nil
 }
 
+// CountProverAssignedTasks returns the number of tasks assigned to a prover with the given public key.
+func (o *ProverTask) CountProverAssignedTasks(ctx context.Context, publicKey string) (int64, error) {
+	var count int64
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ? AND proving_status = ?", publicKey, int(types.ProverAssigned))
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("ProverTask.CountProverAssignedTasks error: %w, public key: %v", err, publicKey)
+	}
+	return count, nil
+}
+
+// IsProverReserved checks if a prover with the given public key has a task reserved by a prefetch, ended reservations
+// included until they are expired.
+func (o *ProverTask) IsProverReserved(ctx context.Context, publicKey string) (bool, error) {
+	var count int64
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ? AND proving_status = ?", publicKey, int(types.ProverReserved))
+	if err := db.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("ProverTask.IsProverReserved error: %w, public key: %v", err, publicKey)
+	}
+	return count > 0, nil
+}
+
+// GetReservedProverTask returns the task of the task type reserved for a prover whose reservation has not ended, nil
+// if there is none.
+func (o *ProverTask) GetReservedProverTask(ctx context.Context, publicKey string, taskType message.ProofType) (*ProverTask, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ?", publicKey)
+	db = db.Where("task_type = ?", int(taskType))
+	db = db.Where("proving_status = ?", int(types.ProverReserved))
+	db = db.Where("deadline >= ?", utils.NowUTC())
+
+	var proverTask ProverTask
+	if err := db.First(&proverTask).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ProverTask.GetReservedProverTask error: %w, public key: %v, task type: %v", err, publicKey, taskType.String())
+	}
+	return &proverTask, nil
+}
+
+// GetExpiredReservedProverTasks returns the reserved prover tasks whose reservation ended before the given time.
+func (o *ProverTask) GetExpiredReservedProverTasks(ctx context.Context, limit int, before time.Time) ([]*ProverTask, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("proving_status = ?", int(types.ProverReserved))
+	db = db.Where("deadline < ?", before)
+	db = db.Limit(limit)
+
+	var proverTasks []*ProverTask
+	if err := db.Find(&proverTasks).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetExpiredReservedProverTasks error: %w", err)
+	}
+	return proverTasks, nil
+}
+
+// ExpireReservedProverTask ends the reservation of a reserved prover task, it returns false if the task is no longer
+// reserved, e.g. it was assigned meanwhile.
+func (o *ProverTask) ExpireReservedProverTask(ctx context.Context, uuid uuid.UUID, dbTX ...*gorm.DB) (bool, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("uuid = ?", uuid)
+	db = db.Where("proving_status = ?", int(types.ProverReserved))
+	result := db.Updates(map[string]interface{}{
+		"proving_status": int(types.ProverProofInvalid),
+		"failure_type":   int(types.ProverTaskFailureTypeReservationExpired),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("ProverTask.ExpireReservedProverTask error: %w, uuid: %v", result.Error, uuid)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // GetProverTasks get prover tasks
 func (o *ProverTask) GetProverTasks(ctx context.Context, fields map[string]interface{}, orderByList []string, offset, limit int) ([]ProverTask, error) {
 	db := o.db.WithContext(ctx)
@@ -181,13 +349,13 @@ func (o *ProverTask) GetProvingStatusByTaskID(ctx context.Context, taskType mess
 	return types.ProverProveStatus(proverTask.ProvingStatus), nil
 }
 
-// GetTimeoutAssignedProverTasks get the timeout and assigned proving_status prover task
-func (o *ProverTask) GetTimeoutAssignedProverTasks(ctx context.Context, limit int, taskType message.ProofType, timeout time.Duration) ([]ProverTask, error) {
+// GetTimeoutAssignedProverTasks get the timeout and assigned proving_status prover task, assigned before assignedBefore
+func (o *ProverTask) GetTimeoutAssignedProverTasks(ctx context.Context, limit int, taskType message.ProofType, assignedBefore time.Time) ([]ProverTask, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&ProverTask{})
 	db = db.Where("proving_status", int(types.ProverAssigned))
 	db = db.Where("task_type", int(taskType))
-	db = db.Where("assigned_at < ?", utils.NowUTC().Add(-timeout))
+	db = db.Where("assigned_at < ?", assignedBefore)
 	db = db.Limit(limit)
 
 	var proverTasks []ProverTask
@@ -198,6 +366,22 @@ func (o *ProverTask) GetTimeoutAssignedProverTasks(ctx context.Context, limit in
 	return proverTasks, nil
 }
 
+// GetAssignedProverTasks returns the prover tasks which are assigned and have not been submitted yet, the longest
+// assigned first.
+func (o *ProverTask) GetAssignedProverTasks(ctx context.Context, limit int) ([]*ProverTask, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("proving_status = ?", int(types.ProverAssigned))
+	db = db.Order("assigned_at ASC")
+	db = db.Limit(limit)
+
+	var proverTasks []*ProverTask
+	if err := db.Find(&proverTasks).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetAssignedProverTasks error: %w", err)
+	}
+	return proverTasks, nil
+}
+
 // TaskTimeoutMoreThanOnce get the timeout twice task. a temp design
 func (o *ProverTask) TaskTimeoutMoreThanOnce(ctx context.Context, taskType message.ProofType, taskID string) bool {
 	db := o.db.WithContext(ctx)
@@ -218,6 +402,26 @@ func (o *ProverTask) TaskTimeoutMoreThanOnce(ctx context.Context, taskType messa
 	return false
 }
 
+// ExpireOrphanedProverTasks expires the assigned prover tasks of a task type whose deadline passed before the given
+// time, e.g. of provers which disappeared without submitting a proof, so that their provers are no longer considered
+// busy. Tasks assigned without a deadline are due collectionTimeSec after their assignment.
+// The active attempts of the expired tasks are released by RecycleLeakedActiveAttempts.
+func (o *ProverTask) ExpireOrphanedProverTasks(ctx context.Context, taskType message.ProofType, collectionTimeSec int, deadlineBefore time.Time) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("proving_status = ?", int(types.ProverAssigned))
+	db = db.Where("task_type = ?", int(taskType))
+	db = db.Where("COALESCE(deadline, assigned_at + ? * INTERVAL '1 second') < ?", collectionTimeSec, deadlineBefore)
+	result := db.Updates(map[string]interface{}{
+		"proving_status": int(types.ProverProofInvalid),
+		"failure_type":   int(types.ProverTaskFailureTypeSessionExpired),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("ProverTask.ExpireOrphanedProverTasks error: %w, task type: %v", result.Error, taskType.String())
+	}
+	return result.RowsAffected, nil
+}
+
 // InsertProverTask insert a prover Task record
 func (o *ProverTask) InsertProverTask(ctx context.Context, proverTask *ProverTask, dbTX ...*gorm.DB) error {
 	db := o.db.WithContext(ctx)
@@ -232,18 +436,125 @@ func (o *ProverTask) InsertProverTask(ctx context.Context, proverTask *ProverTas
 	return nil
 }
 
-// UpdateProverTaskProof update the prover task's proof
-func (o *ProverTask) UpdateProverTaskProof(ctx context.Context, uuid uuid.UUID, proof []byte) error {
+// InsertAssignedProverTask inserts an assigned prover task if the prover has fewer than maxAssigned assigned tasks.
+// The check and the insert run under a postgres advisory lock of the prover's public key, so that coordinator
+// replicas serving the same prover concurrently can not assign it more tasks.
+func (o *ProverTask) InsertAssignedProverTask(ctx context.Context, proverTask *ProverTask, maxAssigned int) error {
+	err := database.TransactionWithRetry(ctx, o.db, func(tx *gorm.DB) error {
+		if err := lockProver(tx, proverTask.ProverPublicKey); err != nil {
+			return err
+		}
+		if err := checkProverTaskCount(tx, proverTask.ProverPublicKey, types.ProverAssigned, maxAssigned, ErrProverAlreadyAssigned); err != nil {
+			return err
+		}
+		return o.InsertProverTask(ctx, proverTask, tx)
+	})
+	if err != nil {
+		return fmt.Errorf("ProverTask.InsertAssignedProverTask error: %w", err)
+	}
+	return nil
+}
+
+// InsertReservedProverTask inserts a prover task reserved by a prefetch if the prover has no other reserved task,
+// under the advisory lock of the prover's public key as InsertAssignedProverTask.
+func (o *ProverTask) InsertReservedProverTask(ctx context.Context, proverTask *ProverTask) error {
+	err := database.TransactionWithRetry(ctx, o.db, func(tx *gorm.DB) error {
+		if err := lockProver(tx, proverTask.ProverPublicKey); err != nil {
+			return err
+		}
+		if err := checkProverTaskCount(tx, proverTask.ProverPublicKey, types.ProverReserved, 1, ErrProverAlreadyReserved); err != nil {
+			return err
+		}
+		return o.InsertProverTask(ctx, proverTask, tx)
+	})
+	if err != nil {
+		return fmt.Errorf("ProverTask.InsertReservedProverTask error: %w", err)
+	}
+	return nil
+}
+
+// AssignReservedProverTask assigns the prover the task reserved for it, with the assignment time and deadline of
+// proverTask, if the reservation has not ended and the prover has fewer than maxAssigned assigned tasks. It runs under
+// the advisory lock of the prover's public key as InsertAssignedProverTask.
+func (o *ProverTask) AssignReservedProverTask(ctx context.Context, proverTask *ProverTask, maxAssigned int) error {
+	err := database.TransactionWithRetry(ctx, o.db, func(tx *gorm.DB) error {
+		if err := lockProver(tx, proverTask.ProverPublicKey); err != nil {
+			return err
+		}
+		if err := checkProverTaskCount(tx, proverTask.ProverPublicKey, types.ProverAssigned, maxAssigned, ErrProverAlreadyAssigned); err != nil {
+			return err
+		}
+
+		db := tx.Model(&ProverTask{})
+		db = db.Where("uuid = ?", proverTask.UUID)
+		db = db.Where("proving_status = ?", int(types.ProverReserved))
+		db = db.Where("deadline >= ?", utils.NowUTC())
+		result := db.Updates(map[string]interface{}{
+			"proving_status": int(types.ProverAssigned),
+			"assigned_at":    proverTask.AssignedAt,
+			"deadline":       proverTask.Deadline,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReservationExpired
+		}
+		proverTask.ProvingStatus = int16(types.ProverAssigned)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ProverTask.AssignReservedProverTask error: %w, uuid: %v", err, proverTask.UUID)
+	}
+	return nil
+}
+
+// lockProver acquires the postgres advisory lock of a prover's public key, it is released when the transaction ends.
+func lockProver(tx *gorm.DB, publicKey string) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "prover_task:"+publicKey).Error; err != nil {
+		return fmt.Errorf("failed to acquire prover lock: %w", err)
+	}
+	return nil
+}
+
+// checkProverTaskCount returns errExists if the prover has maxTasks prover tasks of the proving status or more.
+func checkProverTaskCount(tx *gorm.DB, publicKey string, status types.ProverProveStatus, maxTasks int, errExists error) error {
+	var count int64
+	db := tx.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ? AND proving_status = ?", publicKey, int(status))
+	if err := db.Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count %s tasks of prover: %w", status.String(), err)
+	}
+	if count >= int64(maxTasks) {
+		return errExists
+	}
+	return nil
+}
+
+// UpdateProverTaskProof update the prover task's proof and its checksum
+func (o *ProverTask) UpdateProverTaskProof(ctx context.Context, uuid uuid.UUID, proof []byte, checksum string) error {
 	db := o.db
 	db = db.WithContext(ctx)
 	db = db.Model(&ProverTask{})
 	db = db.Where("uuid = ?", uuid)
-	if err := db.Update("proof", proof).Error; err != nil {
+	if err := db.Updates(map[string]interface{}{"proof": proof, "proof_checksum": checksum}).Error; err != nil {
 		return fmt.Errorf("ProverTask.UpdateProverTaskProof error: %w, uuid: %v", err, uuid)
 	}
 	return nil
 }
 
+// UpdateProverTaskProvingTime updates the proving time reported by the prover of the prover task.
+func (o *ProverTask) UpdateProverTaskProvingTime(ctx context.Context, uuid uuid.UUID, provingTimeMs uint64) error {
+	db := o.db
+	db = db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("uuid = ?", uuid)
+	if err := db.Update("proving_time_ms", provingTimeMs).Error; err != nil {
+		return fmt.Errorf("ProverTask.UpdateProverTaskProvingTime error: %w, uuid: %v", err, uuid)
+	}
+	return nil
+}
+
 // UpdateProverTaskProvingStatusAndFailureType updates the proving_status of a specific ProverTask record.
 func (o *ProverTask) UpdateProverTaskProvingStatusAndFailureType(ctx context.Context, uuid uuid.UUID, status types.ProverProveStatus, failureType types.ProverTaskFailureType, dbTX ...*gorm.DB) error {
 	db := o.db
@@ -279,3 +590,61 @@ func (o *ProverTask) UpdateProverTaskFailureType(ctx context.Context, uuid uuid.
 	}
 	return nil
 }
+
+// GetProverTasksByPublicKey returns the latest prover tasks of the prover of the given public key.
+func (o *ProverTask) GetProverTasksByPublicKey(ctx context.Context, publicKey string, limit int) ([]*ProverTask, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ?", publicKey)
+	db = db.Order("id DESC")
+	db = db.Limit(limit)
+
+	var proverTasks []*ProverTask
+	if err := db.Find(&proverTasks).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetProverTasksByPublicKey error: %w, public key: %v", err, publicKey)
+	}
+	return proverTasks, nil
+}
+
+// GetProverTaskStats returns the stats of the prover tasks assigned since the given time, grouped by task type,
+// prover version and proving status.
+func (o *ProverTask) GetProverTaskStats(ctx context.Context, since time.Time) ([]*ProverTaskStat, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Select("task_type, prover_version, proving_status, COUNT(*) AS count, AVG(EXTRACT(EPOCH FROM (updated_at - assigned_at))) AS avg_duration_sec")
+	db = db.Where("assigned_at >= ?", since)
+	db = db.Group("task_type, prover_version, proving_status")
+	db = db.Order("task_type, prover_version, proving_status")
+
+	var stats []*ProverTaskStat
+	if err := db.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetProverTaskStats error: %w, since: %v", err, since)
+	}
+	return stats, nil
+}
+
+// GetChunkProvingTimeStats returns the percentiles of the proving times reported for the chunk tasks assigned since
+// the given time and verified, grouped by prover version and chunk size class.
+func (o *ProverTask) GetChunkProvingTimeStats(ctx context.Context, since time.Time) ([]*ProvingTimeStat, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Table("prover_task")
+	db = db.Joins("JOIN chunk ON chunk.hash = prover_task.task_id")
+	db = db.Select("prover_task.prover_version AS prover_version, " + chunkSizeClasses.sql("chunk.total_l2_tx_gas") + " AS size_class, COUNT(*) AS count, " +
+		"percentile_cont(0.5) WITHIN GROUP (ORDER BY prover_task.proving_time_ms) AS p50_ms, " +
+		"percentile_cont(0.9) WITHIN GROUP (ORDER BY prover_task.proving_time_ms) AS p90_ms, " +
+		"percentile_cont(0.99) WITHIN GROUP (ORDER BY prover_task.proving_time_ms) AS p99_ms, " +
+		"AVG(chunk.total_l2_tx_num) AS avg_l2_tx_num, AVG(chunk.total_l2_tx_gas) AS avg_l2_tx_gas")
+	db = db.Where("prover_task.task_type = ?", int(message.ProofTypeChunk))
+	db = db.Where("prover_task.proving_status = ?", int(types.ProverProofValid))
+	db = db.Where("prover_task.proving_time_ms IS NOT NULL")
+	db = db.Where("prover_task.assigned_at >= ?", since)
+	db = db.Where("prover_task.deleted_at IS NULL")
+	db = db.Group("1, 2")
+	db = db.Order("1, 2")
+
+	var stats []*ProvingTimeStat
+	if err := db.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetChunkProvingTimeStats error: %w, since: %v", err, since)
+	}
+	return stats, nil
+}
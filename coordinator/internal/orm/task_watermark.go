@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/types/message"
+)
+
+// TaskWatermark is the highest chunk or batch index tasks of a type are assigned for, advanced by the coordinator
+// cron within the budget of outstanding tasks.
+type TaskWatermark struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	TaskType         int16  `json:"task_type" gorm:"column:task_type;primaryKey"`
+	MaxIndex         uint64 `json:"max_index" gorm:"column:max_index"`
+	OutstandingTasks int64  `json:"outstanding_tasks" gorm:"column:outstanding_tasks"`
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewTaskWatermark creates a new TaskWatermark instance.
+func NewTaskWatermark(db *gorm.DB) *TaskWatermark {
+	return &TaskWatermark{db: db}
+}
+
+// TableName returns the name of the "prover_task_watermark" table.
+func (*TaskWatermark) TableName() string {
+	return "prover_task_watermark"
+}
+
+// GetTaskWatermark returns the watermark of the task type, nil if it was never advanced.
+func (o *TaskWatermark) GetTaskWatermark(ctx context.Context, taskType message.ProofType) (*TaskWatermark, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&TaskWatermark{})
+	db = db.Where("task_type = ?", int16(taskType))
+
+	var watermark TaskWatermark
+	if err := db.First(&watermark).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("TaskWatermark.GetTaskWatermark error: %w", err)
+	}
+	return &watermark, nil
+}
+
+// UpsertTaskWatermark stores the watermark of the task type and its number of outstanding tasks.
+func (o *TaskWatermark) UpsertTaskWatermark(ctx context.Context, taskType message.ProofType, maxIndex uint64, outstandingTasks int64) error {
+	watermark := TaskWatermark{
+		TaskType:         int16(taskType),
+		MaxIndex:         maxIndex,
+		OutstandingTasks: outstandingTasks,
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&TaskWatermark{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_index", "outstanding_tasks", "updated_at"}),
+	})
+	if err := db.Create(&watermark).Error; err != nil {
+		return fmt.Errorf("TaskWatermark.UpsertTaskWatermark error: %w", err)
+	}
+	return nil
+}
@@ -34,7 +34,24 @@ func v1(router *gin.RouterGroup, conf *config.Config) {
 	// need jwt token api
 	r.Use(loginMiddleware.MiddlewareFunc())
 	{
-		r.POST("/get_task", api.GetTask.GetTasks)
-		r.POST("/submit_proof", api.SubmitProof.SubmitProof)
+		r.POST("/get_task", api.Tenants.GetTasks)
+		r.POST("/submit_proof", api.Tenants.SubmitProof)
+		r.POST("/submit_proofs", api.Tenants.SubmitProofs)
+		if conf.ProverManager.TaskPayloads != nil {
+			r.GET("/task_payload/:uuid", api.Tenants.GetTaskPayload)
+		}
 	}
+
+	if conf.Admin != nil && conf.Admin.Token != "" {
+		admin(router, conf.Admin)
+	}
+}
+
+func admin(router *gin.RouterGroup, conf *config.AdminConfig) {
+	r := router.Group("/v1/admin")
+	r.Use(middleware.AdminTokenMiddleware(conf))
+	r.GET("/proof_failures", api.Admin.GetProofFailures)
+	r.POST("/reload_vks", api.Admin.ReloadVKs)
+	r.GET("/snapshot", api.Admin.GetSchedulerSnapshot)
+	r.GET("/batch_dependencies", api.Admin.GetBatchDependencies)
 }
@@ -0,0 +1,265 @@
+// Package testfixture synthesizes chunk and batch tasks and fake proofs of them from template block traces,
+// so that controller unit tests and load tests do not depend on captured mainnet data.
+package testfixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// blockInterval is the timestamp difference between two synthesized blocks, in seconds.
+const blockInterval = 3
+
+// LoadBlockTraces reads template blocks from block trace files, e.g. common/testdata/blockTrace_02.json.
+func LoadBlockTraces(paths ...string) ([]*encoding.Block, error) {
+	blocks := make([]*encoding.Block, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block trace %s: %w", path, err)
+		}
+		block := &encoding.Block{}
+		if err = json.Unmarshal(data, block); err != nil {
+			return nil, fmt.Errorf("failed to decode block trace %s: %w", path, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Generator synthesizes a chain of blocks from template blocks, and groups them into chunks and batches.
+// The synthesized blocks take the transactions of the templates in turn, with sequential numbers and timestamps,
+// linked parent hashes and pseudo-random state and withdraw roots. L1 messages of the templates are dropped,
+// as their queue indexes would not continue across the synthesized blocks.
+//
+// The output only depends on the templates and the seed, so that failures can be reproduced.
+type Generator struct {
+	chainID   uint64
+	templates []*encoding.Block
+	rng       *rand.Rand
+
+	parent          *types.Header // header of the last synthesized block
+	nextTemplate    int
+	stateRoots      map[uint64]common.Hash // state root after each synthesized block, and of the block before the first one
+	nextBatchIndex  uint64
+	parentBatchHash common.Hash
+}
+
+// NewGenerator returns a new Generator synthesizing blocks from the given number on, which must not be the genesis.
+func NewGenerator(chainID uint64, seed int64, startBlockNumber uint64, templates []*encoding.Block) (*Generator, error) {
+	if len(templates) == 0 {
+		return nil, errors.New("no template blocks")
+	}
+	if startBlockNumber == 0 {
+		return nil, errors.New("start block number must be positive")
+	}
+	g := &Generator{
+		chainID:    chainID,
+		templates:  templates,
+		rng:        rand.New(rand.NewSource(seed)), //nolint:gosec
+		stateRoots: make(map[uint64]common.Hash),
+	}
+	g.parent = &types.Header{
+		Number:     new(big.Int).SetUint64(startBlockNumber - 1),
+		Root:       g.randomHash(),
+		Difficulty: big.NewInt(0),
+		Time:       templates[0].Header.Time,
+	}
+	g.stateRoots[g.parent.Number.Uint64()] = g.parent.Root
+	return g, nil
+}
+
+// Blocks synthesizes the next n blocks of the chain.
+func (g *Generator) Blocks(n int) []*encoding.Block {
+	blocks := make([]*encoding.Block, 0, n)
+	for i := 0; i < n; i++ {
+		template := g.templates[g.nextTemplate]
+		g.nextTemplate = (g.nextTemplate + 1) % len(g.templates)
+
+		header := types.CopyHeader(template.Header)
+		header.Number = new(big.Int).Add(g.parent.Number, big.NewInt(1))
+		header.ParentHash = g.parent.Hash()
+		header.Time = g.parent.Time + blockInterval
+		header.Root = g.randomHash()
+
+		block := &encoding.Block{
+			Header:         header,
+			WithdrawRoot:   g.randomHash(),
+			RowConsumption: template.RowConsumption,
+		}
+		for _, tx := range template.Transactions {
+			if tx.Type != types.L1MessageTxType {
+				block.Transactions = append(block.Transactions, tx)
+			}
+		}
+		blocks = append(blocks, block)
+
+		g.parent = header
+		g.stateRoots[header.Number.Uint64()] = header.Root
+	}
+	return blocks
+}
+
+// Chunk synthesizes a chunk of the next numBlocks blocks of the chain.
+func (g *Generator) Chunk(numBlocks int) *encoding.Chunk {
+	return &encoding.Chunk{Blocks: g.Blocks(numBlocks)}
+}
+
+// Batch synthesizes the next batch, of numChunks chunks of blocksPerChunk blocks each.
+func (g *Generator) Batch(numChunks, blocksPerChunk int) (*encoding.Batch, error) {
+	batch := &encoding.Batch{
+		Index:           g.nextBatchIndex,
+		ParentBatchHash: g.parentBatchHash,
+	}
+	for i := 0; i < numChunks; i++ {
+		batch.Chunks = append(batch.Chunks, g.Chunk(blocksPerChunk))
+	}
+	daBatch, err := codecv0.NewDABatch(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DA batch %d: %w", batch.Index, err)
+	}
+	g.nextBatchIndex++
+	g.parentBatchHash = daBatch.Hash()
+	return batch, nil
+}
+
+// ChunkTaskDetail returns the detail of the proving task of a chunk.
+func ChunkTaskDetail(chunk *encoding.Chunk) *message.ChunkTaskDetail {
+	detail := &message.ChunkTaskDetail{}
+	for _, block := range chunk.Blocks {
+		detail.BlockHashes = append(detail.BlockHashes, block.Header.Hash())
+	}
+	return detail
+}
+
+// ChunkInfo returns the public input of the proof of a synthesized chunk.
+func (g *Generator) ChunkInfo(chunk *encoding.Chunk) (*message.ChunkInfo, error) {
+	if len(chunk.Blocks) == 0 {
+		return nil, errors.New("empty chunk")
+	}
+	daChunk, err := codecv0.NewDAChunk(chunk, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DA chunk: %w", err)
+	}
+	dataHash, err := daChunk.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DA chunk hash: %w", err)
+	}
+	var txBytes []byte
+	for _, block := range chunk.Blocks {
+		for _, tx := range block.Transactions {
+			rlpTx, err := encoding.ConvertTxDataToRLPEncoding(tx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode tx %s: %w", tx.TxHash, err)
+			}
+			txBytes = append(txBytes, rlpTx...)
+		}
+	}
+	lastBlock := chunk.Blocks[len(chunk.Blocks)-1]
+	return &message.ChunkInfo{
+		ChainID:       g.chainID,
+		PrevStateRoot: g.stateRoots[chunk.Blocks[0].Header.Number.Uint64()-1],
+		PostStateRoot: lastBlock.Header.Root,
+		WithdrawRoot:  lastBlock.WithdrawRoot,
+		DataHash:      dataHash,
+		TxBytes:       txBytes,
+	}, nil
+}
+
+// ChunkProof returns a fake proof of a synthesized chunk, accepted by the mock verifier.
+func (g *Generator) ChunkProof(chunk *encoding.Chunk) (*message.ChunkProof, error) {
+	chunkInfo, err := g.ChunkInfo(chunk)
+	if err != nil {
+		return nil, err
+	}
+	return &message.ChunkProof{
+		Protocol:  g.randomBytes(32),
+		Proof:     g.randomBytes(256),
+		Instances: g.randomBytes(64),
+		Vk:        g.randomBytes(32),
+		ChunkInfo: chunkInfo,
+	}, nil
+}
+
+// BatchTaskDetail returns the detail of the proving task of a synthesized batch, with fake chunk proofs.
+func (g *Generator) BatchTaskDetail(batch *encoding.Batch) (*message.BatchTaskDetail, error) {
+	detail := &message.BatchTaskDetail{}
+	for _, chunk := range batch.Chunks {
+		proof, err := g.ChunkProof(chunk)
+		if err != nil {
+			return nil, err
+		}
+		detail.ChunkInfos = append(detail.ChunkInfos, proof.ChunkInfo)
+		detail.ChunkProofs = append(detail.ChunkProofs, proof)
+	}
+	return detail, nil
+}
+
+// BatchProof returns a fake batch proof, accepted by the mock verifier.
+func (g *Generator) BatchProof() *message.BatchProof {
+	return &message.BatchProof{
+		Proof:     g.randomBytes(256),
+		Instances: g.randomBytes(64),
+		Vk:        g.randomBytes(32),
+	}
+}
+
+// InsertBatch stores the blocks, chunks and batch of a synthesized batch, linked to each other as the rollup relayer
+// does, so that the chunk and batch tasks can be assigned to provers. Batches must be inserted in order.
+func InsertBatch(ctx context.Context, db *gorm.DB, batch *encoding.Batch) (*orm.Batch, error) {
+	l2BlockOrm := orm.NewL2Block(db)
+	chunkOrm := orm.NewChunk(db)
+	batchOrm := orm.NewBatch(db)
+
+	var startChunkIndex, endChunkIndex uint64
+	for i, chunk := range batch.Chunks {
+		if err := l2BlockOrm.InsertL2Blocks(ctx, chunk.Blocks); err != nil {
+			return nil, err
+		}
+		dbChunk, err := chunkOrm.InsertChunk(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if err = l2BlockOrm.UpdateChunkHashInRange(ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber, dbChunk.Hash); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			startChunkIndex = dbChunk.Index
+		}
+		endChunkIndex = dbChunk.Index
+	}
+	dbBatch, err := batchOrm.InsertBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	if err = chunkOrm.UpdateBatchHashInRange(ctx, startChunkIndex, endChunkIndex, dbBatch.Hash); err != nil {
+		return nil, err
+	}
+	return dbBatch, nil
+}
+
+func (g *Generator) randomHash() common.Hash {
+	return common.BytesToHash(g.randomBytes(common.HashLength))
+}
+
+func (g *Generator) randomBytes(n int) []byte {
+	data := make([]byte, n)
+	g.rng.Read(data)
+	return data
+}
@@ -0,0 +1,90 @@
+package testfixture
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+)
+
+func newTestGenerator(t *testing.T, seed int64) *Generator {
+	templates, err := LoadBlockTraces("../../../common/testdata/blockTrace_02.json", "../../../common/testdata/blockTrace_03.json")
+	assert.NoError(t, err)
+	g, err := NewGenerator(534351, seed, 100, templates)
+	assert.NoError(t, err)
+	return g
+}
+
+func TestGeneratorChain(t *testing.T) {
+	g := newTestGenerator(t, 1)
+
+	batch1, err := g.Batch(2, 3)
+	assert.NoError(t, err)
+	batch2, err := g.Batch(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), batch1.Index)
+	assert.Equal(t, uint64(1), batch2.Index)
+	daBatch1, err := codecv0.NewDABatch(batch1)
+	assert.NoError(t, err)
+	assert.Equal(t, daBatch1.Hash(), batch2.ParentBatchHash)
+
+	// the blocks of all batches form a single chain.
+	var parent *types.Header
+	for _, batch := range []*encoding.Batch{batch1, batch2} {
+		for _, chunk := range batch.Chunks {
+			for _, block := range chunk.Blocks {
+				if parent == nil {
+					assert.Equal(t, uint64(100), block.Header.Number.Uint64())
+				} else {
+					assert.Equal(t, parent.Number.Uint64()+1, block.Header.Number.Uint64())
+					assert.Equal(t, parent.Hash(), block.Header.ParentHash)
+					assert.Equal(t, parent.Time+blockInterval, block.Header.Time)
+				}
+				for _, tx := range block.Transactions {
+					assert.NotEqual(t, uint8(types.L1MessageTxType), tx.Type)
+				}
+				parent = block.Header
+			}
+		}
+	}
+
+	// the same seed synthesizes the same chain.
+	replayed, err := newTestGenerator(t, 1).Batch(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, batch1.Chunks[1].Blocks[2].Header.Hash(), replayed.Chunks[1].Blocks[2].Header.Hash())
+}
+
+func TestGeneratorBatchTaskDetail(t *testing.T) {
+	g := newTestGenerator(t, 2)
+	batch, err := g.Batch(3, 2)
+	assert.NoError(t, err)
+
+	detail, err := g.BatchTaskDetail(batch)
+	assert.NoError(t, err)
+	assert.Len(t, detail.ChunkInfos, 3)
+	assert.Len(t, detail.ChunkProofs, 3)
+	for i, chunkInfo := range detail.ChunkInfos {
+		assert.Equal(t, uint64(534351), chunkInfo.ChainID)
+		assert.Equal(t, chunkInfo, detail.ChunkProofs[i].ChunkInfo)
+		assert.NotEmpty(t, detail.ChunkProofs[i].Proof)
+		lastBlock := batch.Chunks[i].Blocks[1]
+		assert.Equal(t, lastBlock.Header.Root, chunkInfo.PostStateRoot)
+		assert.Equal(t, lastBlock.WithdrawRoot, chunkInfo.WithdrawRoot)
+		daChunk, err := codecv0.NewDAChunk(batch.Chunks[i], 0)
+		assert.NoError(t, err)
+		dataHash, err := daChunk.Hash()
+		assert.NoError(t, err)
+		assert.Equal(t, dataHash, chunkInfo.DataHash)
+		if i > 0 {
+			// the chunks continue from the state of the previous one.
+			assert.Equal(t, detail.ChunkInfos[i-1].PostStateRoot, chunkInfo.PrevStateRoot)
+		}
+	}
+
+	taskDetail := ChunkTaskDetail(batch.Chunks[0])
+	assert.Equal(t, []common.Hash{batch.Chunks[0].Blocks[0].Header.Hash(), batch.Chunks[0].Blocks[1].Header.Hash()}, taskDetail.BlockHashes)
+}
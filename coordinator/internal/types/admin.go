@@ -0,0 +1,133 @@
+package types
+
+// GetProofFailuresParameter for the proof failures admin request parameter
+type GetProofFailuresParameter struct {
+	// Since is the unix timestamp (in seconds) failures are returned from, defaults to one day ago.
+	Since int64 `form:"since" json:"since"`
+	// FailureClass filters the listed failures by failure class, all classes if not set.
+	FailureClass *int16 `form:"failure_class" json:"failure_class"`
+	// Limit is the max number of listed failures, defaults to 100.
+	Limit int `form:"limit" json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// ProofFailureCountSchema the number of proof failures of a failure class, task type and prover version
+type ProofFailureCountSchema struct {
+	FailureClass  string `json:"failure_class"`
+	TaskType      int    `json:"task_type"`
+	ProverVersion string `json:"prover_version"`
+	Count         uint64 `json:"count"`
+}
+
+// ProofFailureSchema a classified proof failure and the block range of its task inputs
+type ProofFailureSchema struct {
+	TaskID           string `json:"task_id"`
+	TaskType         int    `json:"task_type"`
+	ProverTaskUUID   string `json:"prover_task_uuid"`
+	ProverName       string `json:"prover_name"`
+	ProverPublicKey  string `json:"prover_public_key"`
+	ProverVersion    string `json:"prover_version"`
+	FailureType      string `json:"failure_type"`
+	FailureClass     string `json:"failure_class"`
+	FailureMsg       string `json:"failure_msg"`
+	StartBlockNumber uint64 `json:"start_block_number"`
+	EndBlockNumber   uint64 `json:"end_block_number"`
+	HardForkName     string `json:"hard_fork_name"`
+	CreatedAt        int64  `json:"created_at"`
+}
+
+// GetProofFailuresSchema the schema data of the proof failures admin request
+type GetProofFailuresSchema struct {
+	Breakdown []*ProofFailureCountSchema `json:"breakdown"`
+	Failures  []*ProofFailureSchema      `json:"failures"`
+}
+
+// ReloadVKsSchema the schema data of the reload verifying keys admin request
+type ReloadVKsSchema struct {
+	Forks []string `json:"forks"`
+}
+
+// GetSchedulerSnapshotParameter for the scheduler snapshot admin request parameter
+type GetSchedulerSnapshotParameter struct {
+	// Limit is the max number of listed pending chunks, pending batches and assignments each, defaults to 1000.
+	Limit int `form:"limit" json:"limit" binding:"omitempty,min=1,max=10000"`
+}
+
+// PendingTaskSchema a chunk or batch which is not proven yet
+type PendingTaskSchema struct {
+	TaskID         string `json:"task_id"`
+	Index          uint64 `json:"index"`
+	ProvingStatus  string `json:"proving_status"`
+	ActiveAttempts int16  `json:"active_attempts"`
+	TotalAttempts  int16  `json:"total_attempts"`
+	// AgeSec is the time (in seconds) since the chunk or batch was created.
+	AgeSec int64 `json:"age_sec"`
+}
+
+// AssignmentSchema a task assigned to a prover which has not submitted its proof yet
+type AssignmentSchema struct {
+	UUID            string `json:"uuid"`
+	TaskID          string `json:"task_id"`
+	TaskType        int    `json:"task_type"`
+	ProverName      string `json:"prover_name"`
+	ProverPublicKey string `json:"prover_public_key"`
+	ProverVersion   string `json:"prover_version"`
+	AssignedAt      int64  `json:"assigned_at"`
+	// AgeSec is the time (in seconds) since the task was assigned.
+	AgeSec   int64 `json:"age_sec"`
+	Deadline int64 `json:"deadline,omitempty"`
+}
+
+// ProverSessionSchema a login session of a prover, without its secrets
+type ProverSessionSchema struct {
+	PublicKey     string `json:"public_key"`
+	ProverName    string `json:"prover_name"`
+	ProverVersion string `json:"prover_version"`
+	ExpiredAt     int64  `json:"expired_at"`
+	// TaskDataEncryption is whether the task data sent to the prover is encrypted with a negotiated key.
+	TaskDataEncryption bool `json:"task_data_encryption"`
+}
+
+// SchedulerSnapshotSchema the schema data of the scheduler snapshot admin request, the scheduler state at TakenAt
+type SchedulerSnapshotSchema struct {
+	TakenAt        int64                  `json:"taken_at"`
+	PendingChunks  []*PendingTaskSchema   `json:"pending_chunks"`
+	PendingBatches []*PendingTaskSchema   `json:"pending_batches"`
+	Assignments    []*AssignmentSchema    `json:"assignments"`
+	Sessions       []*ProverSessionSchema `json:"sessions"`
+}
+
+// GetBatchDependenciesParameter for the batch dependencies admin request parameter
+type GetBatchDependenciesParameter struct {
+	// BatchHash is the batch whose dependencies are returned, the batches waiting for chunk proofs are listed if not set.
+	BatchHash string `form:"batch_hash" json:"batch_hash"`
+	// Limit is the max number of listed batches, defaults to 100.
+	Limit int `form:"limit" json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// BatchDependencySchema a chunk proof a batch task depends on
+type BatchDependencySchema struct {
+	ChunkHash  string `json:"chunk_hash"`
+	ChunkIndex uint64 `json:"chunk_index"`
+	Verified   bool   `json:"verified"`
+	VerifiedAt int64  `json:"verified_at,omitempty"`
+}
+
+// BlockedBatchSchema a batch task waiting for chunk proofs
+type BlockedBatchSchema struct {
+	BatchHash        string `json:"batch_hash"`
+	BatchIndex       uint64 `json:"batch_index"`
+	Dependencies     int64  `json:"dependencies"`
+	UnverifiedChunks int64  `json:"unverified_chunks"`
+	// LowestBlockedChunk is the index of the first chunk whose proof is not verified yet.
+	LowestBlockedChunk uint64 `json:"lowest_blocked_chunk"`
+	// BlockedSec is the time (in seconds) since the dependencies of the batch were recorded.
+	BlockedSec int64 `json:"blocked_sec"`
+}
+
+// BatchDependenciesSchema the schema data of the batch dependencies admin request
+type BatchDependenciesSchema struct {
+	// Dependencies are the chunk proofs the batch of the request depends on, in ascending order by chunk index.
+	Dependencies []*BatchDependencySchema `json:"dependencies,omitempty"`
+	// BlockedBatches are the batches waiting for chunk proofs, in ascending order by index, without a batch hash.
+	BlockedBatches []*BlockedBatchSchema `json:"blocked_batches,omitempty"`
+}
@@ -9,6 +9,16 @@ const (
 	ProverName = "prover_name"
 	// ProverVersion the prover version for context
 	ProverVersion = "prover_version"
+	// ChallengeNonce the challenge nonce key of the challenge token claims
+	ChallengeNonce = "random"
+	// SessionID the prover session id key of the login token claims
+	SessionID = "session_id"
+	// SessionPublicKey the coordinator session public key for context, replied at login
+	SessionPublicKey = "session_public_key"
+	// TaskDataKey the task data encryption key of the prover session for context
+	TaskDataKey = "task_data_key"
+	// Tenant the tenant of the prover for context and the login token claims, empty for the default tenant
+	Tenant = "tenant"
 )
 
 // Message the login message struct
@@ -16,6 +26,8 @@ type Message struct {
 	Challenge     string `form:"challenge" json:"challenge" binding:"required"`
 	ProverVersion string `form:"prover_version" json:"prover_version" binding:"required"`
 	ProverName    string `form:"prover_name" json:"prover_name" binding:"required"`
+	// SessionPublicKey is the compressed ephemeral public key of the prover, from which the task data key is derived.
+	SessionPublicKey string `form:"session_public_key" json:"session_public_key"`
 }
 
 // LoginParameter for /login api
@@ -28,4 +40,6 @@ type LoginParameter struct {
 type LoginSchema struct {
 	Time  time.Time `json:"time"`
 	Token string    `json:"token"`
+	// SessionPublicKey is the compressed ephemeral public key of the coordinator, set if the task data is encrypted.
+	SessionPublicKey string `json:"session_public_key,omitempty"`
 }
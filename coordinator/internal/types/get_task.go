@@ -6,12 +6,20 @@ type GetTaskParameter struct {
 	ProverHeight uint64 `form:"prover_height" json:"prover_height"`
 	TaskType     int    `form:"task_type" json:"task_type"`
 	VK           string `form:"vk" json:"vk"`
+	Prefetch     bool   `form:"prefetch" json:"prefetch,omitempty"` // reserve the next task while proving the assigned one
 }
 
 // GetTaskSchema the schema data return to prover for get prover task
 type GetTaskSchema struct {
-	UUID     string `json:"uuid"`
-	TaskID   string `json:"task_id"`
-	TaskType int    `json:"task_type"`
-	TaskData string `json:"task_data"`
+	UUID          string `json:"uuid"`
+	TaskID        string `json:"task_id"`
+	TaskType      int    `json:"task_type"`
+	TaskData      string `json:"task_data"`
+	HardForkName  string `json:"hard_fork_name"`
+	Deadline      int64  `json:"deadline,omitempty"`       // unix timestamp (in seconds) by which the proof must be submitted
+	TargetTime    int64  `json:"target_time,omitempty"`    // unix timestamp (in seconds) by which the proof meets the finalization target
+	Encrypted     bool   `json:"encrypted,omitempty"`      // whether the task data is encrypted with the key of the prover session
+	ReservedUntil int64  `json:"reserved_until,omitempty"` // unix timestamp (in seconds) until which a prefetched task is reserved, get_task assigns it
+	PayloadSize   int64  `json:"payload_size,omitempty"`   // size of the task data left out to be downloaded from task_payload, 0 if sent inline
+	PayloadSHA256 string `json:"payload_sha256,omitempty"` // hex sha256 of the task data to download, the ETag of the download
 }
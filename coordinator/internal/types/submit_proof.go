@@ -10,4 +10,33 @@ type SubmitProofParameter struct {
 	Proof       string `form:"proof" json:"proof"`
 	FailureType int    `form:"failure_type" json:"failure_type"`
 	FailureMsg  string `form:"failure_msg" json:"failure_msg"`
+	// ProvingTimeMs is the time the prover spent proving the task, optional as older provers do not report it.
+	ProvingTimeMs uint64 `form:"proving_time_ms" json:"proving_time_ms"`
+}
+
+// Modes of the SubmitProofs api.
+const (
+	// SubmitModePartial accepts the valid proofs of the submission whatever the result of the others.
+	SubmitModePartial = "partial"
+	// SubmitModeAllOrNothing only accepts the proofs of the submission if all of them are valid.
+	SubmitModeAllOrNothing = "all_or_nothing"
+)
+
+// SubmitProofsParameter the SubmitProofs api request parameter, submitting several proofs at once
+type SubmitProofsParameter struct {
+	Mode   string                 `form:"mode" json:"mode" binding:"omitempty,oneof=partial all_or_nothing"` // defaults to partial
+	Proofs []SubmitProofParameter `form:"proofs" json:"proofs" binding:"required,min=1,max=32,dive"`
+}
+
+// SubmitProofResult the result of a proof of the SubmitProofs api, in the order of the submitted proofs
+type SubmitProofResult struct {
+	UUID    string `json:"uuid"`
+	TaskID  string `json:"task_id"`
+	ErrCode int    `json:"errcode"` // the error code submit_proof would return for the proof, 0 if it is accepted
+	ErrMsg  string `json:"errmsg,omitempty"`
+}
+
+// SubmitProofsSchema the schema data return to prover for submit proofs
+type SubmitProofsSchema struct {
+	Results []*SubmitProofResult `json:"results"`
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"testing"
@@ -21,6 +22,7 @@ import (
 
 	"scroll-tech/database/migrate"
 
+	"scroll-tech/common/clock"
 	"scroll-tech/common/database"
 	"scroll-tech/common/docker"
 	"scroll-tech/common/types"
@@ -33,6 +35,7 @@ import (
 	"scroll-tech/coordinator/internal/controller/cron"
 	"scroll-tech/coordinator/internal/orm"
 	"scroll-tech/coordinator/internal/route"
+	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
 const (
@@ -84,7 +87,12 @@ func randomURL() string {
 	return fmt.Sprintf("localhost:%d", 10000+2000+id.Int64())
 }
 
-func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL string, nameForkMap map[string]int64) (*cron.Collector, *http.Server) {
+func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL string, nameForkMap map[string]int64, tenants ...*config.TenantConfig) (*cron.Collector, *http.Server) {
+	return setupCoordinatorWithClock(t, clock.New(), proversPerSession, coordinatorURL, nameForkMap, tenants...)
+}
+
+// setupCoordinatorWithClock is setupCoordinator with the cron collector scheduled on clk.
+func setupCoordinatorWithClock(t *testing.T, clk clock.Clock, proversPerSession uint8, coordinatorURL string, nameForkMap map[string]int64, tenants ...*config.TenantConfig) (*cron.Collector, *http.Server) {
 	var err error
 	db, err = database.InitDB(dbCfg)
 	assert.NoError(t, err)
@@ -110,6 +118,7 @@ func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL stri
 			ChallengeExpireDurationSec: tokenTimeout,
 			LoginExpireDurationSec:     tokenTimeout,
 		},
+		Tenants: tenants,
 	}
 
 	var chainConf params.ChainConfig
@@ -128,10 +137,16 @@ func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL stri
 		}
 	}
 
-	proofCollector := cron.NewCollector(context.Background(), db, conf, nil)
+	proofCollector := cron.NewCollector(context.Background(), db, conf, clk, nil)
 
 	router := gin.New()
 	api.InitController(conf, &chainConf, db, nil)
+	tenantDBs := make(map[string]*gorm.DB)
+	for _, tenant := range tenants {
+		tenantDBs[tenant.Name], err = database.InitDB(tenant.DB)
+		assert.NoError(t, err)
+	}
+	api.InitTenants(conf, &chainConf, tenantDBs, nil)
 	route.Route(router, conf, nil)
 	srv := &http.Server{
 		Addr:    coordinatorURL,
@@ -208,9 +223,11 @@ func TestApis(t *testing.T) {
 	t.Run("TestOutdatedProverVersion", testOutdatedProverVersion)
 	t.Run("TestValidProof", testValidProof)
 	t.Run("TestInvalidProof", testInvalidProof)
+	t.Run("TestSubmitProofs", testSubmitProofs)
 	t.Run("TestProofGeneratedFailed", testProofGeneratedFailed)
 	t.Run("TestTimeoutProof", testTimeoutProof)
 	t.Run("TestHardFork", testHardForkAssignTask)
+	t.Run("TestTenantIsolation", testTenantIsolation)
 
 	// Teardown
 	t.Cleanup(func() {
@@ -274,7 +291,7 @@ func testGetTaskBlocked(t *testing.T) {
 
 	expectedErr = fmt.Errorf("get empty prover task")
 	code, errMsg = batchProver.tryGetProverTask(t, message.ProofTypeBatch)
-	assert.Equal(t, types.ErrCoordinatorEmptyProofData, code)
+	assert.Equal(t, types.ErrCoordinatorNoTaskAvailable, code)
 	assert.Equal(t, expectedErr, fmt.Errorf(errMsg))
 
 	err = proverBlockListOrm.InsertProverPublicKey(context.Background(), batchProver.proverName, batchProver.publicKey())
@@ -285,7 +302,7 @@ func testGetTaskBlocked(t *testing.T) {
 
 	expectedErr = fmt.Errorf("get empty prover task")
 	code, errMsg = chunkProver.tryGetProverTask(t, message.ProofTypeChunk)
-	assert.Equal(t, types.ErrCoordinatorEmptyProofData, code)
+	assert.Equal(t, types.ErrCoordinatorNoTaskAvailable, code)
 	assert.Equal(t, expectedErr, fmt.Errorf(errMsg))
 
 	expectedErr = fmt.Errorf("return prover task err:check prover task parameter failed, error:public key %s is blocked from fetching tasks. ProverName: %s, ProverVersion: %s", batchProver.publicKey(), batchProver.proverName, batchProver.proverVersion)
@@ -308,14 +325,17 @@ func testOutdatedProverVersion(t *testing.T) {
 	batchProver := newMockProver(t, "prover_batch_test", coordinatorURL, message.ProofTypeBatch, "v1.999.999")
 	assert.True(t, chunkProver.healthCheckSuccess(t))
 
-	expectedErr := fmt.Errorf("return prover task err:check prover task parameter failed, error:incompatible prover version. please upgrade your prover, minimum allowed version: %s, actual version: %s", version.Version, chunkProver.proverVersion)
-	code, errMsg := chunkProver.tryGetProverTask(t, message.ProofTypeChunk)
-	assert.Equal(t, types.ErrCoordinatorGetTaskFailure, code)
+	// outdated provers are rejected at login.
+	expectedErr := fmt.Errorf("incompatible prover version. please upgrade your prover, minimum allowed version: %s, actual version: %s", version.Version, chunkProver.proverVersion)
+	token, code, errMsg := chunkProver.tryLogin(t, chunkProver.challenge(t))
+	assert.Empty(t, token)
+	assert.Equal(t, types.ErrCoordinatorProverVersionTooLow, code)
 	assert.Equal(t, expectedErr, fmt.Errorf(errMsg))
 
-	expectedErr = fmt.Errorf("return prover task err:check prover task parameter failed, error:incompatible prover version. please upgrade your prover, minimum allowed version: %s, actual version: %s", version.Version, batchProver.proverVersion)
-	code, errMsg = batchProver.tryGetProverTask(t, message.ProofTypeBatch)
-	assert.Equal(t, types.ErrCoordinatorGetTaskFailure, code)
+	expectedErr = fmt.Errorf("incompatible prover version. please upgrade your prover, minimum allowed version: %s, actual version: %s", version.Version, batchProver.proverVersion)
+	token, code, errMsg = batchProver.tryLogin(t, batchProver.challenge(t))
+	assert.Empty(t, token)
+	assert.Equal(t, types.ErrCoordinatorProverVersionTooLow, code)
 	assert.Equal(t, expectedErr, fmt.Errorf(errMsg))
 }
 
@@ -335,7 +355,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"bernoulli": forkNumberFour},
 			exceptTaskNumber:      0,
 			proverForkNames:       []string{"bernoulli", "bernoulli"},
-			exceptGetTaskErrCodes: []int{types.ErrCoordinatorEmptyProofData, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.ErrCoordinatorNoTaskAvailable, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"get empty prover task", "get empty prover task"},
 		},
 		{
@@ -344,7 +364,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"bernoulli": forkNumberFour},
 			exceptTaskNumber:      0,
 			proverForkNames:       []string{"bernoulli", "bernoulli"},
-			exceptGetTaskErrCodes: []int{types.ErrCoordinatorEmptyProofData, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.ErrCoordinatorNoTaskAvailable, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"get empty prover task", "get empty prover task"},
 		},
 		{ // hard fork 1, prover 1 block [2-3]
@@ -353,7 +373,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"istanbul": forkNumberTwo, "homestead": forkNumberOne},
 			exceptTaskNumber:      0,
 			proverForkNames:       []string{"homestead", "homestead"},
-			exceptGetTaskErrCodes: []int{types.ErrCoordinatorEmptyProofData, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.ErrCoordinatorNoTaskAvailable, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"get empty prover task", "get empty prover task"},
 		},
 		{
@@ -362,7 +382,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"istanbul": forkNumberTwo, "homestead": forkNumberOne},
 			exceptTaskNumber:      0,
 			proverForkNames:       []string{"homestead", "homestead"},
-			exceptGetTaskErrCodes: []int{types.ErrCoordinatorEmptyProofData, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.ErrCoordinatorNoTaskAvailable, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"get empty prover task", "get empty prover task"},
 		},
 		{
@@ -371,7 +391,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"istanbul": forkNumberTwo, "london": forkNumberThree},
 			exceptTaskNumber:      0,
 			proverForkNames:       []string{"", ""},
-			exceptGetTaskErrCodes: []int{types.ErrCoordinatorEmptyProofData, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.ErrCoordinatorNoTaskAvailable, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"get empty prover task", "get empty prover task"},
 		},
 		{ // hard fork 3, prover 3 block [2-3]
@@ -380,7 +400,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"london": forkNumberThree},
 			exceptTaskNumber:      1,
 			proverForkNames:       []string{"london", "london"},
-			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"", "get empty prover task"},
 		},
 		{
@@ -389,7 +409,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"london": forkNumberThree},
 			exceptTaskNumber:      1,
 			proverForkNames:       []string{"london", "london"},
-			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"", "get empty prover task"},
 		},
 		{ // hard fork 2, prover 2 block [2-3]
@@ -398,7 +418,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"istanbul": forkNumberTwo, "london": forkNumberThree},
 			exceptTaskNumber:      1,
 			proverForkNames:       []string{"istanbul", "istanbul"},
-			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"", "get empty prover task"},
 		},
 		{
@@ -407,7 +427,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"istanbul": forkNumberTwo, "london": forkNumberThree},
 			exceptTaskNumber:      1,
 			proverForkNames:       []string{"istanbul", "istanbul"},
-			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"", "get empty prover task"},
 		},
 		{ // hard fork 2, prover 2 block [2-3]
@@ -479,7 +499,7 @@ func testHardForkAssignTask(t *testing.T) {
 			forkNumbers:           map[string]int64{"london": forkNumberThree},
 			exceptTaskNumber:      1,
 			proverForkNames:       []string{"", ""},
-			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorEmptyProofData},
+			exceptGetTaskErrCodes: []int{types.Success, types.ErrCoordinatorNoTaskAvailable},
 			exceptGetTaskErrMsgs:  []string{"", "get empty prover task"},
 		},
 	}
@@ -667,7 +687,7 @@ func testInvalidProof(t *testing.T) {
 		assert.NotNil(t, proverTask)
 		assert.Equal(t, errCode, types.Success)
 		assert.Equal(t, errMsg, "")
-		provers[i].submitProof(t, proverTask, verifiedFailed, types.ErrCoordinatorHandleZkProofFailure)
+		provers[i].submitProof(t, proverTask, verifiedFailed, types.ErrCoordinatorProofInvalid)
 	}
 
 	// verify proof status
@@ -711,6 +731,53 @@ func testInvalidProof(t *testing.T) {
 	}
 }
 
+func testSubmitProofs(t *testing.T) {
+	// Setup coordinator and ws server.
+	coordinatorURL := randomURL()
+	collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+	conf.ProverManager.MaxAssignedTasksPerProver = 2
+
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, dbChunk.Hash)
+	assert.NoError(t, err)
+	batch, err := batchOrm.InsertBatch(context.Background(), batch)
+	assert.NoError(t, err)
+	err = batchOrm.UpdateChunkProofsStatusByBatchHash(context.Background(), batch.Hash, types.ChunkProofsStatusReady)
+	assert.NoError(t, err)
+
+	prover := newMockProver(t, "prover_test", coordinatorURL, message.ProofTypeChunk, version.Version)
+	chunkTask, errCode, errMsg := prover.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Empty(t, errMsg)
+	batchTask, errCode, errMsg := prover.getProverTask(t, message.ProofTypeBatch, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Empty(t, errMsg)
+
+	// the valid chunk proof is not accepted as the batch proof of the same submission is invalid.
+	errCodes := prover.submitProofs(t, []*coordinatorType.GetTaskSchema{chunkTask, batchTask}, []proofStatus{verifiedSuccess, verifiedFailed}, coordinatorType.SubmitModeAllOrNothing)
+	assert.Equal(t, []int{types.ErrCoordinatorProofNotAccepted, types.ErrCoordinatorProofInvalid}, errCodes)
+	chunkProvingStatus, err := proverTaskOrm.GetProvingStatusByTaskID(context.Background(), message.ProofTypeChunk, dbChunk.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProverAssigned, chunkProvingStatus)
+	batchProvingStatus, err := proverTaskOrm.GetProvingStatusByTaskID(context.Background(), message.ProofTypeBatch, batch.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProverProofInvalid, batchProvingStatus)
+
+	// submitted again, the chunk proof is accepted.
+	errCodes = prover.submitProofs(t, []*coordinatorType.GetTaskSchema{chunkTask}, []proofStatus{verifiedSuccess}, coordinatorType.SubmitModePartial)
+	assert.Equal(t, []int{types.Success}, errCodes)
+	chunkProvingStatus, err = proverTaskOrm.GetProvingStatusByTaskID(context.Background(), message.ProofTypeChunk, dbChunk.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProverProofValid, chunkProvingStatus)
+}
+
 func testProofGeneratedFailed(t *testing.T) {
 	// Setup coordinator and ws server.
 	coordinatorURL := randomURL()
@@ -801,9 +868,10 @@ func testProofGeneratedFailed(t *testing.T) {
 }
 
 func testTimeoutProof(t *testing.T) {
-	// Setup coordinator and ws server.
+	// Setup coordinator and ws server, the timeouts are checked on a fake clock.
 	coordinatorURL := randomURL()
-	collector, httpHandler := setupCoordinator(t, 1, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+	clk := clock.NewFake(time.Now())
+	collector, httpHandler := setupCoordinatorWithClock(t, clk, 1, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
 	defer func() {
 		collector.Stop()
 		assert.NoError(t, httpHandler.Shutdown(context.Background()))
@@ -859,8 +927,16 @@ func testTimeoutProof(t *testing.T) {
 	assert.Equal(t, 1, int(batchMaxAttempts))
 	assert.Equal(t, 1, int(batchActiveAttempts))
 
-	// wait coordinator to reset the prover task proving status
-	time.Sleep(time.Duration(conf.ProverManager.BatchCollectionTimeSec*2) * time.Second)
+	// move past the collection time, and wait coordinator to reset the prover task proving status
+	clk.Advance(time.Duration(conf.ProverManager.BatchCollectionTimeSec*2) * time.Second)
+	assert.Eventually(t, func() bool {
+		chunkActiveAttempts, _, err = chunkOrm.GetAttemptsByHash(context.Background(), dbChunk.Hash)
+		if err != nil || chunkActiveAttempts != 0 {
+			return false
+		}
+		batchActiveAttempts, _, err = batchOrm.GetAttemptsByHash(context.Background(), batch.Hash)
+		return err == nil && batchActiveAttempts == 0
+	}, 5*time.Second, 100*time.Millisecond)
 
 	// create second mock prover, that will send valid proof.
 	chunkProver2 := newMockProver(t, "prover_test"+strconv.Itoa(2), coordinatorURL, message.ProofTypeChunk, version.Version)
@@ -896,3 +972,106 @@ func testTimeoutProof(t *testing.T) {
 	assert.Equal(t, 2, int(batchMaxAttempts))
 	assert.Equal(t, 0, int(batchActiveAttempts))
 }
+
+// setupTenantDB creates the database of a tenant next to the one of the default tenant, with the schema migrated.
+func setupTenantDB(t *testing.T, name string) (*database.Config, *gorm.DB) {
+	var exists bool
+	assert.NoError(t, db.Raw("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = ?)", name).Scan(&exists).Error)
+	if !exists {
+		assert.NoError(t, db.Exec("CREATE DATABASE "+name).Error)
+	}
+	dsn, err := url.Parse(dbCfg.DSN)
+	assert.NoError(t, err)
+	dsn.Path = "/" + name
+	tenantDBCfg := &database.Config{
+		DSN:        dsn.String(),
+		DriverName: dbCfg.DriverName,
+		MaxOpenNum: dbCfg.MaxOpenNum,
+		MaxIdleNum: dbCfg.MaxIdleNum,
+	}
+	tenantDB, err := database.InitDB(tenantDBCfg)
+	assert.NoError(t, err)
+	sqlDB, err := tenantDB.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+	return tenantDBCfg, tenantDB
+}
+
+func testTenantIsolation(t *testing.T) {
+	coordinatorURL := randomURL()
+	stagingDBCfg, stagingDB := setupTenantDB(t, "coordinator_tenant_staging")
+	defer func() {
+		assert.NoError(t, database.CloseDB(stagingDB))
+	}()
+	stagingProver := newMockProver(t, "prover_staging", coordinatorURL, message.ProofTypeChunk, version.Version)
+	defaultProver := newMockProver(t, "prover_default", coordinatorURL, message.ProofTypeChunk, version.Version)
+	staging := &config.TenantConfig{
+		Name:             "staging",
+		ProverPublicKeys: []string{stagingProver.publicKey()},
+		DB:               stagingDBCfg,
+	}
+	collector, httpHandler := setupCoordinator(t, 1, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo}, staging)
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+
+	// the chunk of the default tenant is not assigned to the provers of the staging tenant.
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, dbChunk.Hash)
+	assert.NoError(t, err)
+
+	_, errCode, _ := stagingProver.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.ErrCoordinatorNoTaskAvailable, errCode)
+	defaultTask, errCode, errMsg := defaultProver.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Empty(t, errMsg)
+	assert.Equal(t, dbChunk.Hash, defaultTask.TaskID)
+
+	// the provers of the staging tenant can not submit proofs for the tasks of the default tenant.
+	stagingProver.submitProof(t, defaultTask, verifiedSuccess, types.ErrCoordinatorHandleZkProofFailure)
+
+	// the same chunk in the db of the staging tenant is assigned to its provers only.
+	stagingL2BlockOrm, stagingChunkOrm := orm.NewL2Block(stagingDB), orm.NewChunk(stagingDB)
+	err = stagingL2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	stagingChunk, err := stagingChunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = stagingL2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, stagingChunk.Hash)
+	assert.NoError(t, err)
+
+	otherDefaultProver := newMockProver(t, "prover_default_other", coordinatorURL, message.ProofTypeChunk, version.Version)
+	_, errCode, _ = otherDefaultProver.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.ErrCoordinatorNoTaskAvailable, errCode)
+	stagingTask, errCode, errMsg := stagingProver.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Empty(t, errMsg)
+	assert.Equal(t, stagingChunk.Hash, stagingTask.TaskID)
+	stagingProver.submitProof(t, stagingTask, verifiedSuccess, types.Success)
+
+	var (
+		tick     = time.Tick(1500 * time.Millisecond)
+		tickStop = time.Tick(time.Minute)
+	)
+	for {
+		select {
+		case <-tick:
+			stagingStatus, statusErr := stagingChunkOrm.GetProvingStatusByHash(context.Background(), stagingChunk.Hash)
+			assert.NoError(t, statusErr)
+			if stagingStatus != types.ProvingTaskVerified {
+				continue
+			}
+			// the proof of the staging tenant does not prove the chunk of the default tenant.
+			defaultStatus, statusErr := chunkOrm.GetProvingStatusByHash(context.Background(), dbChunk.Hash)
+			assert.NoError(t, statusErr)
+			assert.Equal(t, types.ProvingTaskAssigned, defaultStatus)
+			return
+		case <-tickStop:
+			t.Error("failed to check the proof status of the staging tenant")
+			return
+		}
+	}
+}
@@ -77,6 +77,14 @@ func (r *mockProver) challenge(t *testing.T) string {
 }
 
 func (r *mockProver) login(t *testing.T, challengeString string) string {
+	token, errCode, errMsg := r.tryLogin(t, challengeString)
+	assert.Equal(t, ctypes.Success, errCode)
+	assert.Empty(t, errMsg)
+	return token
+}
+
+// Testing expected login errors returned by coordinator.
+func (r *mockProver) tryLogin(t *testing.T, challengeString string) (string, int, string) {
 	authMsg := message.AuthMsg{
 		Identity: &message.Identity{
 			Challenge:     challengeString,
@@ -107,8 +115,7 @@ func (r *mockProver) login(t *testing.T, challengeString string) string {
 	err = mapstructure.Decode(result.Data, &loginData)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode())
-	assert.Empty(t, result.ErrMsg)
-	return loginData.Token
+	return loginData.Token, result.ErrCode, result.ErrMsg
 }
 
 func (r *mockProver) healthCheckSuccess(t *testing.T) bool {
@@ -186,6 +193,65 @@ func (r *mockProver) tryGetProverTask(t *testing.T, proofType message.ProofType)
 }
 
 func (r *mockProver) submitProof(t *testing.T, proverTaskSchema *types.GetTaskSchema, proofStatus proofStatus, errCode int) {
+	submitProof := r.proofParameter(t, proverTaskSchema, proofStatus)
+
+	token := r.connectToCoordinator(t)
+	assert.NotEmpty(t, token)
+
+	submitProofData, err := json.Marshal(submitProof)
+	assert.NoError(t, err)
+	assert.NotNil(t, submitProofData)
+
+	var result ctypes.Response
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Authorization", fmt.Sprintf("Bearer %s", token)).
+		SetBody(string(submitProofData)).
+		SetResult(&result).
+		Post("http://" + r.coordinatorURL + "/coordinator/v1/submit_proof")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, errCode, result.ErrCode)
+}
+
+// submitProofs submits the proofs of the tasks at once in the mode, and returns the error code of each of them.
+func (r *mockProver) submitProofs(t *testing.T, proverTaskSchemas []*types.GetTaskSchema, proofStatuses []proofStatus, mode string) []int {
+	submitProofs := types.SubmitProofsParameter{Mode: mode}
+	for i, proverTaskSchema := range proverTaskSchemas {
+		submitProofs.Proofs = append(submitProofs.Proofs, r.proofParameter(t, proverTaskSchema, proofStatuses[i]))
+	}
+
+	token := r.connectToCoordinator(t)
+	assert.NotEmpty(t, token)
+
+	var result ctypes.Response
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Authorization", fmt.Sprintf("Bearer %s", token)).
+		SetBody(submitProofs).
+		SetResult(&result).
+		Post("http://" + r.coordinatorURL + "/coordinator/v1/submit_proofs")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, ctypes.Success, result.ErrCode)
+
+	resultData, err := json.Marshal(result.Data)
+	assert.NoError(t, err)
+	var schema types.SubmitProofsSchema
+	assert.NoError(t, json.Unmarshal(resultData, &schema))
+
+	errCodes := make([]int, len(schema.Results))
+	for i, proofResult := range schema.Results {
+		assert.Equal(t, proverTaskSchemas[i].TaskID, proofResult.TaskID)
+		errCodes[i] = proofResult.ErrCode
+	}
+	return errCodes
+}
+
+// proofParameter returns the submit proof parameter of a proof of the task with the status.
+func (r *mockProver) proofParameter(t *testing.T, proverTaskSchema *types.GetTaskSchema, proofStatus proofStatus) types.SubmitProofParameter {
 	proofMsgStatus := message.StatusOk
 	if proofStatus == generatedFailed {
 		proofMsgStatus = message.StatusProofError
@@ -227,25 +293,7 @@ func (r *mockProver) submitProof(t *testing.T, proverTaskSchema *types.GetTaskSc
 		assert.NotEmpty(t, encodeData)
 		submitProof.Proof = string(encodeData)
 	}
-
-	token := r.connectToCoordinator(t)
-	assert.NotEmpty(t, token)
-
-	submitProofData, err := json.Marshal(submitProof)
-	assert.NoError(t, err)
-	assert.NotNil(t, submitProofData)
-
-	var result ctypes.Response
-	client := resty.New()
-	resp, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", fmt.Sprintf("Bearer %s", token)).
-		SetBody(string(submitProofData)).
-		SetResult(&result).
-		Post("http://" + r.coordinatorURL + "/coordinator/v1/submit_proof")
-	assert.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode())
-	assert.Equal(t, errCode, result.ErrCode)
+	return submitProof
 }
 
 func (r *mockProver) publicKey() string {
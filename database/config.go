@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"scroll-tech/common/secrets"
 )
 
 // DBConfig db config
@@ -22,6 +24,10 @@ func NewConfig(file string) (*DBConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	buf, err = secrets.ResolveJSON(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &DBConfig{}
 	err = json.Unmarshal(buf, cfg)
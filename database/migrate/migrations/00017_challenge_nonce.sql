@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- challenge now stores the nonce of an issued challenge, which can be used once at login before it expires.
+ALTER TABLE challenge
+    ADD COLUMN expired_at TIMESTAMP(0) DEFAULT NULL,
+    ADD COLUMN used_at    TIMESTAMP(0) DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE challenge
+    DROP COLUMN IF EXISTS expired_at,
+    DROP COLUMN IF EXISTS used_at;
+-- +goose StatementEnd
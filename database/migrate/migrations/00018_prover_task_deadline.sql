@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- deadline is the time by which the prover must submit the proof of the task, proofs submitted later are rejected.
+ALTER TABLE prover_task
+    ADD COLUMN deadline TIMESTAMP(0) DEFAULT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prover_task
+    DROP COLUMN IF EXISTS deadline;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l1_message
+    ADD COLUMN skip_reason                SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN replay_count               SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN replay_tx_hash             VARCHAR  DEFAULT NULL,
+    ADD COLUMN replay_gas_limit           BIGINT   DEFAULT NULL,
+    ADD COLUMN replay_requested_gas_limit BIGINT   DEFAULT NULL;
+
+comment
+on column l1_message.skip_reason is 'undefined, gas_limit_exceeded, circuit_capacity, not_replayable';
+
+comment
+on column l1_message.replay_requested_gas_limit is 'gas limit of a replay forced by an operator, replayed regardless of the replay policy';
+
+create index l1_message_status_index
+on l1_message (status) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists l1_message_status_index;
+
+ALTER TABLE l1_message
+    DROP COLUMN IF EXISTS skip_reason,
+    DROP COLUMN IF EXISTS replay_count,
+    DROP COLUMN IF EXISTS replay_tx_hash,
+    DROP COLUMN IF EXISTS replay_gas_limit,
+    DROP COLUMN IF EXISTS replay_requested_gas_limit;
+-- +goose StatementEnd
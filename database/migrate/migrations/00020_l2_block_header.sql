@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table l2_block_header
+(
+-- block
+    number                  BIGINT          NOT NULL,
+    hash                    VARCHAR         NOT NULL,
+    parent_hash             VARCHAR         NOT NULL,
+    base_fee                BIGINT          NOT NULL DEFAULT 0,
+    gas_used                BIGINT          NOT NULL,
+    gas_limit               BIGINT          NOT NULL,
+    tx_num                  INTEGER         NOT NULL,
+    block_timestamp         NUMERIC         NOT NULL,
+
+-- metadata
+    created_at              TIMESTAMP(0)    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TIMESTAMP(0)    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at              TIMESTAMP(0)    DEFAULT NULL
+);
+
+comment
+on column l2_block_header.base_fee is '0 for blocks without base fee';
+
+create unique index l2_block_header_hash_uindex
+on l2_block_header (hash) where deleted_at IS NULL;
+
+create unique index l2_block_header_number_uindex
+on l2_block_header (number) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists l2_block_header;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE proof_failure
+(
+    id                  BIGSERIAL    PRIMARY KEY,
+
+-- task
+    task_id             VARCHAR      NOT NULL,
+    task_type           SMALLINT     NOT NULL,
+    prover_task_uuid    VARCHAR      NOT NULL,
+
+-- prover
+    prover_name         VARCHAR      NOT NULL,
+    prover_public_key   VARCHAR      NOT NULL,
+    prover_version      VARCHAR      NOT NULL,
+
+-- failure
+    failure_type        SMALLINT     NOT NULL,
+    failure_class       SMALLINT     NOT NULL,
+    failure_msg         TEXT         NOT NULL DEFAULT '',
+
+-- task inputs reference, the l2 blocks whose traces are the inputs of the task
+    start_block_number  BIGINT       NOT NULL DEFAULT 0,
+    end_block_number    BIGINT       NOT NULL DEFAULT 0,
+    hard_fork_name      VARCHAR      NOT NULL DEFAULT '',
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_proof_failure_on_created_at ON proof_failure(created_at);
+CREATE INDEX idx_proof_failure_on_task_id ON proof_failure(task_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS proof_failure;
+-- +goose StatementEnd
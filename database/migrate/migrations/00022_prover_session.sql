@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- prover_session stores the login sessions of provers, so that every coordinator replica accepts them.
+CREATE TABLE prover_session
+(
+    id                  BIGSERIAL    PRIMARY KEY,
+    session_id          VARCHAR      NOT NULL,
+    public_key          VARCHAR      NOT NULL,
+    prover_name         VARCHAR      NOT NULL,
+    prover_version      VARCHAR      NOT NULL,
+    expired_at          TIMESTAMP(0) NOT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX uk_prover_session_session_id ON prover_session(session_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_prover_session_on_expired_at ON prover_session(expired_at) WHERE deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS prover_session;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- task_data_key is the key encrypting the task data sent to the prover of the session, empty if not negotiated.
+ALTER TABLE prover_session ADD COLUMN task_data_key VARCHAR NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prover_session DROP COLUMN IF EXISTS task_data_key;
+-- +goose StatementEnd
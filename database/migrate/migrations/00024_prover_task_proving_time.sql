@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Proving time reported by the prover with the submission of the task, NULL for provers not reporting it.
+ALTER TABLE prover_task
+    ADD COLUMN proving_time_ms BIGINT DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prover_task
+    DROP COLUMN IF EXISTS proving_time_ms;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- feature_flag stores the runtime overrides of the feature flags of the services sharing this database, a row
+-- overrides the config file and the environment until it is deleted.
+CREATE TABLE feature_flag
+(
+    name                VARCHAR      PRIMARY KEY,
+    enabled             BOOLEAN      NOT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS feature_flag;
+-- +goose StatementEnd
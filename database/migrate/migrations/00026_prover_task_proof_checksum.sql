@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Hex encoded sha256 of the proof submitted for the task, so that a proof submitted again, e.g. by a prover retrying,
+-- is answered with the result of its verification without verifying it again.
+ALTER TABLE prover_task
+    ADD COLUMN proof_checksum VARCHAR DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE prover_task
+    DROP COLUMN IF EXISTS proof_checksum;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- prover_task_watermark stores, per task type, the highest chunk or batch index the coordinator assigns tasks for. It
+-- is advanced by the coordinator cron so that at most a budget of chunks or batches are outstanding proving tasks.
+CREATE TABLE prover_task_watermark
+(
+    task_type           SMALLINT     PRIMARY KEY,
+    max_index           BIGINT       NOT NULL,
+    outstanding_tasks   BIGINT       NOT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS prover_task_watermark;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l1_message
+    ADD COLUMN replay_estimated_gas BIGINT DEFAULT NULL;
+
+comment
+on column l1_message.replay_estimated_gas is 'latest gas estimate of the relayMessage call of a skipped message on L2, NULL if not estimated or the simulation failed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE l1_message
+    DROP COLUMN IF EXISTS replay_estimated_gas;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- relayer_pipeline_pause stores the relayer pipelines paused through the admin api, e.g. commit or finalize, a row
+-- pauses its pipeline in all the relayers sharing this database until it is deleted, across restarts.
+CREATE TABLE relayer_pipeline_pause
+(
+    pipeline            VARCHAR      PRIMARY KEY,
+    reason              VARCHAR      NOT NULL DEFAULT '',
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS relayer_pipeline_pause;
+-- +goose StatementEnd
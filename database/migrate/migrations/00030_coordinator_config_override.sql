@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- coordinator_config_override overrides select parameters of the coordinator config, e.g. the proof collection time,
+-- so that operators tune the scheduler without restarts. The coordinator reloads it periodically.
+CREATE TABLE coordinator_config_override
+(
+    name                VARCHAR      PRIMARY KEY,
+    value               VARCHAR      NOT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS coordinator_config_override;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- batch_chunk_dependency records the chunk proofs each batch proof depends on, with the time each was verified. The
+-- coordinator makes a batch assignable once all its dependencies are verified.
+CREATE TABLE batch_chunk_dependency
+(
+    batch_hash          VARCHAR      NOT NULL,
+    chunk_hash          VARCHAR      NOT NULL,
+    chunk_index         BIGINT       NOT NULL,
+    verified_at         TIMESTAMP(0) DEFAULT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (batch_hash, chunk_hash)
+);
+
+CREATE INDEX idx_batch_chunk_dependency_chunk_hash ON batch_chunk_dependency (chunk_hash);
+
+CREATE INDEX idx_batch_chunk_dependency_unverified ON batch_chunk_dependency (batch_hash) WHERE verified_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS batch_chunk_dependency;
+-- +goose StatementEnd
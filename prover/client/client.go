@@ -1,17 +1,28 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/log"
 
 	"scroll-tech/prover/config"
 
+	"scroll-tech/common/retry"
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/version"
@@ -20,11 +31,18 @@ import (
 // CoordinatorClient is a client used for interacting with the Coordinator service.
 type CoordinatorClient struct {
 	client *resty.Client
-
-	proverName string
-	priv       *ecdsa.PrivateKey
-
-	mu sync.Mutex
+	// payloadClient downloads the task data left out of get_task, without the request timeout of client so that large
+	// task data is not cut off on slow links.
+	payloadClient *resty.Client
+	retryCount    int
+	retryWaitTime time.Duration
+
+	proverName      string
+	priv            *ecdsa.PrivateKey
+	encryptTaskData bool
+
+	mu          sync.Mutex
+	taskDataKey []byte // negotiated at login if the task data is encrypted
 }
 
 // NewCoordinatorClient constructs a new CoordinatorClient.
@@ -50,9 +68,13 @@ func NewCoordinatorClient(cfg *config.CoordinatorConfig, proverName string, priv
 		"retry wait time (second)", cfg.RetryWaitTimeSec)
 
 	return &CoordinatorClient{
-		client:     client,
-		proverName: proverName,
-		priv:       priv,
+		client:          client,
+		payloadClient:   resty.New().SetBaseURL(cfg.BaseURL),
+		retryCount:      cfg.RetryCount,
+		retryWaitTime:   time.Duration(cfg.RetryWaitTimeSec) * time.Second,
+		proverName:      proverName,
+		priv:            priv,
+		encryptTaskData: cfg.EncryptTaskData,
 	}, nil
 }
 
@@ -86,6 +108,16 @@ func (c *CoordinatorClient) Login(ctx context.Context) error {
 		},
 	}
 
+	// a new ephemeral key is generated for every session, so a leaked task data key only exposes one session
+	var sessionKey *ecdsa.PrivateKey
+	if c.encryptTaskData {
+		sessionKey, err = crypto.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("generate session key failed: %w", err)
+		}
+		authMsg.Identity.SessionPublicKey = common.Bytes2Hex(crypto.CompressPubkey(&sessionKey.PublicKey))
+	}
+
 	err = authMsg.SignWithKey(c.priv)
 	if err != nil {
 		return fmt.Errorf("signature failed: %w", err)
@@ -94,13 +126,15 @@ func (c *CoordinatorClient) Login(ctx context.Context) error {
 	// Login to coordinator
 	loginReq := &LoginRequest{
 		Message: struct {
-			Challenge     string `json:"challenge"`
-			ProverName    string `json:"prover_name"`
-			ProverVersion string `json:"prover_version"`
+			Challenge        string `json:"challenge"`
+			ProverName       string `json:"prover_name"`
+			ProverVersion    string `json:"prover_version"`
+			SessionPublicKey string `json:"session_public_key,omitempty"`
 		}{
-			Challenge:     authMsg.Identity.Challenge,
-			ProverName:    authMsg.Identity.ProverName,
-			ProverVersion: authMsg.Identity.ProverVersion,
+			Challenge:        authMsg.Identity.Challenge,
+			ProverName:       authMsg.Identity.ProverName,
+			ProverVersion:    authMsg.Identity.ProverVersion,
+			SessionPublicKey: authMsg.Identity.SessionPublicKey,
 		},
 		Signature: authMsg.Signature,
 	}
@@ -124,11 +158,27 @@ func (c *CoordinatorClient) Login(ctx context.Context) error {
 	}
 
 	if loginResult.ErrCode != types.Success {
-		return fmt.Errorf("failed to login, error code: %v, error message: %v", loginResult.ErrCode, loginResult.ErrMsg)
+		return fmt.Errorf("failed to login, %w", &CoordinatorError{Code: loginResult.ErrCode, Msg: loginResult.ErrMsg})
+	}
+
+	if deprecation := loginResp.Header().Get(types.ProverVersionDeprecationHeader); deprecation != "" {
+		log.Warn("prover version deprecated by coordinator", "warning", deprecation)
+	}
+
+	c.taskDataKey = nil
+	if sessionKey != nil {
+		if loginResult.Data.SessionPublicKey == "" {
+			return errors.New("failed to login, the coordinator does not encrypt the task data")
+		}
+		c.taskDataKey, err = message.DeriveTaskDataKey(sessionKey, loginResult.Data.SessionPublicKey)
+		if err != nil {
+			return fmt.Errorf("derive task data key failed: %w", err)
+		}
 	}
 
 	// store JWT token for future requests
 	c.client.SetAuthToken(loginResult.Data.Token)
+	c.payloadClient.SetAuthToken(loginResult.Data.Token)
 
 	return nil
 }
@@ -160,12 +210,126 @@ func (c *CoordinatorClient) GetTask(ctx context.Context, req *GetTaskRequest) (*
 		return c.GetTask(ctx, req)
 	}
 	if result.ErrCode != types.Success {
-		return nil, fmt.Errorf("error code: %v, error message: %v", result.ErrCode, result.ErrMsg)
+		return nil, &CoordinatorError{Code: result.ErrCode, Msg: result.ErrMsg}
+	}
+
+	if result.Data != nil && result.Data.PayloadSize > 0 {
+		if err := c.downloadTaskData(ctx, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Data != nil && result.Data.Encrypted {
+		if err := c.decryptTaskData(&result); err != nil {
+			return nil, err
+		}
 	}
 
 	return &result, nil
 }
 
+// downloadTaskData downloads the task data left out of the get_task response. An interrupted download is resumed from
+// the received bytes with a Range request, If-Range makes the coordinator send the whole task data again if it was
+// replaced meanwhile.
+func (c *CoordinatorClient) downloadTaskData(ctx context.Context, result *GetTaskResponse) error {
+	data := result.Data
+	buf := bytes.NewBuffer(make([]byte, 0, data.PayloadSize))
+	policy := retry.Policy{
+		InitialBackoff: c.retryWaitTime,
+		Multiplier:     1,
+		MaxAttempts:    c.retryCount + 1,
+		// the errors of the coordinator are not transient.
+		Retryable: func(err error) bool {
+			var coordinatorErr *CoordinatorError
+			return !errors.As(err, &coordinatorErr)
+		},
+		OnRetry: func(_ int, _ time.Duration, err error) {
+			log.Warn("task payload download interrupted, resuming", "uuid", data.UUID, "received", buf.Len(), "size", data.PayloadSize, "error", err)
+		},
+	}
+	attempted := false
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		if attempted && int64(buf.Len()) == data.PayloadSize {
+			// interrupted after the last byte, the hash tells whether the task data is whole.
+			return nil
+		}
+		attempted = true
+		return c.downloadTaskDataFrom(ctx, data.UUID, `"`+data.PayloadSHA256+`"`, buf)
+	})
+	if err != nil {
+		var coordinatorErr *CoordinatorError
+		if errors.As(err, &coordinatorErr) {
+			return err
+		}
+		return fmt.Errorf("failed to download task payload: %w", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	if hex.EncodeToString(sum[:]) != data.PayloadSHA256 {
+		return fmt.Errorf("failed to download task payload, sha256 mismatch, uuid: %s", data.UUID)
+	}
+	data.TaskData = buf.String()
+	return nil
+}
+
+// downloadTaskDataFrom downloads the task data from the end of buf, or from the start if the coordinator sends it
+// whole. The bytes received before an interruption are kept in buf.
+func (c *CoordinatorClient) downloadTaskDataFrom(ctx context.Context, uuid, etag string, buf *bytes.Buffer) error {
+	req := c.payloadClient.R().SetContext(ctx).SetDoNotParseResponse(true)
+	if buf.Len() > 0 {
+		req.SetHeader("Range", fmt.Sprintf("bytes=%d-", buf.Len())).SetHeader("If-Range", etag)
+	}
+	resp, err := req.Get("/coordinator/v1/task_payload/" + uuid)
+	if err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer func() { _ = body.Close() }()
+
+	// failures are rendered as json, the task data is an octet stream.
+	if strings.HasPrefix(resp.Header().Get("Content-Type"), "application/json") {
+		var result types.Response
+		if err = json.NewDecoder(body).Decode(&result); err != nil {
+			return fmt.Errorf("failed to decode task payload response: %w", err)
+		}
+		if result.ErrCode == types.ErrJWTTokenExpired {
+			log.Info("JWT expired, attempting to re-login")
+			if err = c.Login(ctx); err != nil {
+				return fmt.Errorf("JWT expired, re-login failed: %w", err)
+			}
+			return errors.New("JWT expired, re-logged in")
+		}
+		return &CoordinatorError{Code: result.ErrCode, Msg: result.ErrMsg}
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		// the whole task data is sent, the first request or the task data was replaced since the download started.
+		buf.Reset()
+	case http.StatusPartialContent:
+	default:
+		return fmt.Errorf("failed to download task payload, status code: %v", resp.StatusCode())
+	}
+	_, err = io.Copy(buf, body)
+	return err
+}
+
+// decryptTaskData decrypts the task data of the response with the task data key of the session.
+func (c *CoordinatorClient) decryptTaskData(result *GetTaskResponse) error {
+	c.mu.Lock()
+	taskDataKey := c.taskDataKey
+	c.mu.Unlock()
+	if taskDataKey == nil {
+		return errors.New("failed to get task, the task data is encrypted but no task data key was negotiated")
+	}
+	taskData, err := message.DecryptTaskData(taskDataKey, result.Data.TaskData)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt task data: %w", err)
+	}
+	result.Data.TaskData = taskData
+	return nil
+}
+
 // SubmitProof sends a request to the coordinator to submit proof.
 func (c *CoordinatorClient) SubmitProof(ctx context.Context, req *SubmitProofRequest) error {
 	var result SubmitProofResponse
@@ -197,7 +361,7 @@ func (c *CoordinatorClient) SubmitProof(ctx context.Context, req *SubmitProofReq
 	}
 
 	if result.ErrCode != types.Success {
-		return fmt.Errorf("error code: %v, error message: %v", result.ErrCode, result.ErrMsg)
+		return &CoordinatorError{Code: result.ErrCode, Msg: result.ErrMsg}
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package client
 
 import (
 	"errors"
+	"fmt"
 
 	"scroll-tech/common/types/message"
 )
@@ -9,6 +10,27 @@ import (
 // ErrCoordinatorConnect connect to coordinator error
 var ErrCoordinatorConnect = errors.New("connect coordinator error")
 
+// CoordinatorError is an error response of the coordinator, the prover branches on its code, one of the
+// scroll-tech/common/types error codes.
+type CoordinatorError struct {
+	Code int
+	Msg  string
+}
+
+func (e *CoordinatorError) Error() string {
+	return fmt.Sprintf("error code: %v, error message: %v", e.Code, e.Msg)
+}
+
+// ErrorCode returns the code of the coordinator error response in the chain of err, ok is false if there is none,
+// e.g. the coordinator could not be reached.
+func ErrorCode(err error) (code int, ok bool) {
+	var coordinatorErr *CoordinatorError
+	if errors.As(err, &coordinatorErr) {
+		return coordinatorErr.Code, true
+	}
+	return 0, false
+}
+
 // ChallengeResponse defines the response structure for random API
 type ChallengeResponse struct {
 	ErrCode int    `json:"errcode"`
@@ -22,9 +44,10 @@ type ChallengeResponse struct {
 // LoginRequest defines the request structure for login API
 type LoginRequest struct {
 	Message struct {
-		Challenge     string `json:"challenge"`
-		ProverName    string `json:"prover_name"`
-		ProverVersion string `json:"prover_version"`
+		Challenge        string `json:"challenge"`
+		ProverName       string `json:"prover_name"`
+		ProverVersion    string `json:"prover_version"`
+		SessionPublicKey string `json:"session_public_key,omitempty"`
 	} `json:"message"`
 	Signature string `json:"signature"`
 }
@@ -34,8 +57,9 @@ type LoginResponse struct {
 	ErrCode int    `json:"errcode"`
 	ErrMsg  string `json:"errmsg"`
 	Data    *struct {
-		Time  string `json:"time"`
-		Token string `json:"token"`
+		Time             string `json:"time"`
+		Token            string `json:"token"`
+		SessionPublicKey string `json:"session_public_key,omitempty"`
 	} `json:"data"`
 }
 
@@ -45,6 +69,7 @@ type GetTaskRequest struct {
 	TaskType     message.ProofType `json:"task_type"`
 	ProverHeight uint64            `json:"prover_height,omitempty"`
 	VK           string            `json:"vk"`
+	Prefetch     bool              `json:"prefetch,omitempty"`
 }
 
 // GetTaskResponse defines the response structure for GetTask API
@@ -52,10 +77,18 @@ type GetTaskResponse struct {
 	ErrCode int    `json:"errcode"`
 	ErrMsg  string `json:"errmsg"`
 	Data    *struct {
-		UUID     string `json:"uuid"`
-		TaskID   string `json:"task_id"`
-		TaskType int    `json:"task_type"`
-		TaskData string `json:"task_data"`
+		UUID       string `json:"uuid"`
+		TaskID     string `json:"task_id"`
+		TaskType   int    `json:"task_type"`
+		TaskData   string `json:"task_data"`
+		Deadline   int64  `json:"deadline,omitempty"`
+		TargetTime int64  `json:"target_time,omitempty"`
+		Encrypted  bool   `json:"encrypted,omitempty"`
+		// ReservedUntil is set instead of the deadline for a prefetched task, reserved until then.
+		ReservedUntil int64 `json:"reserved_until,omitempty"`
+		// PayloadSize is set if the task data was left out to be downloaded from task_payload, with its sha256.
+		PayloadSize   int64  `json:"payload_size,omitempty"`
+		PayloadSHA256 string `json:"payload_sha256,omitempty"`
 	} `json:"data"`
 }
 
@@ -26,6 +26,7 @@ func init() {
 	app.Usage = "The Scroll L2 Prover"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -42,6 +43,11 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
 
 	// Create prover
 	r, err := prover.NewProver(context.Background(), cfg)
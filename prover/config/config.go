@@ -8,6 +8,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
 
+	"scroll-tech/common/chains"
+	"scroll-tech/common/secrets"
 	"scroll-tech/common/types/message"
 )
 
@@ -37,12 +39,17 @@ type CoordinatorConfig struct {
 	RetryCount           int    `json:"retry_count"`
 	RetryWaitTimeSec     int    `json:"retry_wait_time_sec"`
 	ConnectionTimeoutSec int    `json:"connection_timeout_sec"`
+	// EncryptTaskData negotiates a task data key at login, so the coordinator sends the task data encrypted.
+	// It requires a coordinator which supports the session public key in the login identity.
+	EncryptTaskData bool `json:"encrypt_task_data,omitempty"`
 }
 
 // L2GethConfig represents the configuration for the l2geth client.
 type L2GethConfig struct {
 	Endpoint      string          `json:"endpoint"`
 	Confirmations rpc.BlockNumber `json:"confirmations"`
+	// ChainID is checked against the chain id of l2geth at startup, unchecked if 0.
+	ChainID uint64 `json:"chain_id,omitempty"`
 }
 
 // NewConfig returns a new instance of Config.
@@ -51,6 +58,10 @@ func NewConfig(file string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	buf, err = secrets.ResolveJSON(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{}
 	if err = json.Unmarshal(buf, cfg); err != nil {
@@ -64,3 +75,13 @@ func NewConfig(file string) (*Config, error) {
 	}
 	return cfg, nil
 }
+
+// ApplyNetwork fills the l2geth chain id from the network profile if it is not configured.
+func (c *Config) ApplyNetwork(network *chains.Network) {
+	if network == nil || c.L2Geth == nil {
+		return
+	}
+	if c.L2Geth.ChainID == 0 {
+		c.L2Geth.ChainID = network.L2ChainID
+	}
+}
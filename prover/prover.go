@@ -22,6 +22,7 @@ import (
 	"scroll-tech/prover/store"
 	putils "scroll-tech/prover/utils"
 
+	ctypes "scroll-tech/common/types"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 )
@@ -29,6 +30,8 @@ import (
 var (
 	// retry connecting to coordinator
 	retryWait = time.Second * 10
+	// back off when rate limited by coordinator, below the active window of its assignment fairness
+	rateLimitWait = time.Second * 20
 )
 
 // Prover contains websocket conn to coordinator, and task stack.
@@ -72,6 +75,16 @@ func NewProver(ctx context.Context, cfg *config.Config) (*Prover, error) {
 		}
 		// Use gzip compression.
 		l2GethClient.SetHeader("Accept-Encoding", "gzip")
+
+		if cfg.L2Geth.ChainID != 0 {
+			chainID, chainErr := l2GethClient.ChainID(ctx)
+			if chainErr != nil {
+				return nil, fmt.Errorf("failed to get l2geth chain id: %w", chainErr)
+			}
+			if chainID.Uint64() != cfg.L2Geth.ChainID {
+				return nil, fmt.Errorf("l2geth chain id mismatch, expected: %d, got: %v", cfg.L2Geth.ChainID, chainID)
+			}
+		}
 	}
 
 	// Create prover_core instance
@@ -143,8 +156,7 @@ func (r *Prover) proveAndSubmit() error {
 		// fetch new proving task.
 		task, err = r.fetchTaskFromCoordinator()
 		if err != nil {
-			time.Sleep(retryWait)
-			return fmt.Errorf("failed to fetch task from coordinator: %v", err)
+			return r.waitForTask(err)
 		}
 
 		// Push the new task into the stack
@@ -174,6 +186,26 @@ func (r *Prover) proveAndSubmit() error {
 	return r.submitErr(task, message.ProofFailurePanic, errors.New("zk proving panic for task"))
 }
 
+// waitForTask waits before asking coordinator for a task again after failing to fetch one, by the error code of
+// the coordinator response. An outdated prover exits, as coordinator keeps rejecting it until it is upgraded.
+func (r *Prover) waitForTask(err error) error {
+	code, _ := client.ErrorCode(err)
+	switch code {
+	case ctypes.ErrCoordinatorNoTaskAvailable:
+		log.Debug("no task available from coordinator", "prover type", r.cfg.Core.ProofType)
+		time.Sleep(retryWait)
+		return nil
+	case ctypes.ErrCoordinatorRateLimited:
+		log.Debug("rate limited by coordinator, backing off", "prover type", r.cfg.Core.ProofType, "wait", rateLimitWait)
+		time.Sleep(rateLimitWait)
+		return nil
+	case ctypes.ErrCoordinatorProverVersionTooLow:
+		log.Crit("prover version rejected by coordinator, please upgrade the prover", "error", err)
+	}
+	time.Sleep(retryWait)
+	return fmt.Errorf("failed to fetch task from coordinator: %v", err)
+}
+
 // fetchTaskFromCoordinator fetches a new task from the server
 func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 	// prepare the request
@@ -201,7 +233,7 @@ func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 	// send the request
 	resp, err := r.coordinatorClient.GetTask(r.ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task, req: %v, err: %v", req, err)
+		return nil, fmt.Errorf("failed to get task, req: %v, err: %w", req, err)
 	}
 
 	// create a new TaskMsg
@@ -285,9 +317,14 @@ func (r *Prover) proveChunk(task *store.ProvingTask) (*message.ChunkProof, error
 	if task.Task.ChunkTaskDetail == nil {
 		return nil, fmt.Errorf("ChunkTaskDetail is empty")
 	}
-	traces, err := r.getSortedTracesByHashes(task.Task.ChunkTaskDetail.BlockHashes)
-	if err != nil {
-		return nil, fmt.Errorf("get traces from eth node failed, block hashes: %v, err: %v", task.Task.ChunkTaskDetail.BlockHashes, err)
+	traces := task.Task.ChunkTaskDetail.BlockTraces
+	if len(traces) == 0 {
+		// the coordinator only sends the block hashes when it does not fetch the traces itself.
+		var err error
+		traces, err = r.getSortedTracesByHashes(task.Task.ChunkTaskDetail.BlockHashes)
+		if err != nil {
+			return nil, fmt.Errorf("get traces from eth node failed, block hashes: %v, err: %v", task.Task.ChunkTaskDetail.BlockHashes, err)
+		}
 	}
 	return r.proverCore.ProveChunk(task.Task.ID, traces)
 }
@@ -330,7 +367,7 @@ func (r *Prover) submitProof(msg *message.ProofDetail, uuid string) error {
 
 	// send the submit request
 	if err := r.coordinatorClient.SubmitProof(r.ctx, req); err != nil {
-		if !errors.Is(errors.Unwrap(err), client.ErrCoordinatorConnect) {
+		if dropRejectedTask(err) {
 			if deleteErr := r.stack.Delete(msg.ID); deleteErr != nil {
 				log.Error("prover stack pop failed", "task_type", msg.Type, "task_id", msg.ID, "err", deleteErr)
 			}
@@ -360,7 +397,7 @@ func (r *Prover) submitErr(task *store.ProvingTask, proofFailureType message.Pro
 
 	// send the submit request
 	if submitErr := r.coordinatorClient.SubmitProof(r.ctx, req); submitErr != nil {
-		if !errors.Is(errors.Unwrap(err), client.ErrCoordinatorConnect) {
+		if dropRejectedTask(submitErr) {
 			if deleteErr := r.stack.Delete(task.Task.ID); deleteErr != nil {
 				log.Error("prover stack pop failed", "task_type", task.Task.Type, "task_id", task.Task.ID, "err", deleteErr)
 			}
@@ -377,6 +414,24 @@ func (r *Prover) submitErr(task *store.ProvingTask, proofFailureType message.Pro
 	return nil
 }
 
+// dropRejectedTask returns whether the task of a failed submission is dropped from the stack, i.e. coordinator
+// rejected it, rather than submitted again. Tasks are kept if coordinator could not be reached or authenticated.
+func dropRejectedTask(err error) bool {
+	code, ok := client.ErrorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case ctypes.ErrJWTCommonErr, ctypes.ErrJWTTokenExpired:
+		return false
+	case ctypes.ErrCoordinatorTaskExpired:
+		log.Warn("task expired on coordinator, dropping it", "error", err)
+	case ctypes.ErrCoordinatorProofInvalid, ctypes.ErrCoordinatorProofMismatch:
+		log.Warn("proof rejected by coordinator, dropping the task", "error", err)
+	}
+	return true
+}
+
 func (r *Prover) getSortedTracesByHashes(blockHashes []common.Hash) ([]*types.BlockTrace, error) {
 	if len(blockHashes) == 0 {
 		return nil, fmt.Errorf("blockHashes is empty")
@@ -16,29 +16,14 @@ var (
 	// L2GasPriceOracleABI holds information about L2GasPriceOracle's context and available invokable methods.
 	L2GasPriceOracleABI *abi.ABI
 
+	// L1ScrollMessengerABI holds information about L1ScrollMessenger's context and available invokable methods.
+	L1ScrollMessengerABI *abi.ABI
 	// L2ScrollMessengerABI holds information about L2ScrollMessenger's context and available invokable methods.
 	L2ScrollMessengerABI *abi.ABI
 	// L1GasPriceOracleABI holds information about L1GasPriceOracle's context and available invokable methods.
 	L1GasPriceOracleABI *abi.ABI
 	// L2MessageQueueABI holds information about L2MessageQueue contract's context and available invokable methods.
 	L2MessageQueueABI *abi.ABI
-
-	// L1CommitBatchEventSignature = keccak256("CommitBatch(uint256,bytes32)")
-	L1CommitBatchEventSignature common.Hash
-	// L1FinalizeBatchEventSignature = keccak256("FinalizeBatch(uint256,bytes32,bytes32,bytes32)")
-	L1FinalizeBatchEventSignature common.Hash
-	// L1QueueTransactionEventSignature = keccak256("QueueTransaction(address,address,uint256,uint64,uint256,bytes)")
-	L1QueueTransactionEventSignature common.Hash
-
-	// L2SentMessageEventSignature = keccak256("SentMessage(address,address,uint256,uint256,uint256,bytes,uint256,uint256)")
-	L2SentMessageEventSignature common.Hash
-	// L2RelayedMessageEventSignature = keccak256("RelayedMessage(bytes32)")
-	L2RelayedMessageEventSignature common.Hash
-	// L2FailedRelayedMessageEventSignature = keccak256("FailedRelayedMessage(bytes32)")
-	L2FailedRelayedMessageEventSignature common.Hash
-
-	// L2AppendMessageEventSignature = keccak256("AppendMessage(uint256,bytes32)")
-	L2AppendMessageEventSignature common.Hash
 )
 
 func init() {
@@ -46,23 +31,14 @@ func init() {
 	L1MessageQueueABI, _ = L1MessageQueueMetaData.GetAbi()
 	L2GasPriceOracleABI, _ = L2GasPriceOracleMetaData.GetAbi()
 
+	L1ScrollMessengerABI, _ = L1ScrollMessengerMetaData.GetAbi()
 	L2ScrollMessengerABI, _ = L2ScrollMessengerMetaData.GetAbi()
 	L2MessageQueueABI, _ = L2MessageQueueMetaData.GetAbi()
 	L1GasPriceOracleABI, _ = L1GasPriceOracleMetaData.GetAbi()
-
-	L1CommitBatchEventSignature = ScrollChainABI.Events["CommitBatch"].ID
-	L1FinalizeBatchEventSignature = ScrollChainABI.Events["FinalizeBatch"].ID
-
-	L1QueueTransactionEventSignature = L1MessageQueueABI.Events["QueueTransaction"].ID
-
-	L2SentMessageEventSignature = L2ScrollMessengerABI.Events["SentMessage"].ID
-	L2RelayedMessageEventSignature = L2ScrollMessengerABI.Events["RelayedMessage"].ID
-	L2FailedRelayedMessageEventSignature = L2ScrollMessengerABI.Events["FailedRelayedMessage"].ID
-
-	L2AppendMessageEventSignature = L2MessageQueueABI.Events["AppendMessage"].ID
 }
 
-// Generated manually from abigen and only necessary events and mutable calls are kept.
+// Generated manually from abigen and only necessary events and mutable calls are kept, the typed events are in
+// scroll-tech/common/types/events.
 
 // ScrollChainMetaData contains all meta data concerning the ScrollChain contract.
 var ScrollChainMetaData = &bind.MetaData{
@@ -132,78 +108,3 @@ type IScrollChainBlockContext struct {
 	NumTransactions uint16
 	NumL1Messages   uint16
 }
-
-// L1CommitBatchEvent represents a CommitBatch event raised by the ScrollChain contract.
-type L1CommitBatchEvent struct {
-	BatchIndex *big.Int
-	BatchHash  common.Hash
-}
-
-// L1FinalizeBatchEvent represents a FinalizeBatch event raised by the ScrollChain contract.
-type L1FinalizeBatchEvent struct {
-	BatchIndex   *big.Int
-	BatchHash    common.Hash
-	StateRoot    common.Hash
-	WithdrawRoot common.Hash
-}
-
-// L1RevertBatchEvent represents a RevertBatch event raised by the ScrollChain contract.
-type L1RevertBatchEvent struct {
-	BatchHash common.Hash
-}
-
-// L1QueueTransactionEvent represents a QueueTransaction event raised by the L1MessageQueue contract.
-type L1QueueTransactionEvent struct {
-	Sender     common.Address
-	Target     common.Address
-	Value      *big.Int
-	QueueIndex uint64
-	GasLimit   *big.Int
-	Data       []byte
-}
-
-// L1SentMessageEvent represents a SentMessage event raised by the L1ScrollMessenger contract.
-type L1SentMessageEvent struct {
-	Sender       common.Address
-	Target       common.Address
-	Value        *big.Int
-	MessageNonce *big.Int
-	GasLimit     *big.Int
-	Message      []byte
-}
-
-// L1FailedRelayedMessageEvent represents a FailedRelayedMessage event raised by the L1ScrollMessenger contract.
-type L1FailedRelayedMessageEvent struct {
-	MessageHash common.Hash
-}
-
-// L1RelayedMessageEvent represents a RelayedMessage event raised by the L1ScrollMessenger contract.
-type L1RelayedMessageEvent struct {
-	MessageHash common.Hash
-}
-
-// L2AppendMessageEvent represents a AppendMessage event raised by the L2MessageQueue contract.
-type L2AppendMessageEvent struct {
-	Index       *big.Int
-	MessageHash common.Hash
-}
-
-// L2SentMessageEvent represents a SentMessage event raised by the L2ScrollMessenger contract.
-type L2SentMessageEvent struct {
-	Sender       common.Address
-	Target       common.Address
-	Value        *big.Int
-	MessageNonce *big.Int
-	GasLimit     *big.Int
-	Message      []byte
-}
-
-// L2FailedRelayedMessageEvent represents a FailedRelayedMessage event raised by the L2ScrollMessenger contract.
-type L2FailedRelayedMessageEvent struct {
-	MessageHash common.Hash
-}
-
-// L2RelayedMessageEvent represents a RelayedMessage event raised by the L2ScrollMessenger contract.
-type L2RelayedMessageEvent struct {
-	MessageHash common.Hash
-}
@@ -7,12 +7,13 @@ import (
 	"os/signal"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 
+	"scroll-tech/common/crashreport"
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
@@ -23,6 +24,16 @@ import (
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the L1 watcher inserts the events of a range at a time.
+var dbPool = database.Pool{
+	Name:                "event_watcher",
+	MaxOpenNum:          5,
+	MaxIdleNum:          2,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	// Set up event-watcher app info.
 	app = cli.NewApp()
@@ -31,6 +42,7 @@ func init() {
 	app.Usage = "The Scroll Event Watcher"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Commands = []*cli.Command{}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -46,10 +58,15 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
 
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
-	db, err := database.InitDB(cfg.DBConfig)
+	db, err := database.InitDBWithPool(cfg.DBConfig, dbPool)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
@@ -60,7 +77,7 @@ func action(ctx *cli.Context) error {
 		}
 	}()
 
-	registry := prometheus.DefaultRegisterer
+	registry := metrics.Registerer()
 	observability.Server(ctx, db)
 	l1client, err := ethclient.Dial(cfg.L1Config.Endpoint)
 	if err != nil {
@@ -70,11 +87,11 @@ func action(ctx *cli.Context) error {
 	l1watcher := watcher.NewL1WatcherClient(ctx.Context, l1client, cfg.L1Config.StartHeight, cfg.L1Config.Confirmations,
 		cfg.L1Config.L1MessageQueueAddress, cfg.L1Config.ScrollChainContractAddress, db, registry)
 
-	go utils.Loop(subCtx, 10*time.Second, func() {
+	go utils.Loop(subCtx, 10*time.Second, crashreport.Wrap("l1_watcher", func() {
 		if loopErr := l1watcher.FetchContractEvent(); loopErr != nil {
 			log.Error("Failed to fetch bridge contract", "err", loopErr)
 		}
-	})
+	}))
 
 	log.Info("Start event-watcher successfully")
 
@@ -7,14 +7,15 @@ import (
 	"os/signal"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
 
+	"scroll-tech/common/crashreport"
 	"scroll-tech/common/database"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
@@ -27,6 +28,16 @@ import (
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the gas oracles query the db once per block.
+var dbPool = database.Pool{
+	Name:                "gas_oracle",
+	MaxOpenNum:          5,
+	MaxIdleNum:          2,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
 func init() {
 	// Set up gas-oracle app info.
 	app = cli.NewApp()
@@ -36,6 +47,7 @@ func init() {
 	app.Description = "Scroll Gas Oracle."
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Commands = []*cli.Command{}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -51,9 +63,14 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
-	db, err := database.InitDB(cfg.DBConfig)
+	db, err := database.InitDBWithPool(cfg.DBConfig, dbPool)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
@@ -64,8 +81,12 @@ func action(ctx *cli.Context) error {
 		}
 	}()
 
-	registry := prometheus.DefaultRegisterer
-	observability.Server(ctx, db)
+	registry := metrics.Registerer()
+	// the relayer pipelines are paused by the maintenance windows, or through the admin api of the metrics server.
+	pipelineControl := relayer.NewPipelineControl(cfg.Maintenance, db, registry)
+	pipelineControl.Refresh(subCtx)
+	go utils.LoopWithContext(subCtx, pipelineControl.RefreshInterval(), crashreport.WrapWithContext("pipeline_control", pipelineControl.Refresh))
+	observability.Server(ctx, db, pipelineControl.Routes)
 
 	l1client, err := ethclient.Dial(cfg.L1Config.Endpoint)
 	if err != nil {
@@ -84,12 +105,12 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to create new l1 relayer", "config file", cfgFile, "error", err)
 	}
-	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, &params.ChainConfig{}, false /* initGenesis */, relayer.ServiceTypeL2GasOracle, registry)
+	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, &params.ChainConfig{}, false /* initGenesis */, relayer.ServiceTypeL2GasOracle, registry)
 	if err != nil {
 		log.Crit("failed to create new l2 relayer", "config file", cfgFile, "error", err)
 	}
 	// Start l1 watcher process
-	go utils.LoopWithContext(subCtx, 10*time.Second, func(ctx context.Context) {
+	go utils.LoopWithContext(subCtx, 10*time.Second, crashreport.WrapWithContext("l1_watcher", func(ctx context.Context) {
 		// Fetch the latest block number to decrease the delay when fetching gas prices
 		// Use latest block number - 1 to prevent frequent reorg
 		number, loopErr := butils.GetLatestConfirmedBlockNumber(ctx, l1client, rpc.LatestBlockNumber)
@@ -102,11 +123,37 @@ func action(ctx *cli.Context) error {
 			log.Error("Failed to fetch L1 block header", "lastest", number-1, "err", loopErr)
 			return
 		}
-	})
+	}))
+
+	if indexerCfg := cfg.L2Config.BlockHeaderIndexerConfig; indexerCfg != nil && indexerCfg.Enabled {
+		l2RPCClient, dialErr := rpc.Dial(cfg.L2Config.Endpoint)
+		if dialErr != nil {
+			log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", dialErr)
+		}
+		l2BlockHeaderIndexer := watcher.NewL2BlockHeaderIndexer(subCtx, l2RPCClient, indexerCfg, db, registry)
+		go utils.LoopWithContext(subCtx, 2*time.Second, crashreport.WrapWithContext("l2_block_header_indexer", func(ctx context.Context) {
+			number, loopErr := butils.GetLatestConfirmedBlockNumber(ctx, l2client, cfg.L2Config.Confirmations)
+			if loopErr != nil {
+				log.Error("failed to get block number", "err", loopErr)
+				return
+			}
+			if loopErr = l2BlockHeaderIndexer.TryFetchHeaders(number); loopErr != nil {
+				log.Error("Failed to index L2 block headers", "confirmed", number, "err", loopErr)
+			}
+		}))
+	}
+
+	// the gas oracles are paused by the chain watchdog while the L1 or L2 node looks sick.
+	var chainWatchdog *watcher.ChainWatchdog
+	if watchdogCfg := cfg.ChainWatchdog; watchdogCfg != nil && watchdogCfg.Enabled {
+		chainWatchdog = watcher.NewChainWatchdog(watchdogCfg, l1client, l2client, registry)
+		chainWatchdog.Check(subCtx)
+		go utils.LoopWithContext(subCtx, chainWatchdog.CheckInterval(), crashreport.WrapWithContext("chain_watchdog", chainWatchdog.Check))
+	}
 
 	// Start l1relayer process
-	go utils.Loop(subCtx, 10*time.Second, l1relayer.ProcessGasPriceOracle)
-	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessGasPriceOracle)
+	go utils.Loop(subCtx, 10*time.Second, crashreport.Wrap("l1_gas_oracle", chainWatchdog.Gate(pipelineControl.Gate(config.PipelineGasOracle, l1relayer.ProcessGasPriceOracle))))
+	go utils.Loop(subCtx, 2*time.Second, crashreport.Wrap("l2_gas_oracle", chainWatchdog.Gate(pipelineControl.Gate(config.PipelineGasOracle, l2relayer.ProcessGasPriceOracle))))
 
 	// Finish start all message relayer functions
 	log.Info("Start gas-oracle successfully")
@@ -7,12 +7,14 @@ import (
 	"os/signal"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 
+	"scroll-tech/common/crashreport"
 	"scroll-tech/common/database"
+	"scroll-tech/common/featureflag"
+	"scroll-tech/common/metrics"
 	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
@@ -25,6 +27,19 @@ import (
 
 var app *cli.App
 
+// dbPool is the default db connection pool, the proposers and the relayer loops query the db concurrently.
+var dbPool = database.Pool{
+	Name:                "rollup_relayer",
+	MaxOpenNum:          20,
+	MaxIdleNum:          5,
+	ConnMaxLifetime:     10 * time.Minute,
+	ConnMaxIdleTime:     5 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
+// featureBatchFinalization is the feature flag of the finalization of the proven batches, enabled by default.
+const featureBatchFinalization = "batch_finalization"
+
 func init() {
 	// Set up rollup-relayer app info.
 	app = cli.NewApp()
@@ -33,8 +48,9 @@ func init() {
 	app.Usage = "The Scroll Rollup Relayer"
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
 	app.Flags = append(app.Flags, utils.RollupRelayerFlags...)
-	app.Commands = []*cli.Command{}
+	app.Commands = []*cli.Command{skippedMessagesCommand}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -49,10 +65,15 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	network, err := utils.GetNetwork(ctx)
+	if err != nil {
+		log.Crit("failed to load network profile", "error", err)
+	}
+	cfg.ApplyNetwork(network)
 
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
-	db, err := database.InitDB(cfg.DBConfig)
+	db, err := database.InitDBWithPool(cfg.DBConfig, dbPool)
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
@@ -63,8 +84,18 @@ func action(ctx *cli.Context) error {
 		}
 	}()
 
-	registry := prometheus.DefaultRegisterer
-	observability.Server(ctx, db)
+	registry := metrics.Registerer()
+	// the relayer pipelines are paused by the maintenance windows, or through the admin api of the metrics server.
+	pipelineControl := relayer.NewPipelineControl(cfg.Maintenance, db, registry)
+	pipelineControl.Refresh(subCtx)
+	go utils.LoopWithContext(subCtx, pipelineControl.RefreshInterval(), crashreport.WrapWithContext("pipeline_control", pipelineControl.Refresh))
+	observability.Server(ctx, db, pipelineControl.Routes)
+
+	flags := featureflag.New(cfg.FeatureFlags, featureflag.NewDBSource(db), registry)
+	if err = flags.Refresh(subCtx); err != nil {
+		log.Warn("failed to load the feature flag overrides, using the config", "error", err)
+	}
+	flags.Start(subCtx)
 
 	// Init l2geth connection
 	l2client, err := ethclient.Dial(cfg.L2Config.Endpoint)
@@ -79,7 +110,7 @@ func action(ctx *cli.Context) error {
 	}
 
 	initGenesis := ctx.Bool(utils.ImportGenesisFlag.Name)
-	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, genesis.Config, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry)
+	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, genesis.Config, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry)
 	if err != nil {
 		log.Crit("failed to create l2 relayer", "config file", cfgFile, "error", err)
 	}
@@ -97,22 +128,43 @@ func action(ctx *cli.Context) error {
 	l2watcher := watcher.NewL2WatcherClient(subCtx, l2client, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, registry)
 
 	// Watcher loop to fetch missing blocks
-	go utils.LoopWithContext(subCtx, 2*time.Second, func(ctx context.Context) {
+	go utils.LoopWithContext(subCtx, 2*time.Second, crashreport.WrapWithContext("l2_watcher", func(ctx context.Context) {
 		number, loopErr := butils.GetLatestConfirmedBlockNumber(ctx, l2client, cfg.L2Config.Confirmations)
 		if loopErr != nil {
 			log.Error("failed to get block number", "err", loopErr)
 			return
 		}
 		l2watcher.TryFetchRunningMissingBlocks(number)
-	})
+	}))
+
+	// the loops sending transactions are paused by the chain watchdog while the L1 or L2 node looks sick.
+	var chainWatchdog *watcher.ChainWatchdog
+	if watchdogCfg := cfg.ChainWatchdog; watchdogCfg != nil && watchdogCfg.Enabled {
+		l1client, dialErr := ethclient.Dial(cfg.L2Config.RelayerConfig.SenderConfig.Endpoint)
+		if dialErr != nil {
+			log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", dialErr)
+		}
+		chainWatchdog = watcher.NewChainWatchdog(watchdogCfg, l1client, l2client, registry)
+		chainWatchdog.Check(subCtx)
+		go utils.LoopWithContext(subCtx, chainWatchdog.CheckInterval(), crashreport.WrapWithContext("chain_watchdog", chainWatchdog.Check))
+	}
 
-	go utils.Loop(subCtx, 2*time.Second, chunkProposer.TryProposeChunk)
+	go utils.Loop(subCtx, 2*time.Second, crashreport.Wrap("chunk_proposer", chunkProposer.TryProposeChunk))
 
-	go utils.Loop(subCtx, 10*time.Second, batchProposer.TryProposeBatch)
+	go utils.Loop(subCtx, 10*time.Second, crashreport.Wrap("batch_proposer", batchProposer.TryProposeBatch))
 
-	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessPendingBatches)
+	go utils.Loop(subCtx, 2*time.Second, crashreport.Wrap("l2_relayer_pending_batches", chainWatchdog.Gate(pipelineControl.Gate(config.PipelineCommit, l2relayer.ProcessPendingBatches))))
 
-	go utils.Loop(subCtx, 15*time.Second, l2relayer.ProcessCommittedBatches)
+	// finalization can be paused at runtime, e.g. while investigating a batch, by overriding batch_finalization.
+	go utils.Loop(subCtx, 15*time.Second, crashreport.Wrap("l2_relayer_committed_batches", flags.Gate(featureBatchFinalization, true, chainWatchdog.Gate(pipelineControl.Gate(config.PipelineFinalize, l2relayer.ProcessCommittedBatches)))))
+
+	if policyCfg := cfg.L2Config.RelayerConfig.SkippedMessagePolicy; policyCfg != nil && policyCfg.Enabled {
+		skippedMessagePolicy, policyErr := relayer.NewSkippedMessagePolicy(subCtx, l2client, db, cfg.L2Config.RelayerConfig, registry)
+		if policyErr != nil {
+			log.Crit("failed to create skipped message policy", "config file", cfgFile, "error", policyErr)
+		}
+		go utils.Loop(subCtx, 30*time.Second, crashreport.Wrap("skipped_message_policy", chainWatchdog.Gate(pipelineControl.Gate(config.PipelineMessageRelay, skippedMessagePolicy.ProcessSkippedMessages))))
+	}
 
 	// Finish start all rollup relayer functions.
 	log.Info("Start rollup-relayer successfully")
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+var (
+	queueIndexFlag = cli.Uint64Flag{
+		Name:     "queue-index",
+		Usage:    "Queue index of the skipped L1 message",
+		Required: true,
+	}
+	gasLimitFlag = cli.Uint64Flag{
+		Name:     "gas-limit",
+		Usage:    "New L2 gas limit of the replayed L1 message",
+		Required: true,
+	}
+	limitFlag = cli.IntFlag{
+		Name:  "limit",
+		Usage: "Max number of skipped L1 messages listed",
+		Value: 100,
+	}
+)
+
+var skippedMessagesCommand = &cli.Command{
+	Name:  "skipped-messages",
+	Usage: "Inspect and replay the L1 messages skipped by the sequencer.",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the skipped L1 messages with their skip reasons.",
+			Action: listSkippedMessages,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &limitFlag},
+		},
+		{
+			Name:   "replay",
+			Usage:  "Force the replay of a skipped L1 message with a new gas limit, the running skipped message policy sends the replay tx.",
+			Action: requestReplay,
+			Flags:  []cli.Flag{&utils.ConfigFileFlag, &queueIndexFlag, &gasLimitFlag},
+		},
+	},
+}
+
+func initSkippedMessagesDB(ctx *cli.Context) (*gorm.DB, error) {
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file %s, err: %w", cfgFile, err)
+	}
+	return database.InitDB(cfg.DBConfig)
+}
+
+// listSkippedMessages prints the skipped L1 messages, messages not classified yet by the policy have an undefined reason.
+func listSkippedMessages(ctx *cli.Context) error {
+	db, err := initSkippedMessagesDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err = database.CloseDB(db); err != nil {
+			log.Error("failed to close db connection", "error", err)
+		}
+	}()
+
+	messages, err := orm.NewL1Message(db).GetSkippedL1Messages(ctx.Context, ctx.Int(limitFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "QUEUE INDEX\tREASON\tGAS LIMIT\tREPLAY COUNT\tREPLAY REQUESTED")
+	for _, msg := range messages {
+		requested := "-"
+		if msg.ReplayRequestedGasLimit != nil {
+			requested = fmt.Sprintf("%d", *msg.ReplayRequestedGasLimit)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\n", msg.QueueIndex, types.MsgSkipReason(msg.SkipReason), msg.GasLimit, msg.ReplayCount, requested)
+	}
+	return w.Flush()
+}
+
+// requestReplay records a forced replay request, which bypasses the replay limits of the policy.
+func requestReplay(ctx *cli.Context) error {
+	db, err := initSkippedMessagesDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err = database.CloseDB(db); err != nil {
+			log.Error("failed to close db connection", "error", err)
+		}
+	}()
+
+	queueIndex := ctx.Uint64(queueIndexFlag.Name)
+	gasLimit := ctx.Uint64(gasLimitFlag.Name)
+	if err = orm.NewL1Message(db).RequestReplay(ctx.Context, queueIndex, gasLimit); err != nil {
+		return err
+	}
+	log.Info("requested replay of skipped l1 message", "queue index", queueIndex, "gas limit", gasLimit)
+	return nil
+}
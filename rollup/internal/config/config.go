@@ -5,8 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"scroll-tech/common/chains"
 	"scroll-tech/common/database"
+	"scroll-tech/common/featureflag"
+	"scroll-tech/common/secrets"
 )
 
 // Config load configuration items.
@@ -14,12 +20,108 @@ type Config struct {
 	L1Config *L1Config        `json:"l1_config"`
 	L2Config *L2Config        `json:"l2_config"`
 	DBConfig *database.Config `json:"db_config"`
+	// FeatureFlags toggle risky behaviors, they can be overridden at runtime in the feature_flag table.
+	FeatureFlags *featureflag.Config `json:"feature_flags,omitempty"`
+	// ChainWatchdog pauses the relayers while the L1 or L2 node looks sick, disabled if nil.
+	ChainWatchdog *ChainWatchdogConfig `json:"chain_watchdog,omitempty"`
+	// Maintenance schedules the maintenance windows of the relayer pipelines and enables their pause api, the
+	// pipelines are only paused through the api if nil.
+	Maintenance *MaintenanceConfig `json:"maintenance,omitempty"`
+}
+
+// ChainWatchdogConfig configures the watchdog pausing the relayers sending transactions while the progress of the L1
+// or L2 head observed through the node differs from the expected one, e.g. the head stalls or its timestamp drifts
+// from the wall clock, so that they do not act on the view of a sick node. A threshold of 0 disables its check.
+type ChainWatchdogConfig struct {
+	Enabled bool `json:"enabled"`
+	// CheckIntervalSec is the interval (in seconds) of the checks, defaults to 10 seconds.
+	CheckIntervalSec int `json:"check_interval_sec,omitempty"`
+	// MaxL1HeadStallSec is the longest time (in seconds) the L1 head may not advance.
+	MaxL1HeadStallSec int `json:"max_l1_head_stall_sec"`
+	// MaxL2HeadStallSec is the longest time (in seconds) the L2 head may not advance.
+	MaxL2HeadStallSec int `json:"max_l2_head_stall_sec"`
+	// MaxL1TimestampDriftSec is the largest difference (in seconds) between the timestamp of the L1 head and the
+	// wall clock.
+	MaxL1TimestampDriftSec int `json:"max_l1_timestamp_drift_sec"`
+	// MaxL2TimestampDriftSec is the largest difference (in seconds) between the timestamp of the L2 head and the
+	// wall clock.
+	MaxL2TimestampDriftSec int `json:"max_l2_timestamp_drift_sec"`
+	// ResumeAfterSec is the time (in seconds) the checks must pass before the paused relayers are resumed, defaults
+	// to 60 seconds.
+	ResumeAfterSec int `json:"resume_after_sec,omitempty"`
+}
+
+// Relayer pipelines, which can be paused independently through the admin api or by a maintenance window.
+const (
+	// PipelineGasOracle is the update of the L1 and L2 gas price oracles by gas_oracle.
+	PipelineGasOracle = "gas_oracle"
+	// PipelineMessageRelay is the replay of the skipped L1 messages by rollup_relayer.
+	PipelineMessageRelay = "message_relay"
+	// PipelineCommit is the commit of the pending batches by rollup_relayer.
+	PipelineCommit = "commit"
+	// PipelineFinalize is the finalization of the committed batches by rollup_relayer.
+	PipelineFinalize = "finalize"
+)
+
+// Pipelines are all the relayer pipelines.
+var Pipelines = []string{PipelineGasOracle, PipelineMessageRelay, PipelineCommit, PipelineFinalize}
+
+// IsPipeline returns whether name is a relayer pipeline.
+func IsPipeline(name string) bool {
+	for _, pipeline := range Pipelines {
+		if name == pipeline {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceConfig configures the pausing of the relayer pipelines, by scheduled maintenance windows and through
+// the admin api served on the metrics server. The pipelines paused through the api are stored in the database, so
+// they stay paused across restarts and in all the relayers sharing it.
+type MaintenanceConfig struct {
+	// Windows are the scheduled maintenance windows.
+	Windows []*MaintenanceWindow `json:"windows,omitempty"`
+	// AdminToken authenticates the pause and resume requests in the X-Admin-Token header, they are rejected if empty.
+	AdminToken string `json:"admin_token,omitempty"`
+	// RefreshIntervalSec is the interval (in seconds) of reloading the paused pipelines from the database, e.g. the
+	// ones paused through another relayer, defaults to 10 seconds.
+	RefreshIntervalSec int `json:"refresh_interval_sec,omitempty"`
+}
+
+// MaintenanceWindow pauses pipelines from Start (inclusive) until End (exclusive).
+type MaintenanceWindow struct {
+	// Pipelines are the paused pipelines, all of them if empty.
+	Pipelines []string `json:"pipelines,omitempty"`
+	// Start and End are RFC 3339 times, e.g. 2024-05-01T08:00:00Z.
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func (c *MaintenanceConfig) validate() error {
+	for i, window := range c.Windows {
+		if !window.End.After(window.Start) {
+			return fmt.Errorf("maintenance window %d ends at %v, not after its start %v", i, window.End, window.Start)
+		}
+		for _, pipeline := range window.Pipelines {
+			if !IsPipeline(pipeline) {
+				return fmt.Errorf("maintenance window %d pauses the unknown pipeline %q, known ones are %v", i, pipeline, Pipelines)
+			}
+		}
+	}
+	return nil
 }
 
 func (c *Config) validate() error {
 	if maxChunkPerBatch := c.L2Config.BatchProposerConfig.MaxChunkNumPerBatch; maxChunkPerBatch <= 0 {
 		return fmt.Errorf("Invalid max_chunk_num_per_batch configuration: %v", maxChunkPerBatch)
 	}
+	if c.Maintenance != nil {
+		if err := c.Maintenance.validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -29,6 +131,10 @@ func NewConfig(file string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	buf, err = secrets.ResolveJSON(buf)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{}
 	err = json.Unmarshal(buf, cfg)
@@ -41,3 +147,40 @@ func NewConfig(file string) (*Config, error) {
 	}
 	return cfg, nil
 }
+
+// ApplyNetwork fills the contract addresses and start height left empty in the config from the network profile.
+func (c *Config) ApplyNetwork(network *chains.Network) {
+	if network == nil {
+		return
+	}
+	addressOr := func(addr *common.Address, fallback common.Address) {
+		if *addr == (common.Address{}) {
+			*addr = fallback
+		}
+	}
+	if c.L1Config != nil {
+		if c.L1Config.StartHeight == 0 {
+			c.L1Config.StartHeight = network.L1StartHeight
+		}
+		addressOr(&c.L1Config.L1MessageQueueAddress, network.L1Contracts.MessageQueue)
+		addressOr(&c.L1Config.ScrollChainContractAddress, network.L1Contracts.ScrollChain)
+		if c.L1Config.RelayerConfig != nil {
+			// the l1 relayer updates the L1 gas price oracle deployed on L2
+			addressOr(&c.L1Config.RelayerConfig.GasPriceOracleContractAddress, network.L2Contracts.L1GasPriceOracle)
+		}
+	}
+	if c.L2Config != nil {
+		addressOr(&c.L2Config.L2MessageQueueAddress, network.L2Contracts.MessageQueue)
+		if c.L2Config.ChunkProposerConfig != nil {
+			c.L2Config.ChunkProposerConfig.ForkHeights = network.FillForkHeights(c.L2Config.ChunkProposerConfig.ForkHeights)
+		}
+		if c.L2Config.RelayerConfig != nil {
+			addressOr(&c.L2Config.RelayerConfig.RollupContractAddress, network.L1Contracts.ScrollChain)
+			if policy := c.L2Config.RelayerConfig.SkippedMessagePolicy; policy != nil {
+				addressOr(&policy.L1ScrollMessengerAddress, network.L1Contracts.Messenger)
+				addressOr(&policy.L1MessageQueueAddress, network.L1Contracts.MessageQueue)
+				addressOr(&policy.L2ScrollMessengerAddress, network.L2Contracts.Messenger)
+			}
+		}
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/chains"
 )
 
 func TestConfig(t *testing.T) {
@@ -56,3 +59,42 @@ func TestConfig(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestApplyNetwork(t *testing.T) {
+	cfg, err := NewConfig("../../conf/config.json")
+	assert.NoError(t, err)
+
+	network, err := chains.Get(chains.Mainnet)
+	assert.NoError(t, err)
+
+	scrollChain := common.HexToAddress("0x1")
+	cfg.L1Config.ScrollChainContractAddress = scrollChain
+	cfg.ApplyNetwork(network)
+
+	// configured values take precedence over the network profile
+	assert.Equal(t, scrollChain, cfg.L1Config.ScrollChainContractAddress)
+	assert.Equal(t, network.L1Contracts.MessageQueue, cfg.L1Config.L1MessageQueueAddress)
+	assert.Equal(t, network.L1StartHeight, cfg.L1Config.StartHeight)
+	assert.Equal(t, network.L2Contracts.L1GasPriceOracle, cfg.L1Config.RelayerConfig.GasPriceOracleContractAddress)
+	assert.Equal(t, network.L2Contracts.MessageQueue, cfg.L2Config.L2MessageQueueAddress)
+	assert.Equal(t, network.L1Contracts.ScrollChain, cfg.L2Config.RelayerConfig.RollupContractAddress)
+	assert.Equal(t, network.L1Contracts.Messenger, cfg.L2Config.RelayerConfig.SkippedMessagePolicy.L1ScrollMessengerAddress)
+	assert.Equal(t, network.L2Contracts.Messenger, cfg.L2Config.RelayerConfig.SkippedMessagePolicy.L2ScrollMessengerAddress)
+	assert.Equal(t, network.L2ForkHeights, cfg.L2Config.ChunkProposerConfig.ForkHeights)
+}
+
+func TestMaintenanceConfigValidate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	cfg := &MaintenanceConfig{Windows: []*MaintenanceWindow{
+		{Start: start, End: start.Add(time.Hour)},
+		{Pipelines: []string{PipelineCommit, PipelineFinalize}, Start: start, End: start.Add(time.Hour)},
+	}}
+	assert.NoError(t, cfg.validate())
+
+	cfg.Windows[0].End = start
+	assert.ErrorContains(t, cfg.validate(), "maintenance window 0 ends at")
+
+	cfg.Windows[0].End = start.Add(time.Hour)
+	cfg.Windows[1].Pipelines = []string{"batch_commit"}
+	assert.ErrorContains(t, cfg.validate(), `unknown pipeline "batch_commit"`)
+}
@@ -22,6 +22,19 @@ type L2Config struct {
 	ChunkProposerConfig *ChunkProposerConfig `json:"chunk_proposer_config"`
 	// The batch_proposer config
 	BatchProposerConfig *BatchProposerConfig `json:"batch_proposer_config"`
+	// The l2 block header indexer config, optional.
+	BlockHeaderIndexerConfig *L2BlockHeaderIndexerConfig `json:"block_header_indexer_config,omitempty"`
+}
+
+// L2BlockHeaderIndexerConfig loads l2 block header indexer configuration items.
+type L2BlockHeaderIndexerConfig struct {
+	Enabled bool `json:"enabled"`
+	// The first indexed block when nothing is indexed yet, defaults to the latest fetch_limit confirmed blocks.
+	StartHeight uint64 `json:"start_height,omitempty"`
+	// The max number of blocks fetched per run, defaults to 100.
+	FetchLimit uint64 `json:"fetch_limit,omitempty"`
+	// The number of latest blocks kept, older ones are deleted, all blocks are kept if 0.
+	RetentionBlocks uint64 `json:"retention_blocks,omitempty"`
 }
 
 // ChunkProposerConfig loads chunk_proposer configuration items.
@@ -33,6 +46,9 @@ type ChunkProposerConfig struct {
 	ChunkTimeoutSec                 uint64  `json:"chunk_timeout_sec"`
 	MaxRowConsumptionPerChunk       uint64  `json:"max_row_consumption_per_chunk"`
 	GasCostIncreaseMultiplier       float64 `json:"gas_cost_increase_multiplier"`
+	// ForkHeights maps hard fork names to their activation heights, overriding or extending the fork heights of the
+	// chain config like the coordinator's l2.fork_heights, so chunks are cut at the heights the coordinator proves with.
+	ForkHeights map[string]uint64 `json:"fork_heights,omitempty"`
 }
 
 // BatchProposerConfig loads batch_proposer configuration items.
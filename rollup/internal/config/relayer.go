@@ -3,7 +3,9 @@ package config
 import (
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/crypto"
@@ -30,6 +32,50 @@ type SenderConfig struct {
 	MaxBlobGasPrice uint64 `json:"max_blob_gas_price"`
 	// The transaction type to use: LegacyTx, DynamicFeeTx, BlobTx
 	TxType string `json:"tx_type"`
+	// Submitters maps sender names (e.g. commit_sender, finalize_sender, gas_oracle_sender) to alternative endpoints
+	// their transactions are submitted to instead of Endpoint, which is still used to read chain data.
+	Submitters map[string]*SubmitterConfig `json:"submitters,omitempty"`
+	// Pool configures the senders distributing their transactions over multiple accounts.
+	Pool *SenderPoolConfig `json:"pool,omitempty"`
+	// The maximum number of unconfirmed transactions of an account, new transactions are rejected until some are confirmed. 0 means no limit.
+	MaxPendingTransactions uint64 `json:"max_pending_transactions,omitempty"`
+	// SendRetry configures the retries of the transient failures of submitting a transaction, e.g. timeouts or 5xx responses.
+	SendRetry *SendRetryConfig `json:"send_retry,omitempty"`
+}
+
+// SendRetryConfig is the config of the retries of submitting a transaction, with exponential backoff and jitter.
+type SendRetryConfig struct {
+	// The max number of attempts including the first one, defaults to 3, 1 disables the retries.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// The backoff in milliseconds after the first failure, doubled after each retry, defaults to 200.
+	InitialBackoffMs uint64 `json:"initial_backoff_ms,omitempty"`
+	// The total time in milliseconds of the attempts and their backoffs, defaults to 10000.
+	BudgetMs uint64 `json:"budget_ms,omitempty"`
+}
+
+// SenderPoolConfig is the config of a sender pool, which distributes transactions over multiple accounts.
+type SenderPoolConfig struct {
+	// The seconds after which an account with an unconfirmed transaction is considered stuck and takes no new transactions, defaults to 600.
+	StuckTimeoutSec uint64 `json:"stuck_timeout_sec,omitempty"`
+	// The interval in seconds of checking the balances and states of the accounts, defaults to 60.
+	RebalanceIntervalSec uint64 `json:"rebalance_interval_sec,omitempty"`
+	// The balance in wei below which an account is reported as running low on funds.
+	MinBalance *big.Int `json:"min_balance,omitempty"`
+}
+
+// SubmitterConfig is the config of an alternative transaction submission endpoint, e.g. a private RPC or an external tx-service.
+type SubmitterConfig struct {
+	// The JSON-RPC endpoint transactions are submitted to.
+	Endpoint string `json:"endpoint"`
+	// The method used to submit raw transactions: eth_sendRawTransaction (default) or eth_sendPrivateTransaction.
+	Method string `json:"method,omitempty"`
+	// Headers attached to every request, e.g. to authenticate with an external tx-service.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Whether to sign every request with the sender key in the X-Flashbots-Signature header, as Flashbots requires.
+	// Only supported by http endpoints.
+	SignRequests bool `json:"sign_requests,omitempty"`
+	// Whether to submit through Endpoint of the sender when the submitter fails.
+	FallbackToPublic bool `json:"fallback_to_public"`
 }
 
 // ChainMonitor this config is used to get batch status from chain_monitor API.
@@ -55,10 +101,20 @@ type RelayerConfig struct {
 	ChainMonitor *ChainMonitor `json:"chain_monitor"`
 	// L1CommitGasLimitMultiplier multiplier for fallback gas limit in commitBatch txs
 	L1CommitGasLimitMultiplier float64 `json:"l1_commit_gas_limit_multiplier,omitempty"`
+	// MaxCommitCalldataSize is the maximum calldata size of a commitBatch tx, oversized batches are split before sending.
+	// Defaults to a size below the 128KB tx size limit of the L1 tx pool.
+	MaxCommitCalldataSize uint64 `json:"max_commit_calldata_size,omitempty"`
+	// SkippedMessagePolicy config of replaying L1 messages skipped by the sequencer, only used in the rollup relayer.
+	SkippedMessagePolicy *SkippedMessagePolicyConfig `json:"skipped_message_policy,omitempty"`
+	// FinalizeRootCheck config of cross-checking batch roots against l2geth before finalizing batches.
+	FinalizeRootCheck *FinalizeRootCheckConfig `json:"finalize_root_check,omitempty"`
 	// The private key of the relayer
 	GasOracleSenderPrivateKey *ecdsa.PrivateKey `json:"-"`
 	CommitSenderPrivateKey    *ecdsa.PrivateKey `json:"-"`
 	FinalizeSenderPrivateKey  *ecdsa.PrivateKey `json:"-"`
+	ReplaySenderPrivateKey    *ecdsa.PrivateKey `json:"-"`
+	// The private keys of additional replay accounts, replays are distributed over them and the replay sender.
+	ReplaySenderPoolPrivateKeys []*ecdsa.PrivateKey `json:"-"`
 
 	// Indicates if bypass features specific to testing environments are enabled.
 	EnableTestEnvBypassFeatures bool `json:"enable_test_env_bypass_features"`
@@ -72,6 +128,73 @@ type GasOracleConfig struct {
 	MinGasPrice uint64 `json:"min_gas_price"`
 	// GasPriceDiff store the percentage of gas price difference.
 	GasPriceDiff uint64 `json:"gas_price_diff"`
+	// L2BaseFeeWindow is the number of latest indexed l2 blocks whose average base fee is set as the l2 base fee,
+	// requires the l2 block header indexer. The suggested gas price of l2geth is used if 0 or no block has a base fee.
+	L2BaseFeeWindow uint64 `json:"l2_base_fee_window,omitempty"`
+	// L2PriorityFeeTip is the tip (in wei) added to the average base fee of L2BaseFeeWindow, so that the gas price
+	// also covers a priority fee. Opt-in, 0 adds none, it is not added to the suggested gas price of l2geth.
+	L2PriorityFeeTip uint64 `json:"l2_priority_fee_tip,omitempty"`
+	// Bounds rejects the gas prices outside sanity bounds before they are set, e.g. absurd base fees briefly reported
+	// by a bad rpc, so that they do not poison the fee estimation of L2. Opt-in, no gas price is rejected if not set.
+	Bounds *GasOracleBoundsConfig `json:"bounds,omitempty"`
+}
+
+// GasOracleBoundsConfig The config of the sanity bounds of the gas prices set in the gas price oracle, a rejected gas
+// price is logged, counted and not set.
+type GasOracleBoundsConfig struct {
+	// MinGasPrice and MaxGasPrice bound the gas price in wei, 0 for no bound. Unlike the MinGasPrice of
+	// GasOracleConfig, which skips updates, a gas price below MinGasPrice is rejected as an anomaly.
+	MinGasPrice uint64 `json:"min_gas_price,omitempty"`
+	MaxGasPrice uint64 `json:"max_gas_price,omitempty"`
+	// MaxChangeRatio bounds the change from the last gas price set, e.g. 4 rejects a gas price over 4 times or below
+	// a fourth of it. It must be greater than 1, 0 for no bound.
+	MaxChangeRatio float64 `json:"max_change_ratio,omitempty"`
+	// MaxChangeRejections is the number of consecutive gas prices rejected for their change after which the gas price
+	// is set, as the gas price persistently moved that far, e.g. while the relayer was down. Defaults to 10.
+	MaxChangeRejections uint64 `json:"max_change_rejections,omitempty"`
+}
+
+// SkippedMessagePolicyConfig The config for replaying L1 messages skipped by the sequencer.
+type SkippedMessagePolicyConfig struct {
+	Enabled bool `json:"enabled"`
+	// The L1ScrollMessenger contract address, skipped messages are replayed through it.
+	L1ScrollMessengerAddress common.Address `json:"l1_scroll_messenger_address"`
+	// The L1MessageQueue contract address, used to estimate the replay fee.
+	L1MessageQueueAddress common.Address `json:"l1_message_queue_address"`
+	// The L2ScrollMessenger contract address, only messages sent to it are replayable.
+	L2ScrollMessengerAddress common.Address `json:"l2_scroll_messenger_address"`
+	// The gas cap of L2 blocks, messages with a higher gas limit are skipped because of it.
+	L2BlockGasLimit uint64 `json:"l2_block_gas_limit"`
+	// The gas limit of automatic replays, defaults to L2BlockGasLimit.
+	ReplayGasLimit uint64 `json:"replay_gas_limit,omitempty"`
+	// The maximum number of automatic replays of a message, defaults to 1.
+	MaxReplays int16 `json:"max_replays,omitempty"`
+	// The maximum fee in wei paid for an automatic replay, automatic replays wait while the fee is higher, 0 disables them.
+	MaxReplayFee uint64 `json:"max_replay_fee"`
+	// The address refunded the excess replay fee, defaults to the replay sender.
+	RefundAddress common.Address `json:"refund_address,omitempty"`
+	// The estimation of the gas limit of each automatic replay, ReplayGasLimit is used if it is not enabled.
+	GasEstimation *ReplayGasEstimationConfig `json:"gas_estimation,omitempty"`
+}
+
+// ReplayGasEstimationConfig The config for estimating the gas limit of the automatic replay of a skipped message by
+// simulating its relayMessage call on L2 with eth_estimateGas. ReplayGasLimit is used when the simulation fails,
+// e.g. the call reverts.
+type ReplayGasEstimationConfig struct {
+	Enabled bool `json:"enabled"`
+	// The multiplier of the estimated gas, a margin for the state changes until the replay is executed, defaults to 1.5.
+	GasLimitMultiplier float64 `json:"gas_limit_multiplier,omitempty"`
+	// The minimum gas limit of estimated replays.
+	MinGasLimit uint64 `json:"min_gas_limit,omitempty"`
+	// The maximum gas limit of estimated replays, defaults to L2BlockGasLimit.
+	MaxGasLimit uint64 `json:"max_gas_limit,omitempty"`
+}
+
+// FinalizeRootCheckConfig The config for cross-checking the post-state root and withdraw root of a batch
+// against the ones of its last block in l2geth before finalizing the batch. The withdraw root is read
+// with the l2_message_queue_address and withdraw_trie_root_slot of the l2 config.
+type FinalizeRootCheckConfig struct {
+	Enabled bool `json:"enabled"`
 }
 
 // relayerConfigAlias RelayerConfig alias name
@@ -103,6 +226,9 @@ func (r *RelayerConfig) UnmarshalJSON(input []byte) error {
 		GasOracleSenderPrivateKey string `json:"gas_oracle_sender_private_key"`
 		CommitSenderPrivateKey    string `json:"commit_sender_private_key"`
 		FinalizeSenderPrivateKey  string `json:"finalize_sender_private_key"`
+		ReplaySenderPrivateKey    string `json:"replay_sender_private_key"`
+		// The private keys of additional replay accounts
+		ReplaySenderPoolPrivateKeys []string `json:"replay_sender_pool_private_keys"`
 	}
 	var err error
 	if err = json.Unmarshal(input, &privateKeysConfig); err != nil {
@@ -128,6 +254,22 @@ func (r *RelayerConfig) UnmarshalJSON(input []byte) error {
 		return fmt.Errorf("error converting and checking finalize sender private key: %w", err)
 	}
 
+	r.ReplaySenderPrivateKey, err = convertAndCheck(privateKeysConfig.ReplaySenderPrivateKey, uniqueAddressesSet)
+	if err != nil {
+		return fmt.Errorf("error converting and checking replay sender private key: %w", err)
+	}
+
+	for _, key := range privateKeysConfig.ReplaySenderPoolPrivateKeys {
+		privKey, err := convertAndCheck(key, uniqueAddressesSet)
+		if err != nil {
+			return fmt.Errorf("error converting and checking replay sender pool private key: %w", err)
+		}
+		if privKey == nil {
+			return errors.New("empty replay sender pool private key")
+		}
+		r.ReplaySenderPoolPrivateKeys = append(r.ReplaySenderPoolPrivateKeys, privKey)
+	}
+
 	return nil
 }
 
@@ -139,12 +281,21 @@ func (r *RelayerConfig) MarshalJSON() ([]byte, error) {
 		GasOracleSenderPrivateKey string `json:"gas_oracle_sender_private_key"`
 		CommitSenderPrivateKey    string `json:"commit_sender_private_key"`
 		FinalizeSenderPrivateKey  string `json:"finalize_sender_private_key"`
+		ReplaySenderPrivateKey    string `json:"replay_sender_private_key,omitempty"`
+		// The private keys of additional replay accounts
+		ReplaySenderPoolPrivateKeys []string `json:"replay_sender_pool_private_keys,omitempty"`
 	}{}
 
 	privateKeysConfig.relayerConfigAlias = relayerConfigAlias(*r)
 	privateKeysConfig.GasOracleSenderPrivateKey = common.Bytes2Hex(crypto.FromECDSA(r.GasOracleSenderPrivateKey))
 	privateKeysConfig.CommitSenderPrivateKey = common.Bytes2Hex(crypto.FromECDSA(r.CommitSenderPrivateKey))
 	privateKeysConfig.FinalizeSenderPrivateKey = common.Bytes2Hex(crypto.FromECDSA(r.FinalizeSenderPrivateKey))
+	if r.ReplaySenderPrivateKey != nil {
+		privateKeysConfig.ReplaySenderPrivateKey = common.Bytes2Hex(crypto.FromECDSA(r.ReplaySenderPrivateKey))
+	}
+	for _, key := range r.ReplaySenderPoolPrivateKeys {
+		privateKeysConfig.ReplaySenderPoolPrivateKeys = append(privateKeysConfig.ReplaySenderPoolPrivateKeys, common.Bytes2Hex(crypto.FromECDSA(key)))
+	}
 
 	return json.Marshal(&privateKeysConfig)
 }
@@ -0,0 +1,179 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types/encoding"
+	"scroll-tech/common/types/encoding/codecv0"
+	"scroll-tech/common/types/encoding/codecv1"
+
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/utils"
+)
+
+const (
+	// defaultMaxCommitCalldataSize leaves room for the rest of the tx below the 128KB tx size limit of the L1 tx pool.
+	defaultMaxCommitCalldataSize = uint64(126 * 1024)
+
+	// maxBlobSize is the size of a single blob, a commitBatch tx carries at most one blob.
+	maxBlobSize = uint64(131072)
+)
+
+// errCommitPayloadTooLarge indicates that a commitBatch payload exceeds L1 limits.
+var errCommitPayloadTooLarge = errors.New("commit payload too large")
+
+// checkCommitPayloadSize checks the calldata and blob sizes of a commitBatch tx against L1 limits.
+func checkCommitPayloadSize(calldataSize, blobSize, maxCalldataSize uint64) error {
+	if calldataSize > maxCalldataSize {
+		return fmt.Errorf("%w: calldata size %v exceeds limit %v", errCommitPayloadTooLarge, calldataSize, maxCalldataSize)
+	}
+	if blobSize > maxBlobSize {
+		return fmt.Errorf("%w: blob size %v exceeds limit %v", errCommitPayloadTooLarge, blobSize, maxBlobSize)
+	}
+	return nil
+}
+
+// findMaxCommittableChunks returns the largest number of leading chunks, below numChunks, whose commit payload fits,
+// or 0 if even the first chunk does not fit. The payload size grows with the number of chunks, so a binary search suffices.
+func findMaxCommittableChunks(numChunks int, fits func(numChunks int) (bool, error)) (int, error) {
+	// invariant: lo chunks fit, hi chunks do not
+	lo, hi := 0, numChunks
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := fits(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// estimateCommitPayloadSize returns the calldata and blob sizes of the commitBatch tx of a batch.
+// The calldata size is exact, the blob size is estimated. The calldata size is not computed if the blob is already too large.
+func (r *Layer2Relayer) estimateCommitPayloadSize(batch *encoding.Batch, dbChunks []*orm.Chunk, parentBatchHeader []byte, codecVersion encoding.CodecVersion) (uint64, uint64, error) {
+	metrics, err := utils.CalculateBatchMetrics(batch, codecVersion)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to calculate batch metrics: %w", err)
+	}
+	if metrics.L1CommitBlobSize > maxBlobSize {
+		return 0, metrics.L1CommitBlobSize, nil
+	}
+
+	var version uint8
+	var skippedL1MessageBitmap []byte
+	encodedChunks := make([][]byte, len(batch.Chunks))
+	switch codecVersion {
+	case encoding.CodecV0:
+		daBatch, createErr := codecv0.NewDABatch(batch)
+		if createErr != nil {
+			return 0, 0, fmt.Errorf("failed to create DA batch: %w", createErr)
+		}
+		version, skippedL1MessageBitmap = daBatch.Version, daBatch.SkippedL1MessageBitmap
+		for i, chunk := range batch.Chunks {
+			daChunk, createErr := codecv0.NewDAChunk(chunk, dbChunks[i].TotalL1MessagesPoppedBefore)
+			if createErr != nil {
+				return 0, 0, fmt.Errorf("failed to create DA chunk: %w", createErr)
+			}
+			if encodedChunks[i], err = daChunk.Encode(); err != nil {
+				return 0, 0, fmt.Errorf("failed to encode DA chunk: %w", err)
+			}
+		}
+	case encoding.CodecV1:
+		daBatch, createErr := codecv1.NewDABatch(batch)
+		if createErr != nil {
+			return 0, 0, fmt.Errorf("failed to create DA batch: %w", createErr)
+		}
+		version, skippedL1MessageBitmap = daBatch.Version, daBatch.SkippedL1MessageBitmap
+		for i, chunk := range batch.Chunks {
+			daChunk, createErr := codecv1.NewDAChunk(chunk, dbChunks[i].TotalL1MessagesPoppedBefore)
+			if createErr != nil {
+				return 0, 0, fmt.Errorf("failed to create DA chunk: %w", createErr)
+			}
+			encodedChunks[i] = daChunk.Encode()
+		}
+	default:
+		return 0, 0, fmt.Errorf("unsupported codec version: %v", codecVersion)
+	}
+
+	calldata, err := r.l1RollupABI.Pack("commitBatch", version, parentBatchHeader, encodedChunks, skippedL1MessageBitmap)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to pack commitBatch: %w", err)
+	}
+	return uint64(len(calldata)), metrics.L1CommitBlobSize, nil
+}
+
+// splitOversizedBatch replaces an uncommitted batch whose commit payload exceeds L1 limits with a batch of its
+// largest committable prefix of chunks. The later batches are removed as well since they are chained to the
+// replaced batch, their chunks are left unbatched for the batch proposer to propose again.
+func (r *Layer2Relayer) splitOversizedBatch(dbBatch *orm.Batch, dbParentBatch *orm.Batch, dbChunks []*orm.Chunk, chunks []*encoding.Chunk, codecVersion encoding.CodecVersion) error {
+	newBatch := func(numChunks int) *encoding.Batch {
+		return &encoding.Batch{
+			Index:                      dbBatch.Index,
+			TotalL1MessagePoppedBefore: dbChunks[0].TotalL1MessagesPoppedBefore,
+			ParentBatchHash:            common.HexToHash(dbParentBatch.Hash),
+			Chunks:                     chunks[:numChunks],
+		}
+	}
+
+	numChunks, err := findMaxCommittableChunks(len(chunks), func(numChunks int) (bool, error) {
+		calldataSize, blobSize, estimateErr := r.estimateCommitPayloadSize(newBatch(numChunks), dbChunks[:numChunks], dbParentBatch.BatchHeader, codecVersion)
+		if estimateErr != nil {
+			return false, estimateErr
+		}
+		return checkCommitPayloadSize(calldataSize, blobSize, r.maxCommitCalldataSize()) == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find the committable chunks: %w", err)
+	}
+	if numChunks == 0 {
+		// The first chunk exceeds hard limits, which indicates a bug in the chunk-proposer, manual fix is needed.
+		return fmt.Errorf("the first chunk of batch %v exceeds commit limits, start block number: %v, end block number: %v",
+			dbBatch.Index, dbChunks[0].StartBlockNumber, dbChunks[0].EndBlockNumber)
+	}
+
+	return r.db.Transaction(func(dbTX *gorm.DB) error {
+		// lock the replaced batches, the batch proposer locks the parent of the batch it inserts, so it either
+		// chains to a batch before it is replaced and the batch is removed below, or fails and proposes again.
+		lockedBatches, err := r.batchOrm.GetBatchesGEIndexForUpdate(r.ctx, dbBatch.Index, dbTX)
+		if err != nil {
+			return err
+		}
+		if len(lockedBatches) == 0 || lockedBatches[0].Hash != dbBatch.Hash {
+			return fmt.Errorf("batch %v changed while splitting it, hash: %v", dbBatch.Index, dbBatch.Hash)
+		}
+
+		if err := r.batchOrm.DeleteUncommittedBatchesGEIndex(r.ctx, dbBatch.Index, dbTX); err != nil {
+			return err
+		}
+		if err := r.chunkOrm.ResetBatchHashGEIndex(r.ctx, dbBatch.StartChunkIndex, dbTX); err != nil {
+			return err
+		}
+		splitBatch, err := r.batchOrm.InsertBatch(r.ctx, newBatch(numChunks), codecVersion, dbTX)
+		if err != nil {
+			return err
+		}
+		if err := r.chunkOrm.UpdateBatchHashInRange(r.ctx, splitBatch.StartChunkIndex, splitBatch.EndChunkIndex, splitBatch.Hash, dbTX); err != nil {
+			return err
+		}
+		log.Info("split oversized batch", "index", dbBatch.Index, "old hash", dbBatch.Hash, "new hash", splitBatch.Hash,
+			"old chunk count", len(chunks), "new chunk count", numChunks)
+		return nil
+	})
+}
+
+func (r *Layer2Relayer) maxCommitCalldataSize() uint64 {
+	if r.cfg.MaxCommitCalldataSize > 0 {
+		return r.cfg.MaxCommitCalldataSize
+	}
+	return defaultMaxCommitCalldataSize
+}
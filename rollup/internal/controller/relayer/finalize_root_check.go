@@ -0,0 +1,50 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// errFinalizeRootMismatch indicates that the roots of a batch in the db differ from the ones of l2geth.
+var errFinalizeRootMismatch = errors.New("finalize root mismatch")
+
+// checkFinalizeRoots cross-checks the post-state root and withdraw root of a batch against the ones of its last block
+// in l2geth, so that a batch with roots corrupted in the db is never finalized. Without an L2MessageQueue address
+// only the post-state root is checked.
+func (r *Layer2Relayer) checkFinalizeRoots(dbBatch *orm.Batch, dbChunks []*orm.Chunk) error {
+	endBlockNumber := new(big.Int).SetUint64(dbChunks[len(dbChunks)-1].EndBlockNumber)
+
+	header, err := r.l2Client.HeaderByNumber(r.ctx, endBlockNumber)
+	if err != nil {
+		r.metrics.rollupL2RelayerFinalizeRootCheckFailureTotal.Inc()
+		return fmt.Errorf("failed to get header of block %v: %w", endBlockNumber, err)
+	}
+	withdrawRoot := common.HexToHash(dbBatch.WithdrawRoot)
+	if r.l2MessageQueueAddress != (common.Address{}) {
+		storage, storageErr := r.l2Client.StorageAt(r.ctx, r.l2MessageQueueAddress, r.withdrawTrieRootSlot, endBlockNumber)
+		if storageErr != nil {
+			r.metrics.rollupL2RelayerFinalizeRootCheckFailureTotal.Inc()
+			return fmt.Errorf("failed to get withdraw root of block %v: %w", endBlockNumber, storageErr)
+		}
+		withdrawRoot = common.BytesToHash(storage)
+	}
+
+	stateRootMatches := header.Root == common.HexToHash(dbBatch.StateRoot)
+	withdrawRootMatches := withdrawRoot == common.HexToHash(dbBatch.WithdrawRoot)
+	if stateRootMatches && withdrawRootMatches {
+		return nil
+	}
+
+	r.metrics.rollupL2RelayerFinalizeRootMismatchTotal.Inc()
+	log.Error("CRITICAL: batch roots differ from l2geth, refuse to finalize, manual fix is needed",
+		"index", dbBatch.Index, "hash", dbBatch.Hash, "end block number", endBlockNumber,
+		"state root", dbBatch.StateRoot, "l2geth state root", header.Root.Hex(),
+		"withdraw root", dbBatch.WithdrawRoot, "l2geth withdraw root", withdrawRoot.Hex())
+	return fmt.Errorf("%w: batch index %v, end block number %v", errFinalizeRootMismatch, dbBatch.Index, endBlockNumber)
+}
@@ -0,0 +1,85 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+const (
+	// defaultMaxGasPriceChangeRejections is the number of consecutive gas prices rejected for their change after
+	// which the gas price is set, about the gas oracle updates of a couple of minutes.
+	defaultMaxGasPriceChangeRejections = 10
+
+	gasPriceRejectReasonBelowMin = "below_min"
+	gasPriceRejectReasonAboveMax = "above_max"
+	gasPriceRejectReasonChange   = "change"
+)
+
+// errGasPriceOutOfBounds indicates that a gas price is outside the sanity bounds of the gas price oracle.
+var errGasPriceOutOfBounds = errors.New("gas price out of bounds")
+
+// gasPriceBounds rejects the gas prices outside the sanity bounds of a gas price oracle before they are set.
+type gasPriceBounds struct {
+	cfg *config.GasOracleBoundsConfig // nil if no gas price is rejected
+
+	// changeRejections is the number of consecutive gas prices rejected for their change from the last gas price set.
+	changeRejections uint64
+
+	rejectedTotal *prometheus.CounterVec
+}
+
+func newGasPriceBounds(cfg *config.GasOracleConfig, rejectedTotal *prometheus.CounterVec) *gasPriceBounds {
+	bounds := &gasPriceBounds{rejectedTotal: rejectedTotal}
+	if cfg != nil {
+		bounds.cfg = cfg.Bounds
+	}
+	return bounds
+}
+
+// check returns an error if the gas price is outside the bounds, or changes too fast from the last gas price set, 0 if
+// none was set since the relayer started. A change rejected more than MaxChangeRejections consecutive times is
+// accepted, the gas price is not an anomaly then.
+func (b *gasPriceBounds) check(gasPrice, lastGasPrice uint64) error {
+	if b.cfg == nil {
+		return nil
+	}
+	if b.cfg.MinGasPrice > 0 && gasPrice < b.cfg.MinGasPrice {
+		return b.reject(gasPriceRejectReasonBelowMin, fmt.Errorf("%w: gas price %v below minimum %v", errGasPriceOutOfBounds, gasPrice, b.cfg.MinGasPrice))
+	}
+	if b.cfg.MaxGasPrice > 0 && gasPrice > b.cfg.MaxGasPrice {
+		return b.reject(gasPriceRejectReasonAboveMax, fmt.Errorf("%w: gas price %v above maximum %v", errGasPriceOutOfBounds, gasPrice, b.cfg.MaxGasPrice))
+	}
+	if b.cfg.MaxChangeRatio > 1 && lastGasPrice > 0 && changesFasterThan(gasPrice, lastGasPrice, b.cfg.MaxChangeRatio) {
+		b.changeRejections++
+		if b.changeRejections <= b.maxChangeRejections() {
+			return b.reject(gasPriceRejectReasonChange, fmt.Errorf("%w: gas price %v changes over %v times from last gas price %v",
+				errGasPriceOutOfBounds, gasPrice, b.cfg.MaxChangeRatio, lastGasPrice))
+		}
+		log.Warn("accept gas price changing over the max change ratio, it persisted", "gas price", gasPrice,
+			"last gas price", lastGasPrice, "rejections", b.changeRejections-1)
+	}
+	b.changeRejections = 0
+	return nil
+}
+
+func (b *gasPriceBounds) reject(reason string, err error) error {
+	b.rejectedTotal.WithLabelValues(reason).Inc()
+	return err
+}
+
+func (b *gasPriceBounds) maxChangeRejections() uint64 {
+	if b.cfg.MaxChangeRejections > 0 {
+		return b.cfg.MaxChangeRejections
+	}
+	return defaultMaxGasPriceChangeRejections
+}
+
+// changesFasterThan returns whether the gas price is over ratio times or below 1/ratio of the last gas price.
+func changesFasterThan(gasPrice, lastGasPrice uint64, ratio float64) bool {
+	return float64(gasPrice) > float64(lastGasPrice)*ratio || float64(gasPrice)*ratio < float64(lastGasPrice)
+}
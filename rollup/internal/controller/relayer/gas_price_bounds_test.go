@@ -0,0 +1,42 @@
+package relayer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func testGasPriceBounds(t *testing.T) {
+	rejectedTotal := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_gas_oracle_rejected_total"}, []string{"reason"})
+
+	// no gas price is rejected without bounds.
+	bounds := newGasPriceBounds(&config.GasOracleConfig{}, rejectedTotal)
+	assert.NoError(t, bounds.check(1, 0))
+	assert.NoError(t, bounds.check(1e18, 1))
+
+	bounds = newGasPriceBounds(&config.GasOracleConfig{Bounds: &config.GasOracleBoundsConfig{
+		MinGasPrice: 100, MaxGasPrice: 1e12, MaxChangeRatio: 4, MaxChangeRejections: 2,
+	}}, rejectedTotal)
+	assert.True(t, errors.Is(bounds.check(99, 0), errGasPriceOutOfBounds))
+	assert.True(t, errors.Is(bounds.check(1e12+1, 0), errGasPriceOutOfBounds))
+	assert.NoError(t, bounds.check(1e12, 0))
+	assert.Equal(t, 1.0, testutil.ToFloat64(rejectedTotal.WithLabelValues(gasPriceRejectReasonBelowMin)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(rejectedTotal.WithLabelValues(gasPriceRejectReasonAboveMax)))
+
+	// the change from the last gas price set is bounded both ways.
+	assert.NoError(t, bounds.check(4000, 1000))
+	assert.NoError(t, bounds.check(250, 1000))
+	assert.True(t, errors.Is(bounds.check(4001, 1000), errGasPriceOutOfBounds))
+	assert.True(t, errors.Is(bounds.check(249, 1000), errGasPriceOutOfBounds))
+	assert.Equal(t, 2.0, testutil.ToFloat64(rejectedTotal.WithLabelValues(gasPriceRejectReasonChange)))
+
+	// a change persisting over the max rejections is accepted, and the rejections start over.
+	assert.NoError(t, bounds.check(5000, 1000))
+	assert.True(t, errors.Is(bounds.check(20001, 5000), errGasPriceOutOfBounds))
+	assert.Equal(t, 3.0, testutil.ToFloat64(rejectedTotal.WithLabelValues(gasPriceRejectReasonChange)))
+}
@@ -37,6 +37,8 @@ type Layer1Relayer struct {
 	minGasPrice  uint64
 	gasPriceDiff uint64
 
+	gasPriceBounds *gasPriceBounds
+
 	l1BlockOrm *orm.L1Block
 	metrics    *l1RelayerMetrics
 }
@@ -85,6 +87,7 @@ func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfi
 	}
 
 	l1Relayer.metrics = initL1RelayerMetrics(reg)
+	l1Relayer.gasPriceBounds = newGasPriceBounds(cfg.GasOracleConfig, l1Relayer.metrics.rollupL1GasOracleRejectedTotal)
 
 	switch serviceType {
 	case ServiceTypeL1GasOracle:
@@ -125,6 +128,10 @@ func (r *Layer1Relayer) ProcessGasPriceOracle() {
 		}
 		// last is undefine or (block.BaseFee >= minGasPrice && exceed diff)
 		if r.lastGasPrice == 0 || (block.BaseFee >= r.minGasPrice && (block.BaseFee >= r.lastGasPrice+expectedDelta || block.BaseFee <= r.lastGasPrice-expectedDelta)) {
+			if err := r.gasPriceBounds.check(block.BaseFee, r.lastGasPrice); err != nil {
+				log.Error("Reject l1 base fee", "block.Hash", block.Hash, "block.Height", block.Number, "block.BaseFee", block.BaseFee, "err", err)
+				return
+			}
 			baseFee := big.NewInt(int64(block.BaseFee))
 			data, err := r.l1GasOracleABI.Pack("setL1BaseFee", baseFee)
 			if err != nil {
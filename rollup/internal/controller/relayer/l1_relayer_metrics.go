@@ -12,6 +12,7 @@ type l1RelayerMetrics struct {
 	rollupL1RelayerLastGasPrice                 prometheus.Gauge
 	rollupL1UpdateGasOracleConfirmedTotal       prometheus.Counter
 	rollupL1UpdateGasOracleConfirmedFailedTotal prometheus.Counter
+	rollupL1GasOracleRejectedTotal              *prometheus.CounterVec
 }
 
 var (
@@ -38,6 +39,10 @@ func initL1RelayerMetrics(reg prometheus.Registerer) *l1RelayerMetrics {
 				Name: "rollup_layer1_update_gas_oracle_confirmed_failed_total",
 				Help: "The total number of updating layer1 gas oracle confirmed failed",
 			}),
+			rollupL1GasOracleRejectedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_oracle_rejected_total",
+				Help: "The total number of layer1 base fees rejected by the sanity bounds of the gas oracle",
+			}, []string{"reason"}),
 		}
 	})
 	return l1RelayerMetric
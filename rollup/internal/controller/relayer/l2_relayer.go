@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -48,6 +49,12 @@ type Layer2Relayer struct {
 	chunkOrm   *orm.Chunk
 	l2BlockOrm *orm.L2Block
 
+	l2BlockHeaderOrm *orm.L2BlockHeader
+
+	// Used to read the withdraw root of a block when cross-checking batch roots before finalizing.
+	l2MessageQueueAddress common.Address
+	withdrawTrieRootSlot  common.Hash
+
 	cfg *config.RelayerConfig
 
 	commitSender   *sender.Sender
@@ -57,9 +64,13 @@ type Layer2Relayer struct {
 	gasOracleSender *sender.Sender
 	l2GasOracleABI  *abi.ABI
 
-	lastGasPrice uint64
-	minGasPrice  uint64
-	gasPriceDiff uint64
+	lastGasPrice     uint64
+	minGasPrice      uint64
+	gasPriceDiff     uint64
+	l2BaseFeeWindow  uint64
+	l2PriorityFeeTip uint64
+
+	gasPriceBounds *gasPriceBounds
 
 	// Used to get batch status from chain_monitor api.
 	chainMonitorClient *resty.Client
@@ -70,7 +81,7 @@ type Layer2Relayer struct {
 }
 
 // NewLayer2Relayer will return a new instance of Layer2RelayerClient
-func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, chainCfg *params.ChainConfig, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer) (*Layer2Relayer, error) {
+func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, l2MessageQueueAddress common.Address, withdrawTrieRootSlot common.Hash, chainCfg *params.ChainConfig, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer) (*Layer2Relayer, error) {
 	var gasOracleSender, commitSender, finalizeSender *sender.Sender
 	var err error
 
@@ -111,9 +122,13 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 
 	var minGasPrice uint64
 	var gasPriceDiff uint64
+	var l2BaseFeeWindow uint64
+	var l2PriorityFeeTip uint64
 	if cfg.GasOracleConfig != nil {
 		minGasPrice = cfg.GasOracleConfig.MinGasPrice
 		gasPriceDiff = cfg.GasOracleConfig.GasPriceDiff
+		l2BaseFeeWindow = cfg.GasOracleConfig.L2BaseFeeWindow
+		l2PriorityFeeTip = cfg.GasOracleConfig.L2PriorityFeeTip
 	} else {
 		minGasPrice = 0
 		gasPriceDiff = defaultGasPriceDiff
@@ -127,6 +142,11 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		l2BlockOrm: orm.NewL2Block(db),
 		chunkOrm:   orm.NewChunk(db),
 
+		l2BlockHeaderOrm: orm.NewL2BlockHeader(db),
+
+		l2MessageQueueAddress: l2MessageQueueAddress,
+		withdrawTrieRootSlot:  withdrawTrieRootSlot,
+
 		l2Client: l2Client,
 
 		commitSender:   commitSender,
@@ -136,13 +156,19 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		gasOracleSender: gasOracleSender,
 		l2GasOracleABI:  bridgeAbi.L2GasPriceOracleABI,
 
-		minGasPrice:  minGasPrice,
-		gasPriceDiff: gasPriceDiff,
+		minGasPrice:      minGasPrice,
+		gasPriceDiff:     gasPriceDiff,
+		l2BaseFeeWindow:  l2BaseFeeWindow,
+		l2PriorityFeeTip: l2PriorityFeeTip,
 
 		cfg:      cfg,
 		chainCfg: chainCfg,
 	}
 
+	if cfg.FinalizeRootCheck != nil && cfg.FinalizeRootCheck.Enabled && l2MessageQueueAddress == (common.Address{}) {
+		log.Warn("l2_message_queue_address is not set, the finalize root check only checks the state root of batches")
+	}
+
 	// chain_monitor client
 	if cfg.ChainMonitor.Enabled {
 		layer2Relayer.chainMonitorClient = resty.New()
@@ -157,6 +183,7 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		}
 	}
 	layer2Relayer.metrics = initL2RelayerMetrics(reg)
+	layer2Relayer.gasPriceBounds = newGasPriceBounds(cfg.GasOracleConfig, layer2Relayer.metrics.rollupL2RelayerGasOracleRejectedTotal)
 
 	switch serviceType {
 	case ServiceTypeL2GasOracle:
@@ -297,7 +324,7 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 	}
 
 	if types.GasOracleStatus(batch.OracleStatus) == types.GasOraclePending {
-		suggestGasPrice, err := r.l2Client.SuggestGasPrice(r.ctx)
+		suggestGasPrice, err := r.suggestL2GasPrice()
 		if err != nil {
 			log.Error("Failed to fetch SuggestGasPrice from l2geth", "err", err)
 			return
@@ -310,6 +337,10 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 
 		// last is undefine or (suggestGasPriceUint64 >= minGasPrice && exceed diff)
 		if r.lastGasPrice == 0 || (suggestGasPriceUint64 >= r.minGasPrice && (suggestGasPriceUint64 >= r.lastGasPrice+expectedDelta || suggestGasPriceUint64 <= r.lastGasPrice-expectedDelta)) {
+			if err := r.gasPriceBounds.check(suggestGasPriceUint64, r.lastGasPrice); err != nil {
+				log.Error("Reject l2 gas price", "batch.Hash", batch.Hash, "GasPrice", suggestGasPriceUint64, "err", err)
+				return
+			}
 			data, err := r.l2GasOracleABI.Pack("setL2BaseFee", suggestGasPrice)
 			if err != nil {
 				log.Error("Failed to pack setL2BaseFee", "batch.Hash", batch.Hash, "GasPrice", suggestGasPrice.Uint64(), "err", err)
@@ -334,6 +365,38 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 	}
 }
 
+// suggestL2GasPrice returns the average base fee of the latest indexed l2 blocks plus the configured priority fee tip
+// if configured, or the suggested gas price of l2geth otherwise.
+func (r *Layer2Relayer) suggestL2GasPrice() (*big.Int, error) {
+	if r.l2BaseFeeWindow > 0 {
+		headers, err := r.l2BlockHeaderOrm.GetLatestL2BlockHeaders(r.ctx, int(r.l2BaseFeeWindow))
+		if err != nil {
+			log.Warn("Failed to get indexed l2 block headers, fallback to l2geth", "err", err)
+		} else if baseFee := averageBaseFee(headers); baseFee != nil {
+			return baseFee.Add(baseFee, new(big.Int).SetUint64(r.l2PriorityFeeTip)), nil
+		}
+	}
+	return r.l2Client.SuggestGasPrice(r.ctx)
+}
+
+// averageBaseFee returns the average base fee, rounded up, of the headers with a base fee, nil if there is none.
+func averageBaseFee(headers []*orm.L2BlockHeader) *big.Int {
+	sum := new(big.Int)
+	var count int64
+	for _, header := range headers {
+		if header.BaseFee == 0 {
+			continue
+		}
+		sum.Add(sum, new(big.Int).SetUint64(header.BaseFee))
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	sum.Add(sum, big.NewInt(count-1))
+	return sum.Div(sum, big.NewInt(count))
+}
+
 // ProcessPendingBatches processes the pending batches by sending commitBatch transactions to layer 1.
 func (r *Layer2Relayer) ProcessPendingBatches() {
 	// get pending batches from database in ascending order by their index.
@@ -372,9 +435,38 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 			return
 		}
 
+		codecVersion := encoding.CodecV0
+		if r.chainCfg.IsBernoulli(new(big.Int).SetUint64(dbChunks[0].StartBlockNumber)) {
+			codecVersion = encoding.CodecV1
+		}
+
+		// check the payload against L1 limits before sending, an oversized batch would never be committed.
+		batch := &encoding.Batch{
+			Index:                      dbBatch.Index,
+			TotalL1MessagePoppedBefore: dbChunks[0].TotalL1MessagesPoppedBefore,
+			ParentBatchHash:            common.HexToHash(dbParentBatch.Hash),
+			Chunks:                     chunks,
+		}
+		calldataSize, blobSize, err := r.estimateCommitPayloadSize(batch, dbChunks, dbParentBatch.BatchHeader, codecVersion)
+		if err != nil {
+			log.Error("failed to estimate commitBatch payload size", "index", dbBatch.Index, "err", err)
+			return
+		}
+		if sizeErr := checkCommitPayloadSize(calldataSize, blobSize, r.maxCommitCalldataSize()); sizeErr != nil {
+			log.Warn("commitBatch payload exceeds L1 limits, splitting the batch", "index", dbBatch.Index, "hash", dbBatch.Hash, "err", sizeErr)
+			if splitErr := r.splitOversizedBatch(dbBatch, dbParentBatch, dbChunks, chunks, codecVersion); splitErr != nil {
+				r.metrics.rollupL2RelayerSplitOversizedBatchFailureTotal.Inc()
+				log.Error("failed to split oversized batch", "index", dbBatch.Index, "hash", dbBatch.Hash, "err", splitErr)
+				return
+			}
+			r.metrics.rollupL2RelayerSplitOversizedBatchTotal.Inc()
+			// the later batches have been removed, they are fetched again in the next round.
+			return
+		}
+
 		var calldata []byte
 		var blob *kzg4844.Blob
-		if !r.chainCfg.IsBernoulli(new(big.Int).SetUint64(dbChunks[0].StartBlockNumber)) { // codecv0
+		if codecVersion == encoding.CodecV0 {
 			calldata, err = r.constructCommitBatchPayloadCodecV0(dbBatch, dbParentBatch, dbChunks, chunks)
 			if err != nil {
 				log.Error("failed to construct commitBatch payload codecv0", "index", dbBatch.Index, "err", err)
@@ -397,6 +489,11 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 		}
 
 		txHash, err := r.commitSender.SendTransaction(dbBatch.Hash, &r.cfg.RollupContractAddress, calldata, blob, fallbackGasLimit)
+		if errors.Is(err, sender.ErrTooManyPendingTransactions) {
+			// the commit sender is paused, the batch is committed once its pending transactions are confirmed.
+			log.Debug("commit sender paused, skip committing batch", "index", dbBatch.Index, "hash", dbBatch.Hash)
+			return
+		}
 		if err != nil {
 			log.Error(
 				"Failed to send commitBatch tx to layer1",
@@ -522,6 +619,12 @@ func (r *Layer2Relayer) finalizeBatch(dbBatch *orm.Batch, withProof bool) error
 		return fmt.Errorf("failed to fetch chunks: %w", err)
 	}
 
+	if r.cfg.FinalizeRootCheck != nil && r.cfg.FinalizeRootCheck.Enabled {
+		if err = r.checkFinalizeRoots(dbBatch, dbChunks); err != nil {
+			return fmt.Errorf("failed to check finalize roots, index: %d, err: %w", dbBatch.Index, err)
+		}
+	}
+
 	var aggProof *message.BatchProof
 	if withProof {
 		aggProof, getErr = r.batchOrm.GetVerifiedProofByHash(r.ctx, dbBatch.Hash)
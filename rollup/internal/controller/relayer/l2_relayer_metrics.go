@@ -23,6 +23,11 @@ type l2RelayerMetrics struct {
 	rollupL2UpdateGasOracleConfirmedFailedTotal                 prometheus.Counter
 	rollupL2ChainMonitorLatestFailedCall                        prometheus.Counter
 	rollupL2ChainMonitorLatestFailedBatchStatus                 prometheus.Counter
+	rollupL2RelayerSplitOversizedBatchTotal                     prometheus.Counter
+	rollupL2RelayerSplitOversizedBatchFailureTotal              prometheus.Counter
+	rollupL2RelayerFinalizeRootMismatchTotal                    prometheus.Counter
+	rollupL2RelayerFinalizeRootCheckFailureTotal                prometheus.Counter
+	rollupL2RelayerGasOracleRejectedTotal                       *prometheus.CounterVec
 }
 
 var (
@@ -93,6 +98,26 @@ func initL2RelayerMetrics(reg prometheus.Registerer) *l2RelayerMetrics {
 				Name: "rollup_layer2_chain_monitor_latest_failed_batch_status",
 				Help: "The total number of failed batch status get from chain_monitor",
 			}),
+			rollupL2RelayerSplitOversizedBatchTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_split_oversized_batch_total",
+				Help: "The total number of batches split because their commit payload exceeds L1 limits",
+			}),
+			rollupL2RelayerSplitOversizedBatchFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_split_oversized_batch_failure_total",
+				Help: "The total number of failures to split a batch whose commit payload exceeds L1 limits",
+			}),
+			rollupL2RelayerFinalizeRootMismatchTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_finalize_root_mismatch_total",
+				Help: "The total number of batches refused to finalize because their roots differ from l2geth",
+			}),
+			rollupL2RelayerFinalizeRootCheckFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_finalize_root_check_failure_total",
+				Help: "The total number of failures to get the roots of a batch from l2geth before finalizing it",
+			}),
+			rollupL2RelayerGasOracleRejectedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_layer2_gas_oracle_rejected_total",
+				Help: "The total number of layer2 gas prices rejected by the sanity bounds of the gas oracle",
+			}, []string{"reason"}),
 		}
 	})
 	return l2RelayerMetric
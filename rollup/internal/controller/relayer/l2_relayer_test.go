@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/gin-gonic/gin"
@@ -26,6 +27,7 @@ import (
 
 	"scroll-tech/database/migrate"
 
+	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/sender"
 	"scroll-tech/rollup/internal/orm"
 )
@@ -42,7 +44,7 @@ func setupL2RelayerDB(t *testing.T) *gorm.DB {
 func testCreateNewRelayer(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 	defer relayer.StopSenders()
@@ -60,7 +62,7 @@ func testL2RelayerProcessPendingBatches(t *testing.T) {
 			chainConfig.BernoulliBlock = big.NewInt(0)
 		}
 
-		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
 		assert.NoError(t, err)
 
 		patchGuard := gomonkey.ApplyMethodFunc(l2Cli, "SendTransaction", func(_ context.Context, _ *gethTypes.Transaction) error {
@@ -109,7 +111,7 @@ func testL2RelayerProcessCommittedBatches(t *testing.T) {
 		if codecVersion == encoding.CodecV0 {
 			chainConfig.BernoulliBlock = big.NewInt(0)
 		}
-		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
 		assert.NoError(t, err)
 
 		l2BlockOrm := orm.NewL2Block(db)
@@ -174,7 +176,7 @@ func testL2RelayerFinalizeTimeoutBatches(t *testing.T) {
 		if codecVersion == encoding.CodecV0 {
 			chainConfig.BernoulliBlock = big.NewInt(0)
 		}
-		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
 		assert.NoError(t, err)
 
 		l2BlockOrm := orm.NewL2Block(db)
@@ -219,7 +221,7 @@ func testL2RelayerCommitConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	defer l2Relayer.StopSenders()
 
@@ -275,7 +277,7 @@ func testL2RelayerFinalizeConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	defer l2Relayer.StopSenders()
 
@@ -352,7 +354,7 @@ func testL2RelayerGasOracleConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, &params.ChainConfig{}, false, ServiceTypeL2GasOracle, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, &params.ChainConfig{}, false, ServiceTypeL2GasOracle, nil)
 	assert.NoError(t, err)
 	defer l2Relayer.StopSenders()
 
@@ -392,7 +394,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
 
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, &params.ChainConfig{}, false, ServiceTypeL2GasOracle, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, &params.ChainConfig{}, false, ServiceTypeL2GasOracle, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 	defer relayer.StopSenders()
@@ -488,7 +490,7 @@ func testGetBatchStatusByIndex(t *testing.T) {
 	defer database.CloseDB(db)
 
 	cfg.L2Config.RelayerConfig.ChainMonitor.Enabled = true
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 	defer relayer.StopSenders()
@@ -517,3 +519,311 @@ func testGetBatchStatusByIndex(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, status)
 }
+
+func testL2RelayerCommitPayloadSizeGuard(t *testing.T) {
+	assert.NoError(t, checkCommitPayloadSize(1000, 0, 1000))
+	assert.ErrorIs(t, checkCommitPayloadSize(1001, 0, 1000), errCommitPayloadTooLarge)
+	assert.NoError(t, checkCommitPayloadSize(0, maxBlobSize, 1000))
+	assert.ErrorIs(t, checkCommitPayloadSize(0, maxBlobSize+1, 1000), errCommitPayloadTooLarge)
+
+	// fitsUpTo returns a size check which accepts at most limit chunks.
+	fitsUpTo := func(limit int) func(int) (bool, error) {
+		return func(numChunks int) (bool, error) {
+			return numChunks <= limit, nil
+		}
+	}
+	for _, tc := range []struct {
+		numChunks int
+		limit     int
+		expected  int
+	}{
+		{numChunks: 2, limit: 1, expected: 1},
+		{numChunks: 2, limit: 0, expected: 0},
+		{numChunks: 1, limit: 0, expected: 0},
+		{numChunks: 15, limit: 14, expected: 14},
+		{numChunks: 15, limit: 7, expected: 7},
+		{numChunks: 15, limit: 1, expected: 1},
+		{numChunks: 15, limit: 0, expected: 0},
+	} {
+		numChunks, err := findMaxCommittableChunks(tc.numChunks, fitsUpTo(tc.limit))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, numChunks)
+	}
+
+	_, err := findMaxCommittableChunks(3, func(int) (bool, error) {
+		return false, errors.New("estimate failure")
+	})
+	assert.Error(t, err)
+}
+
+func testL2RelayerSplitOversizedBatch(t *testing.T) {
+	codecVersions := []encoding.CodecVersion{encoding.CodecV0, encoding.CodecV1}
+	for _, codecVersion := range codecVersions {
+		db := setupL2RelayerDB(t)
+		defer database.CloseDB(db)
+
+		l2Cfg := cfg.L2Config
+		chainConfig := &params.ChainConfig{}
+		if codecVersion == encoding.CodecV0 {
+			chainConfig.BernoulliBlock = big.NewInt(0)
+		}
+
+		relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+		assert.NoError(t, err)
+
+		patchGuard := gomonkey.ApplyMethodFunc(l2Cli, "SendTransaction", func(_ context.Context, _ *gethTypes.Transaction) error {
+			return nil
+		})
+
+		l2BlockOrm := orm.NewL2Block(db)
+		err = l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+		assert.NoError(t, err)
+		chunkOrm := orm.NewChunk(db)
+		dbChunk1, err := chunkOrm.InsertChunk(context.Background(), chunk1, codecVersion)
+		assert.NoError(t, err)
+		_, err = chunkOrm.InsertChunk(context.Background(), chunk2, codecVersion)
+		assert.NoError(t, err)
+
+		batchOrm := orm.NewBatch(db)
+		genesisBatch, err := batchOrm.GetBatchByIndex(context.Background(), 0)
+		assert.NoError(t, err)
+
+		batch := &encoding.Batch{
+			Index:                      1,
+			TotalL1MessagePoppedBefore: 0,
+			ParentBatchHash:            common.HexToHash(genesisBatch.Hash),
+			Chunks:                     []*encoding.Chunk{chunk1, chunk2},
+		}
+		dbBatch, err := batchOrm.InsertBatch(context.Background(), batch, codecVersion)
+		assert.NoError(t, err)
+
+		// allow exactly the payload of a batch of the first chunk.
+		singleChunkBatch := &encoding.Batch{
+			Index:                      1,
+			TotalL1MessagePoppedBefore: 0,
+			ParentBatchHash:            common.HexToHash(genesisBatch.Hash),
+			Chunks:                     []*encoding.Chunk{chunk1},
+		}
+		calldataSize, _, err := relayer.estimateCommitPayloadSize(singleChunkBatch, []*orm.Chunk{dbChunk1}, genesisBatch.BatchHeader, codecVersion)
+		assert.NoError(t, err)
+		relayerCfg := *l2Cfg.RelayerConfig
+		relayerCfg.MaxCommitCalldataSize = calldataSize
+		relayer.cfg = &relayerCfg
+
+		// the oversized batch is replaced with a batch of the first chunk, the second chunk is left unbatched.
+		relayer.ProcessPendingBatches()
+
+		splitBatch, err := batchOrm.GetBatchByIndex(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.NotEqual(t, dbBatch.Hash, splitBatch.Hash)
+		assert.Equal(t, dbBatch.StartChunkIndex, splitBatch.StartChunkIndex)
+		assert.Equal(t, dbBatch.StartChunkIndex, splitBatch.EndChunkIndex)
+		assert.Equal(t, types.RollupPending, types.RollupStatus(splitBatch.RollupStatus))
+
+		unbatchedChunkIndex, err := batchOrm.GetFirstUnbatchedChunkIndex(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, dbBatch.EndChunkIndex, unbatchedChunkIndex)
+
+		// the split batch fits and is committed.
+		relayer.ProcessPendingBatches()
+
+		statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{splitBatch.Hash})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(statuses))
+		assert.Equal(t, types.RollupCommitting, statuses[0])
+		relayer.StopSenders()
+		patchGuard.Reset()
+	}
+}
+
+func testL2RelayerSplitOversizedBatchWaitsForProposer(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	l2Cfg := cfg.L2Config
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, l2Cfg.L2MessageQueueAddress, l2Cfg.WithdrawTrieRootSlot, &params.ChainConfig{}, true, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+	defer relayer.StopSenders()
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err = l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	chunkOrm := orm.NewChunk(db)
+	dbChunk1, err := chunkOrm.InsertChunk(context.Background(), chunk1, encoding.CodecV1)
+	assert.NoError(t, err)
+	dbChunk2, err := chunkOrm.InsertChunk(context.Background(), chunk2, encoding.CodecV1)
+	assert.NoError(t, err)
+
+	batchOrm := orm.NewBatch(db)
+	genesisBatch, err := batchOrm.GetBatchByIndex(context.Background(), 0)
+	assert.NoError(t, err)
+	dbBatch, err := batchOrm.InsertBatch(context.Background(), &encoding.Batch{
+		Index:                      1,
+		TotalL1MessagePoppedBefore: 0,
+		ParentBatchHash:            common.HexToHash(genesisBatch.Hash),
+		Chunks:                     []*encoding.Chunk{chunk1, chunk2},
+	}, encoding.CodecV1)
+	assert.NoError(t, err)
+
+	calldataSize, _, err := relayer.estimateCommitPayloadSize(&encoding.Batch{
+		Index:                      1,
+		TotalL1MessagePoppedBefore: 0,
+		ParentBatchHash:            common.HexToHash(genesisBatch.Hash),
+		Chunks:                     []*encoding.Chunk{chunk1},
+	}, []*orm.Chunk{dbChunk1}, genesisBatch.BatchHeader, encoding.CodecV1)
+	assert.NoError(t, err)
+	relayerCfg := *l2Cfg.RelayerConfig
+	relayerCfg.MaxCommitCalldataSize = calldataSize
+	relayer.cfg = &relayerCfg
+
+	// the batch proposer holds the lock of the batch it chains a new batch to.
+	proposerTX := db.Begin()
+	parentBatches, err := batchOrm.GetBatchesGEIndexForUpdate(context.Background(), dbBatch.Index, proposerTX)
+	assert.NoError(t, err)
+	assert.Len(t, parentBatches, 1)
+
+	splitErrCh := make(chan error, 1)
+	go func() {
+		splitErrCh <- relayer.splitOversizedBatch(dbBatch, genesisBatch, []*orm.Chunk{dbChunk1, dbChunk2}, []*encoding.Chunk{chunk1, chunk2}, encoding.CodecV1)
+	}()
+
+	select {
+	case err = <-splitErrCh:
+		t.Fatalf("split did not wait for the batch proposer, err: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	_, err = batchOrm.InsertBatch(context.Background(), &encoding.Batch{
+		Index:                      2,
+		TotalL1MessagePoppedBefore: chunk1.NumL1Messages(0) + chunk2.NumL1Messages(chunk1.NumL1Messages(0)),
+		ParentBatchHash:            common.HexToHash(dbBatch.Hash),
+		Chunks:                     []*encoding.Chunk{chunk2},
+	}, encoding.CodecV1, proposerTX)
+	assert.NoError(t, err)
+	assert.NoError(t, proposerTX.Commit().Error)
+
+	// the batch chained to the replaced batch is removed by the split.
+	assert.NoError(t, <-splitErrCh)
+	splitBatch, err := batchOrm.GetBatchByIndex(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, dbBatch.Hash, splitBatch.Hash)
+	assert.Equal(t, dbChunk1.Index, splitBatch.EndChunkIndex)
+	_, err = batchOrm.GetBatchByIndex(context.Background(), 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	latestBatch, err := batchOrm.GetLatestBatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, splitBatch.Hash, latestBatch.Hash)
+}
+
+func testL2RelayerFinalizeRootCheck(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	relayerCfg := *cfg.L2Config.RelayerConfig
+	relayerCfg.FinalizeRootCheck = &config.FinalizeRootCheckConfig{Enabled: true}
+	chainConfig := &params.ChainConfig{BernoulliBlock: big.NewInt(0)}
+	messageQueueAddress := common.HexToAddress("0x5300000000000000000000000000000000000000")
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, &relayerCfg, messageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+	defer relayer.StopSenders()
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err = l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	chunkOrm := orm.NewChunk(db)
+	_, err = chunkOrm.InsertChunk(context.Background(), chunk1, encoding.CodecV0)
+	assert.NoError(t, err)
+	_, err = chunkOrm.InsertChunk(context.Background(), chunk2, encoding.CodecV0)
+	assert.NoError(t, err)
+
+	batch := &encoding.Batch{
+		Index:                      1,
+		TotalL1MessagePoppedBefore: 0,
+		ParentBatchHash:            common.Hash{},
+		Chunks:                     []*encoding.Chunk{chunk1, chunk2},
+	}
+	batchOrm := orm.NewBatch(db)
+	dbBatch, err := batchOrm.InsertBatch(context.Background(), batch, encoding.CodecV0)
+	assert.NoError(t, err)
+	err = batchOrm.UpdateRollupStatus(context.Background(), dbBatch.Hash, types.RollupCommitted)
+	assert.NoError(t, err)
+	err = batchOrm.UpdateProvingStatus(context.Background(), dbBatch.Hash, types.ProvingTaskVerified)
+	assert.NoError(t, err)
+	proof := &message.BatchProof{
+		Proof: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31},
+	}
+	err = batchOrm.UpdateProofByHash(context.Background(), dbBatch.Hash, proof, 100)
+	assert.NoError(t, err)
+
+	stateRoot := common.HexToHash(dbBatch.StateRoot)
+	patchGuard := gomonkey.ApplyMethodFunc(l2Cli, "HeaderByNumber", func(_ context.Context, number *big.Int) (*gethTypes.Header, error) {
+		assert.Equal(t, block2.Header.Number.Uint64(), number.Uint64())
+		return &gethTypes.Header{Number: number, Root: stateRoot}, nil
+	})
+	defer patchGuard.Reset()
+	patchGuard.ApplyMethodFunc(l2Cli, "StorageAt", func(_ context.Context, account common.Address, _ common.Hash, _ *big.Int) ([]byte, error) {
+		assert.Equal(t, messageQueueAddress, account)
+		return common.Hash{1}.Bytes(), nil
+	})
+
+	// the withdraw root differs from l2geth, the batch is not finalized.
+	relayer.ProcessCommittedBatches()
+	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{dbBatch.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, types.RollupCommitted, statuses[0])
+
+	patchGuard.ApplyMethodFunc(l2Cli, "StorageAt", func(context.Context, common.Address, common.Hash, *big.Int) ([]byte, error) {
+		return common.HexToHash(dbBatch.WithdrawRoot).Bytes(), nil
+	})
+
+	relayer.ProcessCommittedBatches()
+	statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{dbBatch.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, types.RollupFinalizing, statuses[0])
+
+	// without an L2MessageQueue address only the state root is checked, the batch is still finalized.
+	err = batchOrm.UpdateRollupStatus(context.Background(), dbBatch.Hash, types.RollupCommitted)
+	assert.NoError(t, err)
+	patchGuard.ApplyMethodFunc(l2Cli, "StorageAt", func(context.Context, common.Address, common.Hash, *big.Int) ([]byte, error) {
+		t.Error("unexpected withdraw root read without an L2MessageQueue address")
+		return common.Hash{1}.Bytes(), nil
+	})
+	zeroAddressRelayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, &relayerCfg, common.Address{}, cfg.L2Config.WithdrawTrieRootSlot, chainConfig, true, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+	defer zeroAddressRelayer.StopSenders()
+
+	zeroAddressRelayer.ProcessCommittedBatches()
+	statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{dbBatch.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, types.RollupFinalizing, statuses[0])
+}
+
+func testAverageBaseFee(t *testing.T) {
+	assert.Nil(t, averageBaseFee(nil))
+	assert.Nil(t, averageBaseFee([]*orm.L2BlockHeader{{Number: 1}}))
+
+	// blocks without base fee are ignored, the average is rounded up.
+	headers := []*orm.L2BlockHeader{{Number: 3, BaseFee: 10}, {Number: 2}, {Number: 1, BaseFee: 11}}
+	assert.Equal(t, big.NewInt(11), averageBaseFee(headers))
+}
+
+func testSuggestL2GasPriceWithPriorityFeeTip(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+	headers := []*orm.L2BlockHeader{{Number: 1, Hash: "0x01", BaseFee: 10}, {Number: 2, Hash: "0x02", ParentHash: "0x01", BaseFee: 12}}
+	assert.NoError(t, orm.NewL2BlockHeader(db).InsertL2BlockHeaders(context.Background(), headers))
+
+	relayer := &Layer2Relayer{ctx: context.Background(), l2BlockHeaderOrm: orm.NewL2BlockHeader(db), l2BaseFeeWindow: 2}
+	gasPrice, err := relayer.suggestL2GasPrice()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(11), gasPrice)
+
+	// the tip is only added once configured.
+	relayer.l2PriorityFeeTip = 5
+	gasPrice, err = relayer.suggestL2GasPrice()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(16), gasPrice)
+}
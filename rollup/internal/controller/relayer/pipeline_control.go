@@ -0,0 +1,238 @@
+package relayer
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const defaultPipelineRefreshInterval = 10 * time.Second
+
+// AdminTokenHeader is the header carrying the admin token of the pause and resume requests.
+const AdminTokenHeader = "X-Admin-Token"
+
+type pipelinePauseStore interface {
+	GetPipelinePauses(ctx context.Context) ([]*orm.PipelinePause, error)
+	PausePipeline(ctx context.Context, pipeline, reason string) error
+	ResumePipeline(ctx context.Context, pipeline string) error
+}
+
+// PipelineStatus is the pause state of a relayer pipeline.
+type PipelineStatus struct {
+	Pipeline string `json:"pipeline"`
+	Paused   bool   `json:"paused"`
+	// PausedBy is api if the pipeline is paused through the admin api, maintenance if by a maintenance window.
+	PausedBy string     `json:"paused_by,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+	Until    *time.Time `json:"until,omitempty"` // the end of the maintenance window
+}
+
+// PipelineControl pauses the relayer pipelines independently, during the scheduled maintenance windows and while
+// they are paused through the admin api. The api pauses are stored in the database and reloaded periodically, so
+// they survive restarts and apply to all the relayers sharing it. It is safe for concurrent use.
+type PipelineControl struct {
+	cfg             *config.MaintenanceConfig
+	store           pipelinePauseStore
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu     sync.RWMutex
+	paused map[string]string // reasons of the pipelines paused through the api, by pipeline
+
+	metrics *pipelineControlMetrics
+}
+
+// NewPipelineControl returns the control of the relayer pipelines, a nil config schedules no maintenance window and
+// rejects the pause and resume requests.
+func NewPipelineControl(cfg *config.MaintenanceConfig, db *gorm.DB, reg prometheus.Registerer) *PipelineControl {
+	if cfg == nil {
+		cfg = &config.MaintenanceConfig{}
+	}
+	c := &PipelineControl{
+		cfg:             cfg,
+		store:           orm.NewPipelinePause(db),
+		refreshInterval: defaultPipelineRefreshInterval,
+		now:             time.Now,
+		paused:          make(map[string]string),
+		metrics:         initPipelineControlMetrics(reg),
+	}
+	if cfg.RefreshIntervalSec > 0 {
+		c.refreshInterval = time.Duration(cfg.RefreshIntervalSec) * time.Second
+	}
+	return c
+}
+
+// RefreshInterval returns the interval the paused pipelines should be reloaded at.
+func (c *PipelineControl) RefreshInterval() time.Duration {
+	return c.refreshInterval
+}
+
+// Refresh reloads the pipelines paused through the api from the database, and exports the pause state of the
+// pipelines.
+func (c *PipelineControl) Refresh(ctx context.Context) {
+	pauses, err := c.store.GetPipelinePauses(ctx)
+	if err != nil {
+		// the last loaded pauses are kept, a pipeline is not resumed because the database is unreachable.
+		log.Warn("failed to load the paused relayer pipelines", "err", err)
+	} else {
+		paused := make(map[string]string, len(pauses))
+		for _, pause := range pauses {
+			paused[pause.Pipeline] = pause.Reason
+		}
+		c.mu.Lock()
+		c.paused = paused
+		c.mu.Unlock()
+	}
+
+	for _, status := range c.Status() {
+		var value float64
+		if status.Paused {
+			value = 1
+		}
+		c.metrics.pipelinePaused.WithLabelValues(status.Pipeline).Set(value)
+	}
+}
+
+// Status returns the pause state of the pipelines.
+func (c *PipelineControl) Status() []*PipelineStatus {
+	statuses := make([]*PipelineStatus, 0, len(config.Pipelines))
+	for _, pipeline := range config.Pipelines {
+		statuses = append(statuses, c.status(pipeline))
+	}
+	return statuses
+}
+
+func (c *PipelineControl) status(pipeline string) *PipelineStatus {
+	c.mu.RLock()
+	reason, paused := c.paused[pipeline]
+	c.mu.RUnlock()
+	if paused {
+		return &PipelineStatus{Pipeline: pipeline, Paused: true, PausedBy: "api", Reason: reason}
+	}
+
+	now := c.now()
+	for _, window := range c.cfg.Windows {
+		if now.Before(window.Start) || !now.Before(window.End) || !windowPauses(window, pipeline) {
+			continue
+		}
+		until := window.End
+		return &PipelineStatus{Pipeline: pipeline, Paused: true, PausedBy: "maintenance", Reason: window.Reason, Until: &until}
+	}
+	return &PipelineStatus{Pipeline: pipeline}
+}
+
+func windowPauses(window *config.MaintenanceWindow, pipeline string) bool {
+	if len(window.Pipelines) == 0 {
+		return true
+	}
+	for _, p := range window.Pipelines {
+		if p == pipeline {
+			return true
+		}
+	}
+	return false
+}
+
+// Paused returns whether the pipeline is paused, a nil control never pauses it.
+func (c *PipelineControl) Paused(pipeline string) bool {
+	if c == nil {
+		return false
+	}
+	return c.status(pipeline).Paused
+}
+
+// Gate returns a function running fn only while the pipeline is not paused, e.g. to pause a loop of utils.Loop.
+func (c *PipelineControl) Gate(pipeline string, fn func()) func() {
+	return func() {
+		if c.Paused(pipeline) {
+			log.Debug("relayer pipeline paused, skip", "pipeline", pipeline)
+			return
+		}
+		fn()
+	}
+}
+
+// Pause pauses the pipeline until it is resumed, in all the relayers sharing the database.
+func (c *PipelineControl) Pause(ctx context.Context, pipeline, reason string) error {
+	if !config.IsPipeline(pipeline) {
+		return fmt.Errorf("unknown pipeline %q", pipeline)
+	}
+	if err := c.store.PausePipeline(ctx, pipeline, reason); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.paused[pipeline] = reason
+	c.mu.Unlock()
+	c.metrics.pipelinePaused.WithLabelValues(pipeline).Set(1)
+	log.Warn("relayer pipeline paused", "pipeline", pipeline, "reason", reason)
+	return nil
+}
+
+// Resume resumes the pipeline paused through the api, a maintenance window still pauses it until its end.
+func (c *PipelineControl) Resume(ctx context.Context, pipeline string) error {
+	if !config.IsPipeline(pipeline) {
+		return fmt.Errorf("unknown pipeline %q", pipeline)
+	}
+	if err := c.store.ResumePipeline(ctx, pipeline); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.paused, pipeline)
+	c.mu.Unlock()
+	if !c.Paused(pipeline) {
+		c.metrics.pipelinePaused.WithLabelValues(pipeline).Set(0)
+	}
+	log.Info("relayer pipeline resumed", "pipeline", pipeline)
+	return nil
+}
+
+// Routes registers the pipeline apis: GET /pipelines returns their status, POST /pipelines/:pipeline/pause?reason=
+// and POST /pipelines/:pipeline/resume pause and resume a pipeline, and require the admin token.
+func (c *PipelineControl) Routes(r gin.IRouter) {
+	r.GET("/pipelines", func(ctx *gin.Context) {
+		types.RenderSuccess(ctx, c.Status())
+	})
+	admin := r.Group("/pipelines/:pipeline", c.adminTokenMiddleware)
+	admin.POST("/pause", func(ctx *gin.Context) {
+		c.handle(ctx, func(pipeline string) error { return c.Pause(ctx, pipeline, ctx.Query("reason")) })
+	})
+	admin.POST("/resume", func(ctx *gin.Context) {
+		c.handle(ctx, func(pipeline string) error { return c.Resume(ctx, pipeline) })
+	})
+}
+
+func (c *PipelineControl) handle(ctx *gin.Context, fn func(pipeline string) error) {
+	pipeline := ctx.Param("pipeline")
+	if !config.IsPipeline(pipeline) {
+		types.RenderFailure(ctx, types.ErrRollupPipelineUnknown, fmt.Errorf("unknown pipeline %q, known ones are %v", pipeline, config.Pipelines))
+		return
+	}
+	if err := fn(pipeline); err != nil {
+		types.RenderFailure(ctx, types.ErrRollupPipelineControlFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, c.status(pipeline))
+}
+
+func (c *PipelineControl) adminTokenMiddleware(ctx *gin.Context) {
+	token := []byte(c.cfg.AdminToken)
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(ctx.GetHeader(AdminTokenHeader)), token) != 1 {
+		types.RenderFailure(ctx, types.ErrRollupAdminUnauthorized, errors.New("invalid admin token"))
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
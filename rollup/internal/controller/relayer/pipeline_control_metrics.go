@@ -0,0 +1,29 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type pipelineControlMetrics struct {
+	pipelinePaused *prometheus.GaugeVec
+}
+
+var (
+	initPipelineControlMetricOnce sync.Once
+	pipelineControlMetric         *pipelineControlMetrics
+)
+
+func initPipelineControlMetrics(reg prometheus.Registerer) *pipelineControlMetrics {
+	initPipelineControlMetricOnce.Do(func() {
+		pipelineControlMetric = &pipelineControlMetrics{
+			pipelinePaused: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_relayer_pipeline_paused",
+				Help: "Whether the relayer pipeline is paused (1) by the admin api or a maintenance window, or not (0).",
+			}, []string{"pipeline"}),
+		}
+	})
+	return pipelineControlMetric
+}
@@ -0,0 +1,169 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// mockPipelinePauseStore keeps the paused pipelines in memory, shared by the controls of the test.
+type mockPipelinePauseStore struct {
+	pauses map[string]string
+	err    error
+}
+
+func (s *mockPipelinePauseStore) GetPipelinePauses(context.Context) ([]*orm.PipelinePause, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var pauses []*orm.PipelinePause
+	for pipeline, reason := range s.pauses {
+		pauses = append(pauses, &orm.PipelinePause{Pipeline: pipeline, Reason: reason})
+	}
+	return pauses, nil
+}
+
+func (s *mockPipelinePauseStore) PausePipeline(_ context.Context, pipeline, reason string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.pauses[pipeline] = reason
+	return nil
+}
+
+func (s *mockPipelinePauseStore) ResumePipeline(_ context.Context, pipeline string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.pauses, pipeline)
+	return nil
+}
+
+func TestPipelineControl(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cfg := &config.MaintenanceConfig{
+		Windows: []*config.MaintenanceWindow{
+			{Pipelines: []string{config.PipelineFinalize}, Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Reason: "verifier upgrade"},
+			{Start: now.Add(3 * time.Hour), End: now.Add(4 * time.Hour)},
+		},
+	}
+	store := &mockPipelinePauseStore{pauses: map[string]string{}}
+	newControl := func() *PipelineControl {
+		c := NewPipelineControl(cfg, nil, prometheus.NewRegistry())
+		c.store = store
+		c.now = func() time.Time { return now }
+		return c
+	}
+	c := newControl()
+	c.Refresh(context.Background())
+	for _, pipeline := range config.Pipelines {
+		assert.False(t, c.Paused(pipeline))
+	}
+
+	var runs int
+	gated := c.Gate(config.PipelineCommit, func() { runs++ })
+	gated()
+	assert.NoError(t, c.Pause(context.Background(), config.PipelineCommit, "investigating batch 42"))
+	gated()
+	assert.Equal(t, 1, runs)
+	assert.True(t, c.Paused(config.PipelineCommit))
+	assert.False(t, c.Paused(config.PipelineFinalize))
+	assert.Error(t, c.Pause(context.Background(), "unknown", ""))
+
+	// the pause survives a restart, and is picked up by the other relayers.
+	other := newControl()
+	assert.False(t, other.Paused(config.PipelineCommit))
+	other.Refresh(context.Background())
+	assert.True(t, other.Paused(config.PipelineCommit))
+	status := other.status(config.PipelineCommit)
+	assert.Equal(t, "api", status.PausedBy)
+	assert.Equal(t, "investigating batch 42", status.Reason)
+
+	// the last loaded pauses are kept while the database is unreachable.
+	store.err = errors.New("db down")
+	other.Refresh(context.Background())
+	assert.True(t, other.Paused(config.PipelineCommit))
+	store.err = nil
+
+	assert.NoError(t, c.Resume(context.Background(), config.PipelineCommit))
+	gated()
+	assert.Equal(t, 2, runs)
+	other.Refresh(context.Background())
+	assert.False(t, other.Paused(config.PipelineCommit))
+
+	// maintenance windows.
+	now = now.Add(time.Hour)
+	assert.True(t, c.Paused(config.PipelineFinalize))
+	assert.False(t, c.Paused(config.PipelineCommit))
+	status = c.status(config.PipelineFinalize)
+	assert.Equal(t, "maintenance", status.PausedBy)
+	assert.Equal(t, "verifier upgrade", status.Reason)
+	assert.Equal(t, now.Add(time.Hour), *status.Until)
+	// resuming does not end the window.
+	assert.NoError(t, c.Resume(context.Background(), config.PipelineFinalize))
+	assert.True(t, c.Paused(config.PipelineFinalize))
+
+	now = now.Add(time.Hour)
+	assert.False(t, c.Paused(config.PipelineFinalize))
+	now = now.Add(time.Hour)
+	for _, pipeline := range config.Pipelines {
+		assert.True(t, c.Paused(pipeline))
+	}
+	now = now.Add(time.Hour)
+	for _, pipeline := range config.Pipelines {
+		assert.False(t, c.Paused(pipeline))
+	}
+
+	var nilControl *PipelineControl
+	assert.False(t, nilControl.Paused(config.PipelineCommit))
+}
+
+func TestPipelineControlRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &mockPipelinePauseStore{pauses: map[string]string{}}
+	c := NewPipelineControl(&config.MaintenanceConfig{AdminToken: "secret"}, nil, prometheus.NewRegistry())
+	c.store = store
+	r := gin.New()
+	c.Routes(r)
+
+	request := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set(AdminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/pause", "").Body.String(), `"errcode":30001`)
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/pause", "wrong").Body.String(), `"errcode":30001`)
+	assert.False(t, c.Paused(config.PipelineCommit))
+	assert.Contains(t, request(http.MethodPost, "/pipelines/unknown/pause", "secret").Body.String(), `"errcode":30002`)
+
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/pause?reason=upgrade", "secret").Body.String(), `"paused":true`)
+	assert.Equal(t, map[string]string{config.PipelineCommit: "upgrade"}, store.pauses)
+	assert.Contains(t, request(http.MethodGet, "/pipelines", "").Body.String(), `{"pipeline":"commit","paused":true,"paused_by":"api","reason":"upgrade"}`)
+
+	store.err = errors.New("db down")
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/resume", "secret").Body.String(), `"errcode":30003`)
+	assert.True(t, c.Paused(config.PipelineCommit))
+	store.err = nil
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/resume", "secret").Body.String(), `"paused":false`)
+	assert.Empty(t, store.pauses)
+
+	// the pause and resume apis are disabled without an admin token.
+	c.cfg = &config.MaintenanceConfig{}
+	assert.Contains(t, request(http.MethodPost, "/pipelines/commit/pause", "").Body.String(), `"errcode":30001`)
+}
@@ -124,15 +124,28 @@ func TestFunctions(t *testing.T) {
 	t.Run("TestL1RelayerGasOracleConfirm", testL1RelayerGasOracleConfirm)
 	t.Run("TestL1RelayerProcessGasPriceOracle", testL1RelayerProcessGasPriceOracle)
 
+	// Run skipped message policy test cases.
+	t.Run("TestSkippedMessagePolicyReplay", testSkippedMessagePolicyReplay)
+	t.Run("TestSkippedMessagePolicyReplaySendFailed", testSkippedMessagePolicyReplaySendFailed)
+	t.Run("TestSkippedMessagePolicyProcessSkippedMessages", testSkippedMessagePolicyProcessSkippedMessages)
+	t.Run("TestSkippedMessagePolicyReplayGasEstimation", testSkippedMessagePolicyReplayGasEstimation)
+
 	// Run l2 relayer test cases.
 	t.Run("TestCreateNewRelayer", testCreateNewRelayer)
 	t.Run("TestL2RelayerProcessPendingBatches", testL2RelayerProcessPendingBatches)
+	t.Run("TestL2RelayerCommitPayloadSizeGuard", testL2RelayerCommitPayloadSizeGuard)
+	t.Run("TestL2RelayerSplitOversizedBatch", testL2RelayerSplitOversizedBatch)
+	t.Run("TestL2RelayerSplitOversizedBatchWaitsForProposer", testL2RelayerSplitOversizedBatchWaitsForProposer)
 	t.Run("TestL2RelayerProcessCommittedBatches", testL2RelayerProcessCommittedBatches)
 	t.Run("TestL2RelayerFinalizeTimeoutBatches", testL2RelayerFinalizeTimeoutBatches)
+	t.Run("TestL2RelayerFinalizeRootCheck", testL2RelayerFinalizeRootCheck)
 	t.Run("TestL2RelayerCommitConfirm", testL2RelayerCommitConfirm)
 	t.Run("TestL2RelayerFinalizeConfirm", testL2RelayerFinalizeConfirm)
 	t.Run("TestL2RelayerGasOracleConfirm", testL2RelayerGasOracleConfirm)
 	t.Run("TestLayer2RelayerProcessGasPriceOracle", testLayer2RelayerProcessGasPriceOracle)
+	t.Run("TestAverageBaseFee", testAverageBaseFee)
+	t.Run("TestSuggestL2GasPriceWithPriorityFeeTip", testSuggestL2GasPriceWithPriorityFeeTip)
+	t.Run("TestGasPriceBounds", testGasPriceBounds)
 	// test getBatchStatusByIndex
 	t.Run("TestGetBatchStatusByIndex", testGetBatchStatusByIndex)
 }
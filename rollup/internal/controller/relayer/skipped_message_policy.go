@@ -0,0 +1,399 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/crossdomain"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/sender"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	defaultMaxReplays = 1
+	// defaultReplayGasLimitMultiplier is the default margin of the estimated replay gas.
+	defaultReplayGasLimitMultiplier = 1.5
+
+	// skippedMessageBatchSize is the max number of skipped messages processed per run.
+	skippedMessageBatchSize = 100
+)
+
+// replaySender sends the replayMessage txs and reports their confirmations, it is a *sender.Sender, or a *sender.SenderPool
+// if replay sender pool keys are configured, outside of tests.
+type replaySender interface {
+	SendTransactionWithValue(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error)
+	ConfirmChan() <-chan *sender.Confirmation
+	Stop()
+}
+
+// SkippedMessagePolicy classifies the L1 messages skipped by the sequencer, and replays them through the L1ScrollMessenger
+// with an adjusted gas limit once the policy allows it, or right away when an operator requested a replay.
+type SkippedMessagePolicy struct {
+	ctx context.Context
+	cfg *config.SkippedMessagePolicyConfig
+
+	replaySender  replaySender
+	l1Client      ethereum.ContractCaller
+	l2Client      ethereum.GasEstimator
+	refundAddress common.Address
+
+	l1MessageOrm *orm.L1Message
+	metrics      *skippedMessagePolicyMetrics
+}
+
+// NewSkippedMessagePolicy will return a new instance of SkippedMessagePolicy.
+func NewSkippedMessagePolicy(ctx context.Context, l2Client ethereum.GasEstimator, db *gorm.DB, cfg *config.RelayerConfig, reg prometheus.Registerer) (*SkippedMessagePolicy, error) {
+	if cfg.SkippedMessagePolicy == nil {
+		return nil, errors.New("skipped message policy is not configured")
+	}
+	if cfg.ReplaySenderPrivateKey == nil {
+		return nil, errors.New("replay sender private key is not configured")
+	}
+
+	var replaySender replaySender
+	if len(cfg.ReplaySenderPoolPrivateKeys) > 0 {
+		// replays of different messages do not depend on each other, they can be sent from any account.
+		privs := append([]*ecdsa.PrivateKey{cfg.ReplaySenderPrivateKey}, cfg.ReplaySenderPoolPrivateKeys...)
+		pool, err := sender.NewSenderPool(ctx, cfg.SenderConfig, privs, "skipped_message_policy", "replay_sender", types.SenderTypeReplayMessage, db, reg)
+		if err != nil {
+			return nil, fmt.Errorf("new replay sender pool failed, err: %w", err)
+		}
+		replaySender = pool
+	} else {
+		single, err := sender.NewSender(ctx, cfg.SenderConfig, cfg.ReplaySenderPrivateKey, "skipped_message_policy", "replay_sender", types.SenderTypeReplayMessage, db, reg)
+		if err != nil {
+			addr := crypto.PubkeyToAddress(cfg.ReplaySenderPrivateKey.PublicKey)
+			return nil, fmt.Errorf("new replay sender failed for address %s, err: %w", addr.Hex(), err)
+		}
+		replaySender = single
+	}
+
+	l1Client, err := ethclient.Dial(cfg.SenderConfig.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect l1 geth, err: %w", err)
+	}
+
+	refundAddress := cfg.SkippedMessagePolicy.RefundAddress
+	if refundAddress == (common.Address{}) {
+		refundAddress = crypto.PubkeyToAddress(cfg.ReplaySenderPrivateKey.PublicKey)
+	}
+
+	p := &SkippedMessagePolicy{
+		ctx:           ctx,
+		cfg:           cfg.SkippedMessagePolicy,
+		replaySender:  replaySender,
+		l1Client:      l1Client,
+		l2Client:      l2Client,
+		refundAddress: refundAddress,
+		l1MessageOrm:  orm.NewL1Message(db),
+		metrics:       initSkippedMessagePolicyMetrics(reg),
+	}
+
+	go p.handleConfirmLoop(ctx)
+
+	return p, nil
+}
+
+// ProcessSkippedMessages classifies the skipped messages and replays those allowed by the policy or requested by an operator.
+func (p *SkippedMessagePolicy) ProcessSkippedMessages() {
+	messages, err := p.l1MessageOrm.GetSkippedL1Messages(p.ctx, skippedMessageBatchSize)
+	if err != nil {
+		log.Error("failed to get skipped l1 messages", "err", err)
+		return
+	}
+
+	for _, msg := range messages {
+		reason := types.MsgSkipReason(msg.SkipReason)
+		if reason == types.MsgSkipReasonUndefined {
+			reason = p.classify(msg)
+			if err = p.l1MessageOrm.UpdateSkipReason(p.ctx, msg.QueueIndex, reason); err != nil {
+				log.Error("failed to update skip reason", "queue index", msg.QueueIndex, "err", err)
+				continue
+			}
+			p.metrics.skippedMessagesTotal.WithLabelValues(reason.String()).Inc()
+			log.Info("classified skipped l1 message", "queue index", msg.QueueIndex, "gas limit", msg.GasLimit, "reason", reason.String())
+		}
+
+		if msg.ReplayRequestedGasLimit != nil {
+			if err = p.replay(msg, *msg.ReplayRequestedGasLimit, true); err != nil {
+				log.Error("failed to replay requested l1 message", "queue index", msg.QueueIndex, "gas limit", *msg.ReplayRequestedGasLimit, "err", err)
+			}
+			continue
+		}
+
+		// messages skipped for their circuit capacity would be skipped again, they are only replayed on request.
+		if reason != types.MsgSkipReasonGasLimitExceeded || msg.ReplayCount >= p.maxReplays() || p.cfg.MaxReplayFee == 0 {
+			continue
+		}
+		gasLimit, ok := p.replayGasLimit(msg)
+		if !ok {
+			continue
+		}
+		if err = p.replay(msg, gasLimit, false); err != nil {
+			log.Warn("failed to replay skipped l1 message", "queue index", msg.QueueIndex, "err", err)
+		}
+	}
+}
+
+// classify returns the skip reason of a skipped message.
+func (p *SkippedMessagePolicy) classify(msg *orm.L1Message) types.MsgSkipReason {
+	if _, err := p.decodeRelayMessage(msg); err != nil {
+		return types.MsgSkipReasonNotReplayable
+	}
+	if msg.GasLimit > p.cfg.L2BlockGasLimit {
+		return types.MsgSkipReasonGasLimitExceeded
+	}
+	return types.MsgSkipReasonCircuitCapacity
+}
+
+// decodeRelayMessage decodes the relayMessage call carried by a message sent through the messengers.
+func (p *SkippedMessagePolicy) decodeRelayMessage(msg *orm.L1Message) (*crossdomain.Message, error) {
+	if common.HexToAddress(msg.Target) != p.cfg.L2ScrollMessengerAddress {
+		return nil, fmt.Errorf("target %s is not the L2ScrollMessenger", msg.Target)
+	}
+	message, _, err := crossdomain.DecodeMessage(common.FromHex(msg.Calldata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode relayMessage, err: %w", err)
+	}
+	return message, nil
+}
+
+// replay sends a replayMessage tx of the message with the new gas limit. Automatic replays wait while the gas limit
+// exceeds the cap of the L1MessageQueue or the fee exceeds the configured maximum, forced replays do not.
+func (p *SkippedMessagePolicy) replay(msg *orm.L1Message, gasLimit uint64, forced bool) error {
+	// replayMessage takes the new gas limit as an uint32.
+	if gasLimit > math.MaxUint32 {
+		return fmt.Errorf("replay gas limit %d exceeds the max uint32", gasLimit)
+	}
+	args, err := p.decodeRelayMessage(msg)
+	if err != nil {
+		return fmt.Errorf("message is not replayable, err: %w", err)
+	}
+
+	fee, err := p.estimateReplayFee(gasLimit)
+	if err != nil {
+		return err
+	}
+	if !forced {
+		maxGasLimit, maxGasLimitErr := p.queueMaxGasLimit()
+		if maxGasLimitErr != nil {
+			return maxGasLimitErr
+		}
+		if gasLimit > maxGasLimit {
+			log.Debug("replay gas limit exceeds the message queue cap, wait", "queue index", msg.QueueIndex, "gas limit", gasLimit, "max gas limit", maxGasLimit)
+			return nil
+		}
+		if fee.Cmp(new(big.Int).SetUint64(p.cfg.MaxReplayFee)) > 0 {
+			log.Debug("replay fee exceeds the limit, wait", "queue index", msg.QueueIndex, "fee", fee, "max fee", p.cfg.MaxReplayFee)
+			return nil
+		}
+	}
+
+	data, err := bridgeAbi.L1ScrollMessengerABI.Pack("replayMessage", args.Sender, args.Target, args.Value,
+		args.MessageNonce, args.Message, uint32(gasLimit), p.refundAddress)
+	if err != nil {
+		return fmt.Errorf("failed to pack replayMessage, err: %w", err)
+	}
+
+	// The replay is recorded before the tx is sent, a message whose replay was sent but not recorded would be replayed again.
+	marked, err := p.l1MessageOrm.UpdateL1MessageReplaying(p.ctx, msg.QueueIndex, gasLimit)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		log.Debug("skipped l1 message is already being replayed", "queue index", msg.QueueIndex)
+		return nil
+	}
+
+	txHash, err := p.replaySender.SendTransactionWithValue(strconv.FormatUint(msg.QueueIndex, 10), &p.cfg.L1ScrollMessengerAddress, fee, data, 0)
+	if err != nil {
+		p.metrics.replaySendFailureTotal.Inc()
+		if revertErr := p.l1MessageOrm.UpdateL1MessageReplaySendFailed(p.ctx, msg.QueueIndex, msg.ReplayRequestedGasLimit); revertErr != nil {
+			log.Error("failed to revert the replay of l1 message", "queue index", msg.QueueIndex, "err", revertErr)
+		}
+		return fmt.Errorf("failed to send replayMessage tx, err: %w", err)
+	}
+	// The tx hash is also recorded on confirmation, so failing to record it here does not lose the replay.
+	if err = p.l1MessageOrm.UpdateL1MessageReplayTxHash(p.ctx, msg.QueueIndex, txHash.String()); err != nil {
+		log.Warn("failed to record the replay tx of l1 message", "queue index", msg.QueueIndex, "tx hash", txHash.String(), "err", err)
+	}
+
+	p.metrics.replaySentTotal.WithLabelValues(strconv.FormatBool(forced)).Inc()
+	log.Info("replayed skipped l1 message", "queue index", msg.QueueIndex, "gas limit", gasLimit, "fee", fee, "forced", forced, "tx hash", txHash.String())
+	return nil
+}
+
+func (p *SkippedMessagePolicy) estimateReplayFee(gasLimit uint64) (*big.Int, error) {
+	var fee *big.Int
+	if err := p.callMessageQueue(&fee, "estimateCrossDomainMessageFee", new(big.Int).SetUint64(gasLimit)); err != nil {
+		return nil, err
+	}
+	return fee, nil
+}
+
+func (p *SkippedMessagePolicy) queueMaxGasLimit() (uint64, error) {
+	var maxGasLimit *big.Int
+	if err := p.callMessageQueue(&maxGasLimit, "maxGasLimit"); err != nil {
+		return 0, err
+	}
+	return maxGasLimit.Uint64(), nil
+}
+
+func (p *SkippedMessagePolicy) callMessageQueue(out interface{}, method string, args ...interface{}) error {
+	data, err := bridgeAbi.L1MessageQueueABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s, err: %w", method, err)
+	}
+	output, err := p.l1Client.CallContract(p.ctx, ethereum.CallMsg{To: &p.cfg.L1MessageQueueAddress, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s, err: %w", method, err)
+	}
+	if err = bridgeAbi.L1MessageQueueABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to unpack %s, err: %w", method, err)
+	}
+	return nil
+}
+
+func (p *SkippedMessagePolicy) maxReplays() int16 {
+	if p.cfg.MaxReplays > 0 {
+		return p.cfg.MaxReplays
+	}
+	return defaultMaxReplays
+}
+
+// replayGasLimit returns the gas limit of the automatic replay of a message: its estimated gas with the margin of the
+// gas estimation, or the static replay gas limit if the estimation is not enabled or the simulation fails. It returns
+// false if the message needs more gas than the max gas limit, such a message is only replayed on request.
+func (p *SkippedMessagePolicy) replayGasLimit(msg *orm.L1Message) (uint64, bool) {
+	estimation := p.cfg.GasEstimation
+	if estimation == nil || !estimation.Enabled {
+		return p.staticReplayGasLimit(), true
+	}
+
+	estimatedGas, err := p.estimateRelayGas(msg)
+	var recordedGas *uint64
+	if err == nil {
+		recordedGas = &estimatedGas
+	}
+	if updateErr := p.l1MessageOrm.UpdateReplayEstimatedGas(p.ctx, msg.QueueIndex, recordedGas); updateErr != nil {
+		log.Warn("failed to record the estimated replay gas", "queue index", msg.QueueIndex, "err", updateErr)
+	}
+	if err != nil {
+		p.metrics.replayGasEstimationTotal.WithLabelValues("fallback").Inc()
+		log.Warn("failed to estimate the replay gas of skipped l1 message, use the static gas limit", "queue index", msg.QueueIndex,
+			"gas limit", p.staticReplayGasLimit(), "err", err)
+		return p.staticReplayGasLimit(), true
+	}
+
+	multiplier := estimation.GasLimitMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultReplayGasLimitMultiplier
+	}
+	maxGasLimit := estimation.MaxGasLimit
+	if maxGasLimit == 0 {
+		maxGasLimit = p.cfg.L2BlockGasLimit
+	}
+	if estimatedGas > maxGasLimit {
+		p.metrics.replayGasEstimationTotal.WithLabelValues("exceeded").Inc()
+		log.Warn("estimated replay gas of skipped l1 message exceeds the max gas limit, only replayed on request", "queue index", msg.QueueIndex,
+			"estimated gas", estimatedGas, "max gas limit", maxGasLimit)
+		return 0, false
+	}
+	gasLimit, capped := applyReplayGasMargin(estimatedGas, multiplier, estimation.MinGasLimit, maxGasLimit)
+	if capped {
+		p.metrics.replayGasEstimationTotal.WithLabelValues("capped").Inc()
+	} else {
+		p.metrics.replayGasEstimationTotal.WithLabelValues("estimated").Inc()
+	}
+	log.Debug("estimated replay gas of skipped l1 message", "queue index", msg.QueueIndex, "estimated gas", estimatedGas, "gas limit", gasLimit)
+	return gasLimit, true
+}
+
+// estimateRelayGas simulates the execution of the relayMessage call of a message on L2, from the sender of the queued
+// message, the L2 alias of the L1ScrollMessenger.
+func (p *SkippedMessagePolicy) estimateRelayGas(msg *orm.L1Message) (uint64, error) {
+	value, ok := new(big.Int).SetString(msg.Value, 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid message value %s", msg.Value)
+	}
+	target := common.HexToAddress(msg.Target)
+	return p.l2Client.EstimateGas(p.ctx, ethereum.CallMsg{
+		From:  common.HexToAddress(msg.Sender),
+		To:    &target,
+		Value: value,
+		Data:  common.FromHex(msg.Calldata),
+	})
+}
+
+// applyReplayGasMargin returns the estimated gas multiplied by the margin and bounded by the min and max gas limits,
+// and whether the margin was cut by the max gas limit.
+func applyReplayGasMargin(estimatedGas uint64, multiplier float64, minGasLimit, maxGasLimit uint64) (uint64, bool) {
+	gasLimit := math.Ceil(float64(estimatedGas) * multiplier)
+	if gasLimit > float64(maxGasLimit) {
+		return maxGasLimit, true
+	}
+	return min(max(uint64(gasLimit), minGasLimit), maxGasLimit), false
+}
+
+func (p *SkippedMessagePolicy) staticReplayGasLimit() uint64 {
+	if p.cfg.ReplayGasLimit > 0 {
+		return p.cfg.ReplayGasLimit
+	}
+	return p.cfg.L2BlockGasLimit
+}
+
+func (p *SkippedMessagePolicy) handleConfirmation(cfm *sender.Confirmation) {
+	queueIndex, err := strconv.ParseUint(cfm.ContextID, 10, 64)
+	if err != nil {
+		log.Warn("invalid replay confirmation context id", "confirmation", cfm, "err", err)
+		return
+	}
+
+	if cfm.IsSuccessful {
+		p.metrics.replayConfirmedTotal.Inc()
+		log.Info("replayMessage transaction confirmed in layer1", "confirmation", cfm)
+	} else {
+		p.metrics.replayConfirmedFailedTotal.Inc()
+		log.Warn("replayMessage transaction confirmed but failed in layer1", "confirmation", cfm)
+	}
+
+	if err = p.l1MessageOrm.UpdateL1MessageReplayResult(p.ctx, queueIndex, cfm.TxHash.String(), cfm.IsSuccessful); err != nil {
+		log.Warn("UpdateL1MessageReplayResult failed", "confirmation", cfm, "err", err)
+	}
+}
+
+func (p *SkippedMessagePolicy) handleConfirmLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfm := <-p.replaySender.ConfirmChan():
+			p.handleConfirmation(cfm)
+		}
+	}
+}
+
+// StopSenders stops the senders of the skipped message policy to prevent querying the removed pending_transaction table in unit tests.
+// for unit test
+func (p *SkippedMessagePolicy) StopSenders() {
+	if p.replaySender != nil {
+		p.replaySender.Stop()
+	}
+}
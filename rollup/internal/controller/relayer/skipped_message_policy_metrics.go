@@ -0,0 +1,54 @@
+package relayer
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type skippedMessagePolicyMetrics struct {
+	skippedMessagesTotal       *prometheus.CounterVec
+	replaySentTotal            *prometheus.CounterVec
+	replaySendFailureTotal     prometheus.Counter
+	replayConfirmedTotal       prometheus.Counter
+	replayConfirmedFailedTotal prometheus.Counter
+	replayGasEstimationTotal   *prometheus.CounterVec
+}
+
+var (
+	initSkippedMessagePolicyMetricOnce sync.Once
+	skippedMessagePolicyMetric         *skippedMessagePolicyMetrics
+)
+
+func initSkippedMessagePolicyMetrics(reg prometheus.Registerer) *skippedMessagePolicyMetrics {
+	initSkippedMessagePolicyMetricOnce.Do(func() {
+		skippedMessagePolicyMetric = &skippedMessagePolicyMetrics{
+			skippedMessagesTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_total",
+				Help: "The total number of skipped l1 messages classified by skip reason",
+			}, []string{"reason"}),
+			replaySentTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_replay_sent_total",
+				Help: "The total number of replayMessage transactions sent, forced by an operator or not",
+			}, []string{"forced"}),
+			replaySendFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_replay_send_failure_total",
+				Help: "The total number of replayMessage transactions failed to be sent",
+			}),
+			replayConfirmedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_replay_confirmed_total",
+				Help: "The total number of replayMessage transactions confirmed",
+			}),
+			replayConfirmedFailedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_replay_confirmed_failed_total",
+				Help: "The total number of replayMessage transactions confirmed but failed",
+			}),
+			replayGasEstimationTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_skipped_message_replay_gas_estimation_total",
+				Help: "The total number of replay gas limits estimated, capped by the max gas limit, exceeding it, or falling back to the static gas limit",
+			}, []string{"result"}),
+		}
+	})
+	return skippedMessagePolicyMetric
+}
@@ -0,0 +1,256 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/crossdomain"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/sender"
+	"scroll-tech/rollup/internal/orm"
+)
+
+var (
+	testL1ScrollMessenger = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testL1MessageQueue    = common.HexToAddress("0x1000000000000000000000000000000000000002")
+	testL2ScrollMessenger = common.HexToAddress("0x1000000000000000000000000000000000000003")
+)
+
+type replayTx struct {
+	contextID string
+	value     *big.Int
+	data      []byte
+}
+
+type mockReplaySender struct {
+	err  error
+	sent []replayTx
+}
+
+func (m *mockReplaySender) SendTransactionWithValue(contextID string, _ *common.Address, value *big.Int, data []byte, _ uint64) (common.Hash, error) {
+	if m.err != nil {
+		return common.Hash{}, m.err
+	}
+	m.sent = append(m.sent, replayTx{contextID: contextID, value: value, data: data})
+	return common.BigToHash(big.NewInt(int64(len(m.sent)))), nil
+}
+
+func (m *mockReplaySender) ConfirmChan() <-chan *sender.Confirmation { return nil }
+
+func (m *mockReplaySender) Stop() {}
+
+// mockMessageQueue answers the calls of the replay policy to the L1MessageQueue.
+type mockMessageQueue struct {
+	fee         *big.Int
+	maxGasLimit *big.Int
+}
+
+func (m *mockMessageQueue) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	method, err := bridgeAbi.L1MessageQueueABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "estimateCrossDomainMessageFee":
+		return method.Outputs.Pack(m.fee)
+	case "maxGasLimit":
+		return method.Outputs.Pack(m.maxGasLimit)
+	}
+	return nil, errors.New("unexpected call")
+}
+
+// mockGasEstimator simulates the relayMessage calls of the replay policy on L2.
+type mockGasEstimator struct {
+	gas uint64
+	err error
+}
+
+func (m *mockGasEstimator) EstimateGas(_ context.Context, _ ethereum.CallMsg) (uint64, error) {
+	return m.gas, m.err
+}
+
+func newTestSkippedMessagePolicy(db *gorm.DB, replaySender *mockReplaySender) *SkippedMessagePolicy {
+	return &SkippedMessagePolicy{
+		ctx: context.Background(),
+		cfg: &config.SkippedMessagePolicyConfig{
+			Enabled:                  true,
+			L1ScrollMessengerAddress: testL1ScrollMessenger,
+			L1MessageQueueAddress:    testL1MessageQueue,
+			L2ScrollMessengerAddress: testL2ScrollMessenger,
+			L2BlockGasLimit:          10_000_000,
+			MaxReplayFee:             1000,
+		},
+		replaySender:  replaySender,
+		l1Client:      &mockMessageQueue{fee: big.NewInt(100), maxGasLimit: big.NewInt(10_000_000)},
+		refundAddress: common.HexToAddress("0x1000000000000000000000000000000000000004"),
+		l1MessageOrm:  orm.NewL1Message(db),
+		metrics:       initSkippedMessagePolicyMetrics(nil),
+	}
+}
+
+// saveSkippedMessage saves a skipped relayMessage call whose messenger nonce differs from its queue index.
+func saveSkippedMessage(t *testing.T, db *gorm.DB, queueIndex uint64, gasLimit uint64) {
+	calldata, err := crossdomain.EncodeMessage(crossdomain.EncodingRelayMessage, common.HexToAddress("0x01"), common.HexToAddress("0x02"),
+		big.NewInt(1), new(big.Int).SetUint64(queueIndex+100), []byte{0x01})
+	assert.NoError(t, err)
+	msg := &orm.L1Message{
+		QueueIndex: queueIndex,
+		MsgHash:    common.BigToHash(new(big.Int).SetUint64(queueIndex + 1)).Hex(),
+		GasLimit:   gasLimit,
+		Target:     testL2ScrollMessenger.Hex(),
+		Value:      "1",
+		Calldata:   common.Bytes2Hex(calldata),
+		Layer1Hash: common.BigToHash(new(big.Int).SetUint64(queueIndex + 1)).Hex(),
+		Status:     int(types.MsgSkipped),
+	}
+	assert.NoError(t, orm.NewL1Message(db).SaveL1Messages(context.Background(), []*orm.L1Message{msg}))
+}
+
+func getL1Message(t *testing.T, db *gorm.DB, queueIndex uint64) *orm.L1Message {
+	msg, err := orm.NewL1Message(db).GetL1MessageByQueueIndex(context.Background(), queueIndex)
+	assert.NoError(t, err)
+	return msg
+}
+
+func testSkippedMessagePolicyReplay(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	saveSkippedMessage(t, db, 0, 20_000_000)
+
+	replaySender := &mockReplaySender{}
+	p := newTestSkippedMessagePolicy(db, replaySender)
+	assert.NoError(t, p.replay(getL1Message(t, db, 0), 10_000_000, false))
+
+	// the replay carries the messenger nonce of the message, not its queue index
+	assert.Len(t, replaySender.sent, 1)
+	assert.Equal(t, "0", replaySender.sent[0].contextID)
+	assert.Equal(t, big.NewInt(100), replaySender.sent[0].value)
+	args, err := bridgeAbi.L1ScrollMessengerABI.Methods["replayMessage"].Inputs.Unpack(replaySender.sent[0].data[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), args[3])
+	assert.Equal(t, uint32(10_000_000), args[5])
+
+	msg := getL1Message(t, db, 0)
+	assert.Equal(t, types.MsgReplayed, types.MsgStatus(msg.Status))
+	assert.Equal(t, int16(1), msg.ReplayCount)
+	assert.Equal(t, common.BigToHash(big.NewInt(1)).String(), msg.ReplayTxHash)
+
+	// a message already being replayed is not replayed again
+	msg.Status = int(types.MsgSkipped)
+	assert.NoError(t, p.replay(msg, 10_000_000, false))
+	assert.Len(t, replaySender.sent, 1)
+
+	// the gas limit of replayMessage is an uint32
+	assert.Error(t, p.replay(msg, math.MaxUint32+1, true))
+}
+
+func testSkippedMessagePolicyReplaySendFailed(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	saveSkippedMessage(t, db, 0, 20_000_000)
+	assert.NoError(t, orm.NewL1Message(db).RequestReplay(context.Background(), 0, 12_000_000))
+
+	replaySender := &mockReplaySender{err: errors.New("send failed")}
+	p := newTestSkippedMessagePolicy(db, replaySender)
+	msg := getL1Message(t, db, 0)
+	assert.Error(t, p.replay(msg, *msg.ReplayRequestedGasLimit, true))
+
+	// the message is skipped again and the requested replay is kept
+	msg = getL1Message(t, db, 0)
+	assert.Equal(t, types.MsgSkipped, types.MsgStatus(msg.Status))
+	assert.Equal(t, int16(0), msg.ReplayCount)
+	if assert.NotNil(t, msg.ReplayRequestedGasLimit) {
+		assert.Equal(t, uint64(12_000_000), *msg.ReplayRequestedGasLimit)
+	}
+}
+
+func testSkippedMessagePolicyProcessSkippedMessages(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	saveSkippedMessage(t, db, 0, 20_000_000) // gas limit exceeded, replayed automatically
+	saveSkippedMessage(t, db, 1, 1_000_000)  // circuit capacity, only replayed on request
+	saveSkippedMessage(t, db, 2, 1_000_000)  // circuit capacity, replay requested
+	assert.NoError(t, orm.NewL1Message(db).RequestReplay(context.Background(), 2, 2_000_000))
+
+	replaySender := &mockReplaySender{}
+	p := newTestSkippedMessagePolicy(db, replaySender)
+	p.ProcessSkippedMessages()
+
+	assert.Len(t, replaySender.sent, 2)
+	assert.Equal(t, "0", replaySender.sent[0].contextID)
+	assert.Equal(t, "2", replaySender.sent[1].contextID)
+
+	msg := getL1Message(t, db, 0)
+	assert.Equal(t, types.MsgSkipReasonGasLimitExceeded, types.MsgSkipReason(msg.SkipReason))
+	assert.Equal(t, uint64(10_000_000), msg.ReplayGasLimit)
+	msg = getL1Message(t, db, 1)
+	assert.Equal(t, types.MsgSkipReasonCircuitCapacity, types.MsgSkipReason(msg.SkipReason))
+	assert.Equal(t, types.MsgSkipped, types.MsgStatus(msg.Status))
+	msg = getL1Message(t, db, 2)
+	assert.Equal(t, types.MsgReplayed, types.MsgStatus(msg.Status))
+	assert.Equal(t, uint64(2_000_000), msg.ReplayGasLimit)
+	assert.Nil(t, msg.ReplayRequestedGasLimit)
+
+	// a failed replay is replayed again until the max replays is reached
+	assert.NoError(t, orm.NewL1Message(db).UpdateL1MessageReplayResult(context.Background(), 0, common.BigToHash(big.NewInt(1)).String(), false))
+	p.ProcessSkippedMessages()
+	assert.Len(t, replaySender.sent, 2)
+}
+
+func testSkippedMessagePolicyReplayGasEstimation(t *testing.T) {
+	db := setupL1RelayerDB(t)
+	defer database.CloseDB(db)
+	saveSkippedMessage(t, db, 0, 20_000_000)
+
+	p := newTestSkippedMessagePolicy(db, &mockReplaySender{})
+	p.cfg.ReplayGasLimit = 8_000_000
+	estimator := &mockGasEstimator{gas: 100_000}
+	p.l2Client = estimator
+
+	// the static gas limit if the estimation is not enabled.
+	gasLimit, ok := p.replayGasLimit(getL1Message(t, db, 0))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(8_000_000), gasLimit)
+	assert.Nil(t, getL1Message(t, db, 0).ReplayEstimatedGas)
+
+	// the estimated gas with the default margin, the estimate is recorded.
+	p.cfg.GasEstimation = &config.ReplayGasEstimationConfig{Enabled: true, MinGasLimit: 200_000}
+	gasLimit, ok = p.replayGasLimit(getL1Message(t, db, 0))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(200_000), gasLimit)
+	estimator.gas = 1_000_000
+	gasLimit, ok = p.replayGasLimit(getL1Message(t, db, 0))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1_500_000), gasLimit)
+	if msg := getL1Message(t, db, 0); assert.NotNil(t, msg.ReplayEstimatedGas) {
+		assert.Equal(t, uint64(1_000_000), *msg.ReplayEstimatedGas)
+	}
+
+	// the margin is cut by the max gas limit, a message needing more is not replayed automatically.
+	estimator.gas = 9_000_000
+	gasLimit, ok = p.replayGasLimit(getL1Message(t, db, 0))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10_000_000), gasLimit)
+	estimator.gas = 11_000_000
+	_, ok = p.replayGasLimit(getL1Message(t, db, 0))
+	assert.False(t, ok)
+
+	// the static gas limit if the simulation reverts.
+	estimator.err = errors.New("execution reverted")
+	gasLimit, ok = p.replayGasLimit(getL1Message(t, db, 0))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(8_000_000), gasLimit)
+	assert.Nil(t, getL1Message(t, db, 0).ReplayEstimatedGas)
+}
@@ -0,0 +1,63 @@
+package sender
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrTooManyPendingTransactions is returned when the account of a sender has as many unconfirmed transactions as
+// allowed, new transactions are accepted again once some are confirmed.
+var ErrTooManyPendingTransactions = errors.New("too many pending transactions")
+
+// backpressure caps the number of unconfirmed transactions of an account, so that transactions do not pile up
+// behind a stuck nonce while L1 is congested. It is safe for concurrent use.
+type backpressure struct {
+	max uint64 // 0 disables the cap
+
+	mu      sync.Mutex
+	pending uint64
+	paused  bool
+}
+
+func newBackpressure(max, pending uint64) *backpressure {
+	return &backpressure{max: max, pending: pending}
+}
+
+// admit returns whether a new transaction can be sent, and whether the sender has just been paused by this call.
+func (b *backpressure) admit() (ok bool, paused bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.max == 0 || b.pending < b.max {
+		return true, false
+	}
+	paused = !b.paused
+	b.paused = true
+	return false, paused
+}
+
+// sent counts a new unconfirmed transaction.
+func (b *backpressure) sent() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending++
+}
+
+// update sets the number of unconfirmed transactions counted after a check of the pending transactions, and
+// returns whether the sender has been resumed by this call.
+func (b *backpressure) update(pending uint64) (resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = pending
+	if b.paused && (b.max == 0 || b.pending < b.max) {
+		b.paused = false
+		return true
+	}
+	return false
+}
+
+// count returns the number of unconfirmed transactions.
+func (b *backpressure) count() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending
+}
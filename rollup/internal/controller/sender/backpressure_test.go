@@ -0,0 +1,42 @@
+package sender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackpressure(t *testing.T) {
+	b := newBackpressure(2, 1)
+
+	ok, paused := b.admit()
+	assert.True(t, ok)
+	assert.False(t, paused)
+	b.sent()
+	assert.Equal(t, uint64(2), b.count())
+
+	// the cap is reached, the sender is paused once.
+	ok, paused = b.admit()
+	assert.False(t, ok)
+	assert.True(t, paused)
+	ok, paused = b.admit()
+	assert.False(t, ok)
+	assert.False(t, paused)
+
+	// no confirmation yet.
+	assert.False(t, b.update(2))
+	ok, _ = b.admit()
+	assert.False(t, ok)
+
+	// a transaction is confirmed, the sender resumes.
+	assert.True(t, b.update(1))
+	assert.False(t, b.update(1))
+	ok, paused = b.admit()
+	assert.True(t, ok)
+	assert.False(t, paused)
+
+	// 0 disables the cap.
+	unlimited := newBackpressure(0, 100)
+	ok, _ = unlimited.admit()
+	assert.True(t, ok)
+}
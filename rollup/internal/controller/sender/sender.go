@@ -65,6 +65,7 @@ type Sender struct {
 	config     *config.SenderConfig
 	gethClient *gethclient.Client
 	client     *ethclient.Client // The client to retrieve on chain data or send transaction.
+	submitter  txSubmitter       // The submitter to send transaction, client with retries unless an alternative endpoint is configured.
 	chainID    *big.Int          // The chain id of the endpoint
 	ctx        context.Context
 	service    string
@@ -72,10 +73,14 @@ type Sender struct {
 	senderType types.SenderType
 
 	auth *bind.TransactOpts
+	// pooled senders share their sender type with the other accounts of a sender pool, and only check their own pending txs.
+	pooled bool
 
 	db                    *gorm.DB
 	pendingTransactionOrm *orm.PendingTransaction
 
+	backpressure *backpressure
+
 	confirmCh chan *Confirmation
 	stopCh    chan struct{}
 
@@ -84,6 +89,10 @@ type Sender struct {
 
 // NewSender returns a new instance of transaction sender
 func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.PrivateKey, service, name string, senderType types.SenderType, db *gorm.DB, reg prometheus.Registerer) (*Sender, error) {
+	return newSender(ctx, config, priv, service, name, senderType, false, db, reg)
+}
+
+func newSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.PrivateKey, service, name string, senderType types.SenderType, pooled bool, db *gorm.DB, reg prometheus.Registerer) (*Sender, error) {
 	if config.EscalateMultipleNum <= config.EscalateMultipleDen {
 		return nil, fmt.Errorf("invalid params, EscalateMultipleNum; %v, EscalateMultipleDen: %v", config.EscalateMultipleNum, config.EscalateMultipleDen)
 	}
@@ -118,6 +127,7 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 		client:                client,
 		chainID:               chainID,
 		auth:                  auth,
+		pooled:                pooled,
 		db:                    db,
 		pendingTransactionOrm: orm.NewPendingTransaction(db),
 		confirmCh:             make(chan *Confirmation, 128),
@@ -127,6 +137,36 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 		senderType:            senderType,
 	}
 	sender.metrics = initSenderMetrics(reg)
+	// The transient failures of the public endpoint and of the alternative one are retried separately, before falling back.
+	retryTotal := sender.metrics.sendTransactionRetryTotal.WithLabelValues(service, name)
+	publicSubmitter := newRetrySubmitter(client, config.SendRetry, retryTotal)
+	sender.submitter = publicSubmitter
+
+	pending, err := sender.pendingTransactionOrm.CountPendingNoncesBySenderAddress(ctx, auth.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending transactions of address %s, err: %w", auth.From.Hex(), err)
+	}
+	sender.backpressure = newBackpressure(config.MaxPendingTransactions, pending)
+	sender.metrics.pendingTransactions.WithLabelValues(service, name, auth.From.String()).Set(float64(pending))
+
+	if submitterCfg := config.Submitters[name]; submitterCfg != nil {
+		primary, err := newRPCSubmitter(submitterCfg, priv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tx submitter of %s, err: %w", name, err)
+		}
+		submitter := &fallbackSubmitter{
+			primary:       newRetrySubmitter(primary, config.SendRetry, retryTotal),
+			sentTotal:     sender.metrics.submitterSendTransactionTotal.WithLabelValues(service, name),
+			failureTotal:  sender.metrics.submitterSendTransactionFailureTotal.WithLabelValues(service, name),
+			fallbackTotal: sender.metrics.submitterFallbackTotal.WithLabelValues(service, name),
+		}
+		if submitterCfg.FallbackToPublic {
+			submitter.fallback = publicSubmitter
+		}
+		sender.submitter = submitter
+		log.Info("sender submits transactions through an alternative endpoint", "service", service, "name", name,
+			"method", primary.method, "signRequests", submitterCfg.SignRequests, "fallbackToPublic", submitterCfg.FallbackToPublic)
+	}
 
 	go sender.loop(ctx)
 
@@ -138,6 +178,16 @@ func (s *Sender) GetChainID() *big.Int {
 	return s.chainID
 }
 
+// GetAddress returns the address of the sender account.
+func (s *Sender) GetAddress() common.Address {
+	return s.auth.From
+}
+
+// GetBalance returns the latest balance of the sender account.
+func (s *Sender) GetBalance(ctx context.Context) (*big.Int, error) {
+	return s.client.BalanceAt(ctx, s.auth.From, nil)
+}
+
 // Stop stop the sender module.
 func (s *Sender) Stop() {
 	close(s.stopCh)
@@ -155,13 +205,13 @@ func (s *Sender) SendConfirmation(cfm *Confirmation) {
 	s.confirmCh <- cfm
 }
 
-func (s *Sender) getFeeData(target *common.Address, data []byte, sidecar *gethTypes.BlobTxSidecar, baseFee, blobBaseFee uint64, fallbackGasLimit uint64) (*FeeData, error) {
+func (s *Sender) getFeeData(target *common.Address, value *big.Int, data []byte, sidecar *gethTypes.BlobTxSidecar, baseFee, blobBaseFee uint64, fallbackGasLimit uint64) (*FeeData, error) {
 	switch s.config.TxType {
 	case LegacyTxType:
-		return s.estimateLegacyGas(target, data, fallbackGasLimit)
+		return s.estimateLegacyGas(target, value, data, fallbackGasLimit)
 	case DynamicFeeTxType:
 		if sidecar == nil {
-			return s.estimateDynamicGas(target, data, baseFee, fallbackGasLimit)
+			return s.estimateDynamicGas(target, value, data, baseFee, fallbackGasLimit)
 		}
 		return s.estimateBlobGas(target, data, sidecar, baseFee, blobBaseFee, fallbackGasLimit)
 	default:
@@ -171,7 +221,25 @@ func (s *Sender) getFeeData(target *common.Address, data []byte, sidecar *gethTy
 
 // SendTransaction send a signed L2tL1 transaction.
 func (s *Sender) SendTransaction(contextID string, target *common.Address, data []byte, blob *kzg4844.Blob, fallbackGasLimit uint64) (common.Hash, error) {
+	return s.sendTransaction(contextID, target, nil, data, blob, fallbackGasLimit)
+}
+
+// SendTransactionWithValue send a signed transaction transferring value, e.g. to pay the fee of a payable call.
+func (s *Sender) SendTransactionWithValue(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return s.sendTransaction(contextID, target, value, data, nil, fallbackGasLimit)
+}
+
+func (s *Sender) sendTransaction(contextID string, target *common.Address, value *big.Int, data []byte, blob *kzg4844.Blob, fallbackGasLimit uint64) (common.Hash, error) {
 	s.metrics.sendTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+	if ok, paused := s.backpressure.admit(); !ok {
+		s.metrics.sendTransactionBackpressureTotal.WithLabelValues(s.service, s.name).Inc()
+		if paused {
+			log.Warn("sender paused until pending transactions are confirmed", "service", s.service, "name", s.name,
+				"from", s.auth.From.String(), "pending", s.backpressure.count(), "max", s.config.MaxPendingTransactions)
+		}
+		return common.Hash{}, ErrTooManyPendingTransactions
+	}
+
 	var (
 		feeData *FeeData
 		tx      *gethTypes.Transaction
@@ -193,13 +261,13 @@ func (s *Sender) SendTransaction(contextID string, target *common.Address, data
 		return common.Hash{}, fmt.Errorf("failed to get block number and base fee, err: %w", err)
 	}
 
-	if feeData, err = s.getFeeData(target, data, sidecar, baseFee, blobBaseFee, fallbackGasLimit); err != nil {
+	if feeData, err = s.getFeeData(target, value, data, sidecar, baseFee, blobBaseFee, fallbackGasLimit); err != nil {
 		s.metrics.sendTransactionFailureGetFee.WithLabelValues(s.service, s.name).Inc()
 		log.Error("failed to get fee data", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "fallback gas limit", fallbackGasLimit, "err", err)
 		return common.Hash{}, fmt.Errorf("failed to get fee data, err: %w", err)
 	}
 
-	if tx, err = s.createAndSendTx(feeData, target, data, sidecar, nil); err != nil {
+	if tx, err = s.createAndSendTx(feeData, target, value, data, sidecar, nil); err != nil {
 		s.metrics.sendTransactionFailureSendTx.WithLabelValues(s.service, s.name).Inc()
 		log.Error("failed to create and send tx (non-resubmit case)", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "err", err)
 		return common.Hash{}, fmt.Errorf("failed to create and send transaction, err: %w", err)
@@ -209,10 +277,12 @@ func (s *Sender) SendTransaction(contextID string, target *common.Address, data
 		log.Error("failed to insert transaction", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "err", err)
 		return common.Hash{}, fmt.Errorf("failed to insert transaction, err: %w", err)
 	}
+	s.backpressure.sent()
+	s.metrics.pendingTransactions.WithLabelValues(s.service, s.name, s.auth.From.String()).Set(float64(s.backpressure.count()))
 	return tx.Hash(), nil
 }
 
-func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, data []byte, sidecar *gethTypes.BlobTxSidecar, overrideNonce *uint64) (*gethTypes.Transaction, error) {
+func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value *big.Int, data []byte, sidecar *gethTypes.BlobTxSidecar, overrideNonce *uint64) (*gethTypes.Transaction, error) {
 	var (
 		nonce  = s.auth.Nonce.Uint64()
 		txData gethTypes.TxData
@@ -230,6 +300,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, data
 			GasPrice: feeData.gasPrice,
 			Gas:      feeData.gasLimit,
 			To:       target,
+			Value:    value,
 			Data:     data,
 		}
 	case DynamicFeeTxType:
@@ -237,6 +308,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, data
 			txData = &gethTypes.DynamicFeeTx{
 				Nonce:      nonce,
 				To:         target,
+				Value:      value,
 				Data:       data,
 				Gas:        feeData.gasLimit,
 				AccessList: feeData.accessList,
@@ -273,7 +345,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, data
 		return nil, err
 	}
 
-	if err = s.client.SendTransaction(s.ctx, signedTx); err != nil {
+	if err = s.submitter.SendTransaction(s.ctx, signedTx); err != nil {
 		log.Error("failed to send tx", "tx hash", signedTx.Hash().String(), "from", s.auth.From.String(), "nonce", signedTx.Nonce(), "err", err)
 		// Check if contain nonce, and reset nonce
 		// only reset nonce when it is not from resubmit
@@ -449,7 +521,7 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee, blobBas
 
 	nonce := tx.Nonce()
 	s.metrics.resubmitTransactionTotal.WithLabelValues(s.service, s.name).Inc()
-	tx, err := s.createAndSendTx(&feeData, tx.To(), tx.Data(), tx.BlobTxSidecar(), &nonce)
+	tx, err := s.createAndSendTx(&feeData, tx.To(), tx.Value(), tx.Data(), tx.BlobTxSidecar(), &nonce)
 	if err != nil {
 		log.Error("failed to create and send tx (resubmit case)", "from", s.auth.From.String(), "nonce", nonce, "err", err)
 		return nil, err
@@ -461,6 +533,7 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee, blobBas
 // If a transaction hasn't been confirmed after a certain number of blocks, it will be resubmitted with an increased gas price.
 func (s *Sender) checkPendingTransaction() {
 	s.metrics.senderCheckPendingTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+	defer s.updateBackpressure()
 
 	blockNumber, baseFee, blobBaseFee, err := s.getBlockNumberAndBaseFeeAndBlobFee(s.ctx)
 	if err != nil {
@@ -468,7 +541,12 @@ func (s *Sender) checkPendingTransaction() {
 		return
 	}
 
-	transactionsToCheck, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(s.ctx, s.senderType, 100)
+	var transactionsToCheck []orm.PendingTransaction
+	if s.pooled {
+		transactionsToCheck, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderAddress(s.ctx, s.senderType, s.auth.From, 100)
+	} else {
+		transactionsToCheck, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(s.ctx, s.senderType, 100)
+	}
 	if err != nil {
 		log.Error("failed to load pending transactions", "sender meta", s.getSenderMeta(), "err", err)
 		return
@@ -564,6 +642,21 @@ func (s *Sender) checkPendingTransaction() {
 	}
 }
 
+// updateBackpressure recounts the unconfirmed transactions of the account, resuming the sender once they are below
+// the max pending transactions.
+func (s *Sender) updateBackpressure() {
+	pending, err := s.pendingTransactionOrm.CountPendingNoncesBySenderAddress(s.ctx, s.auth.From)
+	if err != nil {
+		log.Error("failed to count pending transactions", "sender meta", s.getSenderMeta(), "err", err)
+		return
+	}
+	s.metrics.pendingTransactions.WithLabelValues(s.service, s.name, s.auth.From.String()).Set(float64(pending))
+	if s.backpressure.update(pending) {
+		log.Info("sender resumed", "service", s.service, "name", s.name, "from", s.auth.From.String(),
+			"pending", pending, "max", s.config.MaxPendingTransactions)
+	}
+}
+
 // Loop is the main event loop
 func (s *Sender) loop(ctx context.Context) {
 	checkTick := time.NewTicker(time.Duration(s.config.CheckPendingTime) * time.Second)
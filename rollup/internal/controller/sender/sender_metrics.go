@@ -19,6 +19,18 @@ type senderMetrics struct {
 	currentGasPrice                    *prometheus.GaugeVec
 	currentBlobGasFeeCap               *prometheus.GaugeVec
 	currentGasLimit                    *prometheus.GaugeVec
+	pendingTransactions                *prometheus.GaugeVec
+	sendTransactionBackpressureTotal   *prometheus.CounterVec
+	sendTransactionRetryTotal          *prometheus.CounterVec
+
+	submitterSendTransactionTotal        *prometheus.CounterVec
+	submitterSendTransactionFailureTotal *prometheus.CounterVec
+	submitterFallbackTotal               *prometheus.CounterVec
+
+	poolAccountInFlightTransactions *prometheus.GaugeVec
+	poolAccountStuck                *prometheus.GaugeVec
+	poolAccountBalance              *prometheus.GaugeVec
+	poolAllAccountsStuckTotal       *prometheus.CounterVec
 }
 
 var (
@@ -37,6 +49,22 @@ func initSenderMetrics(reg prometheus.Registerer) *senderMetrics {
 				Name: "rollup_sender_send_transaction_get_fee_failure_total",
 				Help: "The total number of sending transactions failure for getting fee.",
 			}, []string{"service", "name"}),
+			submitterSendTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_submitter_send_transaction_total",
+				Help: "The total number of transactions sent through the alternative submitter.",
+			}, []string{"service", "name"}),
+			submitterSendTransactionFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_submitter_send_transaction_failure_total",
+				Help: "The total number of transactions failed to be sent through the alternative submitter.",
+			}, []string{"service", "name"}),
+			submitterFallbackTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_submitter_fallback_total",
+				Help: "The total number of transactions sent through the public endpoint after the submitter failed.",
+			}, []string{"service", "name"}),
+			sendTransactionRetryTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_send_transaction_retry_total",
+				Help: "The total number of retries of sending transactions after transient failures.",
+			}, []string{"service", "name"}),
 			sendTransactionFailureSendTx: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 				Name: "rollup_sender_send_transaction_send_tx_failure_total",
 				Help: "The total number of sending transactions failure for sending tx.",
@@ -69,10 +97,34 @@ func initSenderMetrics(reg prometheus.Registerer) *senderMetrics {
 				Name: "rollup_sender_gas_limit",
 				Help: "The gas limit of current transaction.",
 			}, []string{"service", "name"}),
+			pendingTransactions: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_pending_transactions",
+				Help: "The number of unconfirmed transactions of the account of a sender.",
+			}, []string{"service", "name", "address"}),
+			sendTransactionBackpressureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_send_transaction_backpressure_total",
+				Help: "The total number of transactions rejected because the sender had the max number of pending transactions.",
+			}, []string{"service", "name"}),
 			senderCheckPendingTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 				Name: "rollup_sender_check_pending_transaction_total",
 				Help: "The total number of check pending transaction.",
 			}, []string{"service", "name"}),
+			poolAccountInFlightTransactions: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_pool_account_in_flight_transactions",
+				Help: "The number of unconfirmed transactions sent by an account of a sender pool.",
+			}, []string{"service", "name", "address"}),
+			poolAccountStuck: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_pool_account_stuck",
+				Help: "Whether an account of a sender pool is stuck, i.e. has a transaction unconfirmed for longer than the stuck timeout.",
+			}, []string{"service", "name", "address"}),
+			poolAccountBalance: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_pool_account_balance",
+				Help: "The balance in wei of an account of a sender pool.",
+			}, []string{"service", "name", "address"}),
+			poolAllAccountsStuckTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_pool_all_accounts_stuck_total",
+				Help: "The total number of transactions rejected because all accounts of a sender pool were stuck.",
+			}, []string{"service", "name"}),
 		}
 	})
 
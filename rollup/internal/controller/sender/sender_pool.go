@@ -0,0 +1,327 @@
+package sender
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+const (
+	defaultPoolStuckTimeoutSec      = 600
+	defaultPoolRebalanceIntervalSec = 60
+)
+
+// ErrAllAccountsStuck is returned when no account of a sender pool can take a new transaction.
+var ErrAllAccountsStuck = errors.New("all accounts of the sender pool are stuck")
+
+// AccountState is the state of an account of a sender pool, reported to the rebalance hook.
+type AccountState struct {
+	Address  common.Address
+	Balance  *big.Int // nil if the balance could not be read
+	InFlight int      // number of unconfirmed transactions
+	Stuck    bool     // whether a transaction is unconfirmed for longer than the stuck timeout
+}
+
+// RebalanceHook is called with the states of the accounts of a sender pool on every rebalance check,
+// e.g. to top up the accounts running low on funds from a treasury.
+type RebalanceHook func(accounts []*AccountState)
+
+// poolMember sends the transactions of an account of a sender pool, it is a *Sender outside of tests.
+type poolMember interface {
+	SendTransaction(contextID string, target *common.Address, data []byte, blob *kzg4844.Blob, fallbackGasLimit uint64) (common.Hash, error)
+	SendTransactionWithValue(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error)
+	ConfirmChan() <-chan *Confirmation
+	GetAddress() common.Address
+	GetBalance(ctx context.Context) (*big.Int, error)
+	Stop()
+}
+
+type inFlightTx struct {
+	member int
+	sentAt time.Time
+}
+
+// SenderPool distributes transactions over the senders of multiple accounts. Each sender manages the nonces and
+// pending transactions of its own account, so the nonce space is sharded by account and a transaction stuck in one
+// account does not block the others. New transactions go to the least loaded account which is not stuck.
+//
+// Transactions of different accounts may be included in any order, so the pool is only suited for transactions
+// which do not depend on each other, e.g. message replays, not batch commits.
+type SenderPool struct {
+	ctx     context.Context
+	cfg     *config.SenderPoolConfig
+	service string
+	name    string
+
+	members []poolMember
+
+	mu            sync.Mutex
+	inFlight      map[string]*inFlightTx // by context id
+	next          int                    // round-robin start among equally loaded accounts
+	rebalanceHook RebalanceHook
+
+	confirmCh chan *Confirmation
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+
+	metrics *senderMetrics
+}
+
+// NewSenderPool returns a new sender pool sending transactions from the accounts of the private keys.
+func NewSenderPool(ctx context.Context, cfg *config.SenderConfig, privs []*ecdsa.PrivateKey, service, name string, senderType types.SenderType, db *gorm.DB, reg prometheus.Registerer) (*SenderPool, error) {
+	if len(privs) == 0 {
+		return nil, errors.New("sender pool requires at least one private key")
+	}
+
+	members := make([]poolMember, 0, len(privs))
+	for _, priv := range privs {
+		member, err := newSender(ctx, cfg, priv, service, name, senderType, true, db, reg)
+		if err != nil {
+			for _, m := range members {
+				m.Stop()
+			}
+			return nil, fmt.Errorf("failed to create sender of pool %s, err: %w", name, err)
+		}
+		members = append(members, member)
+	}
+
+	poolCfg := cfg.Pool
+	if poolCfg == nil {
+		poolCfg = &config.SenderPoolConfig{}
+	}
+	p := newSenderPool(ctx, poolCfg, service, name, members, initSenderMetrics(reg))
+	p.start()
+
+	log.Info("sender pool started", "service", service, "name", name, "accounts", len(members))
+	return p, nil
+}
+
+func newSenderPool(ctx context.Context, cfg *config.SenderPoolConfig, service, name string, members []poolMember, metrics *senderMetrics) *SenderPool {
+	return &SenderPool{
+		ctx:       ctx,
+		cfg:       cfg,
+		service:   service,
+		name:      name,
+		members:   members,
+		inFlight:  make(map[string]*inFlightTx),
+		confirmCh: make(chan *Confirmation, 128),
+		stopCh:    make(chan struct{}),
+		metrics:   metrics,
+	}
+}
+
+func (p *SenderPool) start() {
+	for i := range p.members {
+		go p.forwardConfirmations(i)
+	}
+	go p.rebalanceLoop()
+}
+
+// SetRebalanceHook sets the hook called on every rebalance check, it must not block for long.
+func (p *SenderPool) SetRebalanceHook(hook RebalanceHook) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rebalanceHook = hook
+}
+
+// SendTransaction sends a transaction from the least loaded account of the pool.
+func (p *SenderPool) SendTransaction(contextID string, target *common.Address, data []byte, blob *kzg4844.Blob, fallbackGasLimit uint64) (common.Hash, error) {
+	return p.send(contextID, func(m poolMember) (common.Hash, error) {
+		return m.SendTransaction(contextID, target, data, blob, fallbackGasLimit)
+	})
+}
+
+// SendTransactionWithValue sends a transaction transferring value from the least loaded account of the pool.
+func (p *SenderPool) SendTransactionWithValue(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return p.send(contextID, func(m poolMember) (common.Hash, error) {
+		return m.SendTransactionWithValue(contextID, target, value, data, fallbackGasLimit)
+	})
+}
+
+// ConfirmChan returns the confirmations of the transactions of all accounts of the pool.
+func (p *SenderPool) ConfirmChan() <-chan *Confirmation {
+	return p.confirmCh
+}
+
+// Stop stops the pool and the senders of its accounts.
+func (p *SenderPool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		for _, m := range p.members {
+			m.Stop()
+		}
+		log.Info("sender pool stopped", "service", p.service, "name", p.name)
+	})
+}
+
+// send sends a transaction through the picked account. A failed send is not retried from another account,
+// as the transaction may have been broadcast before the failure, e.g. when it could not be stored.
+func (p *SenderPool) send(contextID string, sendFn func(m poolMember) (common.Hash, error)) (common.Hash, error) {
+	member, err := p.pick()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := sendFn(p.members[member])
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	p.mu.Lock()
+	p.inFlight[contextID] = &inFlightTx{member: member, sentAt: time.Now()}
+	p.mu.Unlock()
+	p.updateInFlightMetrics()
+	return hash, nil
+}
+
+// pick returns the index of the account with the fewest in-flight transactions which is not stuck.
+func (p *SenderPool) pick() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	inFlight, stuck := p.loadLocked()
+	best := -1
+	for i := 0; i < len(p.members); i++ {
+		member := (p.next + i) % len(p.members)
+		if stuck[member] {
+			continue
+		}
+		if best == -1 || inFlight[member] < inFlight[best] {
+			best = member
+		}
+	}
+	if best == -1 {
+		p.metrics.poolAllAccountsStuckTotal.WithLabelValues(p.service, p.name).Inc()
+		return 0, ErrAllAccountsStuck
+	}
+	p.next = (best + 1) % len(p.members)
+	return best, nil
+}
+
+// loadLocked returns the number of in-flight transactions and whether each account is stuck, p.mu must be held.
+func (p *SenderPool) loadLocked() ([]int, []bool) {
+	stuckTimeout := time.Duration(p.cfg.StuckTimeoutSec) * time.Second
+	if stuckTimeout == 0 {
+		stuckTimeout = defaultPoolStuckTimeoutSec * time.Second
+	}
+	inFlight := make([]int, len(p.members))
+	stuck := make([]bool, len(p.members))
+	for _, tx := range p.inFlight {
+		inFlight[tx.member]++
+		if time.Since(tx.sentAt) > stuckTimeout {
+			stuck[tx.member] = true
+		}
+	}
+	return inFlight, stuck
+}
+
+func (p *SenderPool) forwardConfirmations(member int) {
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case cfm := <-p.members[member].ConfirmChan():
+			p.mu.Lock()
+			if tx, ok := p.inFlight[cfm.ContextID]; ok && tx.member == member {
+				delete(p.inFlight, cfm.ContextID)
+			}
+			p.mu.Unlock()
+			p.updateInFlightMetrics()
+
+			select {
+			case p.confirmCh <- cfm:
+			case <-p.ctx.Done():
+				return
+			case <-p.stopCh:
+				return
+			}
+		}
+	}
+}
+
+func (p *SenderPool) rebalanceLoop() {
+	interval := time.Duration(p.cfg.RebalanceIntervalSec) * time.Second
+	if interval == 0 {
+		interval = defaultPoolRebalanceIntervalSec * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.rebalance()
+		}
+	}
+}
+
+// rebalance reports the states of the accounts, warns about stuck accounts and accounts below the minimum balance,
+// and calls the rebalance hook.
+func (p *SenderPool) rebalance() {
+	p.mu.Lock()
+	inFlight, stuck := p.loadLocked()
+	hook := p.rebalanceHook
+	p.mu.Unlock()
+
+	accounts := make([]*AccountState, 0, len(p.members))
+	for i, m := range p.members {
+		address := m.GetAddress()
+		state := &AccountState{Address: address, InFlight: inFlight[i], Stuck: stuck[i]}
+
+		balance, err := m.GetBalance(p.ctx)
+		if err != nil {
+			log.Warn("failed to get balance of sender pool account", "service", p.service, "name", p.name, "address", address.Hex(), "err", err)
+		} else {
+			state.Balance = balance
+			balanceFloat, _ := new(big.Float).SetInt(balance).Float64()
+			p.metrics.poolAccountBalance.WithLabelValues(p.service, p.name, address.Hex()).Set(balanceFloat)
+			if p.cfg.MinBalance != nil && balance.Cmp(p.cfg.MinBalance) < 0 {
+				log.Warn("sender pool account below min balance", "service", p.service, "name", p.name, "address", address.Hex(), "balance", balance, "min balance", p.cfg.MinBalance)
+			}
+		}
+		if state.Stuck {
+			log.Warn("sender pool account is stuck", "service", p.service, "name", p.name, "address", address.Hex(), "in flight", state.InFlight)
+		}
+		accounts = append(accounts, state)
+	}
+	p.updateInFlightMetrics()
+
+	if hook != nil {
+		hook(accounts)
+	}
+}
+
+func (p *SenderPool) updateInFlightMetrics() {
+	p.mu.Lock()
+	inFlight, stuck := p.loadLocked()
+	p.mu.Unlock()
+
+	for i, m := range p.members {
+		address := m.GetAddress().Hex()
+		p.metrics.poolAccountInFlightTransactions.WithLabelValues(p.service, p.name, address).Set(float64(inFlight[i]))
+		var stuckValue float64
+		if stuck[i] {
+			stuckValue = 1
+		}
+		p.metrics.poolAccountStuck.WithLabelValues(p.service, p.name, address).Set(stuckValue)
+	}
+}
@@ -0,0 +1,132 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+type mockPoolMember struct {
+	address   common.Address
+	balance   *big.Int
+	sendErr   error
+	sent      []string
+	confirmCh chan *Confirmation
+}
+
+func newMockPoolMember(i int64) *mockPoolMember {
+	return &mockPoolMember{
+		address:   common.BigToAddress(big.NewInt(i)),
+		balance:   big.NewInt(i),
+		confirmCh: make(chan *Confirmation, 8),
+	}
+}
+
+func (m *mockPoolMember) SendTransaction(contextID string, _ *common.Address, _ []byte, _ *kzg4844.Blob, _ uint64) (common.Hash, error) {
+	if m.sendErr != nil {
+		return common.Hash{}, m.sendErr
+	}
+	m.sent = append(m.sent, contextID)
+	return common.BytesToHash([]byte(contextID)), nil
+}
+
+func (m *mockPoolMember) SendTransactionWithValue(contextID string, target *common.Address, _ *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return m.SendTransaction(contextID, target, data, nil, fallbackGasLimit)
+}
+
+func (m *mockPoolMember) ConfirmChan() <-chan *Confirmation { return m.confirmCh }
+
+func (m *mockPoolMember) GetAddress() common.Address { return m.address }
+
+func (m *mockPoolMember) GetBalance(context.Context) (*big.Int, error) { return m.balance, nil }
+
+func (m *mockPoolMember) Stop() {}
+
+func newTestSenderPool(cfg *config.SenderPoolConfig, members ...*mockPoolMember) *SenderPool {
+	var poolMembers []poolMember
+	for _, m := range members {
+		poolMembers = append(poolMembers, m)
+	}
+	return newSenderPool(context.Background(), cfg, "test", "pool_sender", poolMembers, initSenderMetrics(nil))
+}
+
+func TestSenderPoolDistributesTransactions(t *testing.T) {
+	a, b := newMockPoolMember(1), newMockPoolMember(2)
+	p := newTestSenderPool(&config.SenderPoolConfig{}, a, b)
+	p.start()
+	defer p.Stop()
+
+	for _, contextID := range []string{"0", "1", "2", "3"} {
+		_, err := p.SendTransaction(contextID, nil, nil, nil, 0)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"0", "2"}, a.sent)
+	assert.Equal(t, []string{"1", "3"}, b.sent)
+
+	// confirmations are forwarded and free the account, which then takes the next transaction.
+	a.confirmCh <- &Confirmation{ContextID: "0", IsSuccessful: true}
+	a.confirmCh <- &Confirmation{ContextID: "2", IsSuccessful: true}
+	for _, contextID := range []string{"0", "2"} {
+		select {
+		case cfm := <-p.ConfirmChan():
+			assert.Equal(t, contextID, cfm.ContextID)
+		case <-time.After(time.Second):
+			t.Fatal("confirmation not forwarded")
+		}
+	}
+	_, err := p.SendTransaction("4", nil, nil, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "2", "4"}, a.sent)
+
+	// a failed send is not retried from another account.
+	a.sendErr = errors.New("insufficient funds")
+	_, err = p.SendTransaction("5", nil, nil, nil, 0)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"1", "3"}, b.sent)
+}
+
+func TestSenderPoolSkipsStuckAccounts(t *testing.T) {
+	a, b := newMockPoolMember(1), newMockPoolMember(2)
+	p := newTestSenderPool(&config.SenderPoolConfig{StuckTimeoutSec: 60}, a, b)
+
+	_, err := p.SendTransaction("0", nil, nil, nil, 0)
+	assert.NoError(t, err)
+	p.inFlight["0"].sentAt = time.Now().Add(-2 * time.Minute)
+
+	// the stuck account takes no new transactions, even while less loaded.
+	for _, contextID := range []string{"1", "2"} {
+		_, err = p.SendTransaction(contextID, nil, nil, nil, 0)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"0"}, a.sent)
+	assert.Equal(t, []string{"1", "2"}, b.sent)
+
+	p.inFlight["1"].sentAt = time.Now().Add(-2 * time.Minute)
+	_, err = p.SendTransaction("3", nil, nil, nil, 0)
+	assert.ErrorIs(t, err, ErrAllAccountsStuck)
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.poolAllAccountsStuckTotal.WithLabelValues("test", "pool_sender")))
+}
+
+func TestSenderPoolRebalanceHook(t *testing.T) {
+	a, b := newMockPoolMember(1), newMockPoolMember(2)
+	p := newTestSenderPool(&config.SenderPoolConfig{MinBalance: big.NewInt(2)}, a, b)
+	_, err := p.SendTransaction("0", nil, nil, nil, 0)
+	assert.NoError(t, err)
+
+	var accounts []*AccountState
+	p.SetRebalanceHook(func(states []*AccountState) { accounts = states })
+	p.rebalance()
+
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, &AccountState{Address: a.address, Balance: big.NewInt(1), InFlight: 1}, accounts[0])
+	assert.Equal(t, &AccountState{Address: b.address, Balance: big.NewInt(2)}, accounts[1])
+}
@@ -0,0 +1,219 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/accounts"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"scroll-tech/common/retry"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+const (
+	// sendRawTransactionMethod is the standard method to submit a signed transaction, also served by most private RPCs.
+	sendRawTransactionMethod = "eth_sendRawTransaction"
+	// sendPrivateTransactionMethod is the Flashbots style method to submit a signed transaction without it entering the public mempool.
+	sendPrivateTransactionMethod = "eth_sendPrivateTransaction"
+	// flashbotsSignatureHeader carries the signature of the request body Flashbots requires on every request.
+	flashbotsSignatureHeader = "X-Flashbots-Signature"
+
+	defaultSendRetryMaxAttempts    = 3
+	defaultSendRetryInitialBackoff = 200 * time.Millisecond
+	defaultSendRetryBudget         = 10 * time.Second
+)
+
+// txSubmitter submits signed transactions, the public endpoint of a sender is a txSubmitter itself.
+type txSubmitter interface {
+	SendTransaction(ctx context.Context, tx *gethTypes.Transaction) error
+}
+
+// rpcSubmitter submits signed transactions to an alternative endpoint, e.g. a private RPC or an external tx-service,
+// instead of broadcasting them through the public endpoint of the sender.
+type rpcSubmitter struct {
+	method string
+	client *rpc.Client
+}
+
+// newRPCSubmitter dials the alternative endpoint of cfg, the requests are signed with priv if cfg.SignRequests is set.
+func newRPCSubmitter(cfg *config.SubmitterConfig, priv *ecdsa.PrivateKey) (*rpcSubmitter, error) {
+	var client *rpc.Client
+	var err error
+	if cfg.SignRequests {
+		// The signature covers the body of each request, so it is added by the transport rather than as a fixed header.
+		httpClient := &http.Client{Transport: &flashbotsTransport{base: http.DefaultTransport, key: priv}}
+		client, err = rpc.DialHTTPWithClient(cfg.Endpoint, httpClient)
+	} else {
+		client, err = rpc.Dial(cfg.Endpoint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial submitter endpoint, err: %w", err)
+	}
+	for key, value := range cfg.Headers {
+		client.SetHeader(key, value)
+	}
+
+	method := sendRawTransactionMethod
+	if cfg.Method != "" {
+		method = cfg.Method
+	}
+	return &rpcSubmitter{method: method, client: client}, nil
+}
+
+// SendTransaction submits a signed transaction to the alternative endpoint.
+func (r *rpcSubmitter) SendTransaction(ctx context.Context, tx *gethTypes.Transaction) error {
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode tx, err: %w", err)
+	}
+	if r.method == sendPrivateTransactionMethod {
+		return r.client.CallContext(ctx, nil, r.method, map[string]interface{}{"tx": hexutil.Encode(rawTx)})
+	}
+	return r.client.CallContext(ctx, nil, r.method, hexutil.Encode(rawTx))
+}
+
+// fallbackSubmitter submits transactions through a primary submitter, and through a fallback one if the primary fails.
+type fallbackSubmitter struct {
+	primary  txSubmitter
+	fallback txSubmitter // nil if transactions are not resubmitted when the primary fails
+
+	sentTotal     prometheus.Counter
+	failureTotal  prometheus.Counter
+	fallbackTotal prometheus.Counter
+}
+
+// SendTransaction submits a signed transaction through the primary submitter, falling back if allowed.
+func (f *fallbackSubmitter) SendTransaction(ctx context.Context, tx *gethTypes.Transaction) error {
+	err := f.primary.SendTransaction(ctx, tx)
+	if err == nil {
+		f.sentTotal.Inc()
+		return nil
+	}
+	f.failureTotal.Inc()
+	if f.fallback == nil {
+		return err
+	}
+
+	log.Warn("failed to send tx through submitter, fallback to the public endpoint", "tx hash", tx.Hash().String(), "nonce", tx.Nonce(), "err", err)
+	f.fallbackTotal.Inc()
+	return f.fallback.SendTransaction(ctx, tx)
+}
+
+// retrySubmitter submits transactions through a submitter, retrying its transient failures with exponential backoff
+// and jitter, so that a timeout or an overloaded endpoint does not fail the transaction.
+type retrySubmitter struct {
+	submitter txSubmitter
+	policy    retry.Policy
+}
+
+// newRetrySubmitter wraps submitter with the retries of cfg, nil for the defaults, counting them in retryTotal.
+func newRetrySubmitter(submitter txSubmitter, cfg *config.SendRetryConfig, retryTotal prometheus.Counter) *retrySubmitter {
+	policy := retry.Policy{
+		InitialBackoff: defaultSendRetryInitialBackoff,
+		Jitter:         0.2,
+		MaxAttempts:    defaultSendRetryMaxAttempts,
+		Budget:         defaultSendRetryBudget,
+		Retryable:      isTransientRPCError,
+	}
+	if cfg != nil {
+		if cfg.MaxAttempts > 0 {
+			policy.MaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.InitialBackoffMs > 0 {
+			policy.InitialBackoff = time.Duration(cfg.InitialBackoffMs) * time.Millisecond
+		}
+		if cfg.BudgetMs > 0 {
+			policy.Budget = time.Duration(cfg.BudgetMs) * time.Millisecond
+		}
+	}
+	policy.OnRetry = func(attempt int, backoff time.Duration, err error) {
+		retryTotal.Inc()
+		log.Warn("failed to send tx, retrying", "attempt", attempt, "backoff", backoff, "err", err)
+	}
+	return &retrySubmitter{submitter: submitter, policy: policy}
+}
+
+// SendTransaction submits a signed transaction, retrying the transient failures.
+func (r *retrySubmitter) SendTransaction(ctx context.Context, tx *gethTypes.Transaction) error {
+	attempts := 0
+	return retry.Do(ctx, r.policy, func(ctx context.Context) error {
+		attempts++
+		err := r.submitter.SendTransaction(ctx, tx)
+		// An attempt which timed out may still have reached the node, which then already knows the transaction.
+		if err != nil && attempts > 1 && strings.Contains(err.Error(), "already known") {
+			log.Info("tx already known after retrying", "tx hash", tx.Hash().String(), "nonce", tx.Nonce())
+			return nil
+		}
+		return err
+	})
+}
+
+// isTransientRPCError returns whether an RPC failed because of the endpoint or the network rather than the request,
+// in which case the same request may succeed later.
+func isTransientRPCError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// flashbotsTransport signs the body of every request in the X-Flashbots-Signature header, as Flashbots requires:
+// the header holds the signer address and its EIP-191 signature of the hex encoded keccak256 hash of the body.
+type flashbotsTransport struct {
+	base http.RoundTripper
+	key  *ecdsa.PrivateKey
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *flashbotsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body, err: %w", err)
+		}
+	}
+	signature, err := t.sign(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	signedReq := req.Clone(req.Context())
+	signedReq.Body = io.NopCloser(bytes.NewReader(body))
+	signedReq.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	signedReq.Header.Set(flashbotsSignatureHeader, signature)
+	return t.base.RoundTrip(signedReq)
+}
+
+func (t *flashbotsTransport) sign(body []byte) (string, error) {
+	hash := crypto.Keccak256Hash(body).Hex()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), t.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request body, err: %w", err)
+	}
+	return crypto.PubkeyToAddress(t.key.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
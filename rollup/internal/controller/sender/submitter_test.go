@@ -0,0 +1,167 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/accounts"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+type mockSubmitter struct {
+	err   error
+	calls int
+}
+
+func (m *mockSubmitter) SendTransaction(_ context.Context, _ *gethTypes.Transaction) error {
+	m.calls++
+	return m.err
+}
+
+func newTestFallbackSubmitter(primary, fallback txSubmitter) *fallbackSubmitter {
+	return &fallbackSubmitter{
+		primary:       primary,
+		fallback:      fallback,
+		sentTotal:     prometheus.NewCounter(prometheus.CounterOpts{Name: "sent"}),
+		failureTotal:  prometheus.NewCounter(prometheus.CounterOpts{Name: "failure"}),
+		fallbackTotal: prometheus.NewCounter(prometheus.CounterOpts{Name: "fallback"}),
+	}
+}
+
+func TestFallbackSubmitter(t *testing.T) {
+	tx := gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+
+	primary, public := &mockSubmitter{}, &mockSubmitter{}
+	submitter := newTestFallbackSubmitter(primary, public)
+	assert.NoError(t, submitter.SendTransaction(context.Background(), tx))
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 0, public.calls)
+	assert.Equal(t, float64(1), testutil.ToFloat64(submitter.sentTotal))
+
+	// the public endpoint is used once the primary fails
+	primary.err = errors.New("private rpc unavailable")
+	assert.NoError(t, submitter.SendTransaction(context.Background(), tx))
+	assert.Equal(t, 1, public.calls)
+	assert.Equal(t, float64(1), testutil.ToFloat64(submitter.failureTotal))
+	assert.Equal(t, float64(1), testutil.ToFloat64(submitter.fallbackTotal))
+
+	public.err = errors.New("public rpc unavailable")
+	assert.EqualError(t, submitter.SendTransaction(context.Background(), tx), "public rpc unavailable")
+
+	// without fallback the error of the primary is returned
+	submitter = newTestFallbackSubmitter(primary, nil)
+	assert.EqualError(t, submitter.SendTransaction(context.Background(), tx), "private rpc unavailable")
+	assert.Equal(t, float64(0), testutil.ToFloat64(submitter.fallbackTotal))
+}
+
+// sequenceSubmitter returns the errors of errs in turn, then succeeds.
+type sequenceSubmitter struct {
+	errs  []error
+	calls int
+}
+
+func (m *sequenceSubmitter) SendTransaction(_ context.Context, _ *gethTypes.Transaction) error {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return m.errs[m.calls-1]
+	}
+	return nil
+}
+
+func TestRetrySubmitter(t *testing.T) {
+	tx := gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	cfg := &config.SendRetryConfig{MaxAttempts: 3, InitialBackoffMs: 1}
+	unavailable := rpc.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+
+	// transient failures are retried
+	inner := &sequenceSubmitter{errs: []error{unavailable}}
+	retryTotal := prometheus.NewCounter(prometheus.CounterOpts{Name: "retry"})
+	assert.NoError(t, newRetrySubmitter(inner, cfg, retryTotal).SendTransaction(context.Background(), tx))
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, float64(1), testutil.ToFloat64(retryTotal))
+
+	// up to the max attempts
+	inner = &sequenceSubmitter{errs: []error{unavailable, unavailable, unavailable}}
+	assert.Error(t, newRetrySubmitter(inner, cfg, retryTotal).SendTransaction(context.Background(), tx))
+	assert.Equal(t, 3, inner.calls)
+
+	// a rejected transaction is not retried
+	inner = &sequenceSubmitter{errs: []error{errors.New("nonce too low")}}
+	assert.EqualError(t, newRetrySubmitter(inner, cfg, retryTotal).SendTransaction(context.Background(), tx), "nonce too low")
+	assert.Equal(t, 1, inner.calls)
+
+	// the node knowing the transaction after a failed attempt means it was sent
+	inner = &sequenceSubmitter{errs: []error{io.ErrUnexpectedEOF, errors.New("already known")}}
+	assert.NoError(t, newRetrySubmitter(inner, cfg, retryTotal).SendTransaction(context.Background(), tx))
+	assert.Equal(t, 2, inner.calls)
+
+	inner = &sequenceSubmitter{errs: []error{errors.New("already known")}}
+	assert.EqualError(t, newRetrySubmitter(inner, cfg, retryTotal).SendTransaction(context.Background(), tx), "already known")
+}
+
+func TestRPCSubmitterSignsRequests(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	var bodies [][]byte
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, readErr := io.ReadAll(r.Body)
+		assert.NoError(t, readErr)
+		mu.Lock()
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(flashbotsSignatureHeader))
+		mu.Unlock()
+
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, sendPrivateTransactionMethod, req.Method)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"0x01"}`))
+	}))
+	defer server.Close()
+
+	submitter, err := newRPCSubmitter(&config.SubmitterConfig{Endpoint: server.URL, Method: sendPrivateTransactionMethod, SignRequests: true}, key)
+	assert.NoError(t, err)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, signErr := gethTypes.SignTx(gethTypes.NewTx(&gethTypes.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1), Gas: 21000}), gethTypes.HomesteadSigner{}, key)
+		assert.NoError(t, signErr)
+		assert.NoError(t, submitter.SendTransaction(context.Background(), tx))
+	}
+
+	// each request carries the signature of its own body
+	assert.Len(t, bodies, 2)
+	assert.NotEqual(t, signatures[0], signatures[1])
+	for i, body := range bodies {
+		parts := strings.SplitN(signatures[i], ":", 2)
+		assert.Len(t, parts, 2)
+		assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), parts[0])
+
+		sig, decodeErr := hexutil.Decode(parts[1])
+		assert.NoError(t, decodeErr)
+		pub, recoverErr := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), sig)
+		assert.NoError(t, recoverErr)
+		assert.Equal(t, common.HexToAddress(parts[0]), crypto.PubkeyToAddress(*pub))
+	}
+}
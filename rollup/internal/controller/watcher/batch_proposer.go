@@ -133,6 +133,15 @@ func (p *BatchProposer) TryProposeBatch() {
 
 func (p *BatchProposer) updateDBBatchInfo(batch *encoding.Batch, codecVersion encoding.CodecVersion) error {
 	err := p.db.Transaction(func(dbTX *gorm.DB) error {
+		// lock the parent batch, the relayer replaces uncommitted batches when splitting an oversized one.
+		parentBatches, dbErr := p.batchOrm.GetBatchesGEIndexForUpdate(p.ctx, batch.Index-1, dbTX)
+		if dbErr != nil {
+			return dbErr
+		}
+		if len(parentBatches) != 1 || parentBatches[0].Hash != batch.ParentBatchHash.Hex() {
+			return fmt.Errorf("parent batch %v changed while proposing batch, parent hash: %v", batch.Index-1, batch.ParentBatchHash.Hex())
+		}
+
 		batch, dbErr := p.batchOrm.InsertBatch(p.ctx, batch, codecVersion, dbTX)
 		if dbErr != nil {
 			log.Warn("BatchProposer.updateBatchInfoInDB insert batch failure",
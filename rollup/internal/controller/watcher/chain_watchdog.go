@@ -0,0 +1,197 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+const (
+	defaultWatchdogCheckInterval = 10 * time.Second
+	defaultWatchdogResumeAfter   = 60 * time.Second
+)
+
+// Chain watchdog checks, the check label of the anomaly metrics.
+const (
+	watchdogCheckL1HeadStall      = "l1_head_stall"
+	watchdogCheckL2HeadStall      = "l2_head_stall"
+	watchdogCheckL1TimestampDrift = "l1_timestamp_drift"
+	watchdogCheckL2TimestampDrift = "l2_timestamp_drift"
+)
+
+type headerClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// watchedChain is the progress of the head of a chain observed by the watchdog.
+type watchedChain struct {
+	name       string
+	client     headerClient
+	maxStall   time.Duration
+	maxDrift   time.Duration
+	stallCheck string
+	driftCheck string
+
+	head       uint64
+	advancedAt time.Time // the last time the head advanced, or the watchdog started
+}
+
+// ChainWatchdog pauses the relayers while the L1 or L2 head observed through their nodes does not progress as
+// expected, i.e. it stalls or its timestamp drifts from the wall clock, so that no transaction is sent on the view
+// of a sick node. The relayers are resumed once the checks pass again for a while. It is safe for concurrent use.
+type ChainWatchdog struct {
+	cfg         *config.ChainWatchdogConfig
+	chains      []*watchedChain
+	resumeAfter time.Duration
+	now         func() time.Time
+
+	mu           sync.RWMutex
+	paused       bool
+	healthySince time.Time
+
+	metrics *chainWatchdogMetrics
+}
+
+// NewChainWatchdog returns a watchdog of the heads of the L1 and L2 nodes, a nil client leaves its chain unchecked.
+func NewChainWatchdog(cfg *config.ChainWatchdogConfig, l1Client, l2Client headerClient, reg prometheus.Registerer) *ChainWatchdog {
+	w := &ChainWatchdog{
+		cfg:         cfg,
+		resumeAfter: defaultWatchdogResumeAfter,
+		now:         time.Now,
+		metrics:     initChainWatchdogMetrics(reg),
+	}
+	if cfg.ResumeAfterSec > 0 {
+		w.resumeAfter = time.Duration(cfg.ResumeAfterSec) * time.Second
+	}
+	if l1Client != nil {
+		w.chains = append(w.chains, &watchedChain{
+			name:       "l1",
+			client:     l1Client,
+			maxStall:   time.Duration(cfg.MaxL1HeadStallSec) * time.Second,
+			maxDrift:   time.Duration(cfg.MaxL1TimestampDriftSec) * time.Second,
+			stallCheck: watchdogCheckL1HeadStall,
+			driftCheck: watchdogCheckL1TimestampDrift,
+		})
+	}
+	if l2Client != nil {
+		w.chains = append(w.chains, &watchedChain{
+			name:       "l2",
+			client:     l2Client,
+			maxStall:   time.Duration(cfg.MaxL2HeadStallSec) * time.Second,
+			maxDrift:   time.Duration(cfg.MaxL2TimestampDriftSec) * time.Second,
+			stallCheck: watchdogCheckL2HeadStall,
+			driftCheck: watchdogCheckL2TimestampDrift,
+		})
+	}
+	now := w.now()
+	for _, chain := range w.chains {
+		chain.advancedAt = now
+	}
+	return w
+}
+
+// CheckInterval returns the interval the checks should run at.
+func (w *ChainWatchdog) CheckInterval() time.Duration {
+	if w.cfg.CheckIntervalSec > 0 {
+		return time.Duration(w.cfg.CheckIntervalSec) * time.Second
+	}
+	return defaultWatchdogCheckInterval
+}
+
+// Check fetches the heads of the chains, and pauses the relayers if a check fails, or resumes them if the checks
+// passed for the resume time.
+func (w *ChainWatchdog) Check(ctx context.Context) {
+	var anomalies []string
+	for _, chain := range w.chains {
+		anomalies = append(anomalies, w.checkChain(ctx, chain)...)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	if len(anomalies) > 0 {
+		w.healthySince = time.Time{}
+		if !w.paused {
+			w.paused = true
+			w.metrics.chainWatchdogPaused.Set(1)
+			log.Error("CRITICAL: chain watchdog pauses the relayers, check the L1 and L2 nodes", "anomalies", anomalies)
+		}
+		return
+	}
+	if !w.paused {
+		return
+	}
+	if w.healthySince.IsZero() {
+		w.healthySince = now
+	}
+	if now.Sub(w.healthySince) >= w.resumeAfter {
+		w.paused = false
+		w.healthySince = time.Time{}
+		w.metrics.chainWatchdogPaused.Set(0)
+		log.Info("chain watchdog resumes the relayers, the L1 and L2 heads progress again")
+	}
+}
+
+// checkChain fetches the head of the chain, and returns the failed checks of its progress.
+func (w *ChainWatchdog) checkChain(ctx context.Context, chain *watchedChain) []string {
+	var anomalies []string
+	now := w.now()
+	header, err := chain.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		// the head does not advance while the node is unreachable, it is reported as a stall.
+		log.Warn("chain watchdog failed to get the head", "chain", chain.name, "err", err)
+	} else if header.Number.Uint64() > chain.head {
+		chain.head = header.Number.Uint64()
+		chain.advancedAt = now
+	}
+
+	stall := now.Sub(chain.advancedAt)
+	w.metrics.chainWatchdogHeadStallSeconds.WithLabelValues(chain.name).Set(stall.Seconds())
+	if chain.maxStall > 0 && stall > chain.maxStall {
+		w.metrics.chainWatchdogAnomalyTotal.WithLabelValues(chain.stallCheck).Inc()
+		anomalies = append(anomalies, fmt.Sprintf("%s head stalled at block %d for %s", chain.name, chain.head, stall.Round(time.Second)))
+	}
+
+	if header == nil {
+		return anomalies
+	}
+	drift := now.Sub(time.Unix(int64(header.Time), 0))
+	w.metrics.chainWatchdogTimestampDriftSeconds.WithLabelValues(chain.name).Set(drift.Seconds())
+	if drift < 0 {
+		drift = -drift
+	}
+	if chain.maxDrift > 0 && drift > chain.maxDrift {
+		w.metrics.chainWatchdogAnomalyTotal.WithLabelValues(chain.driftCheck).Inc()
+		anomalies = append(anomalies, fmt.Sprintf("%s head timestamp of block %v drifts %s from the wall clock", chain.name, header.Number, drift.Round(time.Second)))
+	}
+	return anomalies
+}
+
+// Paused returns whether the relayers are paused, a nil watchdog never pauses them.
+func (w *ChainWatchdog) Paused() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.paused
+}
+
+// Gate returns a function running fn only while the relayers are not paused, e.g. to pause a loop of utils.Loop.
+func (w *ChainWatchdog) Gate(fn func()) func() {
+	return func() {
+		if w.Paused() {
+			log.Debug("chain watchdog paused the relayers, skip")
+			return
+		}
+		fn()
+	}
+}
@@ -0,0 +1,44 @@
+package watcher
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type chainWatchdogMetrics struct {
+	chainWatchdogPaused                prometheus.Gauge
+	chainWatchdogAnomalyTotal          *prometheus.CounterVec
+	chainWatchdogHeadStallSeconds      *prometheus.GaugeVec
+	chainWatchdogTimestampDriftSeconds *prometheus.GaugeVec
+}
+
+var (
+	initChainWatchdogMetricOnce sync.Once
+	chainWatchdogMetric         *chainWatchdogMetrics
+)
+
+func initChainWatchdogMetrics(reg prometheus.Registerer) *chainWatchdogMetrics {
+	initChainWatchdogMetricOnce.Do(func() {
+		chainWatchdogMetric = &chainWatchdogMetrics{
+			chainWatchdogPaused: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_chain_watchdog_paused",
+				Help: "Whether the chain watchdog paused the relayers (1) or not (0).",
+			}),
+			chainWatchdogAnomalyTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_chain_watchdog_anomaly_total",
+				Help: "The total number of failed chain watchdog checks by check.",
+			}, []string{"check"}),
+			chainWatchdogHeadStallSeconds: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_chain_watchdog_head_stall_seconds",
+				Help: "The time since the head of the chain last advanced.",
+			}, []string{"chain"}),
+			chainWatchdogTimestampDriftSeconds: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_chain_watchdog_timestamp_drift_seconds",
+				Help: "The wall clock minus the timestamp of the head of the chain.",
+			}, []string{"chain"}),
+		}
+	})
+	return chainWatchdogMetric
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// mockHeadClient serves a head which is advanced by the test.
+type mockHeadClient struct {
+	mu     sync.Mutex
+	number uint64
+	time   time.Time
+	err    error
+}
+
+func (c *mockHeadClient) set(number uint64, timestamp time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.number, c.time, c.err = number, timestamp, err
+}
+
+func (c *mockHeadClient) HeaderByNumber(_ context.Context, _ *big.Int) (*gethTypes.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &gethTypes.Header{Number: new(big.Int).SetUint64(c.number), Time: uint64(c.time.Unix())}, nil
+}
+
+func TestChainWatchdog(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l1, l2 := &mockHeadClient{}, &mockHeadClient{}
+	l1.set(100, now, nil)
+	l2.set(1000, now, nil)
+
+	cfg := &config.ChainWatchdogConfig{
+		Enabled:                true,
+		MaxL1HeadStallSec:      120,
+		MaxL2HeadStallSec:      60,
+		MaxL1TimestampDriftSec: 300,
+		MaxL2TimestampDriftSec: 300,
+		ResumeAfterSec:         30,
+	}
+	w := NewChainWatchdog(cfg, l1, l2, prometheus.NewRegistry())
+	w.now = func() time.Time { return now }
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		w.now = func() time.Time { return now }
+	}
+
+	var runs int
+	gated := w.Gate(func() { runs++ })
+	w.Check(context.Background())
+	gated()
+	assert.False(t, w.Paused())
+	assert.Equal(t, 1, runs)
+
+	// the l2 head stalls, the relayers are paused.
+	advance(50 * time.Second)
+	l1.set(104, now, nil)
+	w.Check(context.Background())
+	assert.False(t, w.Paused())
+	advance(20 * time.Second)
+	l1.set(106, now, nil)
+	w.Check(context.Background())
+	assert.True(t, w.Paused())
+	gated()
+	assert.Equal(t, 1, runs)
+
+	// the relayers are resumed once the checks passed for the resume time.
+	l2.set(1030, now, nil)
+	w.Check(context.Background())
+	assert.True(t, w.Paused())
+	advance(20 * time.Second)
+	l2.set(1040, now, nil)
+	w.Check(context.Background())
+	assert.True(t, w.Paused())
+	advance(10 * time.Second)
+	l2.set(1045, now, nil)
+	w.Check(context.Background())
+	assert.False(t, w.Paused())
+	gated()
+	assert.Equal(t, 2, runs)
+
+	// the l1 node serves an old head, e.g. while it is syncing.
+	l1.set(107, now.Add(-10*time.Minute), nil)
+	w.Check(context.Background())
+	assert.True(t, w.Paused())
+	l1.set(200, now, nil)
+	advance(30 * time.Second)
+	l2.set(1055, now, nil)
+	w.Check(context.Background())
+	advance(30 * time.Second)
+	l1.set(210, now, nil)
+	l2.set(1065, now, nil)
+	w.Check(context.Background())
+	assert.False(t, w.Paused())
+
+	// an unreachable node is a stall.
+	l2.set(0, time.Time{}, errors.New("connection refused"))
+	advance(61 * time.Second)
+	l1.set(215, now, nil)
+	w.Check(context.Background())
+	assert.True(t, w.Paused())
+}
+
+func TestChainWatchdogDisabledChecks(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l1 := &mockHeadClient{}
+	l1.set(100, now.Add(-time.Hour), nil)
+
+	// the checks with a threshold of 0 are disabled, and the chains without client are not checked.
+	w := NewChainWatchdog(&config.ChainWatchdogConfig{Enabled: true, MaxL1HeadStallSec: 60}, l1, nil, prometheus.NewRegistry())
+	w.now = func() time.Time { return now }
+	w.Check(context.Background())
+	assert.False(t, w.Paused())
+	assert.Equal(t, defaultWatchdogCheckInterval, w.CheckInterval())
+}
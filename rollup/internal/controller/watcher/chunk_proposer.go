@@ -56,7 +56,8 @@ type ChunkProposer struct {
 
 // NewChunkProposer creates a new ChunkProposer instance.
 func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, chainCfg *params.ChainConfig, db *gorm.DB, reg prometheus.Registerer) *ChunkProposer {
-	forkHeights, _, _ := forks.CollectSortedForkHeights(chainCfg)
+	_, _, nameForkMap := forks.CollectSortedForkHeights(chainCfg)
+	forkHeights, _ := forks.OverrideForkHeights(nameForkMap, cfg.ForkHeights)
 	log.Debug("new chunk proposer",
 		"maxTxNumPerChunk", cfg.MaxTxNumPerChunk,
 		"maxL1CommitGasPerChunk", cfg.MaxL1CommitGasPerChunk,
@@ -199,6 +200,18 @@ func (p *ChunkProposer) proposeChunk() error {
 
 	var chunk encoding.Chunk
 	for i, block := range blocks {
+		// a chunk never mixes the blocks of two hard forks, the prover circuits differ.
+		if i > 0 && forks.SpansForkBoundary(blocks[0].Header.Number.Uint64(), block.Header.Number.Uint64(), p.forkHeights) {
+			log.Info("reached hard fork boundary in chunk", "start block number", blocks[0].Header.Number, "fork block number", block.Header.Number)
+
+			metrics, calcErr := utils.CalculateChunkMetrics(&chunk, codecVersion)
+			if calcErr != nil {
+				return fmt.Errorf("failed to calculate chunk metrics: %w", calcErr)
+			}
+			p.recordChunkMetrics(metrics)
+			return p.updateDBChunkInfo(&chunk, codecVersion)
+		}
+
 		chunk.Blocks = append(chunk.Blocks, block)
 
 		metrics, calcErr := utils.CalculateChunkMetrics(&chunk, codecVersion)
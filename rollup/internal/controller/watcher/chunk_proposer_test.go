@@ -322,6 +322,59 @@ func testChunkProposerCodecv1Limits(t *testing.T) {
 	}
 }
 
+func testChunkProposerForkBoundary(t *testing.T) {
+	tests := []struct {
+		name        string
+		chainCfg    *params.ChainConfig
+		forkHeights map[string]uint64
+	}{
+		{
+			name:     "ChainConfigFork",
+			chainCfg: &params.ChainConfig{HomesteadBlock: big.NewInt(3)},
+		},
+		{
+			name:        "OverriddenFork",
+			chainCfg:    &params.ChainConfig{},
+			forkHeights: map[string]uint64{"curie": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupDB(t)
+			defer database.CloseDB(db)
+
+			// block1 and block2 are blocks 2 and 3, the first unchunked height is 1, so both blocks are selected
+			// although the fork activates at block 3.
+			l2BlockOrm := orm.NewL2Block(db)
+			err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+			assert.NoError(t, err)
+
+			cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+				MaxBlockNumPerChunk:             100,
+				MaxTxNumPerChunk:                10000,
+				MaxL1CommitGasPerChunk:          50000000000,
+				MaxL1CommitCalldataSizePerChunk: 1000000,
+				MaxRowConsumptionPerChunk:       1000000,
+				ChunkTimeoutSec:                 0,
+				GasCostIncreaseMultiplier:       1.2,
+				ForkHeights:                     tt.forkHeights,
+			}, tt.chainCfg, db, nil)
+			cp.TryProposeChunk()
+			cp.TryProposeChunk()
+
+			chunkOrm := orm.NewChunk(db)
+			chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+			assert.NoError(t, err)
+			assert.Len(t, chunks, 2)
+			assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+			assert.Equal(t, uint64(2), chunks[0].EndBlockNumber)
+			assert.Equal(t, uint64(3), chunks[1].StartBlockNumber)
+			assert.Equal(t, uint64(3), chunks[1].EndBlockNumber)
+		})
+	}
+}
+
 func testChunkProposerCodecv1BlobSizeLimit(t *testing.T) {
 	db := setupDB(t)
 	defer database.CloseDB(db)
@@ -1,3 +1,3 @@
 package watcher
 
-const contractEventsBlocksFetchLimit = int64(10)
+const contractEventsBlocksFetchLimit = uint64(10)
@@ -7,18 +7,18 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	geth "github.com/scroll-tech/go-ethereum"
-	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
-	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/eventwatcher"
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/crossdomain"
+	"scroll-tech/common/types/events"
 
-	bridgeAbi "scroll-tech/rollup/abi"
 	"scroll-tech/rollup/internal/orm"
 	"scroll-tech/rollup/internal/utils"
 )
@@ -29,6 +29,19 @@ type rollupEvent struct {
 	status    types.RollupStatus
 }
 
+// l1Events are the events of a range of L1 blocks.
+type l1Events struct {
+	sentMessages        []*orm.L1Message
+	rollupEvents        []rollupEvent
+	skippedQueueIndices []uint64
+	// hasLogs is set if the range has event logs, nothing is saved otherwise.
+	hasLogs bool
+}
+
+// errRollupStatusMismatch stops saving the L1 events without error when some batches of the rollup events are unknown,
+// the range is fetched again by the next FetchContractEvent.
+var errRollupStatusMismatch = errors.New("rollup status mismatch with batch hashes")
+
 // L1WatcherClient will listen for smart contract events from Eth L1.
 type L1WatcherClient struct {
 	ctx          context.Context
@@ -41,13 +54,10 @@ type L1WatcherClient struct {
 	confirmations rpc.BlockNumber
 
 	messageQueueAddress common.Address
-	messageQueueABI     *abi.ABI
-
-	scrollChainAddress common.Address
-	scrollChainABI     *abi.ABI
+	scrollChainAddress  common.Address
 
-	// The height of the block that the watcher has retrieved event logs
-	processedMsgHeight uint64
+	// eventWatcher retrieves the event logs, its cursor is the height of the block up to which they are retrieved
+	eventWatcher *eventwatcher.Watcher[*l1Events]
 	// The height of the block that the watcher has retrieved header rlp
 	processedBlockHeight uint64
 
@@ -76,7 +86,7 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 		savedL1BlockHeight = startHeight
 	}
 
-	return &L1WatcherClient{
+	w := &L1WatcherClient{
 		ctx:           ctx,
 		client:        client,
 		l1MessageOrm:  l1MessageOrm,
@@ -85,15 +95,17 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 		confirmations: confirmations,
 
 		messageQueueAddress: messageQueueAddress,
-		messageQueueABI:     bridgeAbi.L1MessageQueueABI,
+		scrollChainAddress:  scrollChainAddress,
 
-		scrollChainAddress: scrollChainAddress,
-		scrollChainABI:     bridgeAbi.ScrollChainABI,
-
-		processedMsgHeight:   uint64(savedHeight),
 		processedBlockHeight: savedL1BlockHeight,
 		metrics:              initL1WatcherMetrics(reg),
 	}
+	events := &l1ContractEvents{w: w}
+	w.eventWatcher = eventwatcher.New[*l1Events](events, events, contractEventsBlocksFetchLimit, eventwatcher.Cursor{Height: uint64(savedHeight)})
+	w.eventWatcher.OnAdvance(func(cursor eventwatcher.Cursor, _ bool) {
+		w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(cursor.Height))
+	})
+	return w
 }
 
 // ProcessedBlockHeight get processedBlockHeight
@@ -157,7 +169,7 @@ func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
 // FetchContractEvent pull latest event logs from given contract address and save in DB
 func (w *L1WatcherClient) FetchContractEvent() error {
 	defer func() {
-		log.Info("l1 watcher fetchContractEvent", "w.processedMsgHeight", w.processedMsgHeight)
+		log.Info("l1 watcher fetchContractEvent", "w.processedMsgHeight", w.eventWatcher.Cursor().Height)
 	}()
 	blockHeight, err := utils.GetLatestConfirmedBlockNumber(w.ctx, w.client, w.confirmations)
 	if err != nil {
@@ -165,97 +177,121 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 		return err
 	}
 
-	fromBlock := int64(w.processedMsgHeight) + 1
-	toBlock := int64(blockHeight)
+	err = w.eventWatcher.Sync(w.ctx, blockHeight)
+	if errors.Is(err, errRollupStatusMismatch) {
+		return nil
+	}
+	return err
+}
 
-	for from := fromBlock; from <= toBlock; from += contractEventsBlocksFetchLimit {
-		w.metrics.l1WatcherFetchContractEventTotal.Inc()
-		to := from + contractEventsBlocksFetchLimit - 1
+// l1ContractEvents decodes and saves the events of the L1 message queue and the rollup contracts.
+type l1ContractEvents struct {
+	w *L1WatcherClient
+}
 
-		if to > toBlock {
-			to = toBlock
-		}
+func (e *l1ContractEvents) Decode(ctx context.Context, r eventwatcher.Range, _ eventwatcher.Cursor) (*eventwatcher.Decoded[*l1Events], error) {
+	w := e.w
+	w.metrics.l1WatcherFetchContractEventTotal.Inc()
+
+	query := geth.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(r.From), // inclusive
+		ToBlock:   new(big.Int).SetUint64(r.To),   // inclusive
+		Addresses: []common.Address{
+			w.scrollChainAddress,
+			w.messageQueueAddress,
+		},
+		Topics: make([][]common.Hash, 1),
+	}
+	query.Topics[0] = make([]common.Hash, 4)
+	query.Topics[0][0] = events.L1MessageQueueQueueTransactionEventSig
+	query.Topics[0][1] = events.ScrollChainCommitBatchEventSig
+	query.Topics[0][2] = events.ScrollChainFinalizeBatchEventSig
+	query.Topics[0][3] = events.L1MessageQueueDequeueTransactionEventSig
 
-		// warning: uint int conversion...
-		query := geth.FilterQuery{
-			FromBlock: big.NewInt(from), // inclusive
-			ToBlock:   big.NewInt(to),   // inclusive
-			Addresses: []common.Address{
-				w.scrollChainAddress,
-				w.messageQueueAddress,
-			},
-			Topics: make([][]common.Hash, 1),
-		}
-		query.Topics[0] = make([]common.Hash, 3)
-		query.Topics[0][0] = bridgeAbi.L1QueueTransactionEventSignature
-		query.Topics[0][1] = bridgeAbi.L1CommitBatchEventSignature
-		query.Topics[0][2] = bridgeAbi.L1FinalizeBatchEventSignature
+	logs, err := w.client.FilterLogs(ctx, query)
+	if err != nil {
+		log.Warn("Failed to get event logs", "err", err)
+		return nil, err
+	}
+	decoded := &eventwatcher.Decoded[*l1Events]{Events: &l1Events{}, Cursor: eventwatcher.Cursor{Height: r.To}}
+	if len(logs) == 0 {
+		return decoded, nil
+	}
 
-		logs, err := w.client.FilterLogs(w.ctx, query)
-		if err != nil {
-			log.Warn("Failed to get event logs", "err", err)
-			return err
-		}
-		if len(logs) == 0 {
-			w.processedMsgHeight = uint64(to)
-			w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(to))
-			continue
-		}
+	log.Info("Received new L1 events", "fromBlock", r.From, "toBlock", r.To, "cnt", len(logs))
 
-		log.Info("Received new L1 events", "fromBlock", from, "toBlock", to, "cnt", len(logs))
+	decoded.Events.sentMessages, decoded.Events.rollupEvents, err = w.parseBridgeEventLogs(logs)
+	if err != nil {
+		log.Error("Failed to parse emitted events log", "err", err)
+		return nil, err
+	}
+	// the skipped messages are queued before they are dequeued, so they are saved before being marked skipped.
+	decoded.Events.skippedQueueIndices, err = w.parseSkippedQueueIndices(logs)
+	if err != nil {
+		log.Error("Failed to parse skipped L1 messages", "err", err)
+		return nil, err
+	}
+	decoded.Events.hasLogs = true
+	return decoded, nil
+}
 
-		sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(logs)
-		if err != nil {
-			log.Error("Failed to parse emitted events log", "err", err)
-			return err
-		}
-		sentMessageCount := int64(len(sentMessageEvents))
-		rollupEventCount := int64(len(rollupEvents))
-		w.metrics.l1WatcherFetchContractEventSentEventsTotal.Add(float64(sentMessageCount))
-		w.metrics.l1WatcherFetchContractEventRollupEventsTotal.Add(float64(rollupEventCount))
-		log.Info("L1 events types", "SentMessageCount", sentMessageCount, "RollupEventCount", rollupEventCount)
-
-		// use rollup event to update rollup results db status
-		var batchHashes []string
-		for _, event := range rollupEvents {
-			batchHashes = append(batchHashes, event.batchHash.String())
-		}
-		statuses, err := w.batchOrm.GetRollupStatusByHashList(w.ctx, batchHashes)
-		if err != nil {
-			log.Error("Failed to GetRollupStatusByHashList", "err", err)
-			return err
-		}
-		if len(statuses) != len(batchHashes) {
-			log.Error("RollupStatus.Length mismatch with batchHashes.Length", "RollupStatus.Length", len(statuses), "batchHashes.Length", len(batchHashes))
-			return nil
-		}
+func (e *l1ContractEvents) Persist(ctx context.Context, _ eventwatcher.Range, events *l1Events) error {
+	w := e.w
+	if !events.hasLogs {
+		return nil
+	}
+
+	sentMessageCount := int64(len(events.sentMessages))
+	rollupEventCount := int64(len(events.rollupEvents))
+	w.metrics.l1WatcherFetchContractEventSentEventsTotal.Add(float64(sentMessageCount))
+	w.metrics.l1WatcherFetchContractEventRollupEventsTotal.Add(float64(rollupEventCount))
+	log.Info("L1 events types", "SentMessageCount", sentMessageCount, "RollupEventCount", rollupEventCount)
+
+	// use rollup event to update rollup results db status
+	var batchHashes []string
+	for _, event := range events.rollupEvents {
+		batchHashes = append(batchHashes, event.batchHash.String())
+	}
+	statuses, err := w.batchOrm.GetRollupStatusByHashList(ctx, batchHashes)
+	if err != nil {
+		log.Error("Failed to GetRollupStatusByHashList", "err", err)
+		return err
+	}
+	if len(statuses) != len(batchHashes) {
+		log.Error("RollupStatus.Length mismatch with batchHashes.Length", "RollupStatus.Length", len(statuses), "batchHashes.Length", len(batchHashes))
+		return errRollupStatusMismatch
+	}
 
-		for index, event := range rollupEvents {
-			batchHash := event.batchHash.String()
-			status := statuses[index]
-			// only update when db status is before event status
-			if event.status > status {
-				if event.status == types.RollupFinalized {
-					err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
-				} else if event.status == types.RollupCommitted {
-					err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
-				}
-				if err != nil {
-					log.Error("Failed to update Rollup/Finalize TxHash and Status", "err", err)
-					return err
-				}
+	for index, event := range events.rollupEvents {
+		batchHash := event.batchHash.String()
+		status := statuses[index]
+		// only update when db status is before event status
+		if event.status > status {
+			if event.status == types.RollupFinalized {
+				err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
+			} else if event.status == types.RollupCommitted {
+				err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
+			}
+			if err != nil {
+				log.Error("Failed to update Rollup/Finalize TxHash and Status", "err", err)
+				return err
 			}
 		}
+	}
 
-		if err = w.l1MessageOrm.SaveL1Messages(w.ctx, sentMessageEvents); err != nil {
-			return err
-		}
+	if err = w.l1MessageOrm.SaveL1Messages(ctx, events.sentMessages); err != nil {
+		return err
+	}
 
-		w.processedMsgHeight = uint64(to)
-		w.metrics.l1WatcherFetchContractEventSuccessTotal.Inc()
-		w.metrics.l1WatcherFetchContractEventProcessedBlockHeight.Set(float64(w.processedMsgHeight))
+	if err = w.l1MessageOrm.UpdateL1MessagesSkipped(ctx, events.skippedQueueIndices); err != nil {
+		return err
+	}
+	if len(events.skippedQueueIndices) > 0 {
+		w.metrics.l1WatcherFetchContractEventSkippedMessagesTotal.Add(float64(len(events.skippedQueueIndices)))
+		log.Warn("L1 messages skipped", "queueIndices", events.skippedQueueIndices)
 	}
 
+	w.metrics.l1WatcherFetchContractEventSuccessTotal.Inc()
 	return nil
 }
 
@@ -266,15 +302,14 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 	var rollupEvents []rollupEvent
 	for _, vLog := range logs {
 		switch vLog.Topics[0] {
-		case bridgeAbi.L1QueueTransactionEventSignature:
-			event := bridgeAbi.L1QueueTransactionEvent{}
-			err := utils.UnpackLog(w.messageQueueABI, &event, "QueueTransaction", vLog)
+		case events.L1MessageQueueQueueTransactionEventSig:
+			event, err := events.UnpackL1MessageQueueQueueTransactionEvent(vLog)
 			if err != nil {
 				log.Warn("Failed to unpack layer1 QueueTransaction event", "err", err)
 				return l1Messages, rollupEvents, err
 			}
 
-			msgHash := common.BytesToHash(crypto.Keccak256(event.Data))
+			msgHash := crossdomain.HashEncodedMessage(event.Data)
 
 			l1Messages = append(l1Messages, &orm.L1Message{
 				QueueIndex: event.QueueIndex,
@@ -287,9 +322,8 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				GasLimit:   event.GasLimit.Uint64(),
 				Layer1Hash: vLog.TxHash.Hex(),
 			})
-		case bridgeAbi.L1CommitBatchEventSignature:
-			event := bridgeAbi.L1CommitBatchEvent{}
-			err := utils.UnpackLog(w.scrollChainABI, &event, "CommitBatch", vLog)
+		case events.ScrollChainCommitBatchEventSig:
+			event, err := events.UnpackScrollChainCommitBatchEvent(vLog)
 			if err != nil {
 				log.Warn("Failed to unpack layer1 CommitBatch event", "err", err)
 				return l1Messages, rollupEvents, err
@@ -300,9 +334,8 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				txHash:    vLog.TxHash,
 				status:    types.RollupCommitted,
 			})
-		case bridgeAbi.L1FinalizeBatchEventSignature:
-			event := bridgeAbi.L1FinalizeBatchEvent{}
-			err := utils.UnpackLog(w.scrollChainABI, &event, "FinalizeBatch", vLog)
+		case events.ScrollChainFinalizeBatchEventSig:
+			event, err := events.UnpackScrollChainFinalizeBatchEvent(vLog)
 			if err != nil {
 				log.Warn("Failed to unpack layer1 FinalizeBatch event", "err", err)
 				return l1Messages, rollupEvents, err
@@ -313,6 +346,8 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				txHash:    vLog.TxHash,
 				status:    types.RollupFinalized,
 			})
+		case events.L1MessageQueueDequeueTransactionEventSig:
+			// handled by parseSkippedQueueIndices
 		default:
 			log.Error("Unknown event", "topic", vLog.Topics[0], "txHash", vLog.TxHash)
 		}
@@ -320,3 +355,25 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 
 	return l1Messages, rollupEvents, nil
 }
+
+// parseSkippedQueueIndices returns the queue indices of the L1 messages skipped according to DequeueTransaction events.
+func (w *L1WatcherClient) parseSkippedQueueIndices(logs []gethTypes.Log) ([]uint64, error) {
+	var skippedQueueIndices []uint64
+	for _, vLog := range logs {
+		if len(vLog.Topics) == 0 || vLog.Topics[0] != events.L1MessageQueueDequeueTransactionEventSig {
+			continue
+		}
+		event, err := events.UnpackL1MessageQueueDequeueTransactionEvent(vLog)
+		if err != nil {
+			log.Warn("Failed to unpack layer1 DequeueTransaction event", "err", err)
+			return nil, err
+		}
+		startIndex := event.StartIndex.Uint64()
+		for i := 0; i < int(event.Count.Uint64()); i++ {
+			if event.SkippedBitmap.Bit(i) == 1 {
+				skippedQueueIndices = append(skippedQueueIndices, startIndex+uint64(i))
+			}
+		}
+	}
+	return skippedQueueIndices, nil
+}
@@ -15,6 +15,7 @@ type l1WatcherMetrics struct {
 	l1WatcherFetchContractEventProcessedBlockHeight prometheus.Gauge
 	l1WatcherFetchContractEventSentEventsTotal      prometheus.Counter
 	l1WatcherFetchContractEventRollupEventsTotal    prometheus.Counter
+	l1WatcherFetchContractEventSkippedMessagesTotal prometheus.Counter
 }
 
 var (
@@ -53,6 +54,10 @@ func initL1WatcherMetrics(reg prometheus.Registerer) *l1WatcherMetrics {
 				Name: "rollup_l1_watcher_fetch_block_contract_event_rollup_event_total",
 				Help: "The current processed block height of l1 watcher fetch contract rollup event",
 			}),
+			l1WatcherFetchContractEventSkippedMessagesTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_fetch_block_contract_event_skipped_message_total",
+				Help: "The total number of l1 messages skipped by the sequencer",
+			}),
 		}
 	})
 	return l1WatcherMetric
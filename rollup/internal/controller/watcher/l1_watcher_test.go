@@ -8,7 +8,6 @@ import (
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/scroll-tech/go-ethereum"
-	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethclient"
@@ -19,10 +18,9 @@ import (
 
 	"scroll-tech/common/database"
 	commonTypes "scroll-tech/common/types"
+	"scroll-tech/common/types/events"
 
-	bridgeAbi "scroll-tech/rollup/abi"
 	"scroll-tech/rollup/internal/orm"
-	"scroll-tech/rollup/internal/utils"
 )
 
 func setupL1Watcher(t *testing.T) (*L1WatcherClient, *gorm.DB) {
@@ -264,16 +262,16 @@ func testParseBridgeEventLogsL1QueueTransactionEventSignature(t *testing.T) {
 
 	logs := []types.Log{
 		{
-			Topics:      []common.Hash{bridgeAbi.L1QueueTransactionEventSignature},
+			Topics:      []common.Hash{events.L1MessageQueueQueueTransactionEventSig},
 			BlockNumber: 100,
 			TxHash:      common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
 		},
 	}
 
 	convey.Convey("unpack QueueTransaction log failure", t, func() {
-		targetErr := errors.New("UnpackLog QueueTransaction failure")
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			return targetErr
+		targetErr := errors.New("unpack QueueTransaction failure")
+		patchGuard := gomonkey.ApplyFunc(events.UnpackL1MessageQueueQueueTransactionEvent, func(types.Log) (*events.L1MessageQueueQueueTransactionEvent, error) {
+			return nil, targetErr
 		})
 		defer patchGuard.Reset()
 
@@ -284,15 +282,15 @@ func testParseBridgeEventLogsL1QueueTransactionEventSignature(t *testing.T) {
 	})
 
 	convey.Convey("L1QueueTransactionEventSignature success", t, func() {
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			tmpOut := out.(*bridgeAbi.L1QueueTransactionEvent)
-			tmpOut.QueueIndex = 100
-			tmpOut.Data = []byte("test data")
-			tmpOut.Sender = common.HexToAddress("0xb4c11951957c6f8f642c4af61cd6b24640fec6dc7fc607ee8206a99e92410d30")
-			tmpOut.Value = big.NewInt(1000)
-			tmpOut.Target = common.HexToAddress("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")
-			tmpOut.GasLimit = big.NewInt(10)
-			return nil
+		patchGuard := gomonkey.ApplyFunc(events.UnpackL1MessageQueueQueueTransactionEvent, func(types.Log) (*events.L1MessageQueueQueueTransactionEvent, error) {
+			return &events.L1MessageQueueQueueTransactionEvent{
+				QueueIndex: 100,
+				Data:       []byte("test data"),
+				Sender:     common.HexToAddress("0xb4c11951957c6f8f642c4af61cd6b24640fec6dc7fc607ee8206a99e92410d30"),
+				Value:      big.NewInt(1000),
+				Target:     common.HexToAddress("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5"),
+				GasLimit:   big.NewInt(10),
+			}, nil
 		})
 		defer patchGuard.Reset()
 
@@ -309,16 +307,16 @@ func testParseBridgeEventLogsL1CommitBatchEventSignature(t *testing.T) {
 	defer database.CloseDB(db)
 	logs := []types.Log{
 		{
-			Topics:      []common.Hash{bridgeAbi.L1CommitBatchEventSignature},
+			Topics:      []common.Hash{events.ScrollChainCommitBatchEventSig},
 			BlockNumber: 100,
 			TxHash:      common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
 		},
 	}
 
 	convey.Convey("unpack CommitBatch log failure", t, func() {
-		targetErr := errors.New("UnpackLog CommitBatch failure")
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			return targetErr
+		targetErr := errors.New("unpack CommitBatch failure")
+		patchGuard := gomonkey.ApplyFunc(events.UnpackScrollChainCommitBatchEvent, func(types.Log) (*events.ScrollChainCommitBatchEvent, error) {
+			return nil, targetErr
 		})
 		defer patchGuard.Reset()
 
@@ -330,10 +328,10 @@ func testParseBridgeEventLogsL1CommitBatchEventSignature(t *testing.T) {
 
 	convey.Convey("L1CommitBatchEventSignature success", t, func() {
 		msgHash := common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			tmpOut := out.(*bridgeAbi.L1CommitBatchEvent)
-			tmpOut.BatchHash = msgHash
-			return nil
+		patchGuard := gomonkey.ApplyFunc(events.UnpackScrollChainCommitBatchEvent, func(types.Log) (*events.ScrollChainCommitBatchEvent, error) {
+			return &events.ScrollChainCommitBatchEvent{
+				BatchHash: msgHash,
+			}, nil
 		})
 		defer patchGuard.Reset()
 
@@ -351,16 +349,16 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 	defer database.CloseDB(db)
 	logs := []types.Log{
 		{
-			Topics:      []common.Hash{bridgeAbi.L1FinalizeBatchEventSignature},
+			Topics:      []common.Hash{events.ScrollChainFinalizeBatchEventSig},
 			BlockNumber: 100,
 			TxHash:      common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
 		},
 	}
 
 	convey.Convey("unpack FinalizeBatch log failure", t, func() {
-		targetErr := errors.New("UnpackLog FinalizeBatch failure")
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			return targetErr
+		targetErr := errors.New("unpack FinalizeBatch failure")
+		patchGuard := gomonkey.ApplyFunc(events.UnpackScrollChainFinalizeBatchEvent, func(types.Log) (*events.ScrollChainFinalizeBatchEvent, error) {
+			return nil, targetErr
 		})
 		defer patchGuard.Reset()
 
@@ -372,10 +370,10 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 
 	convey.Convey("L1FinalizeBatchEventSignature success", t, func() {
 		msgHash := common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")
-		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
-			tmpOut := out.(*bridgeAbi.L1FinalizeBatchEvent)
-			tmpOut.BatchHash = msgHash
-			return nil
+		patchGuard := gomonkey.ApplyFunc(events.UnpackScrollChainFinalizeBatchEvent, func(types.Log) (*events.ScrollChainFinalizeBatchEvent, error) {
+			return &events.ScrollChainFinalizeBatchEvent{
+				BatchHash: msgHash,
+			}, nil
 		})
 		defer patchGuard.Reset()
 
@@ -387,3 +385,47 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 		assert.Equal(t, rollupEvents[0].status, commonTypes.RollupFinalized)
 	})
 }
+
+func testParseSkippedQueueIndices(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	logs := []types.Log{
+		{
+			Topics:      []common.Hash{events.L1MessageQueueQueueTransactionEventSig},
+			BlockNumber: 100,
+		},
+		{
+			Topics:      []common.Hash{events.L1MessageQueueDequeueTransactionEventSig},
+			BlockNumber: 100,
+		},
+	}
+
+	convey.Convey("unpack DequeueTransaction log failure", t, func() {
+		targetErr := errors.New("unpack DequeueTransaction failure")
+		patchGuard := gomonkey.ApplyFunc(events.UnpackL1MessageQueueDequeueTransactionEvent, func(types.Log) (*events.L1MessageQueueDequeueTransactionEvent, error) {
+			return nil, targetErr
+		})
+		defer patchGuard.Reset()
+
+		skippedQueueIndices, err := watcher.parseSkippedQueueIndices(logs)
+		assert.EqualError(t, err, targetErr.Error())
+		assert.Empty(t, skippedQueueIndices)
+	})
+
+	convey.Convey("L1DequeueTransactionEventSignature success", t, func() {
+		patchGuard := gomonkey.ApplyFunc(events.UnpackL1MessageQueueDequeueTransactionEvent, func(types.Log) (*events.L1MessageQueueDequeueTransactionEvent, error) {
+			return &events.L1MessageQueueDequeueTransactionEvent{
+				StartIndex: big.NewInt(10),
+				Count:      big.NewInt(4),
+				// skip the 2nd and 4th messages, the bits beyond count are ignored
+				SkippedBitmap: big.NewInt(0b11010),
+			}, nil
+		})
+		defer patchGuard.Reset()
+
+		skippedQueueIndices, err := watcher.parseSkippedQueueIndices(logs)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{11, 13}, skippedQueueIndices)
+	})
+}
@@ -0,0 +1,201 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+const (
+	defaultL2BlockHeaderFetchLimit = uint64(100)
+
+	// maxL2BlockHeaderReorgDepth bounds the number of indexed blocks replaced after a reorg.
+	maxL2BlockHeaderReorgDepth = 64
+)
+
+// L2BlockHeaderIndexer indexes the fee related data of l2 block headers without gaps, so that fee suggestions and
+// analytics are computed from the database instead of repeated history calls to l2geth.
+type L2BlockHeaderIndexer struct {
+	ctx    context.Context
+	client *rpc.Client
+	cfg    *config.L2BlockHeaderIndexerConfig
+
+	l2BlockHeaderOrm *orm.L2BlockHeader
+
+	indexerRunTotal prometheus.Counter
+	indexedHeight   prometheus.Gauge
+	reorgTotal      prometheus.Counter
+}
+
+// NewL2BlockHeaderIndexer returns a new instance of L2BlockHeaderIndexer.
+func NewL2BlockHeaderIndexer(ctx context.Context, client *rpc.Client, cfg *config.L2BlockHeaderIndexerConfig, db *gorm.DB, reg prometheus.Registerer) *L2BlockHeaderIndexer {
+	return &L2BlockHeaderIndexer{
+		ctx:              ctx,
+		client:           client,
+		cfg:              cfg,
+		l2BlockHeaderOrm: orm.NewL2BlockHeader(db),
+
+		indexerRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_l2_block_header_indexer_run_total",
+			Help: "The total number of l2 block header indexer runs",
+		}),
+		indexedHeight: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l2_block_header_indexer_height",
+			Help: "The height of the latest indexed l2 block header",
+		}),
+		reorgTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_l2_block_header_indexer_reorg_total",
+			Help: "The total number of indexed l2 block headers replaced after a reorg",
+		}),
+	}
+}
+
+// TryFetchHeaders indexes the headers following the latest indexed one, up to the confirmed block number.
+// A fetched header which is not a child of the indexed one before it means a reorg, the indexed headers are then
+// replaced down to the common ancestor.
+func (i *L2BlockHeaderIndexer) TryFetchHeaders(confirmed uint64) error {
+	i.indexerRunTotal.Inc()
+
+	latest, err := i.l2BlockHeaderOrm.GetLatestL2BlockHeaders(i.ctx, 1)
+	if err != nil {
+		return err
+	}
+
+	fetchLimit := defaultL2BlockHeaderFetchLimit
+	if i.cfg.FetchLimit > 0 {
+		fetchLimit = i.cfg.FetchLimit
+	}
+
+	var from uint64
+	if len(latest) > 0 {
+		from = latest[0].Number + 1
+	} else if i.cfg.StartHeight > 0 {
+		from = i.cfg.StartHeight
+	} else if confirmed >= fetchLimit {
+		from = confirmed - fetchLimit + 1
+	}
+	if from > confirmed {
+		return nil
+	}
+	to := confirmed
+	if to-from+1 > fetchLimit {
+		to = from + fetchLimit - 1
+	}
+
+	headers, err := i.fetchHeaders(from, to)
+	if err != nil {
+		return err
+	}
+
+	for depth := 0; from > 0; depth++ {
+		parent, getErr := i.l2BlockHeaderOrm.GetL2BlockHeaderByNumber(i.ctx, from-1)
+		if getErr != nil {
+			return getErr
+		}
+		if parent == nil || parent.Hash == headers[0].ParentHash {
+			break
+		}
+		if depth >= maxL2BlockHeaderReorgDepth {
+			return fmt.Errorf("l2 reorg deeper than %d blocks at block %d", maxL2BlockHeaderReorgDepth, from)
+		}
+		from--
+		reorged, fetchErr := i.fetchHeaders(from, from)
+		if fetchErr != nil {
+			return fetchErr
+		}
+		headers = append(reorged, headers...)
+		i.reorgTotal.Inc()
+		log.Warn("l2 block header reorged", "number", from)
+	}
+
+	// the headers of a single run may straddle a reorg, only the chained prefix is indexed.
+	for k := 1; k < len(headers); k++ {
+		if headers[k].ParentHash != headers[k-1].Hash {
+			headers = headers[:k]
+			break
+		}
+	}
+
+	if err = i.l2BlockHeaderOrm.InsertL2BlockHeaders(i.ctx, headers); err != nil {
+		return err
+	}
+	indexed := headers[len(headers)-1].Number
+	i.indexedHeight.Set(float64(indexed))
+
+	if i.cfg.RetentionBlocks > 0 && indexed >= i.cfg.RetentionBlocks {
+		if _, err = i.l2BlockHeaderOrm.DeleteL2BlockHeadersBelow(i.ctx, indexed-i.cfg.RetentionBlocks+1); err != nil {
+			log.Warn("failed to delete expired l2 block headers", "err", err)
+		}
+	}
+	return nil
+}
+
+// rpcBlock is the part of an eth_getBlockByNumber response without full transactions, besides the header.
+type rpcBlock struct {
+	Hash         common.Hash   `json:"hash"`
+	Transactions []common.Hash `json:"transactions"`
+}
+
+// fetchHeaders fetches the headers of the blocks in the range (inclusive) in a single batch call.
+func (i *L2BlockHeaderIndexer) fetchHeaders(from, to uint64) ([]*orm.L2BlockHeader, error) {
+	results := make([]json.RawMessage, to-from+1)
+	elems := make([]rpc.BatchElem, len(results))
+	for k := range elems {
+		elems[k] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(from + uint64(k)), false},
+			Result: &results[k],
+		}
+	}
+	if err := i.client.BatchCallContext(i.ctx, elems); err != nil {
+		return nil, fmt.Errorf("failed to get l2 blocks in range [%d, %d], err: %w", from, to, err)
+	}
+
+	headers := make([]*orm.L2BlockHeader, len(results))
+	for k, result := range results {
+		number := from + uint64(k)
+		if elems[k].Error != nil {
+			return nil, fmt.Errorf("failed to get l2 block %d, err: %w", number, elems[k].Error)
+		}
+		if len(result) == 0 || string(result) == "null" {
+			return nil, fmt.Errorf("l2 block %d not found", number)
+		}
+
+		var header gethTypes.Header
+		if err := json.Unmarshal(result, &header); err != nil {
+			return nil, fmt.Errorf("failed to decode l2 block header %d, err: %w", number, err)
+		}
+		var block rpcBlock
+		if err := json.Unmarshal(result, &block); err != nil {
+			return nil, fmt.Errorf("failed to decode l2 block %d, err: %w", number, err)
+		}
+
+		var baseFee uint64
+		if header.BaseFee != nil {
+			baseFee = header.BaseFee.Uint64()
+		}
+		headers[k] = &orm.L2BlockHeader{
+			Number:         number,
+			Hash:           block.Hash.Hex(),
+			ParentHash:     header.ParentHash.Hex(),
+			BaseFee:        baseFee,
+			GasUsed:        header.GasUsed,
+			GasLimit:       header.GasLimit,
+			TxNum:          uint32(len(block.Transactions)),
+			BlockTimestamp: header.Time,
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// mockL2Chain serves eth_getBlockByNumber of a chain of headers which can be reorged.
+type mockL2Chain struct {
+	mu      sync.Mutex
+	headers map[uint64]*gethTypes.Header
+}
+
+// build replaces the blocks in the range (inclusive) with blocks of the given fork, chained to the block before.
+func (c *mockL2Chain) build(from, to uint64, fork byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for number := from; number <= to; number++ {
+		header := &gethTypes.Header{
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: big.NewInt(0),
+			BaseFee:    new(big.Int).SetUint64(number + 1),
+			Extra:      []byte{fork},
+		}
+		if parent, ok := c.headers[number-1]; ok && number > 0 {
+			header.ParentHash = parent.Hash()
+		}
+		c.headers[number] = header
+	}
+}
+
+func (c *mockL2Chain) hash(number uint64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.headers[number].Hash().Hex()
+}
+
+func (c *mockL2Chain) GetBlockByNumber(number hexutil.Uint64, _ bool) (map[string]interface{}, error) {
+	c.mu.Lock()
+	header, ok := c.headers[uint64(number)]
+	c.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	var block map[string]interface{}
+	if err = json.Unmarshal(data, &block); err != nil {
+		return nil, err
+	}
+	block["transactions"] = []common.Hash{}
+	return block, nil
+}
+
+func newTestL2BlockHeaderIndexer(t *testing.T, db *gorm.DB, chain *mockL2Chain, fetchLimit uint64) *L2BlockHeaderIndexer {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", chain))
+	t.Cleanup(server.Stop)
+	client := rpc.DialInProc(server)
+	t.Cleanup(client.Close)
+	return NewL2BlockHeaderIndexer(context.Background(), client, &config.L2BlockHeaderIndexerConfig{Enabled: true, FetchLimit: fetchLimit}, db, nil)
+}
+
+func assertIndexedChain(t *testing.T, db *gorm.DB, chain *mockL2Chain, from, to uint64) {
+	headers, err := orm.NewL2BlockHeader(db).GetL2BlockHeadersInRange(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.Len(t, headers, int(to-from+1))
+	for _, header := range headers {
+		assert.Equal(t, chain.hash(header.Number), header.Hash, fmt.Sprintf("block %d", header.Number))
+	}
+}
+
+func testL2BlockHeaderIndexerReorg(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	chain := &mockL2Chain{headers: make(map[uint64]*gethTypes.Header)}
+	chain.build(0, 99, 0)
+	indexer := newTestL2BlockHeaderIndexer(t, db, chain, 100)
+	assert.NoError(t, indexer.TryFetchHeaders(99))
+	assertIndexedChain(t, db, chain, 0, 99)
+
+	// blocks 97 to 99 are reorged, the indexed ones are replaced down to the common ancestor 96.
+	chain.build(97, 102, 1)
+	assert.NoError(t, indexer.TryFetchHeaders(102))
+	assertIndexedChain(t, db, chain, 0, 102)
+	assert.Equal(t, float64(3), testutil.ToFloat64(indexer.reorgTotal))
+	assert.Equal(t, float64(102), testutil.ToFloat64(indexer.indexedHeight))
+
+	// a reorg deeper than the max depth is reported instead of rewriting the index.
+	chain.build(102-maxL2BlockHeaderReorgDepth-10, 105, 2)
+	assert.Error(t, indexer.TryFetchHeaders(105))
+	latest, err := orm.NewL2BlockHeader(db).GetLatestL2BlockHeaders(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(102), latest[0].Number)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/eventwatcher"
 	"scroll-tech/common/types/encoding"
 	"scroll-tech/common/types/encoding/codecv0"
 
@@ -71,19 +72,13 @@ func (w *L2WatcherClient) TryFetchRunningMissingBlocks(blockHeight uint64) {
 	}
 
 	// Fetch and store block traces for missing blocks
-	for from := heightInDB + 1; from <= blockHeight; from += blocksFetchLimit {
-		to := from + blocksFetchLimit - 1
-
-		if to > blockHeight {
-			to = blockHeight
-		}
-
-		if err = w.getAndStoreBlocks(w.ctx, from, to); err != nil {
-			log.Error("fail to getAndStoreBlockTraces", "from", from, "to", to, "err", err)
+	for _, r := range eventwatcher.PlanRanges(heightInDB, blockHeight, blocksFetchLimit) {
+		if err = w.getAndStoreBlocks(w.ctx, r.From, r.To); err != nil {
+			log.Error("fail to getAndStoreBlockTraces", "from", r.From, "to", r.To, "err", err)
 			return
 		}
-		w.metrics.fetchRunningMissingBlocksHeight.Set(float64(to))
-		w.metrics.rollupL2BlocksFetchedGap.Set(float64(blockHeight - to))
+		w.metrics.fetchRunningMissingBlocksHeight.Set(float64(r.To))
+		w.metrics.rollupL2BlocksFetchedGap.Set(float64(blockHeight - r.To))
 	}
 }
 
@@ -92,14 +92,17 @@ func TestFunction(t *testing.T) {
 	t.Run("TestParseBridgeEventLogsL1QueueTransactionEventSignature", testParseBridgeEventLogsL1QueueTransactionEventSignature)
 	t.Run("TestParseBridgeEventLogsL1CommitBatchEventSignature", testParseBridgeEventLogsL1CommitBatchEventSignature)
 	t.Run("TestParseBridgeEventLogsL1FinalizeBatchEventSignature", testParseBridgeEventLogsL1FinalizeBatchEventSignature)
+	t.Run("TestParseSkippedQueueIndices", testParseSkippedQueueIndices)
 
 	// Run l2 watcher test cases.
 	t.Run("TestFetchRunningMissingBlocks", testFetchRunningMissingBlocks)
+	t.Run("TestL2BlockHeaderIndexerReorg", testL2BlockHeaderIndexerReorg)
 
 	// Run chunk proposer test cases.
 	t.Run("TestChunkProposerCodecv0Limits", testChunkProposerCodecv0Limits)
 	t.Run("TestChunkProposerCodecv1Limits", testChunkProposerCodecv1Limits)
 	t.Run("TestChunkProposerCodecv1BlobSizeLimit", testChunkProposerCodecv1BlobSizeLimit)
+	t.Run("TestChunkProposerForkBoundary", testChunkProposerForkBoundary)
 
 	// Run chunk proposer test cases.
 	t.Run("TestBatchProposerCodecv0Limits", testBatchProposerCodecv0Limits)
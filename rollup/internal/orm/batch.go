@@ -9,6 +9,7 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"scroll-tech/common/types"
 	"scroll-tech/common/types/encoding"
@@ -218,6 +219,23 @@ func (o *Batch) GetBatchByIndex(ctx context.Context, index uint64) (*Batch, erro
 	return &batch, nil
 }
 
+// GetBatchesGEIndexForUpdate retrieves the batches with an index greater than or equal to the given index
+// and locks them until dbTX ends, so that no batch is chained to them or replaces them meanwhile.
+// The returned batches are sorted by index in ascending order.
+func (o *Batch) GetBatchesGEIndexForUpdate(ctx context.Context, index uint64, dbTX *gorm.DB) ([]*Batch, error) {
+	db := dbTX.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	db = db.Where("index >= ?", index)
+	db = db.Order("index ASC")
+
+	var batches []*Batch
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetBatchesGEIndexForUpdate error: %w, index: %v", err, index)
+	}
+	return batches, nil
+}
+
 // InsertBatch inserts a new batch into the database.
 func (o *Batch) InsertBatch(ctx context.Context, batch *encoding.Batch, codecVersion encoding.CodecVersion, dbTX ...*gorm.DB) (*Batch, error) {
 	if batch == nil {
@@ -287,6 +305,34 @@ func (o *Batch) InsertBatch(ctx context.Context, batch *encoding.Batch, codecVer
 	return &newBatch, nil
 }
 
+// DeleteUncommittedBatchesGEIndex deletes the batches with an index greater than or equal to the given index.
+// It fails without deleting anything if one of these batches has been sent to L1 and not failed.
+func (o *Batch) DeleteUncommittedBatchesGEIndex(ctx context.Context, index uint64, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+
+	var sentCount int64
+	countDB := db.Model(&Batch{})
+	countDB = countDB.Where("index >= ?", index)
+	countDB = countDB.Where("rollup_status NOT IN ?", []types.RollupStatus{types.RollupPending, types.RollupCommitFailed})
+	if err := countDB.Count(&sentCount).Error; err != nil {
+		return fmt.Errorf("Batch.DeleteUncommittedBatchesGEIndex error: %w, index: %v", err, index)
+	}
+	if sentCount > 0 {
+		return fmt.Errorf("Batch.DeleteUncommittedBatchesGEIndex error: %v batches from index %v have been sent to L1", sentCount, index)
+	}
+
+	deleteDB := db.Model(&Batch{})
+	deleteDB = deleteDB.Where("index >= ?", index)
+	if err := deleteDB.Delete(&Batch{}).Error; err != nil {
+		return fmt.Errorf("Batch.DeleteUncommittedBatchesGEIndex error: %w, index: %v", err, index)
+	}
+	return nil
+}
+
 // UpdateL2GasOracleStatusAndOracleTxHash updates the L2 gas oracle status and transaction hash for a batch.
 func (o *Batch) UpdateL2GasOracleStatusAndOracleTxHash(ctx context.Context, hash string, status types.GasOracleStatus, txHash string) error {
 	updateFields := make(map[string]interface{})
@@ -258,3 +258,20 @@ func (o *Chunk) UpdateBatchHashInRange(ctx context.Context, startIndex uint64, e
 	}
 	return nil
 }
+
+// ResetBatchHashGEIndex clears the batch_hash of the chunks with an index greater than or equal to the given index,
+// so that they are batched again.
+func (o *Chunk) ResetBatchHashGEIndex(ctx context.Context, startIndex uint64, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("index >= ?", startIndex)
+
+	if err := db.Update("batch_hash", gorm.Expr("NULL")).Error; err != nil {
+		return fmt.Errorf("Chunk.ResetBatchHashGEIndex error: %w, start index: %v", err, startIndex)
+	}
+	return nil
+}
@@ -3,10 +3,16 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
 )
 
 // L1Message is structure of stored layer1 bridge message
@@ -25,6 +31,14 @@ type L1Message struct {
 	Layer2Hash string `json:"layer2_hash" gorm:"column:layer2_hash;default:NULL"`
 	Status     int    `json:"status" gorm:"column:status;default:1"`
 
+	// skipped message handling
+	SkipReason              int     `json:"skip_reason" gorm:"column:skip_reason;default:0"`
+	ReplayCount             int16   `json:"replay_count" gorm:"column:replay_count;default:0"`
+	ReplayTxHash            string  `json:"replay_tx_hash" gorm:"column:replay_tx_hash;default:NULL"`
+	ReplayGasLimit          uint64  `json:"replay_gas_limit" gorm:"column:replay_gas_limit;default:NULL"`
+	ReplayRequestedGasLimit *uint64 `json:"replay_requested_gas_limit" gorm:"column:replay_requested_gas_limit;default:NULL"`
+	ReplayEstimatedGas      *uint64 `json:"replay_estimated_gas" gorm:"column:replay_estimated_gas;default:NULL"`
+
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
@@ -56,21 +70,252 @@ func (m *L1Message) GetLayer1LatestWatchedHeight() (int64, error) {
 	return -1, nil
 }
 
-// SaveL1Messages batch save a list of layer1 messages
+// L1MessageConflict is a saved layer1 message whose queue index is already stored with another message hash.
+type L1MessageConflict struct {
+	QueueIndex    uint64
+	MsgHash       string
+	StoredMsgHash string
+}
+
+// L1MessageConflictError is returned by SaveL1Messages if some of the messages conflict with the stored ones.
+type L1MessageConflictError struct {
+	Conflicts []L1MessageConflict
+}
+
+func (e *L1MessageConflictError) Error() string {
+	conflicts := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		conflicts = append(conflicts, fmt.Sprintf("queue index %v: msg hash %s, stored %s", c.QueueIndex, c.MsgHash, c.StoredMsgHash))
+	}
+	return fmt.Sprintf("%d l1 messages conflict with the stored ones: %s", len(e.Conflicts), strings.Join(conflicts, "; "))
+}
+
+// SaveL1Messages batch save a list of layer1 messages in a transaction. The messages whose queue index is already
+// stored with the same message hash are skipped, so the watcher can save the messages of a block range again after
+// a partial failure. The stored messages are left as they are, including their status. If a queue index is stored
+// with another message hash, none of the messages are saved and an *L1MessageConflictError lists the conflicting ones.
 func (m *L1Message) SaveL1Messages(ctx context.Context, messages []*L1Message) error {
 	if len(messages) == 0 {
 		return nil
 	}
 
-	err := m.db.WithContext(ctx).Create(&messages).Error
+	queueIndices := make([]uint64, 0, len(messages))
+	for _, msg := range messages {
+		queueIndices = append(queueIndices, msg.QueueIndex)
+	}
+
+	err := database.TransactionWithRetry(ctx, m.db, func(tx *gorm.DB) error {
+		db := tx.Model(&L1Message{})
+		db = db.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "queue_index"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		})
+		if err := db.Create(&messages).Error; err != nil {
+			return err
+		}
+
+		var stored []*L1Message
+		db = tx.Model(&L1Message{})
+		db = db.Select("queue_index, msg_hash")
+		db = db.Where("queue_index IN ?", queueIndices)
+		if err := db.Find(&stored).Error; err != nil {
+			return err
+		}
+		storedMsgHashes := make(map[uint64]string, len(stored))
+		for _, msg := range stored {
+			storedMsgHashes[msg.QueueIndex] = msg.MsgHash
+		}
+		var conflicts []L1MessageConflict
+		for _, msg := range messages {
+			if storedMsgHash := storedMsgHashes[msg.QueueIndex]; storedMsgHash != msg.MsgHash {
+				conflicts = append(conflicts, L1MessageConflict{QueueIndex: msg.QueueIndex, MsgHash: msg.MsgHash, StoredMsgHash: storedMsgHash})
+			}
+		}
+		if len(conflicts) > 0 {
+			return &L1MessageConflictError{Conflicts: conflicts}
+		}
+		return nil
+	})
 	if err != nil {
-		queueIndices := make([]uint64, 0, len(messages))
 		heights := make([]uint64, 0, len(messages))
 		for _, msg := range messages {
-			queueIndices = append(queueIndices, msg.QueueIndex)
 			heights = append(heights, msg.Height)
 		}
 		log.Error("failed to insert l1Messages", "queueIndices", queueIndices, "heights", heights, "err", err)
+		return fmt.Errorf("L1Message.SaveL1Messages error: %w", err)
+	}
+	return nil
+}
+
+// GetSkippedL1Messages returns the skipped layer1 messages ordered by queue index, limit <= 0 returns all of them.
+func (m *L1Message) GetSkippedL1Messages(ctx context.Context, limit int) ([]*L1Message, error) {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("status = ?", types.MsgSkipped)
+	db = db.Order("queue_index ASC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var messages []*L1Message
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("L1Message.GetSkippedL1Messages error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetL1MessageByQueueIndex returns the layer1 message of the queue index, or nil if it does not exist.
+func (m *L1Message) GetL1MessageByQueueIndex(ctx context.Context, queueIndex uint64) (*L1Message, error) {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+
+	var message L1Message
+	if err := db.First(&message).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("L1Message.GetL1MessageByQueueIndex error: %w, queue index: %v", err, queueIndex)
+	}
+	return &message, nil
+}
+
+// UpdateL1MessagesSkipped marks the layer1 messages of the queue indices skipped, replayed messages are not updated.
+func (m *L1Message) UpdateL1MessagesSkipped(ctx context.Context, queueIndices []uint64) error {
+	if len(queueIndices) == 0 {
+		return nil
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index IN ?", queueIndices)
+	db = db.Where("status != ?", types.MsgReplayed)
+
+	if err := db.Update("status", types.MsgSkipped).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateL1MessagesSkipped error: %w, queue indices: %v", err, queueIndices)
+	}
+	return nil
+}
+
+// UpdateSkipReason updates the skip reason of a skipped layer1 message.
+func (m *L1Message) UpdateSkipReason(ctx context.Context, queueIndex uint64, reason types.MsgSkipReason) error {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+
+	if err := db.Update("skip_reason", int(reason)).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateSkipReason error: %w, queue index: %v, reason: %v", err, queueIndex, reason.String())
+	}
+	return nil
+}
+
+// RequestReplay requests the replay of a skipped layer1 message with the gas limit, regardless of the replay policy.
+func (m *L1Message) RequestReplay(ctx context.Context, queueIndex uint64, gasLimit uint64) error {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+	db = db.Where("status = ?", types.MsgSkipped)
+
+	result := db.Update("replay_requested_gas_limit", gasLimit)
+	if result.Error != nil {
+		return fmt.Errorf("L1Message.RequestReplay error: %w, queue index: %v, gas limit: %v", result.Error, queueIndex, gasLimit)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("L1Message.RequestReplay error: no skipped message of queue index %v", queueIndex)
+	}
+	return nil
+}
+
+// UpdateReplayEstimatedGas records the latest gas estimate of the relayMessage call of a skipped layer1 message on layer2,
+// nil if the simulation failed.
+func (m *L1Message) UpdateReplayEstimatedGas(ctx context.Context, queueIndex uint64, estimatedGas *uint64) error {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+
+	if err := db.Update("replay_estimated_gas", estimatedGas).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateReplayEstimatedGas error: %w, queue index: %v", err, queueIndex)
+	}
+	return nil
+}
+
+// UpdateL1MessageReplaying marks a skipped layer1 message replayed with the gas limit before its replay tx is sent,
+// so that a message is never replayed twice even if the tx hash can not be recorded after the tx was sent.
+// It returns false if the message is no longer skipped, e.g. it is already being replayed.
+func (m *L1Message) UpdateL1MessageReplaying(ctx context.Context, queueIndex uint64, gasLimit uint64) (bool, error) {
+	updateFields := map[string]interface{}{
+		"status":                     int(types.MsgReplayed),
+		"replay_count":               gorm.Expr("replay_count + 1"),
+		"replay_tx_hash":             nil,
+		"replay_gas_limit":           gasLimit,
+		"replay_requested_gas_limit": nil,
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+	db = db.Where("status = ?", types.MsgSkipped)
+
+	result := db.Updates(updateFields)
+	if result.Error != nil {
+		return false, fmt.Errorf("L1Message.UpdateL1MessageReplaying error: %w, queue index: %v, gas limit: %v", result.Error, queueIndex, gasLimit)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateL1MessageReplaySendFailed reverts UpdateL1MessageReplaying after the replay tx failed to be sent,
+// restoring the replay requested by an operator if any.
+func (m *L1Message) UpdateL1MessageReplaySendFailed(ctx context.Context, queueIndex uint64, requestedGasLimit *uint64) error {
+	updateFields := map[string]interface{}{
+		"status":                     int(types.MsgSkipped),
+		"replay_count":               gorm.Expr("replay_count - 1"),
+		"replay_gas_limit":           nil,
+		"replay_requested_gas_limit": requestedGasLimit,
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+	db = db.Where("status = ?", types.MsgReplayed)
+	db = db.Where("replay_tx_hash IS NULL")
+
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateL1MessageReplaySendFailed error: %w, queue index: %v", err, queueIndex)
+	}
+	return nil
+}
+
+// UpdateL1MessageReplayTxHash records the sent replay tx of a layer1 message.
+func (m *L1Message) UpdateL1MessageReplayTxHash(ctx context.Context, queueIndex uint64, txHash string) error {
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+	db = db.Where("status = ?", types.MsgReplayed)
+
+	if err := db.Update("replay_tx_hash", txHash).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateL1MessageReplayTxHash error: %w, queue index: %v, tx hash: %v", err, queueIndex, txHash)
+	}
+	return nil
+}
+
+// UpdateL1MessageReplayResult records the confirmed replay tx of a layer1 message, which may differ from the sent one
+// after gas price escalation. The message is marked skipped again if the replay tx failed, so that it can be replayed again.
+func (m *L1Message) UpdateL1MessageReplayResult(ctx context.Context, queueIndex uint64, txHash string, isSuccessful bool) error {
+	updateFields := map[string]interface{}{
+		"replay_tx_hash": txHash,
+	}
+	if !isSuccessful {
+		updateFields["status"] = int(types.MsgSkipped)
+	}
+
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	db = db.Where("queue_index = ?", queueIndex)
+	db = db.Where("status = ?", types.MsgReplayed)
+
+	if err := db.Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("L1Message.UpdateL1MessageReplayResult error: %w, queue index: %v, tx hash: %v", err, queueIndex, txHash)
 	}
-	return err
+	return nil
 }
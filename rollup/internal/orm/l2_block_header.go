@@ -0,0 +1,131 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// L2BlockHeader is the fee related header data of an indexed l2 block.
+type L2BlockHeader struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	// block
+	Number         uint64 `json:"number" gorm:"column:number"`
+	Hash           string `json:"hash" gorm:"column:hash"`
+	ParentHash     string `json:"parent_hash" gorm:"column:parent_hash"`
+	BaseFee        uint64 `json:"base_fee" gorm:"column:base_fee"`
+	GasUsed        uint64 `json:"gas_used" gorm:"column:gas_used"`
+	GasLimit       uint64 `json:"gas_limit" gorm:"column:gas_limit"`
+	TxNum          uint32 `json:"tx_num" gorm:"column:tx_num"`
+	BlockTimestamp uint64 `json:"block_timestamp" gorm:"column:block_timestamp"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewL2BlockHeader creates a new L2BlockHeader instance.
+func NewL2BlockHeader(db *gorm.DB) *L2BlockHeader {
+	return &L2BlockHeader{db: db}
+}
+
+// TableName returns the name of the "l2_block_header" table.
+func (*L2BlockHeader) TableName() string {
+	return "l2_block_header"
+}
+
+// GetL2BlockHeaderByNumber retrieves the indexed l2 block header of the given number, nil if it is not indexed.
+func (o *L2BlockHeader) GetL2BlockHeaderByNumber(ctx context.Context, number uint64) (*L2BlockHeader, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2BlockHeader{})
+	db = db.Where("number = ?", number)
+
+	var header L2BlockHeader
+	if err := db.First(&header).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("L2BlockHeader.GetL2BlockHeaderByNumber error: %w, number: %v", err, number)
+	}
+	return &header, nil
+}
+
+// GetLatestL2BlockHeaders retrieves the latest indexed l2 block headers, in descending order of their numbers.
+func (o *L2BlockHeader) GetLatestL2BlockHeaders(ctx context.Context, limit int) ([]*L2BlockHeader, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2BlockHeader{})
+	db = db.Order("number DESC")
+	db = db.Limit(limit)
+
+	var headers []*L2BlockHeader
+	if err := db.Find(&headers).Error; err != nil {
+		return nil, fmt.Errorf("L2BlockHeader.GetLatestL2BlockHeaders error: %w, limit: %v", err, limit)
+	}
+	return headers, nil
+}
+
+// GetL2BlockHeadersInRange retrieves the indexed l2 block headers within the specified range (inclusive).
+// The range is closed, i.e., it includes both start and end block numbers.
+func (o *L2BlockHeader) GetL2BlockHeadersInRange(ctx context.Context, startBlockNumber uint64, endBlockNumber uint64) ([]*L2BlockHeader, error) {
+	if startBlockNumber > endBlockNumber {
+		return nil, fmt.Errorf("L2BlockHeader.GetL2BlockHeadersInRange: start block number should be less than or equal to end block number, start block: %v, end block: %v", startBlockNumber, endBlockNumber)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2BlockHeader{})
+	db = db.Where("number >= ? AND number <= ?", startBlockNumber, endBlockNumber)
+	db = db.Order("number ASC")
+
+	var headers []*L2BlockHeader
+	if err := db.Find(&headers).Error; err != nil {
+		return nil, fmt.Errorf("L2BlockHeader.GetL2BlockHeadersInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
+	}
+	return headers, nil
+}
+
+// InsertL2BlockHeaders batch inserts l2 block headers.
+// The headers from the lowest inserted number on are soft deleted first, so that re-inserting blocks after a reorg
+// replaces the reorged ones.
+func (o *L2BlockHeader) InsertL2BlockHeaders(ctx context.Context, headers []*L2BlockHeader) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		minBlockNumber := headers[0].Number
+		for _, header := range headers[1:] {
+			if header.Number < minBlockNumber {
+				minBlockNumber = header.Number
+			}
+		}
+
+		db := tx.WithContext(ctx)
+		db = db.Model(&L2BlockHeader{})
+		db = db.Where("number >= ?", minBlockNumber)
+		if err := db.Delete(&L2BlockHeader{}).Error; err != nil {
+			return fmt.Errorf("L2BlockHeader.InsertL2BlockHeaders error: soft deleting headers failed, block numbers starting from: %v, error: %w", minBlockNumber, err)
+		}
+
+		if err := tx.WithContext(ctx).Create(&headers).Error; err != nil {
+			return fmt.Errorf("L2BlockHeader.InsertL2BlockHeaders error: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteL2BlockHeadersBelow permanently deletes the l2 block headers below the given number, including soft deleted ones.
+func (o *L2BlockHeader) DeleteL2BlockHeadersBelow(ctx context.Context, number uint64) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Unscoped()
+	db = db.Where("number < ?", number)
+	result := db.Delete(&L2BlockHeader{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("L2BlockHeader.DeleteL2BlockHeadersBelow error: %w, number: %v", result.Error, number)
+	}
+	return result.RowsAffected, nil
+}
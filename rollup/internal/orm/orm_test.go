@@ -3,6 +3,7 @@ package orm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"os"
 	"testing"
@@ -131,6 +132,40 @@ func TestL1BlockOrm(t *testing.T) {
 	assert.Equal(t, "txhash1", updatedBlocks[0].OracleTxHash)
 }
 
+func TestL1MessageOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	l1MessageOrm := NewL1Message(db)
+	newMessage := func(queueIndex uint64, msgHash string) *L1Message {
+		return &L1Message{QueueIndex: queueIndex, MsgHash: msgHash, Height: queueIndex, Sender: "sender", Target: "target", Value: "0", Calldata: "0x", Layer1Hash: "layer1Hash"}
+	}
+
+	err = l1MessageOrm.SaveL1Messages(context.Background(), []*L1Message{newMessage(0, "hash0"), newMessage(1, "hash1")})
+	assert.NoError(t, err)
+	assert.NoError(t, l1MessageOrm.UpdateL1MessagesSkipped(context.Background(), []uint64{1}))
+
+	// saving the messages again after a partial failure skips the stored ones, with their status.
+	err = l1MessageOrm.SaveL1Messages(context.Background(), []*L1Message{newMessage(1, "hash1"), newMessage(2, "hash2"), newMessage(2, "hash2")})
+	assert.NoError(t, err)
+	msg, err := l1MessageOrm.GetL1MessageByQueueIndex(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int(types.MsgSkipped), msg.Status)
+	msg, err = l1MessageOrm.GetL1MessageByQueueIndex(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "hash2", msg.MsgHash)
+
+	// a queue index stored with another message hash fails the batch and is surfaced.
+	err = l1MessageOrm.SaveL1Messages(context.Background(), []*L1Message{newMessage(2, "hash2-reorg"), newMessage(3, "hash3")})
+	var conflictErr *L1MessageConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, []L1MessageConflict{{QueueIndex: 2, MsgHash: "hash2-reorg", StoredMsgHash: "hash2"}}, conflictErr.Conflicts)
+	msg, err = l1MessageOrm.GetL1MessageByQueueIndex(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+}
+
 func TestL2BlockOrm(t *testing.T) {
 	sqlDB, err := db.DB()
 	assert.NoError(t, err)
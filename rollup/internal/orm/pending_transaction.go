@@ -85,6 +85,37 @@ func (o *PendingTransaction) GetPendingOrReplacedTransactionsBySenderType(ctx co
 	return transactions, nil
 }
 
+// GetPendingOrReplacedTransactionsBySenderAddress retrieves pending or replaced transactions of a sender type sent by an address, ordered by nonce, then gas_fee_cap (gas_price in legacy tx), and limited to a specified count.
+func (o *PendingTransaction) GetPendingOrReplacedTransactionsBySenderAddress(ctx context.Context, senderType types.SenderType, senderAddress common.Address, limit int) ([]PendingTransaction, error) {
+	var transactions []PendingTransaction
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("sender_type = ?", senderType)
+	db = db.Where("sender_address = ?", senderAddress.String())
+	db = db.Where("status = ? OR status = ?", types.TxStatusPending, types.TxStatusReplaced)
+	db = db.Order("nonce asc")
+	db = db.Order("gas_fee_cap asc")
+	db = db.Limit(limit)
+	if err := db.Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending or replaced transactions by sender address, error: %w", err)
+	}
+	return transactions, nil
+}
+
+// CountPendingNoncesBySenderAddress returns the number of nonces of an address whose transactions are pending or replaced, i.e. its unconfirmed transactions, replacements not counted twice.
+func (o *PendingTransaction) CountPendingNoncesBySenderAddress(ctx context.Context, senderAddress common.Address) (uint64, error) {
+	var count int64
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("sender_address = ?", senderAddress.String())
+	db = db.Where("status = ? OR status = ?", types.TxStatusPending, types.TxStatusReplaced)
+	db = db.Distinct("nonce")
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending nonces by sender address, error: %w", err)
+	}
+	return uint64(count), nil
+}
+
 // GetConfirmedTransactionsBySenderType retrieves confirmed transactions filtered by sender type, limited to a specified count.
 // for unit test
 func (o *PendingTransaction) GetConfirmedTransactionsBySenderType(ctx context.Context, senderType types.SenderType, limit int) ([]PendingTransaction, error) {
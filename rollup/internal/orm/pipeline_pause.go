@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PipelinePause is a relayer pipeline paused through the admin api, it stays paused until the row is deleted.
+type PipelinePause struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Pipeline string `json:"pipeline" gorm:"column:pipeline;primaryKey"`
+	Reason   string `json:"reason" gorm:"column:reason"`
+
+	// metadata
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// NewPipelinePause creates a PipelinePause instance
+func NewPipelinePause(db *gorm.DB) *PipelinePause {
+	return &PipelinePause{db: db}
+}
+
+// TableName define the PipelinePause table name
+func (*PipelinePause) TableName() string {
+	return "relayer_pipeline_pause"
+}
+
+// GetPipelinePauses returns the paused pipelines.
+func (o *PipelinePause) GetPipelinePauses(ctx context.Context) ([]*PipelinePause, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PipelinePause{})
+	db = db.Order("pipeline ASC")
+
+	var pauses []*PipelinePause
+	if err := db.Find(&pauses).Error; err != nil {
+		return nil, fmt.Errorf("PipelinePause.GetPipelinePauses error: %w", err)
+	}
+	return pauses, nil
+}
+
+// PausePipeline pauses the pipeline, the reason of a paused one is updated.
+func (o *PipelinePause) PausePipeline(ctx context.Context, pipeline, reason string) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PipelinePause{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "pipeline"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "updated_at"}),
+	})
+	if err := db.Create(&PipelinePause{Pipeline: pipeline, Reason: reason}).Error; err != nil {
+		return fmt.Errorf("PipelinePause.PausePipeline error: %w, pipeline: %v", err, pipeline)
+	}
+	return nil
+}
+
+// ResumePipeline resumes the pipeline, it is a no-op if the pipeline is not paused.
+func (o *PipelinePause) ResumePipeline(ctx context.Context, pipeline string) error {
+	db := o.db.WithContext(ctx)
+	db = db.Where("pipeline = ?", pipeline)
+	if err := db.Delete(&PipelinePause{}).Error; err != nil {
+		return fmt.Errorf("PipelinePause.ResumePipeline error: %w, pipeline: %v", err, pipeline)
+	}
+	return nil
+}
@@ -4,16 +4,13 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
-	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
 
+	"scroll-tech/common/codec"
 	"scroll-tech/common/types/encoding"
 	"scroll-tech/common/types/encoding/codecv0"
 	"scroll-tech/common/types/encoding/codecv1"
-
-	bridgeAbi "scroll-tech/rollup/abi"
 )
 
 // Keccak2 compute the keccack256 of two concatenations of bytes32
@@ -21,18 +18,6 @@ func Keccak2(a common.Hash, b common.Hash) common.Hash {
 	return common.BytesToHash(crypto.Keccak256(append(a.Bytes()[:], b.Bytes()[:]...)))
 }
 
-// ComputeMessageHash compute the message hash
-func ComputeMessageHash(
-	sender common.Address,
-	target common.Address,
-	value *big.Int,
-	messageNonce *big.Int,
-	message []byte,
-) common.Hash {
-	data, _ := bridgeAbi.L2ScrollMessengerABI.Pack("relayMessage", sender, target, value, messageNonce, message)
-	return common.BytesToHash(crypto.Keccak256(data))
-}
-
 // BufferToUint256Le convert bytes array to uint256 array assuming little-endian
 func BufferToUint256Le(buffer []byte) []*big.Int {
 	buffer256 := make([]*big.Int, len(buffer)/32)
@@ -48,26 +33,6 @@ func BufferToUint256Le(buffer []byte) []*big.Int {
 	return buffer256
 }
 
-// UnpackLog unpacks a retrieved log into the provided output structure.
-// @todo: add unit test.
-func UnpackLog(c *abi.ABI, out interface{}, event string, log types.Log) error {
-	if log.Topics[0] != c.Events[event].ID {
-		return fmt.Errorf("event signature mismatch")
-	}
-	if len(log.Data) > 0 {
-		if err := c.UnpackIntoInterface(out, event, log.Data); err != nil {
-			return err
-		}
-	}
-	var indexed abi.Arguments
-	for _, arg := range c.Events[event].Inputs {
-		if arg.Indexed {
-			indexed = append(indexed, arg)
-		}
-	}
-	return abi.ParseTopics(out, indexed, log.Topics[1:])
-}
-
 // ChunkMetrics indicates the metrics for proposing a chunk.
 type ChunkMetrics struct {
 	// common metrics
@@ -275,20 +240,12 @@ func GetBatchMetadata(batch *encoding.Batch, codecVersion encoding.CodecVersion)
 
 // GetTotalL1MessagePoppedBeforeBatch retrieves the total L1 messages popped before the batch.
 func GetTotalL1MessagePoppedBeforeBatch(parentBatchBytes []byte, codecVersion encoding.CodecVersion) (uint64, error) {
-	switch codecVersion {
-	case encoding.CodecV0:
-		parentDABatch, err := codecv0.NewDABatchFromBytes(parentBatchBytes)
-		if err != nil {
-			return 0, fmt.Errorf("failed to create parent DA batch from bytes using codecv0, err: %w", err)
-		}
-		return parentDABatch.TotalL1MessagePopped, nil
-	case encoding.CodecV1:
-		parentDABatch, err := codecv1.NewDABatchFromBytes(parentBatchBytes)
-		if err != nil {
-			return 0, fmt.Errorf("failed to create parent DA batch from bytes using codecv1, err: %w", err)
-		}
-		return parentDABatch.TotalL1MessagePopped, nil
-	default:
+	if codecVersion > codec.MaxVersion {
 		return 0, fmt.Errorf("unsupported codec version: %v", codecVersion)
 	}
+	parentBatchHeader, err := codec.DecodeBatchHeader(parentBatchBytes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode parent batch header, err: %w", err)
+	}
+	return parentBatchHeader.TotalL1MessagePopped, nil
 }
@@ -25,17 +25,6 @@ func TestKeccak2(t *testing.T) {
 	}
 }
 
-func TestComputeMessageHash(t *testing.T) {
-	hash := ComputeMessageHash(
-		common.HexToAddress("0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63"),
-		common.HexToAddress("0x4592D8f8D7B001e72Cb26A73e4Fa1806a51aC79d"),
-		big.NewInt(0),
-		big.NewInt(1),
-		[]byte("testbridgecontract"),
-	)
-	assert.Equal(t, "0xda253c04595a49017bb54b1b46088c69752b5ad2f0c47971ac76b8b25abec202", hash.String())
-}
-
 func TestBufferToUint256Le(t *testing.T) {
 	input := []byte{
 		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -0,0 +1,41 @@
+package integration_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/crossdomain"
+
+	backendAbi "scroll-tech/bridge-history-api/abi"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+)
+
+// TestMessageHashConsistency checks the messages hashed by crossdomain are the relayMessage calls of the messenger
+// abis of both rollup and bridge-history-api.
+func TestMessageHashConsistency(t *testing.T) {
+	sender := common.HexToAddress("0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63")
+	target := common.HexToAddress("0x4592D8f8D7B001e72Cb26A73e4Fa1806a51aC79d")
+	value, messageNonce, message := big.NewInt(1e18), big.NewInt(123456), []byte("testbridgecontract")
+
+	hash := crossdomain.ComputeMessageHash(sender, target, value, messageNonce, message)
+
+	rollupData, err := bridgeAbi.L2ScrollMessengerABI.Pack("relayMessage", sender, target, value, messageNonce, message)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, crypto.Keccak256Hash(rollupData))
+
+	backendData, err := backendAbi.IL2ScrollMessengerABI.Pack("relayMessage", sender, target, value, messageNonce, message)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, crypto.Keccak256Hash(backendData))
+
+	decoded, encoding, err := crossdomain.DecodeMessage(rollupData)
+	assert.NoError(t, err)
+	assert.Equal(t, crossdomain.LatestEncoding, encoding)
+	assert.Equal(t, sender, decoded.Sender)
+	assert.Equal(t, target, decoded.Target)
+	assert.Equal(t, message, decoded.Message)
+}